@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/rehan/go-interview-prep/pkg/validator"
+)
+
+// Functions exercised by 06_testing_test.go.
+// They mirror the ones in testing/main.go so this package's tests can run.
+
+// Sum returns the sum of two integers
+func Sum(a, b int) int {
+	return a + b
+}
+
+// Multiply returns the product of two integers
+func Multiply(a, b int) int {
+	return a * b
+}
+
+// CircleArea returns the area of a circle with the given radius
+func CircleArea(radius float64) (float64, error) {
+	if radius < 0 {
+		return 0, fmt.Errorf("negative radius: %f", radius)
+	}
+	return math.Pi * radius * radius, nil
+}
+
+// WordCount counts the whitespace-separated words in a string
+func WordCount(s string) int {
+	scanner := bufio.NewScanner(strings.NewReader(s))
+	scanner.Split(bufio.ScanWords)
+	count := 0
+	for scanner.Scan() {
+		count++
+	}
+	return count
+}
+
+// User represents a user in the system.
+// The validate tags are read by the validator package.
+type User struct {
+	ID        int
+	FirstName string `validate:"required,max=50"`
+	LastName  string `validate:"required,max=50"`
+	Email     string `validate:"required,email"`
+	Age       int    `validate:"min=0"`
+}
+
+// ValidateUser checks if user data is valid, reporting every invalid field at once
+func ValidateUser(u User) error {
+	return validator.Struct(u)
+}
+
+// EmailSender is an interface for sending emails. Its test double,
+// EmailSenderMock, is generated by go generate ./basic-concepts.
+//
+//go:generate go run ../cmd/mockgen -type=EmailSender -out=emailsender_mock_test.go
+type EmailSender interface {
+	Send(email, subject, body string) error
+}
+
+// NotifyUser sends a notification email to a user
+func NotifyUser(user User, sender EmailSender) error {
+	body := fmt.Sprintf("Hello %s, your account has been created.", user.FirstName)
+	return sender.Send(user.Email, "Account Created", body)
+}
@@ -1,11 +1,17 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/rehan/go-interview-prep/basic-concepts/numbers/approx"
+	"github.com/rehan/go-interview-prep/pkg/mock"
 )
 
 // Basic unit test
@@ -62,7 +68,7 @@ func TestCircleArea(t *testing.T) {
 
 			// If we don't expect an error, check the result
 			if !tc.expectError {
-				if math.Abs(got-tc.expected) > 1e-10 {
+				if !approx.Equal(got, tc.expected, 1e-10) {
 					t.Errorf("CircleArea(%f) = %f; want %f", tc.radius, got, tc.expected)
 				}
 			}
@@ -102,6 +108,8 @@ func TestWordCount(t *testing.T) {
 		{"oneword", 1},
 		{"   spaced   words   ", 2},
 		{"1 2 3 4 5", 5},
+		{" ", 0},
+		{"tabs\tand\nnewlines", 3},
 	}
 
 	for i, tc := range tests {
@@ -176,6 +184,17 @@ func TestValidateUser(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name: "malformed email",
+			user: User{
+				ID:        6,
+				FirstName: "John",
+				LastName:  "Doe",
+				Email:     "john.example.com",
+				Age:       30,
+			},
+			expectError: true,
+		},
 	}
 
 	for _, tc := range tests {
@@ -233,31 +252,11 @@ func ExampleWordCount() {
 	// 3
 }
 
-// Test with mocking (simple example)
-type mockEmailSender struct {
-	sentEmails []struct {
-		email   string
-		subject string
-		body    string
-	}
-	shouldFail bool
-}
-
-func (m *mockEmailSender) Send(email, subject, body string) error {
-	if m.shouldFail {
-		return fmt.Errorf("failed to send email")
-	}
-	m.sentEmails = append(m.sentEmails, struct {
-		email   string
-		subject string
-		body    string
-	}{email, subject, body})
-	return nil
-}
-
+// Test with mocking. EmailSenderMock is generated from the EmailSender
+// interface by go generate; see emailsender_mock_test.go.
 func TestNotifyUser(t *testing.T) {
 	// Create a mock email sender
-	mockSender := &mockEmailSender{}
+	mockSender := &EmailSenderMock{}
 
 	// Create a test user
 	user := User{
@@ -276,28 +275,19 @@ func TestNotifyUser(t *testing.T) {
 		t.Errorf("NotifyUser() returned error: %v", err)
 	}
 
-	// Verify an email was sent
-	if len(mockSender.sentEmails) != 1 {
-		t.Errorf("Expected 1 email to be sent, got %d", len(mockSender.sentEmails))
-	}
-
-	// Verify the email content
-	if len(mockSender.sentEmails) > 0 {
-		sent := mockSender.sentEmails[0]
-		if sent.email != user.Email {
-			t.Errorf("Wrong recipient email: got %s, want %s", sent.email, user.Email)
-		}
-		if sent.subject != "Account Created" {
-			t.Errorf("Wrong subject: got %s, want %s", sent.subject, "Account Created")
-		}
-		expectedBody := fmt.Sprintf("Hello %s, your account has been created.", user.FirstName)
-		if sent.body != expectedBody {
-			t.Errorf("Wrong body: got %s, want %s", sent.body, expectedBody)
-		}
-	}
+	// Verify one email was sent, to the user, with exactly this body. Plain
+	// values must equal the recorded argument; a Matcher such as
+	// mock.Contains would check it its own way.
+	expectedBody := fmt.Sprintf("Hello %s, your account has been created.", user.FirstName)
+	mock.AssertCalledTimes(t, mockSender.SendCalls(), 1,
+		user.Email, "Account Created", expectedBody)
 
 	// Test failure case
-	failingSender := &mockEmailSender{shouldFail: true}
+	failingSender := &EmailSenderMock{
+		SendFunc: func(email, subject, body string) error {
+			return fmt.Errorf("failed to send email")
+		},
+	}
 	err = NotifyUser(user, failingSender)
 	if err == nil {
 		t.Error("NotifyUser() with failing sender should return error")
@@ -323,20 +313,63 @@ func TestParallel(t *testing.T) {
 	})
 }
 
-// TestMain can be used for setup and teardown at the package level
+// testDB is the database TestMain opens for the package's tests, migrated
+// and seeded with testdata/users.json
+var testDB *UserDB
+
+// TestMain sets up what every test in the package shares, runs them, and
+// tears it down. os.Exit skips deferred calls, so the work is in
+// runTests, whose defers run before TestMain exits with its code.
 func TestMain(m *testing.M) {
-	// Setup code here
-	fmt.Println("Starting tests...")
+	os.Exit(runTests(m))
+}
 
-	// Run the tests
-	exitCode := m.Run()
+func runTests(m *testing.M) int {
+	dir, err := os.MkdirTemp("", "basic-concepts-test-")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "setup:", err)
+		return 1
+	}
+	defer os.RemoveAll(dir)
 
-	// Teardown code here
-	fmt.Println("Tests complete.")
+	testDB, err = setupTestDB(filepath.Join(dir, "users.json"), "testdata/users.json")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "setup:", err)
+		return 1
+	}
+	defer testDB.Close()
+
+	return m.Run()
+}
 
-	// Exit with the test exit code
-	// In a real test, you'd use os.Exit(exitCode), but we'll omit it here
-	// so it doesn't actually exit when running the tests
-	// os.Exit(exitCode)
-	_ = exitCode
+// setupTestDB opens a database at path, migrates it and inserts the users
+// in the fixtures file
+func setupTestDB(path, fixtures string) (_ *UserDB, err error) {
+	data, err := os.ReadFile(fixtures)
+	if err != nil {
+		return nil, err
+	}
+	var users []User
+	if err := json.Unmarshal(data, &users); err != nil {
+		return nil, fmt.Errorf("%s: %w", fixtures, err)
+	}
+
+	db, err := OpenUserDB(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			db.Close()
+		}
+	}()
+	if _, err := db.Migrate(UserMigrations); err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		if _, err := db.Insert(u); err != nil {
+			return nil, fmt.Errorf("seeding %s: %w", u.Email, err)
+		}
+	}
+	return db, nil
 }
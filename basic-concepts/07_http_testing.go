@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"sync"
 )
 
 // Response represents a simple API response
@@ -17,7 +18,13 @@ type Response struct {
 
 // UserHandler handles user operations
 type UserHandler struct {
-	users map[string]User
+	mu     sync.Mutex // guards users and lastID
+	users  map[string]User
+	lastID int // the highest ID Register has handed out
+
+	// accounts holds the logins of users created by Register
+	// (09_user_accounts.go)
+	accounts *AccountStore
 }
 
 // NewUserHandler creates a new user handler
@@ -32,6 +39,7 @@ func NewUserHandler() *UserHandler {
 				Age:       30,
 			},
 		},
+		accounts: NewAccountStore(),
 	}
 }
 
@@ -54,7 +62,9 @@ func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Lookup user
+	h.mu.Lock()
 	user, exists := h.users[userID]
+	h.mu.Unlock()
 	if !exists {
 		respondWithJSON(w, http.StatusNotFound, Response{
 			Status: "error",
@@ -101,7 +111,9 @@ func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 
 	// Store user (in a real app, we'd generate a unique ID)
 	userID := fmt.Sprintf("%d", user.ID)
+	h.mu.Lock()
 	h.users[userID] = user
+	h.mu.Unlock()
 
 	// Return success
 	respondWithJSON(w, http.StatusCreated, Response{
@@ -131,6 +143,8 @@ func SetupRoutes() *http.ServeMux {
 
 	mux.HandleFunc("/user", userHandler.GetUser)
 	mux.HandleFunc("/user/create", userHandler.CreateUser)
+	mux.HandleFunc("/user/register", userHandler.Register)
+	mux.HandleFunc("/user/login", userHandler.Login)
 
 	return mux
 }
@@ -148,6 +162,8 @@ func demonstrateHTTPServer() {
 	fmt.Println("Example API endpoints:")
 	fmt.Println("  GET /user?id=1 - Get user with ID 1")
 	fmt.Println("  POST /user/create - Create a new user with JSON payload")
+	fmt.Println("  POST /user/register - Create a user with a password (JSON user fields plus Password)")
+	fmt.Println("  POST /user/login - Log in with Email and Password; 5 wrong passwords lock the account for 15 minutes")
 }
 
 /*
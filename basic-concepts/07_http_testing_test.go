@@ -238,8 +238,8 @@ func TestCreateUser_InvalidData(t *testing.T) {
 		t.Errorf("Expected status 'error', got '%s'", response.Status)
 	}
 
-	// Check error message contains "first name"
-	if response.Error == "" || response.Error != "first name cannot be empty" {
+	// Check error message comes from the validator's required rule
+	if response.Error != "FirstName is required" {
 		t.Errorf("Expected error about empty first name, got '%s'", response.Error)
 	}
 }
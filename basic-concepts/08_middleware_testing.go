@@ -3,9 +3,12 @@ package main
 import (
 	"fmt"
 	"log"
+	"net"
 	"net/http"
-	"sync"
+	"strconv"
 	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/ratelimit"
 )
 
 // Middleware is a function that wraps an http.Handler with additional functionality
@@ -47,41 +50,35 @@ func AuthMiddleware(next http.Handler) http.Handler {
 	})
 }
 
-// RateLimitMiddleware limits the number of requests per client IP
+// RateLimitMiddleware limits each client IP to requestsPerMinute requests
+// a minute, allowing all of them in a burst. It panics unless
+// requestsPerMinute is positive: a rate of zero would never refill.
 func RateLimitMiddleware(requestsPerMinute int) Middleware {
-	// In a real implementation, you'd use a more sophisticated tracking system
-	// For this example, we'll use a simple map to track requests
-	requestCounts := make(map[string]int)
-	lastResetTime := time.Now()
-	var mu = &sync.RWMutex{}
+	if requestsPerMinute <= 0 {
+		panic(fmt.Sprintf("RateLimitMiddleware: requestsPerMinute must be positive, got %d", requestsPerMinute))
+	}
+	return LimitMiddleware(ratelimit.NewLimiter(ratelimit.Config{
+		Rate:  ratelimit.Per(requestsPerMinute, time.Minute),
+		Burst: requestsPerMinute,
+	}))
+}
 
+// LimitMiddleware rate limits requests per client IP with limiter, which
+// sets the burst, the refill rate and how many clients are tracked.
+// Every response carries X-RateLimit-Limit, X-RateLimit-Remaining and
+// X-RateLimit-Reset (seconds until the bucket is full); rejected ones
+// also get Retry-After.
+func LimitMiddleware(limiter *ratelimit.Limiter) Middleware {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Get client IP
-			clientIP := r.RemoteAddr
-
-			mu.RLock()
-			// Check if we need to reset the counters
-			if time.Since(lastResetTime) > time.Minute {
-				mu.RUnlock()
-				mu.Lock()
-				// Reset counters if more than a minute has passed
-				if time.Since(lastResetTime) > time.Minute {
-					requestCounts = make(map[string]int)
-					lastResetTime = time.Now()
-				}
-				mu.Unlock()
-			} else {
-				mu.RUnlock()
-			}
-
-			// Check if this client has exceeded the rate limit
-			mu.Lock()
-			requestCounts[clientIP]++
-			count := requestCounts[clientIP]
-			mu.Unlock()
-
-			if count > requestsPerMinute {
+			res := limiter.Allow(clientIP(r))
+
+			h := w.Header()
+			h.Set("X-RateLimit-Limit", strconv.Itoa(res.Limit))
+			h.Set("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			h.Set("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+			if !res.Allowed {
+				h.Set("Retry-After", strconv.Itoa(ceilSeconds(res.RetryAfter)))
 				http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
 				return
 			}
@@ -92,6 +89,29 @@ func RateLimitMiddleware(requestsPerMinute int) Middleware {
 	}
 }
 
+// clientIP returns the IP of the client without its port, so every
+// connection from one host shares a bucket. Behind a proxy this is the
+// proxy's IP; trusting X-Forwarded-For instead is only safe when the proxy
+// sets it.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// ceilSeconds rounds d up to whole seconds, as HTTP headers count them.
+// It divides before rounding, so a limiter that never refills, whose wait
+// is math.MaxInt64, does not overflow into a negative header.
+func ceilSeconds(d time.Duration) int {
+	secs := d / time.Second
+	if d%time.Second > 0 {
+		secs++
+	}
+	return int(secs)
+}
+
 // RecoveryMiddleware recovers from panics and responds with a 500 Internal Server Error
 func RecoveryMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
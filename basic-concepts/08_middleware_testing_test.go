@@ -2,11 +2,21 @@ package main
 
 import (
 	"bytes"
+	"fmt"
+	"io"
 	"log"
+	"math"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"slices"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/clock"
+	"github.com/rehan/go-interview-prep/pkg/ratelimit"
+	"github.com/rehan/go-interview-prep/pkg/testutil/middlewaretest"
 )
 
 // TestLoggingMiddleware tests that the logging middleware logs requests
@@ -15,31 +25,14 @@ func TestLoggingMiddleware(t *testing.T) {
 	var buf bytes.Buffer
 	log.SetOutput(&buf)
 
-	// Create a simple handler
-	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
-	})
-
-	// Wrap the handler with the logging middleware
-	wrapped := LoggingMiddleware(handler)
-
-	// Create a test request
+	next := &middlewaretest.RecordingHandler{Body: "OK"}
 	req := httptest.NewRequest("GET", "/test", nil)
 	req.RemoteAddr = "127.0.0.1:1234"
+	rr := middlewaretest.Serve(LoggingMiddleware(next), req)
 
-	// Create a response recorder
-	rr := httptest.NewRecorder()
-
-	// Process the request
-	wrapped.ServeHTTP(rr, req)
-
-	// Check that the handler was called (status is OK)
-	if rr.Code != http.StatusOK {
-		t.Errorf("Expected status code %d, got %d", http.StatusOK, rr.Code)
-	}
-
-	// Check that something was logged
+	// The request reached the handler, and something was logged
+	middlewaretest.AssertStatus(t, rr, http.StatusOK)
+	next.AssertCalls(t, 1)
 	logOutput := buf.String()
 	if !strings.Contains(logOutput, "GET /test 127.0.0.1:1234") {
 		t.Errorf("Expected log to contain 'GET /test 127.0.0.1:1234', got: %s", logOutput)
@@ -48,62 +41,31 @@ func TestLoggingMiddleware(t *testing.T) {
 
 // TestAuthMiddleware_ValidKey tests that requests with valid API keys are processed
 func TestAuthMiddleware_ValidKey(t *testing.T) {
-	// Create a simple handler that returns a success message
-	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("Authenticated"))
-	})
-
-	// Wrap the handler with the auth middleware
-	wrapped := AuthMiddleware(handler)
-
-	// Create a test request with a valid API key
+	next := &middlewaretest.RecordingHandler{Body: "Authenticated"}
 	req := httptest.NewRequest("GET", "/secured", nil)
 	req.Header.Set("X-API-Key", "valid-api-key")
+	rr := middlewaretest.Serve(AuthMiddleware(next), req)
 
-	// Create a response recorder
-	rr := httptest.NewRecorder()
-
-	// Process the request
-	wrapped.ServeHTTP(rr, req)
-
-	// Check status code
-	if rr.Code != http.StatusOK {
-		t.Errorf("Expected status code %d, got %d", http.StatusOK, rr.Code)
-	}
-
-	// Check response body
+	middlewaretest.AssertStatus(t, rr, http.StatusOK)
 	if rr.Body.String() != "Authenticated" {
 		t.Errorf("Expected body 'Authenticated', got '%s'", rr.Body.String())
 	}
+	// The handler sees the key the middleware checked
+	if got := next.LastRequest(t).Header.Get("X-API-Key"); got != "valid-api-key" {
+		t.Errorf("handler got X-API-Key %q; want valid-api-key", got)
+	}
 }
 
 // TestAuthMiddleware_InvalidKey tests that requests with invalid API keys are rejected
 func TestAuthMiddleware_InvalidKey(t *testing.T) {
-	// Create a simple handler (should not be called)
-	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		t.Error("Handler was called with invalid API key")
-	})
-
-	// Wrap the handler with the auth middleware
-	wrapped := AuthMiddleware(handler)
-
-	// Create a test request with an invalid API key
+	next := &middlewaretest.RecordingHandler{}
 	req := httptest.NewRequest("GET", "/secured", nil)
 	req.Header.Set("X-API-Key", "invalid-key")
+	rr := middlewaretest.Serve(AuthMiddleware(next), req)
 
-	// Create a response recorder
-	rr := httptest.NewRecorder()
-
-	// Process the request
-	wrapped.ServeHTTP(rr, req)
-
-	// Check status code (should be Unauthorized)
-	if rr.Code != http.StatusUnauthorized {
-		t.Errorf("Expected status code %d, got %d", http.StatusUnauthorized, rr.Code)
-	}
-
-	// Check error message in response
+	// Rejected before the handler
+	middlewaretest.AssertStatus(t, rr, http.StatusUnauthorized)
+	next.AssertCalls(t, 0)
 	if !strings.Contains(rr.Body.String(), "Invalid API key") {
 		t.Errorf("Expected body to contain 'Invalid API key', got '%s'", rr.Body.String())
 	}
@@ -111,111 +73,148 @@ func TestAuthMiddleware_InvalidKey(t *testing.T) {
 
 // TestRateLimitMiddleware tests that the rate limiting middleware restricts requests
 func TestRateLimitMiddleware(t *testing.T) {
-	// Create a simple handler
-	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
-	})
-
-	// Wrap the handler with rate limiting middleware (2 requests per minute)
-	wrapped := RateLimitMiddleware(2)(handler)
-
-	// Create a test request
+	// 2 requests per minute, all from the same IP
+	next := &middlewaretest.RecordingHandler{Body: "OK"}
+	wrapped := RateLimitMiddleware(2)(next)
 	req := httptest.NewRequest("GET", "/rate-limited", nil)
-	req.RemoteAddr = "127.0.0.1:1234" // Same IP for all requests
+	req.RemoteAddr = "127.0.0.1:1234"
 
-	// First request should succeed
-	rr1 := httptest.NewRecorder()
-	wrapped.ServeHTTP(rr1, req)
-	if rr1.Code != http.StatusOK {
-		t.Errorf("Expected first request to succeed with status %d, got %d", http.StatusOK, rr1.Code)
-	}
+	middlewaretest.AssertStatus(t, middlewaretest.Serve(wrapped, req), http.StatusOK)
+	middlewaretest.AssertStatus(t, middlewaretest.Serve(wrapped, req), http.StatusOK)
 
-	// Second request should succeed
-	rr2 := httptest.NewRecorder()
-	wrapped.ServeHTTP(rr2, req)
-	if rr2.Code != http.StatusOK {
-		t.Errorf("Expected second request to succeed with status %d, got %d", http.StatusOK, rr2.Code)
+	// The third is rate limited, without reaching the handler
+	rr3 := middlewaretest.Serve(wrapped, req)
+	middlewaretest.AssertStatus(t, rr3, http.StatusTooManyRequests)
+	next.AssertCalls(t, 2)
+	if !strings.Contains(rr3.Body.String(), "Rate limit exceeded") {
+		t.Errorf("Expected body to contain 'Rate limit exceeded', got '%s'", rr3.Body.String())
 	}
+}
 
-	// Third request should be rate limited
-	rr3 := httptest.NewRecorder()
-	wrapped.ServeHTTP(rr3, req)
-	if rr3.Code != http.StatusTooManyRequests {
-		t.Errorf("Expected third request to be rate limited with status %d, got %d",
-			http.StatusTooManyRequests, rr3.Code)
+// TestRateLimitMiddleware_RejectsNonPositiveRate tests that a rate that
+// would never refill is refused when the middleware is built
+func TestRateLimitMiddleware_RejectsNonPositiveRate(t *testing.T) {
+	for _, rate := range []int{0, -1} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RateLimitMiddleware(%d) did not panic", rate)
+				}
+			}()
+			RateLimitMiddleware(rate)
+		}()
 	}
+}
 
-	// Verify rate limit error message
-	if !strings.Contains(rr3.Body.String(), "Rate limit exceeded") {
-		t.Errorf("Expected body to contain 'Rate limit exceeded', got '%s'", rr3.Body.String())
+// TestCeilSeconds tests rounding up, including the longest wait a limiter
+// reports, which must not overflow
+func TestCeilSeconds(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want int
+	}{
+		{0, 0},
+		{time.Nanosecond, 1},
+		{time.Second, 1},
+		{time.Second + time.Nanosecond, 2},
+		{math.MaxInt64, 9223372037},
+	}
+	for _, tc := range tests {
+		if got := ceilSeconds(tc.d); got != tc.want {
+			t.Errorf("ceilSeconds(%v) = %d; want %d", tc.d, got, tc.want)
+		}
 	}
+
+	// A limiter with no refill reports that wait once its burst is spent
+	limiter := ratelimit.NewLimiter(ratelimit.Config{Rate: 0, Burst: 1})
+	wrapped := LimitMiddleware(limiter)(&middlewaretest.RecordingHandler{})
+	req := httptest.NewRequest("GET", "/limited", nil)
+	middlewaretest.Serve(wrapped, req)
+	rr := middlewaretest.Serve(wrapped, req)
+	middlewaretest.AssertStatus(t, rr, http.StatusTooManyRequests)
+	middlewaretest.AssertHeaders(t, rr.Header(), map[string]string{"Retry-After": "9223372037"})
 }
 
-// TestRecoveryMiddleware tests that the recovery middleware catches panics
-func TestRecoveryMiddleware(t *testing.T) {
-	// Create a handler that panics
-	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		panic("Test panic")
+// TestLimitMiddleware tests the rate limit headers, per-IP buckets and
+// refilling, with a fake clock so the test does not have to wait
+func TestLimitMiddleware(t *testing.T) {
+	c := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	limiter := ratelimit.NewLimiter(ratelimit.Config{
+		Rate:  ratelimit.Per(1, 10*time.Second), // one request every 10 seconds
+		Burst: 2,
+		Clock: c,
 	})
+	wrapped := LimitMiddleware(limiter)(&middlewaretest.RecordingHandler{})
 
-	// Wrap the handler with recovery middleware
-	wrapped := RecoveryMiddleware(handler)
+	send := func(remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/limited", nil)
+		req.RemoteAddr = remoteAddr
+		return middlewaretest.Serve(wrapped, req)
+	}
 
-	// Create a test request
-	req := httptest.NewRequest("GET", "/panic", nil)
+	tests := []struct {
+		name       string
+		advance    time.Duration
+		remoteAddr string
+		wantStatus int
+		wantHeader map[string]string
+	}{
+		{"first", 0, "10.0.0.1:1111", http.StatusOK,
+			map[string]string{"X-RateLimit-Limit": "2", "X-RateLimit-Remaining": "1", "X-RateLimit-Reset": "10"}},
+		// Another port on the same host shares its bucket
+		{"second, new port", 0, "10.0.0.1:2222", http.StatusOK,
+			map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "20"}},
+		{"over the limit", 0, "10.0.0.1:1111", http.StatusTooManyRequests,
+			map[string]string{"X-RateLimit-Remaining": "0", "Retry-After": "10"}},
+		{"other client", 0, "10.0.0.2:1111", http.StatusOK,
+			map[string]string{"X-RateLimit-Remaining": "1"}},
+		{"partly refilled", 4 * time.Second, "10.0.0.1:1111", http.StatusTooManyRequests,
+			map[string]string{"Retry-After": "6"}},
+		{"refilled", 6 * time.Second, "10.0.0.1:1111", http.StatusOK,
+			map[string]string{"X-RateLimit-Remaining": "0"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c.Advance(tc.advance)
+			rr := send(tc.remoteAddr)
+			middlewaretest.AssertStatus(t, rr, tc.wantStatus)
+			middlewaretest.AssertHeaders(t, rr.Header(), tc.wantHeader)
+			if tc.wantStatus == http.StatusOK {
+				middlewaretest.AssertNoHeader(t, rr.Header(), "Retry-After")
+			}
+		})
+	}
+}
 
-	// Create a response recorder
-	rr := httptest.NewRecorder()
+// TestRecoveryMiddleware tests that the recovery middleware catches panics
+func TestRecoveryMiddleware(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
 
 	// This should not panic due to the recovery middleware
-	wrapped.ServeHTTP(rr, req)
+	rr := middlewaretest.Serve(RecoveryMiddleware(middlewaretest.PanicHandler("Test panic")), httptest.NewRequest("GET", "/panic", nil))
 
-	// Check status code (should be Internal Server Error)
-	if rr.Code != http.StatusInternalServerError {
-		t.Errorf("Expected status code %d, got %d", http.StatusInternalServerError, rr.Code)
-	}
-
-	// Check error message
+	middlewaretest.AssertStatus(t, rr, http.StatusInternalServerError)
 	if !strings.Contains(rr.Body.String(), "Internal Server Error") {
 		t.Errorf("Expected body to contain 'Internal Server Error', got '%s'", rr.Body.String())
 	}
 }
 
+// corsHeaders are the headers CORSMiddleware adds to every response
+var corsHeaders = map[string]string{
+	"Access-Control-Allow-Origin":  "*",
+	"Access-Control-Allow-Methods": "GET, POST, PUT, DELETE, OPTIONS",
+	"Access-Control-Allow-Headers": "Content-Type, Authorization",
+}
+
 // TestCORSMiddleware tests that CORS headers are added to responses
 func TestCORSMiddleware(t *testing.T) {
-	// Create a simple handler
-	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
-	})
-
-	// Wrap the handler with CORS middleware
-	wrapped := CORSMiddleware(handler)
-
-	// Create a test request
-	req := httptest.NewRequest("GET", "/cors-test", nil)
-
-	// Create a response recorder
-	rr := httptest.NewRecorder()
+	next := &middlewaretest.RecordingHandler{Body: "OK"}
+	rr := middlewaretest.Serve(CORSMiddleware(next), httptest.NewRequest("GET", "/cors-test", nil))
 
-	// Process the request
-	wrapped.ServeHTTP(rr, req)
-
-	// Check CORS headers
-	expectedHeaders := map[string]string{
-		"Access-Control-Allow-Origin":  "*",
-		"Access-Control-Allow-Methods": "GET, POST, PUT, DELETE, OPTIONS",
-		"Access-Control-Allow-Headers": "Content-Type, Authorization",
-	}
-
-	for header, expectedValue := range expectedHeaders {
-		if value := rr.Header().Get(header); value != expectedValue {
-			t.Errorf("Expected header %s to be '%s', got '%s'", header, expectedValue, value)
-		}
-	}
-
-	// Check that the handler was still called (body should be "OK")
+	middlewaretest.AssertHeaders(t, rr.Header(), corsHeaders)
+	// The handler was still called
+	next.AssertCalls(t, 1)
 	if rr.Body.String() != "OK" {
 		t.Errorf("Expected body 'OK', got '%s'", rr.Body.String())
 	}
@@ -223,104 +222,42 @@ func TestCORSMiddleware(t *testing.T) {
 
 // TestCORSMiddleware_Options tests that OPTIONS requests are handled correctly
 func TestCORSMiddleware_Options(t *testing.T) {
-	// Create a handler that should not be called for OPTIONS requests
-	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		t.Error("Handler was called for OPTIONS request")
-	})
-
-	// Wrap the handler with CORS middleware
-	wrapped := CORSMiddleware(handler)
-
-	// Create an OPTIONS request
-	req := httptest.NewRequest("OPTIONS", "/cors-test", nil)
-
-	// Create a response recorder
-	rr := httptest.NewRecorder()
-
-	// Process the request
-	wrapped.ServeHTTP(rr, req)
+	next := &middlewaretest.RecordingHandler{Body: "OK"}
+	rr := middlewaretest.Serve(CORSMiddleware(next), httptest.NewRequest("OPTIONS", "/cors-test", nil))
 
-	// Check status code (should be OK)
-	if rr.Code != http.StatusOK {
-		t.Errorf("Expected status code %d, got %d", http.StatusOK, rr.Code)
-	}
-
-	// Check that the response body is empty (handler not called)
+	// A preflight is answered without calling the handler
+	middlewaretest.AssertStatus(t, rr, http.StatusOK)
+	next.AssertCalls(t, 0)
 	if rr.Body.String() != "" {
 		t.Errorf("Expected empty body, got '%s'", rr.Body.String())
 	}
-
-	// Check CORS headers
-	expectedHeaders := map[string]string{
-		"Access-Control-Allow-Origin":  "*",
-		"Access-Control-Allow-Methods": "GET, POST, PUT, DELETE, OPTIONS",
-		"Access-Control-Allow-Headers": "Content-Type, Authorization",
-	}
-
-	for header, expectedValue := range expectedHeaders {
-		if value := rr.Header().Get(header); value != expectedValue {
-			t.Errorf("Expected header %s to be '%s', got '%s'", header, expectedValue, value)
-		}
-	}
+	middlewaretest.AssertHeaders(t, rr.Header(), corsHeaders)
 }
 
-// TestChain tests that middleware chaining works correctly
+// TestChain tests that middleware chaining works correctly: the first
+// middleware is the outermost, so it runs first and sees the response the
+// others made
 func TestChain(t *testing.T) {
-	// Create test values to track middleware execution
-	var (
-		middleware1Called bool
-		middleware2Called bool
-		handlerCalled     bool
-	)
-
-	// Create two simple middleware functions
-	middleware1 := func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			middleware1Called = true
-			next.ServeHTTP(w, r)
-		})
-	}
-
-	middleware2 := func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			middleware2Called = true
-			next.ServeHTTP(w, r)
-		})
+	var order []string
+	trace := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name)
+				cw := middlewaretest.NewStatusCapturingWriter(w)
+				next.ServeHTTP(cw, r)
+				order = append(order, fmt.Sprintf("%s saw %d", name, cw.Status))
+			})
+		}
 	}
+	next := &middlewaretest.RecordingHandler{Status: http.StatusAccepted}
 
-	// Create a simple handler
-	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		handlerCalled = true
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
-	})
-
-	// Chain the middleware and handler
-	chained := Chain(handler, middleware1, middleware2)
-
-	// Create a test request
-	req := httptest.NewRequest("GET", "/chain-test", nil)
-
-	// Create a response recorder
-	rr := httptest.NewRecorder()
-
-	// Process the request
-	chained.ServeHTTP(rr, req)
-
-	// Check that all middleware and the handler were called
-	if !middleware1Called {
-		t.Error("Middleware 1 was not called")
-	}
-	if !middleware2Called {
-		t.Error("Middleware 2 was not called")
-	}
-	if !handlerCalled {
-		t.Error("Handler was not called")
-	}
+	rr := middlewaretest.Serve(Chain(next, trace("middleware 1"), trace("middleware 2")), httptest.NewRequest("GET", "/chain-test", nil))
 
-	// Check response status code
-	if rr.Code != http.StatusOK {
-		t.Errorf("Expected status code %d, got %d", http.StatusOK, rr.Code)
+	middlewaretest.AssertStatus(t, rr, http.StatusAccepted)
+	next.AssertCalls(t, 1)
+	want := []string{"middleware 2", "middleware 1", "middleware 1 saw 202", "middleware 2 saw 202"}
+	if !slices.Equal(order, want) {
+		t.Errorf("order = %q; want %q", order, want)
 	}
 }
 
@@ -0,0 +1,367 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Password hashing and accounts behind UserHandler's /user/register and
+// /user/login. Passwords are stored as salted PBKDF2-HMAC-SHA256 hashes
+// (RFC 8018), implemented here on the standard library alone; a real
+// service would reach for golang.org/x/crypto (or crypto/pbkdf2 from Go
+// 1.24) and preferably a memory-hard function such as scrypt or argon2.
+
+// pbkdf2SHA256 derives a keyLen-byte key from password and salt with iter
+// rounds of HMAC-SHA256.
+//
+// Each block T_i of the key is U_1 ^ U_2 ^ ... ^ U_iter, where U_1 is
+// HMAC(password, salt || i) and U_j is HMAC(password, U_{j-1}). The
+// rounds are what make each guess expensive for an attacker.
+func pbkdf2SHA256(password, salt []byte, iter, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	key := make([]byte, 0, keyLen+sha256.Size)
+	u := make([]byte, sha256.Size)
+	for block := uint32(1); len(key) < keyLen; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write([]byte{byte(block >> 24), byte(block >> 16), byte(block >> 8), byte(block)})
+		u = prf.Sum(u[:0])
+		t := append([]byte(nil), u...)
+		for range iter - 1 {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for i := range t {
+				t[i] ^= u[i]
+			}
+		}
+		key = append(key, t...)
+	}
+	return key[:keyLen]
+}
+
+// hashScheme starts every encoded hash, so the scheme can change later
+// without breaking the hashes already stored
+const hashScheme = "pbkdf2-sha256"
+
+// DefaultIterations is OWASP's 2023 recommendation for PBKDF2-HMAC-SHA256
+const DefaultIterations = 600_000
+
+// HashPassword returns password hashed with a random 16-byte salt, encoded
+// as "pbkdf2-sha256$<iterations>$<salt>$<hash>". Everything needed to
+// check a password is in the string, so raising the iteration count later
+// leaves older hashes verifiable.
+func HashPassword(password string, iterations int) string {
+	salt := make([]byte, 16)
+	rand.Read(salt)
+	sum := pbkdf2SHA256([]byte(password), salt, iterations, sha256.Size)
+	b64 := base64.RawStdEncoding
+	return fmt.Sprintf("%s$%d$%s$%s", hashScheme, iterations, b64.EncodeToString(salt), b64.EncodeToString(sum))
+}
+
+// parsedHash is an encoded hash taken apart
+type parsedHash struct {
+	iterations int
+	salt, sum  []byte
+}
+
+func parseHash(encoded string) (parsedHash, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 4 || parts[0] != hashScheme {
+		return parsedHash{}, errors.New("not a " + hashScheme + " hash")
+	}
+	var h parsedHash
+	var err error
+	if h.iterations, err = strconv.Atoi(parts[1]); err != nil || h.iterations < 1 {
+		return parsedHash{}, fmt.Errorf("bad iteration count %q", parts[1])
+	}
+	// Strict rejects encodings with stray bits set, so each hash has
+	// exactly one spelling
+	b64 := base64.RawStdEncoding.Strict()
+	if h.salt, err = b64.DecodeString(parts[2]); err != nil {
+		return parsedHash{}, fmt.Errorf("bad salt: %w", err)
+	}
+	if h.sum, err = b64.DecodeString(parts[3]); err != nil || len(h.sum) != sha256.Size {
+		return parsedHash{}, errors.New("bad hash")
+	}
+	return h, nil
+}
+
+// VerifyPassword reports whether password matches the encoded hash. The
+// comparison takes the same time however many bytes match, so response
+// times do not lead an attacker towards the hash a byte at a time.
+func VerifyPassword(encoded, password string) (bool, error) {
+	h, err := parseHash(encoded)
+	if err != nil {
+		return false, err
+	}
+	sum := pbkdf2SHA256([]byte(password), h.salt, h.iterations, sha256.Size)
+	return subtle.ConstantTimeCompare(sum, h.sum) == 1, nil
+}
+
+// Errors returned by AccountStore
+var (
+	ErrEmailTaken         = errors.New("email is already registered")
+	ErrWeakPassword       = errors.New("password must be at least 8 characters")
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrAccountLocked      = errors.New("account locked after too many failed logins")
+)
+
+// Account is a user's login
+type Account struct {
+	UserID       int
+	Email        string
+	PasswordHash string
+
+	// FailedAttempts counts wrong passwords since the last login or
+	// lockout; reaching the store's MaxAttempts locks the account until
+	// LockedUntil
+	FailedAttempts int
+	LockedUntil    time.Time
+}
+
+// AccountStore registers accounts and checks logins, locking an account
+// for LockoutDuration after MaxAttempts wrong passwords in a row
+type AccountStore struct {
+	Iterations      int
+	MaxAttempts     int
+	LockoutDuration time.Duration
+
+	now func() time.Time // time.Now outside tests
+
+	mu       sync.Mutex
+	accounts map[string]*Account // by lower-cased email
+
+	// dummyHash is checked for unknown emails so that they take as long to
+	// reject as wrong passwords, and timing does not reveal who has an
+	// account. It is made on first use, with the Iterations then set.
+	dummyOnce sync.Once
+	dummyHash string
+}
+
+// NewAccountStore returns an empty store with DefaultIterations, a
+// lockout after 5 failures and a 15 minute lockout
+func NewAccountStore() *AccountStore {
+	return &AccountStore{
+		Iterations:      DefaultIterations,
+		MaxAttempts:     5,
+		LockoutDuration: 15 * time.Minute,
+		now:             time.Now,
+		accounts:        make(map[string]*Account),
+	}
+}
+
+// Register creates an account for userID
+func (s *AccountStore) Register(userID int, email, password string) error {
+	if len(password) < 8 {
+		return ErrWeakPassword
+	}
+	// Hashing is slow on purpose, so it happens outside the lock
+	hash := HashPassword(password, s.Iterations)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := strings.ToLower(email)
+	if _, ok := s.accounts[key]; ok {
+		return ErrEmailTaken
+	}
+	s.accounts[key] = &Account{UserID: userID, Email: email, PasswordHash: hash}
+	return nil
+}
+
+// Authenticate returns the account for email if password is right for it.
+// A locked account fails with an error wrapping ErrAccountLocked, even
+// with the right password, until its lock runs out.
+func (s *AccountStore) Authenticate(email, password string) (Account, error) {
+	key := strings.ToLower(email)
+	s.mu.Lock()
+	a, ok := s.accounts[key]
+	var hash string
+	if ok {
+		if until := a.LockedUntil; s.now().Before(until) {
+			s.mu.Unlock()
+			return Account{}, fmt.Errorf("%w until %s", ErrAccountLocked, until.Format(time.RFC3339))
+		}
+		hash = a.PasswordHash
+	}
+	s.mu.Unlock()
+	if !ok {
+		s.dummyOnce.Do(func() { s.dummyHash = HashPassword("", s.Iterations) })
+		hash = s.dummyHash
+	}
+
+	match, err := VerifyPassword(hash, password)
+	if err != nil {
+		return Account{}, err
+	}
+	// A hash made with fewer iterations than the store now uses is
+	// upgraded while the password is at hand
+	var upgraded string
+	if h, _ := parseHash(hash); ok && match && h.iterations < s.Iterations {
+		upgraded = HashPassword(password, s.Iterations)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !ok {
+		return Account{}, ErrInvalidCredentials
+	}
+	if !match {
+		a.FailedAttempts++
+		if a.FailedAttempts >= s.MaxAttempts {
+			a.FailedAttempts = 0
+			a.LockedUntil = s.now().Add(s.LockoutDuration)
+		}
+		return Account{}, ErrInvalidCredentials
+	}
+	a.FailedAttempts = 0
+	if upgraded != "" && a.PasswordHash == hash {
+		a.PasswordHash = upgraded
+	}
+	return *a, nil
+}
+
+// RegisterRequest is the body of POST /user/register: the user's fields
+// and a password, which is hashed and never stored or returned as sent
+type RegisterRequest struct {
+	User
+	Password string
+}
+
+// LoginRequest is the body of POST /user/login
+type LoginRequest struct {
+	Email    string
+	Password string
+}
+
+// Register handles POST requests creating a user with a login. Unlike
+// CreateUser it assigns the ID itself.
+func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req RegisterRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, Response{Status: "error", Error: "invalid request payload"})
+		return
+	}
+	if err := ValidateUser(req.User); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, Response{Status: "error", Error: err.Error()})
+		return
+	}
+
+	// Reserve an ID past any user's, including ones CreateUser added
+	h.mu.Lock()
+	for _, u := range h.users {
+		h.lastID = max(h.lastID, u.ID)
+	}
+	h.lastID++
+	user := req.User
+	user.ID = h.lastID
+	h.mu.Unlock()
+
+	switch err := h.accounts.Register(user.ID, user.Email, req.Password); {
+	case errors.Is(err, ErrWeakPassword):
+		respondWithJSON(w, http.StatusBadRequest, Response{Status: "error", Error: err.Error()})
+		return
+	case errors.Is(err, ErrEmailTaken):
+		respondWithJSON(w, http.StatusConflict, Response{Status: "error", Error: err.Error()})
+		return
+	case err != nil:
+		respondWithJSON(w, http.StatusInternalServerError, Response{Status: "error", Error: "internal error"})
+		return
+	}
+
+	h.mu.Lock()
+	h.users[fmt.Sprintf("%d", user.ID)] = user
+	h.mu.Unlock()
+
+	respondWithJSON(w, http.StatusCreated, Response{
+		Status:  "success",
+		Message: "User registered successfully",
+		Data:    user,
+	})
+}
+
+// Login handles POST requests checking an email and password
+func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req LoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, Response{Status: "error", Error: "invalid request payload"})
+		return
+	}
+
+	account, err := h.accounts.Authenticate(req.Email, req.Password)
+	switch {
+	case errors.Is(err, ErrAccountLocked):
+		// 423 Locked tells the client that retrying now is pointless
+		respondWithJSON(w, http.StatusLocked, Response{Status: "error", Error: err.Error()})
+		return
+	case errors.Is(err, ErrInvalidCredentials):
+		respondWithJSON(w, http.StatusUnauthorized, Response{Status: "error", Error: err.Error()})
+		return
+	case err != nil:
+		respondWithJSON(w, http.StatusInternalServerError, Response{Status: "error", Error: "internal error"})
+		return
+	}
+
+	h.mu.Lock()
+	user := h.users[fmt.Sprintf("%d", account.UserID)]
+	h.mu.Unlock()
+	respondWithJSON(w, http.StatusOK, Response{
+		Status:  "success",
+		Message: "Login successful",
+		Data:    user,
+	})
+}
+
+/*
+Common Interview Questions about Storing Passwords in Go:
+
+1. Why not store passwords with SHA-256?
+   - A fast hash lets an attacker with a leaked database try billions of
+     guesses a second; a password hash is slow on purpose
+   - PBKDF2 repeats HMAC many times; bcrypt, scrypt and argon2 also make
+     each guess cost memory, which hurts GPUs and ASICs more
+
+2. What is a salt for?
+   - A random per-password value hashed with it, so equal passwords get
+     different hashes and precomputed (rainbow) tables are useless
+   - It is not secret and is stored beside the hash
+
+3. Why compare hashes with crypto/subtle?
+   - bytes.Equal stops at the first difference, so its timing reveals how
+     many leading bytes matched; ConstantTimeCompare always reads them all
+
+4. How do you avoid revealing which accounts exist?
+   - The same error for unknown emails and wrong passwords
+   - Hash something for unknown emails too, so both take as long
+
+5. How does account lockout work, and what are its risks?
+   - After N failures in a row the account refuses logins for a while,
+     which caps online guessing
+   - Anyone can lock a victim out by guessing wrong on purpose, so locks
+     are temporary and real services add per-IP rate limits and CAPTCHAs
+
+6. How do you raise the work factor later?
+   - Store the parameters with each hash, and rehash on the next
+     successful login, when the password is known
+*/
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testIterations keeps hashing fast in tests; the format and code paths
+// are the same as with DefaultIterations
+const testIterations = 1000
+
+// Test PBKDF2-HMAC-SHA256 against the vectors in RFC 7914 section 11
+func TestPBKDF2SHA256(t *testing.T) {
+	tests := []struct {
+		password, salt string
+		iterations     int
+		want           string
+	}{
+		{"passwd", "salt", 1, "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"},
+		{"Password", "NaCl", 80000, "4ddcd8f60b98be21830cee5ef22701f9641a4418d04c0414aeff08876b34ab56a1d425a1225833549adb841b51c9b3176a272bdebba1d078478f62b397f33c8d"},
+	}
+	for _, tc := range tests {
+		got := hex.EncodeToString(pbkdf2SHA256([]byte(tc.password), []byte(tc.salt), tc.iterations, 64))
+		if got != tc.want {
+			t.Errorf("pbkdf2SHA256(%q, %q, %d) = %s; want %s", tc.password, tc.salt, tc.iterations, got, tc.want)
+		}
+	}
+}
+
+// Test that a hashed password verifies, and that the same password is
+// salted differently each time
+func TestHashPassword(t *testing.T) {
+	a := HashPassword("correct horse", testIterations)
+	b := HashPassword("correct horse", testIterations)
+	if a == b {
+		t.Error("Expected two hashes of the same password to differ by salt")
+	}
+	if !strings.HasPrefix(a, fmt.Sprintf("pbkdf2-sha256$%d$", testIterations)) {
+		t.Errorf("Expected the scheme and iterations at the start, got %s", a)
+	}
+	if ok, err := VerifyPassword(a, "correct horse"); !ok || err != nil {
+		t.Errorf("Expected the right password to verify, got %v, %v", ok, err)
+	}
+	if ok, err := VerifyPassword(a, "correct horsf"); ok || err != nil {
+		t.Errorf("Expected a wrong password to fail, got %v, %v", ok, err)
+	}
+}
+
+// Test that any change to a stored hash makes it fail, and that malformed
+// hashes are errors rather than matches
+func TestVerifyPassword_Tampered(t *testing.T) {
+	hash := HashPassword("secret-password", testIterations)
+	parts := strings.Split(hash, "$")
+	withSum := func(sum []byte) string {
+		return strings.Join([]string{parts[0], parts[1], parts[2], base64.RawStdEncoding.EncodeToString(sum)}, "$")
+	}
+	sum, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	flipped := slices.Clone(sum)
+	flipped[len(flipped)-1] ^= 1
+
+	tests := []struct {
+		name    string
+		hash    string
+		wantErr bool
+	}{
+		{"one bit changed", withSum(flipped), false},
+		{"fewer iterations", strings.Join([]string{parts[0], "999", parts[2], parts[3]}, "$"), false},
+		{"hash truncated", withSum(sum[:len(sum)-4]), true},
+		{"hash extended", withSum(append(slices.Clone(sum), 0)), true},
+		{"stray encoding bits", hash[:len(hash)-1] + "B", true}, // the last character holds 4 bits of the hash and 2 unused ones, which B sets
+		{"other scheme", strings.Replace(hash, "pbkdf2-sha256", "md5", 1), true},
+		{"bad iterations", strings.Join([]string{parts[0], "0", parts[2], parts[3]}, "$"), true},
+		{"too few fields", "pbkdf2-sha256$1000$c2FsdA", true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ok, err := VerifyPassword(tc.hash, "secret-password")
+			if ok {
+				t.Error("Expected a tampered hash not to match")
+			}
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Expected error %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+// newTestAccounts returns a store with fast hashing and a clock the test
+// moves by assigning to *now
+func newTestAccounts() (*AccountStore, *time.Time) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := NewAccountStore()
+	s.Iterations = testIterations
+	s.now = func() time.Time { return now }
+	return s, &now
+}
+
+// Test registering, including the checks on passwords and emails
+func TestAccountStore_Register(t *testing.T) {
+	s, _ := newTestAccounts()
+	if err := s.Register(1, "jane@example.com", "short"); !errors.Is(err, ErrWeakPassword) {
+		t.Errorf("Expected ErrWeakPassword, got %v", err)
+	}
+	if err := s.Register(1, "jane@example.com", "long enough"); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := s.Register(2, "JANE@example.com", "another one"); !errors.Is(err, ErrEmailTaken) {
+		t.Errorf("Expected ErrEmailTaken for the same email in other case, got %v", err)
+	}
+
+	a, err := s.Authenticate("Jane@Example.com", "long enough")
+	if err != nil || a.UserID != 1 {
+		t.Fatalf("Expected user 1 to log in, got %+v, %v", a, err)
+	}
+	if strings.Contains(a.PasswordHash, "long enough") {
+		t.Error("Expected the password not to be stored as sent")
+	}
+	if _, err := s.Authenticate("nobody@example.com", "long enough"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected ErrInvalidCredentials for an unknown email, got %v", err)
+	}
+}
+
+// Test that MaxAttempts wrong passwords in a row lock the account, even
+// against the right password, until LockoutDuration has passed
+func TestAccountStore_Lockout(t *testing.T) {
+	s, now := newTestAccounts()
+	if err := s.Register(1, "jane@example.com", "right password"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Failures interrupted by a login do not add up
+	for range s.MaxAttempts - 1 {
+		s.Authenticate("jane@example.com", "wrong")
+	}
+	if _, err := s.Authenticate("jane@example.com", "right password"); err != nil {
+		t.Fatalf("Expected a login after %d failures, got %v", s.MaxAttempts-1, err)
+	}
+
+	for i := range s.MaxAttempts {
+		if _, err := s.Authenticate("jane@example.com", "wrong"); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("Attempt %d: expected ErrInvalidCredentials, got %v", i+1, err)
+		}
+	}
+	if _, err := s.Authenticate("jane@example.com", "right password"); !errors.Is(err, ErrAccountLocked) {
+		t.Errorf("Expected ErrAccountLocked with the right password, got %v", err)
+	}
+
+	*now = now.Add(s.LockoutDuration - time.Second)
+	if _, err := s.Authenticate("jane@example.com", "right password"); !errors.Is(err, ErrAccountLocked) {
+		t.Errorf("Expected the lock to hold until it runs out, got %v", err)
+	}
+	*now = now.Add(time.Second)
+	if _, err := s.Authenticate("jane@example.com", "right password"); err != nil {
+		t.Errorf("Expected a login once the lock ran out, got %v", err)
+	}
+}
+
+// Test that logging in upgrades a hash made with fewer iterations
+func TestAccountStore_RehashOnLogin(t *testing.T) {
+	s, _ := newTestAccounts()
+	if err := s.Register(1, "jane@example.com", "right password"); err != nil {
+		t.Fatal(err)
+	}
+	s.Iterations = 2 * testIterations
+	a, err := s.Authenticate("jane@example.com", "right password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := fmt.Sprintf("pbkdf2-sha256$%d$", 2*testIterations); !strings.HasPrefix(a.PasswordHash, want) {
+		t.Errorf("Expected the hash to be upgraded to %s..., got %s", want, a.PasswordHash)
+	}
+	if _, err := s.Authenticate("jane@example.com", "right password"); err != nil {
+		t.Errorf("Expected the upgraded hash to verify, got %v", err)
+	}
+}
+
+// Test that unknown emails take about as long to reject as wrong
+// passwords. Without the dummy hash they would return in microseconds,
+// against milliseconds for a real check, telling an attacker which emails
+// have accounts.
+func TestAccountStore_UnknownEmailTiming(t *testing.T) {
+	s, _ := newTestAccounts()
+	s.Iterations = 50_000
+	s.MaxAttempts = 1000
+	if err := s.Register(1, "jane@example.com", "right password"); err != nil {
+		t.Fatal(err)
+	}
+	s.Authenticate("nobody@example.com", "warm up") // makes the dummy hash
+
+	median := func(email string) time.Duration {
+		var times []time.Duration
+		for range 5 {
+			start := time.Now()
+			s.Authenticate(email, "wrong password")
+			times = append(times, time.Since(start))
+		}
+		slices.Sort(times)
+		return times[len(times)/2]
+	}
+	known, unknown := median("jane@example.com"), median("nobody@example.com")
+	if unknown < known/3 {
+		t.Errorf("Expected unknown emails to take as long as wrong passwords, got %v against %v", unknown, known)
+	}
+}
+
+// Test registering and logging in through the HTTP handlers
+func TestRegisterAndLogin(t *testing.T) {
+	handler := NewUserHandler()
+	handler.accounts.Iterations = testIterations
+	router := http.NewServeMux()
+	router.HandleFunc("/user/register", handler.Register)
+	router.HandleFunc("/user/login", handler.Login)
+
+	send := func(path, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+		return rr
+	}
+	jane := `{"FirstName":"Jane","LastName":"Doe","Email":"jane@example.com","Age":25,"Password":"correct horse"}`
+
+	rr := send("/user/register", jane)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d (%s)", http.StatusCreated, rr.Code, rr.Body.String())
+	}
+	if strings.Contains(rr.Body.String(), "correct horse") {
+		t.Error("Expected the response not to echo the password")
+	}
+	if _, exists := handler.users["2"]; !exists {
+		t.Error("Expected the user to get the next free ID, 2")
+	}
+
+	tests := []struct {
+		name       string
+		path, body string
+		wantStatus int
+	}{
+		{"duplicate email", "/user/register", jane, http.StatusConflict},
+		{"weak password", "/user/register", `{"FirstName":"Al","LastName":"Lee","Email":"al@example.com","Password":"short"}`, http.StatusBadRequest},
+		{"invalid user", "/user/register", `{"FirstName":"","LastName":"Lee","Email":"al@example.com","Password":"long enough"}`, http.StatusBadRequest},
+		{"login", "/user/login", `{"Email":"jane@example.com","Password":"correct horse"}`, http.StatusOK},
+		{"wrong password", "/user/login", `{"Email":"jane@example.com","Password":"wrong"}`, http.StatusUnauthorized},
+		{"unknown email", "/user/login", `{"Email":"al@example.com","Password":"correct horse"}`, http.StatusUnauthorized},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if rr := send(tc.path, tc.body); rr.Code != tc.wantStatus {
+				t.Errorf("Expected status code %d, got %d (%s)", tc.wantStatus, rr.Code, rr.Body.String())
+			}
+		})
+	}
+
+	// One wrong password has been tried; four more lock the account
+	for range 4 {
+		send("/user/login", `{"Email":"jane@example.com","Password":"wrong"}`)
+	}
+	if rr := send("/user/login", `{"Email":"jane@example.com","Password":"correct horse"}`); rr.Code != http.StatusLocked {
+		t.Errorf("Expected status code %d once locked, got %d", http.StatusLocked, rr.Code)
+	}
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// UserDB is a database of Users kept in one JSON file. It stands in for
+// the SQL database a real service would test against, which this module,
+// using the standard library alone, has no driver for; what the tests do
+// with it is the same. Its schema changes through Migrations, recorded in
+// the file so each runs once, and TestMain in 06_testing_test.go gives
+// the package's tests a migrated and seeded one in a temporary directory.
+type UserDB struct {
+	mu     sync.Mutex
+	path   string
+	data   userDBFile
+	closed bool
+}
+
+// userDBFile is what the file holds. Users is nil until the first
+// migration creates it, as a table would be.
+type userDBFile struct {
+	Version int    `json:"version"`
+	NextID  int    `json:"next_id"`
+	Users   []User `json:"users"`
+}
+
+// Migration moves the schema to Version, rewriting the users if it must
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(users []User) ([]User, error)
+}
+
+// UserMigrations are the migrations a UserDB needs, in order
+var UserMigrations = []Migration{
+	{1, "create users", func([]User) ([]User, error) { return []User{}, nil }},
+	{2, "lower-case emails", func(users []User) ([]User, error) {
+		for i := range users {
+			users[i].Email = strings.ToLower(users[i].Email)
+		}
+		return users, nil
+	}},
+	{3, "unique emails", func(users []User) ([]User, error) {
+		seen := make(map[string]bool)
+		for _, u := range users {
+			if seen[u.Email] {
+				return nil, fmt.Errorf("email %s is used by more than one user", u.Email)
+			}
+			seen[u.Email] = true
+		}
+		return users, nil
+	}},
+}
+
+var (
+	// ErrNotMigrated is returned by queries before the users exist
+	ErrNotMigrated = errors.New("userdb: no users table; run the migrations")
+
+	// ErrDBClosed is returned by every method after Close
+	ErrDBClosed = errors.New("userdb: database is closed")
+
+	// ErrDuplicateEmail is returned by Insert for an email already used
+	ErrDuplicateEmail = errors.New("userdb: email already used")
+)
+
+// OpenUserDB opens the database in the file at path, or an empty one at
+// version 0 if there is no file yet
+func OpenUserDB(path string) (*UserDB, error) {
+	db := &UserDB{path: path, data: userDBFile{NextID: 1}}
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return db, nil
+	case err != nil:
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &db.data); err != nil {
+		return nil, fmt.Errorf("userdb: %s: %w", path, err)
+	}
+	return db, nil
+}
+
+// Version returns the version of the last migration applied
+func (db *UserDB) Version() int {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.data.Version
+}
+
+// Migrate applies the migrations newer than the database, in version
+// order, saving after each so a failure keeps those before it. It returns
+// how many it applied.
+func (db *UserDB) Migrate(migrations []Migration) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.closed {
+		return 0, ErrDBClosed
+	}
+	pending := make([]Migration, 0, len(migrations))
+	for _, m := range migrations {
+		if m.Version > db.data.Version {
+			pending = append(pending, m)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].Version < pending[j].Version })
+
+	for i, m := range pending {
+		users, err := m.Up(slices.Clone(db.data.Users))
+		if err != nil {
+			return i, fmt.Errorf("userdb: migration %d (%s): %w", m.Version, m.Name, err)
+		}
+		prev := db.data
+		db.data.Users, db.data.Version = users, m.Version
+		if err := db.save(); err != nil {
+			db.data = prev
+			return i, err
+		}
+	}
+	return len(pending), nil
+}
+
+// Insert validates u and stores it with a new ID, which it returns
+func (db *UserDB) Insert(u User) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if err := db.usable(); err != nil {
+		return 0, err
+	}
+	if err := ValidateUser(u); err != nil {
+		return 0, err
+	}
+	u.Email = strings.ToLower(u.Email)
+	for _, other := range db.data.Users {
+		if other.Email == u.Email {
+			return 0, fmt.Errorf("%w: %s", ErrDuplicateEmail, u.Email)
+		}
+	}
+	u.ID = db.data.NextID
+	db.data.NextID++
+	db.data.Users = append(db.data.Users, u)
+	if err := db.save(); err != nil {
+		db.data.Users = db.data.Users[:len(db.data.Users)-1]
+		db.data.NextID--
+		return 0, err
+	}
+	return u.ID, nil
+}
+
+// UserByEmail returns the user with email, in any case
+func (db *UserDB) UserByEmail(email string) (User, bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if err := db.usable(); err != nil {
+		return User{}, false, err
+	}
+	email = strings.ToLower(email)
+	for _, u := range db.data.Users {
+		if u.Email == email {
+			return u, true, nil
+		}
+	}
+	return User{}, false, nil
+}
+
+// Count returns how many users there are
+func (db *UserDB) Count() (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if err := db.usable(); err != nil {
+		return 0, err
+	}
+	return len(db.data.Users), nil
+}
+
+// Close releases the database; its file stays
+func (db *UserDB) Close() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.closed {
+		return ErrDBClosed
+	}
+	db.closed = true
+	return nil
+}
+
+// usable returns why the users cannot be queried, if they cannot; db.mu
+// is held
+func (db *UserDB) usable() error {
+	switch {
+	case db.closed:
+		return ErrDBClosed
+	case db.data.Users == nil:
+		return ErrNotMigrated
+	}
+	return nil
+}
+
+// save writes the file to a temporary one renamed over it, so a crash
+// leaves the old or the new version, never half of one; db.mu is held
+func (db *UserDB) save() error {
+	data, err := json.MarshalIndent(db.data, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(db.path), filepath.Base(db.path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // fails once renamed
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), db.path)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// The tests of seeded data use testDB, set up by TestMain; those that
+// need a database of their own open one in t.TempDir.
+
+func TestUserDB_Seeded(t *testing.T) {
+	if n, err := testDB.Count(); err != nil || n < 3 {
+		t.Fatalf("Count() = %d, %v; want at least the 3 fixtures", n, err)
+	}
+	if v := testDB.Version(); v != len(UserMigrations) {
+		t.Errorf("Version() = %d; want %d, every migration applied", v, len(UserMigrations))
+	}
+	u, ok, err := testDB.UserByEmail("JANE.SMITH@example.com")
+	if err != nil || !ok || u.FirstName != "Jane" || u.Email != "jane.smith@example.com" {
+		t.Errorf("UserByEmail = %+v, %v, %v; want Jane, stored lower case", u, ok, err)
+	}
+}
+
+func TestUserDB_Insert(t *testing.T) {
+	id, err := testDB.Insert(User{FirstName: "Grace", LastName: "Hopper", Email: "grace@example.com", Age: 85})
+	if err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	if u, ok, _ := testDB.UserByEmail("grace@example.com"); !ok || u.ID != id {
+		t.Errorf("UserByEmail = %+v, %v; want the user inserted as %d", u, ok, id)
+	}
+
+	if _, err := testDB.Insert(User{FirstName: "John", LastName: "Again", Email: "John@Example.com"}); !errors.Is(err, ErrDuplicateEmail) {
+		t.Errorf("Insert of a fixture's email = %v; want ErrDuplicateEmail", err)
+	}
+	if _, err := testDB.Insert(User{FirstName: "No", LastName: "Email"}); err == nil || !strings.Contains(err.Error(), "Email is required") {
+		t.Errorf("Insert without an email = %v; want a validation error", err)
+	}
+}
+
+func TestUserDB_Migrate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.json")
+	db, err := OpenUserDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Insert(User{FirstName: "A", LastName: "B", Email: "a@example.com"}); !errors.Is(err, ErrNotMigrated) {
+		t.Errorf("Insert before migrating = %v; want ErrNotMigrated", err)
+	}
+
+	// Migrations run in version order, each once
+	if n, err := db.Migrate(UserMigrations[:2]); n != 2 || err != nil {
+		t.Fatalf("Migrate(first two) = %d, %v; want 2, nil", n, err)
+	}
+	if _, err := db.Insert(User{FirstName: "A", LastName: "B", Email: "a@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := db.Migrate(UserMigrations); n != 1 || err != nil {
+		t.Fatalf("Migrate(all) = %d, %v; want only the third", n, err)
+	}
+	db.Close()
+	if _, err := db.Count(); !errors.Is(err, ErrDBClosed) {
+		t.Errorf("Count after Close = %v; want ErrDBClosed", err)
+	}
+
+	// The version and users are in the file
+	db, err = OpenUserDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if n, err := db.Migrate(UserMigrations); n != 0 || err != nil {
+		t.Errorf("Migrate after reopening = %d, %v; want none to run", n, err)
+	}
+	if n, err := db.Count(); n != 1 || err != nil {
+		t.Errorf("Count after reopening = %d, %v; want 1", n, err)
+	}
+}
+
+func TestUserDB_FailedMigration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.json")
+	// Two users whose emails differ only in case, from before emails were
+	// lower-cased
+	os.WriteFile(path, []byte(`{"version": 1, "next_id": 3, "users": [
+		{"ID": 1, "Email": "ann@example.com"}, {"ID": 2, "Email": "Ann@Example.com"}]}`), 0o644)
+
+	db, err := OpenUserDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	n, err := db.Migrate(UserMigrations)
+	if n != 1 || err == nil || !strings.Contains(err.Error(), "migration 3 (unique emails): email ann@example.com is used by more than one user") {
+		t.Fatalf("Migrate = %d, %v; want migration 2 applied and 3 failing", n, err)
+	}
+	if v := db.Version(); v != 2 {
+		t.Errorf("Version() = %d; want 2, the last migration that succeeded", v)
+	}
+}
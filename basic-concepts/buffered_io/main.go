@@ -0,0 +1,236 @@
+package bufferedio
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/rehan/go-interview-prep/pkg/quiz"
+)
+
+// Run prints the bufio reader, writer and scanner examples to w
+func Run(w io.Writer) error {
+	fmt.Fprintln(w, "=========================================")
+	fmt.Fprintln(w, "GO BUFIO AND SCANNER EXAMPLES")
+	fmt.Fprintln(w, "=========================================")
+
+	LineScanningExample(w)
+	CustomSplitExample(w)
+	LongLinesExample(w)
+	BufferedWriterExample(w)
+
+	// Interview questions
+	BufioInterviewQuestions(w)
+	return nil
+}
+
+// CountLines counts the lines in r. A final line without a trailing
+// newline still counts.
+func CountLines(r io.Reader) (int, error) {
+	scanner := bufio.NewScanner(r)
+	n := 0
+	for scanner.Scan() {
+		n++
+	}
+	return n, scanner.Err()
+}
+
+// CountWords counts the words in r, where a word is a run of non-space
+// characters as defined by unicode.IsSpace
+func CountWords(r io.Reader) (int, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Split(bufio.ScanWords)
+	n := 0
+	for scanner.Scan() {
+		n++
+	}
+	return n, scanner.Err()
+}
+
+// WordCount counts the words in s. Unlike counting spaces by hand it
+// handles tabs, newlines, repeated and non-ASCII whitespace.
+func WordCount(s string) int {
+	n, _ := CountWords(strings.NewReader(s)) // a strings.Reader never fails
+	return n
+}
+
+// SplitOn returns a split function that produces the fields between
+// occurrences of sep, like strings.Split but over a stream. A trailing
+// separator does not produce an empty last field.
+func SplitOn(sep byte) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (advance int, token []byte, err error) {
+		if i := bytes.IndexByte(data, sep); i >= 0 {
+			return i + 1, data[:i], nil
+		}
+		if atEOF && len(data) > 0 {
+			return len(data), data, nil
+		}
+		// Request more data; at EOF with nothing left this ends the scan
+		return 0, nil, nil
+	}
+}
+
+// ScanParagraphs is a split function for blocks of text separated by one
+// or more blank lines. Leading blank lines are skipped.
+func ScanParagraphs(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	start := 0
+	for start < len(data) && data[start] == '\n' {
+		start++
+	}
+	if i := bytes.Index(data[start:], []byte("\n\n")); i >= 0 {
+		return start + i + 2, data[start : start+i], nil
+	}
+	if atEOF && start < len(data) {
+		return len(data), bytes.TrimRight(data[start:], "\n"), nil
+	}
+	// Consume the blank lines already seen, or ask for more data
+	return start, nil, nil
+}
+
+// Scan returns every token split from r
+func Scan(r io.Reader, split bufio.SplitFunc) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Split(split)
+	var tokens []string
+	for scanner.Scan() {
+		tokens = append(tokens, scanner.Text())
+	}
+	return tokens, scanner.Err()
+}
+
+// ReadLines returns the lines of r, allowing lines up to maxLine bytes.
+// A Scanner's default limit is bufio.MaxScanTokenSize (64 KiB); a longer
+// line stops the scan with bufio.ErrTooLong.
+func ReadLines(r io.Reader, maxLine int) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), maxLine)
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
+
+// ReadLinesUnbounded reads lines of any length with bufio.Reader, which
+// grows its result instead of failing. Use it only on trusted input: a
+// single line can take all available memory.
+func ReadLinesUnbounded(r io.Reader) ([]string, error) {
+	br := bufio.NewReader(r)
+	var lines []string
+	for {
+		line, err := br.ReadString('\n')
+		if len(line) > 0 {
+			lines = append(lines, strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"))
+		}
+		if errors.Is(err, io.EOF) {
+			return lines, nil
+		}
+		if err != nil {
+			return lines, err
+		}
+	}
+}
+
+// WriteLines writes n numbered lines to w, one Write call per line
+func WriteLines(w io.Writer, n int) error {
+	for i := 0; i < n; i++ {
+		if _, err := fmt.Fprintf(w, "line %d\n", i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteLinesBuffered writes the same lines through a bufio.Writer, which
+// turns many small writes into a few large ones. Forgetting Flush loses
+// whatever is still in the buffer.
+func WriteLinesBuffered(w io.Writer, n int) error {
+	bw := bufio.NewWriter(w)
+	if err := WriteLines(bw, n); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// writeCounter counts the Write calls that reach it; on a file or socket
+// each one would be a system call
+type writeCounter struct {
+	calls int
+	bytes int
+}
+
+func (c *writeCounter) Write(p []byte) (int, error) {
+	c.calls++
+	c.bytes += len(p)
+	return len(p), nil
+}
+
+// LineScanningExample counts lines and words
+func LineScanningExample(w io.Writer) {
+	fmt.Fprintln(w, "=== LINES AND WORDS ===")
+
+	text := "first line\nsecond\tline with tabs\n\n  last line, no newline"
+	lines, _ := CountLines(strings.NewReader(text))
+	words, _ := CountWords(strings.NewReader(text))
+	fmt.Fprintf(w, "%q\nlines: %d, words: %d\n", text, lines, words)
+
+	for _, s := range []string{"", " ", "hello  world", "a\tb\nc", "héllo wörld\u00a0again"} {
+		fmt.Fprintf(w, "WordCount(%q) = %d\n", s, WordCount(s))
+	}
+	fmt.Fprintln(w)
+}
+
+// CustomSplitExample uses hand-written split functions
+func CustomSplitExample(w io.Writer) {
+	fmt.Fprintln(w, "=== CUSTOM SPLIT FUNCTIONS ===")
+
+	fields, _ := Scan(strings.NewReader("go,rust,,zig,"), SplitOn(','))
+	fmt.Fprintf(w, "SplitOn(','): %q\n", fields)
+
+	paras, _ := Scan(strings.NewReader("\n\nfirst paragraph\nstill first\n\n\nsecond\n"), ScanParagraphs)
+	fmt.Fprintf(w, "ScanParagraphs: %q\n", paras)
+
+	runes, _ := Scan(strings.NewReader("añ世"), bufio.ScanRunes)
+	fmt.Fprintf(w, "bufio.ScanRunes: %q\n", runes)
+	fmt.Fprintln(w)
+}
+
+// LongLinesExample shows bufio.ErrTooLong and two ways around it
+func LongLinesExample(w io.Writer) {
+	fmt.Fprintln(w, "=== VERY LONG LINES ===")
+
+	input := "short\n" + strings.Repeat("x", 100_000) + "\nafter\n"
+
+	lines, err := ReadLines(strings.NewReader(input), bufio.MaxScanTokenSize)
+	fmt.Fprintf(w, "default limit:      %d line(s), err: %v\n", len(lines), err)
+
+	lines, err = ReadLines(strings.NewReader(input), 1<<20)
+	fmt.Fprintf(w, "1 MiB limit:        %d line(s), err: %v\n", len(lines), err)
+
+	lines, err = ReadLinesUnbounded(strings.NewReader(input))
+	fmt.Fprintf(w, "bufio.Reader:       %d line(s), err: %v\n", len(lines), err)
+	fmt.Fprintln(w)
+}
+
+// BufferedWriterExample counts the writes that reach the destination
+func BufferedWriterExample(w io.Writer) {
+	fmt.Fprintln(w, "=== BUFFERED WRITING ===")
+
+	var direct, buffered writeCounter
+	WriteLines(&direct, 10_000)
+	WriteLinesBuffered(&buffered, 10_000)
+	fmt.Fprintf(w, "unbuffered: %5d writes for %d bytes\n", direct.calls, direct.bytes)
+	fmt.Fprintf(w, "buffered:   %5d writes for %d bytes\n", buffered.calls, buffered.bytes)
+	fmt.Fprintln(w, "(see BenchmarkWriteLines for the time difference on a real file)")
+	fmt.Fprintln(w)
+}
+
+// BufioInterviewQuestions lists common interview questions on bufio
+func BufioInterviewQuestions(w io.Writer) {
+	if err := quiz.Print(w, "buffered-io"); err != nil {
+		fmt.Fprintln(w, err)
+	}
+}
@@ -0,0 +1,193 @@
+package bufferedio
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestWordCount(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int
+	}{
+		{"", 0},
+		{" ", 0},
+		{"oneword", 1},
+		{"hello world", 2},
+		{"   spaced   words   ", 2},
+		{"tabs\tand\nnewlines\r\n", 3},
+		{"héllo wörld", 2},
+		{"non breaking spaces", 3},
+	}
+	for _, tc := range tests {
+		if got := WordCount(tc.input); got != tc.want {
+			t.Errorf("WordCount(%q) = %d; want %d", tc.input, got, tc.want)
+		}
+	}
+}
+
+func TestCountLines(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int
+	}{
+		{"", 0},
+		{"one", 1},
+		{"one\n", 1},
+		{"one\ntwo", 2},
+		{"one\r\ntwo\r\n", 2},
+		{"\n\n", 2},
+	}
+	for _, tc := range tests {
+		got, err := CountLines(strings.NewReader(tc.input))
+		if err != nil || got != tc.want {
+			t.Errorf("CountLines(%q) = %d, %v; want %d", tc.input, got, err, tc.want)
+		}
+	}
+}
+
+// Scanner errors must be reported, not mistaken for EOF
+func TestCountWords_ReaderError(t *testing.T) {
+	boom := errors.New("disk on fire")
+	r := io.MultiReader(strings.NewReader("some words "), iotest.ErrReader(boom))
+	if _, err := CountWords(r); !errors.Is(err, boom) {
+		t.Errorf("CountWords error = %v; want %v", err, boom)
+	}
+}
+
+func TestSplitFuncs(t *testing.T) {
+	tests := []struct {
+		name  string
+		split bufio.SplitFunc
+		input string
+		want  []string
+	}{
+		{"SplitOn", SplitOn(','), "a,b,,c", []string{"a", "b", "", "c"}},
+		{"SplitOn trailing separator", SplitOn(','), "a,b,", []string{"a", "b"}},
+		{"SplitOn empty", SplitOn(','), "", nil},
+		{"paragraphs", ScanParagraphs, "one\ntwo\n\nthree", []string{"one\ntwo", "three"}},
+		{"paragraphs extra blank lines", ScanParagraphs, "\n\none\n\n\n\ntwo\n\n", []string{"one", "two"}},
+		{"paragraphs only blank lines", ScanParagraphs, "\n\n\n", nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Scan(strings.NewReader(tc.input), tc.split)
+			if err != nil {
+				t.Fatalf("Scan: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Scan(%q) = %q; want %q", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+// A split function must give the same tokens however the input is chunked
+func TestSplitFuncs_OneByteReads(t *testing.T) {
+	input := "\nfirst\npara\n\n\nsecond\n"
+	got, err := Scan(iotest.OneByteReader(strings.NewReader(input)), ScanParagraphs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"first\npara", "second"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ScanParagraphs over one-byte reads = %q; want %q", got, want)
+	}
+
+	got, err = Scan(iotest.OneByteReader(strings.NewReader("ab,cd")), SplitOn(','))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"ab", "cd"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("SplitOn over one-byte reads = %q; want %q", got, want)
+	}
+}
+
+func TestReadLines_LongLines(t *testing.T) {
+	long := strings.Repeat("x", 100_000)
+	input := "short\n" + long + "\nafter\n"
+
+	lines, err := ReadLines(strings.NewReader(input), bufio.MaxScanTokenSize)
+	if !errors.Is(err, bufio.ErrTooLong) {
+		t.Errorf("default limit: err = %v; want bufio.ErrTooLong", err)
+	}
+	if len(lines) != 1 {
+		t.Errorf("default limit: got %d lines before the error; want 1", len(lines))
+	}
+
+	want := []string{"short", long, "after"}
+	lines, err = ReadLines(strings.NewReader(input), 1<<20)
+	if err != nil || !reflect.DeepEqual(lines, want) {
+		t.Errorf("1 MiB limit: %d lines, err %v; want all 3", len(lines), err)
+	}
+
+	lines, err = ReadLinesUnbounded(strings.NewReader(input))
+	if err != nil || !reflect.DeepEqual(lines, want) {
+		t.Errorf("ReadLinesUnbounded: %d lines, err %v; want all 3", len(lines), err)
+	}
+}
+
+func TestReadLinesUnbounded_MatchesScanner(t *testing.T) {
+	for _, input := range []string{"", "a", "a\n", "a\r\nb", "\n\nx\n"} {
+		want, _ := ReadLines(strings.NewReader(input), bufio.MaxScanTokenSize)
+		got, err := ReadLinesUnbounded(strings.NewReader(input))
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("ReadLinesUnbounded(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+}
+
+func TestWriteLinesBuffered(t *testing.T) {
+	var direct, buffered writeCounter
+	if err := WriteLines(&direct, 1000); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteLinesBuffered(&buffered, 1000); err != nil {
+		t.Fatal(err)
+	}
+	if buffered.bytes != direct.bytes {
+		t.Errorf("buffered wrote %d bytes; want %d (was Flush called?)", buffered.bytes, direct.bytes)
+	}
+	if buffered.calls*50 > direct.calls {
+		t.Errorf("buffered made %d writes vs %d unbuffered; want far fewer", buffered.calls, direct.calls)
+	}
+}
+
+func BenchmarkWriteLines(b *testing.B) {
+	writers := []struct {
+		name  string
+		write func(io.Writer, int) error
+	}{
+		{"unbuffered", WriteLines},
+		{"buffered", WriteLinesBuffered},
+	}
+	for _, w := range writers {
+		b.Run(w.name, func(b *testing.B) {
+			f, err := os.Create(filepath.Join(b.TempDir(), "out.txt"))
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer f.Close()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := w.write(f, 1000); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkCountWords(b *testing.B) {
+	text := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 100)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		WordCount(text)
+	}
+}
@@ -0,0 +1,64 @@
+package buildtags
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+
+	"github.com/rehan/go-interview-prep/pkg/quiz"
+)
+
+// Run shows which platform and feature files this build compiled in
+func Run(w io.Writer) error {
+	fmt.Fprintln(w, "=========================================")
+	fmt.Fprintln(w, "GO BUILD TAGS AND CONDITIONAL COMPILATION")
+	fmt.Fprintln(w, "=========================================")
+
+	PlatformFilesExample(w)
+	FeatureTagExample(w)
+
+	// Interview questions
+	BuildTagsInterviewQuestions(w)
+	return nil
+}
+
+// PlatformFilesExample calls functions defined once per platform
+func PlatformFilesExample(w io.Writer) {
+	fmt.Fprintln(w, "=== PLATFORM-SPECIFIC FILES ===")
+
+	fmt.Fprintf(w, "GOOS=%s GOARCH=%s\n", runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintln(w, "platform file in this build:", platformName())
+	fmt.Fprintln(w, "config directory convention:", defaultConfigDir())
+	fmt.Fprintln(w, "try: GOOS=windows go build -o /dev/null ./basic-concepts/build_tags && GOOS=plan9 go vet ./basic-concepts/build_tags")
+	fmt.Fprintln(w)
+}
+
+// process is ordinary code sprinkled with trace calls that cost nothing in
+// the default build
+func process(w io.Writer, items []string) int {
+	total := 0
+	for _, item := range items {
+		if tracingEnabled {
+			trace(w, "processing %q", item)
+		}
+		total += len(item)
+	}
+	return total
+}
+
+// FeatureTagExample shows a feature switched on with -tags tracing
+func FeatureTagExample(w io.Writer) {
+	fmt.Fprintln(w, "=== FEATURE FLAG TAG ===")
+
+	fmt.Fprintln(w, "tracing compiled in:", tracingEnabled)
+	fmt.Fprintln(w, "total:", process(w, []string{"alpha", "beta", "gamma"}))
+	fmt.Fprintln(w, "try: go run -tags tracing ./cmd/runner demo build-tags")
+	fmt.Fprintln(w)
+}
+
+// BuildTagsInterviewQuestions lists common interview questions on build constraints
+func BuildTagsInterviewQuestions(w io.Writer) {
+	if err := quiz.Print(w, "build-tags"); err != nil {
+		fmt.Fprintln(w, err)
+	}
+}
@@ -0,0 +1,27 @@
+package buildtags
+
+import (
+	"io"
+	"runtime"
+	"testing"
+)
+
+func TestPlatformName(t *testing.T) {
+	want := map[string]string{"linux": "Linux", "darwin": "macOS", "windows": "Windows"}[runtime.GOOS]
+	if want == "" {
+		want = "other"
+	}
+	if got := platformName(); got != want {
+		t.Errorf("platformName() = %q on %s; want %q", got, runtime.GOOS, want)
+	}
+}
+
+// The default build, which is what go test runs, must not trace
+func TestTracingDisabledByDefault(t *testing.T) {
+	if tracingEnabled {
+		t.Skip("built with -tags tracing")
+	}
+	if got := process(io.Discard, []string{"ab", "c"}); got != 3 {
+		t.Errorf("process = %d; want 3", got)
+	}
+}
@@ -0,0 +1,5 @@
+package buildtags
+
+func platformName() string { return "macOS" }
+
+func defaultConfigDir() string { return "~/Library/Application Support" }
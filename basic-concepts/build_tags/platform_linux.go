@@ -0,0 +1,9 @@
+package buildtags
+
+// The _linux suffix alone restricts this file to GOOS=linux; no //go:build
+// line is needed.
+
+func platformName() string { return "Linux" }
+
+// defaultConfigDir follows the XDG base directory convention
+func defaultConfigDir() string { return "$XDG_CONFIG_HOME or ~/.config" }
@@ -0,0 +1,11 @@
+//go:build !linux && !darwin && !windows
+
+package buildtags
+
+// A //go:build line covers what a filename suffix cannot: here, every
+// platform without its own file. Exactly one platform file must build for
+// each GOOS, or the package fails with a missing or duplicate function.
+
+func platformName() string { return "other" }
+
+func defaultConfigDir() string { return "~/.config" }
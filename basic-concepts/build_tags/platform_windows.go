@@ -0,0 +1,5 @@
+package buildtags
+
+func platformName() string { return "Windows" }
+
+func defaultConfigDir() string { return "%AppData%" }
@@ -0,0 +1,10 @@
+//go:build !tracing
+
+package buildtags
+
+import "io"
+
+const tracingEnabled = false
+
+// trace does nothing unless the program is built with -tags tracing
+func trace(w io.Writer, format string, args ...any) {}
@@ -0,0 +1,16 @@
+//go:build tracing
+
+package buildtags
+
+import (
+	"fmt"
+	"io"
+)
+
+// tracingEnabled is a constant, so in the default build the compiler
+// removes "if tracingEnabled" blocks entirely
+const tracingEnabled = true
+
+func trace(w io.Writer, format string, args ...any) {
+	fmt.Fprintf(w, "[trace] "+format+"\n", args...)
+}
@@ -0,0 +1,113 @@
+// Package command is a small subcommand dispatcher in the style of the go
+// tool: "prog <command> [arguments]", with generated usage text and
+// "help <command>". It is what cmd/runner uses; larger programs usually
+// reach for a library such as cobra, but the mechanics are the same.
+package command
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Exit codes returned by Run functions, following the shell convention
+// that 2 means the program was invoked incorrectly
+const (
+	ExitOK    = 0
+	ExitError = 1
+	ExitUsage = 2
+)
+
+// Command is one subcommand
+type Command struct {
+	Name    string
+	Summary string // one line, shown in the command list
+	Help    string // shown by "help <name>"; Summary is used if empty
+
+	// Run receives the arguments after the command name and returns the
+	// exit code. Writers are passed in so tests can capture output.
+	Run func(args []string, stdout, stderr io.Writer) int
+}
+
+// Dispatcher routes the first argument to a Command
+type Dispatcher struct {
+	name     string
+	commands map[string]*Command
+}
+
+// New returns a Dispatcher for the program called name. It panics on a
+// duplicate or unnamed command, which is a programming error.
+func New(name string, commands ...*Command) *Dispatcher {
+	d := &Dispatcher{name: name, commands: make(map[string]*Command)}
+	for _, c := range commands {
+		if c.Name == "" || c.Name == "help" {
+			panic(fmt.Sprintf("command: invalid command name %q", c.Name))
+		}
+		if _, dup := d.commands[c.Name]; dup {
+			panic(fmt.Sprintf("command: duplicate command %q", c.Name))
+		}
+		d.commands[c.Name] = c
+	}
+	return d
+}
+
+// Usage lists the commands in alphabetical order
+func (d *Dispatcher) Usage() string {
+	names := make([]string, 0, len(d.commands))
+	width := len("help")
+	for name := range d.commands {
+		names = append(names, name)
+		width = max(width, len(name))
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "usage: %s <command> [arguments]\n\ncommands:\n", d.name)
+	for _, name := range names {
+		fmt.Fprintf(&b, "  %-*s  %s\n", width, name, d.commands[name].Summary)
+	}
+	fmt.Fprintf(&b, "  %-*s  %s\n", width, "help", "show help for a command")
+	return b.String()
+}
+
+// Run dispatches args and returns the exit code
+func (d *Dispatcher) Run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, d.Usage())
+		return ExitUsage
+	}
+
+	name := args[0]
+	switch name {
+	case "help", "-h", "-help", "--help":
+		return d.help(args[1:], stdout, stderr)
+	}
+
+	c, ok := d.commands[name]
+	if !ok {
+		fmt.Fprintf(stderr, "%s: unknown command %q\n\n%s", d.name, name, d.Usage())
+		return ExitUsage
+	}
+	return c.Run(args[1:], stdout, stderr)
+}
+
+// help prints the command list, or one command's help
+func (d *Dispatcher) help(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stdout, d.Usage())
+		return ExitOK
+	}
+
+	c, ok := d.commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "%s help: unknown command %q\n", d.name, args[0])
+		return ExitUsage
+	}
+	text := c.Help
+	if text == "" {
+		text = c.Summary + "\n"
+	}
+	fmt.Fprint(stdout, text)
+	return ExitOK
+}
@@ -0,0 +1,96 @@
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func newTestDispatcher(got *[]string) *Dispatcher {
+	echo := func(name string) func([]string, io.Writer, io.Writer) int {
+		return func(args []string, stdout, stderr io.Writer) int {
+			*got = append([]string{name}, args...)
+			fmt.Fprintln(stdout, "ran", name)
+			return ExitOK
+		}
+	}
+	return New("tool",
+		&Command{Name: "build", Summary: "compile things", Help: "usage: tool build [pkg]\n", Run: echo("build")},
+		&Command{Name: "fmt", Summary: "format things", Run: echo("fmt")},
+	)
+}
+
+func TestDispatcher_Run(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantCode   int
+		wantStdout string
+		wantStderr string
+		wantCall   []string
+	}{
+		{"dispatches with remaining args", []string{"build", "-v", "./..."}, ExitOK, "ran build", "", []string{"build", "-v", "./..."}},
+		{"no args prints usage", nil, ExitUsage, "", "usage: tool <command>", nil},
+		{"unknown command", []string{"deploy"}, ExitUsage, "", `unknown command "deploy"`, nil},
+		{"help lists commands", []string{"help"}, ExitOK, "compile things", "", nil},
+		{"-h lists commands", []string{"-h"}, ExitOK, "usage: tool", "", nil},
+		{"help for a command", []string{"help", "build"}, ExitOK, "usage: tool build [pkg]", "", nil},
+		{"help falls back to summary", []string{"help", "fmt"}, ExitOK, "format things", "", nil},
+		{"help for unknown command", []string{"help", "deploy"}, ExitUsage, "", `unknown command "deploy"`, nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var called []string
+			var stdout, stderr bytes.Buffer
+			code := newTestDispatcher(&called).Run(tc.args, &stdout, &stderr)
+
+			if code != tc.wantCode {
+				t.Errorf("exit code = %d; want %d", code, tc.wantCode)
+			}
+			if !strings.Contains(stdout.String(), tc.wantStdout) {
+				t.Errorf("stdout = %q; want it to contain %q", stdout.String(), tc.wantStdout)
+			}
+			if !strings.Contains(stderr.String(), tc.wantStderr) {
+				t.Errorf("stderr = %q; want it to contain %q", stderr.String(), tc.wantStderr)
+			}
+			if fmt.Sprint(called) != fmt.Sprint(tc.wantCall) {
+				t.Errorf("called = %v; want %v", called, tc.wantCall)
+			}
+		})
+	}
+}
+
+func TestDispatcher_Usage(t *testing.T) {
+	var called []string
+	want := `usage: tool <command> [arguments]
+
+commands:
+  build  compile things
+  fmt    format things
+  help   show help for a command
+`
+	if got := newTestDispatcher(&called).Usage(); got != want {
+		t.Errorf("Usage() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestNew_PanicsOnBadCommands(t *testing.T) {
+	run := func([]string, io.Writer, io.Writer) int { return ExitOK }
+	tests := map[string][]*Command{
+		"duplicate": {{Name: "a", Run: run}, {Name: "a", Run: run}},
+		"empty":     {{Name: "", Run: run}},
+		"help":      {{Name: "help", Run: run}},
+	}
+	for name, cmds := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("New did not panic")
+				}
+			}()
+			New("tool", cmds...)
+		})
+	}
+}
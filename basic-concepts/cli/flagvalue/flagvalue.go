@@ -0,0 +1,76 @@
+// Package flagvalue provides flag.Value implementations for settings the
+// flag package has no built-in type for. Any type with String and Set
+// methods can be passed to flag.Var.
+package flagvalue
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DurationList collects durations from a comma-separated value and from
+// repeated flags: -backoff 1s,2s -backoff 5s gives [1s 2s 5s]
+type DurationList []time.Duration
+
+// String formats the list the way Set accepts it
+func (l *DurationList) String() string {
+	if l == nil {
+		return ""
+	}
+	parts := make([]string, len(*l))
+	for i, d := range *l {
+		parts[i] = d.String()
+	}
+	return strings.Join(parts, ",")
+}
+
+// Set appends the durations in s
+func (l *DurationList) Set(s string) error {
+	for _, part := range strings.Split(s, ",") {
+		d, err := time.ParseDuration(strings.TrimSpace(part))
+		if err != nil {
+			return err
+		}
+		if d < 0 {
+			return fmt.Errorf("negative duration %v", d)
+		}
+		*l = append(*l, d)
+	}
+	return nil
+}
+
+// Enum accepts one of a fixed set of strings
+type Enum struct {
+	Allowed []string
+	Value   string
+}
+
+// NewEnum returns an Enum with a default, which must be one of allowed
+func NewEnum(def string, allowed ...string) *Enum {
+	e := &Enum{Allowed: allowed}
+	if err := e.Set(def); err != nil {
+		panic("flagvalue: " + err.Error())
+	}
+	return e
+}
+
+// String returns the current value
+func (e *Enum) String() string {
+	if e == nil {
+		return ""
+	}
+	return e.Value
+}
+
+// Set accepts s if it is allowed. The error text becomes part of the flag
+// package's message, so it lists the choices.
+func (e *Enum) Set(s string) error {
+	for _, a := range e.Allowed {
+		if s == a {
+			e.Value = s
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of %s", strings.Join(e.Allowed, ", "))
+}
@@ -0,0 +1,98 @@
+package flagvalue
+
+import (
+	"flag"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDurationList(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    DurationList
+		wantErr string
+	}{
+		{"single", []string{"-d", "1s"}, DurationList{time.Second}, ""},
+		{"comma separated", []string{"-d", "1s, 250ms"}, DurationList{time.Second, 250 * time.Millisecond}, ""},
+		{"repeated", []string{"-d", "1s", "-d", "2m"}, DurationList{time.Second, 2 * time.Minute}, ""},
+		{"unset", nil, nil, ""},
+		{"invalid", []string{"-d", "later"}, nil, "invalid duration"},
+		{"negative", []string{"-d", "-1s"}, nil, "negative duration"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got DurationList
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.SetOutput(io.Discard)
+			fs.Var(&got, "d", "")
+
+			err := fs.Parse(tc.args)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("Parse error = %v; want it to contain %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse returned error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("DurationList = %v; want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDurationList_StringRoundTrip(t *testing.T) {
+	in := DurationList{time.Second, 1500 * time.Millisecond}
+	var out DurationList
+	if err := out.Set(in.String()); err != nil {
+		t.Fatalf("Set(%q): %v", in.String(), err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("round trip = %v; want %v", out, in)
+	}
+}
+
+func TestEnum(t *testing.T) {
+	e := NewEnum("json", "json", "text")
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.Var(e, "format", "")
+
+	if e.String() != "json" {
+		t.Errorf("default = %q; want json", e.String())
+	}
+	if err := fs.Parse([]string{"-format", "text"}); err != nil || e.Value != "text" {
+		t.Errorf("Parse(-format text) = %v, value %q", err, e.Value)
+	}
+	err := fs.Parse([]string{"-format", "xml"})
+	if err == nil || !strings.Contains(err.Error(), "must be one of json, text") {
+		t.Errorf("Parse(-format xml) error = %v", err)
+	}
+	if e.Value != "text" {
+		t.Errorf("rejected value changed Enum to %q", e.Value)
+	}
+}
+
+func TestNewEnum_PanicsOnInvalidDefault(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("NewEnum with an invalid default did not panic")
+		}
+	}()
+	NewEnum("yaml", "json", "text")
+}
+
+// flag.PrintDefaults calls String on a zero value, so it must not panic
+func TestZeroValuesString(t *testing.T) {
+	var l *DurationList
+	var e *Enum
+	if l.String() != "" || e.String() != "" {
+		t.Error("String on nil receivers returned non-empty values")
+	}
+}
@@ -0,0 +1,193 @@
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/rehan/go-interview-prep/basic-concepts/cli/command"
+	"github.com/rehan/go-interview-prep/basic-concepts/cli/flagvalue"
+	"github.com/rehan/go-interview-prep/pkg/quiz"
+)
+
+// Run parses example command lines with flag sets and subcommands,
+// printing the results to w
+func Run(w io.Writer) error {
+	fmt.Fprintln(w, "=========================================")
+	fmt.Fprintln(w, "GO COMMAND-LINE FLAGS AND SUBCOMMANDS")
+	fmt.Fprintln(w, "=========================================")
+
+	FlagBasicsExample(w)
+	CustomValueExample(w)
+	SubcommandExample(w)
+
+	// Interview questions
+	CLIInterviewQuestions(w)
+	return nil
+}
+
+// serveOptions is what the example "serve" flags parse into
+type serveOptions struct {
+	Addr    string
+	Workers int
+	Verbose bool
+	Args    []string
+}
+
+// parseServeFlags uses its own FlagSet rather than the global flag.CommandLine,
+// so it can be called more than once and returns errors instead of exiting
+func parseServeFlags(args []string, output io.Writer) (serveOptions, error) {
+	var opts serveOptions
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.StringVar(&opts.Addr, "addr", ":8080", "listen address")
+	fs.IntVar(&opts.Workers, "workers", 4, "number of workers")
+	fs.BoolVar(&opts.Verbose, "v", false, "verbose output")
+
+	if err := fs.Parse(args); err != nil {
+		return serveOptions{}, err
+	}
+	opts.Args = fs.Args()
+	return opts, nil
+}
+
+// FlagBasicsExample shows the syntax the flag package accepts
+func FlagBasicsExample(w io.Writer) {
+	fmt.Fprintln(w, "=== FLAG BASICS ===")
+
+	inputs := [][]string{
+		{"-addr", ":9000", "-workers=8", "--v", "file1", "file2"},
+		{"-v", "false", "-workers", "2"}, // bool flags do not take a separate value
+		{"file1", "-v"},                  // parsing stops at the first non-flag
+		{"-v=false", "--", "-not-a-flag"},
+		{"-workers", "many"},
+	}
+	for _, args := range inputs {
+		opts, err := parseServeFlags(args, io.Discard)
+		if err != nil {
+			fmt.Fprintf(w, "%-36s error: %v\n", strings.Join(args, " "), err)
+			continue
+		}
+		fmt.Fprintf(w, "%-36s %+v\n", strings.Join(args, " "), opts)
+	}
+	fmt.Fprintln(w)
+}
+
+// retryOptions shows custom flag.Value types
+type retryOptions struct {
+	Backoff flagvalue.DurationList
+	Mode    *flagvalue.Enum
+}
+
+func parseRetryFlags(args []string, output io.Writer) (retryOptions, error) {
+	opts := retryOptions{Mode: flagvalue.NewEnum("exponential", "fixed", "exponential")}
+	fs := flag.NewFlagSet("retry", flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.Var(&opts.Backoff, "backoff", "comma-separated delays; may be repeated")
+	fs.Var(opts.Mode, "mode", "fixed or exponential")
+
+	if err := fs.Parse(args); err != nil {
+		return retryOptions{}, err
+	}
+	if len(opts.Backoff) == 0 {
+		opts.Backoff = flagvalue.DurationList{time.Second}
+	}
+	return opts, nil
+}
+
+// CustomValueExample shows flag.Var with DurationList and Enum
+func CustomValueExample(w io.Writer) {
+	fmt.Fprintln(w, "=== CUSTOM flag.Value TYPES ===")
+
+	inputs := [][]string{
+		{"-backoff", "100ms,1s", "-backoff", "5s"},
+		{"-mode", "fixed"},
+		{"-mode", "random"},
+		{"-backoff", "soon"},
+	}
+	for _, args := range inputs {
+		opts, err := parseRetryFlags(args, io.Discard)
+		if err != nil {
+			fmt.Fprintf(w, "%-32s error: %v\n", strings.Join(args, " "), err)
+			continue
+		}
+		fmt.Fprintf(w, "%-32s backoff=%v mode=%v\n", strings.Join(args, " "), opts.Backoff.String(), opts.Mode)
+	}
+	fmt.Fprintln(w)
+}
+
+// newApp builds the example program's subcommands
+func newApp() *command.Dispatcher {
+	return command.New("app",
+		&command.Command{
+			Name:    "greet",
+			Summary: "print a greeting",
+			Help:    "usage: app greet [-shout] <name>\n",
+			Run: func(args []string, stdout, stderr io.Writer) int {
+				fs := flag.NewFlagSet("greet", flag.ContinueOnError)
+				fs.SetOutput(stderr)
+				shout := fs.Bool("shout", false, "use capitals")
+				if err := fs.Parse(args); err != nil {
+					return command.ExitUsage
+				}
+				if fs.NArg() != 1 {
+					fmt.Fprintln(stderr, "usage: app greet [-shout] <name>")
+					return command.ExitUsage
+				}
+				msg := "hello, " + fs.Arg(0)
+				if *shout {
+					msg = strings.ToUpper(msg)
+				}
+				fmt.Fprintln(stdout, msg)
+				return command.ExitOK
+			},
+		},
+		&command.Command{
+			Name:    "retry",
+			Summary: "show a retry schedule",
+			Run: func(args []string, stdout, stderr io.Writer) int {
+				opts, err := parseRetryFlags(args, stderr)
+				if err != nil {
+					return command.ExitUsage
+				}
+				fmt.Fprintf(stdout, "%s backoff: %s\n", opts.Mode, opts.Backoff.String())
+				return command.ExitOK
+			},
+		},
+	)
+}
+
+// SubcommandExample drives the dispatcher with several argument lists
+func SubcommandExample(w io.Writer) {
+	fmt.Fprintln(w, "=== SUBCOMMAND DISPATCHER ===")
+
+	app := newApp()
+	inputs := [][]string{
+		{"greet", "ada"},
+		{"greet", "-shout", "ada"},
+		{"retry", "-mode", "fixed", "-backoff", "1s,2s"},
+		{"help", "greet"},
+		{"deploy"},
+		{},
+	}
+	for _, args := range inputs {
+		var stdout, stderr bytes.Buffer
+		code := app.Run(args, &stdout, &stderr)
+		out := strings.TrimSpace(stdout.String() + stderr.String())
+		first, _, _ := strings.Cut(out, "\n")
+		fmt.Fprintf(w, "app %-36s exit=%d  %s\n", strings.Join(args, " "), code, first)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprint(w, app.Usage())
+	fmt.Fprintln(w)
+}
+
+// CLIInterviewQuestions lists common interview questions about CLIs
+func CLIInterviewQuestions(w io.Writer) {
+	if err := quiz.Print(w, "cli"); err != nil {
+		fmt.Fprintln(w, err)
+	}
+}
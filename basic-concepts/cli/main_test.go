@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/rehan/go-interview-prep/basic-concepts/cli/flagvalue"
+)
+
+func TestParseServeFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    serveOptions
+		wantErr bool
+	}{
+		{"defaults", nil, serveOptions{Addr: ":8080", Workers: 4}, false},
+		{"all forms", []string{"-addr", ":1", "--workers=2", "-v", "x"}, serveOptions{Addr: ":1", Workers: 2, Verbose: true, Args: []string{"x"}}, false},
+		{"bool takes no separate value", []string{"-v", "false"}, serveOptions{Addr: ":8080", Workers: 4, Verbose: true, Args: []string{"false"}}, false},
+		{"stops at first argument", []string{"x", "-v"}, serveOptions{Addr: ":8080", Workers: 4, Args: []string{"x", "-v"}}, false},
+		{"double dash", []string{"--", "-v"}, serveOptions{Addr: ":8080", Workers: 4, Args: []string{"-v"}}, false},
+		{"bad int", []string{"-workers", "x"}, serveOptions{}, true},
+		{"unknown flag", []string{"-port", "1"}, serveOptions{}, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseServeFlags(tc.args, io.Discard)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("error = %v; want error %v", err, tc.wantErr)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("parseServeFlags(%q) = %+v; want %+v", tc.args, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseRetryFlags_Defaults(t *testing.T) {
+	got, err := parseRetryFlags(nil, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Backoff, flagvalue.DurationList{time.Second}) || got.Mode.Value != "exponential" {
+		t.Errorf("defaults = %v, %v", got.Backoff, got.Mode)
+	}
+}
+
+func TestApp(t *testing.T) {
+	tests := []struct {
+		args       []string
+		wantCode   int
+		wantStdout string
+	}{
+		{[]string{"greet", "ada"}, 0, "hello, ada\n"},
+		{[]string{"greet", "-shout", "ada"}, 0, "HELLO, ADA\n"},
+		{[]string{"greet"}, 2, ""},
+		{[]string{"retry", "-backoff", "1s,2s"}, 0, "exponential backoff: 1s,2s\n"},
+		{[]string{"retry", "-mode", "random"}, 2, ""},
+	}
+	for _, tc := range tests {
+		var stdout, stderr bytes.Buffer
+		code := newApp().Run(tc.args, &stdout, &stderr)
+		if code != tc.wantCode || stdout.String() != tc.wantStdout {
+			t.Errorf("app %q = %d, %q; want %d, %q (stderr %q)", tc.args, code, stdout.String(), tc.wantCode, tc.wantStdout, stderr.String())
+		}
+	}
+}
@@ -0,0 +1,28 @@
+//go:build go1.21
+
+// The go1.21 build constraint above downgrades this file's language version
+// to Go 1.21, so its loops use the OLD semantics: one variable shared by
+// every iteration. The rest of the package uses Go 1.22+ semantics.
+
+package closures
+
+// LegacyLoopCapture collects closures that print the loop variable, using
+// pre-Go 1.22 loop semantics. Every closure sees the final value.
+func LegacyLoopCapture(n int) []int {
+	var funcs []func() int
+	for i := 0; i < n; i++ {
+		funcs = append(funcs, func() int { return i })
+	}
+	return callAll(funcs)
+}
+
+// LegacyLoopCaptureFixed is the classic pre-1.22 fix: shadow the loop
+// variable with a per-iteration copy
+func LegacyLoopCaptureFixed(n int) []int {
+	var funcs []func() int
+	for i := 0; i < n; i++ {
+		i := i // new variable for each iteration
+		funcs = append(funcs, func() int { return i })
+	}
+	return callAll(funcs)
+}
@@ -0,0 +1,214 @@
+package closures
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/rehan/go-interview-prep/pkg/quiz"
+)
+
+// Run prints the closure and loop-variable examples to w
+func Run(w io.Writer) error {
+	fmt.Fprintln(w, "=========================================")
+	fmt.Fprintln(w, "GO CLOSURES AND SCOPING EXAMPLES")
+	fmt.Fprintln(w, "=========================================")
+
+	LoopCaptureExample(w)
+	CaptureByReferenceExample(w)
+	MemoizationExample(w)
+
+	// Interview questions
+	ClosuresInterviewQuestions(w)
+	return nil
+}
+
+// callAll calls every function and collects the results
+func callAll(funcs []func() int) []int {
+	results := make([]int, len(funcs))
+	for i, f := range funcs {
+		results[i] = f()
+	}
+	return results
+}
+
+// LOOP VARIABLE CAPTURE
+
+// LoopCapture collects closures over the loop variable. Since Go 1.22 each
+// iteration has its own i, so the closures see 0, 1, 2, ...
+// Compare LegacyLoopCapture in loopvar_legacy.go.
+func LoopCapture(n int) []int {
+	var funcs []func() int
+	for i := 0; i < n; i++ {
+		funcs = append(funcs, func() int { return i })
+	}
+	return callAll(funcs)
+}
+
+// SharedVariableCapture declares the variable OUTSIDE the loop, so even with
+// Go 1.22 semantics all closures share it and see the final value.
+// This is the bug that still exists in current Go.
+func SharedVariableCapture(n int) []int {
+	var funcs []func() int
+	var i int
+	for i = 0; i < n; i++ {
+		funcs = append(funcs, func() int { return i })
+	}
+	return callAll(funcs)
+}
+
+// GoroutineLoopCapture starts a goroutine per item. With per-iteration
+// variables each goroutine sees its own item; results are sorted because
+// goroutines finish in any order.
+func GoroutineLoopCapture(items []string) []string {
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		seen []string
+	)
+	for _, item := range items {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			mu.Lock()
+			seen = append(seen, item)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	sort.Strings(seen)
+	return seen
+}
+
+// CAPTURE BY REFERENCE
+
+// CaptureByReference shows that a closure captures the variable, not its
+// value: changes made after the closure is created are visible to it, and
+// changes made by the closure are visible outside
+func CaptureByReference() (seenByClosure, seenOutside int) {
+	x := 1
+	read := func() int { return x }
+	incr := func() { x++ }
+
+	x = 10
+	incr()
+	return read(), x
+}
+
+// CaptureByValue passes the value as an argument, which copies it, so
+// later changes are not visible to the closure
+func CaptureByValue() int {
+	x := 1
+	read := func(v int) func() int {
+		return func() int { return v }
+	}(x)
+	x = 10
+	return read()
+}
+
+// NewCounterPair returns two closures that share one counter, the way
+// methods on a struct would share a field
+func NewCounterPair() (increment func() int, get func() int) {
+	count := 0
+	increment = func() int {
+		count++
+		return count
+	}
+	get = func() int { return count }
+	return increment, get
+}
+
+// MEMOIZATION
+
+// Memoize wraps fn with a cache held in the closure. The returned function
+// is safe for concurrent use. The second result reports how many times fn
+// actually ran.
+func Memoize[K comparable, V any](fn func(K) V) (memoized func(K) V, calls func() int) {
+	var (
+		mu    sync.Mutex
+		cache = make(map[K]V)
+		count int
+	)
+	memoized = func(key K) V {
+		mu.Lock()
+		defer mu.Unlock()
+		if v, ok := cache[key]; ok {
+			return v
+		}
+		count++
+		v := fn(key)
+		cache[key] = v
+		return v
+	}
+	calls = func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return count
+	}
+	return memoized, calls
+}
+
+// Fibonacci returns a memoized Fibonacci function. The closure refers to
+// itself through the fib variable, which is declared before it is assigned.
+// It holds no lock while recursing, unlike Memoize.
+func Fibonacci() func(int) int {
+	cache := map[int]int{0: 0, 1: 1}
+	var fib func(int) int
+	fib = func(n int) int {
+		if v, ok := cache[n]; ok {
+			return v
+		}
+		v := fib(n-1) + fib(n-2)
+		cache[n] = v
+		return v
+	}
+	return fib
+}
+
+// LoopCaptureExample compares loop variable semantics
+func LoopCaptureExample(w io.Writer) {
+	fmt.Fprintln(w, "=== LOOP VARIABLE CAPTURE EXAMPLE ===")
+
+	fmt.Fprintln(w, "Go 1.22+ per-iteration variable:", LoopCapture(3))
+	fmt.Fprintln(w, "Pre-1.22 shared variable:       ", LegacyLoopCapture(3))
+	fmt.Fprintln(w, "Pre-1.22 with i := i fix:       ", LegacyLoopCaptureFixed(3))
+	fmt.Fprintln(w, "Variable declared outside loop: ", SharedVariableCapture(3))
+	fmt.Fprintln(w, "Goroutines per item:            ", GoroutineLoopCapture([]string{"a", "b", "c"}))
+	fmt.Fprintln(w)
+}
+
+// CaptureByReferenceExample shows closures sharing variables
+func CaptureByReferenceExample(w io.Writer) {
+	fmt.Fprintln(w, "=== CAPTURE BY REFERENCE EXAMPLE ===")
+
+	seenByClosure, seenOutside := CaptureByReference()
+	fmt.Fprintf(w, "Closure sees %d, outside sees %d\n", seenByClosure, seenOutside)
+	fmt.Fprintln(w, "Value passed as argument:", CaptureByValue())
+
+	increment, get := NewCounterPair()
+	increment()
+	increment()
+	fmt.Fprintln(w, "Shared counter after two increments:", get())
+	fmt.Fprintln(w)
+}
+
+// MemoizationExample caches an expensive function
+func MemoizationExample(w io.Writer) {
+	fmt.Fprintln(w, "=== MEMOIZATION EXAMPLE ===")
+
+	square, calls := Memoize(func(n int) int { return n * n })
+	for _, n := range []int{4, 4, 5, 4} {
+		fmt.Fprintf(w, "square(%d) = %d\n", n, square(n))
+	}
+	fmt.Fprintln(w, "Underlying function calls:", calls())
+	fmt.Fprintln(w, "fib(80) =", Fibonacci()(80))
+	fmt.Fprintln(w)
+}
+
+// ClosuresInterviewQuestions lists common interview questions about closures
+func ClosuresInterviewQuestions(w io.Writer) {
+	if err := quiz.Print(w, "closures"); err != nil {
+		fmt.Fprintln(w, err)
+	}
+}
@@ -0,0 +1,101 @@
+package closures
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestLoopCaptureSemantics(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(int) []int
+		want []int
+	}{
+		{"Go 1.22+ per-iteration", LoopCapture, []int{0, 1, 2}},
+		{"pre-1.22 shared variable", LegacyLoopCapture, []int{3, 3, 3}},
+		{"pre-1.22 with shadow fix", LegacyLoopCaptureFixed, []int{0, 1, 2}},
+		{"variable declared outside loop", SharedVariableCapture, []int{3, 3, 3}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.fn(3); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %v; want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGoroutineLoopCapture(t *testing.T) {
+	items := []string{"c", "a", "b"}
+	if got, want := GoroutineLoopCapture(items), []string{"a", "b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GoroutineLoopCapture() = %v; want %v", got, want)
+	}
+}
+
+func TestCaptureByReference(t *testing.T) {
+	seenByClosure, seenOutside := CaptureByReference()
+	if seenByClosure != 11 || seenOutside != 11 {
+		t.Errorf("CaptureByReference() = %d, %d; want 11, 11", seenByClosure, seenOutside)
+	}
+	if got := CaptureByValue(); got != 1 {
+		t.Errorf("CaptureByValue() = %d; want 1", got)
+	}
+}
+
+func TestNewCounterPair(t *testing.T) {
+	increment, get := NewCounterPair()
+	increment()
+	increment()
+	if got := get(); got != 2 {
+		t.Errorf("get() = %d; want 2", got)
+	}
+
+	// Each call creates an independent counter
+	_, otherGet := NewCounterPair()
+	if got := otherGet(); got != 0 {
+		t.Errorf("new pair get() = %d; want 0", got)
+	}
+}
+
+func TestMemoize(t *testing.T) {
+	square, calls := Memoize(func(n int) int { return n * n })
+	for _, n := range []int{3, 3, 4, 3, 4} {
+		if got := square(n); got != n*n {
+			t.Errorf("square(%d) = %d; want %d", n, got, n*n)
+		}
+	}
+	if got := calls(); got != 2 {
+		t.Errorf("underlying calls = %d; want 2", got)
+	}
+}
+
+func TestMemoize_Concurrent(t *testing.T) {
+	length, calls := Memoize(func(s string) int { return len(s) })
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got := length("gopher"); got != 6 {
+				t.Errorf("length() = %d; want 6", got)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := calls(); got != 1 {
+		t.Errorf("underlying calls = %d; want 1", got)
+	}
+}
+
+func TestFibonacci(t *testing.T) {
+	fib := Fibonacci()
+	tests := []struct{ n, want int }{{0, 0}, {1, 1}, {10, 55}, {50, 12586269025}}
+	for _, tc := range tests {
+		if got := fib(tc.n); got != tc.want {
+			t.Errorf("fib(%d) = %d; want %d", tc.n, got, tc.want)
+		}
+	}
+}
@@ -1,182 +1,185 @@
-package main
+package controlflow
 
 import (
 	"fmt"
+	"io"
 	"time"
 )
 
-func main() {
-	fmt.Println("=== CONTROL FLOW ===")
+// Run prints the if, for and switch examples to w
+func Run(w io.Writer) error {
+	fmt.Fprintln(w, "=== CONTROL FLOW ===")
 
 	// IF statements
-	fmt.Println("\n--- IF Statements ---")
+	fmt.Fprintln(w, "\n--- IF Statements ---")
 
 	age := 20
 	if age >= 18 {
-		fmt.Println("You are an adult")
+		fmt.Fprintln(w, "You are an adult")
 	} else {
-		fmt.Println("You are a minor")
+		fmt.Fprintln(w, "You are a minor")
 	}
 
 	// If with short statement
 	if score := 85; score >= 90 {
-		fmt.Println("Grade: A")
+		fmt.Fprintln(w, "Grade: A")
 	} else if score >= 80 {
-		fmt.Println("Grade: B")
+		fmt.Fprintln(w, "Grade: B")
 	} else if score >= 70 {
-		fmt.Println("Grade: C")
+		fmt.Fprintln(w, "Grade: C")
 	} else {
-		fmt.Println("Grade: D or below")
+		fmt.Fprintln(w, "Grade: D or below")
 	}
 
 	// FOR loops
-	fmt.Println("\n--- FOR Loops ---")
+	fmt.Fprintln(w, "\n--- FOR Loops ---")
 
 	// Standard for loop
-	fmt.Println("Standard for loop:")
+	fmt.Fprintln(w, "Standard for loop:")
 	for i := 1; i <= 5; i++ {
-		fmt.Printf("%d ", i)
+		fmt.Fprintf(w, "%d ", i)
 	}
-	fmt.Println()
+	fmt.Fprintln(w)
 
 	// While-like for loop
-	fmt.Println("\nWhile-like for loop:")
+	fmt.Fprintln(w, "\nWhile-like for loop:")
 	counter := 1
 	for counter <= 5 {
-		fmt.Printf("%d ", counter)
+		fmt.Fprintf(w, "%d ", counter)
 		counter++
 	}
-	fmt.Println()
+	fmt.Fprintln(w)
 
 	// Infinite loop with break
-	fmt.Println("\nInfinite loop with break:")
+	fmt.Fprintln(w, "\nInfinite loop with break:")
 	count := 1
 	for {
-		fmt.Printf("%d ", count)
+		fmt.Fprintf(w, "%d ", count)
 		count++
 		if count > 5 {
 			break
 		}
 	}
-	fmt.Println()
+	fmt.Fprintln(w)
 
 	// For loop with continue
-	fmt.Println("\nFor loop with continue (skipping even numbers):")
+	fmt.Fprintln(w, "\nFor loop with continue (skipping even numbers):")
 	for i := 1; i <= 10; i++ {
 		if i%2 == 0 {
 			continue
 		}
-		fmt.Printf("%d ", i)
+		fmt.Fprintf(w, "%d ", i)
 	}
-	fmt.Println()
+	fmt.Fprintln(w)
 
 	// For-range over array
-	fmt.Println("\nFor-range over array:")
+	fmt.Fprintln(w, "\nFor-range over array:")
 	numbers := [5]int{1, 2, 3, 4, 5}
 	for index, value := range numbers {
-		fmt.Printf("numbers[%d] = %d\n", index, value)
+		fmt.Fprintf(w, "numbers[%d] = %d\n", index, value)
 	}
 
 	// For-range over string (iterates over runes)
-	fmt.Println("\nFor-range over string:")
+	fmt.Fprintln(w, "\nFor-range over string:")
 	for index, char := range "Hello" {
-		fmt.Printf("[%d]: %c\n", index, char)
+		fmt.Fprintf(w, "[%d]: %c\n", index, char)
 	}
 
 	// SWITCH statements
-	fmt.Println("\n--- SWITCH Statements ---")
+	fmt.Fprintln(w, "\n--- SWITCH Statements ---")
 
 	// Basic switch
-	fmt.Println("Basic switch:")
+	fmt.Fprintln(w, "Basic switch:")
 	day := 3
 	switch day {
 	case 1:
-		fmt.Println("Monday")
+		fmt.Fprintln(w, "Monday")
 	case 2:
-		fmt.Println("Tuesday")
+		fmt.Fprintln(w, "Tuesday")
 	case 3:
-		fmt.Println("Wednesday")
+		fmt.Fprintln(w, "Wednesday")
 	case 4:
-		fmt.Println("Thursday")
+		fmt.Fprintln(w, "Thursday")
 	case 5:
-		fmt.Println("Friday")
+		fmt.Fprintln(w, "Friday")
 	default:
-		fmt.Println("Weekend")
+		fmt.Fprintln(w, "Weekend")
 	}
 
 	// Switch with multiple cases
-	fmt.Println("\nSwitch with multiple cases:")
+	fmt.Fprintln(w, "\nSwitch with multiple cases:")
 	fruit := "apple"
 	switch fruit {
 	case "apple", "pear", "banana":
-		fmt.Println("Common fruit")
+		fmt.Fprintln(w, "Common fruit")
 	case "dragonfruit", "starfruit":
-		fmt.Println("Exotic fruit")
+		fmt.Fprintln(w, "Exotic fruit")
 	default:
-		fmt.Println("Unknown fruit")
+		fmt.Fprintln(w, "Unknown fruit")
 	}
 
 	// Switch with no expression (like if-else chain)
-	fmt.Println("\nSwitch with no expression:")
+	fmt.Fprintln(w, "\nSwitch with no expression:")
 	temperature := 75
 	switch {
 	case temperature < 32:
-		fmt.Println("Freezing")
+		fmt.Fprintln(w, "Freezing")
 	case temperature < 50:
-		fmt.Println("Cold")
+		fmt.Fprintln(w, "Cold")
 	case temperature < 70:
-		fmt.Println("Cool")
+		fmt.Fprintln(w, "Cool")
 	case temperature < 90:
-		fmt.Println("Warm")
+		fmt.Fprintln(w, "Warm")
 	default:
-		fmt.Println("Hot")
+		fmt.Fprintln(w, "Hot")
 	}
 
 	// Switch with fallthrough
-	fmt.Println("\nSwitch with fallthrough:")
+	fmt.Fprintln(w, "\nSwitch with fallthrough:")
 	num := 5
 	switch num {
 	case 5:
-		fmt.Println("Five")
+		fmt.Fprintln(w, "Five")
 		fallthrough
 	case 4:
-		fmt.Println("Four")
+		fmt.Fprintln(w, "Four")
 		fallthrough
 	case 3:
-		fmt.Println("Three")
+		fmt.Fprintln(w, "Three")
 	default:
-		fmt.Println("Unknown")
+		fmt.Fprintln(w, "Unknown")
 	}
 
 	// DEFER Statement
-	fmt.Println("\n--- DEFER Statement ---")
+	fmt.Fprintln(w, "\n--- DEFER Statement ---")
 
 	// Basic defer
-	fmt.Println("Basic defer example:")
-	defer fmt.Println("This prints last")
-	fmt.Println("This prints first")
-	fmt.Println("This prints second")
+	fmt.Fprintln(w, "Basic defer example:")
+	defer fmt.Fprintln(w, "This prints last")
+	fmt.Fprintln(w, "This prints first")
+	fmt.Fprintln(w, "This prints second")
 
 	// Multiple defers (LIFO order)
-	fmt.Println("\nMultiple defers (LIFO order):")
+	fmt.Fprintln(w, "\nMultiple defers (LIFO order):")
 	for i := 1; i <= 3; i++ {
-		defer fmt.Printf("Deferred %d\n", i)
+		defer fmt.Fprintf(w, "Deferred %d\n", i)
 	}
 
 	// Defer with function call
-	fmt.Println("\nDefer with function call:")
-	defer printTime("End time")
-	printTime("Start time")
+	fmt.Fprintln(w, "\nDefer with function call:")
+	defer printTime(w, "End time")
+	printTime(w, "Start time")
 
 	// Defer with arguments evaluated at defer time
 	a := 10
-	defer fmt.Printf("\nDeferred value of a: %d\n", a)
+	defer fmt.Fprintf(w, "\nDeferred value of a: %d\n", a)
 	a = 20
-	fmt.Printf("Current value of a: %d\n", a)
+	fmt.Fprintf(w, "Current value of a: %d\n", a)
+	return nil
 }
 
-func printTime(label string) {
-	fmt.Printf("%s: %s\n", label, time.Now().Format(time.RFC3339))
+func printTime(w io.Writer, label string) {
+	fmt.Fprintf(w, "%s: %s\n", label, time.Now().Format(time.RFC3339))
 }
 
 /*
@@ -0,0 +1,296 @@
+package deferpanicrecover
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/debug"
+	"sync"
+
+	"github.com/rehan/go-interview-prep/pkg/quiz"
+)
+
+// Run prints the defer ordering and panic recovery examples to w
+func Run(w io.Writer) error {
+	fmt.Fprintln(w, "=========================================")
+	fmt.Fprintln(w, "GO DEFER, PANIC AND RECOVER EXAMPLES")
+	fmt.Fprintln(w, "=========================================")
+
+	DeferEvaluationExample(w)
+	DeferInLoopExample(w)
+	NamedReturnExample(w)
+	RecoverRulesExample(w)
+	SafeGoroutineExample(w)
+
+	// Interview questions
+	DeferPanicRecoverInterviewQuestions(w)
+	return nil
+}
+
+// DEFER EVALUATION TIMING
+
+// DeferArgumentTiming returns the values seen by two deferred calls.
+// The argument of a deferred call is evaluated when the defer statement
+// runs; a deferred closure reads the variable when it finally executes.
+func DeferArgumentTiming() (atDefer, atReturn int) {
+	x := 1
+	func() {
+		defer func(v int) { atDefer = v }(x) // x is copied now: 1
+		defer func() { atReturn = x }()      // x is read later: 3
+		x = 2
+		x = 3
+	}()
+	return atDefer, atReturn
+}
+
+// DeferOrder records the order deferred calls run in: last in, first out
+func DeferOrder(n int) []int {
+	var order []int
+	func() {
+		for i := 0; i < n; i++ {
+			defer func() { order = append(order, i) }()
+		}
+	}()
+	return order
+}
+
+// DEFER IN A LOOP
+
+// resource simulates something that must be released, like a file
+type resource struct{ pool *resourcePool }
+
+func (r *resource) Close() { r.pool.release() }
+
+// resourcePool counts how many resources are open at once
+type resourcePool struct {
+	open, maxOpen int
+}
+
+func (p *resourcePool) acquire() *resource {
+	p.open++
+	p.maxOpen = max(p.maxOpen, p.open)
+	return &resource{pool: p}
+}
+
+func (p *resourcePool) release() { p.open-- }
+
+// ProcessWithDeferInLoop is the pitfall: defers run when the function
+// returns, not at the end of each iteration, so every resource stays open
+// until the loop finishes. It returns the peak number of open resources.
+func ProcessWithDeferInLoop(n int) int {
+	pool := &resourcePool{}
+	func() {
+		for i := 0; i < n; i++ {
+			r := pool.acquire()
+			defer r.Close()
+		}
+	}()
+	return pool.maxOpen
+}
+
+// ProcessWithHelper fixes the pitfall by moving the body into a function,
+// so each defer runs at the end of its own iteration
+func ProcessWithHelper(n int) int {
+	pool := &resourcePool{}
+	for i := 0; i < n; i++ {
+		func() {
+			r := pool.acquire()
+			defer r.Close()
+		}()
+	}
+	return pool.maxOpen
+}
+
+// NAMED RESULTS
+
+// DoubleOnReturn shows a deferred closure changing a named result after
+// the return statement has set it
+func DoubleOnReturn(n int) (result int) {
+	defer func() { result *= 2 }()
+	return n // result = n, then the defer doubles it
+}
+
+// UnnamedNotModified shows that without a named result the defer only
+// changes a local copy
+func UnnamedNotModified(n int) int {
+	result := n
+	defer func() { result *= 2 }()
+	return result // the return value is already fixed
+}
+
+// closeWithError is the common pattern: report a Close error only if the
+// function had not already failed
+func closeWithError(closeErr error, work func() error) (err error) {
+	defer func() {
+		if closeErr != nil && err == nil {
+			err = fmt.Errorf("close: %w", closeErr)
+		}
+	}()
+	return work()
+}
+
+// RECOVER RULES
+
+// PanicError is a recovered panic turned into an error
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap exposes the panic value if it was an error, so errors.As can find
+// e.g. a runtime.Error from an index out of range
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// Try runs fn and converts a panic into a *PanicError. recover only stops
+// a panic when called directly by a deferred function, as it is here.
+func Try(fn func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	fn()
+	return nil
+}
+
+// recoverIndirectly calls recover from a helper rather than from the
+// deferred function itself, which does NOT stop the panic
+func recoverIndirectly() any {
+	return recover()
+}
+
+// RecoverFromHelper reports whether calling recover through a helper
+// stopped the panic. It always returns false: the panic escapes and is
+// caught by the outer Try.
+func RecoverFromHelper() (stopped bool) {
+	err := Try(func() {
+		defer func() { recoverIndirectly() }()
+		panic("boom")
+	})
+	return err == nil
+}
+
+// RepanicAfterCleanup shows recovering only to clean up, then panicking
+// again so the failure is not hidden
+func RepanicAfterCleanup(cleanedUp *bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			*cleanedUp = true
+			panic(r)
+		}
+	}()
+	panic("fatal")
+}
+
+// GOROUTINES
+
+// Go runs fn in a new goroutine and delivers its error, or its panic as a
+// *PanicError, on the returned channel. A panic in a goroutine cannot be
+// recovered by the goroutine that started it and crashes the whole
+// program, so every goroutine that may panic needs its own recover.
+func Go(fn func() error) <-chan error {
+	errc := make(chan error, 1)
+	go func() {
+		var err error
+		defer func() {
+			if r := recover(); r != nil {
+				err = &PanicError{Value: r, Stack: debug.Stack()}
+			}
+			errc <- err
+		}()
+		err = fn()
+	}()
+	return errc
+}
+
+// RunAll runs every task concurrently with Go and joins their errors
+func RunAll(tasks ...func() error) error {
+	chans := make([]<-chan error, len(tasks))
+	for i, task := range tasks {
+		chans[i] = Go(task)
+	}
+	var errs []error
+	for _, errc := range chans {
+		errs = append(errs, <-errc)
+	}
+	return errors.Join(errs...)
+}
+
+// DeferEvaluationExample shows when deferred arguments are evaluated
+func DeferEvaluationExample(w io.Writer) {
+	fmt.Fprintln(w, "=== DEFER EVALUATION TIMING EXAMPLE ===")
+
+	atDefer, atReturn := DeferArgumentTiming()
+	fmt.Fprintf(w, "Argument evaluated at defer: %d, closure read at return: %d\n", atDefer, atReturn)
+	fmt.Fprintln(w, "Defer order (LIFO):", DeferOrder(4))
+	fmt.Fprintln(w)
+}
+
+// DeferInLoopExample compares peak open resources
+func DeferInLoopExample(w io.Writer) {
+	fmt.Fprintln(w, "=== DEFER IN A LOOP EXAMPLE ===")
+
+	fmt.Fprintln(w, "Peak open with defer in loop:", ProcessWithDeferInLoop(100))
+	fmt.Fprintln(w, "Peak open with helper function:", ProcessWithHelper(100))
+	fmt.Fprintln(w)
+}
+
+// NamedReturnExample modifies results in deferred functions
+func NamedReturnExample(w io.Writer) {
+	fmt.Fprintln(w, "=== NAMED RETURNS AND DEFER EXAMPLE ===")
+
+	fmt.Fprintln(w, "DoubleOnReturn(5):", DoubleOnReturn(5))
+	fmt.Fprintln(w, "UnnamedNotModified(5):", UnnamedNotModified(5))
+	fmt.Fprintln(w, "Close error surfaced:", closeWithError(errors.New("disk full"), func() error { return nil }))
+	fmt.Fprintln(w)
+}
+
+// RecoverRulesExample shows what recover can and cannot do
+func RecoverRulesExample(w io.Writer) {
+	fmt.Fprintln(w, "=== RECOVER RULES EXAMPLE ===")
+
+	err := Try(func() {
+		var s []int
+		_ = s[3]
+	})
+	fmt.Fprintln(w, "Recovered:", err)
+	var rtErr runtime.Error
+	fmt.Fprintln(w, "Is a runtime.Error:", errors.As(err, &rtErr))
+
+	fmt.Fprintln(w, "recover via helper stopped the panic:", RecoverFromHelper())
+
+	err = Try(func() { panic(nil) })
+	var nilErr *runtime.PanicNilError
+	fmt.Fprintln(w, "panic(nil) is recoverable as *runtime.PanicNilError:", errors.As(err, &nilErr))
+	fmt.Fprintln(w)
+}
+
+// SafeGoroutineExample converts goroutine panics to errors
+func SafeGoroutineExample(w io.Writer) {
+	fmt.Fprintln(w, "=== PANIC-SAFE GOROUTINES EXAMPLE ===")
+
+	var mu sync.Mutex
+	done := 0
+	err := RunAll(
+		func() error { mu.Lock(); done++; mu.Unlock(); return nil },
+		func() error { return errors.New("task failed") },
+		func() error { panic("task exploded") },
+	)
+	fmt.Fprintf(w, "Completed: %d, errors:\n%v\n", done, err)
+	fmt.Fprintln(w)
+}
+
+// DeferPanicRecoverInterviewQuestions lists common interview questions
+func DeferPanicRecoverInterviewQuestions(w io.Writer) {
+	if err := quiz.Print(w, "defer-panic-recover"); err != nil {
+		fmt.Fprintln(w, err)
+	}
+}
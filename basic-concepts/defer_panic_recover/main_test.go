@@ -0,0 +1,153 @@
+package deferpanicrecover
+
+import (
+	"errors"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestDeferArgumentTiming(t *testing.T) {
+	atDefer, atReturn := DeferArgumentTiming()
+	if atDefer != 1 || atReturn != 3 {
+		t.Errorf("DeferArgumentTiming() = %d, %d; want 1, 3", atDefer, atReturn)
+	}
+}
+
+func TestDeferOrder(t *testing.T) {
+	if got, want := DeferOrder(4), []int{3, 2, 1, 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DeferOrder(4) = %v; want %v", got, want)
+	}
+}
+
+func TestDeferInLoop(t *testing.T) {
+	if got := ProcessWithDeferInLoop(50); got != 50 {
+		t.Errorf("ProcessWithDeferInLoop peak = %d; want 50", got)
+	}
+	if got := ProcessWithHelper(50); got != 1 {
+		t.Errorf("ProcessWithHelper peak = %d; want 1", got)
+	}
+}
+
+func TestNamedReturns(t *testing.T) {
+	if got := DoubleOnReturn(5); got != 10 {
+		t.Errorf("DoubleOnReturn(5) = %d; want 10", got)
+	}
+	if got := UnnamedNotModified(5); got != 5 {
+		t.Errorf("UnnamedNotModified(5) = %d; want 5", got)
+	}
+}
+
+func TestCloseWithError(t *testing.T) {
+	closeErr := errors.New("close failed")
+	workErr := errors.New("work failed")
+
+	tests := []struct {
+		name     string
+		closeErr error
+		workErr  error
+		want     error
+	}{
+		{"both succeed", nil, nil, nil},
+		{"close error surfaces", closeErr, nil, closeErr},
+		{"work error wins", closeErr, workErr, workErr},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := closeWithError(tc.closeErr, func() error { return tc.workErr })
+			if !errors.Is(err, tc.want) || (tc.want == nil && err != nil) {
+				t.Errorf("closeWithError() = %v; want %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestTry(t *testing.T) {
+	if err := Try(func() {}); err != nil {
+		t.Errorf("Try(no panic) = %v; want nil", err)
+	}
+
+	err := Try(func() { panic("boom") })
+	var pe *PanicError
+	if !errors.As(err, &pe) || pe.Value != "boom" {
+		t.Fatalf("Try(panic) = %v; want *PanicError with value boom", err)
+	}
+	if !strings.Contains(string(pe.Stack), "TestTry") {
+		t.Error("stack trace does not include the panicking test")
+	}
+}
+
+func TestTry_RuntimeError(t *testing.T) {
+	err := Try(func() {
+		var m map[string]int
+		m["x"] = 1 // assignment to nil map
+	})
+	var rtErr runtime.Error
+	if !errors.As(err, &rtErr) {
+		t.Errorf("err = %v; want a runtime.Error through Unwrap", err)
+	}
+}
+
+func TestTry_PanicNil(t *testing.T) {
+	err := Try(func() { panic(nil) })
+	var nilErr *runtime.PanicNilError
+	if !errors.As(err, &nilErr) {
+		t.Errorf("err = %v; want *runtime.PanicNilError", err)
+	}
+}
+
+func TestRecoverFromHelper(t *testing.T) {
+	if RecoverFromHelper() {
+		t.Error("recover called from a helper stopped the panic; it should not")
+	}
+}
+
+func TestRepanicAfterCleanup(t *testing.T) {
+	cleanedUp := false
+	err := Try(func() { RepanicAfterCleanup(&cleanedUp) })
+	if !cleanedUp {
+		t.Error("cleanup did not run")
+	}
+	var pe *PanicError
+	if !errors.As(err, &pe) || pe.Value != "fatal" {
+		t.Errorf("err = %v; want the original panic to propagate", err)
+	}
+}
+
+func TestGo(t *testing.T) {
+	if err := <-Go(func() error { return nil }); err != nil {
+		t.Errorf("Go(success) = %v; want nil", err)
+	}
+
+	want := errors.New("failed")
+	if err := <-Go(func() error { return want }); !errors.Is(err, want) {
+		t.Errorf("Go(error) = %v; want %v", err, want)
+	}
+
+	var pe *PanicError
+	if err := <-Go(func() error { panic("in goroutine") }); !errors.As(err, &pe) {
+		t.Errorf("Go(panic) = %v; want *PanicError", err)
+	}
+}
+
+func TestRunAll(t *testing.T) {
+	taskErr := errors.New("task failed")
+	err := RunAll(
+		func() error { return nil },
+		func() error { return taskErr },
+		func() error { panic("exploded") },
+	)
+
+	if !errors.Is(err, taskErr) {
+		t.Errorf("RunAll() = %v; want it to include the task error", err)
+	}
+	var pe *PanicError
+	if !errors.As(err, &pe) {
+		t.Errorf("RunAll() = %v; want it to include the panic", err)
+	}
+
+	if err := RunAll(); err != nil {
+		t.Errorf("RunAll() with no tasks = %v; want nil", err)
+	}
+}
@@ -0,0 +1,184 @@
+package embedfs
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"text/template"
+
+	"github.com/rehan/go-interview-prep/pkg/quiz"
+)
+
+// Files are embedded at compile time, so the binary runs from any directory.
+// A //go:embed directive must directly precede a package-level variable of
+// type string, []byte or embed.FS.
+
+//go:embed templates/*.tmpl
+var templateFiles embed.FS
+
+//go:embed static
+var staticFiles embed.FS
+
+//go:embed static/questions.json
+var rawQuestions []byte
+
+// Run reads the embedded files and serves them from a test server,
+// printing what it finds to w
+func Run(w io.Writer) error {
+	fmt.Fprintln(w, "=========================================")
+	fmt.Fprintln(w, "GO EMBED AND IO/FS EXAMPLES")
+	fmt.Fprintln(w, "=========================================")
+
+	EmbedBytesExample(w)
+	EmbedFSExample(w)
+	TemplatesExample(w)
+	StaticServerExample(w)
+
+	// Interview questions
+	EmbedInterviewQuestions(w)
+	return nil
+}
+
+// Question is one entry of the embedded question bank
+type Question struct {
+	ID       int    `json:"id"`
+	Topic    string `json:"topic"`
+	Question string `json:"question"`
+	Answer   string `json:"answer"`
+}
+
+// QuestionBank returns the embedded question bank as an fs.FS rooted at
+// the static directory, so callers see "questions.json" at the top level
+func QuestionBank() fs.FS {
+	sub, err := fs.Sub(staticFiles, "static")
+	if err != nil {
+		panic(err) // "static" is a valid path, so this cannot happen
+	}
+	return sub
+}
+
+// Templates returns the embedded templates as an fs.FS
+func Templates() fs.FS {
+	return templateFiles
+}
+
+// LoadQuestions reads questions.json from any fs.FS. Production code passes
+// QuestionBank(); tests pass an fstest.MapFS.
+func LoadQuestions(fsys fs.FS) ([]Question, error) {
+	data, err := fs.ReadFile(fsys, "questions.json")
+	if err != nil {
+		return nil, err
+	}
+	var questions []Question
+	if err := json.Unmarshal(data, &questions); err != nil {
+		return nil, fmt.Errorf("parse questions.json: %w", err)
+	}
+	return questions, nil
+}
+
+// Topics returns the distinct topics in the bank, sorted
+func Topics(questions []Question) []string {
+	seen := make(map[string]bool)
+	var topics []string
+	for _, q := range questions {
+		if !seen[q.Topic] {
+			seen[q.Topic] = true
+			topics = append(topics, q.Topic)
+		}
+	}
+	sort.Strings(topics)
+	return topics
+}
+
+// ParseTemplates parses every *.tmpl file found in fsys under templates/
+func ParseTemplates(fsys fs.FS) (*template.Template, error) {
+	return template.ParseFS(fsys, "templates/*.tmpl")
+}
+
+// RenderQuiz renders the "quiz" template to w
+func RenderQuiz(w io.Writer, tmpl *template.Template, questions []Question) error {
+	return tmpl.ExecuteTemplate(w, "quiz", questions)
+}
+
+// NewStaticHandler serves files from fsys over HTTP
+func NewStaticHandler(fsys fs.FS) http.Handler {
+	return http.FileServerFS(fsys)
+}
+
+// EmbedBytesExample uses a file embedded as []byte
+func EmbedBytesExample(w io.Writer) {
+	fmt.Fprintln(w, "=== EMBED AS []BYTE EXAMPLE ===")
+
+	var questions []Question
+	if err := json.Unmarshal(rawQuestions, &questions); err != nil {
+		fmt.Fprintln(w, "Error:", err)
+		return
+	}
+	fmt.Fprintf(w, "Embedded %d bytes containing %d questions\n", len(rawQuestions), len(questions))
+	fmt.Fprintln(w)
+}
+
+// EmbedFSExample walks and reads an embedded embed.FS
+func EmbedFSExample(w io.Writer) {
+	fmt.Fprintln(w, "=== EMBED.FS AND FS.SUB EXAMPLE ===")
+
+	_ = fs.WalkDir(templateFiles, ".", func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			fmt.Fprintln(w, "Embedded template:", path)
+		}
+		return err
+	})
+
+	questions, err := LoadQuestions(QuestionBank())
+	if err != nil {
+		fmt.Fprintln(w, "Error:", err)
+		return
+	}
+	fmt.Fprintln(w, "Topics:", Topics(questions))
+	fmt.Fprintln(w)
+}
+
+// TemplatesExample renders embedded templates with template.ParseFS
+func TemplatesExample(w io.Writer) {
+	fmt.Fprintln(w, "=== TEMPLATES FROM FS.FS EXAMPLE ===")
+
+	tmpl, err := ParseTemplates(Templates())
+	if err != nil {
+		fmt.Fprintln(w, "Error:", err)
+		return
+	}
+	questions, _ := LoadQuestions(QuestionBank())
+	if err := RenderQuiz(w, tmpl, questions); err != nil {
+		fmt.Fprintln(w, "Error:", err)
+	}
+	fmt.Fprintln(w)
+}
+
+// StaticServerExample serves the question bank with http.FileServerFS
+func StaticServerExample(w io.Writer) {
+	fmt.Fprintln(w, "=== SERVING AN FS.FS OVER HTTP EXAMPLE ===")
+
+	server := httptest.NewServer(NewStaticHandler(QuestionBank()))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/questions.json")
+	if err != nil {
+		fmt.Fprintln(w, "Error:", err)
+		return
+	}
+	defer resp.Body.Close()
+	fmt.Fprintln(w, "GET /questions.json:", resp.Status, resp.Header.Get("Content-Type"))
+	fmt.Fprintln(w)
+}
+
+// EmbedInterviewQuestions lists common interview questions about embed and io/fs
+func EmbedInterviewQuestions(w io.Writer) {
+	if err := quiz.Print(w, "embed-fs"); err != nil {
+		fmt.Fprintln(w, err)
+	}
+}
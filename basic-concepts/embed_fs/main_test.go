@@ -0,0 +1,124 @@
+package embedfs
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestEmbeddedFilesAreValid(t *testing.T) {
+	// fstest.TestFS checks that an FS implementation behaves correctly and
+	// that the listed files exist
+	if err := fstest.TestFS(QuestionBank(), "questions.json"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fstest.TestFS(Templates(), "templates/question.tmpl", "templates/quiz.tmpl"); err != nil {
+		t.Fatal(err)
+	}
+
+	questions, err := LoadQuestions(QuestionBank())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(questions) == 0 {
+		t.Fatal("embedded question bank is empty")
+	}
+	for _, q := range questions {
+		if q.ID == 0 || q.Question == "" || q.Answer == "" {
+			t.Errorf("incomplete question: %+v", q)
+		}
+	}
+}
+
+func TestLoadQuestions_MapFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"questions.json": {Data: []byte(`[{"id":7,"topic":"maps","question":"Q?","answer":"A."}]`)},
+	}
+
+	got, err := LoadQuestions(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Question{{ID: 7, Topic: "maps", Question: "Q?", Answer: "A."}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadQuestions() = %+v; want %+v", got, want)
+	}
+}
+
+func TestLoadQuestions_Errors(t *testing.T) {
+	if _, err := LoadQuestions(fstest.MapFS{}); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("missing file err = %v; want fs.ErrNotExist", err)
+	}
+
+	bad := fstest.MapFS{"questions.json": {Data: []byte(`{not json`)}}
+	if _, err := LoadQuestions(bad); err == nil || !strings.Contains(err.Error(), "parse questions.json") {
+		t.Errorf("malformed file err = %v; want a parse error", err)
+	}
+}
+
+func TestTopics(t *testing.T) {
+	questions := []Question{{Topic: "slices"}, {Topic: "maps"}, {Topic: "slices"}}
+	if got, want := Topics(questions), []string{"maps", "slices"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Topics() = %v; want %v", got, want)
+	}
+}
+
+func TestRenderQuiz_MapFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"templates/quiz.tmpl":     {Data: []byte(`{{define "quiz"}}{{range .}}{{template "question" .}};{{end}}{{end}}`)},
+		"templates/question.tmpl": {Data: []byte(`{{define "question"}}{{.ID}}:{{.Topic}}{{end}}`)},
+		"templates/ignored.txt":   {Data: []byte(`not a template`)},
+	}
+
+	tmpl, err := ParseTemplates(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sb strings.Builder
+	if err := RenderQuiz(&sb, tmpl, []Question{{ID: 1, Topic: "a"}, {ID: 2, Topic: "b"}}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := sb.String(), "1:a;2:b;"; got != want {
+		t.Errorf("RenderQuiz() = %q; want %q", got, want)
+	}
+}
+
+func TestRenderQuiz_Embedded(t *testing.T) {
+	tmpl, err := ParseTemplates(Templates())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sb strings.Builder
+	if err := RenderQuiz(&sb, tmpl, []Question{{ID: 9, Topic: "channels", Question: "Why?"}}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := sb.String(), "Quiz: 1 questions\nQ9 [channels] Why?\n"; got != want {
+		t.Errorf("RenderQuiz() = %q; want %q", got, want)
+	}
+}
+
+func TestStaticHandler(t *testing.T) {
+	handler := NewStaticHandler(fstest.MapFS{
+		"questions.json": {Data: []byte(`[]`)},
+	})
+
+	tests := []struct {
+		path       string
+		wantStatus int
+	}{
+		{"/questions.json", http.StatusOK},
+		{"/missing.json", http.StatusNotFound},
+	}
+	for _, tc := range tests {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tc.path, nil))
+		if rr.Code != tc.wantStatus {
+			t.Errorf("GET %s status = %d; want %d", tc.path, rr.Code, tc.wantStatus)
+		}
+	}
+}
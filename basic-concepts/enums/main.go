@@ -0,0 +1,251 @@
+package enums
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/rehan/go-interview-prep/pkg/quiz"
+)
+
+//go:generate stringer -type=OrderStatus -trimprefix=Status
+
+// Run prints the iota, validation, JSON and bit flag examples to w
+func Run(w io.Writer) error {
+	fmt.Fprintln(w, "=========================================")
+	fmt.Fprintln(w, "GO ENUM PATTERNS EXAMPLES")
+	fmt.Fprintln(w, "=========================================")
+
+	IotaExample(w)
+	ValidityExample(w)
+	JSONExample(w)
+	BitFlagExample(w)
+
+	// Interview questions
+	EnumInterviewQuestions(w)
+	return nil
+}
+
+// HAND-WRITTEN STRING METHOD
+
+// Priority is a typed enum. A distinct type stops a plain int or another
+// enum from being passed where a Priority is expected, though any untyped
+// constant such as 42 still converts implicitly.
+type Priority int
+
+const (
+	PriorityLow Priority = iota
+	PriorityMedium
+	PriorityHigh
+)
+
+// priorityNames is indexed by Priority; a constant added without a name
+// falls outside the array and is reported as invalid
+var priorityNames = [...]string{
+	PriorityLow:    "low",
+	PriorityMedium: "medium",
+	PriorityHigh:   "high",
+}
+
+// String implements fmt.Stringer
+func (p Priority) String() string {
+	if !p.IsValid() {
+		return fmt.Sprintf("Priority(%d)", int(p))
+	}
+	return priorityNames[p]
+}
+
+// IsValid reports whether p is one of the declared constants
+func (p Priority) IsValid() bool {
+	return p >= 0 && int(p) < len(priorityNames)
+}
+
+// ParsePriority converts a name back to a Priority, ignoring case
+func ParsePriority(s string) (Priority, error) {
+	for i, name := range priorityNames {
+		if strings.EqualFold(s, name) {
+			return Priority(i), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid priority %q", s)
+}
+
+// MarshalText encodes p by name. encoding/json uses it for values and for
+// map keys, so Priority appears as "high" rather than 2.
+func (p Priority) MarshalText() ([]byte, error) {
+	if !p.IsValid() {
+		return nil, fmt.Errorf("invalid priority %d", int(p))
+	}
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText decodes a name
+func (p *Priority) UnmarshalText(text []byte) error {
+	parsed, err := ParsePriority(string(text))
+	if err != nil {
+		return err
+	}
+	*p = parsed
+	return nil
+}
+
+// GENERATED STRING METHOD
+
+// OrderStatus uses stringer for its String method (see orderstatus_string.go,
+// regenerated with `go generate`). The zero value is deliberately
+// StatusUnknown, so a status that was never set is not mistaken for a real one.
+type OrderStatus uint8
+
+const (
+	StatusUnknown OrderStatus = iota
+	StatusPending
+	StatusShipped
+	StatusDelivered
+	StatusCancelled
+)
+
+// IsValid reports whether s is a declared status other than StatusUnknown
+func (s OrderStatus) IsValid() bool {
+	return s > StatusUnknown && s <= StatusCancelled
+}
+
+// ParseOrderStatus converts a name produced by String back to a status
+func ParseOrderStatus(name string) (OrderStatus, error) {
+	for s := StatusPending; s <= StatusCancelled; s++ {
+		if s.String() == name {
+			return s, nil
+		}
+	}
+	return StatusUnknown, fmt.Errorf("invalid order status %q", name)
+}
+
+// MarshalJSON encodes s as its name
+func (s OrderStatus) MarshalJSON() ([]byte, error) {
+	if !s.IsValid() {
+		return nil, fmt.Errorf("invalid order status %d", uint8(s))
+	}
+	return json.Marshal(s.String())
+}
+
+// UnmarshalJSON decodes a name, rejecting numbers so that reordering the
+// constants cannot silently change the meaning of stored data
+func (s *OrderStatus) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return fmt.Errorf("order status must be a string: %w", err)
+	}
+	parsed, err := ParseOrderStatus(name)
+	if err != nil {
+		return err
+	}
+	*s = parsed
+	return nil
+}
+
+// BIT FLAGS
+
+// Permission is a set of flags combined with |
+type Permission uint8
+
+const (
+	PermRead Permission = 1 << iota
+	PermWrite
+	PermExecute
+)
+
+// Has reports whether every flag in q is set in p
+func (p Permission) Has(q Permission) bool {
+	return p&q == q
+}
+
+// String lists the set flags, e.g. "read|write"
+func (p Permission) String() string {
+	if p == 0 {
+		return "none"
+	}
+	var names []string
+	for _, f := range []struct {
+		flag Permission
+		name string
+	}{{PermRead, "read"}, {PermWrite, "write"}, {PermExecute, "execute"}} {
+		if p.Has(f.flag) {
+			names = append(names, f.name)
+			p &^= f.flag
+		}
+	}
+	if p != 0 {
+		names = append(names, fmt.Sprintf("0x%x", uint8(p)))
+	}
+	return strings.Join(names, "|")
+}
+
+// Task shows both enums inside a JSON document
+type Task struct {
+	Title    string      `json:"title"`
+	Priority Priority    `json:"priority"`
+	Status   OrderStatus `json:"status"`
+}
+
+// IotaExample shows how iota numbers constants
+func IotaExample(w io.Writer) {
+	fmt.Fprintln(w, "=== IOTA EXAMPLE ===")
+
+	fmt.Fprintf(w, "PriorityLow=%d PriorityMedium=%d PriorityHigh=%d\n", PriorityLow, PriorityMedium, PriorityHigh)
+	fmt.Fprintf(w, "With %%v they print via String: %v, %v, %v\n", PriorityLow, PriorityMedium, PriorityHigh)
+	fmt.Fprintf(w, "Generated String: %v, %v\n", StatusPending, StatusDelivered)
+	fmt.Fprintln(w)
+}
+
+// ValidityExample shows that any int converts to the enum type
+func ValidityExample(w io.Writer) {
+	fmt.Fprintln(w, "=== VALIDITY EXAMPLE ===")
+
+	p := Priority(7) // compiles: Go enums are not closed
+	fmt.Fprintf(w, "Priority(7): %v, valid: %t\n", p, p.IsValid())
+	fmt.Fprintf(w, "OrderStatus(9): %v, valid: %t\n", OrderStatus(9), OrderStatus(9).IsValid())
+
+	var zero OrderStatus
+	fmt.Fprintf(w, "Zero OrderStatus: %v, valid: %t\n", zero, zero.IsValid())
+
+	if _, err := ParsePriority("urgent"); err != nil {
+		fmt.Fprintln(w, "ParsePriority:", err)
+	}
+	fmt.Fprintln(w)
+}
+
+// JSONExample shows enums marshalled by name
+func JSONExample(w io.Writer) {
+	fmt.Fprintln(w, "=== JSON EXAMPLE ===")
+
+	task := Task{Title: "ship it", Priority: PriorityHigh, Status: StatusShipped}
+	data, _ := json.Marshal(task)
+	fmt.Fprintln(w, "Marshal:", string(data))
+
+	var decoded Task
+	if err := json.Unmarshal(data, &decoded); err == nil {
+		fmt.Fprintf(w, "Unmarshal: %+v\n", decoded)
+	}
+
+	err := json.Unmarshal([]byte(`{"title":"x","priority":"low","status":2}`), &decoded)
+	fmt.Fprintln(w, "Numeric status rejected:", err)
+	fmt.Fprintln(w)
+}
+
+// BitFlagExample shows 1 << iota flags
+func BitFlagExample(w io.Writer) {
+	fmt.Fprintln(w, "=== BIT FLAG EXAMPLE ===")
+
+	p := PermRead | PermWrite
+	fmt.Fprintf(w, "%v (%03b): can write %t, can execute %t\n", p, uint8(p), p.Has(PermWrite), p.Has(PermExecute))
+	p &^= PermWrite
+	fmt.Fprintf(w, "After clearing write: %v\n", p)
+	fmt.Fprintln(w)
+}
+
+// EnumInterviewQuestions lists common interview questions about enums
+func EnumInterviewQuestions(w io.Writer) {
+	if err := quiz.Print(w, "enums"); err != nil {
+		fmt.Fprintln(w, err)
+	}
+}
@@ -0,0 +1,167 @@
+package enums
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestPriority_String(t *testing.T) {
+	tests := []struct {
+		p    Priority
+		want string
+	}{
+		{PriorityLow, "low"},
+		{PriorityMedium, "medium"},
+		{PriorityHigh, "high"},
+		{Priority(-1), "Priority(-1)"},
+		{Priority(3), "Priority(3)"},
+	}
+	for _, tc := range tests {
+		if got := tc.p.String(); got != tc.want {
+			t.Errorf("Priority(%d).String() = %q; want %q", int(tc.p), got, tc.want)
+		}
+	}
+}
+
+func TestPriority_EveryConstantHasAName(t *testing.T) {
+	for p := PriorityLow; p <= PriorityHigh; p++ {
+		if !p.IsValid() || priorityNames[p] == "" {
+			t.Errorf("Priority %d has no name", int(p))
+		}
+	}
+}
+
+func TestParsePriority(t *testing.T) {
+	if p, err := ParsePriority("HIGH"); err != nil || p != PriorityHigh {
+		t.Errorf("ParsePriority(\"HIGH\") = %v, %v; want high", p, err)
+	}
+	if _, err := ParsePriority("urgent"); err == nil {
+		t.Error("ParsePriority(\"urgent\") returned no error")
+	}
+}
+
+func TestOrderStatus_String(t *testing.T) {
+	tests := []struct {
+		s    OrderStatus
+		want string
+	}{
+		{StatusUnknown, "Unknown"},
+		{StatusPending, "Pending"},
+		{StatusShipped, "Shipped"},
+		{StatusDelivered, "Delivered"},
+		{StatusCancelled, "Cancelled"},
+		{OrderStatus(200), "OrderStatus(200)"},
+	}
+	for _, tc := range tests {
+		if got := tc.s.String(); got != tc.want {
+			t.Errorf("OrderStatus(%d).String() = %q; want %q", uint8(tc.s), got, tc.want)
+		}
+	}
+}
+
+func TestOrderStatus_IsValid(t *testing.T) {
+	if StatusUnknown.IsValid() || OrderStatus(5).IsValid() {
+		t.Error("IsValid accepted the zero value or an undeclared value")
+	}
+	for s := StatusPending; s <= StatusCancelled; s++ {
+		if !s.IsValid() {
+			t.Errorf("%v.IsValid() = false", s)
+		}
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	for p := PriorityLow; p <= PriorityHigh; p++ {
+		for s := StatusPending; s <= StatusCancelled; s++ {
+			in := Task{Title: "t", Priority: p, Status: s}
+			data, err := json.Marshal(in)
+			if err != nil {
+				t.Fatalf("Marshal(%+v): %v", in, err)
+			}
+			var out Task
+			if err := json.Unmarshal(data, &out); err != nil {
+				t.Fatalf("Unmarshal(%s): %v", data, err)
+			}
+			if out != in {
+				t.Errorf("round trip %+v -> %s -> %+v", in, data, out)
+			}
+		}
+	}
+}
+
+func TestJSON_EncodesNames(t *testing.T) {
+	data, err := json.Marshal(Task{Title: "t", Priority: PriorityMedium, Status: StatusDelivered})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"title":"t","priority":"medium","status":"Delivered"}`
+	if string(data) != want {
+		t.Errorf("Marshal = %s; want %s", data, want)
+	}
+}
+
+func TestJSON_PriorityAsMapKey(t *testing.T) {
+	in := map[Priority]int{PriorityLow: 1, PriorityHigh: 3}
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out map[Priority]int
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("Unmarshal(%s): %v", data, err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("map round trip = %v; want %v", out, in)
+	}
+}
+
+func TestJSON_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+	}{
+		{"unknown priority", `{"priority":"urgent","status":"Pending"}`},
+		{"unknown status", `{"priority":"low","status":"Lost"}`},
+		{"numeric status", `{"priority":"low","status":1}`},
+		{"zero status by name", `{"priority":"low","status":"Unknown"}`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var task Task
+			if err := json.Unmarshal([]byte(tc.json), &task); err == nil {
+				t.Errorf("Unmarshal(%s) = %+v; want an error", tc.json, task)
+			}
+		})
+	}
+
+	if _, err := json.Marshal(Task{Priority: Priority(9), Status: StatusPending}); err == nil {
+		t.Error("Marshal with an invalid priority returned no error")
+	}
+	if _, err := json.Marshal(Task{Priority: PriorityLow}); err == nil {
+		t.Error("Marshal with an unset status returned no error")
+	}
+}
+
+func TestPermission(t *testing.T) {
+	tests := []struct {
+		p    Permission
+		want string
+	}{
+		{0, "none"},
+		{PermRead, "read"},
+		{PermRead | PermWrite, "read|write"},
+		{PermRead | PermWrite | PermExecute, "read|write|execute"},
+		{PermExecute | 0x40, "execute|0x40"},
+	}
+	for _, tc := range tests {
+		if got := tc.p.String(); got != tc.want {
+			t.Errorf("Permission(%d).String() = %q; want %q", uint8(tc.p), got, tc.want)
+		}
+	}
+
+	p := PermRead | PermExecute
+	if !p.Has(PermRead|PermExecute) || p.Has(PermWrite) || p.Has(PermRead|PermWrite) {
+		t.Errorf("Has gave wrong answers for %v", p)
+	}
+}
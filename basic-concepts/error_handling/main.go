@@ -1,4 +1,4 @@
-package main
+package errorhandling
 
 import (
 	"errors"
@@ -43,6 +43,11 @@ func (e InputValidationError) Error() string {
 	return fmt.Sprintf("validation error: %s %s", e.Field, e.Msg)
 }
 
+// Unwrap lets errors.Is(err, ErrInvalidInput) match any validation error
+func (e InputValidationError) Unwrap() error {
+	return ErrInvalidInput
+}
+
 // Function that returns a custom error
 func validateNameInput(name string) error {
 	if name == "" {
@@ -101,6 +106,57 @@ func getFileContents(filename string) (string, error) {
 	return string(data), nil
 }
 
+// MULTIPLE ERRORS (Go 1.20+)
+
+// MultiError collects several errors into one. Implementing Unwrap() []error
+// makes errors.Is and errors.As search every collected error, exactly like
+// the value returned by errors.Join.
+type MultiError struct {
+	Errors []error
+}
+
+// Append adds err, ignoring nil so callers don't need to check first
+func (m *MultiError) Append(err error) {
+	if err != nil {
+		m.Errors = append(m.Errors, err)
+	}
+}
+
+func (m *MultiError) Error() string {
+	if len(m.Errors) == 1 {
+		return m.Errors[0].Error()
+	}
+	msgs := make([]string, len(m.Errors))
+	for i, err := range m.Errors {
+		msgs[i] = fmt.Sprintf("%d. %v", i+1, err)
+	}
+	return fmt.Sprintf("%d errors occurred:\n%s", len(m.Errors), strings.Join(msgs, "\n"))
+}
+
+// Unwrap exposes the collected errors to errors.Is and errors.As
+func (m *MultiError) Unwrap() []error {
+	return m.Errors
+}
+
+// ErrorOrNil returns nil when nothing was collected. Returning a nil
+// *MultiError as an error would produce a non-nil interface value.
+func (m *MultiError) ErrorOrNil() error {
+	if m == nil || len(m.Errors) == 0 {
+		return nil
+	}
+	return m
+}
+
+// closeAll closes every closer and joins the failures with errors.Join,
+// which drops nil errors and returns nil if all of them were nil
+func closeAll(closers ...io.Closer) error {
+	var errs []error
+	for _, c := range closers {
+		errs = append(errs, c.Close())
+	}
+	return errors.Join(errs...)
+}
+
 // SENTINEL ERRORS
 
 // Predefined errors for specific error conditions
@@ -165,58 +221,59 @@ func safeParse(s string) (n int, err error) {
 	return n, nil
 }
 
-func main() {
-	fmt.Println("=== BASIC ERROR HANDLING ===")
+// Run prints the error handling examples to w
+func Run(w io.Writer) error {
+	fmt.Fprintln(w, "=== BASIC ERROR HANDLING ===")
 
 	// Basic error handling
 	result, err := divide(10, 2)
 	if err != nil {
-		fmt.Println("Error:", err)
+		fmt.Fprintln(w, "Error:", err)
 	} else {
-		fmt.Println("Result:", result)
+		fmt.Fprintln(w, "Result:", result)
 	}
 
 	result, err = divide(10, 0)
 	if err != nil {
-		fmt.Println("Error:", err)
+		fmt.Fprintln(w, "Error:", err)
 	} else {
-		fmt.Println("Result:", result)
+		fmt.Fprintln(w, "Result:", result)
 	}
 
 	// Handling formatted errors
 	err = validateAge(25)
 	if err != nil {
-		fmt.Println("Age validation error:", err)
+		fmt.Fprintln(w, "Age validation error:", err)
 	} else {
-		fmt.Println("Age is valid")
+		fmt.Fprintln(w, "Age is valid")
 	}
 
 	err = validateAge(200)
 	if err != nil {
-		fmt.Println("Age validation error:", err)
+		fmt.Fprintln(w, "Age validation error:", err)
 	}
 
-	fmt.Println("\n=== CUSTOM ERROR TYPES ===")
+	fmt.Fprintln(w, "\n=== CUSTOM ERROR TYPES ===")
 
 	// Custom error types
 	err = validateNameInput("John")
 	if err != nil {
-		fmt.Println("Name validation error:", err)
+		fmt.Fprintln(w, "Name validation error:", err)
 	} else {
-		fmt.Println("Name is valid")
+		fmt.Fprintln(w, "Name is valid")
 	}
 
 	err = validateNameInput("")
 	if err != nil {
-		fmt.Println("Name validation error:", err)
+		fmt.Fprintln(w, "Name validation error:", err)
 
 		// Type assertion
 		if valErr, ok := err.(InputValidationError); ok {
-			fmt.Printf("Field '%s' has error: %s\n", valErr.Field, valErr.Msg)
+			fmt.Fprintf(w, "Field '%s' has error: %s\n", valErr.Field, valErr.Msg)
 		}
 	}
 
-	fmt.Println("\n=== TYPE ASSERTION AND TYPE SWITCH ===")
+	fmt.Fprintln(w, "\n=== TYPE ASSERTION AND TYPE SWITCH ===")
 
 	// Create different error types
 	var err1 error = SyntaxError{Line: 42, Msg: "unexpected semicolon"}
@@ -224,95 +281,95 @@ func main() {
 
 	// Handling different error types with type assertion
 	if syntaxErr, ok := err1.(SyntaxError); ok {
-		fmt.Printf("Syntax error on line %d: %s\n", syntaxErr.Line, syntaxErr.Msg)
+		fmt.Fprintf(w, "Syntax error on line %d: %s\n", syntaxErr.Line, syntaxErr.Msg)
 	}
 
 	// Handling different error types with type switch
 	switch e := err2.(type) {
 	case SyntaxError:
-		fmt.Printf("Syntax error on line %d: %s\n", e.Line, e.Msg)
+		fmt.Fprintf(w, "Syntax error on line %d: %s\n", e.Line, e.Msg)
 	case RuntimeError:
-		fmt.Printf("Runtime error (%v) at %s: %s\n", e.Fatal, e.Time, e.Msg)
+		fmt.Fprintf(w, "Runtime error (%v) at %s: %s\n", e.Fatal, e.Time, e.Msg)
 	default:
-		fmt.Printf("Unknown error: %v\n", e)
+		fmt.Fprintf(w, "Unknown error: %v\n", e)
 	}
 
-	fmt.Println("\n=== ERROR WRAPPING ===")
+	fmt.Fprintln(w, "\n=== ERROR WRAPPING ===")
 
 	// Error wrapping
 	_, err = getFileContents("nonexistent-file.txt")
 	if err != nil {
-		fmt.Println("Error:", err)
+		fmt.Fprintln(w, "Error:", err)
 
 		// Unwrap the error (Go 1.13+)
-		fmt.Println("Unwrapped error:", errors.Unwrap(err))
+		fmt.Fprintln(w, "Unwrapped error:", errors.Unwrap(err))
 
 		// Check if an error is wrapped inside another
 		if errors.Is(err, os.ErrNotExist) {
-			fmt.Println("The file does not exist")
+			fmt.Fprintln(w, "The file does not exist")
 		}
 	}
 
-	fmt.Println("\n=== SENTINEL ERRORS ===")
+	fmt.Fprintln(w, "\n=== SENTINEL ERRORS ===")
 
 	// Sentinel errors
 	_, err = findItem("")
 	if err != nil {
 		if errors.Is(err, ErrInvalidInput) {
-			fmt.Println("Invalid input provided")
+			fmt.Fprintln(w, "Invalid input provided")
 		} else if errors.Is(err, ErrNotFound) {
-			fmt.Println("Item was not found")
+			fmt.Fprintln(w, "Item was not found")
 		} else {
-			fmt.Println("Unknown error:", err)
+			fmt.Fprintln(w, "Unknown error:", err)
 		}
 	}
 
-	fmt.Println("\n=== ERROR HANDLING PATTERNS ===")
+	fmt.Fprintln(w, "\n=== ERROR HANDLING PATTERNS ===")
 
 	// Parse integer with error handling
 	num, err := parsePositiveInt("42")
 	if err != nil {
-		fmt.Println("Error:", err)
+		fmt.Fprintln(w, "Error:", err)
 	} else {
-		fmt.Println("Parsed number:", num)
+		fmt.Fprintln(w, "Parsed number:", num)
 	}
 
 	num, err = parsePositiveInt("-5")
 	if err != nil {
-		fmt.Println("Error:", err)
+		fmt.Fprintln(w, "Error:", err)
 	} else {
-		fmt.Println("Parsed number:", num)
+		fmt.Fprintln(w, "Parsed number:", num)
 	}
 
 	num, err = parsePositiveInt("abc")
 	if err != nil {
-		fmt.Println("Error:", err)
+		fmt.Fprintln(w, "Error:", err)
 
 		// Check if specific error is wrapped
 		var numErr *strconv.NumError
 		if errors.As(err, &numErr) {
-			fmt.Println("Failed to convert to a number:", numErr.Num)
+			fmt.Fprintln(w, "Failed to convert to a number:", numErr.Num)
 		}
 	}
 
-	fmt.Println("\n=== PANIC AND RECOVER ===")
+	fmt.Fprintln(w, "\n=== PANIC AND RECOVER ===")
 
 	// Panic and recover
 	num, err = safeParse("123")
 	if err != nil {
-		fmt.Println("Error:", err)
+		fmt.Fprintln(w, "Error:", err)
 	} else {
-		fmt.Println("Parsed number:", num)
+		fmt.Fprintln(w, "Parsed number:", num)
 	}
 
 	num, err = safeParse("abc")
 	if err != nil {
-		fmt.Println("Error:", err)
+		fmt.Fprintln(w, "Error:", err)
 	} else {
-		fmt.Println("Parsed number:", num)
+		fmt.Fprintln(w, "Parsed number:", num)
 	}
 
-	fmt.Println("\n=== PRACTICAL EXAMPLES ===")
+	fmt.Fprintln(w, "\n=== PRACTICAL EXAMPLES ===")
 
 	// Demonstrate error handling in real-world scenario
 	userInput := map[string]string{
@@ -321,20 +378,44 @@ func main() {
 		"email":    "invalid-email",
 	}
 
-	validateUserInput(userInput)
+	err = validateUserInput(userInput)
+	if err != nil {
+		fmt.Fprintln(w, "Input validation errors:")
+		fmt.Fprintln(w, err)
+
+		// errors.Is/As look inside every collected error
+		fmt.Fprintln(w, "Contains invalid input:", errors.Is(err, ErrInvalidInput))
+		var numErr *strconv.NumError
+		if errors.As(err, &numErr) {
+			fmt.Fprintf(w, "First number parse failure: %q\n", numErr.Num)
+		}
+	} else {
+		fmt.Fprintln(w, "All input is valid")
+	}
+
+	fmt.Fprintln(w, "\n=== ERRORS.JOIN ===")
+
+	// errors.Join combines errors without a custom type
+	joined := errors.Join(ErrNotFound, fmt.Errorf("lookup user 42: %w", ErrUnauthorized))
+	fmt.Fprintln(w, joined)
+	fmt.Fprintln(w, "Is ErrUnauthorized:", errors.Is(joined, ErrUnauthorized))
+	fmt.Fprintln(w, "Join of only nils is nil:", errors.Join(nil, nil) == nil)
+	return nil
 }
 
-// Demonstrating error handling in a practical scenario
-func validateUserInput(input map[string]string) {
-	var errors []string
+// Demonstrating error handling in a practical scenario.
+// Every problem is collected instead of stopping at the first one, and the
+// result is a single error whose parts stay reachable via errors.Is/As.
+func validateUserInput(input map[string]string) error {
+	var errs MultiError
 
 	// Validate age
 	if ageStr, ok := input["age"]; ok {
 		age, err := strconv.Atoi(ageStr)
 		if err != nil {
-			errors = append(errors, fmt.Sprintf("invalid age format: %v", err))
+			errs.Append(fmt.Errorf("invalid age format: %w", err))
 		} else if age < 0 || age > 150 {
-			errors = append(errors, fmt.Sprintf("age %d out of range", age))
+			errs.Append(InputValidationError{Field: "age", Msg: fmt.Sprintf("%d out of range", age)})
 		}
 	}
 
@@ -342,28 +423,20 @@ func validateUserInput(input map[string]string) {
 	if qtyStr, ok := input["quantity"]; ok {
 		qty, err := strconv.Atoi(qtyStr)
 		if err != nil {
-			errors = append(errors, fmt.Sprintf("invalid quantity format: %v", err))
+			errs.Append(fmt.Errorf("invalid quantity format: %w", err))
 		} else if qty <= 0 {
-			errors = append(errors, "quantity must be positive")
+			errs.Append(InputValidationError{Field: "quantity", Msg: "must be positive"})
 		}
 	}
 
 	// Validate email
 	if email, ok := input["email"]; ok {
 		if !strings.Contains(email, "@") {
-			errors = append(errors, "invalid email format")
+			errs.Append(InputValidationError{Field: "email", Msg: "invalid format"})
 		}
 	}
 
-	// Print validation errors
-	if len(errors) > 0 {
-		fmt.Println("Input validation errors:")
-		for i, err := range errors {
-			fmt.Printf("%d. %s\n", i+1, err)
-		}
-	} else {
-		fmt.Println("All input is valid")
-	}
+	return errs.ErrorOrNil()
 }
 
 /*
@@ -425,4 +498,14 @@ Common interview questions about error handling in Go:
 10. What's the difference between errors.Is() and errors.As()?
     - errors.Is() checks if an error or any error it wraps matches a specific error value
     - errors.As() checks if an error or any error it wraps matches a specific error type
+
+11. How do you return several errors at once (Go 1.20+)?
+    - errors.Join(errs...) combines them and drops nil values
+    - fmt.Errorf can use %w more than once
+    - A custom type can implement Unwrap() []error
+    - errors.Is/As walk the whole tree, depth first
+
+12. Why does a function returning a nil *MultiError as error look non-nil?
+    - The interface holds a type and a nil pointer, so it is not equal to nil
+    - Return a literal nil (see ErrorOrNil) when there are no errors
 */
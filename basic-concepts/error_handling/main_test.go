@@ -0,0 +1,118 @@
+package errorhandling
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestValidateUserInput_Valid(t *testing.T) {
+	err := validateUserInput(map[string]string{"age": "30", "quantity": "2", "email": "a@b.com"})
+	if err != nil {
+		t.Errorf("validateUserInput() = %v; want nil", err)
+	}
+}
+
+func TestValidateUserInput_CollectsAllErrors(t *testing.T) {
+	err := validateUserInput(map[string]string{"age": "thirty", "quantity": "-5", "email": "invalid"})
+	if err == nil {
+		t.Fatal("validateUserInput() = nil; want error")
+	}
+
+	var multi *MultiError
+	if !errors.As(err, &multi) {
+		t.Fatalf("error %T is not a *MultiError", err)
+	}
+	if len(multi.Errors) != 3 {
+		t.Errorf("got %d errors; want 3: %v", len(multi.Errors), err)
+	}
+	if !strings.HasPrefix(err.Error(), "3 errors occurred:") {
+		t.Errorf("Error() = %q; want a 3 error summary", err.Error())
+	}
+}
+
+func TestValidateUserInput_IsAndAsThroughChain(t *testing.T) {
+	err := validateUserInput(map[string]string{"age": "thirty", "email": "invalid"})
+
+	// Sentinel reached through InputValidationError.Unwrap
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Error("errors.Is(err, ErrInvalidInput) = false; want true")
+	}
+	// Sentinel reached through fmt.Errorf %w and *strconv.NumError
+	if !errors.Is(err, strconv.ErrSyntax) {
+		t.Error("errors.Is(err, strconv.ErrSyntax) = false; want true")
+	}
+
+	var numErr *strconv.NumError
+	if !errors.As(err, &numErr) || numErr.Num != "thirty" {
+		t.Errorf("errors.As NumError = %v; want Num \"thirty\"", numErr)
+	}
+
+	var valErr InputValidationError
+	if !errors.As(err, &valErr) || valErr.Field != "email" {
+		t.Errorf("errors.As InputValidationError = %+v; want Field \"email\"", valErr)
+	}
+
+	if errors.Is(err, ErrNotFound) {
+		t.Error("errors.Is(err, ErrNotFound) = true; want false")
+	}
+}
+
+func TestMultiError_ErrorOrNil(t *testing.T) {
+	var m MultiError
+	m.Append(nil)
+	if err := m.ErrorOrNil(); err != nil {
+		t.Errorf("ErrorOrNil() = %v; want nil", err)
+	}
+
+	var nilMulti *MultiError
+	if err := nilMulti.ErrorOrNil(); err != nil {
+		t.Errorf("nil receiver ErrorOrNil() = %v; want nil", err)
+	}
+
+	m.Append(ErrNotFound)
+	if err := m.ErrorOrNil(); err == nil || err.Error() != ErrNotFound.Error() {
+		t.Errorf("ErrorOrNil() = %v; want single error message %q", err, ErrNotFound)
+	}
+}
+
+func TestErrorsJoin(t *testing.T) {
+	wrapped := InputValidationError{Field: "name", Msg: "too short"}
+	joined := errors.Join(ErrNotFound, nil, wrapped)
+
+	if got, want := joined.Error(), "item not found\nvalidation error: name too short"; got != want {
+		t.Errorf("Error() = %q; want %q", got, want)
+	}
+	if !errors.Is(joined, ErrNotFound) || !errors.Is(joined, ErrInvalidInput) {
+		t.Error("errors.Is did not find both joined errors")
+	}
+
+	// A joined error can itself be joined or wrapped; the tree is still searched
+	outer := errors.Join(errors.New("outer"), joined)
+	var valErr InputValidationError
+	if !errors.As(outer, &valErr) || valErr.Field != "name" {
+		t.Errorf("errors.As through nested join = %+v; want Field \"name\"", valErr)
+	}
+
+	if errors.Join(nil, nil) != nil {
+		t.Error("errors.Join(nil, nil) != nil")
+	}
+}
+
+type stubCloser struct{ err error }
+
+func (c stubCloser) Close() error { return c.err }
+
+func TestCloseAll(t *testing.T) {
+	if err := closeAll(stubCloser{}, stubCloser{}); err != nil {
+		t.Errorf("closeAll() = %v; want nil", err)
+	}
+
+	err := closeAll(stubCloser{os.ErrClosed}, stubCloser{}, stubCloser{io.ErrClosedPipe})
+	if !errors.Is(err, os.ErrClosed) || !errors.Is(err, io.ErrClosedPipe) {
+		t.Errorf("closeAll() = %v; want both close errors", err)
+	}
+}
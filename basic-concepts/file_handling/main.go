@@ -0,0 +1,317 @@
+package filehandling
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/rehan/go-interview-prep/pkg/quiz"
+)
+
+// Run works through reading, writing and walking files in a temporary
+// directory, printing what it does to w
+func Run(w io.Writer) error {
+	fmt.Fprintln(w, "=========================================")
+	fmt.Fprintln(w, "GO FILE HANDLING EXAMPLES")
+	fmt.Fprintln(w, "=========================================")
+
+	// Work inside a throwaway directory so the examples leave nothing behind
+	dir, err := os.MkdirTemp("", "file-handling-*")
+	if err != nil {
+		fmt.Fprintln(w, "Error:", err)
+		return nil
+	}
+	defer os.RemoveAll(dir)
+
+	ReadWriteExample(w, dir)
+	AppendExample(w, dir)
+	TempFilesExample(w)
+	WalkDirExample(w, dir)
+	AtomicWriteExample(w, dir)
+	LockFileExample(w, dir)
+
+	// Interview questions
+	FileHandlingInterviewQuestions(w)
+	return nil
+}
+
+// WriteText writes content to path, creating or truncating it
+func WriteText(path, content string) error {
+	return os.WriteFile(path, []byte(content), 0o644)
+}
+
+// ReadText reads the whole file at path. The *fs.PathError it returns
+// already names the operation and path, so it is not wrapped again.
+func ReadText(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	return string(data), err
+}
+
+// AppendLine appends a line to path, creating the file if needed.
+// O_APPEND makes each write land at the current end of file, even if
+// other processes are appending too.
+func AppendLine(path, line string) (err error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	// Close errors matter for writes: buffered data may fail to flush
+	defer func() {
+		if cerr := f.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	_, err = f.WriteString(line + "\n")
+	return err
+}
+
+// ReadLines returns the lines of a file without their newlines
+func ReadLines(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
+
+// WithTempFile creates a temporary file, passes it to fn and removes it
+// afterwards. The file is closed before removal, which Windows requires.
+func WithTempFile(pattern string, fn func(*os.File) error) error {
+	f, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	return fn(f)
+}
+
+// FindFiles walks root and returns the slash-separated paths, relative to
+// root, of regular files with the given extension. Directories named in
+// skip are not descended into.
+func FindFiles(root, ext string, skip ...string) ([]string, error) {
+	var found []string
+	err := fs.WalkDir(os.DirFS(root), ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err // e.g. permission denied; stop the walk
+		}
+		if d.IsDir() {
+			for _, name := range skip {
+				if d.Name() == name {
+					return fs.SkipDir
+				}
+			}
+			return nil
+		}
+		if d.Type().IsRegular() && filepath.Ext(path) == ext {
+			found = append(found, path)
+		}
+		return nil
+	})
+	sort.Strings(found)
+	return found, err
+}
+
+// DirSize returns the total size in bytes of the regular files under root
+func DirSize(root string) (int64, error) {
+	var total int64
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info() // Info costs a stat call, so only ask when needed
+		if err != nil {
+			return err
+		}
+		total += info.Size()
+		return nil
+	})
+	return total, err
+}
+
+// AtomicWriteFile writes data so that readers see either the old contents
+// or the new contents, never a partial file. It writes to a temporary file
+// in the same directory (rename is only atomic within one filesystem),
+// syncs it to disk, then renames it over path.
+func AtomicWriteFile(path string, data []byte, perm fs.FileMode) (err error) {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	// On any failure, remove the temporary file
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = tmp.Write(data); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// ErrLocked is returned when a lock file is already held
+var ErrLocked = errors.New("lock is held by another process")
+
+// AcquireLockFile creates path exclusively and returns a function that
+// releases the lock. O_EXCL makes creation fail if the file already exists,
+// which works on every OS without syscall-specific flock/LockFileEx calls.
+// The trade-off: a crashed process leaves a stale lock file behind.
+func AcquireLockFile(path string) (release func() error, err error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if errors.Is(err, fs.ErrExist) {
+		return nil, ErrLocked
+	}
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(f, "%d\n", os.Getpid()) // owner PID helps diagnose stale locks
+	if err := f.Close(); err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+	return func() error { return os.Remove(path) }, nil
+}
+
+// ReadWriteExample writes a file and reads it back
+func ReadWriteExample(w io.Writer, dir string) {
+	fmt.Fprintln(w, "=== READ / WRITE EXAMPLE ===")
+
+	path := filepath.Join(dir, "hello.txt")
+	if err := WriteText(path, "Hello, files!\n"); err != nil {
+		fmt.Fprintln(w, "Error:", err)
+		return
+	}
+	content, _ := ReadText(path)
+	fmt.Fprintf(w, "Read back: %q\n", content)
+
+	_, err := ReadText(filepath.Join(dir, "missing.txt"))
+	fmt.Fprintln(w, "Missing file error:", err)
+	fmt.Fprintln(w, "Is fs.ErrNotExist:", errors.Is(err, fs.ErrNotExist))
+	fmt.Fprintln(w)
+}
+
+// AppendExample appends lines to a log file
+func AppendExample(w io.Writer, dir string) {
+	fmt.Fprintln(w, "=== APPEND EXAMPLE ===")
+
+	path := filepath.Join(dir, "app.log")
+	for _, line := range []string{"started", "working", "stopped"} {
+		if err := AppendLine(path, line); err != nil {
+			fmt.Fprintln(w, "Error:", err)
+			return
+		}
+	}
+	lines, _ := ReadLines(path)
+	fmt.Fprintln(w, "Log lines:", lines)
+	fmt.Fprintln(w)
+}
+
+// TempFilesExample uses a temp file that is cleaned up automatically
+func TempFilesExample(w io.Writer) {
+	fmt.Fprintln(w, "=== TEMP FILES AND DIRS EXAMPLE ===")
+
+	var name string
+	_ = WithTempFile("example-*.txt", func(f *os.File) error {
+		name = f.Name()
+		_, err := f.WriteString("scratch data")
+		fmt.Fprintln(w, "Temp file:", filepath.Base(name))
+		return err
+	})
+	_, err := os.Stat(name)
+	fmt.Fprintln(w, "Removed afterwards:", errors.Is(err, fs.ErrNotExist))
+	fmt.Fprintln(w, "os.TempDir():", os.TempDir())
+	fmt.Fprintln(w)
+}
+
+// WalkDirExample walks a small directory tree
+func WalkDirExample(w io.Writer, dir string) {
+	fmt.Fprintln(w, "=== WALKING DIRECTORIES EXAMPLE ===")
+
+	root := filepath.Join(dir, "project")
+	for _, p := range []string{"main.go", "pkg/util.go", "pkg/util_test.go", "vendor/dep.go", "README.md"} {
+		full := filepath.Join(root, filepath.FromSlash(p))
+		_ = os.MkdirAll(filepath.Dir(full), 0o755)
+		_ = WriteText(full, "// "+p+"\n")
+	}
+
+	files, _ := FindFiles(root, ".go", "vendor")
+	fmt.Fprintln(w, "Go files (vendor skipped):", strings.Join(files, ", "))
+	size, _ := DirSize(root)
+	fmt.Fprintln(w, "Total size in bytes:", size)
+	fmt.Fprintln(w)
+}
+
+// AtomicWriteExample replaces a config file atomically
+func AtomicWriteExample(w io.Writer, dir string) {
+	fmt.Fprintln(w, "=== ATOMIC WRITE EXAMPLE ===")
+
+	path := filepath.Join(dir, "config.json")
+	_ = WriteText(path, `{"version":1}`)
+	if err := AtomicWriteFile(path, []byte(`{"version":2}`), 0o644); err != nil {
+		fmt.Fprintln(w, "Error:", err)
+		return
+	}
+	content, _ := ReadText(path)
+	fmt.Fprintln(w, "Config after atomic write:", content)
+	fmt.Fprintln(w)
+}
+
+// LockFileExample shows a second acquisition failing
+func LockFileExample(w io.Writer, dir string) {
+	fmt.Fprintln(w, "=== FILE LOCKING EXAMPLE ===")
+
+	path := filepath.Join(dir, "job.lock")
+	release, err := AcquireLockFile(path)
+	if err != nil {
+		fmt.Fprintln(w, "Error:", err)
+		return
+	}
+	_, err = AcquireLockFile(path)
+	fmt.Fprintln(w, "Second acquire:", err)
+
+	_ = release()
+	release, err = AcquireLockFile(path)
+	fmt.Fprintln(w, "Acquire after release succeeded:", err == nil)
+	if err == nil {
+		_ = release()
+	}
+	fmt.Fprintln(w)
+}
+
+// FileHandlingInterviewQuestions lists common interview questions about files
+func FileHandlingInterviewQuestions(w io.Writer) {
+	if err := quiz.Print(w, "file-handling"); err != nil {
+		fmt.Fprintln(w, err)
+	}
+}
@@ -0,0 +1,206 @@
+package filehandling
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestWriteReadText(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.txt")
+	if err := WriteText(path, "first"); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteText(path, "second"); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadText(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "second" {
+		t.Errorf("ReadText() = %q; want %q (WriteText should truncate)", got, "second")
+	}
+}
+
+func TestReadText_Missing(t *testing.T) {
+	_, err := ReadText(filepath.Join(t.TempDir(), "missing"))
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("err = %v; want fs.ErrNotExist", err)
+	}
+}
+
+func TestAppendLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.txt")
+	for _, line := range []string{"one", "two", "three"} {
+		if err := AppendLine(path, line); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lines, err := ReadLines(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"one", "two", "three"}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("lines = %v; want %v", lines, want)
+	}
+}
+
+func TestAppendLine_Concurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.txt")
+	const writers = 20
+
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := AppendLine(path, "entry"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	lines, err := ReadLines(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != writers {
+		t.Errorf("got %d lines; want %d", len(lines), writers)
+	}
+}
+
+func TestWithTempFile(t *testing.T) {
+	var name string
+	err := WithTempFile("test-*.txt", func(f *os.File) error {
+		name = f.Name()
+		_, err := f.WriteString("data")
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(name); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("temp file %s still exists (stat err = %v)", name, err)
+	}
+
+	boom := errors.New("boom")
+	if err := WithTempFile("test-*", func(*os.File) error { return boom }); !errors.Is(err, boom) {
+		t.Errorf("err = %v; want the callback error", err)
+	}
+}
+
+// makeTree creates files (slash-separated, relative to root) with the given contents
+func makeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestFindFiles(t *testing.T) {
+	root := makeTree(t, map[string]string{
+		"main.go":            "",
+		"README.md":          "",
+		"pkg/a/a.go":         "",
+		"pkg/a/a_test.go":    "",
+		"vendor/x/x.go":      "",
+		"testdata/golden.go": "",
+	})
+
+	got, err := FindFiles(root, ".go", "vendor", "testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"main.go", "pkg/a/a.go", "pkg/a/a_test.go"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindFiles() = %v; want %v", got, want)
+	}
+}
+
+func TestFindFiles_MissingRoot(t *testing.T) {
+	if _, err := FindFiles(filepath.Join(t.TempDir(), "nope"), ".go"); err == nil {
+		t.Error("expected an error for a missing root")
+	}
+}
+
+func TestDirSize(t *testing.T) {
+	root := makeTree(t, map[string]string{"a": "12345", "b/c": "123", "b/d/e": "12"})
+	size, err := DirSize(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != 10 {
+		t.Errorf("DirSize() = %d; want 10", size)
+	}
+}
+
+func TestAtomicWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := AtomicWriteFile(path, []byte("new"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, _ := os.ReadFile(path)
+	if string(got) != "new" {
+		t.Errorf("contents = %q; want %q", got, "new")
+	}
+	info, _ := os.Stat(path)
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("perm = %v; want 0600", info.Mode().Perm())
+	}
+
+	// No temporary files are left behind
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("directory has %d entries; want only config.json", len(entries))
+	}
+}
+
+func TestAtomicWriteFile_MissingDir(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "no-such-dir", "config.json")
+	if err := AtomicWriteFile(path, []byte("x"), 0o644); err == nil {
+		t.Error("expected an error when the directory does not exist")
+	}
+}
+
+func TestAcquireLockFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "job.lock")
+
+	release, err := AcquireLockFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := AcquireLockFile(path); !errors.Is(err, ErrLocked) {
+		t.Errorf("second acquire err = %v; want ErrLocked", err)
+	}
+
+	if err := release(); err != nil {
+		t.Fatal(err)
+	}
+	release, err = AcquireLockFile(path)
+	if err != nil {
+		t.Fatalf("acquire after release: %v", err)
+	}
+	release()
+}
@@ -1,115 +1,117 @@
-package main
+package functions
 
 import (
 	"fmt"
+	"io"
 	"strings"
 )
 
-func main() {
-	fmt.Println("=== FUNCTIONS ===")
+// Run prints the function examples to w
+func Run(w io.Writer) error {
+	fmt.Fprintln(w, "=== FUNCTIONS ===")
 
 	// Basic function call
-	fmt.Println("\n--- Basic Function ---")
+	fmt.Fprintln(w, "\n--- Basic Function ---")
 	result := add(5, 3)
-	fmt.Println("5 + 3 =", result)
+	fmt.Fprintln(w, "5 + 3 =", result)
 
 	// Multiple return values
-	fmt.Println("\n--- Multiple Return Values ---")
+	fmt.Fprintln(w, "\n--- Multiple Return Values ---")
 	sum, difference := addAndSubtract(10, 5)
-	fmt.Println("Sum:", sum, "Difference:", difference)
+	fmt.Fprintln(w, "Sum:", sum, "Difference:", difference)
 
 	// Named return values
-	fmt.Println("\n--- Named Return Values ---")
+	fmt.Fprintln(w, "\n--- Named Return Values ---")
 	area, perimeter := rectangleProperties(5, 3)
-	fmt.Println("Area:", area, "Perimeter:", perimeter)
+	fmt.Fprintln(w, "Area:", area, "Perimeter:", perimeter)
 
 	// Variadic function
-	fmt.Println("\n--- Variadic Function ---")
-	fmt.Println("Sum of numbers:", sumNumbers(1, 2, 3, 4, 5))
+	fmt.Fprintln(w, "\n--- Variadic Function ---")
+	fmt.Fprintln(w, "Sum of numbers:", sumNumbers(1, 2, 3, 4, 5))
 
 	// Passing a slice to a variadic function
 	numbers := []int{10, 20, 30, 40, 50}
-	fmt.Println("Sum of slice:", sumNumbers(numbers...))
+	fmt.Fprintln(w, "Sum of slice:", sumNumbers(numbers...))
 
 	// Functions as values
-	fmt.Println("\n--- Functions as Values ---")
+	fmt.Fprintln(w, "\n--- Functions as Values ---")
 	operation := add // Assign function to a variable
-	fmt.Println("Operation result:", operation(10, 5))
+	fmt.Fprintln(w, "Operation result:", operation(10, 5))
 
 	// Function as parameter
-	fmt.Println("\n--- Function as Parameter ---")
-	fmt.Println("Apply operation (add):", applyOperation(10, 5, add))
-	fmt.Println("Apply operation (multiply):", applyOperation(10, 5, multiply))
+	fmt.Fprintln(w, "\n--- Function as Parameter ---")
+	fmt.Fprintln(w, "Apply operation (add):", applyOperation(10, 5, add))
+	fmt.Fprintln(w, "Apply operation (multiply):", applyOperation(10, 5, multiply))
 
 	// Anonymous function
-	fmt.Println("\n--- Anonymous Function ---")
+	fmt.Fprintln(w, "\n--- Anonymous Function ---")
 	func(x, y int) {
-		fmt.Println("Anonymous function result:", x*y)
+		fmt.Fprintln(w, "Anonymous function result:", x*y)
 	}(5, 3)
 
 	// Closure (function that captures variables)
-	fmt.Println("\n--- Closure ---")
+	fmt.Fprintln(w, "\n--- Closure ---")
 	counter := createCounter()
-	fmt.Println("Counter:", counter()) // 1
-	fmt.Println("Counter:", counter()) // 2
-	fmt.Println("Counter:", counter()) // 3
+	fmt.Fprintln(w, "Counter:", counter()) // 1
+	fmt.Fprintln(w, "Counter:", counter()) // 2
+	fmt.Fprintln(w, "Counter:", counter()) // 3
 
 	// Another closure example
-	fmt.Println("\n--- Closure with Parameter ---")
+	fmt.Fprintln(w, "\n--- Closure with Parameter ---")
 	addFive := createAdder(5)
 	addTen := createAdder(10)
-	fmt.Println("Add 5 to 10:", addFive(10)) // 15
-	fmt.Println("Add 10 to 20:", addTen(20)) // 30
+	fmt.Fprintln(w, "Add 5 to 10:", addFive(10)) // 15
+	fmt.Fprintln(w, "Add 10 to 20:", addTen(20)) // 30
 
 	// Higher-order function (returns a function)
-	fmt.Println("\n--- Higher-Order Function ---")
+	fmt.Fprintln(w, "\n--- Higher-Order Function ---")
 	squareFunc := powerFunction(2)
 	cubeFunc := powerFunction(3)
-	fmt.Println("Square of 4:", squareFunc(4)) // 16
-	fmt.Println("Cube of 3:", cubeFunc(3))     // 27
+	fmt.Fprintln(w, "Square of 4:", squareFunc(4)) // 16
+	fmt.Fprintln(w, "Cube of 3:", cubeFunc(3))     // 27
 
 	// Function with deferred call
-	fmt.Println("\n--- Function with Deferred Call ---")
-	functionWithDefer()
+	fmt.Fprintln(w, "\n--- Function with Deferred Call ---")
+	functionWithDefer(w)
 
 	// Function with error return
-	fmt.Println("\n--- Function with Error Return ---")
+	fmt.Fprintln(w, "\n--- Function with Error Return ---")
 	result, err := divide(10, 2)
 	if err != nil {
-		fmt.Println("Error:", err)
+		fmt.Fprintln(w, "Error:", err)
 	} else {
-		fmt.Println("10 / 2 =", result)
+		fmt.Fprintln(w, "10 / 2 =", result)
 	}
 
 	result, err = divide(10, 0)
 	if err != nil {
-		fmt.Println("Error:", err)
+		fmt.Fprintln(w, "Error:", err)
 	} else {
-		fmt.Println("10 / 0 =", result)
+		fmt.Fprintln(w, "10 / 0 =", result)
 	}
 
 	// Method (function attached to a type)
-	fmt.Println("\n--- Method ---")
+	fmt.Fprintln(w, "\n--- Method ---")
 	p := person{firstName: "John", lastName: "Doe", age: 30}
-	fmt.Println("Full name:", p.fullName())
+	fmt.Fprintln(w, "Full name:", p.fullName())
 	p.increaseAge(5)
-	fmt.Println("New age:", p.age)
+	fmt.Fprintln(w, "New age:", p.age)
 
 	// Function with callbacks
-	fmt.Println("\n--- Function with Callbacks ---")
+	fmt.Fprintln(w, "\n--- Function with Callbacks ---")
 	inputStrings := []string{"hello", "world", "go", "programming"}
 
 	// Example 1: Convert to uppercase
 	uppercaseStrings := processStrings(inputStrings, func(s string) string {
 		return strings.ToUpper(s)
 	})
-	fmt.Println("Uppercase strings:", uppercaseStrings)
+	fmt.Fprintln(w, "Uppercase strings:", uppercaseStrings)
 
 	// Example 2: Add prefix
 	prefixedStrings := processStrings(inputStrings, func(s string) string {
 		return "prefix_" + s
 	})
-	fmt.Println("Prefixed strings:", prefixedStrings)
+	fmt.Fprintln(w, "Prefixed strings:", prefixedStrings)
 
 	// Example 3: Reverse strings
 	reversedStrings := processStrings(inputStrings, func(s string) string {
@@ -119,7 +121,8 @@ func main() {
 		}
 		return string(runes)
 	})
-	fmt.Println("Reversed strings:", reversedStrings)
+	fmt.Fprintln(w, "Reversed strings:", reversedStrings)
+	return nil
 }
 
 // Basic function
@@ -185,9 +188,9 @@ func powerFunction(exponent int) func(int) int {
 }
 
 // Function with deferred call
-func functionWithDefer() {
-	defer fmt.Println("This is executed last")
-	fmt.Println("This is executed first")
+func functionWithDefer(w io.Writer) {
+	defer fmt.Fprintln(w, "This is executed last")
+	fmt.Fprintln(w, "This is executed first")
 }
 
 // Function with error return
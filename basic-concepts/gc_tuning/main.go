@@ -0,0 +1,206 @@
+package gctuning
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/quiz"
+)
+
+// Run measures a workload under different GOGC, memory limit and pooling
+// settings, printing the collector's statistics to out. args are the
+// workload's flags.
+func Run(out io.Writer, args []string) error {
+	fs := flag.NewFlagSet("gc-tuning", flag.ContinueOnError)
+	requests := fs.Int("requests", 20000, "number of simulated requests")
+	bufferKB := fs.Int("buffer-kb", 16, "scratch buffer size per request, in KB")
+	liveMB := fs.Int("live-mb", 32, "long-lived heap kept during the run, in MB")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(out, "=========================================")
+	fmt.Fprintln(out, "GO GARBAGE COLLECTION TUNING EXAMPLES")
+	fmt.Fprintln(out, "=========================================")
+
+	w := Workload{Requests: *requests, BufferSize: *bufferKB << 10, LiveBytes: *liveMB << 20}
+	fmt.Fprintf(out, "Workload: %d requests, %d KB buffer each, %d MB live heap\n\n", *requests, *bufferKB, *liveMB)
+
+	GOGCExample(out, w)
+	MemoryLimitExample(out, w)
+	SyncPoolExample(out, w)
+
+	// Interview questions
+	GCInterviewQuestions(out)
+	return nil
+}
+
+// Workload simulates a server handling requests: each request allocates a
+// scratch buffer, and LiveBytes of long-lived data (a cache, say) stays
+// reachable throughout. The live heap matters because GOGC is relative to it.
+type Workload struct {
+	Requests   int
+	BufferSize int
+	LiveBytes  int
+}
+
+// Allocator hands out scratch buffers
+type Allocator interface {
+	Get() *[]byte
+	Put(*[]byte)
+}
+
+// HeapAllocator allocates a fresh buffer for every request
+type HeapAllocator struct {
+	Size int
+}
+
+func (a HeapAllocator) Get() *[]byte {
+	buf := make([]byte, a.Size)
+	return &buf
+}
+
+func (HeapAllocator) Put(*[]byte) {}
+
+// PoolAllocator reuses buffers through a sync.Pool. Pointers to slices are
+// pooled because putting a []byte in the pool would allocate to box it.
+type PoolAllocator struct {
+	pool sync.Pool
+}
+
+// NewPoolAllocator returns a PoolAllocator for buffers of the given size
+func NewPoolAllocator(size int) *PoolAllocator {
+	return &PoolAllocator{pool: sync.Pool{
+		New: func() any {
+			buf := make([]byte, size)
+			return &buf
+		},
+	}}
+}
+
+func (a *PoolAllocator) Get() *[]byte { return a.pool.Get().(*[]byte) }
+
+func (a *PoolAllocator) Put(buf *[]byte) { a.pool.Put(buf) }
+
+// Stats is the difference in runtime.MemStats across a run
+type Stats struct {
+	NumGC      uint32        // completed GC cycles
+	TotalAlloc uint64        // bytes allocated (including freed)
+	Mallocs    uint64        // heap objects allocated
+	PauseTotal time.Duration // stop-the-world pause time
+}
+
+// Measure runs fn and reports what it cost the allocator and collector.
+// ReadMemStats stops the world, so call it around a run, not inside it.
+func Measure(fn func()) Stats {
+	runtime.GC() // start from a clean heap so runs are comparable
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	fn()
+	runtime.ReadMemStats(&after)
+
+	return Stats{
+		NumGC:      after.NumGC - before.NumGC,
+		TotalAlloc: after.TotalAlloc - before.TotalAlloc,
+		Mallocs:    after.Mallocs - before.Mallocs,
+		PauseTotal: time.Duration(after.PauseTotalNs - before.PauseTotalNs),
+	}
+}
+
+// Simulate executes the workload with the given allocator and returns a
+// checksum so the work cannot be optimized away
+func Simulate(w Workload, alloc Allocator) int {
+	live := make([]byte, w.LiveBytes)
+	checksum := 0
+	for i := 0; i < w.Requests; i++ {
+		buf := alloc.Get()
+		b := *buf
+		for j := 0; j < len(b); j += 512 {
+			b[j] = byte(i)
+		}
+		checksum += int(b[0])
+		alloc.Put(buf)
+	}
+	runtime.KeepAlive(live)
+	return checksum
+}
+
+// WithGCPercent runs fn with GOGC set to percent (negative disables the
+// GC), then restores the previous setting. It is the programmatic form of
+// the GOGC environment variable.
+func WithGCPercent(percent int, fn func()) {
+	prev := debug.SetGCPercent(percent)
+	defer debug.SetGCPercent(prev)
+	fn()
+}
+
+// WithMemoryLimit runs fn with a soft memory limit in bytes, then restores
+// the previous limit. It is the programmatic form of GOMEMLIMIT.
+func WithMemoryLimit(limit int64, fn func()) {
+	prev := debug.SetMemoryLimit(limit)
+	defer debug.SetMemoryLimit(prev)
+	fn()
+}
+
+func printStats(w io.Writer, label string, s Stats) {
+	fmt.Fprintf(w, "%-22s GCs: %4d  allocated: %7.1f MB  objects: %7d  pauses: %v\n",
+		label, s.NumGC, float64(s.TotalAlloc)/(1<<20), s.Mallocs, s.PauseTotal)
+}
+
+// GOGCExample shows that a higher GOGC trades memory for fewer collections
+func GOGCExample(out io.Writer, w Workload) {
+	fmt.Fprintln(out, "=== GOGC EXAMPLE ===")
+	fmt.Fprintln(out, "The heap may grow GOGC% beyond the live heap before the next GC")
+
+	for _, pct := range []int{25, 100, 400} {
+		var s Stats
+		WithGCPercent(pct, func() {
+			s = Measure(func() { Simulate(w, HeapAllocator{Size: w.BufferSize}) })
+		})
+		printStats(out, fmt.Sprintf("GOGC=%d", pct), s)
+	}
+	fmt.Fprintln(out)
+}
+
+// MemoryLimitExample shows GOMEMLIMIT bounding the heap with GOGC=off
+func MemoryLimitExample(out io.Writer, w Workload) {
+	fmt.Fprintln(out, "=== GOMEMLIMIT EXAMPLE ===")
+	fmt.Fprintln(out, "With GOGC=off the GC only runs when the memory limit is approached")
+
+	limit := int64(w.LiveBytes) + 16<<20
+	var s Stats
+	WithGCPercent(-1, func() {
+		WithMemoryLimit(limit, func() {
+			s = Measure(func() { Simulate(w, HeapAllocator{Size: w.BufferSize}) })
+		})
+	})
+	printStats(out, fmt.Sprintf("GOGC=off, limit=%dMB", limit>>20), s)
+
+	WithMemoryLimit(math.MaxInt64, func() {
+		s = Measure(func() { Simulate(w, HeapAllocator{Size: w.BufferSize}) })
+	})
+	printStats(out, "default settings", s)
+	fmt.Fprintln(out)
+}
+
+// SyncPoolExample shows sync.Pool removing most per-request allocations
+func SyncPoolExample(out io.Writer, w Workload) {
+	fmt.Fprintln(out, "=== SYNC.POOL MITIGATION EXAMPLE ===")
+
+	printStats(out, "fresh buffers", Measure(func() { Simulate(w, HeapAllocator{Size: w.BufferSize}) }))
+	printStats(out, "sync.Pool buffers", Measure(func() { Simulate(w, NewPoolAllocator(w.BufferSize)) }))
+	fmt.Fprintln(out)
+}
+
+// GCInterviewQuestions lists common interview questions about the GC
+func GCInterviewQuestions(w io.Writer) {
+	if err := quiz.Print(w, "gc-tuning"); err != nil {
+		fmt.Fprintln(w, err)
+	}
+}
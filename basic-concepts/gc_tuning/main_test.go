@@ -0,0 +1,83 @@
+package gctuning
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+// testWorkload is big enough to trigger several GC cycles at default settings
+var testWorkload = Workload{Requests: 4000, BufferSize: 16 << 10, LiveBytes: 4 << 20}
+
+func TestRun_AllocatorsAgree(t *testing.T) {
+	w := Workload{Requests: 100, BufferSize: 1024, LiveBytes: 1024}
+	heap := Simulate(w, HeapAllocator{Size: w.BufferSize})
+	pool := Simulate(w, NewPoolAllocator(w.BufferSize))
+	if heap != pool {
+		t.Errorf("checksums differ: heap %d, pool %d", heap, pool)
+	}
+}
+
+func TestSyncPoolReducesAllocation(t *testing.T) {
+	if raceEnabled {
+		t.Skip("the race detector drops sync.Pool items on purpose")
+	}
+	heap := Measure(func() { Simulate(testWorkload, HeapAllocator{Size: testWorkload.BufferSize}) })
+	pool := Measure(func() { Simulate(testWorkload, NewPoolAllocator(testWorkload.BufferSize)) })
+
+	if pool.TotalAlloc*4 > heap.TotalAlloc {
+		t.Errorf("pool allocated %d bytes, heap %d; want the pool to allocate far less",
+			pool.TotalAlloc, heap.TotalAlloc)
+	}
+	if pool.NumGC > heap.NumGC {
+		t.Errorf("pool GCs = %d, heap GCs = %d; want no more with the pool", pool.NumGC, heap.NumGC)
+	}
+}
+
+func TestGOGCTradesMemoryForCollections(t *testing.T) {
+	run := func(pct int) Stats {
+		var s Stats
+		WithGCPercent(pct, func() {
+			s = Measure(func() { Simulate(testWorkload, HeapAllocator{Size: testWorkload.BufferSize}) })
+		})
+		return s
+	}
+
+	low, high := run(25), run(800)
+	if low.NumGC <= high.NumGC {
+		t.Errorf("GOGC=25 ran %d GCs, GOGC=800 ran %d; want more at the lower setting", low.NumGC, high.NumGC)
+	}
+}
+
+func TestMemoryLimitTriggersGCWhenGOGCOff(t *testing.T) {
+	var off, limited Stats
+	WithGCPercent(-1, func() {
+		off = Measure(func() { Simulate(testWorkload, HeapAllocator{Size: testWorkload.BufferSize}) })
+		WithMemoryLimit(int64(testWorkload.LiveBytes)+8<<20, func() {
+			limited = Measure(func() { Simulate(testWorkload, HeapAllocator{Size: testWorkload.BufferSize}) })
+		})
+	})
+
+	if off.NumGC != 0 {
+		t.Errorf("GOGC=off ran %d GCs; want 0", off.NumGC)
+	}
+	if limited.NumGC == 0 {
+		t.Error("GOGC=off with a memory limit ran no GCs; want the limit to trigger some")
+	}
+}
+
+func TestSettingsAreRestored(t *testing.T) {
+	prevPct := debug.SetGCPercent(100)
+	defer debug.SetGCPercent(prevPct)
+	prevLimit := debug.SetMemoryLimit(-1) // negative only reads the limit
+
+	WithGCPercent(10, func() {
+		WithMemoryLimit(1<<30, func() {})
+	})
+
+	if got := debug.SetGCPercent(100); got != 100 {
+		t.Errorf("GOGC after WithGCPercent = %d; want 100", got)
+	}
+	if got := debug.SetMemoryLimit(-1); got != prevLimit {
+		t.Errorf("memory limit after WithMemoryLimit = %d; want %d", got, prevLimit)
+	}
+}
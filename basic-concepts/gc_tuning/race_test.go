@@ -0,0 +1,5 @@
+//go:build race
+
+package gctuning
+
+const raceEnabled = true
@@ -0,0 +1,35 @@
+// Package constraints defines type sets for use as generic type constraints.
+// It mirrors golang.org/x/exp/constraints so the examples need no dependencies.
+package constraints
+
+// Signed is any signed integer type.
+// The ~ prefix also admits named types such as `type Celsius int`.
+type Signed interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64
+}
+
+// Unsigned is any unsigned integer type
+type Unsigned interface {
+	~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// Integer is any integer type
+type Integer interface {
+	Signed | Unsigned
+}
+
+// Float is any floating-point type
+type Float interface {
+	~float32 | ~float64
+}
+
+// Number is any integer or floating-point type
+type Number interface {
+	Integer | Float
+}
+
+// Ordered is any type that supports the < <= >= > operators.
+// Since Go 1.21 the standard library provides the same set as cmp.Ordered.
+type Ordered interface {
+	Integer | Float | ~string
+}
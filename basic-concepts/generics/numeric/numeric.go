@@ -0,0 +1,63 @@
+// Package numeric provides generic helpers over the type sets in the
+// constraints package.
+package numeric
+
+import "github.com/rehan/go-interview-prep/basic-concepts/generics/constraints"
+
+// Min returns the smallest of its arguments.
+// Taking first separately makes an empty call a compile error instead of a runtime panic.
+func Min[T constraints.Ordered](first T, rest ...T) T {
+	m := first
+	for _, v := range rest {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
+
+// Max returns the largest of its arguments
+func Max[T constraints.Ordered](first T, rest ...T) T {
+	m := first
+	for _, v := range rest {
+		if v > m {
+			m = v
+		}
+	}
+	return m
+}
+
+// Sum adds up values; the sum of no values is zero.
+// Overflow wraps around exactly as it would for the non-generic type.
+func Sum[T constraints.Number](values ...T) T {
+	var total T
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
+
+// Clamp limits v to the range [lo, hi]. It panics if lo > hi.
+func Clamp[T constraints.Ordered](v, lo, hi T) T {
+	if lo > hi {
+		panic("numeric: Clamp called with lo > hi")
+	}
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+// Abs returns the absolute value of v.
+// Unsigned types are excluded because they are never negative.
+// Note that Abs of the most negative integer overflows back to itself,
+// e.g. Abs(int8(-128)) == -128, just like the non-generic expression -v.
+func Abs[T constraints.Signed | constraints.Float](v T) T {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,138 @@
+package numeric
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// Celsius checks that the ~ in the constraints admits named types
+type Celsius int
+
+func TestMin(t *testing.T) {
+	if got := Min(3, 1, 2); got != 1 {
+		t.Errorf("Min(3, 1, 2) = %d; want 1", got)
+	}
+	if got := Min(7); got != 7 {
+		t.Errorf("Min(7) = %d; want 7", got)
+	}
+	if got := Min(-1.5, 2.5, -3.25); got != -3.25 {
+		t.Errorf("Min(float64) = %v; want -3.25", got)
+	}
+	if got := Min[uint8](200, 10, 255); got != 10 {
+		t.Errorf("Min(uint8) = %d; want 10", got)
+	}
+	if got := Min("pear", "apple", "zucchini"); got != "apple" {
+		t.Errorf("Min(string) = %q; want \"apple\"", got)
+	}
+	if got := Min(Celsius(20), Celsius(-5)); got != -5 {
+		t.Errorf("Min(Celsius) = %d; want -5", got)
+	}
+}
+
+func TestMax(t *testing.T) {
+	if got := Max(3, 1, 2); got != 3 {
+		t.Errorf("Max(3, 1, 2) = %d; want 3", got)
+	}
+	if got := Max[int64](math.MinInt64, -1); got != -1 {
+		t.Errorf("Max(int64) = %d; want -1", got)
+	}
+	if got := Max[float32](1.5, 1.25); got != 1.5 {
+		t.Errorf("Max(float32) = %v; want 1.5", got)
+	}
+	if got := Max("b", "a", "c"); got != "c" {
+		t.Errorf("Max(string) = %q; want \"c\"", got)
+	}
+}
+
+func TestSum(t *testing.T) {
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{"int", Sum(1, 2, 3, 4), 10},
+		{"empty", Sum[int](), 0},
+		{"float64", Sum(0.5, 0.25), 0.75},
+		{"uint16", Sum[uint16](100, 200), uint16(300)},
+		{"named type", Sum(Celsius(10), Celsius(-3)), Celsius(7)},
+		{"int8 wraps on overflow", Sum[int8](127, 1), int8(-128)},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.got != tc.want {
+				t.Errorf("got %v (%T); want %v (%T)", tc.got, tc.got, tc.want, tc.want)
+			}
+		})
+	}
+}
+
+func TestClamp(t *testing.T) {
+	tests := []struct {
+		v, lo, hi, want int
+	}{
+		{5, 0, 10, 5},
+		{-1, 0, 10, 0},
+		{11, 0, 10, 10},
+		{0, 0, 0, 0},
+	}
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("%d in [%d,%d]", tc.v, tc.lo, tc.hi), func(t *testing.T) {
+			if got := Clamp(tc.v, tc.lo, tc.hi); got != tc.want {
+				t.Errorf("Clamp(%d, %d, %d) = %d; want %d", tc.v, tc.lo, tc.hi, got, tc.want)
+			}
+		})
+	}
+
+	if got := Clamp(1.7, 0.0, 1.0); got != 1.0 {
+		t.Errorf("Clamp(float64) = %v; want 1", got)
+	}
+	if got := Clamp("m", "a", "f"); got != "f" {
+		t.Errorf("Clamp(string) = %q; want \"f\"", got)
+	}
+}
+
+func TestClamp_PanicsOnInvertedRange(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected Clamp(5, 10, 0) to panic")
+		}
+	}()
+	Clamp(5, 10, 0)
+}
+
+func TestAbs(t *testing.T) {
+	if got := Abs(-5); got != 5 {
+		t.Errorf("Abs(-5) = %d; want 5", got)
+	}
+	if got := Abs(5); got != 5 {
+		t.Errorf("Abs(5) = %d; want 5", got)
+	}
+	if got := Abs(-2.5); got != 2.5 {
+		t.Errorf("Abs(-2.5) = %v; want 2.5", got)
+	}
+	if got := Abs[float32](-0.5); got != 0.5 {
+		t.Errorf("Abs(float32) = %v; want 0.5", got)
+	}
+	if got := Abs(Celsius(-40)); got != 40 {
+		t.Errorf("Abs(Celsius) = %d; want 40", got)
+	}
+	// Two's complement has no positive counterpart for the minimum value
+	if got := Abs(int8(math.MinInt8)); got != math.MinInt8 {
+		t.Errorf("Abs(int8(-128)) = %d; want -128 (overflow)", got)
+	}
+}
+
+func ExampleClamp() {
+	fmt.Println(Clamp(150, 0, 100))
+	fmt.Println(Clamp(-0.5, 0.0, 1.0))
+	// Output:
+	// 100
+	// 0
+}
+
+func ExampleSum() {
+	prices := []float64{9.99, 20.01}
+	fmt.Printf("%.2f\n", Sum(prices...))
+	// Output: 30.00
+}
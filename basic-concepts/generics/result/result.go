@@ -0,0 +1,181 @@
+// Package result provides Result and Option types in the style of Rust's,
+// built with generics.
+//
+// They are here because interviewers ask about them, not because Go code
+// should use them by default. Idiomatic Go returns (T, error) and (T, bool):
+// the standard library, errors.Is/As, and every linter understand those, and
+// an early return is as short as a chain of AndThen calls. Result and Option
+// earn their place in narrower cases:
+//
+//   - storing an outcome for later, e.g. sending it over a channel or
+//     collecting the results of parallel work into a slice
+//   - pipelines of transformations where threading err by hand is noisy
+//   - distinguishing "absent" from the zero value in a struct field without
+//     resorting to a pointer
+//
+// Convert at the boundaries with Of, Get, Some/FromPtr and Option.Get so the
+// rest of the code keeps its usual shape.
+package result
+
+// Result holds either a value or an error
+type Result[T any] struct {
+	value T
+	err   error
+}
+
+// Ok returns a successful Result
+func Ok[T any](v T) Result[T] {
+	return Result[T]{value: v}
+}
+
+// Err returns a failed Result. It panics if err is nil, since a failed
+// Result without an error would report success.
+func Err[T any](err error) Result[T] {
+	if err == nil {
+		panic("result: Err called with nil error")
+	}
+	return Result[T]{err: err}
+}
+
+// Of converts a (T, error) pair, as returned by most Go functions
+func Of[T any](v T, err error) Result[T] {
+	if err != nil {
+		return Result[T]{err: err}
+	}
+	return Result[T]{value: v}
+}
+
+// IsOk reports whether r holds a value
+func (r Result[T]) IsOk() bool { return r.err == nil }
+
+// Err returns the error, or nil for a successful Result
+func (r Result[T]) Err() error { return r.err }
+
+// Get converts back to the (T, error) form
+func (r Result[T]) Get() (T, error) { return r.value, r.err }
+
+// UnwrapOr returns the value, or fallback if r failed
+func (r Result[T]) UnwrapOr(fallback T) T {
+	if r.err != nil {
+		return fallback
+	}
+	return r.value
+}
+
+// Must returns the value and panics if r failed
+func (r Result[T]) Must() T {
+	if r.err != nil {
+		panic(r.err)
+	}
+	return r.value
+}
+
+// Map applies fn to a successful value and passes errors through.
+// Methods cannot have their own type parameters, so Map and AndThen are
+// functions rather than methods on Result.
+func Map[T, U any](r Result[T], fn func(T) U) Result[U] {
+	if r.err != nil {
+		return Result[U]{err: r.err}
+	}
+	return Ok(fn(r.value))
+}
+
+// AndThen chains a step that can itself fail
+func AndThen[T, U any](r Result[T], fn func(T) Result[U]) Result[U] {
+	if r.err != nil {
+		return Result[U]{err: r.err}
+	}
+	return fn(r.value)
+}
+
+// Collect turns a slice of Results into a Result of a slice, failing with
+// the first error
+func Collect[T any](results []Result[T]) Result[[]T] {
+	values := make([]T, 0, len(results))
+	for _, r := range results {
+		if r.err != nil {
+			return Result[[]T]{err: r.err}
+		}
+		values = append(values, r.value)
+	}
+	return Ok(values)
+}
+
+// Option holds a value or nothing
+type Option[T any] struct {
+	value T
+	ok    bool
+}
+
+// Some returns an Option holding v
+func Some[T any](v T) Option[T] {
+	return Option[T]{value: v, ok: true}
+}
+
+// None returns an empty Option
+func None[T any]() Option[T] {
+	return Option[T]{}
+}
+
+// FromPtr converts a pointer, the other common way Go spells "optional"
+func FromPtr[T any](p *T) Option[T] {
+	if p == nil {
+		return None[T]()
+	}
+	return Some(*p)
+}
+
+// FromLookup converts a comma-ok pair such as a map lookup
+func FromLookup[T any](v T, ok bool) Option[T] {
+	if !ok {
+		return None[T]()
+	}
+	return Some(v)
+}
+
+// IsSome reports whether o holds a value
+func (o Option[T]) IsSome() bool { return o.ok }
+
+// Get converts back to the comma-ok form
+func (o Option[T]) Get() (T, bool) { return o.value, o.ok }
+
+// Ptr converts back to a pointer, nil when o is empty
+func (o Option[T]) Ptr() *T {
+	if !o.ok {
+		return nil
+	}
+	v := o.value
+	return &v
+}
+
+// UnwrapOr returns the value, or fallback if o is empty
+func (o Option[T]) UnwrapOr(fallback T) T {
+	if !o.ok {
+		return fallback
+	}
+	return o.value
+}
+
+// OkOr converts to a Result, using err when o is empty
+func (o Option[T]) OkOr(err error) Result[T] {
+	if !o.ok {
+		return Err[T](err)
+	}
+	return Ok(o.value)
+}
+
+// MapOption applies fn to the value, if there is one
+func MapOption[T, U any](o Option[T], fn func(T) U) Option[U] {
+	if !o.ok {
+		return None[U]()
+	}
+	return Some(fn(o.value))
+}
+
+// AndThenOption chains a step that may itself produce nothing
+func AndThenOption[T, U any](o Option[T], fn func(T) Option[U]) Option[U] {
+	if !o.ok {
+		return None[U]()
+	}
+	return fn(o.value)
+}
@@ -0,0 +1,224 @@
+package result
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+var errBoom = errors.New("boom")
+
+func TestResult(t *testing.T) {
+	ok := Ok(42)
+	if !ok.IsOk() || ok.Err() != nil || ok.UnwrapOr(0) != 42 || ok.Must() != 42 {
+		t.Errorf("Ok(42) = %+v; want a successful Result holding 42", ok)
+	}
+
+	failed := Err[int](errBoom)
+	if failed.IsOk() || !errors.Is(failed.Err(), errBoom) || failed.UnwrapOr(-1) != -1 {
+		t.Errorf("Err(errBoom) = %+v; want a failed Result", failed)
+	}
+}
+
+func TestOf_RoundTrip(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int
+		wantErr bool
+	}{
+		{"12", 12, false},
+		{"x", 0, true},
+	}
+	for _, tc := range tests {
+		v, err := Of(strconv.Atoi(tc.input)).Get()
+		if (err != nil) != tc.wantErr || v != tc.want {
+			t.Errorf("Of(Atoi(%q)).Get() = %d, %v; want %d, error %v", tc.input, v, err, tc.want, tc.wantErr)
+		}
+	}
+}
+
+func TestErr_PanicsOnNil(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Err(nil) did not panic")
+		}
+	}()
+	Err[int](nil)
+}
+
+func TestMust_PanicsWithError(t *testing.T) {
+	defer func() {
+		if r := recover(); r != errBoom {
+			t.Errorf("Must panicked with %v; want errBoom", r)
+		}
+	}()
+	Err[int](errBoom).Must()
+}
+
+func TestMapAndThen(t *testing.T) {
+	double := func(n int) int { return n * 2 }
+	parse := func(s string) Result[int] { return Of(strconv.Atoi(s)) }
+
+	if got := Map(Ok(21), double).UnwrapOr(0); got != 42 {
+		t.Errorf("Map(Ok(21), double) = %d; want 42", got)
+	}
+	if got := Map(Err[int](errBoom), double); !errors.Is(got.Err(), errBoom) {
+		t.Errorf("Map over an error = %+v; want errBoom passed through", got)
+	}
+
+	if got := AndThen(Ok("7"), parse).UnwrapOr(0); got != 7 {
+		t.Errorf("AndThen(Ok(\"7\"), parse) = %d; want 7", got)
+	}
+	if got := AndThen(Ok("seven"), parse); got.IsOk() {
+		t.Error("AndThen(Ok(\"seven\"), parse) succeeded; want the parse error")
+	}
+
+	calls := 0
+	AndThen(Err[string](errBoom), func(s string) Result[int] { calls++; return parse(s) })
+	if calls != 0 {
+		t.Errorf("AndThen called fn %d times after an error; want 0", calls)
+	}
+}
+
+func TestCollect(t *testing.T) {
+	got := Collect([]Result[int]{Ok(1), Ok(2), Ok(3)})
+	if v, err := got.Get(); err != nil || !reflect.DeepEqual(v, []int{1, 2, 3}) {
+		t.Errorf("Collect(all ok) = %v, %v; want [1 2 3]", v, err)
+	}
+
+	got = Collect([]Result[int]{Ok(1), Err[int](errBoom), Err[int](errors.New("second"))})
+	if !errors.Is(got.Err(), errBoom) {
+		t.Errorf("Collect(with errors) = %v; want the first error", got.Err())
+	}
+}
+
+func TestOption(t *testing.T) {
+	some := Some("x")
+	if v, ok := some.Get(); !ok || v != "x" || !some.IsSome() {
+		t.Errorf("Some(\"x\").Get() = %q, %v", v, ok)
+	}
+	none := None[string]()
+	if v, ok := none.Get(); ok || v != "" || none.UnwrapOr("default") != "default" {
+		t.Errorf("None().Get() = %q, %v", v, ok)
+	}
+
+	// Some of the zero value is still present, which a bare zero cannot express
+	if !Some(0).IsSome() {
+		t.Error("Some(0).IsSome() = false; want true")
+	}
+}
+
+func TestOption_Conversions(t *testing.T) {
+	n := 5
+	if got := FromPtr(&n).UnwrapOr(0); got != 5 {
+		t.Errorf("FromPtr(&5) = %d; want 5", got)
+	}
+	if FromPtr[int](nil).IsSome() {
+		t.Error("FromPtr(nil) is Some; want None")
+	}
+
+	p := Some(9).Ptr()
+	if p == nil || *p != 9 {
+		t.Errorf("Some(9).Ptr() = %v; want pointer to 9", p)
+	}
+	if None[int]().Ptr() != nil {
+		t.Error("None().Ptr() != nil")
+	}
+
+	m := map[string]int{"a": 1}
+	v, ok := m["a"]
+	if !FromLookup(v, ok).IsSome() {
+		t.Error(`FromLookup(m["a"]) is None; want Some`)
+	}
+	v, ok = m["b"]
+	if FromLookup(v, ok).IsSome() {
+		t.Error(`FromLookup(m["b"]) is Some; want None`)
+	}
+
+	if r := None[int]().OkOr(errBoom); !errors.Is(r.Err(), errBoom) {
+		t.Errorf("None().OkOr(errBoom) = %+v; want errBoom", r)
+	}
+	if r := Some(3).OkOr(errBoom); r.UnwrapOr(0) != 3 {
+		t.Errorf("Some(3).OkOr = %+v; want Ok(3)", r)
+	}
+}
+
+func TestMapOptionAndThenOption(t *testing.T) {
+	length := func(s string) int { return len(s) }
+	if got := MapOption(Some("four"), length).UnwrapOr(0); got != 4 {
+		t.Errorf("MapOption(Some(\"four\"), len) = %d; want 4", got)
+	}
+	if MapOption(None[string](), length).IsSome() {
+		t.Error("MapOption(None) is Some; want None")
+	}
+
+	positive := func(n int) Option[int] {
+		if n > 0 {
+			return Some(n)
+		}
+		return None[int]()
+	}
+	if !AndThenOption(Some(1), positive).IsSome() || AndThenOption(Some(-1), positive).IsSome() {
+		t.Error("AndThenOption did not apply the step")
+	}
+}
+
+// When to use it: collecting the outcomes of concurrent work, where a
+// (T, error) pair cannot be sent over a channel as one value
+func ExampleCollect() {
+	inputs := []string{"1", "2", "3"}
+	ch := make(chan Result[int], len(inputs))
+	for _, in := range inputs {
+		go func() { ch <- Of(strconv.Atoi(in)) }()
+	}
+
+	results := make([]Result[int], 0, len(inputs))
+	for range inputs {
+		results = append(results, <-ch)
+	}
+
+	values, err := Collect(results).Get()
+	fmt.Println(len(values), err)
+	// Output: 3 <nil>
+}
+
+// When not to use it: a straight sequence of fallible calls reads better
+// with early returns, and errors keep their usual handling
+func ExampleAndThen() {
+	parse := func(s string) Result[int] { return Of(strconv.Atoi(s)) }
+	half := func(n int) Result[int] {
+		if n%2 != 0 {
+			return Err[int](fmt.Errorf("%d is odd", n))
+		}
+		return Ok(n / 2)
+	}
+
+	// Chained
+	fmt.Println(AndThen(parse("10"), half).UnwrapOr(-1))
+
+	// Idiomatic equivalent
+	n, err := strconv.Atoi("7")
+	if err == nil && n%2 != 0 {
+		err = fmt.Errorf("%d is odd", n)
+	}
+	fmt.Println(err)
+	// Output:
+	// 5
+	// 7 is odd
+}
+
+// Option distinguishes "not set" from the zero value without a pointer
+func ExampleOption() {
+	type Settings struct {
+		Retries Option[int]
+	}
+
+	for _, s := range []Settings{{}, {Retries: Some(0)}} {
+		fmt.Println(s.Retries.UnwrapOr(3))
+	}
+	// Output:
+	// 3
+	// 0
+}
@@ -0,0 +1,308 @@
+package iterators
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"iter"
+	"maps"
+	"slices"
+
+	"github.com/rehan/go-interview-prep/pkg/quiz"
+)
+
+// Run prints the range-over-func iterator examples to w
+func Run(w io.Writer) error {
+	fmt.Fprintln(w, "=========================================")
+	fmt.Fprintln(w, "GO ITERATORS (RANGE-OVER-FUNC) EXAMPLES")
+	fmt.Fprintln(w, "=========================================")
+
+	// Linked list iterators
+	LinkedListExample(w)
+
+	// Tree traversal
+	TreeExample(w)
+
+	// Sorted map iteration
+	SortedMapExample(w)
+
+	// Pull iterators
+	PullExample(w)
+
+	// Interview questions
+	IteratorsInterviewQuestions(w)
+	return nil
+}
+
+// Node is a singly linked list node
+type Node[T any] struct {
+	val  T
+	next *Node[T]
+}
+
+// LinkedList is a generic version of the list in data-structures/link-list
+type LinkedList[T any] struct {
+	head *Node[T]
+	tail *Node[T]
+}
+
+// Push appends a value to the end of the list
+func (l *LinkedList[T]) Push(val T) {
+	n := &Node[T]{val: val}
+	if l.tail == nil {
+		l.head, l.tail = n, n
+		return
+	}
+	l.tail.next = n
+	l.tail = n
+}
+
+// All yields every value from head to tail.
+// Returning iter.Seq lets callers write: for v := range list.All()
+func (l *LinkedList[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for n := l.head; n != nil; n = n.next {
+			// yield returns false when the loop body breaks; we must stop then
+			if !yield(n.val) {
+				return
+			}
+		}
+	}
+}
+
+// Indexed yields (index, value) pairs, like ranging over a slice
+func (l *LinkedList[T]) Indexed() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		i := 0
+		for n := l.head; n != nil; n = n.next {
+			if !yield(i, n.val) {
+				return
+			}
+			i++
+		}
+	}
+}
+
+// Tree is an unbalanced binary search tree
+type Tree[K cmp.Ordered, V any] struct {
+	root *treeNode[K, V]
+	size int
+}
+
+type treeNode[K cmp.Ordered, V any] struct {
+	key         K
+	value       V
+	left, right *treeNode[K, V]
+}
+
+// Insert adds or replaces a key
+func (t *Tree[K, V]) Insert(key K, value V) {
+	p := &t.root
+	for *p != nil {
+		switch c := cmp.Compare(key, (*p).key); {
+		case c < 0:
+			p = &(*p).left
+		case c > 0:
+			p = &(*p).right
+		default:
+			(*p).value = value
+			return
+		}
+	}
+	*p = &treeNode[K, V]{key: key, value: value}
+	t.size++
+}
+
+// InOrder yields key/value pairs in ascending key order
+func (t *Tree[K, V]) InOrder() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		t.root.walk(yield)
+	}
+}
+
+// walk does the recursive in-order traversal and reports whether to continue.
+// Propagating the bool is what lets an early break stop the whole recursion.
+func (n *treeNode[K, V]) walk(yield func(K, V) bool) bool {
+	if n == nil {
+		return true
+	}
+	return n.left.walk(yield) && yield(n.key, n.value) && n.right.walk(yield)
+}
+
+// SortedKeys yields the keys of m in ascending order.
+// Plain map iteration order is deliberately randomized by the runtime.
+func SortedKeys[K cmp.Ordered, V any](m map[K]V) iter.Seq[K] {
+	return slices.Values(slices.Sorted(maps.Keys(m)))
+}
+
+// SortedEntries yields key/value pairs of m ordered by key
+func SortedEntries[K cmp.Ordered, V any](m map[K]V) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for k := range SortedKeys(m) {
+			if !yield(k, m[k]) {
+				return
+			}
+		}
+	}
+}
+
+// Filter returns the values of seq for which keep returns true
+func Filter[T any](seq iter.Seq[T], keep func(T) bool) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v := range seq {
+			if keep(v) && !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Take yields at most n values of seq
+func Take[T any](seq iter.Seq[T], n int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if n <= 0 {
+			return
+		}
+		i := 0
+		for v := range seq {
+			if !yield(v) {
+				return
+			}
+			i++
+			if i == n {
+				return
+			}
+		}
+	}
+}
+
+// Zip pairs up two sequences, stopping at the shorter one.
+// Two push iterators cannot be advanced in lockstep with range,
+// so this converts both to pull iterators with iter.Pull.
+func Zip[A, B any](a iter.Seq[A], b iter.Seq[B]) iter.Seq2[A, B] {
+	return func(yield func(A, B) bool) {
+		nextA, stopA := iter.Pull(a)
+		defer stopA() // stop releases the goroutine-like state behind Pull
+		nextB, stopB := iter.Pull(b)
+		defer stopB()
+
+		for {
+			va, okA := nextA()
+			vb, okB := nextB()
+			if !okA || !okB {
+				return
+			}
+			if !yield(va, vb) {
+				return
+			}
+		}
+	}
+}
+
+// MergeSorted merges two ascending sequences into one ascending sequence
+func MergeSorted[T cmp.Ordered](a, b iter.Seq[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		nextA, stopA := iter.Pull(a)
+		defer stopA()
+		nextB, stopB := iter.Pull(b)
+		defer stopB()
+
+		va, okA := nextA()
+		vb, okB := nextB()
+		for okA || okB {
+			if okA && (!okB || va <= vb) {
+				if !yield(va) {
+					return
+				}
+				va, okA = nextA()
+			} else {
+				if !yield(vb) {
+					return
+				}
+				vb, okB = nextB()
+			}
+		}
+	}
+}
+
+// LinkedListExample ranges over a linked list
+func LinkedListExample(w io.Writer) {
+	fmt.Fprintln(w, "=== LINKED LIST ITERATOR EXAMPLE ===")
+
+	var list LinkedList[int]
+	for _, v := range []int{2, 4, 45, 3, 23} {
+		list.Push(v)
+	}
+
+	for v := range list.All() {
+		fmt.Fprintf(w, "%d->", v)
+	}
+	fmt.Fprintln(w, "nil")
+
+	for i, v := range list.Indexed() {
+		if i == 2 {
+			break // the iterator sees yield return false and stops
+		}
+		fmt.Fprintf(w, "index %d: %d\n", i, v)
+	}
+
+	even := Filter(list.All(), func(v int) bool { return v%2 == 0 })
+	fmt.Fprintln(w, "Even values:", slices.Collect(even))
+	fmt.Fprintln(w)
+}
+
+// TreeExample walks a BST in order
+func TreeExample(w io.Writer) {
+	fmt.Fprintln(w, "=== TREE IN-ORDER ITERATOR EXAMPLE ===")
+
+	var tree Tree[string, int]
+	for i, word := range []string{"mango", "apple", "peach", "banana", "cherry"} {
+		tree.Insert(word, i)
+	}
+
+	for key, value := range tree.InOrder() {
+		fmt.Fprintf(w, "%s=%d ", key, value)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w)
+}
+
+// SortedMapExample iterates a map in a deterministic order
+func SortedMapExample(w io.Writer) {
+	fmt.Fprintln(w, "=== SORTED MAP ITERATION EXAMPLE ===")
+
+	ages := map[string]int{"Charlie": 35, "Alice": 30, "Bob": 25}
+	for name, age := range SortedEntries(ages) {
+		fmt.Fprintf(w, "%s is %d\n", name, age)
+	}
+	fmt.Fprintln(w)
+}
+
+// PullExample converts push iterators into pull iterators
+func PullExample(w io.Writer) {
+	fmt.Fprintln(w, "=== PULL ITERATOR EXAMPLE ===")
+
+	names := slices.Values([]string{"Alice", "Bob", "Charlie"})
+	scores := slices.Values([]int{90, 85})
+	for name, score := range Zip(names, scores) {
+		fmt.Fprintf(w, "%s scored %d\n", name, score)
+	}
+
+	merged := MergeSorted(slices.Values([]int{1, 4, 9}), slices.Values([]int{2, 3, 10}))
+	fmt.Fprintln(w, "Merged:", slices.Collect(merged))
+
+	// Manual pulling, e.g. to peek at the first element
+	next, stop := iter.Pull(slices.Values([]string{"first", "second"}))
+	defer stop()
+	if v, ok := next(); ok {
+		fmt.Fprintln(w, "Pulled:", v)
+	}
+	fmt.Fprintln(w)
+}
+
+// IteratorsInterviewQuestions lists common interview questions about iterators
+func IteratorsInterviewQuestions(w io.Writer) {
+	if err := quiz.Print(w, "iterators"); err != nil {
+		fmt.Fprintln(w, err)
+	}
+}
@@ -0,0 +1,217 @@
+package iterators
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+	"testing"
+
+	"github.com/rehan/go-interview-prep/pkg/quickcheck"
+)
+
+func newList(values ...int) *LinkedList[int] {
+	var l LinkedList[int]
+	for _, v := range values {
+		l.Push(v)
+	}
+	return &l
+}
+
+func TestLinkedList_All(t *testing.T) {
+	l := newList(1, 2, 3)
+	if got := slices.Collect(l.All()); !slices.Equal(got, []int{1, 2, 3}) {
+		t.Errorf("All() = %v; want [1 2 3]", got)
+	}
+
+	var empty LinkedList[string]
+	if got := slices.Collect(empty.All()); len(got) != 0 {
+		t.Errorf("empty All() = %v; want []", got)
+	}
+}
+
+func TestLinkedList_EarlyBreak(t *testing.T) {
+	l := newList(1, 2, 3, 4)
+	var seen []int
+	for v := range l.All() {
+		if v == 3 {
+			break
+		}
+		seen = append(seen, v)
+	}
+	if !slices.Equal(seen, []int{1, 2}) {
+		t.Errorf("seen = %v; want [1 2]", seen)
+	}
+}
+
+func TestLinkedList_Indexed(t *testing.T) {
+	l := newList(10, 20)
+	var got []string
+	for i, v := range l.Indexed() {
+		got = append(got, fmt.Sprintf("%d:%d", i, v))
+	}
+	if want := []string{"0:10", "1:20"}; !slices.Equal(got, want) {
+		t.Errorf("Indexed() = %v; want %v", got, want)
+	}
+}
+
+func TestTree_InOrder(t *testing.T) {
+	var tree Tree[int, string]
+	for _, k := range []int{50, 30, 70, 20, 40, 60, 80} {
+		tree.Insert(k, fmt.Sprint("v", k))
+	}
+	tree.Insert(40, "replaced")
+
+	var keys []int
+	var values []string
+	for k, v := range tree.InOrder() {
+		keys = append(keys, k)
+		values = append(values, v)
+	}
+
+	if want := []int{20, 30, 40, 50, 60, 70, 80}; !slices.Equal(keys, want) {
+		t.Errorf("keys = %v; want %v", keys, want)
+	}
+	if values[2] != "replaced" {
+		t.Errorf("value for 40 = %q; want \"replaced\"", values[2])
+	}
+	if tree.size != 7 {
+		t.Errorf("size = %d; want 7 (replacing must not grow the tree)", tree.size)
+	}
+}
+
+func TestTree_InOrderEarlyBreak(t *testing.T) {
+	var tree Tree[int, int]
+	for _, k := range []int{5, 3, 8, 1, 4, 7, 9} {
+		tree.Insert(k, k)
+	}
+
+	// Breaking inside the left subtree must not panic or keep yielding
+	var keys []int
+	for k := range tree.InOrder() {
+		keys = append(keys, k)
+		if k == 3 {
+			break
+		}
+	}
+	if !slices.Equal(keys, []int{1, 3}) {
+		t.Errorf("keys = %v; want [1 3]", keys)
+	}
+}
+
+// TestTree_Properties inserts random keys, repeats included, and checks
+// the walk yields each key once, in order, with its last value
+func TestTree_Properties(t *testing.T) {
+	type insert struct{ Key, Value int }
+	quickcheck.Check(t, quickcheck.Any[[]insert](), func(inserts []insert) error {
+		var tree Tree[int, int]
+		last := make(map[int]int)
+		for _, in := range inserts {
+			tree.Insert(in.Key, in.Value)
+			last[in.Key] = in.Value
+		}
+		var keys []int
+		for k, v := range tree.InOrder() {
+			if len(keys) > 0 && k <= keys[len(keys)-1] {
+				return fmt.Errorf("key %d after %d", k, keys[len(keys)-1])
+			}
+			if want, ok := last[k]; !ok || v != want {
+				return fmt.Errorf("key %d has value %d; inserted %d (%v)", k, v, want, ok)
+			}
+			keys = append(keys, k)
+		}
+		if len(keys) != len(last) || tree.size != len(last) {
+			return fmt.Errorf("walked %d keys, size %d; inserted %d", len(keys), tree.size, len(last))
+		}
+		return nil
+	})
+}
+
+func TestSortedKeysAndEntries(t *testing.T) {
+	m := map[string]int{"c": 3, "a": 1, "b": 2}
+
+	// Repeat to make sure randomized map order never leaks through
+	for i := 0; i < 20; i++ {
+		if got := slices.Collect(SortedKeys(m)); !slices.Equal(got, []string{"a", "b", "c"}) {
+			t.Fatalf("SortedKeys = %v; want [a b c]", got)
+		}
+	}
+
+	var pairs []string
+	for k, v := range SortedEntries(m) {
+		pairs = append(pairs, fmt.Sprintf("%s=%d", k, v))
+	}
+	if want := []string{"a=1", "b=2", "c=3"}; !slices.Equal(pairs, want) {
+		t.Errorf("SortedEntries = %v; want %v", pairs, want)
+	}
+}
+
+func TestFilterAndTake(t *testing.T) {
+	evens := Filter(slices.Values([]int{1, 2, 3, 4, 5, 6}), func(v int) bool { return v%2 == 0 })
+	if got := slices.Collect(Take(evens, 2)); !slices.Equal(got, []int{2, 4}) {
+		t.Errorf("Take(Filter(...), 2) = %v; want [2 4]", got)
+	}
+	if got := slices.Collect(Take(evens, 0)); len(got) != 0 {
+		t.Errorf("Take(_, 0) = %v; want []", got)
+	}
+}
+
+// countingSeq yields 0..n-1 and records how many values were produced
+func countingSeq(n int, produced *int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for i := 0; i < n; i++ {
+			*produced++
+			if !yield(i) {
+				return
+			}
+		}
+	}
+}
+
+func TestTake_IsLazy(t *testing.T) {
+	produced := 0
+	for range Take(countingSeq(1000, &produced), 3) {
+	}
+	if produced != 3 {
+		t.Errorf("source produced %d values; want 3", produced)
+	}
+}
+
+func TestZip(t *testing.T) {
+	var got []string
+	for a, b := range Zip(slices.Values([]string{"x", "y", "z"}), slices.Values([]int{1, 2})) {
+		got = append(got, fmt.Sprint(a, b))
+	}
+	if want := []string{"x1", "y2"}; !slices.Equal(got, want) {
+		t.Errorf("Zip = %v; want %v", got, want)
+	}
+}
+
+func TestMergeSorted(t *testing.T) {
+	tests := []struct {
+		a, b, want []int
+	}{
+		{[]int{1, 4, 9}, []int{2, 3, 10}, []int{1, 2, 3, 4, 9, 10}},
+		{nil, []int{1, 2}, []int{1, 2}},
+		{[]int{1, 1}, []int{1}, []int{1, 1, 1}},
+		{nil, nil, nil},
+	}
+	for _, tc := range tests {
+		got := slices.Collect(MergeSorted(slices.Values(tc.a), slices.Values(tc.b)))
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("MergeSorted(%v, %v) = %v; want %v", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestMergeSorted_EarlyBreakStopsPull(t *testing.T) {
+	producedA, producedB := 0, 0
+	merged := MergeSorted(countingSeq(100, &producedA), countingSeq(100, &producedB))
+	for v := range merged {
+		if v == 1 {
+			break
+		}
+	}
+	if producedA > 3 || producedB > 3 {
+		t.Errorf("sources produced %d and %d values after an early break; want only a few", producedA, producedB)
+	}
+}
@@ -0,0 +1,316 @@
+package jsonencoding
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/quiz"
+)
+
+// Run encodes and decodes the example types, printing the JSON to w
+func Run(w io.Writer) error {
+	fmt.Fprintln(w, "=========================================")
+	fmt.Fprintln(w, "GO JSON ENCODING EXAMPLES")
+	fmt.Fprintln(w, "=========================================")
+
+	MarshalUnmarshalExample(w)
+	OmitEmptyExample(w)
+	CustomMarshalerExample(w)
+	RawMessageExample(w)
+	StreamingTokensExample(w)
+	UnknownFieldsExample(w)
+
+	// Interview questions
+	JSONInterviewQuestions(w)
+	return nil
+}
+
+// Book is the sample document used by the examples
+type Book struct {
+	ID     int      `json:"id"`
+	Title  string   `json:"title"`
+	Author string   `json:"author"`
+	Price  float64  `json:"price"`
+	Tags   []string `json:"tags,omitempty"`
+	secret string   // unexported fields are never encoded
+}
+
+// EncodeBook marshals a book with indentation
+func EncodeBook(b Book) (string, error) {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// DecodeBook unmarshals a book from JSON
+func DecodeBook(data string) (Book, error) {
+	var b Book
+	err := json.Unmarshal([]byte(data), &b)
+	return b, err
+}
+
+// PatchRequest shows why pointer fields matter for partial updates.
+// With omitempty on a plain float64, a price of 0 is indistinguishable from
+// "not sent"; a *float64 is nil only when the field was absent.
+type PatchRequest struct {
+	Title    *string  `json:"title,omitempty"`
+	Price    *float64 `json:"price,omitempty"`
+	Discount float64  `json:"discount,omitempty"`
+}
+
+// ApplyPatch applies only the fields that were present in the JSON
+func ApplyPatch(b Book, patchJSON string) (Book, error) {
+	var p PatchRequest
+	if err := json.Unmarshal([]byte(patchJSON), &p); err != nil {
+		return b, err
+	}
+	if p.Title != nil {
+		b.Title = *p.Title
+	}
+	if p.Price != nil {
+		b.Price = *p.Price // an explicit 0 is honored
+	}
+	return b, nil
+}
+
+// Duration encodes as a human-readable string ("1m30s") instead of nanoseconds
+type Duration time.Duration
+
+// MarshalJSON implements json.Marshaler
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It accepts either a duration
+// string or a number of seconds, which keeps older clients working.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		parsed, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q: %w", s, err)
+		}
+		*d = Duration(parsed)
+		return nil
+	}
+
+	var seconds float64
+	if err := json.Unmarshal(data, &seconds); err != nil {
+		return fmt.Errorf("duration must be a string or number, got %s", data)
+	}
+	*d = Duration(seconds * float64(time.Second))
+	return nil
+}
+
+// Job uses the custom Duration type
+type Job struct {
+	Name    string   `json:"name"`
+	Timeout Duration `json:"timeout"`
+}
+
+// Event is an envelope whose payload type depends on Type.
+// json.RawMessage defers decoding the payload until Type is known.
+type Event struct {
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// BookDeleted is the payload of a "book.deleted" event
+type BookDeleted struct {
+	ID int `json:"id"`
+}
+
+// DecodeEvent returns the typed payload of an event
+func DecodeEvent(data []byte) (any, error) {
+	var e Event
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, err
+	}
+
+	switch e.Type {
+	case "book.created":
+		var b Book
+		err := json.Unmarshal(e.Payload, &b)
+		return b, err
+	case "book.deleted":
+		var d BookDeleted
+		err := json.Unmarshal(e.Payload, &d)
+		return d, err
+	default:
+		return nil, fmt.Errorf("unknown event type %q", e.Type)
+	}
+}
+
+// StreamBooks decodes a large JSON array one element at a time, calling fn
+// for each book. Only one book is held in memory at once.
+func StreamBooks(r io.Reader, fn func(Book) error) error {
+	dec := json.NewDecoder(r)
+
+	// Expect the opening '['
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("expected array, got %v", tok)
+	}
+
+	for dec.More() {
+		var b Book
+		if err := dec.Decode(&b); err != nil {
+			return err
+		}
+		if err := fn(b); err != nil {
+			return err
+		}
+	}
+
+	// Consume the closing ']'
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// TokenKinds lists the kind of every token in a JSON document, which is
+// what Decoder.Token exposes for hand-written streaming parsers
+func TokenKinds(data string) ([]string, error) {
+	dec := json.NewDecoder(strings.NewReader(data))
+	dec.UseNumber() // keep numbers as json.Number instead of float64
+
+	var kinds []string
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return kinds, nil
+		}
+		if err != nil {
+			return kinds, err
+		}
+		switch v := tok.(type) {
+		case json.Delim:
+			kinds = append(kinds, v.String())
+		case string:
+			kinds = append(kinds, "string")
+		case json.Number:
+			kinds = append(kinds, "number")
+		case bool:
+			kinds = append(kinds, "bool")
+		case nil:
+			kinds = append(kinds, "null")
+		}
+	}
+}
+
+// DecodeStrict decodes a single book and rejects unknown fields and
+// trailing data, which catches client typos like "titel"
+func DecodeStrict(r io.Reader) (Book, error) {
+	var b Book
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&b); err != nil {
+		return Book{}, err
+	}
+	if dec.More() {
+		return Book{}, errors.New("unexpected data after JSON object")
+	}
+	return b, nil
+}
+
+// MarshalUnmarshalExample shows the basic round trip
+func MarshalUnmarshalExample(w io.Writer) {
+	fmt.Fprintln(w, "=== MARSHAL / UNMARSHAL EXAMPLE ===")
+
+	encoded, err := EncodeBook(Book{ID: 1, Title: "Go in Action", Author: "William Kennedy", Price: 24.99, secret: "hidden"})
+	if err != nil {
+		fmt.Fprintln(w, "Error:", err)
+		return
+	}
+	fmt.Fprintln(w, encoded)
+
+	decoded, err := DecodeBook(`{"id":2,"title":"Concurrency in Go","price":34.99}`)
+	fmt.Fprintf(w, "Decoded: %+v, err: %v\n", decoded, err)
+	fmt.Fprintln(w)
+}
+
+// OmitEmptyExample contrasts omitempty with pointer fields
+func OmitEmptyExample(w io.Writer) {
+	fmt.Fprintln(w, "=== OMITEMPTY VS POINTER FIELDS EXAMPLE ===")
+
+	zero := 0.0
+	withPointer, _ := json.Marshal(PatchRequest{Price: &zero, Discount: 0})
+	fmt.Fprintf(w, "Pointer to zero is kept, zero value is dropped: %s\n", withPointer)
+
+	book := Book{Title: "Old", Price: 10}
+	patched, _ := ApplyPatch(book, `{"price":0}`)
+	fmt.Fprintf(w, "After patch {\"price\":0}: %+v\n", patched)
+	fmt.Fprintln(w)
+}
+
+// CustomMarshalerExample uses MarshalJSON/UnmarshalJSON
+func CustomMarshalerExample(w io.Writer) {
+	fmt.Fprintln(w, "=== CUSTOM MARSHALER EXAMPLE ===")
+
+	data, _ := json.Marshal(Job{Name: "backup", Timeout: Duration(90 * time.Second)})
+	fmt.Fprintf(w, "Encoded: %s\n", data)
+
+	var job Job
+	_ = json.Unmarshal([]byte(`{"name":"legacy","timeout":30}`), &job)
+	fmt.Fprintf(w, "Decoded numeric timeout: %v\n", time.Duration(job.Timeout))
+	fmt.Fprintln(w)
+}
+
+// RawMessageExample decodes a polymorphic payload
+func RawMessageExample(w io.Writer) {
+	fmt.Fprintln(w, "=== JSON.RAWMESSAGE EXAMPLE ===")
+
+	for _, raw := range []string{
+		`{"type":"book.created","payload":{"id":3,"title":"Learning Go"}}`,
+		`{"type":"book.deleted","payload":{"id":3}}`,
+	} {
+		payload, err := DecodeEvent([]byte(raw))
+		fmt.Fprintf(w, "%T %+v %v\n", payload, payload, err)
+	}
+	fmt.Fprintln(w)
+}
+
+// StreamingTokensExample streams an array and lists tokens
+func StreamingTokensExample(w io.Writer) {
+	fmt.Fprintln(w, "=== STREAMING WITH DECODER.TOKEN EXAMPLE ===")
+
+	input := `[{"id":1,"title":"A"},{"id":2,"title":"B"}]`
+	_ = StreamBooks(strings.NewReader(input), func(b Book) error {
+		fmt.Fprintf(w, "Streamed book %d: %s\n", b.ID, b.Title)
+		return nil
+	})
+
+	kinds, _ := TokenKinds(`{"a":[1,true,null]}`)
+	fmt.Fprintln(w, "Tokens:", strings.Join(kinds, " "))
+	fmt.Fprintln(w)
+}
+
+// UnknownFieldsExample shows strict decoding
+func UnknownFieldsExample(w io.Writer) {
+	fmt.Fprintln(w, "=== DISALLOW UNKNOWN FIELDS EXAMPLE ===")
+
+	_, err := DecodeStrict(bytes.NewBufferString(`{"titel":"typo"}`))
+	fmt.Fprintln(w, "Strict decode error:", err)
+
+	lenient, err := DecodeBook(`{"titel":"typo"}`)
+	fmt.Fprintf(w, "Lenient decode silently ignores it: %+v, err: %v\n", lenient, err)
+	fmt.Fprintln(w)
+}
+
+// JSONInterviewQuestions lists common interview questions about encoding/json
+func JSONInterviewQuestions(w io.Writer) {
+	if err := quiz.Print(w, "json-encoding"); err != nil {
+		fmt.Fprintln(w, err)
+	}
+}
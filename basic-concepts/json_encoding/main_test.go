@@ -0,0 +1,204 @@
+package jsonencoding
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	in := Book{ID: 1, Title: "Go", Author: "Gopher", Price: 9.99, Tags: []string{"a"}, secret: "x"}
+	encoded, err := EncodeBook(in)
+	if err != nil {
+		t.Fatalf("EncodeBook: %v", err)
+	}
+	if strings.Contains(encoded, "secret") {
+		t.Errorf("unexported field leaked into JSON: %s", encoded)
+	}
+
+	out, err := DecodeBook(encoded)
+	if err != nil {
+		t.Fatalf("DecodeBook: %v", err)
+	}
+	in.secret = "" // not expected to survive
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("round trip = %+v; want %+v", out, in)
+	}
+}
+
+func TestEncodeBook_OmitsEmptyTags(t *testing.T) {
+	encoded, err := EncodeBook(Book{ID: 1})
+	if err != nil {
+		t.Fatalf("EncodeBook: %v", err)
+	}
+	if strings.Contains(encoded, "tags") {
+		t.Errorf("expected tags to be omitted: %s", encoded)
+	}
+	if !strings.Contains(encoded, `"price": 0`) {
+		t.Errorf("expected zero price without omitempty to be kept: %s", encoded)
+	}
+}
+
+func TestDecodeBook_TypeMismatch(t *testing.T) {
+	_, err := DecodeBook(`{"id":"one"}`)
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) {
+		t.Fatalf("err = %v; want *json.UnmarshalTypeError", err)
+	}
+	if typeErr.Field != "id" {
+		t.Errorf("Field = %q; want \"id\"", typeErr.Field)
+	}
+}
+
+func TestPatchRequest_PointerVsOmitEmpty(t *testing.T) {
+	zero := 0.0
+	data, err := json.Marshal(PatchRequest{Price: &zero})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), `{"price":0}`; got != want {
+		t.Errorf("Marshal = %s; want %s", got, want)
+	}
+}
+
+func TestApplyPatch(t *testing.T) {
+	base := Book{Title: "Old", Price: 10}
+	tests := []struct {
+		name  string
+		patch string
+		want  Book
+	}{
+		{"empty patch changes nothing", `{}`, base},
+		{"explicit zero price is applied", `{"price":0}`, Book{Title: "Old", Price: 0}},
+		{"title only", `{"title":"New"}`, Book{Title: "New", Price: 10}},
+		{"null is treated as absent", `{"title":null}`, base},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ApplyPatch(base, tc.patch)
+			if err != nil {
+				t.Fatalf("ApplyPatch: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("ApplyPatch = %+v; want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDuration_JSON(t *testing.T) {
+	data, err := json.Marshal(Job{Name: "j", Timeout: Duration(90 * time.Second)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), `{"name":"j","timeout":"1m30s"}`; got != want {
+		t.Errorf("Marshal = %s; want %s", got, want)
+	}
+
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{`{"timeout":"1m30s"}`, 90 * time.Second, false},
+		{`{"timeout":1.5}`, 1500 * time.Millisecond, false},
+		{`{"timeout":"soon"}`, 0, true},
+		{`{"timeout":true}`, 0, true},
+	}
+	for _, tc := range tests {
+		var job Job
+		err := json.Unmarshal([]byte(tc.input), &job)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("Unmarshal(%s) err = %v; wantErr %v", tc.input, err, tc.wantErr)
+			continue
+		}
+		if !tc.wantErr && time.Duration(job.Timeout) != tc.want {
+			t.Errorf("Unmarshal(%s) = %v; want %v", tc.input, time.Duration(job.Timeout), tc.want)
+		}
+	}
+}
+
+func TestDecodeEvent(t *testing.T) {
+	created, err := DecodeEvent([]byte(`{"type":"book.created","payload":{"id":3,"title":"T"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, ok := created.(Book); !ok || b.ID != 3 || b.Title != "T" {
+		t.Errorf("created payload = %#v; want Book{ID:3, Title:T}", created)
+	}
+
+	deleted, err := DecodeEvent([]byte(`{"type":"book.deleted","payload":{"id":4}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d, ok := deleted.(BookDeleted); !ok || d.ID != 4 {
+		t.Errorf("deleted payload = %#v; want BookDeleted{ID:4}", deleted)
+	}
+
+	if _, err := DecodeEvent([]byte(`{"type":"nope","payload":{}}`)); err == nil {
+		t.Error("expected error for unknown event type")
+	}
+}
+
+func TestStreamBooks(t *testing.T) {
+	var titles []string
+	err := StreamBooks(strings.NewReader(`[{"title":"A"},{"title":"B"},{"title":"C"}]`), func(b Book) error {
+		titles = append(titles, b.Title)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamBooks: %v", err)
+	}
+	if want := []string{"A", "B", "C"}; !reflect.DeepEqual(titles, want) {
+		t.Errorf("titles = %v; want %v", titles, want)
+	}
+}
+
+func TestStreamBooks_Errors(t *testing.T) {
+	noop := func(Book) error { return nil }
+	if err := StreamBooks(strings.NewReader(`{"title":"A"}`), noop); err == nil {
+		t.Error("expected error for a non-array document")
+	}
+	if err := StreamBooks(strings.NewReader(`[{"title":"A"},{"title":`), noop); err == nil {
+		t.Error("expected error for a truncated document")
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err := StreamBooks(strings.NewReader(`[{},{},{}]`), func(Book) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("err = %v after %d calls; want the callback error after 1 call", err, calls)
+	}
+}
+
+func TestTokenKinds(t *testing.T) {
+	kinds, err := TokenKinds(`{"a":[1,"x",true,null]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"{", "string", "[", "number", "string", "bool", "null", "]", "}"}
+	if !reflect.DeepEqual(kinds, want) {
+		t.Errorf("TokenKinds = %v; want %v", kinds, want)
+	}
+}
+
+func TestDecodeStrict(t *testing.T) {
+	b, err := DecodeStrict(strings.NewReader(`{"id":1,"title":"Go"}`))
+	if err != nil || b.Title != "Go" {
+		t.Errorf("DecodeStrict = %+v, %v; want title Go, nil", b, err)
+	}
+
+	if _, err := DecodeStrict(strings.NewReader(`{"titel":"typo"}`)); err == nil || !strings.Contains(err.Error(), "unknown field") {
+		t.Errorf("err = %v; want an unknown field error", err)
+	}
+
+	if _, err := DecodeStrict(strings.NewReader(`{"id":1} {"id":2}`)); err == nil {
+		t.Error("expected error for trailing data")
+	}
+}
@@ -0,0 +1,292 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/quiz"
+)
+
+// Run sends the slog examples' records to w
+func Run(w io.Writer) error {
+	fmt.Fprintln(w, "=========================================")
+	fmt.Fprintln(w, "GO STRUCTURED LOGGING (log/slog) EXAMPLES")
+	fmt.Fprintln(w, "=========================================")
+
+	HandlersExample(w)
+	LevelsExample(w)
+	GroupsExample(w)
+	ContextExample(w)
+	CaptureExample(w)
+
+	// Interview questions
+	LoggingInterviewQuestions(w)
+	return nil
+}
+
+// NewLogger returns a logger writing JSON or text to w. Time is dropped so
+// the examples print the same output on every run.
+func NewLogger(w io.Writer, format string, level slog.Leveler) *slog.Logger {
+	opts := &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}
+	if format == "json" {
+		return slog.New(slog.NewJSONHandler(w, opts))
+	}
+	return slog.New(slog.NewTextHandler(w, opts))
+}
+
+// CONTEXT-SCOPED LOGGING
+
+type requestIDKey struct{}
+
+// WithRequestID stores a request ID in ctx
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID stored in ctx, or ""
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// ContextHandler adds the request ID from the context to every record
+// logged with a *Context method (InfoContext, ErrorContext, ...). Code deep
+// in the call stack then gets the ID without being handed a logger.
+type ContextHandler struct {
+	slog.Handler
+}
+
+// Handle adds request_id before passing the record on
+func (h ContextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs keeps the wrapper around the derived handler
+func (h ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return ContextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup keeps the wrapper around the derived handler
+func (h ContextHandler) WithGroup(name string) slog.Handler {
+	return ContextHandler{h.Handler.WithGroup(name)}
+}
+
+// CAPTURING HANDLER FOR TESTS
+
+// CapturedRecord is a log record flattened for easy assertions. Grouped
+// attributes use dotted keys, e.g. "request.method".
+type CapturedRecord struct {
+	Level   slog.Level
+	Message string
+	Attrs   map[string]any
+}
+
+// CaptureHandler stores records in memory so tests can assert on them
+// instead of parsing log output. Handlers derived with WithAttrs and
+// WithGroup share the same storage.
+type CaptureHandler struct {
+	level  slog.Leveler
+	store  *captureStore
+	attrs  []slog.Attr // already qualified with their group prefix
+	prefix string      // dotted group prefix for attrs added later
+}
+
+type captureStore struct {
+	mu      sync.Mutex
+	records []CapturedRecord
+}
+
+// NewCaptureHandler returns a CaptureHandler that keeps records at level
+// or above; a nil level keeps everything from Info up
+func NewCaptureHandler(level slog.Leveler) *CaptureHandler {
+	if level == nil {
+		level = slog.LevelInfo
+	}
+	return &CaptureHandler{level: level, store: &captureStore{}}
+}
+
+// Enabled reports whether records at level are kept
+func (h *CaptureHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+// Handle stores the record
+func (h *CaptureHandler) Handle(_ context.Context, r slog.Record) error {
+	rec := CapturedRecord{Level: r.Level, Message: r.Message, Attrs: make(map[string]any)}
+	for _, a := range h.attrs {
+		addAttr(rec.Attrs, "", a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		addAttr(rec.Attrs, h.prefix, a)
+		return true
+	})
+
+	h.store.mu.Lock()
+	h.store.records = append(h.store.records, rec)
+	h.store.mu.Unlock()
+	return nil
+}
+
+// addAttr flattens a, resolving LogValuers and expanding groups
+func addAttr(dst map[string]any, prefix string, a slog.Attr) {
+	v := a.Value.Resolve()
+	if v.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range v.Group() {
+			addAttr(dst, prefix, ga)
+		}
+		return
+	}
+	if a.Key == "" {
+		return
+	}
+	dst[prefix+a.Key] = v.Any()
+}
+
+// WithAttrs returns a handler that adds attrs to every record
+func (h *CaptureHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.attrs = slices.Clone(h.attrs)
+	for _, a := range attrs {
+		a.Key = h.prefix + a.Key
+		h2.attrs = append(h2.attrs, a)
+	}
+	return &h2
+}
+
+// WithGroup returns a handler that qualifies later attrs with name
+func (h *CaptureHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.prefix = h.prefix + name + "."
+	return &h2
+}
+
+// Records returns a copy of everything captured so far
+func (h *CaptureHandler) Records() []CapturedRecord {
+	h.store.mu.Lock()
+	defer h.store.mu.Unlock()
+	return slices.Clone(h.store.records)
+}
+
+// EXAMPLES
+
+// User implements slog.LogValuer so it logs as a group without its secret
+type User struct {
+	ID       int
+	Name     string
+	Password string
+}
+
+// LogValue controls how a User appears in logs
+func (u User) LogValue() slog.Value {
+	return slog.GroupValue(slog.Int("id", u.ID), slog.String("name", u.Name))
+}
+
+// HandlersExample shows the same call through the JSON and text handlers
+func HandlersExample(w io.Writer) {
+	fmt.Fprintln(w, "=== JSON VS TEXT HANDLERS ===")
+
+	user := User{ID: 7, Name: "ada", Password: "hunter2"}
+	for _, format := range []string{"json", "text"} {
+		logger := NewLogger(w, format, slog.LevelInfo)
+		logger.Info("user logged in", "user", user, "attempts", 2)
+	}
+
+	// The attribute-typed API avoids the allocation and key/value mismatch
+	// risks of alternating ...any arguments
+	logger := NewLogger(w, "text", slog.LevelInfo)
+	logger.LogAttrs(context.Background(), slog.LevelInfo, "typed attrs",
+		slog.Int("status", 200), slog.Duration("took", 1500*time.Microsecond))
+	fmt.Fprintln(w)
+}
+
+// LevelsExample shows filtering and changing the level at run time
+func LevelsExample(w io.Writer) {
+	fmt.Fprintln(w, "=== LEVELS ===")
+
+	var level slog.LevelVar // zero value is Info
+	logger := NewLogger(w, "text", &level)
+
+	logger.Debug("hidden at Info level")
+	logger.Info("shown at Info level")
+
+	level.Set(slog.LevelDebug)
+	logger.Debug("shown after switching to Debug")
+
+	level.Set(slog.LevelError)
+	logger.Warn("hidden at Error level")
+	logger.Error("shown at Error level")
+	fmt.Fprintln(w)
+}
+
+// GroupsExample shows With and WithGroup
+func GroupsExample(w io.Writer) {
+	fmt.Fprintln(w, "=== WITH AND GROUPS ===")
+
+	base := NewLogger(w, "json", slog.LevelInfo)
+	svc := base.With("service", "books")
+	req := svc.WithGroup("request")
+	req.Info("handled", "method", "GET", "path", "/books", "status", 200)
+	fmt.Fprintln(w)
+}
+
+// ContextExample shows a request ID flowing through the context
+func ContextExample(w io.Writer) {
+	fmt.Fprintln(w, "=== CONTEXT-SCOPED LOGGER ===")
+
+	handler := NewLogger(w, "text", slog.LevelInfo).Handler()
+	logger := slog.New(ContextHandler{handler})
+
+	ctx := WithRequestID(context.Background(), "req-42")
+	loadBook(ctx, logger, 1)
+	logger.Info("no context, no request_id")
+	fmt.Fprintln(w)
+}
+
+// loadBook stands in for code deep in a request's call stack
+func loadBook(ctx context.Context, logger *slog.Logger, id int) {
+	logger.InfoContext(ctx, "loading book", "id", id)
+}
+
+// CaptureExample shows the capturing handler used in tests
+func CaptureExample(w io.Writer) {
+	fmt.Fprintln(w, "=== CAPTURING RECORDS FOR TESTS ===")
+
+	capture := NewCaptureHandler(slog.LevelDebug)
+	logger := slog.New(capture).With("component", "cache")
+	logger.Debug("miss", "key", "book:1")
+	logger.WithGroup("stats").Info("evicted", "count", 3)
+
+	for _, r := range capture.Records() {
+		fmt.Fprintf(w, "%-5s %-7s %v\n", r.Level, r.Message, r.Attrs)
+	}
+	fmt.Fprintln(w)
+}
+
+// LoggingInterviewQuestions lists common interview questions about logging
+func LoggingInterviewQuestions(w io.Writer) {
+	if err := quiz.Print(w, "logging"); err != nil {
+		fmt.Fprintln(w, err)
+	}
+}
@@ -0,0 +1,103 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewLogger_Formats(t *testing.T) {
+	var buf bytes.Buffer
+	NewLogger(&buf, "json", slog.LevelInfo).Info("hello", "n", 1)
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("JSON output %q does not parse: %v", buf.String(), err)
+	}
+	want := map[string]any{"level": "INFO", "msg": "hello", "n": float64(1)}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("JSON record = %v; want %v", got, want)
+	}
+
+	buf.Reset()
+	NewLogger(&buf, "text", slog.LevelInfo).Info("hello", "n", 1)
+	if got := strings.TrimSpace(buf.String()); got != "level=INFO msg=hello n=1" {
+		t.Errorf("text record = %q", got)
+	}
+}
+
+func TestLevelVar(t *testing.T) {
+	capture := NewCaptureHandler(nil)
+	var level slog.LevelVar
+	logger := slog.New(&CaptureHandler{level: &level, store: capture.store})
+
+	logger.Debug("dropped")
+	level.Set(slog.LevelDebug)
+	logger.Debug("kept")
+
+	records := capture.Records()
+	if len(records) != 1 || records[0].Message != "kept" {
+		t.Errorf("records = %+v; want only the message logged after Set", records)
+	}
+}
+
+func TestCaptureHandler_AttrsAndGroups(t *testing.T) {
+	capture := NewCaptureHandler(slog.LevelDebug)
+	logger := slog.New(capture).With("service", "books").WithGroup("request").With("method", "GET")
+	logger.Info("handled", "status", 200, slog.Group("timing", "ms", 12))
+
+	records := capture.Records()
+	if len(records) != 1 {
+		t.Fatalf("captured %d records; want 1", len(records))
+	}
+	want := map[string]any{
+		"service":           "books",
+		"request.method":    "GET",
+		"request.status":    int64(200),
+		"request.timing.ms": int64(12),
+	}
+	if !reflect.DeepEqual(records[0].Attrs, want) {
+		t.Errorf("attrs = %v; want %v", records[0].Attrs, want)
+	}
+}
+
+func TestCaptureHandler_LogValuerHidesSecret(t *testing.T) {
+	capture := NewCaptureHandler(nil)
+	slog.New(capture).Info("login", "user", User{ID: 1, Name: "ada", Password: "hunter2"})
+
+	attrs := capture.Records()[0].Attrs
+	want := map[string]any{"user.id": int64(1), "user.name": "ada"}
+	if !reflect.DeepEqual(attrs, want) {
+		t.Errorf("attrs = %v; want %v", attrs, want)
+	}
+}
+
+func TestContextHandler_AddsRequestID(t *testing.T) {
+	capture := NewCaptureHandler(nil)
+	logger := slog.New(ContextHandler{capture}).WithGroup("g")
+
+	ctx := WithRequestID(context.Background(), "req-1")
+	logger.InfoContext(ctx, "with id")
+	logger.Info("without id")
+
+	records := capture.Records()
+	if len(records) != 2 {
+		t.Fatalf("captured %d records; want 2", len(records))
+	}
+	if got := records[0].Attrs["g.request_id"]; got != "req-1" {
+		t.Errorf("request_id = %v; want req-1 (attrs %v)", got, records[0].Attrs)
+	}
+	if len(records[1].Attrs) != 0 {
+		t.Errorf("attrs without context = %v; want none", records[1].Attrs)
+	}
+}
+
+func TestRequestID_Missing(t *testing.T) {
+	if got := RequestID(context.Background()); got != "" {
+		t.Errorf("RequestID(empty ctx) = %q; want \"\"", got)
+	}
+}
@@ -0,0 +1,16 @@
+package main
+
+import "fmt"
+
+func main() {
+	fmt.Println("=========================================")
+	fmt.Println("GO TESTING EXAMPLES")
+	fmt.Println("=========================================")
+
+	fmt.Println("The files in this directory are meant to be run with go test:")
+	fmt.Println("    go test -v .")
+	fmt.Println()
+
+	// HTTP handler testing (07_http_testing.go)
+	demonstrateHTTPServer()
+}
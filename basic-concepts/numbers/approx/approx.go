@@ -0,0 +1,29 @@
+// Package approx compares floating-point results, which rarely match an
+// expected value bit for bit, within a tolerance.
+package approx
+
+import "math"
+
+// DefaultTolerance suits results of a handful of float64 operations
+const DefaultTolerance = 1e-9
+
+// Equal reports whether a and b are within tol of each other, measured
+// absolutely near zero and relative to the larger magnitude elsewhere:
+// |a-b| <= tol * max(1, |a|, |b|). A purely absolute check is too strict
+// for large values and a purely relative one never passes for values
+// near zero. NaN equals nothing, and infinities equal only themselves.
+func Equal(a, b, tol float64) bool {
+	if a == b {
+		return true // also covers equal infinities
+	}
+	if math.IsNaN(a) || math.IsNaN(b) || math.IsInf(a, 0) || math.IsInf(b, 0) {
+		return false
+	}
+	scale := math.Max(1, math.Max(math.Abs(a), math.Abs(b)))
+	return math.Abs(a-b) <= tol*scale
+}
+
+// Equalish is Equal with DefaultTolerance
+func Equalish(a, b float64) bool {
+	return Equal(a, b, DefaultTolerance)
+}
@@ -0,0 +1,46 @@
+package approx
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEqual(t *testing.T) {
+	inf, nan := math.Inf(1), math.NaN()
+	// Variables, not constants: constant expressions are evaluated exactly
+	// at compile time, so 0.1 + 0.2 written inline is exactly 0.3
+	a, b := 0.1, 0.2
+	tests := []struct {
+		name string
+		a, b float64
+		tol  float64
+		want bool
+	}{
+		{"identical", 1.5, 1.5, 0, true},
+		{"classic 0.1+0.2", a + b, 0.3, DefaultTolerance, true},
+		{"near zero uses absolute tolerance", 1e-12, -1e-12, DefaultTolerance, true},
+		{"large values use relative tolerance", 1e15, 1e15 + 0.5, DefaultTolerance, true},
+		{"outside tolerance", 1.0, 1.001, DefaultTolerance, false},
+		{"zero tolerance needs exact match", a + b, 0.3, 0, false},
+		{"NaN never equal", nan, nan, 1, false},
+		{"same infinity", inf, inf, 0, true},
+		{"opposite infinities", inf, -inf, 1, false},
+		{"infinity against finite", inf, math.MaxFloat64, 1, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Equal(tc.a, tc.b, tc.tol); got != tc.want {
+				t.Errorf("Equal(%g, %g, %g) = %v; want %v", tc.a, tc.b, tc.tol, got, tc.want)
+			}
+			if got := Equal(tc.b, tc.a, tc.tol); got != tc.want {
+				t.Errorf("Equal is not symmetric for %g, %g", tc.a, tc.b)
+			}
+		})
+	}
+}
+
+func TestEqualish(t *testing.T) {
+	if !Equalish(math.Sqrt(2)*math.Sqrt(2), 2) {
+		t.Error("Equalish(sqrt(2)^2, 2) = false; want true")
+	}
+}
@@ -0,0 +1,219 @@
+package numbers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"math/bits"
+
+	"github.com/rehan/go-interview-prep/basic-concepts/numbers/approx"
+	"github.com/rehan/go-interview-prep/pkg/money"
+	"github.com/rehan/go-interview-prep/pkg/quiz"
+)
+
+// Run prints the overflow, float comparison, math/big and money examples
+// to w
+func Run(w io.Writer) error {
+	fmt.Fprintln(w, "=========================================")
+	fmt.Fprintln(w, "GO NUMBERS EXAMPLES")
+	fmt.Fprintln(w, "=========================================")
+
+	OverflowExample(w)
+	FloatComparisonExample(w)
+	BigIntExample(w)
+	BigRatExample(w)
+	MoneyExample(w)
+
+	// Interview questions
+	NumbersInterviewQuestions(w)
+	return nil
+}
+
+// INTEGER OVERFLOW
+
+// ErrOverflow is returned by the checked arithmetic helpers
+var ErrOverflow = errors.New("integer overflow")
+
+// AddInt64 returns a+b, or ErrOverflow instead of wrapping around.
+// Overflow happened exactly when both operands have the same sign and the
+// sum's sign differs from it.
+func AddInt64(a, b int64) (int64, error) {
+	sum := a + b
+	if (a >= 0) == (b >= 0) && (sum >= 0) != (a >= 0) {
+		return 0, ErrOverflow
+	}
+	return sum, nil
+}
+
+// MulInt64 returns a*b, or ErrOverflow instead of wrapping around
+func MulInt64(a, b int64) (int64, error) {
+	if a == 0 || b == 0 {
+		return 0, nil
+	}
+	p := a * b
+	// MinInt64 * -1 wraps to MinInt64, and so does MinInt64 / -1, so the
+	// division check alone misses it
+	if p/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, ErrOverflow
+	}
+	return p, nil
+}
+
+// AddUint64 returns a+b using math/bits, whose carry out is the overflow flag
+func AddUint64(a, b uint64) (uint64, error) {
+	sum, carry := bits.Add64(a, b, 0)
+	if carry != 0 {
+		return 0, ErrOverflow
+	}
+	return sum, nil
+}
+
+// OverflowExample shows that fixed-size integers wrap silently
+func OverflowExample(w io.Writer) {
+	fmt.Fprintln(w, "\n--- Integer Overflow ---")
+
+	var i8 int8 = math.MaxInt8
+	i8++
+	fmt.Fprintf(w, "int8 127 + 1 = %d (wraps to the minimum)\n", i8)
+
+	var u8 uint8 = 0
+	u8--
+	fmt.Fprintf(w, "uint8 0 - 1 = %d (wraps to the maximum)\n", u8)
+
+	// Conversions truncate to the low bits rather than saturating
+	wide := int64(300)
+	fmt.Fprintf(w, "uint8(int64 300) = %d\n", uint8(wide))
+
+	// math.MinInt64 has no positive counterpart, so negating it overflows
+	minInt := int64(math.MinInt64)
+	fmt.Fprintf(w, "-MinInt64 == MinInt64: %v\n", -minInt == minInt)
+
+	// Constant expressions are checked at compile time instead:
+	//   var x int8 = 128 // constant 128 overflows int8
+
+	if _, err := AddInt64(math.MaxInt64, 1); err != nil {
+		fmt.Fprintln(w, "AddInt64(MaxInt64, 1):", err)
+	}
+	if _, err := MulInt64(math.MaxInt64/2, 3); err != nil {
+		fmt.Fprintln(w, "MulInt64(MaxInt64/2, 3):", err)
+	}
+	if _, err := AddUint64(math.MaxUint64, 1); err != nil {
+		fmt.Fprintln(w, "AddUint64(MaxUint64, 1):", err)
+	}
+}
+
+// FLOAT COMPARISON
+
+// FloatComparisonExample shows why floats are compared with a tolerance
+func FloatComparisonExample(w io.Writer) {
+	fmt.Fprintln(w, "\n--- Float Comparison ---")
+
+	// Variables, not constants: the compiler evaluates 0.1 + 0.2 written
+	// inline exactly, and that does equal 0.3
+	a, b := 0.1, 0.2
+	fmt.Fprintf(w, "0.1 + 0.2 = %.17f\n", a+b)
+	fmt.Fprintln(w, "0.1 + 0.2 == 0.3:", a+b == 0.3)
+	fmt.Fprintln(w, "approx.Equalish(0.1 + 0.2, 0.3):", approx.Equalish(a+b, 0.3))
+
+	// Errors accumulate: adding 0.1 ten times does not give 1
+	sum := 0.0
+	for range 10 {
+		sum += 0.1
+	}
+	fmt.Fprintf(w, "0.1 added 10 times = %.17f\n", sum)
+
+	// A fixed absolute epsilon is meaningless at large magnitudes, where
+	// neighbouring float64 values are further apart than the epsilon
+	x := 1e16
+	fmt.Fprintf(w, "next float64 after 1e16 = %.0f (gap %.0f)\n", math.Nextafter(x, math.Inf(1)), math.Nextafter(x, math.Inf(1))-x)
+
+	// NaN is not equal to anything, itself included
+	nan := math.NaN()
+	fmt.Fprintln(w, "NaN == NaN:", nan == nan, "| math.IsNaN:", math.IsNaN(nan))
+}
+
+// MATH/BIG
+
+// Factorial returns n! exactly; 21! already overflows int64
+func Factorial(n int64) *big.Int {
+	return new(big.Int).MulRange(1, n)
+}
+
+// Fibonacci returns the nth Fibonacci number (Fibonacci(0) = 0)
+func Fibonacci(n int) *big.Int {
+	a, b := big.NewInt(0), big.NewInt(1)
+	for range n {
+		// big.Int methods write into their receiver, so one Add reuses a's
+		// storage instead of allocating a new number each step
+		a.Add(a, b)
+		a, b = b, a
+	}
+	return a
+}
+
+// BigIntExample shows arbitrary-precision integers
+func BigIntExample(w io.Writer) {
+	fmt.Fprintln(w, "\n--- math/big.Int ---")
+
+	fmt.Fprintln(w, "20! =", Factorial(20), "(fits in int64)")
+	fmt.Fprintln(w, "25! =", Factorial(25))
+	fmt.Fprintln(w, "fib(100) =", Fibonacci(100))
+
+	// 2^64 is one past MaxUint64
+	two64 := new(big.Int).Lsh(big.NewInt(1), 64)
+	fmt.Fprintln(w, "2^64 =", two64, "| IsUint64:", two64.IsUint64())
+}
+
+// HarmonicSum returns 1/1 + 1/2 + ... + 1/n as an exact fraction
+func HarmonicSum(n int64) *big.Rat {
+	sum := new(big.Rat)
+	for i := int64(1); i <= n; i++ {
+		sum.Add(sum, big.NewRat(1, i))
+	}
+	return sum
+}
+
+// BigRatExample shows exact rational arithmetic
+func BigRatExample(w io.Writer) {
+	fmt.Fprintln(w, "\n--- math/big.Rat ---")
+
+	tenth := big.NewRat(1, 10)
+	sum := new(big.Rat).Add(tenth, big.NewRat(2, 10))
+	fmt.Fprintln(w, "1/10 + 2/10 =", sum, "| equals 3/10:", sum.Cmp(big.NewRat(3, 10)) == 0)
+
+	h := HarmonicSum(10)
+	f, _ := h.Float64()
+	fmt.Fprintf(w, "H(10) = %s ≈ %s ≈ %.6f\n", h, h.FloatString(4), f)
+}
+
+// MONEY
+
+// MoneyExample shows prices as integer cents with pkg/money
+func MoneyExample(w io.Writer) {
+	fmt.Fprintln(w, "\n--- Money as Integer Cents ---")
+
+	// Summing float prices drifts; summing cents does not
+	floatTotal, total := 0.0, money.FromCents(0)
+	for range 3 {
+		floatTotal += 0.10
+		total += money.MustParse("0.10")
+	}
+	fmt.Fprintf(w, "3 x 0.10 as float64 = %.17f\n", floatTotal)
+	fmt.Fprintln(w, "3 x 0.10 as money.Amount =", total)
+
+	price := money.MustParse("19.99")
+	subtotal, _ := price.Mul(3)
+	tax, _ := subtotal.MulRat(big.NewRat(8, 100))
+	fmt.Fprintln(w, "3 x", price, "=", subtotal, "| 8% tax =", tax)
+	bill := money.MustParse("100.00")
+	fmt.Fprintln(w, "split", bill, "three ways:", bill.Split(3))
+}
+
+// NumbersInterviewQuestions lists common interview questions about numbers
+func NumbersInterviewQuestions(w io.Writer) {
+	if err := quiz.Print(w, "numbers"); err != nil {
+		fmt.Fprintln(w, err)
+	}
+}
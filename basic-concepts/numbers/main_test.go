@@ -0,0 +1,100 @@
+package numbers
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestAddInt64(t *testing.T) {
+	tests := []struct {
+		a, b    int64
+		want    int64
+		wantErr error
+	}{
+		{1, 2, 3, nil},
+		{-1, 0, -1, nil},
+		{math.MaxInt64, math.MinInt64, -1, nil},
+		{math.MaxInt64, 1, 0, ErrOverflow},
+		{math.MinInt64, -1, 0, ErrOverflow},
+		{math.MinInt64, math.MinInt64, 0, ErrOverflow},
+	}
+	for _, tc := range tests {
+		got, err := AddInt64(tc.a, tc.b)
+		if got != tc.want || !errors.Is(err, tc.wantErr) {
+			t.Errorf("AddInt64(%d, %d) = %d, %v; want %d, %v", tc.a, tc.b, got, err, tc.want, tc.wantErr)
+		}
+	}
+}
+
+func TestMulInt64(t *testing.T) {
+	tests := []struct {
+		a, b    int64
+		want    int64
+		wantErr error
+	}{
+		{6, 7, 42, nil},
+		{-3, 4, -12, nil},
+		{0, math.MinInt64, 0, nil},
+		{math.MinInt64, 1, math.MinInt64, nil},
+		{math.MaxInt64 / 2, 3, 0, ErrOverflow},
+		{math.MinInt64, -1, 0, ErrOverflow},
+		{-1, math.MinInt64, 0, ErrOverflow},
+	}
+	for _, tc := range tests {
+		got, err := MulInt64(tc.a, tc.b)
+		if got != tc.want || !errors.Is(err, tc.wantErr) {
+			t.Errorf("MulInt64(%d, %d) = %d, %v; want %d, %v", tc.a, tc.b, got, err, tc.want, tc.wantErr)
+		}
+	}
+}
+
+func TestAddUint64(t *testing.T) {
+	if got, err := AddUint64(math.MaxUint64-1, 1); err != nil || got != math.MaxUint64 {
+		t.Errorf("AddUint64(MaxUint64-1, 1) = %d, %v; want MaxUint64", got, err)
+	}
+	if _, err := AddUint64(math.MaxUint64, 1); !errors.Is(err, ErrOverflow) {
+		t.Errorf("AddUint64(MaxUint64, 1) error = %v; want ErrOverflow", err)
+	}
+}
+
+func TestFactorial(t *testing.T) {
+	if got := Factorial(20); !got.IsInt64() || got.Int64() != 2432902008176640000 {
+		t.Errorf("Factorial(20) = %v; want 2432902008176640000", got)
+	}
+	want, _ := new(big.Int).SetString("51090942171709440000", 10)
+	if got := Factorial(21); got.Cmp(want) != 0 || got.IsInt64() {
+		t.Errorf("Factorial(21) = %v; want %v, beyond int64", got, want)
+	}
+	if got := Factorial(0); got.Int64() != 1 {
+		t.Errorf("Factorial(0) = %v; want 1", got)
+	}
+}
+
+func TestFibonacci(t *testing.T) {
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{0, "0"},
+		{1, "1"},
+		{10, "55"},
+		{93, "12200160415121876738"}, // first beyond int64
+		{100, "354224848179261915075"},
+	}
+	for _, tc := range tests {
+		if got := Fibonacci(tc.n).String(); got != tc.want {
+			t.Errorf("Fibonacci(%d) = %s; want %s", tc.n, got, tc.want)
+		}
+	}
+}
+
+func TestHarmonicSum(t *testing.T) {
+	if got := HarmonicSum(4); got.Cmp(big.NewRat(25, 12)) != 0 {
+		t.Errorf("HarmonicSum(4) = %v; want 25/12", got)
+	}
+	if got := HarmonicSum(10).String(); got != "7381/2520" {
+		t.Errorf("HarmonicSum(10) = %s; want 7381/2520", got)
+	}
+}
@@ -0,0 +1,212 @@
+// Package perf pairs a naive and an optimized implementation of common Go
+// patterns so the performance claims made in interviews can be measured
+// instead of recited:
+//
+//	go test -bench=. -benchmem ./basic-concepts/perf/
+//
+// To see the compiler's escape analysis decisions behind the allocation
+// counts:
+//
+//	go build -gcflags='-m' ./basic-concepts/perf/
+//
+// Each function is deliberately small; the benchmarks in perf_test.go are
+// the point of the package.
+package perf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// VALUE VS POINTER RECEIVERS
+
+// Matrix is large enough (512 bytes) that copying it is measurable
+type Matrix struct {
+	cells [64]int64
+}
+
+// NewMatrix returns a Matrix whose cells hold 0..63
+func NewMatrix() Matrix {
+	var m Matrix
+	for i := range m.cells {
+		m.cells[i] = int64(i)
+	}
+	return m
+}
+
+// SumValue has a value receiver: every call copies all 512 bytes, unless
+// the call is inlined. Run the benchmark with -gcflags=-l to disable
+// inlining and see the copy's cost.
+func (m Matrix) SumValue() int64 {
+	var total int64
+	for _, c := range m.cells {
+		total += c
+	}
+	return total
+}
+
+// SumPointer has a pointer receiver: every call copies one pointer
+func (m *Matrix) SumPointer() int64 {
+	var total int64
+	for _, c := range m.cells {
+		total += c
+	}
+	return total
+}
+
+// Point is small (16 bytes); for types this size a value receiver is
+// usually as fast or faster, since it avoids indirection
+type Point struct {
+	X, Y float64
+}
+
+// ScaleValue returns a scaled copy
+func (p Point) ScaleValue(f float64) Point {
+	return Point{p.X * f, p.Y * f}
+}
+
+// ScalePointer scales in place
+func (p *Point) ScalePointer(f float64) {
+	p.X *= f
+	p.Y *= f
+}
+
+// SLICES
+
+// AppendGrowing appends to a nil slice, so append reallocates and copies
+// the backing array each time capacity runs out
+func AppendGrowing(n int) []int {
+	var s []int
+	for i := 0; i < n; i++ {
+		s = append(s, i)
+	}
+	return s
+}
+
+// AppendPreallocated sets the capacity up front: one allocation in total
+func AppendPreallocated(n int) []int {
+	s := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		s = append(s, i)
+	}
+	return s
+}
+
+// STRING CONCATENATION
+
+// ConcatPlus uses +=, which allocates a new string on every iteration
+func ConcatPlus(parts []string) string {
+	s := ""
+	for _, p := range parts {
+		s += p
+	}
+	return s
+}
+
+// ConcatSprintf uses fmt.Sprintf, which adds formatting overhead and boxes
+// its arguments in interfaces
+func ConcatSprintf(parts []string) string {
+	s := ""
+	for _, p := range parts {
+		s = fmt.Sprintf("%s%s", s, p)
+	}
+	return s
+}
+
+// ConcatBuilder uses strings.Builder, which grows its buffer geometrically
+func ConcatBuilder(parts []string) string {
+	var b strings.Builder
+	for _, p := range parts {
+		b.WriteString(p)
+	}
+	return b.String()
+}
+
+// ConcatBuilderGrow sizes the Builder first: a single allocation
+func ConcatBuilderGrow(parts []string) string {
+	n := 0
+	for _, p := range parts {
+		n += len(p)
+	}
+	var b strings.Builder
+	b.Grow(n)
+	for _, p := range parts {
+		b.WriteString(p)
+	}
+	return b.String()
+}
+
+// ConcatJoin uses strings.Join, which computes the size and allocates once
+func ConcatJoin(parts []string) string {
+	return strings.Join(parts, "")
+}
+
+// MAPS
+
+// FillMap inserts n keys into a map created without a size hint, so it
+// rehashes into bigger bucket arrays as it grows
+func FillMap(n int) map[int]int {
+	m := make(map[int]int)
+	for i := 0; i < n; i++ {
+		m[i] = i
+	}
+	return m
+}
+
+// FillMapSized passes a size hint so the buckets are allocated up front
+func FillMapSized(n int) map[int]int {
+	m := make(map[int]int, n)
+	for i := 0; i < n; i++ {
+		m[i] = i
+	}
+	return m
+}
+
+// ESCAPE ANALYSIS
+
+// sink makes values escape on purpose
+var sink *Point
+
+// NewPointValue returns a value; the compiler keeps it on the stack
+// (-gcflags=-m reports nothing escaping)
+func NewPointValue(x, y float64) Point {
+	return Point{x, y}
+}
+
+// NewPointPointer returns a pointer. Whether it escapes depends on the
+// caller: after inlining, a pointer that never leaves the caller's frame
+// can still live on the stack.
+func NewPointPointer(x, y float64) *Point {
+	return &Point{x, y}
+}
+
+// StorePoint stores a pointer in a package-level variable, which always
+// forces a heap allocation ("moved to heap: p")
+func StorePoint(x, y float64) {
+	p := Point{x, y}
+	sink = &p
+}
+
+// SumBoxed stores ints in a []any before summing them, as code written
+// against interface{} APIs does. Each int that does not fit the runtime's
+// small-value cache is copied to the heap when it is boxed.
+func SumBoxed(values []int) int {
+	boxed := make([]any, len(values))
+	for i, v := range values {
+		boxed[i] = v
+	}
+	total := 0
+	for _, v := range boxed {
+		total += v.(int)
+	}
+	return total
+}
+
+// SumUnboxed is the same loop without the interface
+func SumUnboxed(values []int) int {
+	total := 0
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
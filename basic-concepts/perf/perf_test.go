@@ -0,0 +1,244 @@
+package perf
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Correctness: each pair must compute the same result, otherwise the
+// benchmarks compare different work
+
+func TestPairsAgree(t *testing.T) {
+	m := NewMatrix()
+	if m.SumValue() != m.SumPointer() || m.SumValue() != 2016 {
+		t.Errorf("SumValue = %d, SumPointer = %d; want 2016", m.SumValue(), m.SumPointer())
+	}
+
+	p := Point{1, 2}
+	scaled := p.ScaleValue(3)
+	p.ScalePointer(3)
+	if p != scaled {
+		t.Errorf("ScalePointer = %v; ScaleValue = %v", p, scaled)
+	}
+
+	if !reflect.DeepEqual(AppendGrowing(100), AppendPreallocated(100)) {
+		t.Error("AppendGrowing and AppendPreallocated differ")
+	}
+
+	parts := testParts(20)
+	want := strings.Join(parts, "")
+	for name, fn := range map[string]func([]string) string{
+		"Plus": ConcatPlus, "Sprintf": ConcatSprintf, "Builder": ConcatBuilder,
+		"BuilderGrow": ConcatBuilderGrow, "Join": ConcatJoin,
+	} {
+		if got := fn(parts); got != want {
+			t.Errorf("Concat%s = %q; want %q", name, got, want)
+		}
+	}
+
+	if !reflect.DeepEqual(FillMap(100), FillMapSized(100)) {
+		t.Error("FillMap and FillMapSized differ")
+	}
+
+	values := testValues(100)
+	if SumBoxed(values) != SumUnboxed(values) {
+		t.Error("SumBoxed and SumUnboxed differ")
+	}
+}
+
+// Allocation claims, checked with testing.AllocsPerRun
+
+func TestAllocationClaims(t *testing.T) {
+	parts := testParts(50)
+	values := testValues(50)
+
+	tests := []struct {
+		name string
+		fn   func()
+		max  float64
+	}{
+		{"preallocated slice allocates once", func() { AppendPreallocated(1000) }, 1},
+		{"sized Builder allocates once", func() { ConcatBuilderGrow(parts) }, 1},
+		{"strings.Join allocates once", func() { ConcatJoin(parts) }, 1},
+		{"value receiver does not allocate", func() { m := NewMatrix(); m.SumValue() }, 0},
+		{"returned value stays on the stack", func() { _ = NewPointValue(1, 2) }, 0},
+		{"inlined pointer stays on the stack", func() { _ = NewPointPointer(1, 2).X }, 0},
+		{"unboxed sum does not allocate", func() { SumUnboxed(values) }, 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := testing.AllocsPerRun(100, tc.fn); got > tc.max {
+				t.Errorf("allocs = %v; want <= %v", got, tc.max)
+			}
+		})
+	}
+}
+
+func TestAllocationComparisons(t *testing.T) {
+	parts := testParts(50)
+	values := testValues(50)
+
+	tests := []struct {
+		name         string
+		slower, fast func()
+	}{
+		{"growing vs preallocated slice", func() { AppendGrowing(1000) }, func() { AppendPreallocated(1000) }},
+		{"+= vs Builder", func() { ConcatPlus(parts) }, func() { ConcatBuilder(parts) }},
+		{"Builder vs sized Builder", func() { ConcatBuilder(parts) }, func() { ConcatBuilderGrow(parts) }},
+		{"map without vs with size hint", func() { FillMap(1000) }, func() { FillMapSized(1000) }},
+		{"boxed vs unboxed", func() { SumBoxed(values) }, func() { SumUnboxed(values) }},
+		{"escaping vs stack value", func() { StorePoint(1, 2) }, func() { _ = NewPointValue(1, 2) }},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			slow := testing.AllocsPerRun(20, tc.slower)
+			fast := testing.AllocsPerRun(20, tc.fast)
+			if fast >= slow {
+				t.Errorf("allocs: %v vs %v; want the second to allocate less", slow, fast)
+			}
+		})
+	}
+}
+
+// testParts returns n short strings
+func testParts(n int) []string {
+	parts := make([]string, n)
+	for i := range parts {
+		parts[i] = fmt.Sprintf("part%d-", i)
+	}
+	return parts
+}
+
+// testValues returns n ints above 255; smaller ints are boxed without
+// allocating because the runtime keeps preallocated copies of them
+func testValues(n int) []int {
+	values := make([]int, n)
+	for i := range values {
+		values[i] = 1000 + i
+	}
+	return values
+}
+
+// Benchmarks: go test -bench=. -benchmem ./basic-concepts/perf/
+
+func BenchmarkReceiver(b *testing.B) {
+	m := NewMatrix()
+	b.Run("Value", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			m.SumValue()
+		}
+	})
+	b.Run("Pointer", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			m.SumPointer()
+		}
+	})
+}
+
+func BenchmarkSmallReceiver(b *testing.B) {
+	b.Run("Value", func(b *testing.B) {
+		b.ReportAllocs()
+		p := Point{1, 2}
+		for i := 0; i < b.N; i++ {
+			p = p.ScaleValue(1.0001)
+		}
+	})
+	b.Run("Pointer", func(b *testing.B) {
+		b.ReportAllocs()
+		p := Point{1, 2}
+		for i := 0; i < b.N; i++ {
+			p.ScalePointer(1.0001)
+		}
+	})
+}
+
+func BenchmarkAppend(b *testing.B) {
+	for _, n := range []int{10, 1000, 100000} {
+		b.Run(fmt.Sprintf("Growing/%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				AppendGrowing(n)
+			}
+		})
+		b.Run(fmt.Sprintf("Preallocated/%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				AppendPreallocated(n)
+			}
+		})
+	}
+}
+
+func BenchmarkConcat(b *testing.B) {
+	parts := testParts(100)
+	impls := []struct {
+		name string
+		fn   func([]string) string
+	}{
+		{"Plus", ConcatPlus},
+		{"Sprintf", ConcatSprintf},
+		{"Builder", ConcatBuilder},
+		{"BuilderGrow", ConcatBuilderGrow},
+		{"Join", ConcatJoin},
+	}
+	for _, impl := range impls {
+		b.Run(impl.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				impl.fn(parts)
+			}
+		})
+	}
+}
+
+func BenchmarkMap(b *testing.B) {
+	for _, n := range []int{100, 10000} {
+		b.Run(fmt.Sprintf("NoHint/%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				FillMap(n)
+			}
+		})
+		b.Run(fmt.Sprintf("SizeHint/%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				FillMapSized(n)
+			}
+		})
+	}
+}
+
+func BenchmarkEscape(b *testing.B) {
+	b.Run("StackValue", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = NewPointValue(1, 2)
+		}
+	})
+	b.Run("HeapPointer", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			StorePoint(1, 2)
+		}
+	})
+}
+
+func BenchmarkBoxing(b *testing.B) {
+	values := testValues(100)
+	b.Run("Boxed", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			SumBoxed(values)
+		}
+	})
+	b.Run("Unboxed", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			SumUnboxed(values)
+		}
+	})
+}
@@ -0,0 +1,204 @@
+package protobufwire
+
+import (
+	"fmt"
+	"math"
+	"unicode/utf8"
+)
+
+// Book is the Go side of this message, written by hand instead of by
+// protoc-gen-go:
+//
+//	syntax = "proto3";
+//
+//	message Book {
+//	  int32 id = 1;
+//	  string title = 2;
+//	  string author = 3;
+//	  int64 price_cents = 4;
+//	  repeated string tags = 5;
+//	  bool in_print = 6;
+//	  double rating = 7;
+//	  repeated uint32 chapter_pages = 8; // packed, the proto3 default
+//	}
+type Book struct {
+	ID           int32
+	Title        string
+	Author       string
+	PriceCents   int64
+	Tags         []string
+	InPrint      bool
+	Rating       float64
+	ChapterPages []uint32
+}
+
+// Field numbers of Book. They, not the names, are what goes on the wire,
+// so renaming a field is compatible and renumbering one is not.
+const (
+	bookID           FieldNumber = 1
+	bookTitle        FieldNumber = 2
+	bookAuthor       FieldNumber = 3
+	bookPriceCents   FieldNumber = 4
+	bookTags         FieldNumber = 5
+	bookInPrint      FieldNumber = 6
+	bookRating       FieldNumber = 7
+	bookChapterPages FieldNumber = 8
+)
+
+// Marshal encodes b in field-number order. As in proto3, fields holding
+// their zero value are left out, so Book{} encodes to no bytes at all.
+func (b Book) Marshal() []byte {
+	var out []byte
+	if b.ID != 0 {
+		out = AppendTag(out, bookID, VarintType)
+		out = AppendVarint(out, uint64(int64(b.ID))) // sign-extended: -1 takes 10 bytes
+	}
+	if b.Title != "" {
+		out = AppendTag(out, bookTitle, BytesType)
+		out = AppendString(out, b.Title)
+	}
+	if b.Author != "" {
+		out = AppendTag(out, bookAuthor, BytesType)
+		out = AppendString(out, b.Author)
+	}
+	if b.PriceCents != 0 {
+		out = AppendTag(out, bookPriceCents, VarintType)
+		out = AppendVarint(out, uint64(b.PriceCents))
+	}
+	for _, tag := range b.Tags {
+		// Each element of a repeated string is a field of its own
+		out = AppendTag(out, bookTags, BytesType)
+		out = AppendString(out, tag)
+	}
+	if b.InPrint {
+		out = AppendTag(out, bookInPrint, VarintType)
+		out = AppendVarint(out, 1)
+	}
+	if b.Rating != 0 {
+		out = AppendTag(out, bookRating, Fixed64Type)
+		out = AppendDouble(out, b.Rating)
+	}
+	if len(b.ChapterPages) > 0 {
+		// Packed: one tag and length for all the varints
+		size := 0
+		for _, p := range b.ChapterPages {
+			size += SizeVarint(uint64(p))
+		}
+		out = AppendTag(out, bookChapterPages, BytesType)
+		out = AppendVarint(out, uint64(size))
+		for _, p := range b.ChapterPages {
+			out = AppendVarint(out, uint64(p))
+		}
+	}
+	return out
+}
+
+// Unmarshal decodes data into b, replacing what it held. Fields it does
+// not know are skipped. A scalar field that appears twice takes the last
+// value, and chapter_pages is read packed or not, as the spec requires of
+// parsers.
+func (b *Book) Unmarshal(data []byte) error {
+	*b = Book{}
+	for len(data) > 0 {
+		num, typ, n, err := ConsumeTag(data)
+		if err != nil {
+			return err
+		}
+		data = data[n:]
+
+		want, known := bookWireTypes[num]
+		if num == bookChapterPages && typ == VarintType {
+			want = VarintType // an unpacked element
+		}
+		if !known {
+			n, err := ConsumeFieldValue(typ, data)
+			if err != nil {
+				return fmt.Errorf("field %d: %w", num, err)
+			}
+			data = data[n:]
+			continue
+		}
+		if typ != want {
+			return fmt.Errorf("protobufwire: field %d has wire type %d; want %d", num, typ, want)
+		}
+
+		n, err = b.consumeField(num, typ, data)
+		if err != nil {
+			return fmt.Errorf("field %d: %w", num, err)
+		}
+		data = data[n:]
+	}
+	return nil
+}
+
+// bookWireTypes is the wire type each known field is written with
+var bookWireTypes = map[FieldNumber]WireType{
+	bookID:           VarintType,
+	bookTitle:        BytesType,
+	bookAuthor:       BytesType,
+	bookPriceCents:   VarintType,
+	bookTags:         BytesType,
+	bookInPrint:      VarintType,
+	bookRating:       Fixed64Type,
+	bookChapterPages: BytesType,
+}
+
+// consumeField reads the value of field num into b, returning its length
+func (b *Book) consumeField(num FieldNumber, typ WireType, data []byte) (int, error) {
+	switch typ {
+	case VarintType:
+		v, n, err := ConsumeVarint(data)
+		if err != nil {
+			return 0, err
+		}
+		switch num {
+		case bookID:
+			b.ID = int32(v) // int32 keeps the low 32 bits
+		case bookPriceCents:
+			b.PriceCents = int64(v)
+		case bookInPrint:
+			b.InPrint = v != 0
+		case bookChapterPages:
+			b.ChapterPages = append(b.ChapterPages, uint32(v))
+		}
+		return n, nil
+
+	case Fixed64Type:
+		v, n, err := ConsumeFixed64(data)
+		if err != nil {
+			return 0, err
+		}
+		b.Rating = math.Float64frombits(v)
+		return n, nil
+
+	default: // BytesType
+		v, n, err := ConsumeBytes(data)
+		if err != nil {
+			return 0, err
+		}
+		if num == bookChapterPages {
+			for len(v) > 0 {
+				p, m, err := ConsumeVarint(v)
+				if err != nil {
+					return 0, err
+				}
+				b.ChapterPages = append(b.ChapterPages, uint32(p))
+				v = v[m:]
+			}
+			return n, nil
+		}
+		// proto3 strings must be UTF-8; bytes fields need not be
+		if !utf8.Valid(v) {
+			return 0, fmt.Errorf("protobufwire: string is not valid UTF-8")
+		}
+		switch num {
+		case bookTitle:
+			b.Title = string(v)
+		case bookAuthor:
+			b.Author = string(v)
+		case bookTags:
+			b.Tags = append(b.Tags, string(v))
+		}
+		return n, nil
+	}
+}
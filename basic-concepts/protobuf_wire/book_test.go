@@ -0,0 +1,151 @@
+package protobufwire
+
+import (
+	"bytes"
+	"encoding/hex"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// unhex turns "08 96 01" into bytes
+func unhex(t testing.TB, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(strings.ReplaceAll(s, " ", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestBook_KnownBytes(t *testing.T) {
+	tests := []struct {
+		name string
+		book Book
+		want string
+	}{
+		{"empty", Book{}, ""},
+		{"id 150", Book{ID: 150}, "08 96 01"},
+		{"negative id", Book{ID: -1}, "08 ff ff ff ff ff ff ff ff ff 01"},
+		{"title", Book{Title: "testing"}, "12 07 74 65 73 74 69 6e 67"},
+		{"price", Book{PriceCents: 2499}, "20 c3 13"},
+		{"tags", Book{Tags: []string{"a", "", "b"}}, "2a 01 61 2a 00 2a 01 62"},
+		{"in print", Book{InPrint: true}, "30 01"},
+		{"rating", Book{Rating: 1.5}, "39 00 00 00 00 00 00 f8 3f"},
+		{"packed pages", Book{ChapterPages: []uint32{3, 270, 86942}}, "42 06 03 8e 02 9e a7 05"},
+		{"fields in number order", Book{InPrint: true, ID: 1, Author: "A"}, "08 01 1a 01 41 30 01"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			want := unhex(t, tc.want)
+			got := tc.book.Marshal()
+			if !bytes.Equal(got, want) {
+				t.Errorf("Marshal = % x; want % x", got, want)
+			}
+			var decoded Book
+			if err := decoded.Unmarshal(want); err != nil {
+				t.Fatalf("Unmarshal(% x): %v", want, err)
+			}
+			if !reflect.DeepEqual(decoded, tc.book) {
+				t.Errorf("Unmarshal(% x) = %+v; want %+v", want, decoded, tc.book)
+			}
+		})
+	}
+}
+
+func TestBook_Unmarshal(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want Book
+	}{
+		{"unpacked pages", "40 03 40 8e 02", Book{ChapterPages: []uint32{3, 270}}},
+		{"packed and unpacked mixed", "42 01 03 40 04", Book{ChapterPages: []uint32{3, 4}}},
+		{"last scalar wins", "08 01 12 01 61 08 02 12 01 62", Book{ID: 2, Title: "b"}},
+		{"int32 keeps the low 32 bits", "08 80 80 80 80 10", Book{ID: 0}},
+		{"any non-zero varint is true", "30 02", Book{InPrint: true}},
+		{"unknown fields skipped", "08 07 48 96 01 51 00 00 00 00 00 00 00 00 5a 02 68 69 65 0c 00 00 00 12 01 78", Book{ID: 7, Title: "x"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Book{Author: "replaced"}
+			if err := got.Unmarshal(unhex(t, tc.in)); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Unmarshal(%s) = %+v; want %+v", tc.in, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBook_UnmarshalErrors(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"truncated tag", "80", "unexpected end of input"},
+		{"field number 0", "00 01", "invalid field number 0"},
+		{"truncated varint", "08 96", "field 1: protobufwire: unexpected end of input"},
+		{"truncated string", "12 05 61 62", "field 2: protobufwire: unexpected end of input"},
+		{"truncated double", "39 00 00", "field 7: protobufwire: unexpected end of input"},
+		{"wrong wire type", "12 01 61 15 00 00 00 00", "field 2 has wire type 5; want 2"},
+		{"invalid UTF-8", "1a 02 c3 28", "field 3: protobufwire: string is not valid UTF-8"},
+		{"bad packed varint", "42 01 96", "field 8: protobufwire: unexpected end of input"},
+		{"unknown field truncated", "4a 05 61", "field 9: protobufwire: unexpected end of input"},
+		{"group", "4b 4c", "unsupported wire type 3"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var b Book
+			if err := b.Unmarshal(unhex(t, tc.in)); err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("Unmarshal(%s) error = %v; want it to contain %q", tc.in, err, tc.want)
+			}
+		})
+	}
+}
+
+func TestDecodeRaw(t *testing.T) {
+	data := Book{ID: 150, Title: "Go", Rating: 2, ChapterPages: []uint32{1}}.Marshal()
+	data = append(AppendTag(data, 9, Fixed32Type), 1, 0, 0, 0)
+	got, err := DecodeRaw(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"1: varint 150",
+		`2: len 2 "Go"`,
+		"7: i64 4611686018427387904 (as double 2)",
+		`8: len 1 "\x01"`,
+		"9: wire type 5, 4 bytes",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DecodeRaw = %q; want %q", got, want)
+	}
+
+	if got, err := DecodeRaw(unhex(t, "08 01 12 05")); err == nil || len(got) != 1 {
+		t.Errorf("DecodeRaw of a truncated message = %q, %v; want the first field and an error", got, err)
+	}
+}
+
+// FuzzBookUnmarshal checks the decoder never panics on arbitrary input and
+// that whatever it accepts encodes to bytes it decodes back to the same
+// encoding
+func FuzzBookUnmarshal(f *testing.F) {
+	f.Add(Book{ID: 150, Title: "Go in Action", Tags: []string{"go"}, Rating: 4.5, ChapterPages: []uint32{3, 270}}.Marshal())
+	f.Add([]byte{0x40, 0x03, 0x4a, 0x01, 0x61})
+	f.Add([]byte{0x08, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var b Book
+		if err := b.Unmarshal(data); err != nil {
+			return
+		}
+		encoded := b.Marshal()
+		var again Book
+		if err := again.Unmarshal(encoded); err != nil {
+			t.Fatalf("Unmarshal(Marshal(%+v)): %v", b, err)
+		}
+		if reencoded := again.Marshal(); !bytes.Equal(reencoded, encoded) {
+			t.Fatalf("% x decodes to % x, which re-encodes as % x", data, encoded, reencoded)
+		}
+	})
+}
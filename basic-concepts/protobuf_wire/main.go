@@ -0,0 +1,139 @@
+package protobufwire
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/rehan/go-interview-prep/pkg/quiz"
+)
+
+// Run encodes and decodes the example messages, printing their bytes to w
+func Run(w io.Writer) error {
+	fmt.Fprintln(w, "=========================================")
+	fmt.Fprintln(w, "PROTOCOL BUFFERS WIRE FORMAT EXAMPLES")
+	fmt.Fprintln(w, "=========================================")
+
+	VarintExample(w)
+	BookExample(w)
+	UnknownFieldsExample(w)
+
+	// Interview questions
+	ProtobufInterviewQuestions(w)
+	return nil
+}
+
+// DecodeRaw describes each field of a message without its schema, as
+// protoc --decode_raw does: the number, the wire type and the value,
+// shown as a number for varints and fixed-width values and as a quoted
+// string for length-delimited ones. The schema is needed to tell a string
+// from a nested message or a double from a fixed64.
+func DecodeRaw(data []byte) ([]string, error) {
+	var fields []string
+	for len(data) > 0 {
+		num, typ, n, err := ConsumeTag(data)
+		if err != nil {
+			return fields, err
+		}
+		data = data[n:]
+
+		var desc string
+		switch typ {
+		case VarintType:
+			v, m, err := ConsumeVarint(data)
+			if err != nil {
+				return fields, err
+			}
+			desc, n = fmt.Sprintf("%d: varint %d", num, v), m
+		case Fixed64Type:
+			v, m, err := ConsumeFixed64(data)
+			if err != nil {
+				return fields, err
+			}
+			desc, n = fmt.Sprintf("%d: i64 %d (as double %g)", num, v, math.Float64frombits(v)), m
+		case BytesType:
+			v, m, err := ConsumeBytes(data)
+			if err != nil {
+				return fields, err
+			}
+			desc, n = fmt.Sprintf("%d: len %d %q", num, len(v), v), m
+		default:
+			m, err := ConsumeFieldValue(typ, data)
+			if err != nil {
+				return fields, err
+			}
+			desc, n = fmt.Sprintf("%d: wire type %d, %d bytes", num, typ, m), m
+		}
+		fields = append(fields, desc)
+		data = data[n:]
+	}
+	return fields, nil
+}
+
+// VarintExample shows how integers grow on the wire
+func VarintExample(w io.Writer) {
+	fmt.Fprintln(w, "=== VARINTS AND ZIGZAG EXAMPLE ===")
+
+	for _, v := range []uint64{1, 127, 128, 150, 300, math.MaxUint32} {
+		fmt.Fprintf(w, "varint %-10d % x\n", v, AppendVarint(nil, v))
+	}
+	for _, v := range []int64{-1, 1, -64, 64} {
+		fmt.Fprintf(w, "int64 %3d: % x; sint64 (zigzag %d): % x\n",
+			v, AppendVarint(nil, uint64(v)), EncodeZigZag(v), AppendVarint(nil, EncodeZigZag(v)))
+	}
+	fmt.Fprintln(w)
+}
+
+// BookExample encodes a book, lists its fields and compares the size
+// with JSON
+func BookExample(w io.Writer) {
+	fmt.Fprintln(w, "=== BOOK MESSAGE EXAMPLE ===")
+
+	book := Book{
+		ID: 150, Title: "Go in Action", Author: "William Kennedy", PriceCents: 2499,
+		Tags: []string{"go", "concurrency"}, InPrint: true, Rating: 4.5, ChapterPages: []uint32{3, 270, 86942},
+	}
+	data := book.Marshal()
+	fmt.Fprintf(w, "Encoded: % x\n", data)
+	fields, _ := DecodeRaw(data)
+	for _, f := range fields {
+		fmt.Fprintln(w, " ", f)
+	}
+
+	var decoded Book
+	err := decoded.Unmarshal(data)
+	fmt.Fprintf(w, "Decoded: %+v, err: %v\n", decoded, err)
+
+	jsonData, _ := json.Marshal(book)
+	fmt.Fprintf(w, "%d bytes as protobuf, %d as JSON: no field names, no quotes, binary numbers\n", len(data), len(jsonData))
+	fmt.Fprintf(w, "Book{} encodes to %d bytes: zero values are not written\n", len(Book{}.Marshal()))
+	fmt.Fprintln(w)
+}
+
+// UnknownFieldsExample decodes a message written by a newer schema
+func UnknownFieldsExample(w io.Writer) {
+	fmt.Fprintln(w, "=== UNKNOWN FIELDS EXAMPLE ===")
+
+	// A newer Book with field 9, string isbn, and field 10, fixed32 stock
+	data := Book{ID: 7, Title: "Learning Go"}.Marshal()
+	data = AppendString(AppendTag(data, 9, BytesType), "978-1492077213")
+	data = append(AppendTag(data, 10, Fixed32Type), 12, 0, 0, 0)
+
+	var old Book
+	err := old.Unmarshal(data)
+	fmt.Fprintf(w, "Old reader skips fields 9 and 10: %+v, err: %v\n", old, err)
+
+	var mismatch Book
+	err = mismatch.Unmarshal(AppendVarint(AppendTag(nil, bookTitle, VarintType), 1))
+	fmt.Fprintln(w, "Changing a field's type breaks readers:", err)
+	fmt.Fprintln(w)
+}
+
+// ProtobufInterviewQuestions lists common interview questions about the
+// protobuf wire format
+func ProtobufInterviewQuestions(w io.Writer) {
+	if err := quiz.Print(w, "protobuf-wire"); err != nil {
+		fmt.Fprintln(w, err)
+	}
+}
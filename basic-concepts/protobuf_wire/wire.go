@@ -0,0 +1,190 @@
+package protobufwire
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// THE WIRE FORMAT
+//
+// A message is a sequence of fields, each a tag followed by a value. The
+// tag is the varint fieldNumber<<3 | wireType, so field numbers 1 to 15
+// fit in one byte. The wire type says how long the value is, which is all
+// a decoder needs to skip a field it does not know:
+//
+//	0 VARINT  int32, int64, uint32, uint64, sint32, sint64, bool, enum
+//	1 I64     fixed64, sfixed64, double: 8 bytes, little-endian
+//	2 LEN     string, bytes, embedded messages, packed repeated fields:
+//	          a varint length, then that many bytes
+//	5 I32     fixed32, sfixed32, float: 4 bytes, little-endian
+//
+// Types 3 and 4 start and end groups, deprecated before proto3.
+
+// WireType is the low three bits of a tag
+type WireType int
+
+const (
+	VarintType  WireType = 0
+	Fixed64Type WireType = 1
+	BytesType   WireType = 2
+	Fixed32Type WireType = 5
+)
+
+// FieldNumber identifies a field within its message. 0 is invalid and
+// 19000 to 19999 are reserved for the implementation.
+type FieldNumber int32
+
+// MaxFieldNumber is the largest field number: 2^29 - 1, since the tag
+// holds it in a 32-bit varint above the three wire-type bits
+const MaxFieldNumber FieldNumber = 1<<29 - 1
+
+var (
+	// ErrTruncated is returned when the input ends inside a value
+	ErrTruncated = errors.New("protobufwire: unexpected end of input")
+
+	// ErrOverflow is returned for a varint that does not fit in 64 bits:
+	// more than 10 bytes, or a tenth byte above 1
+	ErrOverflow = errors.New("protobufwire: varint overflows 64 bits")
+)
+
+// AppendVarint appends v in base 128, least significant group first, with
+// the high bit of each byte set when more follow: 1 to 10 bytes
+func AppendVarint(b []byte, v uint64) []byte {
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(b, byte(v))
+}
+
+// ConsumeVarint reads a varint from the start of b, returning it and the
+// number of bytes it took
+func ConsumeVarint(b []byte) (uint64, int, error) {
+	var v uint64
+	for i := 0; i < len(b); i++ {
+		c := b[i]
+		if i == binary.MaxVarintLen64-1 && c > 1 {
+			return 0, 0, ErrOverflow // the tenth byte holds only bit 63
+		}
+		v |= uint64(c&0x7f) << (7 * i)
+		if c < 0x80 {
+			return v, i + 1, nil
+		}
+	}
+	return 0, 0, ErrTruncated
+}
+
+// SizeVarint returns how many bytes AppendVarint writes for v
+func SizeVarint(v uint64) int {
+	n := 1
+	for v >= 0x80 {
+		v >>= 7
+		n++
+	}
+	return n
+}
+
+// EncodeZigZag maps signed integers to unsigned ones so small magnitudes
+// stay small: 0, -1, 1, -2, ... become 0, 1, 2, 3, ... sint32 and sint64
+// fields use it; int32 and int64 write a negative number as its 64-bit
+// two's complement, which always takes ten bytes.
+func EncodeZigZag(v int64) uint64 {
+	return uint64(v<<1) ^ uint64(v>>63)
+}
+
+// DecodeZigZag reverses EncodeZigZag
+func DecodeZigZag(v uint64) int64 {
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+// AppendTag appends the tag of a field
+func AppendTag(b []byte, num FieldNumber, typ WireType) []byte {
+	return AppendVarint(b, uint64(num)<<3|uint64(typ))
+}
+
+// ConsumeTag reads a tag, checking the field number is valid
+func ConsumeTag(b []byte) (FieldNumber, WireType, int, error) {
+	v, n, err := ConsumeVarint(b)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	num := v >> 3
+	if num == 0 || num > uint64(MaxFieldNumber) {
+		return 0, 0, 0, fmt.Errorf("protobufwire: invalid field number %d", num)
+	}
+	return FieldNumber(num), WireType(v & 7), n, nil
+}
+
+// AppendBytes appends a length-delimited value: its length as a varint,
+// then v
+func AppendBytes(b, v []byte) []byte {
+	b = AppendVarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+// AppendString appends s as AppendBytes does
+func AppendString(b []byte, s string) []byte {
+	b = AppendVarint(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+// ConsumeBytes reads a length-delimited value. The result shares b's
+// memory.
+func ConsumeBytes(b []byte) ([]byte, int, error) {
+	length, n, err := ConsumeVarint(b)
+	if err != nil {
+		return nil, 0, err
+	}
+	if length > uint64(len(b)-n) {
+		return nil, 0, ErrTruncated
+	}
+	end := n + int(length)
+	return b[n:end], end, nil
+}
+
+// AppendFixed64 appends v as 8 little-endian bytes
+func AppendFixed64(b []byte, v uint64) []byte {
+	return binary.LittleEndian.AppendUint64(b, v)
+}
+
+// ConsumeFixed64 reads 8 little-endian bytes
+func ConsumeFixed64(b []byte) (uint64, int, error) {
+	if len(b) < 8 {
+		return 0, 0, ErrTruncated
+	}
+	return binary.LittleEndian.Uint64(b), 8, nil
+}
+
+// AppendDouble appends a double, its IEEE 754 bits as a fixed64
+func AppendDouble(b []byte, v float64) []byte {
+	return AppendFixed64(b, math.Float64bits(v))
+}
+
+// ConsumeFieldValue returns the length of the value of a field of type
+// typ at the start of b, so a decoder can skip fields it does not know.
+// Skipping rather than failing is what lets an old program read messages
+// from a newer schema.
+func ConsumeFieldValue(typ WireType, b []byte) (int, error) {
+	switch typ {
+	case VarintType:
+		_, n, err := ConsumeVarint(b)
+		return n, err
+	case Fixed64Type:
+		if len(b) < 8 {
+			return 0, ErrTruncated
+		}
+		return 8, nil
+	case BytesType:
+		_, n, err := ConsumeBytes(b)
+		return n, err
+	case Fixed32Type:
+		if len(b) < 4 {
+			return 0, ErrTruncated
+		}
+		return 4, nil
+	default:
+		return 0, fmt.Errorf("protobufwire: unsupported wire type %d", typ)
+	}
+}
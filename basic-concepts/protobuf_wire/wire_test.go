@@ -0,0 +1,156 @@
+package protobufwire
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestVarint(t *testing.T) {
+	tests := []struct {
+		v    uint64
+		want []byte
+	}{
+		{0, []byte{0x00}},
+		{1, []byte{0x01}},
+		{127, []byte{0x7f}},
+		{128, []byte{0x80, 0x01}},
+		{150, []byte{0x96, 0x01}}, // the example in the protobuf encoding guide
+		{300, []byte{0xac, 0x02}},
+		{16384, []byte{0x80, 0x80, 0x01}},
+		{math.MaxUint64, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}},
+	}
+	for _, tc := range tests {
+		got := AppendVarint(nil, tc.v)
+		if !bytes.Equal(got, tc.want) {
+			t.Errorf("AppendVarint(%d) = % x; want % x", tc.v, got, tc.want)
+		}
+		if n := SizeVarint(tc.v); n != len(tc.want) {
+			t.Errorf("SizeVarint(%d) = %d; want %d", tc.v, n, len(tc.want))
+		}
+		v, n, err := ConsumeVarint(append(tc.want, 0xaa)) // trailing bytes are left alone
+		if v != tc.v || n != len(tc.want) || err != nil {
+			t.Errorf("ConsumeVarint(% x) = %d, %d, %v; want %d, %d", tc.want, v, n, err, tc.v, len(tc.want))
+		}
+	}
+}
+
+func TestConsumeVarint_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []byte
+		want error
+	}{
+		{"empty", nil, ErrTruncated},
+		{"continuation at the end", []byte{0x96}, ErrTruncated},
+		{"tenth byte too big", []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x02}, ErrOverflow},
+		{"eleven bytes", []byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x01}, ErrOverflow},
+	}
+	for _, tc := range tests {
+		if _, _, err := ConsumeVarint(tc.in); !errors.Is(err, tc.want) {
+			t.Errorf("%s: ConsumeVarint(% x) error = %v; want %v", tc.name, tc.in, err, tc.want)
+		}
+	}
+}
+
+func TestZigZag(t *testing.T) {
+	tests := []struct {
+		v    int64
+		want uint64
+	}{
+		{0, 0},
+		{-1, 1},
+		{1, 2},
+		{-2, 3},
+		{2147483647, 4294967294},
+		{-2147483648, 4294967295},
+		{math.MaxInt64, math.MaxUint64 - 1},
+		{math.MinInt64, math.MaxUint64},
+	}
+	for _, tc := range tests {
+		if got := EncodeZigZag(tc.v); got != tc.want {
+			t.Errorf("EncodeZigZag(%d) = %d; want %d", tc.v, got, tc.want)
+		}
+		if got := DecodeZigZag(tc.want); got != tc.v {
+			t.Errorf("DecodeZigZag(%d) = %d; want %d", tc.want, got, tc.v)
+		}
+	}
+}
+
+func TestTag(t *testing.T) {
+	tests := []struct {
+		num  FieldNumber
+		typ  WireType
+		want []byte
+	}{
+		{1, VarintType, []byte{0x08}},
+		{2, BytesType, []byte{0x12}},
+		{15, Fixed32Type, []byte{0x7d}},
+		{16, VarintType, []byte{0x80, 0x01}}, // the first field number needing two bytes
+		{MaxFieldNumber, Fixed64Type, []byte{0xf9, 0xff, 0xff, 0xff, 0x0f}},
+	}
+	for _, tc := range tests {
+		got := AppendTag(nil, tc.num, tc.typ)
+		if !bytes.Equal(got, tc.want) {
+			t.Errorf("AppendTag(%d, %d) = % x; want % x", tc.num, tc.typ, got, tc.want)
+		}
+		num, typ, n, err := ConsumeTag(got)
+		if num != tc.num || typ != tc.typ || n != len(got) || err != nil {
+			t.Errorf("ConsumeTag(% x) = %d, %d, %d, %v", got, num, typ, n, err)
+		}
+	}
+
+	for _, in := range [][]byte{{0x00}, {0x07}, AppendVarint(nil, uint64(MaxFieldNumber+1)<<3)} {
+		if _, _, _, err := ConsumeTag(in); err == nil {
+			t.Errorf("ConsumeTag(% x): want an invalid field number error", in)
+		}
+	}
+}
+
+func TestBytesAndFixed(t *testing.T) {
+	if got, want := AppendString(nil, "testing"), []byte{0x07, 't', 'e', 's', 't', 'i', 'n', 'g'}; !bytes.Equal(got, want) {
+		t.Errorf("AppendString = % x; want % x", got, want)
+	}
+	long := bytes.Repeat([]byte{'x'}, 200)
+	enc := AppendBytes(nil, long)
+	if !bytes.Equal(enc[:2], []byte{0xc8, 0x01}) {
+		t.Errorf("length of 200 bytes = % x; want c8 01", enc[:2])
+	}
+	if v, n, err := ConsumeBytes(enc); !bytes.Equal(v, long) || n != 202 || err != nil {
+		t.Errorf("ConsumeBytes = %d bytes, %d, %v", len(v), n, err)
+	}
+	if _, _, err := ConsumeBytes([]byte{0x05, 'a', 'b'}); !errors.Is(err, ErrTruncated) {
+		t.Errorf("ConsumeBytes of a short value: error = %v; want ErrTruncated", err)
+	}
+
+	if got, want := AppendDouble(nil, 1.5), []byte{0, 0, 0, 0, 0, 0, 0xf8, 0x3f}; !bytes.Equal(got, want) {
+		t.Errorf("AppendDouble(1.5) = % x; want % x", got, want)
+	}
+	if _, _, err := ConsumeFixed64(make([]byte, 7)); !errors.Is(err, ErrTruncated) {
+		t.Errorf("ConsumeFixed64 of 7 bytes: error = %v; want ErrTruncated", err)
+	}
+}
+
+func TestConsumeFieldValue(t *testing.T) {
+	tests := []struct {
+		typ  WireType
+		in   []byte
+		want int
+		err  bool
+	}{
+		{VarintType, []byte{0x96, 0x01, 0xff}, 2, false},
+		{Fixed64Type, make([]byte, 9), 8, false},
+		{BytesType, []byte{0x02, 'h', 'i', 0xff}, 3, false},
+		{Fixed32Type, make([]byte, 4), 4, false},
+		{Fixed32Type, make([]byte, 3), 0, true},
+		{3, []byte{0x00}, 0, true}, // start group
+		{6, []byte{0x00}, 0, true},
+	}
+	for _, tc := range tests {
+		n, err := ConsumeFieldValue(tc.typ, tc.in)
+		if n != tc.want || (err != nil) != tc.err {
+			t.Errorf("ConsumeFieldValue(%d, % x) = %d, %v; want %d, error %v", tc.typ, tc.in, n, err, tc.want, tc.err)
+		}
+	}
+}
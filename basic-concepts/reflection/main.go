@@ -0,0 +1,261 @@
+package reflection
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/rehan/go-interview-prep/pkg/quiz"
+)
+
+// Run inspects the example values with reflect, printing to w
+func Run(w io.Writer) error {
+	fmt.Fprintln(w, "=========================================")
+	fmt.Fprintln(w, "GO REFLECTION EXAMPLES")
+	fmt.Fprintln(w, "=========================================")
+
+	// Type and Value basics
+	ReflectionBasicsExample(w)
+
+	// Struct to map
+	StructToMapExample(w)
+
+	// Tag driven validation
+	ValidationExample(w)
+
+	// Deep equality
+	DeepEqualExample(w)
+
+	// Interview questions
+	ReflectionInterviewQuestions(w)
+	return nil
+}
+
+// Product is the sample type used throughout the examples
+type Product struct {
+	ID       int      `json:"id"`
+	Name     string   `json:"name" check:"nonzero"`
+	Price    float64  `json:"price" check:"min=0.01"`
+	Tags     []string `json:"tags,omitempty"`
+	internal string   // unexported fields are invisible to StructToMap
+}
+
+// StructToMap converts a struct (or pointer to struct) into a map keyed by
+// the json tag name, falling back to the field name. Fields tagged "-" and
+// unexported fields are skipped.
+func StructToMap(v any) (map[string]any, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, errors.New("StructToMap: nil pointer")
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("StructToMap: expected struct, got %s", rv.Kind())
+	}
+
+	rt := rv.Type()
+	out := make(map[string]any, rt.NumField())
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			tagName, _, _ := strings.Cut(tag, ",")
+			if tagName == "-" {
+				continue
+			}
+			if tagName != "" {
+				name = tagName
+			}
+		}
+		out[name] = rv.Field(i).Interface()
+	}
+	return out, nil
+}
+
+// productToMap is the hand-written equivalent of StructToMap for Product,
+// used by the benchmarks to show what reflection costs
+func productToMap(p Product) map[string]any {
+	return map[string]any{
+		"id":    p.ID,
+		"name":  p.Name,
+		"price": p.Price,
+		"tags":  p.Tags,
+	}
+}
+
+// FieldError describes one failed check
+type FieldError struct {
+	Field string
+	Rule  string
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s: failed %q", e.Field, e.Rule)
+}
+
+// Check validates struct fields using `check:"..."` tags.
+// Supported rules: nonzero, and min=N for numeric fields.
+// It is a deliberately small example of reading struct tags.
+func Check(v any) error {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("Check: expected struct, got %s", rv.Kind())
+	}
+
+	var errs []error
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		tag := field.Tag.Get("check")
+		if tag == "" || !field.IsExported() {
+			continue
+		}
+		fv := rv.Field(i)
+		for _, rule := range strings.Split(tag, ",") {
+			if err := checkRule(field.Name, fv, rule); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkRule applies a single rule to a field value
+func checkRule(name string, fv reflect.Value, rule string) error {
+	key, arg, _ := strings.Cut(rule, "=")
+	switch key {
+	case "nonzero":
+		if fv.IsZero() {
+			return FieldError{Field: name, Rule: rule}
+		}
+	case "min":
+		limit, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return fmt.Errorf("%s: bad rule %q: %w", name, rule, err)
+		}
+		var n float64
+		switch {
+		case fv.CanInt():
+			n = float64(fv.Int())
+		case fv.CanUint():
+			n = float64(fv.Uint())
+		case fv.CanFloat():
+			n = fv.Float()
+		default:
+			return fmt.Errorf("%s: rule %q needs a numeric field, got %s", name, rule, fv.Kind())
+		}
+		if n < limit {
+			return FieldError{Field: name, Rule: rule}
+		}
+	default:
+		return fmt.Errorf("%s: unknown rule %q", name, rule)
+	}
+	return nil
+}
+
+// checkProduct is the hand-written equivalent of Check for Product
+func checkProduct(p Product) error {
+	var errs []error
+	if p.Name == "" {
+		errs = append(errs, FieldError{Field: "Name", Rule: "nonzero"})
+	}
+	if p.Price < 0.01 {
+		errs = append(errs, FieldError{Field: "Price", Rule: "min=0.01"})
+	}
+	return errors.Join(errs...)
+}
+
+// DeepEqual reports whether a and b are deeply equal.
+// It wraps reflect.DeepEqual with type parameters so mismatched types
+// become a compile error instead of a silent false.
+func DeepEqual[T any](a, b T) bool {
+	return reflect.DeepEqual(a, b)
+}
+
+// productsEqual is the hand-written equivalent of DeepEqual for Product
+func productsEqual(a, b Product) bool {
+	if a.ID != b.ID || a.Name != b.Name || a.Price != b.Price || a.internal != b.internal {
+		return false
+	}
+	if len(a.Tags) != len(b.Tags) || (a.Tags == nil) != (b.Tags == nil) {
+		return false
+	}
+	for i := range a.Tags {
+		if a.Tags[i] != b.Tags[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// ReflectionBasicsExample shows reflect.Type, reflect.Value and Kind
+func ReflectionBasicsExample(w io.Writer) {
+	fmt.Fprintln(w, "=== REFLECTION BASICS EXAMPLE ===")
+
+	p := Product{ID: 1, Name: "Gopher plush", Price: 19.99}
+	t := reflect.TypeOf(p)
+	v := reflect.ValueOf(p)
+	fmt.Fprintf(w, "Type: %s, Kind: %s, NumField: %d\n", t.Name(), t.Kind(), t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		fmt.Fprintf(w, "  %-8s %-9s exported=%-5v tag=%q\n", f.Name, f.Type, f.IsExported(), f.Tag)
+	}
+
+	// Setting a value requires an addressable Value, i.e. one obtained through a pointer
+	pv := reflect.ValueOf(&p).Elem()
+	pv.FieldByName("Price").SetFloat(24.99)
+	fmt.Fprintf(w, "Price after reflective set: %.2f\n", p.Price)
+	fmt.Fprintf(w, "CanSet on a copy: %v\n", v.FieldByName("Price").CanSet())
+	fmt.Fprintln(w)
+}
+
+// StructToMapExample converts a struct into a map
+func StructToMapExample(w io.Writer) {
+	fmt.Fprintln(w, "=== STRUCT TO MAP EXAMPLE ===")
+
+	m, err := StructToMap(Product{ID: 7, Name: "Mug", Price: 9.5, Tags: []string{"kitchen"}})
+	if err != nil {
+		fmt.Fprintln(w, "Error:", err)
+		return
+	}
+	fmt.Fprintf(w, "%v\n", m)
+	fmt.Fprintln(w)
+}
+
+// ValidationExample validates structs with tags
+func ValidationExample(w io.Writer) {
+	fmt.Fprintln(w, "=== TAG DRIVEN VALIDATION EXAMPLE ===")
+
+	fmt.Fprintln(w, "Valid product:", Check(Product{Name: "Pen", Price: 1.5}))
+	fmt.Fprintln(w, "Invalid product:", Check(Product{Price: 0}))
+	fmt.Fprintln(w)
+}
+
+// DeepEqualExample compares nested values
+func DeepEqualExample(w io.Writer) {
+	fmt.Fprintln(w, "=== DEEP EQUAL EXAMPLE ===")
+
+	a := Product{ID: 1, Tags: []string{"x"}}
+	b := Product{ID: 1, Tags: []string{"x"}}
+	fmt.Fprintf(w, "DeepEqual(a, b): %v\n", DeepEqual(a, b))
+
+	// A nil slice and an empty slice are not deeply equal
+	fmt.Fprintf(w, "DeepEqual(nil, []string{}): %v\n", DeepEqual([]string(nil), []string{}))
+	fmt.Fprintln(w)
+}
+
+// ReflectionInterviewQuestions lists common interview questions about reflection
+func ReflectionInterviewQuestions(w io.Writer) {
+	if err := quiz.Print(w, "reflection"); err != nil {
+		fmt.Fprintln(w, err)
+	}
+}
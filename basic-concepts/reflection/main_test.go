@@ -0,0 +1,185 @@
+package reflection
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestStructToMap(t *testing.T) {
+	type withSkips struct {
+		Visible string `json:"visible"`
+		Skipped string `json:"-"`
+		NoTag   int
+		Options string `json:",omitempty"`
+		hidden  bool
+	}
+
+	got, err := StructToMap(&withSkips{Visible: "v", Skipped: "s", NoTag: 3, Options: "o", hidden: true})
+	if err != nil {
+		t.Fatalf("StructToMap returned error: %v", err)
+	}
+	want := map[string]any{"visible": "v", "NoTag": 3, "Options": "o"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("StructToMap = %v; want %v", got, want)
+	}
+}
+
+func TestStructToMap_MatchesHandWritten(t *testing.T) {
+	p := Product{ID: 1, Name: "Mug", Price: 9.5, Tags: []string{"a"}}
+	got, err := StructToMap(p)
+	if err != nil {
+		t.Fatalf("StructToMap returned error: %v", err)
+	}
+	if want := productToMap(p); !reflect.DeepEqual(got, want) {
+		t.Errorf("StructToMap = %v; want %v", got, want)
+	}
+}
+
+func TestStructToMap_Errors(t *testing.T) {
+	var nilProduct *Product
+	if _, err := StructToMap(nilProduct); err == nil {
+		t.Error("expected error for nil pointer")
+	}
+	if _, err := StructToMap(42); err == nil {
+		t.Error("expected error for non-struct")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		product    Product
+		wantFields []string
+	}{
+		{"valid", Product{Name: "Pen", Price: 1}, nil},
+		{"missing name", Product{Price: 1}, []string{"Name"}},
+		{"price too low", Product{Name: "Pen", Price: 0.001}, []string{"Price"}},
+		{"both invalid", Product{}, []string{"Name", "Price"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Check(tc.product)
+			for _, field := range tc.wantFields {
+				if !hasFieldError(err, field) {
+					t.Errorf("Check() = %v; want a FieldError for %s", err, field)
+				}
+			}
+			if tc.wantFields == nil && err != nil {
+				t.Errorf("Check() = %v; want nil", err)
+			}
+
+			// The reflective and hand-written validators must agree
+			if (err == nil) != (checkProduct(tc.product) == nil) {
+				t.Errorf("Check and checkProduct disagree for %+v", tc.product)
+			}
+		})
+	}
+}
+
+// hasFieldError reports whether err (possibly joined) contains a FieldError for field
+func hasFieldError(err error, field string) bool {
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		var fe FieldError
+		return errors.As(err, &fe) && fe.Field == field
+	}
+	for _, e := range joined.Unwrap() {
+		var fe FieldError
+		if errors.As(e, &fe) && fe.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCheck_BadRules(t *testing.T) {
+	type badMin struct {
+		Name string `check:"min=abc"`
+	}
+	type unknown struct {
+		Age int `check:"positive"`
+	}
+	if err := Check(badMin{Name: "x"}); err == nil {
+		t.Error("expected error for non-numeric min rule")
+	}
+	if err := Check(unknown{Age: 1}); err == nil {
+		t.Error("expected error for unknown rule")
+	}
+}
+
+func TestDeepEqual(t *testing.T) {
+	a := Product{ID: 1, Tags: []string{"x", "y"}}
+	b := Product{ID: 1, Tags: []string{"x", "y"}}
+	c := Product{ID: 1, Tags: []string{"x"}}
+
+	if !DeepEqual(a, b) || !productsEqual(a, b) {
+		t.Error("expected a and b to be equal")
+	}
+	if DeepEqual(a, c) || productsEqual(a, c) {
+		t.Error("expected a and c to differ")
+	}
+	if DeepEqual([]int(nil), []int{}) {
+		t.Error("DeepEqual treats nil and empty slices as different")
+	}
+	if DeepEqual(map[string]int{"a": 1}, map[string]int{"a": 2}) {
+		t.Error("expected maps with different values to differ")
+	}
+}
+
+// The benchmarks below are the point of this module: compare the reflective
+// version against the hand-written one with go test -bench=. -benchmem
+
+var (
+	benchProduct = Product{ID: 1, Name: "Mug", Price: 9.5, Tags: []string{"kitchen", "ceramic"}}
+	sinkMap      map[string]any
+	sinkErr      error
+	sinkBool     bool
+)
+
+func BenchmarkStructToMap_Reflect(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sinkMap, _ = StructToMap(benchProduct)
+	}
+}
+
+func BenchmarkStructToMap_Manual(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sinkMap = productToMap(benchProduct)
+	}
+}
+
+func BenchmarkCheck_Reflect(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sinkErr = Check(benchProduct)
+	}
+}
+
+func BenchmarkCheck_Manual(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sinkErr = checkProduct(benchProduct)
+	}
+}
+
+func BenchmarkDeepEqual_Reflect(b *testing.B) {
+	other := benchProduct
+	other.Tags = []string{"kitchen", "ceramic"}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sinkBool = DeepEqual(benchProduct, other)
+	}
+}
+
+func BenchmarkDeepEqual_Manual(b *testing.B) {
+	other := benchProduct
+	other.Tags = []string{"kitchen", "ceramic"}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sinkBool = productsEqual(benchProduct, other)
+	}
+}
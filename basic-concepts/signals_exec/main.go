@@ -0,0 +1,290 @@
+package signalsexec
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/quiz"
+)
+
+// childEnv selects a child behaviour when this program runs itself as a
+// subprocess, so the examples need no external commands
+const childEnv = "SIGNALS_EXEC_CHILD"
+
+// RunChild runs the child behaviour and exits if this process was started
+// as one of the examples' children; a main running the examples calls it
+// before anything else
+func RunChild() {
+	if mode := os.Getenv(childEnv); mode != "" {
+		os.Exit(childMain(mode, os.Args[1:]))
+	}
+}
+
+// Run prints the subprocess and signal examples to w, starting the running
+// executable again as each child, so the program must call RunChild first
+func Run(w io.Writer) error {
+	fmt.Fprintln(w, "=========================================")
+	fmt.Fprintln(w, "GO SIGNALS AND os/exec EXAMPLES")
+	fmt.Fprintln(w, "=========================================")
+
+	CaptureOutputExample(w)
+	StreamingExample(w)
+	TimeoutExample(w)
+	GracefulTerminationExample(w)
+	NotifyContextExample(w)
+
+	// Interview questions
+	ProcessInterviewQuestions(w)
+	return nil
+}
+
+// selfCommand runs this executable as a child in the given mode
+func selfCommand(ctx context.Context, mode string, args ...string) *exec.Cmd {
+	exe, err := os.Executable()
+	if err != nil {
+		exe = os.Args[0]
+	}
+	cmd := exec.CommandContext(ctx, exe, args...)
+	cmd.Env = append(os.Environ(), childEnv+"="+mode)
+	return cmd
+}
+
+// Output runs cmd and returns what it wrote to stdout and stderr. Unlike
+// cmd.Output, stderr is captured even when the command succeeds.
+func Output(cmd *exec.Cmd) (stdout, stderr []byte, err error) {
+	var outBuf, errBuf bytes.Buffer
+	cmd.Stdout = &outBuf
+	cmd.Stderr = &errBuf
+	err = cmd.Run()
+	return outBuf.Bytes(), errBuf.Bytes(), err
+}
+
+// StreamLines starts cmd and calls fn with each line of stdout as it is
+// written. All output must be read before Wait, which closes the pipe.
+func StreamLines(cmd *exec.Cmd, fn func(line string)) error {
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		fn(scanner.Text())
+	}
+	scanErr := scanner.Err()
+
+	if err := cmd.Wait(); err != nil {
+		return err
+	}
+	return scanErr
+}
+
+// GracefulStop changes what happens when the context of cmd (created with
+// exec.CommandContext) is done: the child receives SIGTERM instead of
+// SIGKILL, and is killed only if it has not exited after grace.
+func GracefulStop(cmd *exec.Cmd, grace time.Duration) {
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(syscall.SIGTERM)
+	}
+	cmd.WaitDelay = grace
+}
+
+// ExitCode returns the exit code carried by an error from Run or Wait:
+// 0 for nil, the child's code for *exec.ExitError, and -1 otherwise (the
+// command could not start, or was killed by a signal)
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+// RunUntilSignal runs work with a context that is cancelled when one of
+// sigs arrives (os.Interrupt and SIGTERM if none are given). The signals
+// stay caught until work returns, so work decides how long shutdown takes.
+func RunUntilSignal(ctx context.Context, work func(context.Context) error, sigs ...os.Signal) error {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	ctx, stop := signal.NotifyContext(ctx, sigs...)
+	defer stop()
+
+	return work(ctx)
+}
+
+// childMain implements the child behaviours and returns the exit code
+func childMain(mode string, args []string) int {
+	switch mode {
+	case "echo":
+		fmt.Println(strings.Join(args, " "))
+		fmt.Fprintln(os.Stderr, "warning: this went to stderr")
+		return 0
+	case "fail":
+		fmt.Fprintln(os.Stderr, "something went wrong")
+		return 3
+	case "lines":
+		for i := 1; i <= 3; i++ {
+			fmt.Printf("line %d\n", i)
+			time.Sleep(20 * time.Millisecond)
+		}
+		return 0
+	case "sleep":
+		time.Sleep(time.Minute)
+		return 0
+	case "graceful":
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM)
+		defer stop()
+		fmt.Println("ready")
+		<-ctx.Done()
+		fmt.Println("SIGTERM received, cleaned up")
+		return 0
+	case "stubborn":
+		signal.Ignore(syscall.SIGTERM)
+		fmt.Println("ready")
+		time.Sleep(time.Minute)
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "unknown child mode %q\n", mode)
+		return 2
+	}
+}
+
+// waitReady reads lines from r until the child prints "ready", so a test or
+// example never signals a child before it has installed its handler
+func waitReady(r *bufio.Reader) error {
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("child exited before it was ready: %w", err)
+		}
+		if strings.TrimSpace(line) == "ready" {
+			return nil
+		}
+	}
+}
+
+// CaptureOutputExample shows stdout, stderr and exit codes
+func CaptureOutputExample(w io.Writer) {
+	fmt.Fprintln(w, "=== CAPTURING OUTPUT ===")
+
+	stdout, stderr, err := Output(selfCommand(context.Background(), "echo", "hello", "child"))
+	fmt.Fprintf(w, "stdout: %q\nstderr: %q\nexit code: %d\n", stdout, stderr, ExitCode(err))
+
+	_, stderr, err = Output(selfCommand(context.Background(), "fail"))
+	fmt.Fprintf(w, "failing child: %v (exit code %d, stderr %q)\n", err, ExitCode(err), stderr)
+
+	_, _, err = Output(exec.Command("definitely-not-a-real-command"))
+	fmt.Fprintf(w, "missing binary: %v (exit code %d)\n", err, ExitCode(err))
+	fmt.Fprintln(w)
+}
+
+// StreamingExample shows reading output while the child is still running
+func StreamingExample(w io.Writer) {
+	fmt.Fprintln(w, "=== STREAMING STDOUT THROUGH A PIPE ===")
+
+	start := time.Now()
+	err := StreamLines(selfCommand(context.Background(), "lines"), func(line string) {
+		fmt.Fprintf(w, "  %-7s after %v\n", line, time.Since(start).Round(10*time.Millisecond))
+	})
+	fmt.Fprintln(w, "error:", err)
+	fmt.Fprintln(w)
+}
+
+// TimeoutExample shows CommandContext killing a child at its deadline
+func TimeoutExample(w io.Writer) {
+	fmt.Fprintln(w, "=== TIMEOUT WITH CommandContext ===")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := selfCommand(ctx, "sleep").Run()
+	fmt.Fprintf(w, "sleeping child stopped after %v: %v (ctx: %v)\n",
+		time.Since(start).Round(100*time.Millisecond), err, ctx.Err())
+	fmt.Fprintln(w)
+}
+
+// GracefulTerminationExample shows SIGTERM first, SIGKILL after a grace period
+func GracefulTerminationExample(w io.Writer) {
+	fmt.Fprintln(w, "=== GRACEFUL CHILD TERMINATION ===")
+
+	for _, mode := range []string{"graceful", "stubborn"} {
+		ctx, cancel := context.WithCancel(context.Background())
+		cmd := selfCommand(ctx, mode)
+		GracefulStop(cmd, 300*time.Millisecond)
+
+		pipe, err := cmd.StdoutPipe()
+		if err != nil {
+			fmt.Fprintln(w, "error:", err)
+			cancel()
+			continue
+		}
+		if err := cmd.Start(); err != nil {
+			fmt.Fprintln(w, "error:", err)
+			cancel()
+			continue
+		}
+		out := bufio.NewReader(pipe)
+		if err := waitReady(out); err != nil {
+			fmt.Fprintln(w, "error:", err)
+		}
+
+		start := time.Now()
+		cancel()
+		rest, _ := out.ReadString(0) // until EOF
+		err = cmd.Wait()
+		fmt.Fprintf(w, "%-8s child: output %q, stopped after %v, err: %v\n",
+			mode, strings.TrimSpace(rest), time.Since(start).Round(100*time.Millisecond), err)
+	}
+	fmt.Fprintln(w)
+}
+
+// NotifyContextExample sends this process SIGTERM and shows the work
+// function observing the cancelled context instead of the process dying
+func NotifyContextExample(w io.Writer) {
+	fmt.Fprintln(w, "=== signal.NotifyContext ===")
+
+	err := RunUntilSignal(context.Background(), func(ctx context.Context) error {
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			syscall.Kill(os.Getpid(), syscall.SIGTERM)
+		}()
+
+		ticker := time.NewTicker(30 * time.Millisecond)
+		defer ticker.Stop()
+		for ticks := 0; ; ticks++ {
+			select {
+			case <-ctx.Done():
+				fmt.Fprintf(w, "shutting down after %d ticks: %v\n", ticks, context.Cause(ctx))
+				return nil
+			case <-ticker.C:
+			}
+		}
+	})
+	fmt.Fprintln(w, "error:", err)
+	fmt.Fprintln(w)
+}
+
+// ProcessInterviewQuestions lists common interview questions on signals and processes
+func ProcessInterviewQuestions(w io.Writer) {
+	if err := quiz.Print(w, "signals-exec"); err != nil {
+		fmt.Fprintln(w, err)
+	}
+}
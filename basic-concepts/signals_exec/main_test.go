@@ -0,0 +1,194 @@
+package signalsexec
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// helperCommand runs the test binary itself as the child process. Only
+// TestHelperProcess matches -test.run, and it hands over to childMain
+// because childEnv is set.
+func helperCommand(ctx context.Context, mode string, args ...string) *exec.Cmd {
+	cs := append([]string{"-test.run=^TestHelperProcess$", "--"}, args...)
+	cmd := exec.CommandContext(ctx, os.Args[0], cs...)
+	cmd.Env = append(os.Environ(), childEnv+"="+mode)
+	return cmd
+}
+
+// TestHelperProcess is not a real test; it is the child for helperCommand
+func TestHelperProcess(t *testing.T) {
+	mode := os.Getenv(childEnv)
+	if mode == "" {
+		return
+	}
+	args := os.Args
+	for len(args) > 0 && args[0] != "--" {
+		args = args[1:]
+	}
+	if len(args) > 0 {
+		args = args[1:]
+	}
+	os.Exit(childMain(mode, args))
+}
+
+func TestOutput(t *testing.T) {
+	stdout, stderr, err := Output(helperCommand(context.Background(), "echo", "a", "b"))
+	if err != nil {
+		t.Fatalf("Output returned error: %v", err)
+	}
+	if string(stdout) != "a b\n" {
+		t.Errorf("stdout = %q; want %q", stdout, "a b\n")
+	}
+	if !strings.Contains(string(stderr), "stderr") {
+		t.Errorf("stderr = %q; want the child's warning", stderr)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	_, stderr, err := Output(helperCommand(context.Background(), "fail"))
+	if got := ExitCode(err); got != 3 {
+		t.Errorf("ExitCode(%v) = %d; want 3", err, got)
+	}
+	if !strings.Contains(string(stderr), "something went wrong") {
+		t.Errorf("stderr = %q", stderr)
+	}
+
+	_, _, err = Output(exec.Command("definitely-not-a-real-command"))
+	if !errors.Is(err, exec.ErrNotFound) || ExitCode(err) != -1 {
+		t.Errorf("missing binary: err = %v, ExitCode = %d; want ErrNotFound, -1", err, ExitCode(err))
+	}
+	if ExitCode(nil) != 0 {
+		t.Error("ExitCode(nil) != 0")
+	}
+}
+
+func TestStreamLines(t *testing.T) {
+	var got []string
+	err := StreamLines(helperCommand(context.Background(), "lines"), func(line string) {
+		got = append(got, line)
+	})
+	if err != nil {
+		t.Fatalf("StreamLines returned error: %v", err)
+	}
+	want := []string{"line 1", "line 2", "line 3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("lines = %q; want %q", got, want)
+	}
+}
+
+func TestCommandContext_Timeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := helperCommand(ctx, "sleep").Run()
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("child ran for %v; the deadline did not stop it", elapsed)
+	}
+	if err == nil {
+		t.Fatal("Run returned nil for a killed child")
+	}
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		t.Errorf("ctx.Err() = %v; want DeadlineExceeded", ctx.Err())
+	}
+	if ExitCode(err) != -1 {
+		t.Errorf("ExitCode = %d; want -1 for a killed child", ExitCode(err))
+	}
+}
+
+// startReady starts cmd with GracefulStop and waits until the child is ready
+// to handle signals
+func startReady(t *testing.T, cmd *exec.Cmd) *bufio.Reader {
+	t.Helper()
+	pipe, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	out := bufio.NewReader(pipe)
+	if err := waitReady(out); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestGracefulStop_ChildHandlesSIGTERM(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd := helperCommand(ctx, "graceful")
+	GracefulStop(cmd, 5*time.Second)
+	out := startReady(t, cmd)
+
+	cancel()
+	rest, _ := out.ReadString(0)
+	err := cmd.Wait()
+
+	if !strings.Contains(rest, "cleaned up") {
+		t.Errorf("child output after SIGTERM = %q; want its cleanup message", rest)
+	}
+	// The child exited 0, so Wait reports why it was asked to stop
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait = %v; want context.Canceled", err)
+	}
+}
+
+func TestGracefulStop_KillsAfterGrace(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd := helperCommand(ctx, "stubborn")
+	GracefulStop(cmd, 100*time.Millisecond)
+	out := startReady(t, cmd)
+
+	start := time.Now()
+	cancel()
+	out.ReadString(0)
+	err := cmd.Wait()
+
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("child ignoring SIGTERM survived for %v", elapsed)
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("Wait = %v; want an *exec.ExitError", err)
+	}
+	status, ok := exitErr.Sys().(syscall.WaitStatus)
+	if !ok || status.Signal() != syscall.SIGKILL {
+		t.Errorf("child ended with %v; want it killed by SIGKILL", exitErr)
+	}
+}
+
+func TestRunUntilSignal(t *testing.T) {
+	err := RunUntilSignal(context.Background(), func(ctx context.Context) error {
+		syscall.Kill(os.Getpid(), syscall.SIGUSR1)
+		select {
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		case <-time.After(5 * time.Second):
+			return errors.New("signal did not cancel the context")
+		}
+	}, syscall.SIGUSR1)
+
+	if err == nil || !strings.Contains(err.Error(), "user defined signal 1") {
+		t.Errorf("RunUntilSignal = %v; want the cancellation cause to name SIGUSR1", err)
+	}
+}
+
+func TestRunUntilSignal_ParentCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := RunUntilSignal(ctx, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("RunUntilSignal = %v; want context.Canceled", err)
+	}
+}
@@ -0,0 +1,176 @@
+package structsinterfaces
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// INTERFACE REPRESENTATION
+//
+// An interface value is a pair (dynamic type, dynamic value). It is nil
+// only when BOTH parts are nil. Storing a nil *T in an interface sets the
+// type part, so the interface is no longer nil.
+
+// InterfacePair describes the two words of an interface value
+type InterfacePair struct {
+	Type    string // "<nil>" when the interface has no dynamic type
+	IsNil   bool   // the interface itself == nil
+	ValNil  bool   // the dynamic value is a nil pointer, map, slice, etc.
+	Display string
+}
+
+// Inspect reports the (type, value) pair stored in i
+func Inspect(i any) InterfacePair {
+	return InterfacePair{
+		Type:    fmt.Sprintf("%T", i),
+		IsNil:   i == nil,
+		ValNil:  IsNilValue(i),
+		Display: fmt.Sprintf("%v", i),
+	}
+}
+
+// IsNilValue reports whether i is nil or holds a nil pointer, map, slice,
+// channel, function or interface. Comparing i == nil misses the second case.
+func IsNilValue(i any) bool {
+	if i == nil {
+		return true
+	}
+	v := reflect.ValueOf(i)
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Chan, reflect.Func, reflect.Interface:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
+
+// THE TYPED-NIL ERROR BUG
+
+// NotFoundError is a custom error returned by pointer
+type NotFoundError struct {
+	Key string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("%s not found", e.Key)
+}
+
+var users = map[string]string{"alice": "Alice Smith"}
+
+// lookupUserBuggy declares its error as *NotFoundError and returns it as
+// error. When the user exists, err is a nil *NotFoundError, but the
+// returned error interface holds (type=*NotFoundError, value=nil), so the
+// caller's err != nil check is TRUE even on success.
+func lookupUserBuggy(key string) (string, error) {
+	var err *NotFoundError
+	name, ok := users[key]
+	if !ok {
+		err = &NotFoundError{Key: key}
+	}
+	return name, err // BUG: never a nil interface
+}
+
+// lookupUserFixed returns a literal nil on success, so the interface is nil
+func lookupUserFixed(key string) (string, error) {
+	name, ok := users[key]
+	if !ok {
+		return "", &NotFoundError{Key: key}
+	}
+	return name, nil
+}
+
+// INTERFACE SATISFACTION CHECKS
+
+// Resetter is implemented by types that can be reset
+type Resetter interface {
+	Reset()
+}
+
+// Compile-time checks: the build fails if a type stops satisfying an interface
+var (
+	_ error        = (*NotFoundError)(nil)
+	_ fmt.Stringer = Book{}
+	_ WriteCloser  = (*StringWriter)(nil)
+	_ Resetter     = (*Counter)(nil)
+)
+
+// Implements reports at runtime whether v satisfies fmt.Stringer and error,
+// using type assertions on the empty interface
+func Implements(v any) (stringer, isError bool) {
+	_, stringer = v.(fmt.Stringer)
+	_, isError = v.(error)
+	return stringer, isError
+}
+
+// METHOD SETS
+
+// Counter has a value-receiver method and a pointer-receiver method
+type Counter struct {
+	n int
+}
+
+// Value has a value receiver: it is in the method set of Counter and *Counter
+func (c Counter) Value() int { return c.n }
+
+// Increment has a pointer receiver: it is only in the method set of *Counter
+func (c *Counter) Increment() { c.n++ }
+
+// Reset has a pointer receiver, so only *Counter implements Resetter
+func (c *Counter) Reset() { c.n = 0 }
+
+// Valuer is satisfied by both Counter and *Counter
+type Valuer interface {
+	Value() int
+}
+
+// MethodSetSatisfies reports, via reflection, whether the value type and the
+// pointer type of Counter implement Resetter. Assigning Counter{} to a
+// Resetter variable would not compile.
+func MethodSetSatisfies() (valueImplements, pointerImplements bool) {
+	resetter := reflect.TypeOf((*Resetter)(nil)).Elem()
+	return reflect.TypeOf(Counter{}).Implements(resetter),
+		reflect.TypeOf(&Counter{}).Implements(resetter)
+}
+
+// IncrementThroughInterface shows that an interface stores a copy: calling
+// a pointer method on the original does not change what a Valuer holding a
+// Counter value reports
+func IncrementThroughInterface() (viaValue, viaPointer int) {
+	c := Counter{}
+	var byValue Valuer = c    // copies c
+	var byPointer Valuer = &c // points at c
+	c.Increment()             // c is addressable, so Go calls (&c).Increment()
+	return byValue.Value(), byPointer.Value()
+}
+
+// InterfaceInternalsExample demonstrates interface representation and method sets
+func InterfaceInternalsExample(w io.Writer) {
+	fmt.Fprintln(w, "\n=== INTERFACE INTERNALS ===")
+
+	var nilShape Shape
+	var nilCircle *Circle
+	for _, v := range []any{nilShape, Shape(nilCircle), Circle{Radius: 1}} {
+		fmt.Fprintf(w, "%+v\n", Inspect(v))
+	}
+
+	fmt.Fprintln(w, "\nTyped-nil error bug:")
+	if _, err := lookupUserBuggy("alice"); err != nil {
+		fmt.Fprintf(w, "buggy: err != nil for an existing user (type %T)\n", err)
+	}
+	if _, err := lookupUserFixed("alice"); err == nil {
+		fmt.Fprintln(w, "fixed: err == nil for an existing user")
+	}
+	_, err := lookupUserFixed("bob")
+	var nf *NotFoundError
+	fmt.Fprintln(w, "fixed: missing user is a *NotFoundError:", errors.As(err, &nf))
+
+	fmt.Fprintln(w, "\nMethod sets:")
+	valueOK, pointerOK := MethodSetSatisfies()
+	fmt.Fprintf(w, "Counter implements Resetter: %t, *Counter: %t\n", valueOK, pointerOK)
+	viaValue, viaPointer := IncrementThroughInterface()
+	fmt.Fprintf(w, "After increment, interface holding a copy: %d, holding a pointer: %d\n", viaValue, viaPointer)
+	stringer, isError := Implements(Book{})
+	fmt.Fprintf(w, "Book is a Stringer: %t, an error: %t\n", stringer, isError)
+}
@@ -0,0 +1,94 @@
+package structsinterfaces
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestInspect(t *testing.T) {
+	var nilShape Shape
+	var nilCircle *Circle
+
+	tests := []struct {
+		name       string
+		value      any
+		wantType   string
+		wantIsNil  bool
+		wantValNil bool
+	}{
+		{"nil interface", nilShape, "<nil>", true, true},
+		{"interface holding nil pointer", Shape(nilCircle), "*structsinterfaces.Circle", false, true},
+		{"interface holding value", Circle{Radius: 1}, "structsinterfaces.Circle", false, false},
+		{"nil map", map[string]int(nil), "map[string]int", false, true},
+		{"zero int", 0, "int", false, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Inspect(tc.value)
+			if got.Type != tc.wantType || got.IsNil != tc.wantIsNil || got.ValNil != tc.wantValNil {
+				t.Errorf("Inspect() = %+v; want type %s, IsNil %t, ValNil %t",
+					got, tc.wantType, tc.wantIsNil, tc.wantValNil)
+			}
+		})
+	}
+}
+
+// TestLookupUserBuggy documents the bug: a successful lookup still
+// returns a non-nil error because the interface holds a typed nil
+func TestLookupUserBuggy(t *testing.T) {
+	_, err := lookupUserBuggy("alice")
+	if err == nil {
+		t.Fatal("expected the buggy version to return a non-nil error interface")
+	}
+	var nf *NotFoundError
+	if !errors.As(err, &nf) || nf != nil {
+		t.Errorf("err holds %#v; want a nil *NotFoundError", nf)
+	}
+}
+
+func TestLookupUserFixed(t *testing.T) {
+	name, err := lookupUserFixed("alice")
+	if err != nil || name != "Alice Smith" {
+		t.Errorf("lookupUserFixed(alice) = %q, %v; want Alice Smith, nil", name, err)
+	}
+
+	_, err = lookupUserFixed("bob")
+	var nf *NotFoundError
+	if !errors.As(err, &nf) || nf.Key != "bob" {
+		t.Errorf("lookupUserFixed(bob) err = %v; want *NotFoundError for bob", err)
+	}
+}
+
+func TestImplements(t *testing.T) {
+	tests := []struct {
+		name         string
+		value        any
+		wantStringer bool
+		wantError    bool
+	}{
+		{"Book", Book{}, true, false},
+		{"*NotFoundError", &NotFoundError{}, false, true},
+		{"NotFoundError value", NotFoundError{}, false, false},
+		{"int", 1, false, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			stringer, isError := Implements(tc.value)
+			if stringer != tc.wantStringer || isError != tc.wantError {
+				t.Errorf("Implements() = %t, %t; want %t, %t", stringer, isError, tc.wantStringer, tc.wantError)
+			}
+		})
+	}
+}
+
+func TestMethodSets(t *testing.T) {
+	valueOK, pointerOK := MethodSetSatisfies()
+	if valueOK || !pointerOK {
+		t.Errorf("MethodSetSatisfies() = %t, %t; want false, true", valueOK, pointerOK)
+	}
+
+	viaValue, viaPointer := IncrementThroughInterface()
+	if viaValue != 0 || viaPointer != 1 {
+		t.Errorf("IncrementThroughInterface() = %d, %d; want 0, 1", viaValue, viaPointer)
+	}
+}
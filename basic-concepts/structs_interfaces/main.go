@@ -1,7 +1,8 @@
-package main
+package structsinterfaces
 
 import (
 	"fmt"
+	"io"
 	"math"
 	"strings"
 )
@@ -89,8 +90,8 @@ func (r Rectangle) IsSquare() bool {
 }
 
 // Empty interface
-func PrintAny(any interface{}) {
-	fmt.Printf("Value: %v, Type: %T\n", any, any)
+func PrintAny(w io.Writer, any interface{}) {
+	fmt.Fprintf(w, "Value: %v, Type: %T\n", any, any)
 }
 
 // Interface embedding
@@ -135,8 +136,9 @@ func (b Book) String() string {
 	return fmt.Sprintf("%s by %s (%d pages)", b.Title, b.Author, b.Pages)
 }
 
-func main() {
-	fmt.Println("=== STRUCTS ===")
+// Run prints the struct and interface examples to w
+func Run(w io.Writer) error {
+	fmt.Fprintln(w, "=== STRUCTS ===")
 
 	// Creating a struct
 	addr := Address{
@@ -173,26 +175,26 @@ func main() {
 	p4 := Person{FirstName: "Alice", LastName: "Brown"}
 
 	// Printing structs
-	fmt.Println("Person 1:", p1)
-	fmt.Println("Person 2:", p2)
-	fmt.Println("Person 3:", p3)
-	fmt.Println("Person 4:", p4)
+	fmt.Fprintln(w, "Person 1:", p1)
+	fmt.Fprintln(w, "Person 2:", p2)
+	fmt.Fprintln(w, "Person 3:", p3)
+	fmt.Fprintln(w, "Person 4:", p4)
 
 	// Accessing struct fields
-	fmt.Println("\nAccessing struct fields:")
-	fmt.Println("p2 first name:", p2.FirstName)
-	fmt.Println("p2 city:", p2.Address.City)
+	fmt.Fprintln(w, "\nAccessing struct fields:")
+	fmt.Fprintln(w, "p2 first name:", p2.FirstName)
+	fmt.Fprintln(w, "p2 city:", p2.Address.City)
 
 	// Calling a method on struct
-	fmt.Println("\nCalling methods:")
-	fmt.Println("Full name:", p2.FullName())
+	fmt.Fprintln(w, "\nCalling methods:")
+	fmt.Fprintln(w, "Full name:", p2.FullName())
 
 	// Calling a method with pointer receiver
 	p2.UpdateName("Janet", "Smith-Jones")
-	fmt.Println("Updated full name:", p2.FullName())
+	fmt.Fprintln(w, "Updated full name:", p2.FullName())
 
 	// Struct with embedded struct
-	fmt.Println("\nStruct composition:")
+	fmt.Fprintln(w, "\nStruct composition:")
 	emp := Employee{
 		Person: Person{
 			FirstName: "Mike",
@@ -210,21 +212,23 @@ func main() {
 	}
 
 	// Accessing fields from embedded struct
-	fmt.Println("Employee name:", emp.FirstName, emp.LastName) // Direct access to Person fields
-	fmt.Println("Employee full name:", emp.FullName())         // Direct access to Person methods
-	fmt.Println("Employee company:", emp.Company)
+	fmt.Fprintln(w, "Employee name:", emp.FirstName, emp.LastName) // Direct access to Person fields
+	fmt.Fprintln(w, "Employee full name:", emp.FullName())         // Direct access to Person methods
+	fmt.Fprintln(w, "Employee company:", emp.Company)
 
 	// Anonymous structs
-	fmt.Println("\nAnonymous structs:")
+	fmt.Fprintln(w, "\nAnonymous structs:")
 	point := struct {
 		X, Y int
 	}{
 		X: 10,
 		Y: 20,
 	}
-	fmt.Println("Point:", point)
+	fmt.Fprintln(w, "Point:", point)
 
-	fmt.Println("\n=== INTERFACES ===")
+	StructTagsExample(w)
+
+	fmt.Fprintln(w, "\n=== INTERFACES ===")
 
 	// Creating shape instances
 	circle := Circle{Radius: 5}
@@ -235,90 +239,93 @@ func main() {
 
 	// Iterating through interface slice
 	for _, shape := range shapes {
-		fmt.Printf("Shape: %#v\n", shape)
-		fmt.Printf("Area: %.2f\n", shape.Area())
-		fmt.Printf("Perimeter: %.2f\n", shape.Perimeter())
+		fmt.Fprintf(w, "Shape: %#v\n", shape)
+		fmt.Fprintf(w, "Area: %.2f\n", shape.Area())
+		fmt.Fprintf(w, "Perimeter: %.2f\n", shape.Perimeter())
 
 		// Type assertion to check specific type
 		if rect, ok := shape.(Rectangle); ok {
-			fmt.Printf("Is square: %t\n", rect.IsSquare())
+			fmt.Fprintf(w, "Is square: %t\n", rect.IsSquare())
 		}
 
-		fmt.Println()
+		fmt.Fprintln(w)
 	}
 
 	// Type switches
-	fmt.Println("Type switches:")
+	fmt.Fprintln(w, "Type switches:")
 	for _, shape := range shapes {
 		switch s := shape.(type) {
 		case Circle:
-			fmt.Printf("Circle with radius %.2f\n", s.Radius)
+			fmt.Fprintf(w, "Circle with radius %.2f\n", s.Radius)
 		case Rectangle:
-			fmt.Printf("Rectangle with width %.2f and height %.2f\n", s.Width, s.Height)
+			fmt.Fprintf(w, "Rectangle with width %.2f and height %.2f\n", s.Width, s.Height)
 		default:
-			fmt.Println("Unknown shape")
+			fmt.Fprintln(w, "Unknown shape")
 		}
 	}
 
 	// Empty interface
-	fmt.Println("\nEmpty interface examples:")
-	PrintAny(42)
-	PrintAny("Hello")
-	PrintAny(true)
-	PrintAny(circle)
+	fmt.Fprintln(w, "\nEmpty interface examples:")
+	PrintAny(w, 42)
+	PrintAny(w, "Hello")
+	PrintAny(w, true)
+	PrintAny(w, circle)
 
 	// Interface implementation check (compile-time)
 	var _ Shape = Circle{}    // This will compile only if Circle implements Shape
 	var _ Shape = Rectangle{} // This will compile only if Rectangle implements Shape
 
 	// Interface composition
-	fmt.Println("\nInterface composition:")
+	fmt.Fprintln(w, "\nInterface composition:")
 	sw := &StringWriter{}
 
 	// Write data
 	sw.Write([]byte("Hello, "))
 	sw.Write([]byte("Go!"))
-	fmt.Println("StringWriter data:", sw.data)
+	fmt.Fprintln(w, "StringWriter data:", sw.data)
 
 	// Use the interface
 	var wc WriteCloser = sw
 	wc.Write([]byte(" Welcome."))
-	fmt.Println("After writing through interface:", sw.data)
+	fmt.Fprintln(w, "After writing through interface:", sw.data)
 
 	wc.Close()
-	fmt.Println("After closing:", sw.data)
+	fmt.Fprintln(w, "After closing:", sw.data)
 
 	// Stringer interface
-	fmt.Println("\nStringer interface:")
+	fmt.Fprintln(w, "\nStringer interface:")
 	book := Book{
 		Title:  "The Go Programming Language",
 		Author: "Alan A. A. Donovan and Brian W. Kernighan",
 		Pages:  380,
 	}
-	fmt.Println(book) // fmt.Println uses the String() method
+	fmt.Fprintln(w, book) // fmt.Println uses the String() method
 
 	// Error handling example
-	fmt.Println("\nError handling example:")
+	fmt.Fprintln(w, "\nError handling example:")
 	if err := validateNameInput(""); err != nil {
-		fmt.Println("Error:", err)
+		fmt.Fprintln(w, "Error:", err)
 	}
 	if err := validateNameInput("John"); err != nil {
-		fmt.Println("Error:", err)
+		fmt.Fprintln(w, "Error:", err)
 	} else {
-		fmt.Println("Name validation successful")
+		fmt.Fprintln(w, "Name validation successful")
 	}
 
 	// nil interface values
-	fmt.Println("\nNil interface values:")
+	fmt.Fprintln(w, "\nNil interface values:")
 	var s1 Shape
 	// s1.Area() would panic: runtime error: invalid memory address or nil pointer dereference
-	fmt.Println("s1 == nil:", s1 == nil)
+	fmt.Fprintln(w, "s1 == nil:", s1 == nil)
 
 	// Interface with nil value
 	var c *Circle
 	var s2 Shape = c // Non-nil interface containing nil pointer
-	fmt.Println("c == nil:", c == nil)
-	fmt.Println("s2 == nil:", s2 == nil) // false, because interface is not nil
+	fmt.Fprintln(w, "c == nil:", c == nil)
+	fmt.Fprintln(w, "s2 == nil:", s2 == nil) // false, because interface is not nil
+
+	InterfaceInternalsExample(w)
+	return nil
 }
 
 // Implementing error interface
@@ -393,4 +400,17 @@ Common interview questions about structs and interfaces in Go:
     - A nil interface has no type and no value (var i Interface = nil)
     - An interface with a nil value has a type but its value is nil (var p *Person = nil; var i Interface = p)
     - Calling methods on a nil interface will panic, but calling methods on an interface with a nil value is valid
+
+11. Why can returning a nil *MyError as error make err != nil true?
+    - The returned interface holds (type=*MyError, value=nil), which is not a nil interface
+    - Return a literal nil on success, never a typed nil pointer variable
+
+12. What are the method set rules for value and pointer receivers?
+    - The method set of T contains only value-receiver methods
+    - The method set of *T contains both value- and pointer-receiver methods
+    - So if any interface method has a pointer receiver, only *T satisfies the interface
+    - Calling p.Method() on an addressable T works because Go takes &p automatically
+
+13. How do you check at compile time that a type implements an interface?
+    - var _ Interface = (*Type)(nil)
 */
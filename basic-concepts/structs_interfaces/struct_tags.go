@@ -0,0 +1,83 @@
+package structsinterfaces
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"github.com/rehan/go-interview-prep/pkg/yamlx"
+)
+
+// STRUCT TAGS
+//
+// A tag is a string attached to a field that the compiler ignores and
+// packages read through reflection. Each encoder looks up its own key, so
+// one struct can have different names in every format: Product's ID is
+// "id" in JSON and YAML (pkg/yamlx goes through encoding/json) and
+// <product_id> in XML.
+
+// Catalog is the XML document holding a list of products. XML needs a
+// single root element; XMLName names it, and the tag on Products names
+// each child, since a slice has no element name of its own.
+type Catalog struct {
+	XMLName  xml.Name  `xml:"catalog"`
+	Products []Product `xml:"product"`
+}
+
+// EncodeProductsXML writes products as an indented XML catalog with the
+// <?xml ...?> declaration
+func EncodeProductsXML(products []Product) (string, error) {
+	data, err := xml.MarshalIndent(Catalog{Products: products}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return xml.Header + string(data), nil
+}
+
+// DecodeProductsXML reads the products of an XML catalog. Elements and
+// attributes Product has no field for are skipped; encoding/xml has no
+// strict mode like json.Decoder's DisallowUnknownFields.
+func DecodeProductsXML(data string) ([]Product, error) {
+	var c Catalog
+	if err := xml.Unmarshal([]byte(data), &c); err != nil {
+		return nil, err
+	}
+	return c.Products, nil
+}
+
+// StructTagsExample encodes the same products as JSON, XML and YAML and
+// decodes each back
+func StructTagsExample(w io.Writer) {
+	fmt.Fprintln(w, "\nStruct tags:")
+	products := []Product{
+		{ID: 1, Name: "Gopher plush", Price: 12.5, Description: "Soft & blue"},
+		{ID: 2, Name: "Sticker", Price: 1}, // no description: omitempty leaves it out
+	}
+
+	data, err := json.MarshalIndent(products, "", "  ")
+	if err != nil {
+		fmt.Fprintln(w, "Error:", err)
+		return
+	}
+	fmt.Fprintf(w, "JSON (json tags; encoding/json escapes & for HTML):\n%s\n", data)
+
+	xmlText, err := EncodeProductsXML(products)
+	if err != nil {
+		fmt.Fprintln(w, "Error:", err)
+		return
+	}
+	fmt.Fprintf(w, "XML (xml tags; & is escaped):\n%s\n", xmlText)
+	decoded, err := DecodeProductsXML(xmlText)
+	fmt.Fprintf(w, "Decoded from XML: %+v (error: %v)\n", decoded, err)
+
+	yamlText, err := yamlx.Marshal(products)
+	if err != nil {
+		fmt.Fprintln(w, "Error:", err)
+		return
+	}
+	fmt.Fprintf(w, "YAML (json tags, through pkg/yamlx):\n%s", yamlText)
+	decoded = nil
+	err = yamlx.Unmarshal(yamlText, &decoded)
+	fmt.Fprintf(w, "Decoded from YAML: %+v (error: %v)\n", decoded, err)
+}
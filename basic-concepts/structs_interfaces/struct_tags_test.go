@@ -0,0 +1,69 @@
+package structsinterfaces
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rehan/go-interview-prep/pkg/yamlx"
+)
+
+func TestProductsXML_RoundTrip(t *testing.T) {
+	in := []Product{
+		{ID: 1, Name: "Gopher plush", Price: 12.5, Description: "Soft & <blue>"},
+		{ID: 2, Name: "Sticker", Price: 1},
+	}
+	encoded, err := EncodeProductsXML(in)
+	if err != nil {
+		t.Fatalf("EncodeProductsXML: %v", err)
+	}
+	for _, want := range []string{`<?xml version="1.0"`, "<catalog>", "<product_id>1</product_id>", "<product_name>Sticker</product_name>", "Soft &amp; &lt;blue&gt;"} {
+		if !strings.Contains(encoded, want) {
+			t.Errorf("encoded XML lacks %q:\n%s", want, encoded)
+		}
+	}
+	if strings.Count(encoded, "<description>") != 1 {
+		t.Errorf("want omitempty to leave out the empty description:\n%s", encoded)
+	}
+
+	out, err := DecodeProductsXML(encoded)
+	if err != nil {
+		t.Fatalf("DecodeProductsXML: %v", err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("round trip = %+v; want %+v", out, in)
+	}
+}
+
+func TestDecodeProductsXML(t *testing.T) {
+	got, err := DecodeProductsXML(`<catalog><product sku="x"><product_id>7</product_id><colour>red</colour></product></catalog>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []Product{{ID: 7}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DecodeProductsXML = %+v; want %+v with unknown elements skipped", got, want)
+	}
+
+	if _, err := DecodeProductsXML(`<catalog><product><product_id>one</product_id></product></catalog>`); err == nil {
+		t.Error("a non-numeric product_id: want an error")
+	}
+	if _, err := DecodeProductsXML(`<catalog><product>`); err == nil {
+		t.Error("unclosed elements: want an error")
+	}
+}
+
+func TestProductYAML_UsesJSONTags(t *testing.T) {
+	in := []Product{{ID: 3, Name: "Mug: large", Price: 8.25, Description: "#1 mug"}}
+	data, err := yamlx.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "- id: 3\n  name: \"Mug: large\"\n  price: 8.25\n  desc: \"#1 mug\"\n"
+	if string(data) != want {
+		t.Errorf("yamlx.Marshal =\n%s; want\n%s", data, want)
+	}
+	var out []Product
+	if err := yamlx.Unmarshal(data, &out); err != nil || !reflect.DeepEqual(out, in) {
+		t.Errorf("round trip = %+v, %v; want %+v", out, err, in)
+	}
+}
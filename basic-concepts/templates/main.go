@@ -0,0 +1,207 @@
+package templates
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/rehan/go-interview-prep/pkg/quiz"
+)
+
+// Run renders the text/template and html/template examples to w
+func Run(w io.Writer) error {
+	fmt.Fprintln(w, "=========================================")
+	fmt.Fprintln(w, "GO TEMPLATES (text/template AND html/template)")
+	fmt.Fprintln(w, "=========================================")
+
+	FuncsExample(w)
+	NestedTemplatesExample(w)
+	EscapingExample(w)
+
+	// Interview questions
+	TemplateInterviewQuestions(w)
+	return nil
+}
+
+// Book is the data the example templates render
+type Book struct {
+	Title  string
+	Author string
+	Price  float64
+	Tags   []string
+}
+
+// Report is the top-level value passed to the report template
+type Report struct {
+	Title string
+	Books []Book
+}
+
+// funcs must be registered with Funcs before Parse, because the parser
+// rejects calls to functions it does not know
+var funcs = texttemplate.FuncMap{
+	"upper": strings.ToUpper,
+	"join":  strings.Join,
+	"price": func(p float64) string { return fmt.Sprintf("$%.2f", p) },
+	"inc":   func(i int) int { return i + 1 },
+}
+
+// reportSource uses a nested "book" template, range with an index, and
+// {{- -}} to trim the whitespace around actions
+const reportSource = `{{define "book" -}}
+{{.Title}} by {{.Author}} ({{price .Price}})
+{{- with .Tags}} [{{join . ", "}}]{{end}}
+{{- end -}}
+
+{{upper .Title}}
+{{range $i, $b := .Books -}}
+{{inc $i}}. {{template "book" $b}}
+{{else -}}
+no books
+{{end -}}
+{{len .Books}} book(s)
+`
+
+// reportTemplate is parsed once; a parse error is a programming error, so
+// template.Must panics at start-up instead of on the first request
+var reportTemplate = texttemplate.Must(texttemplate.New("report").Funcs(funcs).Parse(reportSource))
+
+// RenderReport writes r as plain text
+func RenderReport(w io.Writer, r Report) error {
+	return reportTemplate.Execute(w, r)
+}
+
+// layoutSource defines the page skeleton. {{block}} is {{define}} plus
+// {{template}}: it supplies a default that a page may redefine.
+const layoutSource = `<!DOCTYPE html>
+<html>
+<head><title>{{block "title" .}}Bookshop{{end}}</title></head>
+<body>
+{{block "content" .}}<p>Nothing here yet.</p>{{end}}
+</body>
+</html>
+`
+
+var layout = htmltemplate.Must(htmltemplate.New("layout").Parse(layoutSource))
+
+// NewPage returns the layout with its blocks overridden by pageSource.
+// Cloning keeps each page's definitions separate, because redefining a
+// block in the shared layout would change every page.
+func NewPage(pageSource string) (*htmltemplate.Template, error) {
+	page, err := layout.Clone()
+	if err != nil {
+		return nil, err
+	}
+	return page.Parse(pageSource)
+}
+
+// escapingSource puts the same value into HTML text, an attribute, a URL
+// and a script, which html/template escapes differently
+const escapingSource = `<p>{{.Text}}</p>
+<a href="{{.URL}}" title="{{.Text}}">link</a>
+<script>var name = {{.Text}};</script>
+`
+
+// EscapingData is rendered by escapingSource
+type EscapingData struct {
+	Text string
+	URL  string
+}
+
+// RenderBoth renders escapingSource with text/template, which copies
+// values through untouched, and html/template, which escapes each value
+// for the context it appears in
+func RenderBoth(data EscapingData) (text, html string, err error) {
+	var tb, hb strings.Builder
+	tt := texttemplate.Must(texttemplate.New("t").Parse(escapingSource))
+	if err := tt.Execute(&tb, data); err != nil {
+		return "", "", err
+	}
+	ht := htmltemplate.Must(htmltemplate.New("h").Parse(escapingSource))
+	if err := ht.Execute(&hb, data); err != nil {
+		return "", "", err
+	}
+	return tb.String(), hb.String(), nil
+}
+
+// FuncsExample renders the text report with custom functions
+func FuncsExample(w io.Writer) {
+	fmt.Fprintln(w, "=== FUNCTIONS, RANGE AND NESTED DEFINE ===")
+
+	report := Report{
+		Title: "Reading list",
+		Books: []Book{
+			{Title: "The Go Programming Language", Author: "Donovan & Kernighan", Price: 32.99, Tags: []string{"go", "classic"}},
+			{Title: "Concurrency in Go", Author: "Katherine Cox-Buday", Price: 34.99},
+		},
+	}
+	if err := RenderReport(w, report); err != nil {
+		fmt.Fprintln(w, "error:", err)
+	}
+	fmt.Fprintln(w)
+	if err := RenderReport(w, Report{Title: "empty shelf"}); err != nil {
+		fmt.Fprintln(w, "error:", err)
+	}
+
+	// Unknown fields are only found by Execute; unknown functions by Parse
+	_, err := texttemplate.New("bad").Parse("{{.Missing}}")
+	fmt.Fprintln(w, "parse of {{.Missing}}:", err)
+	err = texttemplate.Must(texttemplate.New("bad").Parse("{{.Missing}}")).Execute(io.Discard, report)
+	fmt.Fprintln(w, "execute of {{.Missing}}:", err)
+	_, err = texttemplate.New("bad").Parse("{{shout .}}")
+	fmt.Fprintln(w, "parse of an unknown function:", err)
+	fmt.Fprintln(w)
+}
+
+// NestedTemplatesExample shows a layout with blocks overridden per page
+func NestedTemplatesExample(w io.Writer) {
+	fmt.Fprintln(w, "=== LAYOUTS WITH block AND Clone ===")
+
+	home, err := NewPage(`{{define "content"}}<h1>Welcome</h1>{{end}}`)
+	if err != nil {
+		fmt.Fprintln(w, "error:", err)
+		return
+	}
+	about, err := NewPage(`{{define "title"}}About{{end}}`)
+	if err != nil {
+		fmt.Fprintln(w, "error:", err)
+		return
+	}
+	for _, page := range []*htmltemplate.Template{home, about} {
+		if err := page.Execute(w, nil); err != nil {
+			fmt.Fprintln(w, "error:", err)
+		}
+	}
+	fmt.Fprintln(w)
+}
+
+// EscapingExample compares text/template and html/template output
+func EscapingExample(w io.Writer) {
+	fmt.Fprintln(w, "=== CONTEXTUAL ESCAPING ===")
+
+	text, html, err := RenderBoth(EscapingData{
+		Text: `<b>"Bob" & 'Alice'</b>`,
+		URL:  "javascript:alert(1)",
+	})
+	if err != nil {
+		fmt.Fprintln(w, "error:", err)
+		return
+	}
+	fmt.Fprint(w, "text/template (unsafe in a browser):\n", text)
+	fmt.Fprint(w, "html/template:\n", html)
+
+	// template.HTML marks a value as trusted, so it is inserted unescaped.
+	// Only use it for markup the program itself produced.
+	t := htmltemplate.Must(htmltemplate.New("trusted").Parse("{{.}}\n"))
+	t.Execute(w, htmltemplate.HTML("<em>trusted markup</em>"))
+	fmt.Fprintln(w)
+}
+
+// TemplateInterviewQuestions lists common interview questions on templates
+func TemplateInterviewQuestions(w io.Writer) {
+	if err := quiz.Print(w, "templates"); err != nil {
+		fmt.Fprintln(w, err)
+	}
+}
@@ -0,0 +1,87 @@
+package templates
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/rehan/go-interview-prep/pkg/testutil/golden"
+)
+
+func TestRenderReport(t *testing.T) {
+	tests := []struct {
+		name   string
+		report Report
+	}{
+		{"report", Report{
+			Title: "Reading list",
+			Books: []Book{
+				{Title: "The Go Programming Language", Author: "Donovan & Kernighan", Price: 32.99, Tags: []string{"go", "classic"}},
+				{Title: "Concurrency in Go", Author: "Katherine Cox-Buday", Price: 34.99},
+			},
+		}},
+		{"report_empty", Report{Title: "Empty shelf"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := RenderReport(&buf, tc.report); err != nil {
+				t.Fatalf("RenderReport: %v", err)
+			}
+			golden.Assert(t, tc.name, buf.Bytes())
+		})
+	}
+}
+
+func TestNewPage(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{"page_default", ``},
+		{"page_overrides", `{{define "title"}}About{{end}}{{define "content"}}<h1>{{.}}</h1>{{end}}`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			page, err := NewPage(tc.source)
+			if err != nil {
+				t.Fatalf("NewPage: %v", err)
+			}
+			var buf bytes.Buffer
+			if err := page.Execute(&buf, "Tom & Jerry"); err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+			golden.Assert(t, tc.name, buf.Bytes())
+		})
+	}
+}
+
+// Overriding a block in one page must not leak into the shared layout
+func TestNewPage_DoesNotModifyLayout(t *testing.T) {
+	if _, err := NewPage(`{{define "title"}}Changed{{end}}`); err != nil {
+		t.Fatal(err)
+	}
+	other, err := NewPage(``)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := other.Execute(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "Changed") {
+		t.Errorf("a page override leaked into another page:\n%s", buf.String())
+	}
+}
+
+func TestRenderBoth(t *testing.T) {
+	text, html, err := RenderBoth(EscapingData{
+		Text: `<b>"Bob" & 'Alice'</b>`,
+		URL:  "javascript:alert(1)",
+	})
+	if err != nil {
+		t.Fatalf("RenderBoth: %v", err)
+	}
+	golden.Assert(t, "escaping_text", []byte(text))
+	golden.Assert(t, "escaping_html", []byte(html))
+}
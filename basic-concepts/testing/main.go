@@ -1,8 +1,12 @@
 package main
 
 import (
+	"bufio"
 	"fmt"
 	"math"
+	"strings"
+
+	"github.com/rehan/go-interview-prep/pkg/validator"
 )
 
 // Functions to be tested
@@ -26,56 +30,32 @@ func CircleArea(radius float64) (float64, error) {
 	return math.Pi * radius * radius, nil
 }
 
-// WordCount counts the number of words in a string
+// WordCount counts the whitespace-separated words in a string.
+// bufio.ScanWords treats any Unicode space as a separator, so tabs,
+// newlines and runs of spaces need no special cases.
 func WordCount(s string) int {
-	// Edge case: empty string
-	if len(s) == 0 {
-		return 0
+	scanner := bufio.NewScanner(strings.NewReader(s))
+	scanner.Split(bufio.ScanWords)
+	count := 0
+	for scanner.Scan() {
+		count++
 	}
-
-	// Count spaces to determine words
-	count := 1 // Start with 1 for the first word
-	for i := 0; i < len(s); i++ {
-		if s[i] == ' ' && i > 0 && s[i-1] != ' ' {
-			count++
-		}
-	}
-
-	// Handle cases like "  hello  "
-	if s[0] == ' ' {
-		count--
-	}
-	if s[len(s)-1] == ' ' {
-		count--
-	}
-
 	return count
 }
 
-// User represents a user in the system
+// User represents a user in the system.
+// The validate tags are read by the validator package.
 type User struct {
 	ID        int
-	FirstName string
-	LastName  string
-	Email     string
-	Age       int
+	FirstName string `validate:"required,max=50"`
+	LastName  string `validate:"required,max=50"`
+	Email     string `validate:"required,email"`
+	Age       int    `validate:"min=0"`
 }
 
-// ValidateUser checks if user data is valid
+// ValidateUser checks if user data is valid, reporting every invalid field at once
 func ValidateUser(u User) error {
-	if u.FirstName == "" {
-		return fmt.Errorf("first name cannot be empty")
-	}
-	if u.LastName == "" {
-		return fmt.Errorf("last name cannot be empty")
-	}
-	if u.Email == "" {
-		return fmt.Errorf("email cannot be empty")
-	}
-	if u.Age < 0 {
-		return fmt.Errorf("age cannot be negative")
-	}
-	return nil
+	return validator.Struct(u)
 }
 
 // EmailSender is an interface for sending emails
@@ -310,67 +290,29 @@ func ExampleWordCount() {
 	// 3
 }
 
-// Mock implementation for email sender
-type mockEmailSender struct {
-	sentEmails []struct {
-		email   string
-		subject string
-		body    string
-	}
-	shouldFail bool
-}
-
-func (m *mockEmailSender) Send(email, subject, body string) error {
-	if m.shouldFail {
-		return fmt.Errorf("failed to send email")
-	}
-	m.sentEmails = append(m.sentEmails, struct {
-		email   string
-		subject string
-		body    string
-	}{email, subject, body})
-	return nil
-}
-
+// Test with mocking. Rather than writing a mock of EmailSender by hand,
+// generate one next to the interface with
+//
+//	//go:generate go run ../cmd/mockgen -type=EmailSender -out=emailsender_mock_test.go
+//
+// EmailSenderMock records the arguments of each Send call, and pkg/mock
+// matches them (see basic-concepts/06_testing_test.go).
 func TestNotifyUser(t *testing.T) {
-	// Create a mock email sender
-	mockSender := &mockEmailSender{}
-
-	// Create a test user
-	user := User{
-		ID:        1,
-		FirstName: "John",
-		LastName:  "Doe",
-		Email:     "john@example.com",
-		Age:       30,
-	}
+	user := User{ID: 1, FirstName: "John", LastName: "Doe", Email: "john@example.com", Age: 30}
 
-	// Call the function with our mock
-	err := NotifyUser(user, mockSender)
-
-	// Verify no error occurred
-	if err != nil {
+	sender := &EmailSenderMock{}
+	if err := NotifyUser(user, sender); err != nil {
 		t.Errorf("NotifyUser() returned error: %v", err)
 	}
+	mock.AssertCalledTimes(t, sender.SendCalls(), 1,
+		user.Email, "Account Created", mock.Contains("Hello John,"))
 
-	// Verify an email was sent
-	if len(mockSender.sentEmails) != 1 {
-		t.Errorf("Expected 1 email to be sent, got %d", len(mockSender.sentEmails))
-	}
-
-	// Verify the email content
-	if len(mockSender.sentEmails) > 0 {
-		sent := mockSender.sentEmails[0]
-		if sent.email != user.Email {
-			t.Errorf("Wrong recipient email: got %s, want %s", sent.email, user.Email)
-		}
-		if sent.subject != "Account Created" {
-			t.Errorf("Wrong subject: got %s, want %s", sent.subject, "Account Created")
-		}
-		expectedBody := fmt.Sprintf("Hello %s, your account has been created.", user.FirstName)
-		if sent.body != expectedBody {
-			t.Errorf("Wrong body: got %s, want %s", sent.body, expectedBody)
-		}
+	// SendFunc decides what Send returns
+	failing := &EmailSenderMock{SendFunc: func(email, subject, body string) error {
+		return fmt.Errorf("failed to send email")
+	}}
+	if err := NotifyUser(user, failing); err == nil {
+		t.Error("NotifyUser() with failing sender should return error")
 	}
 }
 
@@ -425,7 +367,10 @@ Common interview questions about testing in Go:
 6. How do you implement setup and teardown in Go tests?
    - Use test fixtures/helpers for setup
    - Use defer for teardown
-   - TestMain(m *testing.M) for package-level setup/teardown
+   - TestMain(m *testing.M) for package-level setup/teardown, such as a
+     database in a temp dir that is migrated and seeded before m.Run and
+     removed after it (see basic-concepts/06_testing_test.go); put the
+     work in a function TestMain passes to os.Exit, so its defers run
 
 7. What are subtests in Go?
    - Tests created with t.Run("name", func(t *testing.T) {...})
@@ -444,8 +389,11 @@ Common interview questions about testing in Go:
 
 10. How do you mock dependencies in Go tests?
     - Use interfaces for external dependencies
-    - Create mock implementations for testing
+    - Create mock implementations for testing, by hand or generated
+      with go:generate (cmd/mockgen here, mockgen or moq elsewhere)
     - Inject the mock during tests
+    - Assert on the recorded calls, matching arguments exactly or with
+      matchers (pkg/mock here)
 
 11. How do parallel tests work in Go?
     - Call t.Parallel() in a test to indicate it can run in parallel
@@ -0,0 +1,328 @@
+// Command mockgen writes a test double for an interface declared in a Go
+// source file. It is meant to run from a go:generate directive:
+//
+//	//go:generate go run ../cmd/mockgen -type=EmailSender -out=emailsender_mock_test.go
+//
+// For an interface Foo with a method Bar, the generated FooMock has a
+// BarFunc field that decides what Bar returns (zero values when nil) and a
+// BarCalls method returning the recorded arguments of every call.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Getenv, os.Stderr))
+}
+
+// run parses flags, generates the mock and writes it, returning the exit code
+func run(args []string, getenv func(string) string, stderr io.Writer) int {
+	fs := flag.NewFlagSet("mockgen", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	typeName := fs.String("type", "", "name of the interface to mock (required)")
+	source := fs.String("source", getenv("GOFILE"), "file declaring the interface (default $GOFILE, set by go generate)")
+	out := fs.String("out", "", "output file (default <type>_mock_test.go, lower case)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *typeName == "" || *source == "" {
+		fmt.Fprintln(stderr, "mockgen: -type and -source (or $GOFILE) are required")
+		fs.Usage()
+		return 2
+	}
+	if *out == "" {
+		*out = strings.ToLower(*typeName) + "_mock_test.go"
+	}
+
+	src, err := os.ReadFile(*source)
+	if err != nil {
+		fmt.Fprintln(stderr, "mockgen:", err)
+		return 1
+	}
+	code, err := Generate(*source, src, *typeName)
+	if err != nil {
+		fmt.Fprintln(stderr, "mockgen:", err)
+		return 1
+	}
+	if err := os.WriteFile(*out, code, 0o644); err != nil {
+		fmt.Fprintln(stderr, "mockgen:", err)
+		return 1
+	}
+	return 0
+}
+
+// Generate returns the formatted source of a mock for the interface
+// typeName declared in src. filename is used only in error messages and the
+// generated header.
+func Generate(filename string, src []byte, typeName string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	iface, err := findInterface(file, typeName)
+	if err != nil {
+		return nil, err
+	}
+
+	data := mockData{
+		Source:    filename,
+		Package:   file.Name.Name,
+		Interface: typeName,
+		Mock:      typeName + "Mock",
+	}
+	used := map[string]bool{}
+	for _, field := range iface.Methods.List {
+		fn, ok := field.Type.(*ast.FuncType)
+		if !ok || len(field.Names) == 0 {
+			return nil, fmt.Errorf("%s: embedded interfaces are not supported; list the methods instead", typeName)
+		}
+		for _, name := range field.Names {
+			data.Methods = append(data.Methods, newMethod(name.Name, fn))
+		}
+		collectPackages(fn, used)
+	}
+	data.Imports, err = resolveImports(file, used)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := mockTemplate.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	code, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w\n%s", err, buf.Bytes())
+	}
+	return code, nil
+}
+
+// findInterface returns the interface type named name in file
+func findInterface(file *ast.File, name string) (*ast.InterfaceType, error) {
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			if ts.Name.Name != name {
+				continue
+			}
+			if ts.TypeParams != nil {
+				return nil, fmt.Errorf("%s: generic interfaces are not supported", name)
+			}
+			iface, ok := ts.Type.(*ast.InterfaceType)
+			if !ok {
+				return nil, fmt.Errorf("%s is not an interface", name)
+			}
+			return iface, nil
+		}
+	}
+	return nil, fmt.Errorf("interface %s not found", name)
+}
+
+type mockData struct {
+	Source    string
+	Package   string
+	Interface string
+	Mock      string
+	Imports   []string
+	Methods   []method
+}
+
+type method struct {
+	Name    string
+	Params  []param
+	Results []string
+}
+
+type param struct {
+	Name     string // parameter name in the generated method
+	Field    string // field name in the recorded call struct
+	Type     string // type in the method signature, e.g. ...string
+	CallType string // type of the recorded field, e.g. []string
+	Variadic bool
+}
+
+// reserved are names the generated method body uses itself
+var reserved = map[string]bool{"_": true, "m": true, "fn": true, "sync": true}
+
+// newMethod describes one interface method. Unnamed or blank parameters,
+// and any that clash with names in the generated body, are named p0, p1,
+// ... so they can be recorded and passed on.
+func newMethod(name string, fn *ast.FuncType) method {
+	m := method{Name: name}
+	i := 0
+	for _, field := range fn.Params.List {
+		typ := types.ExprString(field.Type)
+		callType := typ
+		_, variadic := field.Type.(*ast.Ellipsis)
+		if variadic {
+			callType = "[]" + strings.TrimPrefix(typ, "...")
+		}
+		names := field.Names
+		if len(names) == 0 {
+			names = []*ast.Ident{{Name: "_"}}
+		}
+		for _, n := range names {
+			pname := n.Name
+			if reserved[pname] || strings.HasPrefix(pname, "r") && isDigits(pname[1:]) {
+				pname = "p" + strconv.Itoa(i)
+			}
+			m.Params = append(m.Params, param{
+				Name:     pname,
+				Field:    exported(pname),
+				Type:     typ,
+				CallType: callType,
+				Variadic: variadic,
+			})
+			i++
+		}
+	}
+	if fn.Results != nil {
+		for _, field := range fn.Results.List {
+			n := max(len(field.Names), 1)
+			for range n {
+				m.Results = append(m.Results, types.ExprString(field.Type))
+			}
+		}
+	}
+	return m
+}
+
+func isDigits(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
+
+func exported(name string) string {
+	r := []rune(name)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+// collectPackages records the package names referenced by fn's types
+func collectPackages(fn *ast.FuncType, used map[string]bool) {
+	ast.Inspect(fn, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok {
+				used[id.Name] = true
+			}
+		}
+		return true
+	})
+}
+
+// resolveImports returns the import lines of file that provide the package
+// names in used. A package is matched by its explicit name or by the last
+// element of its path, which covers every import in this repository.
+func resolveImports(file *ast.File, used map[string]bool) ([]string, error) {
+	var imports []string
+	found := map[string]bool{}
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		name := path[strings.LastIndex(path, "/")+1:]
+		line := strconv.Quote(path)
+		if spec.Name != nil {
+			name = spec.Name.Name
+			line = name + " " + line
+		}
+		if used[name] {
+			found[name] = true
+			if line != `"sync"` { // always imported by the mock
+				imports = append(imports, line)
+			}
+		}
+	}
+	var missing []string
+	for name := range used {
+		if !found[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, errors.New("no import found for package " + strings.Join(missing, ", "))
+	}
+	return imports, nil
+}
+
+var mockTemplate = template.Must(template.New("mock").Funcs(template.FuncMap{
+	"results": func(rs []string) string {
+		if len(rs) < 2 {
+			return strings.Join(rs, "")
+		}
+		return "(" + strings.Join(rs, ", ") + ")"
+	},
+	"lower": func(s string) string { r := []rune(s); r[0] = unicode.ToLower(r[0]); return string(r) },
+}).Parse(`// Code generated by mockgen from {{.Source}}; DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"sync"
+{{range .Imports}}	{{.}}
+{{end}})
+
+// {{.Mock}} is a test double for {{.Interface}}. Set a method's Func field to
+// control its results; the zero value returns zero values.
+type {{.Mock}} struct {
+	mu sync.Mutex
+{{range .Methods}}
+	// {{.Name}}Func is called by {{.Name}} if it is not nil
+	{{.Name}}Func func({{range $i, $p := .Params}}{{if $i}}, {{end}}{{$p.Name}} {{$p.Type}}{{end}}) {{results .Results}}
+	{{lower .Name}}Calls []{{$.Mock}}{{.Name}}Call
+{{end}}}
+
+var _ {{.Interface}} = (*{{.Mock}})(nil)
+{{range .Methods}}
+// {{$.Mock}}{{.Name}}Call holds the arguments of one {{.Name}} call
+type {{$.Mock}}{{.Name}}Call struct {
+{{range .Params}}	{{.Field}} {{.CallType}}
+{{end}}}
+
+// {{.Name}} records the call and delegates to {{.Name}}Func
+func (m *{{$.Mock}}) {{.Name}}({{range $i, $p := .Params}}{{if $i}}, {{end}}{{$p.Name}} {{$p.Type}}{{end}}) {{results .Results}} {
+	m.mu.Lock()
+	m.{{lower .Name}}Calls = append(m.{{lower .Name}}Calls, {{$.Mock}}{{.Name}}Call{ {{- range $i, $p := .Params}}{{if $i}}, {{end}}{{$p.Field}}: {{$p.Name}}{{end -}} })
+	fn := m.{{.Name}}Func
+	m.mu.Unlock()
+
+	if fn == nil {
+{{- if .Results}}
+{{- range $i, $r := .Results}}
+		var r{{$i}} {{$r}}
+{{- end}}
+		return {{range $i, $r := .Results}}{{if $i}}, {{end}}r{{$i}}{{end}}
+{{- else}}
+		return
+{{- end}}
+	}
+	{{if .Results}}return {{end}}fn({{range $i, $p := .Params}}{{if $i}}, {{end}}{{$p.Name}}{{if $p.Variadic}}...{{end}}{{end}})
+}
+
+// {{.Name}}Calls returns a copy of the recorded {{.Name}} calls
+func (m *{{$.Mock}}) {{.Name}}Calls() []{{$.Mock}}{{.Name}}Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]{{$.Mock}}{{.Name}}Call(nil), m.{{lower .Name}}Calls...)
+}
+{{end}}`))
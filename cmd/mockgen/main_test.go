@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rehan/go-interview-prep/pkg/testutil/golden"
+)
+
+// storeSource covers the shapes the generator must handle: imports,
+// unnamed and variadic parameters, names that clash with the generated
+// body, and zero, one and several results
+const storeSource = `package store
+
+import (
+	"context"
+	stdio "io"
+	"time"
+)
+
+type Store interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Put(context.Context, string, []byte) error
+	Keys(prefix string, limit ...int) []string
+	Copy(m stdio.Writer, fn string) (n int64, err error)
+	Touch(key string, at time.Time)
+	Close()
+}
+
+type NotAnInterface struct{}
+
+type Embeds interface {
+	stdio.Reader
+}
+`
+
+func TestGenerate_Golden(t *testing.T) {
+	got, err := Generate("store.go", []byte(storeSource), "Store")
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+
+	golden.Assert(t, "store_mock", got)
+}
+
+// The generated mock must compile alongside the interface and satisfy it;
+// the mock declares var _ Store = (*StoreMock)(nil) to make that checkable
+func TestGenerate_TypeChecks(t *testing.T) {
+	mock, err := Generate("store.go", []byte(storeSource), "Store")
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for name, src := range map[string]string{"store.go": storeSource, "store_mock.go": string(mock)} {
+		f, err := parser.ParseFile(fset, name, src, 0)
+		if err != nil {
+			t.Fatalf("parsing %s: %v", name, err)
+		}
+		files = append(files, f)
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := conf.Check("store", fset, files, nil); err != nil {
+		t.Errorf("generated code does not type-check: %v\n%s", err, mock)
+	}
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	tests := []struct {
+		typeName string
+		wantErr  string
+	}{
+		{"Missing", "interface Missing not found"},
+		{"NotAnInterface", "is not an interface"},
+		{"Embeds", "embedded interfaces are not supported"},
+	}
+	for _, tc := range tests {
+		_, err := Generate("store.go", []byte(storeSource), tc.typeName)
+		if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Errorf("Generate(%s) error = %v; want it to contain %q", tc.typeName, err, tc.wantErr)
+		}
+	}
+
+	_, err := Generate("bad.go", []byte("package p\ntype I interface{ F(x foo.Bar) }\n"), "I")
+	if err == nil || !strings.Contains(err.Error(), "no import found for package foo") {
+		t.Errorf("Generate with an unknown package: error = %v", err)
+	}
+}
+
+// Generated files are committed, so a test fails when the interface changes
+// and nobody reran go generate
+func TestCheckedInMocksAreUpToDate(t *testing.T) {
+	mocks := []struct {
+		source, typeName, out string
+	}{
+		{"../../basic-concepts/06_testing.go", "EmailSender", "../../basic-concepts/emailsender_mock_test.go"},
+		{"../../mini-projects/rest_api/main.go", "BookRepository", "../../mini-projects/rest_api/bookrepository_mock_test.go"},
+	}
+	for _, m := range mocks {
+		src, err := os.ReadFile(m.source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := Generate(filepath.Base(m.source), src, m.typeName)
+		if err != nil {
+			t.Fatalf("Generate(%s): %v", m.typeName, err)
+		}
+		got, err := os.ReadFile(m.out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s is stale; run go generate in %s", m.out, filepath.Dir(m.source))
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "store.go")
+	if err := os.WriteFile(source, []byte(storeSource), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "mock.go")
+
+	// -source defaults to $GOFILE, which go generate sets
+	getenv := func(key string) string {
+		if key == "GOFILE" {
+			return source
+		}
+		return ""
+	}
+	if code := run([]string{"-type", "Store", "-out", out}, getenv, io.Discard); code != 0 {
+		t.Fatalf("run exit code = %d; want 0", code)
+	}
+	if data, err := os.ReadFile(out); err != nil || !bytes.Contains(data, []byte("type StoreMock struct")) {
+		t.Errorf("output file: %v\n%s", err, data)
+	}
+
+	if code := run(nil, getenv, io.Discard); code != 2 {
+		t.Errorf("run without -type exit code = %d; want 2", code)
+	}
+	if code := run([]string{"-type", "Missing", "-out", out}, getenv, io.Discard); code != 1 {
+		t.Errorf("run with a missing type exit code = %d; want 1", code)
+	}
+}
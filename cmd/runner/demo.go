@@ -0,0 +1,125 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	bufferedio "github.com/rehan/go-interview-prep/basic-concepts/buffered_io"
+	buildtags "github.com/rehan/go-interview-prep/basic-concepts/build_tags"
+	"github.com/rehan/go-interview-prep/basic-concepts/cli"
+	"github.com/rehan/go-interview-prep/basic-concepts/cli/command"
+	"github.com/rehan/go-interview-prep/basic-concepts/closures"
+	controlflow "github.com/rehan/go-interview-prep/basic-concepts/control_flow"
+	deferpanicrecover "github.com/rehan/go-interview-prep/basic-concepts/defer_panic_recover"
+	embedfs "github.com/rehan/go-interview-prep/basic-concepts/embed_fs"
+	"github.com/rehan/go-interview-prep/basic-concepts/enums"
+	errorhandling "github.com/rehan/go-interview-prep/basic-concepts/error_handling"
+	filehandling "github.com/rehan/go-interview-prep/basic-concepts/file_handling"
+	"github.com/rehan/go-interview-prep/basic-concepts/functions"
+	gctuning "github.com/rehan/go-interview-prep/basic-concepts/gc_tuning"
+	"github.com/rehan/go-interview-prep/basic-concepts/iterators"
+	jsonencoding "github.com/rehan/go-interview-prep/basic-concepts/json_encoding"
+	"github.com/rehan/go-interview-prep/basic-concepts/logging"
+	"github.com/rehan/go-interview-prep/basic-concepts/numbers"
+	protobufwire "github.com/rehan/go-interview-prep/basic-concepts/protobuf_wire"
+	"github.com/rehan/go-interview-prep/basic-concepts/reflection"
+	signalsexec "github.com/rehan/go-interview-prep/basic-concepts/signals_exec"
+	structsinterfaces "github.com/rehan/go-interview-prep/basic-concepts/structs_interfaces"
+	"github.com/rehan/go-interview-prep/basic-concepts/templates"
+	contextpackage "github.com/rehan/go-interview-prep/concurrency/context_package"
+	goroutines "github.com/rehan/go-interview-prep/concurrency/goroutines_and_channels"
+	httpaggregator "github.com/rehan/go-interview-prep/concurrency/http_aggregator"
+	runtimeintrospection "github.com/rehan/go-interview-prep/concurrency/runtime_introspection"
+	syncpackage "github.com/rehan/go-interview-prep/concurrency/sync_package"
+	arraysslices "github.com/rehan/go-interview-prep/data-structures/arrays_slices"
+	"github.com/rehan/go-interview-prep/data-structures/maps"
+	dependencyinjection "github.com/rehan/go-interview-prep/examples/dependency-injection"
+)
+
+const demoAbout = `Runs a topic's examples, printing what each one shows and then the
+topic's interview questions, which "runner quiz -topic <name>" asks
+interactively. Only gc-tuning and goroutines-and-channels take arguments:
+"runner demo gc-tuning -h" lists the flags of the first, and
+goroutines-and-channels takes -seed, to give its workers the same
+simulated delays as a run before.
+`
+
+// demos are the examples in each topic directory, named after the topic's
+// quiz where it has one
+var demos = []*command.Command{
+	demo("arrays-slices", "arrays, slices and their backing arrays", arraysslices.Run),
+	demo("buffered-io", "bufio readers, writers and scanners", bufferedio.Run),
+	demo("build-tags", "platform files and feature tags", buildtags.Run),
+	demo("cli", "flag sets, custom flag values and subcommands", cli.Run),
+	demo("closures", "captured variables and memoization", closures.Run),
+	demo("context-package", "cancellation, deadlines and values (takes a few seconds)", contextpackage.Run),
+	demo("control-flow", "if, for and switch", controlflow.Run),
+	demo("defer-panic-recover", "defer ordering and recovering from panics", deferpanicrecover.Run),
+	demo("dependency-injection", "wiring services through constructors", dependencyinjection.Run),
+	demo("embed-fs", "go:embed and io/fs", embedfs.Run),
+	demo("enums", "iota constants, validation, JSON and bit flags", enums.Run),
+	demo("error-handling", "error values, wrapping and custom errors", errorhandling.Run),
+	demo("file-handling", "reading, writing and walking files", filehandling.Run),
+	demo("functions", "multiple results, variadic functions and function values", functions.Run),
+	&command.Command{
+		Name:    "gc-tuning",
+		Summary: "GOGC, the memory limit and sync.Pool on a simulated workload",
+		Run: func(args []string, stdout, stderr io.Writer) int {
+			return demoExit("gc-tuning", gctuning.Run(stdout, args), stderr)
+		},
+	},
+	&command.Command{
+		Name:    "goroutines-and-channels",
+		Summary: "goroutines, channels, select and worker pools",
+		Run: func(args []string, stdout, stderr io.Writer) int {
+			return demoExit("goroutines-and-channels", goroutines.Run(stdout, args), stderr)
+		},
+	},
+	demo("http-aggregator", "concurrent HTTP calls, each with a timeout", httpaggregator.Run),
+	demo("iterators", "range-over-func iterators", iterators.Run),
+	demo("json-encoding", "encoding/json tags, custom marshalers and streaming", jsonencoding.Run),
+	demo("logging", "structured logging with log/slog", logging.Run),
+	demo("maps", "map operations, key types and concurrent access", maps.Run),
+	demo("numbers", "overflow, floats, math/big and money", numbers.Run),
+	demo("protobuf-wire", "protobuf varints, tags and length-delimited fields by hand", protobufwire.Run),
+	demo("reflection", "reflect types, values and struct tags", reflection.Run),
+	demo("runtime-introspection", "the scheduler, stack dumps and tracing", runtimeintrospection.Run),
+	demo("signals-exec", "running commands and handling signals", signalsexec.Run),
+	demo("structs-interfaces", "structs, methods and interfaces", structsinterfaces.Run),
+	demo("sync-package", "mutexes, WaitGroup, Once, Cond, Map and Pool", syncpackage.Run),
+	demo("templates", "text/template and html/template", templates.Run),
+}
+
+func newDemos() *command.Dispatcher {
+	return command.New("runner demo", demos...)
+}
+
+// demo makes a command of a demo that takes no arguments
+func demo(name, summary string, run func(w io.Writer) error) *command.Command {
+	return &command.Command{
+		Name:    name,
+		Summary: summary,
+		Run: func(args []string, stdout, stderr io.Writer) int {
+			if len(args) > 0 {
+				fmt.Fprintf(stderr, "runner demo %s: takes no arguments\n", name)
+				return command.ExitUsage
+			}
+			return demoExit(name, run(stdout), stderr)
+		},
+	}
+}
+
+// demoExit reports a demo's error and returns the exit code for it
+func demoExit(name string, err error, stderr io.Writer) int {
+	if err == nil || errors.Is(err, flag.ErrHelp) {
+		return command.ExitOK
+	}
+	fmt.Fprintf(stderr, "runner demo %s: %v\n", name, err)
+	return command.ExitError
+}
+
+func runDemo(args []string, stdout, stderr io.Writer) int {
+	return newDemos().Run(args, stdout, stderr)
+}
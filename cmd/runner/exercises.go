@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/rehan/go-interview-prep/basic-concepts/cli/command"
+	"github.com/rehan/go-interview-prep/pkg/coverage"
+	"github.com/rehan/go-interview-prep/pkg/exercises"
+)
+
+const exercisesAbout = `Helps with the exercises and with writing tests. "runner judge" checks
+a solution against hidden vectors; these commands look at your own tests.
+`
+
+// newExerciseCommands lists the exercises subcommands
+func newExerciseCommands() *command.Dispatcher {
+	return command.New("runner exercises",
+		&command.Command{
+			Name:    "verify",
+			Summary: "run a package's tests and list the exported functions they leave uncovered",
+			Help:    verifyUsage,
+			Run:     runVerify,
+		},
+	)
+}
+
+func runExercises(args []string, stdout, stderr io.Writer) int {
+	return newExerciseCommands().Run(args, stdout, stderr)
+}
+
+const verifyUsage = `usage: runner exercises verify [-min pct] [-run regexp] [-profile file] <exercise|dir>
+
+Runs the tests of a package with -coverprofile and reports, for each of
+its exported functions and methods, how many of its statements the tests
+ran: first those never run, then those below -min, so you know what to
+test next. The package is an exercise's stub, exercises/<exercise>, or
+any package directory. Fails if a function is below -min. Needs the go
+command.
+
+  -min pct       coverage each exported function needs (default 80)
+  -run regexp    run only the tests matching regexp
+  -profile file  read this coverage profile rather than running the tests
+`
+
+func runVerify(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("exercises verify", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { fmt.Fprint(stderr, verifyUsage) }
+	min := fs.Float64("min", 80, "coverage each exported function needs, in percent")
+	runTests := fs.String("run", "", "tests to run")
+	profile := fs.String("profile", "", "coverage profile to read")
+	if err := fs.Parse(args); err != nil {
+		return command.ExitUsage
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return command.ExitUsage
+	}
+	if *min < 0 || *min > 100 {
+		fmt.Fprintln(stderr, "runner exercises verify: -min must be from 0 to 100")
+		return command.ExitUsage
+	}
+	dir := fs.Arg(0)
+	if e, err := exercises.Lookup(dir); err == nil {
+		dir = filepath.Join("exercises", e.ID)
+	}
+
+	importPath, err := goList(dir)
+	if err != nil {
+		fmt.Fprintf(stderr, "runner exercises verify: %v\n", err)
+		return command.ExitError
+	}
+	failed := false
+	if *profile == "" {
+		f, err := os.CreateTemp("", "runner-verify-*.out")
+		if err != nil {
+			fmt.Fprintf(stderr, "runner exercises verify: %v\n", err)
+			return command.ExitError
+		}
+		f.Close()
+		*profile = f.Name()
+		defer os.Remove(*profile)
+		if err := coverTests(dir, *profile, *runTests, stderr); err != nil {
+			// The profile still records the tests that ran
+			fmt.Fprintf(stderr, "runner exercises verify: %v; the coverage below is of the tests that ran\n", err)
+			failed = true
+		}
+	}
+	f, err := os.Open(*profile)
+	if err != nil {
+		fmt.Fprintf(stderr, "runner exercises verify: %v\n", err)
+		return command.ExitError
+	}
+	blocks, err := coverage.ParseProfile(f)
+	f.Close()
+	if err != nil {
+		fmt.Fprintf(stderr, "runner exercises verify: %s: %v\n", *profile, err)
+		return command.ExitError
+	}
+	funcs, err := coverage.Funcs(dir, importPath, blocks)
+	if err != nil {
+		fmt.Fprintf(stderr, "runner exercises verify: %v\n", err)
+		return command.ExitError
+	}
+
+	if !writeCoverage(stdout, importPath, funcs, *min) || failed {
+		return command.ExitError
+	}
+	return command.ExitOK
+}
+
+// goList returns the import path of the package in dir
+func goList(dir string) (string, error) {
+	cmd := exec.Command("go", "list", "-f", "{{.ImportPath}}", ".")
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("go list %s: %v\n%s", dir, err, stderr.Bytes())
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// coverTests runs the tests of the package in dir, writing their coverage
+// to profile. If the tests fail their output goes to stderr.
+func coverTests(dir, profile, run string, stderr io.Writer) error {
+	args := []string{"test", "-coverprofile=" + profile}
+	if run != "" {
+		args = append(args, "-run="+run)
+	}
+	cmd := exec.Command("go", append(args, ".")...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		stderr.Write(out)
+		return fmt.Errorf("go test: %w", err)
+	}
+	return nil
+}
+
+// writeCoverage prints the functions never run, then those below min
+// percent, then a count of the rest, and reports whether none was below
+// min
+func writeCoverage(w io.Writer, importPath string, funcs []coverage.Func, min float64) bool {
+	if len(funcs) == 0 {
+		fmt.Fprintf(w, "%s: no exported functions\n", importPath)
+		return true
+	}
+	var untested, partial []coverage.Func
+	stmts, covered := 0, 0
+	for _, f := range funcs {
+		stmts += f.Stmts
+		covered += f.Covered
+		switch {
+		case f.Stmts > 0 && f.Covered == 0:
+			untested = append(untested, f)
+		case f.Percent() < min:
+			partial = append(partial, f)
+		}
+	}
+	total := coverage.Func{Stmts: stmts, Covered: covered}
+	fmt.Fprintf(w, "%s: %d exported functions, %d/%d statements covered (%.1f%%)\n",
+		importPath, len(funcs), covered, stmts, total.Percent())
+
+	if len(untested) > 0 {
+		fmt.Fprintln(w, "\nnot tested:")
+		for _, f := range untested {
+			fmt.Fprintf(w, "  %-16s %s\n", fmt.Sprintf("%s:%d", f.File, f.Line), f.Name)
+		}
+	}
+	if len(partial) > 0 {
+		fmt.Fprintf(w, "\nbelow %g%%:\n", min)
+		for _, f := range partial {
+			fmt.Fprintf(w, "  %-16s %s  %d/%d statements (%.1f%%)\n", fmt.Sprintf("%s:%d", f.File, f.Line), f.Name, f.Covered, f.Stmts, f.Percent())
+		}
+	}
+	fmt.Fprintf(w, "\n%d of %d at %g%% or more\n", len(funcs)-len(untested)-len(partial), len(funcs), min)
+
+	switch {
+	case len(untested) > 0:
+		fmt.Fprintf(w, "next: write a test that calls %s\n", untested[0].Name)
+	case len(partial) > 0:
+		fmt.Fprintf(w, "next: test the branches of %s that no test takes; go tool cover -html shows them\n", partial[0].Name)
+	default:
+		fmt.Fprintf(w, "ok: none below %g%%\n", min)
+		return true
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"time"
+
+	"github.com/rehan/go-interview-prep/basic-concepts/cli/command"
+	"github.com/rehan/go-interview-prep/pkg/exercises"
+)
+
+const judgeUsage = `usage: runner judge [-dir path] [-timeout d] <exercise>
+       runner judge -list
+
+Builds your solution to an exercise and runs it on hidden test vectors,
+reporting each case with its timing. The stubs are in exercises/<name>;
+replace the panic with a solution, or copy the stub and point -dir at the
+copy. Needs the go command.
+
+  -dir path    the solution package (default exercises/<exercise>)
+  -timeout d   how long building and running may take (default 30s)
+  -list        print the exercises and exit
+`
+
+func runJudge(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("judge", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { fmt.Fprint(stderr, judgeUsage) }
+	dir := fs.String("dir", "", "solution package directory")
+	timeout := fs.Duration("timeout", 30*time.Second, "time limit")
+	list := fs.Bool("list", false, "print the exercises")
+	if err := fs.Parse(args); err != nil {
+		return command.ExitUsage
+	}
+	if *list {
+		for _, e := range exercises.All {
+			fmt.Fprintf(stdout, "%-16s %s\n%-16s %s\n", e.ID, e.Title, "", e.Signature)
+		}
+		return command.ExitOK
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return command.ExitUsage
+	}
+
+	e, err := exercises.Lookup(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "runner judge: %v (see -list)\n", err)
+		return command.ExitUsage
+	}
+	if *dir == "" {
+		*dir = filepath.Join("exercises", e.ID)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	report, err := exercises.Judge(ctx, e, *dir)
+	if err != nil {
+		fmt.Fprintf(stderr, "runner judge: %v\n", err)
+		return command.ExitError
+	}
+
+	writeReport(stdout, report)
+	if !report.OK() {
+		return command.ExitError
+	}
+	return command.ExitOK
+}
+
+// writeReport prints a line per case, with the input and the answers for
+// failures, and a total
+func writeReport(w io.Writer, r exercises.Report) {
+	fmt.Fprintf(w, "%s: %s\n%s\nbuilt in %v\n", r.Exercise.ID, r.Exercise.Title, r.Exercise.Signature, r.Build.Round(time.Millisecond))
+	if r.CompileError != "" {
+		fmt.Fprintf(w, "\ncompile error:\n%s", r.CompileError)
+		return
+	}
+	fmt.Fprintln(w)
+	stopped := false
+	for i, c := range r.Cases {
+		switch {
+		case c.Passed:
+			fmt.Fprintf(w, "case %-2d PASS  %v\n", i+1, c.Duration)
+		case c.Panic != "":
+			fmt.Fprintf(w, "case %-2d FAIL  %v  input %s: panic: %s\n", i+1, c.Duration, c.Input, c.Panic)
+		case c.Got == nil && stopped:
+			fmt.Fprintf(w, "case %-2d not run\n", i+1)
+		case c.Got == nil && r.TimedOut:
+			fmt.Fprintf(w, "case %-2d FAIL  input %s: did not finish in time\n", i+1, c.Input)
+			stopped = true
+		case c.Got == nil:
+			fmt.Fprintf(w, "case %-2d FAIL  input %s: crashed the program:\n%s", i+1, c.Input, r.Crash)
+			stopped = true
+		default:
+			fmt.Fprintf(w, "case %-2d FAIL  %v  input %s: got %s, want %s\n", i+1, c.Duration, c.Input, c.Got, c.Want)
+		}
+	}
+	fmt.Fprintf(w, "\n%d/%d passed\n", r.Passed(), len(r.Cases))
+}
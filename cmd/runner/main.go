@@ -0,0 +1,171 @@
+// Command runner runs tools, demos and servers from this repository.
+//
+//	go run ./cmd/runner demo maps
+//	go run ./cmd/runner demo gc-tuning -requests 5000
+//	go run ./cmd/runner demo help
+//	go run ./cmd/runner serve rest-api -addr :9090
+//	go run -tags filestore ./cmd/runner serve rest-api seed -fake-books 100
+//	go run ./cmd/runner profile cpu -o cpu.out
+//	go run ./cmd/runner profile heap -o heap.out
+//	go run ./cmd/runner profile help
+//	go run ./cmd/runner perf -update -bench Concat
+//	go run ./cmd/runner perf -bench Concat -time-tolerance 15
+//	go run ./cmd/runner sort -algo merge 3 1 2
+//	go run ./cmd/runner stress -duration 1m -race
+//	go run ./cmd/runner quiz -topic maps,sync-package -difficulty medium -n 5
+//	go run ./cmd/runner judge reverse
+//	go run ./cmd/runner exercises verify reverse
+//	go run ./cmd/runner exercises verify -min 100 ./concurrency/races
+//	go run ./cmd/runner help
+//	RUNNER_PROFILE_ITERATIONS=500 go run ./cmd/runner profile cpu
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/rehan/go-interview-prep/basic-concepts/cli/command"
+	signalsexec "github.com/rehan/go-interview-prep/basic-concepts/signals_exec"
+	"github.com/rehan/go-interview-prep/pkg/config"
+	"github.com/rehan/go-interview-prep/pkg/profiling"
+)
+
+func main() {
+	// The signals-exec demo starts this program again as its child
+	signalsexec.RunChild()
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// newDispatcher lists the runner's commands
+func newDispatcher() *command.Dispatcher {
+	return command.New("runner",
+		&command.Command{
+			Name:    "demo",
+			Summary: "run a topic's examples and print its interview questions",
+			Help:    newDemos().Usage() + "\n" + demoAbout,
+			Run:     runDemo,
+		},
+		&command.Command{
+			Name:    "exercises",
+			Summary: "check how much of a package your tests cover, function by function",
+			Help:    newExerciseCommands().Usage() + "\n" + exercisesAbout,
+			Run:     runExercises,
+		},
+		&command.Command{
+			Name:    "judge",
+			Summary: "build your solution to an exercise and run it on hidden test vectors",
+			Help:    judgeUsage,
+			Run:     runJudge,
+		},
+		&command.Command{
+			Name:    "perf",
+			Summary: "run benchmarks and fail if they regressed from a saved baseline",
+			Help:    perfUsage,
+			Run:     runPerf,
+		},
+		&command.Command{
+			Name:    "profile",
+			Summary: "capture CPU and heap profiles of a demo workload",
+			Help:    profileUsage,
+			Run:     runProfile,
+		},
+		&command.Command{
+			Name:    "quiz",
+			Summary: "answer interview questions in random order and get a score",
+			Help:    quizUsage,
+			Run:     runQuiz,
+		},
+		&command.Command{
+			Name:    "serve",
+			Summary: "run a mini-project's server",
+			Help:    newServers().Usage() + "\n" + serveAbout,
+			Run:     runServe,
+		},
+		&command.Command{
+			Name:    "sort",
+			Summary: "sort integers with a sorter chosen by name from a registry",
+			Help:    sortUsage,
+			Run:     runSort,
+		},
+		&command.Command{
+			Name:    "stress",
+			Summary: "hammer the concurrent packages from many goroutines and check their invariants",
+			Help:    stressUsage,
+			Run:     runStress,
+		},
+	)
+}
+
+// run executes the command in args and returns the process exit code
+func run(args []string, stdout, stderr io.Writer) int {
+	return newDispatcher().Run(args, stdout, stderr)
+}
+
+const profileUsage = `usage: runner profile <cpu|heap|help> [flags]
+
+  cpu    run the demo workload under the CPU profiler
+  heap   run the demo workload and write a heap profile
+  help   explain how to read profiles and profile the REST API
+`
+
+func runProfile(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, profileUsage)
+		return command.ExitUsage
+	}
+
+	kind := args[0]
+	switch kind {
+	case "help":
+		fmt.Fprint(stdout, profiling.Instructions)
+		return command.ExitOK
+	case "cpu", "heap":
+	default:
+		fmt.Fprintf(stderr, "runner profile: unknown profile %q\n\n%s", kind, profileUsage)
+		return command.ExitUsage
+	}
+
+	cfg := profileConfig{Output: kind + ".out", Iterations: 200}
+
+	fs := flag.NewFlagSet("profile "+kind, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	configFile := fs.String("config", "", "JSON or YAML file with output and iterations")
+	fs.String("o", cfg.Output, "output file (env RUNNER_PROFILE_OUTPUT)")
+	fs.Int("n", cfg.Iterations, "workload iterations (env RUNNER_PROFILE_ITERATIONS)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return command.ExitUsage
+	}
+
+	err := config.Load(&cfg, config.Options{
+		File:      *configFile,
+		EnvPrefix: "RUNNER_PROFILE_",
+		Flags:     fs,
+	})
+	if err != nil {
+		fmt.Fprintf(stderr, "runner profile %s: %v\n", kind, err)
+		return command.ExitUsage
+	}
+
+	if kind == "cpu" {
+		err = profiling.CaptureCPUToFile(cfg.Output, func() { profiling.Workload(cfg.Iterations) })
+	} else {
+		profiling.Workload(cfg.Iterations)
+		err = profiling.WriteHeapToFile(cfg.Output)
+		profiling.ReleaseWorkload()
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "runner profile %s: %v\n", kind, err)
+		return command.ExitError
+	}
+
+	fmt.Fprintf(stdout, "wrote %s profile to %s\ninspect it with: go tool pprof -top %s\n", kind, cfg.Output, cfg.Output)
+	return command.ExitOK
+}
+
+// profileConfig holds the profile settings; see pkg/config for precedence
+type profileConfig struct {
+	Output     string `config:"output" flag:"o" validate:"required"`
+	Iterations int    `config:"iterations" flag:"n" validate:"min=1"`
+}
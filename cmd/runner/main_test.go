@@ -0,0 +1,516 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	signalsexec "github.com/rehan/go-interview-prep/basic-concepts/signals_exec"
+	"github.com/rehan/go-interview-prep/pkg/quiz"
+)
+
+func TestMain(m *testing.M) {
+	// The signals-exec demo starts the test binary again as its child
+	signalsexec.RunChild()
+	os.Exit(m.Run())
+}
+
+func TestRun_Usage(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantCode   int
+		wantStderr string
+	}{
+		{"no command", nil, 2, "usage: runner"},
+		{"unknown command", []string{"nope"}, 2, `unknown command "nope"`},
+		{"profile without kind", []string{"profile"}, 2, "usage: runner profile"},
+		{"unknown profile", []string{"profile", "mutex"}, 2, `unknown profile "mutex"`},
+		{"bad flag", []string{"profile", "cpu", "-x"}, 2, "flag provided but not defined"},
+		{"invalid iterations", []string{"profile", "cpu", "-n", "0"}, 2, "Iterations must be at least 1"},
+		{"demo without name", []string{"demo"}, 2, "usage: runner demo"},
+		{"unknown demo", []string{"demo", "context"}, 2, `unknown command "context"`},
+		{"demo with arguments", []string{"demo", "maps", "-v"}, 2, "runner demo maps: takes no arguments"},
+		{"serve without name", []string{"serve"}, 2, "usage: runner serve"},
+		{"server failing", []string{"serve", "kvstore", "-addr", "no-port", "-aof", ""}, 1, "runner serve kvstore: listen tcp: address no-port: missing port"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := run(tc.args, &stdout, &stderr); code != tc.wantCode {
+				t.Errorf("exit code = %d; want %d", code, tc.wantCode)
+			}
+			if !strings.Contains(stderr.String(), tc.wantStderr) {
+				t.Errorf("stderr = %q; want it to contain %q", stderr.String(), tc.wantStderr)
+			}
+		})
+	}
+}
+
+func TestRun_ProfileHelp(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"profile", "help"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code = %d; stderr = %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "go tool pprof") {
+		t.Errorf("help output missing pprof instructions:\n%s", stdout.String())
+	}
+}
+
+func TestRun_Profiles(t *testing.T) {
+	for _, kind := range []string{"cpu", "heap"} {
+		t.Run(kind, func(t *testing.T) {
+			out := filepath.Join(t.TempDir(), kind+".out")
+			var stdout, stderr bytes.Buffer
+
+			code := run([]string{"profile", kind, "-o", out, "-n", "5"}, &stdout, &stderr)
+			if code != 0 {
+				t.Fatalf("exit code = %d; stderr = %s", code, stderr.String())
+			}
+			info, err := os.Stat(out)
+			if err != nil || info.Size() == 0 {
+				t.Errorf("profile file %s missing or empty (err = %v)", out, err)
+			}
+			if !strings.Contains(stdout.String(), "wrote "+kind+" profile") {
+				t.Errorf("stdout = %q", stdout.String())
+			}
+		})
+	}
+}
+
+func TestRun_ProfileWriteError(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "missing", "heap.out")
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"profile", "heap", "-o", out, "-n", "1"}, &stdout, &stderr); code != 1 {
+		t.Errorf("exit code = %d; want 1", code)
+	}
+}
+
+func TestRun_ProfileConfigFromEnv(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "from-env.out")
+	t.Setenv("RUNNER_PROFILE_OUTPUT", out)
+	t.Setenv("RUNNER_PROFILE_ITERATIONS", "2")
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"profile", "heap"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code = %d; stderr = %s", code, stderr.String())
+	}
+	if _, err := os.Stat(out); err != nil {
+		t.Errorf("profile not written to the path from the environment: %v", err)
+	}
+}
+
+func TestRun_ProfileFlagBeatsConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	cfgFile := filepath.Join(dir, "runner.yaml")
+	fileOut := filepath.Join(dir, "from-file.out")
+	flagOut := filepath.Join(dir, "from-flag.out")
+	if err := os.WriteFile(cfgFile, []byte("output: "+fileOut+"\niterations: 2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"profile", "heap", "-config", cfgFile, "-o", flagOut}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("exit code = %d; stderr = %s", code, stderr.String())
+	}
+	if _, err := os.Stat(flagOut); err != nil {
+		t.Errorf("flag output missing: %v", err)
+	}
+	if _, err := os.Stat(fileOut); err == nil {
+		t.Error("config file output was written; want the flag to win")
+	}
+}
+
+func TestRun_Help(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"help"}, "profile    capture CPU and heap profiles"},
+		{[]string{"help", "profile"}, "usage: runner profile"},
+	}
+	for _, tc := range tests {
+		var stdout, stderr bytes.Buffer
+		if code := run(tc.args, &stdout, &stderr); code != 0 {
+			t.Errorf("run(%q) exit code = %d; stderr = %s", tc.args, code, stderr.String())
+		}
+		if !strings.Contains(stdout.String(), tc.want) {
+			t.Errorf("run(%q) stdout = %q; want it to contain %q", tc.args, stdout.String(), tc.want)
+		}
+	}
+}
+
+func TestRun_Sort(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantCode   int
+		wantStdout string
+		wantStderr string
+	}{
+		{"default sorter", []string{"sort", "3", "-1", "2"}, 0, "-1 2 3\n", ""},
+		{"chosen sorter", []string{"sort", "-algo", "merge", "5", "4", "4"}, 0, "4 4 5\n", ""},
+		{"no numbers", []string{"sort", "-algo", "insertion"}, 0, "\n", ""},
+		{"list", []string{"sort", "-list"}, 0, "insertion\nmerge\nstd\n", ""},
+		{"unknown sorter", []string{"sort", "-algo", "bogo", "1"}, 2, "", `sorter "bogo" not registered (have [insertion merge std])`},
+		{"not a number", []string{"sort", "1", "two"}, 2, "", `"two" is not an integer`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := run(tc.args, &stdout, &stderr); code != tc.wantCode {
+				t.Errorf("exit code = %d; want %d (stderr: %s)", code, tc.wantCode, stderr.String())
+			}
+			if stdout.String() != tc.wantStdout {
+				t.Errorf("stdout = %q; want %q", stdout.String(), tc.wantStdout)
+			}
+			if !strings.Contains(stderr.String(), tc.wantStderr) {
+				t.Errorf("stderr = %q; want it to contain %q", stderr.String(), tc.wantStderr)
+			}
+		})
+	}
+}
+
+func TestRun_Quiz(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		input      string
+		wantCode   int
+		wantStdout string
+		wantStderr string
+	}{
+		{"list", []string{"quiz", "-list"}, "", 0, "maps                     Maps (", ""},
+		{"scripted", []string{"quiz", "-topic", "maps", "-n", "2", "-seed", "1"}, "a map\ny\nnot sure\nn\n", 0, "-seed=1 asks the same ones again", ""},
+		{"unknown topic", []string{"quiz", "-topic", "mapz"}, "", 2, "", `unknown topic "mapz" (see -list)`},
+		{"bad difficulty", []string{"quiz", "-difficulty", "tricky"}, "", 2, "", `unknown difficulty "tricky"`},
+		{"negative count", []string{"quiz", "-n", "-1"}, "", 2, "", "usage: runner quiz"},
+	}
+	defer func(r io.Reader) { stdin = r }(stdin)
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			stdin = strings.NewReader(tc.input)
+			var stdout, stderr bytes.Buffer
+			if code := run(tc.args, &stdout, &stderr); code != tc.wantCode {
+				t.Errorf("exit code = %d; want %d (stderr: %s)", code, tc.wantCode, stderr.String())
+			}
+			if !strings.Contains(stdout.String(), tc.wantStdout) {
+				t.Errorf("stdout = %q; want it to contain %q", stdout.String(), tc.wantStdout)
+			}
+			if !strings.Contains(stderr.String(), tc.wantStderr) {
+				t.Errorf("stderr = %q; want it to contain %q", stderr.String(), tc.wantStderr)
+			}
+		})
+	}
+}
+
+func TestRun_QuizSeedRepeats(t *testing.T) {
+	defer func(r io.Reader) { stdin = r }(stdin)
+	quiz := func() string {
+		stdin = strings.NewReader(strings.Repeat("x\ny\n", 3))
+		var stdout, stderr bytes.Buffer
+		if code := run([]string{"quiz", "-n", "3", "-seed", "7"}, &stdout, &stderr); code != 0 {
+			t.Fatalf("exit code = %d (stderr: %s)", code, stderr.String())
+		}
+		return stdout.String()
+	}
+	first := quiz()
+	if !strings.Contains(first, "Score: 3/3 (100%)") {
+		t.Errorf("output lacks the score:\n%s", first)
+	}
+	if second := quiz(); second != first {
+		t.Errorf("-seed 7 asked different questions:\n%s\nthen\n%s", first, second)
+	}
+}
+
+func TestRun_Judge(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantCode   int
+		wantStdout string
+		wantStderr string
+	}{
+		{"list", []string{"judge", "-list"}, 0, "twosum           Find two numbers adding up to a target\n                 func TwoSum(nums []int, target int) []int\n", ""},
+		{"no exercise", []string{"judge"}, 2, "", "usage: runner judge"},
+		{"unknown exercise", []string{"judge", "fizzbuzz"}, 2, "", `unknown exercise "fizzbuzz" (see -list)`},
+		{"no solution", []string{"judge", "-dir", t.TempDir(), "reverse"}, 1, "", "no Go files in"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := run(tc.args, &stdout, &stderr); code != tc.wantCode {
+				t.Errorf("exit code = %d; want %d (stderr: %s)", code, tc.wantCode, stderr.String())
+			}
+			if !strings.Contains(stdout.String(), tc.wantStdout) {
+				t.Errorf("stdout = %q; want it to contain %q", stdout.String(), tc.wantStdout)
+			}
+			if !strings.Contains(stderr.String(), tc.wantStderr) {
+				t.Errorf("stderr = %q; want it to contain %q", stderr.String(), tc.wantStderr)
+			}
+		})
+	}
+}
+
+func TestRun_JudgeStub(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a program")
+	}
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"judge", "-dir", "../../exercises/reverse", "reverse"}, &stdout, &stderr); code != 1 {
+		t.Errorf("exit code = %d; want 1 (stderr: %s)", code, stderr.String())
+	}
+	for _, want := range []string{"reverse: Reverse a string\n", `input "hello": panic: not implemented`, "\n0/7 passed\n"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("stdout lacks %q:\n%s", want, stdout.String())
+		}
+	}
+}
+
+func TestRun_Perf(t *testing.T) {
+	dir := t.TempDir()
+	baseline := filepath.Join(dir, "baseline.json")
+	write := func(name, output string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(output), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	before := write("before.txt", "BenchmarkConcat/Builder-8  1000000  150 ns/op  248 B/op  5 allocs/op\n")
+	slower := write("slower.txt", "BenchmarkConcat/Builder-8  1000000  160 ns/op  248 B/op  5 allocs/op\n")
+	allocating := write("allocating.txt", "BenchmarkConcat/Builder-8  1000000  150 ns/op  256 B/op  6 allocs/op\n")
+	empty := write("empty.txt", "PASS\n")
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantCode   int
+		wantStdout string
+		wantStderr string
+	}{
+		{"no baseline", []string{"perf", "-input", before, "-baseline", baseline}, 1, "", "create it with -update"},
+		{"update", []string{"perf", "-input", before, "-baseline", baseline, "-update"}, 0, "wrote 1 benchmarks to " + baseline, ""},
+		{"same", []string{"perf", "-input", before, "-baseline", baseline}, 0, "ok: no regressions", ""},
+		{"slower within tolerance", []string{"perf", "-input", slower, "-baseline", baseline}, 0, "ns/op 150 -> 160 (+6.7%)", ""},
+		{"slower beyond tolerance", []string{"perf", "-input", slower, "-baseline", baseline, "-time-tolerance", "5%"}, 1, "REGRESSED: ns/op", ""},
+		{"more allocations", []string{"perf", "-input", allocating, "-baseline", baseline}, 1, "REGRESSED: B/op, allocs/op", ""},
+		{"allocations tolerated", []string{"perf", "-input", allocating, "-baseline", baseline, "-bytes-tolerance", "5", "-alloc-tolerance", "25"}, 0, "ok: no regressions", ""},
+		{"no benchmarks", []string{"perf", "-input", empty, "-baseline", baseline}, 1, "", `no benchmarks matched "."`},
+		{"bad tolerance", []string{"perf", "-time-tolerance", "-3"}, 2, "", "want a percentage of at least 0"},
+		{"bad count", []string{"perf", "-count", "0"}, 2, "", "-count must be at least 1"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := run(tc.args, &stdout, &stderr); code != tc.wantCode {
+				t.Errorf("exit code = %d; want %d (stderr: %s)", code, tc.wantCode, stderr.String())
+			}
+			if !strings.Contains(stdout.String(), tc.wantStdout) {
+				t.Errorf("stdout = %q; want it to contain %q", stdout.String(), tc.wantStdout)
+			}
+			if !strings.Contains(stderr.String(), tc.wantStderr) {
+				t.Errorf("stderr = %q; want it to contain %q", stderr.String(), tc.wantStderr)
+			}
+		})
+	}
+}
+
+func TestRun_PerfRunsBenchmarks(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go test")
+	}
+	baseline := filepath.Join(t.TempDir(), "baseline.json")
+	perf := func(flags ...string) (int, string, string) {
+		args := append([]string{"perf", "-bench", "Escape", "-benchtime", "100x", "-count", "1", "-baseline", baseline}, flags...)
+		var stdout, stderr bytes.Buffer
+		code := run(append(args, "../../basic-concepts/perf/"), &stdout, &stderr)
+		return code, stdout.String(), stderr.String()
+	}
+	if code, stdout, stderr := perf("-update"); code != 0 || !strings.Contains(stdout, "wrote 2 benchmarks") {
+		t.Fatalf("perf -update = %d, %q (stderr: %s); want the 2 Escape benchmarks written", code, stdout, stderr)
+	}
+	// Times vary from run to run; allocations do not
+	if code, stdout, stderr := perf("-time-tolerance", "100000"); code != 0 {
+		t.Errorf("perf exit code = %d (stdout: %s, stderr: %s)", code, stdout, stderr)
+	}
+}
+
+func TestRun_ExercisesVerify(t *testing.T) {
+	dir := t.TempDir()
+	profile := func(name, count string) string {
+		path := filepath.Join(dir, name)
+		data := "mode: set\ngithub.com/rehan/go-interview-prep/exercises/reverse/reverse.go:7.2,7.26 1 " + count + "\n"
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	untested, tested := profile("untested.out", "0"), profile("tested.out", "1")
+	const stub = "../../exercises/reverse"
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantCode   int
+		wantStdout string
+		wantStderr string
+	}{
+		{"untested", []string{"exercises", "verify", "-profile", untested, stub}, 1, "not tested:\n  reverse.go:6     Reverse\n", ""},
+		{"next", []string{"exercises", "verify", "-profile", untested, stub}, 1, "next: write a test that calls Reverse\n", ""},
+		{"tested", []string{"exercises", "verify", "-profile", tested, stub}, 0, "1/1 statements covered (100.0%)", ""},
+		{"all at min", []string{"exercises", "verify", "-profile", tested, "-min", "100", stub}, 0, "ok: none below 100%\n", ""},
+		{"bad profile", []string{"exercises", "verify", "-profile", filepath.Join(dir, "none.out"), stub}, 1, "", "none.out"},
+		{"not a package", []string{"exercises", "verify", "-profile", tested, dir}, 1, "", "go list " + dir},
+		{"bad min", []string{"exercises", "verify", "-min", "120", stub}, 2, "", "-min must be from 0 to 100"},
+		{"no package", []string{"exercises", "verify"}, 2, "", "usage: runner exercises verify"},
+		{"help", []string{"help", "exercises"}, 0, "verify  run a package's tests", ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := run(tc.args, &stdout, &stderr); code != tc.wantCode {
+				t.Errorf("exit code = %d; want %d (stderr: %s)", code, tc.wantCode, stderr.String())
+			}
+			if !strings.Contains(stdout.String(), tc.wantStdout) {
+				t.Errorf("stdout = %q; want it to contain %q", stdout.String(), tc.wantStdout)
+			}
+			if !strings.Contains(stderr.String(), tc.wantStderr) {
+				t.Errorf("stderr = %q; want it to contain %q", stderr.String(), tc.wantStderr)
+			}
+		})
+	}
+}
+
+func TestRun_ExercisesVerifyRunsTests(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go test")
+	}
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"exercises", "verify", "-min", "50", "../../pkg/coverage"}, &stdout, &stderr)
+	if code != 0 {
+		t.Errorf("exit code = %d; want 0 (stdout: %s, stderr: %s)", code, stdout.String(), stderr.String())
+	}
+	if want := "pkg/coverage: 3 exported functions"; !strings.Contains(stdout.String(), want) {
+		t.Errorf("stdout lacks %q:\n%s", want, stdout.String())
+	}
+}
+
+func TestRun_Stress(t *testing.T) {
+	tests := []struct {
+		args       []string
+		wantStderr string
+	}{
+		{[]string{"stress", "-duration", "0s"}, "-duration must be positive"},
+		{[]string{"stress", "-duration", "soon"}, "usage: runner stress"},
+	}
+	for _, tc := range tests {
+		var stdout, stderr bytes.Buffer
+		if code := run(tc.args, &stdout, &stderr); code != 2 || !strings.Contains(stderr.String(), tc.wantStderr) {
+			t.Errorf("run(%q) = %d, stderr %q; want 2 and %q", tc.args, code, stderr.String(), tc.wantStderr)
+		}
+	}
+}
+
+func TestRun_StressRunsTests(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go test")
+	}
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"stress", "-duration", "100ms", "-seed", "5", "../../pkg/shardmap/"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("exit code = %d; want 0 (stdout: %s, stderr: %s)", code, stdout.String(), stderr.String())
+	}
+	for _, want := range []string{"stress: 1000 goroutines for 100ms, STRESS_SEED=5", "--- PASS: TestStress_Map"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("stdout lacks %q:\n%s", want, stdout.String())
+		}
+	}
+}
+
+// lockedBuffer is a bytes.Buffer that the goroutines of a demo can write to
+// at once
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestRun_Demos(t *testing.T) {
+	topics, err := quiz.Topics()
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := map[string][]string{
+		"gc-tuning":               {"-requests", "2000", "-live-mb", "4"},
+		"goroutines-and-channels": {"-seed", "42"},
+	}
+	wants := map[string]string{"goroutines-and-channels": "-seed=42 gives the workers the same delays again"}
+	skip := make(map[string]string)
+	if testing.Short() {
+		skip["context-package"] = "waits for its examples' deadlines"
+	}
+	if raceEnabled {
+		skip["sync-package"] = "races on purpose, to show what a mutex prevents"
+	}
+	for _, d := range demos {
+		t.Run(d.Name, func(t *testing.T) {
+			if why := skip[d.Name]; why != "" {
+				t.Skip(why)
+			}
+			var stdout, stderr lockedBuffer
+			if code := run(append([]string{"demo", d.Name}, args[d.Name]...), &stdout, &stderr); code != 0 {
+				t.Fatalf("exit code = %d; stderr = %s", code, stderr.String())
+			}
+			out := stdout.String()
+			if !strings.HasPrefix(out, "===") {
+				t.Errorf("output does not start with a banner:\n%.200s", out)
+			}
+			if want := wants[d.Name]; !strings.Contains(out, want) {
+				t.Errorf("output lacks %q:\n%.400s", want, out)
+			}
+			// Demos with a quiz topic end with all its questions
+			i := slices.IndexFunc(topics, func(topic quiz.Topic) bool { return topic.ID == d.Name })
+			if i < 0 {
+				return
+			}
+			if !strings.Contains(out, "COMMON INTERVIEW QUESTIONS:") {
+				t.Errorf("output lacks the interview questions:\n%s", out)
+			}
+			for _, q := range topics[i].Questions {
+				if !strings.Contains(out, q.Question) {
+					t.Errorf("output lacks the question %q", q.Question)
+				}
+			}
+		})
+	}
+}
+
+func TestRun_DemoHelp(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"help", "demo"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code = %d; stderr = %s", code, stderr.String())
+	}
+	for _, d := range demos {
+		if !strings.Contains(stdout.String(), "  "+d.Name+" ") {
+			t.Errorf("help lacks the %s demo:\n%s", d.Name, stdout.String())
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/rehan/go-interview-prep/basic-concepts/cli/command"
+	"github.com/rehan/go-interview-prep/pkg/benchdiff"
+)
+
+const perfUsage = `usage: runner perf [flags] [packages]
+
+Runs benchmarks with -benchmem and compares them with a JSON baseline,
+failing when a benchmark's time, bytes or allocations per op grew by more
+than the tolerance. -update saves the run as the baseline instead. The
+packages default to ./basic-concepts/perf/. Needs the go command, unless
+-input gives output already captured.
+
+  -bench regexp          the benchmarks to run (default .)
+  -count n               runs of each, summarized by their median (default 5)
+  -benchtime d           go test -benchtime, a duration or Nx (default 1s)
+  -baseline file         the baseline (default perf-baseline.json)
+  -update                write the baseline rather than compare with it
+  -time-tolerance pct    growth in ns/op allowed, in percent (default 10)
+  -bytes-tolerance pct   growth in B/op allowed (default 0)
+  -alloc-tolerance pct   growth in allocs/op allowed (default 0)
+  -input file            read go test -bench output from file, - for stdin
+`
+
+func runPerf(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("perf", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { fmt.Fprint(stderr, perfUsage) }
+	bench := fs.String("bench", ".", "benchmarks to run")
+	count := fs.Int("count", 5, "runs of each benchmark")
+	benchtime := fs.String("benchtime", "1s", "go test -benchtime")
+	baseline := fs.String("baseline", "perf-baseline.json", "baseline file")
+	update := fs.Bool("update", false, "write the baseline")
+	tol := benchdiff.Tolerance{Time: 0.10}
+	fs.Func("time-tolerance", "ns/op growth allowed, in percent", percent(&tol.Time))
+	fs.Func("bytes-tolerance", "B/op growth allowed, in percent", percent(&tol.Bytes))
+	fs.Func("alloc-tolerance", "allocs/op growth allowed, in percent", percent(&tol.Allocs))
+	input := fs.String("input", "", "go test -bench output to read")
+	if err := fs.Parse(args); err != nil {
+		return command.ExitUsage
+	}
+	if *count < 1 {
+		fmt.Fprintln(stderr, "runner perf: -count must be at least 1")
+		return command.ExitUsage
+	}
+	pkgs := fs.Args()
+	if len(pkgs) == 0 {
+		pkgs = []string{"./basic-concepts/perf/"}
+	}
+
+	var out []byte
+	var err error
+	switch *input {
+	case "":
+		out, err = runBenchmarks(pkgs, *bench, *benchtime, *count, stderr)
+	case "-":
+		out, err = io.ReadAll(os.Stdin)
+	default:
+		out, err = os.ReadFile(*input)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "runner perf: %v\n", err)
+		return command.ExitError
+	}
+	results, err := benchdiff.Parse(bytes.NewReader(out))
+	if err == nil && len(results) == 0 {
+		err = fmt.Errorf("no benchmarks matched %q", *bench)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "runner perf: %v\n", err)
+		return command.ExitError
+	}
+
+	if *update {
+		if err := benchdiff.WriteBaseline(*baseline, benchdiff.NewBaseline(results)); err != nil {
+			fmt.Fprintf(stderr, "runner perf: %v\n", err)
+			return command.ExitError
+		}
+		fmt.Fprintf(stdout, "wrote %d benchmarks to %s\n", len(results), *baseline)
+		return command.ExitOK
+	}
+
+	base, err := benchdiff.ReadBaseline(*baseline)
+	if errors.Is(err, os.ErrNotExist) {
+		err = fmt.Errorf("%w; create it with -update", err)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "runner perf: %v\n", err)
+		return command.ExitError
+	}
+	current := benchdiff.NewBaseline(nil)
+	if base.GOOS != current.GOOS || base.GOARCH != current.GOARCH {
+		fmt.Fprintf(stderr, "runner perf: warning: the baseline is from %s/%s, this is %s/%s\n", base.GOOS, base.GOARCH, current.GOOS, current.GOARCH)
+	}
+
+	report := benchdiff.Compare(base.Benchmarks, results, tol)
+	report.Write(stdout)
+	if report.Regressed() {
+		fmt.Fprintln(stdout, "\nFAIL: benchmarks regressed")
+		return command.ExitError
+	}
+	fmt.Fprintln(stdout, "\nok: no regressions")
+	return command.ExitOK
+}
+
+// percent parses a flag in percent, such as 10 or 10%, into a fraction
+func percent(dst *float64) func(string) error {
+	return func(s string) error {
+		var pct float64
+		if _, err := fmt.Sscan(strings.TrimSuffix(s, "%"), &pct); err != nil || pct < 0 {
+			return fmt.Errorf("want a percentage of at least 0, such as 10")
+		}
+		*dst = pct / 100
+		return nil
+	}
+}
+
+// runBenchmarks runs only the benchmarks of pkgs matching bench and returns
+// go test's output. If the tests fail the output goes to stderr.
+func runBenchmarks(pkgs []string, bench, benchtime string, count int, stderr io.Writer) ([]byte, error) {
+	args := append([]string{"test", "-run=^$", "-bench=" + bench, "-benchmem",
+		"-benchtime=" + benchtime, fmt.Sprintf("-count=%d", count)}, pkgs...)
+	out, err := exec.Command("go", args...).CombinedOutput()
+	if err != nil {
+		stderr.Write(out)
+		return nil, fmt.Errorf("go test: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"strings"
+
+	"github.com/rehan/go-interview-prep/basic-concepts/cli/command"
+	"github.com/rehan/go-interview-prep/pkg/quiz"
+)
+
+const quizUsage = `usage: runner quiz [-topic ids] [-difficulty level] [-n count] [-seed n] [-list]
+
+Asks interview questions from pkg/quiz in random order. Type your answer,
+compare it with the points a good one makes and say whether you got it
+right; q stops early. The score is shown at the end, per topic too.
+
+  -topic ids         comma-separated topic IDs to ask about (default all)
+  -difficulty level  only easy, medium or hard questions (default any)
+  -n count           how many questions to ask (default 10; 0 asks all)
+  -seed n            shuffle with this seed, to get the same questions again
+  -list              print the topics and exit
+`
+
+// stdin is where the quiz reads answers; tests replace it
+var stdin io.Reader = os.Stdin
+
+func runQuiz(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("quiz", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { fmt.Fprint(stderr, quizUsage) }
+	topicList := fs.String("topic", "", "comma-separated topic IDs")
+	level := fs.String("difficulty", "", "easy, medium or hard")
+	count := fs.Int("n", 10, "how many questions to ask")
+	seed := fs.Uint64("seed", 0, "shuffle seed")
+	list := fs.Bool("list", false, "print the topics")
+	if err := fs.Parse(args); err != nil {
+		return command.ExitUsage
+	}
+	if fs.NArg() > 0 || *count < 0 {
+		fs.Usage()
+		return command.ExitUsage
+	}
+
+	topics, err := quiz.Topics()
+	if err != nil {
+		fmt.Fprintf(stderr, "runner quiz: %v\n", err)
+		return command.ExitError
+	}
+	if *list {
+		for _, t := range topics {
+			fmt.Fprintf(stdout, "%-24s %s (%d questions)\n", t.ID, t.Title, len(t.Questions))
+		}
+		return command.ExitOK
+	}
+
+	var filter quiz.Filter
+	if *topicList != "" {
+		filter.Topics = strings.Split(*topicList, ",")
+	}
+	if *level != "" {
+		if filter.Difficulty, err = quiz.ParseDifficulty(*level); err != nil {
+			fmt.Fprintf(stderr, "runner quiz: %v\n", err)
+			return command.ExitUsage
+		}
+	}
+	questions, err := quiz.Select(topics, filter)
+	if err != nil {
+		fmt.Fprintf(stderr, "runner quiz: %v (see -list)\n", err)
+		return command.ExitUsage
+	}
+	if len(questions) == 0 {
+		fmt.Fprintln(stderr, "runner quiz: no questions match")
+		return command.ExitUsage
+	}
+
+	// A random seed is printed so that a quiz can be taken again
+	if !isFlagSet(fs, "seed") {
+		*seed = rand.Uint64()
+	}
+	fmt.Fprintf(stdout, "%d questions to choose from; -seed=%d asks the same ones again\n", len(questions), *seed)
+
+	_, err = quiz.Run(stdin, stdout, questions, quiz.Options{Count: *count, Rand: rand.New(rand.NewPCG(*seed, 0))})
+	if err != nil {
+		fmt.Fprintf(stderr, "runner quiz: %v\n", err)
+		return command.ExitError
+	}
+	return command.ExitOK
+}
+
+// isFlagSet reports whether the flag name was given on the command line
+func isFlagSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) { set = set || f.Name == name })
+	return set
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/rehan/go-interview-prep/basic-concepts/cli/command"
+	"github.com/rehan/go-interview-prep/mini-projects/jsonrpc"
+	"github.com/rehan/go-interview-prep/mini-projects/kvstore"
+	"github.com/rehan/go-interview-prep/mini-projects/quizserver"
+	restapi "github.com/rehan/go-interview-prep/mini-projects/rest_api"
+)
+
+const serveAbout = `Runs one of the mini-projects' servers until Ctrl-C or SIGTERM. The
+arguments after the name are the server's own; "-h" lists them, and the
+end of each project's main.go has examples to try. Build with
+-tags filestore for rest-api to keep its books in a file.
+`
+
+// newServers lists the servers in mini-projects
+func newServers() *command.Dispatcher {
+	return command.New("runner serve",
+		server("jsonrpc", "JSON-RPC 2.0 book service over TCP, and a client for it", jsonrpc.Run),
+		server("kvstore", "Redis-like key-value store over TCP with an append-only log", kvstore.Run),
+		server("quizserver", "the interview questions as a JSON API and web page", quizserver.Run),
+		server("rest-api", "the book store REST API; \"seed\" fills its store", restapi.Run),
+	)
+}
+
+// server makes a command of a server's run function, which gets the
+// arguments and stops with an error, or nil on a signal
+func server(name, summary string, run func(args []string) error) *command.Command {
+	return &command.Command{
+		Name:    name,
+		Summary: summary,
+		Run: func(args []string, stdout, stderr io.Writer) int {
+			err := run(args)
+			if err == nil || errors.Is(err, flag.ErrHelp) {
+				return command.ExitOK
+			}
+			fmt.Fprintf(stderr, "runner serve %s: %v\n", name, err)
+			return command.ExitError
+		},
+	}
+}
+
+func runServe(args []string, stdout, stderr io.Writer) int {
+	return newServers().Run(args, stdout, stderr)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/rehan/go-interview-prep/basic-concepts/cli/command"
+	"github.com/rehan/go-interview-prep/examples/registry/sorter"
+
+	// Each import registers a sorter in its init function; the runner only
+	// ever refers to them by name. Delete a line and that name disappears
+	// from -list.
+	_ "github.com/rehan/go-interview-prep/examples/registry/sorter/insertion"
+	_ "github.com/rehan/go-interview-prep/examples/registry/sorter/merge"
+	_ "github.com/rehan/go-interview-prep/examples/registry/sorter/stdsort"
+)
+
+const sortUsage = `usage: runner sort [-algo name] [-list] <numbers...>
+
+Sorts integers with a sorter chosen by name from those registered through
+examples/registry/sorter.
+
+  -algo name  sorter to use (default "std")
+  -list       print the registered sorter names and exit
+`
+
+func runSort(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("sort", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { fmt.Fprint(stderr, sortUsage) }
+	algo := fs.String("algo", "std", "sorter to use")
+	list := fs.Bool("list", false, "print the registered sorter names")
+	if err := fs.Parse(args); err != nil {
+		return command.ExitUsage
+	}
+
+	if *list {
+		fmt.Fprintln(stdout, strings.Join(sorter.Names(), "\n"))
+		return command.ExitOK
+	}
+
+	s, err := sorter.Lookup(*algo)
+	if err != nil {
+		fmt.Fprintf(stderr, "runner sort: %v\n", err)
+		return command.ExitUsage
+	}
+
+	values := make([]int, fs.NArg())
+	for i, arg := range fs.Args() {
+		if values[i], err = strconv.Atoi(arg); err != nil {
+			fmt.Fprintf(stderr, "runner sort: %q is not an integer\n", arg)
+			return command.ExitUsage
+		}
+	}
+
+	s.Sort(values)
+	fmt.Fprintln(stdout, strings.Trim(fmt.Sprint(values), "[]"))
+	return command.ExitOK
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"github.com/rehan/go-interview-prep/basic-concepts/cli/command"
+	"github.com/rehan/go-interview-prep/pkg/testutil/stress"
+)
+
+const stressUsage = `usage: runner stress [flags] [packages]
+
+Runs the stress tests, built only with -tags stress, in which a thousand
+goroutines hammer a concurrent package with random operations and check
+its invariants. The packages default to the sharded map, the worker pool
+and the pub/sub bus. A failure prints the seed; -seed repeats its random
+operations, though not the goroutines' interleaving. Needs the go command.
+
+  -duration d   how long each test runs (default 10s)
+  -seed n       the random seed (default a new one each run)
+  -race         also run the race detector, slower but thorough
+  -run regexp   the tests to run (default Stress)
+`
+
+// stressPackages are the packages with stress tests
+var stressPackages = []string{"./pkg/shardmap/", "./pkg/workerpool/", "./pkg/pubsub/"}
+
+func runStress(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("stress", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { fmt.Fprint(stderr, stressUsage) }
+	duration := fs.Duration("duration", 10*time.Second, "how long each test runs")
+	seed := fs.Uint64("seed", 0, "random seed")
+	race := fs.Bool("race", false, "run the race detector")
+	runTests := fs.String("run", "Stress", "tests to run")
+	if err := fs.Parse(args); err != nil {
+		return command.ExitUsage
+	}
+	if *duration <= 0 {
+		fmt.Fprintln(stderr, "runner stress: -duration must be positive")
+		return command.ExitUsage
+	}
+	pkgs := fs.Args()
+	if len(pkgs) == 0 {
+		pkgs = stressPackages
+	}
+
+	// Give the tests of a package their time and then some before go test
+	// decides one hangs
+	testArgs := []string{"test", "-tags", "stress", "-run", *runTests, "-count=1", "-v",
+		"-timeout", (10**duration + 5*time.Minute).String()}
+	if *race {
+		testArgs = append(testArgs, "-race")
+	}
+	cmd := exec.Command("go", append(testArgs, pkgs...)...)
+	cmd.Env = append(os.Environ(), stress.DurationEnvVar+"="+duration.String())
+	if *seed != 0 {
+		cmd.Env = append(cmd.Env, stress.SeedEnvVar+"="+strconv.FormatUint(*seed, 10))
+	}
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Fprintf(stderr, "runner stress: go test: %v\n", err)
+		return command.ExitError
+	}
+	return command.ExitOK
+}
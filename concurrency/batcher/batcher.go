@@ -0,0 +1,200 @@
+package batcher
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/clock"
+)
+
+// ErrClosed is returned by Add once the batcher has been closed
+var ErrClosed = errors.New("batcher: closed")
+
+// Batcher accumulates items and hands them to a flush function in batches.
+// A batch is flushed when it reaches maxSize items or when timeout has
+// elapsed since the first item of the batch arrived, whichever comes first.
+type Batcher[T any] struct {
+	maxSize int
+	timeout time.Duration
+	flush   func([]T)
+	clock   clock.Clock
+
+	items chan T
+	quit  chan struct{}
+	done  chan struct{}
+
+	startOnce sync.Once
+	closeOnce sync.Once
+}
+
+// Defaults used when the corresponding option is not given
+const (
+	DefaultMaxSize = 100
+	DefaultTimeout = time.Second
+)
+
+// config holds the settings that options can change. It is not generic, so
+// options can be written without naming the item type.
+type config struct {
+	maxSize int
+	timeout time.Duration
+	clock   clock.Clock
+}
+
+// Option configures a Batcher
+type Option func(*config)
+
+// WithMaxSize flushes a batch once it holds n items; values below 1 are
+// treated as 1
+func WithMaxSize(n int) Option {
+	return func(c *config) {
+		if n < 1 {
+			n = 1
+		}
+		c.maxSize = n
+	}
+}
+
+// WithTimeout flushes a batch once d has elapsed since its first item
+func WithTimeout(d time.Duration) Option {
+	return func(c *config) {
+		c.timeout = d
+	}
+}
+
+// WithClock times batches with c instead of the system clock, so tests
+// can fire the timeout with a clock.Fake rather than wait for it
+func WithClock(c clock.Clock) Option {
+	return func(cfg *config) {
+		cfg.clock = c
+	}
+}
+
+// New creates a Batcher that calls flush with at most DefaultMaxSize items at
+// a time unless configured otherwise. The flush function is always called
+// from a single goroutine, so it does not need to be safe for concurrent use.
+func New[T any](flush func([]T), opts ...Option) *Batcher[T] {
+	cfg := config{maxSize: DefaultMaxSize, timeout: DefaultTimeout, clock: clock.Real}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &Batcher[T]{
+		maxSize: cfg.maxSize,
+		timeout: cfg.timeout,
+		flush:   flush,
+		clock:   cfg.clock,
+		items:   make(chan T),
+		quit:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// start launches the run loop on first use
+func (b *Batcher[T]) start() {
+	b.startOnce.Do(func() {
+		go b.run()
+	})
+}
+
+// Add queues an item for the next batch.
+// It blocks until the run loop has accepted the item, so any item for which
+// Add returned nil is guaranteed to be flushed, at the latest by Close.
+// An Add that is still blocked when Close is called returns ErrClosed, and
+// its item is not flushed.
+func (b *Batcher[T]) Add(item T) error {
+	b.start()
+	select {
+	case <-b.quit:
+		return ErrClosed
+	default:
+	}
+
+	select {
+	case b.items <- item:
+		return nil
+	case <-b.quit:
+		return ErrClosed
+	}
+}
+
+// Close stops the batcher, flushing any pending items before it returns.
+// It is safe to call Close more than once.
+func (b *Batcher[T]) Close() {
+	b.start()
+	b.closeOnce.Do(func() {
+		close(b.quit)
+	})
+	<-b.done
+}
+
+// run owns the pending batch and the timer; no locking is needed because
+// every state change happens on this goroutine
+func (b *Batcher[T]) run() {
+	defer close(b.done)
+
+	var (
+		pending []T
+		tm      clock.Timer
+		timeout <-chan time.Time // nil while there is no pending batch
+	)
+
+	flush := func() {
+		if tm != nil {
+			tm.Stop()
+			tm, timeout = nil, nil
+		}
+		if len(pending) == 0 {
+			return
+		}
+		batch := pending
+		pending = make([]T, 0, b.maxSize)
+		b.flush(batch)
+	}
+
+	for {
+		select {
+		case item := <-b.items:
+			pending = append(pending, item)
+			if len(pending) >= b.maxSize {
+				flush()
+			} else if tm == nil {
+				// First item of a new batch starts the clock
+				tm = b.clock.NewTimer(b.timeout)
+				timeout = tm.C()
+			}
+
+		case <-timeout:
+			tm, timeout = nil, nil
+			flush()
+
+		case <-b.quit:
+			flush()
+			return
+		}
+	}
+}
+
+/*
+Common Interview Questions about batching:
+
+1. Why batch work at all?
+   - Amortizes per-call overhead (network round trips, syscalls, DB transactions)
+   - Trades a bounded amount of latency for much higher throughput
+
+2. Why flush on both size and time?
+   - Size alone can leave a half-full batch waiting forever under low traffic
+   - Time alone can build unbounded batches under high traffic
+
+3. Why does the timer start at the first item rather than on a fixed tick?
+   - It bounds the latency of every item to the timeout
+   - An idle batcher does no work at all
+
+4. How do you avoid losing items on shutdown?
+   - Close signals the run loop, which flushes the pending batch before exiting
+   - Add uses an unbuffered channel, so an accepted item is already owned by the loop
+
+5. Why is a nil channel useful in the select loop?
+   - Receiving from a nil channel blocks forever, which disables the timeout case
+     while there is no pending batch
+*/
@@ -0,0 +1,260 @@
+package batcher
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/clock"
+)
+
+// recorder collects flushed batches from the run loop
+type recorder[T any] struct {
+	mu      sync.Mutex
+	batches [][]T
+	flushed chan struct{}
+}
+
+func newRecorder[T any]() *recorder[T] {
+	return &recorder[T]{flushed: make(chan struct{}, 100)}
+}
+
+func (r *recorder[T]) flush(batch []T) {
+	r.mu.Lock()
+	r.batches = append(r.batches, batch)
+	r.mu.Unlock()
+
+	// Never block the run loop if nobody is waiting for this flush
+	select {
+	case r.flushed <- struct{}{}:
+	default:
+	}
+}
+
+func (r *recorder[T]) get() [][]T {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([][]T(nil), r.batches...)
+}
+
+func (r *recorder[T]) waitFlush(t *testing.T) {
+	t.Helper()
+	select {
+	case <-r.flushed:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for flush")
+	}
+}
+
+// mustAdd fails the test immediately if Add is rejected
+func mustAdd[T any](t *testing.T, b *Batcher[T], item T) {
+	t.Helper()
+	if err := b.Add(item); err != nil {
+		t.Fatalf("Add(%v) returned error: %v", item, err)
+	}
+}
+
+// newTestBatcher returns a batcher with an hour's timeout on a fake clock
+func newTestBatcher[T any](size int, rec *recorder[T]) (*Batcher[T], *clock.Fake) {
+	c := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	return New(rec.flush, WithMaxSize(size), WithTimeout(time.Hour), WithClock(c)), c
+}
+
+func TestBatcher_FlushOnSize(t *testing.T) {
+	rec := newRecorder[int]()
+	b, _ := newTestBatcher(3, rec)
+	defer b.Close()
+
+	for i := 1; i <= 6; i++ {
+		mustAdd(t, b, i)
+	}
+	rec.waitFlush(t)
+	rec.waitFlush(t)
+
+	want := [][]int{{1, 2, 3}, {4, 5, 6}}
+	if got := rec.get(); !reflect.DeepEqual(got, want) {
+		t.Errorf("batches = %v; want %v", got, want)
+	}
+}
+
+func TestBatcher_FlushOnTimeout(t *testing.T) {
+	rec := newRecorder[string]()
+	b, c := newTestBatcher(10, rec)
+	defer b.Close()
+
+	mustAdd(t, b, "a")
+	c.BlockUntil(1)
+	c.Advance(30 * time.Minute)
+	mustAdd(t, b, "b")
+
+	// Only the first item of a batch starts a timer, so the hour is up
+	// half an hour after "b"
+	if n := c.Timers(); n != 1 {
+		t.Fatalf("%d timers for one batch; want 1", n)
+	}
+	c.Advance(30*time.Minute - time.Nanosecond)
+	if got := rec.get(); len(got) != 0 {
+		t.Fatalf("flushed before timeout: %v", got)
+	}
+	c.Advance(time.Nanosecond)
+	rec.waitFlush(t)
+
+	want := [][]string{{"a", "b"}}
+	if got := rec.get(); !reflect.DeepEqual(got, want) {
+		t.Errorf("batches = %v; want %v", got, want)
+	}
+
+	// The next item starts a fresh timer
+	mustAdd(t, b, "c")
+	c.BlockUntil(1)
+	c.Advance(time.Hour)
+	rec.waitFlush(t)
+
+	want = append(want, []string{"c"})
+	if got := rec.get(); !reflect.DeepEqual(got, want) {
+		t.Errorf("batches = %v; want %v", got, want)
+	}
+}
+
+func TestBatcher_SizeFlushStopsTimer(t *testing.T) {
+	rec := newRecorder[int]()
+	b, c := newTestBatcher(2, rec)
+	defer b.Close()
+
+	mustAdd(t, b, 1)
+	c.BlockUntil(1)
+	mustAdd(t, b, 2)
+	rec.waitFlush(t)
+
+	if n := c.Timers(); n != 0 {
+		t.Errorf("%d timers left after a size-triggered flush; want the batch's stopped", n)
+	}
+}
+
+func TestBatcher_CloseFlushesPending(t *testing.T) {
+	rec := newRecorder[int]()
+	b, _ := newTestBatcher(10, rec)
+
+	mustAdd(t, b, 1)
+	mustAdd(t, b, 2)
+	b.Close()
+
+	want := [][]int{{1, 2}}
+	if got := rec.get(); !reflect.DeepEqual(got, want) {
+		t.Errorf("batches = %v; want %v", got, want)
+	}
+
+	if err := b.Add(3); !errors.Is(err, ErrClosed) {
+		t.Errorf("Add after Close returned %v; want ErrClosed", err)
+	}
+
+	// Closing twice must not panic or flush again
+	b.Close()
+	if got := rec.get(); len(got) != 1 {
+		t.Errorf("expected 1 batch after second Close, got %d", len(got))
+	}
+}
+
+func TestBatcher_CloseWithoutItems(t *testing.T) {
+	rec := newRecorder[int]()
+	b, _ := newTestBatcher(10, rec)
+	b.Close()
+
+	if got := rec.get(); len(got) != 0 {
+		t.Errorf("expected no flush for an empty batcher, got %v", got)
+	}
+}
+
+func TestBatcher_ConcurrentAdd(t *testing.T) {
+	rec := newRecorder[int]()
+	b, _ := newTestBatcher(7, rec)
+
+	const producers, perProducer = 10, 100
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perProducer; i++ {
+				// t.Fatal must not be called from a non-test goroutine
+				if err := b.Add(i); err != nil {
+					t.Errorf("Add(%d) returned error: %v", i, err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	b.Close()
+
+	total := 0
+	for _, batch := range rec.get() {
+		if len(batch) > 7 {
+			t.Errorf("batch of %d items exceeds max size 7", len(batch))
+		}
+		total += len(batch)
+	}
+	if total != producers*perProducer {
+		t.Errorf("flushed %d items; want %d", total, producers*perProducer)
+	}
+}
+
+func TestNew_Options(t *testing.T) {
+	flush := func([]int) {}
+	tests := []struct {
+		name        string
+		opts        []Option
+		wantSize    int
+		wantTimeout time.Duration
+	}{
+		{"defaults", nil, DefaultMaxSize, DefaultTimeout},
+		{"max size", []Option{WithMaxSize(5)}, 5, DefaultTimeout},
+		{"max size below 1", []Option{WithMaxSize(0)}, 1, DefaultTimeout},
+		{"timeout", []Option{WithTimeout(time.Minute)}, DefaultMaxSize, time.Minute},
+		{"later option wins", []Option{WithMaxSize(5), WithMaxSize(9)}, 9, DefaultTimeout},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			b := New(flush, tc.opts...)
+			if b.maxSize != tc.wantSize || b.timeout != tc.wantTimeout {
+				t.Errorf("maxSize, timeout = %d, %v; want %d, %v", b.maxSize, b.timeout, tc.wantSize, tc.wantTimeout)
+			}
+		})
+	}
+}
+
+func TestBatcher_RealTimer(t *testing.T) {
+	rec := newRecorder[int]()
+	b := New(rec.flush, WithTimeout(10*time.Millisecond))
+	defer b.Close()
+
+	mustAdd(t, b, 42)
+	rec.waitFlush(t)
+
+	want := [][]int{{42}}
+	if got := rec.get(); !reflect.DeepEqual(got, want) {
+		t.Errorf("batches = %v; want %v", got, want)
+	}
+}
+
+// ExampleBatcher shows the typical lifecycle: add items, then Close to flush
+// whatever is left
+func ExampleBatcher() {
+	b := New(func(batch []string) {
+		fmt.Println(batch)
+	}, WithMaxSize(2), WithTimeout(time.Minute))
+
+	for _, item := range []string{"a", "b", "c"} {
+		if err := b.Add(item); err != nil {
+			fmt.Println("add failed:", err)
+		}
+	}
+	b.Close()
+
+	// Output:
+	// [a b]
+	// [c]
+}
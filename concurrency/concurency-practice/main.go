@@ -1,10 +1,10 @@
 // package main
 
 // import (
+// 	"flag"
 // 	"fmt"
-// 	"math/rand"
+// 	"math/rand/v2"
 // 	"sync"
-// 	"time"
 // )
 
 // type Order struct {
@@ -13,7 +13,10 @@
 // }
 
 // func main() {
-// 	rand.Seed(time.Now().UnixNano())
+// 	// The same -seed gives the orders the same statuses
+// 	seed := flag.Uint64("seed", 1, "random seed")
+// 	flag.Parse()
+// 	r := rand.New(rand.NewPCG(*seed, 0))
 
 // 	var wg sync.WaitGroup
 // 	orderChan := make(chan *Order)
@@ -23,7 +26,7 @@
 // 	wg.Add(1)
 // 	go func() {
 // 		defer wg.Done()
-// 		processOrder(orderChan, processedOrder)
+// 		processOrder(orderChan, processedOrder, r)
 // 	}()
 
 // 	// Add 1 for printer goroutine
@@ -45,11 +48,13 @@
 // 	wg.Wait() // Wait for both goroutines to finish
 // }
 
-// func processOrder(orders <-chan *Order, processedOrder chan<- *Order) {
+// // processOrder is the only goroutine using r, which is not safe for
+// // concurrent use
+// func processOrder(orders <-chan *Order, processedOrder chan<- *Order, r *rand.Rand) {
 // 	statuses := []string{"Processing", "Delivered", "InTransit"}
 
 // 	for order := range orders {
-// 		order.Status = statuses[rand.Intn(len(statuses))]
+// 		order.Status = statuses[r.IntN(len(statuses))]
 // 		processedOrder <- order
 // 	}
 
@@ -1,175 +1,199 @@
-package main
+package goroutines
 
 import (
+	"flag"
 	"fmt"
-	"math/rand"
-	"runtime"
+	"io"
+	"math/rand/v2"
+	"slices"
+	"strconv"
 	"sync"
 	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/metrics"
+	"github.com/rehan/go-interview-prep/pkg/quiz"
 )
 
-func main() {
-	fmt.Println("=========================================")
-	fmt.Println("GO GOROUTINES AND CHANNELS EXAMPLES")
-	fmt.Println("=========================================")
+// Run prints the goroutine, channel and select examples to w. args are
+// the demo's flags: -seed picks the simulated work times, the same ones
+// for the same seed.
+func Run(w io.Writer, args []string) error {
+	fs := flag.NewFlagSet("goroutines-and-channels", flag.ContinueOnError)
+	seed := fs.Uint64("seed", 0, "seed for the simulated work times (default a new one each run)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	for *seed == 0 {
+		*seed = rand.Uint64()
+	}
+	r := rand.New(rand.NewPCG(*seed, 0))
 
-	// Initialize random seed
-	rand.Seed(time.Now().UnixNano())
+	fmt.Fprintln(w, "=========================================")
+	fmt.Fprintln(w, "GO GOROUTINES AND CHANNELS EXAMPLES")
+	fmt.Fprintln(w, "=========================================")
+	fmt.Fprintf(w, "-seed=%d gives the workers the same delays again\n\n", *seed)
 
 	// Basic goroutine
-	SimpleGoroutine()
+	SimpleGoroutine(w)
 
 	// WaitGroup for synchronization
-	WaitGroupExample()
+	WaitGroupExample(w, r)
 
 	// Channel examples
-	UnbufferedChannels()
-	BufferedChannels()
-	ChannelDirections()
-	ClosingChannels()
-	IteratingOverChannels()
+	UnbufferedChannels(w)
+	BufferedChannels(w)
+	ChannelDirections(w)
+	ClosingChannels(w)
+	IteratingOverChannels(w)
 
 	// Select examples
-	SelectStatement()
-	SelectWithTimeout()
-	SelectWithDefault()
+	SelectStatement(w)
+	SelectWithTimeout(w)
+	SelectWithDefault(w)
 
 	// Concurrency patterns
-	WorkerPool()
-	FanOutFanIn()
+	WorkerPool(w, r)
+	FanOutFanIn(w, r)
 
 	// Informational
-	ChannelComparison()
+	ChannelComparison(w)
 
 	// Interview questions
-	GoroutinesAndChannelsInterviewQuestions()
+	GoroutinesAndChannelsInterviewQuestions(w)
+	return nil
 }
 
 // SimpleGoroutine demonstrates a basic goroutine
-func SimpleGoroutine() {
-	fmt.Println("=== SIMPLE GOROUTINE EXAMPLE ===")
+func SimpleGoroutine(w io.Writer) {
+	fmt.Fprintln(w, "=== SIMPLE GOROUTINE EXAMPLE ===")
 
-	// Start a goroutine
+	// Start a goroutine; main does not wait for it unless told to, so it
+	// closes done when it has finished
+	done := make(chan struct{})
 	go func() {
-		fmt.Println("Hello from goroutine!")
+		defer close(done)
+		fmt.Fprintln(w, "Hello from goroutine!")
 	}()
 
-	// Give the goroutine time to execute
-	// In real code, you'd use proper synchronization instead
-	time.Sleep(100 * time.Millisecond)
-	fmt.Println()
+	<-done
+	fmt.Fprintln(w)
 }
 
-// WaitGroupExample demonstrates using WaitGroup for synchronization
-func WaitGroupExample() {
-	fmt.Println("=== WAITGROUP EXAMPLE ===")
+// WaitGroupExample demonstrates using WaitGroup for synchronization. r
+// picks how long each worker works.
+func WaitGroupExample(w io.Writer, r *rand.Rand) {
+	fmt.Fprintln(w, "=== WAITGROUP EXAMPLE ===")
 
 	var wg sync.WaitGroup
 
 	// Launch 5 workers
+	rands := sources(r, 5)
 	for i := 1; i <= 5; i++ {
 		wg.Add(1) // Increment counter before launching goroutine
 
 		go func(id int) {
 			defer wg.Done() // Decrement counter when goroutine completes
 
-			fmt.Printf("Worker %d starting\n", id)
-			time.Sleep(time.Duration(rand.Intn(1000)) * time.Millisecond)
-			fmt.Printf("Worker %d done\n", id)
+			fmt.Fprintf(w, "Worker %d starting\n", id)
+			time.Sleep(delay(rands[id-1], time.Second))
+			fmt.Fprintf(w, "Worker %d done\n", id)
 		}(i)
 	}
 
-	fmt.Println("Waiting for all workers to complete...")
+	fmt.Fprintln(w, "Waiting for all workers to complete...")
 	wg.Wait() // Block until counter becomes 0
-	fmt.Println("All workers completed!")
-	fmt.Println()
+	fmt.Fprintln(w, "All workers completed!")
+	fmt.Fprintln(w)
 }
 
 // UnbufferedChannels demonstrates basic channel operations
-func UnbufferedChannels() {
-	fmt.Println("=== UNBUFFERED CHANNELS EXAMPLE ===")
+func UnbufferedChannels(w io.Writer) {
+	fmt.Fprintln(w, "=== UNBUFFERED CHANNELS EXAMPLE ===")
 
 	// Create an unbuffered channel
 	ch := make(chan string)
+	ready := make(chan struct{})
+	done := make(chan struct{})
 
 	// Sender goroutine
 	go func() {
-		fmt.Println("Sender: Sending message")
+		defer close(done)
+		fmt.Fprintln(w, "Sender: Sending message")
+		close(ready)
 		ch <- "Hello from sender!" // Will block until someone receives
-		fmt.Println("Sender: Message sent")
+		fmt.Fprintln(w, "Sender: Message sent")
 	}()
 
-	// Give sender time to start
-	time.Sleep(100 * time.Millisecond)
+	// Wait until the sender is about to send
+	<-ready
 
 	// Receive the message
-	fmt.Println("Receiver: About to receive")
+	fmt.Fprintln(w, "Receiver: About to receive")
 	msg := <-ch // Will unblock the sender
-	fmt.Printf("Receiver: Got message: %q\n", msg)
-	fmt.Println()
+	<-done
+	fmt.Fprintf(w, "Receiver: Got message: %q\n", msg)
+	fmt.Fprintln(w)
 }
 
 // BufferedChannels demonstrates buffered channel behavior
-func BufferedChannels() {
-	fmt.Println("=== BUFFERED CHANNELS EXAMPLE ===")
+func BufferedChannels(w io.Writer) {
+	fmt.Fprintln(w, "=== BUFFERED CHANNELS EXAMPLE ===")
 
 	// Create a buffered channel with capacity 2
 	ch := make(chan string, 2)
 
 	// Send messages (won't block until buffer is full)
-	fmt.Println("Sending to buffered channel")
+	fmt.Fprintln(w, "Sending to buffered channel")
 	ch <- "First message"
-	fmt.Println("Sent first message")
+	fmt.Fprintln(w, "Sent first message")
 	ch <- "Second message"
-	fmt.Println("Sent second message")
+	fmt.Fprintln(w, "Sent second message")
 
 	// This would block because buffer is full:
 	// ch <- "Third message"
 
 	// Receive messages
-	fmt.Printf("Received: %q\n", <-ch)
-	fmt.Printf("Received: %q\n", <-ch)
-	fmt.Println()
+	fmt.Fprintf(w, "Received: %q\n", <-ch)
+	fmt.Fprintf(w, "Received: %q\n", <-ch)
+	fmt.Fprintln(w)
 }
 
 // ChannelDirections demonstrates channel direction constraints
-func ChannelDirections() {
-	fmt.Println("=== CHANNEL DIRECTIONS EXAMPLE ===")
+func ChannelDirections(w io.Writer) {
+	fmt.Fprintln(w, "=== CHANNEL DIRECTIONS EXAMPLE ===")
 
 	// Create a bidirectional channel
 	ch := make(chan int)
 
 	// Start the sender
-	go sender(ch)
-
-	// Start the receiver, passing the same channel
-	go receiver(ch)
+	go sender(w, ch)
 
-	// Let them communicate
-	time.Sleep(1 * time.Second)
-	fmt.Println()
+	// Run the receiver, passing the same channel, until it has had all
+	// five values
+	receiver(w, ch)
+	fmt.Fprintln(w)
 }
 
 // sender only sends to the channel (send-only channel)
-func sender(ch chan<- int) {
+func sender(w io.Writer, ch chan<- int) {
 	for i := 0; i < 5; i++ {
-		fmt.Printf("Sender: sending %d\n", i)
+		fmt.Fprintf(w, "Sender: sending %d\n", i)
 		ch <- i
 		time.Sleep(100 * time.Millisecond)
 	}
 }
 
 // receiver only receives from the channel (receive-only channel)
-func receiver(ch <-chan int) {
+func receiver(w io.Writer, ch <-chan int) {
 	for i := 0; i < 5; i++ {
 		val := <-ch
-		fmt.Printf("Receiver: got %d\n", val)
+		fmt.Fprintf(w, "Receiver: got %d\n", val)
 	}
 }
 
 // ClosingChannels demonstrates closing a channel and ranging over it
-func ClosingChannels() {
-	fmt.Println("=== CLOSING CHANNELS EXAMPLE ===")
+func ClosingChannels(w io.Writer) {
+	fmt.Fprintln(w, "=== CLOSING CHANNELS EXAMPLE ===")
 
 	// Create a channel
 	ch := make(chan int, 5)
@@ -178,9 +202,9 @@ func ClosingChannels() {
 	go func() {
 		for i := 0; i < 5; i++ {
 			ch <- i
-			fmt.Printf("Sent: %d\n", i)
+			fmt.Fprintf(w, "Sent: %d\n", i)
 		}
-		fmt.Println("Producer: closing channel")
+		fmt.Fprintln(w, "Producer: closing channel")
 		close(ch)
 	}()
 
@@ -188,17 +212,17 @@ func ClosingChannels() {
 	for {
 		val, ok := <-ch
 		if !ok {
-			fmt.Println("Channel closed, exiting")
+			fmt.Fprintln(w, "Channel closed, exiting")
 			break
 		}
-		fmt.Printf("Received: %d\n", val)
+		fmt.Fprintf(w, "Received: %d\n", val)
 	}
-	fmt.Println()
+	fmt.Fprintln(w)
 }
 
 // IteratingOverChannels demonstrates the range syntax for channels
-func IteratingOverChannels() {
-	fmt.Println("=== ITERATING OVER CHANNELS EXAMPLE ===")
+func IteratingOverChannels(w io.Writer) {
+	fmt.Fprintln(w, "=== ITERATING OVER CHANNELS EXAMPLE ===")
 
 	// Create a channel
 	ch := make(chan int, 5)
@@ -213,15 +237,15 @@ func IteratingOverChannels() {
 
 	// Consume with range (automatically handles closed channels)
 	for val := range ch {
-		fmt.Printf("Received: %d\n", val)
+		fmt.Fprintf(w, "Received: %d\n", val)
 	}
-	fmt.Println("Channel closed, loop exited")
-	fmt.Println()
+	fmt.Fprintln(w, "Channel closed, loop exited")
+	fmt.Fprintln(w)
 }
 
 // SelectStatement demonstrates using select to wait on multiple channels
-func SelectStatement() {
-	fmt.Println("=== SELECT STATEMENT EXAMPLE ===")
+func SelectStatement(w io.Writer) {
+	fmt.Fprintln(w, "=== SELECT STATEMENT EXAMPLE ===")
 
 	ch1 := make(chan string)
 	ch2 := make(chan string)
@@ -242,17 +266,17 @@ func SelectStatement() {
 	for i := 0; i < 2; i++ {
 		select {
 		case msg1 := <-ch1:
-			fmt.Printf("Received from ch1: %s\n", msg1)
+			fmt.Fprintf(w, "Received from ch1: %s\n", msg1)
 		case msg2 := <-ch2:
-			fmt.Printf("Received from ch2: %s\n", msg2)
+			fmt.Fprintf(w, "Received from ch2: %s\n", msg2)
 		}
 	}
-	fmt.Println()
+	fmt.Fprintln(w)
 }
 
 // SelectWithTimeout demonstrates using select with timeout
-func SelectWithTimeout() {
-	fmt.Println("=== SELECT WITH TIMEOUT EXAMPLE ===")
+func SelectWithTimeout(w io.Writer) {
+	fmt.Fprintln(w, "=== SELECT WITH TIMEOUT EXAMPLE ===")
 
 	ch := make(chan string)
 
@@ -265,48 +289,50 @@ func SelectWithTimeout() {
 	// Wait for the message with a 250ms timeout
 	select {
 	case msg := <-ch:
-		fmt.Printf("Received: %s\n", msg)
+		fmt.Fprintf(w, "Received: %s\n", msg)
 	case <-time.After(250 * time.Millisecond):
-		fmt.Println("Timeout! No message received in time")
+		fmt.Fprintln(w, "Timeout! No message received in time")
 	}
 
 	// Wait for the message with a 1 second timeout (will succeed)
 	select {
 	case msg := <-ch:
-		fmt.Printf("Received: %s\n", msg)
+		fmt.Fprintf(w, "Received: %s\n", msg)
 	case <-time.After(1 * time.Second):
-		fmt.Println("Timeout! No message received in time")
+		fmt.Fprintln(w, "Timeout! No message received in time")
 	}
-	fmt.Println()
+	fmt.Fprintln(w)
 }
 
 // SelectWithDefault demonstrates non-blocking channel operations
-func SelectWithDefault() {
-	fmt.Println("=== SELECT WITH DEFAULT EXAMPLE ===")
+func SelectWithDefault(w io.Writer) {
+	fmt.Fprintln(w, "=== SELECT WITH DEFAULT EXAMPLE ===")
 
 	ch := make(chan string)
 
 	// Try to receive, but don't block
 	select {
 	case msg := <-ch:
-		fmt.Printf("Received: %s\n", msg)
+		fmt.Fprintf(w, "Received: %s\n", msg)
 	default:
-		fmt.Println("No message available")
+		fmt.Fprintln(w, "No message available")
 	}
 
 	// Try to send, but don't block
 	select {
 	case ch <- "Hello":
-		fmt.Println("Sent message")
+		fmt.Fprintln(w, "Sent message")
 	default:
-		fmt.Println("No receiver available")
+		fmt.Fprintln(w, "No receiver available")
 	}
-	fmt.Println()
+	fmt.Fprintln(w)
 }
 
-// WorkerPool demonstrates a worker pool pattern
-func WorkerPool() {
-	fmt.Println("=== WORKER POOL EXAMPLE ===")
+// WorkerPool demonstrates a worker pool pattern. It returns the results
+// sorted, as they arrive in whatever order the workers finish. r picks
+// how long each job takes.
+func WorkerPool(out io.Writer, r *rand.Rand) []int {
+	fmt.Fprintln(out, "=== WORKER POOL EXAMPLE ===")
 
 	const numJobs = 10
 	const numWorkers = 3
@@ -315,13 +341,23 @@ func WorkerPool() {
 	jobs := make(chan int, numJobs)
 	results := make(chan int, numJobs)
 
+	// Metrics show how evenly the workers share the load
+	reg := metrics.NewRegistry()
+	m := &poolMetrics{
+		processed: reg.NewCounter("pool_jobs_processed_total", "Jobs each worker finished.", "worker"),
+		busy:      reg.NewGauge("pool_workers_busy", "Workers processing a job."),
+		duration: reg.NewHistogram("pool_job_duration_seconds", "Time taken per job.",
+			[]float64{0.025, 0.05, 0.1}),
+	}
+
 	// Start workers
 	var wg sync.WaitGroup
+	rands := sources(r, numWorkers)
 	for w := 1; w <= numWorkers; w++ {
 		wg.Add(1)
 		go func(id int) {
 			defer wg.Done()
-			worker(id, jobs, results)
+			worker(out, id, jobs, results, m, rands[id-1])
 		}(w)
 	}
 
@@ -338,25 +374,45 @@ func WorkerPool() {
 	}()
 
 	// Collect results
+	var collected []int
 	for result := range results {
-		fmt.Printf("Result: %d\n", result)
+		fmt.Fprintf(out, "Result: %d\n", result)
+		collected = append(collected, result)
 	}
-	fmt.Println()
+
+	fmt.Fprintln(out, "\nMetrics:")
+	reg.WriteText(out)
+	fmt.Fprintln(out)
+	slices.Sort(collected)
+	return collected
+}
+
+// poolMetrics are what the worker pool records about its jobs
+type poolMetrics struct {
+	processed *metrics.Counter
+	busy      *metrics.Gauge
+	duration  *metrics.Histogram
 }
 
 // worker processes jobs from jobs channel and sends results to results channel
-func worker(id int, jobs <-chan int, results chan<- int) {
+func worker(w io.Writer, id int, jobs <-chan int, results chan<- int, m *poolMetrics, r *rand.Rand) {
 	for job := range jobs {
-		fmt.Printf("Worker %d processing job %d\n", id, job)
-		time.Sleep(time.Duration(rand.Intn(100)) * time.Millisecond)
+		m.busy.Inc()
+		start := time.Now()
+		fmt.Fprintf(w, "Worker %d processing job %d\n", id, job)
+		time.Sleep(delay(r, 100*time.Millisecond))
+		m.duration.Observe(time.Since(start).Seconds())
+		m.processed.Inc(strconv.Itoa(id))
+		m.busy.Dec()
 		results <- job * 2 // Simulate some processing
 	}
 
 }
 
-// FanOutFanIn demonstrates the fan-out/fan-in pattern
-func FanOutFanIn() {
-	fmt.Println("=== FAN-OUT/FAN-IN EXAMPLE ===")
+// FanOutFanIn demonstrates the fan-out/fan-in pattern. r picks how long
+// each value takes to process.
+func FanOutFanIn(w io.Writer, r *rand.Rand) {
+	fmt.Fprintln(w, "=== FAN-OUT/FAN-IN EXAMPLE ===")
 
 	// Create channels
 	input := make(chan int, 10)
@@ -370,26 +426,27 @@ func FanOutFanIn() {
 	}()
 
 	// Create multiple channels to fan out the work
-	c1 := fanOut(input)
-	c2 := fanOut(input)
-	c3 := fanOut(input)
+	rands := sources(r, 3)
+	c1 := fanOut(input, rands[0])
+	c2 := fanOut(input, rands[1])
+	c3 := fanOut(input, rands[2])
 
 	// Fan in the results
 	for results := range fanIn(c1, c2, c3) {
-		fmt.Printf("Result: %d\n", results)
+		fmt.Fprintf(w, "Result: %d\n", results)
 	}
-	fmt.Println()
+	fmt.Fprintln(w)
 }
 
 // fanOut creates a channel that processes input values and sends results
-func fanOut(input <-chan int) <-chan int {
+func fanOut(input <-chan int, r *rand.Rand) <-chan int {
 	output := make(chan int)
 
 	go func() {
 		defer close(output)
 		for n := range input {
 			// Simulate varying processing times
-			time.Sleep(time.Duration(rand.Intn(100)) * time.Millisecond)
+			time.Sleep(delay(r, 100*time.Millisecond))
 			output <- n * n // Square the number
 		}
 	}()
@@ -422,104 +479,47 @@ func fanIn(inputs ...<-chan int) <-chan int {
 	return output
 }
 
+// sources returns n random sources, one for each of n goroutines, seeded
+// from r in order. A *rand.Rand is not safe for concurrent use, and
+// goroutines sharing a locked one would each draw whatever number their
+// turn came to, so the same seed would not give a worker the same delays.
+func sources(r *rand.Rand, n int) []*rand.Rand {
+	rands := make([]*rand.Rand, n)
+	for i := range rands {
+		rands[i] = rand.New(rand.NewPCG(r.Uint64(), r.Uint64()))
+	}
+	return rands
+}
+
+// delay returns a simulated work time below max
+func delay(r *rand.Rand, max time.Duration) time.Duration {
+	return time.Duration(r.Int64N(int64(max)))
+}
+
 // ChannelComparison demonstrates channel behaviors and differences
-func ChannelComparison() {
-	fmt.Println("=== CHANNEL COMPARISON ===")
-
-	fmt.Println("Channel Behaviors:")
-	fmt.Println("- Unbuffered: send blocks until receive")
-	fmt.Println("- Buffered: send blocks only when buffer full")
-	fmt.Println("- Receive always blocks if no data available")
-	fmt.Println("- Channel closing: sends panic, receives get zero value")
-	fmt.Println("- Closed check: val, ok := <-ch (ok is false if closed)")
-	fmt.Println("- nil channels: sends and receives block forever")
-
-	fmt.Println("\nChannel Use Cases:")
-	fmt.Println("- Signaling: close a channel to broadcast to multiple goroutines")
-	fmt.Println("- Done channel: channel to signal completion")
-	fmt.Println("- Worker pools: distribute work among multiple workers")
-	fmt.Println("- Rate limiting: buffer capacity controls processing rate")
-	fmt.Println("- Pipelines: chain of stages connected by channels")
-	fmt.Println()
+func ChannelComparison(w io.Writer) {
+	fmt.Fprintln(w, "=== CHANNEL COMPARISON ===")
+
+	fmt.Fprintln(w, "Channel Behaviors:")
+	fmt.Fprintln(w, "- Unbuffered: send blocks until receive")
+	fmt.Fprintln(w, "- Buffered: send blocks only when buffer full")
+	fmt.Fprintln(w, "- Receive always blocks if no data available")
+	fmt.Fprintln(w, "- Channel closing: sends panic, receives get zero value")
+	fmt.Fprintln(w, "- Closed check: val, ok := <-ch (ok is false if closed)")
+	fmt.Fprintln(w, "- nil channels: sends and receives block forever")
+
+	fmt.Fprintln(w, "\nChannel Use Cases:")
+	fmt.Fprintln(w, "- Signaling: close a channel to broadcast to multiple goroutines")
+	fmt.Fprintln(w, "- Done channel: channel to signal completion")
+	fmt.Fprintln(w, "- Worker pools: distribute work among multiple workers")
+	fmt.Fprintln(w, "- Rate limiting: buffer capacity controls processing rate")
+	fmt.Fprintln(w, "- Pipelines: chain of stages connected by channels")
+	fmt.Fprintln(w)
 }
 
 // GoroutinesAndChannelsInterviewQuestions lists common interview questions
-func GoroutinesAndChannelsInterviewQuestions() {
-	fmt.Println("=========================================")
-	fmt.Println("COMMON INTERVIEW QUESTIONS:")
-	fmt.Println("=========================================")
-
-	fmt.Println("1. What is a goroutine and how is it different from a thread?")
-	fmt.Println("   - Lightweight thread managed by Go runtime")
-	fmt.Println("   - Much smaller stack size (2KB initially vs MB for OS threads)")
-	fmt.Println("   - Cheaper creation and context switching")
-	fmt.Println("   - Go runtime multiplexes goroutines onto OS threads")
-	fmt.Println()
-
-	fmt.Println("2. How do goroutines communicate?")
-	fmt.Println("   - Primarily through channels")
-	fmt.Println("   - Can also use shared memory with proper synchronization")
-	fmt.Println("   - \"Don't communicate by sharing memory; share memory by communicating\"")
-	fmt.Println()
-
-	fmt.Println("3. What is the difference between buffered and unbuffered channels?")
-	fmt.Println("   - Unbuffered: synchronous, sender blocks until receiver receives")
-	fmt.Println("   - Buffered: asynchronous up to buffer capacity")
-	fmt.Println("   - Buffered channels decouple sender and receiver temporally")
-	fmt.Println()
-
-	fmt.Println("4. How do you prevent goroutine leaks?")
-	fmt.Println("   - Ensure goroutines can exit (e.g., by using contexts, timeout channels)")
-	fmt.Println("   - Properly close channels to signal completion")
-	fmt.Println("   - Use cancellation mechanisms like context.Context")
-	fmt.Println("   - Use WaitGroups to track completion")
-	fmt.Println()
-
-	fmt.Println("5. What does the select statement do?")
-	fmt.Println("   - Waits on multiple channel operations")
-	fmt.Println("   - Blocks until one case can proceed")
-	fmt.Println("   - If multiple cases ready, chooses one at random")
-	fmt.Println("   - default case makes select non-blocking")
-	fmt.Println()
-
-	fmt.Println("6. What happens when you close a channel?")
-	fmt.Println("   - Sends on closed channel panic")
-	fmt.Println("   - Receives from closed channel get zero value immediately")
-	fmt.Println("   - Receive check (val, ok := <-ch) returns ok=false when closed")
-	fmt.Println()
-
-	fmt.Println("7. What is a race condition and how to detect it?")
-	fmt.Println("   - Concurrent access to shared data without proper synchronization")
-	fmt.Println("   - Detect with go run -race or go test -race")
-	fmt.Println("   - Fix with proper synchronization mechanisms")
-	fmt.Println()
-
-	fmt.Println("8. What are common concurrency patterns in Go?")
-	fmt.Println("   - Worker pools: fixed number of workers processing from queue")
-	fmt.Println("   - Fan-out/fan-in: distribute work and collect results")
-	fmt.Println("   - Pipeline: chain of stages connected by channels")
-	fmt.Println("   - Cancellation: propagate cancellation using context")
-	fmt.Println()
-
-	fmt.Println("9. How many OS threads does Go use for goroutines?")
-	fmt.Println("   - By default, GOMAXPROCS (usually matches CPU cores)")
-	fmt.Println("   - Can be modified with runtime.GOMAXPROCS()")
-	fmt.Println("   - Current value is", runtime.GOMAXPROCS(0))
-	fmt.Println()
-
-	fmt.Println("10. What is channel directionality?")
-	fmt.Println("    - Restrict channel to send-only (chan<-) or receive-only (<-chan)")
-	fmt.Println("    - Provides better type safety")
-	fmt.Println("    - Documents intent and prevents incorrect usage")
-	fmt.Println()
-
-	fmt.Println("11. What's the difference between len() and cap() for channels?")
-	fmt.Println("    - len(): number of elements currently in the channel")
-	fmt.Println("    - cap(): total capacity of the channel buffer")
-	fmt.Println()
-
-	fmt.Println("12. When would you use a nil channel?")
-	fmt.Println("    - In select statements to disable specific cases")
-	fmt.Println("    - Note: sends and receives on nil channels block forever")
-	fmt.Println()
+func GoroutinesAndChannelsInterviewQuestions(w io.Writer) {
+	if err := quiz.Print(w, "goroutines-and-channels"); err != nil {
+		fmt.Fprintln(w, err)
+	}
 }
@@ -0,0 +1,209 @@
+package goroutines
+
+import (
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"slices"
+	"testing"
+	"time"
+)
+
+// seeded returns the random source the examples use
+func seeded() *rand.Rand {
+	return rand.New(rand.NewPCG(1, 2))
+}
+
+func ExampleSimpleGoroutine() {
+	SimpleGoroutine(os.Stdout)
+	// Output:
+	// === SIMPLE GOROUTINE EXAMPLE ===
+	// Hello from goroutine!
+}
+
+func ExampleWaitGroupExample() {
+	// The workers start and finish in any order
+	WaitGroupExample(os.Stdout, seeded())
+	// Unordered output:
+	// === WAITGROUP EXAMPLE ===
+	// Waiting for all workers to complete...
+	// Worker 5 starting
+	// Worker 1 starting
+	// Worker 2 starting
+	// Worker 3 starting
+	// Worker 4 starting
+	// Worker 3 done
+	// Worker 2 done
+	// Worker 1 done
+	// Worker 4 done
+	// Worker 5 done
+	// All workers completed!
+}
+
+func ExampleUnbufferedChannels() {
+	UnbufferedChannels(os.Stdout)
+	// Output:
+	// === UNBUFFERED CHANNELS EXAMPLE ===
+	// Sender: Sending message
+	// Receiver: About to receive
+	// Sender: Message sent
+	// Receiver: Got message: "Hello from sender!"
+}
+
+func ExampleBufferedChannels() {
+	BufferedChannels(os.Stdout)
+	// Output:
+	// === BUFFERED CHANNELS EXAMPLE ===
+	// Sending to buffered channel
+	// Sent first message
+	// Sent second message
+	// Received: "First message"
+	// Received: "Second message"
+}
+
+func ExampleChannelDirections() {
+	// The sender's next line can come before or after the receiver's
+	ChannelDirections(os.Stdout)
+	// Unordered output:
+	// === CHANNEL DIRECTIONS EXAMPLE ===
+	// Sender: sending 0
+	// Receiver: got 0
+	// Sender: sending 1
+	// Receiver: got 1
+	// Sender: sending 2
+	// Receiver: got 2
+	// Sender: sending 3
+	// Receiver: got 3
+	// Sender: sending 4
+	// Receiver: got 4
+}
+
+func ExampleClosingChannels() {
+	// The producer fills the buffer while the consumer drains it, so their
+	// lines interleave differently from run to run
+	ClosingChannels(os.Stdout)
+	// Unordered output:
+	// === CLOSING CHANNELS EXAMPLE ===
+	// Sent: 0
+	// Sent: 1
+	// Sent: 2
+	// Sent: 3
+	// Sent: 4
+	// Producer: closing channel
+	// Received: 0
+	// Received: 1
+	// Received: 2
+	// Received: 3
+	// Received: 4
+	// Channel closed, exiting
+}
+
+func ExampleIteratingOverChannels() {
+	IteratingOverChannels(os.Stdout)
+	// Output:
+	// === ITERATING OVER CHANNELS EXAMPLE ===
+	// Received: 0
+	// Received: 1
+	// Received: 2
+	// Received: 3
+	// Received: 4
+	// Channel closed, loop exited
+}
+
+func ExampleSelectStatement() {
+	SelectStatement(os.Stdout)
+	// Output:
+	// === SELECT STATEMENT EXAMPLE ===
+	// Received from ch2: Message from channel 2
+	// Received from ch1: Message from channel 1
+}
+
+func ExampleSelectWithTimeout() {
+	SelectWithTimeout(os.Stdout)
+	// Output:
+	// === SELECT WITH TIMEOUT EXAMPLE ===
+	// Timeout! No message received in time
+	// Received: Message from goroutine
+}
+
+func ExampleSelectWithDefault() {
+	SelectWithDefault(os.Stdout)
+	// Output:
+	// === SELECT WITH DEFAULT EXAMPLE ===
+	// No message available
+	// No receiver available
+}
+
+func ExampleWorkerPool() {
+	// The demo prints results as they arrive; the returned ones are sorted
+	fmt.Println(WorkerPool(io.Discard, seeded()))
+	// Output: [2 4 6 8 10 12 14 16 18 20]
+}
+
+func ExampleFanOutFanIn() {
+	// The squares arrive in whatever order the workers finish
+	FanOutFanIn(os.Stdout, seeded())
+	// Unordered output:
+	// === FAN-OUT/FAN-IN EXAMPLE ===
+	// Result: 0
+	// Result: 1
+	// Result: 4
+	// Result: 9
+	// Result: 25
+	// Result: 16
+	// Result: 49
+	// Result: 64
+	// Result: 81
+	// Result: 36
+}
+
+func ExampleChannelComparison() {
+	ChannelComparison(os.Stdout)
+	// Output:
+	// === CHANNEL COMPARISON ===
+	// Channel Behaviors:
+	// - Unbuffered: send blocks until receive
+	// - Buffered: send blocks only when buffer full
+	// - Receive always blocks if no data available
+	// - Channel closing: sends panic, receives get zero value
+	// - Closed check: val, ok := <-ch (ok is false if closed)
+	// - nil channels: sends and receives block forever
+	//
+	// Channel Use Cases:
+	// - Signaling: close a channel to broadcast to multiple goroutines
+	// - Done channel: channel to signal completion
+	// - Worker pools: distribute work among multiple workers
+	// - Rate limiting: buffer capacity controls processing rate
+	// - Pipelines: chain of stages connected by channels
+}
+
+// The same seed gives each goroutine the same delays, however the
+// goroutines interleave, and different goroutines different ones
+func TestSources_SameSeedSameDelays(t *testing.T) {
+	draw := func() [][]time.Duration {
+		var delays [][]time.Duration
+		for _, r := range sources(seeded(), 3) {
+			var d []time.Duration
+			for range 5 {
+				d = append(d, delay(r, 100*time.Millisecond))
+			}
+			delays = append(delays, d)
+		}
+		return delays
+	}
+	first, second := draw(), draw()
+	for i := range first {
+		if !slices.Equal(first[i], second[i]) {
+			t.Errorf("goroutine %d: delays %v, then %v with the same seed", i, first[i], second[i])
+		}
+		for _, d := range first[i] {
+			if d < 0 || d >= 100*time.Millisecond {
+				t.Errorf("delay = %v; want from 0 to 100ms", d)
+			}
+		}
+	}
+	if slices.Equal(first[0], first[1]) {
+		t.Errorf("goroutines 0 and 1 got the same delays %v", first[0])
+	}
+}
@@ -0,0 +1,183 @@
+package httpaggregator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/quiz"
+)
+
+// Run fans requests out to test servers and prints the combined results
+// to w
+func Run(w io.Writer) error {
+	fmt.Fprintln(w, "=========================================")
+	fmt.Fprintln(w, "CONCURRENT HTTP AGGREGATOR EXAMPLE")
+	fmt.Fprintln(w, "=========================================")
+
+	AggregatorExample(w)
+
+	// Interview questions
+	AggregatorInterviewQuestions(w)
+	return nil
+}
+
+// Call describes one upstream request to aggregate
+type Call struct {
+	Name string
+	URL  string
+}
+
+// CallResult is the outcome of a single call.
+// Exactly one of Body or Err is meaningful.
+type CallResult struct {
+	Name       string
+	StatusCode int
+	Body       string
+	Err        error
+	Duration   time.Duration
+}
+
+// AggregateResult combines the outcomes of all calls, in the order they were requested
+type AggregateResult struct {
+	Results   []CallResult
+	Succeeded int
+	Failed    int
+}
+
+// Err joins the errors of every failed call, or returns nil if all succeeded
+func (a AggregateResult) Err() error {
+	var errs []error
+	for _, r := range a.Results {
+		if r.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", r.Name, r.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ErrUnexpectedStatus is wrapped by calls that returned a non-2xx status
+var ErrUnexpectedStatus = errors.New("unexpected status")
+
+// FetchAll runs every call concurrently, each bounded by perCallTimeout, and
+// waits for all of them to settle. Like Promise.allSettled, one failure does
+// not cancel the others; cancelling ctx cancels everything.
+func FetchAll(ctx context.Context, client *http.Client, calls []Call, perCallTimeout time.Duration) AggregateResult {
+	results := make([]CallResult, len(calls))
+
+	var wg sync.WaitGroup
+	for i, call := range calls {
+		wg.Add(1)
+		go func(i int, call Call) {
+			defer wg.Done()
+			// Each goroutine writes only its own slot, so no mutex is needed
+			results[i] = fetchOne(ctx, client, call, perCallTimeout)
+		}(i, call)
+	}
+	wg.Wait()
+
+	agg := AggregateResult{Results: results}
+	for _, r := range results {
+		if r.Err != nil {
+			agg.Failed++
+		} else {
+			agg.Succeeded++
+		}
+	}
+	return agg
+}
+
+// fetchOne performs a single GET with its own timeout
+func fetchOne(ctx context.Context, client *http.Client, call Call, timeout time.Duration) CallResult {
+	start := time.Now()
+	result := CallResult{Name: call.Name}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, call.URL, nil)
+	if err != nil {
+		result.Err = err
+		return finish(result, start)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		result.Err = err
+		return finish(result, start)
+	}
+	defer resp.Body.Close()
+
+	result.StatusCode = resp.StatusCode
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		result.Err = err
+		return finish(result, start)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		result.Err = fmt.Errorf("%w: %d", ErrUnexpectedStatus, resp.StatusCode)
+		return finish(result, start)
+	}
+
+	result.Body = string(body)
+	return finish(result, start)
+}
+
+// finish stamps the elapsed time on a result
+func finish(r CallResult, start time.Time) CallResult {
+	r.Duration = time.Since(start)
+	return r
+}
+
+// AggregatorExample aggregates a fast, a slow, and a failing upstream
+func AggregatorExample(out io.Writer) {
+	fmt.Fprintln(out, "=== AGGREGATOR EXAMPLE ===")
+
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"service":"users"}`)
+	}))
+	defer fast.Close()
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(2 * time.Second):
+			fmt.Fprint(w, `{"service":"recommendations"}`)
+		case <-r.Context().Done():
+		}
+	}))
+	defer slow.Close()
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	calls := []Call{
+		{Name: "users", URL: fast.URL},
+		{Name: "recommendations", URL: slow.URL},
+		{Name: "billing", URL: failing.URL},
+	}
+
+	agg := FetchAll(context.Background(), http.DefaultClient, calls, 500*time.Millisecond)
+	for _, r := range agg.Results {
+		if r.Err != nil {
+			fmt.Fprintf(out, "%-16s FAILED  %v\n", r.Name, r.Err)
+		} else {
+			fmt.Fprintf(out, "%-16s OK      %s\n", r.Name, r.Body)
+		}
+	}
+	fmt.Fprintf(out, "Succeeded: %d, Failed: %d\n", agg.Succeeded, agg.Failed)
+	fmt.Fprintln(out)
+}
+
+// AggregatorInterviewQuestions lists common interview questions about fan-out requests
+func AggregatorInterviewQuestions(w io.Writer) {
+	if err := quiz.Print(w, "http-aggregator"); err != nil {
+		fmt.Fprintln(w, err)
+	}
+}
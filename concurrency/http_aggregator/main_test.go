@@ -0,0 +1,128 @@
+package httpaggregator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newServer returns a test server that replies with body after delay
+func newServer(t *testing.T, status int, body string, delay time.Duration) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+		w.WriteHeader(status)
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestFetchAll_AllSucceed(t *testing.T) {
+	a := newServer(t, http.StatusOK, "a", 0)
+	b := newServer(t, http.StatusOK, "b", 20*time.Millisecond)
+
+	agg := FetchAll(context.Background(), http.DefaultClient, []Call{
+		{Name: "a", URL: a.URL},
+		{Name: "b", URL: b.URL},
+	}, time.Second)
+
+	if agg.Succeeded != 2 || agg.Failed != 0 {
+		t.Fatalf("Succeeded=%d Failed=%d; want 2, 0", agg.Succeeded, agg.Failed)
+	}
+	if err := agg.Err(); err != nil {
+		t.Errorf("Err() = %v; want nil", err)
+	}
+
+	// Results keep the input order even though b finishes last
+	for i, want := range []string{"a", "b"} {
+		if got := agg.Results[i]; got.Name != want || got.Body != want {
+			t.Errorf("Results[%d] = {%s %q}; want {%s %q}", i, got.Name, got.Body, want, want)
+		}
+	}
+}
+
+func TestFetchAll_PartialFailure(t *testing.T) {
+	ok := newServer(t, http.StatusOK, "fine", 0)
+	broken := newServer(t, http.StatusInternalServerError, "boom", 0)
+	slow := newServer(t, http.StatusOK, "late", time.Second)
+
+	agg := FetchAll(context.Background(), http.DefaultClient, []Call{
+		{Name: "ok", URL: ok.URL},
+		{Name: "broken", URL: broken.URL},
+		{Name: "slow", URL: slow.URL},
+	}, 50*time.Millisecond)
+
+	if agg.Succeeded != 1 || agg.Failed != 2 {
+		t.Fatalf("Succeeded=%d Failed=%d; want 1, 2", agg.Succeeded, agg.Failed)
+	}
+
+	if r := agg.Results[0]; r.Err != nil || r.Body != "fine" {
+		t.Errorf("ok call = {%q, %v}; want {\"fine\", nil}", r.Body, r.Err)
+	}
+
+	if r := agg.Results[1]; !errors.Is(r.Err, ErrUnexpectedStatus) || r.StatusCode != http.StatusInternalServerError {
+		t.Errorf("broken call err = %v, status = %d; want ErrUnexpectedStatus, 500", r.Err, r.StatusCode)
+	}
+
+	if r := agg.Results[2]; !errors.Is(r.Err, context.DeadlineExceeded) {
+		t.Errorf("slow call err = %v; want context.DeadlineExceeded", r.Err)
+	}
+
+	err := agg.Err()
+	if !errors.Is(err, ErrUnexpectedStatus) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Err() = %v; want it to wrap both failures", err)
+	}
+	if !strings.Contains(err.Error(), "broken:") || !strings.Contains(err.Error(), "slow:") {
+		t.Errorf("Err() = %q; want call names in the message", err)
+	}
+}
+
+func TestFetchAll_RunsConcurrently(t *testing.T) {
+	const delay = 100 * time.Millisecond
+	var calls []Call
+	for i := 0; i < 5; i++ {
+		srv := newServer(t, http.StatusOK, "x", delay)
+		calls = append(calls, Call{Name: fmt.Sprint(i), URL: srv.URL})
+	}
+
+	start := time.Now()
+	agg := FetchAll(context.Background(), http.DefaultClient, calls, time.Second)
+	elapsed := time.Since(start)
+
+	if agg.Failed != 0 {
+		t.Fatalf("unexpected failures: %v", agg.Err())
+	}
+	// Sequential calls would take 5*delay
+	if elapsed > 3*delay {
+		t.Errorf("FetchAll took %v; expected roughly %v for concurrent calls", elapsed, delay)
+	}
+}
+
+func TestFetchAll_ParentCancellation(t *testing.T) {
+	slow := newServer(t, http.StatusOK, "late", time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	agg := FetchAll(ctx, http.DefaultClient, []Call{{Name: "slow", URL: slow.URL}}, time.Second)
+	if r := agg.Results[0]; !errors.Is(r.Err, context.Canceled) {
+		t.Errorf("err = %v; want context.Canceled", r.Err)
+	}
+}
+
+func TestFetchAll_NoCalls(t *testing.T) {
+	agg := FetchAll(context.Background(), http.DefaultClient, nil, time.Second)
+	if len(agg.Results) != 0 || agg.Err() != nil {
+		t.Errorf("FetchAll(nil) = %+v; want an empty, successful result", agg)
+	}
+}
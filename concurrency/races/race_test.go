@@ -0,0 +1,57 @@
+//go:build race
+
+package races
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// racy are the racy functions, each with the function the race
+// detector's report should name
+var racy = map[string]struct {
+	run   func()
+	frame string
+}{
+	"CountUnsafe":   {func() { CountUnsafe(100) }, "races.CountUnsafe.func1"},
+	"CollectUnsafe": {func() { CollectUnsafe(100) }, "races.CollectUnsafe.func1"},
+	"LazyUnsafe": {func() {
+		var l LazyUnsafe
+		GetConcurrently(100, l.Get)
+	}, "races.(*LazyUnsafe).Get"},
+}
+
+// racyEnv names the racy function TestRacy runs
+const racyEnv = "RACES_RUN"
+
+// TestRacy runs a racy function when TestRaceDetector starts the test
+// binary again to run it. A race fails the test that it happens in, so
+// it happens in that child, not here.
+func TestRacy(t *testing.T) {
+	name := os.Getenv(racyEnv)
+	if name == "" {
+		t.Skip("run by TestRaceDetector")
+	}
+	racy[name].run()
+}
+
+func TestRaceDetector(t *testing.T) {
+	for name, r := range racy {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			cmd := exec.Command(os.Args[0], "-test.run=^TestRacy$")
+			cmd.Env = append(os.Environ(), racyEnv+"="+name)
+			out, err := cmd.CombinedOutput()
+			if err == nil {
+				t.Fatalf("%s passed under the race detector:\n%s", name, out)
+			}
+			for _, want := range []string{"WARNING: DATA RACE", r.frame} {
+				if !strings.Contains(string(out), want) {
+					t.Errorf("%s's output lacks %q:\n%s", name, want, out)
+				}
+			}
+		})
+	}
+}
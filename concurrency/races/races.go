@@ -0,0 +1,185 @@
+// Package races pairs functions with data races, written on purpose,
+// with fixed versions of them. A data race is two goroutines touching
+// the same memory, at least one writing, with nothing ordering them; its
+// result is undefined, and a run that happens to print the right answer
+// proves nothing. The race detector finds them as they happen:
+//
+//	go test -race ./concurrency/races/
+//
+// runs race_test.go, which runs each racy function under the detector
+// and checks that it reports the race. Without -race that file is not
+// built, and the tests check only the fixed versions.
+package races
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// COUNTERS: a read-modify-write from many goroutines
+
+// CountUnsafe increments a counter from n goroutines without
+// synchronization. counter++ is a load, an add and a store, so two
+// goroutines can load the same value and one increment is lost: the
+// result may be less than n.
+func CountUnsafe(n int) int {
+	counter := 0
+	var wg sync.WaitGroup
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			counter++ // RACE: unsynchronized read-modify-write
+		}()
+	}
+	wg.Wait()
+	return counter
+}
+
+// CountMutex makes each increment a critical section
+func CountMutex(n int) int {
+	var (
+		mu      sync.Mutex
+		counter int
+		wg      sync.WaitGroup
+	)
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			mu.Lock()
+			counter++
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return counter
+}
+
+// CountAtomic makes each increment one atomic instruction, cheaper than a
+// mutex for a lone counter
+func CountAtomic(n int) int {
+	var (
+		counter atomic.Int64
+		wg      sync.WaitGroup
+	)
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			counter.Add(1)
+		}()
+	}
+	wg.Wait()
+	return int(counter.Load())
+}
+
+// SLICES: append from many goroutines
+
+// CollectUnsafe appends 0..n-1 to a slice from n goroutines. append reads
+// and writes the slice header and may copy the backing array, so values
+// are lost or duplicated.
+func CollectUnsafe(n int) []int {
+	var (
+		out []int
+		wg  sync.WaitGroup
+	)
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			out = append(out, i) // RACE: unsynchronized append
+		}()
+	}
+	wg.Wait()
+	return out
+}
+
+// CollectIndexed gives each goroutine its own element of a slice
+// allocated up front. Distinct elements are distinct memory, so nothing
+// is shared and no lock is needed; wg.Wait orders the writes before the
+// return.
+func CollectIndexed(n int) []int {
+	out := make([]int, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			out[i] = i
+		}()
+	}
+	wg.Wait()
+	return out
+}
+
+// CollectChannel has the goroutines send their values to the one
+// goroutine that appends, sharing memory by communicating
+func CollectChannel(n int) []int {
+	ch := make(chan int)
+	for i := range n {
+		go func() { ch <- i }()
+	}
+	out := make([]int, 0, n)
+	for range n {
+		out = append(out, <-ch)
+	}
+	return out
+}
+
+// LAZY INITIALIZATION: check-then-act
+
+// Config stands for something expensive to build, built on first use
+type Config struct {
+	Name string
+}
+
+// LazyUnsafe builds its Config on the first Get, checking for it without
+// synchronization. Goroutines calling Get together may all see nil and
+// each build one, and may see the pointer before the Config it points to.
+type LazyUnsafe struct {
+	config *Config
+	Builds int // how many Configs were built
+}
+
+// Get returns the Config, building it if no call has yet
+func (l *LazyUnsafe) Get() *Config {
+	if l.config == nil { // RACE: unsynchronized check
+		l.Builds++
+		l.config = &Config{Name: "app"} // RACE: and act
+	}
+	return l.config
+}
+
+// LazyOnce builds its Config in a sync.Once, which runs the build once
+// and makes every Get wait for it to finish
+type LazyOnce struct {
+	once   sync.Once
+	config *Config
+	Builds int
+}
+
+// Get returns the Config, building it if no call has yet
+func (l *LazyOnce) Get() *Config {
+	l.once.Do(func() {
+		l.Builds++
+		l.config = &Config{Name: "app"}
+	})
+	return l.config
+}
+
+// GetConcurrently calls get from n goroutines and returns the Configs
+// they got
+func GetConcurrently(n int, get func() *Config) []*Config {
+	configs := make([]*Config, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			configs[i] = get()
+		}()
+	}
+	wg.Wait()
+	return configs
+}
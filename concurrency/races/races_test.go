@@ -0,0 +1,52 @@
+package races
+
+import (
+	"slices"
+	"testing"
+)
+
+// The fixed versions, which give the right answer every run. The racy
+// ones are tested in race_test.go, under the race detector.
+
+func TestCounters(t *testing.T) {
+	for name, count := range map[string]func(int) int{"CountMutex": CountMutex, "CountAtomic": CountAtomic} {
+		if got := count(1000); got != 1000 {
+			t.Errorf("%s(1000) = %d; want 1000", name, got)
+		}
+	}
+}
+
+func TestCollect(t *testing.T) {
+	want := make([]int, 1000)
+	for i := range want {
+		want[i] = i
+	}
+	for name, collect := range map[string]func(int) []int{"CollectIndexed": CollectIndexed, "CollectChannel": CollectChannel} {
+		got := collect(1000)
+		slices.Sort(got)
+		if !slices.Equal(got, want) {
+			t.Errorf("%s(1000) has %d values, not each of 0..999 once", name, len(got))
+		}
+	}
+}
+
+func TestLazyOnce(t *testing.T) {
+	var l LazyOnce
+	configs := GetConcurrently(100, l.Get)
+	if l.Builds != 1 {
+		t.Errorf("Builds = %d; want 1", l.Builds)
+	}
+	for _, c := range configs {
+		if c != configs[0] || c.Name != "app" {
+			t.Fatalf("got configs %p and %p; want every Get to return the one built", configs[0], c)
+		}
+	}
+}
+
+// LazyUnsafe is only wrong when Get is called concurrently
+func TestLazyUnsafe_OneGoroutine(t *testing.T) {
+	var l LazyUnsafe
+	if a, b := l.Get(), l.Get(); a != b || l.Builds != 1 {
+		t.Errorf("two Gets returned %p and %p after %d builds; want one Config", a, b, l.Builds)
+	}
+}
@@ -0,0 +1,303 @@
+package runtimeintrospection
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/quiz"
+)
+
+// Run reports on the scheduler, goroutines and memory to w
+func Run(w io.Writer) error {
+	fmt.Fprintln(w, "=========================================")
+	fmt.Fprintln(w, "GO SCHEDULER AND RUNTIME INTROSPECTION")
+	fmt.Fprintln(w, "=========================================")
+
+	// Goroutine counts and GOMAXPROCS
+	RuntimeInfoExample(w)
+
+	// Cooperative yielding on a single P
+	SchedulingExample(w)
+
+	// Profiler labels
+	PprofLabelsExample(w)
+
+	// Stack dumps for finding leaks
+	StackDumpExample(w)
+
+	// Execution tracing
+	TraceExample(w)
+
+	// Exercises about the GMP model
+	GMPExercises(w)
+
+	// Interview questions
+	RuntimeInterviewQuestions(w)
+	return nil
+}
+
+// RuntimeInfo is a snapshot of scheduler-related settings
+type RuntimeInfo struct {
+	GOMAXPROCS   int
+	NumCPU       int
+	NumGoroutine int
+	GoVersion    string
+}
+
+// CurrentRuntimeInfo reads the current runtime settings.
+// GOMAXPROCS(0) queries the value without changing it.
+func CurrentRuntimeInfo() RuntimeInfo {
+	return RuntimeInfo{
+		GOMAXPROCS:   runtime.GOMAXPROCS(0),
+		NumCPU:       runtime.NumCPU(),
+		NumGoroutine: runtime.NumGoroutine(),
+		GoVersion:    runtime.Version(),
+	}
+}
+
+// GoroutineDelta starts n goroutines that block until released and
+// returns how many extra goroutines the runtime reported while they ran
+func GoroutineDelta(n int) int {
+	before := runtime.NumGoroutine()
+
+	release := make(chan struct{})
+	var started, finished sync.WaitGroup
+	for i := 0; i < n; i++ {
+		started.Add(1)
+		finished.Add(1)
+		go func() {
+			defer finished.Done()
+			started.Done()
+			<-release
+		}()
+	}
+	started.Wait()
+	during := runtime.NumGoroutine()
+
+	close(release)
+	finished.Wait()
+	return during - before
+}
+
+// Interleave runs two goroutines on a single P (GOMAXPROCS=1). Each appends
+// its name and calls runtime.Gosched to yield the P, so the scheduler
+// alternates between them. GOMAXPROCS is restored afterwards.
+func Interleave(steps int) []string {
+	prev := runtime.GOMAXPROCS(1)
+	defer runtime.GOMAXPROCS(prev)
+
+	var (
+		mu    sync.Mutex
+		order []string
+		wg    sync.WaitGroup
+	)
+	worker := func(name string) {
+		defer wg.Done()
+		for i := 0; i < steps; i++ {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			runtime.Gosched()
+		}
+	}
+	wg.Add(2)
+	go worker("A")
+	go worker("B")
+	wg.Wait()
+	return order
+}
+
+// LabeledWork runs fn with pprof labels attached. CPU profile samples taken
+// while fn runs carry the labels, so a profile can be filtered by, say,
+// tenant or endpoint. Goroutines started inside fn inherit them.
+func LabeledWork(ctx context.Context, worker string, fn func(context.Context)) {
+	pprof.Do(ctx, pprof.Labels("worker", worker), fn)
+}
+
+// WorkerLabel reads the "worker" label back from a context
+func WorkerLabel(ctx context.Context) (string, bool) {
+	return pprof.Label(ctx, "worker")
+}
+
+// DumpGoroutineStacks writes the stack of every goroutine to w, in the same
+// format as an unrecovered panic or SIGQUIT
+func DumpGoroutineStacks(w io.Writer) error {
+	return pprof.Lookup("goroutine").WriteTo(w, 2)
+}
+
+// CountGoroutinesIn returns how many goroutines have a frame whose function
+// name contains fn. Tests use it to detect goroutine leaks.
+func CountGoroutinesIn(fn string) int {
+	var buf bytes.Buffer
+	_ = DumpGoroutineStacks(&buf)
+
+	count := 0
+	for _, g := range strings.Split(buf.String(), "\n\n") {
+		if strings.Contains(g, fn) {
+			count++
+		}
+	}
+	return count
+}
+
+// leakyWorkerFrame matches leakyWorker in a stack dump. The package part of
+// the name is "main" in a binary but the import path in a test binary.
+const leakyWorkerFrame = ".leakyWorker("
+
+// leakyWorker blocks forever on a channel nobody sends to
+func leakyWorker(started *sync.WaitGroup, ch <-chan int) {
+	started.Done()
+	<-ch
+}
+
+// StartLeakyWorkers starts n goroutines that never exit until the
+// returned stop function is called. It returns once all of them are
+// running inside leakyWorker.
+func StartLeakyWorkers(n int) (stop func()) {
+	ch := make(chan int)
+	var started sync.WaitGroup
+	started.Add(n)
+	for i := 0; i < n; i++ {
+		go leakyWorker(&started, ch)
+	}
+	started.Wait()
+	return func() { close(ch) }
+}
+
+// WaitForGoroutines polls until at most want goroutines match fn, or the
+// timeout passes. Goroutines exit asynchronously, so a single check right
+// after stopping them is flaky.
+func WaitForGoroutines(fn string, want int, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for {
+		if CountGoroutinesIn(fn) <= want {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// CaptureTrace records an execution trace of fn to w. View it with
+// `go tool trace <file>`. Tasks and regions show up as named spans.
+func CaptureTrace(w io.Writer, fn func(context.Context)) error {
+	if err := trace.Start(w); err != nil {
+		return err
+	}
+	defer trace.Stop()
+
+	ctx, task := trace.NewTask(context.Background(), "example")
+	defer task.End()
+	fn(ctx)
+	return nil
+}
+
+// RuntimeInfoExample prints scheduler settings and goroutine counts
+func RuntimeInfoExample(w io.Writer) {
+	fmt.Fprintln(w, "=== RUNTIME INFO EXAMPLE ===")
+
+	info := CurrentRuntimeInfo()
+	fmt.Fprintf(w, "Go %s, GOMAXPROCS=%d, NumCPU=%d, goroutines=%d\n",
+		info.GoVersion, info.GOMAXPROCS, info.NumCPU, info.NumGoroutine)
+	fmt.Fprintln(w, "Extra goroutines while 100 are blocked:", GoroutineDelta(100))
+	fmt.Fprintln(w)
+}
+
+// SchedulingExample shows Gosched alternating two goroutines
+func SchedulingExample(w io.Writer) {
+	fmt.Fprintln(w, "=== SCHEDULING WITH GOMAXPROCS=1 EXAMPLE ===")
+
+	fmt.Fprintln(w, "Order:", strings.Join(Interleave(4), " "))
+	fmt.Fprintln(w, "(Since Go 1.14 tight loops are also preempted asynchronously)")
+	fmt.Fprintln(w)
+}
+
+// PprofLabelsExample attaches and reads profiler labels
+func PprofLabelsExample(w io.Writer) {
+	fmt.Fprintln(w, "=== PPROF LABELS EXAMPLE ===")
+
+	LabeledWork(context.Background(), "image-resizer", func(ctx context.Context) {
+		label, _ := WorkerLabel(ctx)
+		fmt.Fprintln(w, "Running with worker label:", label)
+	})
+	fmt.Fprintln(w)
+}
+
+// StackDumpExample finds leaked goroutines in a stack dump
+func StackDumpExample(w io.Writer) {
+	fmt.Fprintln(w, "=== GOROUTINE STACK DUMP EXAMPLE ===")
+
+	stop := StartLeakyWorkers(3)
+	fmt.Fprintln(w, "Goroutines blocked in leakyWorker:", CountGoroutinesIn(leakyWorkerFrame))
+	stop()
+	fmt.Fprintln(w, "Cleaned up:", WaitForGoroutines(leakyWorkerFrame, 0, time.Second))
+
+	var buf bytes.Buffer
+	_ = DumpGoroutineStacks(&buf)
+	firstLine, _, _ := strings.Cut(buf.String(), "\n")
+	fmt.Fprintln(w, "First line of dump:", firstLine)
+	fmt.Fprintln(w)
+}
+
+// TraceExample writes an execution trace to a temporary file
+func TraceExample(w io.Writer) {
+	fmt.Fprintln(w, "=== RUNTIME/TRACE EXAMPLE ===")
+
+	f, err := os.CreateTemp("", "trace-*.out")
+	if err != nil {
+		fmt.Fprintln(w, "Error:", err)
+		return
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	err = CaptureTrace(f, func(ctx context.Context) {
+		trace.WithRegion(ctx, "fan-out", func() {
+			GoroutineDelta(10)
+		})
+	})
+	if err != nil {
+		fmt.Fprintln(w, "Error:", err)
+		return
+	}
+	info, _ := f.Stat()
+	fmt.Fprintf(w, "Wrote %d bytes of trace (removed on exit)\n", info.Size())
+	fmt.Fprintln(w, "To keep one, write it to trace.out and run: go tool trace trace.out")
+	fmt.Fprintln(w)
+}
+
+// GMPExercises prints exercises about the scheduler's G, M and P
+func GMPExercises(w io.Writer) {
+	fmt.Fprintln(w, "=========================================")
+	fmt.Fprintln(w, "EXERCISES: THE GMP MODEL")
+	fmt.Fprintln(w, "=========================================")
+
+	fmt.Fprintln(w, "G = goroutine, M = OS thread, P = processor (a run queue plus the right to run Go code)")
+	fmt.Fprintln(w, "An M must hold a P to run a G; there are exactly GOMAXPROCS Ps")
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, "1. Run SchedulingExample without runtime.Gosched. What changes, and why?")
+	fmt.Fprintln(w, "2. GoroutineDelta(100_000): watch memory. Why are goroutines cheap (hint: 2 KB growable stacks)?")
+	fmt.Fprintln(w, "3. A G blocks in a syscall. What does the scheduler do with its P? (hand-off to another M)")
+	fmt.Fprintln(w, "4. Capture a trace of Interleave and find the Gosched calls in `go tool trace`")
+	fmt.Fprintln(w, "5. Why can GOMAXPROCS be higher than the number of running threads, and vice versa?")
+	fmt.Fprintln(w)
+}
+
+// RuntimeInterviewQuestions lists common interview questions about the scheduler
+func RuntimeInterviewQuestions(w io.Writer) {
+	if err := quiz.Print(w, "runtime-introspection"); err != nil {
+		fmt.Fprintln(w, err)
+	}
+}
@@ -0,0 +1,98 @@
+package runtimeintrospection
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCurrentRuntimeInfo(t *testing.T) {
+	info := CurrentRuntimeInfo()
+	if info.GOMAXPROCS < 1 || info.NumCPU < 1 || info.NumGoroutine < 1 {
+		t.Errorf("CurrentRuntimeInfo() = %+v; want positive values", info)
+	}
+	if !strings.HasPrefix(info.GoVersion, "go") && !strings.HasPrefix(info.GoVersion, "devel") {
+		t.Errorf("GoVersion = %q; want a go version", info.GoVersion)
+	}
+}
+
+func TestGoroutineDelta(t *testing.T) {
+	// Other goroutines (e.g. the test runner's) may come and go, so allow slack
+	if got := GoroutineDelta(50); got < 50 || got > 55 {
+		t.Errorf("GoroutineDelta(50) = %d; want about 50", got)
+	}
+}
+
+func TestInterleave(t *testing.T) {
+	before := runtime.GOMAXPROCS(0)
+	order := Interleave(5)
+
+	if runtime.GOMAXPROCS(0) != before {
+		t.Errorf("GOMAXPROCS not restored: %d; want %d", runtime.GOMAXPROCS(0), before)
+	}
+
+	counts := map[string]int{}
+	for _, name := range order {
+		counts[name]++
+	}
+	if counts["A"] != 5 || counts["B"] != 5 {
+		t.Errorf("order = %v; want 5 steps from each goroutine", order)
+	}
+}
+
+func TestLabeledWork(t *testing.T) {
+	ran := false
+	LabeledWork(context.Background(), "resizer", func(ctx context.Context) {
+		ran = true
+		if label, ok := WorkerLabel(ctx); !ok || label != "resizer" {
+			t.Errorf("WorkerLabel() = %q, %t; want resizer, true", label, ok)
+		}
+	})
+	if !ran {
+		t.Fatal("fn was not called")
+	}
+
+	if _, ok := WorkerLabel(context.Background()); ok {
+		t.Error("label present on a context without labels")
+	}
+}
+
+func TestDumpGoroutineStacks(t *testing.T) {
+	var buf bytes.Buffer
+	if err := DumpGoroutineStacks(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "TestDumpGoroutineStacks") {
+		t.Error("dump does not contain the current test's stack")
+	}
+}
+
+func TestLeakDetection(t *testing.T) {
+	fn := leakyWorkerFrame
+	baseline := CountGoroutinesIn(fn)
+
+	stop := StartLeakyWorkers(4)
+	if got := CountGoroutinesIn(fn) - baseline; got != 4 {
+		t.Errorf("leaked goroutines = %d; want 4", got)
+	}
+
+	stop()
+	if !WaitForGoroutines(fn, baseline, time.Second) {
+		t.Errorf("goroutines still running after stop: %d", CountGoroutinesIn(fn))
+	}
+}
+
+func TestCaptureTrace(t *testing.T) {
+	var buf bytes.Buffer
+	called := false
+	err := CaptureTrace(&buf, func(ctx context.Context) { called = true })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !called || buf.Len() == 0 {
+		t.Errorf("called = %t, trace bytes = %d; want true and a non-empty trace", called, buf.Len())
+	}
+}
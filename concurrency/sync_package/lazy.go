@@ -0,0 +1,80 @@
+package syncpackage
+
+import (
+	"sync"
+)
+
+// Lazy holds a value that is computed on first use.
+// It is safe for concurrent use; the init function runs at most once.
+// If init panics, Get panics with the same value on that call and every
+// later one.
+type Lazy[T any] struct {
+	once  sync.Once
+	init  func() T
+	value T
+	valid bool
+	p     any // what init panicked with, if it did
+}
+
+// NewLazy returns a Lazy that computes its value with init on the first Get
+func NewLazy[T any](init func() T) *Lazy[T] {
+	return &Lazy[T]{init: init}
+}
+
+// Get returns the value, computing it if this is the first call.
+// Concurrent callers block until the first computation finishes.
+func (l *Lazy[T]) Get() T {
+	l.once.Do(func() {
+		defer func() {
+			l.p = recover()
+			if !l.valid {
+				panic(l.p)
+			}
+		}()
+		l.value = l.init()
+		l.init = nil // allow the closure and anything it captured to be collected
+		l.valid = true
+	})
+	if !l.valid {
+		panic(l.p)
+	}
+	return l.value
+}
+
+// OnceFunc returns a function that calls f only the first time it is invoked.
+// It mirrors sync.OnceFunc (Go 1.21): if f panics, every call panics with
+// the same value.
+func OnceFunc(f func()) func() {
+	get := OnceValue(func() struct{} {
+		f()
+		return struct{}{}
+	})
+	return func() {
+		get()
+	}
+}
+
+// OnceValue returns a function that calls f once and then returns its result on every call.
+// It mirrors sync.OnceValue (Go 1.21): if f panics, every call panics with
+// the same value.
+func OnceValue[T any](f func() T) func() T {
+	l := NewLazy(f)
+	return l.Get
+}
+
+// OnceValues is OnceValue for functions that also return an error.
+// The error is cached too: a failed initialization is not retried.
+func OnceValues[T any](f func() (T, error)) func() (T, error) {
+	type result struct {
+		value T
+		err   error
+	}
+	get := OnceValue(func() result {
+		v, err := f()
+		return result{v, err}
+	})
+	return func() (T, error) {
+		r := get()
+		return r.value, r.err
+	}
+}
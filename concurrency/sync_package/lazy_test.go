@@ -0,0 +1,161 @@
+package syncpackage
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+const concurrentCallers = 1000
+
+// callConcurrently runs f from n goroutines released at the same moment
+func callConcurrently(n int, f func()) {
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			f()
+		}()
+	}
+	close(start)
+	wg.Wait()
+}
+
+func TestLazy_InitializesOnce(t *testing.T) {
+	var calls atomic.Int32
+	lazy := NewLazy(func() int {
+		calls.Add(1)
+		return 42
+	})
+
+	var wrong atomic.Int32
+	callConcurrently(concurrentCallers, func() {
+		if lazy.Get() != 42 {
+			wrong.Add(1)
+		}
+	})
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("init called %d times; want 1", got)
+	}
+	if got := wrong.Load(); got != 0 {
+		t.Errorf("%d callers saw a value other than 42", got)
+	}
+}
+
+func TestLazy_NotCalledUntilGet(t *testing.T) {
+	called := false
+	lazy := NewLazy(func() string {
+		called = true
+		return "x"
+	})
+	if called {
+		t.Fatal("init ran before Get")
+	}
+	if got := lazy.Get(); got != "x" || !called {
+		t.Errorf("Get() = %q, called = %v; want \"x\", true", got, called)
+	}
+}
+
+func TestOnceFunc(t *testing.T) {
+	var calls atomic.Int32
+	f := OnceFunc(func() { calls.Add(1) })
+
+	callConcurrently(concurrentCallers, f)
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("f called %d times; want 1", got)
+	}
+}
+
+func TestOnceValue(t *testing.T) {
+	var calls atomic.Int32
+	get := OnceValue(func() []string {
+		calls.Add(1)
+		return []string{"a", "b"}
+	})
+
+	var wrong atomic.Int32
+	callConcurrently(concurrentCallers, func() {
+		if v := get(); len(v) != 2 {
+			wrong.Add(1)
+		}
+	})
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("f called %d times; want 1", got)
+	}
+	if got := wrong.Load(); got != 0 {
+		t.Errorf("%d callers saw an unexpected value", got)
+	}
+}
+
+func TestOnceValue_RepanicsEveryCall(t *testing.T) {
+	var calls atomic.Int32
+	get := OnceValue(func() int {
+		calls.Add(1)
+		panic("boom")
+	})
+	// recovered returns what a call to get panicked with
+	recovered := func() (p any) {
+		defer func() { p = recover() }()
+		get()
+		return nil
+	}
+
+	for i := range 3 {
+		if p := recovered(); p != "boom" {
+			t.Errorf("call %d panicked with %v; want boom", i+1, p)
+		}
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("f called %d times; want 1", got)
+	}
+
+	run := OnceFunc(func() { panic("once") })
+	for i := range 2 {
+		func() {
+			defer func() {
+				if p := recover(); p != "once" {
+					t.Errorf("OnceFunc call %d panicked with %v; want once", i+1, p)
+				}
+			}()
+			run()
+		}()
+	}
+}
+
+func TestOnceValues_CachesError(t *testing.T) {
+	errBoom := errors.New("boom")
+	var calls atomic.Int32
+	get := OnceValues(func() (int, error) {
+		calls.Add(1)
+		return 0, errBoom
+	})
+
+	var wrong atomic.Int32
+	callConcurrently(concurrentCallers, func() {
+		if _, err := get(); !errors.Is(err, errBoom) {
+			wrong.Add(1)
+		}
+	})
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("f called %d times; want 1 (errors must not trigger a retry)", got)
+	}
+	if got := wrong.Load(); got != 0 {
+		t.Errorf("%d callers did not see the cached error", got)
+	}
+}
+
+func TestOnceValues_Success(t *testing.T) {
+	get := OnceValues(func() (string, error) { return "ready", nil })
+	v, err := get()
+	if v != "ready" || err != nil {
+		t.Errorf("get() = %q, %v; want \"ready\", nil", v, err)
+	}
+}
@@ -1,6 +1,10 @@
 package main
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/rehan/go-interview-prep/pkg/debug/assert"
+)
 
 func main() {
 	arr := []int{23, 54, 24, 1, 4, 3, 6, 90, 21, 87, 546, 42, 12, 45, 87, 1, 2, 7, 8, 0}
@@ -9,7 +13,7 @@ func main() {
 }
 
 func mergeSort(arr []int) []int {
-	if len(arr) == 1 {
+	if len(arr) <= 1 {
 		return arr
 	}
 	mid := len(arr) / 2
@@ -19,6 +23,8 @@ func mergeSort(arr []int) []int {
 	return merge(left, right)
 }
 func merge(first []int, second []int) []int {
+	assert.Invariant("merge input", isSorted(first))
+	assert.Invariant("merge input", isSorted(second))
 	mixed := make([]int, len(first)+len(second))
 	i := 0
 	j := 0
@@ -48,7 +54,7 @@ func merge(first []int, second []int) []int {
 }
 
 func mergeSortWithIndex(arr []int, s, e int) {
-	if e-s == 1 {
+	if e-s <= 1 {
 		return
 	}
 	mid := (s + e) / 2
@@ -58,6 +64,9 @@ func mergeSortWithIndex(arr []int, s, e int) {
 	mergeInPlace(arr, mid, s, e)
 }
 func mergeInPlace(arr []int, mid, s, e int) {
+	assert.Require(0 <= s && s <= mid && mid <= e && e <= len(arr), "mergeInPlace bounds s=%d mid=%d e=%d len=%d", s, mid, e, len(arr))
+	assert.Invariant("left half", isSorted(arr[s:mid]))
+	assert.Invariant("right half", isSorted(arr[mid:e]))
 	mix := make([]int, e-s)
 	i := s
 	j := mid
@@ -82,6 +91,7 @@ func mergeInPlace(arr []int, mid, s, e int) {
 		k++
 		j++
 	}
+	assert.Assert(k == e-s, "merged %d of %d elements", k, e-s)
 	for l := 0; l < len(mix); l++ {
 		arr[s+l] = mix[l]
 	}
@@ -109,7 +119,22 @@ func quickSort(arr []int, low, high int) {
 			e--
 		}
 	}
+	// Partitioning leaves e < s, everything up to e <= pivot <= everything from s
+	assert.Assert(e < s, "partition crossed: e=%d s=%d", e, s)
 	quickSort(arr, low, e)
 	quickSort(arr, s, high)
+	assert.Invariant("sorted range", isSorted(arr[low:high+1]))
+
+}
 
+// isSorted returns an invariant check that arr is in ascending order
+func isSorted(arr []int) func() error {
+	return func() error {
+		for i := 1; i < len(arr); i++ {
+			if arr[i-1] > arr[i] {
+				return fmt.Errorf("arr[%d]=%d > arr[%d]=%d", i-1, arr[i-1], i, arr[i])
+			}
+		}
+		return nil
+	}
 }
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/rehan/go-interview-prep/pkg/debug/assert"
+	"github.com/rehan/go-interview-prep/pkg/quickcheck"
+)
+
+// Run every test with the invariants in main.go checked
+func TestMain(m *testing.M) {
+	assert.SetEnabled(true)
+	os.Exit(m.Run())
+}
+
+var sorts = []struct {
+	name string
+	sort func([]int) []int
+}{
+	{"mergeSort", mergeSort},
+	{"mergeSortWithIndex", func(arr []int) []int {
+		mergeSortWithIndex(arr, 0, len(arr))
+		return arr
+	}},
+	{"quickSort", func(arr []int) []int {
+		quickSort(arr, 0, len(arr)-1)
+		return arr
+	}},
+}
+
+func TestSorts(t *testing.T) {
+	inputs := [][]int{
+		{},
+		{1},
+		{2, 1},
+		{1, 2, 3, 4},
+		{4, 3, 2, 1},
+		{5, 5, 5},
+		{3, -1, 3, 0, -1},
+		{23, 54, 24, 1, 4, 3, 6, 90, 21, 87, 546, 42, 12, 45, 87, 1, 2, 7, 8, 0},
+	}
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 50; i++ {
+		in := make([]int, rng.Intn(100))
+		for j := range in {
+			in[j] = rng.Intn(20) - 10
+		}
+		inputs = append(inputs, in)
+	}
+
+	for _, s := range sorts {
+		t.Run(s.name, func(t *testing.T) {
+			for _, in := range inputs {
+				want := slices.Clone(in)
+				slices.Sort(want)
+				if got := s.sort(slices.Clone(in)); !slices.Equal(got, want) {
+					t.Errorf("%s(%v) = %v; want %v", s.name, in, got, want)
+				}
+			}
+		})
+	}
+}
+
+// TestSorts_Properties checks each sort returns a sorted permutation of
+// its input, for inputs that are not compared with slices.Sort's answer
+func TestSorts_Properties(t *testing.T) {
+	for _, s := range sorts {
+		t.Run(s.name, func(t *testing.T) {
+			quickcheck.Check(t, quickcheck.SliceOf(quickcheck.Int()), func(in []int) error {
+				got := s.sort(slices.Clone(in))
+				if !slices.IsSorted(got) {
+					return fmt.Errorf("got %v, which is not sorted", got)
+				}
+				count := make(map[int]int)
+				for _, n := range in {
+					count[n]++
+				}
+				for _, n := range got {
+					count[n]--
+				}
+				for _, c := range count {
+					if c != 0 {
+						return fmt.Errorf("got %v, which is not a permutation of the input", got)
+					}
+				}
+				return nil
+			})
+		})
+	}
+}
+
+func TestMergeInPlace_RequiresSortedHalves(t *testing.T) {
+	defer func() {
+		if _, ok := recover().(*assert.Failure); !ok {
+			t.Error("merging an unsorted half did not fail an invariant")
+		}
+	}()
+	mergeInPlace([]int{2, 1, 3}, 2, 0, 3)
+}
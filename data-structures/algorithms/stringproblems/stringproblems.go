@@ -0,0 +1,191 @@
+// Package stringproblems solves the string questions that come up most
+// often in coding interviews. Every function works on runes rather than
+// bytes, so multi-byte UTF-8 input such as "héllo" or "日本" is handled as
+// characters; combining sequences (e plus a combining accent) are still
+// separate runes, as Unicode normalization is out of scope.
+package stringproblems
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ReverseWords returns the words of s in reverse order separated by single
+// spaces. Words are split on any Unicode white space, so leading, trailing
+// and repeated spaces disappear: "  the sky  is blue " -> "blue is sky the".
+func ReverseWords(s string) string {
+	words := strings.Fields(s)
+	slices.Reverse(words)
+	return strings.Join(words, " ")
+}
+
+// IsAnagram reports whether a and b contain the same runes the same number
+// of times. The comparison is case-sensitive and counts spaces.
+//
+// One map counts up for a and down for b; any count left non-zero means a
+// mismatch. O(n) time, O(k) space for k distinct runes.
+func IsAnagram(a, b string) bool {
+	if utf8.RuneCountInString(a) != utf8.RuneCountInString(b) {
+		return false
+	}
+	counts := make(map[rune]int)
+	for _, r := range a {
+		counts[r]++
+	}
+	for _, r := range b {
+		counts[r]--
+		if counts[r] < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// GroupAnagrams partitions words into groups of anagrams of each other.
+// Groups appear in the order of their first word, and words keep their
+// input order within a group, so the result is deterministic.
+//
+// The key of a group is the word's runes in sorted order: O(n·k log k)
+// for n words of length k.
+func GroupAnagrams(words []string) [][]string {
+	index := make(map[string]int) // key -> position in groups
+	var groups [][]string
+	for _, w := range words {
+		runes := []rune(w)
+		slices.Sort(runes)
+		key := string(runes)
+
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], w)
+	}
+	return groups
+}
+
+// Compress run-length encodes s as each rune followed by its repeat count:
+// "aabcccccaaa" -> "a2b1c5a3". If the encoding is not shorter than s, s is
+// returned unchanged. Lengths are compared in runes.
+//
+// Digits in the input make the encoding ambiguous to decode, which the
+// classic statement avoids by limiting input to letters.
+func Compress(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	b.Grow(len(s))
+	outLen := 0 // in runes
+
+	for i := 0; i < len(runes); {
+		j := i
+		for j < len(runes) && runes[j] == runes[i] {
+			j++
+		}
+		count := strconv.Itoa(j - i)
+		b.WriteRune(runes[i])
+		b.WriteString(count)
+		i = j
+
+		// Stop as soon as the output is no longer a saving
+		outLen += 1 + len(count)
+		if outLen >= len(runes) {
+			return s
+		}
+	}
+	return b.String()
+}
+
+// ErrRomanRange is returned by IntToRoman for numbers outside 1-3999,
+// which standard Roman numerals cannot write
+var ErrRomanRange = errors.New("roman numerals cover 1 to 3999")
+
+// romanValues lists each symbol, including the subtractive pairs, from
+// largest to smallest, so converting is a greedy walk down the table
+var romanValues = []struct {
+	value  int
+	symbol string
+}{
+	{1000, "M"}, {900, "CM"}, {500, "D"}, {400, "CD"},
+	{100, "C"}, {90, "XC"}, {50, "L"}, {40, "XL"},
+	{10, "X"}, {9, "IX"}, {5, "V"}, {4, "IV"}, {1, "I"},
+}
+
+// IntToRoman writes n as a Roman numeral: 1994 -> "MCMXCIV"
+func IntToRoman(n int) (string, error) {
+	if n < 1 || n > 3999 {
+		return "", fmt.Errorf("%w: %d", ErrRomanRange, n)
+	}
+	var b strings.Builder
+	for _, rv := range romanValues {
+		for n >= rv.value {
+			b.WriteString(rv.symbol)
+			n -= rv.value
+		}
+	}
+	return b.String(), nil
+}
+
+// Errors returned by Atoi, mirroring strconv.ErrSyntax and strconv.ErrRange
+var (
+	ErrSyntax = errors.New("invalid syntax")
+	ErrRange  = errors.New("value out of range")
+)
+
+// Atoi parses a base-10 int64 the way strconv.ParseInt(s, 10, 64) does:
+// an optional sign followed by at least one ASCII digit, nothing else.
+// Out-of-range input returns the nearest limit with ErrRange.
+//
+// Overflow is caught before it happens: accumulating the magnitude as a
+// uint64 leaves room for -MinInt64, which has no positive int64 twin.
+func Atoi(s string) (int64, error) {
+	orig := s
+	neg := false
+	if s != "" && (s[0] == '+' || s[0] == '-') {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+	if s == "" {
+		return 0, fmt.Errorf("atoi %q: %w", orig, ErrSyntax)
+	}
+
+	limit := uint64(math.MaxInt64)
+	if neg {
+		limit++ // |MinInt64|
+	}
+
+	var n uint64
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < '0' || c > '9' {
+			return 0, fmt.Errorf("atoi %q: %w", orig, ErrSyntax)
+		}
+		d := uint64(c - '0')
+		if n > (limit-d)/10 {
+			// Keep scanning so "999...9x" reports the syntax error first,
+			// as strconv does
+			for _, c := range s[i+1:] {
+				if c < '0' || c > '9' {
+					return 0, fmt.Errorf("atoi %q: %w", orig, ErrSyntax)
+				}
+			}
+			if neg {
+				return math.MinInt64, fmt.Errorf("atoi %q: %w", orig, ErrRange)
+			}
+			return math.MaxInt64, fmt.Errorf("atoi %q: %w", orig, ErrRange)
+		}
+		n = n*10 + d
+	}
+
+	if neg {
+		// Two's complement negation through uint64 also yields MinInt64
+		return -int64(n), nil
+	}
+	return int64(n), nil
+}
@@ -0,0 +1,228 @@
+package stringproblems
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestReverseWords(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"the sky is blue", "blue is sky the"},
+		{"  hello world  ", "world hello"},
+		{"a good   example", "example good a"},
+		{"single", "single"},
+		{"", ""},
+		{"   ", ""},
+		{"tab\tand\nnewline", "newline and tab"},
+		{"héllo 世界", "世界 héllo"},
+		{"no break space", "space break no"}, // U+00A0 is white space to unicode.IsSpace
+	}
+	for _, tc := range tests {
+		if got := ReverseWords(tc.in); got != tc.want {
+			t.Errorf("ReverseWords(%q) = %q; want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestIsAnagram(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"anagram", "nagaram", true},
+		{"rat", "car", false},
+		{"", "", true},
+		{"a", "", false},
+		{"aab", "abb", false}, // same letters, different counts
+		{"ab", "abc", false},
+		{"Listen", "silent", false}, // case-sensitive
+		{"dormitory", "dirty room", false},
+		{"日本語", "語日本", true},
+		{"héllo", "olléh", true},
+		{"é", "e", false},
+		// Same byte length, different rune counts: "é" is two bytes
+		{"é", "ab", false},
+	}
+	for _, tc := range tests {
+		if got := IsAnagram(tc.a, tc.b); got != tc.want {
+			t.Errorf("IsAnagram(%q, %q) = %v; want %v", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestGroupAnagrams(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []string
+		want [][]string
+	}{
+		{
+			"classic",
+			[]string{"eat", "tea", "tan", "ate", "nat", "bat"},
+			[][]string{{"eat", "tea", "ate"}, {"tan", "nat"}, {"bat"}},
+		},
+		{"empty string", []string{""}, [][]string{{""}}},
+		{"no words", nil, nil},
+		{"duplicates stay", []string{"ab", "ba", "ab"}, [][]string{{"ab", "ba", "ab"}}},
+		{"unicode", []string{"日本", "本日", "日"}, [][]string{{"日本", "本日"}, {"日"}}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := GroupAnagrams(tc.in); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("GroupAnagrams(%q) = %q; want %q", tc.in, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCompress(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"aabcccccaaa", "a2b1c5a3"},
+		{"abc", "abc"},    // a1b1c1 is longer
+		{"aabb", "aabb"},  // a2b2 is the same length
+		{"aaab", "aaab"},  // a3b1 is the same length
+		{"aaaab", "a4b1"}, // one shorter
+		{"", ""},
+		{"a", "a"},
+		{"aaaaaaaaaaaa", "a12"}, // multi-digit counts
+		{"ééééé", "é5"},         // counted in runes, not bytes
+		{"ab日日日日日日", "a1b1日6"},
+	}
+	for _, tc := range tests {
+		if got := Compress(tc.in); got != tc.want {
+			t.Errorf("Compress(%q) = %q; want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestIntToRoman(t *testing.T) {
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{1, "I"},
+		{3, "III"},
+		{4, "IV"},
+		{9, "IX"},
+		{14, "XIV"},
+		{40, "XL"},
+		{58, "LVIII"},
+		{90, "XC"},
+		{400, "CD"},
+		{900, "CM"},
+		{1994, "MCMXCIV"},
+		{2024, "MMXXIV"},
+		{3999, "MMMCMXCIX"},
+	}
+	for _, tc := range tests {
+		if got, err := IntToRoman(tc.n); err != nil || got != tc.want {
+			t.Errorf("IntToRoman(%d) = %q, %v; want %q", tc.n, got, err, tc.want)
+		}
+	}
+	for _, n := range []int{0, -1, 4000, math.MaxInt} {
+		if _, err := IntToRoman(n); !errors.Is(err, ErrRomanRange) {
+			t.Errorf("IntToRoman(%d) error = %v; want ErrRomanRange", n, err)
+		}
+	}
+}
+
+func TestIntToRoman_AllDistinct(t *testing.T) {
+	seen := make(map[string]int)
+	for n := 1; n <= 3999; n++ {
+		s, err := IntToRoman(n)
+		if err != nil {
+			t.Fatalf("IntToRoman(%d): %v", n, err)
+		}
+		if prev, dup := seen[s]; dup {
+			t.Fatalf("IntToRoman(%d) = IntToRoman(%d) = %q", n, prev, s)
+		}
+		seen[s] = n
+	}
+}
+
+func TestAtoi(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr error
+	}{
+		{"0", 0, nil},
+		{"42", 42, nil},
+		{"-42", -42, nil},
+		{"+7", 7, nil},
+		{"007", 7, nil},
+		{"-0", 0, nil},
+		{"9223372036854775807", math.MaxInt64, nil},
+		{"-9223372036854775808", math.MinInt64, nil},
+		{"9223372036854775808", math.MaxInt64, ErrRange},
+		{"-9223372036854775809", math.MinInt64, ErrRange},
+		{"99999999999999999999999", math.MaxInt64, ErrRange},
+		{"", 0, ErrSyntax},
+		{"-", 0, ErrSyntax},
+		{"+-1", 0, ErrSyntax},
+		{" 42", 0, ErrSyntax},
+		{"42 ", 0, ErrSyntax},
+		{"4 2", 0, ErrSyntax},
+		{"12abc", 0, ErrSyntax},
+		{"1e3", 0, ErrSyntax},
+		{"0x1F", 0, ErrSyntax},
+		{"١٢", 0, ErrSyntax}, // Arabic-Indic digits are not ASCII
+		{"99999999999999999999x", 0, ErrSyntax},
+	}
+	for _, tc := range tests {
+		got, err := Atoi(tc.in)
+		if got != tc.want || !errors.Is(err, tc.wantErr) {
+			t.Errorf("Atoi(%q) = %d, %v; want %d, %v", tc.in, got, err, tc.want, tc.wantErr)
+		}
+	}
+}
+
+// Atoi promises to behave like strconv.ParseInt; check the boundaries and
+// every input in the table above against it
+func TestAtoi_MatchesStrconv(t *testing.T) {
+	inputs := []string{"", "+", "-", "0", "-0", "+0", "1", "-1", "12x", "x12"}
+	for _, base := range []int64{math.MaxInt64, math.MinInt64} {
+		s := strconv.FormatInt(base, 10)
+		inputs = append(inputs, s, s+"0", s[:len(s)-1])
+	}
+	for i := int64(-3); i <= 3; i++ {
+		inputs = append(inputs, fmt.Sprint(uint64(math.MaxInt64)+uint64(i)), "-"+fmt.Sprint(uint64(math.MaxInt64)+uint64(i)))
+	}
+
+	for _, in := range inputs {
+		got, err := Atoi(in)
+		want, wantErr := strconv.ParseInt(in, 10, 64)
+		if got != want {
+			t.Errorf("Atoi(%q) = %d; strconv.ParseInt = %d", in, got, want)
+		}
+		var numErr *strconv.NumError
+		errors.As(wantErr, &numErr)
+		switch {
+		case wantErr == nil && err != nil,
+			wantErr != nil && numErr.Err == strconv.ErrSyntax && !errors.Is(err, ErrSyntax),
+			wantErr != nil && numErr.Err == strconv.ErrRange && !errors.Is(err, ErrRange):
+			t.Errorf("Atoi(%q) error = %v; strconv.ParseInt error = %v", in, err, wantErr)
+		}
+	}
+}
+
+func ExampleGroupAnagrams() {
+	fmt.Println(GroupAnagrams([]string{"eat", "tea", "tan", "ate", "nat", "bat"}))
+	// Output: [[eat tea ate] [tan nat] [bat]]
+}
+
+func ExampleAtoi() {
+	fmt.Println(Atoi("-123"))
+	fmt.Println(Atoi("9223372036854775808"))
+	// Output:
+	// -123 <nil>
+	// 9223372036854775807 atoi "9223372036854775808": value out of range
+}
@@ -0,0 +1,162 @@
+package arraysslices
+
+import "os"
+
+func ExampleBasicArraysExample() {
+	BasicArraysExample(os.Stdout)
+	// Output:
+	// === BASIC ARRAYS EXAMPLE ===
+	// Empty array: [0 0 0 0 0]
+	// Prime numbers: [2 3 5 7 11]
+	// Fibonacci numbers: [1 1 2 3 5 8 13]
+	// Colors: [red blue green yellow]
+	// First prime: 2
+	// Last color: yellow
+	// Modified numbers: [10 20 0 0 0]
+	// Length of primes array: 5
+	// Length of fibonacci array: 7
+	// Iterating over colors array:
+	//   Index 0: red
+	//   Index 1: blue
+	//   Index 2: green
+	//   Index 3: yellow
+	// arr1 == arr2: true
+	// arr1 == arr3: false
+}
+
+func ExampleBasicSlicesExample() {
+	BasicSlicesExample(os.Stdout)
+	// Output:
+	// === BASIC SLICES EXAMPLE ===
+	// Empty slice: [] - Length: 0 - Is nil: true
+	// Numbers slice: [1 2 3 4 5] - Length: 5
+	// Names slice: [Alice Bob Charlie] - Length: 3
+	// Original array: [2 3 5 7 11 13]
+	// Slice somePrimes: [3 5 7]
+	// Slice allPrimes: [2 3 5 7 11 13]
+	// Slice firstThreePrimes: [2 3 5]
+	// Slice lastThreePrimes: [7 11 13]
+	// Slice created with make: [0 0 0 0 0]
+	// Slice with capacity: [0 0 0] - Length: 3 - Capacity: 10
+}
+
+func ExampleSliceManipulationExample() {
+	SliceManipulationExample(os.Stdout)
+	// Output:
+	// === SLICE MANIPULATION EXAMPLE ===
+	// Original slice: [1 2 3]
+	// After append(numbers, 4): [1 2 3 4]
+	// After append(numbers, 5, 6, 7): [1 2 3 4 5 6 7]
+	// After appending another slice: [1 2 3 4 5 6 7 8 9 10]
+	// After removing first element: [2 3 4 5 6 7 8 9 10]
+	// After removing last element: [2 3 4 5 6 7 8 9]
+	// After removing element at index 2: [2 3 5 6 7 8 9]
+	// After clearing the slice: [] - Length: 0 - Capacity: 11
+}
+
+func ExampleSliceCapacityExample() {
+	SliceCapacityExample(os.Stdout)
+	// Output:
+	// === SLICE CAPACITY AND GROWTH EXAMPLE ===
+	// Initial slice: len=0 cap=5 []
+	// After appending 1: len=1 cap=5 [1]
+	// After appending 2: len=2 cap=5 [1 2]
+	// After appending 3: len=3 cap=5 [1 2 3]
+	// After appending 4: len=4 cap=5 [1 2 3 4]
+	// After appending 5: len=5 cap=5 [1 2 3 4 5]
+	// After appending 6: len=6 cap=10 [1 2 3 4 5 6]
+	// After appending 7: len=7 cap=10 [1 2 3 4 5 6 7]
+	// After appending 8: len=8 cap=10 [1 2 3 4 5 6 7 8]
+	// After appending 9: len=9 cap=10 [1 2 3 4 5 6 7 8 9]
+	// After appending 10: len=10 cap=10 [1 2 3 4 5 6 7 8 9 10]
+	// Pre-allocated slice: len=0 cap=1000
+	// After 1000 appends: len=1000 cap=1000
+}
+
+func ExampleSliceMemorySharingExample() {
+	SliceMemorySharingExample(os.Stdout)
+	// Output:
+	// === SLICE MEMORY SHARING EXAMPLE ===
+	// Original slice: [1 2 3 4 5]
+	// Shared slice: [2 3 4]
+	// Original after modifying shared: [1 99 3 4 5]
+	// Shared after modification: [99 3 4]
+	// Shared capacity: 4
+	// Original after append to shared: [1 99 3 4 100]
+	// Shared after append: [99 3 4 100]
+	// Original numbers: [1 2 3 4 5]
+	// Copy after modification: [99 2 3 4 5]
+}
+
+func ExampleMultidimensionalSlicesExample() {
+	MultidimensionalSlicesExample(os.Stdout)
+	// Output:
+	// === MULTIDIMENSIONAL SLICES EXAMPLE ===
+	// 2D matrix: [[1 2 3] [4 5 6] [7 8 9]]
+	// Element at row 1, col 2: 6
+	// Modified matrix: [[99 2 3] [4 5 6] [7 8 9]]
+	// Created grid: [[0 1 2 3] [4 5 6 7] [8 9 10 11]]
+	// Iterating over the grid:
+	//   grid[0][0] = 0
+	//   grid[0][1] = 1
+	//   grid[0][2] = 2
+	//   grid[0][3] = 3
+	//   grid[1][0] = 4
+	//   grid[1][1] = 5
+	//   grid[1][2] = 6
+	//   grid[1][3] = 7
+	//   grid[2][0] = 8
+	//   grid[2][1] = 9
+	//   grid[2][2] = 10
+	//   grid[2][3] = 11
+}
+
+func ExampleSliceSortingExample() {
+	SliceSortingExample(os.Stdout)
+	// Output:
+	// === SLICE SORTING EXAMPLE ===
+	// Original numbers: [5 2 9 1 3 6]
+	// Sorted numbers: [1 2 3 5 6 9]
+	// Original names: [Charlie Alice Bob David]
+	// Sorted names: [Alice Bob Charlie David]
+	// People sorted by age: [{David 20} {Bob 25} {Alice 30} {Charlie 35}]
+	// People sorted by name: [{Alice 30} {Bob 25} {Charlie 35} {David 20}]
+}
+
+func ExampleCommonSliceOperationsExample() {
+	CommonSliceOperationsExample(os.Stdout)
+	// Output:
+	// === COMMON SLICE OPERATIONS EXAMPLE ===
+	// Even numbers: [2 4 6 8 10]
+	// Doubled numbers: [2 4 6 8 10 12 14 16 18 20]
+	// Slice contains 5: true
+	// Sum of elements: 55
+	// String as runes: [72 101 108 108 111 44 32 19990 30028]
+	// Back to string: Hello, 世界
+	// Joined string: Hello-Go-World
+	// Split string: [Hello Go World]
+}
+
+func ExamplePerformanceConsiderationsExample() {
+	PerformanceConsiderationsExample(os.Stdout)
+	// Output:
+	// === PERFORMANCE CONSIDERATIONS ===
+	// 1. Pre-allocation
+	//    - Use make() with capacity when size is known
+	//    - Avoids multiple reallocations during growth
+	//
+	// 2. Avoiding Unnecessary Copies
+	//    - Be careful with large slices in function parameters
+	//    - Use pointers for large slices if modification needed
+	//    - Pass slice by value if no modification needed (slices are references)
+	//
+	// 3. Memory Leaks
+	//    - Slices can cause memory leaks by holding references
+	//    - Use copy() to create a new slice without references
+	//    - Be mindful of very large backing arrays
+	//
+	// 4. Slice Tricks
+	//    - Append is efficient due to growth strategy
+	//    - Filter in place for better memory efficiency
+	//    - Slicing doesn't copy elements, only creates a view
+}
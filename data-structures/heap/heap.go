@@ -0,0 +1,97 @@
+// Package heap is a binary heap used as a priority queue, for the
+// interview questions on the k largest elements, merging k sorted lists,
+// scheduling and running medians. It keeps the tree in a slice: the
+// children of index i are at 2i+1 and 2i+2, and every element comes no
+// later than its children by the heap's ordering, so the first one is
+// always next. Push and Pop are O(log n), Peek O(1).
+//
+// container/heap does the same through an interface the caller's slice
+// implements; this one is generic and does the sifting itself, which is
+// what an interviewer usually asks to see.
+package heap
+
+// Heap orders elements by a less function: Pop returns the element no
+// other is less than. The zero value is not usable; call New.
+type Heap[T any] struct {
+	items []T
+	less  func(a, b T) bool
+}
+
+// New returns a heap ordered by less, holding items; less(a, b) reports
+// whether a comes out before b, so cmp.Less makes a min-heap. items is
+// reordered in place in O(n) and used as the heap's storage.
+func New[T any](less func(a, b T) bool, items ...T) *Heap[T] {
+	h := &Heap[T]{items: items, less: less}
+	for i := len(items)/2 - 1; i >= 0; i-- {
+		h.down(i)
+	}
+	return h
+}
+
+// Len returns the number of elements
+func (h *Heap[T]) Len() int {
+	return len(h.items)
+}
+
+// Push adds x
+func (h *Heap[T]) Push(x T) {
+	h.items = append(h.items, x)
+	h.up(len(h.items) - 1)
+}
+
+// Peek returns the next element Pop would return, and false if the heap
+// is empty
+func (h *Heap[T]) Peek() (T, bool) {
+	if len(h.items) == 0 {
+		var zero T
+		return zero, false
+	}
+	return h.items[0], true
+}
+
+// Pop removes and returns the first element, and false if the heap is
+// empty
+func (h *Heap[T]) Pop() (T, bool) {
+	var zero T
+	if len(h.items) == 0 {
+		return zero, false
+	}
+	top := h.items[0]
+	last := len(h.items) - 1
+	h.items[0] = h.items[last]
+	h.items[last] = zero // let the garbage collector have it
+	h.items = h.items[:last]
+	h.down(0)
+	return top, true
+}
+
+// up moves the element at i towards the root until its parent comes first
+func (h *Heap[T]) up(i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !h.less(h.items[i], h.items[parent]) {
+			return
+		}
+		h.items[i], h.items[parent] = h.items[parent], h.items[i]
+		i = parent
+	}
+}
+
+// down moves the element at i towards the leaves until it comes before
+// both children
+func (h *Heap[T]) down(i int) {
+	n := len(h.items)
+	for {
+		first := i
+		for _, child := range []int{2*i + 1, 2*i + 2} {
+			if child < n && h.less(h.items[child], h.items[first]) {
+				first = child
+			}
+		}
+		if first == i {
+			return
+		}
+		h.items[i], h.items[first] = h.items[first], h.items[i]
+		i = first
+	}
+}
@@ -0,0 +1,127 @@
+package heap
+
+import (
+	"cmp"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestHeap_PopsInOrder(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	for _, n := range []int{0, 1, 2, 3, 10, 100} {
+		in := make([]int, n)
+		for i := range in {
+			in[i] = r.IntN(50) // with repeats
+		}
+		want := slices.Sorted(slices.Values(in))
+
+		pushed := New[int](cmp.Less)
+		for _, v := range in {
+			pushed.Push(v)
+		}
+		built := New(cmp.Less, slices.Clone(in)...)
+		for name, h := range map[string]*Heap[int]{"Push": pushed, "New": built} {
+			if h.Len() != n {
+				t.Errorf("%s: Len() = %d; want %d", name, h.Len(), n)
+			}
+			var got []int
+			for {
+				peeked, _ := h.Peek()
+				v, ok := h.Pop()
+				if !ok {
+					break
+				}
+				if v != peeked {
+					t.Errorf("%s: Pop() = %d after Peek() = %d", name, v, peeked)
+				}
+				got = append(got, v)
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("%s of %v: popped %v; want %v", name, in, got, want)
+			}
+		}
+	}
+}
+
+func TestHeap_Empty(t *testing.T) {
+	h := New(cmp.Less[string])
+	if v, ok := h.Peek(); ok {
+		t.Errorf("Peek() = %q, true; want false", v)
+	}
+	if v, ok := h.Pop(); ok {
+		t.Errorf("Pop() = %q, true; want false", v)
+	}
+}
+
+func Example() {
+	h := New(cmp.Less[int])
+	for _, v := range []int{5, 1, 8, 3} {
+		h.Push(v)
+	}
+	var sorted []int
+	for h.Len() > 0 {
+		v, _ := h.Pop()
+		sorted = append(sorted, v)
+	}
+	fmt.Println(sorted)
+	// Output: [1 3 5 8]
+}
+
+// The k largest elements: a min-heap of the k largest so far, whose top is
+// the one to replace, is O(n log k) rather than sorting's O(n log n)
+func Example_kLargest() {
+	nums, k := []int{3, 2, 1, 5, 6, 4, 9, 7}, 3
+	h := New(cmp.Less, slices.Clone(nums[:k])...)
+	for _, v := range nums[k:] {
+		if smallest, _ := h.Peek(); v > smallest {
+			h.Pop()
+			h.Push(v)
+		}
+	}
+	kth, _ := h.Peek()
+	fmt.Println("3rd largest:", kth)
+	// Output: 3rd largest: 6
+}
+
+// Merging k sorted lists: the heap holds the next element of each list,
+// so each step takes the smallest of k in O(log k)
+func Example_mergeSorted() {
+	lists := [][]int{{1, 4, 5}, {1, 3, 4}, {2, 6}}
+	type next struct{ list, index int }
+	value := func(n next) int { return lists[n.list][n.index] }
+	h := New(func(a, b next) bool { return value(a) < value(b) })
+	for i := range lists {
+		h.Push(next{i, 0})
+	}
+	var merged []int
+	for h.Len() > 0 {
+		n, _ := h.Pop()
+		merged = append(merged, value(n))
+		if n.index+1 < len(lists[n.list]) {
+			h.Push(next{n.list, n.index + 1})
+		}
+	}
+	fmt.Println(merged)
+	// Output: [1 1 2 3 4 4 5 6]
+}
+
+// A max-heap is a min-heap with less reversed; here it orders tasks by
+// priority
+func ExampleNew() {
+	type task struct {
+		name     string
+		priority int
+	}
+	tasks := New(func(a, b task) bool { return a.priority > b.priority },
+		task{"write tests", 2}, task{"fix outage", 9}, task{"lunch", 5})
+	for tasks.Len() > 0 {
+		t, _ := tasks.Pop()
+		fmt.Println(t.priority, t.name)
+	}
+	// Output:
+	// 9 fix outage
+	// 5 lunch
+	// 2 write tests
+}
@@ -1,6 +1,11 @@
 package main
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/rehan/go-interview-prep/pkg/debug/assert"
+)
 
 type Node struct {
 	val  int
@@ -32,6 +37,8 @@ func (h *LinkList) addElement(val int) {
 		h.head = newNode
 		return
 	}
+	// Walking a list with a cycle never ends, so check before walking
+	assert.Invariant("acyclic list", h.checkAcyclic)
 	current := h.head
 
 	for current.next != nil {
@@ -42,6 +49,7 @@ func (h *LinkList) addElement(val int) {
 }
 
 func (h *LinkList) display() {
+	assert.Invariant("acyclic list", h.checkAcyclic)
 
 	current := h.head
 	for current != nil {
@@ -49,3 +57,17 @@ func (h *LinkList) display() {
 		current = current.next
 	}
 }
+
+// checkAcyclic reports a cycle using Floyd's tortoise and hare: a fast
+// pointer moving two nodes at a time meets the slow one only in a cycle
+func (h *LinkList) checkAcyclic() error {
+	slow, fast := h.head, h.head
+	for fast != nil && fast.next != nil {
+		slow = slow.next
+		fast = fast.next.next
+		if slow == fast {
+			return errors.New("list contains a cycle")
+		}
+	}
+	return nil
+}
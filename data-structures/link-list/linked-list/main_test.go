@@ -0,0 +1,43 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/rehan/go-interview-prep/pkg/debug/assert"
+)
+
+// Run every test with the invariants in main.go checked
+func TestMain(m *testing.M) {
+	assert.SetEnabled(true)
+	os.Exit(m.Run())
+}
+
+func TestAddElement(t *testing.T) {
+	ll := new(LinkList)
+	for _, v := range []int{2, 4, 45} {
+		ll.addElement(v)
+	}
+	var got []int
+	for n := ll.head; n != nil; n = n.next {
+		got = append(got, n.val)
+	}
+	if len(got) != 3 || got[0] != 2 || got[1] != 4 || got[2] != 45 {
+		t.Errorf("list = %v; want [2 4 45]", got)
+	}
+}
+
+// Without the invariant, addElement on a cyclic list would loop forever
+func TestAddElement_CycleFailsInvariant(t *testing.T) {
+	ll := new(LinkList)
+	ll.addElement(1)
+	ll.addElement(2)
+	ll.head.next.next = ll.head
+
+	defer func() {
+		if _, ok := recover().(*assert.Failure); !ok {
+			t.Error("a cyclic list passed the invariant")
+		}
+	}()
+	ll.addElement(3)
+}
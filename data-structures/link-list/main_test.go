@@ -0,0 +1,51 @@
+package main
+
+import "fmt"
+
+// A stack gives elements back last in, first out, which is how it
+// reverses a sequence
+func ExampleStack() {
+	s := new(Stack)
+	for _, v := range []int{1, 2, 3} {
+		s.push(v)
+	}
+	fmt.Println("top:", s.topNode())
+	var popped []int
+	for !s.isEmpty() {
+		popped = append(popped, s.pop())
+	}
+	fmt.Println("popped:", popped)
+	fmt.Println("pop on empty:", s.pop())
+	// Output:
+	// top: 3
+	// popped: [3 2 1]
+	// pop on empty: 0
+}
+
+// Checking that brackets are balanced, the classic stack question: each
+// closing bracket must match the opening one pushed last. The stack holds
+// the runes as ints.
+func ExampleStack_balanced() {
+	pairs := map[rune]rune{')': '(', ']': '[', '}': '{'}
+	balanced := func(s string) bool {
+		st := new(Stack)
+		for _, r := range s {
+			switch r {
+			case '(', '[', '{':
+				st.push(int(r))
+			case ')', ']', '}':
+				if st.isEmpty() || rune(st.pop()) != pairs[r] {
+					return false
+				}
+			}
+		}
+		return st.isEmpty()
+	}
+	for _, s := range []string{"{[()]}", "([)]", "(("} {
+		fmt.Println(s, balanced(s))
+	}
+	// Output:
+	// {[()]} true
+	// ([)] false
+	// (( false
+}
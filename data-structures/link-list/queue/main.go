@@ -1,7 +1,10 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+
+	"github.com/rehan/go-interview-prep/pkg/debug/assert"
 )
 
 type Node struct {
@@ -49,6 +52,7 @@ func (q *Queue) addElement(val int) {
 		q.rear = newNode
 	}
 
+	assert.Invariant("queue links", q.checkLinks)
 }
 
 func (q *Queue) removeElement() int {
@@ -61,6 +65,8 @@ func (q *Queue) removeElement() int {
 	if q.front == nil {
 		q.rear = nil
 	}
+
+	assert.Invariant("queue links", q.checkLinks)
 	return val
 
 }
@@ -81,3 +87,22 @@ func (q *Queue) peek() (int, bool) {
 func (q *Queue) isEmpty() bool {
 	return q.front == nil
 }
+
+// checkLinks verifies that front and rear agree: both are nil, or rear is
+// the last node reachable from front
+func (q *Queue) checkLinks() error {
+	if (q.front == nil) != (q.rear == nil) {
+		return fmt.Errorf("front is %v but rear is %v", q.front, q.rear)
+	}
+	if q.front == nil {
+		return nil
+	}
+	last := q.front
+	for last.next != nil {
+		last = last.next
+	}
+	if last != q.rear {
+		return errors.New("rear is not the last node")
+	}
+	return nil
+}
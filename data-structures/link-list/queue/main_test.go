@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/rehan/go-interview-prep/pkg/debug/assert"
+)
+
+// Run every test with the invariants in main.go checked
+func TestMain(m *testing.M) {
+	assert.SetEnabled(true)
+	os.Exit(m.Run())
+}
+
+func TestQueue_FIFO(t *testing.T) {
+	q := new(Queue)
+	for _, v := range []int{1, 2, 3} {
+		q.addElement(v)
+	}
+	for _, want := range []int{1, 2, 3} {
+		if got, ok := q.peek(); !ok || got != want {
+			t.Errorf("peek() = %d, %v; want %d, true", got, ok, want)
+		}
+		if got := q.removeElement(); got != want {
+			t.Errorf("removeElement() = %d; want %d", got, want)
+		}
+	}
+	if !q.isEmpty() {
+		t.Error("queue not empty after removing every element")
+	}
+
+	// Emptying the queue must reset rear, or the next add is lost
+	q.addElement(4)
+	if got, ok := q.peek(); !ok || got != 4 {
+		t.Errorf("peek() after refilling = %d, %v; want 4, true", got, ok)
+	}
+}
+
+func TestQueue_InvariantCatchesStaleRear(t *testing.T) {
+	second := &Node{val: 2}
+	first := &Node{val: 1, next: second}
+	q := &Queue{front: first, rear: first} // rear should be second
+
+	defer func() {
+		if _, ok := recover().(*assert.Failure); !ok {
+			t.Error("a queue with a stale rear passed the invariant")
+		}
+	}()
+	q.removeElement()
+}
+
+// A queue gives elements back first in, first out: here, a round-robin
+// scheduler that puts a job back at the end until its work is done
+func ExampleQueue() {
+	q := new(Queue)
+	work := map[int]int{1: 2, 2: 1, 3: 3} // job: turns it needs
+	for _, job := range []int{1, 2, 3} {
+		q.addElement(job)
+	}
+	var order []int
+	for !q.isEmpty() {
+		job := q.removeElement()
+		order = append(order, job)
+		if work[job]--; work[job] > 0 {
+			q.addElement(job)
+		}
+	}
+	fmt.Println(order)
+	q.addElement(7)
+	fmt.Println(q.peek())
+	// Output:
+	// [1 2 3 1 3 3]
+	// 7 true
+}
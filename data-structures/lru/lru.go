@@ -0,0 +1,75 @@
+// Package lru is a least-recently-used cache, the "design an LRU cache"
+// interview question. A map finds an entry in O(1) and a doubly linked
+// list keeps the entries in order of use, most recent at the front, so
+// both Get and Put are O(1): a use moves the entry to the front, and a
+// Put into a full cache evicts the entry at the back.
+package lru
+
+import "container/list"
+
+// Cache holds up to a fixed number of entries, evicting the least recently
+// used to make room. It is not safe for concurrent use; guard it with a
+// mutex, as Get changes the order too.
+type Cache[K comparable, V any] struct {
+	capacity int
+	order    *list.List // of *entry[K, V], most recently used first
+	items    map[K]*list.Element
+}
+
+type entry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// New returns an empty cache holding up to capacity entries, at least 1
+func New[K comparable, V any](capacity int) *Cache[K, V] {
+	return &Cache[K, V]{
+		capacity: max(capacity, 1),
+		order:    list.New(),
+		items:    make(map[K]*list.Element),
+	}
+}
+
+// Get returns the value for key and whether there is one, making it the
+// most recently used entry
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	e, ok := c.items[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*entry[K, V]).value, true
+}
+
+// Put sets the value for key, making it the most recently used entry. If
+// that leaves the cache over capacity, the least recently used entry is
+// evicted and returned.
+func (c *Cache[K, V]) Put(key K, value V) (evicted K, ok bool) {
+	if e, ok := c.items[key]; ok {
+		e.Value.(*entry[K, V]).value = value
+		c.order.MoveToFront(e)
+		return evicted, false
+	}
+	c.items[key] = c.order.PushFront(&entry[K, V]{key, value})
+	if c.order.Len() <= c.capacity {
+		return evicted, false
+	}
+	oldest := c.order.Remove(c.order.Back()).(*entry[K, V])
+	delete(c.items, oldest.key)
+	return oldest.key, true
+}
+
+// Len returns the number of entries
+func (c *Cache[K, V]) Len() int {
+	return c.order.Len()
+}
+
+// Keys returns the keys from most to least recently used
+func (c *Cache[K, V]) Keys() []K {
+	keys := make([]K, 0, c.order.Len())
+	for e := c.order.Front(); e != nil; e = e.Next() {
+		keys = append(keys, e.Value.(*entry[K, V]).key)
+	}
+	return keys
+}
@@ -0,0 +1,83 @@
+package lru
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestCache(t *testing.T) {
+	c := New[string, int](2)
+	c.Put("a", 1)
+	c.Put("b", 2)
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Errorf(`Get("a") = %d, %v; want 1, true`, v, ok)
+	}
+	// "b" is now the least recently used
+	if evicted, ok := c.Put("c", 3); !ok || evicted != "b" {
+		t.Errorf(`Put("c") evicted %q, %v; want "b", true`, evicted, ok)
+	}
+	if _, ok := c.Get("b"); ok {
+		t.Error(`Get("b") after eviction = _, true`)
+	}
+	// Updating a key uses it and evicts nothing
+	if _, ok := c.Put("a", 10); ok {
+		t.Error(`Put of an existing key evicted an entry`)
+	}
+	if got, want := c.Keys(), []string{"a", "c"}; !slices.Equal(got, want) {
+		t.Errorf("Keys() = %q; want %q", got, want)
+	}
+	if v, _ := c.Get("a"); v != 10 {
+		t.Errorf(`Get("a") = %d; want 10`, v)
+	}
+	if c.Len() != 2 {
+		t.Errorf("Len() = %d; want 2", c.Len())
+	}
+}
+
+func TestNew_CapacityAtLeastOne(t *testing.T) {
+	c := New[int, int](0)
+	c.Put(1, 1)
+	if evicted, ok := c.Put(2, 2); !ok || evicted != 1 {
+		t.Errorf("Put(2) evicted %d, %v; want 1, true", evicted, ok)
+	}
+	if c.Len() != 1 {
+		t.Errorf("Len() = %d; want 1", c.Len())
+	}
+}
+
+// The LeetCode 146 sequence: capacity 2, and -1 for a miss
+func Example() {
+	cache := New[int, int](2)
+	get := func(key int) int {
+		if v, ok := cache.Get(key); ok {
+			return v
+		}
+		return -1
+	}
+	cache.Put(1, 1)
+	cache.Put(2, 2)
+	fmt.Println(get(1))
+	cache.Put(3, 3) // evicts 2, used less recently than 1
+	fmt.Println(get(2))
+	cache.Put(4, 4) // evicts 1
+	fmt.Println(get(1), get(3), get(4))
+	// Output:
+	// 1
+	// -1
+	// -1 3 4
+}
+
+func ExampleCache_Put() {
+	cache := New[string, string](2)
+	cache.Put("/index.html", "<h1>Home</h1>")
+	cache.Put("/about.html", "<h1>About</h1>")
+	cache.Get("/index.html")
+	if evicted, ok := cache.Put("/blog.html", "<h1>Blog</h1>"); ok {
+		fmt.Println("evicted", evicted)
+	}
+	fmt.Println(cache.Keys())
+	// Output:
+	// evicted /about.html
+	// [/blog.html /index.html]
+}
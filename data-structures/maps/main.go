@@ -1,56 +1,61 @@
-package main
+package maps
 
 import (
 	"fmt"
+	"io"
 	"sort"
 	"sync"
+
+	"github.com/rehan/go-interview-prep/pkg/quiz"
 )
 
-func main() {
-	fmt.Println("=========================================")
-	fmt.Println("GO MAPS EXAMPLES")
-	fmt.Println("=========================================")
+// Run prints the map examples to w
+func Run(w io.Writer) error {
+	fmt.Fprintln(w, "=========================================")
+	fmt.Fprintln(w, "GO MAPS EXAMPLES")
+	fmt.Fprintln(w, "=========================================")
 
 	// Basic map examples
-	BasicMapsExample()
+	BasicMapsExample(w)
 
 	// Map operations
-	MapOperationsExample()
+	MapOperationsExample(w)
 
 	// Maps with complex keys
-	ComplexKeysExample()
+	ComplexKeysExample(w)
 
 	// Maps of maps
-	MapsOfMapsExample()
+	MapsOfMapsExample(w)
 
 	// Concurrent map access
-	ConcurrentMapAccessExample()
+	ConcurrentMapAccessExample(w)
 
 	// Maps with structs
-	MapsWithStructsExample()
+	MapsWithStructsExample(w)
 
 	// Maps performance considerations
-	MapsPerformanceExample()
+	MapsPerformanceExample(w)
 
 	// Common map operations
-	CommonMapOperationsExample()
+	CommonMapOperationsExample(w)
 
 	// Map gotchas and tips
-	MapGotchasAndTipsExample()
+	MapGotchasAndTipsExample(w)
 
 	// Interview questions
-	MapsInterviewQuestions()
+	MapsInterviewQuestions(w)
+	return nil
 }
 
 // BasicMapsExample demonstrates map declaration and initialization
-func BasicMapsExample() {
-	fmt.Println("=== BASIC MAPS EXAMPLE ===")
+func BasicMapsExample(w io.Writer) {
+	fmt.Fprintln(w, "=== BASIC MAPS EXAMPLE ===")
 
 	// Declaring and initializing maps
 	var emptyMap map[string]int // nil map (zero value)
 
-	fmt.Println("Empty map:", emptyMap)
-	fmt.Println("Empty map is nil:", emptyMap == nil)
+	fmt.Fprintln(w, "Empty map:", emptyMap)
+	fmt.Fprintln(w, "Empty map is nil:", emptyMap == nil)
 
 	// Creating a map with make
 	ages := make(map[string]int)
@@ -60,8 +65,8 @@ func BasicMapsExample() {
 	ages["Bob"] = 25
 	ages["Charlie"] = 35
 
-	fmt.Println("Ages map:", ages)
-	fmt.Println("Number of entries:", len(ages))
+	fmt.Fprintln(w, "Ages map:", ages)
+	fmt.Fprintln(w, "Number of entries:", len(ages))
 
 	// Map literal syntax
 	scores := map[string]int{
@@ -69,24 +74,24 @@ func BasicMapsExample() {
 		"Bob":     80,
 		"Charlie": 90,
 	}
-	fmt.Println("Scores map:", scores)
+	fmt.Fprintln(w, "Scores map:", scores)
 
 	// Empty map with make
 	emptyButNotNil := make(map[string]int)
-	fmt.Println("Empty but not nil map:", emptyButNotNil)
-	fmt.Println("Is nil?", emptyButNotNil == nil)
+	fmt.Fprintln(w, "Empty but not nil map:", emptyButNotNil)
+	fmt.Fprintln(w, "Is nil?", emptyButNotNil == nil)
 
 	// Empty map literal
 	emptyLiteral := map[string]int{}
-	fmt.Println("Empty literal map:", emptyLiteral)
-	fmt.Println("Is nil?", emptyLiteral == nil)
+	fmt.Fprintln(w, "Empty literal map:", emptyLiteral)
+	fmt.Fprintln(w, "Is nil?", emptyLiteral == nil)
 
-	fmt.Println()
+	fmt.Fprintln(w)
 }
 
 // MapOperationsExample demonstrates common map operations
-func MapOperationsExample() {
-	fmt.Println("=== MAP OPERATIONS EXAMPLE ===")
+func MapOperationsExample(w io.Writer) {
+	fmt.Fprintln(w, "=== MAP OPERATIONS EXAMPLE ===")
 
 	// Create a map of fruits and their counts
 	fruits := map[string]int{
@@ -94,64 +99,65 @@ func MapOperationsExample() {
 		"banana": 8,
 		"orange": 3,
 	}
-	fmt.Println("Initial map:", fruits)
+	fmt.Fprintln(w, "Initial map:", fruits)
 
 	// Retrieving values
 	appleCount := fruits["apple"]
-	fmt.Println("Apple count:", appleCount)
+	fmt.Fprintln(w, "Apple count:", appleCount)
 
 	// Retrieving values with "comma ok" idiom
 	orangeCount, exists := fruits["orange"]
-	fmt.Printf("Orange count: %d, exists: %t\n", orangeCount, exists)
+	fmt.Fprintf(w, "Orange count: %d, exists: %t\n", orangeCount, exists)
 
 	// Check for non-existent key
 	grapeCount, exists := fruits["grape"]
-	fmt.Printf("Grape count: %d, exists: %t\n", grapeCount, exists)
+	fmt.Fprintf(w, "Grape count: %d, exists: %t\n", grapeCount, exists)
 
 	// Adding new key-value pairs
 	fruits["grape"] = 10
-	fmt.Println("After adding grape:", fruits)
+	fmt.Fprintln(w, "After adding grape:", fruits)
 
 	// Updating existing entries
 	fruits["apple"] = 7
-	fmt.Println("After updating apple:", fruits)
+	fmt.Fprintln(w, "After updating apple:", fruits)
 
 	// Deleting entries
 	delete(fruits, "banana")
-	fmt.Println("After deleting banana:", fruits)
+	fmt.Fprintln(w, "After deleting banana:", fruits)
 
 	// Deleting non-existent entry (no error)
 	delete(fruits, "pear")
-	fmt.Println("After deleting non-existent key:", fruits)
+	fmt.Fprintln(w, "After deleting non-existent key:", fruits)
 
 	// Zero value for non-existent keys
-	fmt.Println("Requesting non-existent key:", fruits["pear"]) // Returns 0 (zero value for int)
+	fmt.Fprintln(w, "Requesting non-existent key:", fruits["pear"]) // Returns 0 (zero value for int)
 
-	// Iterating over map using range (order is not guaranteed)
-	fmt.Println("Iterating over map:")
-	for key, value := range fruits {
-		fmt.Printf("  %s: %d\n", key, value)
+	// Iterating over map using range (order is not guaranteed, so the
+	// entries are printed in sorted key order)
+	fmt.Fprintln(w, "Iterating over map:")
+	for _, key := range sortedKeys(fruits) {
+		fmt.Fprintf(w, "  %s: %d\n", key, fruits[key])
 	}
 
 	// Iterating over just keys
-	fmt.Println("Keys in the map:")
-	for key := range fruits {
-		fmt.Printf("  %s\n", key)
+	fmt.Fprintln(w, "Keys in the map:")
+	for _, key := range sortedKeys(fruits) {
+		fmt.Fprintf(w, "  %s\n", key)
 	}
 
 	// Clearing a map
 	for k := range fruits {
 		delete(fruits, k)
 	}
-	fmt.Println("After clearing map:", fruits)
-	fmt.Println("Length of cleared map:", len(fruits))
+	fmt.Fprintln(w, "After clearing map:", fruits)
+	fmt.Fprintln(w, "Length of cleared map:", len(fruits))
 
-	fmt.Println()
+	fmt.Fprintln(w)
 }
 
 // ComplexKeysExample demonstrates using complex types as map keys
-func ComplexKeysExample() {
-	fmt.Println("=== COMPLEX KEYS EXAMPLE ===")
+func ComplexKeysExample(w io.Writer) {
+	fmt.Fprintln(w, "=== COMPLEX KEYS EXAMPLE ===")
 
 	// Integer keys
 	intMap := map[int]string{
@@ -159,14 +165,14 @@ func ComplexKeysExample() {
 		2: "two",
 		3: "three",
 	}
-	fmt.Println("Map with integer keys:", intMap)
+	fmt.Fprintln(w, "Map with integer keys:", intMap)
 
 	// Boolean keys
 	boolMap := map[bool]string{
 		true:  "yes",
 		false: "no",
 	}
-	fmt.Println("Map with boolean keys:", boolMap)
+	fmt.Fprintln(w, "Map with boolean keys:", boolMap)
 
 	// Struct as key (must be comparable)
 	type Point struct {
@@ -177,18 +183,18 @@ func ComplexKeysExample() {
 		{1, 2}: "point A",
 		{3, 4}: "point B",
 	}
-	fmt.Println("Map with struct keys:", pointMap)
+	fmt.Fprintln(w, "Map with struct keys:", pointMap)
 
 	// Accessing values with struct keys
 	p := Point{1, 2}
-	fmt.Println("Value for point {1, 2}:", pointMap[p])
+	fmt.Fprintln(w, "Value for point {1, 2}:", pointMap[p])
 
 	// Array as key (fixed size arrays are comparable)
 	arrayMap := map[[3]int]string{
 		{1, 2, 3}: "array 1",
 		{4, 5, 6}: "array 2",
 	}
-	fmt.Println("Map with array keys:", arrayMap)
+	fmt.Fprintln(w, "Map with array keys:", arrayMap)
 
 	// NOT ALLOWED: Slices as keys (uncommenting will cause error)
 	// sliceMap := map[[]int]string{} // Compile error: invalid map key type []int
@@ -207,17 +213,17 @@ func ComplexKeysExample() {
 		"bob":     {"Bob Johnson", 25},
 		"charlie": {"Charlie Brown", 35},
 	}
-	fmt.Println("Map with struct values:", people)
+	fmt.Fprintln(w, "Map with struct values:", people)
 
 	// Accessing struct fields
-	fmt.Printf("Charlie's age: %d\n", people["charlie"].Age)
+	fmt.Fprintf(w, "Charlie's age: %d\n", people["charlie"].Age)
 
-	fmt.Println()
+	fmt.Fprintln(w)
 }
 
 // MapsOfMapsExample demonstrates nested maps
-func MapsOfMapsExample() {
-	fmt.Println("=== MAPS OF MAPS EXAMPLE ===")
+func MapsOfMapsExample(w io.Writer) {
+	fmt.Fprintln(w, "=== MAPS OF MAPS EXAMPLE ===")
 
 	// Declaring a map of maps
 	cityPopulationByCountry := map[string]map[string]int{
@@ -233,22 +239,22 @@ func MapsOfMapsExample() {
 		},
 	}
 
-	fmt.Println("City populations by country:", cityPopulationByCountry)
+	fmt.Fprintln(w, "City populations by country:", cityPopulationByCountry)
 
 	// Accessing nested maps
-	fmt.Println("Population of New York:", cityPopulationByCountry["USA"]["New York"])
+	fmt.Fprintln(w, "Population of New York:", cityPopulationByCountry["USA"]["New York"])
 
 	// You must initialize inner maps before using them
 	cityPopulationByCountry["Canada"] = make(map[string]int)
 	cityPopulationByCountry["Canada"]["Toronto"] = 2930000
 	cityPopulationByCountry["Canada"]["Vancouver"] = 675218
 
-	fmt.Println("After adding Canada:", cityPopulationByCountry)
+	fmt.Fprintln(w, "After adding Canada:", cityPopulationByCountry)
 
 	// Accessing a non-existent nested key safely
 	_, exists := cityPopulationByCountry["UK"]
 	if !exists {
-		fmt.Println("No data for UK")
+		fmt.Fprintln(w, "No data for UK")
 	}
 
 	// This would panic if UK didn't exist and we didn't check:
@@ -257,20 +263,20 @@ func MapsOfMapsExample() {
 	// Safer way to access nested map values
 	population, err := getNestedMapValue(cityPopulationByCountry, "Japan", "Tokyo")
 	if err != nil {
-		fmt.Println("Error:", err)
+		fmt.Fprintln(w, "Error:", err)
 	} else {
-		fmt.Println("Population of Tokyo:", population)
+		fmt.Fprintln(w, "Population of Tokyo:", population)
 	}
 
 	// Try a non-existent path
 	population, err = getNestedMapValue(cityPopulationByCountry, "UK", "London")
 	if err != nil {
-		fmt.Println("Error:", err)
+		fmt.Fprintln(w, "Error:", err)
 	} else {
-		fmt.Println("Population of London:", population)
+		fmt.Fprintln(w, "Population of London:", population)
 	}
 
-	fmt.Println()
+	fmt.Fprintln(w)
 }
 
 // Helper function to safely get values from nested maps
@@ -285,8 +291,8 @@ func getNestedMapValue(m map[string]map[string]int, outerKey, innerKey string) (
 }
 
 // ConcurrentMapAccessExample demonstrates thread-safe map access
-func ConcurrentMapAccessExample() {
-	fmt.Println("=== CONCURRENT MAP ACCESS EXAMPLE ===")
+func ConcurrentMapAccessExample(w io.Writer) {
+	fmt.Fprintln(w, "=== CONCURRENT MAP ACCESS EXAMPLE ===")
 
 	// Regular maps are not safe for concurrent use
 	// This would cause a race condition if run with -race flag:
@@ -340,7 +346,7 @@ func ConcurrentMapAccessExample() {
 	}
 
 	wg.Wait()
-	fmt.Println("Final count:", get("counter"))
+	fmt.Fprintln(w, "Final count:", get("counter"))
 
 	// Using sync.Map for concurrent access
 	var syncMap sync.Map
@@ -351,28 +357,35 @@ func ConcurrentMapAccessExample() {
 			defer wg.Done()
 			key := fmt.Sprintf("key%d", n%10) // Use 10 different keys
 
-			// Load or initialize
+			// Load or initialize, then increment. A plain Store could
+			// overwrite another goroutine's increment, so retry until the
+			// swap succeeds against the value that was loaded.
 			value, _ := syncMap.LoadOrStore(key, 0)
-			// Increment and store
-			syncMap.Store(key, value.(int)+1)
+			for !syncMap.CompareAndSwap(key, value, value.(int)+1) {
+				value, _ = syncMap.Load(key)
+			}
 		}(i)
 	}
 
 	wg.Wait()
 
-	// Print contents of sync.Map
-	fmt.Println("sync.Map contents:")
+	// Print contents of sync.Map, sorted as Range has no order either
+	fmt.Fprintln(w, "sync.Map contents:")
+	contents := make(map[string]int)
 	syncMap.Range(func(key, value interface{}) bool {
-		fmt.Printf("  %v: %v\n", key, value)
+		contents[key.(string)] = value.(int)
 		return true // Continue iteration
 	})
+	for _, key := range sortedKeys(contents) {
+		fmt.Fprintf(w, "  %s: %d\n", key, contents[key])
+	}
 
-	fmt.Println()
+	fmt.Fprintln(w)
 }
 
 // MapsWithStructsExample demonstrates maps with struct values
-func MapsWithStructsExample() {
-	fmt.Println("=== MAPS WITH STRUCTS EXAMPLE ===")
+func MapsWithStructsExample(w io.Writer) {
+	fmt.Fprintln(w, "=== MAPS WITH STRUCTS EXAMPLE ===")
 
 	// Define a struct type
 	type Employee struct {
@@ -398,10 +411,10 @@ func MapsWithStructsExample() {
 		},
 	}
 
-	fmt.Println("Employee map:", employees)
+	fmt.Fprintln(w, "Employee map:", employees)
 
 	// Accessing struct fields
-	fmt.Printf("Alice's position: %s\n", employees["alice"].Position)
+	fmt.Fprintf(w, "Alice's position: %s\n", employees["alice"].Position)
 
 	// Cannot modify struct fields directly through map
 	// This would cause a compile error:
@@ -412,7 +425,7 @@ func MapsWithStructsExample() {
 	alice.Salary += 5000
 	employees["alice"] = alice
 
-	fmt.Printf("Alice's new salary: %.2f\n", employees["alice"].Salary)
+	fmt.Fprintf(w, "Alice's new salary: %.2f\n", employees["alice"].Salary)
 
 	// Using maps with pointers to structs allows direct modification
 	type Department struct {
@@ -438,7 +451,7 @@ func MapsWithStructsExample() {
 	// Now we can modify fields directly
 	departments["engineering"].Budget += 200000
 
-	fmt.Println("Engineering department budget:",
+	fmt.Fprintln(w, "Engineering department budget:",
 		departments["engineering"].Budget)
 
 	// Adding a new department
@@ -449,52 +462,53 @@ func MapsWithStructsExample() {
 	}
 
 	// Printing the departments
-	fmt.Println("Departments:")
-	for name, dept := range departments {
-		fmt.Printf("  %s: %s, Budget: %.2f\n",
+	fmt.Fprintln(w, "Departments:")
+	for _, name := range sortedKeys(departments) {
+		dept := departments[name]
+		fmt.Fprintf(w, "  %s: %s, Budget: %.2f\n",
 			name, dept.Location, dept.Budget)
 	}
 
-	fmt.Println()
+	fmt.Fprintln(w)
 }
 
 // MapsPerformanceExample discusses map performance considerations
-func MapsPerformanceExample() {
-	fmt.Println("=== MAPS PERFORMANCE EXAMPLE ===")
+func MapsPerformanceExample(w io.Writer) {
+	fmt.Fprintln(w, "=== MAPS PERFORMANCE EXAMPLE ===")
 
-	fmt.Println("Map Performance Considerations:")
+	fmt.Fprintln(w, "Map Performance Considerations:")
 
-	fmt.Println("\n1. Initial Capacity")
-	fmt.Println("   - Use make(map[K]V, cap) to pre-allocate for known size")
-	fmt.Println("   - Reduces rehashing operations for better performance")
-	fmt.Println("   - Example: users := make(map[string]User, 10000)")
+	fmt.Fprintln(w, "\n1. Initial Capacity")
+	fmt.Fprintln(w, "   - Use make(map[K]V, cap) to pre-allocate for known size")
+	fmt.Fprintln(w, "   - Reduces rehashing operations for better performance")
+	fmt.Fprintln(w, "   - Example: users := make(map[string]User, 10000)")
 
-	fmt.Println("\n2. Map Growth")
-	fmt.Println("   - Maps grow automatically as needed")
-	fmt.Println("   - Growth triggers rehashing (moving all items)")
-	fmt.Println("   - Pre-allocation helps avoid this cost")
+	fmt.Fprintln(w, "\n2. Map Growth")
+	fmt.Fprintln(w, "   - Maps grow automatically as needed")
+	fmt.Fprintln(w, "   - Growth triggers rehashing (moving all items)")
+	fmt.Fprintln(w, "   - Pre-allocation helps avoid this cost")
 
-	fmt.Println("\n3. Key Types and Performance")
-	fmt.Println("   - Simple keys (int, string) typically perform better")
-	fmt.Println("   - Complex keys (structs) require more computation for hashing")
-	fmt.Println("   - Comparable types only (no slices, maps, functions)")
+	fmt.Fprintln(w, "\n3. Key Types and Performance")
+	fmt.Fprintln(w, "   - Simple keys (int, string) typically perform better")
+	fmt.Fprintln(w, "   - Complex keys (structs) require more computation for hashing")
+	fmt.Fprintln(w, "   - Comparable types only (no slices, maps, functions)")
 
-	fmt.Println("\n4. Access Complexity")
-	fmt.Println("   - Average case O(1) for lookups, insertions, deletions")
-	fmt.Println("   - Worst case O(n) if many hash collisions")
-	fmt.Println("   - Go's implementation uses high-quality hash functions")
+	fmt.Fprintln(w, "\n4. Access Complexity")
+	fmt.Fprintln(w, "   - Average case O(1) for lookups, insertions, deletions")
+	fmt.Fprintln(w, "   - Worst case O(n) if many hash collisions")
+	fmt.Fprintln(w, "   - Go's implementation uses high-quality hash functions")
 
-	fmt.Println("\n5. Memory Usage")
-	fmt.Println("   - Maps have higher memory overhead than arrays or slices")
-	fmt.Println("   - Overhead is due to the hash table structure")
-	fmt.Println("   - Consider this for memory-sensitive applications")
+	fmt.Fprintln(w, "\n5. Memory Usage")
+	fmt.Fprintln(w, "   - Maps have higher memory overhead than arrays or slices")
+	fmt.Fprintln(w, "   - Overhead is due to the hash table structure")
+	fmt.Fprintln(w, "   - Consider this for memory-sensitive applications")
 
-	fmt.Println()
+	fmt.Fprintln(w)
 }
 
 // CommonMapOperationsExample demonstrates frequently used map patterns
-func CommonMapOperationsExample() {
-	fmt.Println("=== COMMON MAP OPERATIONS EXAMPLE ===")
+func CommonMapOperationsExample(w io.Writer) {
+	fmt.Fprintln(w, "=== COMMON MAP OPERATIONS EXAMPLE ===")
 
 	// Counting occurrences
 	words := []string{"apple", "banana", "apple", "orange", "banana", "apple"}
@@ -503,7 +517,7 @@ func CommonMapOperationsExample() {
 	for _, word := range words {
 		counts[word]++
 	}
-	fmt.Println("Word counts:", counts)
+	fmt.Fprintln(w, "Word counts:", counts)
 
 	// Set implementation (map with empty struct values)
 	uniqueWords := map[string]struct{}{}
@@ -511,9 +525,9 @@ func CommonMapOperationsExample() {
 		uniqueWords[word] = struct{}{}
 	}
 
-	fmt.Println("Unique words:")
-	for word := range uniqueWords {
-		fmt.Printf("  %s\n", word)
+	fmt.Fprintln(w, "Unique words:")
+	for _, word := range sortedKeys(uniqueWords) {
+		fmt.Fprintf(w, "  %s\n", word)
 	}
 
 	// Getting sorted keys from a map
@@ -530,9 +544,9 @@ func CommonMapOperationsExample() {
 	}
 	sort.Strings(keys)
 
-	fmt.Println("Sorted fruits by name:")
+	fmt.Fprintln(w, "Sorted fruits by name:")
 	for _, k := range keys {
-		fmt.Printf("  %s: %d\n", k, fruits[k])
+		fmt.Fprintf(w, "  %s: %d\n", k, fruits[k])
 	}
 
 	// Grouping with maps
@@ -552,9 +566,15 @@ func CommonMapOperationsExample() {
 		byAge[person.Age] = append(byAge[person.Age], person.Name)
 	}
 
-	fmt.Println("People grouped by age:")
-	for age, names := range byAge {
-		fmt.Printf("  Age %d: %v\n", age, names)
+	ages := make([]int, 0, len(byAge))
+	for age := range byAge {
+		ages = append(ages, age)
+	}
+	sort.Ints(ages)
+
+	fmt.Fprintln(w, "People grouped by age:")
+	for _, age := range ages {
+		fmt.Fprintf(w, "  Age %d: %v\n", age, byAge[age])
 	}
 
 	// Inverting a map (value -> key)
@@ -569,8 +589,8 @@ func CommonMapOperationsExample() {
 		nameByScore[score] = name
 	}
 
-	fmt.Println("Names by score:", nameByScore)
-	fmt.Println("Note: This only works if values are unique!")
+	fmt.Fprintln(w, "Names by score:", nameByScore)
+	fmt.Fprintln(w, "Note: This only works if values are unique!")
 
 	// Merging maps
 	map1 := map[string]int{"a": 1, "b": 2}
@@ -584,7 +604,7 @@ func CommonMapOperationsExample() {
 		merged[k] = v // Overwrites if key exists
 	}
 
-	fmt.Println("Merged map:", merged)
+	fmt.Fprintln(w, "Merged map:", merged)
 
 	// Finding all keys with a particular value
 	colorMap := map[string]string{
@@ -601,59 +621,71 @@ func CommonMapOperationsExample() {
 			redFruits = append(redFruits, fruit)
 		}
 	}
+	sort.Strings(redFruits)
 
-	fmt.Println("Red fruits:", redFruits)
+	fmt.Fprintln(w, "Red fruits:", redFruits)
 
-	fmt.Println()
+	fmt.Fprintln(w)
 }
 
 // MapGotchasAndTipsExample demonstrates common pitfalls and best practices
-func MapGotchasAndTipsExample() {
-	fmt.Println("=== MAP GOTCHAS AND TIPS EXAMPLE ===")
+func MapGotchasAndTipsExample(w io.Writer) {
+	fmt.Fprintln(w, "=== MAP GOTCHAS AND TIPS EXAMPLE ===")
 
-	fmt.Println("1. Nil map access")
-	fmt.Println("   - Reading from nil map returns zero values")
-	fmt.Println("   - Writing to nil map causes panic")
+	fmt.Fprintln(w, "1. Nil map access")
+	fmt.Fprintln(w, "   - Reading from nil map returns zero values")
+	fmt.Fprintln(w, "   - Writing to nil map causes panic")
 
 	var nilMap map[string]int
-	fmt.Println("   Read from nil map:", nilMap["key"]) // Returns 0
+	fmt.Fprintln(w, "   Read from nil map:", nilMap["key"]) // Returns 0
 	// Uncommenting this line would panic:
 	// nilMap["key"] = 1 // panic: assignment to entry in nil map
 
-	fmt.Println("\n2. Map iteration order")
-	fmt.Println("   - Map iteration order is not guaranteed")
-	fmt.Println("   - Order may change between runs or even iterations")
-	fmt.Println("   - Sort keys first if order matters")
+	fmt.Fprintln(w, "\n2. Map iteration order")
+	fmt.Fprintln(w, "   - Map iteration order is not guaranteed")
+	fmt.Fprintln(w, "   - Order may change between runs or even iterations")
+	fmt.Fprintln(w, "   - Sort keys first if order matters")
 
-	fmt.Println("\n3. Map equality")
-	fmt.Println("   - Maps can't be compared with == except with nil")
-	fmt.Println("   - Must write custom equality function")
+	fmt.Fprintln(w, "\n3. Map equality")
+	fmt.Fprintln(w, "   - Maps can't be compared with == except with nil")
+	fmt.Fprintln(w, "   - Must write custom equality function")
 
 	map1 := map[string]int{"a": 1, "b": 2}
 	map2 := map[string]int{"a": 1, "b": 2}
-	fmt.Println("   Maps equal?", mapEqual(map1, map2))
+	fmt.Fprintln(w, "   Maps equal?", mapEqual(map1, map2))
 
-	fmt.Println("\n4. Zero value behavior")
-	fmt.Println("   - Accessing non-existent key returns zero value")
-	fmt.Println("   - Use 'comma ok' idiom to check existence")
+	fmt.Fprintln(w, "\n4. Zero value behavior")
+	fmt.Fprintln(w, "   - Accessing non-existent key returns zero value")
+	fmt.Fprintln(w, "   - Use 'comma ok' idiom to check existence")
 
 	users := map[string]int{"alice": 30}
 	age := users["bob"] // Returns 0 (zero value for int)
-	fmt.Println("   Bob's age (non-existent key):", age)
+	fmt.Fprintln(w, "   Bob's age (non-existent key):", age)
 
 	age, exists := users["bob"]
-	fmt.Printf("   Bob's age: %d, exists: %t\n", age, exists)
+	fmt.Fprintf(w, "   Bob's age: %d, exists: %t\n", age, exists)
+
+	fmt.Fprintln(w, "\n5. Map capacity")
+	fmt.Fprintln(w, "   - No direct way to get current capacity")
+	fmt.Fprintln(w, "   - No way to shrink a map once it grows")
+	fmt.Fprintln(w, "   - Only way is to create a new map")
 
-	fmt.Println("\n5. Map capacity")
-	fmt.Println("   - No direct way to get current capacity")
-	fmt.Println("   - No way to shrink a map once it grows")
-	fmt.Println("   - Only way is to create a new map")
+	fmt.Fprintln(w, "\n6. Concurrent access")
+	fmt.Fprintln(w, "   - Maps are not safe for concurrent access")
+	fmt.Fprintln(w, "   - Use sync.RWMutex or sync.Map for concurrent use")
 
-	fmt.Println("\n6. Concurrent access")
-	fmt.Println("   - Maps are not safe for concurrent access")
-	fmt.Println("   - Use sync.RWMutex or sync.Map for concurrent use")
+	fmt.Fprintln(w)
+}
 
-	fmt.Println()
+// sortedKeys returns the keys of m in order, for printing a map the same
+// way every time
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
 }
 
 // Helper function to compare two maps
@@ -672,69 +704,8 @@ func mapEqual(m1, m2 map[string]int) bool {
 }
 
 // MapsInterviewQuestions presents common interview questions about maps
-func MapsInterviewQuestions() {
-	fmt.Println("=========================================")
-	fmt.Println("COMMON INTERVIEW QUESTIONS:")
-	fmt.Println("=========================================")
-
-	fmt.Println("1. What is a map in Go and how does it work internally?")
-	fmt.Println("   - Map is a hash table implementation")
-	fmt.Println("   - Stores key-value pairs with O(1) average lookup")
-	fmt.Println("   - Implemented as hash table with buckets for collisions")
-	fmt.Println("   - Uses a high-quality hash function to minimize collisions")
-	fmt.Println()
-
-	fmt.Println("2. What types can be used as map keys in Go?")
-	fmt.Println("   - Only comparable types: bool, numeric, string, pointer, channel")
-	fmt.Println("   - Arrays and structs if their elements are comparable")
-	fmt.Println("   - Cannot use: slices, maps, functions (non-comparable)")
-	fmt.Println()
-
-	fmt.Println("3. How do you check if a key exists in a map?")
-	fmt.Println("   - Use the comma ok idiom: value, ok := map[key]")
-	fmt.Println("   - ok is true if key exists, false otherwise")
-	fmt.Println("   - value will be the zero value if key doesn't exist")
-	fmt.Println()
-
-	fmt.Println("4. What's the time complexity of map operations in Go?")
-	fmt.Println("   - Average case: O(1) for lookup, insert, delete")
-	fmt.Println("   - Worst case: O(n) if many hash collisions")
-	fmt.Println("   - Iteration: O(n) where n is the number of entries")
-	fmt.Println()
-
-	fmt.Println("5. How to make maps safe for concurrent use?")
-	fmt.Println("   - Option 1: Use sync.RWMutex around map operations")
-	fmt.Println("   - Option 2: Use sync.Map for specific concurrent patterns")
-	fmt.Println("   - Option 3: Use channels to coordinate access")
-	fmt.Println()
-
-	fmt.Println("6. What happens when you access a key that doesn't exist?")
-	fmt.Println("   - Returns zero value for the value type")
-	fmt.Println("   - Does not panic or return an error")
-	fmt.Println("   - Use comma ok idiom to check existence")
-	fmt.Println()
-
-	fmt.Println("7. How to implement a set in Go?")
-	fmt.Println("   - Use map with empty struct values: map[KeyType]struct{}")
-	fmt.Println("   - Empty struct takes 0 bytes of memory")
-	fmt.Println("   - Check membership with: _, exists := set[item]")
-	fmt.Println()
-
-	fmt.Println("8. What's the difference between delete(map, key) and map[key] = Zero?")
-	fmt.Println("   - delete removes entry completely, may free memory")
-	fmt.Println("   - setting to zero value keeps entry with zero value")
-	fmt.Println("   - comma ok idiom will return different results")
-	fmt.Println()
-
-	fmt.Println("9. How to get a map's entries in a specific order?")
-	fmt.Println("   - Extract keys to a slice")
-	fmt.Println("   - Sort the keys slice")
-	fmt.Println("   - Iterate over sorted keys and access map")
-	fmt.Println()
-
-	fmt.Println("10. Can a map be used as a key in another map?")
-	fmt.Println("    - No, maps are not comparable")
-	fmt.Println("    - Would need to convert map to a comparable representation")
-	fmt.Println("    - Could use encoding/json or a custom string representation")
-	fmt.Println()
+func MapsInterviewQuestions(w io.Writer) {
+	if err := quiz.Print(w, "maps"); err != nil {
+		fmt.Fprintln(w, err)
+	}
 }
@@ -0,0 +1,191 @@
+package maps
+
+import "os"
+
+func ExampleBasicMapsExample() {
+	BasicMapsExample(os.Stdout)
+	// Output:
+	// === BASIC MAPS EXAMPLE ===
+	// Empty map: map[]
+	// Empty map is nil: true
+	// Ages map: map[Alice:30 Bob:25 Charlie:35]
+	// Number of entries: 3
+	// Scores map: map[Alice:95 Bob:80 Charlie:90]
+	// Empty but not nil map: map[]
+	// Is nil? false
+	// Empty literal map: map[]
+	// Is nil? false
+}
+
+func ExampleMapOperationsExample() {
+	MapOperationsExample(os.Stdout)
+	// Output:
+	// === MAP OPERATIONS EXAMPLE ===
+	// Initial map: map[apple:5 banana:8 orange:3]
+	// Apple count: 5
+	// Orange count: 3, exists: true
+	// Grape count: 0, exists: false
+	// After adding grape: map[apple:5 banana:8 grape:10 orange:3]
+	// After updating apple: map[apple:7 banana:8 grape:10 orange:3]
+	// After deleting banana: map[apple:7 grape:10 orange:3]
+	// After deleting non-existent key: map[apple:7 grape:10 orange:3]
+	// Requesting non-existent key: 0
+	// Iterating over map:
+	//   apple: 7
+	//   grape: 10
+	//   orange: 3
+	// Keys in the map:
+	//   apple
+	//   grape
+	//   orange
+	// After clearing map: map[]
+	// Length of cleared map: 0
+}
+
+func ExampleComplexKeysExample() {
+	ComplexKeysExample(os.Stdout)
+	// Output:
+	// === COMPLEX KEYS EXAMPLE ===
+	// Map with integer keys: map[1:one 2:two 3:three]
+	// Map with boolean keys: map[false:no true:yes]
+	// Map with struct keys: map[{1 2}:point A {3 4}:point B]
+	// Value for point {1, 2}: point A
+	// Map with array keys: map[[1 2 3]:array 1 [4 5 6]:array 2]
+	// Map with struct values: map[alice:{Alice Smith 30} bob:{Bob Johnson 25} charlie:{Charlie Brown 35}]
+	// Charlie's age: 35
+}
+
+func ExampleMapsOfMapsExample() {
+	MapsOfMapsExample(os.Stdout)
+	// Output:
+	// === MAPS OF MAPS EXAMPLE ===
+	// City populations by country: map[Japan:map[Osaka:2691000 Tokyo:13960000 Yokohama:3760000] USA:map[Chicago:2746388 Los Angeles:3898747 New York:8804190]]
+	// Population of New York: 8804190
+	// After adding Canada: map[Canada:map[Toronto:2930000 Vancouver:675218] Japan:map[Osaka:2691000 Tokyo:13960000 Yokohama:3760000] USA:map[Chicago:2746388 Los Angeles:3898747 New York:8804190]]
+	// No data for UK
+	// Population of Tokyo: 13960000
+	// Error: outer key "UK" not found
+}
+
+func ExampleConcurrentMapAccessExample() {
+	ConcurrentMapAccessExample(os.Stdout)
+	// Output:
+	// === CONCURRENT MAP ACCESS EXAMPLE ===
+	// Final count: 1000
+	// sync.Map contents:
+	//   key0: 100
+	//   key1: 100
+	//   key2: 100
+	//   key3: 100
+	//   key4: 100
+	//   key5: 100
+	//   key6: 100
+	//   key7: 100
+	//   key8: 100
+	//   key9: 100
+}
+
+func ExampleMapsWithStructsExample() {
+	MapsWithStructsExample(os.Stdout)
+	// Output:
+	// === MAPS WITH STRUCTS EXAMPLE ===
+	// Employee map: map[alice:{Alice Smith 1001 Software Engineer 90000} bob:{Bob Johnson 1002 Product Manager 95000}]
+	// Alice's position: Software Engineer
+	// Alice's new salary: 95000.00
+	// Engineering department budget: 1.2e+06
+	// Departments:
+	//   engineering: Building A, Budget: 1200000.00
+	//   marketing: Building B, Budget: 500000.00
+	//   sales: Building C, Budget: 750000.00
+}
+
+func ExampleMapsPerformanceExample() {
+	MapsPerformanceExample(os.Stdout)
+	// Output:
+	// === MAPS PERFORMANCE EXAMPLE ===
+	// Map Performance Considerations:
+	//
+	// 1. Initial Capacity
+	//    - Use make(map[K]V, cap) to pre-allocate for known size
+	//    - Reduces rehashing operations for better performance
+	//    - Example: users := make(map[string]User, 10000)
+	//
+	// 2. Map Growth
+	//    - Maps grow automatically as needed
+	//    - Growth triggers rehashing (moving all items)
+	//    - Pre-allocation helps avoid this cost
+	//
+	// 3. Key Types and Performance
+	//    - Simple keys (int, string) typically perform better
+	//    - Complex keys (structs) require more computation for hashing
+	//    - Comparable types only (no slices, maps, functions)
+	//
+	// 4. Access Complexity
+	//    - Average case O(1) for lookups, insertions, deletions
+	//    - Worst case O(n) if many hash collisions
+	//    - Go's implementation uses high-quality hash functions
+	//
+	// 5. Memory Usage
+	//    - Maps have higher memory overhead than arrays or slices
+	//    - Overhead is due to the hash table structure
+	//    - Consider this for memory-sensitive applications
+}
+
+func ExampleCommonMapOperationsExample() {
+	CommonMapOperationsExample(os.Stdout)
+	// Output:
+	// === COMMON MAP OPERATIONS EXAMPLE ===
+	// Word counts: map[apple:3 banana:2 orange:1]
+	// Unique words:
+	//   apple
+	//   banana
+	//   orange
+	// Sorted fruits by name:
+	//   apple: 5
+	//   banana: 8
+	//   grape: 10
+	//   orange: 3
+	// People grouped by age:
+	//   Age 25: [Bob David]
+	//   Age 30: [Alice Charlie]
+	//   Age 35: [Eve]
+	// Names by score: map[80:Bob 90:Charlie 95:Alice]
+	// Note: This only works if values are unique!
+	// Merged map: map[a:1 b:3 c:4]
+	// Red fruits: [apple cherry]
+}
+
+func ExampleMapGotchasAndTipsExample() {
+	MapGotchasAndTipsExample(os.Stdout)
+	// Output:
+	// === MAP GOTCHAS AND TIPS EXAMPLE ===
+	// 1. Nil map access
+	//    - Reading from nil map returns zero values
+	//    - Writing to nil map causes panic
+	//    Read from nil map: 0
+	//
+	// 2. Map iteration order
+	//    - Map iteration order is not guaranteed
+	//    - Order may change between runs or even iterations
+	//    - Sort keys first if order matters
+	//
+	// 3. Map equality
+	//    - Maps can't be compared with == except with nil
+	//    - Must write custom equality function
+	//    Maps equal? true
+	//
+	// 4. Zero value behavior
+	//    - Accessing non-existent key returns zero value
+	//    - Use 'comma ok' idiom to check existence
+	//    Bob's age (non-existent key): 0
+	//    Bob's age: 0, exists: false
+	//
+	// 5. Map capacity
+	//    - No direct way to get current capacity
+	//    - No way to shrink a map once it grows
+	//    - Only way is to create a new map
+	//
+	// 6. Concurrent access
+	//    - Maps are not safe for concurrent access
+	//    - Use sync.RWMutex or sync.Map for concurrent use
+}
@@ -0,0 +1,117 @@
+// Package trie is a prefix tree of words, for the interview questions on
+// autocomplete, word search and longest common prefix. Each node has a
+// child per next rune, so looking up a word or prefix of length m is O(m)
+// however many words the trie holds.
+package trie
+
+import (
+	"slices"
+	"strings"
+)
+
+// Trie is a set of words. The zero value is an empty trie ready to use.
+type Trie struct {
+	root node
+	size int
+}
+
+type node struct {
+	children map[rune]*node
+	word     bool // a word ends here
+}
+
+// Insert adds word, reporting whether it was not already there
+func (t *Trie) Insert(word string) bool {
+	n := &t.root
+	for _, r := range word {
+		if n.children == nil {
+			n.children = make(map[rune]*node)
+		}
+		child, ok := n.children[r]
+		if !ok {
+			child = &node{}
+			n.children[r] = child
+		}
+		n = child
+	}
+	if n.word {
+		return false
+	}
+	n.word = true
+	t.size++
+	return true
+}
+
+// find returns the node at the end of s, or nil if no word starts with s
+func (t *Trie) find(s string) *node {
+	n := &t.root
+	for _, r := range s {
+		if n = n.children[r]; n == nil {
+			return nil
+		}
+	}
+	return n
+}
+
+// Contains reports whether word was inserted
+func (t *Trie) Contains(word string) bool {
+	n := t.find(word)
+	return n != nil && n.word
+}
+
+// HasPrefix reports whether any word starts with prefix
+func (t *Trie) HasPrefix(prefix string) bool {
+	return t.find(prefix) != nil
+}
+
+// Len returns the number of words
+func (t *Trie) Len() int {
+	return t.size
+}
+
+// WithPrefix returns the words starting with prefix in sorted order, at
+// most limit of them if limit is positive
+func (t *Trie) WithPrefix(prefix string, limit int) []string {
+	n := t.find(prefix)
+	if n == nil {
+		return nil
+	}
+	var words []string
+	n.collect([]rune(prefix), &words, limit)
+	return words
+}
+
+// collect appends the words below n, spelled from path, in sorted order
+// until there are limit of them
+func (n *node) collect(path []rune, words *[]string, limit int) {
+	if limit > 0 && len(*words) == limit {
+		return
+	}
+	if n.word {
+		*words = append(*words, string(path))
+	}
+	runes := make([]rune, 0, len(n.children))
+	for r := range n.children {
+		runes = append(runes, r)
+	}
+	slices.Sort(runes)
+	for _, r := range runes {
+		n.children[r].collect(append(path, r), words, limit)
+	}
+}
+
+// LongestCommonPrefix returns the longest prefix every word shares: the
+// path from the root while each node has one child and ends no word
+func (t *Trie) LongestCommonPrefix() string {
+	if t.size == 0 {
+		return ""
+	}
+	var b strings.Builder
+	for n := &t.root; !n.word && len(n.children) == 1; {
+		for r, child := range n.children {
+			b.WriteRune(r)
+			n = child
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,123 @@
+package trie
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestTrie(t *testing.T) {
+	var tr Trie
+	for _, w := range []string{"car", "card", "care", "cat", "dog", "日本", "日本語"} {
+		if !tr.Insert(w) {
+			t.Errorf("Insert(%q) = false; want true", w)
+		}
+	}
+	if tr.Insert("car") {
+		t.Error(`Insert("car") twice = true; want false`)
+	}
+	if tr.Len() != 7 {
+		t.Errorf("Len() = %d; want 7", tr.Len())
+	}
+
+	tests := []struct {
+		s                   string
+		contains, hasPrefix bool
+	}{
+		{"car", true, true},
+		{"ca", false, true},
+		{"cards", false, false},
+		{"", false, true},
+		{"日本", true, true},
+		{"日", false, true},
+		{"x", false, false},
+	}
+	for _, tc := range tests {
+		if got := tr.Contains(tc.s); got != tc.contains {
+			t.Errorf("Contains(%q) = %v; want %v", tc.s, got, tc.contains)
+		}
+		if got := tr.HasPrefix(tc.s); got != tc.hasPrefix {
+			t.Errorf("HasPrefix(%q) = %v; want %v", tc.s, got, tc.hasPrefix)
+		}
+	}
+}
+
+func TestTrie_WithPrefix(t *testing.T) {
+	var tr Trie
+	for _, w := range []string{"tea", "ten", "to", "inn", "tend", "in"} {
+		tr.Insert(w)
+	}
+	tests := []struct {
+		prefix string
+		limit  int
+		want   []string
+	}{
+		{"te", 0, []string{"tea", "ten", "tend"}},
+		{"t", 2, []string{"tea", "ten"}},
+		{"", 0, []string{"in", "inn", "tea", "ten", "tend", "to"}},
+		{"ten", 0, []string{"ten", "tend"}},
+		{"x", 0, nil},
+	}
+	for _, tc := range tests {
+		if got := tr.WithPrefix(tc.prefix, tc.limit); !slices.Equal(got, tc.want) {
+			t.Errorf("WithPrefix(%q, %d) = %q; want %q", tc.prefix, tc.limit, got, tc.want)
+		}
+	}
+}
+
+func TestTrie_LongestCommonPrefix(t *testing.T) {
+	tests := []struct {
+		words []string
+		want  string
+	}{
+		{[]string{"flower", "flow", "flight"}, "fl"},
+		{[]string{"dog", "racecar", "car"}, ""},
+		{[]string{"interview", "internet", "interval"}, "inter"},
+		{[]string{"ab", "abc"}, "ab"},
+		{[]string{"solo"}, "solo"},
+		{nil, ""},
+	}
+	for _, tc := range tests {
+		var tr Trie
+		for _, w := range tc.words {
+			tr.Insert(w)
+		}
+		if got := tr.LongestCommonPrefix(); got != tc.want {
+			t.Errorf("LongestCommonPrefix of %q = %q; want %q", tc.words, got, tc.want)
+		}
+	}
+}
+
+func Example() {
+	var words Trie
+	for _, w := range []string{"apple", "app", "application", "banana"} {
+		words.Insert(w)
+	}
+	fmt.Println(words.Contains("app"), words.Contains("appl"))
+	fmt.Println(words.HasPrefix("appl"), words.HasPrefix("bx"))
+	// Output:
+	// true false
+	// true false
+}
+
+// Autocomplete: the first few words for what has been typed so far
+func ExampleTrie_WithPrefix() {
+	var words Trie
+	for _, w := range []string{"go", "golang", "gopher", "goroutine", "gofmt", "rust"} {
+		words.Insert(w)
+	}
+	fmt.Println(words.WithPrefix("go", 3))
+	fmt.Println(words.WithPrefix("gop", 0))
+	// Output:
+	// [go gofmt golang]
+	// [gopher]
+}
+
+func ExampleTrie_LongestCommonPrefix() {
+	var words Trie
+	for _, w := range []string{"flower", "flow", "flight"} {
+		words.Insert(w)
+	}
+	fmt.Printf("%q\n", words.LongestCommonPrefix())
+	// Output: "fl"
+}
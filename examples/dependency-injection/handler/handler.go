@@ -0,0 +1,92 @@
+// Package handler is the HTTP layer. It translates requests into service
+// calls and errors into status codes, and knows nothing about storage.
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/rehan/go-interview-prep/examples/dependency-injection/user"
+	"github.com/rehan/go-interview-prep/pkg/errorsx"
+)
+
+// UserService is what the handler needs from the service layer
+type UserService interface {
+	Register(ctx context.Context, email, name string) (user.User, error)
+	Get(ctx context.Context, id int) (user.User, error)
+}
+
+// Handler serves the user endpoints
+type Handler struct {
+	svc    UserService
+	logger *log.Logger
+	mux    *http.ServeMux
+}
+
+// New returns a Handler for svc; errors the client cannot see are logged
+// to logger
+func New(svc UserService, logger *log.Logger) *Handler {
+	h := &Handler{svc: svc, logger: logger, mux: http.NewServeMux()}
+	h.mux.HandleFunc("POST /users", h.register)
+	h.mux.HandleFunc("GET /users/{id}", h.get)
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+type registerRequest struct {
+	Email string `json:"email"`
+	Name  string `json:"name"`
+}
+
+func (h *Handler) register(w http.ResponseWriter, r *http.Request) {
+	var req registerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, errorsx.Wrap(err, errorsx.CodeInvalidArgument, "Invalid request body"))
+		return
+	}
+
+	u, err := h.svc.Register(r.Context(), req.Email, req.Name)
+	if err != nil {
+		h.respondWithError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, u)
+}
+
+func (h *Handler) get(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id <= 0 {
+		h.respondWithError(w, errorsx.New(errorsx.CodeInvalidArgument, "Invalid user ID"))
+		return
+	}
+
+	u, err := h.svc.Get(r.Context(), id)
+	if err != nil {
+		h.respondWithError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, u)
+}
+
+func (h *Handler) respondWithError(w http.ResponseWriter, err error) {
+	code := errorsx.CodeOf(err)
+	if code == errorsx.CodeInternal {
+		h.logger.Printf("internal error: %+v", err)
+	}
+	respondWithJSON(w, code.HTTPStatus(), map[string]string{
+		"code":    string(code),
+		"message": errorsx.PublicMessage(err),
+	})
+}
+
+func respondWithJSON(w http.ResponseWriter, status int, payload any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(payload)
+}
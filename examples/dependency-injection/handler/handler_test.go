@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rehan/go-interview-prep/examples/dependency-injection/user"
+	"github.com/rehan/go-interview-prep/pkg/errorsx"
+)
+
+// fakeService returns whatever the test configures and records its inputs
+type fakeService struct {
+	user  user.User
+	err   error
+	calls []string
+}
+
+func (f *fakeService) Register(_ context.Context, email, name string) (user.User, error) {
+	f.calls = append(f.calls, "Register "+email+" "+name)
+	return f.user, f.err
+}
+
+func (f *fakeService) Get(_ context.Context, id int) (user.User, error) {
+	f.calls = append(f.calls, "Get")
+	return f.user, f.err
+}
+
+func serve(h http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	var r io.Reader
+	if body != "" {
+		r = strings.NewReader(body)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, path, r))
+	return rec
+}
+
+func TestRegister(t *testing.T) {
+	svc := &fakeService{user: user.User{ID: 7, Email: "ada@example.com", Name: "Ada"}}
+	h := New(svc, log.New(io.Discard, "", 0))
+
+	rec := serve(h, http.MethodPost, "/users", `{"email": "ada@example.com", "name": "Ada"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d; want %d", rec.Code, http.StatusCreated)
+	}
+	var got user.User
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil || got != svc.user {
+		t.Errorf("body = %+v (err %v); want %+v", got, err, svc.user)
+	}
+	if len(svc.calls) != 1 || svc.calls[0] != "Register ada@example.com Ada" {
+		t.Errorf("service calls = %v", svc.calls)
+	}
+}
+
+func TestErrorMapping(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		svcErr     error
+		wantStatus int
+		wantCalled bool
+	}{
+		{"malformed body", http.MethodPost, "/users", "{", nil, http.StatusBadRequest, false},
+		{"invalid input", http.MethodPost, "/users", `{}`, errorsx.New(errorsx.CodeInvalidArgument, "Name is required"), http.StatusBadRequest, true},
+		{"conflict", http.MethodPost, "/users", `{}`, errorsx.New(errorsx.CodeConflict, "taken"), http.StatusConflict, true},
+		{"bad id", http.MethodGet, "/users/abc", "", nil, http.StatusBadRequest, false},
+		{"not found", http.MethodGet, "/users/9", "", errorsx.New(errorsx.CodeNotFound, "User not found"), http.StatusNotFound, true},
+		{"internal", http.MethodGet, "/users/9", "", errors.New("db exploded"), http.StatusInternalServerError, true},
+		{"wrong method", http.MethodDelete, "/users/9", "", nil, http.StatusMethodNotAllowed, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := &fakeService{err: tc.svcErr}
+			h := New(svc, log.New(io.Discard, "", 0))
+
+			rec := serve(h, tc.method, tc.path, tc.body)
+			if rec.Code != tc.wantStatus {
+				t.Errorf("status = %d; want %d", rec.Code, tc.wantStatus)
+			}
+			if called := len(svc.calls) > 0; called != tc.wantCalled {
+				t.Errorf("service called = %v; want %v", called, tc.wantCalled)
+			}
+		})
+	}
+}
+
+func TestInternalErrorsAreLoggedNotLeaked(t *testing.T) {
+	var logs bytes.Buffer
+	h := New(&fakeService{err: errors.New("db password is hunter2")}, log.New(&logs, "", 0))
+
+	rec := serve(h, http.MethodGet, "/users/1", "")
+	if strings.Contains(rec.Body.String(), "hunter2") {
+		t.Errorf("response leaked internal error: %s", rec.Body.String())
+	}
+	if !strings.Contains(logs.String(), "hunter2") {
+		t.Errorf("internal error not logged: %q", logs.String())
+	}
+}
@@ -0,0 +1,89 @@
+// Package dependencyinjection wires a handler → service → repository stack
+// by hand. Each layer depends on an interface declared by its consumer, and
+// wire is the only place that knows about every concrete type.
+package dependencyinjection
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/rehan/go-interview-prep/examples/dependency-injection/handler"
+	"github.com/rehan/go-interview-prep/examples/dependency-injection/memory"
+	"github.com/rehan/go-interview-prep/examples/dependency-injection/service"
+	"github.com/rehan/go-interview-prep/examples/dependency-injection/user"
+	"github.com/rehan/go-interview-prep/pkg/quiz"
+)
+
+// Run wires the example services together by hand and prints what they
+// do to w
+func Run(w io.Writer) error {
+	fmt.Fprintln(w, "=========================================")
+	fmt.Fprintln(w, "DEPENDENCY INJECTION EXAMPLES")
+	fmt.Fprintln(w, "=========================================")
+
+	logger := log.New(w, "[app] ", 0)
+	app := wire(logger)
+
+	WiringExample(w, app)
+
+	// Interview questions
+	DIInterviewQuestions(w)
+	return nil
+}
+
+// wire builds the application. Swapping the repository for a database
+// implementation, or the notifier for an email client, changes only this
+// function.
+func wire(logger *log.Logger) http.Handler {
+	repo := memory.NewRepository()
+	notifier := logNotifier{logger: logger}
+	svc := service.New(repo, notifier, logger)
+	return handler.New(svc, logger)
+}
+
+// logNotifier "sends" welcome messages by logging them
+type logNotifier struct {
+	logger *log.Logger
+}
+
+func (n logNotifier) Welcome(_ context.Context, u user.User) error {
+	n.logger.Printf("welcome, %s <%s>", u.Name, u.Email)
+	return nil
+}
+
+// WiringExample drives the wired handler with in-process requests
+func WiringExample(w io.Writer, app http.Handler) {
+	fmt.Fprintln(w, "=== WIRED APPLICATION ===")
+
+	requests := []struct {
+		method, path, body string
+	}{
+		{http.MethodPost, "/users", `{"email": "ada@example.com", "name": "Ada"}`},
+		{http.MethodPost, "/users", `{"email": "ada@example.com", "name": "Ada again"}`},
+		{http.MethodPost, "/users", `{"email": "not-an-email", "name": "Bob"}`},
+		{http.MethodGet, "/users/1", ""},
+		{http.MethodGet, "/users/42", ""},
+	}
+	for _, req := range requests {
+		var body io.Reader
+		if req.body != "" {
+			body = strings.NewReader(req.body)
+		}
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, httptest.NewRequest(req.method, req.path, body))
+		fmt.Fprintf(w, "%-4s %-9s -> %d %s", req.method, req.path, rec.Code, rec.Body.String())
+	}
+	fmt.Fprintln(w)
+}
+
+// DIInterviewQuestions lists common interview questions about dependency injection
+func DIInterviewQuestions(w io.Writer) {
+	if err := quiz.Print(w, "dependency-injection"); err != nil {
+		fmt.Fprintln(w, err)
+	}
+}
@@ -0,0 +1,53 @@
+package dependencyinjection
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rehan/go-interview-prep/examples/dependency-injection/user"
+)
+
+// TestWire checks the real implementations fit together end to end
+func TestWire(t *testing.T) {
+	var logs bytes.Buffer
+	app := wire(log.New(&logs, "", 0))
+
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/users",
+		strings.NewReader(`{"email": "ada@example.com", "name": "Ada"}`)))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST /users status = %d; body = %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	var got user.User
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("decoding GET /users/1: %v", err)
+	}
+	if want := (user.User{ID: 1, Email: "ada@example.com", Name: "Ada"}); got != want {
+		t.Errorf("GET /users/1 = %+v; want %+v", got, want)
+	}
+
+	if !strings.Contains(logs.String(), "welcome, Ada") {
+		t.Errorf("logs = %q; want the welcome message", logs.String())
+	}
+}
+
+func TestWire_DuplicateEmailIsConflict(t *testing.T) {
+	app := wire(log.New(&bytes.Buffer{}, "", 0))
+	body := `{"email": "ada@example.com", "name": "Ada"}`
+
+	for i, want := range []int{http.StatusCreated, http.StatusConflict} {
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(body)))
+		if rec.Code != want {
+			t.Errorf("request %d status = %d; want %d", i+1, rec.Code, want)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+// Package memory is an in-memory user repository. It returns a concrete
+// type and declares no interface: the service package says what it needs,
+// and *Repository happens to satisfy it.
+package memory
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/rehan/go-interview-prep/examples/dependency-injection/user"
+)
+
+// Repository stores users in a map guarded by a mutex
+type Repository struct {
+	mu      sync.RWMutex
+	users   map[int]user.User
+	byEmail map[string]int
+	nextID  int
+}
+
+// NewRepository returns an empty Repository
+func NewRepository() *Repository {
+	return &Repository{
+		users:   make(map[int]user.User),
+		byEmail: make(map[string]int),
+		nextID:  1,
+	}
+}
+
+// Create assigns an ID to u and stores it. Emails are compared case-insensitively.
+func (r *Repository) Create(_ context.Context, u user.User) (user.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := strings.ToLower(u.Email)
+	if _, taken := r.byEmail[key]; taken {
+		return user.User{}, user.ErrEmailTaken
+	}
+	u.ID = r.nextID
+	r.nextID++
+	r.users[u.ID] = u
+	r.byEmail[key] = u.ID
+	return u, nil
+}
+
+// Get returns the user with the given ID
+func (r *Repository) Get(_ context.Context, id int) (user.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	u, ok := r.users[id]
+	if !ok {
+		return user.User{}, user.ErrNotFound
+	}
+	return u, nil
+}
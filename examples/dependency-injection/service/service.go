@@ -0,0 +1,82 @@
+// Package service holds the business rules. Its dependencies are the small
+// interfaces declared here, at the point of use, and they arrive through
+// the constructor, so tests can pass fakes and main can pass real
+// implementations.
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/mail"
+	"strings"
+
+	"github.com/rehan/go-interview-prep/examples/dependency-injection/user"
+	"github.com/rehan/go-interview-prep/pkg/errorsx"
+)
+
+// Repository is the storage the service needs
+type Repository interface {
+	Create(ctx context.Context, u user.User) (user.User, error)
+	Get(ctx context.Context, id int) (user.User, error)
+}
+
+// Notifier tells a new user they have registered
+type Notifier interface {
+	Welcome(ctx context.Context, u user.User) error
+}
+
+// Service registers and looks up users
+type Service struct {
+	repo     Repository
+	notifier Notifier
+	logger   *log.Logger
+}
+
+// New wires a Service to its dependencies. All are required; a nil
+// dependency is a programming error, caught here rather than on first use.
+func New(repo Repository, notifier Notifier, logger *log.Logger) *Service {
+	if repo == nil || notifier == nil || logger == nil {
+		panic("service: New called with a nil dependency")
+	}
+	return &Service{repo: repo, notifier: notifier, logger: logger}
+}
+
+// Register validates the input, stores the user and sends a welcome
+// message. A failed notification is logged rather than returned: the user
+// is already stored, so the registration itself succeeded.
+func (s *Service) Register(ctx context.Context, email, name string) (user.User, error) {
+	email = strings.TrimSpace(email)
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return user.User{}, errorsx.New(errorsx.CodeInvalidArgument, "Name is required")
+	}
+	if _, err := mail.ParseAddress(email); err != nil {
+		return user.User{}, errorsx.Wrap(err, errorsx.CodeInvalidArgument, "Email is invalid")
+	}
+
+	u, err := s.repo.Create(ctx, user.User{Email: email, Name: name})
+	if errors.Is(err, user.ErrEmailTaken) {
+		return user.User{}, errorsx.Wrap(err, errorsx.CodeConflict, "Email is already registered")
+	}
+	if err != nil {
+		return user.User{}, errorsx.Wrap(err, errorsx.CodeInternal, "Could not create user")
+	}
+
+	if err := s.notifier.Welcome(ctx, u); err != nil {
+		s.logger.Printf("welcome message for user %d failed: %v", u.ID, err)
+	}
+	return u, nil
+}
+
+// Get returns a user by ID
+func (s *Service) Get(ctx context.Context, id int) (user.User, error) {
+	u, err := s.repo.Get(ctx, id)
+	if errors.Is(err, user.ErrNotFound) {
+		return user.User{}, errorsx.Wrap(err, errorsx.CodeNotFound, "User not found")
+	}
+	if err != nil {
+		return user.User{}, errorsx.Wrap(err, errorsx.CodeInternal, "Could not load user")
+	}
+	return u, nil
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/rehan/go-interview-prep/examples/dependency-injection/user"
+	"github.com/rehan/go-interview-prep/pkg/errorsx"
+)
+
+// fakeRepo records what it was asked to store and returns canned errors
+type fakeRepo struct {
+	created   []user.User
+	createErr error
+	users     map[int]user.User
+	getErr    error
+}
+
+func (f *fakeRepo) Create(_ context.Context, u user.User) (user.User, error) {
+	if f.createErr != nil {
+		return user.User{}, f.createErr
+	}
+	u.ID = len(f.created) + 1
+	f.created = append(f.created, u)
+	return u, nil
+}
+
+func (f *fakeRepo) Get(_ context.Context, id int) (user.User, error) {
+	if f.getErr != nil {
+		return user.User{}, f.getErr
+	}
+	u, ok := f.users[id]
+	if !ok {
+		return user.User{}, user.ErrNotFound
+	}
+	return u, nil
+}
+
+// fakeNotifier records welcomed users
+type fakeNotifier struct {
+	welcomed []user.User
+	err      error
+}
+
+func (f *fakeNotifier) Welcome(_ context.Context, u user.User) error {
+	f.welcomed = append(f.welcomed, u)
+	return f.err
+}
+
+func newTestService(repo *fakeRepo, notifier *fakeNotifier) (*Service, *bytes.Buffer) {
+	var logs bytes.Buffer
+	return New(repo, notifier, log.New(&logs, "", 0)), &logs
+}
+
+func TestRegister(t *testing.T) {
+	repo, notifier := &fakeRepo{}, &fakeNotifier{}
+	svc, _ := newTestService(repo, notifier)
+
+	u, err := svc.Register(context.Background(), "  ada@example.com ", " Ada ")
+	if err != nil {
+		t.Fatalf("Register returned error: %v", err)
+	}
+	want := user.User{ID: 1, Email: "ada@example.com", Name: "Ada"}
+	if u != want {
+		t.Errorf("Register = %+v; want %+v", u, want)
+	}
+	if len(repo.created) != 1 || len(notifier.welcomed) != 1 || notifier.welcomed[0] != want {
+		t.Errorf("created %v, welcomed %v; want the user stored and welcomed once", repo.created, notifier.welcomed)
+	}
+}
+
+func TestRegister_Errors(t *testing.T) {
+	tests := []struct {
+		name         string
+		email, uname string
+		createErr    error
+		wantCode     errorsx.Code
+	}{
+		{"missing name", "ada@example.com", " ", nil, errorsx.CodeInvalidArgument},
+		{"invalid email", "ada", "Ada", nil, errorsx.CodeInvalidArgument},
+		{"email taken", "ada@example.com", "Ada", user.ErrEmailTaken, errorsx.CodeConflict},
+		{"storage failure", "ada@example.com", "Ada", errors.New("disk full"), errorsx.CodeInternal},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			notifier := &fakeNotifier{}
+			svc, _ := newTestService(&fakeRepo{createErr: tc.createErr}, notifier)
+
+			_, err := svc.Register(context.Background(), tc.email, tc.uname)
+			if got := errorsx.CodeOf(err); got != tc.wantCode {
+				t.Errorf("CodeOf(err) = %q; want %q (err = %v)", got, tc.wantCode, err)
+			}
+			if len(notifier.welcomed) != 0 {
+				t.Error("failed registration sent a welcome message")
+			}
+		})
+	}
+}
+
+func TestRegister_NotifierFailureIsLogged(t *testing.T) {
+	repo := &fakeRepo{}
+	svc, logs := newTestService(repo, &fakeNotifier{err: errors.New("smtp down")})
+
+	u, err := svc.Register(context.Background(), "ada@example.com", "Ada")
+	if err != nil {
+		t.Fatalf("Register returned error: %v; want success despite the notifier", err)
+	}
+	if u.ID != 1 || len(repo.created) != 1 {
+		t.Errorf("user not stored: %+v", u)
+	}
+	if !strings.Contains(logs.String(), "smtp down") {
+		t.Errorf("logs = %q; want the notifier error", logs.String())
+	}
+}
+
+func TestGet(t *testing.T) {
+	ada := user.User{ID: 1, Email: "ada@example.com", Name: "Ada"}
+	tests := []struct {
+		name     string
+		id       int
+		getErr   error
+		want     user.User
+		wantCode errorsx.Code
+	}{
+		{"found", 1, nil, ada, ""},
+		{"not found", 2, nil, user.User{}, errorsx.CodeNotFound},
+		{"storage failure", 1, errors.New("timeout"), user.User{}, errorsx.CodeInternal},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, _ := newTestService(&fakeRepo{users: map[int]user.User{1: ada}, getErr: tc.getErr}, &fakeNotifier{})
+
+			got, err := svc.Get(context.Background(), tc.id)
+			if got != tc.want || errorsx.CodeOf(err) != tc.wantCode {
+				t.Errorf("Get(%d) = %+v, %v; want %+v, code %q", tc.id, got, err, tc.want, tc.wantCode)
+			}
+		})
+	}
+}
+
+func TestNew_PanicsOnNilDependency(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("New with a nil repository did not panic")
+		}
+	}()
+	New(nil, &fakeNotifier{}, log.Default())
+}
@@ -0,0 +1,19 @@
+// Package user holds the domain type shared by every layer. It imports
+// nothing from the other layers, so each of them can depend on it without
+// creating a cycle.
+package user
+
+import "errors"
+
+// Errors a repository reports; the service decides what they mean to callers
+var (
+	ErrNotFound   = errors.New("user: not found")
+	ErrEmailTaken = errors.New("user: email already registered")
+)
+
+// User is a registered account
+type User struct {
+	ID    int    `json:"id"`
+	Email string `json:"email"`
+	Name  string `json:"name"`
+}
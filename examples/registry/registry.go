@@ -0,0 +1,77 @@
+// Package registry implements the driver-registration pattern used by
+// database/sql and image: implementations register themselves by name from
+// an init function, and a program opts in to one by importing its package,
+// often only for that side effect:
+//
+//	import _ "github.com/lib/pq" // registers the "postgres" driver
+//	db, err := sql.Open("postgres", dsn)
+//
+// The program then chooses among the linked-in implementations at run
+// time, for example from a flag, without importing their types. The
+// sorter package is a worked example with the runner's sort command as
+// its main.
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// ErrNotFound is wrapped by Lookup errors for names nothing registered
+var ErrNotFound = errors.New("not registered")
+
+// Registry maps names to implementations of T. It is safe for concurrent
+// use, though registration normally happens in init functions, which run
+// one at a time before main.
+type Registry[T any] struct {
+	kind  string // used in messages, e.g. "sorter"
+	mu    sync.RWMutex
+	items map[string]T
+}
+
+// New returns an empty registry whose messages describe entries as kind
+func New[T any](kind string) *Registry[T] {
+	return &Registry[T]{kind: kind, items: make(map[string]T)}
+}
+
+// Register adds impl under name. Like sql.Register it panics on an empty
+// or duplicate name: both are programming errors found at start-up, and
+// silently replacing an implementation would depend on init order.
+func (r *Registry[T]) Register(name string, impl T) {
+	if name == "" {
+		panic(fmt.Sprintf("registry: %s registered with an empty name", r.kind))
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, dup := r.items[name]; dup {
+		panic(fmt.Sprintf("registry: %s %q registered twice", r.kind, name))
+	}
+	r.items[name] = impl
+}
+
+// Lookup returns the implementation registered under name. The error for
+// an unknown name lists the registered ones, which usually reveals a
+// missing blank import.
+func (r *Registry[T]) Lookup(name string) (T, error) {
+	r.mu.RLock()
+	impl, ok := r.items[name]
+	r.mu.RUnlock()
+	if !ok {
+		return impl, fmt.Errorf("%s %q %w (have %v)", r.kind, name, ErrNotFound, r.Names())
+	}
+	return impl, nil
+}
+
+// Names returns the registered names in sorted order
+func (r *Registry[T]) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.items))
+	for name := range r.items {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
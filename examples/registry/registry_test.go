@@ -0,0 +1,53 @@
+package registry
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	r := New[int]("number")
+	r.Register("two", 2)
+	r.Register("one", 1)
+
+	if got, err := r.Lookup("two"); err != nil || got != 2 {
+		t.Errorf("Lookup(two) = %d, %v; want 2", got, err)
+	}
+	if got := r.Names(); !reflect.DeepEqual(got, []string{"one", "two"}) {
+		t.Errorf("Names() = %v; want [one two]", got)
+	}
+
+	_, err := r.Lookup("three")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Lookup(three) error = %v; want ErrNotFound", err)
+	}
+	if want := `number "three" not registered (have [one two])`; err.Error() != want {
+		t.Errorf("Lookup(three) error = %q; want %q", err, want)
+	}
+}
+
+func TestRegister_Panics(t *testing.T) {
+	tests := []struct {
+		name      string
+		register  string
+		wantPanic string
+	}{
+		{"duplicate", "one", `number "one" registered twice`},
+		{"empty name", "", "registered with an empty name"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := New[int]("number")
+			r.Register("one", 1)
+			defer func() {
+				msg, _ := recover().(string)
+				if !strings.Contains(msg, tc.wantPanic) {
+					t.Errorf("panic = %q; want it to contain %q", msg, tc.wantPanic)
+				}
+			}()
+			r.Register(tc.register, 2)
+		})
+	}
+}
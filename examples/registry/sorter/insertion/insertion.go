@@ -0,0 +1,18 @@
+// Package insertion registers the "insertion" sorter: O(n²) comparisons,
+// but fast for short or nearly sorted input and allocation-free
+package insertion
+
+import "github.com/rehan/go-interview-prep/examples/registry/sorter"
+
+func init() {
+	sorter.Register("insertion", sorter.Func(Sort))
+}
+
+// Sort sorts values in place
+func Sort(values []int) {
+	for i := 1; i < len(values); i++ {
+		for j := i; j > 0 && values[j] < values[j-1]; j-- {
+			values[j], values[j-1] = values[j-1], values[j]
+		}
+	}
+}
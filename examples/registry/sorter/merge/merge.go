@@ -0,0 +1,40 @@
+// Package merge registers the "merge" sorter: a stable O(n log n) top-down
+// merge sort that uses one scratch buffer for the whole sort
+package merge
+
+import "github.com/rehan/go-interview-prep/examples/registry/sorter"
+
+func init() {
+	sorter.Register("merge", sorter.Func(Sort))
+}
+
+// Sort sorts values in place
+func Sort(values []int) {
+	if len(values) < 2 {
+		return
+	}
+	mergeSort(values, make([]int, len(values)))
+}
+
+func mergeSort(values, scratch []int) {
+	if len(values) < 2 {
+		return
+	}
+	mid := len(values) / 2
+	mergeSort(values[:mid], scratch[:mid])
+	mergeSort(values[mid:], scratch[mid:])
+
+	copy(scratch, values)
+	left, right := scratch[:mid], scratch[mid:len(values)]
+	i, j := 0, 0
+	for k := range values {
+		// <= takes from the left on ties, which keeps the sort stable
+		if j == len(right) || (i < len(left) && left[i] <= right[j]) {
+			values[k] = left[i]
+			i++
+		} else {
+			values[k] = right[j]
+			j++
+		}
+	}
+}
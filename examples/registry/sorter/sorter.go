@@ -0,0 +1,44 @@
+// Package sorter is the registry example: each subpackage registers a
+// Sorter in its init function, and a program picks one by name.
+//
+//	import (
+//		"github.com/rehan/go-interview-prep/examples/registry/sorter"
+//		_ "github.com/rehan/go-interview-prep/examples/registry/sorter/merge"
+//	)
+//
+//	s, err := sorter.Lookup("merge")
+//
+// This package never imports its implementations, so it cannot have an
+// import cycle with them and a program links in only the ones it uses.
+package sorter
+
+import "github.com/rehan/go-interview-prep/examples/registry"
+
+// Sorter sorts a slice of ints in place in ascending order
+type Sorter interface {
+	Sort(values []int)
+}
+
+// Func adapts an ordinary function to the Sorter interface
+type Func func(values []int)
+
+// Sort calls f(values)
+func (f Func) Sort(values []int) { f(values) }
+
+var sorters = registry.New[Sorter]("sorter")
+
+// Register makes s available under name; implementations call it from
+// init. It panics if name is empty or already registered.
+func Register(name string, s Sorter) {
+	sorters.Register(name, s)
+}
+
+// Lookup returns the Sorter registered under name
+func Lookup(name string) (Sorter, error) {
+	return sorters.Lookup(name)
+}
+
+// Names returns the registered sorter names in sorted order
+func Names() []string {
+	return sorters.Names()
+}
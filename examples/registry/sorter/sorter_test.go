@@ -0,0 +1,61 @@
+package sorter_test
+
+import (
+	"errors"
+	"math/rand/v2"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/rehan/go-interview-prep/examples/registry"
+	"github.com/rehan/go-interview-prep/examples/registry/sorter"
+	_ "github.com/rehan/go-interview-prep/examples/registry/sorter/insertion"
+	_ "github.com/rehan/go-interview-prep/examples/registry/sorter/merge"
+	_ "github.com/rehan/go-interview-prep/examples/registry/sorter/stdsort"
+)
+
+// The blank imports above are the only link between this test and the
+// implementations; their init functions did the registering
+func TestNames(t *testing.T) {
+	want := []string{"insertion", "merge", "std"}
+	if got := sorter.Names(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Names() = %v; want %v", got, want)
+	}
+}
+
+func TestRegisteredSortersSort(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	random := make([]int, 500)
+	for i := range random {
+		random[i] = rng.IntN(100) - 50
+	}
+	inputs := map[string][]int{
+		"empty":      {},
+		"single":     {7},
+		"sorted":     {1, 2, 3, 4},
+		"reversed":   {4, 3, 2, 1},
+		"duplicates": {3, 1, 3, 1, 2},
+		"random":     random,
+	}
+
+	for _, name := range sorter.Names() {
+		s, err := sorter.Lookup(name)
+		if err != nil {
+			t.Fatalf("Lookup(%q): %v", name, err)
+		}
+		for inputName, in := range inputs {
+			want := slices.Sorted(slices.Values(in))
+			got := slices.Clone(in)
+			s.Sort(got)
+			if !slices.Equal(got, want) {
+				t.Errorf("%s sorter on %s input = %v", name, inputName, got)
+			}
+		}
+	}
+}
+
+func TestLookup_Unknown(t *testing.T) {
+	if _, err := sorter.Lookup("bogo"); !errors.Is(err, registry.ErrNotFound) {
+		t.Errorf("Lookup(bogo) error = %v; want registry.ErrNotFound", err)
+	}
+}
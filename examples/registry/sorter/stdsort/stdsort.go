@@ -0,0 +1,13 @@
+// Package stdsort registers the "std" sorter, the standard library's
+// slices.Sort (pattern-defeating quicksort), as a baseline
+package stdsort
+
+import (
+	"slices"
+
+	"github.com/rehan/go-interview-prep/examples/registry/sorter"
+)
+
+func init() {
+	sorter.Register("std", sorter.Func(slices.Sort[[]int]))
+}
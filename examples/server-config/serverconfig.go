@@ -0,0 +1,252 @@
+// Package serverconfig configures the same Server three ways so the
+// trade-offs interviewers ask about can be compared side by side:
+//
+//   - a Config struct passed to NewFromConfig
+//   - a Builder with chained setters
+//   - functional options passed to New, the pattern most Go libraries use
+//
+// Functional options keep the common call short (New(":8080")), let defaults
+// live in one place, can validate each setting, and let the package add
+// options later without breaking callers. The cost is more code in the
+// package and options that are harder to discover than struct fields.
+package serverconfig
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+// Defaults applied when a setting is not given
+const (
+	DefaultTimeout = 30 * time.Second
+	DefaultMaxConn = 100
+)
+
+// Server holds the settings; its fields are unexported so that every way of
+// building one goes through the same defaults and validation
+type Server struct {
+	addr    string
+	timeout time.Duration
+	maxConn int
+	logger  *log.Logger
+	tls     *tls.Config
+}
+
+func defaultServer(addr string) *Server {
+	return &Server{
+		addr:    addr,
+		timeout: DefaultTimeout,
+		maxConn: DefaultMaxConn,
+		logger:  log.New(io.Discard, "", 0),
+	}
+}
+
+func (s *Server) validate() error {
+	if s.addr == "" {
+		return errors.New("serverconfig: address is required")
+	}
+	return nil
+}
+
+// Addr returns the listen address
+func (s *Server) Addr() string { return s.addr }
+
+// Timeout returns the read and write timeout
+func (s *Server) Timeout() time.Duration { return s.timeout }
+
+// MaxConn returns the connection limit
+func (s *Server) MaxConn() int { return s.maxConn }
+
+// Logger returns the logger; it is never nil
+func (s *Server) Logger() *log.Logger { return s.logger }
+
+// TLS returns the TLS configuration, or nil when serving plain HTTP
+func (s *Server) TLS() *tls.Config { return s.tls }
+
+// HTTPServer builds the *http.Server these settings describe
+func (s *Server) HTTPServer(handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:         s.addr,
+		Handler:      handler,
+		ReadTimeout:  s.timeout,
+		WriteTimeout: s.timeout,
+		TLSConfig:    s.tls,
+		ErrorLog:     s.logger,
+	}
+}
+
+// FUNCTIONAL OPTIONS
+
+// Option configures a Server. Returning an error lets each option validate
+// its own argument.
+type Option func(*Server) error
+
+// WithTimeout sets the read and write timeout
+func WithTimeout(d time.Duration) Option {
+	return func(s *Server) error {
+		if d <= 0 {
+			return fmt.Errorf("serverconfig: timeout must be positive, got %v", d)
+		}
+		s.timeout = d
+		return nil
+	}
+}
+
+// WithMaxConn sets the connection limit
+func WithMaxConn(n int) Option {
+	return func(s *Server) error {
+		if n < 1 {
+			return fmt.Errorf("serverconfig: max connections must be at least 1, got %d", n)
+		}
+		s.maxConn = n
+		return nil
+	}
+}
+
+// WithLogger sets the logger; nil is rejected rather than silently ignored
+func WithLogger(l *log.Logger) Option {
+	return func(s *Server) error {
+		if l == nil {
+			return errors.New("serverconfig: logger must not be nil")
+		}
+		s.logger = l
+		return nil
+	}
+}
+
+// WithTLS serves HTTPS with the given configuration. A minimum version of
+// TLS 1.2 is enforced if the configuration leaves it unset.
+func WithTLS(cfg *tls.Config) Option {
+	return func(s *Server) error {
+		if cfg == nil {
+			return errors.New("serverconfig: TLS config must not be nil")
+		}
+		cfg = cfg.Clone()
+		if cfg.MinVersion == 0 {
+			cfg.MinVersion = tls.VersionTLS12
+		}
+		s.tls = cfg
+		return nil
+	}
+}
+
+// New creates a Server listening on addr. Required settings are ordinary
+// parameters; everything optional is an Option, applied in order so a later
+// option overrides an earlier one.
+func New(addr string, opts ...Option) (*Server, error) {
+	s := defaultServer(addr)
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// CONFIG STRUCT
+
+// Config is the struct-based alternative. It is simple and discoverable,
+// but the zero value has to mean "use the default", so a caller cannot ask
+// for an explicit zero, and every caller sees every field.
+type Config struct {
+	Addr    string
+	Timeout time.Duration // zero means DefaultTimeout
+	MaxConn int           // zero means DefaultMaxConn
+	Logger  *log.Logger   // nil means discard
+	TLS     *tls.Config   // nil means plain HTTP
+}
+
+// NewFromConfig creates a Server from a Config. It reuses the options so
+// both paths share validation.
+func NewFromConfig(cfg Config) (*Server, error) {
+	var opts []Option
+	if cfg.Timeout != 0 {
+		opts = append(opts, WithTimeout(cfg.Timeout))
+	}
+	if cfg.MaxConn != 0 {
+		opts = append(opts, WithMaxConn(cfg.MaxConn))
+	}
+	if cfg.Logger != nil {
+		opts = append(opts, WithLogger(cfg.Logger))
+	}
+	if cfg.TLS != nil {
+		opts = append(opts, WithTLS(cfg.TLS))
+	}
+	return New(cfg.Addr, opts...)
+}
+
+// BUILDER
+
+// Builder is the builder-pattern alternative, common in Java. In Go it needs
+// either an error from every setter or, as here, an error remembered until
+// Build, and the chain cannot be extended by other packages.
+type Builder struct {
+	addr string
+	opts []Option
+}
+
+// NewBuilder starts building a Server for addr
+func NewBuilder(addr string) *Builder {
+	return &Builder{addr: addr}
+}
+
+// Timeout sets the read and write timeout
+func (b *Builder) Timeout(d time.Duration) *Builder {
+	b.opts = append(b.opts, WithTimeout(d))
+	return b
+}
+
+// MaxConn sets the connection limit
+func (b *Builder) MaxConn(n int) *Builder {
+	b.opts = append(b.opts, WithMaxConn(n))
+	return b
+}
+
+// Logger sets the logger
+func (b *Builder) Logger(l *log.Logger) *Builder {
+	b.opts = append(b.opts, WithLogger(l))
+	return b
+}
+
+// TLS serves HTTPS with the given configuration
+func (b *Builder) TLS(cfg *tls.Config) *Builder {
+	b.opts = append(b.opts, WithTLS(cfg))
+	return b
+}
+
+// Build returns the Server, or the first error from any setter
+func (b *Builder) Build() (*Server, error) {
+	return New(b.addr, b.opts...)
+}
+
+/*
+Common Interview Questions about configuration patterns:
+
+1. What are functional options?
+   - Variadic functions of type func(*T) (or func(*T) error) passed to a constructor
+   - Popularized by Rob Pike and Dave Cheney; used by gRPC, zap and many others
+
+2. Why not just a config struct?
+   - A struct is fine for internal code and for many settings
+   - Zero values are ambiguous: "unset" and "explicitly zero" look the same
+   - Adding a required field later breaks nothing at compile time but everything at runtime
+
+3. What are the downsides of functional options?
+   - More boilerplate in the package and less discoverable than struct fields
+   - Option order matters when options overlap
+
+4. How do you validate options?
+   - Return an error from each option, as here, or validate once at the end of New
+
+5. Why is a builder uncommon in Go?
+   - Methods cannot return errors in a chain without breaking it
+   - Variadic options give the same fluency with plain functions
+*/
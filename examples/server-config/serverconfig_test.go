@@ -0,0 +1,154 @@
+package serverconfig
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNew_Defaults(t *testing.T) {
+	s, err := New(":8080")
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	if s.Addr() != ":8080" || s.Timeout() != DefaultTimeout || s.MaxConn() != DefaultMaxConn {
+		t.Errorf("New(\":8080\") = %s, %v, %d; want defaults", s.Addr(), s.Timeout(), s.MaxConn())
+	}
+	if s.Logger() == nil {
+		t.Error("Logger() = nil; want a discarding logger")
+	}
+	if s.TLS() != nil {
+		t.Error("TLS() != nil; want plain HTTP by default")
+	}
+}
+
+func TestNew_Options(t *testing.T) {
+	var buf bytes.Buffer
+	logger := log.New(&buf, "", 0)
+
+	s, err := New(":8443",
+		WithTimeout(5*time.Second),
+		WithMaxConn(10),
+		WithLogger(logger),
+		WithTLS(&tls.Config{}),
+	)
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	if s.Timeout() != 5*time.Second || s.MaxConn() != 10 || s.Logger() != logger {
+		t.Errorf("options not applied: %v, %d", s.Timeout(), s.MaxConn())
+	}
+	if s.TLS() == nil || s.TLS().MinVersion != tls.VersionTLS12 {
+		t.Errorf("TLS() = %+v; want MinVersion TLS 1.2", s.TLS())
+	}
+}
+
+func TestNew_LaterOptionWins(t *testing.T) {
+	s, err := New(":80", WithTimeout(time.Second), WithTimeout(2*time.Second))
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	if s.Timeout() != 2*time.Second {
+		t.Errorf("Timeout() = %v; want 2s", s.Timeout())
+	}
+}
+
+func TestWithTLS_DoesNotModifyCallerConfig(t *testing.T) {
+	cfg := &tls.Config{}
+	if _, err := New(":443", WithTLS(cfg)); err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	if cfg.MinVersion != 0 {
+		t.Errorf("caller's MinVersion = %d; want it left unchanged", cfg.MinVersion)
+	}
+}
+
+func TestNew_InvalidOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		addr    string
+		opts    []Option
+		wantErr string
+	}{
+		{"missing address", "", nil, "address is required"},
+		{"zero timeout", ":80", []Option{WithTimeout(0)}, "timeout must be positive"},
+		{"zero max conn", ":80", []Option{WithMaxConn(0)}, "max connections"},
+		{"nil logger", ":80", []Option{WithLogger(nil)}, "logger must not be nil"},
+		{"nil TLS", ":80", []Option{WithTLS(nil)}, "TLS config must not be nil"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := New(tc.addr, tc.opts...)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("New error = %v; want it to contain %q", err, tc.wantErr)
+			}
+			if s != nil {
+				t.Errorf("New returned a Server alongside an error")
+			}
+		})
+	}
+}
+
+// All three construction styles must produce the same Server
+func TestStylesAgree(t *testing.T) {
+	logger := log.New(&bytes.Buffer{}, "", 0)
+
+	fromOptions, err1 := New(":9000", WithTimeout(time.Minute), WithMaxConn(5), WithLogger(logger))
+	fromConfig, err2 := NewFromConfig(Config{Addr: ":9000", Timeout: time.Minute, MaxConn: 5, Logger: logger})
+	fromBuilder, err3 := NewBuilder(":9000").Timeout(time.Minute).MaxConn(5).Logger(logger).Build()
+	if err1 != nil || err2 != nil || err3 != nil {
+		t.Fatalf("errors: %v, %v, %v", err1, err2, err3)
+	}
+
+	for name, s := range map[string]*Server{"config": fromConfig, "builder": fromBuilder} {
+		if *s != *fromOptions {
+			t.Errorf("%s Server = %+v; want %+v", name, *s, *fromOptions)
+		}
+	}
+}
+
+func TestConfig_ZeroMeansDefault(t *testing.T) {
+	s, err := NewFromConfig(Config{Addr: ":80"})
+	if err != nil {
+		t.Fatalf("NewFromConfig returned error: %v", err)
+	}
+	if s.Timeout() != DefaultTimeout || s.MaxConn() != DefaultMaxConn {
+		t.Errorf("zero Config gave %v, %d; want defaults", s.Timeout(), s.MaxConn())
+	}
+}
+
+func TestBuilder_ReportsFirstError(t *testing.T) {
+	_, err := NewBuilder(":80").Timeout(-1).MaxConn(0).Build()
+	if err == nil || !strings.Contains(err.Error(), "timeout") {
+		t.Errorf("Build error = %v; want the timeout error", err)
+	}
+}
+
+func TestHTTPServer(t *testing.T) {
+	s, err := New(":8080", WithTimeout(3*time.Second))
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	hs := s.HTTPServer(http.NotFoundHandler())
+	if hs.Addr != ":8080" || hs.ReadTimeout != 3*time.Second || hs.WriteTimeout != 3*time.Second {
+		t.Errorf("HTTPServer = %+v; want settings copied", hs)
+	}
+}
+
+func ExampleNew() {
+	s, err := New(":8080",
+		WithTimeout(10*time.Second),
+		WithMaxConn(50),
+	)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(s.Addr(), s.Timeout(), s.MaxConn())
+	// Output: :8080 10s 50
+}
@@ -0,0 +1,9 @@
+// Package binarysearch is an exercise: search a sorted slice.
+package binarysearch
+
+// Search returns the index of target in nums, which is sorted in
+// ascending order without duplicates, or -1 if it is not there. Aim for
+// O(log n) time, without the sort or slices packages.
+func Search(nums []int, target int) int {
+	panic("not implemented")
+}
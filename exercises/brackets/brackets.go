@@ -0,0 +1,9 @@
+// Package brackets is an exercise: check brackets are balanced.
+package brackets
+
+// Balanced reports whether every (, [ and { in s is closed by the
+// matching bracket, in the right order. Other characters are ignored, so
+// "f(a[0]) { }" is balanced and "([)]" is not.
+func Balanced(s string) bool {
+	panic("not implemented")
+}
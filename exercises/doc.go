@@ -0,0 +1,10 @@
+// Package exercises holds practice problems, one package per exercise.
+// Each has a function stub that panics; replace its body with a solution
+// and have it judged against test vectors you cannot see:
+//
+//	go run ./cmd/runner judge -list
+//	go run ./cmd/runner judge reverse
+//
+// To keep the stubs clean, copy one elsewhere and judge the copy with
+// -dir. Solutions may use the standard library only.
+package exercises
@@ -0,0 +1,10 @@
+// Package mergeintervals is an exercise: merge overlapping intervals.
+package mergeintervals
+
+// Merge returns the union of intervals as intervals that do not overlap,
+// sorted by start. Each interval is {start, end} with start <= end, and
+// intervals that touch merge: {1, 4} and {4, 5} become {1, 5}. The input
+// may be in any order.
+func Merge(intervals [][2]int) [][2]int {
+	panic("not implemented")
+}
@@ -0,0 +1,8 @@
+// Package reverse is an exercise: reverse a string.
+package reverse
+
+// Reverse returns s with its characters in reverse order. Characters are
+// Unicode code points, not bytes: Reverse("Hello, 世界") is "界世 ,olleH".
+func Reverse(s string) string {
+	panic("not implemented")
+}
@@ -0,0 +1,9 @@
+// Package twosum is an exercise: find two numbers adding up to a target.
+package twosum
+
+// TwoSum returns the indices i < j of the two numbers in nums that add up
+// to target. There is always exactly one such pair. Aim for better than
+// comparing every pair.
+func TwoSum(nums []int, target int) []int {
+	panic("not implemented")
+}
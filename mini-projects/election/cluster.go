@@ -0,0 +1,246 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// Config configures a Cluster. Zero fields get the defaults shown.
+type Config struct {
+	// Nodes is the size of the cluster (5). A leader needs the votes of a
+	// majority of all of them, down or not.
+	Nodes int
+
+	// Tick is the resolution of virtual time (10ms). Every message takes
+	// one tick to arrive.
+	Tick time.Duration
+
+	// HeartbeatInterval is how often a leader asserts itself (50ms). It
+	// must be well under ElectionTimeout.
+	HeartbeatInterval time.Duration
+
+	// ElectionTimeout is the shortest time a follower waits to hear from
+	// a leader before standing itself (150ms). Each wait is random, up to
+	// twice as long.
+	ElectionTimeout time.Duration
+
+	// Seed seeds each node's election timeouts. The same seed, config and
+	// calls replay the same run.
+	Seed uint64
+
+	// Logf, if set, is called with each node's events and each failure
+	// injected
+	Logf func(format string, args ...any)
+}
+
+// Election is a node winning a term
+type Election struct {
+	Term   int
+	Leader int
+	At     time.Duration
+}
+
+// Cluster is Nodes in-process nodes, each on its own goroutine, and the
+// network between them.
+//
+// Time is virtual: it starts at 0 and moves only in Advance, one Tick at
+// a time. In each tick the cluster hands every live node the messages
+// sent to it in the tick before, waits for all of them to reply with
+// what they sent, and delivers nothing until the next tick. The nodes
+// run in parallel, but what each sees is fixed by the seed and the order
+// of calls, so a run can be replayed and tested exactly; Advance is the
+// fake clock.
+//
+// A Cluster is not safe for concurrent use.
+type Cluster struct {
+	cfg Config
+	now time.Duration
+
+	in     []chan input
+	out    []chan output
+	status []NodeStatus
+
+	restart []bool // down nodes to restart in the next tick
+	group   []int  // nodes talk only within their group; all 0 when healed
+
+	inFlight  []Message
+	elections []Election
+}
+
+// NewCluster starts the nodes, all followers in term 0. Close stops them.
+func NewCluster(cfg Config) *Cluster {
+	if cfg.Nodes <= 0 {
+		cfg.Nodes = 5
+	}
+	if cfg.Tick <= 0 {
+		cfg.Tick = 10 * time.Millisecond
+	}
+	if cfg.HeartbeatInterval <= 0 {
+		cfg.HeartbeatInterval = 50 * time.Millisecond
+	}
+	if cfg.ElectionTimeout <= 0 {
+		cfg.ElectionTimeout = 150 * time.Millisecond
+	}
+
+	c := &Cluster{
+		cfg:     cfg,
+		in:      make([]chan input, cfg.Nodes),
+		out:     make([]chan output, cfg.Nodes),
+		status:  make([]NodeStatus, cfg.Nodes),
+		restart: make([]bool, cfg.Nodes),
+		group:   make([]int, cfg.Nodes),
+	}
+	for id := range cfg.Nodes {
+		n := newNode(id, cfg)
+		c.status[id] = n.status()
+		c.in[id], c.out[id] = make(chan input), make(chan output)
+		go n.run(c.in[id], c.out[id])
+	}
+	return c
+}
+
+// Close stops the nodes' goroutines
+func (c *Cluster) Close() {
+	for _, in := range c.in {
+		close(in)
+	}
+}
+
+// Now returns the virtual time since the cluster started
+func (c *Cluster) Now() time.Duration { return c.now }
+
+// Advance moves virtual time on by d, rounded down to whole ticks
+func (c *Cluster) Advance(d time.Duration) {
+	for range d / c.cfg.Tick {
+		c.tick()
+	}
+}
+
+// AdvanceUntil moves virtual time on a tick at a time until done returns
+// true, for at most limit. It reports whether done did.
+func (c *Cluster) AdvanceUntil(limit time.Duration, done func() bool) bool {
+	for end := c.now + limit; !done(); c.tick() {
+		if c.now >= end {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Cluster) tick() {
+	c.now += c.cfg.Tick
+
+	// Messages to down nodes or across a partition are lost
+	inbox := make([][]Message, c.cfg.Nodes)
+	for _, m := range c.inFlight {
+		if !c.status[m.To].Down && c.group[m.From] == c.group[m.To] {
+			inbox[m.To] = append(inbox[m.To], m)
+		}
+	}
+	c.inFlight = nil
+
+	for id, in := range c.in {
+		if !c.status[id].Down || c.restart[id] {
+			in <- input{now: c.now, msgs: inbox[id], restart: c.restart[id]}
+		}
+	}
+	// Collecting in id order keeps the next tick's deliveries in a fixed
+	// order, however the goroutines were scheduled
+	for id, out := range c.out {
+		if c.status[id].Down && !c.restart[id] {
+			continue
+		}
+		c.restart[id] = false
+		o := <-out
+		for _, e := range o.events {
+			c.logf("node %d %s", id, e)
+		}
+		if prev := c.status[id]; o.status.State == Leader && (prev.State != Leader || prev.Term != o.status.Term) {
+			c.elections = append(c.elections, Election{o.status.Term, id, c.now})
+		}
+		c.status[id] = o.status
+		c.inFlight = append(c.inFlight, o.msgs...)
+	}
+}
+
+// Crash stops node id. It keeps only its term and vote, as a real node
+// would on disk, until Restart.
+func (c *Cluster) Crash(id int) {
+	if c.status[id].Down {
+		return
+	}
+	c.status[id].Down = true
+	c.logf("node %d crashed", id)
+}
+
+// Restart brings a crashed node back, as a follower, in the next tick
+func (c *Cluster) Restart(id int) {
+	if c.status[id].Down {
+		c.restart[id] = true
+	}
+}
+
+// Partition splits the network: nodes in the same group reach each other
+// and no one else. Nodes not in any group form one more group.
+func (c *Cluster) Partition(groups ...[]int) {
+	clear(c.group)
+	for i, g := range groups {
+		for _, id := range g {
+			c.group[id] = i + 1
+		}
+	}
+	c.logf("network partitioned: %v", groups)
+}
+
+// Heal joins a partitioned network back together
+func (c *Cluster) Heal() {
+	clear(c.group)
+	c.logf("network healed")
+}
+
+// Status returns every node's view of the cluster
+func (c *Cluster) Status() []NodeStatus {
+	return append([]NodeStatus(nil), c.status...)
+}
+
+// Elections returns every term won so far, in order
+func (c *Cluster) Elections() []Election {
+	return append([]Election(nil), c.elections...)
+}
+
+// Leader returns the live leader that a majority of the cluster,
+// counting the leader, follows in its term. A leader cut off in a
+// minority still believes it leads, but it is not returned.
+func (c *Cluster) Leader() (int, bool) {
+	for _, s := range c.status {
+		if s.Down || s.State != Leader {
+			continue
+		}
+		followers := 0
+		for _, t := range c.status {
+			if !t.Down && t.Term == s.Term && t.Leader == s.ID {
+				followers++
+			}
+		}
+		if followers > c.cfg.Nodes/2 {
+			return s.ID, true
+		}
+	}
+	return -1, false
+}
+
+func (c *Cluster) logf(format string, args ...any) {
+	if c.cfg.Logf != nil {
+		c.cfg.Logf("%7v "+format, append([]any{c.now}, args...)...)
+	}
+}
+
+func (s NodeStatus) String() string {
+	if s.Down {
+		return fmt.Sprintf("node %d: down (term %d)", s.ID, s.Term)
+	}
+	if s.State == Leader || s.Leader < 0 {
+		return fmt.Sprintf("node %d: %s in term %d", s.ID, s.State, s.Term)
+	}
+	return fmt.Sprintf("node %d: %s of node %d in term %d", s.ID, s.State, s.Leader, s.Term)
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newCluster starts a cluster that logs to t and stops with the test
+func newCluster(t *testing.T, cfg Config) *Cluster {
+	t.Helper()
+	cfg.Logf = func(format string, args ...any) { t.Logf(format, args...) }
+	c := NewCluster(cfg)
+	t.Cleanup(c.Close)
+	return c
+}
+
+// waitForLeader advances c until a majority follows a leader other than
+// not, and returns it
+func waitForLeader(t *testing.T, c *Cluster, not int) int {
+	t.Helper()
+	var id int
+	if !c.AdvanceUntil(5*time.Second, func() bool {
+		var ok bool
+		id, ok = c.Leader()
+		return ok && id != not
+	}) {
+		t.Fatalf("no leader other than %d by %v: %v", not, c.Now(), c.Status())
+	}
+	return id
+}
+
+// checkElectionSafety fails if any term was won twice
+func checkElectionSafety(t *testing.T, c *Cluster) {
+	t.Helper()
+	won := map[int]int{}
+	for _, e := range c.Elections() {
+		if prev, ok := won[e.Term]; ok {
+			t.Errorf("term %d won by node %d and node %d", e.Term, prev, e.Leader)
+		}
+		won[e.Term] = e.Leader
+	}
+}
+
+func TestElection_StableLeader(t *testing.T) {
+	for _, nodes := range []int{1, 3, 5, 7} {
+		c := newCluster(t, Config{Nodes: nodes, Seed: 1})
+		leader := waitForLeader(t, c, -1)
+		if c.Now() > time.Second {
+			t.Errorf("%d nodes took %v to elect a leader", nodes, c.Now())
+		}
+
+		// Heartbeats keep every follower from standing
+		c.Advance(10 * time.Second)
+		if id, ok := c.Leader(); !ok || id != leader {
+			t.Errorf("%d nodes: leader is %d, %v; want %d still", nodes, id, ok, leader)
+		}
+		if n := len(c.Elections()); n != 1 {
+			t.Errorf("%d nodes: %d elections while the leader was up; want 1", nodes, n)
+		}
+		for _, s := range c.Status() {
+			if s.Term != 1 || s.Leader != leader {
+				t.Errorf("%d nodes: %v; want following %d in term 1", nodes, s, leader)
+			}
+		}
+	}
+}
+
+func TestElection_LeaderCrash(t *testing.T) {
+	c := newCluster(t, Config{Seed: 2})
+	first := waitForLeader(t, c, -1)
+
+	c.Crash(first)
+	second := waitForLeader(t, c, first)
+	if s := c.Status()[second]; s.Term <= 1 {
+		t.Errorf("new leader %v; want a later term than 1", s)
+	}
+
+	// The old leader comes back in its old term and follows the new one
+	c.Restart(first)
+	c.Advance(time.Second)
+	if id, _ := c.Leader(); id != second {
+		t.Errorf("leader after restart = %d; want %d", id, second)
+	}
+	if s, want := c.Status()[first], c.Status()[second].Term; s.State != Follower || s.Leader != second || s.Term != want {
+		t.Errorf("restarted node %v; want following %d in term %d", s, second, want)
+	}
+	checkElectionSafety(t, c)
+}
+
+func TestElection_Partition(t *testing.T) {
+	c := newCluster(t, Config{Seed: 3})
+	old := waitForLeader(t, c, -1)
+
+	// The leader and one other node are cut off from the other three
+	minority := []int{old, (old + 1) % 5}
+	var majority []int
+	for id := range 5 {
+		if id != minority[0] && id != minority[1] {
+			majority = append(majority, id)
+		}
+	}
+	c.Partition(minority, majority)
+	leader := waitForLeader(t, c, old)
+
+	// The old leader has not heard of the new term, so it still believes
+	// it leads the old one, but no majority follows it
+	c.Advance(time.Second)
+	if s := c.Status()[old]; s.State != Leader || s.Term != 1 {
+		t.Errorf("cut-off leader %v; want still leading term 1", s)
+	}
+	if id, _ := c.Leader(); id != leader {
+		t.Errorf("leader = %d; want %d in the majority", id, leader)
+	}
+
+	// Healing brings the old leader into the new term, so it steps down
+	// and the whole cluster follows one leader again
+	c.Heal()
+	c.Advance(time.Second)
+	leader, ok := c.Leader()
+	if !ok || leader == old {
+		t.Fatalf("leader after healing = %d, %v; want one other than %d", leader, ok, old)
+	}
+	term := c.Status()[leader].Term
+	for _, s := range c.Status() {
+		if s.Term != term || s.Leader != leader {
+			t.Errorf("after healing %v; want following %d in term %d", s, leader, term)
+		}
+	}
+	checkElectionSafety(t, c)
+}
+
+func TestElection_NoQuorum(t *testing.T) {
+	c := newCluster(t, Config{Seed: 4})
+	leader := waitForLeader(t, c, -1)
+
+	// With three of five nodes down nobody can win, however many times
+	// the survivors stand
+	var up []int
+	for id := range 5 {
+		if id != leader && len(up) < 2 {
+			up = append(up, id)
+		} else {
+			c.Crash(id)
+		}
+	}
+	elections := len(c.Elections())
+	c.Advance(5 * time.Second)
+	if n := len(c.Elections()); n != elections {
+		t.Errorf("%d terms won without a quorum", n-elections)
+	}
+	if id, ok := c.Leader(); ok {
+		t.Errorf("leader %d without a quorum", id)
+	}
+	for _, id := range up {
+		if s := c.Status()[id]; s.Term < 5 {
+			t.Errorf("%v; want many failed elections", s)
+		}
+	}
+
+	// One node back makes a quorum again
+	c.Restart(leader)
+	waitForLeader(t, c, -1)
+	checkElectionSafety(t, c)
+}
+
+// The same seed and calls replay the same run, however the node
+// goroutines are scheduled
+func TestElection_Deterministic(t *testing.T) {
+	replay := func(seed uint64) (string, []Election) {
+		var log strings.Builder
+		c := NewCluster(Config{
+			Seed: seed,
+			// Close elections make split votes likely
+			ElectionTimeout: 20 * time.Millisecond,
+			Logf:            func(format string, args ...any) { fmt.Fprintf(&log, format+"\n", args...) },
+		})
+		defer c.Close()
+		for i := range 5 {
+			c.Advance(time.Second)
+			if id, ok := c.Leader(); ok {
+				c.Crash(id)
+			}
+			c.Restart((i + 2) % 5)
+		}
+		return log.String(), c.Elections()
+	}
+
+	log1, elections1 := replay(42)
+	for range 3 {
+		log2, elections2 := replay(42)
+		if log1 != log2 || !reflect.DeepEqual(elections1, elections2) {
+			t.Fatalf("runs with the same seed differ:\n%s\n---\n%s", log1, log2)
+		}
+	}
+	if log1 == "" || len(elections1) < 3 {
+		t.Fatalf("run had %d elections; want several:\n%s", len(elections1), log1)
+	}
+	if log2, _ := replay(43); log2 == log1 {
+		t.Error("runs with different seeds are the same")
+	}
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// State is a node's role in the election
+type State int
+
+const (
+	Follower State = iota
+	Candidate
+	Leader
+)
+
+func (s State) String() string {
+	switch s {
+	case Follower:
+		return "follower"
+	case Candidate:
+		return "candidate"
+	case Leader:
+		return "leader"
+	}
+	return fmt.Sprintf("State(%d)", int(s))
+}
+
+// Kind is the type of a Message
+type Kind int
+
+const (
+	RequestVote    Kind = iota // a candidate asking for a vote in its term
+	VoteReply                  // the answer, with Granted set if the vote was given
+	Heartbeat                  // a leader asserting its term
+	HeartbeatReply             // sent to a leader with a stale term, so it steps down
+)
+
+// Message is what nodes send each other. Every message carries the
+// sender's term, which is how nodes learn that an election has moved on.
+type Message struct {
+	Kind     Kind
+	From, To int
+	Term     int
+	Granted  bool
+}
+
+// NodeStatus is a node's view of the cluster after a step
+type NodeStatus struct {
+	ID     int
+	State  State
+	Term   int
+	Leader int // the leader it follows in Term, or -1
+	Down   bool
+}
+
+// input is one tick of virtual time for a node: the messages delivered to
+// it, and whether it is coming back from a crash
+type input struct {
+	now     time.Duration
+	msgs    []Message
+	restart bool
+}
+
+// output is what a node did in a tick
+type output struct {
+	msgs   []Message
+	events []string
+	status NodeStatus
+}
+
+// node runs the election half of Raft: terms, randomized election
+// timeouts, one vote per term, and heartbeats from the leader. There is
+// no log, so any candidate may win a vote.
+//
+// A node's fields belong to its goroutine; the cluster sees them only
+// through the NodeStatus in each output.
+type node struct {
+	id, size int
+	cfg      Config
+	rng      *rand.Rand
+
+	state    State
+	term     int
+	votedFor int // -1 for nobody in term; with term, what a real node persists
+	leader   int
+	votes    int
+
+	electionAt  time.Duration // when a follower or candidate stops waiting
+	heartbeatAt time.Duration // when a leader next sends heartbeats
+
+	out    []Message
+	events []string
+}
+
+func newNode(id int, cfg Config) *node {
+	n := &node{
+		id:       id,
+		size:     cfg.Nodes,
+		cfg:      cfg,
+		rng:      rand.New(rand.NewPCG(cfg.Seed, uint64(id))),
+		votedFor: -1,
+		leader:   -1,
+	}
+	n.resetElection(0)
+	return n
+}
+
+// run steps the node once per input until in is closed
+func (n *node) run(in <-chan input, out chan<- output) {
+	for i := range in {
+		out <- n.step(i)
+	}
+}
+
+func (n *node) step(in input) output {
+	n.out, n.events = nil, nil
+	if in.restart {
+		// Only term and votedFor survive a crash
+		n.state, n.leader, n.votes = Follower, -1, 0
+		n.resetElection(in.now)
+		n.logf("restarted in term %d", n.term)
+	}
+	for _, m := range in.msgs {
+		n.handle(in.now, m)
+	}
+	switch {
+	case n.state == Leader && in.now >= n.heartbeatAt:
+		n.broadcast(Heartbeat)
+		n.heartbeatAt = in.now + n.cfg.HeartbeatInterval
+	case n.state != Leader && in.now >= n.electionAt:
+		n.startElection(in.now)
+	}
+	return output{n.out, n.events, n.status()}
+}
+
+func (n *node) handle(now time.Duration, m Message) {
+	if m.Term > n.term {
+		// Whatever this node was, it is a follower in the newer term
+		if n.state != Follower {
+			n.logf("steps down: node %d is in term %d", m.From, m.Term)
+			// A leader's timeout is long past; it waits like any follower
+			n.resetElection(now)
+		}
+		n.state, n.term, n.votedFor, n.leader = Follower, m.Term, -1, -1
+	}
+
+	switch m.Kind {
+	case RequestVote:
+		granted := m.Term == n.term && (n.votedFor == -1 || n.votedFor == m.From)
+		if granted {
+			n.votedFor = m.From
+			// A follower that just voted gives the candidate time to win
+			n.resetElection(now)
+		}
+		n.send(m.From, VoteReply, granted)
+
+	case VoteReply:
+		if n.state != Candidate || m.Term != n.term || !m.Granted {
+			return
+		}
+		n.votes++
+		if n.votes > n.size/2 {
+			n.becomeLeader(now)
+		}
+
+	case Heartbeat:
+		if m.Term < n.term {
+			n.send(m.From, HeartbeatReply, false)
+			return
+		}
+		// Only one node can win a term, so this is its leader
+		if n.state == Candidate {
+			n.logf("steps down: node %d won term %d", m.From, m.Term)
+		}
+		n.state = Follower
+		if n.leader != m.From {
+			n.leader = m.From
+			n.logf("follows node %d in term %d", m.From, m.Term)
+		}
+		n.resetElection(now)
+
+	case HeartbeatReply:
+		// Its term, handled above, is all it carries
+	}
+}
+
+func (n *node) startElection(now time.Duration) {
+	n.state = Candidate
+	n.term++
+	n.votedFor, n.votes, n.leader = n.id, 1, -1
+	n.resetElection(now)
+	n.logf("stands for term %d", n.term)
+	if n.votes > n.size/2 {
+		n.becomeLeader(now) // a cluster of one
+		return
+	}
+	n.broadcast(RequestVote)
+}
+
+func (n *node) becomeLeader(now time.Duration) {
+	n.state, n.leader = Leader, n.id
+	n.logf("leads term %d with %d of %d votes", n.term, n.votes, n.size)
+	// Heartbeats go out at once so the other candidates stand down
+	n.broadcast(Heartbeat)
+	n.heartbeatAt = now + n.cfg.HeartbeatInterval
+}
+
+// resetElection picks a new election timeout, random in
+// [ElectionTimeout, 2×ElectionTimeout) so that nodes rarely stand at once
+func (n *node) resetElection(now time.Duration) {
+	t := n.cfg.ElectionTimeout
+	n.electionAt = now + t + time.Duration(n.rng.Int64N(int64(t)))
+}
+
+func (n *node) send(to int, kind Kind, granted bool) {
+	n.out = append(n.out, Message{Kind: kind, From: n.id, To: to, Term: n.term, Granted: granted})
+}
+
+func (n *node) broadcast(kind Kind) {
+	for to := range n.size {
+		if to != n.id {
+			n.send(to, kind, false)
+		}
+	}
+}
+
+func (n *node) logf(format string, args ...any) {
+	n.events = append(n.events, fmt.Sprintf(format, args...))
+}
+
+func (n *node) status() NodeStatus {
+	return NodeStatus{ID: n.id, State: n.state, Term: n.term, Leader: n.leader}
+}
@@ -0,0 +1,151 @@
+package jsonrpc
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/rehan/go-interview-prep/pkg/money"
+	"github.com/rehan/go-interview-prep/pkg/validator"
+)
+
+// CodeBookNotFound is the application error code for an id with no book
+const CodeBookNotFound = 404
+
+// Book represents book data
+type Book struct {
+	ID     int          `json:"id"`
+	Title  string       `json:"title" validate:"required,max=200"`
+	Author string       `json:"author" validate:"required,max=200"`
+	Price  money.Amount `json:"price" validate:"required,min=0.01"`
+}
+
+// IDArgs names a book for Books.Get and Books.Delete
+type IDArgs struct {
+	ID int `json:"id"`
+}
+
+// ListArgs filters Books.List; an empty Author lists every book
+type ListArgs struct {
+	Author string `json:"author"`
+}
+
+// UpdateArgs replaces the title, author and price of book ID
+type UpdateArgs struct {
+	ID   int  `json:"id"`
+	Book Book `json:"book"`
+}
+
+// BookService is the book operations the server registers as "Books".
+// Each method has the form net/rpc calls for.
+type BookService struct {
+	mu     sync.RWMutex
+	books  map[int]Book
+	nextID int
+}
+
+// NewBookService creates a new BookService with some sample data
+func NewBookService() *BookService {
+	s := &BookService{books: make(map[int]Book), nextID: 1}
+	for _, b := range []Book{
+		{Title: "The Go Programming Language", Author: "Alan A. A. Donovan and Brian W. Kernighan", Price: money.MustParse("32.99")},
+		{Title: "Concurrency in Go", Author: "Katherine Cox-Buday", Price: money.MustParse("34.99")},
+		{Title: "Go in Action", Author: "William Kennedy", Price: money.MustParse("24.99")},
+	} {
+		var created Book
+		s.Create(b, &created)
+	}
+	return s
+}
+
+// Get replies with book args.ID
+func (s *BookService) Get(args IDArgs, reply *Book) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	book, ok := s.books[args.ID]
+	if !ok {
+		return notFound(args.ID)
+	}
+	*reply = book
+	return nil
+}
+
+// List replies with the books by args.Author, ignoring case, in id order
+func (s *BookService) List(args ListArgs, reply *[]Book) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	books := make([]Book, 0, len(s.books))
+	for _, b := range s.books {
+		if args.Author == "" || strings.EqualFold(b.Author, args.Author) {
+			books = append(books, b)
+		}
+	}
+	slices.SortFunc(books, func(a, b Book) int { return cmp.Compare(a.ID, b.ID) })
+	*reply = books
+	return nil
+}
+
+// Create adds args, ignoring its ID, and replies with the stored book
+func (s *BookService) Create(args Book, reply *Book) error {
+	if err := validate(args); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	args.ID = s.nextID
+	s.nextID++
+	s.books[args.ID] = args
+	*reply = args
+	return nil
+}
+
+// Update replaces book args.ID and replies with the stored book
+func (s *BookService) Update(args UpdateArgs, reply *Book) error {
+	if err := validate(args.Book); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.books[args.ID]; !ok {
+		return notFound(args.ID)
+	}
+	args.Book.ID = args.ID
+	s.books[args.ID] = args.Book
+	*reply = args.Book
+	return nil
+}
+
+// Delete removes book args.ID, replying true
+func (s *BookService) Delete(args IDArgs, reply *bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.books[args.ID]; !ok {
+		return notFound(args.ID)
+	}
+	delete(s.books, args.ID)
+	*reply = true
+	return nil
+}
+
+func notFound(id int) *Error {
+	return &Error{Code: CodeBookNotFound, Message: "book not found", Data: map[string]int{"id": id}}
+}
+
+// validate reports a book that fails its validate tags as invalid params,
+// with the failed rules as data
+func validate(book Book) error {
+	err := validator.Struct(book)
+	if err == nil {
+		return nil
+	}
+	var msgs []string
+	if errs, ok := err.(validator.Errors); ok {
+		for _, e := range errs {
+			msgs = append(msgs, e.Error())
+		}
+	} else {
+		msgs = []string{err.Error()}
+	}
+	return &Error{Code: CodeInvalidParams, Message: "Invalid params", Data: msgs}
+}
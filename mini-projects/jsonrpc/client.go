@@ -0,0 +1,236 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+)
+
+// ErrClosed is returned by calls on a Client whose connection has ended
+var ErrClosed = errors.New("jsonrpc: connection closed")
+
+// Call is one request of a Client.Batch. Err is set, as Client.Call
+// would return it, once the batch has been answered; a notification is
+// not answered, so its Result is left alone and its Err stays nil.
+type Call struct {
+	Method string
+	Params any
+	Result any // pointer the result is decoded into; nil to discard it
+	Notify bool
+	Err    error
+}
+
+// Client calls methods on a JSON-RPC 2.0 server over one connection. Its
+// methods may be called from several goroutines at once; a goroutine
+// reading the connection hands each response to the call with its id.
+type Client struct {
+	conn net.Conn
+
+	wmu sync.Mutex // serializes writes to conn
+
+	mu      sync.Mutex
+	nextID  uint64
+	pending map[uint64]chan *message
+	err     error // why the connection ended; set once, with done closed
+	done    chan struct{}
+}
+
+// Dial connects to the server at addr over TCP
+func Dial(ctx context.Context, addr string) (*Client, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(conn), nil
+}
+
+// NewClient returns a Client using conn, which it closes on Close
+func NewClient(conn net.Conn) *Client {
+	c := &Client{conn: conn, pending: make(map[uint64]chan *message), done: make(chan struct{})}
+	go c.read()
+	return c
+}
+
+// Close closes the connection; calls waiting for a response fail with
+// ErrClosed
+func (c *Client) Close() error {
+	err := c.conn.Close()
+	<-c.done
+	return err
+}
+
+// Call calls method with params, a struct or map sent by name or a slice
+// sent by position, and decodes the result into result if it is not nil.
+// An error the server responds with is an *Error.
+func (c *Client) Call(ctx context.Context, method string, params, result any) error {
+	call := &Call{Method: method, Params: params, Result: result}
+	if err := c.Batch(ctx, call); err != nil {
+		return err
+	}
+	return call.Err
+}
+
+// Notify sends method with params as a notification, which the server
+// runs without responding
+func (c *Client) Notify(method string, params any) error {
+	return c.Batch(context.Background(), &Call{Method: method, Params: params, Notify: true})
+}
+
+// Batch sends calls and waits for their responses. One call is sent as a
+// single request and several as a batch, which the server may run
+// concurrently. The returned error is for the batch as a whole, such as the
+// connection failing; the outcome of each call is in its Err.
+func (c *Client) Batch(ctx context.Context, calls ...*Call) error {
+	if len(calls) == 0 {
+		return nil // an empty batch is not a valid request
+	}
+	type waiting struct {
+		call *Call
+		id   uint64
+		ch   chan *message
+	}
+	var waits []waiting
+	defer func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		// Late responses to calls given up on are discarded
+		for _, w := range waits {
+			delete(c.pending, w.id)
+		}
+	}()
+
+	reqs := make([]*message, len(calls))
+	for i, call := range calls {
+		req := &message{JSONRPC: "2.0", Method: call.Method}
+		if call.Params != nil {
+			params, err := marshalParams(call.Params)
+			if err != nil {
+				return err
+			}
+			req.Params = params
+		}
+		if !call.Notify {
+			id, ch, err := c.register()
+			if err != nil {
+				return err
+			}
+			req.ID = json.RawMessage(strconv.FormatUint(id, 10))
+			waits = append(waits, waiting{call, id, ch})
+		}
+		reqs[i] = req
+	}
+
+	var err error
+	if len(reqs) == 1 {
+		err = c.write(reqs[0])
+	} else {
+		err = c.write(reqs)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, w := range waits {
+		select {
+		case resp := <-w.ch:
+			w.call.Err = decodeResult(resp, w.call.Result)
+		case <-c.done:
+			return c.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// register allocates a request id and the channel its response is
+// delivered on
+func (c *Client) register() (uint64, chan *message, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return 0, nil, c.err
+	}
+	c.nextID++
+	ch := make(chan *message, 1)
+	c.pending[c.nextID] = ch
+	return c.nextID, ch, nil
+}
+
+func (c *Client) write(v any) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	if err := json.NewEncoder(c.conn).Encode(v); err != nil {
+		return fmt.Errorf("jsonrpc: sending request: %w", err)
+	}
+	return nil
+}
+
+// read delivers responses to their calls until the connection ends
+func (c *Client) read() {
+	dec := json.NewDecoder(c.conn)
+	var err error
+	for {
+		var raw json.RawMessage
+		if err = dec.Decode(&raw); err != nil {
+			break
+		}
+		var resps []*message
+		if raw[0] == '[' {
+			err = json.Unmarshal(raw, &resps)
+		} else {
+			resps = []*message{{}}
+			err = json.Unmarshal(raw, resps[0])
+		}
+		if err != nil {
+			break
+		}
+		c.mu.Lock()
+		for _, resp := range resps {
+			// A response with a null id answers a request the server could
+			// not read, which a call of this client never is
+			id, perr := strconv.ParseUint(string(resp.ID), 10, 64)
+			if ch, ok := c.pending[id]; ok && perr == nil {
+				delete(c.pending, id)
+				ch <- resp
+			}
+		}
+		c.mu.Unlock()
+	}
+
+	c.mu.Lock()
+	c.err = fmt.Errorf("%w: %v", ErrClosed, err)
+	c.mu.Unlock()
+	close(c.done)
+}
+
+// marshalParams encodes params, which the specification requires to be
+// an object or an array
+func marshalParams(params any) (json.RawMessage, error) {
+	b, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("jsonrpc: encoding params: %w", err)
+	}
+	if b[0] != '{' && b[0] != '[' {
+		return nil, fmt.Errorf("jsonrpc: params must be an object or an array, not %s", b)
+	}
+	return b, nil
+}
+
+func decodeResult(resp *message, result any) error {
+	if resp.Error != nil {
+		return resp.Error
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(resp.Result, result); err != nil {
+		return fmt.Errorf("jsonrpc: decoding result: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,440 @@
+package jsonrpc
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/money"
+)
+
+// testService has methods for the cases BookService does not cover
+type testService struct {
+	release chan struct{} // Wait returns once it is closed
+	started chan struct{} // Wait sends on it when called, if not nil
+}
+
+func (s *testService) Panic(args struct{}, reply *bool) error {
+	panic("boom")
+}
+
+func (s *testService) Fail(args struct{}, reply *bool) error {
+	return errors.New("disk full")
+}
+
+func (s *testService) Wait(args struct{}, reply *string) error {
+	if s.started != nil {
+		s.started <- struct{}{}
+	}
+	<-s.release
+	*reply = "done"
+	return nil
+}
+
+// startServer serves Books and Test on a local port until the test ends
+// and returns its address
+func startServer(t *testing.T, test *testService) string {
+	t.Helper()
+	srv := NewServer(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err := srv.RegisterName("Books", NewBookService()); err != nil {
+		t.Fatal(err)
+	}
+	if test == nil {
+		test = &testService{release: make(chan struct{})}
+		close(test.release)
+	}
+	if err := srv.RegisterName("Test", test); err != nil {
+		t.Fatal(err)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ctx, ln) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Serve: %v", err)
+		}
+	})
+	return ln.Addr().String()
+}
+
+func dial(t *testing.T, addr string) *Client {
+	t.Helper()
+	client, err := Dial(context.Background(), addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestClient_Books(t *testing.T) {
+	client := dial(t, startServer(t, nil))
+	ctx := context.Background()
+
+	var created Book
+	err := client.Call(ctx, "Books.Create", Book{Title: "Learning Go", Author: "Jon Bodner", Price: money.MustParse("29.99")}, &created)
+	if err != nil || created.ID != 4 || created.Price.String() != "29.99" {
+		t.Fatalf("Create = %+v, %v; want book 4", created, err)
+	}
+
+	var updated Book
+	err = client.Call(ctx, "Books.Update", UpdateArgs{ID: 4, Book: Book{Title: "Learning Go, 2nd Edition", Author: "Jon Bodner", Price: money.MustParse("39.99")}}, &updated)
+	if err != nil || updated.ID != 4 || updated.Title != "Learning Go, 2nd Edition" {
+		t.Fatalf("Update = %+v, %v; want the new title", updated, err)
+	}
+
+	var got Book
+	// By position: an array holding the args
+	if err := client.Call(ctx, "Books.Get", []IDArgs{{ID: 4}}, &got); err != nil || got != updated {
+		t.Errorf("Get = %+v, %v; want %+v", got, err, updated)
+	}
+
+	var books []Book
+	if err := client.Call(ctx, "Books.List", ListArgs{Author: "JON BODNER"}, &books); err != nil || len(books) != 1 || books[0].ID != 4 {
+		t.Errorf("List by author = %+v, %v; want book 4", books, err)
+	}
+
+	var deleted bool
+	if err := client.Call(ctx, "Books.Delete", IDArgs{ID: 4}, &deleted); err != nil || !deleted {
+		t.Errorf("Delete = %v, %v; want true", deleted, err)
+	}
+	if err := client.Call(ctx, "Books.List", nil, &books); err != nil || len(books) != 3 {
+		t.Errorf("List = %d books, %v; want the 3 sample books", len(books), err)
+	}
+}
+
+func TestClient_Errors(t *testing.T) {
+	client := dial(t, startServer(t, nil))
+
+	tests := []struct {
+		name     string
+		method   string
+		params   any
+		wantCode int
+		wantData string
+	}{
+		{"application error", "Books.Get", IDArgs{ID: 99}, CodeBookNotFound, `map[id:99]`},
+		{"fails validation", "Books.Create", Book{Title: "T", Author: "A"}, CodeInvalidParams, `[price is required]`},
+		{"unknown param", "Books.Get", map[string]any{"isbn": "x"}, CodeInvalidParams, `json: unknown field "isbn"`},
+		{"too many positional params", "Books.Get", []int{1, 2}, CodeInvalidParams, `2 positional params; want at most 1`},
+		{"unknown method", "Books.Publish", nil, CodeMethodNotFound, `Books.Publish`},
+		{"method error", "Test.Fail", nil, CodeServerError, `<nil>`},
+		{"panic", "Test.Panic", nil, CodeInternalError, `<nil>`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := client.Call(context.Background(), tc.method, tc.params, nil)
+			var rpcErr *Error
+			if !errors.As(err, &rpcErr) {
+				t.Fatalf("Call = %v; want an *Error", err)
+			}
+			if rpcErr.Code != tc.wantCode || fmt.Sprint(rpcErr.Data) != tc.wantData {
+				t.Errorf("error = %d, data %v; want %d, data %s", rpcErr.Code, rpcErr.Data, tc.wantCode, tc.wantData)
+			}
+		})
+	}
+
+	if err := client.Call(context.Background(), "Books.Get", 1, nil); err == nil || !strings.Contains(err.Error(), "object or an array") {
+		t.Errorf("Call with params 1 = %v; want it refused before sending", err)
+	}
+}
+
+func TestClient_Batch(t *testing.T) {
+	client := dial(t, startServer(t, nil))
+	ctx := context.Background()
+
+	var first Book
+	var deleted bool
+	calls := []*Call{
+		{Method: "Books.Get", Params: IDArgs{ID: 1}, Result: &first},
+		{Method: "Books.Delete", Params: IDArgs{ID: 2}, Notify: true},
+		{Method: "Books.Get", Params: IDArgs{ID: 99}},
+		{Method: "Books.Delete", Params: IDArgs{ID: 3}, Result: &deleted},
+	}
+	if err := client.Batch(ctx, calls...); err != nil {
+		t.Fatal(err)
+	}
+	if calls[0].Err != nil || first.Title != "The Go Programming Language" {
+		t.Errorf("Get 1 = %+v, %v", first, calls[0].Err)
+	}
+	if calls[1].Err != nil {
+		t.Errorf("notification Err = %v; want nil", calls[1].Err)
+	}
+	if !errors.As(calls[2].Err, new(*Error)) {
+		t.Errorf("Get 99 Err = %v; want an *Error", calls[2].Err)
+	}
+	if calls[3].Err != nil || !deleted {
+		t.Errorf("Delete 3 = %v, %v; want true", deleted, calls[3].Err)
+	}
+
+	// The notification ran, although nothing answered it
+	var books []Book
+	if err := client.Call(ctx, "Books.List", nil, &books); err != nil || len(books) != 1 {
+		t.Errorf("List = %+v, %v; want only book 1", books, err)
+	}
+}
+
+// Calls from several goroutines share the connection, and a slow call
+// does not hold up the others
+func TestClient_Concurrent(t *testing.T) {
+	test := &testService{release: make(chan struct{}), started: make(chan struct{})}
+	client := dial(t, startServer(t, test))
+	ctx := context.Background()
+
+	slow := make(chan error, 1)
+	go func() {
+		var reply string
+		slow <- client.Call(ctx, "Test.Wait", nil, &reply)
+	}()
+	<-test.started
+
+	var wg sync.WaitGroup
+	for id := 1; id <= 3; id++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var book Book
+			if err := client.Call(ctx, "Books.Get", IDArgs{ID: id}, &book); err != nil || book.ID != id {
+				t.Errorf("Get %d = %+v, %v", id, book, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	close(test.release)
+	if err := <-slow; err != nil {
+		t.Errorf("Wait = %v", err)
+	}
+}
+
+// rawConn sends lines to addr and reads the lines that come back
+type rawConn struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func dialRaw(t *testing.T, addr string) *rawConn {
+	t.Helper()
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return &rawConn{t: t, conn: conn, r: bufio.NewReader(conn)}
+}
+
+func (c *rawConn) send(line string) {
+	c.t.Helper()
+	if _, err := io.WriteString(c.conn, line+"\n"); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func (c *rawConn) recv() string {
+	c.t.Helper()
+	c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		c.t.Fatalf("reading response: %v", err)
+	}
+	return strings.TrimSpace(line)
+}
+
+func TestServer_Protocol(t *testing.T) {
+	addr := startServer(t, nil)
+
+	tests := []struct {
+		name string
+		req  string
+		want string // "" for no response
+	}{
+		{
+			"by name",
+			`{"jsonrpc":"2.0","method":"Books.Get","params":{"id":3},"id":1}`,
+			`{"jsonrpc":"2.0","result":{"id":3,"title":"Go in Action","author":"William Kennedy","price":24.99},"id":1}`,
+		},
+		{
+			"by position, with a string id",
+			`{"jsonrpc":"2.0","method":"Books.Get","params":[{"id":3}],"id":"abc"}`,
+			`{"jsonrpc":"2.0","result":{"id":3,"title":"Go in Action","author":"William Kennedy","price":24.99},"id":"abc"}`,
+		},
+		{
+			"null id is answered",
+			`{"jsonrpc":"2.0","method":"Books.List","params":{"author":"nobody"},"id":null}`,
+			`{"jsonrpc":"2.0","result":[],"id":null}`,
+		},
+		{
+			"notification",
+			`{"jsonrpc":"2.0","method":"Books.Get","params":{"id":3}}`,
+			``,
+		},
+		{
+			"notification of an unknown method",
+			`{"jsonrpc":"2.0","method":"Books.Nope"}`,
+			``,
+		},
+		{
+			"wrong version",
+			`{"jsonrpc":"1.0","method":"Books.Get","id":7}`,
+			`{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request"},"id":7}`,
+		},
+		{
+			"method not a string",
+			`{"jsonrpc":"2.0","method":1,"params":"bar"}`,
+			`{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request"},"id":null}`,
+		},
+		{
+			"params not structured",
+			`{"jsonrpc":"2.0","method":"Books.Get","params":3,"id":8}`,
+			`{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request"},"id":8}`,
+		},
+		{
+			"id not a string or number",
+			`{"jsonrpc":"2.0","method":"Books.Get","id":{"n":1}}`,
+			`{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request"},"id":null}`,
+		},
+		{
+			"not an object",
+			`"Books.Get"`,
+			`{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request"},"id":null}`,
+		},
+		{
+			"empty batch",
+			`[]`,
+			`{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request"},"id":null}`,
+		},
+		{
+			"batch of invalid requests",
+			`[1,2]`,
+			`[{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request"},"id":null},{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request"},"id":null}]`,
+		},
+		{
+			"batch answered in order, without its notifications",
+			`[{"jsonrpc":"2.0","method":"Books.Nope","id":"1"},{"jsonrpc":"2.0","method":"Books.Get","params":{"id":3}},{"jsonrpc":"2.0","method":"Books.Get","params":{"id":99},"id":"2"}]`,
+			`[{"jsonrpc":"2.0","error":{"code":-32601,"message":"Method not found","data":"Books.Nope"},"id":"1"},{"jsonrpc":"2.0","error":{"code":404,"message":"book not found","data":{"id":99}},"id":"2"}]`,
+		},
+		{
+			"batch of notifications",
+			`[{"jsonrpc":"2.0","method":"Books.Get","params":{"id":1}},{"jsonrpc":"2.0","method":"Books.Get","params":{"id":2}}]`,
+			``,
+		},
+	}
+	conn := dialRaw(t, addr)
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			conn.t = t
+			conn.send(tc.req)
+			// A request sent after one with no response shows there was
+			// none, as its response is the next line
+			const probe = `{"jsonrpc":"2.0","method":"Books.List","params":{"author":"nobody"},"id":"probe"}`
+			const probeResp = `{"jsonrpc":"2.0","result":[],"id":"probe"}`
+			if tc.want == "" {
+				conn.send(probe)
+				if got := conn.recv(); got != probeResp {
+					t.Errorf("response\n got %s\nwant none", got)
+				}
+				return
+			}
+			if got := conn.recv(); got != tc.want {
+				t.Errorf("response\n got %s\nwant %s", got, tc.want)
+			}
+		})
+	}
+}
+
+// Input that is not JSON gets a parse error, and then the connection is
+// closed, as the requests after it cannot be told apart
+func TestServer_ParseError(t *testing.T) {
+	conn := dialRaw(t, startServer(t, nil))
+	conn.send(`{"jsonrpc":"2.0","method":"Books.Get","params":{"id":1},"id":1]`)
+	if got, want := conn.recv(), `{"jsonrpc":"2.0","error":{"code":-32700,"message":"Parse error"},"id":null}`; got != want {
+		t.Errorf("response\n got %s\nwant %s", got, want)
+	}
+	// EOF, or a reset if the close beat the rest of the line
+	if line, err := conn.r.ReadString('\n'); err == nil {
+		t.Errorf("read after parse error = %s; want the connection closed", line)
+	}
+}
+
+// Stopping the server lets a call in progress respond, then closes the
+// connection
+func TestServer_Shutdown(t *testing.T) {
+	test := &testService{release: make(chan struct{}), started: make(chan struct{})}
+	srv := NewServer(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err := srv.Register(test); err != nil {
+		t.Fatal(err)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ctx, ln) }()
+
+	client := dial(t, ln.Addr().String())
+	reply := make(chan error, 1)
+	go func() {
+		var s string
+		reply <- client.Call(context.Background(), "testService.Wait", nil, &s)
+	}()
+	<-test.started
+
+	cancel()
+	select {
+	case err := <-done:
+		t.Fatalf("Serve returned %v with a call in progress", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(test.release)
+	if err := <-reply; err != nil {
+		t.Errorf("call in progress = %v; want its reply", err)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("Serve = %v; want nil", err)
+	}
+	if err := client.Call(context.Background(), "testService.Wait", nil, nil); !errors.Is(err, ErrClosed) {
+		t.Errorf("call after shutdown = %v; want ErrClosed", err)
+	}
+	if _, err := net.Dial("tcp", ln.Addr().String()); err == nil {
+		t.Error("server still accepting connections")
+	}
+}
+
+func TestServer_Register(t *testing.T) {
+	srv := NewServer(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err := srv.RegisterName("Books", NewBookService()); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		rcvr    any
+		wantErr string
+	}{
+		{"Books", NewBookService(), "jsonrpc: Books.Create already registered"},
+		{"rpc.discover", NewBookService(), `jsonrpc: invalid receiver name "rpc.discover"`},
+		{"Empty", &struct{}{}, "jsonrpc: Empty has no methods of the form Method(args, *reply) error"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := srv.RegisterName(tc.name, tc.rcvr); err == nil || err.Error() != tc.wantErr {
+				t.Errorf("RegisterName = %v; want %s", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/money"
+)
+
+// Run starts the server, or with "client" as the first argument runs the
+// demo client against one
+func Run(args []string) error {
+	if len(args) > 0 && args[0] == "client" {
+		fs := flag.NewFlagSet("client", flag.ContinueOnError)
+		addr := fs.String("addr", "localhost:4000", "address of the server")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		return runClient(*addr)
+	}
+
+	fs := flag.NewFlagSet("jsonrpc", flag.ContinueOnError)
+	addr := fs.String("addr", ":4000", "TCP address to listen on")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	return serve(*addr)
+}
+
+// serve runs the server on addr until SIGINT or SIGTERM
+func serve(addr string) error {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	srv := NewServer(logger)
+	if err := srv.RegisterName("Books", NewBookService()); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Serving JSON-RPC 2.0 on %s\n", ln.Addr())
+	fmt.Println("Methods:")
+	fmt.Println(`  Books.List   {"author": "..."}          - List books, all or by one author`)
+	fmt.Println(`  Books.Get    {"id": 1}                  - Get a book`)
+	fmt.Println(`  Books.Create {"title", "author", "price"} - Create a book`)
+	fmt.Println(`  Books.Update {"id": 1, "book": {...}}   - Replace a book`)
+	fmt.Println(`  Books.Delete {"id": 1}                  - Delete a book`)
+
+	if err := srv.Serve(ctx, ln); err != nil {
+		return err
+	}
+	logger.Info("server shut down")
+	return nil
+}
+
+// runClient makes a few calls to the server at addr, one at a time and as
+// a batch, printing what comes back
+func runClient(addr string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	client, err := Dial(ctx, addr)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	var books []Book
+	if err := client.Call(ctx, "Books.List", ListArgs{}, &books); err != nil {
+		return err
+	}
+	fmt.Println("Books.List:")
+	for _, b := range books {
+		fmt.Printf("  %d. %s by %s, %s\n", b.ID, b.Title, b.Author, b.Price)
+	}
+
+	var created Book
+	newBook := Book{Title: "Learning Go", Author: "Jon Bodner", Price: money.MustParse("29.99")}
+	if err := client.Call(ctx, "Books.Create", newBook, &created); err != nil {
+		return err
+	}
+	fmt.Printf("Books.Create: book %d\n", created.ID)
+
+	// One round trip for three calls, one of which fails on its own
+	var first, missing Book
+	var deleted bool
+	calls := []*Call{
+		{Method: "Books.Get", Params: IDArgs{ID: 1}, Result: &first},
+		{Method: "Books.Get", Params: IDArgs{ID: 99}, Result: &missing},
+		{Method: "Books.Delete", Params: IDArgs{ID: created.ID}, Result: &deleted},
+	}
+	if err := client.Batch(ctx, calls...); err != nil {
+		return err
+	}
+	fmt.Println("Batch:")
+	for _, c := range calls {
+		if c.Err != nil {
+			fmt.Printf("  %s %+v: %v\n", c.Method, c.Params, c.Err)
+			continue
+		}
+		result, _ := json.Marshal(c.Result)
+		fmt.Printf("  %s %+v: %s\n", c.Method, c.Params, result)
+	}
+	return nil
+}
+
+/*
+This project demonstrates:
+
+1. JSON-RPC 2.0 (https://www.jsonrpc.org/specification)
+   - Requests, notifications (no id, so no response) and batches sent as
+     an array and answered as one, leaving out the notifications
+   - The specification's error codes: -32700 for input that is not JSON,
+     -32600 for a request without "jsonrpc": "2.0" or a method, -32601 for
+     an unknown method, -32602 for params that do not fit, -32603 for a
+     panic, -32000 for other method errors, and an application code of
+     404 for a missing book
+   - Params by name (an object) or by position (an array of one object)
+
+2. net/rpc concepts without net/rpc
+   - Methods of the form Method(args T, reply *R) error found by
+     reflection and registered as "Books.Method"
+   - A codec reading one JSON value after another from a TCP stream, with
+     requests handled concurrently and responses written under a mutex
+   - A client matching responses to calls by id from a reader goroutine,
+     so calls on one connection can overlap
+
+3. TCP servers
+   - Accepting connections until a context is canceled, then stopping
+     reads with a deadline and letting calls in progress respond
+
+To try it, run the server and the demo client, or talk to it with netcat:
+
+go run ./cmd/runner serve jsonrpc -addr :4000
+go run ./cmd/runner serve jsonrpc client -addr localhost:4000
+
+# One request per line; each response is a line too
+echo '{"jsonrpc":"2.0","method":"Books.Get","params":{"id":1},"id":1}' | nc localhost 4000
+# {"jsonrpc":"2.0","result":{"id":1,"title":"The Go Programming Language",...},"id":1}
+
+# A batch; the notification in the middle gets no response
+echo '[{"jsonrpc":"2.0","method":"Books.Get","params":[{"id":2}],"id":"a"},
+       {"jsonrpc":"2.0","method":"Books.Delete","params":{"id":3}},
+       {"jsonrpc":"2.0","method":"Books.Nope","id":"b"}]' | nc localhost 4000
+# [{"jsonrpc":"2.0","result":{"id":2,...},"id":"a"},{"jsonrpc":"2.0","error":{"code":-32601,"message":"Method not found","data":"Books.Nope"},"id":"b"}]
+*/
@@ -0,0 +1,366 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Error codes of the JSON-RPC 2.0 specification. -32000 to -32099 are left
+// to the server; codes outside -32768 to -32000 are the application's.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+	CodeServerError    = -32000 // a method's error that is not an *Error
+)
+
+// Error is a JSON-RPC error object. A method returns one to choose the code
+// its caller sees; any other error it returns is sent as CodeServerError.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("jsonrpc: %s (%d)", e.Message, e.Code)
+}
+
+// message is a request or a response on the wire. ID is nil for a
+// notification, which was sent without one, and "null" for a response to a
+// request whose id could not be read.
+type message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"`
+}
+
+// nullID is the id of a response to a request whose id is unknown
+var nullID = json.RawMessage("null")
+
+var typeOfError = reflect.TypeFor[error]()
+
+// method is a registered method, called as rcvr.fn(args, reply)
+type method struct {
+	rcvr      reflect.Value
+	fn        reflect.Value
+	argType   reflect.Type
+	replyType reflect.Type // the type reply points to
+}
+
+// Server calls the methods of registered receivers for JSON-RPC 2.0
+// requests, which name them "Receiver.Method" as net/rpc does. Each
+// connection carries one JSON value after another: a request, a
+// notification or a batch of them as an array.
+type Server struct {
+	logger *slog.Logger
+
+	mu      sync.RWMutex
+	methods map[string]*method
+}
+
+// NewServer returns a Server with no methods, logging to logger
+func NewServer(logger *slog.Logger) *Server {
+	return &Server{logger: logger, methods: make(map[string]*method)}
+}
+
+// Register publishes the methods of rcvr under the name of its type; see
+// RegisterName
+func (s *Server) Register(rcvr any) error {
+	return s.RegisterName(reflect.Indirect(reflect.ValueOf(rcvr)).Type().Name(), rcvr)
+}
+
+// RegisterName publishes the methods of rcvr that have the net/rpc form
+//
+//	func (t *T) Method(args A, reply *R) error
+//
+// as name.Method. The params of a request are decoded into args, from an
+// object by name or from an array holding just that object, and *reply is
+// the result. It is an error if rcvr has no such methods.
+func (s *Server) RegisterName(name string, rcvr any) error {
+	if name == "" || strings.HasPrefix(name, "rpc.") {
+		return fmt.Errorf("jsonrpc: invalid receiver name %q", name)
+	}
+	v := reflect.ValueOf(rcvr)
+	methods := make(map[string]*method)
+	for i := range v.Type().NumMethod() {
+		m := v.Type().Method(i)
+		t := m.Type
+		if !m.IsExported() || t.NumIn() != 3 || t.NumOut() != 1 ||
+			t.In(2).Kind() != reflect.Pointer || t.Out(0) != typeOfError {
+			continue
+		}
+		methods[name+"."+m.Name] = &method{rcvr: v, fn: m.Func, argType: t.In(1), replyType: t.In(2).Elem()}
+	}
+	if len(methods) == 0 {
+		return fmt.Errorf("jsonrpc: %s has no methods of the form Method(args, *reply) error", name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, n := range slices.Sorted(maps.Keys(methods)) {
+		if _, ok := s.methods[n]; ok {
+			return fmt.Errorf("jsonrpc: %s already registered", n)
+		}
+	}
+	for n, m := range methods {
+		s.methods[n] = m
+	}
+	return nil
+}
+
+// Serve accepts connections on ln and serves each in its own goroutine
+// until ctx is done. It then stops reading requests, lets the calls in
+// progress send their responses and closes the connections before
+// returning nil.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	var (
+		mu    sync.Mutex
+		conns = make(map[net.Conn]struct{})
+		wg    sync.WaitGroup
+	)
+	// An expired read deadline ends a connection's read loop, which then
+	// waits for its calls; the listener closing ends the accept loop
+	stop := context.AfterFunc(ctx, func() {
+		ln.Close()
+		mu.Lock()
+		defer mu.Unlock()
+		for c := range conns {
+			c.SetReadDeadline(time.Now())
+		}
+	})
+	defer stop()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			wg.Wait()
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		mu.Lock()
+		conns[conn] = struct{}{}
+		if ctx.Err() != nil {
+			conn.SetReadDeadline(time.Now())
+		}
+		mu.Unlock()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.ServeConn(conn)
+			mu.Lock()
+			delete(conns, conn)
+			mu.Unlock()
+		}()
+	}
+}
+
+// ServeConn serves requests on conn until the client closes it or sends
+// something that is not JSON, then closes conn once every call has
+// responded. Requests are handled concurrently, so responses may come back
+// in a different order; their ids tell them apart.
+func (s *Server) ServeConn(conn io.ReadWriteCloser) {
+	var (
+		wmu   sync.Mutex
+		calls sync.WaitGroup
+	)
+	defer conn.Close()
+	defer calls.Wait()
+	write := func(v any) {
+		wmu.Lock()
+		defer wmu.Unlock()
+		if err := json.NewEncoder(conn).Encode(v); err != nil {
+			s.logger.Debug("jsonrpc: writing response", "error", err)
+		}
+	}
+
+	dec := json.NewDecoder(conn)
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			// After a syntax error the rest of the stream cannot be
+			// framed, so the connection ends with the parse error
+			var syntax *json.SyntaxError
+			if errors.As(err, &syntax) || errors.Is(err, io.ErrUnexpectedEOF) {
+				write(&message{JSONRPC: "2.0", Error: &Error{Code: CodeParseError, Message: "Parse error"}, ID: nullID})
+			}
+			return
+		}
+		calls.Add(1)
+		go func() {
+			defer calls.Done()
+			if resp := s.handle(raw); resp != nil {
+				write(resp)
+			}
+		}()
+	}
+}
+
+// handle returns the response to raw, a request or a batch, or nil if it
+// needs none: a notification, or a batch of nothing but notifications
+func (s *Server) handle(raw json.RawMessage) any {
+	if raw[0] != '[' {
+		if resp := s.call(raw); resp != nil {
+			return resp
+		}
+		return nil
+	}
+
+	var batch []json.RawMessage
+	if err := json.Unmarshal(raw, &batch); err != nil || len(batch) == 0 {
+		return &message{JSONRPC: "2.0", Error: &Error{Code: CodeInvalidRequest, Message: "Invalid Request"}, ID: nullID}
+	}
+	resps := make([]*message, len(batch))
+	var wg sync.WaitGroup
+	for i, req := range batch {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resps[i] = s.call(req)
+		}()
+	}
+	wg.Wait()
+
+	var out []*message
+	for _, resp := range resps {
+		if resp != nil {
+			out = append(out, resp)
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// call runs one request and returns its response, or nil if it was a
+// notification. A request that is not valid always gets a response, as it
+// cannot be known to be a notification.
+func (s *Server) call(raw json.RawMessage) *message {
+	req, err := parseRequest(raw)
+	if err != nil {
+		id := nullID
+		if req != nil && req.ID != nil {
+			id = req.ID
+		}
+		return &message{JSONRPC: "2.0", Error: err, ID: id}
+	}
+	result, err := s.invoke(req)
+	if req.ID == nil {
+		return nil
+	}
+	if err != nil {
+		return &message{JSONRPC: "2.0", Error: err, ID: req.ID}
+	}
+	return &message{JSONRPC: "2.0", Result: result, ID: req.ID}
+}
+
+// invoke calls the method req names and returns its reply as JSON
+func (s *Server) invoke(req *message) (result json.RawMessage, rpcErr *Error) {
+	s.mu.RLock()
+	m := s.methods[req.Method]
+	s.mu.RUnlock()
+	if m == nil {
+		return nil, &Error{Code: CodeMethodNotFound, Message: "Method not found", Data: req.Method}
+	}
+
+	args := reflect.New(m.argType)
+	if err := decodeParams(req.Params, args.Interface()); err != nil {
+		return nil, &Error{Code: CodeInvalidParams, Message: "Invalid params", Data: err.Error()}
+	}
+	reply := reflect.New(m.replyType)
+
+	defer func() {
+		if v := recover(); v != nil {
+			s.logger.Error("jsonrpc: method panicked", "method", req.Method, "panic", v)
+			result, rpcErr = nil, &Error{Code: CodeInternalError, Message: "Internal error"}
+		}
+	}()
+	out := m.fn.Call([]reflect.Value{m.rcvr, args.Elem(), reply})
+	if err, _ := out[0].Interface().(error); err != nil {
+		var e *Error
+		if errors.As(err, &e) {
+			return nil, e
+		}
+		return nil, &Error{Code: CodeServerError, Message: err.Error()}
+	}
+	result, err := json.Marshal(reply.Interface())
+	if err != nil {
+		s.logger.Error("jsonrpc: encoding result", "method", req.Method, "error", err)
+		return nil, &Error{Code: CodeInternalError, Message: "Internal error"}
+	}
+	return result, nil
+}
+
+// parseRequest checks that raw is a request object as the specification
+// defines it. When it is not, the returned message holds the id if that
+// much could be read.
+func parseRequest(raw json.RawMessage) (*message, *Error) {
+	invalid := &Error{Code: CodeInvalidRequest, Message: "Invalid Request"}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil || fields == nil {
+		return nil, invalid
+	}
+
+	req := &message{Params: fields["params"], ID: fields["id"]}
+	if id := req.ID; id != nil && id[0] != '"' && id[0] != '-' && (id[0] < '0' || id[0] > '9') && !bytes.Equal(id, nullID) {
+		req.ID = nil
+		return req, invalid
+	}
+	if json.Unmarshal(fields["jsonrpc"], &req.JSONRPC) != nil || req.JSONRPC != "2.0" {
+		return req, invalid
+	}
+	if json.Unmarshal(fields["method"], &req.Method) != nil || req.Method == "" {
+		return req, invalid
+	}
+	if p := req.Params; p != nil && p[0] != '[' && p[0] != '{' {
+		return req, invalid
+	}
+	return req, nil
+}
+
+// decodeParams decodes by-name params, an object, or by-position params,
+// an array of at most one object, into args. Fields args does not have are
+// an error, so a misspelt name is not silently ignored.
+func decodeParams(params json.RawMessage, args any) error {
+	if params == nil {
+		return nil
+	}
+	if params[0] == '[' {
+		var list []json.RawMessage
+		if err := json.Unmarshal(params, &list); err != nil {
+			return err
+		}
+		switch len(list) {
+		case 0:
+			return nil
+		case 1:
+			params = list[0]
+		default:
+			return fmt.Errorf("%d positional params; want at most 1", len(list))
+		}
+	}
+	dec := json.NewDecoder(bytes.NewReader(params))
+	dec.DisallowUnknownFields()
+	return dec.Decode(args)
+}
@@ -0,0 +1,262 @@
+package kvstore
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a clock the test moves with Advance
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// client is the test's end of a net.Pipe served by ServeConn
+type client struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+	done chan struct{} // closed when ServeConn returns
+}
+
+func connect(t *testing.T, store *Store) *client {
+	t.Helper()
+	srv := NewServer(store, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	serverEnd, clientEnd := net.Pipe()
+	c := &client{t: t, conn: clientEnd, r: bufio.NewReader(clientEnd), done: make(chan struct{})}
+	go func() {
+		defer close(c.done)
+		srv.ServeConn(serverEnd)
+	}()
+	t.Cleanup(func() {
+		clientEnd.Close()
+		<-c.done
+	})
+	return c
+}
+
+// do sends line and returns the reply, as many lines as it has, joined
+// by spaces
+func (c *client) do(line string) string {
+	c.t.Helper()
+	c.conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, err := io.WriteString(c.conn, line+"\r\n"); err != nil {
+		c.t.Fatalf("sending %q: %v", line, err)
+	}
+	reply := c.readLine()
+	var n int
+	if _, err := fmt.Sscanf(reply, "*%d", &n); err == nil {
+		for range n {
+			reply += " " + c.readLine()
+		}
+	}
+	return reply
+}
+
+func (c *client) readLine() string {
+	c.t.Helper()
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		c.t.Fatalf("reading reply: %v", err)
+	}
+	return strings.TrimSuffix(line, "\n")
+}
+
+func TestProtocol(t *testing.T) {
+	c := connect(t, NewStore(nil))
+	steps := []struct{ cmd, want string }{
+		{"GET greeting", "_"},
+		{"SET greeting hello  world ", "+OK"},
+		{"GET greeting", "$hello  world "},
+		{"get greeting", "$hello  world "},
+		{"SET empty ", "+OK"},
+		{"GET empty", "$"},
+		{"SET user:1 alice", "+OK"},
+		{"SET user:2 bob", "+OK"},
+		{"KEYS user:*", "*2 $user:1 $user:2"},
+		{"KEYS *", "*4 $empty $greeting $user:1 $user:2"},
+		{"KEYS nothing*", "*0"},
+		{"KEYS [", `-ERR invalid pattern "["`},
+		{"TTL greeting", ":-1"},
+		{"TTL missing", ":-2"},
+		{"EXPIRE missing 10", ":0"},
+		{"EXPIRE greeting ten", `-ERR expire time "ten" is not an integer`},
+		{"EXPIRE greeting 0", ":1"},
+		{"GET greeting", "_"},
+		{"DEL user:1 user:2 user:3", ":2"},
+		{"DEL user:1", ":0"},
+		{"SET", "-ERR wrong number of arguments for SET"},
+		{"SET onlykey", "-ERR wrong number of arguments for SET"},
+		{"get", "-ERR wrong number of arguments for GET"},
+		{"DEL", "-ERR wrong number of arguments for DEL"},
+		{"INCR counter", `-ERR unknown command "INCR"`},
+		{"QUIT", "+OK"},
+	}
+	for _, s := range steps {
+		if got := c.do(s.cmd); got != s.want {
+			t.Errorf("%s\n got %s\nwant %s", s.cmd, got, s.want)
+		}
+	}
+	select {
+	case <-c.done:
+	case <-time.After(5 * time.Second):
+		t.Error("connection still open after QUIT")
+	}
+}
+
+func TestProtocol_LineTooLong(t *testing.T) {
+	c := connect(t, NewStore(nil))
+	c.conn.SetDeadline(time.Now().Add(5 * time.Second))
+	go io.WriteString(c.conn, "SET big "+strings.Repeat("x", MaxLineSize)+"\n")
+	if got, want := c.readLine(), fmt.Sprintf("-ERR line longer than %d bytes", MaxLineSize); got != want {
+		t.Errorf("reply = %s; want %s", got, want)
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	clock := newFakeClock()
+	store := NewStore(clock.Now)
+	c := connect(t, store)
+
+	c.do("SET session abc")
+	c.do("SET other xyz")
+	c.do("EXPIRE session 10")
+	c.do("EXPIRE other 5")
+	clock.Advance(9500 * time.Millisecond)
+	if got := c.do("TTL session"); got != ":1" {
+		t.Errorf("TTL with 0.5s left = %s; want :1", got)
+	}
+	if got := c.do("GET session"); got != "$abc" {
+		t.Errorf("GET before expiry = %s", got)
+	}
+	if got := c.do("KEYS *"); got != "*1 $session" {
+		t.Errorf("KEYS = %s; want only the key not yet expired", got)
+	}
+
+	clock.Advance(500 * time.Millisecond)
+	if got := c.do("GET session"); got != "_" {
+		t.Errorf("GET at expiry = %s; want _", got)
+	}
+	// other expired too, but nothing has read it
+	if n := store.Sweep(); n != 1 {
+		t.Errorf("Sweep removed %d keys; want 1", n)
+	}
+
+	c.do("SET session def")
+	if got := c.do("TTL session"); got != ":-1" {
+		t.Errorf("TTL after SET = %s; want SET to clear the expiry", got)
+	}
+}
+
+func TestPersistence(t *testing.T) {
+	clock := newFakeClock()
+	path := filepath.Join(t.TempDir(), "kv.aof")
+	store, err := OpenStore(path, clock.Now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := connect(t, store)
+	for _, cmd := range []string{
+		"SET a 1",
+		"SET b two words",
+		"SET c 3",
+		"DEL c",
+		"SET d short-lived",
+		"EXPIRE d 60",
+		"SET e long-lived",
+		"EXPIRE e 3600",
+		"SET b changed",
+	} {
+		c.do(cmd)
+	}
+	c.do("QUIT")
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Down for two minutes, and the last write was cut short
+	clock.Advance(2 * time.Minute)
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(f, "SET f never-acknowl")
+	f.Close()
+
+	store, err = OpenStore(path, clock.Now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	c = connect(t, store)
+	if got := c.do("KEYS *"); got != "*3 $a $b $e" {
+		t.Errorf("KEYS after replay = %s; want a, b and e", got)
+	}
+	if got := c.do("GET b"); got != "$changed" {
+		t.Errorf("GET b = %s; want the last value", got)
+	}
+	if got := c.do("TTL e"); got != ":3480" {
+		t.Errorf("TTL e = %s; want the deadline kept across the restart", got)
+	}
+}
+
+func TestOpenStore_Corrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kv.aof")
+	if err := os.WriteFile(path, []byte("SET a 1\nFLUSHALL\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenStore(path, nil); err == nil || !strings.Contains(err.Error(), `line 2: unknown operation "FLUSHALL"`) {
+		t.Errorf("OpenStore = %v; want the bad line reported", err)
+	}
+}
+
+// Clients on their own connections see each other's writes
+func TestConcurrentClients(t *testing.T) {
+	store := NewStore(nil)
+	const clients, keys = 8, 50
+	var wg sync.WaitGroup
+	for i := range clients {
+		c := connect(t, store)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k := range keys {
+				if got := c.do(fmt.Sprintf("SET c%d:k%d v", i, k)); got != "+OK" {
+					t.Errorf("SET = %s", got)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	got, _ := store.Keys("*")
+	if len(got) != clients*keys {
+		t.Errorf("%d keys; want %d", len(got), clients*keys)
+	}
+}
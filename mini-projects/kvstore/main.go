@@ -0,0 +1,113 @@
+package kvstore
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// Run serves the store over TCP, configured by the flags in args, until
+// SIGINT or SIGTERM
+func Run(args []string) error {
+	fs := flag.NewFlagSet("kvstore", flag.ContinueOnError)
+	addr := fs.String("addr", ":6380", "TCP address to listen on")
+	aof := fs.String("aof", "kvstore.aof", "append-only log to replay and persist to; empty keeps keys in memory only")
+	sweep := fs.Duration("sweep", time.Second, "how often expired keys are removed from memory")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+	store := NewStore(time.Now)
+	if *aof != "" {
+		var err error
+		if store, err = OpenStore(*aof, time.Now); err != nil {
+			return err
+		}
+		keys, _ := store.Keys("*")
+		logger.Info("log replayed", "path", *aof, "keys", len(keys))
+	}
+	defer func() {
+		if err := store.Close(); err != nil {
+			logger.Error("closing log", "error", err)
+		}
+	}()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		ticker := time.NewTicker(*sweep)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				store.Sweep()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	ln, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Key-value store listening on %s\n", ln.Addr())
+	fmt.Println("Commands: SET key value | GET key | DEL key... | EXPIRE key seconds | TTL key | KEYS pattern | QUIT")
+	if err := NewServer(store, logger).Serve(ctx, ln); err != nil {
+		return err
+	}
+	logger.Info("server shut down")
+	return nil
+}
+
+/*
+This project demonstrates:
+
+1. A TCP server with a line-based text protocol
+   - bufio.Scanner framing commands, with a bound on the line length
+   - One goroutine per connection over one mutex-guarded map
+   - Graceful shutdown: the listener closes and read deadlines end each
+     connection's loop once its current command has replied
+
+2. Expiry
+   - Keys checked lazily when read, plus a periodic sweep so expired keys
+     that are never read again do not stay in memory
+   - An injected clock, so tests move time instead of sleeping
+
+3. Persistence with an append-only log
+   - Each change appended under the same lock as the change, in order
+   - Deadlines logged instead of TTLs, so a restart does not extend them
+   - Replay on startup, ignoring a last line cut short by a crash
+
+To try it, run the server and talk to it with netcat or telnet:
+
+go run ./cmd/runner serve kvstore -addr :6380 -aof /tmp/kv.aof
+
+nc localhost 6380
+SET greeting hello world
++OK
+GET greeting
+$hello world
+EXPIRE greeting 60
+:1
+TTL greeting
+:60
+KEYS g*
+*1
+$greeting
+DEL greeting missing
+:1
+GET greeting
+_
+QUIT
++OK
+
+Keys set without an expiry are there again after restarting the server.
+*/
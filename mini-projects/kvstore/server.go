@@ -0,0 +1,221 @@
+package kvstore
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxLineSize bounds a command line, the value of a SET included
+const MaxLineSize = 1 << 20
+
+// Server speaks the text protocol to clients of one Store. A command is a
+// line of space-separated words, the command name in any case:
+//
+//	SET key value     +OK               value is the rest of the line
+//	GET key           $value, or _      _ for no such key
+//	DEL key [key...]  :2                how many keys there were
+//	EXPIRE key secs   :1, or :0         :0 for no such key
+//	TTL key           :secs             :-1 for no expiry, :-2 for no key
+//	KEYS pattern      *2 $a $b          a count, then one key per line
+//	QUIT              +OK               and the server hangs up
+//
+// Errors are a line starting -ERR. Lines may end in \n or \r\n, so a
+// client can be telnet or netcat.
+type Server struct {
+	store  *Store
+	logger *slog.Logger
+}
+
+// NewServer returns a Server for store, logging to logger
+func NewServer(store *Store, logger *slog.Logger) *Server {
+	return &Server{store: store, logger: logger}
+}
+
+// Serve accepts connections on ln and serves each in its own goroutine
+// until ctx is done. It then stops reading commands, lets the command in
+// progress on each connection reply and closes the connections before
+// returning nil.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	var (
+		mu    sync.Mutex
+		conns = make(map[net.Conn]struct{})
+		wg    sync.WaitGroup
+	)
+	// An expired read deadline ends a connection's read loop; the listener
+	// closing ends the accept loop
+	stop := context.AfterFunc(ctx, func() {
+		ln.Close()
+		mu.Lock()
+		defer mu.Unlock()
+		for c := range conns {
+			c.SetReadDeadline(time.Now())
+		}
+	})
+	defer stop()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			wg.Wait()
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		mu.Lock()
+		conns[conn] = struct{}{}
+		if ctx.Err() != nil {
+			conn.SetReadDeadline(time.Now())
+		}
+		mu.Unlock()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.ServeConn(conn)
+			mu.Lock()
+			delete(conns, conn)
+			mu.Unlock()
+		}()
+	}
+}
+
+// ServeConn runs the commands read from conn, one at a time, until the
+// client hangs up or sends QUIT, then closes conn
+func (s *Server) ServeConn(conn io.ReadWriteCloser) {
+	defer conn.Close()
+	sc := bufio.NewScanner(conn)
+	sc.Buffer(make([]byte, 0, 4096), MaxLineSize)
+	w := bufio.NewWriter(conn)
+	for sc.Scan() {
+		line := strings.TrimSuffix(sc.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		reply, quit := s.exec(line)
+		w.WriteString(reply)
+		if err := w.Flush(); err != nil || quit {
+			return
+		}
+	}
+	if errors.Is(sc.Err(), bufio.ErrTooLong) {
+		w.WriteString(errorReply("line longer than %d bytes", MaxLineSize))
+		w.Flush()
+	}
+}
+
+// exec runs one command line and returns its reply, newline included, and
+// whether the connection should end
+func (s *Server) exec(line string) (reply string, quit bool) {
+	name, rest, _ := strings.Cut(line, " ")
+	args := strings.Fields(rest)
+	switch strings.ToUpper(name) {
+	case "GET":
+		if len(args) != 1 {
+			return arityError(name), false
+		}
+		if v, ok := s.store.Get(args[0]); ok {
+			return "$" + v + "\n", false
+		}
+		return "_\n", false
+
+	case "SET":
+		// The value is the rest of the line, spaces and all
+		key, value, ok := strings.Cut(rest, " ")
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return arityError(name), false
+		}
+		if err := s.store.Set(key, value); err != nil {
+			return s.internalError(err), false
+		}
+		return "+OK\n", false
+
+	case "DEL":
+		if len(args) == 0 {
+			return arityError(name), false
+		}
+		n, err := s.store.Del(args...)
+		if err != nil {
+			return s.internalError(err), false
+		}
+		return intReply(n), false
+
+	case "EXPIRE":
+		if len(args) != 2 {
+			return arityError(name), false
+		}
+		secs, err := strconv.Atoi(args[1])
+		if err != nil {
+			return errorReply("expire time %q is not an integer", args[1]), false
+		}
+		ok, err := s.store.Expire(args[0], time.Duration(secs)*time.Second)
+		if err != nil {
+			return s.internalError(err), false
+		}
+		if ok {
+			return intReply(1), false
+		}
+		return intReply(0), false
+
+	case "TTL":
+		if len(args) != 1 {
+			return arityError(name), false
+		}
+		ttl, expires, ok := s.store.TTL(args[0])
+		switch {
+		case !ok:
+			return intReply(-2), false
+		case !expires:
+			return intReply(-1), false
+		}
+		// Rounded up, so a key with any time left is not reported as 0
+		return intReply(int((ttl + time.Second - 1) / time.Second)), false
+
+	case "KEYS":
+		if len(args) != 1 {
+			return arityError(name), false
+		}
+		keys, err := s.store.Keys(args[0])
+		if err != nil {
+			return errorReply("invalid pattern %q", args[0]), false
+		}
+		var b strings.Builder
+		fmt.Fprintf(&b, "*%d\n", len(keys))
+		for _, k := range keys {
+			b.WriteString("$" + k + "\n")
+		}
+		return b.String(), false
+
+	case "QUIT":
+		return "+OK\n", true
+	}
+	return errorReply("unknown command %q", name), false
+}
+
+func intReply(n int) string {
+	return ":" + strconv.Itoa(n) + "\n"
+}
+
+func errorReply(format string, args ...any) string {
+	return "-ERR " + fmt.Sprintf(format, args...) + "\n"
+}
+
+func arityError(name string) string {
+	return errorReply("wrong number of arguments for %s", strings.ToUpper(name))
+}
+
+// internalError logs err, which is the log failing, and replies without
+// its details
+func (s *Server) internalError(err error) string {
+	s.logger.Error("kvstore: command failed", "error", err)
+	return errorReply("internal error")
+}
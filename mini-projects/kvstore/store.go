@@ -0,0 +1,249 @@
+package kvstore
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// entry is a value and when it expires; a zero expires never does
+type entry struct {
+	value   string
+	expires time.Time
+}
+
+// Store is the key space: string keys to string values, each with an
+// optional expiry. An expired key is removed when it is next read, or by
+// Sweep, whichever comes first.
+//
+// A Store opened with OpenStore appends each change to a log file under
+// the same lock as the change, so the log holds the changes in the order
+// they were made and replaying it rebuilds the same keys. It is safe for
+// concurrent use.
+type Store struct {
+	now func() time.Time
+
+	mu   sync.Mutex
+	data map[string]entry
+	log  *bufio.Writer // nil if the store is not persisted
+	file *os.File
+}
+
+// NewStore returns an empty store kept only in memory, reading the time
+// from now (time.Now if nil)
+func NewStore(now func() time.Time) *Store {
+	if now == nil {
+		now = time.Now
+	}
+	return &Store{now: now, data: make(map[string]entry)}
+}
+
+// OpenStore returns a store persisted to the append-only log at path,
+// created if missing. The changes already in the log are replayed first;
+// keys whose expiry passed while the server was down are gone.
+func OpenStore(path string, now func() time.Time) (*Store, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	s := NewStore(now)
+	if err := s.replay(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("replaying %s: %w", path, err)
+	}
+	s.file = f
+	s.log = bufio.NewWriter(f)
+	return s, nil
+}
+
+// Close flushes the log to disk and closes it
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := errors.Join(s.log.Flush(), s.file.Sync(), s.file.Close())
+	s.file, s.log = nil, nil
+	return err
+}
+
+// Get returns the value of key
+func (s *Store) Get(key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.lookup(key)
+	return e.value, ok
+}
+
+// Set sets key to value, clearing any expiry it had
+func (s *Store) Set(key, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.append("SET", key, value); err != nil {
+		return err
+	}
+	s.data[key] = entry{value: value}
+	return nil
+}
+
+// Del removes keys and returns how many of them there were
+func (s *Store) Del(keys ...string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, key := range keys {
+		if _, ok := s.lookup(key); !ok {
+			continue
+		}
+		if err := s.append("DEL", key); err != nil {
+			return n, err
+		}
+		delete(s.data, key)
+		n++
+	}
+	return n, nil
+}
+
+// Expire makes key expire after ttl, at once if ttl is not positive, and
+// reports whether there was such a key
+func (s *Store) Expire(key string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.lookup(key)
+	if !ok {
+		return false, nil
+	}
+	// The log gets the deadline rather than the ttl, so a replay does not
+	// start the countdown again
+	e.expires = s.now().Add(ttl)
+	if err := s.append("EXPIREAT", key, strconv.FormatInt(e.expires.UnixMilli(), 10)); err != nil {
+		return false, err
+	}
+	if ttl <= 0 {
+		delete(s.data, key)
+	} else {
+		s.data[key] = e
+	}
+	return true, nil
+}
+
+// TTL returns how long until key expires, and whether it expires at all;
+// ok is false if there is no such key
+func (s *Store) TTL(key string) (ttl time.Duration, expires, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.lookup(key)
+	if !ok || e.expires.IsZero() {
+		return 0, false, ok
+	}
+	return e.expires.Sub(s.now()), true, true
+}
+
+// Keys returns the keys matching pattern, a path.Match pattern such as
+// "user:*", in order
+func (s *Store) Keys(pattern string) ([]string, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	var keys []string
+	for key, e := range s.data {
+		if ok, _ := path.Match(pattern, key); ok && !e.expired(now) {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys, nil
+}
+
+// Sweep removes the expired keys, which would otherwise stay in memory
+// until read, and returns how many it removed
+func (s *Store) Sweep() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	n := 0
+	for key, e := range s.data {
+		if e.expired(now) {
+			delete(s.data, key)
+			n++
+		}
+	}
+	return n
+}
+
+func (e entry) expired(now time.Time) bool {
+	return !e.expires.IsZero() && !now.Before(e.expires)
+}
+
+// lookup returns key's entry, removing it if it has expired; s.mu is held
+func (s *Store) lookup(key string) (entry, bool) {
+	e, ok := s.data[key]
+	if ok && e.expired(s.now()) {
+		delete(s.data, key)
+		return entry{}, false
+	}
+	return e, ok
+}
+
+// append writes one change to the log as a line of space-separated
+// fields, the last of which, a value, may itself hold spaces; s.mu is
+// held. Each line is written through to the file, so a crash of the
+// process loses nothing that was acknowledged; a crash of the machine may
+// lose what the OS had not yet written out.
+func (s *Store) append(fields ...string) error {
+	if s.log == nil {
+		return nil
+	}
+	s.log.WriteString(strings.Join(fields, " "))
+	s.log.WriteByte('\n')
+	if err := s.log.Flush(); err != nil {
+		return fmt.Errorf("writing log: %w", err)
+	}
+	return nil
+}
+
+// replay applies the changes in r, written by append. A last line without
+// its newline is a write the process died during and is ignored.
+func (s *Store) replay(r io.Reader) error {
+	br := bufio.NewReader(r)
+	for n := 1; ; n++ {
+		line, err := br.ReadString('\n')
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		op, rest, _ := strings.Cut(strings.TrimSuffix(line, "\n"), " ")
+		key, arg, _ := strings.Cut(rest, " ")
+		switch op {
+		case "SET":
+			s.data[key] = entry{value: arg}
+		case "DEL":
+			delete(s.data, key)
+		case "EXPIREAT":
+			ms, err := strconv.ParseInt(arg, 10, 64)
+			if err != nil {
+				return fmt.Errorf("line %d: %w", n, err)
+			}
+			if e, ok := s.data[key]; ok {
+				e.expires = time.UnixMilli(ms)
+				s.data[key] = e
+			}
+		default:
+			return fmt.Errorf("line %d: unknown operation %q", n, op)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// batchSize is the number of lines a worker gets at once. Sending lines
+// one by one would spend more on channel operations than on parsing.
+const batchSize = 1024
+
+// pathStats aggregates the requests for one path
+type pathStats struct {
+	statuses  map[int]int
+	latencies []time.Duration
+}
+
+// Stats aggregates records. The zero Stats is empty and ready to use. It
+// is not safe for concurrent use: each worker fills its own, and Merge
+// combines them.
+type Stats struct {
+	Lines   int // lines read, blank ones included
+	Skipped int // lines that were not requests: blank, malformed or other events
+	paths   map[string]*pathStats
+}
+
+// Add counts rec
+func (s *Stats) Add(rec Record) {
+	if s.paths == nil {
+		s.paths = make(map[string]*pathStats)
+	}
+	ps := s.paths[rec.Path]
+	if ps == nil {
+		ps = &pathStats{statuses: make(map[int]int)}
+		s.paths[rec.Path] = ps
+	}
+	ps.statuses[rec.Status]++
+	ps.latencies = append(ps.latencies, rec.Latency)
+}
+
+// addLine parses and counts one line
+func (s *Stats) addLine(line []byte) {
+	s.Lines++
+	rec, err := ParseLine(line)
+	if err != nil {
+		s.Skipped++
+		return
+	}
+	s.Add(rec)
+}
+
+// Merge adds the counts of other to s, taking over some of its memory, so
+// other must not be used afterwards
+func (s *Stats) Merge(other *Stats) {
+	s.Lines += other.Lines
+	s.Skipped += other.Skipped
+	if s.paths == nil {
+		s.paths = make(map[string]*pathStats)
+	}
+	for path, o := range other.paths {
+		ps := s.paths[path]
+		if ps == nil {
+			s.paths[path] = o
+			continue
+		}
+		for status, n := range o.statuses {
+			ps.statuses[status] += n
+		}
+		ps.latencies = append(ps.latencies, o.latencies...)
+	}
+}
+
+// AnalyzeSequential reads r line by line on the calling goroutine
+func AnalyzeSequential(r io.Reader) (*Stats, error) {
+	var s Stats
+	sc := newScanner(r)
+	for sc.Scan() {
+		s.addLine(sc.Bytes())
+	}
+	return &s, sc.Err()
+}
+
+// Analyze reads r on the calling goroutine and hands its lines, in
+// batches, to workers goroutines that parse them. Each worker aggregates
+// into its own Stats, so the workers share nothing until their Stats are
+// merged at the end. Reading stops early if ctx is canceled.
+func Analyze(ctx context.Context, r io.Reader, workers int) (*Stats, error) {
+	workers = max(workers, 1)
+	batches := make(chan [][]byte, workers)
+	results := make([]*Stats, workers)
+	var wg sync.WaitGroup
+	for i := range workers {
+		results[i] = &Stats{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range batches {
+				for _, line := range batch {
+					results[i].addLine(line)
+				}
+			}
+		}()
+	}
+
+	// Scanner reuses its buffer, so each line is copied into the batch;
+	// one allocation per batch holds them all
+	sc := newScanner(r)
+	var err error
+	for more := true; more; {
+		var buf []byte
+		var ends []int
+		for len(ends) < batchSize {
+			if more = sc.Scan(); !more {
+				break
+			}
+			buf = append(buf, sc.Bytes()...)
+			ends = append(ends, len(buf))
+		}
+		batch := make([][]byte, len(ends))
+		start := 0
+		for i, end := range ends {
+			batch[i] = buf[start:end:end]
+			start = end
+		}
+		if len(batch) == 0 {
+			break
+		}
+		select {
+		case batches <- batch:
+		case <-ctx.Done():
+			err, more = ctx.Err(), false
+		}
+	}
+	close(batches)
+	wg.Wait()
+	if err == nil {
+		err = sc.Err()
+	}
+
+	total := &Stats{}
+	for _, s := range results {
+		total.Merge(s)
+	}
+	return total, err
+}
+
+// newScanner returns a line scanner allowing lines up to 1MB, as a JSON
+// log line can be long
+func newScanner(r io.Reader) *bufio.Scanner {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	return sc
+}
+
+// Report is the result of an analysis, ready to write out
+type Report struct {
+	Lines    int          `json:"lines"`
+	Skipped  int          `json:"skipped"`
+	Requests int          `json:"requests"`
+	Paths    []PathReport `json:"paths"`
+}
+
+// PathReport is one path's requests by status and its latency
+// percentiles, in milliseconds
+type PathReport struct {
+	Path     string         `json:"path"`
+	Requests int            `json:"requests"`
+	Statuses map[string]int `json:"statuses"` // keyed by status code
+	P50      float64        `json:"p50_ms"`
+	P90      float64        `json:"p90_ms"`
+	P99      float64        `json:"p99_ms"`
+	Max      float64        `json:"max_ms"`
+}
+
+// Report sorts each path's latencies and builds the report, with the
+// busiest paths first
+func (s *Stats) Report() Report {
+	rep := Report{Lines: s.Lines, Skipped: s.Skipped, Paths: []PathReport{}}
+	for _, path := range slices.Sorted(maps.Keys(s.paths)) {
+		ps := s.paths[path]
+		slices.Sort(ps.latencies)
+		pr := PathReport{
+			Path:     path,
+			Requests: len(ps.latencies),
+			Statuses: make(map[string]int, len(ps.statuses)),
+			P50:      millis(percentile(ps.latencies, 50)),
+			P90:      millis(percentile(ps.latencies, 90)),
+			P99:      millis(percentile(ps.latencies, 99)),
+			Max:      millis(ps.latencies[len(ps.latencies)-1]),
+		}
+		for status, n := range ps.statuses {
+			pr.Statuses[strconv.Itoa(status)] = n
+		}
+		rep.Requests += pr.Requests
+		rep.Paths = append(rep.Paths, pr)
+	}
+	slices.SortStableFunc(rep.Paths, func(a, b PathReport) int { return b.Requests - a.Requests })
+	return rep
+}
+
+// percentile returns the nearest-rank p-th percentile of sorted, which is
+// not empty: the smallest value at least p percent of values are at or
+// below
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[max(rank, 1)-1]
+}
+
+// millis returns d in milliseconds, to the microsecond
+func millis(d time.Duration) float64 {
+	return math.Round(float64(d)/float64(time.Microsecond)) / 1000
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		want    Record
+		wantErr string
+	}{
+		{
+			"common log format",
+			`203.0.113.9 - - [10/Oct/2024:13:55:36 +0000] "GET /books?page=2 HTTP/1.1" 200 2326 0.012`,
+			Record{"/books", 200, 12 * time.Millisecond}, "",
+		},
+		{
+			"combined, with referer and user agent",
+			`203.0.113.9 - bob [10/Oct/2024:13:55:36 +0000] "POST /auth/login HTTP/2.0" 401 64 "https://example.com/" "curl/8.5 (x86_64)" 0.0005`,
+			Record{"/auth/login", 401, 500 * time.Microsecond}, "",
+		},
+		{
+			"rest_api JSON request log",
+			`{"time":"2024-10-10T13:55:36Z","level":"INFO","msg":"request","method":"GET","path":"/books/1","pattern":"GET /books/{id}","status":404,"bytes":120,"duration":1500000,"request_id":"abc"}`,
+			Record{"/books/1", 404, 1500 * time.Microsecond}, "",
+		},
+		{
+			"other JSON event",
+			`{"time":"2024-10-10T13:55:36Z","level":"INFO","msg":"server shut down"}`,
+			Record{}, "not a request",
+		},
+		{"no request", `203.0.113.9 - - [10/Oct/2024:13:55:36 +0000] 200 1 0.1`, Record{}, "no quoted request"},
+		{"no request time", `- - - [x] "GET / HTTP/1.1" 200 1`, Record{}, "want status, bytes and request time after the request"},
+		{"bad status", `- - - [x] "GET / HTTP/1.1" OK 1 0.1`, Record{}, `bad status "OK"`},
+		{"bad request time", `- - - [x] "GET / HTTP/1.1" 200 1 fast`, Record{}, `bad request time "fast"`},
+		{"blank", ``, Record{}, "no quoted request"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseLine([]byte(tc.line))
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Errorf("ParseLine = %+v, %v; want error %q", got, err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tc.want {
+				t.Errorf("ParseLine = %+v, %v; want %+v", got, err, tc.want)
+			}
+		})
+	}
+}
+
+// sampleLog is a log small enough to check the report of by hand
+const sampleLog = `1.1.1.1 - - [10/Oct/2024:13:00:00 +0000] "GET /books HTTP/1.1" 200 10 0.010
+1.1.1.1 - - [10/Oct/2024:13:00:01 +0000] "GET /books HTTP/1.1" 200 10 0.020
+1.1.1.1 - - [10/Oct/2024:13:00:02 +0000] "GET /books?page=2 HTTP/1.1" 304 0 0.001
+1.1.1.1 - - [10/Oct/2024:13:00:03 +0000] "GET /books HTTP/1.1" 500 10 1.5
+
+not a log line
+{"msg":"request","path":"/books/1","status":404,"duration":2000000}
+{"msg":"request","path":"/books/1","status":200,"duration":4000000}
+`
+
+func TestReport(t *testing.T) {
+	stats, err := AnalyzeSequential(strings.NewReader(sampleLog))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rep := stats.Report()
+
+	var js bytes.Buffer
+	if err := WriteJSON(&js, rep); err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "lines": 8,
+  "skipped": 2,
+  "requests": 6,
+  "paths": [
+    {
+      "path": "/books",
+      "requests": 4,
+      "statuses": {
+        "200": 2,
+        "304": 1,
+        "500": 1
+      },
+      "p50_ms": 10,
+      "p90_ms": 1500,
+      "p99_ms": 1500,
+      "max_ms": 1500
+    },
+    {
+      "path": "/books/1",
+      "requests": 2,
+      "statuses": {
+        "200": 1,
+        "404": 1
+      },
+      "p50_ms": 2,
+      "p90_ms": 4,
+      "p99_ms": 4,
+      "max_ms": 4
+    }
+  ]
+}
+`
+	if js.String() != want {
+		t.Errorf("JSON report\n got %s\nwant %s", js.String(), want)
+	}
+
+	var csv bytes.Buffer
+	if err := WriteCSV(&csv, rep); err != nil {
+		t.Fatal(err)
+	}
+	want = `path,requests,2xx,3xx,4xx,5xx,p50_ms,p90_ms,p99_ms,max_ms
+/books,4,2,1,0,1,10,1500,1500,1500
+/books/1,2,1,0,1,0,2,4,4,4
+`
+	if csv.String() != want {
+		t.Errorf("CSV report\n got %s\nwant %s", csv.String(), want)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	var sorted []time.Duration
+	for i := 1; i <= 100; i++ {
+		sorted = append(sorted, time.Duration(i))
+	}
+	for _, tc := range []struct {
+		p    float64
+		want time.Duration
+	}{{0, 1}, {1, 1}, {50, 50}, {99, 99}, {99.5, 100}, {100, 100}} {
+		if got := percentile(sorted, tc.p); got != tc.want {
+			t.Errorf("percentile(1..100, %v) = %d; want %d", tc.p, got, tc.want)
+		}
+	}
+	if got := percentile([]time.Duration{7}, 50); got != 7 {
+		t.Errorf("percentile of one value = %d; want 7", got)
+	}
+}
+
+// The parallel analyzer agrees with the sequential one, however many
+// workers it has and however the lines fall into batches
+func TestAnalyze_MatchesSequential(t *testing.T) {
+	var log bytes.Buffer
+	Generate(&log, 10*batchSize+7, rand.New(rand.NewPCG(1, 2)))
+	seq, err := AnalyzeSequential(bytes.NewReader(log.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := seq.Report()
+	if want.Skipped == 0 || want.Requests == 0 {
+		t.Fatalf("generated log has %d requests and %d skipped lines; want both", want.Requests, want.Skipped)
+	}
+
+	for _, workers := range []int{1, 2, 8} {
+		t.Run(fmt.Sprint(workers, " workers"), func(t *testing.T) {
+			par, err := Analyze(context.Background(), bytes.NewReader(log.Bytes()), workers)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := par.Report(); !reflect.DeepEqual(got, want) {
+				t.Errorf("report = %+v\nwant %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestAnalyze_Canceled(t *testing.T) {
+	var log bytes.Buffer
+	Generate(&log, 100*batchSize, rand.New(rand.NewPCG(1, 2)))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	stats, err := Analyze(ctx, &log, 1)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Analyze = %v; want context.Canceled", err)
+	}
+	if stats.Lines >= 100*batchSize {
+		t.Errorf("read all %d lines after cancellation", stats.Lines)
+	}
+}
+
+func BenchmarkAnalyze(b *testing.B) {
+	var log bytes.Buffer
+	Generate(&log, 200_000, rand.New(rand.NewPCG(1, 2)))
+	b.Run("sequential", func(b *testing.B) {
+		b.SetBytes(int64(log.Len()))
+		for range b.N {
+			if _, err := AnalyzeSequential(bytes.NewReader(log.Bytes())); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	for _, workers := range []int{2, 4, 8} {
+		b.Run(fmt.Sprint("parallel-", workers), func(b *testing.B) {
+			b.SetBytes(int64(log.Len()))
+			for range b.N {
+				if _, err := Analyze(context.Background(), bytes.NewReader(log.Bytes()), workers); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"os/signal"
+	"runtime"
+	"time"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("loganalyzer", flag.ContinueOnError)
+	workers := fs.Int("workers", runtime.NumCPU(), "goroutines parsing lines; 1 reads and parses on one goroutine")
+	format := fs.String("format", "json", "report format: json or csv")
+	generate := fs.Int("generate", 0, "instead of analyzing, write this many lines of a made-up access log")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: loganalyzer [flags] [file...]   (stdin if no files)")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *format != "json" && *format != "csv" {
+		return fmt.Errorf("-format must be json or csv, got %q", *format)
+	}
+	if *generate > 0 {
+		w := bufio.NewWriter(stdout)
+		Generate(w, *generate, rand.New(rand.NewPCG(1, 2)))
+		return w.Flush()
+	}
+
+	// Ctrl-C stops reading and reports what was read so far
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	analyze := func(r io.Reader) (*Stats, error) {
+		if *workers == 1 {
+			return AnalyzeSequential(r)
+		}
+		return Analyze(ctx, r, *workers)
+	}
+	start := time.Now()
+	total := &Stats{}
+	if fs.NArg() == 0 {
+		s, err := analyze(stdin)
+		total.Merge(s)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			return err
+		}
+	}
+	for _, name := range fs.Args() {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		s, err := analyze(f)
+		f.Close()
+		total.Merge(s)
+		if errors.Is(err, context.Canceled) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "%d lines in %v with %d workers\n", total.Lines, time.Since(start).Round(time.Millisecond), *workers)
+
+	if *format == "csv" {
+		return WriteCSV(stdout, total.Report())
+	}
+	return WriteJSON(stdout, total.Report())
+}
+
+// generatedPaths are the paths Generate picks from, most often the first
+var generatedPaths = []string{"/books", "/books/1", "/books/2", "/books/3", "/auth/login", "/books/events", "/metrics"}
+
+// Generate writes n lines of a made-up access log in the common log format
+// with request times, some of them in the JSON request log format instead
+// and a few malformed, for trying the analyzer and for benchmarks
+func Generate(w io.Writer, n int, rng *rand.Rand) {
+	ts := time.Date(2024, 10, 10, 13, 0, 0, 0, time.UTC)
+	for i := range n {
+		path := generatedPaths[min(rng.IntN(len(generatedPaths)*2), rng.IntN(len(generatedPaths)))]
+		status := 200
+		switch r := rng.IntN(100); {
+		case r < 2:
+			status = 500
+		case r < 10:
+			status = 404
+		case r < 12:
+			status = 304
+		}
+		// Mostly fast, with a long tail
+		latency := time.Duration(rng.ExpFloat64() * float64(8*time.Millisecond))
+		ts = ts.Add(time.Duration(rng.IntN(50)) * time.Millisecond)
+
+		switch {
+		case i%997 == 0:
+			fmt.Fprintf(w, "garbled line %d\n", i)
+		case i%10 == 0:
+			fmt.Fprintf(w, `{"time":%q,"level":"INFO","msg":"request","method":"GET","path":%q,"status":%d,"bytes":%d,"duration":%d}`+"\n",
+				ts.Format(time.RFC3339Nano), path, status, rng.IntN(5000), latency)
+		default:
+			fmt.Fprintf(w, "203.0.113.%d - - [%s] \"GET %s?page=%d HTTP/1.1\" %d %d %.3f\n",
+				rng.IntN(255), ts.Format("02/Jan/2006:15:04:05 -0700"), path, rng.IntN(5), status, rng.IntN(5000), latency.Seconds())
+		}
+	}
+}
+
+/*
+This project demonstrates:
+
+1. A worker pool over channels
+   - One goroutine reading lines with bufio.Scanner, workers parsing them
+   - Lines sent in batches, so channel operations do not dominate
+   - Each worker aggregating into its own Stats with no locking, merged
+     once at the end (the map-reduce shape)
+   - Cancellation with a context: Ctrl-C reports what was read so far
+
+2. Parsing
+   - The common log format with request times, scanned by hand rather than
+     with a regexp, and slog's JSON request log in the same stream
+   - Malformed lines counted and skipped instead of failing the run
+
+3. Aggregation and reports
+   - Requests per path and status, and nearest-rank latency percentiles
+   - JSON with encoding/json and CSV rows mapped from a tagged struct by
+     pkg/csvutil
+
+4. Benchmarks
+   - BenchmarkAnalyze compares the sequential and parallel analyzers on the
+     same generated log: go test -bench Analyze ./mini-projects/loganalyzer
+
+To try it:
+
+go run ./mini-projects/loganalyzer -generate 1000000 > /tmp/access.log
+go run ./mini-projects/loganalyzer /tmp/access.log
+go run ./mini-projects/loganalyzer -format csv -workers 1 /tmp/access.log
+
+# The REST API's own request log
+go run ./cmd/runner serve rest-api 2> /tmp/api.log
+go run ./mini-projects/loganalyzer /tmp/api.log
+*/
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Record is what the analyzer keeps of one request in an access log
+type Record struct {
+	Path    string
+	Status  int
+	Latency time.Duration
+}
+
+// errNotRequest is returned for a JSON log line that is some other event
+// than a request
+var errNotRequest = errors.New("not a request")
+
+// ParseLine reads one access log line in either of two formats. The
+// first is the common log format with the request time in seconds as the
+// last field, as nginx writes with $request_time at the end of its
+// log_format; the referer and user agent of the combined format may come
+// in between:
+//
+//	203.0.113.9 - - [10/Oct/2024:13:55:36 +0000] "GET /books?page=2 HTTP/1.1" 200 2326 0.012
+//
+// The second is the request log of mini-projects/rest_api in its default
+// -log-format json, whose duration slog writes in nanoseconds:
+//
+//	{"time":"...","level":"INFO","msg":"request","method":"GET","path":"/books","status":200,"duration":1200000}
+//
+// The query string is not part of Path, so /books?page=2 counts as /books.
+func ParseLine(line []byte) (Record, error) {
+	line = bytes.TrimSpace(line)
+	if len(line) > 0 && line[0] == '{' {
+		return parseJSON(line)
+	}
+	return parseCommon(line)
+}
+
+func parseCommon(line []byte) (Record, error) {
+	// The request is the first quoted field
+	start := bytes.IndexByte(line, '"')
+	if start < 0 {
+		return Record{}, errors.New("no quoted request")
+	}
+	n := bytes.IndexByte(line[start+1:], '"')
+	if n < 0 {
+		return Record{}, errors.New("unterminated request")
+	}
+	request := strings.Fields(string(line[start+1 : start+1+n]))
+	if len(request) < 2 {
+		return Record{}, fmt.Errorf("request %q is not METHOD PATH", line[start+1:start+1+n])
+	}
+	rest := line[start+n+2:]
+
+	fields := bytes.Fields(rest)
+	if len(fields) < 3 {
+		return Record{}, errors.New("want status, bytes and request time after the request")
+	}
+	status, err := strconv.Atoi(string(fields[0]))
+	if err != nil || status < 100 || status > 599 {
+		return Record{}, fmt.Errorf("bad status %q", fields[0])
+	}
+	secs, err := strconv.ParseFloat(string(fields[len(fields)-1]), 64)
+	if err != nil || secs < 0 {
+		return Record{}, fmt.Errorf("bad request time %q", fields[len(fields)-1])
+	}
+	return Record{
+		Path:    stripQuery(request[1]),
+		Status:  status,
+		Latency: time.Duration(secs * float64(time.Second)),
+	}, nil
+}
+
+func parseJSON(line []byte) (Record, error) {
+	var v struct {
+		Msg      string `json:"msg"`
+		Path     string `json:"path"`
+		Status   int    `json:"status"`
+		Duration int64  `json:"duration"`
+	}
+	if err := json.Unmarshal(line, &v); err != nil {
+		return Record{}, err
+	}
+	if v.Msg != "request" || v.Path == "" {
+		return Record{}, errNotRequest
+	}
+	return Record{Path: stripQuery(v.Path), Status: v.Status, Latency: time.Duration(v.Duration)}, nil
+}
+
+func stripQuery(path string) string {
+	path, _, _ = strings.Cut(path, "?")
+	return path
+}
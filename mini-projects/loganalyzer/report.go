@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"github.com/rehan/go-interview-prep/pkg/csvutil"
+)
+
+// WriteJSON writes rep as indented JSON
+func WriteJSON(w io.Writer, rep Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(rep)
+}
+
+// csvRow is a line of WriteCSV's output. Statuses are counted by class,
+// as the set of exact codes differs from path to path.
+type csvRow struct {
+	Path     string  `csv:"path"`
+	Requests int     `csv:"requests"`
+	Class2xx int     `csv:"2xx"`
+	Class3xx int     `csv:"3xx"`
+	Class4xx int     `csv:"4xx"`
+	Class5xx int     `csv:"5xx"`
+	P50      float64 `csv:"p50_ms"`
+	P90      float64 `csv:"p90_ms"`
+	P99      float64 `csv:"p99_ms"`
+	Max      float64 `csv:"max_ms"`
+}
+
+// WriteCSV writes one row per path of rep, busiest first
+func WriteCSV(w io.Writer, rep Report) error {
+	cw := csvutil.NewWriter[csvRow](w)
+	for _, p := range rep.Paths {
+		var classes [4]int // 2xx to 5xx; 1xx responses are not logged as such
+		for status, n := range p.Statuses {
+			code, _ := strconv.Atoi(status)
+			if c := code/100 - 2; c >= 0 && c < len(classes) {
+				classes[c] += n
+			}
+		}
+		err := cw.Write(csvRow{
+			Path:     p.Path,
+			Requests: p.Requests,
+			Class2xx: classes[0],
+			Class3xx: classes[1],
+			Class4xx: classes[2],
+			Class5xx: classes[3],
+			P50:      p.P50,
+			P90:      p.P90,
+			P99:      p.P99,
+			Max:      p.Max,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return cw.Flush()
+}
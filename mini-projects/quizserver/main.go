@@ -0,0 +1,104 @@
+package quizserver
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/quiz"
+)
+
+// Run serves the quizzes over HTTP, configured by the flags in args, until
+// SIGINT or SIGTERM
+func Run(args []string) error {
+	fs := flag.NewFlagSet("quizserver", flag.ContinueOnError)
+	addr := fs.String("addr", ":8081", "HTTP address to listen on")
+	perQuestion := fs.Duration("time-per-question", 2*time.Minute, "time allowed for each question of a quiz")
+	capacity := fs.Int("max-quizzes", 1000, "most quizzes kept in memory at once")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *perQuestion <= 0 || *capacity <= 0 {
+		return errors.New("-time-per-question and -max-quizzes must be positive")
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+	topics, err := quiz.Topics()
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{
+		Handler:           NewServer(topics, NewStore(*capacity, time.Now), *perQuestion, logger).Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       10 * time.Second,
+		WriteTimeout:      10 * time.Second,
+		ErrorLog:          slog.NewLogLogger(logger.Handler(), slog.LevelError),
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ln, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Quiz server listening on http://%s/\n", ln.Addr())
+
+	errc := make(chan error, 1)
+	go func() { errc <- srv.Serve(ln) }()
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("shutdown: %w", err)
+	}
+	logger.Info("server shut down")
+	return nil
+}
+
+/*
+This project demonstrates:
+
+1. A JSON API over a question bank
+   - Go 1.22 ServeMux patterns with methods and {id} wildcards
+   - Errors with codes from pkg/errorsx, sent as RFC 7807 problems the
+     way the REST API sends them
+   - Request bodies bounded in size and decoded strictly: unknown fields
+     and trailing data are rejected
+
+2. Timed quizzes
+   - In-memory sessions behind one mutex, each with a deadline from the
+     number of questions; answers after it are refused
+   - Old quizzes dropped lazily, and a cap on how many are kept, so the
+     server's memory stays bounded
+   - An injected clock, so tests move time instead of sleeping
+   - Handlers returning copies of the session, never the shared state
+
+3. A web page embedded with go:embed
+   - One HTML file with a little JavaScript, served by the binary itself,
+     driving the same API a script would
+
+To try it:
+
+go run ./cmd/runner serve quizserver -time-per-question 1m
+
+Open http://localhost:8081/ to take a quiz in a browser, or use curl:
+
+curl localhost:8081/topics
+curl 'localhost:8081/topics/maps?difficulty=hard'
+curl -i -X POST localhost:8081/quizzes -d '{"topics": ["maps", "closures"], "count": 3, "seed": 1}'
+curl -X POST localhost:8081/quizzes/<id>/answers -d '{"question": 1, "answer": "a hash table"}'
+curl -X POST localhost:8081/quizzes/<id>/grades -d '{"question": 1, "correct": true}'
+curl localhost:8081/quizzes/<id>
+*/
@@ -0,0 +1,273 @@
+package quizserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/quiz"
+)
+
+// fakeClock is a clock the test moves with Advance
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// testTopics has three questions on two topics
+var testTopics = []quiz.Topic{
+	{ID: "maps", Title: "Maps", Questions: []quiz.Question{
+		{Topic: "maps", Question: "Are maps ordered?", Answer: []string{"No"}, Difficulty: quiz.Easy},
+		{Topic: "maps", Question: "Are maps safe for concurrent use?", Answer: []string{"No", "Use a mutex"}, Difficulty: quiz.Hard},
+	}},
+	{ID: "slices", Title: "Slices", Questions: []quiz.Question{
+		{Topic: "slices", Question: "What does append return?", Answer: []string{"The new slice"}, Difficulty: quiz.Easy},
+	}},
+}
+
+// newTestServer serves testTopics, allowing a minute a question, and keeps
+// at most capacity quizzes
+func newTestServer(t *testing.T, capacity int) (*httptest.Server, *fakeClock) {
+	t.Helper()
+	clock := newFakeClock()
+	srv := NewServer(testTopics, NewStore(capacity, clock.Now), time.Minute, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+	return ts, clock
+}
+
+// call sends a request with body, if it is not empty, and decodes the JSON
+// reply into out, if it is not nil. It returns the status and, for error
+// replies, the problem's detail.
+func call(t *testing.T, method, url, body string, out any) (int, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode >= 400 {
+		var p Problem
+		if err := json.Unmarshal(data, &p); err != nil {
+			t.Fatalf("%s %s: %d with body %q", method, url, res.StatusCode, data)
+		}
+		return res.StatusCode, p.Detail
+	}
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			t.Fatalf("%s %s: decoding %q: %v", method, url, data, err)
+		}
+	}
+	return res.StatusCode, ""
+}
+
+func TestTopics(t *testing.T) {
+	ts, _ := newTestServer(t, 10)
+
+	var list []TopicSummary
+	call(t, "GET", ts.URL+"/topics", "", &list)
+	if len(list) != 2 || list[0].ID != "maps" || list[0].Questions != 2 || list[0].ByDifficulty[quiz.Hard] != 1 {
+		t.Errorf("GET /topics = %+v", list)
+	}
+
+	var topic quiz.Topic
+	call(t, "GET", ts.URL+"/topics/maps?difficulty=hard", "", &topic)
+	if topic.Title != "Maps" || len(topic.Questions) != 1 || topic.Questions[0].Answer[1] != "Use a mutex" {
+		t.Errorf("GET /topics/maps?difficulty=hard = %+v", topic)
+	}
+	if len(testTopics[0].Questions) != 2 {
+		t.Error("filtering by difficulty changed the question bank")
+	}
+
+	if status, _ := call(t, "GET", ts.URL+"/topics/channels", "", nil); status != http.StatusNotFound {
+		t.Errorf("unknown topic: status %d", status)
+	}
+	if status, detail := call(t, "GET", ts.URL+"/topics/maps?difficulty=tricky", "", nil); status != http.StatusBadRequest || !strings.Contains(detail, "tricky") {
+		t.Errorf("bad difficulty: status %d, detail %q", status, detail)
+	}
+}
+
+func TestIndex(t *testing.T) {
+	ts, _ := newTestServer(t, 10)
+	res, err := http.Get(ts.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	body, _ := io.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK || !strings.HasPrefix(res.Header.Get("Content-Type"), "text/html") || !bytes.Contains(body, []byte("/quizzes")) {
+		t.Errorf("GET / = %d %s", res.StatusCode, res.Header.Get("Content-Type"))
+	}
+}
+
+func TestQuiz(t *testing.T) {
+	ts, _ := newTestServer(t, 10)
+
+	var q QuizView
+	if status, detail := call(t, "POST", ts.URL+"/quizzes", `{"topics": ["maps"]}`, &q); status != http.StatusCreated {
+		t.Fatalf("create: status %d, %s", status, detail)
+	}
+	if len(q.Questions) != 2 || q.SecondsLeft != 120 || q.Finished {
+		t.Errorf("new quiz = %+v; want 2 questions and 2 minutes", q)
+	}
+	for _, question := range q.Questions {
+		if question.Expected != nil {
+			t.Errorf("question %d shows its answer before it is answered", question.Number)
+		}
+	}
+	quizURL := ts.URL + "/quizzes/" + q.ID
+
+	var answered QuestionView
+	call(t, "POST", quizURL+"/answers", `{"question": 1, "answer": "my answer"}`, &answered)
+	if *answered.Answer != "my answer" || len(answered.Expected) == 0 {
+		t.Errorf("answered question = %+v; want the answer and what a good one says", answered)
+	}
+
+	steps := []struct {
+		path, body string
+		wantStatus int
+		wantDetail string
+	}{
+		{"/answers", `{"question": 1, "answer": "again"}`, http.StatusConflict, "question 1 is already answered"},
+		{"/grades", `{"question": 2, "correct": true}`, http.StatusConflict, "question 2 has not been answered"},
+		{"/grades", `{"question": 1}`, http.StatusBadRequest, "correct must be true or false"},
+		{"/grades", `{"question": 3, "correct": true}`, http.StatusBadRequest, "question must be between 1 and 2"},
+		{"/grades", `{"question": 1, "correct": true}`, http.StatusOK, ""},
+		{"/grades", `{"question": 1, "correct": false}`, http.StatusConflict, "question 1 is already graded"},
+		{"/answers", `{"question": 2, "answer": ""}`, http.StatusOK, ""},
+		{"/grades", `{"question": 2, "correct": false}`, http.StatusOK, ""},
+	}
+	for _, step := range steps {
+		status, detail := call(t, "POST", quizURL+step.path, step.body, nil)
+		if status != step.wantStatus || detail != step.wantDetail {
+			t.Errorf("POST %s %s = %d %q; want %d %q", step.path, step.body, status, detail, step.wantStatus, step.wantDetail)
+		}
+	}
+
+	call(t, "GET", quizURL, "", &q)
+	want := quiz.Score{Asked: 2, Correct: 1}
+	if !q.Finished || q.Result.Score != want || q.Result.ByTopic["maps"] != want {
+		t.Errorf("graded quiz: finished %v, result %+v; want finished with %+v", q.Finished, q.Result, want)
+	}
+
+	if status, _ := call(t, "GET", ts.URL+"/quizzes/nope", "", nil); status != http.StatusNotFound {
+		t.Errorf("unknown quiz: status %d", status)
+	}
+}
+
+func TestCreateQuiz_Invalid(t *testing.T) {
+	ts, _ := newTestServer(t, 10)
+	tests := []struct {
+		body, wantDetail string
+		wantStatus       int
+	}{
+		{`{"count": 51}`, "count must be between 1 and 50", http.StatusBadRequest},
+		{`{"topics": ["channels"]}`, `unknown topic "channels"`, http.StatusBadRequest},
+		{`{"topics": ["slices"], "difficulty": "hard"}`, "No questions match", http.StatusBadRequest},
+		{`{"difficulty": "tricky"}`, `unknown difficulty "tricky"`, http.StatusBadRequest},
+		{`{"level": 1}`, `unknown field "level"`, http.StatusBadRequest},
+		{`{} {}`, "more than one JSON value", http.StatusBadRequest},
+		{``, "Invalid request body", http.StatusBadRequest},
+		{`{"topics": ["` + strings.Repeat("x", maxBodyBytes) + `"]}`, "at most", http.StatusRequestEntityTooLarge},
+	}
+	for _, tc := range tests {
+		status, detail := call(t, "POST", ts.URL+"/quizzes", tc.body, nil)
+		if status != tc.wantStatus || !strings.Contains(detail, tc.wantDetail) {
+			t.Errorf("POST /quizzes %.40s = %d %q; want %d containing %q", tc.body, status, detail, tc.wantStatus, tc.wantDetail)
+		}
+	}
+}
+
+func TestCreateQuiz_Seed(t *testing.T) {
+	ts, _ := newTestServer(t, 10)
+	questions := func() string {
+		var q QuizView
+		call(t, "POST", ts.URL+"/quizzes", `{"count": 2, "seed": 3}`, &q)
+		var asked []string
+		for _, question := range q.Questions {
+			asked = append(asked, question.Question)
+		}
+		return strings.Join(asked, " | ")
+	}
+	first := questions()
+	if strings.Count(first, " | ") != 1 {
+		t.Errorf("count 2 asked %q", first)
+	}
+	if second := questions(); second != first {
+		t.Errorf("seed 3 asked %q, then %q", first, second)
+	}
+}
+
+func TestQuiz_TimeIsUp(t *testing.T) {
+	ts, clock := newTestServer(t, 10)
+	var q QuizView
+	call(t, "POST", ts.URL+"/quizzes", `{"topics": ["slices"]}`, &q)
+	quizURL := ts.URL + "/quizzes/" + q.ID
+
+	clock.Advance(59 * time.Second)
+	call(t, "POST", quizURL+"/answers", `{"question": 1, "answer": "a slice"}`, nil)
+	clock.Advance(time.Second)
+	status, detail := call(t, "POST", quizURL+"/grades", `{"question": 1, "correct": true}`, nil)
+	if status != http.StatusConflict || detail != "Time is up for this quiz" {
+		t.Errorf("grading late = %d %q", status, detail)
+	}
+
+	call(t, "GET", quizURL, "", &q)
+	if !q.Finished || q.SecondsLeft != 0 || q.Result.Asked != 0 {
+		t.Errorf("quiz after its time = %+v; want finished with nothing graded", q)
+	}
+}
+
+func TestStore_Capacity(t *testing.T) {
+	ts, clock := newTestServer(t, 1)
+	var first QuizView
+	call(t, "POST", ts.URL+"/quizzes", `{"topics": ["slices"]}`, &first)
+	if status, _ := call(t, "POST", ts.URL+"/quizzes", `{}`, nil); status != http.StatusTooManyRequests {
+		t.Errorf("quiz over capacity: status %d; want 429", status)
+	}
+
+	// The first quiz is readable for an hour after its minute is up, then
+	// it is dropped to make room
+	clock.Advance(time.Minute + keepFinished)
+	if status, _ := call(t, "GET", ts.URL+"/quizzes/"+first.ID, "", nil); status != http.StatusOK {
+		t.Errorf("finished quiz: status %d; want 200", status)
+	}
+	clock.Advance(time.Second)
+	if status, detail := call(t, "POST", ts.URL+"/quizzes", `{}`, nil); status != http.StatusCreated {
+		t.Errorf("quiz after the old one expired: %d %q", status, detail)
+	}
+	if status, _ := call(t, "GET", ts.URL+"/quizzes/"+first.ID, "", nil); status != http.StatusNotFound {
+		t.Errorf("expired quiz: status %d; want 404", status)
+	}
+}
@@ -0,0 +1,276 @@
+package quizserver
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/errorsx"
+	"github.com/rehan/go-interview-prep/pkg/quiz"
+)
+
+const (
+	// maxBodyBytes bounds a request body
+	maxBodyBytes = 64 << 10
+
+	// defaultQuestions is how many questions a quiz asks if the request
+	// does not say; maxQuestions is the most it may ask for
+	defaultQuestions = 10
+	maxQuestions     = 50
+)
+
+//go:embed index.html
+var indexHTML []byte
+
+// Server serves a question bank: topics to browse, and timed quizzes to
+// take. Answers are free text, so as in "runner quiz" the player grades
+// their own after seeing what a good answer says.
+type Server struct {
+	topics          []quiz.Topic
+	quizzes         *Store
+	timePerQuestion time.Duration
+	logger          *slog.Logger
+}
+
+// NewServer returns a Server asking questions from topics, keeping quizzes
+// in quizzes and allowing timePerQuestion for each question of a quiz
+func NewServer(topics []quiz.Topic, quizzes *Store, timePerQuestion time.Duration, logger *slog.Logger) *Server {
+	return &Server{topics: topics, quizzes: quizzes, timePerQuestion: timePerQuestion, logger: logger}
+}
+
+// Handler returns the routes:
+//
+//	GET  /                       the web page, which uses the API below
+//	GET  /topics                 the topics, with question counts
+//	GET  /topics/{id}            a topic's questions and answers; ?difficulty=hard
+//	POST /quizzes                start a quiz: {"topics", "difficulty", "count", "seed"}
+//	GET  /quizzes/{id}           a quiz, with its score so far
+//	POST /quizzes/{id}/answers   answer a question: {"question": 1, "answer": "..."}
+//	POST /quizzes/{id}/grades    grade the answer: {"question": 1, "correct": true}
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.handleIndex)
+	mux.HandleFunc("GET /topics", s.handleListTopics)
+	mux.HandleFunc("GET /topics/{id}", s.handleGetTopic)
+	mux.HandleFunc("POST /quizzes", s.handleCreateQuiz)
+	mux.HandleFunc("GET /quizzes/{id}", s.handleGetQuiz)
+	mux.HandleFunc("POST /quizzes/{id}/answers", s.handleAnswer)
+	mux.HandleFunc("POST /quizzes/{id}/grades", s.handleGrade)
+	return mux
+}
+
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(indexHTML)
+}
+
+// TopicSummary describes a topic in the list of topics
+type TopicSummary struct {
+	ID           string                  `json:"id"`
+	Title        string                  `json:"title"`
+	Questions    int                     `json:"questions"`
+	ByDifficulty map[quiz.Difficulty]int `json:"by_difficulty"`
+}
+
+func (s *Server) handleListTopics(w http.ResponseWriter, r *http.Request) {
+	list := make([]TopicSummary, 0, len(s.topics))
+	for _, t := range s.topics {
+		sum := TopicSummary{ID: t.ID, Title: t.Title, Questions: len(t.Questions), ByDifficulty: make(map[quiz.Difficulty]int)}
+		for _, q := range t.Questions {
+			sum.ByDifficulty[q.Difficulty]++
+		}
+		list = append(list, sum)
+	}
+	respondWithJSON(w, http.StatusOK, list)
+}
+
+func (s *Server) handleGetTopic(w http.ResponseWriter, r *http.Request) {
+	i := slices.IndexFunc(s.topics, func(t quiz.Topic) bool { return t.ID == r.PathValue("id") })
+	if i < 0 {
+		respondWithError(w, s.logger, errorsx.New(errorsx.CodeNotFound, "Topic not found"))
+		return
+	}
+	topic := s.topics[i]
+	if level := r.URL.Query().Get("difficulty"); level != "" {
+		d, err := quiz.ParseDifficulty(level)
+		if err != nil {
+			respondWithError(w, s.logger, errorsx.Wrap(err, errorsx.CodeInvalidArgument, ""))
+			return
+		}
+		topic.Questions = slices.DeleteFunc(slices.Clone(topic.Questions), func(q quiz.Question) bool { return q.Difficulty != d })
+	}
+	respondWithJSON(w, http.StatusOK, topic)
+}
+
+// CreateQuizRequest chooses a quiz's questions. Topics empty means every
+// topic, Difficulty empty any difficulty, and Count 0 ten questions. The
+// same Seed picks the same questions again; without one they are random.
+type CreateQuizRequest struct {
+	Topics     []string `json:"topics"`
+	Difficulty string   `json:"difficulty"`
+	Count      int      `json:"count"`
+	Seed       *uint64  `json:"seed"`
+}
+
+func (s *Server) handleCreateQuiz(w http.ResponseWriter, r *http.Request) {
+	var req CreateQuizRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		respondWithError(w, s.logger, err)
+		return
+	}
+	if req.Count < 0 || req.Count > maxQuestions {
+		respondWithError(w, s.logger, errorsx.Errorf(errorsx.CodeInvalidArgument, "count must be between 1 and %d", maxQuestions))
+		return
+	}
+	if req.Count == 0 {
+		req.Count = defaultQuestions
+	}
+	filter := quiz.Filter{Topics: req.Topics}
+	if req.Difficulty != "" {
+		var err error
+		if filter.Difficulty, err = quiz.ParseDifficulty(req.Difficulty); err != nil {
+			respondWithError(w, s.logger, errorsx.Wrap(err, errorsx.CodeInvalidArgument, ""))
+			return
+		}
+	}
+	questions, err := quiz.Select(s.topics, filter)
+	if err != nil {
+		respondWithError(w, s.logger, errorsx.Wrap(err, errorsx.CodeInvalidArgument, ""))
+		return
+	}
+	if len(questions) == 0 {
+		respondWithError(w, s.logger, errorsx.New(errorsx.CodeInvalidArgument, "No questions match"))
+		return
+	}
+
+	seed := rand.Uint64()
+	if req.Seed != nil {
+		seed = *req.Seed
+	}
+	questions = quiz.Pick(questions, quiz.Options{Count: req.Count, Rand: rand.New(rand.NewPCG(seed, 0))})
+	view, err := s.quizzes.Create(questions, s.timePerQuestion*time.Duration(len(questions)))
+	if err != nil {
+		respondWithError(w, s.logger, err)
+		return
+	}
+	w.Header().Set("Location", "/quizzes/"+view.ID)
+	respondWithJSON(w, http.StatusCreated, view)
+}
+
+func (s *Server) handleGetQuiz(w http.ResponseWriter, r *http.Request) {
+	view, err := s.quizzes.Get(r.PathValue("id"))
+	if err != nil {
+		respondWithError(w, s.logger, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, view)
+}
+
+// AnswerRequest answers question number Question of a quiz, counting from 1
+type AnswerRequest struct {
+	Question int    `json:"question"`
+	Answer   string `json:"answer"`
+}
+
+// handleAnswer records an answer and replies with the question, now
+// showing what a good answer says
+func (s *Server) handleAnswer(w http.ResponseWriter, r *http.Request) {
+	var req AnswerRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		respondWithError(w, s.logger, err)
+		return
+	}
+	question, err := s.quizzes.Answer(r.PathValue("id"), req.Question, req.Answer)
+	if err != nil {
+		respondWithError(w, s.logger, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, question)
+}
+
+// GradeRequest says whether the answer to question number Question was
+// right. Correct is a pointer so that leaving it out is an error rather
+// than a wrong answer.
+type GradeRequest struct {
+	Question int   `json:"question"`
+	Correct  *bool `json:"correct"`
+}
+
+// handleGrade records a grade and replies with the quiz and its score
+func (s *Server) handleGrade(w http.ResponseWriter, r *http.Request) {
+	var req GradeRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		respondWithError(w, s.logger, err)
+		return
+	}
+	if req.Correct == nil {
+		respondWithError(w, s.logger, errorsx.New(errorsx.CodeInvalidArgument, "correct must be true or false"))
+		return
+	}
+	view, err := s.quizzes.Grade(r.PathValue("id"), req.Question, *req.Correct)
+	if err != nil {
+		respondWithError(w, s.logger, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, view)
+}
+
+// decodeJSON decodes the request body, a single JSON object with no
+// unknown fields, into v
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return errorsx.Errorf(errorsx.CodeTooLarge, "request body must be at most %d bytes", maxBodyBytes)
+		}
+		return errorsx.Wrap(err, errorsx.CodeInvalidArgument, "Invalid request body")
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return errorsx.New(errorsx.CodeInvalidArgument, "Invalid request body: more than one JSON value")
+	}
+	return nil
+}
+
+func respondWithJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+// Problem is the body of every error response, an RFC 7807 problem
+// details object, as the REST API sends them
+type Problem struct {
+	Type   string       `json:"type"`
+	Title  string       `json:"title"`
+	Status int          `json:"status"`
+	Detail string       `json:"detail,omitempty"`
+	Code   errorsx.Code `json:"code"`
+}
+
+// respondWithError replies with err as a problem, its status from its
+// code. Internal errors are logged and their details withheld.
+func respondWithError(w http.ResponseWriter, logger *slog.Logger, err error) {
+	code := errorsx.CodeOf(err)
+	if code == errorsx.CodeInternal {
+		logger.Error("internal error", "error", fmt.Sprintf("%+v", err))
+	}
+	status := code.HTTPStatus()
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: errorsx.PublicMessage(err),
+		Code:   code,
+	})
+}
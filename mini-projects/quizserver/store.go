@@ -0,0 +1,211 @@
+package quizserver
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/errorsx"
+	"github.com/rehan/go-interview-prep/pkg/quiz"
+)
+
+// keepFinished is how long a quiz can still be read after its time is up
+const keepFinished = time.Hour
+
+// session is one quiz being taken. Each question is answered, which
+// reveals the points a good answer makes, and then graded by the player.
+type session struct {
+	id        string
+	questions []quiz.Question
+	answers   []string
+	answered  []bool
+	correct   []*bool // nil until graded
+	started   time.Time
+	deadline  time.Time
+}
+
+// QuizView is a quiz as the API shows it
+type QuizView struct {
+	ID          string         `json:"id"`
+	StartedAt   time.Time      `json:"started_at"`
+	Deadline    time.Time      `json:"deadline"`
+	SecondsLeft int            `json:"seconds_left"`
+	Finished    bool           `json:"finished"` // time is up, or every question is graded
+	Questions   []QuestionView `json:"questions"`
+	Result      quiz.Result    `json:"result"` // of the graded questions
+}
+
+// QuestionView is one question of a quiz. Expected, the points a good
+// answer makes, is only shown once the question has been answered.
+type QuestionView struct {
+	Number     int             `json:"number"`
+	Topic      string          `json:"topic"`
+	Question   string          `json:"question"`
+	Difficulty quiz.Difficulty `json:"difficulty"`
+	Answer     *string         `json:"answer,omitempty"`
+	Expected   []string        `json:"expected,omitempty"`
+	Correct    *bool           `json:"correct,omitempty"`
+}
+
+// Store keeps the quizzes being taken, in memory. A quiz is dropped an
+// hour after its time is up.
+type Store struct {
+	mu       sync.Mutex
+	quizzes  map[string]*session
+	capacity int
+	now      func() time.Time
+}
+
+// NewStore returns a Store holding at most capacity quizzes at a time,
+// reading the time from now
+func NewStore(capacity int, now func() time.Time) *Store {
+	return &Store{quizzes: make(map[string]*session), capacity: capacity, now: now}
+}
+
+// Create starts a quiz of questions that must be finished within limit.
+// It fails if the store already holds as many quizzes as it may.
+func (s *Store) Create(questions []quiz.Question, limit time.Duration) (QuizView, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	s.sweep(now)
+	if len(s.quizzes) >= s.capacity {
+		return QuizView{}, errorsx.New(errorsx.CodeResourceExhausted, "Too many quizzes in progress; try again later")
+	}
+
+	sess := &session{
+		id:        newQuizID(),
+		questions: questions,
+		answers:   make([]string, len(questions)),
+		answered:  make([]bool, len(questions)),
+		correct:   make([]*bool, len(questions)),
+		started:   now,
+		deadline:  now.Add(limit),
+	}
+	s.quizzes[sess.id] = sess
+	return s.view(sess, now), nil
+}
+
+// Get returns the quiz with this ID
+func (s *Store) Get(id string) (QuizView, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess, err := s.lookup(id)
+	if err != nil {
+		return QuizView{}, err
+	}
+	return s.view(sess, s.now()), nil
+}
+
+// Answer records the answer to question number n, counting from 1, and
+// returns the question with the points a good answer makes. A question is
+// answered once, before the quiz's time is up.
+func (s *Store) Answer(id string, n int, answer string) (QuestionView, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess, i, err := s.open(id, n)
+	if err != nil {
+		return QuestionView{}, err
+	}
+	if sess.answered[i] {
+		return QuestionView{}, errorsx.Errorf(errorsx.CodeConflict, "question %d is already answered", n)
+	}
+	sess.answers[i] = answer
+	sess.answered[i] = true
+	return questionView(sess, i), nil
+}
+
+// Grade records whether the answer to question number n was right. The
+// question must have been answered and not graded yet.
+func (s *Store) Grade(id string, n int, correct bool) (QuizView, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess, i, err := s.open(id, n)
+	if err != nil {
+		return QuizView{}, err
+	}
+	switch {
+	case !sess.answered[i]:
+		return QuizView{}, errorsx.Errorf(errorsx.CodeConflict, "question %d has not been answered", n)
+	case sess.correct[i] != nil:
+		return QuizView{}, errorsx.Errorf(errorsx.CodeConflict, "question %d is already graded", n)
+	}
+	sess.correct[i] = &correct
+	return s.view(sess, s.now()), nil
+}
+
+func (s *Store) lookup(id string) (*session, error) {
+	sess, ok := s.quizzes[id]
+	if !ok {
+		return nil, errorsx.New(errorsx.CodeNotFound, "Quiz not found")
+	}
+	return sess, nil
+}
+
+// open returns the quiz with this ID and the index of its question number
+// n, if the quiz's time is not up
+func (s *Store) open(id string, n int) (*session, int, error) {
+	sess, err := s.lookup(id)
+	if err != nil {
+		return nil, 0, err
+	}
+	if n < 1 || n > len(sess.questions) {
+		return nil, 0, errorsx.Errorf(errorsx.CodeInvalidArgument, "question must be between 1 and %d", len(sess.questions))
+	}
+	if !s.now().Before(sess.deadline) {
+		return nil, 0, errorsx.New(errorsx.CodeConflict, "Time is up for this quiz")
+	}
+	return sess, n - 1, nil
+}
+
+// sweep drops the quizzes whose time ran out more than keepFinished ago
+func (s *Store) sweep(now time.Time) {
+	for id, sess := range s.quizzes {
+		if now.Sub(sess.deadline) > keepFinished {
+			delete(s.quizzes, id)
+		}
+	}
+}
+
+func (s *Store) view(sess *session, now time.Time) QuizView {
+	v := QuizView{
+		ID:          sess.id,
+		StartedAt:   sess.started,
+		Deadline:    sess.deadline,
+		SecondsLeft: max(0, int(sess.deadline.Sub(now).Round(time.Second)/time.Second)),
+		Questions:   make([]QuestionView, len(sess.questions)),
+		Result:      quiz.Result{ByTopic: make(map[string]quiz.Score)},
+	}
+	graded := 0
+	for i := range sess.questions {
+		v.Questions[i] = questionView(sess, i)
+		if c := sess.correct[i]; c != nil {
+			v.Result.Add(sess.questions[i].Topic, *c)
+			graded++
+		}
+	}
+	v.Finished = !now.Before(sess.deadline) || graded == len(sess.questions)
+	return v
+}
+
+func questionView(sess *session, i int) QuestionView {
+	q := sess.questions[i]
+	v := QuestionView{Number: i + 1, Topic: q.Topic, Question: q.Question, Difficulty: q.Difficulty}
+	if sess.answered[i] {
+		answer := sess.answers[i]
+		v.Answer = &answer
+		v.Expected = q.Answer
+	}
+	if c := sess.correct[i]; c != nil {
+		correct := *c
+		v.Correct = &correct
+	}
+	return v
+}
+
+func newQuizID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,291 @@
+package restapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/rehan/go-interview-prep/pkg/money"
+	"github.com/rehan/go-interview-prep/pkg/validator"
+)
+
+// The admin pages are server-rendered HTML forms for browsers, signed in
+// with a session cookie. Each page is the shared layout plus a file
+// defining its "content" block, so the navigation, flash message and
+// CSRF field are written once.
+
+//go:embed templates/admin
+var adminTemplates embed.FS
+
+// parseAdminPage returns templates/admin/<name>.html in the layout
+func parseAdminPage(name string) *template.Template {
+	return template.Must(template.New(name).Funcs(pageFuncs).ParseFS(adminTemplates,
+		"templates/admin/layout.html", "templates/admin/"+name+".html"))
+}
+
+var (
+	adminListPage    = parseAdminPage("list")
+	adminFormPage    = parseAdminPage("form")
+	adminLoginPage   = parseAdminPage("login")
+	adminMessagePage = parseAdminPage("message")
+)
+
+// maxAdminFormBytes bounds a form body; a book's fields need a few hundred
+const maxAdminFormBytes = 64 << 10
+
+// adminPage is the data every admin page is rendered with; each page
+// reads the fields it needs
+type adminPage struct {
+	Title   string
+	Session Session // the signed-in user; empty on the login page
+	Flash   string
+
+	Books []Book
+	Form  bookForm
+	// Errors holds a message per form field, and "form" for one about
+	// the whole form
+	Errors map[string]string
+
+	Next     string // login: where to go after signing in
+	Username string // login: what was typed, to type again less
+	Message  string // message: the text
+}
+
+// renderAdmin writes page with data and status. The pages carry the
+// user's CSRF token, so no cache may keep them.
+func renderAdmin(w http.ResponseWriter, page *template.Template, status int, data adminPage) {
+	// Render into a buffer so a template error cannot send half a page
+	var buf bytes.Buffer
+	if err := page.ExecuteTemplate(&buf, "layout", data); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	buf.WriteTo(w)
+}
+
+// renderAdminMessage renders a page of text, for errors
+func renderAdminMessage(w http.ResponseWriter, s Session, status int, msg string) {
+	renderAdmin(w, adminMessagePage, status, adminPage{Title: http.StatusText(status), Session: s, Message: msg})
+}
+
+// bookForm is a book as typed into the form. The fields stay strings so
+// that a form with errors is shown again as it was sent.
+type bookForm struct {
+	Title, Author, Price string
+}
+
+// bookFormFrom reads the posted form fields of r
+func bookFormFrom(r *http.Request) bookForm {
+	return bookForm{
+		Title:  strings.TrimSpace(r.PostFormValue("title")),
+		Author: strings.TrimSpace(r.PostFormValue("author")),
+		Price:  strings.TrimSpace(r.PostFormValue("price")),
+	}
+}
+
+// book validates f as the JSON API validates a book, and returns the book
+// or a message per field in error
+func (f bookForm) book() (Book, map[string]string) {
+	book := Book{Title: f.Title, Author: f.Author}
+	errs := make(map[string]string)
+	if f.Price != "" {
+		var err error
+		if book.Price, err = money.Parse(f.Price); err != nil {
+			errs["price"] = "price must be an amount such as 12.99"
+		}
+	}
+	var fieldErrs validator.Errors
+	if err := validator.Struct(book); errors.As(err, &fieldErrs) {
+		for _, fe := range fieldErrs {
+			// An unparsable price already has its message
+			if _, ok := errs[fe.Field]; !ok {
+				errs[fe.Field] = fe.Error()
+			}
+		}
+	} else if err != nil {
+		errs["form"] = err.Error()
+	}
+	return book, errs
+}
+
+// formFromBook returns the form for editing b
+func formFromBook(b Book) bookForm {
+	return bookForm{Title: b.Title, Author: b.Author, Price: b.Price.String()}
+}
+
+// adminMiddleware lets through requests with a live session whose role
+// grants perm; the empty perm lets through any signed-in user. Browsers
+// without a session are sent to the login page, to come back after. Forms
+// cannot set headers, so POSTs carry the CSRF token in a csrf_token field
+// rather than in X-CSRF-Token. Like requirePermission it passes next the
+// caller as the request's actor.
+func adminMiddleware(sm *sessionManager, perm Permission) Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			s, ok := sm.lookup(r)
+			if !ok {
+				http.Redirect(w, r, "/admin/login?next="+url.QueryEscape(r.URL.RequestURI()), http.StatusSeeOther)
+				return
+			}
+			if perm != "" && !s.Role.Can(perm) {
+				renderAdminMessage(w, s, http.StatusForbidden, fmt.Sprintf("The %s role lacks permission %s.", s.Role, perm))
+				return
+			}
+			if !safeMethod(r.Method) {
+				r.Body = http.MaxBytesReader(w, r.Body, maxAdminFormBytes)
+				if subtle.ConstantTimeCompare([]byte(r.PostFormValue("csrf_token")), []byte(s.CSRFToken)) != 1 {
+					renderAdminMessage(w, s, http.StatusForbidden, "The form is out of date. Go back, reload the page and try again.")
+					return
+				}
+			}
+			ctx := context.WithValue(r.Context(), sessionKey{}, s)
+			next(w, r.WithContext(withActor(ctx, "user:"+s.Username)))
+		}
+	}
+}
+
+// adminRoutes returns the admin pages by pattern. Book changes go through
+// storeFor, as the JSON API's do, so they are audited and published.
+func adminRoutes(sm *sessionManager, storeFor func(*http.Request) BookRepository) map[string]methodHandlers {
+	admin := func(perm Permission, h func(http.ResponseWriter, *http.Request, BookRepository, *sessionManager)) http.HandlerFunc {
+		return adminMiddleware(sm, perm)(func(w http.ResponseWriter, r *http.Request) { h(w, r, storeFor(r), sm) })
+	}
+	return map[string]methodHandlers{
+		"/admin/login": {
+			http.MethodGet:  func(w http.ResponseWriter, r *http.Request) { handleAdminLoginPage(w, r) },
+			http.MethodPost: func(w http.ResponseWriter, r *http.Request) { handleAdminLogin(w, r, sm) },
+		},
+		"/admin/logout": {http.MethodPost: admin("", handleAdminLogout)},
+		"/admin/books":  {http.MethodGet: admin(PermUpdateBooks, handleAdminBooks)},
+		"/admin/books/new": {
+			http.MethodGet:  admin(PermCreateBooks, handleAdminNewBook),
+			http.MethodPost: admin(PermCreateBooks, handleAdminCreateBook),
+		},
+		"/admin/books/{id}/edit": {
+			http.MethodGet:  admin(PermUpdateBooks, handleAdminEditBook),
+			http.MethodPost: admin(PermUpdateBooks, handleAdminUpdateBook),
+		},
+	}
+}
+
+// safeNext returns next if it is an admin page, so the login form cannot
+// be used to send a browser off to another site
+func safeNext(next string) string {
+	if !strings.HasPrefix(next, "/admin/") {
+		return "/admin/books"
+	}
+	return next
+}
+
+// handleAdminLoginPage handles GET /admin/login
+func handleAdminLoginPage(w http.ResponseWriter, r *http.Request) {
+	renderAdmin(w, adminLoginPage, http.StatusOK, adminPage{Title: "Sign in", Next: safeNext(r.URL.Query().Get("next"))})
+}
+
+// handleAdminLogin handles POST /admin/login, starting a session as POST
+// /auth/session does and going on to the page the user asked for
+func handleAdminLogin(w http.ResponseWriter, r *http.Request, sm *sessionManager) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxAdminFormBytes)
+	username, next := r.PostFormValue("username"), safeNext(r.PostFormValue("next"))
+	role, ok := sm.users.authenticate(username, r.PostFormValue("password"))
+	if !ok {
+		renderAdmin(w, adminLoginPage, http.StatusUnauthorized, adminPage{
+			Title:    "Sign in",
+			Next:     next,
+			Username: username,
+			Errors:   map[string]string{"form": "Invalid username or password"},
+		})
+		return
+	}
+	s := sm.create(w, username, role)
+	sm.setFlash(s, "Signed in as "+username+".")
+	http.Redirect(w, r, next, http.StatusSeeOther)
+}
+
+// handleAdminLogout handles POST /admin/logout
+func handleAdminLogout(w http.ResponseWriter, r *http.Request, _ BookRepository, sm *sessionManager) {
+	s, _ := SessionFromContext(r.Context())
+	sm.end(w, s)
+	http.Redirect(w, r, "/admin/login", http.StatusSeeOther)
+}
+
+// handleAdminBooks handles GET /admin/books, listing the books to edit
+func handleAdminBooks(w http.ResponseWriter, r *http.Request, store BookRepository, sm *sessionManager) {
+	s, _ := SessionFromContext(r.Context())
+	books := store.GetBooks()
+	sort.Slice(books, func(i, j int) bool { return books[i].ID < books[j].ID })
+	renderAdmin(w, adminListPage, http.StatusOK, adminPage{Title: "Books", Session: s, Flash: sm.takeFlash(s), Books: books})
+}
+
+// handleAdminNewBook handles GET /admin/books/new
+func handleAdminNewBook(w http.ResponseWriter, r *http.Request, _ BookRepository, _ *sessionManager) {
+	s, _ := SessionFromContext(r.Context())
+	renderAdmin(w, adminFormPage, http.StatusOK, adminPage{Title: "New book", Session: s})
+}
+
+// handleAdminCreateBook handles POST /admin/books/new. A valid book is
+// created and the browser redirected to the list (post/redirect/get, so
+// reloading does not post again); an invalid one shows the form again
+// with its errors.
+func handleAdminCreateBook(w http.ResponseWriter, r *http.Request, store BookRepository, sm *sessionManager) {
+	s, _ := SessionFromContext(r.Context())
+	form := bookFormFrom(r)
+	book, errs := form.book()
+	if len(errs) > 0 {
+		renderAdmin(w, adminFormPage, http.StatusBadRequest, adminPage{Title: "New book", Session: s, Form: form, Errors: errs})
+		return
+	}
+	id := store.AddBook(book)
+	sm.setFlash(s, fmt.Sprintf("Created %q as book %d.", book.Title, id))
+	http.Redirect(w, r, "/admin/books", http.StatusSeeOther)
+}
+
+// handleAdminEditBook handles GET /admin/books/{id}/edit
+func handleAdminEditBook(w http.ResponseWriter, r *http.Request, store BookRepository, _ *sessionManager) {
+	s, _ := SessionFromContext(r.Context())
+	id, err := pathID(r)
+	if err != nil {
+		renderAdminMessage(w, s, http.StatusNotFound, "No such book.")
+		return
+	}
+	book, ok := store.GetBook(id)
+	if !ok {
+		renderAdminMessage(w, s, http.StatusNotFound, "No such book.")
+		return
+	}
+	renderAdmin(w, adminFormPage, http.StatusOK, adminPage{Title: fmt.Sprintf("Edit book %d", id), Session: s, Form: formFromBook(book)})
+}
+
+// handleAdminUpdateBook handles POST /admin/books/{id}/edit, as
+// handleAdminCreateBook does a new book
+func handleAdminUpdateBook(w http.ResponseWriter, r *http.Request, store BookRepository, sm *sessionManager) {
+	s, _ := SessionFromContext(r.Context())
+	id, err := pathID(r)
+	if err != nil {
+		renderAdminMessage(w, s, http.StatusNotFound, "No such book.")
+		return
+	}
+	form := bookFormFrom(r)
+	book, errs := form.book()
+	if len(errs) > 0 {
+		renderAdmin(w, adminFormPage, http.StatusBadRequest, adminPage{Title: fmt.Sprintf("Edit book %d", id), Session: s, Form: form, Errors: errs})
+		return
+	}
+	if !store.UpdateBook(id, book) {
+		renderAdminMessage(w, s, http.StatusNotFound, "No such book.")
+		return
+	}
+	sm.setFlash(s, fmt.Sprintf("Saved %q.", book.Title))
+	http.Redirect(w, r, "/admin/books", http.StatusSeeOther)
+}
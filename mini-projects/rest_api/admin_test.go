@@ -0,0 +1,247 @@
+package restapi
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/money"
+	"github.com/rehan/go-interview-prep/pkg/testutil/golden"
+)
+
+// testAdmin returns a router with the admin pages, sign-ins for an admin
+// and a reader, and the book store behind it
+func testAdmin(t *testing.T) (http.Handler, *BookStore) {
+	t.Helper()
+	auth, _ := testAuth(t)
+	users := newUserStore(map[string]Account{
+		"alice": {Password: "wonderland", Role: RoleAdmin},
+		"bob":   {Password: "builder", Role: RoleReader},
+	})
+	auth.sessions = newSessionManager(NewMemorySessionStore(), users, time.Hour, true)
+	auth.sessions.now = auth.now
+	store := NewBookStore()
+	router := newRouter(store, auth, slog.New(slog.NewTextHandler(io.Discard, nil)), nil, newResponseCache(withCacheTTL(time.Minute)), nil, nil, nil, nil, nil, nil)
+	return router, store
+}
+
+// postForm posts form to path with cookie, if not nil
+func postForm(handler http.Handler, path string, cookie *http.Cookie, form url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr
+}
+
+var csrfField = regexp.MustCompile(`name="csrf_token" value="([^"]+)"`)
+
+// adminLogin signs username in through the login form and returns the
+// session cookie and the CSRF token the pages' forms carry
+func adminLogin(t *testing.T, handler http.Handler, username, password string) (*http.Cookie, string) {
+	t.Helper()
+	rr := postForm(handler, "/admin/login", nil, url.Values{"username": {username}, "password": {password}})
+	if rr.Code != http.StatusSeeOther {
+		t.Fatalf("login status = %d; want 303 (body: %s)", rr.Code, rr.Body.String())
+	}
+	cookie := rr.Result().Cookies()[0]
+	page := sendWithSession(handler, http.MethodGet, "/admin/books/new", "", cookie)
+	m := csrfField.FindStringSubmatch(page.Body.String())
+	if m == nil {
+		t.Fatalf("no CSRF field in the form page:\n%s", page.Body.String())
+	}
+	return cookie, m[1]
+}
+
+func TestAdminPages(t *testing.T) {
+	s := Session{Username: "alice", Role: RoleAdmin, CSRFToken: "token"}
+	books := []Book{
+		{ID: 1, Title: "<script>alert(1)</script>", Author: "Eve & Mallory", Price: money.FromCents(100)},
+		{ID: 2, Title: "Learning Go", Author: "Jon Bodner", Price: money.FromCents(2999)},
+	}
+	tests := []struct {
+		name   string
+		page   *template.Template
+		status int
+		data   adminPage
+	}{
+		{"admin_list", adminListPage, http.StatusOK, adminPage{Title: "Books", Session: s, Flash: `Created "Learning Go" as book 2.`, Books: books}},
+		{"admin_form_errors", adminFormPage, http.StatusBadRequest, adminPage{
+			Title:   "New book",
+			Session: s,
+			Form:    bookForm{Title: `"><b>`, Price: "12.999"},
+			Errors:  map[string]string{"author": "author is required", "price": "price must be an amount such as 12.99"},
+		}},
+		{"admin_login", adminLoginPage, http.StatusUnauthorized, adminPage{Title: "Sign in", Next: "/admin/books/1/edit", Username: "alice", Errors: map[string]string{"form": "Invalid username or password"}}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			renderAdmin(rr, tc.page, tc.status, tc.data)
+			if rr.Code != tc.status || rr.Header().Get("Cache-Control") != "no-store" {
+				t.Errorf("status %d, Cache-Control %q; want %d, no-store", rr.Code, rr.Header().Get("Cache-Control"), tc.status)
+			}
+			golden.Assert(t, tc.name, rr.Body.Bytes())
+		})
+	}
+}
+
+func TestAdmin_Login(t *testing.T) {
+	router, _ := testAdmin(t)
+
+	// Pages send browsers without a session to sign in, and back after
+	rr := sendWithSession(router, http.MethodGet, "/admin/books/1/edit", "", nil)
+	if loc := rr.Header().Get("Location"); rr.Code != http.StatusSeeOther || loc != "/admin/login?next=%2Fadmin%2Fbooks%2F1%2Fedit" {
+		t.Fatalf("without a session: %d to %q; want 303 to the login page", rr.Code, loc)
+	}
+	rr = sendWithSession(router, http.MethodGet, "/admin/login?next=%2Fadmin%2Fbooks%2F1%2Fedit", "", nil)
+	if !strings.Contains(rr.Body.String(), `name="next" value="/admin/books/1/edit"`) {
+		t.Errorf("login page does not carry next:\n%s", rr.Body.String())
+	}
+
+	rr = postForm(router, "/admin/login", nil, url.Values{"username": {"alice"}, "password": {"looking-glass"}, "next": {"/admin/books/1/edit"}})
+	if rr.Code != http.StatusUnauthorized || len(rr.Result().Cookies()) != 0 || !strings.Contains(rr.Body.String(), "Invalid username or password") {
+		t.Errorf("wrong password: %d with cookies %v; want 401 with the form again", rr.Code, rr.Result().Cookies())
+	}
+
+	tests := []struct {
+		next, want string
+	}{
+		{"/admin/books/1/edit", "/admin/books/1/edit"},
+		{"", "/admin/books"},
+		{"https://evil.example/", "/admin/books"},
+		{"//evil.example/admin/", "/admin/books"},
+	}
+	for _, tc := range tests {
+		rr := postForm(router, "/admin/login", nil, url.Values{"username": {"alice"}, "password": {"wonderland"}, "next": {tc.next}})
+		if loc := rr.Header().Get("Location"); rr.Code != http.StatusSeeOther || loc != tc.want {
+			t.Errorf("login with next %q: %d to %q; want 303 to %q", tc.next, rr.Code, loc, tc.want)
+		}
+	}
+}
+
+func TestAdmin_FlashShownOnce(t *testing.T) {
+	router, _ := testAdmin(t)
+	rr := postForm(router, "/admin/login", nil, url.Values{"username": {"alice"}, "password": {"wonderland"}})
+	cookie := rr.Result().Cookies()[0]
+
+	first := sendWithSession(router, http.MethodGet, "/admin/books", "", cookie)
+	if !strings.Contains(first.Body.String(), `<p class="flash">Signed in as alice.</p>`) {
+		t.Errorf("first page has no flash:\n%s", first.Body.String())
+	}
+	second := sendWithSession(router, http.MethodGet, "/admin/books", "", cookie)
+	if strings.Contains(second.Body.String(), "flash") {
+		t.Errorf("second page shows the flash again:\n%s", second.Body.String())
+	}
+}
+
+func TestAdmin_CreateBook(t *testing.T) {
+	router, store := testAdmin(t)
+	cookie, token := adminLogin(t, router, "alice", "wonderland")
+	before := len(store.GetBooks())
+
+	// Without the form's token the post is refused
+	rr := postForm(router, "/admin/books/new", cookie, url.Values{"title": {"T"}, "author": {"A"}, "price": {"1"}})
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("no CSRF token: status = %d; want 403", rr.Code)
+	}
+
+	// Invalid fields show the form again, as typed, with their errors
+	rr = postForm(router, "/admin/books/new", cookie, url.Values{"csrf_token": {token}, "title": {"Learning Go"}, "price": {"12.999"}})
+	body := rr.Body.String()
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("invalid book: status = %d; want 400", rr.Code)
+	}
+	for _, want := range []string{`value="Learning Go"`, `value="12.999"`, "author is required", "price must be an amount such as 12.99"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("form with errors lacks %s:\n%s", want, body)
+		}
+	}
+	if n := len(store.GetBooks()); n != before {
+		t.Fatalf("invalid book stored: %d books; want %d", n, before)
+	}
+
+	rr = postForm(router, "/admin/books/new", cookie, url.Values{"csrf_token": {token}, "title": {" Learning Go "}, "author": {"Jon Bodner"}, "price": {"29.99"}})
+	if loc := rr.Header().Get("Location"); rr.Code != http.StatusSeeOther || loc != "/admin/books" {
+		t.Fatalf("valid book: %d to %q; want 303 to the list (body: %s)", rr.Code, loc, rr.Body.String())
+	}
+	id := before + 1
+	book, ok := store.GetBook(id)
+	if !ok || book.Title != "Learning Go" || book.Author != "Jon Bodner" || book.Price != money.FromCents(2999) {
+		t.Errorf("stored book %d = %+v, %v; want Learning Go by Jon Bodner at 29.99", id, book, ok)
+	}
+	list := sendWithSession(router, http.MethodGet, "/admin/books", "", cookie).Body.String()
+	if !strings.Contains(list, fmt.Sprintf("Created &#34;Learning Go&#34; as book %d.", id)) || !strings.Contains(list, fmt.Sprintf(`<a href="/admin/books/%d/edit">`, id)) {
+		t.Errorf("list lacks the flash or the new book:\n%s", list)
+	}
+}
+
+func TestAdmin_EditBook(t *testing.T) {
+	router, store := testAdmin(t)
+	cookie, token := adminLogin(t, router, "alice", "wonderland")
+	old, _ := store.GetBook(1)
+
+	form := sendWithSession(router, http.MethodGet, "/admin/books/1/edit", "", cookie).Body.String()
+	for _, want := range []string{"<h1>Edit book 1</h1>", `value="` + old.Author + `"`, `value="` + old.Price.String() + `"`} {
+		if !strings.Contains(form, want) {
+			t.Errorf("edit form lacks %s:\n%s", want, form)
+		}
+	}
+
+	rr := postForm(router, "/admin/books/1/edit", cookie, url.Values{"csrf_token": {token}, "title": {old.Title}, "author": {old.Author}, "price": {"5"}})
+	if rr.Code != http.StatusSeeOther {
+		t.Fatalf("update: status = %d; want 303 (body: %s)", rr.Code, rr.Body.String())
+	}
+	if book, _ := store.GetBook(1); book.Price != money.FromCents(500) {
+		t.Errorf("price after update = %v; want 5.00", book.Price)
+	}
+
+	for _, path := range []string{"/admin/books/999/edit", "/admin/books/x/edit"} {
+		if rr := sendWithSession(router, http.MethodGet, path, "", cookie); rr.Code != http.StatusNotFound {
+			t.Errorf("GET %s: status = %d; want 404", path, rr.Code)
+		}
+	}
+	rr = postForm(router, "/admin/books/999/edit", cookie, url.Values{"csrf_token": {token}, "title": {"T"}, "author": {"A"}, "price": {"1"}})
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("updating a missing book: status = %d; want 404", rr.Code)
+	}
+}
+
+func TestAdmin_ReaderForbidden(t *testing.T) {
+	router, _ := testAdmin(t)
+	rr := postForm(router, "/admin/login", nil, url.Values{"username": {"bob"}, "password": {"builder"}})
+	cookie := rr.Result().Cookies()[0]
+	for _, path := range []string{"/admin/books", "/admin/books/new", "/admin/books/1/edit"} {
+		rr := sendWithSession(router, http.MethodGet, path, "", cookie)
+		if rr.Code != http.StatusForbidden || !strings.Contains(rr.Body.String(), "The reader role lacks permission") {
+			t.Errorf("GET %s as a reader: status = %d; want 403 with the reason", path, rr.Code)
+		}
+	}
+}
+
+func TestAdmin_Logout(t *testing.T) {
+	router, _ := testAdmin(t)
+	cookie, token := adminLogin(t, router, "alice", "wonderland")
+
+	if rr := postForm(router, "/admin/logout", cookie, nil); rr.Code != http.StatusForbidden {
+		t.Errorf("logout without a CSRF token: status = %d; want 403", rr.Code)
+	}
+	rr := postForm(router, "/admin/logout", cookie, url.Values{"csrf_token": {token}})
+	if loc := rr.Header().Get("Location"); rr.Code != http.StatusSeeOther || loc != "/admin/login" {
+		t.Fatalf("logout: %d to %q; want 303 to the login page", rr.Code, loc)
+	}
+	if rr := sendWithSession(router, http.MethodGet, "/admin/books", "", cookie); rr.Code != http.StatusSeeOther {
+		t.Errorf("GET after logout: status = %d; want 303 to sign in again", rr.Code)
+	}
+}
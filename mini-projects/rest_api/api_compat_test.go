@@ -0,0 +1,192 @@
+package restapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/rehan/go-interview-prep/pkg/testutil/golden"
+)
+
+// jsonShape describes the shape of a decoded JSON value, leaving out the
+// values: an object becomes its fields' shapes by name, an array the
+// shape of all its elements merged, and anything else its JSON type
+func jsonShape(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		fields := make(map[string]any, len(v))
+		for name, field := range v {
+			fields[name] = jsonShape(field)
+		}
+		return fields
+	case []any:
+		var elem any = "empty"
+		for i, e := range v {
+			if i == 0 {
+				elem = jsonShape(e)
+			} else {
+				elem = mergeShapes(elem, jsonShape(e))
+			}
+		}
+		return []any{elem}
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	default:
+		return "null"
+	}
+}
+
+// mergeShapes returns one shape for two elements of an array: objects
+// have the fields of both, arrays their elements merged, and types that
+// differ are joined as "number|string"
+func mergeShapes(a, b any) any {
+	switch a := a.(type) {
+	case map[string]any:
+		if b, ok := b.(map[string]any); ok {
+			fields := make(map[string]any, len(a))
+			for name, shape := range a {
+				fields[name] = shape
+			}
+			for name, shape := range b {
+				if have, ok := fields[name]; ok {
+					fields[name] = mergeShapes(have, shape)
+				} else {
+					fields[name] = shape
+				}
+			}
+			return fields
+		}
+	case []any:
+		if b, ok := b.([]any); ok {
+			return []any{mergeShapes(a[0], b[0])}
+		}
+	}
+	if a == "empty" {
+		return b
+	}
+	if b == "empty" {
+		return a
+	}
+	types := append(strings.Split(shapeName(a), "|"), strings.Split(shapeName(b), "|")...)
+	slices.Sort(types)
+	return strings.Join(slices.Compact(types), "|")
+}
+
+// shapeName names a shape for mergeShapes to join with another type
+func shapeName(shape any) string {
+	switch shape := shape.(type) {
+	case string:
+		return shape
+	case []any:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// TestAPICompatibility records the shape of each response under
+// testdata/api/v<apiVersion> and fails when one no longer matches. A
+// version's snapshots are never rewritten: a change of shape needs a new
+// apiVersion, whose snapshots go test -update then records, keeping the
+// old version's beside them. Deleting a snapshot lets -update record it
+// again, for a response that was never released.
+func TestAPICompatibility(t *testing.T) {
+	router, _, token := auditRouter(t)
+	bearer := http.Header{"Authorization": {"Bearer " + token}}
+	dir := filepath.Join("testdata", "api", "v"+strconv.Itoa(apiVersion))
+
+	// The requests run in order against one router
+	tests := []struct {
+		name         string
+		method, path string
+		body         string
+		header       http.Header
+		want         int
+	}{
+		{"login", http.MethodPost, "/auth/login", `{"username":"alice","password":"wonderland"}`, nil, http.StatusOK},
+		{"list_books", http.MethodGet, "/books?limit=2", "", nil, http.StatusOK},
+		{"get_book", http.MethodGet, "/books/1", "", nil, http.StatusOK},
+		{"create_book", http.MethodPost, "/books", `{"title":"Learning Go","author":"Jon Bodner","price":29.99}`, bearer, http.StatusCreated},
+		{"update_book", http.MethodPut, "/books/4", `{"title":"Learning Go","author":"Jon Bodner","price":39.99}`, bearer, http.StatusOK},
+		{"batch_create_books", http.MethodPost, "/books/batch", `[{"title":"T","author":"A","price":1},{"title":""}]`, bearer, http.StatusOK},
+		{"create_api_key", http.MethodPost, "/admin/keys", `{"name":"importer","scopes":["books:create"],"rate_limit":2}`, bearer, http.StatusCreated},
+		{"list_api_keys", http.MethodGet, "/admin/keys", "", bearer, http.StatusOK},
+		{"audit_log", http.MethodGet, "/admin/audit", "", bearer, http.StatusOK},
+		{"error_not_found", http.MethodGet, "/books/999", "", nil, http.StatusNotFound},
+		{"error_validation", http.MethodPost, "/books", `{"title":"","price":-1}`, bearer, http.StatusBadRequest},
+		{"error_unauthorized", http.MethodPost, "/books", `{}`, nil, http.StatusUnauthorized},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := auditRequest(t, router, tc.method, tc.path, tc.body, tc.header, tc.want)
+			if got := rr.Header().Get("API-Version"); got != strconv.Itoa(apiVersion) {
+				t.Errorf("API-Version = %q; want %d", got, apiVersion)
+			}
+			var body any
+			if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+				t.Fatalf("response is not JSON: %v\n%s", err, rr.Body)
+			}
+			got, err := json.MarshalIndent(jsonShape(body), "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, '\n')
+
+			path := filepath.Join(dir, tc.name+".json")
+			want, err := os.ReadFile(path)
+			switch {
+			case os.IsNotExist(err) && golden.Updating():
+				if err := os.MkdirAll(dir, 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			case os.IsNotExist(err):
+				t.Fatalf("no snapshot of v%d at %s (run go test -update to record it)", apiVersion, path)
+			case err != nil:
+				t.Fatal(err)
+			case !bytes.Equal(got, want):
+				t.Errorf("the shape of %s %s no longer matches %s. Clients of v%d rely on it: bump apiVersion to %d and run go test -update to record the new shapes\ngot:\n%s\nwant:\n%s",
+					tc.method, tc.path, path, apiVersion, apiVersion+1, got, want)
+			}
+		})
+	}
+}
+
+func TestJSONShape(t *testing.T) {
+	tests := []struct {
+		json string
+		want string
+	}{
+		{`"a"`, `"string"`},
+		{`1.5`, `"number"`},
+		{`true`, `"boolean"`},
+		{`null`, `"null"`},
+		{`[]`, `["empty"]`},
+		{`{"id":1,"tags":["a"]}`, `{"id":"number","tags":["string"]}`},
+		{`[{"a":1},{"b":"x"}]`, `[{"a":"number","b":"string"}]`},
+		{`[1,"x",null,2]`, `["null|number|string"]`},
+		{`[[],[1]]`, `[["number"]]`},
+		{`[{"a":1},[1]]`, `["array|object"]`},
+	}
+	for _, tc := range tests {
+		var v any
+		if err := json.Unmarshal([]byte(tc.json), &v); err != nil {
+			t.Fatal(err)
+		}
+		if got := mustJSON(t, jsonShape(v)); got != tc.want {
+			t.Errorf("jsonShape(%s) = %s; want %s", tc.json, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,284 @@
+package restapi
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/errorsx"
+	"github.com/rehan/go-interview-prep/pkg/ratelimit"
+	"github.com/rehan/go-interview-prep/pkg/validator"
+)
+
+// apiKeyPrefix starts every key, so a leaked key is easy to recognise in
+// logs and by secret scanners
+const apiKeyPrefix = "bk_"
+
+// APIKey is a stored key. Only a SHA-256 hash of the secret is kept: keys
+// are long random strings, so unlike passwords they need no slow hash, and
+// a leaked store does not leak usable keys.
+type APIKey struct {
+	ID        string       `json:"id"`
+	Name      string       `json:"name"`
+	Hash      string       `json:"hash"` // hex SHA-256 of the secret
+	Scopes    []Permission `json:"scopes"`
+	RateLimit int          `json:"rate_limit"` // requests per minute; 0 is unlimited
+	CreatedAt time.Time    `json:"created_at"`
+	RevokedAt *time.Time   `json:"revoked_at,omitempty"`
+}
+
+// APIKeyRepository stores API keys. Like BookRepository the build selects
+// the implementation: memory by default, a JSON file with -tags filestore.
+type APIKeyRepository interface {
+	ListAPIKeys() []APIKey
+	// PutAPIKey inserts key, or replaces the key with the same ID
+	PutAPIKey(key APIKey)
+}
+
+// MemoryAPIKeyRepository keeps keys in a map
+type MemoryAPIKeyRepository struct {
+	mu   sync.RWMutex
+	keys map[string]APIKey
+}
+
+// NewMemoryAPIKeyRepository returns an empty repository
+func NewMemoryAPIKeyRepository() *MemoryAPIKeyRepository {
+	return &MemoryAPIKeyRepository{keys: make(map[string]APIKey)}
+}
+
+// ListAPIKeys returns every key, ordered by ID
+func (m *MemoryAPIKeyRepository) ListAPIKeys() []APIKey {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	keys := make([]APIKey, 0, len(m.keys))
+	for _, k := range m.keys {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+	return keys
+}
+
+// PutAPIKey stores key under its ID
+func (m *MemoryAPIKeyRepository) PutAPIKey(key APIKey) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.keys[key.ID] = key
+}
+
+// Errors returned by APIKeyStore
+var (
+	errKeyNotFound    = errorsx.New(errorsx.CodeNotFound, "API key not found")
+	errKeyInvalid     = errorsx.New(errorsx.CodeUnauthenticated, "Invalid API key")
+	errKeyRateLimited = errorsx.New(errorsx.CodeResourceExhausted, "API key rate limit exceeded")
+)
+
+// APIKeyStore creates, revokes and checks API keys, and enforces each
+// key's rate limit
+type APIKeyStore struct {
+	repo APIKeyRepository
+	now  func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*ratelimit.Bucket // by key ID; rate limits are not persisted
+}
+
+// NewAPIKeyStore returns a store over repo
+func NewAPIKeyStore(repo APIKeyRepository) *APIKeyStore {
+	return &APIKeyStore{repo: repo, now: time.Now, buckets: make(map[string]*ratelimit.Bucket)}
+}
+
+// hashKey returns the stored form of a secret
+func hashKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// Create makes a key and returns it with its secret. The secret is not
+// stored and cannot be shown again.
+func (s *APIKeyStore) Create(name string, scopes []Permission, rateLimit int) (APIKey, string) {
+	var id [6]byte
+	var secret [24]byte
+	rand.Read(id[:])
+	rand.Read(secret[:])
+
+	key := APIKey{
+		ID:        hex.EncodeToString(id[:]),
+		Name:      name,
+		Scopes:    slices.Clone(scopes),
+		RateLimit: rateLimit,
+		CreatedAt: s.now().UTC(),
+	}
+	// The ID is part of the secret so Authenticate can find the key
+	// without comparing against every stored hash
+	plain := apiKeyPrefix + key.ID + "_" + base64.RawURLEncoding.EncodeToString(secret[:])
+	key.Hash = hashKey(plain)
+	s.repo.PutAPIKey(key)
+	return key, plain
+}
+
+// List returns every key, revoked ones included
+func (s *APIKeyStore) List() []APIKey {
+	return s.repo.ListAPIKeys()
+}
+
+// find returns the key with id
+func (s *APIKeyStore) find(id string) (APIKey, bool) {
+	for _, k := range s.repo.ListAPIKeys() {
+		if k.ID == id {
+			return k, true
+		}
+	}
+	return APIKey{}, false
+}
+
+// Revoke disables the key with id. Revoking a revoked key changes nothing.
+func (s *APIKeyStore) Revoke(id string) error {
+	key, ok := s.find(id)
+	if !ok {
+		return errKeyNotFound
+	}
+	if key.RevokedAt == nil {
+		now := s.now().UTC()
+		key.RevokedAt = &now
+		s.repo.PutAPIKey(key)
+	}
+	return nil
+}
+
+// Authenticate returns the live key whose secret is plain, after charging
+// one request to its rate limit. A key over its limit is returned along
+// with errKeyRateLimited, so the caller can say when to retry.
+func (s *APIKeyStore) Authenticate(plain string) (APIKey, error) {
+	rest, ok := strings.CutPrefix(plain, apiKeyPrefix)
+	if !ok {
+		return APIKey{}, errKeyInvalid
+	}
+	id, _, _ := strings.Cut(rest, "_")
+	key, ok := s.find(id)
+	// The hashes are compared rather than the secrets, so comparison
+	// time says nothing about the secret
+	if !ok || key.Hash != hashKey(plain) || key.RevokedAt != nil {
+		return APIKey{}, errKeyInvalid
+	}
+	if !s.allow(key) {
+		return key, errKeyRateLimited
+	}
+	return key, nil
+}
+
+// allow takes a token from key's bucket. Each key has its own rate, so
+// the store keeps ratelimit.Buckets rather than one ratelimit.Limiter.
+func (s *APIKeyStore) allow(key APIKey) bool {
+	if key.RateLimit <= 0 {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.buckets[key.ID]
+	if !ok {
+		b = ratelimit.NewBucket(ratelimit.Per(key.RateLimit, time.Minute), key.RateLimit, s.now())
+		s.buckets[key.ID] = b
+	}
+	return b.Allow(s.now()).Allowed
+}
+
+// retryAfterSeconds is how long a key over its limit waits for its next
+// token, rounded up to whole seconds as the Retry-After header needs
+func retryAfterSeconds(key APIKey) int {
+	if key.RateLimit <= 0 {
+		return 1
+	}
+	return int(math.Ceil(60 / float64(key.RateLimit)))
+}
+
+// Can reports whether the key's scopes include perm
+func (k APIKey) Can(perm Permission) bool {
+	return slices.Contains(k.Scopes, perm)
+}
+
+// apiKeyScopes are the permissions a key may be given. Managing keys is
+// not one of them, so a key can never mint more keys.
+var apiKeyScopes = []Permission{PermCreateBooks, PermUpdateBooks, PermDeleteBooks}
+
+// CreateAPIKeyRequest is the body of POST /admin/keys
+type CreateAPIKeyRequest struct {
+	Name      string       `json:"name" validate:"required,max=100"`
+	Scopes    []Permission `json:"scopes"`
+	RateLimit int          `json:"rate_limit" validate:"min=0"`
+}
+
+// APIKeyInfo is a key as the admin endpoints show it, without its hash
+type APIKeyInfo struct {
+	ID        string       `json:"id"`
+	Name      string       `json:"name"`
+	Scopes    []Permission `json:"scopes"`
+	RateLimit int          `json:"rate_limit"`
+	CreatedAt time.Time    `json:"created_at"`
+	RevokedAt *time.Time   `json:"revoked_at,omitempty"`
+
+	// Key is the secret, present only in the response to its creation
+	Key string `json:"key,omitempty"`
+}
+
+func infoOf(k APIKey) APIKeyInfo {
+	return APIKeyInfo{ID: k.ID, Name: k.Name, Scopes: k.Scopes, RateLimit: k.RateLimit, CreatedAt: k.CreatedAt, RevokedAt: k.RevokedAt}
+}
+
+// handleCreateAPIKey handles POST /admin/keys
+func handleCreateAPIKey(w http.ResponseWriter, r *http.Request, keys *APIKeyStore, audit auditor) {
+	var req CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, errorsx.Wrap(err, errorsx.CodeInvalidArgument, "Invalid request body"))
+		return
+	}
+	if err := validator.Struct(req); err != nil {
+		respondWithError(w, errorsx.Wrap(err, errorsx.CodeInvalidArgument, "Invalid API key request"))
+		return
+	}
+	for _, scope := range req.Scopes {
+		if !slices.Contains(apiKeyScopes, scope) {
+			respondWithError(w, errorsx.Errorf(errorsx.CodeInvalidArgument, "Unknown scope %q", scope))
+			return
+		}
+	}
+
+	key, secret := keys.Create(req.Name, req.Scopes, req.RateLimit)
+	// The secret is only in the response; the entry has no Key to show
+	audit.record(AuditCreated, AuditAPIKey, key.ID, nil, infoOf(key))
+	info := infoOf(key)
+	info.Key = secret
+	respondWithJSON(w, http.StatusCreated, info)
+}
+
+// handleListAPIKeys handles GET /admin/keys
+func handleListAPIKeys(w http.ResponseWriter, r *http.Request, keys *APIKeyStore) {
+	infos := []APIKeyInfo{}
+	for _, k := range keys.List() {
+		infos = append(infos, infoOf(k))
+	}
+	respondWithJSON(w, http.StatusOK, infos)
+}
+
+// handleRevokeAPIKey handles DELETE /admin/keys/{id}. Only the first
+// revocation of a key is audited; later ones change nothing.
+func handleRevokeAPIKey(w http.ResponseWriter, r *http.Request, keys *APIKeyStore, audit auditor) {
+	id := r.PathValue("id")
+	before, _ := keys.find(id)
+	if err := keys.Revoke(id); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	if after, _ := keys.find(id); before.RevokedAt == nil {
+		audit.record(AuditRevoked, AuditAPIKey, id, infoOf(before), infoOf(after))
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,171 @@
+package restapi
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/errorsx"
+)
+
+func TestAPIKeyStore_Authenticate(t *testing.T) {
+	auth, now := testAuth(t)
+	keys := auth.keys
+	key, secret := keys.Create("ci", []Permission{PermCreateBooks}, 0)
+
+	if !strings.HasPrefix(secret, apiKeyPrefix+key.ID+"_") {
+		t.Errorf("secret %q does not start with %q", secret, apiKeyPrefix+key.ID+"_")
+	}
+	for _, k := range keys.List() {
+		if strings.Contains(k.Hash, secret) || k.Hash != hashKey(secret) {
+			t.Errorf("stored hash = %q; want the SHA-256 of the secret", k.Hash)
+		}
+	}
+
+	got, err := keys.Authenticate(secret)
+	if err != nil || got.ID != key.ID {
+		t.Fatalf("Authenticate(secret) = %v, %v; want key %s", got.ID, err, key.ID)
+	}
+	if !got.Can(PermCreateBooks) || got.Can(PermDeleteBooks) {
+		t.Errorf("scopes = %v; want only %s", got.Scopes, PermCreateBooks)
+	}
+
+	for _, bad := range []string{"", "nope", secret + "x", apiKeyPrefix + key.ID + "_guess", apiKeyPrefix + "unknown_" + secret} {
+		if _, err := keys.Authenticate(bad); !errors.Is(err, errKeyInvalid) {
+			t.Errorf("Authenticate(%q) err = %v; want errKeyInvalid", bad, err)
+		}
+	}
+
+	*now = now.Add(time.Minute)
+	if err := keys.Revoke(key.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keys.Authenticate(secret); !errors.Is(err, errKeyInvalid) {
+		t.Errorf("revoked key: err = %v; want errKeyInvalid", err)
+	}
+	if revoked := keys.List()[0].RevokedAt; revoked == nil || !revoked.Equal(*now) {
+		t.Errorf("RevokedAt = %v; want %v", revoked, *now)
+	}
+	if err := keys.Revoke("missing"); !errors.Is(err, errKeyNotFound) {
+		t.Errorf("Revoke(missing) err = %v; want errKeyNotFound", err)
+	}
+}
+
+func TestAPIKeyStore_RateLimit(t *testing.T) {
+	auth, now := testAuth(t)
+	_, secret := auth.keys.Create("ci", nil, 2)
+
+	for i := range 2 {
+		if _, err := auth.keys.Authenticate(secret); err != nil {
+			t.Fatalf("request %d: %v", i+1, err)
+		}
+	}
+	if _, err := auth.keys.Authenticate(secret); !errors.Is(err, errKeyRateLimited) {
+		t.Fatalf("third request err = %v; want errKeyRateLimited", err)
+	}
+
+	// Two a minute refills one token every 30 seconds
+	*now = now.Add(30 * time.Second)
+	if _, err := auth.keys.Authenticate(secret); err != nil {
+		t.Errorf("after 30s: %v", err)
+	}
+	if _, err := auth.keys.Authenticate(secret); !errors.Is(err, errKeyRateLimited) {
+		t.Errorf("second request after 30s err = %v; want errKeyRateLimited", err)
+	}
+}
+
+func TestRouter_APIKeys(t *testing.T) {
+	auth, _ := testAuth(t)
+	router := newRouter(NewBookStore(), auth, slog.New(slog.NewTextHandler(io.Discard, nil)), nil, nil, nil, nil, nil, nil, nil, nil)
+	rr := login(t, router, `{"username":"alice","password":"wonderland"}`)
+	var lr LoginResponse
+	if err := json.NewDecoder(rr.Body).Decode(&lr); err != nil {
+		t.Fatal(err)
+	}
+
+	send := func(method, path, body string, header ...string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+	bearer := "Bearer " + lr.Token
+
+	rr = send(http.MethodPost, "/admin/keys", `{"name":"importer","scopes":["books:create"],"rate_limit":2}`, "Authorization", bearer)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create status = %d; want 201 (body: %s)", rr.Code, rr.Body.String())
+	}
+	var created APIKeyInfo
+	if err := json.NewDecoder(rr.Body).Decode(&created); err != nil {
+		t.Fatal(err)
+	}
+	if created.Key == "" {
+		t.Fatal("create response has no key")
+	}
+
+	// The list never shows secrets or hashes
+	rr = send(http.MethodGet, "/admin/keys", "", "Authorization", bearer)
+	if body := rr.Body.String(); rr.Code != http.StatusOK || !strings.Contains(body, created.ID) || strings.Contains(body, created.Key) || strings.Contains(body, "hash") {
+		t.Errorf("list = %d %s; want 200 with the key's ID and no secret or hash", rr.Code, body)
+	}
+
+	book := `{"title":"T","author":"A","price":1}`
+	tests := []struct {
+		name     string
+		method   string
+		key      string
+		want     int
+		wantCode errorsx.Code
+	}{
+		{"in scope", http.MethodPost, created.Key, http.StatusCreated, ""},
+		{"out of scope", http.MethodDelete, created.Key, http.StatusForbidden, errorsx.CodePermissionDenied},
+		{"over the limit", http.MethodPost, created.Key, http.StatusTooManyRequests, errorsx.CodeResourceExhausted},
+		{"unknown key", http.MethodPost, "bk_nope", http.StatusUnauthorized, errorsx.CodeUnauthenticated},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			path := "/books"
+			if tc.method == http.MethodDelete {
+				path = "/books/1"
+			}
+			rr := send(tc.method, path, book, apiKeyHeader, tc.key)
+			if rr.Code != tc.want {
+				t.Fatalf("status = %d; want %d (body: %s)", rr.Code, tc.want, rr.Body.String())
+			}
+			if tc.want == http.StatusTooManyRequests && rr.Header().Get("Retry-After") != "30" {
+				t.Errorf("Retry-After = %q; want 30", rr.Header().Get("Retry-After"))
+			}
+			if tc.wantCode != "" {
+				var p Problem
+				if err := json.NewDecoder(rr.Body).Decode(&p); err != nil {
+					t.Fatal(err)
+				}
+				if p.Code != tc.wantCode {
+					t.Errorf("problem code = %q; want %q", p.Code, tc.wantCode)
+				}
+			}
+		})
+	}
+
+	// Keys cannot manage keys, whatever their scopes
+	if rr := send(http.MethodPost, "/admin/keys", `{"name":"x","scopes":["keys:manage"]}`, "Authorization", bearer); rr.Code != http.StatusBadRequest {
+		t.Errorf("keys:manage scope: status = %d; want 400", rr.Code)
+	}
+
+	if rr := send(http.MethodDelete, "/admin/keys/"+created.ID, "", "Authorization", bearer); rr.Code != http.StatusNoContent {
+		t.Fatalf("revoke status = %d; want 204", rr.Code)
+	}
+	if rr := send(http.MethodPost, "/books", book, apiKeyHeader, created.Key); rr.Code != http.StatusUnauthorized {
+		t.Errorf("revoked key: status = %d; want 401", rr.Code)
+	}
+}
@@ -0,0 +1,308 @@
+package restapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/errorsx"
+)
+
+// Audit actions
+const (
+	AuditCreated = "created"
+	AuditUpdated = "updated"
+	AuditDeleted = "deleted"
+	AuditRevoked = "revoked"
+)
+
+// Audited resources
+const (
+	AuditBook   = "book"
+	AuditCover  = "cover"
+	AuditAPIKey = "api_key"
+)
+
+// Paging limits for GET /admin/audit
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// AuditEntry is one change made through the API: who made it, when, and
+// which fields it changed
+type AuditEntry struct {
+	ID         int               `json:"id"`
+	Time       time.Time         `json:"time"`
+	Actor      string            `json:"actor"` // "user:<name>" or "key:<id>"
+	Action     string            `json:"action"`
+	Resource   string            `json:"resource"`
+	ResourceID string            `json:"resource_id"`
+	RequestID  string            `json:"request_id,omitempty"`
+	Changes    map[string]Change `json:"changes"`
+}
+
+// Change is one field's JSON value before and after. From is absent for a
+// created resource and To for a deleted one.
+type Change struct {
+	From json.RawMessage `json:"from,omitempty"`
+	To   json.RawMessage `json:"to,omitempty"`
+}
+
+// diffJSON compares the JSON objects before and after encode to, field by
+// field. A nil before or after is an object with no fields.
+func diffJSON(before, after any) (map[string]Change, error) {
+	from, err := jsonFields(before)
+	if err != nil {
+		return nil, err
+	}
+	to, err := jsonFields(after)
+	if err != nil {
+		return nil, err
+	}
+	changes := make(map[string]Change)
+	for name, v := range from {
+		if !bytes.Equal(v, to[name]) {
+			changes[name] = Change{From: v, To: to[name]}
+		}
+	}
+	for name, v := range to {
+		if _, ok := from[name]; !ok {
+			changes[name] = Change{To: v}
+		}
+	}
+	return changes, nil
+}
+
+// jsonFields returns the fields of v's JSON object. Encoding is
+// deterministic, so equal values have equal bytes.
+func jsonFields(v any) (map[string]json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	err = json.Unmarshal(data, &fields)
+	return fields, err
+}
+
+// AuditLog is an append-only record of changes. Entries cannot be edited
+// or removed, and the file store also appends each one to a JSON lines
+// file (see newAuditLog).
+type AuditLog struct {
+	now func() time.Time
+
+	mu      sync.RWMutex
+	entries []AuditEntry
+	w       io.WriteCloser // each entry is written here as a JSON line, if set
+}
+
+// NewAuditLog returns an empty in-memory log
+func NewAuditLog() *AuditLog {
+	return &AuditLog{now: time.Now}
+}
+
+// Append numbers e, timestamps it unless it has a time, and adds it to the
+// log. If it cannot be written out, it is not kept, so the log never holds
+// entries its file does not.
+func (l *AuditLog) Append(e AuditEntry) (AuditEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e.ID = len(l.entries) + 1
+	if e.Time.IsZero() {
+		e.Time = l.now().UTC()
+	}
+	if l.w != nil {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return AuditEntry{}, err
+		}
+		if _, err := l.w.Write(append(line, '\n')); err != nil {
+			return AuditEntry{}, err
+		}
+	}
+	l.entries = append(l.entries, e)
+	return e, nil
+}
+
+// Close closes the file the log is written to, if it has one. Appending
+// afterwards fails.
+func (l *AuditLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.w == nil {
+		return nil
+	}
+	return l.w.Close()
+}
+
+// AuditFilter selects audit entries; zero fields match everything
+type AuditFilter struct {
+	Actor, Action, Resource, ResourceID string
+	Since, Until                        time.Time // Since inclusive, Until exclusive
+	Before                              int       // only entries with lower IDs
+	Limit                               int
+}
+
+func (f AuditFilter) match(e AuditEntry) bool {
+	return (f.Actor == "" || e.Actor == f.Actor) &&
+		(f.Action == "" || e.Action == f.Action) &&
+		(f.Resource == "" || e.Resource == f.Resource) &&
+		(f.ResourceID == "" || e.ResourceID == f.ResourceID) &&
+		(f.Since.IsZero() || !e.Time.Before(f.Since)) &&
+		(f.Until.IsZero() || e.Time.Before(f.Until)) &&
+		(f.Before == 0 || e.ID < f.Before)
+}
+
+// Entries returns the entries f matches, newest first, at most f.Limit of
+// them if it is set
+func (l *AuditLog) Entries(f AuditFilter) []AuditEntry {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	matched := []AuditEntry{}
+	for _, e := range slices.Backward(l.entries) {
+		if f.Limit > 0 && len(matched) == f.Limit {
+			break
+		}
+		if f.match(e) {
+			matched = append(matched, e)
+		}
+	}
+	return matched
+}
+
+// actorKey is the context key for who is making a request
+type actorKey struct{}
+
+// withActor returns ctx carrying actor, set by requirePermission once it
+// knows who the request is from
+func withActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor requirePermission stored in ctx, or
+// "anonymous"
+func ActorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok {
+		return actor
+	}
+	return "anonymous"
+}
+
+// newAuditEntry returns an entry for a change from before to after, either
+// of which may be nil, without its actor
+func newAuditEntry(action, resource, id string, before, after any) (AuditEntry, error) {
+	changes, err := diffJSON(before, after)
+	return AuditEntry{Action: action, Resource: resource, ResourceID: id, Changes: changes}, err
+}
+
+// auditor records the changes one request makes. The zero auditor, as
+// for a router without an audit log, records nothing.
+type auditor struct {
+	log       *AuditLog
+	actor     string
+	requestID string
+}
+
+// auditorFor returns an auditor for r's changes to log, which may be nil
+func auditorFor(log *AuditLog, r *http.Request) auditor {
+	if log == nil {
+		return auditor{}
+	}
+	return auditor{log: log, actor: ActorFromContext(r.Context()), requestID: RequestIDFromContext(r.Context())}
+}
+
+// record appends an entry for a change from before to after, either of
+// which may be nil. The change has already been made, so an entry that
+// cannot be recorded is logged rather than failing the request.
+func (a auditor) record(action, resource, id string, before, after any) {
+	if a.log == nil {
+		return
+	}
+	entry, err := newAuditEntry(action, resource, id, before, after)
+	if err == nil {
+		entry.Actor, entry.RequestID = a.actor, a.requestID
+		_, err = a.log.Append(entry)
+	}
+	if err != nil {
+		slog.Error("recording audit entry", "action", action, "resource", resource, "id", id, "error", err)
+	}
+}
+
+// auditingCoverStore records cover uploads, as their type and size. Covers
+// deleted with their book are covered by the book's entry.
+type auditingCoverStore struct {
+	CoverStore
+	audit auditor
+}
+
+func (s auditingCoverStore) PutCover(id int, cover Cover) error {
+	var before any
+	action := AuditCreated
+	if old, err := s.Cover(id); err == nil {
+		before = CoverUpload{ContentType: old.ContentType, Size: len(old.Data)}
+		action = AuditUpdated
+	}
+	if err := s.CoverStore.PutCover(id, cover); err != nil {
+		return err
+	}
+	s.audit.record(action, AuditCover, strconv.Itoa(id), before, CoverUpload{ContentType: cover.ContentType, Size: len(cover.Data)})
+	return nil
+}
+
+// parseAuditQuery reads the filters of GET /admin/audit:
+//
+//	?actor=user:alice&action=updated      who, and what they did
+//	?resource=book&resource_id=4          what it was done to
+//	?since=2024-01-02T15:04:05Z&until=... RFC 3339 time range
+//	?limit=50&before=120                  page back from entry 120
+func parseAuditQuery(values url.Values) (AuditFilter, error) {
+	f := AuditFilter{
+		Actor:      values.Get("actor"),
+		Action:     values.Get("action"),
+		Resource:   values.Get("resource"),
+		ResourceID: values.Get("resource_id"),
+	}
+	var err error
+	if f.Limit, err = positiveInt(values, "limit", defaultAuditLimit); err != nil {
+		return f, err
+	}
+	if f.Limit > maxAuditLimit {
+		return f, errorsx.Errorf(errorsx.CodeInvalidArgument, "limit must be at most %d", maxAuditLimit)
+	}
+	if f.Before, err = positiveInt(values, "before", 0); err != nil {
+		return f, err
+	}
+	for name, t := range map[string]*time.Time{"since": &f.Since, "until": &f.Until} {
+		s := values.Get(name)
+		if s == "" {
+			continue
+		}
+		if *t, err = time.Parse(time.RFC3339, s); err != nil {
+			return f, errorsx.Errorf(errorsx.CodeInvalidArgument, "%s must be an RFC 3339 time", name)
+		}
+	}
+	return f, nil
+}
+
+// handleGetAudit handles GET /admin/audit: the entries matching the query's
+// filters, newest first. To page back, pass the last entry's ID as before.
+func handleGetAudit(w http.ResponseWriter, r *http.Request, log *AuditLog) {
+	f, err := parseAuditQuery(r.URL.Query())
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, log.Entries(f))
+}
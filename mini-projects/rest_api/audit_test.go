@@ -0,0 +1,246 @@
+package restapi
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiffJSON(t *testing.T) {
+	type item struct {
+		Name  string   `json:"name"`
+		Price float64  `json:"price"`
+		Tags  []string `json:"tags,omitempty"`
+	}
+	tests := []struct {
+		name          string
+		before, after any
+		want          map[string]Change
+	}{
+		{"created", nil, item{Name: "a", Price: 1}, map[string]Change{
+			"name":  {To: json.RawMessage(`"a"`)},
+			"price": {To: json.RawMessage(`1`)},
+		}},
+		{"deleted", item{Name: "a", Price: 1}, nil, map[string]Change{
+			"name":  {From: json.RawMessage(`"a"`)},
+			"price": {From: json.RawMessage(`1`)},
+		}},
+		{"one field changed", item{Name: "a", Price: 1}, item{Name: "a", Price: 2.5}, map[string]Change{
+			"price": {From: json.RawMessage(`1`), To: json.RawMessage(`2.5`)},
+		}},
+		{"field added and removed", item{Name: "a", Tags: []string{"x"}}, item{Name: "a"}, map[string]Change{
+			"tags": {From: json.RawMessage(`["x"]`)},
+		}},
+		{"unchanged", item{Name: "a"}, item{Name: "a"}, map[string]Change{}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := diffJSON(tc.before, tc.after)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("diffJSON = %s; want %s", mustJSON(t, got), mustJSON(t, tc.want))
+			}
+		})
+	}
+}
+
+func mustJSON(t *testing.T, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// adminToken logs in to router as testAuth's admin
+func adminToken(t *testing.T, router http.Handler) string {
+	t.Helper()
+	var lr LoginResponse
+	if err := json.NewDecoder(login(t, router, `{"username":"alice","password":"wonderland"}`).Body).Decode(&lr); err != nil {
+		t.Fatal(err)
+	}
+	return lr.Token
+}
+
+// auditRouter returns a router auditing to a fresh log, and an admin token
+func auditRouter(t *testing.T) (http.Handler, *AuditLog, string) {
+	t.Helper()
+	auth, _ := testAuth(t)
+	audit := NewAuditLog()
+	outbox, flush := testChanges(t, nil, nil, audit)
+	router := flushing(t, newRouter(NewBookStore(), auth, slog.New(slog.NewTextHandler(io.Discard, nil)), nil, nil, NewMemoryCoverStore(), nil, audit, outbox, nil, nil), flush)
+	return router, audit, adminToken(t, router)
+}
+
+// auditRequest makes a request with header and returns the response,
+// failing the test on an unexpected status
+func auditRequest(t *testing.T, router http.Handler, method, path, body string, header http.Header, want int) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != want {
+		t.Fatalf("%s %s = %d; want %d (body: %s)", method, path, rr.Code, want, rr.Body.String())
+	}
+	return rr
+}
+
+func TestAudit_BookChanges(t *testing.T) {
+	router, _, token := auditRouter(t)
+	bearer := http.Header{"Authorization": {"Bearer " + token}}
+
+	auditRequest(t, router, http.MethodPost, "/books", `{"title":"Learning Go","author":"Jon Bodner","price":29.99}`, bearer, http.StatusCreated)
+	auditRequest(t, router, http.MethodPut, "/books/4", `{"title":"Learning Go, 2nd ed.","author":"Jon Bodner","price":39.99}`, bearer, http.StatusOK)
+	// An update that changes nothing is still recorded, with no changes
+	auditRequest(t, router, http.MethodPut, "/books/4", `{"title":"Learning Go, 2nd ed.","author":"Jon Bodner","price":39.99}`, bearer, http.StatusOK)
+	auditRequest(t, router, http.MethodDelete, "/books/4", "", bearer, http.StatusNoContent)
+	// Failed changes are not
+	auditRequest(t, router, http.MethodDelete, "/books/4", "", bearer, http.StatusNotFound)
+
+	rr := auditRequest(t, router, http.MethodGet, "/admin/audit?resource=book&resource_id=4", "", bearer, http.StatusOK)
+	var entries []AuditEntry
+	if err := json.NewDecoder(rr.Body).Decode(&entries); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		action  string
+		changes map[string]Change
+	}{
+		{AuditDeleted, nil},
+		{AuditUpdated, map[string]Change{}},
+		{AuditUpdated, map[string]Change{
+			"title": {From: json.RawMessage(`"Learning Go"`), To: json.RawMessage(`"Learning Go, 2nd ed."`)},
+			"price": {From: json.RawMessage(`29.99`), To: json.RawMessage(`39.99`)},
+		}},
+		{AuditCreated, nil},
+	}
+	if len(entries) != len(tests) {
+		t.Fatalf("got %d entries; want %d: %s", len(entries), len(tests), mustJSON(t, entries))
+	}
+	for i, tc := range tests {
+		e := entries[i]
+		if e.Action != tc.action || e.Resource != AuditBook || e.ResourceID != "4" || e.Actor != "user:alice" || e.RequestID == "" {
+			t.Errorf("entry %d = %+v; want %s of book 4 by user:alice with a request ID", i, e, tc.action)
+		}
+		if tc.changes != nil && !reflect.DeepEqual(e.Changes, tc.changes) {
+			t.Errorf("entry %d changes = %s; want %s", i, mustJSON(t, e.Changes), mustJSON(t, tc.changes))
+		}
+	}
+	// Creations and deletions have every field, on one side only
+	if c := entries[3].Changes["title"]; c.From != nil || string(c.To) != `"Learning Go"` {
+		t.Errorf("created title change = %s; want only to", mustJSON(t, c))
+	}
+	if c := entries[0].Changes["title"]; c.To != nil || string(c.From) != `"Learning Go, 2nd ed."` {
+		t.Errorf("deleted title change = %s; want only from", mustJSON(t, c))
+	}
+}
+
+func TestAudit_KeysAndCovers(t *testing.T) {
+	router, audit, token := auditRouter(t)
+	bearer := http.Header{"Authorization": {"Bearer " + token}}
+
+	rr := auditRequest(t, router, http.MethodPost, "/admin/keys", `{"name":"ci","scopes":["books:update"]}`, bearer, http.StatusCreated)
+	var key APIKeyInfo
+	if err := json.NewDecoder(rr.Body).Decode(&key); err != nil {
+		t.Fatal(err)
+	}
+	byKey := http.Header{}
+	byKey.Set(apiKeyHeader, key.Key)
+	body, contentType := multipartFile(t, "file", "cover.png", pngCover)
+	byKey.Set("Content-Type", contentType)
+	auditRequest(t, router, http.MethodPost, "/books/1/cover", body.String(), byKey, http.StatusCreated)
+	auditRequest(t, router, http.MethodDelete, "/admin/keys/"+key.ID, "", bearer, http.StatusNoContent)
+	auditRequest(t, router, http.MethodDelete, "/admin/keys/"+key.ID, "", bearer, http.StatusNoContent)
+
+	entries := audit.Entries(AuditFilter{})
+	want := []struct{ actor, action, resource, id string }{
+		{"user:alice", AuditRevoked, AuditAPIKey, key.ID},
+		{"key:" + key.ID, AuditCreated, AuditCover, "1"},
+		{"user:alice", AuditCreated, AuditAPIKey, key.ID},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries; want %d: %s", len(entries), len(want), mustJSON(t, entries))
+	}
+	for i, w := range want {
+		e := entries[i]
+		if e.Actor != w.actor || e.Action != w.action || e.Resource != w.resource || e.ResourceID != w.id {
+			t.Errorf("entry %d = %+v; want %s %s %s %s", i, e, w.actor, w.action, w.resource, w.id)
+		}
+	}
+	if _, ok := entries[0].Changes["revoked_at"]; !ok || len(entries[0].Changes) != 1 {
+		t.Errorf("revocation changes = %s; want revoked_at only", mustJSON(t, entries[0].Changes))
+	}
+	if strings.Contains(mustJSON(t, entries), key.Key) {
+		t.Error("the audit log contains the key's secret")
+	}
+}
+
+func TestAudit_Filters(t *testing.T) {
+	audit := NewAuditLog()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	audit.now = func() time.Time { now = now.Add(time.Minute); return now }
+	for _, e := range []AuditEntry{
+		{Actor: "user:alice", Action: AuditCreated, Resource: AuditBook, ResourceID: "4"}, // 12:01
+		{Actor: "user:bob", Action: AuditUpdated, Resource: AuditBook, ResourceID: "4"},   // 12:02
+		{Actor: "user:alice", Action: AuditCreated, Resource: AuditAPIKey, ResourceID: "k"},
+		{Actor: "key:k", Action: AuditDeleted, Resource: AuditBook, ResourceID: "1"},
+	} {
+		if _, err := audit.Append(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		query  string
+		status int
+		want   []int // entry IDs
+	}{
+		{"", 200, []int{4, 3, 2, 1}},
+		{"actor=user:alice", 200, []int{3, 1}},
+		{"action=created&resource=book", 200, []int{1}},
+		{"resource=book&resource_id=4", 200, []int{2, 1}},
+		{"since=2024-01-01T12:02:00Z&until=2024-01-01T12:04:00Z", 200, []int{3, 2}},
+		{"limit=2", 200, []int{4, 3}},
+		{"limit=2&before=3", 200, []int{2, 1}},
+		{"actor=nobody", 200, []int{}},
+		{"limit=0", 400, nil},
+		{"limit=1001", 400, nil},
+		{"before=x", 400, nil},
+		{"since=yesterday", 400, nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.query, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			handleGetAudit(rr, httptest.NewRequest(http.MethodGet, "/admin/audit?"+tc.query, nil), audit)
+			if rr.Code != tc.status {
+				t.Fatalf("status = %d; want %d (body: %s)", rr.Code, tc.status, rr.Body.String())
+			}
+			if tc.status != http.StatusOK {
+				return
+			}
+			var entries []AuditEntry
+			if err := json.NewDecoder(rr.Body).Decode(&entries); err != nil {
+				t.Fatal(err)
+			}
+			ids := []int{}
+			for _, e := range entries {
+				ids = append(ids, e.ID)
+			}
+			if !reflect.DeepEqual(ids, tc.want) {
+				t.Errorf("entries = %v; want %v", ids, tc.want)
+			}
+		})
+	}
+}
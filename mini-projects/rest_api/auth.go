@@ -0,0 +1,208 @@
+package restapi
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/errorsx"
+	"github.com/rehan/go-interview-prep/pkg/jwt"
+	"github.com/rehan/go-interview-prep/pkg/validator"
+)
+
+// passwordHash is a salted SHA-256 of a password. SHA-256 is fast, which
+// is what makes it a poor password hash: a real service stores bcrypt or
+// argon2 hashes (golang.org/x/crypto) so that guessing is slow. The shape
+// of the check, salt plus constant-time comparison, is the same.
+type passwordHash struct {
+	salt [16]byte
+	sum  [sha256.Size]byte
+}
+
+func hashPassword(password string) passwordHash {
+	var h passwordHash
+	rand.Read(h.salt[:])
+	h.sum = h.digest(password)
+	return h
+}
+
+func (h passwordHash) digest(password string) [sha256.Size]byte {
+	return sha256.Sum256(append(h.salt[:], password...))
+}
+
+// matches compares in constant time so timing does not reveal how much of
+// a guess was right
+func (h passwordHash) matches(password string) bool {
+	sum := h.digest(password)
+	return subtle.ConstantTimeCompare(sum[:], h.sum[:]) == 1
+}
+
+// Account is a user who may log in
+type Account struct {
+	Password string
+	Role     Role
+}
+
+// userStore holds the accounts that may log in
+type userStore struct {
+	accounts map[string]storedAccount
+
+	// dummy is checked for unknown usernames so that they take as long
+	// to reject as wrong passwords, and response times do not reveal
+	// which usernames exist
+	dummy passwordHash
+}
+
+// storedAccount is an Account with its password hashed
+type storedAccount struct {
+	hash passwordHash
+	role Role
+}
+
+// newUserStore returns a store holding accounts, keyed by username
+func newUserStore(accounts map[string]Account) *userStore {
+	s := &userStore{accounts: make(map[string]storedAccount), dummy: hashPassword("")}
+	for name, a := range accounts {
+		s.accounts[name] = storedAccount{hash: hashPassword(a.Password), role: a.Role}
+	}
+	return s
+}
+
+// authenticate returns the role of username if password is right for it
+func (s *userStore) authenticate(username, password string) (Role, bool) {
+	a, ok := s.accounts[username]
+	if !ok {
+		s.dummy.matches(password)
+		return "", false
+	}
+	if !a.hash.matches(password) {
+		return "", false
+	}
+	return a.role, true
+}
+
+// tokenAuth issues tokens at login and checks them on protected routes
+type tokenAuth struct {
+	users *userStore
+	key   []byte
+	ttl   time.Duration
+	now   func() time.Time // time.Now outside tests
+
+	// keys, if set, lets requirePermission accept API keys as well
+	keys *APIKeyStore
+
+	// sessions, if set, lets requirePermission accept session cookies
+	sessions *sessionManager
+}
+
+// newTokenAuth signs tokens with secret. An empty secret is replaced by a
+// random key, which means tokens stop working when the server restarts.
+func newTokenAuth(users *userStore, secret string, ttl time.Duration) *tokenAuth {
+	key := []byte(secret)
+	if secret == "" {
+		key = make([]byte, jwt.MinKeySize)
+		rand.Read(key)
+	}
+	return &tokenAuth{users: users, key: key, ttl: ttl, now: time.Now}
+}
+
+// LoginRequest is the body of POST /auth/login
+type LoginRequest struct {
+	Username string `json:"username" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+// LoginResponse carries a bearer token; ExpiresIn is in seconds, as in
+// OAuth 2.0 token responses
+type LoginResponse struct {
+	Token     string `json:"token"`
+	TokenType string `json:"token_type"`
+	ExpiresIn int    `json:"expires_in"`
+}
+
+// handleLogin handles POST /auth/login, exchanging a username and password
+// for a signed token
+func handleLogin(w http.ResponseWriter, r *http.Request, auth *tokenAuth) {
+	if r.Method != http.MethodPost {
+		respondWithError(w, errorsx.New(errorsx.CodeMethodNotAllowed, "Method not allowed"))
+		return
+	}
+
+	var req LoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, errorsx.Wrap(err, errorsx.CodeInvalidArgument, "Invalid request body"))
+		return
+	}
+	if err := validator.Struct(req); err != nil {
+		respondWithError(w, errorsx.Wrap(err, errorsx.CodeInvalidArgument, "Invalid login request"))
+		return
+	}
+	role, ok := auth.users.authenticate(req.Username, req.Password)
+	if !ok {
+		// The same message for unknown users and wrong passwords
+		respondWithError(w, errorsx.New(errorsx.CodeUnauthenticated, "Invalid username or password"))
+		return
+	}
+
+	claims := jwt.NewClaims(req.Username, auth.now(), auth.ttl)
+	claims.Role = string(role)
+	token, err := jwt.Sign(claims, auth.key)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, LoginResponse{
+		Token:     token,
+		TokenType: "Bearer",
+		ExpiresIn: int(auth.ttl / time.Second),
+	})
+}
+
+// claimsKey is the context key for the claims of an authenticated request
+type claimsKey struct{}
+
+// ClaimsFromContext returns the token claims authMiddleware stored in ctx
+func ClaimsFromContext(ctx context.Context) (jwt.Claims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(jwt.Claims)
+	return claims, ok
+}
+
+// authMiddleware rejects requests without a valid "Authorization: Bearer
+// <token>" header and passes the token's claims to next in the request
+// context
+func authMiddleware(auth *tokenAuth) Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || token == "" {
+				unauthorized(w, "Missing bearer token")
+				return
+			}
+			claims, err := jwt.Verify(token, auth.key, auth.now())
+			if err != nil {
+				// Expired tokens get their own message so clients know to
+				// log in again; anything else could be probing
+				msg := "Invalid token"
+				if errors.Is(err, jwt.ErrExpired) {
+					msg = "Token expired"
+				}
+				unauthorized(w, msg)
+				return
+			}
+			next(w, r.WithContext(context.WithValue(r.Context(), claimsKey{}, claims)))
+		}
+	}
+}
+
+// unauthorized writes a 401 with the WWW-Authenticate header RFC 9110
+// requires on that status
+func unauthorized(w http.ResponseWriter, msg string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="books"`)
+	respondWithError(w, errorsx.New(errorsx.CodeUnauthenticated, msg))
+}
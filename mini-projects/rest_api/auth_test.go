@@ -0,0 +1,227 @@
+package restapi
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/errorsx"
+	"github.com/rehan/go-interview-prep/pkg/jwt"
+)
+
+var authTestSecret = strings.Repeat("s", jwt.MinKeySize)
+
+// testAuth returns a tokenAuth with one account, an empty API key store
+// and a clock the test moves by assigning to *now
+func testAuth(t *testing.T) (*tokenAuth, *time.Time) {
+	t.Helper()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	auth := newTokenAuth(newUserStore(map[string]Account{"alice": {Password: "wonderland", Role: RoleAdmin}}), authTestSecret, time.Hour)
+	auth.now = func() time.Time { return now }
+	auth.keys = NewAPIKeyStore(NewMemoryAPIKeyRepository())
+	auth.keys.now = auth.now
+	return auth, &now
+}
+
+func login(t *testing.T, handler http.Handler, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(body)))
+	return rr
+}
+
+func TestLogin(t *testing.T) {
+	auth, now := testAuth(t)
+	router := newRouter(NewBookStore(), auth, slog.New(slog.NewTextHandler(io.Discard, nil)), nil, nil, nil, nil, nil, nil, nil, nil)
+
+	rr := login(t, router, `{"username":"alice","password":"wonderland"}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d; want 200 (body: %s)", rr.Code, rr.Body.String())
+	}
+	var resp LoginResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.TokenType != "Bearer" || resp.ExpiresIn != 3600 {
+		t.Errorf("response = %+v; want a Bearer token expiring in 3600s", resp)
+	}
+	claims, err := jwt.Verify(resp.Token, auth.key, *now)
+	if err != nil || claims.Subject != "alice" || claims.Role != "admin" || claims.ExpiresAt != now.Add(time.Hour).Unix() {
+		t.Errorf("token claims = %+v, %v; want alice as admin, expiring in an hour", claims, err)
+	}
+}
+
+func TestLogin_Rejected(t *testing.T) {
+	auth, _ := testAuth(t)
+	router := newRouter(NewBookStore(), auth, slog.New(slog.NewTextHandler(io.Discard, nil)), nil, nil, nil, nil, nil, nil, nil, nil)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantDetail string
+	}{
+		{"wrong password", `{"username":"alice","password":"looking-glass"}`, http.StatusUnauthorized, "Invalid username or password"},
+		{"unknown user", `{"username":"mallory","password":"wonderland"}`, http.StatusUnauthorized, "Invalid username or password"},
+		{"missing password", `{"username":"alice"}`, http.StatusBadRequest, "Invalid login request: password is required"},
+		{"malformed body", `{"username":`, http.StatusBadRequest, "Invalid request body: unexpected EOF"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := login(t, router, tc.body)
+			if rr.Code != tc.wantStatus {
+				t.Fatalf("status = %d; want %d", rr.Code, tc.wantStatus)
+			}
+			var p Problem
+			if err := json.NewDecoder(rr.Body).Decode(&p); err != nil {
+				t.Fatal(err)
+			}
+			if p.Detail != tc.wantDetail {
+				t.Errorf("detail = %q; want %q", p.Detail, tc.wantDetail)
+			}
+		})
+	}
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	auth, now := testAuth(t)
+	valid, err := jwt.Sign(jwt.NewClaims("alice", *now, time.Hour), auth.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := jwt.Sign(jwt.NewClaims("alice", *now, time.Hour), []byte(strings.Repeat("x", jwt.MinKeySize)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		header     string
+		advance    time.Duration
+		wantStatus int
+		wantDetail string
+	}{
+		{"valid token", "Bearer " + valid, 0, http.StatusOK, ""},
+		{"valid until the last second", "Bearer " + valid, time.Hour - time.Second, http.StatusOK, ""},
+		{"expired", "Bearer " + valid, time.Hour, http.StatusUnauthorized, "Token expired"},
+		{"wrong signature", "Bearer " + otherKey, 0, http.StatusUnauthorized, "Invalid token"},
+		{"garbage", "Bearer not.a.token", 0, http.StatusUnauthorized, "Invalid token"},
+		{"no header", "", 0, http.StatusUnauthorized, "Missing bearer token"},
+		{"wrong scheme", "Basic " + valid, 0, http.StatusUnauthorized, "Missing bearer token"},
+		{"empty token", "Bearer ", 0, http.StatusUnauthorized, "Missing bearer token"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			start := *now
+			*now = start.Add(tc.advance)
+			defer func() { *now = start }()
+
+			var gotSubject string
+			handler := applyMiddleware(func(w http.ResponseWriter, r *http.Request) {
+				claims, _ := ClaimsFromContext(r.Context())
+				gotSubject = claims.Subject
+			}, authMiddleware(auth))
+
+			req := httptest.NewRequest(http.MethodPost, "/books", nil)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+			rr := httptest.NewRecorder()
+			handler(rr, req)
+
+			if rr.Code != tc.wantStatus {
+				t.Fatalf("status = %d; want %d (body: %s)", rr.Code, tc.wantStatus, rr.Body.String())
+			}
+			if tc.wantStatus == http.StatusOK {
+				if gotSubject != "alice" {
+					t.Errorf("claims subject in context = %q; want alice", gotSubject)
+				}
+				return
+			}
+			if got := rr.Header().Get("WWW-Authenticate"); !strings.HasPrefix(got, "Bearer") {
+				t.Errorf("WWW-Authenticate = %q; want a Bearer challenge", got)
+			}
+			var p Problem
+			if err := json.NewDecoder(rr.Body).Decode(&p); err != nil {
+				t.Fatal(err)
+			}
+			if p.Code != errorsx.CodeUnauthenticated || p.Detail != tc.wantDetail {
+				t.Errorf("problem = %+v; want unauthenticated %q", p, tc.wantDetail)
+			}
+		})
+	}
+}
+
+// Reading stays public; each mutation needs a token
+func TestRouter_MutationsNeedToken(t *testing.T) {
+	auth, _ := testAuth(t)
+	router := newRouter(NewBookStore(), auth, slog.New(slog.NewTextHandler(io.Discard, nil)), nil, nil, nil, nil, nil, nil, nil, nil)
+
+	rr := login(t, router, `{"username":"alice","password":"wonderland"}`)
+	var resp LoginResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+
+	book := `{"title":"T","author":"A","price":1}`
+	tests := []struct {
+		method, path, body string
+		wantWithout        int
+		wantWith           int
+	}{
+		{http.MethodGet, "/books", "", http.StatusOK, http.StatusOK},
+		{http.MethodGet, "/books/1", "", http.StatusOK, http.StatusOK},
+		{http.MethodGet, "/books/html", "", http.StatusOK, http.StatusOK},
+		{http.MethodPost, "/books", book, http.StatusUnauthorized, http.StatusCreated},
+		{http.MethodPut, "/books/1", book, http.StatusUnauthorized, http.StatusOK},
+		{http.MethodDelete, "/books/2", "", http.StatusUnauthorized, http.StatusNoContent},
+	}
+	for _, tc := range tests {
+		for _, withToken := range []bool{false, true} {
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			want := tc.wantWithout
+			if withToken {
+				req.Header.Set("Authorization", "Bearer "+resp.Token)
+				want = tc.wantWith
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+			if rr.Code != want {
+				t.Errorf("%s %s (token %v): status = %d; want %d", tc.method, tc.path, withToken, rr.Code, want)
+			}
+		}
+	}
+}
+
+func TestUserStore_Authenticate(t *testing.T) {
+	users := newUserStore(map[string]Account{"alice": {Password: "wonderland", Role: RoleEditor}})
+	tests := []struct {
+		user, password string
+		wantRole       Role
+		wantOK         bool
+	}{
+		{"alice", "wonderland", RoleEditor, true},
+		{"alice", "Wonderland", "", false},
+		{"alice", "", "", false},
+		{"bob", "wonderland", "", false},
+		{"", "", "", false},
+	}
+	for _, tc := range tests {
+		if role, ok := users.authenticate(tc.user, tc.password); role != tc.wantRole || ok != tc.wantOK {
+			t.Errorf("authenticate(%q, %q) = %q, %v; want %q, %v", tc.user, tc.password, role, ok, tc.wantRole, tc.wantOK)
+		}
+	}
+}
+
+func TestNewTokenAuth_RandomKeyWithoutSecret(t *testing.T) {
+	a := newTokenAuth(newUserStore(nil), "", time.Hour)
+	b := newTokenAuth(newUserStore(nil), "", time.Hour)
+	if len(a.key) != jwt.MinKeySize || string(a.key) == string(b.key) {
+		t.Errorf("keys %x and %x; want distinct random %d-byte keys", a.key, b.key, jwt.MinKeySize)
+	}
+}
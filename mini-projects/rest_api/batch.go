@@ -0,0 +1,228 @@
+package restapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/rehan/go-interview-prep/pkg/errorsx"
+	"github.com/rehan/go-interview-prep/pkg/ndjson"
+	"github.com/rehan/go-interview-prep/pkg/validator"
+)
+
+// mediaNDJSON is newline-delimited JSON: one book per line, so a client
+// can stream a batch without building one large array
+const mediaNDJSON = "application/x-ndjson"
+
+// maxBatchItems bounds one batch. Books are read one at a time, but every
+// item gets a result, and an atomic batch holds its books in a transaction
+// until the end.
+const maxBatchItems = 10000
+
+// BatchResult is the response to POST /books/batch
+type BatchResult struct {
+	Atomic  bool              `json:"atomic"`
+	Created int               `json:"created"`
+	Failed  int               `json:"failed"`
+	Results []BatchItemResult `json:"results"`
+}
+
+// BatchItemResult reports one book of a batch, by its position in the
+// request. Status is 201 with the created book, or an error status with
+// a problem describing why the book was rejected. In an atomic batch that
+// failed, the books that were valid have status 424 Failed Dependency:
+// they were not created because another book was rejected.
+type BatchItemResult struct {
+	Index  int      `json:"index"`
+	Status int      `json:"status"`
+	Book   *Book    `json:"book,omitempty"`
+	Error  *Problem `json:"error,omitempty"`
+}
+
+// handleBatchCreateBooks handles POST /books/batch. The body is a JSON
+// array of books, or NDJSON when the Content-Type says so. By default each
+// valid book is created as it is read and invalid ones are reported
+// without stopping the batch; with ?atomic=true the books are created in a
+// transaction, which is rolled back unless every book is valid.
+func handleBatchCreateBooks(w http.ResponseWriter, r *http.Request, store BookRepository) {
+	atomic := false
+	if v := r.URL.Query().Get("atomic"); v != "" {
+		var err error
+		if atomic, err = strconv.ParseBool(v); err != nil {
+			respondWithError(w, errorsx.Wrap(err, errorsx.CodeInvalidArgument, "atomic must be true or false"))
+			return
+		}
+	}
+
+	var tx Tx
+	if atomic {
+		var err error
+		if tx, err = store.Begin(); err != nil {
+			respondWithError(w, err)
+			return
+		}
+		defer tx.Rollback()
+		store = tx
+	}
+
+	requestID := w.Header().Get(requestIDHeader)
+	items := newBatchReader(r)
+	result := BatchResult{Atomic: atomic, Results: []BatchItemResult{}}
+	for i := 0; ; i++ {
+		book, ok, err := items.next()
+		if !ok && err == nil {
+			break
+		}
+		if err == nil {
+			if verr := validator.Struct(book); verr != nil {
+				err = errorsx.Wrap(verr, errorsx.CodeInvalidArgument, "Invalid book data")
+			}
+		}
+		if err != nil {
+			if !ok && i == 0 {
+				// Nothing was read, so this is an error in the request
+				// as a whole rather than in one of its books
+				respondWithError(w, err)
+				return
+			}
+			problem := newProblem(err, requestID)
+			result.Results = append(result.Results, BatchItemResult{Index: i, Status: problem.Status, Error: &problem})
+			result.Failed++
+			if !ok {
+				break
+			}
+			continue
+		}
+
+		created, _ := store.GetBook(store.AddBook(book))
+		result.Results = append(result.Results, BatchItemResult{Index: i, Status: http.StatusCreated, Book: &created})
+		result.Created++
+	}
+
+	if !atomic {
+		respondWithJSON(w, http.StatusOK, result)
+		return
+	}
+	if result.Failed > 0 {
+		// The deferred Rollback discards the books created so far
+		for i := range result.Results {
+			if result.Results[i].Status == http.StatusCreated {
+				result.Results[i].Status = http.StatusFailedDependency
+				result.Results[i].Book = nil
+			}
+		}
+		result.Created = 0
+		respondWithJSON(w, http.StatusBadRequest, result)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, result)
+}
+
+// batchReader reads the books of a batch one at a time, from either a
+// JSON array or NDJSON, without holding the whole body in memory
+type batchReader struct {
+	ctx     context.Context
+	lines   *ndjson.Decoder // NDJSON
+	dec     *json.Decoder   // JSON array
+	started bool
+	n       int
+}
+
+func newBatchReader(r *http.Request) *batchReader {
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == mediaNDJSON {
+		return &batchReader{ctx: r.Context(), lines: ndjson.NewDecoder(r.Body)}
+	}
+	return &batchReader{ctx: r.Context(), dec: json.NewDecoder(r.Body)}
+}
+
+// next returns the next book. ok is true if a book was read, with err
+// describing what was wrong with it, if anything; ok is false at the end
+// of the batch, with err set if the body could not be read to its end.
+func (br *batchReader) next() (book Book, ok bool, err error) {
+	if br.n == maxBatchItems {
+		if br.more() {
+			return Book{}, false, errorsx.Errorf(errorsx.CodeInvalidArgument, "A batch holds at most %d books", maxBatchItems)
+		}
+		return Book{}, false, nil
+	}
+	if br.lines != nil {
+		return br.nextLine()
+	}
+	return br.nextElement()
+}
+
+// more reports whether another book follows, for the size check
+func (br *batchReader) more() bool {
+	if br.lines != nil {
+		return br.lines.More()
+	}
+	return br.dec.More()
+}
+
+// nextLine reads an NDJSON line, skipping blank ones. A malformed line
+// fails only that book, since the next line starts afresh; the problem
+// names the line, as the index does not count blank ones.
+func (br *batchReader) nextLine() (Book, bool, error) {
+	var book Book
+	err := br.lines.Decode(br.ctx, &book)
+	var lineErr *ndjson.LineError
+	switch {
+	case errors.Is(err, io.EOF):
+		return Book{}, false, nil
+	case errors.As(err, &lineErr):
+		br.n++
+		return Book{}, true, errorsx.Wrap(lineErr.Err, errorsx.CodeInvalidArgument, fmt.Sprintf("Invalid book on line %d", lineErr.Line))
+	case err != nil:
+		return Book{}, false, errorsx.Wrap(err, errorsx.CodeInvalidArgument, "Invalid request body")
+	}
+	br.n++
+	return book, true, nil
+}
+
+// nextElement reads the next element of the JSON array. An element of the
+// wrong shape, such as a string price, fails only that book; a syntax
+// error ends the batch, as there is no telling where the next book starts.
+func (br *batchReader) nextElement() (Book, bool, error) {
+	if !br.started {
+		br.started = true
+		if tok, err := br.dec.Token(); err != nil || tok != json.Delim('[') {
+			return Book{}, false, errorsx.New(errorsx.CodeInvalidArgument,
+				"Request body must be a JSON array of books, or one book per line with Content-Type "+mediaNDJSON)
+		}
+	}
+	if !br.dec.More() {
+		if _, err := br.dec.Token(); err != nil {
+			return Book{}, false, errorsx.Wrap(unexpectedEOF(err), errorsx.CodeInvalidArgument, "Invalid request body")
+		}
+		return Book{}, false, nil
+	}
+	br.n++
+	// Reading the element whole first separates the errors that leave the
+	// decoder lost from those that only concern this book
+	var raw json.RawMessage
+	if err := br.dec.Decode(&raw); err != nil {
+		return Book{}, false, errorsx.Wrap(unexpectedEOF(err), errorsx.CodeInvalidArgument, "Invalid request body")
+	}
+	var book Book
+	if err := json.Unmarshal(raw, &book); err != nil {
+		return Book{}, true, errorsx.Wrap(err, errorsx.CodeInvalidArgument, "Invalid request body")
+	}
+	return book, true, nil
+}
+
+// unexpectedEOF reports a body that ends inside the array as truncated
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
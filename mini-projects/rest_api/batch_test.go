@@ -0,0 +1,257 @@
+package restapi
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/rehan/go-interview-prep/pkg/errorsx"
+)
+
+// batchRouter returns the store and a function posting body to path as
+// the admin, with contentType if it is not empty
+func batchRouter(t *testing.T) (*BookStore, func(path, contentType, body string) *httptest.ResponseRecorder) {
+	t.Helper()
+	auth, _ := testAuth(t)
+	store := NewBookStore()
+	router := newRouter(store, auth, slog.New(slog.NewTextHandler(io.Discard, nil)), nil, nil, nil, nil, nil, nil, nil, nil)
+
+	var lr LoginResponse
+	if err := json.NewDecoder(login(t, router, `{"username":"alice","password":"wonderland"}`).Body).Decode(&lr); err != nil {
+		t.Fatal(err)
+	}
+	post := func(path, contentType, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+lr.Token)
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+	return store, post
+}
+
+func decodeBatch(t *testing.T, rr *httptest.ResponseRecorder) BatchResult {
+	t.Helper()
+	var result BatchResult
+	if err := json.NewDecoder(rr.Body).Decode(&result); err != nil {
+		t.Fatalf("decoding batch result: %v", err)
+	}
+	return result
+}
+
+// statuses returns the status of each item result
+func statuses(result BatchResult) []int {
+	var got []int
+	for _, item := range result.Results {
+		got = append(got, item.Status)
+	}
+	return got
+}
+
+const (
+	validBook   = `{"title":"Learning Go","author":"Jon Bodner","price":29.99}`
+	invalidBook = `{"title":"","author":"Nobody","price":5}`
+	wrongShape  = `{"title":"T","author":"A","price":"cheap"}`
+)
+
+func TestBatchCreate_PartialFailure(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        []int
+	}{
+		{"array", "application/json",
+			"[" + validBook + "," + invalidBook + "," + wrongShape + "," + validBook + "]",
+			[]int{201, 400, 400, 201}},
+		{"ndjson", "application/x-ndjson; charset=utf-8",
+			validBook + "\n" + invalidBook + "\n\n{not json\n" + validBook + "\n",
+			[]int{201, 400, 400, 201}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			store, post := batchRouter(t)
+			rr := post("/books/batch", tc.contentType, tc.body)
+			if rr.Code != http.StatusOK {
+				t.Fatalf("status = %d; want 200 (body: %s)", rr.Code, rr.Body.String())
+			}
+			result := decodeBatch(t, rr)
+			if got := statuses(result); !slices.Equal(got, tc.want) {
+				t.Errorf("statuses = %v; want %v", got, tc.want)
+			}
+			if result.Atomic || result.Created != 2 || result.Failed != 2 {
+				t.Errorf("summary = atomic %v, created %d, failed %d; want false, 2, 2", result.Atomic, result.Created, result.Failed)
+			}
+			for i, item := range result.Results {
+				if item.Index != i {
+					t.Errorf("result %d has index %d", i, item.Index)
+				}
+			}
+			if len(store.GetBooks()) != 5 {
+				t.Errorf("store has %d books; want the 3 samples and 2 created", len(store.GetBooks()))
+			}
+
+			created := result.Results[3].Book
+			if got, ok := store.GetBook(created.ID); !ok || got.Title != "Learning Go" {
+				t.Errorf("created book %d = %+v, %v; want it in the store", created.ID, got, ok)
+			}
+			invalid := result.Results[1].Error
+			if invalid.Code != errorsx.CodeInvalidArgument || len(invalid.Errors) != 1 || invalid.Errors[0].Field != "title" {
+				t.Errorf("invalid book problem = %+v; want a title field error", invalid)
+			}
+			if tc.name == "ndjson" && !strings.HasPrefix(result.Results[2].Error.Detail, "Invalid book on line 4") {
+				t.Errorf("malformed line problem = %+v; want it to name line 4, blank lines counted", result.Results[2].Error)
+			}
+		})
+	}
+}
+
+func TestBatchCreate_Atomic(t *testing.T) {
+	t.Run("rejects all", func(t *testing.T) {
+		store, post := batchRouter(t)
+		rr := post("/books/batch?atomic=true", "", "["+validBook+","+invalidBook+","+validBook+"]")
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("status = %d; want 400 (body: %s)", rr.Code, rr.Body.String())
+		}
+		result := decodeBatch(t, rr)
+		if got, want := statuses(result), []int{424, 400, 424}; !slices.Equal(got, want) {
+			t.Errorf("statuses = %v; want %v", got, want)
+		}
+		if result.Created != 0 || result.Failed != 1 || result.Results[0].Book != nil {
+			t.Errorf("result = %+v; want nothing created", result)
+		}
+		if len(store.GetBooks()) != 3 {
+			t.Errorf("store has %d books; want only the 3 samples", len(store.GetBooks()))
+		}
+	})
+
+	t.Run("creates all", func(t *testing.T) {
+		store, post := batchRouter(t)
+		rr := post("/books/batch?atomic=1", mediaNDJSON, validBook+"\n"+validBook)
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("status = %d; want 201 (body: %s)", rr.Code, rr.Body.String())
+		}
+		result := decodeBatch(t, rr)
+		if got, want := statuses(result), []int{201, 201}; !slices.Equal(got, want) {
+			t.Errorf("statuses = %v; want %v", got, want)
+		}
+		if !result.Atomic || result.Created != 2 || result.Results[1].Book.ID != 5 {
+			t.Errorf("result = %+v; want books 4 and 5 created", result)
+		}
+		if len(store.GetBooks()) != 5 {
+			t.Errorf("store has %d books; want 5", len(store.GetBooks()))
+		}
+	})
+}
+
+// An atomic batch creates its books in a transaction as it reads them, so
+// one failing midway rolls back those before it: they are neither stored
+// nor recorded in the outbox
+func TestBatchCreate_AtomicRollsBackMidBatch(t *testing.T) {
+	auth, _ := testAuth(t)
+	store := NewBookStore()
+	outbox := NewOutbox()
+	router := newRouter(store, auth, slog.New(slog.NewTextHandler(io.Discard, nil)), nil, nil, nil, nil, nil, outbox, nil, nil)
+	token := adminToken(t, router)
+
+	send := func(body string) *httptest.ResponseRecorder {
+		return sendWithSession(router, http.MethodPost, "/books/batch?atomic=true", body, nil, "Authorization", "Bearer "+token, "Content-Type", mediaNDJSON)
+	}
+	if rr := send(validBook + "\n" + validBook + "\n" + invalidBook + "\n" + validBook); rr.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d; want 400 (body: %s)", rr.Code, rr.Body.String())
+	}
+	if n := len(store.GetBooks()); n != 3 {
+		t.Errorf("store has %d books; want the 3 samples", n)
+	}
+	if n := len(outbox.Pending()); n != 0 {
+		t.Errorf("outbox has %d changes; want none", n)
+	}
+
+	if rr := send(validBook + "\n" + validBook); rr.Code != http.StatusCreated {
+		t.Fatalf("status = %d; want 201 (body: %s)", rr.Code, rr.Body.String())
+	}
+	if n := len(outbox.Pending()); n != 2 {
+		t.Errorf("outbox has %d changes; want one per book", n)
+	}
+}
+
+func TestBatchCreate_TruncatedArray(t *testing.T) {
+	// Books before a syntax error stand; nothing after it can be read
+	store, post := batchRouter(t)
+	rr := post("/books/batch", "", "["+validBook+","+validBook+`,{"title":`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d; want 200 (body: %s)", rr.Code, rr.Body.String())
+	}
+	result := decodeBatch(t, rr)
+	if got, want := statuses(result), []int{201, 201, 400}; !slices.Equal(got, want) {
+		t.Errorf("statuses = %v; want %v", got, want)
+	}
+	if !strings.Contains(result.Results[2].Error.Detail, "unexpected EOF") {
+		t.Errorf("detail = %q; want it to say the body was cut short", result.Results[2].Error.Detail)
+	}
+	if len(store.GetBooks()) != 5 {
+		t.Errorf("store has %d books; want 5", len(store.GetBooks()))
+	}
+
+	// In an atomic batch the same body creates nothing
+	store, post = batchRouter(t)
+	if rr := post("/books/batch?atomic=true", "", "["+validBook+`,{"title":`); rr.Code != http.StatusBadRequest {
+		t.Errorf("atomic status = %d; want 400", rr.Code)
+	}
+	if len(store.GetBooks()) != 3 {
+		t.Errorf("atomic batch left %d books; want 3", len(store.GetBooks()))
+	}
+}
+
+func TestBatchCreate_RejectedRequests(t *testing.T) {
+	tests := []struct {
+		name, path, body string
+	}{
+		{"not an array", "/books/batch", validBook},
+		{"empty body", "/books/batch", ""},
+		{"bad atomic flag", "/books/batch?atomic=maybe", "[]"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, post := batchRouter(t)
+			rr := post(tc.path, "", tc.body)
+			if rr.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d; want 400", rr.Code)
+			}
+			if ct := rr.Header().Get("Content-Type"); ct != problemContentType {
+				t.Errorf("Content-Type = %q; want a problem, not a batch result", ct)
+			}
+		})
+	}
+}
+
+func TestBatchCreate_Limit(t *testing.T) {
+	store, post := batchRouter(t)
+	body := strings.Repeat(validBook+"\n", maxBatchItems+1)
+	rr := post("/books/batch?atomic=true", mediaNDJSON, body)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d; want 400", rr.Code)
+	}
+	result := decodeBatch(t, rr)
+	last := result.Results[len(result.Results)-1]
+	if last.Index != maxBatchItems || last.Error == nil || !strings.Contains(last.Error.Detail, "at most") {
+		t.Errorf("last result = %+v; want the limit reported at index %d", last, maxBatchItems)
+	}
+	if len(store.GetBooks()) != 3 {
+		t.Errorf("store has %d books; want 3", len(store.GetBooks()))
+	}
+
+	// Exactly the limit is fine
+	rr = post("/books/batch", mediaNDJSON, strings.Repeat(validBook+"\n", maxBatchItems))
+	if result := decodeBatch(t, rr); result.Created != maxBatchItems || result.Failed != 0 {
+		t.Errorf("full batch created %d, failed %d; want %d, 0", result.Created, result.Failed, maxBatchItems)
+	}
+}
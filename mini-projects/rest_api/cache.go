@@ -0,0 +1,223 @@
+package restapi
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/clock"
+)
+
+// cachedResponse is a 200 response as a handler wrote it
+type cachedResponse struct {
+	header  http.Header
+	body    []byte
+	etag    string
+	expires time.Time
+}
+
+// responseCache keeps GET responses in memory for ttl. Books change
+// rarely and are read often, so most list requests are served without
+// sorting and encoding the whole store.
+type responseCache struct {
+	ttl   time.Duration
+	clock clock.Clock // clock.Real outside tests
+
+	mu      sync.Mutex
+	entries map[string]*cachedResponse
+}
+
+// defaultCacheTTL is how long entries live without withCacheTTL
+const defaultCacheTTL = 30 * time.Second
+
+// cacheOption configures a responseCache
+type cacheOption func(*responseCache)
+
+// withCacheTTL keeps entries for ttl
+func withCacheTTL(ttl time.Duration) cacheOption {
+	return func(c *responseCache) {
+		c.ttl = ttl
+	}
+}
+
+// withCacheClock expires entries by clk instead of the system clock, so
+// tests can move past the TTL with a clock.Fake
+func withCacheClock(clk clock.Clock) cacheOption {
+	return func(c *responseCache) {
+		c.clock = clk
+	}
+}
+
+// newResponseCache returns an empty cache configured by opts
+func newResponseCache(opts ...cacheOption) *responseCache {
+	c := &responseCache{ttl: defaultCacheTTL, clock: clock.Real, entries: make(map[string]*cachedResponse)}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+func (c *responseCache) get(key string) (*cachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	resp, ok := c.entries[key]
+	if !ok || !c.clock.Now().Before(resp.expires) {
+		return nil, false
+	}
+	return resp, true
+}
+
+// put stores resp, dropping expired entries on the way so the map does
+// not grow with keys that are never asked for again
+func (c *responseCache) put(key string, resp *cachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.clock.Now()
+	for k, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, k)
+		}
+	}
+	resp.expires = now.Add(c.ttl)
+	c.entries[key] = resp
+}
+
+// Invalidate empties the cache
+func (c *responseCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.entries)
+}
+
+// cacheKey identifies a response. The Accept header is part of it because
+// GET /books varies on it; Accept-Encoding is not, because the cache sits
+// inside gzipMiddleware and stores bodies uncompressed.
+func cacheKey(r *http.Request) string {
+	return r.URL.RequestURI() + "\x00" + r.Header.Get("Accept")
+}
+
+// etagOf returns a strong ETag derived from body
+func etagOf(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + base64.RawURLEncoding.EncodeToString(sum[:12]) + `"`
+}
+
+// etagMatches reports whether an If-None-Match header names etag. The
+// comparison is weak, as RFC 9110 requires for If-None-Match, so a W/
+// prefix is ignored.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// bufferedResponse captures what a handler writes
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header { return b.header }
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+	return b.body.Write(p)
+}
+
+func (b *bufferedResponse) WriteHeader(code int) {
+	if b.status == 0 {
+		b.status = code
+	}
+}
+
+// storable reports whether a response with header may be cached. Handlers
+// whose responses depend on who asked opt out with Cache-Control: no-store
+// or private.
+func storable(header http.Header) bool {
+	cc := header.Get("Cache-Control")
+	return !strings.Contains(cc, "no-store") && !strings.Contains(cc, "private")
+}
+
+// cacheMiddleware serves GET requests from c, marking responses with
+// X-Cache: HIT or MISS. Only storable 200 responses are stored. Every 200, cached
+// or not, gets an ETag, and a request whose If-None-Match names it gets
+// 304 Not Modified with no body. A nil cache disables caching.
+func cacheMiddleware(c *responseCache) Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		if c == nil {
+			return next
+		}
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				next(w, r)
+				return
+			}
+			key := cacheKey(r)
+			if resp, ok := c.get(key); ok {
+				w.Header().Set("X-Cache", "HIT")
+				serveCached(w, r, resp)
+				return
+			}
+
+			// The handler sees the headers outer middleware already set,
+			// such as X-Request-ID, which respondWithError reads
+			outer := w.Header().Clone()
+			buf := &bufferedResponse{header: outer.Clone()}
+			next(buf, r)
+			if buf.status == 0 {
+				// A handler that writes nothing has answered 200, as
+				// net/http would send for it
+				buf.status = http.StatusOK
+			}
+			for k, v := range buf.header {
+				w.Header()[k] = v
+			}
+			w.Header().Set("X-Cache", "MISS")
+			if buf.status != http.StatusOK || !storable(buf.header) {
+				w.WriteHeader(buf.status)
+				w.Write(buf.body.Bytes())
+				return
+			}
+
+			resp := &cachedResponse{header: buf.header.Clone(), body: buf.body.Bytes(), etag: etagOf(buf.body.Bytes())}
+			// Those headers, such as X-Request-ID or a tenant's quota, are
+			// this request's, so a hit must not replay them
+			for k, v := range outer {
+				if slices.Equal(resp.header[k], v) {
+					resp.header.Del(k)
+				}
+			}
+			c.put(key, resp)
+			serveCached(w, r, resp)
+		}
+	}
+}
+
+// serveCached writes resp, or 304 if the client already has it
+func serveCached(w http.ResponseWriter, r *http.Request, resp *cachedResponse) {
+	for k, v := range resp.header {
+		w.Header()[k] = v
+	}
+	w.Header().Set("ETag", resp.etag)
+	if etagMatches(r.Header.Get("If-None-Match"), resp.etag) {
+		// A 304 has no body, so the headers describing one go
+		w.Header().Del("Content-Type")
+		w.Header().Del("Content-Length")
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(resp.body)
+}
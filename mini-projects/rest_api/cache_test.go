@@ -0,0 +1,179 @@
+package restapi
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/clock"
+)
+
+func TestEtagMatches(t *testing.T) {
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{`"abc"`, true},
+		{`W/"abc"`, true},
+		{`"x", "abc"`, true},
+		{`*`, true},
+		{`"abcd"`, false},
+		{`abc`, false},
+		{``, false},
+	}
+	for _, tc := range tests {
+		if got := etagMatches(tc.header, `"abc"`); got != tc.want {
+			t.Errorf("etagMatches(%q) = %v; want %v", tc.header, got, tc.want)
+		}
+	}
+}
+
+// cachedRouter returns a router with a cache on a fake clock, the store
+// behind it, and a way to send requests
+func cachedRouter(t *testing.T) (*responseCache, *clock.Fake, *BookStore, func(method, path, body string, header ...string) *httptest.ResponseRecorder) {
+	t.Helper()
+	auth, _ := testAuth(t)
+	c := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	cache := newResponseCache(withCacheTTL(time.Minute), withCacheClock(c))
+	store := NewBookStore()
+	outbox, flush := testChanges(t, cache, nil, nil)
+	router := flushing(t, newRouter(store, auth, slog.New(slog.NewTextHandler(io.Discard, nil)), nil, cache, nil, nil, nil, outbox, nil, nil), flush)
+
+	rr := login(t, router, `{"username":"alice","password":"wonderland"}`)
+	var lr LoginResponse
+	if err := json.NewDecoder(rr.Body).Decode(&lr); err != nil {
+		t.Fatal(err)
+	}
+	send := func(method, path, body string, header ...string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+lr.Token)
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+	return cache, c, store, send
+}
+
+func TestCacheMiddleware_HitMissExpire(t *testing.T) {
+	_, c, store, send := cachedRouter(t)
+
+	first := send(http.MethodGet, "/books", "")
+	if first.Header().Get("X-Cache") != "MISS" {
+		t.Errorf("first request X-Cache = %q; want MISS", first.Header().Get("X-Cache"))
+	}
+
+	// Changing the store behind the router's back shows the cache is used
+	store.AddBook(Book{Title: "Hidden", Author: "A", Price: 100})
+	second := send(http.MethodGet, "/books", "")
+	if second.Header().Get("X-Cache") != "HIT" || second.Body.String() != first.Body.String() {
+		t.Errorf("second request X-Cache = %q; want a HIT with the first body", second.Header().Get("X-Cache"))
+	}
+	if second.Header().Get("ETag") != first.Header().Get("ETag") || first.Header().Get("ETag") == "" {
+		t.Errorf("ETags %q and %q; want the same non-empty tag", first.Header().Get("ETag"), second.Header().Get("ETag"))
+	}
+	if second.Header().Get(requestIDHeader) == first.Header().Get(requestIDHeader) {
+		t.Error("cached response replayed the first request's X-Request-ID")
+	}
+
+	// The query and the Accept header are part of the key
+	if rr := send(http.MethodGet, "/books?limit=1", ""); rr.Header().Get("X-Cache") != "MISS" {
+		t.Errorf("different query X-Cache = %q; want MISS", rr.Header().Get("X-Cache"))
+	}
+	if rr := send(http.MethodGet, "/books", "", "Accept", "text/csv"); rr.Header().Get("X-Cache") != "MISS" || !strings.HasPrefix(rr.Header().Get("Content-Type"), mediaCSV) {
+		t.Errorf("CSV request X-Cache = %q, Content-Type %q; want a CSV MISS", rr.Header().Get("X-Cache"), rr.Header().Get("Content-Type"))
+	}
+
+	c.Advance(time.Minute)
+	expired := send(http.MethodGet, "/books", "")
+	if expired.Header().Get("X-Cache") != "MISS" || !strings.Contains(expired.Body.String(), "Hidden") {
+		t.Errorf("after the TTL X-Cache = %q; want a MISS showing the new book", expired.Header().Get("X-Cache"))
+	}
+}
+
+func TestCacheMiddleware_InvalidatedByMutations(t *testing.T) {
+	book := `{"title":"T","author":"A","price":1}`
+	tests := []struct {
+		name, method, path, body string
+		wantInvalidated          bool
+	}{
+		{"create", http.MethodPost, "/books", book, true},
+		{"update", http.MethodPut, "/books/1", book, true},
+		{"delete", http.MethodDelete, "/books/1", "", true},
+		{"failed update", http.MethodPut, "/books/999", book, false},
+		{"failed delete", http.MethodDelete, "/books/999", "", false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, _, send := cachedRouter(t)
+			send(http.MethodGet, "/books", "")
+			send(http.MethodGet, "/books/1", "")
+
+			send(tc.method, tc.path, tc.body)
+
+			for _, path := range []string{"/books", "/books/1"} {
+				got := send(http.MethodGet, path, "").Header().Get("X-Cache")
+				if want := map[bool]string{true: "MISS", false: "HIT"}[tc.wantInvalidated]; got != want {
+					t.Errorf("GET %s after %s: X-Cache = %q; want %q", path, tc.name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCacheMiddleware_ConditionalGet(t *testing.T) {
+	_, _, _, send := cachedRouter(t)
+
+	etag := send(http.MethodGet, "/books/2", "").Header().Get("ETag")
+	for _, cached := range []string{"MISS", "HIT"} {
+		if cached == "MISS" {
+			send(http.MethodPut, "/books/1", `{"title":"T","author":"A","price":1}`) // empties the cache
+		}
+		rr := send(http.MethodGet, "/books/2", "", "If-None-Match", etag)
+		if rr.Code != http.StatusNotModified || rr.Body.Len() != 0 {
+			t.Errorf("If-None-Match on a %s: %d with %d body bytes; want an empty 304", cached, rr.Code, rr.Body.Len())
+		}
+		if rr.Header().Get("ETag") != etag {
+			t.Errorf("304 ETag = %q; want %q", rr.Header().Get("ETag"), etag)
+		}
+	}
+
+	send(http.MethodPut, "/books/2", `{"title":"Changed","author":"A","price":1}`)
+	rr := send(http.MethodGet, "/books/2", "", "If-None-Match", etag)
+	if rr.Code != http.StatusOK || rr.Header().Get("ETag") == etag {
+		t.Errorf("after a change: %d with ETag %q; want 200 with a new ETag", rr.Code, rr.Header().Get("ETag"))
+	}
+}
+
+func TestCacheMiddleware_SkipsErrorsAndProtectedRoutes(t *testing.T) {
+	_, _, _, send := cachedRouter(t)
+
+	for range 2 {
+		rr := send(http.MethodGet, "/books/999", "")
+		if rr.Code != http.StatusNotFound || rr.Header().Get("X-Cache") != "MISS" || rr.Header().Get("ETag") != "" {
+			t.Errorf("404: X-Cache %q, ETag %q; want an uncached MISS without ETag", rr.Header().Get("X-Cache"), rr.Header().Get("ETag"))
+		}
+	}
+	if rr := send(http.MethodGet, "/admin/keys", ""); rr.Header().Get("X-Cache") != "" {
+		t.Errorf("GET /admin/keys X-Cache = %q; want the route uncached", rr.Header().Get("X-Cache"))
+	}
+}
+
+func TestCacheMiddleware_EmptyResponse(t *testing.T) {
+	h := cacheMiddleware(newResponseCache())(func(w http.ResponseWriter, r *http.Request) {})
+	for _, want := range []string{"MISS", "HIT"} {
+		rr := httptest.NewRecorder()
+		h(rr, httptest.NewRequest(http.MethodGet, "/empty", nil))
+		if rr.Code != http.StatusOK || rr.Body.Len() != 0 || rr.Header().Get("X-Cache") != want {
+			t.Errorf("got %d, %d bytes, X-Cache %q; want an empty 200 %s", rr.Code, rr.Body.Len(), rr.Header().Get("X-Cache"), want)
+		}
+	}
+}
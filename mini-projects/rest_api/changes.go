@@ -0,0 +1,92 @@
+package restapi
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/dispatch"
+	"github.com/rehan/go-interview-prep/pkg/pubsub"
+)
+
+// BookChange is the domain event for one change to the books: a book
+// created, updated or deleted, as Type says. Before is not set for
+// creations, nor After for deletions.
+type BookChange struct {
+	Type      string // EventBookCreated, EventBookUpdated or EventBookDeleted
+	ID        int
+	Before    *Book
+	After     *Book
+	Actor     string
+	RequestID string
+	Time      time.Time
+}
+
+// Retries of a failing change handler, such as the audit log on a full
+// disk. After the last attempt the change is logged and skipped.
+var changeDispatchOptions = dispatch.Options{
+	MaxAttempts: 5,
+	Backoff:     100 * time.Millisecond,
+	MaxBackoff:  5 * time.Second,
+}
+
+// newChangeDispatcher returns the dispatcher book changes are emitted on,
+// with a handler for each of cache, events and audit that is not nil.
+// They run in the background, each in its own goroutine, so a request
+// does not wait for them, and a cached list may be stale for the moment
+// between a change and its invalidation.
+func newChangeDispatcher(cache *responseCache, events *pubsub.Bus[BookEvent], audit *AuditLog) *dispatch.Dispatcher[BookChange] {
+	opts := changeDispatchOptions
+	opts.OnError = func(f dispatch.Failure) {
+		if f.GaveUp {
+			slog.Error("book change handler gave up", "handler", f.Handler, "attempts", f.Attempt, "error", f.Err)
+			return
+		}
+		slog.Warn("book change handler failed", "handler", f.Handler, "attempt", f.Attempt, "error", f.Err)
+	}
+	d := dispatch.New[BookChange](opts)
+	if cache != nil {
+		d.Subscribe("cache", invalidateOnChange(cache))
+	}
+	if events != nil {
+		d.Subscribe("events", publishChange(events))
+	}
+	if audit != nil {
+		d.Subscribe("audit", auditChange(audit))
+	}
+	return d
+}
+
+// invalidateOnChange empties the response cache after every change
+func invalidateOnChange(cache *responseCache) dispatch.Handler[BookChange] {
+	return func(ctx context.Context, c BookChange) error {
+		cache.Invalidate()
+		return nil
+	}
+}
+
+// publishChange sends each change to the event stream and WebSocket
+// clients. The dispatcher delivers changes in the order they were
+// emitted, so event IDs follow that order.
+func publishChange(events *pubsub.Bus[BookEvent]) dispatch.Handler[BookChange] {
+	return func(ctx context.Context, c BookChange) error {
+		events.Publish(BookEvent{Type: c.Type, ID: c.ID, Book: c.After})
+		return nil
+	}
+}
+
+// auditChange records each change in the audit log. An entry that could
+// not be appended was not kept, so retrying does not duplicate it.
+func auditChange(audit *AuditLog) dispatch.Handler[BookChange] {
+	return func(ctx context.Context, c BookChange) error {
+		// A nil *Book encodes as null, which diffJSON reads as no fields
+		entry, err := newAuditEntry(c.Type, AuditBook, strconv.Itoa(c.ID), c.Before, c.After)
+		if err != nil {
+			return err
+		}
+		entry.Time, entry.Actor, entry.RequestID = c.Time, c.Actor, c.RequestID
+		_, err = audit.Append(entry)
+		return err
+	}
+}
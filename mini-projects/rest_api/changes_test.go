@@ -0,0 +1,157 @@
+package restapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/dispatch"
+	"github.com/rehan/go-interview-prep/pkg/pubsub"
+)
+
+// testRelay returns an outbox relayed to changes until the test ends, and
+// a function that waits for the changes in it to be published and handled
+func testRelay(t *testing.T, changes *dispatch.Dispatcher[BookChange]) (*Outbox, func(context.Context) error) {
+	t.Helper()
+	outbox := NewOutbox()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		NewOutboxRelay(outbox, changes.Dispatch).Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	flush := func(ctx context.Context) error {
+		if err := outbox.Wait(ctx); err != nil {
+			return err
+		}
+		return changes.Flush(ctx)
+	}
+	return outbox, flush
+}
+
+// testChanges returns an outbox relayed to a change dispatcher for cache,
+// events and audit, both stopped when the test ends, and a function that
+// waits for the changes in it to be handled
+func testChanges(t *testing.T, cache *responseCache, events *pubsub.Bus[BookEvent], audit *AuditLog) (*Outbox, func(context.Context) error) {
+	t.Helper()
+	d := newChangeDispatcher(cache, events, audit)
+	t.Cleanup(func() { d.Close(context.Background()) })
+	return testRelay(t, d)
+}
+
+// flushing serves each request with h, then waits for the changes it made
+// to be handled, so tests can check their effects straight away
+func flushing(t *testing.T, h http.Handler, flush func(context.Context) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.ServeHTTP(w, r)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := flush(ctx); err != nil {
+			t.Errorf("book changes not handled: %v", err)
+		}
+	})
+}
+
+func TestBookChanges_Emitted(t *testing.T) {
+	auth, _ := testAuth(t)
+	changes := dispatch.New[BookChange](dispatch.Options{})
+	t.Cleanup(func() { changes.Close(context.Background()) })
+	got := make(chan BookChange, 10)
+	changes.Subscribe("test", func(ctx context.Context, c BookChange) error {
+		got <- c
+		return nil
+	})
+	outbox, flush := testRelay(t, changes)
+	router := newRouter(NewBookStore(), auth, slog.New(slog.NewTextHandler(io.Discard, nil)), nil, nil, nil, nil, nil, outbox, nil, nil)
+	bearer := http.Header{"Authorization": {"Bearer " + adminToken(t, router)}}
+
+	auditRequest(t, router, http.MethodPost, "/books", `{"title":"Learning Go","author":"Jon Bodner","price":29.99}`, bearer, http.StatusCreated)
+	auditRequest(t, router, http.MethodPut, "/books/4", `{"title":"Learning Go, 2nd ed.","author":"Jon Bodner","price":39.99}`, bearer, http.StatusOK)
+	auditRequest(t, router, http.MethodDelete, "/books/4", "", bearer, http.StatusNoContent)
+	auditRequest(t, router, http.MethodDelete, "/books/4", "", bearer, http.StatusNotFound)
+
+	tests := []struct {
+		typ           string
+		before, after string // titles; "" for no book
+	}{
+		{EventBookCreated, "", "Learning Go"},
+		{EventBookUpdated, "Learning Go", "Learning Go, 2nd ed."},
+		{EventBookDeleted, "Learning Go, 2nd ed.", ""},
+	}
+	title := func(b *Book) string {
+		if b == nil {
+			return ""
+		}
+		return b.Title
+	}
+	for _, tc := range tests {
+		select {
+		case c := <-got:
+			if c.Type != tc.typ || c.ID != 4 || title(c.Before) != tc.before || title(c.After) != tc.after {
+				t.Errorf("change = %s of %d from %q to %q; want %s of 4 from %q to %q", c.Type, c.ID, title(c.Before), title(c.After), tc.typ, tc.before, tc.after)
+			}
+			if c.Actor != "user:alice" || c.RequestID == "" || c.Time.IsZero() {
+				t.Errorf("change by %q, request %q at %v; want alice's request with a time", c.Actor, c.RequestID, c.Time)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no %s change within 5s", tc.typ)
+		}
+	}
+	flush(context.Background())
+	if len(got) != 0 {
+		t.Errorf("%d more changes; want none for the failed delete", len(got))
+	}
+}
+
+// failingWriter fails its first failures writes
+type failingWriter struct {
+	failures int
+	strings.Builder
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.failures > 0 {
+		w.failures--
+		return 0, errors.New("disk full")
+	}
+	return w.Builder.Write(p)
+}
+
+func (w *failingWriter) Close() error { return nil }
+
+// A change the audit log fails to record is retried, and the changes
+// after it wait, so the log keeps them in order
+func TestBookChanges_AuditRetried(t *testing.T) {
+	auth, _ := testAuth(t)
+	file := &failingWriter{failures: 2}
+	audit := NewAuditLog()
+	audit.w = file
+	outbox, flush := testChanges(t, nil, nil, audit)
+	router := newRouter(NewBookStore(), auth, slog.New(slog.NewTextHandler(io.Discard, nil)), nil, nil, nil, nil, audit, outbox, nil, nil)
+	bearer := http.Header{"Authorization": {"Bearer " + adminToken(t, router)}}
+
+	auditRequest(t, router, http.MethodPost, "/books", `{"title":"T","author":"A","price":1}`, bearer, http.StatusCreated)
+	auditRequest(t, router, http.MethodDelete, "/books/4", "", bearer, http.StatusNoContent)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := flush(ctx); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+
+	entries := audit.Entries(AuditFilter{})
+	if len(entries) != 2 || entries[0].Action != AuditDeleted || entries[1].Action != AuditCreated {
+		t.Fatalf("entries = %s; want the creation then the deletion", mustJSON(t, entries))
+	}
+	if lines := strings.Count(file.String(), "\n"); lines != 2 {
+		t.Errorf("file has %d lines; want each entry written once", lines)
+	}
+}
@@ -0,0 +1,181 @@
+package restapi
+
+import (
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/errorsx"
+)
+
+// errStoreUnavailable is the failure a chaosRepository injects
+var errStoreUnavailable = errorsx.New(errorsx.CodeUnavailable, "Book store unavailable; try again")
+
+// Fault is what a chaosRepository does to one call
+type Fault int
+
+const (
+	// FaultNone passes the call through
+	FaultNone Fault = iota
+
+	// FaultError fails the call before it reaches the store, which is
+	// left as it was
+	FaultError
+
+	// FaultPartial fails the call after the store has made the change,
+	// or some of it: AddBooks adds only the first half of the books. The
+	// caller sees an error for a change that happened, the failure that
+	// makes retrying a non-idempotent request unsafe. Reads fail as with
+	// FaultError.
+	FaultPartial
+)
+
+// ChaosConfig is how a chaosRepository misbehaves
+type ChaosConfig struct {
+	// Latency is added to every call, and up to Jitter more at random
+	Latency, Jitter time.Duration
+
+	// Schedule is the faults of the first calls, in order; calls after
+	// them get faults at the rates below
+	Schedule []Fault
+
+	// ErrorRate and PartialRate are the shares of calls, from 0 to 1,
+	// that get FaultError and FaultPartial
+	ErrorRate, PartialRate float64
+
+	// Seed makes the random faults and jitter repeatable; 0 picks one
+	Seed uint64
+}
+
+// chaosRepository wraps a BookRepository and injects latency and
+// failures, to test how callers cope with a store that is slow or down.
+// Its methods have no error results, so a failure is a panic with
+// errStoreUnavailable, which recoveryMiddleware answers with 503; Begin
+// returns the error instead.
+type chaosRepository struct {
+	BookRepository
+	cfg ChaosConfig
+
+	mu       sync.Mutex
+	rand     *rand.Rand
+	schedule []Fault
+
+	calls, injected atomic.Int64
+}
+
+// newChaosRepository wraps store with the faults cfg describes
+func newChaosRepository(store BookRepository, cfg ChaosConfig) *chaosRepository {
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+	return &chaosRepository{
+		BookRepository: store,
+		cfg:            cfg,
+		rand:           rand.New(rand.NewPCG(seed, seed)),
+		schedule:       append([]Fault(nil), cfg.Schedule...),
+	}
+}
+
+// Calls returns how many calls were made
+func (r *chaosRepository) Calls() int64 {
+	return r.calls.Load()
+}
+
+// Injected returns how many calls were given a fault
+func (r *chaosRepository) Injected() int64 {
+	return r.injected.Load()
+}
+
+// next sleeps for the call's latency and returns its fault
+func (r *chaosRepository) next() Fault {
+	r.calls.Add(1)
+	r.mu.Lock()
+	delay := r.cfg.Latency
+	if r.cfg.Jitter > 0 {
+		delay += time.Duration(r.rand.Int64N(int64(r.cfg.Jitter)))
+	}
+	var f Fault
+	if len(r.schedule) > 0 {
+		f, r.schedule = r.schedule[0], r.schedule[1:]
+	} else {
+		switch p := r.rand.Float64(); {
+		case p < r.cfg.ErrorRate:
+			f = FaultError
+		case p < r.cfg.ErrorRate+r.cfg.PartialRate:
+			f = FaultPartial
+		}
+	}
+	r.mu.Unlock()
+
+	time.Sleep(delay)
+	if f != FaultNone {
+		r.injected.Add(1)
+	}
+	return f
+}
+
+// read fails a read for any fault, as there is no change to half make
+func (r *chaosRepository) read() {
+	if r.next() != FaultNone {
+		panic(errStoreUnavailable)
+	}
+}
+
+// write runs change unless the fault is FaultError, and fails unless there
+// is no fault
+func (r *chaosRepository) write(change func()) {
+	f := r.next()
+	if f != FaultError {
+		change()
+	}
+	if f != FaultNone {
+		panic(errStoreUnavailable)
+	}
+}
+
+func (r *chaosRepository) GetBooks() []Book {
+	r.read()
+	return r.BookRepository.GetBooks()
+}
+
+func (r *chaosRepository) GetBook(id int) (Book, bool) {
+	r.read()
+	return r.BookRepository.GetBook(id)
+}
+
+func (r *chaosRepository) AddBook(book Book) (id int) {
+	r.write(func() { id = r.BookRepository.AddBook(book) })
+	return id
+}
+
+func (r *chaosRepository) AddBooks(books []Book) (ids []int) {
+	switch r.next() {
+	case FaultError:
+		panic(errStoreUnavailable)
+	case FaultPartial:
+		r.BookRepository.AddBooks(books[:len(books)/2])
+		panic(errStoreUnavailable)
+	}
+	return r.BookRepository.AddBooks(books)
+}
+
+func (r *chaosRepository) UpdateBook(id int, book Book) (ok bool) {
+	r.write(func() { ok = r.BookRepository.UpdateBook(id, book) })
+	return ok
+}
+
+func (r *chaosRepository) DeleteBook(id int) (ok bool) {
+	r.write(func() { ok = r.BookRepository.DeleteBook(id) })
+	return ok
+}
+
+// Begin fails for any fault; the transaction it returns, once begun, is
+// the store's own and has none
+func (r *chaosRepository) Begin() (Tx, error) {
+	if r.next() != FaultNone {
+		return nil, errStoreUnavailable
+	}
+	return r.BookRepository.Begin()
+}
@@ -0,0 +1,228 @@
+package restapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/clock"
+	"github.com/rehan/go-interview-prep/pkg/httpclient"
+)
+
+// storePanic runs fn and returns what it panicked with
+func storePanic(fn func()) (p any) {
+	defer func() { p = recover() }()
+	fn()
+	return nil
+}
+
+func TestChaosRepository_Faults(t *testing.T) {
+	store := NewBookStore()
+	before := len(store.GetBooks())
+	chaos := newChaosRepository(store, ChaosConfig{Schedule: []Fault{FaultError, FaultPartial, FaultPartial, FaultError}})
+	book := Book{Title: "Chaos Engineering", Author: "Casey Rosenthal"}
+
+	if p := storePanic(func() { chaos.AddBook(book) }); p != errStoreUnavailable {
+		t.Errorf("AddBook with FaultError panicked with %v; want errStoreUnavailable", p)
+	}
+	if n := len(store.GetBooks()); n != before {
+		t.Errorf("after FaultError the store has %d books; want %d, unchanged", n, before)
+	}
+	if p := storePanic(func() { chaos.AddBook(book) }); p != errStoreUnavailable {
+		t.Errorf("AddBook with FaultPartial panicked with %v; want errStoreUnavailable", p)
+	}
+	if n := len(store.GetBooks()); n != before+1 {
+		t.Errorf("after FaultPartial the store has %d books; want %d, the book added anyway", n, before+1)
+	}
+	if p := storePanic(func() { chaos.AddBooks([]Book{book, book, book, book}) }); p != errStoreUnavailable {
+		t.Errorf("AddBooks with FaultPartial panicked with %v; want errStoreUnavailable", p)
+	}
+	if n := len(store.GetBooks()); n != before+3 {
+		t.Errorf("after a partial AddBooks of 4 the store has %d books; want %d, half of them added", n, before+3)
+	}
+	if _, err := chaos.Begin(); err != errStoreUnavailable {
+		t.Errorf("Begin with FaultError = %v; want errStoreUnavailable", err)
+	}
+
+	// The schedule is used up, and the rates are zero
+	if p := storePanic(func() { chaos.GetBooks() }); p != nil {
+		t.Errorf("GetBooks after the schedule panicked with %v", p)
+	}
+	if c, i := chaos.Calls(), chaos.Injected(); c != 5 || i != 4 {
+		t.Errorf("Calls(), Injected() = %d, %d; want 5, 4", c, i)
+	}
+}
+
+func TestChaosRepository_SeededRates(t *testing.T) {
+	faults := func() []bool {
+		chaos := newChaosRepository(NewBookStore(), ChaosConfig{ErrorRate: 0.5, Seed: 7})
+		var failed []bool
+		for range 20 {
+			failed = append(failed, storePanic(func() { chaos.GetBook(1) }) != nil)
+		}
+		return failed
+	}
+	first := faults()
+	if !slices.Contains(first, true) || !slices.Contains(first, false) {
+		t.Errorf("faults at rate 0.5 = %v; want some calls to fail and some not", first)
+	}
+	if second := faults(); !slices.Equal(first, second) {
+		t.Errorf("the same seed gave faults %v, then %v", first, second)
+	}
+}
+
+func TestChaosRepository_Latency(t *testing.T) {
+	chaos := newChaosRepository(NewBookStore(), ChaosConfig{Latency: 20 * time.Millisecond, Jitter: 10 * time.Millisecond})
+	start := time.Now()
+	chaos.GetBooks()
+	if d := time.Since(start); d < 20*time.Millisecond {
+		t.Errorf("GetBooks took %v; want at least the 20ms latency", d)
+	}
+}
+
+// chaosServer serves the API over a chaosRepository and returns it with
+// the admin's token
+func chaosServer(t *testing.T, cfg ChaosConfig) (*httptest.Server, *chaosRepository, string) {
+	t.Helper()
+	auth, _ := testAuth(t)
+	chaos := newChaosRepository(NewBookStore(), cfg)
+	router := newRouter(chaos, auth, slog.New(slog.NewTextHandler(io.Discard, nil)), nil, nil, nil, nil, nil, nil, nil, nil)
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+	return srv, chaos, adminToken(t, router)
+}
+
+// recordStatuses records the status of each attempt a client makes, 0 for
+// one that got no response
+func recordStatuses(got *[]int) func(httpclient.Attempt) {
+	return func(a httpclient.Attempt) {
+		status := 0
+		if a.Response != nil {
+			status = a.Response.StatusCode
+		}
+		*got = append(*got, status)
+	}
+}
+
+func TestChaos_RetriesRideOutFailures(t *testing.T) {
+	srv, _, _ := chaosServer(t, ChaosConfig{Schedule: []Fault{FaultError, FaultError}})
+	var got []int
+	c := httpclient.New(httpclient.Options{MaxAttempts: 3, Backoff: time.Millisecond, OnResponse: recordStatuses(&got)})
+
+	resp, err := c.Get(context.Background(), srv.URL+"/books")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !slices.Equal(got, []int{503, 503, 200}) {
+		t.Errorf("status = %d after attempts %v; want 200 after 503, 503, 200", resp.StatusCode, got)
+	}
+}
+
+func TestChaos_BreakerFailsFast(t *testing.T) {
+	srv, chaos, _ := chaosServer(t, ChaosConfig{Schedule: []Fault{FaultError, FaultError, FaultError}})
+	clk := clock.NewFake(time.Now())
+	breaker := httpclient.NewBreaker(httpclient.BreakerConfig{Threshold: 3, Cooldown: time.Minute, Clock: clk})
+	c := httpclient.New(httpclient.Options{MaxAttempts: 1, Breaker: breaker})
+	get := func() (int, error) {
+		resp, err := c.Get(context.Background(), srv.URL+"/books/1")
+		if err != nil {
+			return 0, err
+		}
+		resp.Body.Close()
+		return resp.StatusCode, nil
+	}
+
+	for i := range 3 {
+		if status, err := get(); status != http.StatusServiceUnavailable {
+			t.Fatalf("call %d = %d, %v; want 503", i+1, status, err)
+		}
+	}
+	// Open: the store is not called while it is given time to recover
+	for range 2 {
+		if _, err := get(); !errors.Is(err, httpclient.ErrCircuitOpen) {
+			t.Errorf("call with the breaker open = %v; want ErrCircuitOpen", err)
+		}
+	}
+	if n := chaos.Calls(); n != 3 {
+		t.Errorf("store calls = %d; want 3, none while open", n)
+	}
+
+	// After the cooldown a trial call finds the store back, closing it
+	clk.Advance(time.Minute)
+	if status, err := get(); status != http.StatusOK {
+		t.Errorf("trial call = %d, %v; want 200", status, err)
+	}
+	if s := breaker.State(); s != httpclient.Closed {
+		t.Errorf("breaker state = %v; want closed", s)
+	}
+}
+
+func TestChaos_AttemptTimeout(t *testing.T) {
+	srv, _, _ := chaosServer(t, ChaosConfig{Latency: 200 * time.Millisecond})
+	var got []int
+	c := httpclient.New(httpclient.Options{Timeout: 20 * time.Millisecond, MaxAttempts: 2, Backoff: time.Millisecond, OnResponse: recordStatuses(&got)})
+
+	_, err := c.Get(context.Background(), srv.URL+"/books")
+	if !errors.Is(err, context.DeadlineExceeded) || !slices.Equal(got, []int{0, 0}) {
+		t.Errorf("Get = %v after attempts %v; want both attempts to time out", err, got)
+	}
+}
+
+func TestChaos_PartialFailure(t *testing.T) {
+	var got []int
+	c := httpclient.New(httpclient.Options{MaxAttempts: 3, Backoff: time.Millisecond, OnResponse: recordStatuses(&got)})
+	send := func(srv *httptest.Server, token, method, path, body string) int {
+		t.Helper()
+		req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := c.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	const book = `{"title":"Release It!","author":"Michael Nygard","price":35}`
+
+	t.Run("POST is not retried", func(t *testing.T) {
+		got = nil
+		srv, chaos, token := chaosServer(t, ChaosConfig{Schedule: []Fault{FaultPartial}})
+		// The book was added though the answer was 503; a retry would add
+		// it again
+		if status := send(srv, token, http.MethodPost, "/books", book); status != http.StatusServiceUnavailable || len(got) != 1 {
+			t.Errorf("POST = %d after %d attempts; want 503 after 1", status, len(got))
+		}
+		added := 0
+		for _, b := range chaos.BookRepository.GetBooks() {
+			if b.Title == "Release It!" {
+				added++
+			}
+		}
+		if added != 1 {
+			t.Errorf("the store has the book %d times; want once", added)
+		}
+	})
+
+	t.Run("PUT is", func(t *testing.T) {
+		got = nil
+		srv, chaos, token := chaosServer(t, ChaosConfig{Schedule: []Fault{FaultPartial}})
+		// Making the change again leaves the same book
+		if status := send(srv, token, http.MethodPut, "/books/1", book); status != http.StatusOK || !slices.Equal(got, []int{503, 200}) {
+			t.Errorf("PUT = %d after attempts %v; want 200 after 503, 200", status, got)
+		}
+		if b, _ := chaos.BookRepository.GetBook(1); b.Title != "Release It!" {
+			t.Errorf("book 1 = %+v; want it updated", b)
+		}
+	})
+}
@@ -0,0 +1,248 @@
+package restapi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/errorsx"
+)
+
+// maxCoverBytes bounds one cover image
+const maxCoverBytes = 2 << 20
+
+// coverTypes are the image types a cover may have, as sniffed from its
+// bytes by http.DetectContentType; what the client claims is not trusted
+var coverTypes = []string{"image/gif", "image/jpeg", "image/png", "image/webp"}
+
+// Cover is a book's cover image
+type Cover struct {
+	ContentType string
+	Data        []byte
+	ModTime     time.Time
+}
+
+// CoverStore keeps one cover image per book ID. Cover returns an error
+// matching fs.ErrNotExist for a book without one. The build selects the
+// implementation, like BookRepository: MemoryCoverStore by default, or
+// FileCoverStore with -tags filestore.
+type CoverStore interface {
+	PutCover(id int, cover Cover) error
+	Cover(id int) (Cover, error)
+	DeleteCover(id int) error
+}
+
+// MemoryCoverStore is a CoverStore in memory
+type MemoryCoverStore struct {
+	mu     sync.RWMutex
+	covers map[int]Cover
+}
+
+// NewMemoryCoverStore returns an empty MemoryCoverStore
+func NewMemoryCoverStore() *MemoryCoverStore {
+	return &MemoryCoverStore{covers: make(map[int]Cover)}
+}
+
+// PutCover stores cover for the book id, replacing any it had
+func (s *MemoryCoverStore) PutCover(id int, cover Cover) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.covers[id] = cover
+	return nil
+}
+
+// Cover returns the cover of the book id
+func (s *MemoryCoverStore) Cover(id int) (Cover, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	cover, ok := s.covers[id]
+	if !ok {
+		return Cover{}, fs.ErrNotExist
+	}
+	return cover, nil
+}
+
+// DeleteCover removes the cover of the book id, if it has one
+func (s *MemoryCoverStore) DeleteCover(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.covers, id)
+	return nil
+}
+
+// CoverUpload is the response to POST /books/{id}/cover
+type CoverUpload struct {
+	ContentType string `json:"content_type"`
+	Size        int    `json:"size"`
+}
+
+// handleUploadCover handles POST /books/{id}/cover: a multipart/form-data
+// upload whose "file" part is a GIF, JPEG, PNG or WebP image of at most
+// maxCoverBytes. It replaces the book's cover if it had one.
+func handleUploadCover(w http.ResponseWriter, r *http.Request, store BookRepository, covers CoverStore) {
+	id, err := pathID(r)
+	if err != nil {
+		respondWithError(w, errorsx.Wrap(err, errorsx.CodeInvalidArgument, "Invalid book ID"))
+		return
+	}
+	if _, ok := store.GetBook(id); !ok {
+		respondWithError(w, errorsx.New(errorsx.CodeNotFound, "Book not found"))
+		return
+	}
+
+	// The slack leaves room for the multipart headers and boundaries; the
+	// image itself is held to maxCoverBytes below
+	r.Body = http.MaxBytesReader(w, r.Body, maxCoverBytes+64<<10)
+	file, err := uploadedFile(r, "file")
+	if err != nil {
+		respondWithError(w, coverError(err))
+		return
+	}
+	data, err := io.ReadAll(io.LimitReader(file, maxCoverBytes+1))
+	if err != nil {
+		respondWithError(w, coverError(err))
+		return
+	}
+	if len(data) > maxCoverBytes {
+		respondWithError(w, errorsx.Errorf(errorsx.CodeTooLarge, "Cover images are limited to %d bytes", maxCoverBytes))
+		return
+	}
+	if len(data) == 0 {
+		respondWithError(w, errorsx.New(errorsx.CodeInvalidArgument, "The cover image is empty"))
+		return
+	}
+	contentType := http.DetectContentType(data)
+	if !slices.Contains(coverTypes, contentType) {
+		respondWithError(w, errorsx.Errorf(errorsx.CodeUnsupportedMedia,
+			"Cover images must be GIF, JPEG, PNG or WebP, not %s", contentType))
+		return
+	}
+
+	cover := Cover{ContentType: contentType, Data: data, ModTime: time.Now().UTC()}
+	if err := covers.PutCover(id, cover); err != nil {
+		respondWithError(w, errorsx.Wrap(err, errorsx.CodeInternal, "Saving the cover failed"))
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("/books/%d/cover", id))
+	respondWithJSON(w, http.StatusCreated, CoverUpload{ContentType: contentType, Size: len(data)})
+}
+
+// handleGetCover handles GET /books/{id}/cover. Covers can be replaced,
+// so clients may store them but must revalidate; the ETag and
+// Last-Modified headers make that a 304 when nothing changed.
+// http.ServeContent answers If-None-Match, If-Modified-Since and Range.
+func handleGetCover(w http.ResponseWriter, r *http.Request, covers CoverStore) {
+	id, err := pathID(r)
+	if err != nil {
+		respondWithError(w, errorsx.Wrap(err, errorsx.CodeInvalidArgument, "Invalid book ID"))
+		return
+	}
+	cover, err := covers.Cover(id)
+	if errors.Is(err, fs.ErrNotExist) {
+		respondWithError(w, errorsx.New(errorsx.CodeNotFound, "Book has no cover"))
+		return
+	}
+	if err != nil {
+		respondWithError(w, errorsx.Wrap(err, errorsx.CodeInternal, "Reading the cover failed"))
+		return
+	}
+
+	w.Header().Set("Content-Type", cover.ContentType)
+	w.Header().Set("ETag", etagOf(cover.Data))
+	w.Header().Set("Cache-Control", "public, no-cache")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, "", cover.ModTime, bytes.NewReader(cover.Data))
+}
+
+// coverError reports an upload that could not be read, or one over the
+// request size limit
+func coverError(err error) error {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return errorsx.Errorf(errorsx.CodeTooLarge, "Cover images are limited to %d bytes", maxCoverBytes)
+	}
+	if errorsx.CodeOf(err) != errorsx.CodeInternal {
+		return err
+	}
+	return errorsx.Wrap(err, errorsx.CodeInvalidArgument, "Invalid multipart body")
+}
+
+// coverDeletingRepository deletes a book's cover along with the book
+type coverDeletingRepository struct {
+	BookRepository
+	covers CoverStore
+}
+
+func (r coverDeletingRepository) DeleteBook(id int) bool {
+	ok := r.BookRepository.DeleteBook(id)
+	if ok {
+		// The book is gone either way, and IDs are not reused, so a cover
+		// that could not be deleted is logged rather than failing the call
+		if err := r.covers.DeleteCover(id); err != nil {
+			slog.Error("deleting cover", "book_id", id, "error", err)
+		}
+	}
+	return ok
+}
+
+// Begin starts a transaction that deletes the covers of the books it
+// deleted once it commits
+func (r coverDeletingRepository) Begin() (Tx, error) {
+	tx, err := r.BookRepository.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return &coverDeletingTx{Tx: tx, covers: r.covers}, nil
+}
+
+// coverDeletingTx is a transaction over a coverDeletingRepository
+type coverDeletingTx struct {
+	Tx
+	covers  CoverStore
+	deleted []int
+}
+
+func (t *coverDeletingTx) DeleteBook(id int) bool {
+	ok := t.Tx.DeleteBook(id)
+	if ok {
+		t.deleted = append(t.deleted, id)
+	}
+	return ok
+}
+
+func (t *coverDeletingTx) Commit() error {
+	if err := t.Tx.Commit(); err != nil {
+		return err
+	}
+	for _, id := range t.deleted {
+		if err := t.covers.DeleteCover(id); err != nil {
+			slog.Error("deleting cover", "book_id", id, "error", err)
+		}
+	}
+	return nil
+}
+
+// cacheInvalidatingCoverStore empties a response cache after a cover
+// changes, as invalidateOnChange does for books. Covers are not book
+// changes, so this happens before the upload is answered.
+type cacheInvalidatingCoverStore struct {
+	CoverStore
+	cache *responseCache
+}
+
+func (s cacheInvalidatingCoverStore) PutCover(id int, cover Cover) error {
+	defer s.cache.Invalidate()
+	return s.CoverStore.PutCover(id, cover)
+}
+
+func (s cacheInvalidatingCoverStore) DeleteCover(id int) error {
+	defer s.cache.Invalidate()
+	return s.CoverStore.DeleteCover(id)
+}
@@ -0,0 +1,166 @@
+package restapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// pngCover and gifCover start with their formats' signatures, which is
+// all http.DetectContentType looks at
+var (
+	pngCover = append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 64)...)
+	gifCover = append([]byte("GIF89a"), bytes.Repeat([]byte{0}, 64)...)
+)
+
+// coverRouter returns a router over store and covers, with a response
+// cache, and an admin token for it
+func coverRouter(t *testing.T, store BookRepository, covers CoverStore) (http.Handler, string) {
+	t.Helper()
+	auth, _ := testAuth(t)
+	cache := newResponseCache(withCacheTTL(time.Minute))
+	outbox, flush := testChanges(t, cache, nil, nil)
+	router := flushing(t, newRouter(store, auth, slog.New(slog.NewTextHandler(io.Discard, nil)), nil, cache, covers, nil, nil, outbox, nil, nil), flush)
+	var lr LoginResponse
+	if err := json.NewDecoder(login(t, router, `{"username":"alice","password":"wonderland"}`).Body).Decode(&lr); err != nil {
+		t.Fatal(err)
+	}
+	return router, lr.Token
+}
+
+// uploadCover posts content to path as the multipart file field
+func uploadCover(t *testing.T, router http.Handler, token, path, field string, content []byte) *httptest.ResponseRecorder {
+	t.Helper()
+	body, contentType := multipartFile(t, field, "cover.img", content)
+	req := httptest.NewRequest(http.MethodPost, path, body)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", contentType)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+func getCover(router http.Handler, path, ifNoneMatch string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if ifNoneMatch != "" {
+		req.Header.Set("If-None-Match", ifNoneMatch)
+	}
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestCover_UploadAndServe(t *testing.T) {
+	router, token := coverRouter(t, NewBookStore(), NewMemoryCoverStore())
+
+	rr := uploadCover(t, router, token, "/books/1/cover", "file", pngCover)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("upload status = %d; want 201 (body: %s)", rr.Code, rr.Body.String())
+	}
+	if got := rr.Header().Get("Location"); got != "/books/1/cover" {
+		t.Errorf("Location = %q; want /books/1/cover", got)
+	}
+	var uploaded CoverUpload
+	if err := json.NewDecoder(rr.Body).Decode(&uploaded); err != nil {
+		t.Fatal(err)
+	}
+	if uploaded != (CoverUpload{ContentType: "image/png", Size: len(pngCover)}) {
+		t.Errorf("upload result = %+v; want the PNG's type and size", uploaded)
+	}
+
+	rr = getCover(router, "/books/1/cover", "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("GET status = %d; want 200", rr.Code)
+	}
+	if !bytes.Equal(rr.Body.Bytes(), pngCover) {
+		t.Error("GET returned different bytes than were uploaded")
+	}
+	for header, want := range map[string]string{
+		"Content-Type":  "image/png",
+		"Cache-Control": "public, no-cache",
+		"ETag":          etagOf(pngCover),
+	} {
+		if got := rr.Header().Get(header); got != want {
+			t.Errorf("%s = %q; want %q", header, got, want)
+		}
+	}
+	if rr.Header().Get("Last-Modified") == "" {
+		t.Error("no Last-Modified header")
+	}
+
+	if rr := getCover(router, "/books/1/cover", etagOf(pngCover)); rr.Code != http.StatusNotModified || rr.Body.Len() != 0 {
+		t.Errorf("conditional GET = %d with %d bytes; want 304 with none", rr.Code, rr.Body.Len())
+	}
+
+	// A new cover replaces the old one, cached or not
+	if rr := uploadCover(t, router, token, "/books/1/cover", "file", gifCover); rr.Code != http.StatusCreated {
+		t.Fatalf("replacing upload status = %d; want 201", rr.Code)
+	}
+	rr = getCover(router, "/books/1/cover", etagOf(pngCover))
+	if rr.Code != http.StatusOK || rr.Header().Get("Content-Type") != "image/gif" {
+		t.Errorf("GET after replacing = %d, %s; want 200 with the GIF", rr.Code, rr.Header().Get("Content-Type"))
+	}
+}
+
+func TestCover_RejectedUploads(t *testing.T) {
+	tooLarge := append(bytes.Clone(pngCover), make([]byte, maxCoverBytes)...)
+	tests := []struct {
+		name, path, field string
+		content           []byte
+		want              int
+	}{
+		{"too large", "/books/1/cover", "file", tooLarge, http.StatusRequestEntityTooLarge},
+		{"not an image", "/books/1/cover", "file", []byte("<html><body>hi</body></html>"), http.StatusUnsupportedMediaType},
+		{"empty", "/books/1/cover", "file", nil, http.StatusBadRequest},
+		{"no file field", "/books/1/cover", "cover", pngCover, http.StatusBadRequest},
+		{"missing book", "/books/999/cover", "file", pngCover, http.StatusNotFound},
+		{"bad ID", "/books/abc/cover", "file", pngCover, http.StatusBadRequest},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			covers := NewMemoryCoverStore()
+			router, token := coverRouter(t, NewBookStore(), covers)
+			rr := uploadCover(t, router, token, tc.path, tc.field, tc.content)
+			if rr.Code != tc.want {
+				t.Fatalf("status = %d; want %d (body: %s)", rr.Code, tc.want, rr.Body.String())
+			}
+			if ct := rr.Header().Get("Content-Type"); ct != problemContentType {
+				t.Errorf("Content-Type = %q; want a problem", ct)
+			}
+			if _, err := covers.Cover(1); err == nil {
+				t.Error("a rejected upload was stored")
+			}
+		})
+	}
+}
+
+func TestCover_DeletedWithBook(t *testing.T) {
+	covers := NewMemoryCoverStore()
+	router, token := coverRouter(t, NewBookStore(), covers)
+	if rr := uploadCover(t, router, token, "/books/2/cover", "file", pngCover); rr.Code != http.StatusCreated {
+		t.Fatalf("upload status = %d; want 201", rr.Code)
+	}
+	// Cached now, so the 404 below also shows the cache was emptied
+	if rr := getCover(router, "/books/2/cover", ""); rr.Code != http.StatusOK {
+		t.Fatalf("GET status = %d; want 200", rr.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodDelete, "/books/2", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusNoContent {
+		t.Fatalf("DELETE status = %d; want 204", rr.Code)
+	}
+	if _, err := covers.Cover(2); err == nil {
+		t.Error("cover still stored after its book was deleted")
+	}
+	if rr := getCover(router, "/books/2/cover", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("GET after delete = %d; want 404", rr.Code)
+	}
+}
@@ -0,0 +1,165 @@
+package restapi
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/rehan/go-interview-prep/pkg/csvutil"
+	"github.com/rehan/go-interview-prep/pkg/errorsx"
+	"github.com/rehan/go-interview-prep/pkg/money"
+	"github.com/rehan/go-interview-prep/pkg/validator"
+)
+
+// maxImportBytes bounds an upload to POST /books/import
+const maxImportBytes = 10 << 20
+
+// ImportResult is the response to POST /books/import. Nothing is imported
+// unless every row is valid; otherwise Errors lists each problem by row.
+type ImportResult struct {
+	Imported int        `json:"imported"`
+	IDs      []int      `json:"ids,omitempty"`
+	Errors   []RowError `json:"errors,omitempty"`
+}
+
+// RowError is one problem with one CSV row. Row is the line in the file,
+// counting the header as line 1, so it matches what a spreadsheet shows.
+type RowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Rule    string `json:"rule,omitempty"`
+	Message string `json:"message"`
+}
+
+// handleExportBooks handles GET /books/export: every book as a CSV
+// download, ordered by ID
+func handleExportBooks(w http.ResponseWriter, r *http.Request, store BookRepository) {
+	books := store.GetBooks()
+	sort.Slice(books, func(i, j int) bool { return books[i].ID < books[j].ID })
+
+	w.Header().Set("Content-Type", mediaCSV+"; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="books.csv"`)
+	w.WriteHeader(http.StatusOK)
+	writeBooksCSV(w, books)
+}
+
+// handleImportBooks handles POST /books/import: a multipart/form-data
+// upload whose "file" part is a CSV with a header row naming at least the
+// title, author and price columns. The upload is read as it arrives
+// rather than buffered to disk.
+func handleImportBooks(w http.ResponseWriter, r *http.Request, store BookRepository) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportBytes)
+	file, err := uploadedFile(r, "file")
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+
+	books, rowErrs, err := readBooksCSV(file)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	if len(rowErrs) > 0 {
+		respondWithJSON(w, http.StatusBadRequest, ImportResult{Errors: rowErrs})
+		return
+	}
+	ids := store.AddBooks(books)
+	respondWithJSON(w, http.StatusCreated, ImportResult{Imported: len(ids), IDs: ids})
+}
+
+// uploadedFile returns the multipart part of r named field
+func uploadedFile(r *http.Request, field string) (io.Reader, error) {
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, errorsx.Wrap(err, errorsx.CodeInvalidArgument,
+			fmt.Sprintf("Upload the file as multipart/form-data field %q", field))
+	}
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil, errorsx.Errorf(errorsx.CodeInvalidArgument, "The upload has no %q field", field)
+		}
+		if err != nil {
+			return nil, errorsx.Wrap(err, errorsx.CodeInvalidArgument, "Invalid multipart body")
+		}
+		if part.FormName() == field {
+			return part, nil
+		}
+	}
+}
+
+// importRow holds the CSV columns an import needs. Others, such as the id
+// and created_at columns an export has, are ignored: imported books get
+// new IDs, so an export can be imported into another store.
+type importRow struct {
+	Title  string       `csv:"title,required"`
+	Author string       `csv:"author,required"`
+	Price  money.Amount `csv:"price,required"`
+}
+
+// readBooksCSV reads books from CSV with a header row. Problems with
+// individual rows, such as a bad price or a missing title, are collected
+// so an import reports all of them at once. The error is for a file that
+// cannot be read as a whole, such as one without the needed columns.
+func readBooksCSV(r io.Reader) ([]Book, []RowError, error) {
+	cr, err := csvutil.NewReader[importRow](r)
+	var missing *csvutil.MissingColumnsError
+	switch {
+	case errors.Is(err, csvutil.ErrNoHeader):
+		return nil, nil, errorsx.New(errorsx.CodeInvalidArgument, "The CSV file is empty")
+	case errors.As(err, &missing):
+		return nil, nil, errorsx.Errorf(errorsx.CodeInvalidArgument,
+			"The CSV header has no %s column", strings.Join(missing.Columns, ", "))
+	case err != nil:
+		return nil, nil, csvError(err)
+	}
+
+	var books []Book
+	var rowErrs []RowError
+	rows := 0
+	for row, err := range cr.All() {
+		if rows++; rows > maxBatchItems {
+			return nil, nil, errorsx.Errorf(errorsx.CodeInvalidArgument, "An import holds at most %d books", maxBatchItems)
+		}
+		var rowErr *csvutil.RowError
+		if errors.As(err, &rowErr) {
+			// price is the only column of an import row that is parsed
+			re := RowError{Row: rowErr.Line, Message: rowErr.Err.Error()}
+			if rowErr.Column != "" {
+				re.Field, re.Rule = rowErr.Column, "decimal"
+			}
+			rowErrs = append(rowErrs, re)
+			continue
+		}
+		if err != nil {
+			return nil, nil, csvError(err)
+		}
+
+		book := Book{Title: row.Title, Author: row.Author, Price: row.Price}
+		var fieldErrs validator.Errors
+		if err := validator.Struct(book); errors.As(err, &fieldErrs) {
+			for _, fe := range fieldErrs {
+				rowErrs = append(rowErrs, RowError{Row: cr.Line(), Field: fe.Field, Rule: fe.Rule, Message: fe.Error()})
+			}
+			continue
+		} else if err != nil {
+			return nil, nil, err
+		}
+		books = append(books, book)
+	}
+	return books, rowErrs, nil
+}
+
+// csvError reports a file that is not valid CSV, or an upload over
+// maxImportBytes
+func csvError(err error) error {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return errorsx.Errorf(errorsx.CodeInvalidArgument, "Uploads are limited to %d bytes", tooLarge.Limit)
+	}
+	return errorsx.Wrap(err, errorsx.CodeInvalidArgument, "Invalid CSV")
+}
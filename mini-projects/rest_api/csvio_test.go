@@ -0,0 +1,220 @@
+package restapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/money"
+	"github.com/rehan/go-interview-prep/pkg/testutil/golden"
+)
+
+func TestReadBooksCSV(t *testing.T) {
+	tests := []struct {
+		name      string
+		csv       string
+		wantBooks []Book
+		wantErrs  []RowError
+		wantErr   bool
+	}{
+		{
+			name: "columns in any order, extra ones ignored",
+			csv:  "price,id,Author,title\n12.50,7,Ann,A\n3,8,Bob,\"B, the sequel\"\n",
+			wantBooks: []Book{
+				{Title: "A", Author: "Ann", Price: money.MustParse("12.50")},
+				{Title: "B, the sequel", Author: "Bob", Price: money.MustParse("3")},
+			},
+		},
+		{
+			name:      "header only",
+			csv:       "title,author,price\n",
+			wantBooks: nil,
+		},
+		{
+			name: "row errors",
+			csv:  "title,author,price\nGood,Ann,1\n,Bob,2\nC,Cy,two\nD,Di\nE,,0\n",
+			wantBooks: []Book{
+				{Title: "Good", Author: "Ann", Price: money.MustParse("1")},
+			},
+			wantErrs: []RowError{
+				{Row: 3, Field: "title", Rule: "required", Message: "title is required"},
+				{Row: 4, Field: "price", Rule: "decimal", Message: `money: invalid amount: "two"`},
+				{Row: 5, Message: "row has 2 fields; the header has 3"},
+				{Row: 6, Field: "author", Rule: "required", Message: "author is required"},
+				{Row: 6, Field: "price", Rule: "required", Message: "price is required"},
+			},
+		},
+		{name: "missing column", csv: "title,author\nA,B\n", wantErr: true},
+		{name: "empty", csv: "", wantErr: true},
+		{name: "bad quoting", csv: "title,author,price\n\"A,B,1\n", wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			books, rowErrs, err := readBooksCSV(strings.NewReader(tc.csv))
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("error = %v; want error %v", err, tc.wantErr)
+			}
+			if !reflect.DeepEqual(books, tc.wantBooks) {
+				t.Errorf("books = %+v; want %+v", books, tc.wantBooks)
+			}
+			if !reflect.DeepEqual(rowErrs, tc.wantErrs) {
+				t.Errorf("row errors = %+v; want %+v", rowErrs, tc.wantErrs)
+			}
+		})
+	}
+}
+
+// multipartFile returns a multipart/form-data body with content as the
+// field's file, named filename, and its Content-Type
+func multipartFile(t *testing.T, field, filename string, content []byte) (*bytes.Buffer, string) {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile(field, filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fw.Write(content)
+	if err := mw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &body, mw.FormDataContentType()
+}
+
+// importRouter returns a router over store and an admin token for it
+func importRouter(t *testing.T, store BookRepository) (http.Handler, string) {
+	t.Helper()
+	auth, _ := testAuth(t)
+	router := newRouter(store, auth, slog.New(slog.NewTextHandler(io.Discard, nil)), nil, nil, nil, nil, nil, nil, nil, nil)
+	var lr LoginResponse
+	if err := json.NewDecoder(login(t, router, `{"username":"alice","password":"wonderland"}`).Body).Decode(&lr); err != nil {
+		t.Fatal(err)
+	}
+	return router, lr.Token
+}
+
+func postImport(router http.Handler, token string, body io.Reader, contentType string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/books/import", body)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", contentType)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+// TestWriteBooksCSV pins the export format, quoting included, that
+// spreadsheets and the import endpoint read
+func TestWriteBooksCSV(t *testing.T) {
+	created := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	books := []Book{
+		{ID: 1, Title: "The Go Programming Language", Author: "Alan A. A. Donovan", Price: money.MustParse("32.99"), CreatedAt: created},
+		{ID: 2, Title: `Quotes "and", commas`, Author: "Line\nBreak", Price: money.MustParse("0.99"), CreatedAt: created.Add(time.Hour)},
+		{ID: 10, Title: "Ünïcode 日本語", Author: " Leading space", Price: money.MustParse("-5"), CreatedAt: created.In(time.FixedZone("UTC+2", 2*60*60))},
+	}
+	var buf bytes.Buffer
+	if err := writeBooksCSV(&buf, books); err != nil {
+		t.Fatal(err)
+	}
+	golden.Assert(t, "books_export", buf.Bytes())
+}
+
+func TestExportImport_RoundTrip(t *testing.T) {
+	source := NewBookStore()
+	source.AddBook(Book{Title: `Quotes "and", commas`, Author: "Line\nBreak", Price: money.MustParse("0.99")})
+	router, _ := importRouter(t, source)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/books/export", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("export status = %d", rr.Code)
+	}
+	if got := rr.Header().Get("Content-Disposition"); got != `attachment; filename="books.csv"` {
+		t.Errorf("Content-Disposition = %q; want a books.csv attachment", got)
+	}
+	if got := rr.Header().Get("Content-Type"); got != "text/csv; charset=utf-8" {
+		t.Errorf("Content-Type = %q; want text/csv", got)
+	}
+
+	target := NewBookStore()
+	for _, b := range target.GetBooks() {
+		target.DeleteBook(b.ID)
+	}
+	router, token := importRouter(t, target)
+	body, contentType := multipartFile(t, "file", "books.csv", rr.Body.Bytes())
+	rr = postImport(router, token, body, contentType)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("import status = %d; want 201 (body: %s)", rr.Code, rr.Body.String())
+	}
+	var result ImportResult
+	if err := json.NewDecoder(rr.Body).Decode(&result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Imported != 4 || len(result.IDs) != 4 {
+		t.Errorf("result = %+v; want 4 books imported", result)
+	}
+
+	// The books come back in ID order with the same fields; IDs and
+	// creation times are the target store's own
+	for i, id := range result.IDs {
+		want, _ := source.GetBook(i + 1)
+		got, _ := target.GetBook(id)
+		if got.Title != want.Title || got.Author != want.Author || got.Price != want.Price {
+			t.Errorf("imported book %d = %+v; want %+v", id, got, want)
+		}
+	}
+}
+
+func TestImport_RowErrorsImportNothing(t *testing.T) {
+	store := NewBookStore()
+	router, token := importRouter(t, store)
+	body, contentType := multipartFile(t, "file", "books.csv", []byte("title,author,price\nA,Ann,1\nB,,2\n"))
+	rr := postImport(router, token, body, contentType)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d; want 400", rr.Code)
+	}
+	var result ImportResult
+	if err := json.NewDecoder(rr.Body).Decode(&result); err != nil {
+		t.Fatal(err)
+	}
+	want := []RowError{{Row: 3, Field: "author", Rule: "required", Message: "author is required"}}
+	if result.Imported != 0 || !reflect.DeepEqual(result.Errors, want) {
+		t.Errorf("result = %+v; want only the row 3 error", result)
+	}
+	if len(store.GetBooks()) != 3 {
+		t.Errorf("store has %d books; want the valid row not imported either", len(store.GetBooks()))
+	}
+}
+
+func TestImport_RejectedUploads(t *testing.T) {
+	router, token := importRouter(t, NewBookStore())
+	wrongField, wrongFieldType := multipartFile(t, "upload", "books.csv", []byte("title,author,price\n"))
+	noColumns, noColumnsType := multipartFile(t, "file", "books.csv", []byte("name\nA\n"))
+	tests := []struct {
+		name        string
+		body        io.Reader
+		contentType string
+	}{
+		{"not multipart", strings.NewReader("title,author,price\n"), "text/csv"},
+		{"no file field", wrongField, wrongFieldType},
+		{"missing columns", noColumns, noColumnsType},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := postImport(router, token, tc.body, tc.contentType)
+			if rr.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d; want 400", rr.Code)
+			}
+			if ct := rr.Header().Get("Content-Type"); ct != problemContentType {
+				t.Errorf("Content-Type = %q; want a problem", ct)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package restapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/errorsx"
+	"github.com/rehan/go-interview-prep/pkg/ndjson"
+	"github.com/rehan/go-interview-prep/pkg/pubsub"
+)
+
+// Book event types, also used as the SSE event names
+const (
+	EventBookCreated = "created"
+	EventBookUpdated = "updated"
+	EventBookDeleted = "deleted"
+)
+
+// eventHistory is how many book events the bus keeps for clients that
+// reconnect with Last-Event-ID
+const eventHistory = 1000
+
+// sseKeepalive is how often an idle stream gets a comment line, so
+// proxies do not time it out and a dead client is noticed
+const sseKeepalive = 15 * time.Second
+
+// sseBuffer is how many events a stream may fall behind before it is
+// dropped; the client reconnects and resumes from its Last-Event-ID
+const sseBuffer = 64
+
+// BookEvent is one change to the store. Book is the book as it is after
+// the change, and is not set for deletions.
+type BookEvent struct {
+	Type string `json:"type"`
+	ID   int    `json:"id"`
+	Book *Book  `json:"book,omitempty"`
+}
+
+// EventRecord is one line of GET /books/events/export: a book event and
+// its event ID, which ?after= takes to export only the events since
+type EventRecord struct {
+	EventID uint64 `json:"event_id"`
+	BookEvent
+}
+
+// newEventBus returns the bus book changes are published on
+func newEventBus() *pubsub.Bus[BookEvent] {
+	return pubsub.New[BookEvent](eventHistory)
+}
+
+// handleBookEvents handles GET /books/events: a text/event-stream of book
+// changes, each with its event ID. A client that reconnects with the
+// Last-Event-ID header, as EventSource does, first gets the events it
+// missed. If some of those are no longer kept it gets a "reset" event
+// instead, and should fetch the list again.
+//
+// The stream runs until the client goes away or the server shuts down,
+// so the server's write timeout is lifted for it.
+func handleBookEvents(w http.ResponseWriter, r *http.Request, events *pubsub.Bus[BookEvent]) {
+	var after uint64
+	if v := r.Header.Get("Last-Event-ID"); v != "" {
+		var err error
+		if after, err = strconv.ParseUint(v, 10, 64); err != nil {
+			respondWithError(w, errorsx.Wrap(err, errorsx.CodeInvalidArgument, "Last-Event-ID must be an event ID"))
+			return
+		}
+	}
+
+	rc := http.NewResponseController(w)
+	// Not every ResponseWriter can do this, httptest's for one; the
+	// stream still works, only the server's timeout may end it
+	rc.SetWriteDeadline(time.Time{})
+
+	sub, complete := events.Subscribe(after, sseBuffer)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	if !complete {
+		fmt.Fprintf(w, "event: reset\ndata: {}\n\n")
+	}
+	if rc.Flush() != nil {
+		return
+	}
+
+	keepalive := time.NewTicker(sseKeepalive)
+	defer keepalive.Stop()
+	for {
+		select {
+		case ev, ok := <-sub.C():
+			if !ok {
+				// Dropped for falling behind, or shutting down; either
+				// way the client reconnects with its Last-Event-ID
+				return
+			}
+			if err := writeSSE(w, ev); err != nil {
+				return
+			}
+		case <-keepalive.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+		if rc.Flush() != nil {
+			return
+		}
+	}
+}
+
+// handleExportEvents handles GET /books/events/export: the kept book
+// events after ?after=, or all of them, as NDJSON, oldest first. The
+// X-Events-Complete header is false if some events after that one are no
+// longer kept, as the stream's "reset" event says. The export stops if the
+// client goes away.
+func handleExportEvents(w http.ResponseWriter, r *http.Request, events *pubsub.Bus[BookEvent]) {
+	var after uint64
+	if v := r.URL.Query().Get("after"); v != "" {
+		var err error
+		if after, err = strconv.ParseUint(v, 10, 64); err != nil {
+			respondWithError(w, errorsx.Wrap(err, errorsx.CodeInvalidArgument, "after must be an event ID"))
+			return
+		}
+	}
+
+	kept, complete := events.Since(after)
+	w.Header().Set("Content-Type", mediaNDJSON)
+	w.Header().Set("X-Events-Complete", strconv.FormatBool(complete))
+	w.WriteHeader(http.StatusOK)
+	enc := ndjson.NewEncoder(w)
+	for _, ev := range kept {
+		if err := enc.Encode(r.Context(), EventRecord{EventID: ev.ID, BookEvent: ev.Data}); err != nil {
+			return
+		}
+	}
+}
+
+// writeSSE writes ev as one server-sent event. The JSON has no newlines,
+// so it fits on one data line.
+func writeSSE(w http.ResponseWriter, ev pubsub.Event[BookEvent]) error {
+	data, err := json.Marshal(ev.Data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.ID, ev.Data.Type, data)
+	return err
+}
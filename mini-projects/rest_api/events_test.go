@@ -0,0 +1,266 @@
+package restapi
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/ndjson"
+	"github.com/rehan/go-interview-prep/pkg/pubsub"
+)
+
+// sseEvent is one event as a client parses it off the stream
+type sseEvent struct {
+	id, event, data string
+}
+
+// eventStream is an open GET /books/events response
+type eventStream struct {
+	events chan sseEvent
+}
+
+// eventServer serves a router with an event bus, and returns it with the
+// bus and an admin token
+func eventServer(t *testing.T) (*httptest.Server, *pubsub.Bus[BookEvent], string) {
+	t.Helper()
+	auth, _ := testAuth(t)
+	events := newEventBus()
+	outbox, _ := testChanges(t, nil, events, nil)
+	srv := httptest.NewServer(newRouter(NewBookStore(), auth, slog.New(slog.NewTextHandler(io.Discard, nil)), nil, nil, nil, events, nil, outbox, nil, nil))
+	// Streams only end when the bus closes, and Close waits for them
+	t.Cleanup(srv.Close)
+	t.Cleanup(events.Close)
+
+	resp, err := http.Post(srv.URL+"/auth/login", "application/json", strings.NewReader(`{"username":"alice","password":"wonderland"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var lr LoginResponse
+	if err := json.NewDecoder(resp.Body).Decode(&lr); err != nil {
+		t.Fatal(err)
+	}
+	return srv, events, lr.Token
+}
+
+// openStream connects to the event stream, resuming after lastEventID if
+// it is not empty, and parses events off it as they arrive
+func openStream(t *testing.T, srv *httptest.Server, lastEventID string) *eventStream {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/books/events", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d; want 200", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q; want text/event-stream", ct)
+	}
+
+	s := &eventStream{events: make(chan sseEvent, 16)}
+	go func() {
+		defer close(s.events)
+		var ev sseEvent
+		sc := bufio.NewScanner(resp.Body)
+		for sc.Scan() {
+			field, value, _ := strings.Cut(sc.Text(), ": ")
+			switch field {
+			case "id":
+				ev.id = value
+			case "event":
+				ev.event = value
+			case "data":
+				ev.data = value
+			case "":
+				if ev != (sseEvent{}) {
+					s.events <- ev
+				}
+				ev = sseEvent{}
+			}
+		}
+	}()
+	return s
+}
+
+// next returns the next event, failing the test if none comes in time
+func (s *eventStream) next(t *testing.T) sseEvent {
+	t.Helper()
+	select {
+	case ev, ok := <-s.events:
+		if !ok {
+			t.Fatal("stream ended")
+		}
+		return ev
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event within 5s")
+	}
+	return sseEvent{}
+}
+
+// send makes an authenticated request with a JSON body
+func send(t *testing.T, srv *httptest.Server, token, method, path, body string) {
+	t.Helper()
+	req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		t.Fatalf("%s %s = %d", method, path, resp.StatusCode)
+	}
+}
+
+func TestBookEvents_Stream(t *testing.T) {
+	srv, _, token := eventServer(t)
+	stream := openStream(t, srv, "")
+
+	send(t, srv, token, http.MethodPost, "/books", `{"title":"Learning Go","author":"Jon Bodner","price":29.99}`)
+	send(t, srv, token, http.MethodPut, "/books/4", `{"title":"Learning Go, 2nd ed.","author":"Jon Bodner","price":39.99}`)
+	send(t, srv, token, http.MethodDelete, "/books/4", "")
+
+	tests := []struct {
+		id, event, title string
+	}{
+		{"1", EventBookCreated, "Learning Go"},
+		{"2", EventBookUpdated, "Learning Go, 2nd ed."},
+		{"3", EventBookDeleted, ""},
+	}
+	for _, tc := range tests {
+		ev := stream.next(t)
+		var data BookEvent
+		if err := json.Unmarshal([]byte(ev.data), &data); err != nil {
+			t.Fatalf("event %s data %q: %v", ev.id, ev.data, err)
+		}
+		if ev.id != tc.id || ev.event != tc.event || data.Type != tc.event || data.ID != 4 {
+			t.Errorf("event = %+v; want id %s, %s of book 4", ev, tc.id, tc.event)
+		}
+		var title string
+		if data.Book != nil {
+			title = data.Book.Title
+		}
+		if title != tc.title {
+			t.Errorf("event %s book title = %q; want %q", ev.id, title, tc.title)
+		}
+	}
+}
+
+func TestBookEvents_Resume(t *testing.T) {
+	srv, events, token := eventServer(t)
+	for range 3 {
+		send(t, srv, token, http.MethodPost, "/books", `{"title":"T","author":"A","price":1}`)
+	}
+
+	// A client that saw event 1 gets 2 and 3, then new events
+	stream := openStream(t, srv, "1")
+	for _, want := range []string{"2", "3"} {
+		if ev := stream.next(t); ev.id != want || ev.event != EventBookCreated {
+			t.Errorf("replayed event = %+v; want created event %s", ev, want)
+		}
+	}
+	send(t, srv, token, http.MethodDelete, "/books/1", "")
+	if ev := stream.next(t); ev.id != "4" || ev.event != EventBookDeleted {
+		t.Errorf("live event = %+v; want deleted event 4", ev)
+	}
+
+	// An ID this bus never issued, as after a restart, gets a reset
+	if ev := openStream(t, srv, "99").next(t); ev.event != "reset" {
+		t.Errorf("first event after unknown ID = %+v; want reset", ev)
+	}
+
+	// Closing the bus, as shutdown does, ends the stream
+	events.Close()
+	select {
+	case _, ok := <-stream.events:
+		if ok {
+			t.Error("event after the bus closed")
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("stream still open 5s after the bus closed")
+	}
+}
+
+func TestBookEvents_BadLastEventID(t *testing.T) {
+	auth, _ := testAuth(t)
+	router := newRouter(NewBookStore(), auth, slog.New(slog.NewTextHandler(io.Discard, nil)), nil, nil, nil, newEventBus(), nil, nil, nil, nil)
+	req := httptest.NewRequest(http.MethodGet, "/books/events", nil)
+	req.Header.Set("Last-Event-ID", "yesterday")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest || rr.Header().Get("Content-Type") != problemContentType {
+		t.Errorf("response = %d, %s; want a 400 problem", rr.Code, rr.Header().Get("Content-Type"))
+	}
+}
+
+func TestExportEvents(t *testing.T) {
+	auth, _ := testAuth(t)
+	events := pubsub.New[BookEvent](3)
+	router := newRouter(NewBookStore(), auth, slog.New(slog.NewTextHandler(io.Discard, nil)), nil, nil, nil, events, nil, nil, nil, nil)
+	book := Book{ID: 4, Title: "Learning Go"}
+	events.Publish(BookEvent{Type: EventBookCreated, ID: 4, Book: &book})
+	events.Publish(BookEvent{Type: EventBookUpdated, ID: 4, Book: &book})
+	events.Publish(BookEvent{Type: EventBookDeleted, ID: 4})
+	events.Publish(BookEvent{Type: EventBookDeleted, ID: 1})
+	// Only events 2 to 4 are kept
+
+	export := func(query string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/books/events/export"+query, nil))
+		return rr
+	}
+	tests := []struct {
+		query        string
+		want         []uint64
+		wantComplete string
+	}{
+		{"", []uint64{2, 3, 4}, "false"},
+		{"?after=1", []uint64{2, 3, 4}, "true"},
+		{"?after=3", []uint64{4}, "true"},
+		{"?after=4", nil, "true"},
+	}
+	for _, tc := range tests {
+		rr := export(tc.query)
+		if rr.Code != http.StatusOK || rr.Header().Get("Content-Type") != mediaNDJSON {
+			t.Fatalf("%s: response = %d, %s; want 200 NDJSON", tc.query, rr.Code, rr.Header().Get("Content-Type"))
+		}
+		var got []uint64
+		for rec, err := range ndjson.Values[EventRecord](context.Background(), iotest.HalfReader(rr.Body)) {
+			if err != nil {
+				t.Fatalf("%s: %v", tc.query, err)
+			}
+			got = append(got, rec.EventID)
+			if rec.EventID == 3 && (rec.Type != EventBookDeleted || rec.ID != 4 || rec.Book != nil) {
+				t.Errorf("event 3 = %+v; want book 4 deleted", rec)
+			}
+		}
+		if !slices.Equal(got, tc.want) || rr.Header().Get("X-Events-Complete") != tc.wantComplete {
+			t.Errorf("%s: exported %v, complete %s; want %v, %s", tc.query, got, rr.Header().Get("X-Events-Complete"), tc.want, tc.wantComplete)
+		}
+	}
+
+	if rr := export("?after=-1"); rr.Code != http.StatusBadRequest {
+		t.Errorf("?after=-1: status = %d; want 400", rr.Code)
+	}
+}
@@ -0,0 +1,207 @@
+package restapi
+
+import (
+	"embed"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+
+	"github.com/rehan/go-interview-prep/pkg/fixtures"
+	"github.com/rehan/go-interview-prep/pkg/money"
+	"github.com/rehan/go-interview-prep/pkg/validator"
+)
+
+// The sample books and demo accounts the server starts with are fixtures:
+// files in fixtures/, embedded in the binary and loaded with pkg/fixtures.
+// The seed subcommand adds the same books, or those in a directory of its
+// own, to the configured store, along with any number of fake ones.
+
+//go:embed fixtures
+var fixtureFiles embed.FS
+
+// UserFixture is an account as a users fixture lists it
+type UserFixture struct {
+	Username string `json:"username" validate:"required"`
+	Password string `json:"password" validate:"required"`
+	Role     Role   `json:"role" validate:"required"`
+}
+
+var (
+	// sampleBooks are the books NewBookStore starts with
+	sampleBooks = mustLoadFixture(loadBookFixtures(embeddedFixtures()))
+
+	// demoAccounts are the accounts the server starts with, one per role.
+	// They exist so the API can be tried out; their passwords are in the
+	// source.
+	demoAccounts = mustLoadFixture(loadUserFixtures(embeddedFixtures()))
+)
+
+// embeddedFixtures returns the fixtures directory embedded in the binary
+func embeddedFixtures() fs.FS {
+	sub, err := fs.Sub(fixtureFiles, "fixtures")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
+
+// mustLoadFixture panics if the embedded fixtures do not load, which the
+// tests would have caught
+func mustLoadFixture[T any](v T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// loadFixture loads name.yaml, name.yml or name.json from fsys, whichever
+// it finds first
+func loadFixture[T any](fsys fs.FS, name string) ([]T, error) {
+	for _, ext := range []string{".yaml", ".yml", ".json"} {
+		records, err := fixtures.Load[T](fsys, name+ext)
+		if !errors.Is(err, fs.ErrNotExist) {
+			return records, err
+		}
+	}
+	return nil, fmt.Errorf("fixtures: no %[1]s.yaml, %[1]s.yml or %[1]s.json", name)
+}
+
+// loadBookFixtures loads the books fixture from fsys, checking each book
+// as POST /books would
+func loadBookFixtures(fsys fs.FS) ([]Book, error) {
+	books, err := loadFixture[Book](fsys, "books")
+	if err != nil {
+		return nil, err
+	}
+	for i, book := range books {
+		if err := validator.Struct(book); err != nil {
+			return nil, fmt.Errorf("fixtures: book %d: %w", i+1, err)
+		}
+	}
+	return books, nil
+}
+
+// loadUserFixtures loads the users fixture from fsys as accounts keyed by
+// username, for newUserStore
+func loadUserFixtures(fsys fs.FS) (map[string]Account, error) {
+	users, err := loadFixture[UserFixture](fsys, "users")
+	if err != nil {
+		return nil, err
+	}
+	accounts := make(map[string]Account, len(users))
+	for i, u := range users {
+		if err := validator.Struct(u); err != nil {
+			return nil, fmt.Errorf("fixtures: user %d: %w", i+1, err)
+		}
+		if _, ok := rolePermissions[u.Role]; !ok {
+			return nil, fmt.Errorf("fixtures: user %q: unknown role %q", u.Username, u.Role)
+		}
+		if _, dup := accounts[u.Username]; dup {
+			return nil, fmt.Errorf("fixtures: user %q appears twice", u.Username)
+		}
+		accounts[u.Username] = Account{Password: u.Password, Role: u.Role}
+	}
+	return accounts, nil
+}
+
+// seedBooks adds books to store in one step, leaving out any with the
+// title and author of a book the store already has, so seeding twice adds
+// nothing the second time. It works through the BookRepository interface,
+// so any store, or decorator of one, can be seeded.
+func seedBooks(store BookRepository, books []Book) (ids []int, skipped int) {
+	type key struct{ title, author string }
+	have := make(map[key]bool)
+	for _, b := range store.GetBooks() {
+		have[key{b.Title, b.Author}] = true
+	}
+	var add []Book
+	for _, b := range books {
+		k := key{b.Title, b.Author}
+		if have[k] {
+			skipped++
+			continue
+		}
+		have[k] = true
+		add = append(add, b)
+	}
+	if len(add) == 0 {
+		return nil, skipped
+	}
+	return store.AddBooks(add), skipped
+}
+
+// fakeBooks makes up n valid books from seed, the same ones for the same
+// seed, with no two sharing a title and author
+func fakeBooks(seed uint64, n int) []Book {
+	f := fixtures.NewFaker(seed)
+	seen := make(map[string]int)
+	books := make([]Book, n)
+	for i := range books {
+		title, author := f.Title(), f.Name()
+		k := title + "\x00" + author
+		seen[k]++
+		if n := seen[k]; n > 1 {
+			title = fmt.Sprintf("%s, Volume %d", title, n)
+		}
+		books[i] = Book{Title: title, Author: author, Price: money.FromCents(int64(f.Between(4, 59)*100 + 99))}
+	}
+	return books
+}
+
+// runSeed runs "serve rest-api seed": it adds the books fixture, and -fake-books
+// fake books, to the store the server would open with the same flags,
+// config file and environment, or to each tenant's store
+func runSeed(args []string, lookupEnv func(string) (string, bool), out io.Writer) error {
+	fset := flag.NewFlagSet("rest-api seed", flag.ContinueOnError)
+	dir := fset.String("fixtures", "", "directory holding books.yaml, books.yml or books.json to seed from instead of the embedded fixtures")
+	fake := fset.Int("fake-books", 0, "also add this many fake books, made up from -seed")
+	seed := fset.Uint64("seed", 1, "seed for the fake books: the same seed makes the same books")
+	cfg, err := parseConfig(fset, args, lookupEnv)
+	if err != nil {
+		return err
+	}
+	if *fake < 0 {
+		return errors.New("-fake-books must not be negative")
+	}
+
+	books := sampleBooks
+	if *dir != "" {
+		if books, err = loadBookFixtures(os.DirFS(*dir)); err != nil {
+			return err
+		}
+	}
+	books = append(books[:len(books):len(books)], fakeBooks(*seed, *fake)...)
+
+	if len(cfg.Tenants) == 0 {
+		return seedStore(cfg, "", books, out)
+	}
+	for _, tenant := range cfg.Tenants {
+		tcfg, err := tenantConfig(cfg, tenant)
+		if err != nil {
+			return fmt.Errorf("configuring tenant %s: %w", tenant, err)
+		}
+		if err := seedStore(tcfg, tenant+": ", books, out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// seedStore adds books to the store cfg names and reports on it to out
+func seedStore(cfg Config, prefix string, books []Book, out io.Writer) error {
+	store, stop, err := newRepository(cfg)
+	if err != nil {
+		return fmt.Errorf("%sopening book store: %w", prefix, err)
+	}
+	ids, skipped := seedBooks(store, books)
+	stop()
+	where := cfg.DataFile
+	if repositoryKind == "memory" {
+		where = "the in-memory store, which is lost on exit (build with -tags filestore to keep them)"
+	}
+	fmt.Fprintf(out, "%sadded %d books to %s; %d were already there\n", prefix, len(ids), where, skipped)
+	return nil
+}
@@ -0,0 +1,162 @@
+package restapi
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/rehan/go-interview-prep/pkg/validator"
+)
+
+func TestEmbeddedFixtures(t *testing.T) {
+	if got, want := titles(NewBookStore().GetBooks()), []string{"The Go Programming Language", "Concurrency in Go", "Go in Action"}; !slices.Equal(got, want) {
+		t.Errorf("NewBookStore has %q; want %q", got, want)
+	}
+	for name, role := range map[string]Role{"admin": RoleAdmin, "editor": RoleEditor, "reader": RoleReader} {
+		if got, ok := newUserStore(demoAccounts).authenticate(name, name+"-password"); !ok || got != role {
+			t.Errorf("logging in as %s = %q, %v; want %q", name, got, ok, role)
+		}
+	}
+}
+
+func TestLoadFixtures_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files fstest.MapFS
+		load  func(fstest.MapFS) error
+		want  string
+	}{
+		{
+			"missing",
+			fstest.MapFS{},
+			func(fsys fstest.MapFS) error { _, err := loadBookFixtures(fsys); return err },
+			"no books.yaml, books.yml or books.json",
+		},
+		{
+			"invalid book",
+			fstest.MapFS{"books.json": {Data: []byte(`[{"title": "T", "author": "A", "price": 1}, {"title": "T", "price": 1}]`)}},
+			func(fsys fstest.MapFS) error { _, err := loadBookFixtures(fsys); return err },
+			"fixtures: book 2: author is required",
+		},
+		{
+			"unknown role",
+			fstest.MapFS{"users.yml": {Data: []byte("- username: root\n  password: p\n  role: superuser\n")}},
+			func(fsys fstest.MapFS) error { _, err := loadUserFixtures(fsys); return err },
+			`user "root": unknown role "superuser"`,
+		},
+		{
+			"flow mapping",
+			fstest.MapFS{"users.yaml": {Data: []byte("- {username: a}\n")}},
+			func(fsys fstest.MapFS) error { _, err := loadUserFixtures(fsys); return err },
+			"line 1: flow collections are not supported",
+		},
+		{
+			"duplicate user",
+			fstest.MapFS{"users.json": {Data: []byte(`[{"username": "a", "password": "p", "role": "reader"}, {"username": "a", "password": "q", "role": "admin"}]`)}},
+			func(fsys fstest.MapFS) error { _, err := loadUserFixtures(fsys); return err },
+			`user "a" appears twice`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.load(tc.files); err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("error = %v; want it to contain %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestFakeBooks(t *testing.T) {
+	books := fakeBooks(7, 500)
+	if !reflect.DeepEqual(books, fakeBooks(7, 500)) {
+		t.Error("seed 7 made different books on a second run")
+	}
+	if reflect.DeepEqual(books, fakeBooks(8, 500)) {
+		t.Error("seeds 7 and 8 made the same books")
+	}
+	seen := make(map[[2]string]bool)
+	for _, b := range books {
+		if err := validator.Struct(b); err != nil {
+			t.Fatalf("fake book %+v is invalid: %v", b, err)
+		}
+		k := [2]string{b.Title, b.Author}
+		if seen[k] {
+			t.Fatalf("two fake books are %q by %q", b.Title, b.Author)
+		}
+		seen[k] = true
+	}
+}
+
+// Seeding goes through the repository interface, so a decorated store
+// records what it adds like any other change, and seeding again adds
+// nothing
+func TestSeedBooks(t *testing.T) {
+	outbox := NewOutbox()
+	store := outboxRepository{BookRepository: NewBookStore(), outbox: outbox, actor: "seed"}
+	books := append(sampleBooks[:len(sampleBooks):len(sampleBooks)], fakeBooks(1, 5)...)
+
+	ids, skipped := seedBooks(store, books)
+	if len(ids) != 5 || skipped != 3 {
+		t.Errorf("first seed added %d and skipped %d; want 5 and the 3 sample books", len(ids), skipped)
+	}
+	if n := len(outbox.Pending()); n != 5 {
+		t.Errorf("outbox has %d records; want 5", n)
+	}
+	if ids, skipped := seedBooks(store, books); len(ids) != 0 || skipped != 8 {
+		t.Errorf("second seed added %d and skipped %d; want nothing added", len(ids), skipped)
+	}
+	if n := len(store.GetBooks()); n != 8 {
+		t.Errorf("store has %d books; want 8", n)
+	}
+}
+
+func TestRunSeed(t *testing.T) {
+	// With -tags filestore, every file and the tenants' directories beside
+	// them must land outside the package directory
+	dir := t.TempDir()
+	args := []string{"-fake-books=4", "-seed=3",
+		"-data-file=" + filepath.Join(dir, "books.json"),
+		"-keys-file=" + filepath.Join(dir, "keys.json"),
+		"-covers-dir=" + filepath.Join(dir, "covers"),
+		"-audit-file=" + filepath.Join(dir, "audit.jsonl"),
+		"-sessions-file=" + filepath.Join(dir, "sessions.json"),
+	}
+	var out bytes.Buffer
+	if err := runSeed(args, noEnv, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out.String(), "added 4 books to ") || !strings.Contains(out.String(), "; 3 were already there") {
+		t.Errorf("output = %q", out.String())
+	}
+
+	// A store that keeps its books has them all the second time
+	out.Reset()
+	if err := runSeed(args, noEnv, &out); err != nil {
+		t.Fatal(err)
+	}
+	if repositoryKind == "file" && !strings.HasPrefix(out.String(), "added 0 books") {
+		t.Errorf("second run: output = %q; want nothing added", out.String())
+	}
+
+	// Each tenant's store is seeded
+	out.Reset()
+	if err := runSeed(append(args, "-tenants=acme,globex"), noEnv, &out); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(out.String()), "\n"); len(lines) != 2 || !strings.HasPrefix(lines[0], "acme: added 4") || !strings.HasPrefix(lines[1], "globex: added 4") {
+		t.Errorf("output = %q; want a line per tenant", out.String())
+	}
+
+	for _, bad := range [][]string{{"-fake-books=-1"}, {"-fixtures=" + t.TempDir()}, {"-no-such-flag"}} {
+		if err := runSeed(bad, noEnv, &out); err == nil {
+			t.Errorf("runSeed(%q) succeeded; want an error", bad)
+		}
+	}
+}
+
+// noEnv is a LookupEnv with nothing set
+func noEnv(string) (string, bool) { return "", false }
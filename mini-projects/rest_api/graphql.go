@@ -0,0 +1,151 @@
+package restapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/errorsx"
+	"github.com/rehan/go-interview-prep/pkg/graphql"
+	"github.com/rehan/go-interview-prep/pkg/money"
+	"github.com/rehan/go-interview-prep/pkg/validator"
+)
+
+// bookType is Book in the GraphQL schema. id, title and author are read
+// from the Book by the default resolver; price and createdAt have
+// resolvers to convert them.
+var bookType = &graphql.Object{Name: "Book", Fields: graphql.Fields{
+	"id":     {Type: graphql.NewNonNull(graphql.Int)},
+	"title":  {Type: graphql.NewNonNull(graphql.String)},
+	"author": {Type: graphql.NewNonNull(graphql.String)},
+	"price": {Type: graphql.NewNonNull(graphql.Float), Resolve: func(p graphql.ResolveParams) (any, error) {
+		return p.Source.(Book).Price.Float64(), nil
+	}},
+	"createdAt": {Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (any, error) {
+		return p.Source.(Book).CreatedAt.Format(time.RFC3339), nil
+	}},
+}}
+
+// bookInputType is the book createBook takes, as POST /books does
+var bookInputType = &graphql.InputObject{Name: "BookInput", Fields: graphql.Args{
+	"title":  {Type: graphql.NewNonNull(graphql.String)},
+	"author": {Type: graphql.NewNonNull(graphql.String)},
+	"price":  {Type: graphql.NewNonNull(graphql.Float)},
+}}
+
+// graphQLMutationPermissions are the permissions each mutation needs,
+// those of the REST route doing the same
+var graphQLMutationPermissions = map[string]Permission{
+	"createBook": PermCreateBooks,
+}
+
+// bookSchema returns the schema of /graphql, resolving against store:
+//
+//	type Query {
+//	  books(author: String, filter: String, sort: String, order: String, page: Int, limit: Int): [Book!]!
+//	  book(id: Int!): Book
+//	}
+//	type Mutation {
+//	  createBook(input: BookInput!): Book!
+//	}
+//
+// The books arguments are those of GET /books (see listQuery).
+func bookSchema(store BookRepository) *graphql.Schema {
+	return &graphql.Schema{
+		Query: &graphql.Object{Name: "Query", Fields: graphql.Fields{
+			"books": {
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(bookType))),
+				Args: graphql.Args{
+					"author": {Type: graphql.String},
+					"filter": {Type: graphql.String},
+					"sort":   {Type: graphql.String},
+					"order":  {Type: graphql.String},
+					"page":   {Type: graphql.Int},
+					"limit":  {Type: graphql.Int},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					values := url.Values{}
+					for name, v := range p.Args {
+						switch v := v.(type) {
+						case string:
+							values.Set(name, v)
+						case int:
+							values.Set(name, strconv.Itoa(v))
+						}
+					}
+					q, err := parseListQuery(values)
+					if err != nil {
+						return nil, err
+					}
+					return q.apply(store.GetBooks()).Books, nil
+				},
+			},
+			"book": {
+				Type: bookType,
+				Args: graphql.Args{"id": {Type: graphql.NewNonNull(graphql.Int)}},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					if book, ok := store.GetBook(p.Args["id"].(int)); ok {
+						return book, nil
+					}
+					return nil, nil
+				},
+			},
+		}},
+		Mutation: &graphql.Object{Name: "Mutation", Fields: graphql.Fields{
+			"createBook": {
+				Type: graphql.NewNonNull(bookType),
+				Args: graphql.Args{"input": {Type: graphql.NewNonNull(bookInputType)}},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					in := p.Args["input"].(map[string]any)
+					price, err := money.Parse(strconv.FormatFloat(in["price"].(float64), 'f', -1, 64))
+					if err != nil {
+						return nil, errorsx.Wrap(err, errorsx.CodeInvalidArgument, "Invalid price")
+					}
+					book := Book{Title: in["title"].(string), Author: in["author"].(string), Price: price}
+					if err := validator.Struct(book); err != nil {
+						return nil, errorsx.Wrap(err, errorsx.CodeInvalidArgument, "Invalid book data")
+					}
+					created, _ := store.GetBook(store.AddBook(book))
+					return created, nil
+				},
+			},
+		}},
+	}
+}
+
+// handleGraphQL handles POST /graphql, whose JSON body is a query,
+// operationName and variables. The response is 200 with data and errors,
+// even if there are errors, as GraphQL clients expect; only a body that is
+// not JSON is a 400.
+//
+// Queries are public, like GET /books. A mutation is authenticated and
+// checked for the permissions of the fields it selects the way
+// requirePermission does for the REST routes, so it fails with 401 or 403
+// before anything runs, and the store it gets is storeFor the
+// authenticated request.
+func handleGraphQL(w http.ResponseWriter, r *http.Request, auth *tokenAuth, storeFor func(*http.Request) BookRepository) {
+	var req graphql.Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, errorsx.Wrap(err, errorsx.CodeInvalidArgument, "Invalid request body"))
+		return
+	}
+
+	execute := func(w http.ResponseWriter, r *http.Request) {
+		respondWithJSON(w, http.StatusOK, bookSchema(storeFor(r)).Execute(r.Context(), req))
+	}
+	// A request that does not parse runs nothing; Execute reports why
+	if doc, err := graphql.Parse(req.Query); err == nil {
+		if op, err := doc.Operation(req.OperationName); err == nil && op.Type == graphql.Mutation {
+			seen := make(map[Permission]bool)
+			for _, sel := range op.SelectionSet {
+				if perm, ok := graphQLMutationPermissions[sel.Name]; ok && !seen[perm] {
+					seen[perm] = true
+					execute = requirePermission(auth, perm)(execute)
+				}
+			}
+		}
+	}
+	execute(w, r)
+}
@@ -0,0 +1,159 @@
+package restapi
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// graphQLRequest POSTs query and variables to /graphql and returns the
+// response body
+func graphQLRequest(t *testing.T, router http.Handler, query string, variables map[string]any, header http.Header) string {
+	t.Helper()
+	body, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := auditRequest(t, router, http.MethodPost, "/graphql", string(body), header, http.StatusOK)
+	if ct := rr.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q; want application/json", ct)
+	}
+	return strings.TrimSpace(rr.Body.String())
+}
+
+func TestGraphQL_Queries(t *testing.T) {
+	auth, _ := testAuth(t)
+	router := newRouter(NewBookStore(), auth, slog.New(slog.NewTextHandler(io.Discard, nil)), nil, nil, nil, nil, nil, nil, nil, nil)
+
+	tests := []struct {
+		name      string
+		query     string
+		variables map[string]any
+		want      string
+	}{
+		{
+			"selected fields only",
+			`{ books { id title } }`, nil,
+			`{"data":{"books":[{"id":1,"title":"The Go Programming Language"},{"id":2,"title":"Concurrency in Go"},{"id":3,"title":"Go in Action"}]}}`,
+		},
+		{
+			"list arguments as GET /books takes them",
+			`{ books(sort: "price", order: "desc", limit: 2) { title price } }`, nil,
+			`{"data":{"books":[{"title":"Concurrency in Go","price":34.99},{"title":"The Go Programming Language","price":32.99}]}}`,
+		},
+		{
+			"author filter ignores case",
+			`{ books(author: "william kennedy") { id } }`, nil,
+			`{"data":{"books":[{"id":3}]}}`,
+		},
+		{
+			"filter expression",
+			`{ books(filter: "price < 33 AND title ~ \"go\"", sort: "price") { id } }`, nil,
+			`{"data":{"books":[{"id":3},{"id":1}]}}`,
+		},
+		{
+			"book by variable, with aliases",
+			`query ($id: Int!) { first: book(id: $id) { author } missing: book(id: 99) { author } }`, map[string]any{"id": 2},
+			`{"data":{"first":{"author":"Katherine Cox-Buday"},"missing":null}}`,
+		},
+		{
+			"invalid list argument",
+			`{ books(limit: 500) { id } }`, nil,
+			`{"data":null,"errors":[{"message":"limit must be at most 100","locations":[{"line":1,"column":3}],"path":["books"]}]}`,
+		},
+		{
+			"unknown field",
+			`{ books { isbn } }`, nil,
+			`{"errors":[{"message":"Cannot query field \"isbn\" on type \"Book\".","locations":[{"line":1,"column":11}]}]}`,
+		},
+		{
+			"syntax error",
+			`{ books { id }`, nil,
+			`{"errors":[{"message":"Syntax Error: Expected Name, found \u003cEOF\u003e","locations":[{"line":1,"column":15}]}]}`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := graphQLRequest(t, router, tc.query, tc.variables, nil); got != tc.want {
+				t.Errorf("response\n got %s\nwant %s", got, tc.want)
+			}
+		})
+	}
+
+	t.Run("createdAt", func(t *testing.T) {
+		var resp struct {
+			Data struct {
+				Book struct{ CreatedAt string }
+			}
+		}
+		body := graphQLRequest(t, router, `{ book(id: 1) { createdAt } }`, nil, nil)
+		if err := json.Unmarshal([]byte(body), &resp); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := time.Parse(time.RFC3339, resp.Data.Book.CreatedAt); err != nil {
+			t.Errorf("createdAt = %q; want an RFC 3339 time", resp.Data.Book.CreatedAt)
+		}
+	})
+}
+
+// createBook goes through the same store, validation and audit trail as
+// POST /books
+func TestGraphQL_CreateBook(t *testing.T) {
+	auth, _ := testAuth(t)
+	store := NewBookStore()
+	audit := NewAuditLog()
+	outbox, flush := testChanges(t, nil, nil, audit)
+	router := flushing(t, newRouter(store, auth, slog.New(slog.NewTextHandler(io.Discard, nil)), nil, nil, nil, nil, audit, outbox, nil, nil), flush)
+	bearer := http.Header{"Authorization": {"Bearer " + adminToken(t, router)}}
+	const mutation = `mutation ($in: BookInput!) { createBook(input: $in) { id title price } }`
+
+	got := graphQLRequest(t, router, mutation, map[string]any{"in": map[string]any{"title": "Learning Go", "author": "Jon Bodner", "price": 29.99}}, bearer)
+	if want := `{"data":{"createBook":{"id":4,"title":"Learning Go","price":29.99}}}`; got != want {
+		t.Errorf("response\n got %s\nwant %s", got, want)
+	}
+	if book, ok := store.GetBook(4); !ok || book.Author != "Jon Bodner" || book.Price.String() != "29.99" {
+		t.Errorf("stored book = %+v, %v; want the new book", book, ok)
+	}
+	entries := audit.Entries(AuditFilter{})
+	if len(entries) != 1 || entries[0].Actor != "user:alice" || entries[0].Action != AuditCreated || entries[0].ResourceID != "4" {
+		t.Errorf("audit entries = %s; want alice's creation of book 4", mustJSON(t, entries))
+	}
+
+	tests := []struct {
+		name  string
+		input map[string]any
+		want  string
+	}{
+		{"fails validation", map[string]any{"title": strings.Repeat("x", 201), "author": "A", "price": 1}, "Invalid book data: title must be at most 200"},
+		{"price in fractions of a cent", map[string]any{"title": "T", "author": "A", "price": 1.005}, `Invalid price: money: invalid amount: "1.005"`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var resp struct {
+				Data   any
+				Errors []struct{ Message string }
+			}
+			body := graphQLRequest(t, router, mutation, map[string]any{"in": tc.input}, bearer)
+			if err := json.Unmarshal([]byte(body), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.Data != nil || len(resp.Errors) != 1 || resp.Errors[0].Message != tc.want {
+				t.Errorf("response = %s; want null data and error %q", body, tc.want)
+			}
+		})
+	}
+	if n := len(store.GetBooks()); n != 4 {
+		t.Errorf("%d books; want no more after the failed mutations", n)
+	}
+}
+
+func TestGraphQL_BadBody(t *testing.T) {
+	auth, _ := testAuth(t)
+	router := newRouter(NewBookStore(), auth, slog.New(slog.NewTextHandler(io.Discard, nil)), nil, nil, nil, nil, nil, nil, nil, nil)
+	auditRequest(t, router, http.MethodPost, "/graphql", `{ books { id } }`, nil, http.StatusBadRequest)
+	auditRequest(t, router, http.MethodGet, "/graphql", "", nil, http.StatusMethodNotAllowed)
+}
@@ -0,0 +1,501 @@
+package restapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/csvutil"
+	"github.com/rehan/go-interview-prep/pkg/errorsx"
+	"github.com/rehan/go-interview-prep/pkg/validator"
+)
+
+// Jobs are work too slow to do while a client waits for the response.
+// POST /jobs queues one and answers 202 at once; a fixed pool of workers
+// takes jobs off the queue, and the client polls GET /jobs/{id} for its
+// status and progress until it can download the result.
+
+// The job runner's size. The queue is bounded so that a burst of
+// submissions is turned away with 429 rather than piling up without end.
+const (
+	jobWorkers   = 2
+	jobQueueSize = 100
+	// jobRetention is how long a finished job and its result are kept
+	jobRetention = time.Hour
+)
+
+// JobStatus is where a job is in its life
+type JobStatus string
+
+const (
+	JobQueued    JobStatus = "queued"
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+	JobCanceled  JobStatus = "canceled"
+)
+
+// Job is the state of one job, as GET /jobs/{id} returns it
+type Job struct {
+	ID       int       `json:"id"`
+	Kind     string    `json:"kind"`
+	Status   JobStatus `json:"status"`
+	Progress int       `json:"progress"` // percent done
+	Error    string    `json:"error,omitempty"`
+	// ResultURL is where to download the result, once the job succeeded
+	ResultURL  string     `json:"result_url,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+
+	// Owner is the actor who submitted the job; only they can see it
+	Owner string `json:"-"`
+}
+
+// finished reports whether the job has stopped for good
+func (j Job) finished() bool {
+	return j.Status == JobSucceeded || j.Status == JobFailed || j.Status == JobCanceled
+}
+
+// JobRequest is the body of POST /jobs
+type JobRequest struct {
+	Kind   string          `json:"kind" validate:"required"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// JobResult is what a job that succeeded leaves to download
+type JobResult struct {
+	ContentType string
+	Filename    string
+	Body        []byte
+}
+
+// JobKind is one kind of job. Run should return soon after ctx is done,
+// which is how a job is canceled, and report its progress as it goes.
+type JobKind struct {
+	// Check, if set, rejects bad params when the job is submitted, so the
+	// client hears of them at once rather than from a failed job
+	Check func(params json.RawMessage) error
+	Run   func(ctx context.Context, params json.RawMessage, progress func(done, total int)) (JobResult, error)
+}
+
+// jobEntry is a job with what the runner keeps beside it
+type jobEntry struct {
+	job    Job
+	params json.RawMessage
+	result JobResult
+	cancel context.CancelFunc // set while the job runs
+}
+
+// JobRunner runs jobs of the kinds it was made with on a pool of workers.
+// It is safe for concurrent use.
+type JobRunner struct {
+	kinds map[string]JobKind
+	queue chan *jobEntry
+	now   func() time.Time
+	// ctx is canceled by Close, which cancels every running job
+	ctx    context.Context
+	stop   context.CancelFunc
+	wg     sync.WaitGroup
+	mu     sync.Mutex
+	jobs   map[int]*jobEntry
+	nextID int
+	closed bool
+}
+
+// NewJobRunner starts workers goroutines running jobs of kinds, with room
+// for queueSize jobs waiting their turn
+func NewJobRunner(workers, queueSize int, kinds map[string]JobKind) *JobRunner {
+	ctx, stop := context.WithCancel(context.Background())
+	jr := &JobRunner{
+		kinds:  kinds,
+		queue:  make(chan *jobEntry, queueSize),
+		now:    time.Now,
+		ctx:    ctx,
+		stop:   stop,
+		jobs:   make(map[int]*jobEntry),
+		nextID: 1,
+	}
+	jr.wg.Add(workers)
+	for range workers {
+		go jr.work()
+	}
+	return jr
+}
+
+// errJobsClosed is returned by Submit after Close
+var errJobsClosed = errorsx.New(errorsx.CodeUnavailable, "Server is shutting down")
+
+// Submit queues a job for owner
+func (jr *JobRunner) Submit(owner string, req JobRequest) (Job, error) {
+	kind, ok := jr.kinds[req.Kind]
+	if !ok {
+		return Job{}, errorsx.Errorf(errorsx.CodeInvalidArgument, "Unknown job kind %q", req.Kind)
+	}
+	if kind.Check != nil {
+		if err := kind.Check(req.Params); err != nil {
+			return Job{}, errorsx.Wrap(err, errorsx.CodeInvalidArgument, "Invalid job params: "+err.Error())
+		}
+	}
+
+	jr.mu.Lock()
+	defer jr.mu.Unlock()
+	if jr.closed {
+		return Job{}, errJobsClosed
+	}
+	jr.prune()
+	e := &jobEntry{
+		job:    Job{ID: jr.nextID, Kind: req.Kind, Status: JobQueued, CreatedAt: jr.now(), Owner: owner},
+		params: req.Params,
+	}
+	// The send cannot block, so holding the lock for it is safe
+	select {
+	case jr.queue <- e:
+	default:
+		return Job{}, errorsx.New(errorsx.CodeResourceExhausted, "Too many jobs waiting; try again later")
+	}
+	jr.nextID++
+	jr.jobs[e.job.ID] = e
+	return e.job, nil
+}
+
+// prune forgets jobs that finished more than jobRetention ago. jr.mu must
+// be held.
+func (jr *JobRunner) prune() {
+	cutoff := jr.now().Add(-jobRetention)
+	for id, e := range jr.jobs {
+		if e.job.FinishedAt != nil && e.job.FinishedAt.Before(cutoff) {
+			delete(jr.jobs, id)
+		}
+	}
+}
+
+// lookup returns owner's job id. jr.mu must be held.
+func (jr *JobRunner) lookup(owner string, id int) (*jobEntry, error) {
+	e, ok := jr.jobs[id]
+	// Other owners' jobs are reported missing, so their IDs give nothing
+	// away
+	if !ok || e.job.Owner != owner {
+		return nil, errorsx.New(errorsx.CodeNotFound, "Job not found")
+	}
+	return e, nil
+}
+
+// Get returns owner's job id
+func (jr *JobRunner) Get(owner string, id int) (Job, error) {
+	jr.mu.Lock()
+	defer jr.mu.Unlock()
+	e, err := jr.lookup(owner, id)
+	if err != nil {
+		return Job{}, err
+	}
+	return e.job, nil
+}
+
+// Cancel stops owner's job id. A queued job never starts; a running one
+// has its context canceled, and whatever it returns is thrown away.
+func (jr *JobRunner) Cancel(owner string, id int) (Job, error) {
+	jr.mu.Lock()
+	defer jr.mu.Unlock()
+	e, err := jr.lookup(owner, id)
+	if err != nil {
+		return Job{}, err
+	}
+	if e.job.finished() {
+		return Job{}, errorsx.Errorf(errorsx.CodeConflict, "Job has already %s", e.job.Status)
+	}
+	if e.cancel != nil {
+		e.cancel()
+	}
+	jr.finish(e, JobCanceled, "")
+	return e.job, nil
+}
+
+// Result returns the result of owner's job id, which must have succeeded
+func (jr *JobRunner) Result(owner string, id int) (JobResult, error) {
+	jr.mu.Lock()
+	defer jr.mu.Unlock()
+	e, err := jr.lookup(owner, id)
+	if err != nil {
+		return JobResult{}, err
+	}
+	if e.job.Status != JobSucceeded {
+		return JobResult{}, errorsx.Errorf(errorsx.CodeConflict, "Job is %s; only a succeeded job has a result", e.job.Status)
+	}
+	return e.result, nil
+}
+
+// finish records that e stopped with status. jr.mu must be held.
+func (jr *JobRunner) finish(e *jobEntry, status JobStatus, msg string) {
+	now := jr.now()
+	e.job.Status = status
+	e.job.Error = msg
+	e.job.FinishedAt = &now
+	if status == JobSucceeded {
+		e.job.Progress = 100
+		e.job.ResultURL = fmt.Sprintf("/jobs/%d/result", e.job.ID)
+	}
+}
+
+// work runs queued jobs until Close closes the queue
+func (jr *JobRunner) work() {
+	defer jr.wg.Done()
+	for e := range jr.queue {
+		jr.run(e)
+	}
+}
+
+// run runs e unless it was canceled while it waited
+func (jr *JobRunner) run(e *jobEntry) {
+	jr.mu.Lock()
+	if e.job.Status != JobQueued {
+		jr.mu.Unlock()
+		return
+	}
+	if jr.ctx.Err() != nil {
+		jr.finish(e, JobCanceled, "server shut down before the job started")
+		jr.mu.Unlock()
+		return
+	}
+	ctx, cancel := context.WithCancel(jr.ctx)
+	defer cancel()
+	started := jr.now()
+	e.job.Status, e.job.StartedAt, e.cancel = JobRunning, &started, cancel
+	kind := jr.kinds[e.job.Kind]
+	jr.mu.Unlock()
+
+	progress := func(done, total int) {
+		jr.mu.Lock()
+		defer jr.mu.Unlock()
+		if e.job.Status == JobRunning && total > 0 {
+			e.job.Progress = min(100, max(0, done*100/total))
+		}
+	}
+	result, err := runJobKind(ctx, kind, e.params, progress)
+
+	jr.mu.Lock()
+	defer jr.mu.Unlock()
+	e.cancel = nil
+	switch {
+	case e.job.Status == JobCanceled:
+		// Cancel has already recorded it
+	case jr.ctx.Err() != nil:
+		jr.finish(e, JobCanceled, "server shut down while the job ran")
+	case err != nil:
+		jr.finish(e, JobFailed, err.Error())
+	default:
+		e.result = result
+		jr.finish(e, JobSucceeded, "")
+	}
+}
+
+// runJobKind runs kind, turning a panic into an error so one bad job
+// cannot take the server down
+func runJobKind(ctx context.Context, kind JobKind, params json.RawMessage, progress func(done, total int)) (result JobResult, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return kind.Run(ctx, params, progress)
+}
+
+// Close stops taking jobs, cancels the running ones and those still
+// queued, and waits until ctx is done for the workers to stop
+func (jr *JobRunner) Close(ctx context.Context) error {
+	jr.mu.Lock()
+	if !jr.closed {
+		jr.closed = true
+		close(jr.queue)
+	}
+	jr.mu.Unlock()
+	jr.stop()
+
+	done := make(chan struct{})
+	go func() {
+		jr.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ExportParams are the params of an export job
+type ExportParams struct {
+	Format string `json:"format"` // csv, the default, or json
+}
+
+// exportParams decodes params, which may be absent, defaulting to CSV
+func exportParams(raw json.RawMessage) (ExportParams, error) {
+	p := ExportParams{Format: "csv"}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return p, err
+		}
+	}
+	if p.Format != "csv" && p.Format != "json" {
+		return p, fmt.Errorf("format must be \"csv\" or \"json\", got %q", p.Format)
+	}
+	return p, nil
+}
+
+// jobKinds returns the kinds of job the API runs against store
+func jobKinds(store BookRepository) map[string]JobKind {
+	return map[string]JobKind{
+		"export": {
+			Check: func(params json.RawMessage) error {
+				_, err := exportParams(params)
+				return err
+			},
+			Run: func(ctx context.Context, params json.RawMessage, progress func(done, total int)) (JobResult, error) {
+				p, err := exportParams(params)
+				if err != nil {
+					return JobResult{}, err
+				}
+				return exportBooks(ctx, store, p.Format, progress)
+			},
+		},
+	}
+}
+
+// exportBooks writes every book, ordered by ID, as CSV like GET
+// /books/export or as a JSON array. It writes a book at a time, reporting
+// progress and checking whether it has been canceled between books.
+func exportBooks(ctx context.Context, store BookRepository, format string, progress func(done, total int)) (JobResult, error) {
+	books := store.GetBooks()
+	sort.Slice(books, func(i, j int) bool { return books[i].ID < books[j].ID })
+
+	var buf bytes.Buffer
+	result := JobResult{ContentType: mediaCSV + "; charset=utf-8", Filename: "books.csv"}
+	cw := csvutil.NewWriter[Book](&buf)
+	write := func(i int, b Book) error { return cw.Write(b) }
+	finish := cw.Flush
+	if format == "json" {
+		result = JobResult{ContentType: "application/json", Filename: "books.json"}
+		write = func(i int, b Book) error {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			data, err := json.Marshal(b)
+			buf.Write(data)
+			return err
+		}
+		finish = func() error {
+			_, err := buf.WriteString("]\n")
+			return err
+		}
+		buf.WriteByte('[')
+	}
+
+	for i, b := range books {
+		if err := ctx.Err(); err != nil {
+			return JobResult{}, err
+		}
+		if err := write(i, b); err != nil {
+			return JobResult{}, err
+		}
+		progress(i+1, len(books))
+	}
+	if err := finish(); err != nil {
+		return JobResult{}, err
+	}
+	result.Body = buf.Bytes()
+	return result, nil
+}
+
+// jobID returns the {id} wildcard as a job ID
+func jobID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := pathID(r)
+	if err != nil {
+		respondWithError(w, errorsx.Wrap(err, errorsx.CodeInvalidArgument, "Invalid job ID"))
+		return 0, false
+	}
+	return id, true
+}
+
+// handleCreateJob handles POST /jobs. The job is only queued, so the
+// answer is 202 Accepted with the job's URL to poll.
+func handleCreateJob(w http.ResponseWriter, r *http.Request, jobs *JobRunner) {
+	var req JobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, errorsx.Wrap(err, errorsx.CodeInvalidArgument, "Invalid request body"))
+		return
+	}
+	if err := validator.Struct(req); err != nil {
+		respondWithError(w, errorsx.Wrap(err, errorsx.CodeInvalidArgument, "Invalid job"))
+		return
+	}
+	job, err := jobs.Submit(ActorFromContext(r.Context()), req)
+	if errors.Is(err, errJobsClosed) {
+		w.Header().Set("Retry-After", "5")
+	}
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	w.Header().Set("Location", "/jobs/"+strconv.Itoa(job.ID))
+	respondWithJSON(w, http.StatusAccepted, job)
+}
+
+// handleGetJob handles GET /jobs/{id}
+func handleGetJob(w http.ResponseWriter, r *http.Request, jobs *JobRunner) {
+	id, ok := jobID(w, r)
+	if !ok {
+		return
+	}
+	job, err := jobs.Get(ActorFromContext(r.Context()), id)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	// A client polling a job that is still going is told when to look
+	// again
+	if !job.finished() {
+		w.Header().Set("Retry-After", "1")
+	}
+	respondWithJSON(w, http.StatusOK, job)
+}
+
+// handleCancelJob handles POST /jobs/{id}/cancel
+func handleCancelJob(w http.ResponseWriter, r *http.Request, jobs *JobRunner) {
+	id, ok := jobID(w, r)
+	if !ok {
+		return
+	}
+	job, err := jobs.Cancel(ActorFromContext(r.Context()), id)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, job)
+}
+
+// handleGetJobResult handles GET /jobs/{id}/result, downloading what a
+// succeeded job produced
+func handleGetJobResult(w http.ResponseWriter, r *http.Request, jobs *JobRunner) {
+	id, ok := jobID(w, r)
+	if !ok {
+		return
+	}
+	result, err := jobs.Result(ActorFromContext(r.Context()), id)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", result.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", result.Filename))
+	w.Header().Set("Content-Length", strconv.Itoa(len(result.Body)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(result.Body)
+}
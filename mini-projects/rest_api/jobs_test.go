@@ -0,0 +1,294 @@
+package restapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// jobSend sends a request to the jobs router as user
+type jobSend func(method, path, user, body string) *httptest.ResponseRecorder
+
+// testJobs returns a router running jobs of kinds on one worker with room
+// for one more in the queue, for alice (admin) and carol (editor)
+func testJobs(t *testing.T, kinds map[string]JobKind) (*JobRunner, jobSend) {
+	t.Helper()
+	auth, _ := testAuth(t)
+	auth.users = newUserStore(map[string]Account{
+		"alice": {Password: "wonderland", Role: RoleAdmin},
+		"carol": {Password: "christmas", Role: RoleEditor},
+	})
+	jobs := NewJobRunner(1, 1, kinds)
+	t.Cleanup(func() { jobs.Close(context.Background()) })
+	router := newRouter(NewBookStore(), auth, slog.New(slog.NewTextHandler(io.Discard, nil)), nil, nil, nil, nil, nil, nil, nil, jobs)
+
+	tokens := make(map[string]string)
+	for user, password := range map[string]string{"alice": "wonderland", "carol": "christmas"} {
+		var lr LoginResponse
+		if err := json.NewDecoder(login(t, router, `{"username":"`+user+`","password":"`+password+`"}`).Body).Decode(&lr); err != nil {
+			t.Fatal(err)
+		}
+		tokens[user] = lr.Token
+	}
+	return jobs, func(method, path, user, body string) *httptest.ResponseRecorder {
+		return sendWithSession(router, method, path, body, nil, "Authorization", "Bearer "+tokens[user])
+	}
+}
+
+// gateKind is a job that reports half its progress, tells started, and
+// then waits to be released or canceled
+func gateKind(started chan<- int, release <-chan struct{}) JobKind {
+	var n int
+	return JobKind{Run: func(ctx context.Context, _ json.RawMessage, progress func(done, total int)) (JobResult, error) {
+		n++
+		progress(1, 2)
+		started <- n
+		select {
+		case <-release:
+			return JobResult{ContentType: "text/plain", Filename: "gate.txt", Body: []byte("opened")}, nil
+		case <-ctx.Done():
+			return JobResult{}, ctx.Err()
+		}
+	}}
+}
+
+func decodeJob(t *testing.T, rr *httptest.ResponseRecorder) Job {
+	t.Helper()
+	var job Job
+	if err := json.NewDecoder(rr.Body).Decode(&job); err != nil {
+		t.Fatalf("decoding job: %v (status %d)", err, rr.Code)
+	}
+	return job
+}
+
+// waitJob polls job id as user until it has finished
+func waitJob(t *testing.T, send jobSend, user string, id int) Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job := decodeJob(t, send(http.MethodGet, "/jobs/"+strconv.Itoa(id), user, ""))
+		if job.finished() {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %d still %s", id, job.Status)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestJobs_Export(t *testing.T) {
+	store := NewBookStore()
+	books := store.GetBooks()
+	sort.Slice(books, func(i, j int) bool { return books[i].ID < books[j].ID })
+	var wantCSV bytes.Buffer
+	writeBooksCSV(&wantCSV, books)
+
+	tests := []struct {
+		params, contentType, filename string
+	}{
+		{``, "text/csv; charset=utf-8", "books.csv"},
+		{`,"params":{"format":"csv"}`, "text/csv; charset=utf-8", "books.csv"},
+		{`,"params":{"format":"json"}`, "application/json", "books.json"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.filename+tc.params, func(t *testing.T) {
+			_, send := testJobs(t, jobKinds(store))
+			rr := send(http.MethodPost, "/jobs", "alice", `{"kind":"export"`+tc.params+`}`)
+			if rr.Code != http.StatusAccepted || rr.Header().Get("Location") != "/jobs/1" {
+				t.Fatalf("status %d, Location %q; want 202 to /jobs/1 (body: %s)", rr.Code, rr.Header().Get("Location"), rr.Body.String())
+			}
+
+			job := waitJob(t, send, "alice", 1)
+			if job.Status != JobSucceeded || job.Progress != 100 || job.ResultURL != "/jobs/1/result" {
+				t.Fatalf("job = %+v; want succeeded at 100%% with a result", job)
+			}
+			rr = send(http.MethodGet, job.ResultURL, "alice", "")
+			if rr.Code != http.StatusOK || rr.Header().Get("Content-Type") != tc.contentType {
+				t.Fatalf("result: status %d, Content-Type %q; want 200 %s", rr.Code, rr.Header().Get("Content-Type"), tc.contentType)
+			}
+			if cd := rr.Header().Get("Content-Disposition"); !strings.Contains(cd, tc.filename) {
+				t.Errorf("Content-Disposition = %q; want %s", cd, tc.filename)
+			}
+			if tc.filename == "books.csv" {
+				if rr.Body.String() != wantCSV.String() {
+					t.Errorf("result =\n%s\nwant the same as GET /books/export:\n%s", rr.Body.String(), wantCSV.String())
+				}
+				return
+			}
+			var books []Book
+			if err := json.Unmarshal(rr.Body.Bytes(), &books); err != nil || len(books) != len(store.GetBooks()) || books[0].ID != 1 {
+				t.Errorf("result %s: %v; want every book in ID order", rr.Body.String(), err)
+			}
+		})
+	}
+}
+
+func TestJobs_ProgressAndCancel(t *testing.T) {
+	started, release := make(chan int, 2), make(chan struct{})
+	_, send := testJobs(t, map[string]JobKind{"gate": gateKind(started, release)})
+
+	send(http.MethodPost, "/jobs", "alice", `{"kind":"gate"}`)
+	<-started
+	rr := send(http.MethodGet, "/jobs/1", "alice", "")
+	if job := decodeJob(t, rr); job.Status != JobRunning || job.Progress != 50 {
+		t.Fatalf("job = %+v; want running at 50%%", job)
+	}
+	if rr.Header().Get("Retry-After") == "" {
+		t.Error("no Retry-After while the job runs")
+	}
+
+	rr = send(http.MethodPost, "/jobs/1/cancel", "alice", "")
+	if job := decodeJob(t, rr); rr.Code != http.StatusOK || job.Status != JobCanceled {
+		t.Fatalf("cancel: status %d, job %+v; want 200 and canceled", rr.Code, job)
+	}
+	// The job saw its context end, and stays canceled once it returns
+	if job := waitJob(t, send, "alice", 1); job.Status != JobCanceled {
+		t.Errorf("after returning job is %s; want canceled", job.Status)
+	}
+	if rr := send(http.MethodGet, "/jobs/1/result", "alice", ""); rr.Code != http.StatusConflict {
+		t.Errorf("result of a canceled job: status %d; want 409", rr.Code)
+	}
+	if rr := send(http.MethodPost, "/jobs/1/cancel", "alice", ""); rr.Code != http.StatusConflict {
+		t.Errorf("canceling again: status %d; want 409", rr.Code)
+	}
+}
+
+// A job canceled while it waits in the queue never runs
+func TestJobs_CancelQueued(t *testing.T) {
+	started, release := make(chan int, 2), make(chan struct{})
+	_, send := testJobs(t, map[string]JobKind{"gate": gateKind(started, release)})
+
+	send(http.MethodPost, "/jobs", "alice", `{"kind":"gate"}`)
+	<-started
+	send(http.MethodPost, "/jobs", "alice", `{"kind":"gate"}`)
+	if job := decodeJob(t, send(http.MethodGet, "/jobs/2", "alice", "")); job.Status != JobQueued {
+		t.Fatalf("second job is %s; want queued behind the first", job.Status)
+	}
+	if job := decodeJob(t, send(http.MethodPost, "/jobs/2/cancel", "alice", "")); job.Status != JobCanceled {
+		t.Fatalf("canceled job is %s", job.Status)
+	}
+
+	close(release)
+	if job := waitJob(t, send, "alice", 1); job.Status != JobSucceeded {
+		t.Errorf("first job is %s; want succeeded", job.Status)
+	}
+	if rr := send(http.MethodGet, "/jobs/1/result", "alice", ""); rr.Body.String() != "opened" {
+		t.Errorf("first job's result = %q", rr.Body.String())
+	}
+	// The canceled job may hold the one place in the queue until the
+	// worker has taken it off and skipped it
+	for deadline := time.Now().Add(5 * time.Second); send(http.MethodPost, "/jobs", "alice", `{"kind":"gate"}`).Code != http.StatusAccepted; {
+		if time.Now().After(deadline) {
+			t.Fatal("queue never emptied")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if n := <-started; n != 2 {
+		t.Errorf("job run as number %d; want the canceled one skipped", n)
+	}
+}
+
+func TestJobs_QueueFull(t *testing.T) {
+	started, release := make(chan int, 2), make(chan struct{})
+	defer close(release)
+	_, send := testJobs(t, map[string]JobKind{"gate": gateKind(started, release)})
+
+	send(http.MethodPost, "/jobs", "alice", `{"kind":"gate"}`)
+	<-started
+	if rr := send(http.MethodPost, "/jobs", "alice", `{"kind":"gate"}`); rr.Code != http.StatusAccepted {
+		t.Fatalf("second job: status %d; want 202, queued", rr.Code)
+	}
+	if rr := send(http.MethodPost, "/jobs", "alice", `{"kind":"gate"}`); rr.Code != http.StatusTooManyRequests {
+		t.Errorf("third job: status %d; want 429 with the queue full", rr.Code)
+	}
+}
+
+func TestJobs_Failed(t *testing.T) {
+	_, send := testJobs(t, map[string]JobKind{
+		"fail": {Run: func(context.Context, json.RawMessage, func(int, int)) (JobResult, error) {
+			return JobResult{}, errors.New("disk full")
+		}},
+		"panic": {Run: func(context.Context, json.RawMessage, func(int, int)) (JobResult, error) {
+			panic("bad job")
+		}},
+	})
+	send(http.MethodPost, "/jobs", "alice", `{"kind":"fail"}`)
+	if job := waitJob(t, send, "alice", 1); job.Status != JobFailed || job.Error != "disk full" {
+		t.Errorf("job = %+v; want failed with the error", job)
+	}
+	// The worker survives a panicking job
+	send(http.MethodPost, "/jobs", "alice", `{"kind":"panic"}`)
+	if job := waitJob(t, send, "alice", 2); job.Status != JobFailed || !strings.Contains(job.Error, "panicked") {
+		t.Errorf("job = %+v; want failed by a panic", job)
+	}
+}
+
+func TestJobs_Rejected(t *testing.T) {
+	_, send := testJobs(t, jobKinds(NewBookStore()))
+	tests := []struct {
+		name, body string
+	}{
+		{"no kind", `{}`},
+		{"unknown kind", `{"kind":"mine bitcoin"}`},
+		{"bad format", `{"kind":"export","params":{"format":"xml"}}`},
+		{"bad params", `{"kind":"export","params":[1]}`},
+		{"not JSON", `{`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if rr := send(http.MethodPost, "/jobs", "alice", tc.body); rr.Code != http.StatusBadRequest {
+				t.Errorf("status = %d; want 400 (body: %s)", rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+// Jobs are their owner's alone; to anyone else they do not exist
+func TestJobs_OnlyTheOwnerSees(t *testing.T) {
+	_, send := testJobs(t, jobKinds(NewBookStore()))
+	send(http.MethodPost, "/jobs", "alice", `{"kind":"export"}`)
+	waitJob(t, send, "alice", 1)
+	for _, path := range []string{"/jobs/1", "/jobs/1/result"} {
+		if rr := send(http.MethodGet, path, "carol", ""); rr.Code != http.StatusNotFound {
+			t.Errorf("carol GET %s: status %d; want 404", path, rr.Code)
+		}
+	}
+	if rr := send(http.MethodPost, "/jobs/1/cancel", "carol", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("carol cancel: status %d; want 404", rr.Code)
+	}
+}
+
+// Close cancels the running job and the queued ones, and turns new ones
+// away
+func TestJobRunner_Close(t *testing.T) {
+	started := make(chan int, 2)
+	jobs := NewJobRunner(1, 1, map[string]JobKind{"gate": gateKind(started, nil)})
+	running, _ := jobs.Submit("alice", JobRequest{Kind: "gate"})
+	<-started
+	queued, _ := jobs.Submit("alice", JobRequest{Kind: "gate"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := jobs.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []int{running.ID, queued.ID} {
+		if job, _ := jobs.Get("alice", id); job.Status != JobCanceled || job.Error == "" {
+			t.Errorf("job %d = %+v; want canceled with a reason", id, job)
+		}
+	}
+	if _, err := jobs.Submit("alice", JobRequest{Kind: "gate"}); !errors.Is(err, errJobsClosed) {
+		t.Errorf("Submit after Close = %v; want errJobsClosed", err)
+	}
+}
@@ -0,0 +1,212 @@
+package restapi
+
+import (
+	"encoding/xml"
+	"math/big"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rehan/go-interview-prep/pkg/errorsx"
+	"github.com/rehan/go-interview-prep/pkg/filter"
+	"github.com/rehan/go-interview-prep/pkg/money"
+)
+
+// Paging limits for GET /books
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// BookList is the body of GET /books: one page of books and where it sits
+// in the full, filtered result
+type BookList struct {
+	XMLName    xml.Name   `json:"-" xml:"books"`
+	Books      []Book     `json:"books" xml:"book"`
+	Pagination Pagination `json:"pagination" xml:"pagination"`
+}
+
+// Pagination describes the page returned. NextPage is null on the last
+// page, so clients can loop until it is.
+type Pagination struct {
+	Page     int  `json:"page" xml:"page"`
+	Limit    int  `json:"limit" xml:"limit"`
+	Total    int  `json:"total" xml:"total"` // books matching the filters, across all pages
+	NextPage *int `json:"next_page" xml:"next_page,omitempty"`
+}
+
+// listQuery holds the parsed query parameters of GET /books:
+//
+//	?page=2&limit=10               1-based page of at most limit books
+//	?sort=price|title&order=desc   order; by ID ascending when unset
+//	?author=Katherine%20Cox-Buday  author, ignoring case
+//	?min_price=10&max_price=30.50  inclusive price range
+//	?filter=price>20 AND author~"Kennedy"
+//	                               an expression over id, title, author and
+//	                               price (see pkg/filter)
+type listQuery struct {
+	page, limit              int
+	sortBy                   string // "id", "price" or "title"
+	desc                     bool
+	author                   string
+	minPrice, maxPrice       money.Amount
+	hasMinPrice, hasMaxPrice bool
+	filter                   filter.Expr // nil if there is none
+}
+
+// maxFilterLength bounds ?filter, which is parsed on every request
+const maxFilterLength = 1000
+
+// bookFilterFields are the fields ?filter may name
+var bookFilterFields = filter.Fields{"id": filter.Number, "title": filter.String, "author": filter.String, "price": filter.Number}
+
+// bookFilterField returns b's value for a field in bookFilterFields
+func bookFilterField(b Book) func(string) filter.Value {
+	return func(name string) filter.Value {
+		switch name {
+		case "id":
+			return filter.Int(int64(b.ID))
+		case "title":
+			return filter.Str(b.Title)
+		case "author":
+			return filter.Str(b.Author)
+		case "price":
+			return filter.Num(big.NewRat(b.Price.Cents(), 100))
+		}
+		return filter.Value{}
+	}
+}
+
+// parseListQuery validates the query parameters; errors are
+// CodeInvalidArgument with a message naming the parameter
+func parseListQuery(values url.Values) (listQuery, error) {
+	q := listQuery{page: 1, limit: defaultPageLimit, sortBy: "id"}
+
+	var err error
+	if q.page, err = positiveInt(values, "page", q.page); err != nil {
+		return q, err
+	}
+	if q.limit, err = positiveInt(values, "limit", q.limit); err != nil {
+		return q, err
+	}
+	if q.limit > maxPageLimit {
+		return q, errorsx.Errorf(errorsx.CodeInvalidArgument, "limit must be at most %d", maxPageLimit)
+	}
+
+	switch s := values.Get("sort"); s {
+	case "":
+	case "price", "title":
+		q.sortBy = s
+	default:
+		return q, errorsx.New(errorsx.CodeInvalidArgument, `sort must be "price" or "title"`)
+	}
+	switch values.Get("order") {
+	case "", "asc":
+	case "desc":
+		q.desc = true
+	default:
+		return q, errorsx.New(errorsx.CodeInvalidArgument, `order must be "asc" or "desc"`)
+	}
+
+	q.author = values.Get("author")
+	if q.minPrice, q.hasMinPrice, err = price(values, "min_price"); err != nil {
+		return q, err
+	}
+	if q.maxPrice, q.hasMaxPrice, err = price(values, "max_price"); err != nil {
+		return q, err
+	}
+	if q.hasMinPrice && q.hasMaxPrice && q.minPrice > q.maxPrice {
+		return q, errorsx.New(errorsx.CodeInvalidArgument, "min_price must not be greater than max_price")
+	}
+	if src := values.Get("filter"); src != "" {
+		if len(src) > maxFilterLength {
+			return q, errorsx.Errorf(errorsx.CodeInvalidArgument, "filter must be at most %d bytes", maxFilterLength)
+		}
+		if q.filter, err = filter.Parse(src, bookFilterFields); err != nil {
+			return q, errorsx.Wrap(err, errorsx.CodeInvalidArgument, "")
+		}
+	}
+	return q, nil
+}
+
+// positiveInt reads an integer parameter that must be at least 1
+func positiveInt(values url.Values, name string, def int) (int, error) {
+	s := values.Get(name)
+	if s == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		return 0, errorsx.Errorf(errorsx.CodeInvalidArgument, "%s must be a positive integer", name)
+	}
+	return n, nil
+}
+
+// price reads an optional money parameter
+func price(values url.Values, name string) (money.Amount, bool, error) {
+	s := values.Get(name)
+	if s == "" {
+		return 0, false, nil
+	}
+	a, err := money.Parse(s)
+	if err != nil {
+		return 0, false, errorsx.Errorf(errorsx.CodeInvalidArgument, "%s must be an amount such as 12.50", name)
+	}
+	return a, true, nil
+}
+
+// apply filters, sorts and pages books
+func (q listQuery) apply(books []Book) BookList {
+	matched := books[:0:0]
+	for _, b := range books {
+		if q.author != "" && !strings.EqualFold(b.Author, q.author) {
+			continue
+		}
+		if q.hasMinPrice && b.Price < q.minPrice || q.hasMaxPrice && b.Price > q.maxPrice {
+			continue
+		}
+		if q.filter != nil && !q.filter.Match(bookFilterField(b)) {
+			continue
+		}
+		matched = append(matched, b)
+	}
+
+	// Ties fall back to ID so equal prices or titles keep a stable order
+	// from one page to the next
+	less := func(a, b Book) bool {
+		switch q.sortBy {
+		case "price":
+			if a.Price != b.Price {
+				return a.Price < b.Price
+			}
+		case "title":
+			if a.Title != b.Title {
+				return a.Title < b.Title
+			}
+		}
+		return a.ID < b.ID
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if q.desc {
+			return less(matched[j], matched[i])
+		}
+		return less(matched[i], matched[j])
+	})
+
+	list := BookList{
+		Books:      []Book{}, // [] rather than null past the last page
+		Pagination: Pagination{Page: q.page, Limit: q.limit, Total: len(matched)},
+	}
+	// Compare by division so a huge page cannot overflow page*limit
+	if (q.page - 1) < (len(matched)+q.limit-1)/q.limit {
+		start := (q.page - 1) * q.limit
+		end := min(start+q.limit, len(matched))
+		list.Books = matched[start:end]
+		if end < len(matched) {
+			next := q.page + 1
+			list.Pagination.NextPage = &next
+		}
+	}
+	return list
+}
@@ -0,0 +1,143 @@
+package restapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rehan/go-interview-prep/pkg/errorsx"
+	"github.com/rehan/go-interview-prep/pkg/money"
+)
+
+// listStore holds five books with a price tie (ids 2 and 4) and a title
+// tie (ids 3 and 5) so tests can check the ID tie-break
+func listStore() *BookStore {
+	store := &BookStore{books: make(map[int]Book), nextID: 1}
+	for _, b := range []Book{
+		{Title: "Go in Action", Author: "William Kennedy", Price: money.MustParse("24.99")},
+		{Title: "Concurrency in Go", Author: "Katherine Cox-Buday", Price: money.MustParse("34.99")},
+		{Title: "Learning Go", Author: "Jon Bodner", Price: money.MustParse("29.99")},
+		{Title: "Cloud Native Go", Author: "Matthew Titmus", Price: money.MustParse("34.99")},
+		{Title: "Learning Go", Author: "Jon Bodner", Price: money.MustParse("39.99")},
+	} {
+		store.AddBook(b)
+	}
+	return store
+}
+
+func getBookList(t *testing.T, store BookRepository, query string) (*httptest.ResponseRecorder, BookList) {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	handleGetBooks(rr, httptest.NewRequest(http.MethodGet, "/books"+query, nil), store)
+
+	var list BookList
+	if rr.Code == http.StatusOK {
+		if err := json.NewDecoder(rr.Body).Decode(&list); err != nil {
+			t.Fatalf("decoding %s: %v", query, err)
+		}
+	}
+	return rr, list
+}
+
+func ids(books []Book) []int {
+	out := []int{}
+	for _, b := range books {
+		out = append(out, b.ID)
+	}
+	return out
+}
+
+func TestGetBooks_Query(t *testing.T) {
+	next := func(n int) *int { return &n }
+	tests := []struct {
+		name     string
+		query    string
+		wantIDs  []int
+		wantPage Pagination
+	}{
+		{"defaults", "", []int{1, 2, 3, 4, 5}, Pagination{Page: 1, Limit: 20, Total: 5}},
+		{"first page", "?limit=2", []int{1, 2}, Pagination{Page: 1, Limit: 2, Total: 5, NextPage: next(2)}},
+		{"middle page", "?limit=2&page=2", []int{3, 4}, Pagination{Page: 2, Limit: 2, Total: 5, NextPage: next(3)}},
+		{"last partial page", "?limit=2&page=3", []int{5}, Pagination{Page: 3, Limit: 2, Total: 5}},
+		{"exact last page", "?limit=5", []int{1, 2, 3, 4, 5}, Pagination{Page: 1, Limit: 5, Total: 5}},
+		{"past the end", "?limit=2&page=4", []int{}, Pagination{Page: 4, Limit: 2, Total: 5}},
+		{"huge page", "?page=9223372036854775807&limit=100", []int{}, Pagination{Page: 9223372036854775807, Limit: 100, Total: 5}},
+		{"sort by price", "?sort=price", []int{1, 3, 2, 4, 5}, Pagination{Page: 1, Limit: 20, Total: 5}},
+		{"sort by price desc", "?sort=price&order=desc", []int{5, 4, 2, 3, 1}, Pagination{Page: 1, Limit: 20, Total: 5}},
+		{"sort by title", "?sort=title&order=asc", []int{4, 2, 1, 3, 5}, Pagination{Page: 1, Limit: 20, Total: 5}},
+		{"order alone reverses IDs", "?order=desc", []int{5, 4, 3, 2, 1}, Pagination{Page: 1, Limit: 20, Total: 5}},
+		{"author ignores case", "?author=jon%20bodner", []int{3, 5}, Pagination{Page: 1, Limit: 20, Total: 2}},
+		{"unknown author", "?author=Nobody", []int{}, Pagination{Page: 1, Limit: 20, Total: 0}},
+		{"price range is inclusive", "?min_price=29.99&max_price=34.99", []int{2, 3, 4}, Pagination{Page: 1, Limit: 20, Total: 3}},
+		{"min price only", "?min_price=35", []int{5}, Pagination{Page: 1, Limit: 20, Total: 1}},
+		{"max price only", "?max_price=25", []int{1}, Pagination{Page: 1, Limit: 20, Total: 1}},
+		{"filters then pages", "?min_price=25&sort=price&limit=2&page=2", []int{4, 5}, Pagination{Page: 2, Limit: 2, Total: 4}},
+		{"filter expression", "?filter=price%3E30%20AND%20author~%22bodner%22", []int{5}, Pagination{Page: 1, Limit: 20, Total: 1}},
+		{"filter with or and not", "?filter=(id%3D1%20OR%20title~%22native%22)%20AND%20NOT%20price%3D34.99", []int{1}, Pagination{Page: 1, Limit: 20, Total: 1}},
+		{"filter compares price exactly", "?filter=price%3D34.990", []int{2, 4}, Pagination{Page: 1, Limit: 20, Total: 2}},
+		{"filter with other parameters", "?filter=title%3D%22Learning%20Go%22&max_price=30", []int{3}, Pagination{Page: 1, Limit: 20, Total: 1}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr, list := getBookList(t, listStore(), tc.query)
+			if rr.Code != http.StatusOK {
+				t.Fatalf("status = %d; want 200 (body: %s)", rr.Code, rr.Body.String())
+			}
+			if got := ids(list.Books); !reflect.DeepEqual(got, tc.wantIDs) {
+				t.Errorf("ids = %v; want %v", got, tc.wantIDs)
+			}
+			if !reflect.DeepEqual(list.Pagination, tc.wantPage) {
+				t.Errorf("pagination = %+v; want %+v", list.Pagination, tc.wantPage)
+			}
+		})
+	}
+}
+
+// An empty page must be [] so clients can iterate it without a null check
+func TestGetBooks_EmptyPageIsArray(t *testing.T) {
+	rr := httptest.NewRecorder()
+	handleGetBooks(rr, httptest.NewRequest(http.MethodGet, "/books?page=99", nil), listStore())
+	if !strings.Contains(rr.Body.String(), `"books":[]`) || !strings.Contains(rr.Body.String(), `"next_page":null`) {
+		t.Errorf("body = %s; want an empty books array and a null next_page", rr.Body.String())
+	}
+}
+
+func TestGetBooks_InvalidQuery(t *testing.T) {
+	tests := []struct {
+		query       string
+		wantMessage string
+	}{
+		{"?page=0", "page must be a positive integer"},
+		{"?page=-1", "page must be a positive integer"},
+		{"?page=two", "page must be a positive integer"},
+		{"?limit=0", "limit must be a positive integer"},
+		{"?limit=101", "limit must be at most 100"},
+		{"?sort=author", `sort must be "price" or "title"`},
+		{"?order=up", `order must be "asc" or "desc"`},
+		{"?min_price=cheap", "min_price must be an amount such as 12.50"},
+		{"?max_price=1.999", "max_price must be an amount such as 12.50"},
+		{"?min_price=30&max_price=20", "min_price must not be greater than max_price"},
+		{"?filter=price%3E", "filter: expected a string or number, found end of filter at offset 6"},
+		{"?filter=isbn%3D%221%22", `filter: unknown field "isbn" at offset 0`},
+		{"?filter=price~%223%22", "filter: price is a number, compared with a string at offset 6"},
+		{"?filter=" + strings.Repeat("x", maxFilterLength+1), "filter must be at most 1000 bytes"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.query, func(t *testing.T) {
+			rr, _ := getBookList(t, listStore(), tc.query)
+			if rr.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d; want 400", rr.Code)
+			}
+			var body Problem
+			if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Code != errorsx.CodeInvalidArgument || body.Detail != tc.wantMessage {
+				t.Errorf("problem = %+v; want invalid_argument %q", body, tc.wantMessage)
+			}
+		})
+	}
+}
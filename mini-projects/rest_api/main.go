@@ -1,22 +1,72 @@
-package main
+package restapi
 
 import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	_ "embed"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"html/template"
+	"log/slog"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"sort"
 	"strconv"
+	"strings"
 	"sync"
+	"syscall"
 	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/config"
+	"github.com/rehan/go-interview-prep/pkg/dispatch"
+	"github.com/rehan/go-interview-prep/pkg/errorsx"
+	"github.com/rehan/go-interview-prep/pkg/jwt"
+	"github.com/rehan/go-interview-prep/pkg/metrics"
+	"github.com/rehan/go-interview-prep/pkg/money"
+	"github.com/rehan/go-interview-prep/pkg/pubsub"
+	"github.com/rehan/go-interview-prep/pkg/validator"
 )
 
+// apiVersion is the version of the JSON shapes the API responds with, sent
+// in the API-Version header. A change to the shape of a response, a field
+// added, removed, renamed or of another type, can break clients that
+// decode it, so it comes with a new version. The snapshots of each
+// version's responses in testdata/api fail the tests until it does (see
+// api_compat_test.go).
+const apiVersion = 1
+
 // Book represents book data
 type Book struct {
-	ID        int       `json:"id"`
-	Title     string    `json:"title"`
-	Author    string    `json:"author"`
-	Price     float64   `json:"price"`
-	CreatedAt time.Time `json:"created_at"`
+	ID        int          `json:"id" xml:"id,attr" csv:"id"`
+	Title     string       `json:"title" xml:"title" csv:"title" validate:"required,max=200"`
+	Author    string       `json:"author" xml:"author" csv:"author" validate:"required,max=200"`
+	Price     money.Amount `json:"price" xml:"price" csv:"price" validate:"required,min=0.01"`
+	CreatedAt time.Time    `json:"created_at" xml:"created_at" csv:"created_at"`
+}
+
+// BookRepository is the storage the handlers depend on. The build selects
+// the implementation: BookStore in memory by default, or FileBookStore
+// with -tags filestore (see store_memory.go and store_file.go). Handler
+// tests use BookRepositoryMock, generated by go generate; every real
+// implementation is run through the shared contract in repository_test.go.
+//
+//go:generate go run ../../cmd/mockgen -type=BookRepository -out=bookrepository_mock_test.go
+type BookRepository interface {
+	GetBooks() []Book
+	GetBook(id int) (Book, bool)
+	AddBook(book Book) int
+	AddBooks(books []Book) []int
+	UpdateBook(id int, book Book) bool
+	DeleteBook(id int) bool
+
+	// Begin starts a transaction, for changes that must all be made or
+	// none (see Tx)
+	Begin() (Tx, error)
 }
 
 // BookStore manages a collection of books with thread-safety
@@ -27,32 +77,16 @@ type BookStore struct {
 	idCounter int
 }
 
-// NewBookStore creates a new BookStore with some sample data
+// NewBookStore creates a new BookStore with some sample data, the books
+// in fixtures/books.yaml
 func NewBookStore() *BookStore {
 	store := &BookStore{
 		books:  make(map[int]Book),
 		nextID: 1,
 	}
-
-	// Add some sample books
-	store.AddBook(Book{
-		Title:  "The Go Programming Language",
-		Author: "Alan A. A. Donovan and Brian W. Kernighan",
-		Price:  32.99,
-	})
-
-	store.AddBook(Book{
-		Title:  "Concurrency in Go",
-		Author: "Katherine Cox-Buday",
-		Price:  34.99,
-	})
-
-	store.AddBook(Book{
-		Title:  "Go in Action",
-		Author: "William Kennedy",
-		Price:  24.99,
-	})
-
+	for _, book := range sampleBooks {
+		store.AddBook(book)
+	}
 	return store
 }
 
@@ -93,6 +127,24 @@ func (bs *BookStore) AddBook(book Book) int {
 	return book.ID
 }
 
+// AddBooks adds books in one step, so readers see all of them or none,
+// and returns their IDs in order
+func (bs *BookStore) AddBooks(books []Book) []int {
+	bs.Lock()
+	defer bs.Unlock()
+
+	ids := make([]int, len(books))
+	now := time.Now()
+	for i, book := range books {
+		book.ID = bs.nextID
+		book.CreatedAt = now
+		bs.books[book.ID] = book
+		bs.nextID++
+		ids[i] = book.ID
+	}
+	return ids
+}
+
 // UpdateBook updates an existing book
 func (bs *BookStore) UpdateBook(id int, book Book) bool {
 	bs.Lock()
@@ -128,59 +180,63 @@ func (bs *BookStore) DeleteBook(id int) bool {
 
 // API handler functions
 
-// handleGetBooks handles GET requests for all books
-func handleGetBooks(w http.ResponseWriter, r *http.Request, store *BookStore) {
+// handleGetBooks handles GET requests for the book list, one page at a
+// time, filtered and sorted as the query parameters ask (see listQuery),
+// in the representation the Accept header asks for
+func handleGetBooks(w http.ResponseWriter, r *http.Request, store BookRepository) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		respondWithError(w, errorsx.New(errorsx.CodeMethodNotAllowed, "Method not allowed"))
 		return
 	}
 
-	books := store.GetBooks()
-	respondWithJSON(w, http.StatusOK, books)
+	q, err := parseListQuery(r.URL.Query())
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	respondWithBookList(w, r, q.apply(store.GetBooks()))
 }
 
 // handleGetBook handles GET requests for a specific book
-func handleGetBook(w http.ResponseWriter, r *http.Request, store *BookStore) {
+func handleGetBook(w http.ResponseWriter, r *http.Request, store BookRepository) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		respondWithError(w, errorsx.New(errorsx.CodeMethodNotAllowed, "Method not allowed"))
 		return
 	}
 
-	// Extract ID from URL path
-	// Expecting /books/{id}
-	id, err := extractIDFromPath(r.URL.Path, "/books/")
+	id, err := pathID(r)
 	if err != nil {
-		http.Error(w, "Invalid book ID", http.StatusBadRequest)
+		respondWithError(w, errorsx.Wrap(err, errorsx.CodeInvalidArgument, "Invalid book ID"))
 		return
 	}
 
 	book, exists := store.GetBook(id)
 	if !exists {
-		http.Error(w, "Book not found", http.StatusNotFound)
+		respondWithError(w, errorsx.New(errorsx.CodeNotFound, "Book not found"))
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, book)
+	respondWithBook(w, r, http.StatusOK, book)
 }
 
 // handleCreateBook handles POST requests to create a book
-func handleCreateBook(w http.ResponseWriter, r *http.Request, store *BookStore) {
+func handleCreateBook(w http.ResponseWriter, r *http.Request, store BookRepository) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		respondWithError(w, errorsx.New(errorsx.CodeMethodNotAllowed, "Method not allowed"))
 		return
 	}
 
 	// Parse request body
 	var book Book
-	err := json.NewDecoder(r.Body).Decode(&book)
+	err := decodeBook(r, &book)
 	if err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		respondWithError(w, errorsx.Wrap(err, errorsx.CodeInvalidArgument, "Invalid request body"))
 		return
 	}
 
 	// Validate book data
-	if book.Title == "" || book.Author == "" || book.Price <= 0 {
-		http.Error(w, "Invalid book data: title, author and price are required", http.StatusBadRequest)
+	if err := validator.Struct(book); err != nil {
+		respondWithError(w, errorsx.Wrap(err, errorsx.CodeInvalidArgument, "Invalid book data"))
 		return
 	}
 
@@ -189,67 +245,65 @@ func handleCreateBook(w http.ResponseWriter, r *http.Request, store *BookStore)
 
 	// Return the created book with its ID
 	createdBook, _ := store.GetBook(id)
-	respondWithJSON(w, http.StatusCreated, createdBook)
+	respondWithBook(w, r, http.StatusCreated, createdBook)
 }
 
 // handleUpdateBook handles PUT requests to update a book
-func handleUpdateBook(w http.ResponseWriter, r *http.Request, store *BookStore) {
+func handleUpdateBook(w http.ResponseWriter, r *http.Request, store BookRepository) {
 	if r.Method != http.MethodPut {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		respondWithError(w, errorsx.New(errorsx.CodeMethodNotAllowed, "Method not allowed"))
 		return
 	}
 
-	// Extract ID from URL path
-	id, err := extractIDFromPath(r.URL.Path, "/books/")
+	id, err := pathID(r)
 	if err != nil {
-		http.Error(w, "Invalid book ID", http.StatusBadRequest)
+		respondWithError(w, errorsx.Wrap(err, errorsx.CodeInvalidArgument, "Invalid book ID"))
 		return
 	}
 
 	// Parse request body
 	var book Book
-	err = json.NewDecoder(r.Body).Decode(&book)
+	err = decodeBook(r, &book)
 	if err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		respondWithError(w, errorsx.Wrap(err, errorsx.CodeInvalidArgument, "Invalid request body"))
 		return
 	}
 
 	// Validate book data
-	if book.Title == "" || book.Author == "" || book.Price <= 0 {
-		http.Error(w, "Invalid book data: title, author and price are required", http.StatusBadRequest)
+	if err := validator.Struct(book); err != nil {
+		respondWithError(w, errorsx.Wrap(err, errorsx.CodeInvalidArgument, "Invalid book data"))
 		return
 	}
 
 	// Update book
 	success := store.UpdateBook(id, book)
 	if !success {
-		http.Error(w, "Book not found", http.StatusNotFound)
+		respondWithError(w, errorsx.New(errorsx.CodeNotFound, "Book not found"))
 		return
 	}
 
 	// Return the updated book
 	updatedBook, _ := store.GetBook(id)
-	respondWithJSON(w, http.StatusOK, updatedBook)
+	respondWithBook(w, r, http.StatusOK, updatedBook)
 }
 
 // handleDeleteBook handles DELETE requests to delete a book
-func handleDeleteBook(w http.ResponseWriter, r *http.Request, store *BookStore) {
+func handleDeleteBook(w http.ResponseWriter, r *http.Request, store BookRepository) {
 	if r.Method != http.MethodDelete {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		respondWithError(w, errorsx.New(errorsx.CodeMethodNotAllowed, "Method not allowed"))
 		return
 	}
 
-	// Extract ID from URL path
-	id, err := extractIDFromPath(r.URL.Path, "/books/")
+	id, err := pathID(r)
 	if err != nil {
-		http.Error(w, "Invalid book ID", http.StatusBadRequest)
+		respondWithError(w, errorsx.Wrap(err, errorsx.CodeInvalidArgument, "Invalid book ID"))
 		return
 	}
 
 	// Delete book
 	success := store.DeleteBook(id)
 	if !success {
-		http.Error(w, "Book not found", http.StatusNotFound)
+		respondWithError(w, errorsx.New(errorsx.CodeNotFound, "Book not found"))
 		return
 	}
 
@@ -257,6 +311,40 @@ func handleDeleteBook(w http.ResponseWriter, r *http.Request, store *BookStore)
 	w.WriteHeader(http.StatusNoContent)
 }
 
+//go:embed templates/books.html
+var booksPageSource string
+
+// pageFuncs are the functions the HTML pages' templates may call
+var pageFuncs = template.FuncMap{
+	"price": func(p money.Amount) string { return "$" + p.String() },
+}
+
+// booksPage is parsed at start-up; html/template escapes book fields, so a
+// title containing markup is shown as text
+var booksPage = template.Must(template.New("books").Funcs(pageFuncs).Parse(booksPageSource))
+
+// handleBooksHTML handles GET requests for the book list as an HTML page
+func handleBooksHTML(w http.ResponseWriter, r *http.Request, store BookRepository) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, errorsx.New(errorsx.CodeMethodNotAllowed, "Method not allowed"))
+		return
+	}
+
+	books := store.GetBooks()
+	sort.Slice(books, func(i, j int) bool { return books[i].ID < books[j].ID })
+
+	// Render into a buffer so a template error cannot send half a page
+	// with a 200 status
+	var buf bytes.Buffer
+	if err := booksPage.Execute(&buf, books); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	buf.WriteTo(w)
+}
+
 // Utility functions
 
 // respondWithJSON writes a JSON response
@@ -266,12 +354,75 @@ func respondWithJSON(w http.ResponseWriter, status int, data interface{}) {
 	json.NewEncoder(w).Encode(data)
 }
 
-// extractIDFromPath extracts and validates ID from URL path
-func extractIDFromPath(path, prefix string) (int, error) {
-	// Remove prefix from path
-	idStr := path[len(prefix):]
+// problemContentType is the media type of error bodies (RFC 7807)
+const problemContentType = "application/problem+json"
+
+// Problem is the body of every error response, an RFC 7807 problem
+// details object. Type is always "about:blank", which the RFC defines as
+// "nothing beyond the HTTP status", so Title is the status text. Code and
+// Errors are extension members: Code is the stable value clients should
+// switch on, and Errors lists each invalid field of a rejected body.
+type Problem struct {
+	Type   string         `json:"type"`
+	Title  string         `json:"title"`
+	Status int            `json:"status"`
+	Detail string         `json:"detail,omitempty"`
+	Code   errorsx.Code   `json:"code"`
+	Errors []FieldProblem `json:"errors,omitempty"`
+
+	// RequestID lets a client quote the failed request when reporting it
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// FieldProblem describes one field that failed validation
+type FieldProblem struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// respondWithError writes err as a problem+json body. The status comes
+// from the error's code, and validator errors in err's chain become field
+// problems. Internal errors are logged with their stack trace and their
+// details are hidden from the client. The request ID comes from the
+// response header requestIDMiddleware set.
+func respondWithError(w http.ResponseWriter, err error) {
+	problem := newProblem(err, w.Header().Get(requestIDHeader))
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(problem.Status)
+	json.NewEncoder(w).Encode(problem)
+}
+
+// newProblem describes err as respondWithError sends it, logging it first
+// if it is internal
+func newProblem(err error, requestID string) Problem {
+	code := errorsx.CodeOf(err)
+	if code == errorsx.CodeInternal {
+		slog.Error("internal error", "error", fmt.Sprintf("%+v", err), "request_id", requestID)
+	}
+
+	status := code.HTTPStatus()
+	problem := Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    errorsx.PublicMessage(err),
+		Code:      code,
+		RequestID: requestID,
+	}
+	var fieldErrs validator.Errors
+	if code != errorsx.CodeInternal && errors.As(err, &fieldErrs) {
+		for _, fe := range fieldErrs {
+			problem.Errors = append(problem.Errors, FieldProblem{Field: fe.Field, Rule: fe.Rule, Message: fe.Error()})
+		}
+	}
+	return problem
+}
 
-	// Convert to integer
+// pathID returns the {id} wildcard of the matched route, which must be a
+// positive integer
+func pathID(r *http.Request) (int, error) {
+	idStr := r.PathValue("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil || id <= 0 {
 		return 0, fmt.Errorf("invalid ID: %s", idStr)
@@ -283,12 +434,107 @@ func extractIDFromPath(path, prefix string) (int, error) {
 // Define a middleware type
 type Middleware func(http.HandlerFunc) http.HandlerFunc
 
-// loggingMiddleware logs request information
-func loggingMiddleware(next http.HandlerFunc) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		startTime := time.Now()
-		next(w, r)
-		log.Printf("%s %s %v", r.Method, r.URL.Path, time.Since(startTime))
+// statusRecorder remembers the status code and body size a handler wrote
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+// WriteHeader records the first status only, as net/http ignores (and
+// logs) any later call
+func (rec *statusRecorder) WriteHeader(code int) {
+	if !rec.wroteHeader {
+		rec.status = code
+		rec.wroteHeader = true
+	}
+	rec.ResponseWriter.WriteHeader(code)
+}
+
+// Write counts the bytes written; writing before WriteHeader means 200
+func (rec *statusRecorder) Write(b []byte) (int, error) {
+	if !rec.wroteHeader {
+		rec.WriteHeader(http.StatusOK)
+	}
+	n, err := rec.ResponseWriter.Write(b)
+	rec.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the writer underneath, to
+// flush a stream or change its deadlines
+func (rec *statusRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// loggingMiddleware logs one structured record per request and, if m is
+// not nil, records the request in its metrics. The record has the route's
+// pattern as well as the path: the pattern groups requests to /books/1
+// and /books/2 together, and it alone labels the metrics.
+func loggingMiddleware(logger *slog.Logger, m *httpMetrics) Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if m != nil {
+				m.inFlight.Inc()
+				defer m.inFlight.Dec()
+			}
+			startTime := time.Now()
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			next(rec, r)
+			elapsed := time.Since(startTime)
+			logger.LogAttrs(r.Context(), slog.LevelInfo, "request",
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.String("pattern", r.Pattern),
+				slog.Int("status", rec.status),
+				slog.Int64("bytes", rec.bytes),
+				slog.Duration("duration", elapsed),
+			)
+			if m != nil {
+				m.observe(r.Method, r.Pattern, rec.status, rec.bytes, elapsed)
+			}
+		}
+	}
+}
+
+// recoveryMiddleware turns a panic in a handler into an error response
+// rather than a dropped connection. A store whose methods have no error
+// result, such as a BookRepository, can only fail by panicking; a panic
+// with an error responds as that error, so a store that is unavailable
+// answers 503, and anything else is an internal error.
+// http.ErrAbortHandler is panicked again, as it asks net/http to abort.
+func recoveryMiddleware() Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				p := recover()
+				if p == nil {
+					return
+				}
+				if p == http.ErrAbortHandler {
+					panic(p)
+				}
+				err, ok := p.(error)
+				if !ok {
+					err = errorsx.Errorf(errorsx.CodeInternal, "panic: %v", p)
+				}
+				respondWithError(w, err)
+			}()
+			next(w, r)
+		}
+	}
+}
+
+// versionMiddleware sends apiVersion with every response, so a client can
+// tell which shapes it got
+func versionMiddleware() Middleware {
+	version := strconv.Itoa(apiVersion)
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("API-Version", version)
+			next(w, r)
+		}
 	}
 }
 
@@ -300,57 +546,634 @@ func applyMiddleware(handler http.HandlerFunc, middlewares ...Middleware) http.H
 	return handler
 }
 
-func main() {
-	// Create book store
-	store := NewBookStore()
+// Config holds the server settings. Each can come from a JSON or YAML file
+// (-config), a BOOKS_* environment variable, or a flag, in increasing order
+// of precedence.
+type Config struct {
+	Addr      string `config:"addr" validate:"required"`
+	PprofAddr string `config:"pprof"`
+	LogFormat string `config:"log_format"`
+	DataFile  string `config:"data_file"`
+	KeysFile  string `config:"keys_file"`
+	CoversDir string `config:"covers_dir"`
+	AuditFile string `config:"audit_file"`
+
+	// SessionsFile holds browser sessions with -tags filestore
+	SessionsFile string `config:"sessions_file"`
+
+	// SnapshotInterval is how often the file store copies its data file to
+	// <data_file>.snapshot; zero disables snapshots
+	SnapshotInterval time.Duration `config:"snapshot_interval" validate:"min=0"`
+
+	// JWTSecret signs login tokens and must be at least 32 bytes; empty
+	// means a random key per process
+	JWTSecret string        `config:"jwt_secret"`
+	TokenTTL  time.Duration `config:"token_ttl" validate:"min=1"`
+
+	// SessionTTL is how long a browser session lasts after login
+	SessionTTL time.Duration `config:"session_ttl" validate:"min=1"`
+
+	// InsecureCookies leaves the Secure flag off the session cookie, for
+	// browsers that will not send Secure cookies to a plain-HTTP host
+	InsecureCookies bool `config:"insecure_cookies"`
+
+	// TLSCert and TLSKey are PEM files to serve HTTPS with; empty serves
+	// plain HTTP. With TLSSelfSigned, a self-signed certificate for
+	// localhost is written to them first if TLSCert does not exist.
+	TLSCert       string `config:"tls_cert"`
+	TLSKey        string `config:"tls_key"`
+	TLSSelfSigned bool   `config:"tls_self_signed"`
+
+	// RedirectAddr, with TLS on, serves plain HTTP redirecting to HTTPS
+	RedirectAddr string `config:"redirect_addr"`
+
+	// HSTSMaxAge, with TLS on, is how long browsers are told to use only
+	// HTTPS for the host. Zero sends no header: a browser remembers it, so
+	// a long max-age on a development host such as localhost outlives the
+	// server that sent it.
+	HSTSMaxAge time.Duration `config:"hsts_max_age" validate:"min=0"`
+
+	// PaymentURL is the payment provider's API; empty leaves out the
+	// order routes. PaymentWebhookSecret, which must be at least 32 bytes,
+	// checks the signatures on its webhooks.
+	PaymentURL           string `config:"payment_url"`
+	PaymentWebhookSecret string `config:"payment_webhook_secret"`
+
+	// FakePayments runs a fake payment provider in the process instead,
+	// which settles each payment a moment after it is made
+	FakePayments bool `config:"fake_payments"`
+
+	// Tenants, if set, gives each tenant named an API of its own, which a
+	// request picks with the X-Tenant-ID header or, with TenantDomain set,
+	// by host: acme.<tenant_domain> is tenant acme. With -tags filestore
+	// each tenant's files go in a directory named for it.
+	Tenants      []string `config:"tenants"`
+	TenantDomain string   `config:"tenant_domain"`
+
+	// TenantRateLimit is the requests a minute and TenantQuota the
+	// requests a day each tenant may make; zero is unlimited
+	TenantRateLimit int `config:"tenant_rate_limit" validate:"min=0"`
+	TenantQuota     int `config:"tenant_quota" validate:"min=0"`
+
+	// CacheTTL is how long public GET responses are cached; zero disables
+	// the cache. Changes to books empty it early.
+	CacheTTL time.Duration `config:"cache_ttl" validate:"min=0"`
+
+	// ShutdownTimeout is how long in-flight requests get to finish after
+	// SIGINT or SIGTERM before their connections are closed
+	ShutdownTimeout time.Duration `config:"shutdown_timeout" validate:"min=1"`
+}
+
+// defaultConfig is used for anything no source sets
+var defaultConfig = Config{Addr: ":8080", LogFormat: "json", DataFile: "books.json", KeysFile: "api_keys.json", CoversDir: "covers", AuditFile: "audit.jsonl", SessionsFile: "sessions.json", TokenTTL: time.Hour, SessionTTL: 24 * time.Hour, CacheTTL: defaultCacheTTL, ShutdownTimeout: 15 * time.Second}
+
+// Validate checks the settings struct tags cannot express
+func (c Config) Validate() error {
+	if c.LogFormat != "json" && c.LogFormat != "text" {
+		return fmt.Errorf("log_format must be \"json\" or \"text\", got %q", c.LogFormat)
+	}
+	if c.JWTSecret != "" && len(c.JWTSecret) < jwt.MinKeySize {
+		return fmt.Errorf("jwt_secret must be at least %d bytes", jwt.MinKeySize)
+	}
+	if (c.TLSCert == "") != (c.TLSKey == "") {
+		return errors.New("tls_cert and tls_key must be set together")
+	}
+	if c.TLSCert == "" && (c.TLSSelfSigned || c.RedirectAddr != "" || c.HSTSMaxAge > 0) {
+		return errors.New("tls_self_signed, redirect_addr and hsts_max_age need tls_cert and tls_key")
+	}
+	if c.PaymentURL != "" && len(c.PaymentWebhookSecret) < minWebhookSecretSize {
+		return fmt.Errorf("payment_url needs a payment_webhook_secret of at least %d bytes", minWebhookSecretSize)
+	}
+	if c.FakePayments && (c.PaymentURL != "" || c.TLSCert != "") {
+		// The fake provider calls this server back over plain HTTP
+		return errors.New("fake_payments cannot be combined with payment_url or tls_cert")
+	}
+	if err := validateTenants(c.Tenants); err != nil {
+		return err
+	}
+	if len(c.Tenants) == 0 && (c.TenantDomain != "" || c.TenantRateLimit > 0 || c.TenantQuota > 0) {
+		return errors.New("tenant_domain, tenant_rate_limit and tenant_quota need tenants")
+	}
+	if len(c.Tenants) > 0 && (c.PaymentURL != "" || c.FakePayments) {
+		// The provider would know orders by references that only one
+		// tenant's orders are numbered by, and webhooks do not say which
+		// tenant they are for
+		return errors.New("payment_url and fake_payments cannot be combined with tenants")
+	}
+	return nil
+}
+
+// loadConfig parses args and merges them with the config file and the
+// environment read through lookupEnv
+func loadConfig(args []string, lookupEnv func(string) (string, bool)) (Config, error) {
+	return parseConfig(flag.NewFlagSet("rest-api", flag.ContinueOnError), args, lookupEnv)
+}
+
+// parseConfig is loadConfig with the flags defined on fs, which may have
+// flags of its own, as the seed subcommand's has
+func parseConfig(fs *flag.FlagSet, args []string, lookupEnv func(string) (string, bool)) (Config, error) {
+	configFile := fs.String("config", "", "path to a JSON or YAML config file")
+	fs.String("addr", defaultConfig.Addr, "listen address")
+	fs.String("pprof", defaultConfig.PprofAddr, "serve net/http/pprof on this address, e.g. localhost:6060 (disabled if empty)")
+	fs.String("log-format", defaultConfig.LogFormat, "log format: json or text")
+	fs.String("data-file", defaultConfig.DataFile, "JSON file holding the books (builds with -tags filestore only)")
+	fs.String("keys-file", defaultConfig.KeysFile, "JSON file holding the API keys (builds with -tags filestore only)")
+	fs.String("covers-dir", defaultConfig.CoversDir, "directory holding uploaded cover images (builds with -tags filestore only)")
+	fs.String("audit-file", defaultConfig.AuditFile, "JSON lines file the audit log is appended to (builds with -tags filestore only)")
+	fs.String("sessions-file", defaultConfig.SessionsFile, "JSON file holding browser sessions (builds with -tags filestore only)")
+	fs.Duration("token-ttl", defaultConfig.TokenTTL, "how long login tokens stay valid (secret via jwt_secret or BOOKS_JWT_SECRET)")
+	fs.Duration("session-ttl", defaultConfig.SessionTTL, "how long browser sessions last after login")
+	fs.Bool("insecure-cookies", defaultConfig.InsecureCookies, "send the session cookie without the Secure flag")
+	fs.String("tls-cert", defaultConfig.TLSCert, "serve HTTPS with this PEM certificate (needs -tls-key)")
+	fs.String("tls-key", defaultConfig.TLSKey, "PEM private key for -tls-cert")
+	fs.Bool("tls-self-signed", defaultConfig.TLSSelfSigned, "write a self-signed localhost certificate to -tls-cert and -tls-key if missing (development only)")
+	fs.String("redirect-addr", defaultConfig.RedirectAddr, "with TLS on, redirect plain HTTP on this address to HTTPS, e.g. :8080")
+	fs.Duration("hsts-max-age", defaultConfig.HSTSMaxAge, "with TLS on, send Strict-Transport-Security with this max-age, e.g. 8760h; 0 disables")
+	fs.String("payment-url", defaultConfig.PaymentURL, "payment provider API to take payment for orders (webhook secret via payment_webhook_secret or BOOKS_PAYMENT_WEBHOOK_SECRET)")
+	fs.Bool("fake-payments", defaultConfig.FakePayments, "take payment for orders from a fake provider run in the process (development only)")
+	fs.String("tenants", "", "comma-separated tenant IDs, each served its own books, tokens, caches, events, audit log and jobs")
+	fs.String("tenant-domain", defaultConfig.TenantDomain, "with -tenants, also pick the tenant by subdomain of this domain, e.g. books.example.com")
+	fs.Int("tenant-rate-limit", defaultConfig.TenantRateLimit, "with -tenants, requests a minute each tenant may make; 0 is unlimited")
+	fs.Int("tenant-quota", defaultConfig.TenantQuota, "with -tenants, requests a day each tenant may make; 0 is unlimited")
+	fs.Duration("cache-ttl", defaultConfig.CacheTTL, "how long to cache public GET responses; 0 disables")
+	fs.Duration("shutdown-timeout", defaultConfig.ShutdownTimeout, "how long in-flight requests get to finish on SIGINT or SIGTERM")
+	fs.Duration("snapshot-interval", defaultConfig.SnapshotInterval, "how often to snapshot the data file, e.g. 5m; 0 disables (builds with -tags filestore only)")
+	if err := fs.Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	cfg := defaultConfig
+	err := config.Load(&cfg, config.Options{
+		File:      *configFile,
+		EnvPrefix: "BOOKS_",
+		LookupEnv: lookupEnv,
+		Flags:     fs,
+	})
+	return cfg, err
+}
+
+// newLogger returns the request logger for the configured format. Records
+// logged with a request context carry its request ID.
+func newLogger(format string) *slog.Logger {
+	if format == "text" {
+		return slog.New(contextHandler{slog.NewTextHandler(os.Stderr, nil)})
+	}
+	return slog.New(contextHandler{slog.NewJSONHandler(os.Stderr, nil)})
+}
+
+// route is one endpoint. Perm is the permission it requires; the empty
+// permission means the route is public.
+type route struct {
+	method  string
+	pattern string
+	perm    Permission
+	handler http.HandlerFunc
+}
+
+// newRouter registers the API's routes. tracer and cache may be nil, and
+// a nil covers, events, audit, orders or jobs leaves out the cover image,
+// event stream, audit log, order or job routes, as a nil auth.sessions
+// leaves out the session routes and the admin pages. Book changes are recorded in outbox,
+// whose relay should publish them to a dispatcher made by
+// newChangeDispatcher with the same cache, events and audit; if it is nil,
+// nothing hears of them.
+func newRouter(store BookRepository, auth *tokenAuth, logger *slog.Logger, tracer Tracer, cache *responseCache, covers CoverStore, events *pubsub.Bus[BookEvent], audit *AuditLog, outbox *Outbox, orders *Orders, jobs *JobRunner) *http.ServeMux {
+	if covers != nil {
+		store = coverDeletingRepository{store, covers}
+		if cache != nil {
+			covers = cacheInvalidatingCoverStore{covers, cache}
+		}
+	}
+	// The outbox and audit wrappers are made per request, as changes name
+	// the caller
+	storeFor := func(r *http.Request) BookRepository {
+		if outbox == nil {
+			return store
+		}
+		return outboxFor(store, outbox, r)
+	}
+	withStore := func(h func(http.ResponseWriter, *http.Request, BookRepository)) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) { h(w, r, storeFor(r)) }
+	}
+	withKeys := func(h func(http.ResponseWriter, *http.Request, *APIKeyStore, auditor)) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) { h(w, r, auth.keys, auditorFor(audit, r)) }
+	}
 
-	// Create router
+	// Patterns are ServeMux patterns without a method, which methodHandlers
+	// dispatches on. /books/html is more specific than /books/{id}, so it wins.
+	routes := []route{
+		{http.MethodPost, "/auth/login", "", func(w http.ResponseWriter, r *http.Request) { handleLogin(w, r, auth) }},
+		{http.MethodGet, "/books", "", withStore(handleGetBooks)},
+		{http.MethodPost, "/books", PermCreateBooks, withStore(handleCreateBook)},
+		{http.MethodPost, "/books/batch", PermCreateBooks, withStore(handleBatchCreateBooks)},
+		{http.MethodGet, "/books/export", "", withStore(handleExportBooks)},
+		{http.MethodPost, "/books/import", PermCreateBooks, withStore(handleImportBooks)},
+		{http.MethodGet, "/books/html", "", withStore(handleBooksHTML)},
+		{http.MethodGet, "/books/{id}", "", withStore(handleGetBook)},
+		{http.MethodPut, "/books/{id}", PermUpdateBooks, withStore(handleUpdateBook)},
+		{http.MethodDelete, "/books/{id}", PermDeleteBooks, withStore(handleDeleteBook)},
+		// Mutations are authorized by handleGraphQL, as the operation
+		// decides what they need
+		{http.MethodPost, "/graphql", "", func(w http.ResponseWriter, r *http.Request) { handleGraphQL(w, r, auth, storeFor) }},
+		{http.MethodGet, "/admin/keys", PermManageKeys, func(w http.ResponseWriter, r *http.Request) { handleListAPIKeys(w, r, auth.keys) }},
+		{http.MethodPost, "/admin/keys", PermManageKeys, withKeys(handleCreateAPIKey)},
+		{http.MethodDelete, "/admin/keys/{id}", PermManageKeys, withKeys(handleRevokeAPIKey)},
+	}
+	if covers != nil {
+		routes = append(routes,
+			route{http.MethodGet, "/books/{id}/cover", "", func(w http.ResponseWriter, r *http.Request) { handleGetCover(w, r, covers) }},
+			route{http.MethodPost, "/books/{id}/cover", PermUpdateBooks, func(w http.ResponseWriter, r *http.Request) {
+				handleUploadCover(w, r, store, auditingCoverStore{covers, auditorFor(audit, r)})
+			}},
+		)
+	}
+	if sm := auth.sessions; sm != nil {
+		routes = append(routes,
+			route{http.MethodPost, "/auth/session", "", func(w http.ResponseWriter, r *http.Request) { handleCreateSession(w, r, sm) }},
+			route{http.MethodGet, "/auth/session", "", applyMiddleware(handleGetSession, sessionMiddleware(sm))},
+			route{http.MethodDelete, "/auth/session", "", applyMiddleware(func(w http.ResponseWriter, r *http.Request) { handleDeleteSession(w, r, sm) },
+				csrfMiddleware(), sessionMiddleware(sm))},
+		)
+	}
+	if orders != nil {
+		routes = append(routes,
+			route{http.MethodPost, "/orders", PermPlaceOrders, func(w http.ResponseWriter, r *http.Request) { handleCreateOrder(w, r, store, orders) }},
+			route{http.MethodGet, "/orders/{id}", PermPlaceOrders, func(w http.ResponseWriter, r *http.Request) { handleGetOrder(w, r, orders) }},
+			// The provider signs its webhooks instead of logging in
+			route{http.MethodPost, "/webhooks/payment", "", func(w http.ResponseWriter, r *http.Request) { handlePaymentWebhook(w, r, orders) }},
+		)
+	}
+	if jobs != nil {
+		withJobs := func(h func(http.ResponseWriter, *http.Request, *JobRunner)) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) { h(w, r, jobs) }
+		}
+		routes = append(routes,
+			route{http.MethodPost, "/jobs", PermRunJobs, withJobs(handleCreateJob)},
+			route{http.MethodGet, "/jobs/{id}", PermRunJobs, withJobs(handleGetJob)},
+			route{http.MethodPost, "/jobs/{id}/cancel", PermRunJobs, withJobs(handleCancelJob)},
+			route{http.MethodGet, "/jobs/{id}/result", PermRunJobs, withJobs(handleGetJobResult)},
+		)
+	}
+	if audit != nil {
+		routes = append(routes, route{http.MethodGet, "/admin/audit", PermReadAudit, func(w http.ResponseWriter, r *http.Request) { handleGetAudit(w, r, audit) }})
+	}
+
+	byPattern := make(map[string]methodHandlers)
+	var patterns []string
+	for _, rt := range routes {
+		h := spanMiddleware("handle")(rt.handler)
+		// Only public reads are cached: a cached response must not
+		// depend on who asked
+		if rt.method == http.MethodGet && rt.perm == "" {
+			h = cacheMiddleware(cache)(h)
+		}
+		if rt.perm != "" {
+			h = requirePermission(auth, rt.perm)(h)
+		}
+		if byPattern[rt.pattern] == nil {
+			byPattern[rt.pattern] = methodHandlers{}
+			patterns = append(patterns, rt.pattern)
+		}
+		byPattern[rt.pattern][rt.method] = h
+	}
+
+	// The last middleware is the outermost, so the request ID is set
+	// before anything logs or traces. Logging sits outside gzip so that
+	// bytes counts what went over the wire, and recovery inside everything
+	// so that the status it sends for a panic is logged and traced.
+	reg := metrics.NewRegistry()
+	httpMetrics := newHTTPMetrics(reg)
 	mux := http.NewServeMux()
+	for _, pattern := range patterns {
+		mux.HandleFunc(pattern, applyMiddleware(byPattern[pattern].ServeHTTP,
+			recoveryMiddleware(), tracingMiddleware(tracer), gzipMiddleware(), loggingMiddleware(logger, httpMetrics), requestIDMiddleware(), versionMiddleware()))
+	}
+	// /metrics is not logged, timed or cached, so scraping it does not
+	// change what it reports
+	mux.Handle("/metrics", methodHandlers{http.MethodGet: reg.ServeHTTP})
+	// The admin pages are per user, so they are never cached
+	if auth.sessions != nil {
+		for pattern, h := range adminRoutes(auth.sessions, storeFor) {
+			mux.HandleFunc(pattern, applyMiddleware(h.ServeHTTP,
+				recoveryMiddleware(), tracingMiddleware(tracer), gzipMiddleware(), loggingMiddleware(logger, httpMetrics), requestIDMiddleware()))
+		}
+	}
+	// The event stream and WebSocket are open for as long as the client
+	// listens, so they are neither cached nor compressed, either of which
+	// would hold events back or get in the way of taking over the
+	// connection. The export is flushed a line at a time in the same way,
+	// and a cached one would miss the events since.
+	if events != nil {
+		streams := map[string]methodHandlers{
+			"/books/events":        {http.MethodGet: func(w http.ResponseWriter, r *http.Request) { handleBookEvents(w, r, events) }},
+			"/books/events/export": {http.MethodGet: func(w http.ResponseWriter, r *http.Request) { handleExportEvents(w, r, events) }},
+			"/ws":                  {http.MethodGet: func(w http.ResponseWriter, r *http.Request) { handleWebSocket(w, r, events, wsPingPeriod) }},
+		}
+		for pattern, h := range streams {
+			mux.HandleFunc(pattern, applyMiddleware(h.ServeHTTP,
+				recoveryMiddleware(), tracingMiddleware(tracer), loggingMiddleware(logger, httpMetrics), requestIDMiddleware()))
+		}
+	}
+	return mux
+}
+
+// apiStack is one API: its router and the stores, queues and background
+// work behind it. A multi-tenant server runs one per tenant, which is what
+// keeps one tenant's books, tokens, caches, events, audit log and jobs
+// from ever reaching another.
+type apiStack struct {
+	handler       http.Handler
+	events        *pubsub.Bus[BookEvent]
+	orders        *Orders
+	jobs          *JobRunner
+	audit         *AuditLog
+	changes       *dispatch.Dispatcher[BookChange]
+	stopPayments  func()
+	stopRelay     context.CancelFunc
+	relayDone     sync.WaitGroup
+	stopSnapshots func()
+}
+
+// newAPIStack opens the stores cfg names, which kind depending on the
+// build tags, and starts the outbox relay
+func newAPIStack(cfg Config, logger *slog.Logger) (_ *apiStack, err error) {
+	store, stopSnapshots, err := newRepository(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("opening book store: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			stopSnapshots()
+		}
+	}()
+	auth := newTokenAuth(newUserStore(demoAccounts), cfg.JWTSecret, cfg.TokenTTL)
+	keyRepo, err := newKeyRepository(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("opening API key store: %w", err)
+	}
+	auth.keys = NewAPIKeyStore(keyRepo)
+	sessions, err := newSessionStore(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("opening session store: %w", err)
+	}
+	auth.sessions = newSessionManager(sessions, auth.users, cfg.SessionTTL, !cfg.InsecureCookies)
+
+	var cache *responseCache
+	if cfg.CacheTTL > 0 {
+		cache = newResponseCache(withCacheTTL(cfg.CacheTTL))
+	}
+	covers, err := newCoverStore(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("opening cover store: %w", err)
+	}
+	audit, err := newAuditLog(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("opening audit log: %w", err)
+	}
+	orders, stopPayments, err := newOrders(cfg, logger)
+	if err != nil {
+		audit.Close()
+		return nil, fmt.Errorf("setting up payments: %w", err)
+	}
+	s := &apiStack{
+		events:        newEventBus(),
+		orders:        orders,
+		jobs:          NewJobRunner(jobWorkers, jobQueueSize, jobKinds(store)),
+		audit:         audit,
+		stopPayments:  stopPayments,
+		stopSnapshots: stopSnapshots,
+	}
+	s.changes = newChangeDispatcher(cache, s.events, audit)
+	outbox := NewOutbox()
+	s.handler = newRouter(store, auth, logger, nil, cache, covers, s.events, audit, outbox, orders, s.jobs)
+
+	// The relay publishes the outbox's changes to the dispatcher until the
+	// server has stopped, so the last requests' changes are not left behind
+	relayCtx, stopRelay := context.WithCancel(context.Background())
+	s.stopRelay = stopRelay
+	s.relayDone.Add(1)
+	go func() {
+		defer s.relayDone.Done()
+		NewOutboxRelay(outbox, s.changes.Dispatch).Run(relayCtx)
+	}()
+	return s, nil
+}
+
+// close stops the stack once the server has stopped, giving each step up
+// to timeout
+func (s *apiStack) close(timeout time.Duration, logger *slog.Logger) {
+	// Jobs still running are canceled; their clients have gone with the
+	// server, and results are kept in memory only
+	jobsCtx, cancelJobs := context.WithTimeout(context.Background(), timeout)
+	if err := s.jobs.Close(jobsCtx); err != nil {
+		logger.Error("jobs did not stop", "error", err)
+	}
+	cancelJobs()
+	s.stopRelay()
+	s.relayDone.Wait()
+	// Let the last requests' changes reach the audit log before it closes
+	drainCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	if err := s.changes.Close(drainCtx); err != nil {
+		logger.Error("book changes not all handled", "error", err)
+	}
+	cancel()
+	s.stopPayments()
+	if err := s.audit.Close(); err != nil {
+		logger.Error("closing audit log", "error", err)
+	}
+	// Requests and jobs have finished, so the last snapshot has every change
+	s.stopSnapshots()
+}
+
+// Run serves the API, configured by args, the environment and any config
+// file, until SIGINT or SIGTERM. With "seed" as the first argument it fills
+// the store instead of serving it.
+func Run(args []string) error {
+	if len(args) > 0 && args[0] == "seed" {
+		return runSeed(args[1:], os.LookupEnv, os.Stdout)
+	}
+
+	cfg, err := loadConfig(args, os.LookupEnv)
+	if err != nil {
+		return err
+	}
 
-	// Register routes with middleware
-	mux.HandleFunc("/books", applyMiddleware(
-		func(w http.ResponseWriter, r *http.Request) {
-			switch r.Method {
-			case http.MethodGet:
-				handleGetBooks(w, r, store)
-			case http.MethodPost:
-				handleCreateBook(w, r, store)
-			default:
-				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	logger := newLogger(cfg.LogFormat)
+	slog.SetDefault(logger)
+
+	// SIGINT or SIGTERM cancels ctx, which shuts both servers down
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Load the certificate before anything starts, so a bad one stops the
+	// server at once
+	var tlsCfg *tls.Config
+	if cfg.TLSCert != "" {
+		if cfg.TLSSelfSigned {
+			created, err := ensureSelfSignedCert(cfg.TLSCert, cfg.TLSKey, time.Now())
+			if err != nil {
+				return fmt.Errorf("writing self-signed certificate: %w", err)
 			}
-		},
-		loggingMiddleware,
-	))
-
-	mux.HandleFunc("/books/", applyMiddleware(
-		func(w http.ResponseWriter, r *http.Request) {
-			switch r.Method {
-			case http.MethodGet:
-				handleGetBook(w, r, store)
-			case http.MethodPut:
-				handleUpdateBook(w, r, store)
-			case http.MethodDelete:
-				handleDeleteBook(w, r, store)
-			default:
-				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			if created {
+				logger.Warn("wrote a self-signed certificate; clients must be told to trust it", "cert", cfg.TLSCert)
 			}
-		},
-		loggingMiddleware,
-	))
+		}
+		if tlsCfg, err = loadTLSConfig(cfg.TLSCert, cfg.TLSKey); err != nil {
+			return err
+		}
+	}
+	var httpsPort string
+	if tlsCfg != nil && cfg.RedirectAddr != "" {
+		if _, httpsPort, err = net.SplitHostPort(cfg.Addr); err != nil {
+			return fmt.Errorf("finding the HTTPS port to redirect to: %w", err)
+		}
+	}
+
+	// Profiling endpoints get their own listener so they are never exposed
+	// on the public API port
+	var pprofDone sync.WaitGroup
+	if cfg.PprofAddr != "" {
+		pprofDone.Add(1)
+		go func() {
+			defer pprofDone.Done()
+			logger.Info("pprof listening", "url", "http://"+cfg.PprofAddr+"/debug/pprof/")
+			srv := newPprofServer(cfg.PprofAddr, logger)
+			if err := listenAndServe(ctx, srv, cfg.ShutdownTimeout); err != nil {
+				logger.Error("pprof server stopped", "error", err)
+			}
+		}()
+	}
+
+	// Each tenant gets an API of its own, with its own stores; without
+	// tenants there is just one, which gets every request
+	var stacks []*apiStack
+	var api http.Handler
+	if len(cfg.Tenants) == 0 {
+		stack, err := newAPIStack(cfg, logger)
+		if err != nil {
+			return fmt.Errorf("starting the API: %w", err)
+		}
+		stacks = append(stacks, stack)
+		api = stack.handler
+	} else {
+		byTenant := make(map[string]http.Handler)
+		for _, tenant := range cfg.Tenants {
+			tcfg, err := tenantConfig(cfg, tenant)
+			if err != nil {
+				return fmt.Errorf("configuring tenant %s: %w", tenant, err)
+			}
+			stack, err := newAPIStack(tcfg, logger)
+			if err != nil {
+				return fmt.Errorf("starting the API for tenant %s: %w", tenant, err)
+			}
+			stacks = append(stacks, stack)
+			byTenant[tenant] = stack.handler
+		}
+		api = newTenantRouter(byTenant, cfg.TenantDomain, cfg.TenantRateLimit, cfg.TenantQuota)
+	}
+	logger.Info("book store ready", "kind", repositoryKind, "tenants", len(cfg.Tenants))
+	if cfg.JWTSecret == "" {
+		logger.Warn("no jwt_secret configured; using a random key, so tokens end with this process")
+	}
+	if cfg.FakePayments {
+		logger.Warn("taking payment from a fake provider; nobody is charged")
+	}
 
 	// Start server
-	port := ":8080"
-	fmt.Printf("Starting RESTful API server on http://localhost%s\n", port)
+	scheme := "http"
+	if tlsCfg != nil {
+		scheme = "https"
+	}
+	fmt.Printf("Starting RESTful API server on %s (%s)\n", cfg.Addr, scheme)
 	fmt.Println("API Endpoints:")
-	fmt.Println("  GET    /books      - List all books")
-	fmt.Println("  GET    /books/{id} - Get a specific book")
-	fmt.Println("  POST   /books      - Create a new book")
-	fmt.Println("  PUT    /books/{id} - Update a book")
-	fmt.Println("  DELETE /books/{id} - Delete a book")
+	fmt.Println("  POST   /auth/login - Exchange username and password for a bearer token")
+	fmt.Println("  POST   /auth/session - Log a browser in: sets an HttpOnly session cookie and returns a CSRF token")
+	fmt.Println("  GET    /auth/session - The current session and its CSRF token (session cookie)")
+	fmt.Println("  DELETE /auth/session - Log out (session cookie and X-CSRF-Token)")
+	fmt.Println("  GET    /books      - List books as JSON, XML, CSV or MessagePack (?page, ?limit, ?sort, ?order, ?author, ?min_price, ?max_price, ?filter)")
+	fmt.Println("  GET    /books/html - List all books as an HTML page")
+	fmt.Println("  GET    /books/{id} - Get a specific book as JSON, XML or MessagePack")
+	fmt.Println("  POST   /books      - Create a new book from JSON, XML or MessagePack (editor or admin token)")
+	fmt.Println("  POST   /books/batch - Create many books from a JSON array or NDJSON (?atomic=true for all or nothing)")
+	fmt.Println("  GET    /books/export - Download all books as CSV")
+	fmt.Println("  POST   /books/import - Create books from an uploaded CSV file, all or none (editor or admin token)")
+	fmt.Println("  PUT    /books/{id} - Update a book (editor or admin token)")
+	fmt.Println("  GET    /books/{id}/cover - Get a book's cover image")
+	fmt.Println("  GET    /books/events - Stream book changes as server-sent events (resumes from Last-Event-ID)")
+	fmt.Println("  GET    /books/events/export - Download the kept book changes as NDJSON (?after=event ID)")
+	fmt.Println("  GET    /ws         - WebSocket sending each book change as a JSON message")
+	fmt.Println("  POST   /books/{id}/cover - Upload a GIF, JPEG, PNG or WebP cover up to 2MB as multipart field \"file\" (editor or admin token)")
+	fmt.Println("  DELETE /books/{id} - Delete a book (admin token)")
+	fmt.Println("  POST   /graphql    - GraphQL: books and book(id) queries, createBook mutation (editor or admin token)")
+	if stacks[0].orders != nil {
+		fmt.Println("  POST   /orders     - Order copies of a book, paid through the payment provider (Idempotency-Key header makes retries safe)")
+		fmt.Println("  GET    /orders/{id} - One of your orders, to see its payment go through")
+		fmt.Println("  POST   /webhooks/payment - Payment outcomes from the provider, signed in the Payment-Signature header")
+	}
+	fmt.Println("  POST   /jobs       - Start a background job, e.g. {\"kind\":\"export\",\"params\":{\"format\":\"json\"}} (editor or admin token)")
+	fmt.Println("  GET    /jobs/{id}  - A job's status and progress; poll until it has finished")
+	fmt.Println("  POST   /jobs/{id}/cancel - Cancel a queued or running job")
+	fmt.Println("  GET    /jobs/{id}/result - Download what a job that succeeded produced")
+	fmt.Println("  GET    /metrics    - Request metrics in Prometheus text format")
+	fmt.Println("  GET    /admin/books - HTML admin pages to sign in, list, create and edit books (editor or admin)")
+	fmt.Println("  GET    /admin/keys - List API keys (admin token)")
+	fmt.Println("  POST   /admin/keys - Create an API key; the secret is shown once (admin token)")
+	fmt.Println("  DELETE /admin/keys/{id} - Revoke an API key (admin token)")
+	fmt.Println("  GET    /admin/audit - Changes with who made them, newest first (?actor, ?action, ?resource, ?resource_id, ?since, ?until, ?limit, ?before; admin token)")
+	fmt.Println("Book mutations also accept an X-API-Key header with a key scoped to them,")
+	fmt.Println("or a session cookie with the session's CSRF token in X-CSRF-Token")
+	if len(cfg.Tenants) > 0 {
+		fmt.Printf("Tenants %s: name one in the %s header", strings.Join(cfg.Tenants, ", "), tenantHeader)
+		if cfg.TenantDomain != "" {
+			fmt.Printf(" or as a subdomain of %s", cfg.TenantDomain)
+		}
+		fmt.Println()
+	}
 
-	if err := http.ListenAndServe(port, mux); err != nil {
-		log.Fatalf("Server failed to start: %v", err)
+	// Shutdown waits for handlers to return, which event streams only do
+	// once their subscriptions end; closing the bus also sends WebSocket
+	// clients a close frame, as Shutdown does not track hijacked connections
+	handler := api
+	if tlsCfg != nil && cfg.HSTSMaxAge > 0 {
+		handler = hstsMiddleware(cfg.HSTSMaxAge)(api.ServeHTTP)
+	}
+	srv := newServer(cfg.Addr, handler, logger)
+	srv.TLSConfig = tlsCfg
+	for _, stack := range stacks {
+		srv.RegisterOnShutdown(stack.events.Close)
+	}
+
+	// Plain HTTP gets only redirects, so nothing is ever served unencrypted
+	var redirectDone sync.WaitGroup
+	if tlsCfg != nil && cfg.RedirectAddr != "" {
+		redirectDone.Add(1)
+		go func() {
+			defer redirectDone.Done()
+			logger.Info("redirecting plain HTTP to HTTPS", "addr", cfg.RedirectAddr)
+			srv := newServer(cfg.RedirectAddr, redirectHandler(httpsPort), logger)
+			if err := listenAndServe(ctx, srv, cfg.ShutdownTimeout); err != nil {
+				logger.Error("redirect server stopped", "error", err)
+			}
+		}()
+	}
+	err = listenAndServe(ctx, srv, cfg.ShutdownTimeout)
+	stop()
+	var closed sync.WaitGroup
+	for _, stack := range stacks {
+		closed.Add(1)
+		go func() {
+			defer closed.Done()
+			stack.close(cfg.ShutdownTimeout, logger)
+		}()
+	}
+	closed.Wait()
+	pprofDone.Wait()
+	redirectDone.Wait()
+	if err != nil {
+		return fmt.Errorf("server stopped: %w", err)
 	}
+	logger.Info("server shut down")
+	return nil
 }
 
 /*
@@ -361,46 +1184,292 @@ This project demonstrates:
    - Appropriate HTTP methods (GET, POST, PUT, DELETE)
    - HTTP status codes
    - JSON responses
+   - An HTML view rendered with html/template
+   - Admin pages composed from a shared layout and per-page content
+     blocks, with HTML forms validated by the API's rules, shown again
+     with per-field errors, and post/redirect/get with flash messages
+     kept in the session
 
 2. Concurrency-safe data access
    - Using RWMutex to protect a shared data store
    - Read locks for GET operations
    - Write locks for POST, PUT, DELETE operations
+   - Copy-on-write transactions over the store, with Begin, Commit and
+     Rollback and a conflict check at commit, which atomic batches use
 
 3. HTTP server implementation
-   - Request routing
+   - Request routing with ServeMux path patterns such as /books/{id},
+     and 405 with an Allow header for unsupported methods
    - Request/response handling
    - Middleware pattern
+   - Structured request logging with log/slog
+   - Read, write and idle timeouts, and graceful shutdown on SIGINT or
+     SIGTERM that lets in-flight requests finish
+   - Server-sent events flushed through http.ResponseController, with
+     the write timeout lifted for the long-lived stream
+   - A WebSocket endpoint on a hand-rolled RFC 6455 implementation
+     (pkg/websocket), with read and write pumps and ping/pong keepalives
+   - Cookie sessions for browsers (HttpOnly, Secure, SameSite=Lax) with
+     a per-session CSRF token checked on state-changing requests
+   - HTTPS with a hardened tls.Config (TLS 1.2 or later, forward-secret
+     AEAD suites only), a self-signed certificate for local development,
+     plain HTTP redirected to HTTPS, and Strict-Transport-Security
 
 4. Common Go patterns
    - Middleware chaining
    - Handler functions
-   - Error handling
+   - Error handling with codes mapped to HTTP statuses (pkg/errorsx)
+   - Decorators over a repository interface, one of which records a
+     domain event for each change to the books in an outbox, under the
+     same lock as the change, for a background relay to publish with
+     retries (the transactional outbox pattern)
+   - An in-memory event dispatcher (pkg/dispatch) delivering those events
+     in order, at least once, to the cache invalidator, the event stream
+     and the audit log of JSON field diffs
+   - A GraphQL endpoint on a hand-rolled parser and executor
+     (pkg/graphql), with one resolver per field and the REST routes'
+     list query, validation and permissions reused
+   - Background jobs run by a fixed pool of workers from a bounded
+     queue, with 202 Accepted, status and progress polling, cancellation
+     through a context and the result kept for download
+   - Orders paid through an upstream payment API (pkg/httpclient) with
+     retries made safe by idempotency keys on both sides, and outcomes
+     taken from webhooks checked by timestamped HMAC-SHA256 signatures
+     and deduplicated by event ID
+   - Multi-tenancy by giving each tenant its own stack of stores, caches,
+     token key and workers behind a router that resolves the tenant from
+     a header or subdomain, rate limits it and counts its daily quota
+   - A filter expression language for GET /books?filter= (pkg/filter),
+     with a lexer, a recursive-descent parser building an AST checked
+     against the book's fields, an evaluator comparing prices exactly,
+     and fuzz tests
+   - Fixtures for the sample books and demo accounts, embedded YAML and
+     JSON files decoded by pkg/fixtures, and a seed subcommand that adds
+     them and deterministic fake books from a seeded generator to any
+     BookRepository, skipping books already there
 
 5. JSON serialization/deserialization
    - Using struct tags to control JSON field names
    - Request body parsing
    - Response generation
+   - A version for the response shapes (API-Version), guarded by tests
+     comparing each response's shape with the snapshots recorded for
+     that version
 
 To test, run this server and use curl or a tool like Postman to make API requests:
 
 # List all books
 curl -X GET http://localhost:8080/books
+# {"books":[...],"pagination":{"page":1,"limit":20,"total":3,"next_page":null}}
+
+# Page, sort and filter the list
+curl -X GET 'http://localhost:8080/books?page=2&limit=10&sort=price&order=desc'
+curl -X GET 'http://localhost:8080/books?author=william%20kennedy&min_price=10&max_price=30'
+curl -G http://localhost:8080/books --data-urlencode 'filter=price>20 AND author~"Kennedy"'
+# A filter that does not parse is a 400 saying where:
+# {"detail":"filter: unknown field \"isbn\" at offset 0","code":"invalid_argument",...}
+
+# Reads are cached for -cache-ttl (X-Cache: HIT or MISS) and carry an
+# ETag; sending it back gets 304 Not Modified until a book changes
+curl -i http://localhost:8080/books -H 'If-None-Match: "<etag from a previous response>"'
+
+# The list as XML or CSV instead of JSON, gzip-compressed
+curl -X GET http://localhost:8080/books -H "Accept: application/xml"
+curl -X GET http://localhost:8080/books -H "Accept: text/csv" --compressed
 
 # Get a specific book
 curl -X GET http://localhost:8080/books/1
 
+# Manage books in a browser: open http://localhost:8080/admin/books and
+# sign in as editor or admin (over plain HTTP other than localhost, run
+# with -insecure-cookies)
+
+# List all books as an HTML page (or open it in a browser)
+curl -X GET http://localhost:8080/books/html
+
+# Log in; creating and updating books need an editor or admin token,
+# deleting needs admin. The demo accounts are admin, editor and reader,
+# each with the password <name>-password.
+TOKEN=$(curl -s -X POST http://localhost:8080/auth/login \
+  -d '{"username":"admin","password":"admin-password"}' | jq -r .token)
+
+# Or log in as a browser would: the session cookie is HttpOnly, Secure
+# and SameSite=Lax, and changes need the CSRF token from the response in
+# X-CSRF-Token (curl sends Secure cookies to localhost; elsewhere over
+# plain HTTP run with -insecure-cookies)
+CSRF=$(curl -s -c cookies.txt -X POST http://localhost:8080/auth/session \
+  -d '{"username":"editor","password":"editor-password"}' | jq -r .csrf_token)
+curl -b cookies.txt -X POST http://localhost:8080/books -H "X-CSRF-Token: $CSRF" \
+  -d '{"title":"Learning Go","author":"Jon Bodner","price":29.99}'
+curl -b cookies.txt -X POST http://localhost:8080/books -d '{...}'   # 403: no CSRF token
+curl -b cookies.txt -X DELETE http://localhost:8080/auth/session -H "X-CSRF-Token: $CSRF"
+
 # Create a new book
 curl -X POST http://localhost:8080/books \
+  -H "Authorization: Bearer $TOKEN" \
   -H "Content-Type: application/json" \
   -d '{"title":"Learning Go","author":"Jon Bodner","price":29.99}'
 
+# Books are read and written as XML too: the Content-Type says what the
+# body is, the Accept header what comes back
+curl -X GET http://localhost:8080/books/1 -H "Accept: application/xml"
+curl -X POST http://localhost:8080/books -H "Authorization: Bearer $TOKEN" \
+  -H "Content-Type: application/xml" -H "Accept: application/xml" \
+  -d '<book><title>Go in Practice</title><author>Matt Butcher</author><price>29.99</price></book>'
+# and as MessagePack (pkg/msgpack), with the JSON field names and prices in cents
+curl -X GET http://localhost:8080/books -H "Accept: application/msgpack" -o books.msgpack
+
+# Create many books: each is reported by index with 201 or its problem.
+# NDJSON streams large batches; ?atomic=true creates none unless all are valid
+curl -X POST http://localhost:8080/books/batch -H "Authorization: Bearer $TOKEN" \
+  -d '[{"title":"A","author":"X","price":10},{"title":"","author":"Y","price":5}]'
+curl -X POST 'http://localhost:8080/books/batch?atomic=true' -H "Authorization: Bearer $TOKEN" \
+  -H "Content-Type: application/x-ndjson" --data-binary @books.ndjson
+
+# Export every book as CSV, and import a CSV upload (title, author and
+# price columns); a file with any invalid row is rejected, row by row
+curl -OJ http://localhost:8080/books/export
+curl -X POST http://localhost:8080/books/import -H "Authorization: Bearer $TOKEN" -F file=@books.csv
+# {"imported":3,"ids":[4,5,6]}
+
+# Follow book changes as server-sent events; after a disconnect, send the
+# last event's id to get what was missed (EventSource does this itself)
+curl -N http://localhost:8080/books/events
+curl -N http://localhost:8080/books/events -H "Last-Event-ID: 42"
+# or download the ones still kept as NDJSON, one event per line
+curl 'http://localhost:8080/books/events/export?after=42'
+# id: 43
+# event: created
+# data: {"type":"created","id":7,"book":{...}}
+
+# The same changes over a WebSocket, one JSON message each, e.g. with
+# websocat; the server pings every 30s and drops clients that stop answering
+websocat ws://localhost:8080/ws
+# {"event_id":43,"type":"created","id":7,"book":{...}}
+
+# Upload a cover image (GIF, JPEG, PNG or WebP, at most 2MB; the type is
+# read from the bytes, not the file name) and fetch it with its ETag
+curl -X POST http://localhost:8080/books/1/cover -H "Authorization: Bearer $TOKEN" -F file=@cover.png
+curl -i http://localhost:8080/books/1/cover -o cover.png
+
 # Update a book
 curl -X PUT http://localhost:8080/books/1 \
+  -H "Authorization: Bearer $TOKEN" \
   -H "Content-Type: application/json" \
   -d '{"title":"The Go Programming Language","author":"Donovan & Kernighan","price":39.99}'
 
 # Delete a book
-curl -X DELETE http://localhost:8080/books/1
+curl -X DELETE http://localhost:8080/books/1 -H "Authorization: Bearer $TOKEN"
+
+# Create an API key for a program (admin token); the "key" field is only
+# ever shown in this response
+curl -X POST http://localhost:8080/admin/keys \
+  -H "Authorization: Bearer $TOKEN" \
+  -d '{"name":"importer","scopes":["books:create"],"rate_limit":60}'
+# {"id":"3f2a...","name":"importer",...,"key":"bk_3f2a..._..."}
+
+# Use it instead of a token; past 60 requests a minute it gets 429
+curl -X POST http://localhost:8080/books -H "X-API-Key: $KEY" \
+  -d '{"title":"Learning Go","author":"Jon Bodner","price":29.99}'
+
+# List and revoke keys
+curl -X GET http://localhost:8080/admin/keys -H "Authorization: Bearer $TOKEN"
+curl -X DELETE http://localhost:8080/admin/keys/3f2a... -H "Authorization: Bearer $TOKEN"
+
+# Audit log of every change (admin token), newest first: who made it, and
+# each changed field's JSON before and after
+curl 'http://localhost:8080/admin/audit?resource=book&resource_id=1' -H "Authorization: Bearer $TOKEN"
+# [{"id":2,"time":"...","actor":"user:admin","action":"updated","resource":"book",
+#   "resource_id":"1","request_id":"...","changes":{"price":{"from":29.99,"to":24.99}}},...]
+
+# GraphQL: only the fields selected come back; createBook needs an editor
+# or admin token, as POST /books does
+curl -X POST http://localhost:8080/graphql \
+  -d '{"query":"{ books(author: \"Jon Bodner\") { id title } book(id: 1) { title price } }"}'
+# {"data":{"books":[...],"book":{"title":"...","price":32.99}}}
+curl -X POST http://localhost:8080/graphql -H "Authorization: Bearer $TOKEN" \
+  -d '{"query":"mutation ($in: BookInput!) { createBook(input: $in) { id } }",
+       "variables":{"in":{"title":"Learning Go","author":"Jon Bodner","price":29.99}}}'
+
+# Export the books in the background: the job is queued at once, and its
+# status shows its progress until the result can be downloaded
+curl -i -X POST http://localhost:8080/jobs -H "Authorization: Bearer $TOKEN" \
+  -d '{"kind":"export","params":{"format":"json"}}'
+# HTTP/1.1 202 Accepted
+# Location: /jobs/1
+curl http://localhost:8080/jobs/1 -H "Authorization: Bearer $TOKEN"
+# {"id":1,"kind":"export","status":"succeeded","progress":100,"result_url":"/jobs/1/result",...}
+curl -OJ http://localhost:8080/jobs/1/result -H "Authorization: Bearer $TOKEN"
+curl -X POST http://localhost:8080/jobs/2/cancel -H "Authorization: Bearer $TOKEN"
+
+# Order books against a fake payment provider, which settles each payment
+# two seconds later by webhook (totals ending in .13 are declined). Send
+# the same Idempotency-Key to retry an order whose answer was lost.
+go run ./cmd/runner serve rest-api -fake-payments
+curl -i -X POST http://localhost:8080/orders -H "Authorization: Bearer $TOKEN" \
+  -H "Idempotency-Key: 7c1d9e" -d '{"book_id":1,"quantity":2}'
+# HTTP/1.1 201 Created
+# Location: /orders/1
+# {"id":1,"book_id":1,"quantity":2,"amount":65.98,"status":"awaiting_payment","payment_id":"pay_1",...}
+curl http://localhost:8080/orders/1 -H "Authorization: Bearer $TOKEN"
+# {"id":1,...,"status":"paid",...}
+
+# Or a real provider, whose webhooks must be signed with this secret
+BOOKS_PAYMENT_WEBHOOK_SECRET=... go run ./cmd/runner serve rest-api -payment-url=https://payments.example.com
+
+# Serve several tenants, each with books, accounts' tokens, API keys,
+# caches, events, audit log and jobs of its own. A request names its
+# tenant in X-Tenant-ID or as a subdomain; each tenant gets 600 requests a
+# minute and 100000 a day (X-Quota-Remaining counts down; past either, 429).
+go run ./cmd/runner serve rest-api -tenants=acme,globex -tenant-domain=books.localhost \
+  -tenant-rate-limit=600 -tenant-quota=100000
+curl http://localhost:8080/books -H "X-Tenant-ID: acme"
+curl http://globex.books.localhost:8080/books
+curl http://localhost:8080/books
+# {..."status":400,"detail":"No tenant: send X-Tenant-ID or use the tenant's subdomain",...}
+
+# Configure with a file, BOOKS_* environment variables or flags (flags win)
+BOOKS_ADDR=:9090 go run ./cmd/runner serve rest-api -log-format=text
+go run ./cmd/runner serve rest-api -config=config.yaml   # addr: ":9090", log_format: text, pprof: ...
+
+# Ctrl-C or SIGTERM stops accepting connections and waits up to
+# -shutdown-timeout for in-flight requests before exiting
+go run ./cmd/runner serve rest-api -shutdown-timeout=30s
+
+# Build with the file-backed store instead of the in-memory one
+go run -tags filestore ./cmd/runner serve rest-api -data-file=books.json
+
+# Seed the data file with the embedded fixtures (fixtures/books.yaml) and
+# 1000 fake books made up from seed 42, the same ones every time; books
+# already there are skipped, so seeding twice adds nothing
+go run -tags filestore ./cmd/runner serve rest-api seed -data-file=books.json -fake-books=1000 -seed=42
+# added 1000 books to books.json; 3 were already there
+
+# Seed from fixtures of your own: books.yaml, books.yml or books.json
+go run -tags filestore ./cmd/runner serve rest-api seed -fixtures=./my-fixtures
+
+# Run with profiling endpoints on a separate port
+go run ./cmd/runner serve rest-api -pprof=localhost:6060
+go tool pprof http://localhost:6060/debug/pprof/heap
+
+# Serve HTTPS with a self-signed certificate for localhost, written to
+# cert.pem and key.pem on the first run; plain HTTP on :8080 is redirected,
+# and browsers are told to stay on HTTPS for an hour
+go run ./cmd/runner serve rest-api -addr=:8443 -tls-cert=cert.pem -tls-key=key.pem -tls-self-signed \
+  -redirect-addr=:8080 -hsts-max-age=1h
+curl --cacert cert.pem https://localhost:8443/books
+curl -i http://localhost:8080/books
+# HTTP/1.1 301 Moved Permanently
+# Location: https://localhost:8443/books
+
+# Errors are returned as RFC 7807 application/problem+json, with the
+# request ID that is also echoed in X-Request-ID and logged
+curl -X GET http://localhost:8080/books/999 -H "X-Request-ID: my-req-1"
+# {"type":"about:blank","title":"Not Found","status":404,"detail":"Book not found","code":"not_found","request_id":"my-req-1"}
+
+# Invalid bodies list each field that failed validation
+curl -X POST http://localhost:8080/books -d '{"price":0}'
+# {..."status":400,"detail":"Invalid book data: ...","code":"invalid_argument",
+#  "errors":[{"field":"title","rule":"required","message":"title is required"},...]}
 
 */
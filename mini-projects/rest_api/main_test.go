@@ -0,0 +1,408 @@
+package restapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/errorsx"
+	"github.com/rehan/go-interview-prep/pkg/mock"
+	"github.com/rehan/go-interview-prep/pkg/money"
+	"github.com/rehan/go-interview-prep/pkg/testutil/golden"
+)
+
+func TestCreateBook_Validation(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantInBody []string
+	}{
+		{
+			name:       "valid book",
+			body:       `{"title":"Learning Go","author":"Jon Bodner","price":29.99}`,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "missing title and author",
+			body:       `{"price":29.99}`,
+			wantStatus: http.StatusBadRequest,
+			wantInBody: []string{"title is required", "author is required"},
+		},
+		{
+			name:       "non-positive price",
+			body:       `{"title":"Free Book","author":"Nobody","price":-1}`,
+			wantStatus: http.StatusBadRequest,
+			wantInBody: []string{"price must be at least 0.01"},
+		},
+		{
+			name:       "price with fractions of a cent",
+			body:       `{"title":"Learning Go","author":"Jon Bodner","price":29.999}`,
+			wantStatus: http.StatusBadRequest,
+			wantInBody: []string{"Invalid request body"},
+		},
+		{
+			name:       "malformed JSON",
+			body:       `{"title":`,
+			wantStatus: http.StatusBadRequest,
+			wantInBody: []string{"Invalid request body"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			store := NewBookStore()
+			req := httptest.NewRequest(http.MethodPost, "/books", strings.NewReader(tc.body))
+			rr := httptest.NewRecorder()
+
+			handleCreateBook(rr, req, store)
+
+			if rr.Code != tc.wantStatus {
+				t.Fatalf("status = %d; want %d (body: %s)", rr.Code, tc.wantStatus, rr.Body.String())
+			}
+			for _, want := range tc.wantInBody {
+				if !strings.Contains(rr.Body.String(), want) {
+					t.Errorf("body %q does not contain %q", rr.Body.String(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateBook_Validation(t *testing.T) {
+	store := NewBookStore()
+	req := httptest.NewRequest(http.MethodPut, "/books/1", strings.NewReader(`{"title":"","author":"A","price":5}`))
+	req.SetPathValue("id", "1")
+	rr := httptest.NewRecorder()
+
+	handleUpdateBook(rr, req, store)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d; want %d", rr.Code, http.StatusBadRequest)
+	}
+	if book, _ := store.GetBook(1); book.Title == "" {
+		t.Error("invalid update was applied to the store")
+	}
+}
+
+func TestErrorResponses(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    func(http.ResponseWriter, *http.Request, BookRepository)
+		method     string
+		path       string
+		wantStatus int
+		wantCode   errorsx.Code
+		wantMsg    string
+	}{
+		{"book not found", handleGetBook, http.MethodGet, "/books/999", http.StatusNotFound, errorsx.CodeNotFound, "Book not found"},
+		{"invalid id", handleGetBook, http.MethodGet, "/books/abc", http.StatusBadRequest, errorsx.CodeInvalidArgument, "Invalid book ID: invalid ID: abc"},
+		{"wrong method", handleGetBooks, http.MethodPatch, "/books", http.StatusMethodNotAllowed, errorsx.CodeMethodNotAllowed, "Method not allowed"},
+		{"delete missing", handleDeleteBook, http.MethodDelete, "/books/999", http.StatusNotFound, errorsx.CodeNotFound, "Book not found"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			// The handlers are called directly, so set what the router would
+			if id, ok := strings.CutPrefix(tc.path, "/books/"); ok {
+				req.SetPathValue("id", id)
+			}
+			rr := httptest.NewRecorder()
+
+			tc.handler(rr, req, NewBookStore())
+
+			if rr.Code != tc.wantStatus {
+				t.Fatalf("status = %d; want %d", rr.Code, tc.wantStatus)
+			}
+			if ct := rr.Header().Get("Content-Type"); ct != "application/problem+json" {
+				t.Errorf("Content-Type = %q; want application/problem+json", ct)
+			}
+
+			var got Problem
+			if err := json.NewDecoder(rr.Body).Decode(&got); err != nil {
+				t.Fatalf("decoding error body: %v", err)
+			}
+			want := Problem{
+				Type:   "about:blank",
+				Title:  http.StatusText(tc.wantStatus),
+				Status: tc.wantStatus,
+				Detail: tc.wantMsg,
+				Code:   tc.wantCode,
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("problem = %+v; want %+v", got, want)
+			}
+		})
+	}
+}
+
+// TestHandlers_StoreCalls checks what the handlers ask of the store, with
+// a BookRepositoryMock standing in for it
+func TestHandlers_StoreCalls(t *testing.T) {
+	learningGo := func(b Book) bool { return b.Title == "Learning Go" && b.Price == money.FromCents(3999) }
+	body := `{"title":"Learning Go","author":"Jon Bodner","price":39.99}`
+	serve := func(handler func(http.ResponseWriter, *http.Request, BookRepository), method, path, body string, store BookRepository) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if id, ok := strings.CutPrefix(path, "/books/"); ok {
+			req.SetPathValue("id", id)
+		}
+		rr := httptest.NewRecorder()
+		handler(rr, req, store)
+		return rr.Code
+	}
+
+	t.Run("create", func(t *testing.T) {
+		store := &BookRepositoryMock{
+			AddBookFunc: func(Book) int { return 42 },
+			GetBookFunc: func(id int) (Book, bool) { return Book{ID: id}, true },
+		}
+		if code := serve(handleCreateBook, http.MethodPost, "/books", body, store); code != http.StatusCreated {
+			t.Fatalf("status = %d; want 201", code)
+		}
+		mock.AssertCalledTimes(t, store.AddBookCalls(), 1, mock.Func("Learning Go at 39.99", learningGo))
+		mock.AssertCalled(t, store.GetBookCalls(), 42)
+	})
+
+	t.Run("update missing", func(t *testing.T) {
+		store := &BookRepositoryMock{}
+		if code := serve(handleUpdateBook, http.MethodPut, "/books/7", body, store); code != http.StatusNotFound {
+			t.Fatalf("status = %d; want 404", code)
+		}
+		mock.AssertCalled(t, store.UpdateBookCalls(), 7, mock.Func("Learning Go at 39.99", learningGo))
+	})
+
+	t.Run("invalid update", func(t *testing.T) {
+		store := &BookRepositoryMock{}
+		if code := serve(handleUpdateBook, http.MethodPut, "/books/7", `{"title":"Learning Go"}`, store); code != http.StatusBadRequest {
+			t.Fatalf("status = %d; want 400", code)
+		}
+		mock.AssertNotCalled(t, store.UpdateBookCalls())
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		store := &BookRepositoryMock{DeleteBookFunc: func(int) bool { return true }}
+		if code := serve(handleDeleteBook, http.MethodDelete, "/books/3", "", store); code != http.StatusNoContent {
+			t.Fatalf("status = %d; want 204", code)
+		}
+		mock.AssertCalledTimes(t, store.DeleteBookCalls(), 1, 3)
+	})
+}
+
+func TestRespondWithError_HidesInternalDetails(t *testing.T) {
+	rr := httptest.NewRecorder()
+	respondWithError(rr, errors.New("connection to db-primary:5432 refused"))
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d; want %d", rr.Code, http.StatusInternalServerError)
+	}
+	if strings.Contains(rr.Body.String(), "db-primary") {
+		t.Errorf("internal details leaked to client: %s", rr.Body.String())
+	}
+}
+
+func TestCreateBook_ProblemFieldErrors(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/books", strings.NewReader(`{"author":"A","price":-1}`))
+	rr := httptest.NewRecorder()
+
+	handleCreateBook(rr, req, NewBookStore())
+
+	var got map[string]any
+	if err := json.NewDecoder(rr.Body).Decode(&got); err != nil {
+		t.Fatalf("decoding error body: %v", err)
+	}
+	want := map[string]any{
+		"type":   "about:blank",
+		"title":  "Bad Request",
+		"status": float64(400),
+		"detail": "Invalid book data: title is required; price must be at least 0.01",
+		"code":   "invalid_argument",
+		"errors": []any{
+			map[string]any{"field": "title", "rule": "required", "message": "title is required"},
+			map[string]any{"field": "price", "rule": "min", "message": "price must be at least 0.01"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("problem = %v\nwant %v", got, want)
+	}
+}
+
+// Errors that are not validation failures have no errors member
+func TestRespondWithError_OmitsEmptyFieldErrors(t *testing.T) {
+	rr := httptest.NewRecorder()
+	respondWithError(rr, errorsx.New(errorsx.CodeNotFound, "Book not found"))
+	if strings.Contains(rr.Body.String(), `"errors"`) {
+		t.Errorf("body = %s; want no errors member", rr.Body.String())
+	}
+}
+
+func TestLoggingMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	handler := applyMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		respondWithError(w, errorsx.New(errorsx.CodeNotFound, "Book not found"))
+	}, loggingMiddleware(logger, nil))
+
+	req := httptest.NewRequest(http.MethodGet, "/books/42", nil)
+	req.Pattern = "/books/{id}"
+	handler(httptest.NewRecorder(), req)
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("log output %q is not one JSON record: %v", buf.String(), err)
+	}
+	for key, want := range map[string]any{"msg": "request", "method": "GET", "path": "/books/42", "pattern": "/books/{id}", "status": float64(404)} {
+		if record[key] != want {
+			t.Errorf("record[%q] = %v; want %v", key, record[key], want)
+		}
+	}
+	if _, ok := record["duration"]; !ok {
+		t.Error("record has no duration")
+	}
+	if n, _ := record["bytes"].(float64); n == 0 {
+		t.Error("record has no body size")
+	}
+}
+
+func TestRecoveryMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		panicWith  any
+		wantStatus int
+		wantCode   errorsx.Code
+	}{
+		{"error", errorsx.New(errorsx.CodeUnavailable, "Store down"), http.StatusServiceUnavailable, errorsx.CodeUnavailable},
+		{"other value", "nil map", http.StatusInternalServerError, errorsx.CodeInternal},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := applyMiddleware(func(w http.ResponseWriter, r *http.Request) { panic(tc.panicWith) }, recoveryMiddleware())
+			rr := httptest.NewRecorder()
+			handler(rr, httptest.NewRequest(http.MethodGet, "/books", nil))
+			var problem Problem
+			if err := json.NewDecoder(rr.Body).Decode(&problem); err != nil {
+				t.Fatal(err)
+			}
+			if rr.Code != tc.wantStatus || problem.Code != tc.wantCode {
+				t.Errorf("response = %d, code %q; want %d, %q", rr.Code, problem.Code, tc.wantStatus, tc.wantCode)
+			}
+		})
+	}
+
+	t.Run("abort", func(t *testing.T) {
+		handler := applyMiddleware(func(w http.ResponseWriter, r *http.Request) { panic(http.ErrAbortHandler) }, recoveryMiddleware())
+		defer func() {
+			if p := recover(); p != http.ErrAbortHandler {
+				t.Errorf("panic = %v; want http.ErrAbortHandler passed on", p)
+			}
+		}()
+		handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/books", nil))
+	})
+}
+
+func TestLoadConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		env     map[string]string
+		want    Config
+		wantErr bool
+	}{
+		{"defaults", nil, nil, defaultConfig, false},
+		{"env", nil, map[string]string{"BOOKS_ADDR": ":9090"}, Config{Addr: ":9090", LogFormat: "json", DataFile: "books.json", KeysFile: "api_keys.json", CoversDir: "covers", AuditFile: "audit.jsonl", SessionsFile: "sessions.json", TokenTTL: time.Hour, SessionTTL: 24 * time.Hour, CacheTTL: 30 * time.Second, ShutdownTimeout: 15 * time.Second}, false},
+		{"flag beats env", []string{"-addr", ":7070"}, map[string]string{"BOOKS_ADDR": ":9090"}, Config{Addr: ":7070", LogFormat: "json", DataFile: "books.json", KeysFile: "api_keys.json", CoversDir: "covers", AuditFile: "audit.jsonl", SessionsFile: "sessions.json", TokenTTL: time.Hour, SessionTTL: 24 * time.Hour, CacheTTL: 30 * time.Second, ShutdownTimeout: 15 * time.Second}, false},
+		{"pprof and format", []string{"-pprof", "localhost:6060", "-log-format", "text"}, nil, Config{Addr: ":8080", PprofAddr: "localhost:6060", LogFormat: "text", DataFile: "books.json", KeysFile: "api_keys.json", CoversDir: "covers", AuditFile: "audit.jsonl", SessionsFile: "sessions.json", TokenTTL: time.Hour, SessionTTL: 24 * time.Hour, CacheTTL: 30 * time.Second, ShutdownTimeout: 15 * time.Second}, false},
+		{"data file", []string{"-data-file", "/tmp/b.json"}, nil, Config{Addr: ":8080", LogFormat: "json", DataFile: "/tmp/b.json", KeysFile: "api_keys.json", CoversDir: "covers", AuditFile: "audit.jsonl", SessionsFile: "sessions.json", TokenTTL: time.Hour, SessionTTL: 24 * time.Hour, CacheTTL: 30 * time.Second, ShutdownTimeout: 15 * time.Second}, false},
+		{"snapshot interval", []string{"-snapshot-interval", "5m"}, nil, Config{Addr: ":8080", LogFormat: "json", DataFile: "books.json", KeysFile: "api_keys.json", CoversDir: "covers", AuditFile: "audit.jsonl", SessionsFile: "sessions.json", SnapshotInterval: 5 * time.Minute, TokenTTL: time.Hour, SessionTTL: 24 * time.Hour, CacheTTL: 30 * time.Second, ShutdownTimeout: 15 * time.Second}, false},
+		{"negative snapshot interval", []string{"-snapshot-interval", "-1s"}, nil, Config{}, true},
+		{"jwt secret and ttl", []string{"-token-ttl", "15m"}, map[string]string{"BOOKS_JWT_SECRET": strings.Repeat("k", 32)}, Config{Addr: ":8080", LogFormat: "json", DataFile: "books.json", KeysFile: "api_keys.json", CoversDir: "covers", AuditFile: "audit.jsonl", SessionsFile: "sessions.json", JWTSecret: strings.Repeat("k", 32), TokenTTL: 15 * time.Minute, SessionTTL: 24 * time.Hour, CacheTTL: 30 * time.Second, ShutdownTimeout: 15 * time.Second}, false},
+		{"shutdown timeout", []string{"-shutdown-timeout", "1m"}, nil, Config{Addr: ":8080", LogFormat: "json", DataFile: "books.json", KeysFile: "api_keys.json", CoversDir: "covers", AuditFile: "audit.jsonl", SessionsFile: "sessions.json", TokenTTL: time.Hour, SessionTTL: 24 * time.Hour, CacheTTL: 30 * time.Second, ShutdownTimeout: time.Minute}, false},
+		{"zero shutdown timeout", []string{"-shutdown-timeout", "0"}, nil, Config{}, true},
+		{"short jwt secret", nil, map[string]string{"BOOKS_JWT_SECRET": "short"}, Config{}, true},
+		{"zero token ttl", []string{"-token-ttl", "0"}, nil, Config{}, true},
+		{"sessions", []string{"-session-ttl", "2h", "-insecure-cookies"}, map[string]string{"BOOKS_SESSIONS_FILE": "/tmp/s.json"}, Config{Addr: ":8080", LogFormat: "json", DataFile: "books.json", KeysFile: "api_keys.json", CoversDir: "covers", AuditFile: "audit.jsonl", SessionsFile: "/tmp/s.json", TokenTTL: time.Hour, SessionTTL: 2 * time.Hour, InsecureCookies: true, CacheTTL: 30 * time.Second, ShutdownTimeout: 15 * time.Second}, false},
+		{"zero session ttl", []string{"-session-ttl", "0"}, nil, Config{}, true},
+		{"tls", []string{"-addr", ":8443", "-tls-cert", "c.pem", "-tls-key", "k.pem", "-tls-self-signed", "-redirect-addr", ":8080"}, map[string]string{"BOOKS_HSTS_MAX_AGE": "1h"}, Config{Addr: ":8443", LogFormat: "json", DataFile: "books.json", KeysFile: "api_keys.json", CoversDir: "covers", AuditFile: "audit.jsonl", SessionsFile: "sessions.json", TokenTTL: time.Hour, SessionTTL: 24 * time.Hour, TLSCert: "c.pem", TLSKey: "k.pem", TLSSelfSigned: true, RedirectAddr: ":8080", HSTSMaxAge: time.Hour, CacheTTL: 30 * time.Second, ShutdownTimeout: 15 * time.Second}, false},
+		{"tls cert without key", []string{"-tls-cert", "c.pem"}, nil, Config{}, true},
+		{"redirect without tls", []string{"-redirect-addr", ":8080"}, nil, Config{}, true},
+		{"hsts without tls", []string{"-hsts-max-age", "1h"}, nil, Config{}, true},
+		{"negative hsts max age", []string{"-tls-cert", "c.pem", "-tls-key", "k.pem", "-hsts-max-age", "-1s"}, nil, Config{}, true},
+		{"payments", []string{"-payment-url", "https://pay.example.com"}, map[string]string{"BOOKS_PAYMENT_WEBHOOK_SECRET": strings.Repeat("w", 32)}, Config{Addr: ":8080", LogFormat: "json", DataFile: "books.json", KeysFile: "api_keys.json", CoversDir: "covers", AuditFile: "audit.jsonl", SessionsFile: "sessions.json", TokenTTL: time.Hour, SessionTTL: 24 * time.Hour, PaymentURL: "https://pay.example.com", PaymentWebhookSecret: strings.Repeat("w", 32), CacheTTL: 30 * time.Second, ShutdownTimeout: 15 * time.Second}, false},
+		{"fake payments", []string{"-fake-payments"}, nil, Config{Addr: ":8080", LogFormat: "json", DataFile: "books.json", KeysFile: "api_keys.json", CoversDir: "covers", AuditFile: "audit.jsonl", SessionsFile: "sessions.json", TokenTTL: time.Hour, SessionTTL: 24 * time.Hour, FakePayments: true, CacheTTL: 30 * time.Second, ShutdownTimeout: 15 * time.Second}, false},
+		{"payments without webhook secret", []string{"-payment-url", "https://pay.example.com"}, nil, Config{}, true},
+		{"short webhook secret", []string{"-payment-url", "https://pay.example.com"}, map[string]string{"BOOKS_PAYMENT_WEBHOOK_SECRET": "short"}, Config{}, true},
+		{"fake payments with payment url", []string{"-fake-payments", "-payment-url", "https://pay.example.com"}, map[string]string{"BOOKS_PAYMENT_WEBHOOK_SECRET": strings.Repeat("w", 32)}, Config{}, true},
+		{"fake payments with tls", []string{"-fake-payments", "-tls-cert", "c.pem", "-tls-key", "k.pem"}, nil, Config{}, true},
+		{"tenants", []string{"-tenants", "acme, globex", "-tenant-domain", "books.example.com", "-tenant-rate-limit", "600"}, map[string]string{"BOOKS_TENANT_QUOTA": "10000"}, Config{Addr: ":8080", LogFormat: "json", DataFile: "books.json", KeysFile: "api_keys.json", CoversDir: "covers", AuditFile: "audit.jsonl", SessionsFile: "sessions.json", TokenTTL: time.Hour, SessionTTL: 24 * time.Hour, Tenants: []string{"acme", "globex"}, TenantDomain: "books.example.com", TenantRateLimit: 600, TenantQuota: 10000, CacheTTL: 30 * time.Second, ShutdownTimeout: 15 * time.Second}, false},
+		{"tenant not a DNS label", []string{"-tenants", "Acme_Corp"}, nil, Config{}, true},
+		{"tenant listed twice", []string{"-tenants", "acme,acme"}, nil, Config{}, true},
+		{"tenant quota without tenants", []string{"-tenant-quota", "100"}, nil, Config{}, true},
+		{"negative tenant rate limit", []string{"-tenants", "acme", "-tenant-rate-limit", "-1"}, nil, Config{}, true},
+		{"tenants with payments", []string{"-tenants", "acme", "-fake-payments"}, nil, Config{}, true},
+		{"invalid format", nil, map[string]string{"BOOKS_LOG_FORMAT": "xml"}, Config{}, true},
+		{"empty addr", []string{"-addr", ""}, nil, Config{}, true},
+		{"unknown flag", []string{"-port", "1"}, nil, Config{}, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			lookup := func(name string) (string, bool) {
+				v, ok := tc.env[name]
+				return v, ok
+			}
+			got, err := loadConfig(tc.args, lookup)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("loadConfig error = %v; want error %v", err, tc.wantErr)
+			}
+			if !tc.wantErr && !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("loadConfig = %+v; want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBooksHTML(t *testing.T) {
+	withMarkup := NewBookStore()
+	withMarkup.AddBook(Book{Title: "<script>alert(1)</script>", Author: "Eve & Mallory", Price: money.FromCents(100)})
+
+	empty := NewBookStore()
+	for _, b := range empty.GetBooks() {
+		empty.DeleteBook(b.ID)
+	}
+
+	tests := []struct {
+		name  string
+		store *BookStore
+	}{
+		{"books_html", withMarkup},
+		{"books_html_empty", empty},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			handleBooksHTML(rr, httptest.NewRequest(http.MethodGet, "/books/html", nil), tc.store)
+
+			if rr.Code != http.StatusOK {
+				t.Fatalf("status = %d; want %d", rr.Code, http.StatusOK)
+			}
+			if ct := rr.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+				t.Errorf("Content-Type = %q; want text/html; charset=utf-8", ct)
+			}
+			golden.Assert(t, tc.name, rr.Body.Bytes())
+		})
+	}
+}
+
+func TestBooksHTML_WrongMethod(t *testing.T) {
+	rr := httptest.NewRecorder()
+	handleBooksHTML(rr, httptest.NewRequest(http.MethodPost, "/books/html", nil), NewBookStore())
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d; want %d", rr.Code, http.StatusMethodNotAllowed)
+	}
+}
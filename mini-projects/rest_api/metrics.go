@@ -0,0 +1,34 @@
+package restapi
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/metrics"
+)
+
+// httpMetrics are the request metrics loggingMiddleware records. They are
+// labelled by route pattern, never by raw path, so the number of series
+// stays fixed however many book IDs are requested.
+type httpMetrics struct {
+	duration *metrics.Histogram
+	bytes    *metrics.Counter
+	inFlight *metrics.Gauge
+}
+
+// newHTTPMetrics registers the request metrics in reg
+func newHTTPMetrics(reg *metrics.Registry) *httpMetrics {
+	return &httpMetrics{
+		duration: reg.NewHistogram("http_request_duration_seconds", "Time taken to serve requests.",
+			metrics.DefaultBuckets, "method", "path", "status"),
+		bytes: reg.NewCounter("http_response_size_bytes_total", "Response body bytes written, after compression.",
+			"method", "path"),
+		inFlight: reg.NewGauge("http_requests_in_flight", "Requests being served."),
+	}
+}
+
+// observe records one finished request
+func (m *httpMetrics) observe(method, pattern string, status int, bytes int64, d time.Duration) {
+	m.duration.Observe(d.Seconds(), method, pattern, strconv.Itoa(status))
+	m.bytes.Add(float64(bytes), method, pattern)
+}
@@ -0,0 +1,103 @@
+package restapi
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestStatusRecorder(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		wantStatus int
+		wantBytes  int64
+	}{
+		{"nothing written", func(w http.ResponseWriter, r *http.Request) {}, http.StatusOK, 0},
+		{"body only", func(w http.ResponseWriter, r *http.Request) { io.WriteString(w, "hello") }, http.StatusOK, 5},
+		{"explicit status", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+			io.WriteString(w, "{}")
+		}, http.StatusCreated, 2},
+		{"second WriteHeader ignored", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			w.WriteHeader(http.StatusOK)
+		}, http.StatusNotFound, 0},
+		{"WriteHeader after body ignored", func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, "abc")
+			w.WriteHeader(http.StatusInternalServerError)
+			io.WriteString(w, "de")
+		}, http.StatusOK, 5},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			rec := &statusRecorder{ResponseWriter: rr, status: http.StatusOK}
+			tc.handler(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+			if rec.status != tc.wantStatus || rec.bytes != tc.wantBytes {
+				t.Errorf("recorded %d, %d bytes; want %d, %d bytes", rec.status, rec.bytes, tc.wantStatus, tc.wantBytes)
+			}
+			if rr.Code != tc.wantStatus {
+				t.Errorf("underlying writer got %d; want %d", rr.Code, tc.wantStatus)
+			}
+		})
+	}
+}
+
+// parseMetrics reads text exposition output into sample values keyed by
+// series, e.g. `http_requests_in_flight`
+func parseMetrics(t *testing.T, body string) map[string]float64 {
+	t.Helper()
+	samples := make(map[string]float64)
+	for _, line := range strings.Split(body, "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.LastIndexByte(line, ' ')
+		v, err := strconv.ParseFloat(line[i+1:], 64)
+		if i < 0 || err != nil {
+			t.Fatalf("malformed sample line %q", line)
+		}
+		samples[line[:i]] = v
+	}
+	return samples
+}
+
+func TestRouter_MetricsEndpoint(t *testing.T) {
+	auth, _ := testAuth(t)
+	router := newRouter(NewBookStore(), auth, slog.New(slog.NewTextHandler(io.Discard, nil)), nil, nil, nil, nil, nil, nil, nil, nil)
+	for _, path := range []string{"/books/1", "/books/2", "/books/999", "/books"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if !strings.HasPrefix(rr.Header().Get("Content-Type"), "text/plain") {
+		t.Errorf("Content-Type = %q; want text/plain", rr.Header().Get("Content-Type"))
+	}
+	samples := parseMetrics(t, rr.Body.String())
+
+	for series, want := range map[string]float64{
+		`http_request_duration_seconds_count{method="GET",path="/books/{id}",status="200"}`:       2,
+		`http_request_duration_seconds_count{method="GET",path="/books/{id}",status="404"}`:       1,
+		`http_request_duration_seconds_count{method="GET",path="/books",status="200"}`:            1,
+		`http_request_duration_seconds_bucket{method="GET",path="/books",status="200",le="+Inf"}`: 1,
+		`http_requests_in_flight`: 0,
+	} {
+		if got, ok := samples[series]; !ok || got != want {
+			t.Errorf("%s = %v (present %v); want %v", series, got, ok, want)
+		}
+	}
+	if got := samples[`http_response_size_bytes_total{method="GET",path="/books/{id}"}`]; got <= 0 {
+		t.Errorf("bytes for /books/ = %v; want the bodies' size", got)
+	}
+	for series := range samples {
+		if strings.Contains(series, `path="/metrics"`) {
+			t.Errorf("scrapes of /metrics are counted: %s", series)
+		}
+	}
+}
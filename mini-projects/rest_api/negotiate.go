@@ -0,0 +1,208 @@
+package restapi
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/rehan/go-interview-prep/pkg/csvutil"
+	"github.com/rehan/go-interview-prep/pkg/errorsx"
+	"github.com/rehan/go-interview-prep/pkg/msgpack"
+)
+
+// Media types GET /books can return, in order of preference when the
+// client accepts several equally. MessagePack is last, so only clients
+// that ask for it by name get binary bodies.
+const (
+	mediaJSON    = "application/json"
+	mediaXML     = "application/xml"
+	mediaCSV     = "text/csv"
+	mediaMsgpack = "application/msgpack"
+)
+
+var bookListMediaTypes = []string{mediaJSON, mediaXML, mediaCSV, mediaMsgpack}
+
+// bookMediaTypes are the representations of a single book; a row of CSV
+// has no header to name its columns
+var bookMediaTypes = []string{mediaJSON, mediaXML, mediaMsgpack}
+
+// maxMsgpackBytes bounds a MessagePack book body, which is read whole
+// before it is decoded
+const maxMsgpackBytes = 64 << 10
+
+// bookElement names the root element of a single book in XML, as the
+// elements of a list are named
+var bookElement = xml.StartElement{Name: xml.Name{Local: "book"}}
+
+// acceptRange is one entry of an Accept or Accept-Encoding header
+type acceptRange struct {
+	value string
+	q     float64
+}
+
+// parseAccept splits a header such as "text/csv;q=0.5, application/*"
+// into its ranges. A missing or malformed q counts as 1, as RFC 9110 has
+// clients treat unknown parameters leniently.
+func parseAccept(header string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(header, ",") {
+		value, params, _ := strings.Cut(part, ";")
+		value = strings.ToLower(strings.TrimSpace(value))
+		if value == "" {
+			continue
+		}
+		r := acceptRange{value: value, q: 1}
+		for _, p := range strings.Split(params, ";") {
+			k, v, _ := strings.Cut(strings.TrimSpace(p), "=")
+			if strings.EqualFold(k, "q") {
+				if q, err := strconv.ParseFloat(v, 64); err == nil && q >= 0 && q <= 1 {
+					r.q = q
+				}
+			}
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges
+}
+
+// quality returns the q the ranges give mediaType, taken from the most
+// specific range that matches it: type/subtype, then type/*, then */*.
+// It is 0 when none matches.
+func quality(ranges []acceptRange, mediaType string) float64 {
+	typ, _, _ := strings.Cut(mediaType, "/")
+	best, specificity := 0.0, -1
+	for _, r := range ranges {
+		s := -1
+		switch r.value {
+		case mediaType:
+			s = 2
+		case typ + "/*":
+			s = 1
+		case "*/*", "*": // "*" is the Accept-Encoding wildcard
+			s = 0
+		}
+		if s > specificity {
+			best, specificity = r.q, s
+		}
+	}
+	return best
+}
+
+// negotiate picks the offer the Accept header rates highest, preferring
+// earlier offers on ties. No header means the first offer; a header that
+// rules out every offer means no match.
+func negotiate(accept string, offers []string) (string, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return offers[0], true
+	}
+	ranges := parseAccept(accept)
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		if q := quality(ranges, offer); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best, bestQ > 0
+}
+
+// respondWithBookList writes list as JSON, XML or CSV, whichever the
+// Accept header prefers. CSV has no room for the pagination block, so it
+// moves to X-Total-Count and, unless this is the last page, X-Next-Page.
+func respondWithBookList(w http.ResponseWriter, r *http.Request, list BookList) {
+	w.Header().Add("Vary", "Accept")
+	mediaType, ok := negotiate(r.Header.Get("Accept"), bookListMediaTypes)
+	if !ok {
+		respondWithError(w, errorsx.Errorf(errorsx.CodeNotAcceptable,
+			"Acceptable representations are %s", strings.Join(bookListMediaTypes, ", ")))
+		return
+	}
+
+	switch mediaType {
+	case mediaXML:
+		w.Header().Set("Content-Type", mediaXML+"; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		io.WriteString(w, xml.Header)
+		xml.NewEncoder(w).Encode(list)
+	case mediaCSV:
+		w.Header().Set("Content-Type", mediaCSV+"; charset=utf-8")
+		w.Header().Set("X-Total-Count", strconv.Itoa(list.Pagination.Total))
+		if next := list.Pagination.NextPage; next != nil {
+			w.Header().Set("X-Next-Page", strconv.Itoa(*next))
+		}
+		w.WriteHeader(http.StatusOK)
+		writeBooksCSV(w, list.Books)
+	case mediaMsgpack:
+		respondWithMsgpack(w, http.StatusOK, list)
+	default:
+		respondWithJSON(w, http.StatusOK, list)
+	}
+}
+
+// respondWithBook writes book as JSON, XML or MessagePack, whichever the
+// Accept header prefers
+func respondWithBook(w http.ResponseWriter, r *http.Request, status int, book Book) {
+	w.Header().Add("Vary", "Accept")
+	mediaType, ok := negotiate(r.Header.Get("Accept"), bookMediaTypes)
+	if !ok {
+		respondWithError(w, errorsx.Errorf(errorsx.CodeNotAcceptable,
+			"Acceptable representations are %s", strings.Join(bookMediaTypes, ", ")))
+		return
+	}
+
+	switch mediaType {
+	case mediaXML:
+		w.Header().Set("Content-Type", mediaXML+"; charset=utf-8")
+		w.WriteHeader(status)
+		io.WriteString(w, xml.Header)
+		xml.NewEncoder(w).EncodeElement(book, bookElement)
+	case mediaMsgpack:
+		respondWithMsgpack(w, status, book)
+	default:
+		respondWithJSON(w, status, book)
+	}
+}
+
+// respondWithMsgpack writes data as MessagePack. Its field names are the
+// json tags, so the shape is the JSON one; prices are in cents.
+func respondWithMsgpack(w http.ResponseWriter, status int, data any) {
+	body, err := msgpack.Marshal(data)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", mediaMsgpack)
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// decodeBook reads the request body into book: XML or MessagePack if the
+// Content-Type says so, JSON otherwise, so clients that send no
+// Content-Type keep working
+func decodeBook(r *http.Request, book *Book) error {
+	switch mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType {
+	case mediaXML, "text/xml":
+		return xml.NewDecoder(r.Body).Decode(book)
+	case mediaMsgpack, "application/x-msgpack":
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxMsgpackBytes+1))
+		if err != nil {
+			return err
+		}
+		if len(body) > maxMsgpackBytes {
+			return fmt.Errorf("MessagePack bodies are limited to %d bytes", maxMsgpackBytes)
+		}
+		return msgpack.Unmarshal(body, book)
+	default:
+		return json.NewDecoder(r.Body).Decode(book)
+	}
+}
+
+// writeBooksCSV writes a header row and one row per book
+func writeBooksCSV(w io.Writer, books []Book) error {
+	return csvutil.WriteAll(w, slices.Values(books))
+}
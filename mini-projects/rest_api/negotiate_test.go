@@ -0,0 +1,267 @@
+package restapi
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rehan/go-interview-prep/pkg/errorsx"
+	"github.com/rehan/go-interview-prep/pkg/msgpack"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   string
+		wantOK bool
+	}{
+		{"", mediaJSON, true},
+		{"*/*", mediaJSON, true},
+		{"application/xml", mediaXML, true},
+		{"text/csv", mediaCSV, true},
+		{"TEXT/CSV", mediaCSV, true},
+		{"text/*", mediaCSV, true},
+		{"application/*", mediaJSON, true},
+		{"text/csv;q=0.9, application/xml", mediaXML, true},
+		{"application/json;q=0.1, */*;q=0.5", mediaXML, true}, // the specific q=0.1 beats */*
+		{"application/json;q=0, */*", mediaXML, true},
+		{"text/csv;q=bad", mediaCSV, true},
+		{"text/html, image/png", "", false},
+		{"*/*;q=0", "", false},
+	}
+	for _, tc := range tests {
+		got, ok := negotiate(tc.accept, bookListMediaTypes)
+		if got != tc.want || ok != tc.wantOK {
+			t.Errorf("negotiate(%q) = %q, %v; want %q, %v", tc.accept, got, ok, tc.want, tc.wantOK)
+		}
+	}
+}
+
+// getBooks sends GET /books through the full router with the given headers
+func getBooks(t *testing.T, path string, header ...string) *httptest.ResponseRecorder {
+	t.Helper()
+	auth, _ := testAuth(t)
+	router := newRouter(NewBookStore(), auth, slog.New(slog.NewTextHandler(io.Discard, nil)), nil, nil, nil, nil, nil, nil, nil, nil)
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestGetBooks_Representations(t *testing.T) {
+	t.Run("json", func(t *testing.T) {
+		rr := getBooks(t, "/books?limit=2", "Accept", "application/json")
+		var list BookList
+		if err := json.NewDecoder(rr.Body).Decode(&list); err != nil {
+			t.Fatal(err)
+		}
+		if ct := rr.Header().Get("Content-Type"); ct != mediaJSON {
+			t.Errorf("Content-Type = %q; want %q", ct, mediaJSON)
+		}
+		if len(list.Books) != 2 || list.Pagination.Total != 3 {
+			t.Errorf("got %d books of %d; want 2 of 3", len(list.Books), list.Pagination.Total)
+		}
+	})
+
+	t.Run("xml", func(t *testing.T) {
+		rr := getBooks(t, "/books?limit=2", "Accept", "application/xml")
+		if ct := rr.Header().Get("Content-Type"); !strings.HasPrefix(ct, mediaXML) {
+			t.Errorf("Content-Type = %q; want %q", ct, mediaXML)
+		}
+		body := rr.Body.String()
+		if !strings.HasPrefix(body, "<?xml") || !strings.Contains(body, `<book id="1"><title>The Go Programming Language</title>`) || !strings.Contains(body, "<price>32.99</price>") {
+			t.Errorf("body = %s; want an XML book list with prices as decimals", body)
+		}
+		var list BookList
+		if err := xml.Unmarshal(rr.Body.Bytes(), &list); err != nil {
+			t.Fatal(err)
+		}
+		if len(list.Books) != 2 || list.Books[1].Price.String() != "34.99" || list.Pagination.NextPage == nil || *list.Pagination.NextPage != 2 {
+			t.Errorf("decoded %+v; want 2 books and next page 2", list)
+		}
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		rr := getBooks(t, "/books?limit=2&sort=price", "Accept", "text/csv")
+		if ct := rr.Header().Get("Content-Type"); !strings.HasPrefix(ct, mediaCSV) {
+			t.Errorf("Content-Type = %q; want %q", ct, mediaCSV)
+		}
+		if rr.Header().Get("X-Total-Count") != "3" || rr.Header().Get("X-Next-Page") != "2" {
+			t.Errorf("X-Total-Count = %q, X-Next-Page = %q; want 3 and 2", rr.Header().Get("X-Total-Count"), rr.Header().Get("X-Next-Page"))
+		}
+		records, err := csv.NewReader(rr.Body).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got [][]string
+		for _, rec := range records {
+			got = append(got, rec[:4])
+		}
+		want := [][]string{
+			{"id", "title", "author", "price"},
+			{"3", "Go in Action", "William Kennedy", "24.99"},
+			{"1", "The Go Programming Language", "Alan A. A. Donovan and Brian W. Kernighan", "32.99"},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("rows = %q; want %q", got, want)
+		}
+	})
+
+	t.Run("not acceptable", func(t *testing.T) {
+		rr := getBooks(t, "/books", "Accept", "image/png")
+		if rr.Code != http.StatusNotAcceptable {
+			t.Fatalf("status = %d; want 406", rr.Code)
+		}
+		var p Problem
+		if err := json.NewDecoder(rr.Body).Decode(&p); err != nil {
+			t.Fatal(err)
+		}
+		if p.Code != errorsx.CodeNotAcceptable {
+			t.Errorf("problem code = %q; want not_acceptable", p.Code)
+		}
+	})
+
+	if vary := getBooks(t, "/books").Header().Values("Vary"); !reflect.DeepEqual(vary, []string{"Accept-Encoding", "Accept"}) {
+		t.Errorf("Vary = %q; want Accept-Encoding and Accept", vary)
+	}
+}
+
+func TestBook_XML(t *testing.T) {
+	router, _, token := auditRouter(t)
+	xmlBody := http.Header{
+		"Authorization": {"Bearer " + token},
+		"Content-Type":  {"application/xml; charset=utf-8"},
+		"Accept":        {mediaXML},
+	}
+
+	rr := auditRequest(t, router, http.MethodPost, "/books",
+		`<book><title>Learning Go</title><author>Jon Bodner</author><price>29.99</price></book>`, xmlBody, http.StatusCreated)
+	if ct := rr.Header().Get("Content-Type"); !strings.HasPrefix(ct, mediaXML) {
+		t.Errorf("Content-Type = %q; want %q", ct, mediaXML)
+	}
+	if body := rr.Body.String(); !strings.HasPrefix(body, "<?xml") || !strings.Contains(body, `<book id="4"><title>Learning Go</title>`) {
+		t.Errorf("body = %s; want book 4 as XML", body)
+	}
+
+	auditRequest(t, router, http.MethodPut, "/books/4",
+		`<book><title>Learning Go, 2nd ed.</title><author>Jon Bodner</author><price>39.99</price></book>`, xmlBody, http.StatusOK)
+	rr = auditRequest(t, router, http.MethodGet, "/books/4", "", http.Header{"Accept": {"text/xml;q=0.5, application/xml"}}, http.StatusOK)
+	var book Book
+	if err := xml.Unmarshal(rr.Body.Bytes(), &book); err != nil {
+		t.Fatal(err)
+	}
+	if book.ID != 4 || book.Title != "Learning Go, 2nd ed." || book.Price.String() != "39.99" || book.CreatedAt.IsZero() {
+		t.Errorf("decoded %+v; want the updated book 4", book)
+	}
+
+	// JSON stays the default both ways
+	rr = auditRequest(t, router, http.MethodGet, "/books/4", "", nil, http.StatusOK)
+	if ct := rr.Header().Get("Content-Type"); ct != mediaJSON || rr.Header().Get("Vary") == "" {
+		t.Errorf("Content-Type = %q, Vary = %q; want JSON varying by Accept", ct, rr.Header().Get("Vary"))
+	}
+	rr = auditRequest(t, router, http.MethodPost, "/books", "<book><title>x</title>", xmlBody, http.StatusBadRequest)
+	if ct := rr.Header().Get("Content-Type"); ct != problemContentType {
+		t.Errorf("Content-Type = %q; want errors as %s whatever the Accept header", ct, problemContentType)
+	}
+	auditRequest(t, router, http.MethodGet, "/books/4", "", http.Header{"Accept": {"text/csv"}}, http.StatusNotAcceptable)
+}
+
+func TestBook_Msgpack(t *testing.T) {
+	router, _, token := auditRouter(t)
+	body, err := msgpack.Marshal(map[string]any{"title": "Learning Go", "author": "Jon Bodner", "price": 2999})
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := http.Header{
+		"Authorization": {"Bearer " + token},
+		"Content-Type":  {mediaMsgpack},
+		"Accept":        {mediaMsgpack},
+	}
+
+	rr := auditRequest(t, router, http.MethodPost, "/books", string(body), header, http.StatusCreated)
+	if ct := rr.Header().Get("Content-Type"); ct != mediaMsgpack {
+		t.Errorf("Content-Type = %q; want %q", ct, mediaMsgpack)
+	}
+	var book Book
+	if err := msgpack.Unmarshal(rr.Body.Bytes(), &book); err != nil {
+		t.Fatal(err)
+	}
+	if book.ID != 4 || book.Title != "Learning Go" || book.Price.String() != "29.99" || book.CreatedAt.IsZero() {
+		t.Errorf("decoded %+v; want book 4 priced 29.99", book)
+	}
+
+	rr = auditRequest(t, router, http.MethodGet, "/books?limit=2", "", http.Header{"Accept": {"application/msgpack, application/json;q=0.5"}}, http.StatusOK)
+	var list BookList
+	if err := msgpack.Unmarshal(rr.Body.Bytes(), &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Books) != 2 || list.Pagination.Total != 4 || list.Pagination.NextPage == nil {
+		t.Errorf("decoded %+v; want 2 of 4 books and a next page", list)
+	}
+	if js, _ := json.Marshal(list); rr.Body.Len() >= len(js) {
+		t.Errorf("MessagePack list is %d bytes, JSON %d; want it smaller", rr.Body.Len(), len(js))
+	}
+
+	// Wildcards keep getting JSON, and a bad body is a 400 problem
+	rr = auditRequest(t, router, http.MethodGet, "/books/4", "", http.Header{"Accept": {"*/*"}}, http.StatusOK)
+	if ct := rr.Header().Get("Content-Type"); ct != mediaJSON {
+		t.Errorf("Content-Type = %q for */*; want %q", ct, mediaJSON)
+	}
+	auditRequest(t, router, http.MethodPost, "/books", "\x82\xa5title", header, http.StatusBadRequest)
+}
+
+func TestGzipMiddleware(t *testing.T) {
+	t.Run("compressed", func(t *testing.T) {
+		rr := getBooks(t, "/books", "Accept-Encoding", "br;q=1, gzip;q=0.8")
+		if rr.Header().Get("Content-Encoding") != "gzip" {
+			t.Fatalf("Content-Encoding = %q; want gzip", rr.Header().Get("Content-Encoding"))
+		}
+		zr, err := gzip.NewReader(rr.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var list BookList
+		if err := json.NewDecoder(zr).Decode(&list); err != nil {
+			t.Fatal(err)
+		}
+		if len(list.Books) != 3 {
+			t.Errorf("decompressed %d books; want 3", len(list.Books))
+		}
+	})
+
+	for _, enc := range []string{"", "identity", "gzip;q=0", "*;q=0"} {
+		t.Run("not accepted: "+enc, func(t *testing.T) {
+			rr := getBooks(t, "/books", "Accept-Encoding", enc)
+			if ce := rr.Header().Get("Content-Encoding"); ce != "" {
+				t.Errorf("Content-Encoding = %q; want none", ce)
+			}
+			if !json.Valid(rr.Body.Bytes()) {
+				t.Errorf("body is not plain JSON: %q", rr.Body.String())
+			}
+		})
+	}
+
+	t.Run("no body", func(t *testing.T) {
+		handler := gzipMiddleware()(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		})
+		req := httptest.NewRequest(http.MethodDelete, "/books/1", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		if rr.Code != http.StatusNoContent || rr.Header().Get("Content-Encoding") != "" || rr.Body.Len() != 0 {
+			t.Errorf("got %d, Content-Encoding %q, %d body bytes; want a bare 204", rr.Code, rr.Header().Get("Content-Encoding"), rr.Body.Len())
+		}
+	})
+}
@@ -0,0 +1,260 @@
+package restapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/errorsx"
+	"github.com/rehan/go-interview-prep/pkg/money"
+	"github.com/rehan/go-interview-prep/pkg/validator"
+)
+
+// An order is paid for in two steps. POST /orders creates the order and a
+// payment for it at the provider, and answers at once with the order
+// awaiting payment; the provider then tells us how the payment went with a
+// webhook, which moves the order to paid or payment_failed. Orders are
+// kept in memory whichever store the books use.
+
+// OrderStatus is where an order is in being paid for
+type OrderStatus string
+
+const (
+	OrderAwaitingPayment OrderStatus = "awaiting_payment"
+	OrderPaid            OrderStatus = "paid"
+	OrderPaymentFailed   OrderStatus = "payment_failed"
+)
+
+// Order is a request to buy copies of a book
+type Order struct {
+	ID        int          `json:"id"`
+	BookID    int          `json:"book_id"`
+	Quantity  int          `json:"quantity"`
+	Amount    money.Amount `json:"amount"`
+	Status    OrderStatus  `json:"status"`
+	PaymentID string       `json:"payment_id,omitempty"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+
+	// Customer is the actor who placed the order; only they can see it
+	Customer string `json:"-"`
+}
+
+// OrderRequest is the body of POST /orders
+type OrderRequest struct {
+	BookID   int `json:"book_id" validate:"min=1"`
+	Quantity int `json:"quantity" validate:"min=1,max=100"`
+}
+
+// orderCurrency is the currency of every price in the store
+const orderCurrency = "USD"
+
+// Orders holds the orders and talks to the payment provider about them.
+// It is safe for concurrent use.
+type Orders struct {
+	payments *PaymentClient
+	secret   []byte // signs the provider's webhooks
+	now      func() time.Time
+
+	mu     sync.Mutex
+	orders map[int]*Order
+	nextID int
+	// byKey finds the order a customer's Idempotency-Key created, by
+	// customer+"\x00"+key, so one customer cannot replay another's key
+	byKey map[string]int
+	// events holds the IDs of the webhook events already applied. The
+	// provider sends an event again until it gets a 2xx, so one may
+	// arrive twice.
+	events map[string]bool
+}
+
+// NewOrders returns an empty Orders paying through payments and accepting
+// webhooks signed with secret
+func NewOrders(payments *PaymentClient, secret []byte) *Orders {
+	return &Orders{
+		payments: payments,
+		secret:   secret,
+		now:      time.Now,
+		orders:   make(map[int]*Order),
+		nextID:   1,
+		byKey:    make(map[string]int),
+		events:   make(map[string]bool),
+	}
+}
+
+// paymentReference returns the reference the provider knows order id by.
+// It doubles as the provider's idempotency key, so however often we ask
+// for the order's payment, there is only one.
+func paymentReference(id int) string {
+	return "order-" + strconv.Itoa(id)
+}
+
+// place creates customer's order for req, or with a key the customer has
+// used before returns the order it created, reporting that it did. A
+// replay whose order never got a payment, because the provider could not
+// be reached, asks the provider again.
+func (o *Orders) place(ctx context.Context, store BookRepository, customer, key string, req OrderRequest) (Order, bool, error) {
+	o.mu.Lock()
+	var order *Order
+	if key != "" {
+		if id, ok := o.byKey[customer+"\x00"+key]; ok {
+			order = o.orders[id]
+			if order.BookID != req.BookID || order.Quantity != req.Quantity {
+				o.mu.Unlock()
+				return Order{}, false, errorsx.New(errorsx.CodeConflict, "Idempotency-Key was already used for a different order")
+			}
+			if order.PaymentID != "" {
+				defer o.mu.Unlock()
+				return *order, true, nil
+			}
+		}
+	}
+	if order == nil {
+		book, ok := store.GetBook(req.BookID)
+		if !ok {
+			o.mu.Unlock()
+			return Order{}, false, errorsx.Errorf(errorsx.CodeInvalidArgument, "Book %d does not exist", req.BookID)
+		}
+		amount, err := book.Price.Mul(int64(req.Quantity))
+		if err != nil {
+			o.mu.Unlock()
+			return Order{}, false, errorsx.Wrap(err, errorsx.CodeInvalidArgument, "Order total is too large")
+		}
+		now := o.now()
+		order = &Order{
+			ID:        o.nextID,
+			BookID:    req.BookID,
+			Quantity:  req.Quantity,
+			Amount:    amount,
+			Status:    OrderAwaitingPayment,
+			Customer:  customer,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		o.nextID++
+		o.orders[order.ID] = order
+		if key != "" {
+			o.byKey[customer+"\x00"+key] = order.ID
+		}
+	}
+	id, amount := order.ID, order.Amount
+	o.mu.Unlock()
+
+	// The provider is called without the lock, so a slow one holds up only
+	// this request. Two replays racing here both ask for the payment, and
+	// the provider's idempotency key gives them the same one.
+	ref := paymentReference(id)
+	payment, err := o.payments.CreatePayment(ctx, PaymentRequest{Amount: amount, Currency: orderCurrency, Reference: ref}, ref)
+	if err != nil {
+		// The order stays, so a retry with the same key picks it up
+		return Order{}, false, errorsx.Wrap(err, errorsx.CodeUnavailable, "Payment provider unavailable; retry with the same Idempotency-Key")
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	order = o.orders[id]
+	// A webhook may have got here first
+	if order.PaymentID == "" {
+		order.PaymentID = payment.ID
+		order.UpdatedAt = o.now()
+	}
+	return *order, false, nil
+}
+
+// get returns customer's order id. Other customers' orders are reported
+// missing, not forbidden, so their IDs give nothing away.
+func (o *Orders) get(customer string, id int) (Order, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	order, ok := o.orders[id]
+	if !ok || order.Customer != customer {
+		return Order{}, false
+	}
+	return *order, true
+}
+
+// applyPaymentEvent records the outcome of an order's payment. Only an
+// order awaiting payment changes: the provider may deliver events out of
+// order or twice, and a paid order stays paid. Event types we do not know
+// are accepted and ignored, so the provider stops sending them.
+func (o *Orders) applyPaymentEvent(ev PaymentEvent) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.events[ev.ID] {
+		return nil
+	}
+	idStr, found := strings.CutPrefix(ev.Reference, "order-")
+	id, err := strconv.Atoi(idStr)
+	order, ok := o.orders[id]
+	if !found || err != nil || !ok {
+		return errorsx.Errorf(errorsx.CodeNotFound, "No order for reference %q", ev.Reference)
+	}
+	if order.PaymentID != "" && order.PaymentID != ev.PaymentID {
+		return errorsx.Errorf(errorsx.CodeConflict, "Order %d is paid by %s, not %s", order.ID, order.PaymentID, ev.PaymentID)
+	}
+	// The webhook can beat the provider's answer to CreatePayment
+	order.PaymentID = ev.PaymentID
+	if order.Status == OrderAwaitingPayment {
+		switch ev.Type {
+		case PaymentSucceeded:
+			order.Status = OrderPaid
+			order.UpdatedAt = o.now()
+		case PaymentFailed:
+			order.Status = OrderPaymentFailed
+			order.UpdatedAt = o.now()
+		}
+	}
+	o.events[ev.ID] = true
+	return nil
+}
+
+// idempotentReplayedHeader marks a response replayed for a repeated
+// Idempotency-Key rather than made afresh
+const idempotentReplayedHeader = "Idempotent-Replayed"
+
+// handleCreateOrder handles POST /orders. A client that sends an
+// Idempotency-Key header can retry a request whose answer it never got,
+// and gets the order the first one placed instead of a second order.
+func handleCreateOrder(w http.ResponseWriter, r *http.Request, store BookRepository, orders *Orders) {
+	var req OrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, errorsx.Wrap(err, errorsx.CodeInvalidArgument, "Invalid request body"))
+		return
+	}
+	if err := validator.Struct(req); err != nil {
+		respondWithError(w, errorsx.Wrap(err, errorsx.CodeInvalidArgument, "Invalid order"))
+		return
+	}
+
+	order, replayed, err := orders.place(r.Context(), store, ActorFromContext(r.Context()), r.Header.Get("Idempotency-Key"), req)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	if replayed {
+		w.Header().Set(idempotentReplayedHeader, "true")
+	}
+	w.Header().Set("Location", fmt.Sprintf("/orders/%d", order.ID))
+	respondWithJSON(w, http.StatusCreated, order)
+}
+
+// handleGetOrder handles GET /orders/{id}, which clients poll to see the
+// payment go through
+func handleGetOrder(w http.ResponseWriter, r *http.Request, orders *Orders) {
+	id, err := pathID(r)
+	if err != nil {
+		respondWithError(w, errorsx.Wrap(err, errorsx.CodeInvalidArgument, "Invalid order ID"))
+		return
+	}
+	order, ok := orders.get(ActorFromContext(r.Context()), id)
+	if !ok {
+		respondWithError(w, errorsx.New(errorsx.CodeNotFound, "Order not found"))
+		return
+	}
+	respondWithJSON(w, http.StatusOK, order)
+}
@@ -0,0 +1,248 @@
+package restapi
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/money"
+)
+
+// orderSend sends a request with the given header pairs to the orders
+// router as user; the empty user is anonymous
+type orderSend func(method, path, user, body string, header ...string) *httptest.ResponseRecorder
+
+// testOrders returns the payment provider behind a router taking orders
+// from alice (admin) and bob (reader). The router is served over HTTP so
+// the provider's webhooks reach it.
+func testOrders(t *testing.T) (*FakePaymentProvider, orderSend) {
+	t.Helper()
+	auth, _ := testAuth(t)
+	auth.users = newUserStore(map[string]Account{
+		"alice": {Password: "wonderland", Role: RoleAdmin},
+		"bob":   {Password: "builder", Role: RoleReader},
+	})
+	provider := NewFakePaymentProvider(webhookTestSecret, "")
+	orders := NewOrders(testPaymentClient(t, provider), webhookTestSecret)
+	router := newRouter(NewBookStore(), auth, slog.New(slog.NewTextHandler(io.Discard, nil)), nil, nil, nil, nil, nil, nil, orders, nil)
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+	provider.webhookURL = srv.URL + "/webhooks/payment"
+
+	tokens := make(map[string]string)
+	for user, password := range map[string]string{"alice": "wonderland", "bob": "builder"} {
+		var lr LoginResponse
+		if err := json.NewDecoder(login(t, router, `{"username":"`+user+`","password":"`+password+`"}`).Body).Decode(&lr); err != nil {
+			t.Fatal(err)
+		}
+		tokens[user] = lr.Token
+	}
+	return provider, func(method, path, user, body string, header ...string) *httptest.ResponseRecorder {
+		if user != "" {
+			header = append(header, "Authorization", "Bearer "+tokens[user])
+		}
+		return sendWithSession(router, method, path, body, nil, header...)
+	}
+}
+
+func decodeOrder(t *testing.T, rr *httptest.ResponseRecorder) Order {
+	t.Helper()
+	var order Order
+	if err := json.NewDecoder(rr.Body).Decode(&order); err != nil {
+		t.Fatalf("decoding order: %v (status %d)", err, rr.Code)
+	}
+	return order
+}
+
+// An order awaits payment until the provider's webhook says how it went
+func TestOrders_PaidByWebhook(t *testing.T) {
+	provider, send := testOrders(t)
+
+	rr := send(http.MethodPost, "/orders", "bob", `{"book_id":1,"quantity":2}`, "Idempotency-Key", "k1")
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("status = %d; want 201 (body: %s)", rr.Code, rr.Body.String())
+	}
+	if loc := rr.Header().Get("Location"); loc != "/orders/1" {
+		t.Errorf("Location = %q; want /orders/1", loc)
+	}
+	order := decodeOrder(t, rr)
+	if order.Status != OrderAwaitingPayment || order.Amount != money.MustParse("65.98") || order.PaymentID == "" {
+		t.Fatalf("order = %+v; want 65.98 awaiting payment with a payment ID", order)
+	}
+	payments := provider.Payments()
+	if len(payments) != 1 || payments[0].Amount != order.Amount || payments[0].Reference != "order-1" {
+		t.Fatalf("provider has %+v; want one payment of 65.98 for order-1", payments)
+	}
+
+	if err := provider.Settle(context.Background(), order.PaymentID, true); err != nil {
+		t.Fatal(err)
+	}
+	if got := decodeOrder(t, send(http.MethodGet, "/orders/1", "bob", "")); got.Status != OrderPaid {
+		t.Fatalf("after the webhook status = %s; want paid", got.Status)
+	}
+
+	// A later failure event, as a provider sending events out of order
+	// might, does not unpay the order
+	if err := provider.Settle(context.Background(), order.PaymentID, false); err != nil {
+		t.Fatal(err)
+	}
+	if got := decodeOrder(t, send(http.MethodGet, "/orders/1", "bob", "")); got.Status != OrderPaid {
+		t.Errorf("after a late failure status = %s; want paid", got.Status)
+	}
+}
+
+func TestOrders_PaymentFailed(t *testing.T) {
+	provider, send := testOrders(t)
+	order := decodeOrder(t, send(http.MethodPost, "/orders", "bob", `{"book_id":2,"quantity":1}`))
+	if err := provider.Settle(context.Background(), order.PaymentID, false); err != nil {
+		t.Fatal(err)
+	}
+	if got := decodeOrder(t, send(http.MethodGet, "/orders/1", "bob", "")); got.Status != OrderPaymentFailed {
+		t.Errorf("status = %s; want payment_failed", got.Status)
+	}
+}
+
+// The provider sends an event again when it misses our answer; applying
+// it twice must do no more than applying it once
+func TestOrders_DuplicateWebhook(t *testing.T) {
+	provider, send := testOrders(t)
+	first := decodeOrder(t, send(http.MethodPost, "/orders", "bob", `{"book_id":1,"quantity":1}`))
+	second := decodeOrder(t, send(http.MethodPost, "/orders", "bob", `{"book_id":1,"quantity":1}`))
+
+	succeeded := PaymentEvent{ID: "evt_9", Type: PaymentSucceeded, PaymentID: first.PaymentID, Reference: "order-1"}
+	for range 2 {
+		if err := provider.SendEvent(context.Background(), succeeded); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Reusing the event ID for another event is a replay, so it is ignored
+	failed := PaymentEvent{ID: "evt_9", Type: PaymentFailed, PaymentID: second.PaymentID, Reference: "order-2"}
+	if err := provider.SendEvent(context.Background(), failed); err != nil {
+		t.Fatal(err)
+	}
+	if got := decodeOrder(t, send(http.MethodGet, "/orders/1", "bob", "")); got.Status != OrderPaid {
+		t.Errorf("order 1 status = %s; want paid", got.Status)
+	}
+	if got := decodeOrder(t, send(http.MethodGet, "/orders/2", "bob", "")); got.Status != OrderAwaitingPayment {
+		t.Errorf("order 2 status = %s; want awaiting_payment", got.Status)
+	}
+}
+
+func TestOrders_IdempotencyKey(t *testing.T) {
+	provider, send := testOrders(t)
+	body := `{"book_id":1,"quantity":1}`
+
+	first := send(http.MethodPost, "/orders", "bob", body, "Idempotency-Key", "k1")
+	again := send(http.MethodPost, "/orders", "bob", body, "Idempotency-Key", "k1")
+	if again.Code != http.StatusCreated || again.Header().Get(idempotentReplayedHeader) != "true" {
+		t.Errorf("replay: status %d, %s %q; want 201 marked replayed", again.Code, idempotentReplayedHeader, again.Header().Get(idempotentReplayedHeader))
+	}
+	if first.Header().Get(idempotentReplayedHeader) != "" {
+		t.Error("first response marked replayed")
+	}
+	if a, b := decodeOrder(t, first), decodeOrder(t, again); a.ID != b.ID || a.PaymentID != b.PaymentID {
+		t.Errorf("replay got order %d paid by %s; want order %d paid by %s", b.ID, b.PaymentID, a.ID, a.PaymentID)
+	}
+
+	if rr := send(http.MethodPost, "/orders", "bob", `{"book_id":1,"quantity":3}`, "Idempotency-Key", "k1"); rr.Code != http.StatusConflict {
+		t.Errorf("same key, other order: status %d; want 409", rr.Code)
+	}
+	// Keys are per customer, so alice's k1 is her own
+	if got := decodeOrder(t, send(http.MethodPost, "/orders", "alice", body, "Idempotency-Key", "k1")); got.ID == 1 {
+		t.Error("alice's k1 replayed bob's order")
+	}
+	// Without a key, every request is a new order
+	send(http.MethodPost, "/orders", "bob", body)
+	send(http.MethodPost, "/orders", "bob", body)
+	if n := len(provider.Payments()); n != 4 {
+		t.Errorf("provider has %d payments; want 4", n)
+	}
+}
+
+// While the provider is down the order is kept, and retrying with the
+// same key pays for it rather than placing another
+func TestOrders_ProviderDown(t *testing.T) {
+	provider, send := testOrders(t)
+	body := `{"book_id":1,"quantity":1}`
+
+	provider.FailNext(3)
+	if rr := send(http.MethodPost, "/orders", "bob", body, "Idempotency-Key", "k1"); rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d; want 503 (body: %s)", rr.Code, rr.Body.String())
+	}
+	rr := send(http.MethodPost, "/orders", "bob", body, "Idempotency-Key", "k1")
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("retry status = %d; want 201 (body: %s)", rr.Code, rr.Body.String())
+	}
+	if order := decodeOrder(t, rr); order.ID != 1 || order.PaymentID == "" {
+		t.Errorf("retry got %+v; want order 1 with its payment", order)
+	}
+	if n := len(provider.Payments()); n != 1 {
+		t.Errorf("provider has %d payments; want 1", n)
+	}
+}
+
+func TestOrders_Invalid(t *testing.T) {
+	_, send := testOrders(t)
+	tests := []struct {
+		name, body string
+		want       int
+	}{
+		{"no quantity", `{"book_id":1}`, http.StatusBadRequest},
+		{"too many", `{"book_id":1,"quantity":101}`, http.StatusBadRequest},
+		{"no such book", `{"book_id":99,"quantity":1}`, http.StatusBadRequest},
+		{"not JSON", `{`, http.StatusBadRequest},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if rr := send(http.MethodPost, "/orders", "bob", tc.body); rr.Code != tc.want {
+				t.Errorf("status = %d; want %d (body: %s)", rr.Code, tc.want, rr.Body.String())
+			}
+		})
+	}
+}
+
+// Orders are visible to their customer only; to anyone else they do not
+// exist
+func TestOrders_OnlyTheCustomerSees(t *testing.T) {
+	_, send := testOrders(t)
+	send(http.MethodPost, "/orders", "bob", `{"book_id":1,"quantity":1}`)
+	if rr := send(http.MethodGet, "/orders/1", "alice", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("alice: status %d; want 404", rr.Code)
+	}
+	if rr := send(http.MethodGet, "/orders/1", "", ""); rr.Code != http.StatusUnauthorized {
+		t.Errorf("anonymous: status %d; want 401", rr.Code)
+	}
+}
+
+func TestPaymentWebhook_Rejected(t *testing.T) {
+	_, send := testOrders(t)
+	order := decodeOrder(t, send(http.MethodPost, "/orders", "bob", `{"book_id":1,"quantity":1}`))
+	event := func(ref, paymentID string) string {
+		return `{"id":"evt_1","type":"payment.succeeded","payment_id":"` + paymentID + `","reference":"` + ref + `"}`
+	}
+	now := time.Now()
+	tests := []struct {
+		name, body, signature string
+		want                  int
+	}{
+		{"unsigned", event("order-1", order.PaymentID), "", http.StatusUnauthorized},
+		{"signed with another secret", event("order-1", order.PaymentID), signPaymentWebhook([]byte(strings.Repeat("x", 32)), now, []byte(event("order-1", order.PaymentID))), http.StatusUnauthorized},
+		{"unknown order", event("order-9", "pay_9"), signPaymentWebhook(webhookTestSecret, now, []byte(event("order-9", "pay_9"))), http.StatusNotFound},
+		{"other payment", event("order-1", "pay_9"), signPaymentWebhook(webhookTestSecret, now, []byte(event("order-1", "pay_9"))), http.StatusConflict},
+		{"not JSON", "{", signPaymentWebhook(webhookTestSecret, now, []byte("{")), http.StatusBadRequest},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := send(http.MethodPost, "/webhooks/payment", "", tc.body, paymentSignatureHeader, tc.signature)
+			if rr.Code != tc.want {
+				t.Errorf("status = %d; want %d (body: %s)", rr.Code, tc.want, rr.Body.String())
+			}
+		})
+	}
+}
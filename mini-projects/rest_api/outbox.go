@@ -0,0 +1,333 @@
+package restapi
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/clock"
+)
+
+// OutboxRecord is a book change waiting in the outbox to be published
+type OutboxRecord struct {
+	ID        int64
+	Change    BookChange
+	Attempts  int    // failed publishes so far
+	LastError string // of the last failed publish
+}
+
+// Outbox holds book changes until the relay has published them. A change
+// is recorded under the same lock as the mutation that made it, the
+// in-memory stand-in for writing both in one database transaction: the
+// outbox has a record of every change, in the order the changes were
+// made, and never one of a change that did not happen. Publishing then
+// happens after the mutation, so a slow or failing publisher cannot hold
+// up or fail a request.
+//
+// The outbox is not persisted, so with either store a crash loses the
+// changes not yet published; with a database it would be a table written
+// in the mutation's transaction.
+type Outbox struct {
+	mu      sync.Mutex // held across a mutation and its record
+	records []OutboxRecord
+	nextID  int64
+	notify  chan struct{} // has a value once records are added
+	idle    chan struct{} // closed while records is empty
+}
+
+// NewOutbox returns an empty outbox
+func NewOutbox() *Outbox {
+	idle := make(chan struct{})
+	close(idle)
+	return &Outbox{notify: make(chan struct{}, 1), idle: idle}
+}
+
+// add records c. o.mu must be held.
+func (o *Outbox) add(c BookChange) {
+	if len(o.records) == 0 {
+		o.idle = make(chan struct{})
+	}
+	o.nextID++
+	o.records = append(o.records, OutboxRecord{ID: o.nextID, Change: c})
+	select {
+	case o.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Pending returns the records not yet published, oldest first
+func (o *Outbox) Pending() []OutboxRecord {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return slices.Clone(o.records)
+}
+
+// Wait waits until every record has been published, or ctx ends
+func (o *Outbox) Wait(ctx context.Context) error {
+	o.mu.Lock()
+	idle := o.idle
+	o.mu.Unlock()
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// next returns the oldest record, if there is one
+func (o *Outbox) next() (OutboxRecord, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if len(o.records) == 0 {
+		return OutboxRecord{}, false
+	}
+	return o.records[0], true
+}
+
+// sent removes record id, which next returned, once it is published
+func (o *Outbox) sent(id int64) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if len(o.records) == 0 || o.records[0].ID != id {
+		return
+	}
+	o.records[0] = OutboxRecord{}
+	o.records = o.records[1:]
+	if len(o.records) == 0 {
+		close(o.idle)
+	}
+}
+
+// failed notes a failed attempt to publish record id
+func (o *Outbox) failed(id int64, err error) int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if len(o.records) == 0 || o.records[0].ID != id {
+		return 0
+	}
+	o.records[0].Attempts++
+	o.records[0].LastError = err.Error()
+	return o.records[0].Attempts
+}
+
+// OutboxRelay publishes an outbox's records in order, at least once: a
+// record is removed only after publish returns nil, and one that fails is
+// retried after a backoff, with the records behind it waiting, until it
+// goes through. Nothing is given up on, so a publisher that is down for a
+// while gets every change once it is back.
+type OutboxRelay struct {
+	outbox              *Outbox
+	publish             func(BookChange) error
+	backoff, maxBackoff time.Duration
+	clock               clock.Clock // times the backoffs; clock.Real outside tests
+}
+
+// NewOutboxRelay returns a relay from outbox to publish, which must be
+// safe to call again with a change it already published
+func NewOutboxRelay(outbox *Outbox, publish func(BookChange) error) *OutboxRelay {
+	return &OutboxRelay{outbox: outbox, publish: publish, backoff: 100 * time.Millisecond, maxBackoff: 5 * time.Second, clock: clock.Real}
+}
+
+// Run publishes records as they are added until ctx is done, then makes
+// one last pass, so a clean shutdown leaves nothing behind unless the
+// publisher is failing
+func (r *OutboxRelay) Run(ctx context.Context) {
+	backoff := r.backoff
+	for {
+		var wake <-chan struct{}
+		var timer clock.Timer
+		var retry <-chan time.Time
+		if r.relay() {
+			backoff = r.backoff
+			wake = r.outbox.notify
+		} else {
+			// New records queue behind the failed one, so only the
+			// backoff ends the wait
+			timer = r.clock.NewTimer(backoff)
+			retry = timer.C()
+			backoff = min(2*backoff, r.maxBackoff)
+		}
+		select {
+		case <-wake:
+		case <-retry:
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			if !r.relay() {
+				slog.Error("outbox not empty at shutdown", "pending", len(r.outbox.Pending()))
+			}
+			return
+		}
+	}
+}
+
+// relay publishes records until the outbox is empty, reporting true, or
+// one fails
+func (r *OutboxRelay) relay() bool {
+	for {
+		rec, ok := r.outbox.next()
+		if !ok {
+			return true
+		}
+		if err := r.publish(rec.Change); err != nil {
+			attempts := r.outbox.failed(rec.ID, err)
+			slog.Warn("publishing book change", "outbox_id", rec.ID, "type", rec.Change.Type, "book_id", rec.Change.ID, "attempts", attempts, "error", err)
+			return false
+		}
+		r.outbox.sent(rec.ID)
+	}
+}
+
+// outboxRepository records every change made through it in the outbox,
+// under the outbox's lock, so the book read before and after a change is
+// the one it changed. It is made per request, so changes name who made
+// them.
+type outboxRepository struct {
+	BookRepository
+	outbox           *Outbox
+	actor, requestID string
+}
+
+// outboxFor returns store recording r's changes in outbox
+func outboxFor(store BookRepository, outbox *Outbox, r *http.Request) outboxRepository {
+	return outboxRepository{store, outbox, ActorFromContext(r.Context()), RequestIDFromContext(r.Context())}
+}
+
+func (r outboxRepository) record(typ string, id int, before, after *Book) {
+	r.outbox.add(r.change(typ, id, before, after))
+}
+
+func (r outboxRepository) change(typ string, id int, before, after *Book) BookChange {
+	return BookChange{
+		Type:      typ,
+		ID:        id,
+		Before:    before,
+		After:     after,
+		Actor:     r.actor,
+		RequestID: r.requestID,
+		Time:      time.Now().UTC(),
+	}
+}
+
+// book returns the book id, or nil if there is none
+func (r outboxRepository) book(id int) *Book {
+	if book, ok := r.GetBook(id); ok {
+		return &book
+	}
+	return nil
+}
+
+func (r outboxRepository) AddBook(book Book) int {
+	r.outbox.mu.Lock()
+	defer r.outbox.mu.Unlock()
+	id := r.BookRepository.AddBook(book)
+	r.record(EventBookCreated, id, nil, r.book(id))
+	return id
+}
+
+func (r outboxRepository) AddBooks(books []Book) []int {
+	r.outbox.mu.Lock()
+	defer r.outbox.mu.Unlock()
+	ids := r.BookRepository.AddBooks(books)
+	for _, id := range ids {
+		r.record(EventBookCreated, id, nil, r.book(id))
+	}
+	return ids
+}
+
+func (r outboxRepository) UpdateBook(id int, book Book) bool {
+	r.outbox.mu.Lock()
+	defer r.outbox.mu.Unlock()
+	before := r.book(id)
+	ok := r.BookRepository.UpdateBook(id, book)
+	if ok {
+		r.record(EventBookUpdated, id, before, r.book(id))
+	}
+	return ok
+}
+
+func (r outboxRepository) DeleteBook(id int) bool {
+	r.outbox.mu.Lock()
+	defer r.outbox.mu.Unlock()
+	before := r.book(id)
+	ok := r.BookRepository.DeleteBook(id)
+	if ok {
+		r.record(EventBookDeleted, id, before, nil)
+	}
+	return ok
+}
+
+// Begin starts a transaction whose changes are recorded in the outbox
+// when, and only if, it commits
+func (r outboxRepository) Begin() (Tx, error) {
+	tx, err := r.BookRepository.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return &outboxTx{Tx: tx, r: r}, nil
+}
+
+// outboxTx holds a transaction's changes until Commit, which records them
+// under the outbox's lock, as outboxRepository does a single change
+type outboxTx struct {
+	Tx
+	r       outboxRepository
+	changes []BookChange
+}
+
+// book returns the book id as the transaction sees it, or nil
+func (t *outboxTx) book(id int) *Book {
+	if book, ok := t.GetBook(id); ok {
+		return &book
+	}
+	return nil
+}
+
+func (t *outboxTx) AddBook(book Book) int {
+	id := t.Tx.AddBook(book)
+	t.changes = append(t.changes, t.r.change(EventBookCreated, id, nil, t.book(id)))
+	return id
+}
+
+func (t *outboxTx) AddBooks(books []Book) []int {
+	ids := t.Tx.AddBooks(books)
+	for _, id := range ids {
+		t.changes = append(t.changes, t.r.change(EventBookCreated, id, nil, t.book(id)))
+	}
+	return ids
+}
+
+func (t *outboxTx) UpdateBook(id int, book Book) bool {
+	before := t.book(id)
+	ok := t.Tx.UpdateBook(id, book)
+	if ok {
+		t.changes = append(t.changes, t.r.change(EventBookUpdated, id, before, t.book(id)))
+	}
+	return ok
+}
+
+func (t *outboxTx) DeleteBook(id int) bool {
+	before := t.book(id)
+	ok := t.Tx.DeleteBook(id)
+	if ok {
+		t.changes = append(t.changes, t.r.change(EventBookDeleted, id, before, nil))
+	}
+	return ok
+}
+
+func (t *outboxTx) Commit() error {
+	t.r.outbox.mu.Lock()
+	defer t.r.outbox.mu.Unlock()
+	if err := t.Tx.Commit(); err != nil {
+		return err
+	}
+	for _, c := range t.changes {
+		t.r.outbox.add(c)
+	}
+	return nil
+}
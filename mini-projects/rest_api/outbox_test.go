@@ -0,0 +1,194 @@
+package restapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/clock"
+)
+
+// flakyPublisher is a publisher that can be taken down, failing every
+// publish until it is brought back
+type flakyPublisher struct {
+	mu        sync.Mutex
+	down      bool
+	failures  int
+	published []BookChange
+}
+
+func (p *flakyPublisher) publish(c BookChange) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.down {
+		p.failures++
+		return errors.New("broker unavailable")
+	}
+	p.published = append(p.published, c)
+	return nil
+}
+
+func (p *flakyPublisher) setDown(down bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.down = down
+}
+
+// state returns the failed publishes so far and the books published, as
+// "<type> <id>"
+func (p *flakyPublisher) state() (int, []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var books []string
+	for _, c := range p.published {
+		books = append(books, fmt.Sprintf("%s %d", c.Type, c.ID))
+	}
+	return p.failures, books
+}
+
+// startRelay runs a relay from outbox to p, retrying a second apart by
+// clk, and returns a function that stops it and waits for it to return
+func startRelay(outbox *Outbox, p *flakyPublisher, clk clock.Clock) (stop func()) {
+	relay := NewOutboxRelay(outbox, p.publish)
+	relay.backoff, relay.maxBackoff, relay.clock = time.Second, time.Second, clk
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		relay.Run(ctx)
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+func waitOutbox(t *testing.T, outbox *Outbox) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := outbox.Wait(ctx); err != nil {
+		t.Fatalf("outbox still has %d records: %v", len(outbox.Pending()), err)
+	}
+}
+
+// The outbox records changes under the same lock as making them, so even
+// racing updates to one book each record the book they changed
+func TestOutbox_RecordsWithMutation(t *testing.T) {
+	outbox := NewOutbox()
+	store := outboxRepository{BookRepository: NewBookStore(), outbox: outbox, actor: "user:alice"}
+
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			store.UpdateBook(1, Book{Title: fmt.Sprintf("Edition %d", i), Author: "A", Price: 1})
+		}()
+	}
+	wg.Wait()
+	if store.DeleteBook(99) || store.UpdateBook(99, Book{Title: "T", Author: "A", Price: 1}) {
+		t.Fatal("changed book 99, which does not exist")
+	}
+
+	records := outbox.Pending()
+	if len(records) != 50 {
+		t.Fatalf("%d records; want one per update and none for the failed changes", len(records))
+	}
+	for i, rec := range records {
+		if rec.ID != int64(i+1) || rec.Change.Type != EventBookUpdated || rec.Change.Actor != "user:alice" {
+			t.Fatalf("record %d = %+v; want update %d by alice", i, rec, i+1)
+		}
+		if i > 0 && *rec.Change.Before != *records[i-1].Change.After {
+			t.Errorf("record %d changed %q, but the one before left %q", i, rec.Change.Before.Title, records[i-1].Change.After.Title)
+		}
+	}
+	if book, _ := store.GetBook(1); book != *records[49].Change.After {
+		t.Errorf("book 1 is %q; want the last record's %q", book.Title, records[49].Change.After.Title)
+	}
+}
+
+// Changes made while the publisher is down stay in the outbox, and are
+// published in order once it is back
+func TestOutboxRelay_PublisherDown(t *testing.T) {
+	auth, _ := testAuth(t)
+	outbox := NewOutbox()
+	publisher := &flakyPublisher{down: true}
+	c := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	defer startRelay(outbox, publisher, c)()
+	router := newRouter(NewBookStore(), auth, slog.New(slog.NewTextHandler(io.Discard, nil)), nil, nil, nil, nil, nil, outbox, nil, nil)
+	bearer := http.Header{"Authorization": {"Bearer " + adminToken(t, router)}}
+
+	// Mutations succeed whether or not their changes can be published
+	auditRequest(t, router, http.MethodPost, "/books", `{"title":"T","author":"A","price":1}`, bearer, http.StatusCreated)
+	auditRequest(t, router, http.MethodPut, "/books/4", `{"title":"T2","author":"A","price":2}`, bearer, http.StatusOK)
+	auditRequest(t, router, http.MethodDelete, "/books/1", "", bearer, http.StatusNoContent)
+
+	// Each failure sets a backoff timer; firing it makes the next attempt
+	c.BlockUntil(1)
+	for range 2 {
+		c.Advance(time.Second)
+		c.BlockUntil(1)
+	}
+	if failures, _ := publisher.state(); failures != 3 {
+		t.Fatalf("%d failed publishes after two backoffs; want 3", failures)
+	}
+	records := outbox.Pending()
+	if len(records) != 3 {
+		t.Fatalf("%d records pending while the publisher is down; want all 3", len(records))
+	}
+	if records[0].Attempts < 3 || records[0].LastError != "broker unavailable" || records[1].Attempts != 0 {
+		t.Errorf("records = %+v; want the first retried, with the others waiting behind it", records)
+	}
+	if _, published := publisher.state(); len(published) != 0 {
+		t.Errorf("published %v while down", published)
+	}
+
+	publisher.setDown(false)
+	c.Advance(time.Second)
+	waitOutbox(t, outbox)
+	want := []string{EventBookCreated + " 4", EventBookUpdated + " 4", EventBookDeleted + " 1"}
+	if _, published := publisher.state(); !slices.Equal(published, want) {
+		t.Errorf("published %v; want %v", published, want)
+	}
+}
+
+// Stopping the relay publishes what is left, unless the publisher still
+// fails, in which case the records stay
+func TestOutboxRelay_Stop(t *testing.T) {
+	tests := []struct {
+		name        string
+		down        bool
+		wantPending int
+	}{
+		{"publisher up", false, 0},
+		{"publisher down", true, 2},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			outbox := NewOutbox()
+			store := outboxRepository{BookRepository: NewBookStore(), outbox: outbox}
+			// A stopped context makes Run go straight to its last pass
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			store.AddBook(Book{Title: "T", Author: "A", Price: 1})
+			store.DeleteBook(2)
+
+			publisher := &flakyPublisher{down: tc.down}
+			NewOutboxRelay(outbox, publisher.publish).Run(ctx)
+			if got := len(outbox.Pending()); got != tc.wantPending {
+				t.Errorf("%d records pending after Run; want %d", got, tc.wantPending)
+			}
+			if _, published := publisher.state(); len(published)+tc.wantPending != 2 {
+				t.Errorf("published %v; want the records that are not pending", published)
+			}
+		})
+	}
+}
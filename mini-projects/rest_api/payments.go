@@ -0,0 +1,245 @@
+package restapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/errorsx"
+	"github.com/rehan/go-interview-prep/pkg/httpclient"
+	"github.com/rehan/go-interview-prep/pkg/money"
+)
+
+// The payment provider is an upstream HTTP API, in the style of Stripe's:
+// POST /v1/payments creates a payment, and the outcome arrives later as a
+// signed webhook to POST /webhooks/payment. FakePaymentProvider stands in
+// for it in tests and with -fake-payments.
+
+// PaymentRequest is the body of POST /v1/payments
+type PaymentRequest struct {
+	Amount    money.Amount `json:"amount"`
+	Currency  string       `json:"currency"`
+	Reference string       `json:"reference"` // ours, echoed in webhooks
+}
+
+// Payment is the provider's record of a payment
+type Payment struct {
+	ID        string       `json:"id"`
+	Status    string       `json:"status"` // "pending" until the webhook
+	Amount    money.Amount `json:"amount"`
+	Reference string       `json:"reference"`
+}
+
+// PaymentEvent is the body of a payment webhook
+type PaymentEvent struct {
+	ID        string `json:"id"`   // unique per event, retries included
+	Type      string `json:"type"` // payment.succeeded or payment.failed
+	PaymentID string `json:"payment_id"`
+	Reference string `json:"reference"`
+}
+
+// Payment event types
+const (
+	PaymentSucceeded = "payment.succeeded"
+	PaymentFailed    = "payment.failed"
+)
+
+// PaymentClient calls the payment provider's API
+type PaymentClient struct {
+	baseURL string
+	client  *httpclient.Client
+}
+
+// NewPaymentClient returns a client for the provider at baseURL. Retries
+// and the circuit breaker are client's.
+func NewPaymentClient(baseURL string, client *httpclient.Client) *PaymentClient {
+	return &PaymentClient{baseURL: strings.TrimSuffix(baseURL, "/"), client: client}
+}
+
+// newPaymentHTTPClient returns the retrying client main gives
+// NewPaymentClient
+func newPaymentHTTPClient(logger *slog.Logger) *httpclient.Client {
+	return httpclient.New(httpclient.Options{
+		Timeout:     5 * time.Second,
+		MaxAttempts: 3,
+		Breaker:     httpclient.NewBreaker(httpclient.BreakerConfig{Threshold: 5, Cooldown: 30 * time.Second}),
+		OnResponse:  httpclient.LogAttempts(logger),
+	})
+}
+
+// newOrders returns the orders cfg configures a payment provider for, or
+// nil if it configures none. With cfg.FakePayments the provider is started
+// here, calling back the server on cfg.Addr, and stop shuts it down.
+func newOrders(cfg Config, logger *slog.Logger) (orders *Orders, stop func(), err error) {
+	switch {
+	case cfg.PaymentURL != "":
+		client := NewPaymentClient(cfg.PaymentURL, newPaymentHTTPClient(logger))
+		return NewOrders(client, []byte(cfg.PaymentWebhookSecret)), func() {}, nil
+	case cfg.FakePayments:
+		host, port, err := net.SplitHostPort(cfg.Addr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("finding the webhook address: %w", err)
+		}
+		if host == "" {
+			host = "localhost"
+		}
+		// Nothing outside the process needs the secret
+		secret := make([]byte, minWebhookSecretSize)
+		rand.Read(secret)
+		fake := NewFakePaymentProvider(secret, "http://"+net.JoinHostPort(host, port)+"/webhooks/payment")
+		fake.AutoSettle = 2 * time.Second
+		// Like a test card number, an amount ending in .13 is declined
+		fake.Decline = func(p Payment) bool { return p.Amount.Cents()%100 == 13 }
+		srv := httptest.NewServer(fake)
+		client := NewPaymentClient(srv.URL, newPaymentHTTPClient(logger))
+		return NewOrders(client, secret), srv.Close, nil
+	}
+	return nil, func() {}, nil
+}
+
+// CreatePayment asks the provider to take req.Amount. The provider
+// creates at most one payment per idempotencyKey and answers repeats with
+// that payment, which is what makes the POST safe to retry: an attempt
+// that timed out may have succeeded, and retrying it must not charge
+// twice.
+func (p *PaymentClient) CreatePayment(ctx context.Context, req PaymentRequest, idempotencyKey string) (Payment, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return Payment{}, err
+	}
+	// A bytes.Reader body lets the client send it again on a retry
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/v1/payments", bytes.NewReader(body))
+	if err != nil {
+		return Payment{}, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Idempotency-Key", idempotencyKey)
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return Payment{}, fmt.Errorf("creating payment: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return Payment{}, fmt.Errorf("creating payment: provider answered %s", resp.Status)
+	}
+	var payment Payment
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&payment); err != nil {
+		return Payment{}, fmt.Errorf("creating payment: reading the answer: %w", err)
+	}
+	return payment, nil
+}
+
+// paymentSignatureHeader carries a webhook's signature, as
+// "t=<unix seconds>,v1=<hex HMAC-SHA256>"
+const paymentSignatureHeader = "Payment-Signature"
+
+// webhookTolerance is how far a webhook's timestamp may be from our clock.
+// The timestamp is signed, so an attacker who captured a webhook can
+// replay it only this long.
+const webhookTolerance = 5 * time.Minute
+
+// webhookMAC returns the HMAC-SHA256 under secret of the timestamp and
+// body, which is what a webhook's signature covers
+func webhookMAC(secret []byte, unix int64, body []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	fmt.Fprintf(mac, "%d.", unix)
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// signPaymentWebhook returns the signature header for body sent at t
+func signPaymentWebhook(secret []byte, t time.Time, body []byte) string {
+	return fmt.Sprintf("t=%d,v1=%s", t.Unix(), hex.EncodeToString(webhookMAC(secret, t.Unix(), body)))
+}
+
+// errBadSignature is the error for webhooks that fail verification
+var errBadSignature = errors.New("invalid payment webhook signature")
+
+// verifyPaymentWebhook checks that header signs body under secret at a
+// time within webhookTolerance of now. The header may hold several v1
+// signatures, as providers send while a secret is being rotated; one
+// match is enough.
+func verifyPaymentWebhook(secret []byte, header string, body []byte, now time.Time) error {
+	var unix int64
+	var sigs [][]byte
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "t":
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return fmt.Errorf("%w: bad timestamp", errBadSignature)
+			}
+			unix = n
+		case "v1":
+			if sig, err := hex.DecodeString(v); err == nil {
+				sigs = append(sigs, sig)
+			}
+		}
+	}
+	if unix == 0 || len(sigs) == 0 {
+		return fmt.Errorf("%w: missing timestamp or signature", errBadSignature)
+	}
+	if d := now.Sub(time.Unix(unix, 0)); d > webhookTolerance || d < -webhookTolerance {
+		return fmt.Errorf("%w: timestamp outside the tolerance", errBadSignature)
+	}
+	want := webhookMAC(secret, unix, body)
+	for _, sig := range sigs {
+		// hmac.Equal takes the same time however much matches
+		if hmac.Equal(sig, want) {
+			return nil
+		}
+	}
+	return errBadSignature
+}
+
+// minWebhookSecretSize is the shortest webhook secret allowed, as long as
+// the HMAC-SHA256 it keys
+const minWebhookSecretSize = 32
+
+// maxWebhookBytes bounds a webhook body; events are a few hundred bytes
+const maxWebhookBytes = 64 << 10
+
+// handlePaymentWebhook handles POST /webhooks/payment. The route is
+// public: the signature, not a token, shows the provider sent it. 2xx
+// tells the provider to stop sending the event; anything else makes it
+// retry, so an event for an order we cannot find yet gets 404.
+func handlePaymentWebhook(w http.ResponseWriter, r *http.Request, orders *Orders) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBytes))
+	if err != nil {
+		respondWithError(w, errorsx.Wrap(err, errorsx.CodeInvalidArgument, "Invalid request body"))
+		return
+	}
+	// The body must be verified as it arrived, before decoding: JSON
+	// re-encoded from a decoded value need not be byte for byte the same
+	if err := verifyPaymentWebhook(orders.secret, r.Header.Get(paymentSignatureHeader), body, orders.now()); err != nil {
+		// No WWW-Authenticate: there is no scheme to log in with here
+		respondWithError(w, errorsx.Wrap(err, errorsx.CodeUnauthenticated, "Invalid webhook signature"))
+		return
+	}
+	var ev PaymentEvent
+	if err := json.Unmarshal(body, &ev); err != nil {
+		respondWithError(w, errorsx.Wrap(err, errorsx.CodeInvalidArgument, "Invalid payment event"))
+		return
+	}
+	if err := orders.applyPaymentEvent(ev); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
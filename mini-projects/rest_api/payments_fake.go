@@ -0,0 +1,194 @@
+package restapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/httpclient"
+)
+
+// FakePaymentProvider is an in-process stand-in for the payment provider,
+// served with httptest.NewServer in tests and by -fake-payments. It keeps
+// the parts of a real provider that callers must cope with: it creates
+// one payment per idempotency key, can be made to fail, and reports the
+// outcome later in a signed webhook that it retries until acknowledged.
+type FakePaymentProvider struct {
+	secret     []byte
+	webhookURL string
+	client     *httpclient.Client
+	now        func() time.Time
+
+	// AutoSettle, if set, settles each new payment this long after it is
+	// created, as if the customer had paid
+	AutoSettle time.Duration
+	// Decline, if set, picks the payments AutoSettle fails
+	Decline func(Payment) bool
+
+	mu       sync.Mutex
+	payments map[string]Payment // by idempotency key
+	failNext int
+	requests int
+	nextID   int
+	nextEvt  int
+}
+
+// NewFakePaymentProvider returns a provider that signs its webhooks with
+// secret and sends them to webhookURL
+func NewFakePaymentProvider(secret []byte, webhookURL string) *FakePaymentProvider {
+	return &FakePaymentProvider{
+		secret:     secret,
+		webhookURL: webhookURL,
+		client: httpclient.New(httpclient.Options{
+			Timeout:     5 * time.Second,
+			MaxAttempts: 5,
+			Backoff:     50 * time.Millisecond,
+		}),
+		now:      time.Now,
+		payments: make(map[string]Payment),
+	}
+}
+
+// FailNext makes the next n requests fail with 503 Service Unavailable
+func (f *FakePaymentProvider) FailNext(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failNext = n
+}
+
+// Requests returns how many requests the provider has received, failed
+// ones included
+func (f *FakePaymentProvider) Requests() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requests
+}
+
+// Payments returns the payments created, oldest first
+func (f *FakePaymentProvider) Payments() []Payment {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	payments := make([]Payment, 0, len(f.payments))
+	for _, p := range f.payments {
+		payments = append(payments, p)
+	}
+	sort.Slice(payments, func(i, j int) bool { return payments[i].ID < payments[j].ID })
+	return payments
+}
+
+// ServeHTTP handles POST /v1/payments
+func (f *FakePaymentProvider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/v1/payments" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests++
+	if f.failNext > 0 {
+		f.failNext--
+		http.Error(w, "provider unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	key := r.Header.Get("Idempotency-Key")
+	if key == "" {
+		http.Error(w, "Idempotency-Key required", http.StatusBadRequest)
+		return
+	}
+	var req PaymentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Amount.Cents() <= 0 || req.Reference == "" {
+		http.Error(w, "invalid payment", http.StatusBadRequest)
+		return
+	}
+
+	if p, ok := f.payments[key]; ok {
+		if p.Amount != req.Amount || p.Reference != req.Reference {
+			http.Error(w, "Idempotency-Key reused for a different payment", http.StatusConflict)
+			return
+		}
+		respondWithJSON(w, http.StatusOK, p)
+		return
+	}
+	f.nextID++
+	p := Payment{ID: fmt.Sprintf("pay_%d", f.nextID), Status: "pending", Amount: req.Amount, Reference: req.Reference}
+	f.payments[key] = p
+	if f.AutoSettle > 0 {
+		succeeded := f.Decline == nil || !f.Decline(p)
+		time.AfterFunc(f.AutoSettle, func() {
+			if err := f.Settle(context.Background(), p.ID, succeeded); err != nil {
+				slog.Warn("fake payment provider: webhook not delivered", "payment_id", p.ID, "error", err)
+			}
+		})
+	}
+	respondWithJSON(w, http.StatusCreated, p)
+}
+
+// Settle decides payment id and sends the webhook saying so, retrying
+// while the receiver fails. Each call is a new event; to deliver one event
+// twice, as a provider that missed the acknowledgement does, use
+// SendEvent.
+func (f *FakePaymentProvider) Settle(ctx context.Context, id string, succeeded bool) error {
+	f.mu.Lock()
+	var found *Payment
+	for key, p := range f.payments {
+		if p.ID == id {
+			p.Status = "succeeded"
+			if !succeeded {
+				p.Status = "failed"
+			}
+			f.payments[key] = p
+			found = &p
+			break
+		}
+	}
+	f.nextEvt++
+	evtID := fmt.Sprintf("evt_%d", f.nextEvt)
+	f.mu.Unlock()
+	if found == nil {
+		return fmt.Errorf("no payment %s", id)
+	}
+
+	ev := PaymentEvent{ID: evtID, Type: PaymentSucceeded, PaymentID: found.ID, Reference: found.Reference}
+	if !succeeded {
+		ev.Type = PaymentFailed
+	}
+	return f.SendEvent(ctx, ev)
+}
+
+// SendEvent signs ev and posts it to the webhook URL. The event ID goes
+// in the Idempotency-Key header, which is what lets the client retry the
+// POST: the receiver dedupes on it.
+func (f *FakePaymentProvider) SendEvent(ctx context.Context, ev PaymentEvent) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", ev.ID)
+	req.Header.Set(paymentSignatureHeader, signPaymentWebhook(f.secret, f.now(), body))
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending %s: %w", ev.ID, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("sending %s: receiver answered %s", ev.ID, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package restapi
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/httpclient"
+	"github.com/rehan/go-interview-prep/pkg/money"
+)
+
+var webhookTestSecret = []byte(strings.Repeat("w", minWebhookSecretSize))
+
+func TestVerifyPaymentWebhook(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	body := []byte(`{"id":"evt_1","type":"payment.succeeded","payment_id":"pay_1","reference":"order-1"}`)
+	valid := signPaymentWebhook(webhookTestSecret, now, body)
+	_, validSig, _ := strings.Cut(valid, ",v1=")
+	other := signPaymentWebhook([]byte(strings.Repeat("x", minWebhookSecretSize)), now, body)
+	_, otherSig, _ := strings.Cut(other, ",v1=")
+
+	tests := []struct {
+		name    string
+		header  string
+		body    []byte
+		wantErr bool
+	}{
+		{"valid", valid, body, false},
+		{"sent a minute ago", signPaymentWebhook(webhookTestSecret, now.Add(-time.Minute), body), body, false},
+		{"tampered body", valid, []byte(strings.Replace(string(body), "succeeded", "failed", 1)), true},
+		{"other secret", other, body, true},
+		{"stale", signPaymentWebhook(webhookTestSecret, now.Add(-6*time.Minute), body), body, true},
+		{"from the future", signPaymentWebhook(webhookTestSecret, now.Add(6*time.Minute), body), body, true},
+		// The timestamp is signed, so moving it breaks the signature
+		{"timestamp changed", strings.Replace(valid, "t=", "t=1", 1), body, true},
+		{"rotated secret", strings.Split(valid, ",")[0] + ",v1=" + otherSig + ",v1=" + validSig, body, false},
+		{"no signature", strings.Split(valid, ",")[0], body, true},
+		{"no timestamp", "v1=" + validSig, body, true},
+		{"not hex", strings.Split(valid, ",")[0] + ",v1=zz", body, true},
+		{"empty", "", body, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := verifyPaymentWebhook(webhookTestSecret, tc.header, tc.body, now)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("verify = %v; want error %v", err, tc.wantErr)
+			}
+			if err != nil && !errors.Is(err, errBadSignature) {
+				t.Errorf("error %v is not errBadSignature", err)
+			}
+		})
+	}
+}
+
+// testPaymentClient returns a client for provider that retries without
+// waiting long
+func testPaymentClient(t *testing.T, provider *FakePaymentProvider) *PaymentClient {
+	t.Helper()
+	srv := httptest.NewServer(provider)
+	t.Cleanup(srv.Close)
+	return NewPaymentClient(srv.URL, httpclient.New(httpclient.Options{MaxAttempts: 3, Backoff: time.Millisecond}))
+}
+
+// A POST is retried because it carries an idempotency key, and the
+// provider creates one payment however often it is asked
+func TestPaymentClient_RetriesWithIdempotencyKey(t *testing.T) {
+	provider := NewFakePaymentProvider(webhookTestSecret, "")
+	client := testPaymentClient(t, provider)
+	provider.FailNext(2)
+
+	req := PaymentRequest{Amount: money.MustParse("65.98"), Currency: "USD", Reference: "order-1"}
+	first, err := client.CreatePayment(context.Background(), req, "order-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if provider.Requests() != 3 {
+		t.Errorf("provider got %d requests; want 3, two failed and one retry", provider.Requests())
+	}
+	again, err := client.CreatePayment(context.Background(), req, "order-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.ID != first.ID || len(provider.Payments()) != 1 {
+		t.Errorf("second call got %s, with %d payments; want %s and one payment", again.ID, len(provider.Payments()), first.ID)
+	}
+
+	// The key names one payment, so it cannot be reused for another
+	req.Amount = money.MustParse("1.00")
+	if _, err := client.CreatePayment(context.Background(), req, "order-1"); err == nil {
+		t.Error("key reused for another amount: no error")
+	}
+}
+
+func TestPaymentClient_GivesUp(t *testing.T) {
+	provider := NewFakePaymentProvider(webhookTestSecret, "")
+	client := testPaymentClient(t, provider)
+	provider.FailNext(10)
+
+	req := PaymentRequest{Amount: money.MustParse("10.00"), Currency: "USD", Reference: "order-1"}
+	if _, err := client.CreatePayment(context.Background(), req, "order-1"); err == nil {
+		t.Fatal("CreatePayment succeeded against a provider that is down")
+	}
+	if provider.Requests() != 3 {
+		t.Errorf("provider got %d requests; want 3", provider.Requests())
+	}
+	if len(provider.Payments()) != 0 {
+		t.Errorf("%d payments created; want none", len(provider.Payments()))
+	}
+}
@@ -0,0 +1,131 @@
+package restapi
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/errorsx"
+	"github.com/rehan/go-interview-prep/pkg/jwt"
+)
+
+func TestRole_Can(t *testing.T) {
+	perms := []Permission{PermCreateBooks, PermUpdateBooks, PermDeleteBooks, PermManageKeys, PermPlaceOrders, PermRunJobs}
+	want := map[Role][]bool{
+		RoleReader:    {false, false, false, false, true, false},
+		RoleEditor:    {true, true, false, false, true, true},
+		RoleAdmin:     {true, true, true, true, true, true},
+		"":            {false, false, false, false, false, false},
+		"superuser":   {false, false, false, false, false, false},
+		Role("ADMIN"): {false, false, false, false, false, false}, // roles are case-sensitive
+	}
+	for role, wantCan := range want {
+		for i, perm := range perms {
+			if got := role.Can(perm); got != wantCan[i] {
+				t.Errorf("Role(%q).Can(%s) = %v; want %v", role, perm, got, wantCan[i])
+			}
+		}
+	}
+}
+
+// TestRouter_PermissionMatrix sends every route as every kind of caller:
+// anonymous callers get 401 on protected routes, authenticated callers
+// without the permission get 403
+func TestRouter_PermissionMatrix(t *testing.T) {
+	auth, now := testAuth(t)
+	tokenFor := func(role Role) string {
+		claims := jwt.NewClaims("user-"+string(role), *now, time.Hour)
+		claims.Role = string(role)
+		token, err := jwt.Sign(claims, auth.key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	book := `{"title":"T","author":"A","price":1}`
+	callers := []string{"anonymous", "reader", "editor", "admin", "unknown role"}
+	tests := []struct {
+		method, path, body string
+		want               []int // status per caller, in the order above
+	}{
+		{http.MethodGet, "/books", "", []int{200, 200, 200, 200, 200}},
+		{http.MethodGet, "/books/1", "", []int{200, 200, 200, 200, 200}},
+		{http.MethodGet, "/books/html", "", []int{200, 200, 200, 200, 200}},
+		{http.MethodGet, "/books/export", "", []int{200, 200, 200, 200, 200}},
+		{http.MethodPost, "/books/import", "", []int{401, 403, 400, 400, 403}}, // 400: not a multipart upload
+		{http.MethodPost, "/books", book, []int{401, 403, 201, 201, 403}},
+		{http.MethodPost, "/books/batch", "[" + book + "]", []int{401, 403, 200, 200, 403}},
+		{http.MethodPut, "/books/1", book, []int{401, 403, 200, 200, 403}},
+		{http.MethodDelete, "/books/1", "", []int{401, 403, 403, 204, 403}},
+		{http.MethodPatch, "/books/1", "", []int{405, 405, 405, 405, 405}},
+		{http.MethodGet, "/books/1/cover", "", []int{404, 404, 404, 404, 404}},  // 404: no cover uploaded
+		{http.MethodPost, "/books/1/cover", "", []int{401, 403, 400, 400, 403}}, // 400: not a multipart upload
+		{http.MethodGet, "/admin/keys", "", []int{401, 403, 403, 200, 403}},
+		{http.MethodPost, "/admin/keys", `{"name":"ci"}`, []int{401, 403, 403, 201, 403}},
+		{http.MethodDelete, "/admin/keys/missing", "", []int{401, 403, 403, 404, 403}},
+		{http.MethodGet, "/admin/audit", "", []int{401, 403, 403, 200, 403}},
+		{http.MethodPost, "/orders", "{}", []int{401, 400, 400, 400, 403}}, // 400: no book or quantity
+		{http.MethodGet, "/orders/1", "", []int{401, 404, 404, 404, 403}},
+		{http.MethodPost, "/webhooks/payment", "{}", []int{401, 401, 401, 401, 401}}, // unsigned
+		{http.MethodPost, "/jobs", "{}", []int{401, 403, 400, 400, 403}},             // 400: no kind
+		{http.MethodGet, "/jobs/1", "", []int{401, 403, 404, 404, 403}},
+		{http.MethodPost, "/graphql", `{"query":"{ books { id } }"}`, []int{200, 200, 200, 200, 200}},
+		{http.MethodPost, "/graphql", `{"query":"mutation { createBook(input: {title: \"T\", author: \"A\", price: 1}) { id } }"}`, []int{401, 403, 200, 200, 403}},
+	}
+
+	// No job is ever queued, so no workers are needed
+	jobs := NewJobRunner(0, 0, nil)
+	for _, tc := range tests {
+		for i, caller := range callers {
+			t.Run(tc.method+" "+tc.path+" as "+caller, func(t *testing.T) {
+				router := newRouter(NewBookStore(), auth, slog.New(slog.NewTextHandler(io.Discard, nil)), nil, nil, NewMemoryCoverStore(), nil, NewAuditLog(), nil, NewOrders(nil, webhookTestSecret), jobs)
+				req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+				switch caller {
+				case "anonymous":
+				case "unknown role":
+					req.Header.Set("Authorization", "Bearer "+tokenFor("superuser"))
+				default:
+					req.Header.Set("Authorization", "Bearer "+tokenFor(Role(caller)))
+				}
+				rr := httptest.NewRecorder()
+				router.ServeHTTP(rr, req)
+
+				if rr.Code != tc.want[i] {
+					t.Fatalf("status = %d; want %d (body: %s)", rr.Code, tc.want[i], rr.Body.String())
+				}
+				if rr.Code == http.StatusForbidden {
+					var p Problem
+					if err := json.NewDecoder(rr.Body).Decode(&p); err != nil {
+						t.Fatal(err)
+					}
+					if p.Code != errorsx.CodePermissionDenied {
+						t.Errorf("problem code = %q; want permission_denied", p.Code)
+					}
+				}
+			})
+		}
+	}
+}
+
+// Each demo account logs in with the role its name says
+func TestDemoAccounts(t *testing.T) {
+	auth := newTokenAuth(newUserStore(demoAccounts), authTestSecret, time.Hour)
+	router := newRouter(NewBookStore(), auth, slog.New(slog.NewTextHandler(io.Discard, nil)), nil, nil, nil, nil, nil, nil, nil, nil)
+	for name, account := range demoAccounts {
+		rr := login(t, router, `{"username":"`+name+`","password":"`+account.Password+`"}`)
+		var resp LoginResponse
+		if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		claims, err := jwt.Verify(resp.Token, auth.key, time.Now())
+		if err != nil || claims.Role != name {
+			t.Errorf("%s logged in with role %q, %v; want %q", name, claims.Role, err, name)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package restapi
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/money"
+)
+
+// testRepository checks the behaviour the handlers rely on from every
+// BookRepository. newRepo returns a new store for each subtest; any books
+// it starts with are deleted first. A new backend, or a decorator of one,
+// gets the whole suite by calling it from a test of its own, as
+// TestBookStore_Contract does.
+func testRepository(t *testing.T, newRepo func(t *testing.T) BookRepository) {
+	t.Helper()
+	empty := func(t *testing.T) BookRepository {
+		t.Helper()
+		repo := newRepo(t)
+		for _, b := range repo.GetBooks() {
+			if !repo.DeleteBook(b.ID) {
+				t.Fatalf("DeleteBook(%d) of a listed book = false", b.ID)
+			}
+		}
+		if books := repo.GetBooks(); len(books) != 0 {
+			t.Fatalf("GetBooks() after deleting them all = %v; want none", books)
+		}
+		return repo
+	}
+	book := func(title string) Book {
+		return Book{Title: title, Author: "A", Price: money.FromCents(999)}
+	}
+
+	t.Run("AddBook", func(t *testing.T) {
+		repo := empty(t)
+		before := time.Now()
+		in := book("Learning Go")
+		in.ID, in.CreatedAt = 1000, time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+		id := repo.AddBook(in)
+
+		got, ok := repo.GetBook(id)
+		if !ok {
+			t.Fatalf("GetBook(%d) after AddBook = _, false", id)
+		}
+		if got.ID != id || got.Title != in.Title || got.Author != in.Author || got.Price != in.Price {
+			t.Errorf("GetBook(%d) = %+v; want %+v with ID %d", id, got, in, id)
+		}
+		if got.CreatedAt.Before(before.Add(-time.Second)) {
+			t.Errorf("CreatedAt = %v; want the time it was added, not the one given", got.CreatedAt)
+		}
+		if next := repo.AddBook(book("Next")); next <= id {
+			t.Errorf("IDs = %d then %d; want them increasing", id, next)
+		}
+	})
+
+	t.Run("AddBooks", func(t *testing.T) {
+		repo := empty(t)
+		in := []Book{book("One"), book("Two"), book("Three")}
+		ids := repo.AddBooks(in)
+		if len(ids) != len(in) {
+			t.Fatalf("AddBooks(%d books) = %v; want %d IDs", len(in), ids, len(in))
+		}
+		for i, id := range ids {
+			if got, ok := repo.GetBook(id); !ok || got.Title != in[i].Title {
+				t.Errorf("GetBook(%d) = %+v, %v; want %q", id, got, ok, in[i].Title)
+			}
+			if i > 0 && id <= ids[i-1] {
+				t.Errorf("AddBooks IDs = %v; want them increasing", ids)
+			}
+		}
+		if got := titles(repo.GetBooks()); !slices.Equal(got, []string{"One", "Two", "Three"}) {
+			t.Errorf("GetBooks() = %q; want the three added", got)
+		}
+		if ids := repo.AddBooks(nil); len(ids) != 0 {
+			t.Errorf("AddBooks(nil) = %v; want no IDs", ids)
+		}
+	})
+
+	t.Run("UpdateBook", func(t *testing.T) {
+		repo := empty(t)
+		id := repo.AddBook(book("Old"))
+		added, _ := repo.GetBook(id)
+
+		in := book("New")
+		in.ID, in.CreatedAt = id+100, added.CreatedAt.Add(time.Hour)
+		if !repo.UpdateBook(id, in) {
+			t.Fatalf("UpdateBook(%d) = false; want true", id)
+		}
+		got, _ := repo.GetBook(id)
+		if got.Title != "New" || got.ID != id || !got.CreatedAt.Equal(added.CreatedAt) {
+			t.Errorf("after UpdateBook = %+v; want the new title with ID %d and CreatedAt %v kept", got, id, added.CreatedAt)
+		}
+		if repo.UpdateBook(id+1, in) {
+			t.Errorf("UpdateBook(%d) of a missing book = true; want false", id+1)
+		}
+		if _, ok := repo.GetBook(id + 1); ok {
+			t.Errorf("UpdateBook of a missing book added it")
+		}
+	})
+
+	t.Run("DeleteBook", func(t *testing.T) {
+		repo := empty(t)
+		id := repo.AddBook(book("Gone"))
+		if !repo.DeleteBook(id) {
+			t.Fatalf("DeleteBook(%d) = false; want true", id)
+		}
+		if repo.DeleteBook(id) {
+			t.Errorf("DeleteBook(%d) twice = true; want false", id)
+		}
+		if _, ok := repo.GetBook(id); ok {
+			t.Errorf("GetBook(%d) after DeleteBook = _, true", id)
+		}
+		// IDs are not reused, so a link to a deleted book never finds
+		// another
+		if next := repo.AddBook(book("Next")); next == id {
+			t.Errorf("AddBook after deleting %d reused its ID", id)
+		}
+	})
+
+	t.Run("GetBooks returns copies", func(t *testing.T) {
+		repo := empty(t)
+		id := repo.AddBook(book("Kept"))
+		books := repo.GetBooks()
+		books[0].Title = "Changed"
+		if got, _ := repo.GetBook(id); got.Title != "Kept" {
+			t.Errorf("changing a book GetBooks returned changed the store: %q", got.Title)
+		}
+	})
+
+	t.Run("Tx", func(t *testing.T) {
+		for _, commit := range []bool{true, false} {
+			repo := empty(t)
+			keep, gone := repo.AddBook(book("Keep")), repo.AddBook(book("Gone"))
+			tx, err := repo.Begin()
+			if err != nil {
+				t.Fatalf("Begin() = %v", err)
+			}
+			added := tx.AddBook(book("Added"))
+			if !tx.UpdateBook(keep, book("Kept")) || !tx.DeleteBook(gone) {
+				t.Fatal("changes in the transaction failed")
+			}
+			if got := titles(tx.GetBooks()); !slices.Equal(got, []string{"Kept", "Added"}) {
+				t.Errorf("in the transaction: %q; want [Kept Added]", got)
+			}
+			if got := titles(repo.GetBooks()); !slices.Equal(got, []string{"Keep", "Gone"}) {
+				t.Errorf("outside the transaction: %q; want [Keep Gone]", got)
+			}
+			if _, err := tx.Begin(); err == nil {
+				t.Error("Begin() on a transaction = nil; want an error")
+			}
+
+			want := []string{"Keep", "Gone"}
+			if commit {
+				err, want = tx.Commit(), []string{"Kept", "Added"}
+			} else {
+				err = tx.Rollback()
+			}
+			if err != nil {
+				t.Fatalf("ending the transaction (commit %v) = %v", commit, err)
+			}
+			if got := titles(repo.GetBooks()); !slices.Equal(got, want) {
+				t.Errorf("after commit %v: %q; want %q", commit, got, want)
+			}
+			if _, ok := repo.GetBook(added); ok != commit {
+				t.Errorf("GetBook(%d) after commit %v = _, %v", added, commit, ok)
+			}
+			if err := tx.Rollback(); !errors.Is(err, errTxDone) {
+				t.Errorf("Rollback() after the transaction ended = %v; want %v", err, errTxDone)
+			}
+		}
+	})
+
+	t.Run("Tx conflict", func(t *testing.T) {
+		repo := empty(t)
+		id := repo.AddBook(book("Original"))
+		tx, err := repo.Begin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer tx.Rollback()
+		tx.UpdateBook(id, book("Theirs"))
+		repo.UpdateBook(id, book("Ours"))
+		if err := tx.Commit(); !errors.Is(err, errTxConflict) {
+			t.Fatalf("Commit() after a conflicting change = %v; want %v", err, errTxConflict)
+		}
+		if got, _ := repo.GetBook(id); got.Title != "Ours" {
+			t.Errorf("title after the conflict = %q; want Ours", got.Title)
+		}
+	})
+
+	t.Run("concurrent adds", func(t *testing.T) {
+		repo := empty(t)
+		const goroutines, each = 8, 25
+		ids := make([][]int, goroutines)
+		var wg sync.WaitGroup
+		for g := range goroutines {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for range each {
+					ids[g] = append(ids[g], repo.AddBook(book("Concurrent")))
+				}
+				ids[g] = append(ids[g], repo.AddBooks([]Book{book("Batch"), book("Batch")})...)
+			}()
+		}
+		wg.Wait()
+
+		seen := make(map[int]bool)
+		for _, g := range ids {
+			for _, id := range g {
+				if seen[id] {
+					t.Fatalf("ID %d given out twice", id)
+				}
+				seen[id] = true
+			}
+		}
+		if got, want := len(repo.GetBooks()), goroutines*(each+2); got != want {
+			t.Errorf("len(GetBooks()) = %d; want %d", got, want)
+		}
+	})
+}
+
+func TestBookStore_Contract(t *testing.T) {
+	testRepository(t, func(*testing.T) BookRepository { return NewBookStore() })
+}
+
+// The decorators must keep the contract of the store they wrap
+func TestDecorators_Contract(t *testing.T) {
+	tests := []struct {
+		name string
+		wrap func(BookRepository) BookRepository
+	}{
+		{"coverDeletingRepository", func(store BookRepository) BookRepository {
+			return coverDeletingRepository{store, NewMemoryCoverStore()}
+		}},
+		{"outboxRepository", func(store BookRepository) BookRepository {
+			return outboxRepository{BookRepository: store, outbox: NewOutbox(), actor: "test"}
+		}},
+		{"chaosRepository", func(store BookRepository) BookRepository {
+			return newChaosRepository(store, ChaosConfig{})
+		}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			testRepository(t, func(*testing.T) BookRepository { return tc.wrap(NewBookStore()) })
+		})
+	}
+}
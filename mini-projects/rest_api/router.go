@@ -0,0 +1,39 @@
+package restapi
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/rehan/go-interview-prep/pkg/errorsx"
+)
+
+// methodHandlers serves each HTTP method with its own handler. Any other
+// method gets 405 with an Allow header listing the ones that would work,
+// as RFC 9110 requires.
+//
+// ServeMux can match "GET /books/{id}" itself, but its 405 is plain text
+// written before any middleware runs, so it would carry no request ID and
+// never be logged. Registering one methodHandlers per path keeps every
+// response going through the same middleware and problem+json errors.
+type methodHandlers map[string]http.HandlerFunc
+
+func (m methodHandlers) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h, ok := m[r.Method]
+	if !ok {
+		w.Header().Set("Allow", m.allow())
+		respondWithError(w, errorsx.New(errorsx.CodeMethodNotAllowed, "Method not allowed"))
+		return
+	}
+	h(w, r)
+}
+
+// allow lists the methods in a stable order for the Allow header
+func (m methodHandlers) allow() string {
+	methods := make([]string, 0, len(m))
+	for method := range m {
+		methods = append(methods, method)
+	}
+	slices.Sort(methods)
+	return strings.Join(methods, ", ")
+}
@@ -0,0 +1,100 @@
+package restapi
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRouter_Patterns(t *testing.T) {
+	auth, _ := testAuth(t)
+	router := newRouter(NewBookStore(), auth, slog.New(slog.NewTextHandler(io.Discard, nil)), nil, nil, NewMemoryCoverStore(), newEventBus(), NewAuditLog(), nil, nil, nil)
+	tests := []struct {
+		path, want string // want is "" for no match
+	}{
+		{"/books", "/books"},
+		{"/books/1", "/books/{id}"},
+		{"/books/abc", "/books/{id}"}, // matched; the handler rejects the ID
+		{"/books/html", "/books/html"},
+		{"/books/", ""},
+		{"/books/1/reviews", ""},
+		{"/books/1/cover", "/books/{id}/cover"},
+		{"/books/events", "/books/events"},
+		{"/ws", "/ws"},
+		{"/admin/keys", "/admin/keys"},
+		{"/admin/keys/3f2a", "/admin/keys/{id}"},
+		{"/admin/audit", "/admin/audit"},
+		{"/auth/login", "/auth/login"},
+		{"/metrics", "/metrics"},
+		{"/", ""},
+	}
+	for _, tc := range tests {
+		_, pattern := router.Handler(httptest.NewRequest(http.MethodGet, tc.path, nil))
+		if pattern != tc.want {
+			t.Errorf("%s matched %q; want %q", tc.path, pattern, tc.want)
+		}
+	}
+}
+
+func TestRouter_PathID(t *testing.T) {
+	auth, _ := testAuth(t)
+	router := newRouter(NewBookStore(), auth, slog.New(slog.NewTextHandler(io.Discard, nil)), nil, nil, nil, nil, nil, nil, nil, nil)
+	tests := []struct {
+		path       string
+		wantStatus int
+	}{
+		{"/books/2", http.StatusOK},
+		{"/books/999", http.StatusNotFound},
+		{"/books/abc", http.StatusBadRequest},
+		{"/books/0", http.StatusBadRequest},
+		{"/books/-1", http.StatusBadRequest},
+		{"/books/1/reviews", http.StatusNotFound},
+	}
+	for _, tc := range tests {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tc.path, nil))
+		if rr.Code != tc.wantStatus {
+			t.Errorf("GET %s = %d; want %d", tc.path, rr.Code, tc.wantStatus)
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/books/2", nil))
+	var book Book
+	if err := json.NewDecoder(rr.Body).Decode(&book); err != nil || book.ID != 2 {
+		t.Errorf("GET /books/2 = %+v, %v; want book 2", book, err)
+	}
+}
+
+func TestRouter_MethodNotAllowed(t *testing.T) {
+	auth, _ := testAuth(t)
+	router := newRouter(NewBookStore(), auth, slog.New(slog.NewTextHandler(io.Discard, nil)), nil, nil, nil, nil, nil, nil, nil, nil)
+	tests := []struct {
+		method, path, wantAllow string
+	}{
+		{http.MethodPatch, "/books/1", "DELETE, GET, PUT"},
+		{http.MethodDelete, "/books", "GET, POST"},
+		{http.MethodPost, "/books/html", "GET"},
+		{http.MethodGet, "/auth/login", "POST"},
+		{http.MethodPut, "/admin/keys/3f2a", "DELETE"},
+		{http.MethodPost, "/metrics", "GET"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest(tc.method, tc.path, nil))
+			if rr.Code != http.StatusMethodNotAllowed {
+				t.Fatalf("status = %d; want 405", rr.Code)
+			}
+			if got := rr.Header().Get("Allow"); got != tc.wantAllow {
+				t.Errorf("Allow = %q; want %q", got, tc.wantAllow)
+			}
+			if ct := rr.Header().Get("Content-Type"); ct != problemContentType {
+				t.Errorf("Content-Type = %q; want %s", ct, problemContentType)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package restapi
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/rehan/go-interview-prep/pkg/testutil/httptestx"
+)
+
+// scenarioClient returns a client of a router with a fresh store, logged
+// in as alice (admin), carol (editor) and bob (reader)
+func scenarioClient(t *testing.T) *httptestx.Client {
+	t.Helper()
+	auth, _ := testAuth(t)
+	users := map[string]Account{
+		"alice": {Password: "wonderland", Role: RoleAdmin},
+		"carol": {Password: "christmas", Role: RoleEditor},
+		"bob":   {Password: "builder", Role: RoleReader},
+	}
+	auth.users = newUserStore(users)
+	router := newRouter(NewBookStore(), auth, slog.New(slog.NewTextHandler(io.Discard, nil)), nil, nil, nil, nil, nil, nil, nil, nil)
+
+	c := httptestx.NewClient(t, router)
+	for user, account := range users {
+		c.LoginBearer(user, "/auth/login", map[string]string{"username": user, "password": account.Password}, "token")
+	}
+	return c
+}
+
+func TestScenarios_Books(t *testing.T) {
+	scenarios := []httptestx.Scenario{
+		{
+			Name: "editor adds a book and an admin deletes it",
+			Steps: []httptestx.Step{
+				{
+					Name: "create", As: "carol", Method: http.MethodPost, Path: "/books",
+					Body:   map[string]any{"title": "Learning Go", "author": "Jon Bodner", "price": 39.99},
+					Status: http.StatusCreated,
+					JSON:   `{"id": "<any>", "title": "Learning Go", "author": "Jon Bodner", "price": 39.99, "created_at": "<any>"}`,
+					Save:   map[string]string{"id": "id"},
+				},
+				{
+					Name: "read", Method: http.MethodGet, Path: "/books/{{id}}",
+					Status: http.StatusOK,
+					JSON:   `{"id": {{id}}, "title": "Learning Go", "author": "Jon Bodner", "price": 39.99, "created_at": "<any>"}`,
+				},
+				{
+					Name: "update", As: "carol", Method: http.MethodPut, Path: "/books/{{id}}",
+					Body:   `{"title": "Learning Go, 2nd Edition", "author": "Jon Bodner", "price": 49.99}`,
+					Status: http.StatusOK,
+					JSON:   `{"id": {{id}}, "title": "Learning Go, 2nd Edition", "author": "Jon Bodner", "price": 49.99, "created_at": "<any>"}`,
+				},
+				{
+					Name: "editor cannot delete", As: "carol", Method: http.MethodDelete, Path: "/books/{{id}}",
+					Status: http.StatusForbidden,
+				},
+				{
+					Name: "admin deletes", As: "alice", Method: http.MethodDelete, Path: "/books/{{id}}",
+					Status: http.StatusNoContent,
+				},
+				{
+					Name: "gone", Method: http.MethodGet, Path: "/books/{{id}}",
+					Status: http.StatusNotFound,
+				},
+			},
+		},
+		{
+			Name: "readers and anonymous callers cannot write",
+			Steps: []httptestx.Step{
+				{
+					Name: "reader creates", As: "bob", Method: http.MethodPost, Path: "/books",
+					Body:   map[string]any{"title": "T", "author": "A", "price": 1},
+					Status: http.StatusForbidden,
+				},
+				{
+					Name: "anonymous creates", Method: http.MethodPost, Path: "/books",
+					Body:   map[string]any{"title": "T", "author": "A", "price": 1},
+					Status: http.StatusUnauthorized,
+				},
+				{
+					Name: "reader updates", As: "bob", Method: http.MethodPut, Path: "/books/1",
+					Body:   map[string]any{"title": "T", "author": "A", "price": 1},
+					Status: http.StatusForbidden,
+				},
+				{
+					Name: "unchanged", Method: http.MethodGet, Path: "/books/1",
+					Status: http.StatusOK,
+					JSON:   `{"id": 1, "title": "The Go Programming Language", "author": "<any>", "price": "<any>", "created_at": "<any>"}`,
+				},
+			},
+		},
+		{
+			Name: "invalid books are problems",
+			Steps: []httptestx.Step{
+				{
+					Name: "missing author", As: "carol", Method: http.MethodPost, Path: "/books",
+					Body:    map[string]any{"title": "Learning Go", "price": 39.99},
+					Status:  http.StatusBadRequest,
+					Headers: map[string]string{"Content-Type": "application/problem+json"},
+					JSON: `{
+						"type": "about:blank", "title": "Bad Request", "status": 400, "code": "invalid_argument",
+						"detail": "Invalid book data: author is required",
+						"errors": [{"field": "author", "rule": "required", "message": "author is required"}],
+						"request_id": "<any>"
+					}`,
+				},
+			},
+		},
+	}
+	for _, s := range scenarios {
+		httptestx.RunScenario(t, scenarioClient(t), s)
+	}
+}
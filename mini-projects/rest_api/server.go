@@ -0,0 +1,87 @@
+package restapi
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/profiling"
+)
+
+// Server timeouts. Without them a client that sends headers a byte at a
+// time, or never reads its response, holds a connection and a goroutine
+// for as long as it likes.
+const (
+	readHeaderTimeout = 5 * time.Second
+	readTimeout       = 15 * time.Second
+	writeTimeout      = 30 * time.Second // covers the handler, so it bounds the slowest request
+	idleTimeout       = 2 * time.Minute  // keep-alive connections between requests
+)
+
+// newServer returns a server for handler on addr with timeouts set and
+// its own errors, such as TLS handshake failures, sent to logger
+func newServer(addr string, handler http.Handler, logger *slog.Logger) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       readTimeout,
+		WriteTimeout:      writeTimeout,
+		IdleTimeout:       idleTimeout,
+		ErrorLog:          slog.NewLogLogger(logger.Handler(), slog.LevelError),
+	}
+}
+
+// newPprofServer returns a server for the profiling endpoints on addr. It
+// has no write timeout: a CPU profile or trace writes its response only
+// after the ?seconds it was asked for, which may be longer than any
+// API request is allowed.
+func newPprofServer(addr string, logger *slog.Logger) *http.Server {
+	srv := newServer(addr, profiling.Handler(), logger)
+	srv.WriteTimeout = 0
+	return srv
+}
+
+// listenAndServe listens on srv.Addr and serves until ctx is done, then
+// shuts down as serve does
+func listenAndServe(ctx context.Context, srv *http.Server, shutdownTimeout time.Duration) error {
+	ln, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		return err
+	}
+	return serve(ctx, srv, ln, shutdownTimeout)
+}
+
+// serve serves on ln, over TLS if srv.TLSConfig is set, until ctx is
+// done. It then stops accepting connections and waits up to
+// shutdownTimeout for in-flight requests to finish; any still running
+// after that are cut off and an error is returned. A server that fails
+// before ctx is done returns its error.
+func serve(ctx context.Context, srv *http.Server, ln net.Listener, shutdownTimeout time.Duration) error {
+	if srv.TLSConfig != nil {
+		ln = tls.NewListener(ln, srv.TLSConfig)
+	}
+	errc := make(chan error, 1)
+	go func() { errc <- srv.Serve(ln) }()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	// ctx is already done, so the deadline needs a fresh context
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		srv.Close()
+		return fmt.Errorf("shutdown: %w", err)
+	}
+	// Shutdown made Serve return http.ErrServerClosed, which is expected
+	<-errc
+	return nil
+}
@@ -0,0 +1,137 @@
+package restapi
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// startServer serves handler on a loopback port until the returned cancel
+// is called; serve's result arrives on the returned channel
+func startServer(t *testing.T, handler http.HandlerFunc, shutdownTimeout time.Duration) (url string, cancel context.CancelFunc, done <-chan error) {
+	return startServerTLS(t, handler, shutdownTimeout, nil)
+}
+
+// startServerTLS is startServer serving over TLS with tlsCfg, unless it
+// is nil
+func startServerTLS(t *testing.T, handler http.HandlerFunc, shutdownTimeout time.Duration, tlsCfg *tls.Config) (url string, cancel context.CancelFunc, done <-chan error) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	srv := newServer(ln.Addr().String(), handler, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	srv.TLSConfig = tlsCfg
+	errc := make(chan error, 1)
+	go func() { errc <- serve(ctx, srv, ln, shutdownTimeout) }()
+	scheme := "http"
+	if tlsCfg != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + ln.Addr().String(), cancel, errc
+}
+
+func TestServe_DrainsInFlightRequest(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	url, cancel, done := startServer(t, func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		io.WriteString(w, "finished")
+	}, 5*time.Second)
+
+	type result struct {
+		body string
+		err  error
+	}
+	resc := make(chan result, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err != nil {
+			resc <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		resc <- result{string(body), err}
+	}()
+
+	<-started
+	cancel()
+
+	// Shutdown waits for the request, and new connections are refused
+	select {
+	case err := <-done:
+		t.Fatalf("serve returned %v with a request in flight", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if _, err := net.DialTimeout("tcp", url[len("http://"):], time.Second); err == nil {
+		t.Error("server accepted a connection after shutdown began")
+	}
+
+	close(release)
+	if res := <-resc; res.err != nil || res.body != "finished" {
+		t.Errorf("in-flight request = %q, %v; want it to complete", res.body, res.err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("serve = %v; want nil after a clean shutdown", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve did not return after the last request finished")
+	}
+}
+
+func TestServe_ShutdownTimeout(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	url, cancel, done := startServer(t, func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	}, 50*time.Millisecond)
+
+	go http.Get(url)
+	<-started
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("serve = %v; want the shutdown deadline exceeded", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve waited past the shutdown timeout")
+	}
+}
+
+func TestNewServer_SetsTimeouts(t *testing.T) {
+	srv := newServer(":0", http.NotFoundHandler(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	for name, d := range map[string]time.Duration{
+		"ReadHeaderTimeout": srv.ReadHeaderTimeout,
+		"ReadTimeout":       srv.ReadTimeout,
+		"WriteTimeout":      srv.WriteTimeout,
+		"IdleTimeout":       srv.IdleTimeout,
+	} {
+		if d <= 0 {
+			t.Errorf("%s = %v; want a limit", name, d)
+		}
+	}
+}
+
+func TestNewPprofServer_NoWriteTimeout(t *testing.T) {
+	srv := newPprofServer(":0", slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if srv.WriteTimeout != 0 {
+		t.Errorf("WriteTimeout = %v; want none, so a long profile is not cut off", srv.WriteTimeout)
+	}
+	if srv.ReadHeaderTimeout <= 0 {
+		t.Errorf("ReadHeaderTimeout = %v; want the API's limit kept", srv.ReadHeaderTimeout)
+	}
+}
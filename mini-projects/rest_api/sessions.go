@@ -0,0 +1,333 @@
+package restapi
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/errorsx"
+	"github.com/rehan/go-interview-prep/pkg/validator"
+)
+
+// sessionCookie names the cookie carrying a browser's session ID
+const sessionCookie = "session_id"
+
+// csrfHeader carries the CSRF token of the session on state-changing
+// requests
+const csrfHeader = "X-CSRF-Token"
+
+// Session is a browser login. The cookie holds a random ID; only its
+// SHA-256 hash is stored, like an API key's, so a leaked store does not
+// leak usable sessions.
+type Session struct {
+	ID        string    `json:"id"` // hex SHA-256 of the cookie value
+	Username  string    `json:"username"`
+	Role      Role      `json:"role"`
+	CSRFToken string    `json:"csrf_token"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+
+	// Flash is a message for the next page shown, such as "Saved", set
+	// before a redirect; showing it clears it
+	Flash string `json:"flash,omitempty"`
+}
+
+// SessionStore keeps sessions by ID. Like BookRepository the build
+// selects the implementation: memory by default, a JSON file with
+// -tags filestore.
+type SessionStore interface {
+	Session(id string) (Session, bool)
+	// PutSession inserts s, or replaces the session with the same ID
+	PutSession(s Session)
+	DeleteSession(id string)
+	// UpdateSession calls update on the session with id and stores the
+	// result, all under the store's lock, so a session deleted meanwhile is
+	// not brought back. It reports whether there was such a session.
+	UpdateSession(id string, update func(*Session)) bool
+	// DeleteExpiredSessions removes the sessions expired at now and
+	// returns how many there were
+	DeleteExpiredSessions(now time.Time) int
+}
+
+// MemorySessionStore keeps sessions in a map
+type MemorySessionStore struct {
+	mu       sync.RWMutex
+	sessions map[string]Session
+}
+
+// NewMemorySessionStore returns an empty store
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{sessions: make(map[string]Session)}
+}
+
+// Session returns the session with id
+func (m *MemorySessionStore) Session(id string) (Session, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	s, ok := m.sessions[id]
+	return s, ok
+}
+
+// PutSession stores s under its ID
+func (m *MemorySessionStore) PutSession(s Session) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions[s.ID] = s
+}
+
+// DeleteSession removes the session with id, if there is one
+func (m *MemorySessionStore) DeleteSession(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, id)
+}
+
+// UpdateSession applies update to the session with id, if there is one
+func (m *MemorySessionStore) UpdateSession(id string, update func(*Session)) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.sessions[id]
+	if !ok {
+		return false
+	}
+	update(&s)
+	m.sessions[id] = s
+	return true
+}
+
+// DeleteExpiredSessions removes every session expired at now
+func (m *MemorySessionStore) DeleteExpiredSessions(now time.Time) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := 0
+	for id, s := range m.sessions {
+		if !now.Before(s.ExpiresAt) {
+			delete(m.sessions, id)
+			n++
+		}
+	}
+	return n
+}
+
+// listSessions returns every session, ordered by ID
+func (m *MemorySessionStore) listSessions() []Session {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	sessions := make([]Session, 0, len(m.sessions))
+	for _, s := range m.sessions {
+		sessions = append(sessions, s)
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].ID < sessions[j].ID })
+	return sessions
+}
+
+// sessionManager logs browsers in with a session cookie, as tokenAuth
+// logs programs in with a bearer token
+type sessionManager struct {
+	store SessionStore
+	users *userStore
+	ttl   time.Duration
+	now   func() time.Time // time.Now outside tests
+
+	// secure sets the cookie's Secure flag, so browsers send it over
+	// HTTPS only
+	secure bool
+}
+
+// newSessionManager returns a manager whose sessions last ttl
+func newSessionManager(store SessionStore, users *userStore, ttl time.Duration, secure bool) *sessionManager {
+	return &sessionManager{store: store, users: users, ttl: ttl, now: time.Now, secure: secure}
+}
+
+// randomToken returns 32 random bytes, base64url encoded
+func randomToken() string {
+	var b [32]byte
+	rand.Read(b[:])
+	return base64.RawURLEncoding.EncodeToString(b[:])
+}
+
+// cookie returns the session cookie holding value. HttpOnly keeps it from
+// scripts, so an XSS bug cannot steal it; SameSite=Lax keeps browsers
+// from sending it on cross-site POSTs, and the CSRF token covers browsers
+// that ignore SameSite.
+func (sm *sessionManager) cookie(value string, expires time.Time) *http.Cookie {
+	return &http.Cookie{
+		Name:     sessionCookie,
+		Value:    value,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   sm.secure,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// create starts a session for username and sets its cookie on w. Each
+// login gets a new ID, so an ID planted in a browser before login
+// (session fixation) is never promoted to a logged-in session.
+func (sm *sessionManager) create(w http.ResponseWriter, username string, role Role) Session {
+	now := sm.now()
+	// Logins are rare enough to sweep the store on, so it does not grow
+	// with sessions nobody logged out of
+	sm.store.DeleteExpiredSessions(now)
+
+	id := randomToken()
+	s := Session{
+		ID:        hashKey(id),
+		Username:  username,
+		Role:      role,
+		CSRFToken: randomToken(),
+		CreatedAt: now.UTC(),
+		ExpiresAt: now.Add(sm.ttl).UTC(),
+	}
+	sm.store.PutSession(s)
+	http.SetCookie(w, sm.cookie(id, s.ExpiresAt))
+	return s
+}
+
+// lookup returns the live session named by r's cookie
+func (sm *sessionManager) lookup(r *http.Request) (Session, bool) {
+	c, err := r.Cookie(sessionCookie)
+	if err != nil || c.Value == "" {
+		return Session{}, false
+	}
+	s, ok := sm.store.Session(hashKey(c.Value))
+	if !ok || !sm.now().Before(s.ExpiresAt) {
+		return Session{}, false
+	}
+	return s, true
+}
+
+// end deletes s and tells the browser to drop its cookie
+func (sm *sessionManager) end(w http.ResponseWriter, s Session) {
+	sm.store.DeleteSession(s.ID)
+	c := sm.cookie("", time.Unix(0, 0))
+	c.MaxAge = -1
+	http.SetCookie(w, c)
+}
+
+// setFlash stores msg in s for the next page to show
+func (sm *sessionManager) setFlash(s Session, msg string) {
+	sm.store.UpdateSession(s.ID, func(s *Session) { s.Flash = msg })
+}
+
+// takeFlash returns the flash message of s and clears it, so it is shown
+// once
+func (sm *sessionManager) takeFlash(s Session) string {
+	// Most pages have no message; they need not write to the store
+	if s.Flash == "" {
+		return ""
+	}
+	var msg string
+	sm.store.UpdateSession(s.ID, func(s *Session) { msg, s.Flash = s.Flash, "" })
+	return msg
+}
+
+// SessionResponse describes the caller's session. It carries the CSRF
+// token because the cookie is HttpOnly: this is how a page learns what to
+// send in X-CSRF-Token.
+type SessionResponse struct {
+	Username  string    `json:"username"`
+	Role      Role      `json:"role"`
+	CSRFToken string    `json:"csrf_token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func respondWithSession(w http.ResponseWriter, status int, s Session) {
+	// The body holds the CSRF token, so no cache may keep it
+	w.Header().Set("Cache-Control", "no-store")
+	respondWithJSON(w, status, SessionResponse{Username: s.Username, Role: s.Role, CSRFToken: s.CSRFToken, ExpiresAt: s.ExpiresAt})
+}
+
+// handleCreateSession handles POST /auth/session, exchanging a username
+// and password for a session cookie and a CSRF token
+func handleCreateSession(w http.ResponseWriter, r *http.Request, sm *sessionManager) {
+	var req LoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, errorsx.Wrap(err, errorsx.CodeInvalidArgument, "Invalid request body"))
+		return
+	}
+	if err := validator.Struct(req); err != nil {
+		respondWithError(w, errorsx.Wrap(err, errorsx.CodeInvalidArgument, "Invalid login request"))
+		return
+	}
+	role, ok := sm.users.authenticate(req.Username, req.Password)
+	if !ok {
+		respondWithError(w, errorsx.New(errorsx.CodeUnauthenticated, "Invalid username or password"))
+		return
+	}
+	respondWithSession(w, http.StatusCreated, sm.create(w, req.Username, role))
+}
+
+// handleGetSession handles GET /auth/session, so a page can fetch the
+// CSRF token of the session it already has
+func handleGetSession(w http.ResponseWriter, r *http.Request) {
+	s, _ := SessionFromContext(r.Context())
+	respondWithSession(w, http.StatusOK, s)
+}
+
+// handleDeleteSession handles DELETE /auth/session, logging out
+func handleDeleteSession(w http.ResponseWriter, r *http.Request, sm *sessionManager) {
+	s, _ := SessionFromContext(r.Context())
+	sm.end(w, s)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// sessionKey is the context key for the session of a request
+type sessionKey struct{}
+
+// SessionFromContext returns the session sessionMiddleware stored in ctx
+func SessionFromContext(ctx context.Context) (Session, bool) {
+	s, ok := ctx.Value(sessionKey{}).(Session)
+	return s, ok
+}
+
+// sessionMiddleware rejects requests without a live session cookie and
+// passes the session to next in the request context
+func sessionMiddleware(sm *sessionManager) Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			s, ok := sm.lookup(r)
+			if !ok {
+				unauthorized(w, "Missing or expired session")
+				return
+			}
+			next(w, r.WithContext(context.WithValue(r.Context(), sessionKey{}, s)))
+		}
+	}
+}
+
+// safeMethod reports whether method only reads, per RFC 9110, and so
+// needs no CSRF token
+func safeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// csrfMiddleware rejects state-changing requests whose X-CSRF-Token header
+// is not their session's token. Browsers attach the cookie to requests
+// any site makes, but another site cannot read the token to send with
+// them. It goes inside sessionMiddleware; bearer tokens and API keys need
+// no such check, as browsers never attach them on their own.
+func csrfMiddleware() Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			s, _ := SessionFromContext(r.Context())
+			token := r.Header.Get(csrfHeader)
+			if !safeMethod(r.Method) && (token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.CSRFToken)) != 1) {
+				respondWithError(w, errorsx.New(errorsx.CodePermissionDenied, "Missing or invalid CSRF token"))
+				return
+			}
+			next(w, r)
+		}
+	}
+}
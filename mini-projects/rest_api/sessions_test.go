@@ -0,0 +1,224 @@
+package restapi
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testSessions returns a router with session logins for an admin and a
+// reader, response caching on, and a clock the test moves by assigning to
+// *now
+func testSessions(t *testing.T) (http.Handler, *sessionManager, *time.Time) {
+	t.Helper()
+	auth, now := testAuth(t)
+	users := newUserStore(map[string]Account{
+		"alice": {Password: "wonderland", Role: RoleAdmin},
+		"bob":   {Password: "builder", Role: RoleReader},
+	})
+	auth.sessions = newSessionManager(NewMemorySessionStore(), users, time.Hour, true)
+	auth.sessions.now = auth.now
+	router := newRouter(NewBookStore(), auth, slog.New(slog.NewTextHandler(io.Discard, nil)), nil, newResponseCache(withCacheTTL(time.Minute)), nil, nil, nil, nil, nil, nil)
+	return router, auth.sessions, now
+}
+
+// sessionLogin logs username in and returns the session cookie and the
+// response
+func sessionLogin(t *testing.T, handler http.Handler, username, password string) (*http.Cookie, SessionResponse) {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	body := `{"username":"` + username + `","password":"` + password + `"}`
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/auth/session", strings.NewReader(body)))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("login status = %d; want 201 (body: %s)", rr.Code, rr.Body.String())
+	}
+	var resp SessionResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	cookies := rr.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != sessionCookie {
+		t.Fatalf("login set cookies %v; want one %s", cookies, sessionCookie)
+	}
+	return cookies[0], resp
+}
+
+// sendWithSession sends a request carrying cookie, if not nil, and the
+// given header pairs
+func sendWithSession(handler http.Handler, method, path, body string, cookie *http.Cookie, header ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestSession_Login(t *testing.T) {
+	router, sm, now := testSessions(t)
+	cookie, resp := sessionLogin(t, router, "alice", "wonderland")
+
+	if !cookie.HttpOnly || !cookie.Secure || cookie.SameSite != http.SameSiteLaxMode || cookie.Path != "/" {
+		t.Errorf("cookie = %+v; want HttpOnly, Secure, SameSite=Lax on /", cookie)
+	}
+	if want := now.Add(time.Hour); !cookie.Expires.Equal(want) || !resp.ExpiresAt.Equal(want) {
+		t.Errorf("cookie expires %v, session %v; want %v", cookie.Expires, resp.ExpiresAt, want)
+	}
+	if resp.Username != "alice" || resp.Role != RoleAdmin || len(resp.CSRFToken) < 40 {
+		t.Errorf("response = %+v; want alice as admin with a CSRF token", resp)
+	}
+
+	// The store knows the session by the hash of the cookie only
+	if _, ok := sm.store.Session(cookie.Value); ok {
+		t.Error("session stored under the cookie value")
+	}
+	if s, ok := sm.store.Session(hashKey(cookie.Value)); !ok || s.CSRFToken != resp.CSRFToken {
+		t.Errorf("stored session = %+v, %v; want it under the cookie's hash", s, ok)
+	}
+
+	// Each login is a new session
+	again, _ := sessionLogin(t, router, "alice", "wonderland")
+	if again.Value == cookie.Value {
+		t.Error("second login reused the session ID")
+	}
+}
+
+func TestSession_LoginRejected(t *testing.T) {
+	router, _, _ := testSessions(t)
+	for _, body := range []string{`{"username":"alice","password":"looking-glass"}`, `{"username":"mallory","password":"x"}`, `{"username":"alice"}`} {
+		rr := sendWithSession(router, http.MethodPost, "/auth/session", body, nil)
+		if rr.Code == http.StatusCreated || len(rr.Result().Cookies()) != 0 {
+			t.Errorf("login with %s = %d with cookies %v; want an error and no cookie", body, rr.Code, rr.Result().Cookies())
+		}
+	}
+}
+
+func TestSession_CSRF(t *testing.T) {
+	router, _, _ := testSessions(t)
+	alice, aliceSession := sessionLogin(t, router, "alice", "wonderland")
+	bob, bobSession := sessionLogin(t, router, "bob", "builder")
+	book := `{"title":"T","author":"A","price":1}`
+
+	tests := []struct {
+		name       string
+		cookie     *http.Cookie
+		header     []string
+		wantStatus int
+		wantDetail string
+	}{
+		{"no token", alice, nil, http.StatusForbidden, "Missing or invalid CSRF token"},
+		{"wrong token", alice, []string{csrfHeader, "forged"}, http.StatusForbidden, "Missing or invalid CSRF token"},
+		{"another session's token", alice, []string{csrfHeader, bobSession.CSRFToken}, http.StatusForbidden, "Missing or invalid CSRF token"},
+		{"right token", alice, []string{csrfHeader, aliceSession.CSRFToken}, http.StatusCreated, ""},
+		{"role lacks permission", bob, []string{csrfHeader, bobSession.CSRFToken}, http.StatusForbidden, `Role "reader" lacks permission books:create`},
+		{"unknown session", &http.Cookie{Name: sessionCookie, Value: "nope"}, []string{csrfHeader, aliceSession.CSRFToken}, http.StatusUnauthorized, "Missing or expired session"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := sendWithSession(router, http.MethodPost, "/books", book, tc.cookie, tc.header...)
+			if rr.Code != tc.wantStatus {
+				t.Fatalf("status = %d; want %d (body: %s)", rr.Code, tc.wantStatus, rr.Body.String())
+			}
+			if tc.wantDetail != "" {
+				var p Problem
+				if err := json.NewDecoder(rr.Body).Decode(&p); err != nil {
+					t.Fatal(err)
+				}
+				if p.Detail != tc.wantDetail {
+					t.Errorf("detail = %q; want %q", p.Detail, tc.wantDetail)
+				}
+			}
+		})
+	}
+
+	// A bearer token wins over a cookie, and needs no CSRF token
+	rr := login(t, router, `{"username":"alice","password":"wonderland"}`)
+	var lr LoginResponse
+	if err := json.NewDecoder(rr.Body).Decode(&lr); err != nil {
+		t.Fatal(err)
+	}
+	if rr := sendWithSession(router, http.MethodPost, "/books", book, bob, "Authorization", "Bearer "+lr.Token); rr.Code != http.StatusCreated {
+		t.Errorf("bearer token with a reader's cookie: status = %d; want 201", rr.Code)
+	}
+}
+
+func TestSession_GetIsNotCached(t *testing.T) {
+	router, _, _ := testSessions(t)
+	alice, aliceSession := sessionLogin(t, router, "alice", "wonderland")
+	bob, _ := sessionLogin(t, router, "bob", "builder")
+
+	// Reads need no CSRF token, and each caller sees their own session
+	// even though GET responses are cached
+	for _, want := range []struct {
+		cookie *http.Cookie
+		user   string
+	}{{alice, "alice"}, {bob, "bob"}, {alice, "alice"}} {
+		rr := sendWithSession(router, http.MethodGet, "/auth/session", "", want.cookie)
+		var resp SessionResponse
+		if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		if rr.Code != http.StatusOK || resp.Username != want.user || rr.Header().Get("Cache-Control") != "no-store" {
+			t.Errorf("GET /auth/session = %d %+v, Cache-Control %q; want %s, no-store", rr.Code, resp, rr.Header().Get("Cache-Control"), want.user)
+		}
+		if want.user == "alice" && resp.CSRFToken != aliceSession.CSRFToken {
+			t.Errorf("CSRF token = %q; want the one issued at login", resp.CSRFToken)
+		}
+	}
+	if rr := sendWithSession(router, http.MethodGet, "/auth/session", "", nil); rr.Code != http.StatusUnauthorized {
+		t.Errorf("GET /auth/session without a cookie: status = %d; want 401", rr.Code)
+	}
+}
+
+func TestSession_Logout(t *testing.T) {
+	router, _, _ := testSessions(t)
+	cookie, resp := sessionLogin(t, router, "alice", "wonderland")
+
+	if rr := sendWithSession(router, http.MethodDelete, "/auth/session", "", cookie); rr.Code != http.StatusForbidden {
+		t.Errorf("logout without a CSRF token: status = %d; want 403", rr.Code)
+	}
+	rr := sendWithSession(router, http.MethodDelete, "/auth/session", "", cookie, csrfHeader, resp.CSRFToken)
+	if rr.Code != http.StatusNoContent {
+		t.Fatalf("logout status = %d; want 204", rr.Code)
+	}
+	if c := rr.Result().Cookies(); len(c) != 1 || c[0].Name != sessionCookie || c[0].MaxAge >= 0 || c[0].Value != "" {
+		t.Errorf("logout cookies = %v; want the session cookie cleared", c)
+	}
+
+	// The old cookie is dead, whatever the browser does with it
+	if rr := sendWithSession(router, http.MethodGet, "/auth/session", "", cookie); rr.Code != http.StatusUnauthorized {
+		t.Errorf("GET after logout: status = %d; want 401", rr.Code)
+	}
+	if rr := sendWithSession(router, http.MethodPost, "/books", `{"title":"T","author":"A","price":1}`, cookie, csrfHeader, resp.CSRFToken); rr.Code != http.StatusUnauthorized {
+		t.Errorf("POST after logout: status = %d; want 401", rr.Code)
+	}
+}
+
+func TestSession_Expiry(t *testing.T) {
+	router, sm, now := testSessions(t)
+	cookie, _ := sessionLogin(t, router, "alice", "wonderland")
+
+	*now = now.Add(time.Hour - time.Second)
+	if rr := sendWithSession(router, http.MethodGet, "/auth/session", "", cookie); rr.Code != http.StatusOK {
+		t.Errorf("just before expiry: status = %d; want 200", rr.Code)
+	}
+	*now = now.Add(time.Second)
+	if rr := sendWithSession(router, http.MethodGet, "/auth/session", "", cookie); rr.Code != http.StatusUnauthorized {
+		t.Errorf("at expiry: status = %d; want 401", rr.Code)
+	}
+
+	// The next login sweeps the expired session out of the store
+	sessionLogin(t, router, "bob", "builder")
+	if _, ok := sm.store.Session(hashKey(cookie.Value)); ok {
+		t.Error("expired session still stored after the next login")
+	}
+}
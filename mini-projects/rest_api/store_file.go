@@ -0,0 +1,596 @@
+//go:build filestore
+
+package restapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/clock"
+)
+
+// repositoryKind names the store compiled into this binary
+const repositoryKind = "file"
+
+// newRepository returns a store backed by cfg.DataFile, snapshotted every
+// cfg.SnapshotInterval if that is set. stop ends the snapshots once the
+// store has no more changes coming, taking a last one first.
+func newRepository(cfg Config) (store BookRepository, stop func(), err error) {
+	s, err := NewFileBookStore(cfg.DataFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cfg.SnapshotInterval <= 0 {
+		return s, func() {}, nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.RunSnapshots(ctx, cfg.SnapshotInterval)
+	}()
+	return s, func() {
+		cancel()
+		<-done
+	}, nil
+}
+
+// FileBookStore is a BookStore that writes every change to a JSON file and
+// reads it back on start-up, so books survive a restart.
+//
+// Every write goes to a temporary file in the same directory, which is
+// synced and then renamed over the data file. Rename within a directory is
+// atomic, so after a crash the data file holds either the old or the new
+// books, never half of each. Snapshots copy the data file to
+// <path>.snapshot, the fallback if the data file is ever unreadable.
+type FileBookStore struct {
+	*BookStore
+	path string
+
+	// mu makes each change and its save one step, so concurrent changes
+	// cannot write their files out of order
+	mu sync.Mutex
+
+	// version counts changes; snapshotted is the version the last
+	// snapshot saved, so an idle store is not rewritten
+	version, snapshotted uint64
+
+	clock clock.Clock // times RunSnapshots; clock.Real outside tests
+}
+
+// snapshotPath returns where snapshots of the data file at path are kept
+func snapshotPath(path string) string {
+	return path + ".snapshot"
+}
+
+// NewFileBookStore loads the books in path. A missing file is created with
+// the same sample books NewBookStore starts with. If the file is missing
+// or unreadable but a snapshot exists, the books are recovered from the
+// snapshot and written back to path.
+func NewFileBookStore(path string) (*FileBookStore, error) {
+	s := &FileBookStore{path: path, clock: clock.Real}
+	removeStaleTemps(path)
+
+	books, err := readBooks(path)
+	if err != nil {
+		snapshot, snapErr := readBooks(snapshotPath(path))
+		switch {
+		case snapErr == nil:
+			slog.Warn("recovering books from snapshot", "path", path, "error", err)
+			books, err = snapshot, nil
+		case errors.Is(err, fs.ErrNotExist) && errors.Is(snapErr, fs.ErrNotExist):
+			s.BookStore = NewBookStore()
+			return s, s.save()
+		default:
+			return nil, errors.Join(err, snapErr)
+		}
+		s.load(books)
+		return s, s.save()
+	}
+
+	s.load(books)
+	return s, nil
+}
+
+// readBooks parses the JSON file at path
+func readBooks(path string) ([]Book, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var books []Book
+	if err := json.Unmarshal(data, &books); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return books, nil
+}
+
+// load replaces the store's contents with books
+func (s *FileBookStore) load(books []Book) {
+	s.BookStore = &BookStore{books: make(map[int]Book, len(books)), nextID: 1}
+	for _, book := range books {
+		s.books[book.ID] = book
+		if book.ID >= s.nextID {
+			s.nextID = book.ID + 1
+		}
+	}
+}
+
+// AddBook adds a book and saves the file
+func (s *FileBookStore) AddBook(book Book) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := s.BookStore.AddBook(book)
+	s.persist()
+	return id
+}
+
+// AddBooks adds books and saves the file once for all of them
+func (s *FileBookStore) AddBooks(books []Book) []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := s.BookStore.AddBooks(books)
+	s.persist()
+	return ids
+}
+
+// UpdateBook updates a book and saves the file
+func (s *FileBookStore) UpdateBook(id int, book Book) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.BookStore.UpdateBook(id, book) {
+		return false
+	}
+	s.persist()
+	return true
+}
+
+// DeleteBook deletes a book and saves the file
+func (s *FileBookStore) DeleteBook(id int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.BookStore.DeleteBook(id) {
+		return false
+	}
+	s.persist()
+	return true
+}
+
+// Begin starts a transaction whose Commit saves the file once for all of
+// its changes
+func (s *FileBookStore) Begin() (Tx, error) {
+	return fileTx{s.BookStore.begin(), s}, nil
+}
+
+// fileTx is a transaction over a FileBookStore
+type fileTx struct {
+	*bookTx
+	s *FileBookStore
+}
+
+func (t fileTx) Commit() error {
+	t.s.mu.Lock()
+	defer t.s.mu.Unlock()
+
+	if err := t.bookTx.Commit(); err != nil {
+		return err
+	}
+	t.s.persist()
+	return nil
+}
+
+// persist saves the file. BookRepository has no error results, so a failed
+// save is logged; the change stays in memory and the next save writes it.
+func (s *FileBookStore) persist() {
+	s.version++
+	if err := s.save(); err != nil {
+		slog.Error("saving books", "path", s.path, "error", err)
+	}
+}
+
+// save writes every book to the data file
+func (s *FileBookStore) save() error {
+	return s.writeTo(s.path)
+}
+
+// writeTo writes every book to path, ordered by ID
+func (s *FileBookStore) writeTo(path string) error {
+	books := s.GetBooks()
+	sort.Slice(books, func(i, j int) bool { return books[i].ID < books[j].ID })
+
+	data, err := json.MarshalIndent(books, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// Snapshot writes the books to the snapshot file unless nothing changed
+// since the last snapshot
+func (s *FileBookStore) Snapshot() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.version == s.snapshotted {
+		return nil
+	}
+	if err := s.writeTo(snapshotPath(s.path)); err != nil {
+		return err
+	}
+	s.snapshotted = s.version
+	return nil
+}
+
+// RunSnapshots calls Snapshot every interval until ctx is done, then takes
+// a final snapshot so a clean shutdown leaves it up to date
+func (s *FileBookStore) RunSnapshots(ctx context.Context, interval time.Duration) {
+	timer := s.clock.NewTimer(interval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if err := s.Snapshot(); err != nil {
+				slog.Error("final snapshot", "path", s.path, "error", err)
+			}
+			return
+		case <-timer.C():
+			if err := s.Snapshot(); err != nil {
+				slog.Error("snapshot", "path", s.path, "error", err)
+			}
+			timer.Reset(interval)
+		}
+	}
+}
+
+// newKeyRepository returns an API key repository backed by cfg.KeysFile
+func newKeyRepository(cfg Config) (APIKeyRepository, error) {
+	return NewFileAPIKeyRepository(cfg.KeysFile)
+}
+
+// FileAPIKeyRepository keeps API keys in a JSON file, written atomically
+// on every change like the books. The file holds key hashes, not secrets.
+type FileAPIKeyRepository struct {
+	*MemoryAPIKeyRepository
+	path string
+	mu   sync.Mutex // serialises each change with its save
+}
+
+// NewFileAPIKeyRepository loads the keys in path; a missing file is an
+// empty repository
+func NewFileAPIKeyRepository(path string) (*FileAPIKeyRepository, error) {
+	removeStaleTemps(path)
+	repo := &FileAPIKeyRepository{MemoryAPIKeyRepository: NewMemoryAPIKeyRepository(), path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return repo, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var keys []APIKey
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("reading API keys from %s: %w", path, err)
+	}
+	for _, k := range keys {
+		repo.MemoryAPIKeyRepository.PutAPIKey(k)
+	}
+	return repo, nil
+}
+
+// PutAPIKey stores key and saves the file. As with books, a failed save is
+// logged and the change kept in memory.
+func (r *FileAPIKeyRepository) PutAPIKey(key APIKey) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.MemoryAPIKeyRepository.PutAPIKey(key)
+	data, err := json.MarshalIndent(r.ListAPIKeys(), "", "  ")
+	if err == nil {
+		err = writeFileAtomic(r.path, func(w io.Writer) error {
+			_, err := w.Write(data)
+			return err
+		})
+	}
+	if err != nil {
+		slog.Error("saving API keys", "path", r.path, "error", err)
+	}
+}
+
+// newSessionStore returns a session store backed by cfg.SessionsFile
+func newSessionStore(cfg Config) (SessionStore, error) {
+	return NewFileSessionStore(cfg.SessionsFile)
+}
+
+// FileSessionStore keeps sessions in a JSON file, written atomically on
+// every change like the API keys, so logins survive a restart. The file
+// holds hashes of the session IDs, not the IDs the cookies carry.
+type FileSessionStore struct {
+	*MemorySessionStore
+	path string
+	mu   sync.Mutex // serialises each change with its save
+}
+
+// NewFileSessionStore loads the sessions in path; a missing file is an
+// empty store
+func NewFileSessionStore(path string) (*FileSessionStore, error) {
+	removeStaleTemps(path)
+	store := &FileSessionStore{MemorySessionStore: NewMemorySessionStore(), path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var sessions []Session
+	if err := json.Unmarshal(data, &sessions); err != nil {
+		return nil, fmt.Errorf("reading sessions from %s: %w", path, err)
+	}
+	for _, s := range sessions {
+		store.MemorySessionStore.PutSession(s)
+	}
+	return store, nil
+}
+
+// PutSession stores s and saves the file
+func (s *FileSessionStore) PutSession(sess Session) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.MemorySessionStore.PutSession(sess)
+	s.save()
+}
+
+// DeleteSession removes the session with id and saves the file
+func (s *FileSessionStore) DeleteSession(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.MemorySessionStore.DeleteSession(id)
+	s.save()
+}
+
+// UpdateSession applies update to the session with id and saves the file,
+// if there is such a session
+func (s *FileSessionStore) UpdateSession(id string, update func(*Session)) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ok := s.MemorySessionStore.UpdateSession(id, update)
+	if ok {
+		s.save()
+	}
+	return ok
+}
+
+// DeleteExpiredSessions removes the sessions expired at now, saving the
+// file only if there were any
+func (s *FileSessionStore) DeleteExpiredSessions(now time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := s.MemorySessionStore.DeleteExpiredSessions(now)
+	if n > 0 {
+		s.save()
+	}
+	return n
+}
+
+// save writes every session to the file. As with books, a failed save is
+// logged and the change kept in memory.
+func (s *FileSessionStore) save() {
+	data, err := json.MarshalIndent(s.listSessions(), "", "  ")
+	if err == nil {
+		err = writeFileAtomic(s.path, func(w io.Writer) error {
+			_, err := w.Write(data)
+			return err
+		})
+	}
+	if err != nil {
+		slog.Error("saving sessions", "path", s.path, "error", err)
+	}
+}
+
+// newCoverStore returns a cover store keeping images in cfg.CoversDir
+func newCoverStore(cfg Config) (CoverStore, error) {
+	return NewFileCoverStore(cfg.CoversDir)
+}
+
+// FileCoverStore keeps each cover image in a file named for its book ID,
+// written atomically like the books. The content type is sniffed again on
+// reading and the modification time is the file's own, so no metadata is
+// kept beside the images.
+type FileCoverStore struct {
+	dir string
+}
+
+// NewFileCoverStore returns a store for the covers in dir, creating it if
+// it does not exist
+func NewFileCoverStore(dir string) (*FileCoverStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileCoverStore{dir: dir}, nil
+}
+
+func (s *FileCoverStore) path(id int) string {
+	return filepath.Join(s.dir, strconv.Itoa(id))
+}
+
+// PutCover writes the cover of the book id, replacing any it had
+func (s *FileCoverStore) PutCover(id int, cover Cover) error {
+	return writeFileAtomic(s.path(id), func(w io.Writer) error {
+		_, err := w.Write(cover.Data)
+		return err
+	})
+}
+
+// Cover reads the cover of the book id
+func (s *FileCoverStore) Cover(id int) (Cover, error) {
+	f, err := os.Open(s.path(id))
+	if err != nil {
+		return Cover{}, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return Cover{}, err
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return Cover{}, err
+	}
+	return Cover{ContentType: http.DetectContentType(data), Data: data, ModTime: info.ModTime().UTC()}, nil
+}
+
+// DeleteCover removes the cover of the book id, if it has one
+func (s *FileCoverStore) DeleteCover(id int) error {
+	if err := os.Remove(s.path(id)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// newAuditLog returns an audit log appended to cfg.AuditFile
+func newAuditLog(cfg Config) (*AuditLog, error) {
+	return NewFileAuditLog(cfg.AuditFile)
+}
+
+// NewFileAuditLog loads the entries in the JSON lines file path and
+// appends new ones to it. Unlike the other files it is never rewritten:
+// each entry is one write to a file opened with O_APPEND, so a crash can
+// lose at most the entry being written.
+func NewFileAuditLog(path string) (*AuditLog, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	log := NewAuditLog()
+	dec := json.NewDecoder(f)
+	for {
+		var e AuditEntry
+		err := dec.Decode(&e)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		// A crash mid-write leaves a torn last line; cut it off so the
+		// next entry starts on a line of its own
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			off := dec.InputOffset()
+			err := f.Truncate(off)
+			if err == nil && off > 0 {
+				_, err = f.Write([]byte("\n"))
+			}
+			if err != nil {
+				f.Close()
+				return nil, err
+			}
+			break
+		}
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("reading audit log %s: %w", path, err)
+		}
+		log.entries = append(log.entries, e)
+	}
+	log.w = f
+	return log, nil
+}
+
+// tempPrefix starts the names of the temporary files written for path
+func tempPrefix(path string) string {
+	return "." + filepath.Base(path) + ".tmp-"
+}
+
+// writeFileAtomic replaces path with what write produces. The data goes to
+// a temporary file in the same directory (rename is only atomic within one
+// file system), is synced to disk, and is renamed over path only if every
+// step succeeded; on failure path is untouched and the temporary file is
+// removed.
+func writeFileAtomic(path string, write func(io.Writer) error) (err error) {
+	f, err := os.CreateTemp(filepath.Dir(path), tempPrefix(path)+"*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+
+	if err := write(f); err != nil {
+		return err
+	}
+	// Without Sync the rename can reach the disk before the data does, and
+	// a power cut would leave an empty file under the final name
+	if err := f.Sync(); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(f.Name(), 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return err
+	}
+	syncDir(filepath.Dir(path))
+	return nil
+}
+
+// syncDir makes a rename in dir durable. It is best effort: some platforms
+// cannot open or sync directories, and the rename has happened either way.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	d.Sync()
+	d.Close()
+}
+
+// removeStaleTemps deletes temporary files left by writes that crashed
+// before their rename; they were never part of the data
+func removeStaleTemps(path string) {
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		return
+	}
+	prefix := tempPrefix(path)
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), prefix) {
+			os.Remove(filepath.Join(filepath.Dir(path), e.Name()))
+		}
+	}
+}
+
+// tenantFiles returns cfg with each file moved into a directory named for
+// tenant beside it, so books.json becomes acme/books.json, and creates
+// those directories
+func tenantFiles(cfg Config, tenant string) (Config, error) {
+	for _, path := range []*string{&cfg.DataFile, &cfg.KeysFile, &cfg.CoversDir, &cfg.AuditFile, &cfg.SessionsFile} {
+		*path = filepath.Join(filepath.Dir(*path), tenant, filepath.Base(*path))
+		if err := os.MkdirAll(filepath.Dir(*path), 0o755); err != nil {
+			return Config{}, err
+		}
+	}
+	return cfg, nil
+}
@@ -0,0 +1,559 @@
+//go:build filestore
+
+package restapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/clock"
+	"github.com/rehan/go-interview-prep/pkg/money"
+)
+
+// These tests only build with -tags filestore:
+//
+//	go test -tags filestore ./mini-projects/rest_api
+
+func TestFileBookStore_Persists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "books.json")
+
+	store, err := NewFileBookStore(path)
+	if err != nil {
+		t.Fatalf("NewFileBookStore: %v", err)
+	}
+	if got := len(store.GetBooks()); got != 3 {
+		t.Fatalf("new file has %d books; want the 3 samples", got)
+	}
+	id := store.AddBook(Book{Title: "Learning Go", Author: "Jon Bodner", Price: money.MustParse("29.99")})
+	store.UpdateBook(1, Book{Title: "Updated", Author: "A", Price: money.FromCents(100)})
+	store.DeleteBook(2)
+
+	reopened, err := NewFileBookStore(path)
+	if err != nil {
+		t.Fatalf("reopening: %v", err)
+	}
+	if got := len(reopened.GetBooks()); got != 3 {
+		t.Errorf("reopened store has %d books; want 3", got)
+	}
+	if book, ok := reopened.GetBook(id); !ok || book.Title != "Learning Go" {
+		t.Errorf("GetBook(%d) = %+v, %v; want the added book", id, book, ok)
+	}
+	if book, _ := reopened.GetBook(1); book.Title != "Updated" {
+		t.Errorf("book 1 title = %q; want the update to be saved", book.Title)
+	}
+	if _, ok := reopened.GetBook(2); ok {
+		t.Error("deleted book 2 is back after reopening")
+	}
+
+	// IDs continue after the highest saved one instead of reusing deleted ones
+	if next := reopened.AddBook(Book{Title: "T", Author: "A", Price: money.FromCents(100)}); next != id+1 {
+		t.Errorf("next ID = %d; want %d", next, id+1)
+	}
+}
+
+func TestFileBookStore_Contract(t *testing.T) {
+	testRepository(t, func(t *testing.T) BookRepository {
+		store, err := NewFileBookStore(filepath.Join(t.TempDir(), "books.json"))
+		if err != nil {
+			t.Fatalf("NewFileBookStore: %v", err)
+		}
+		return store
+	})
+}
+
+func TestFileBookStore_AddBooks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "books.json")
+	store, err := NewFileBookStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := store.AddBooks([]Book{
+		{Title: "A", Author: "A", Price: money.FromCents(100)},
+		{Title: "B", Author: "B", Price: money.FromCents(200)},
+	})
+	if !slices.Equal(ids, []int{4, 5}) {
+		t.Fatalf("AddBooks = %v; want [4 5]", ids)
+	}
+
+	reopened, err := NewFileBookStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if book, ok := reopened.GetBook(5); !ok || book.Title != "B" {
+		t.Errorf("GetBook(5) = %+v, %v; want the second added book", book, ok)
+	}
+}
+
+// A transaction's changes reach the file when it commits, and a rolled
+// back one's never do
+func TestFileBookStore_Tx(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "books.json")
+	store, err := NewFileBookStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	savedTitles := func() []string {
+		t.Helper()
+		reopened, err := NewFileBookStore(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return titles(reopened.GetBooks())
+	}
+
+	tx, _ := store.Begin()
+	tx.DeleteBook(1)
+	tx.Rollback()
+
+	tx, _ = store.Begin()
+	tx.AddBook(Book{Title: "Learning Go", Author: "Jon Bodner", Price: money.MustParse("29.99")})
+	tx.DeleteBook(2)
+	if got := savedTitles(); len(got) != 3 || got[0] != "The Go Programming Language" {
+		t.Fatalf("saved before Commit: %q; want the 3 samples", got)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := savedTitles(), []string{"The Go Programming Language", "Go in Action", "Learning Go"}; !slices.Equal(got, want) {
+		t.Errorf("saved after Commit: %q; want %q", got, want)
+	}
+}
+
+func TestFileAPIKeyRepository_Persists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api_keys.json")
+
+	repo, err := NewFileAPIKeyRepository(path)
+	if err != nil {
+		t.Fatalf("NewFileAPIKeyRepository: %v", err)
+	}
+	keys := NewAPIKeyStore(repo)
+	kept, keptSecret := keys.Create("kept", []Permission{PermCreateBooks}, 10)
+	revoked, revokedSecret := keys.Create("revoked", nil, 0)
+	if err := keys.Revoke(revoked.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), keptSecret) || strings.Contains(string(data), revokedSecret) {
+		t.Error("keys file contains a secret")
+	}
+
+	reopened, err := NewFileAPIKeyRepository(path)
+	if err != nil {
+		t.Fatalf("reopening: %v", err)
+	}
+	keys = NewAPIKeyStore(reopened)
+	if got, err := keys.Authenticate(keptSecret); err != nil || got.ID != kept.ID || got.RateLimit != 10 || !got.Can(PermCreateBooks) {
+		t.Errorf("Authenticate(kept) = %+v, %v; want the saved key", got, err)
+	}
+	if _, err := keys.Authenticate(revokedSecret); !errors.Is(err, errKeyInvalid) {
+		t.Errorf("revoked key after reopening: err = %v; want errKeyInvalid", err)
+	}
+}
+
+func TestFileAPIKeyRepository_CorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api_keys.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFileAPIKeyRepository(path); err == nil {
+		t.Error("NewFileAPIKeyRepository on a corrupt file: err = nil")
+	}
+}
+
+func TestFileSessionStore_Persists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.json")
+	store, err := NewFileSessionStore(path)
+	if err != nil {
+		t.Fatalf("NewFileSessionStore: %v", err)
+	}
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	sm := newSessionManager(store, newUserStore(nil), time.Hour, true)
+	sm.now = func() time.Time { return now }
+	rr := httptest.NewRecorder()
+	kept := sm.create(rr, "alice", RoleEditor)
+	cookie := rr.Result().Cookies()[0]
+	sm.setFlash(kept, "Saved.")
+	kept.Flash = "Saved."
+	ended := sm.create(httptest.NewRecorder(), "bob", RoleReader)
+	sm.end(httptest.NewRecorder(), ended)
+	// A flash for an ended session does not bring it back
+	sm.setFlash(ended, "Too late.")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), cookie.Value) {
+		t.Error("sessions file contains a session ID")
+	}
+
+	reopened, err := NewFileSessionStore(path)
+	if err != nil {
+		t.Fatalf("reopening: %v", err)
+	}
+	sm.store = reopened
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(cookie)
+	if got, ok := sm.lookup(req); !ok || got != kept {
+		t.Errorf("lookup after reopening = %+v, %v; want %+v", got, ok, kept)
+	}
+	if _, ok := reopened.Session(ended.ID); ok {
+		t.Error("ended session is back after reopening")
+	}
+
+	// Expired sessions are swept from the file too
+	if n := reopened.DeleteExpiredSessions(now.Add(time.Hour)); n != 1 {
+		t.Errorf("DeleteExpiredSessions = %d; want 1", n)
+	}
+	if again, err := NewFileSessionStore(path); err != nil || len(again.listSessions()) != 0 {
+		t.Errorf("after sweeping, the file holds %d sessions, %v; want none", len(again.listSessions()), err)
+	}
+}
+
+func TestFileSessionStore_CorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFileSessionStore(path); err == nil {
+		t.Error("NewFileSessionStore on a corrupt file: err = nil")
+	}
+}
+
+func TestFileCoverStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "covers")
+	store, err := NewFileCoverStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileCoverStore: %v", err)
+	}
+	if _, err := store.Cover(1); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Cover before any upload: err = %v; want fs.ErrNotExist", err)
+	}
+	if err := store.PutCover(1, Cover{ContentType: "image/png", Data: pngCover}); err != nil {
+		t.Fatalf("PutCover: %v", err)
+	}
+
+	reopened, err := NewFileCoverStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cover, err := reopened.Cover(1)
+	if err != nil {
+		t.Fatalf("Cover after reopening: %v", err)
+	}
+	if cover.ContentType != "image/png" || string(cover.Data) != string(pngCover) || cover.ModTime.IsZero() {
+		t.Errorf("cover = %s, %d bytes, modified %v; want the PNG with a modification time", cover.ContentType, len(cover.Data), cover.ModTime)
+	}
+
+	if err := reopened.DeleteCover(1); err != nil {
+		t.Fatalf("DeleteCover: %v", err)
+	}
+	if err := reopened.DeleteCover(1); err != nil {
+		t.Errorf("deleting a missing cover: %v; want nil", err)
+	}
+	if _, err := reopened.Cover(1); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Cover after delete: err = %v; want fs.ErrNotExist", err)
+	}
+}
+
+func TestFileAuditLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	log, err := NewFileAuditLog(path)
+	if err != nil {
+		t.Fatalf("NewFileAuditLog: %v", err)
+	}
+	for _, id := range []string{"1", "2"} {
+		if _, err := log.Append(AuditEntry{Actor: "user:alice", Action: AuditDeleted, Resource: AuditBook, ResourceID: id}); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+	log.Close()
+
+	// A crash mid-write leaves part of a line, which is cut off
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"id":3,"actor":"us`)
+	f.Close()
+
+	reopened, err := NewFileAuditLog(path)
+	if err != nil {
+		t.Fatalf("reopening: %v", err)
+	}
+	defer reopened.Close()
+	e, err := reopened.Append(AuditEntry{Actor: "user:alice", Action: AuditDeleted, Resource: AuditBook, ResourceID: "3"})
+	if err != nil || e.ID != 3 {
+		t.Fatalf("Append after reopening = entry %d, %v; want entry 3", e.ID, err)
+	}
+	var ids []string
+	for _, e := range reopened.Entries(AuditFilter{}) {
+		ids = append(ids, e.ResourceID)
+	}
+	if strings.Join(ids, ",") != "3,2,1" {
+		t.Errorf("entries after reopening are for books %v; want 3, 2, 1", ids)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n"); len(lines) != 3 || !strings.HasPrefix(lines[2], `{"id":3,"time"`) {
+		t.Errorf("file = %q; want three whole lines", data)
+	}
+}
+
+func TestFileAuditLog_CorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	if err := os.WriteFile(path, []byte("not json\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFileAuditLog(path); err == nil {
+		t.Fatal("NewFileAuditLog on a corrupt file: want an error")
+	}
+}
+
+func TestFileBookStore_CorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "books.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFileBookStore(path); err == nil {
+		t.Error("NewFileBookStore accepted a corrupt file")
+	}
+}
+
+// A write that fails part-way, as a crash would, must leave the old file
+// intact and no temporary file behind
+func TestWriteFileAtomic_FailureKeepsOldFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "books.json")
+	if err := os.WriteFile(path, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	errCrash := errors.New("crash")
+	err := writeFileAtomic(path, func(w io.Writer) error {
+		io.WriteString(w, "half of the new da")
+		return errCrash
+	})
+	if !errors.Is(err, errCrash) {
+		t.Fatalf("writeFileAtomic error = %v; want the write error", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "old" {
+		t.Errorf("file = %q after a failed write; want \"old\"", data)
+	}
+	assertOnlyFiles(t, dir, "books.json")
+
+	if err := writeFileAtomic(path, func(w io.Writer) error {
+		_, err := io.WriteString(w, "new")
+		return err
+	}); err != nil {
+		t.Fatalf("writeFileAtomic: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "new" {
+		t.Errorf("file = %q; want \"new\"", data)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o644 {
+		t.Errorf("file mode = %v, %v; want 0644", info.Mode().Perm(), err)
+	}
+	assertOnlyFiles(t, dir, "books.json")
+}
+
+// Temporary files from a write that crashed before its rename are not
+// data; opening the store removes them and loads the last complete file
+func TestFileBookStore_RemovesStaleTemps(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "books.json")
+	if _, err := NewFileBookStore(path); err != nil {
+		t.Fatal(err)
+	}
+	stale := filepath.Join(dir, tempPrefix(path)+"12345")
+	if err := os.WriteFile(stale, []byte(`[{"id":99,"ti`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := NewFileBookStore(path)
+	if err != nil {
+		t.Fatalf("NewFileBookStore: %v", err)
+	}
+	if got := len(store.GetBooks()); got != 3 {
+		t.Errorf("store has %d books; want the 3 saved ones", got)
+	}
+	assertOnlyFiles(t, dir, "books.json")
+}
+
+func TestFileBookStore_RecoversFromSnapshot(t *testing.T) {
+	tests := []struct {
+		name   string
+		damage func(path string) error
+	}{
+		{"truncated data file", func(path string) error { return os.WriteFile(path, []byte(`[{"id":1,`), 0o644) }},
+		{"missing data file", os.Remove},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "books.json")
+			store, err := NewFileBookStore(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			id := store.AddBook(Book{Title: "Snapshotted", Author: "A", Price: money.FromCents(100)})
+			if err := store.Snapshot(); err != nil {
+				t.Fatalf("Snapshot: %v", err)
+			}
+			// Changes after the snapshot are lost along with the data file
+			store.AddBook(Book{Title: "After snapshot", Author: "A", Price: money.FromCents(100)})
+
+			if err := tc.damage(path); err != nil {
+				t.Fatal(err)
+			}
+			recovered, err := NewFileBookStore(path)
+			if err != nil {
+				t.Fatalf("NewFileBookStore: %v", err)
+			}
+			if got := len(recovered.GetBooks()); got != 4 {
+				t.Errorf("recovered %d books; want the 4 in the snapshot", got)
+			}
+			if book, ok := recovered.GetBook(id); !ok || book.Title != "Snapshotted" {
+				t.Errorf("GetBook(%d) = %+v, %v; want the snapshotted book", id, book, ok)
+			}
+
+			// The recovered books are written back to the data file
+			if _, err := readBooks(path); err != nil {
+				t.Errorf("data file not rewritten after recovery: %v", err)
+			}
+		})
+	}
+}
+
+func TestFileBookStore_CorruptFileAndSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "books.json")
+	for _, p := range []string{path, snapshotPath(path)} {
+		if err := os.WriteFile(p, []byte("{not json"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := NewFileBookStore(path)
+	if err == nil || !strings.Contains(err.Error(), "books.json.snapshot") {
+		t.Errorf("NewFileBookStore error = %v; want it to report both files", err)
+	}
+}
+
+func TestFileBookStore_SnapshotSkipsUnchanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "books.json")
+	store, err := NewFileBookStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Snapshot(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(snapshotPath(path)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("snapshot written with no changes (stat error %v)", err)
+	}
+
+	store.DeleteBook(1)
+	if err := store.Snapshot(); err != nil {
+		t.Fatal(err)
+	}
+	first, err := os.Stat(snapshotPath(path))
+	if err != nil {
+		t.Fatalf("no snapshot after a change: %v", err)
+	}
+
+	// A second snapshot without changes leaves the file alone
+	os.Chtimes(snapshotPath(path), time.Time{}, first.ModTime().Add(-time.Hour))
+	if err := store.Snapshot(); err != nil {
+		t.Fatal(err)
+	}
+	if second, _ := os.Stat(snapshotPath(path)); !second.ModTime().Before(first.ModTime()) {
+		t.Error("unchanged store rewrote its snapshot")
+	}
+}
+
+func TestFileBookStore_RunSnapshots(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "books.json")
+	store, err := NewFileBookStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	store.clock = c
+	store.DeleteBook(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		store.RunSnapshots(ctx, time.Minute)
+		close(done)
+	}()
+
+	// Nothing is written before the first tick
+	c.BlockUntil(1)
+	if _, err := os.Stat(snapshotPath(path)); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("snapshot before the first tick: %v", err)
+	}
+	// The timer is set again only after the tick's snapshot is written
+	c.Advance(time.Minute)
+	c.BlockUntil(1)
+	if books, err := readBooks(snapshotPath(path)); err != nil || len(books) != 2 {
+		t.Fatalf("snapshot after a tick has %d books, %v; want 2", len(books), err)
+	}
+
+	// Cancelling takes a final snapshot of changes since the last tick
+	store.DeleteBook(2)
+	cancel()
+	<-done
+	if books, err := readBooks(snapshotPath(path)); err != nil || len(books) != 1 {
+		t.Errorf("final snapshot has %d books, %v; want 1", len(books), err)
+	}
+}
+
+// The server's store snapshots until stop, which saves the changes made
+// since the last tick before it returns
+func TestNewRepository_StopSnapshots(t *testing.T) {
+	cfg := defaultConfig
+	cfg.DataFile = filepath.Join(t.TempDir(), "books.json")
+	cfg.SnapshotInterval = time.Hour
+	store, stop, err := newRepository(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.DeleteBook(1)
+	stop()
+	if books, err := readBooks(snapshotPath(cfg.DataFile)); err != nil || len(books) != 2 {
+		t.Errorf("snapshot after stop has %d books, %v; want 2", len(books), err)
+	}
+}
+
+// assertOnlyFiles fails unless dir holds exactly the named files
+func assertOnlyFiles(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Name())
+	}
+	if strings.Join(got, ",") != strings.Join(names, ",") {
+		t.Errorf("directory holds %v; want %v", got, names)
+	}
+}
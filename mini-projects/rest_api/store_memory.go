@@ -0,0 +1,43 @@
+//go:build !filestore
+
+package restapi
+
+// repositoryKind names the store compiled into this binary
+const repositoryKind = "memory"
+
+// newRepository returns the in-memory store, whose books are lost on
+// restart. Build with -tags filestore to keep them in cfg.DataFile instead.
+// It has nothing running in the background, so stop does nothing.
+func newRepository(cfg Config) (store BookRepository, stop func(), err error) {
+	return NewBookStore(), func() {}, nil
+}
+
+// newKeyRepository returns an in-memory API key repository, so keys are
+// lost on restart like the books
+func newKeyRepository(cfg Config) (APIKeyRepository, error) {
+	return NewMemoryAPIKeyRepository(), nil
+}
+
+// newCoverStore returns an in-memory cover store, so covers are lost on
+// restart like the books
+func newCoverStore(cfg Config) (CoverStore, error) {
+	return NewMemoryCoverStore(), nil
+}
+
+// newSessionStore returns an in-memory session store, so everyone is
+// logged out on restart
+func newSessionStore(cfg Config) (SessionStore, error) {
+	return NewMemorySessionStore(), nil
+}
+
+// newAuditLog returns an in-memory audit log, lost on restart like the
+// books
+func newAuditLog(cfg Config) (*AuditLog, error) {
+	return NewAuditLog(), nil
+}
+
+// tenantFiles returns cfg unchanged: a tenant's in-memory stores are its
+// own without any files to keep apart
+func tenantFiles(cfg Config, tenant string) (Config, error) {
+	return cfg, nil
+}
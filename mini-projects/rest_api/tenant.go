@@ -0,0 +1,209 @@
+package restapi
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/errorsx"
+	"github.com/rehan/go-interview-prep/pkg/ratelimit"
+)
+
+// tenantHeader names the request's tenant, as an alternative to a
+// subdomain of the tenant domain
+const tenantHeader = "X-Tenant-ID"
+
+// tenantQuotaWindow is the period a tenant's quota counts requests over.
+// Windows start at midnight UTC.
+const tenantQuotaWindow = 24 * time.Hour
+
+// tenantIDPattern matches a DNS label, so every tenant can also be
+// reached by subdomain
+var tenantIDPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+var (
+	errNoTenant      = errorsx.New(errorsx.CodeInvalidArgument, "No tenant: send "+tenantHeader+" or use the tenant's subdomain")
+	errTenantLimited = errorsx.New(errorsx.CodeResourceExhausted, "Tenant rate limit exceeded")
+	errTenantQuota   = errorsx.New(errorsx.CodeResourceExhausted, "Tenant request quota used up")
+)
+
+// tenantKey is the context key for the request's tenant
+type tenantKey struct{}
+
+func withTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant the request was made to, or "" if
+// the server is not multi-tenant
+func TenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
+
+// tenantConfig returns cfg as one tenant's API is built from. Its files
+// are its own, and its token key is derived from the JWT secret, so a
+// token issued to one tenant is refused by every other.
+func tenantConfig(cfg Config, tenant string) (Config, error) {
+	if cfg.JWTSecret != "" {
+		mac := hmac.New(sha256.New, []byte(cfg.JWTSecret))
+		mac.Write([]byte("tenant:" + tenant))
+		cfg.JWTSecret = hex.EncodeToString(mac.Sum(nil))
+	}
+	return tenantFiles(cfg, tenant)
+}
+
+// tenantRouter resolves each request's tenant and hands it to that
+// tenant's API. Each tenant is rate limited and given a quota of its own,
+// so a busy tenant cannot starve the others.
+type tenantRouter struct {
+	tenants map[string]http.Handler
+
+	// domain, if set, makes acme.<domain> reach tenant acme
+	domain string
+
+	limiter *ratelimit.Limiter // nil is unlimited
+	quota   *tenantQuota       // nil is unlimited
+}
+
+// newTenantRouter returns a router for tenants, each allowed ratePerMinute
+// requests a minute and quota a day; zero is unlimited
+func newTenantRouter(tenants map[string]http.Handler, domain string, ratePerMinute, quota int) *tenantRouter {
+	tr := &tenantRouter{tenants: tenants, domain: strings.ToLower(domain)}
+	if ratePerMinute > 0 {
+		tr.limiter = ratelimit.NewLimiter(ratelimit.Config{Rate: ratelimit.Per(ratePerMinute, time.Minute), Burst: ratePerMinute})
+	}
+	if quota > 0 {
+		tr.quota = newTenantQuota(quota, tenantQuotaWindow)
+	}
+	return tr
+}
+
+func (tr *tenantRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	tenant, err := tr.resolve(r)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	if tr.limiter != nil {
+		if res := tr.limiter.Allow(tenant); !res.Allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(res.RetryAfter)))
+			respondWithError(w, errTenantLimited)
+			return
+		}
+	}
+	if tr.quota != nil {
+		limit, remaining, reset, ok := tr.quota.take(tenant, time.Now())
+		w.Header().Set("X-Quota-Limit", strconv.Itoa(limit))
+		w.Header().Set("X-Quota-Remaining", strconv.Itoa(remaining))
+		if !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(reset)))
+			respondWithError(w, errTenantQuota)
+			return
+		}
+	}
+	tr.tenants[tenant].ServeHTTP(w, r.WithContext(withTenant(r.Context(), tenant)))
+}
+
+// resolve returns the tenant named by the X-Tenant-ID header or the Host's
+// subdomain. A request naming two different tenants is refused rather
+// than served by either.
+func (tr *tenantRouter) resolve(r *http.Request) (string, error) {
+	fromHeader := r.Header.Get(tenantHeader)
+	fromHost := tr.subdomain(r.Host)
+	if fromHeader != "" && fromHost != "" && fromHeader != fromHost {
+		return "", errorsx.Errorf(errorsx.CodeInvalidArgument, "%s %q does not match the host's tenant %q", tenantHeader, fromHeader, fromHost)
+	}
+	tenant := fromHeader
+	if tenant == "" {
+		tenant = fromHost
+	}
+	if tenant == "" {
+		return "", errNoTenant
+	}
+	if _, ok := tr.tenants[tenant]; !ok {
+		return "", errorsx.Errorf(errorsx.CodeNotFound, "Tenant %q not found", tenant)
+	}
+	return tenant, nil
+}
+
+// subdomain returns the label host has in front of the tenant domain, or ""
+// if host is not directly under it
+func (tr *tenantRouter) subdomain(host string) string {
+	if tr.domain == "" {
+		return ""
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	label, ok := strings.CutSuffix(strings.ToLower(host), "."+tr.domain)
+	if !ok || strings.Contains(label, ".") {
+		return ""
+	}
+	return label
+}
+
+// ceilSeconds rounds d up to whole seconds, as Retry-After needs
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// tenantQuota counts each tenant's requests in fixed windows, refusing
+// any past the limit until the next window starts. It is safe for
+// concurrent use.
+type tenantQuota struct {
+	limit  int
+	window time.Duration
+
+	mu    sync.Mutex
+	start time.Time      // start of the current window
+	used  map[string]int // requests per tenant in the current window
+}
+
+func newTenantQuota(limit int, window time.Duration) *tenantQuota {
+	return &tenantQuota{limit: limit, window: window, used: make(map[string]int)}
+}
+
+// take counts a request by tenant at now, reporting whether it is within
+// the quota, what is left of it and how long until the window resets
+func (q *tenantQuota) take(tenant string, now time.Time) (limit, remaining int, reset time.Duration, ok bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if start := now.Truncate(q.window); !start.Equal(q.start) {
+		q.start = start
+		clear(q.used)
+	}
+	reset = q.start.Add(q.window).Sub(now)
+	if q.used[tenant] >= q.limit {
+		return q.limit, 0, reset, false
+	}
+	q.used[tenant]++
+	return q.limit, q.limit - q.used[tenant], reset, true
+}
+
+// validateTenants checks the tenant IDs can name a tenant in a header and
+// a subdomain alike
+func validateTenants(tenants []string) error {
+	seen := make(map[string]bool)
+	for _, t := range tenants {
+		if !tenantIDPattern.MatchString(t) {
+			return fmt.Errorf("tenant %q must be lowercase letters, digits and inner hyphens, at most 63 long", t)
+		}
+		if seen[t] {
+			return fmt.Errorf("tenant %q is listed twice", t)
+		}
+		seen[t] = true
+	}
+	return nil
+}
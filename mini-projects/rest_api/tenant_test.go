@@ -0,0 +1,264 @@
+package restapi
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/jwt"
+)
+
+// testTenants returns a router for tenants acme and globex, built as main
+// builds them, with their files in a temporary directory
+func testTenants(t *testing.T, ratePerMinute, quota int) http.Handler {
+	t.Helper()
+	dir := t.TempDir()
+	cfg := defaultConfig
+	cfg.DataFile = filepath.Join(dir, "books.json")
+	cfg.KeysFile = filepath.Join(dir, "api_keys.json")
+	cfg.CoversDir = filepath.Join(dir, "covers")
+	cfg.AuditFile = filepath.Join(dir, "audit.jsonl")
+	cfg.SessionsFile = filepath.Join(dir, "sessions.json")
+	cfg.JWTSecret = strings.Repeat("k", jwt.MinKeySize)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	byTenant := make(map[string]http.Handler)
+	for _, tenant := range []string{"acme", "globex"} {
+		tcfg, err := tenantConfig(cfg, tenant)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stack, err := newAPIStack(tcfg, logger)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { stack.close(time.Second, logger) })
+		byTenant[tenant] = stack.handler
+	}
+	return newTenantRouter(byTenant, "books.test", ratePerMinute, quota)
+}
+
+// tenantSend sends a request to tenant by the X-Tenant-ID header, with
+// token as its bearer token unless it is empty
+func tenantSend(router http.Handler, tenant, method, path, token, body string) *httptest.ResponseRecorder {
+	header := []string{tenantHeader, tenant}
+	if token != "" {
+		header = append(header, "Authorization", "Bearer "+token)
+	}
+	return sendWithSession(router, method, path, body, nil, header...)
+}
+
+// tenantToken logs the demo admin in to tenant
+func tenantToken(t *testing.T, router http.Handler, tenant string) string {
+	t.Helper()
+	rr := tenantSend(router, tenant, http.MethodPost, "/auth/login", "", `{"username":"admin","password":"admin-password"}`)
+	var lr LoginResponse
+	if err := json.NewDecoder(rr.Body).Decode(&lr); err != nil || lr.Token == "" {
+		t.Fatalf("logging in to %s: status %d, %v", tenant, rr.Code, err)
+	}
+	return lr.Token
+}
+
+func TestTenantRouter_Resolve(t *testing.T) {
+	router := testTenants(t, 0, 0)
+	tests := []struct {
+		name, host, header string
+		want               int
+	}{
+		{"header", "example.com", "acme", http.StatusOK},
+		{"subdomain", "acme.books.test", "", http.StatusOK},
+		{"subdomain with port", "globex.books.test:8080", "", http.StatusOK},
+		{"host names are case-insensitive", "ACME.Books.Test", "", http.StatusOK},
+		{"header and subdomain agree", "acme.books.test", "acme", http.StatusOK},
+		{"header and subdomain disagree", "acme.books.test", "globex", http.StatusBadRequest},
+		{"no tenant", "books.test", "", http.StatusBadRequest},
+		{"not directly under the domain", "www.acme.books.test", "", http.StatusBadRequest},
+		{"other domain", "acme.example.com", "", http.StatusBadRequest},
+		{"unknown tenant", "initech.books.test", "", http.StatusNotFound},
+		{"unknown tenant by header", "example.com", "initech", http.StatusNotFound},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/books", nil)
+			req.Host = tc.host
+			if tc.header != "" {
+				req.Header.Set(tenantHeader, tc.header)
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+			if rr.Code != tc.want {
+				t.Errorf("status = %d; want %d (body: %s)", rr.Code, tc.want, rr.Body.String())
+			}
+		})
+	}
+}
+
+// Everything acme does stays in acme: globex, whose books start out the
+// same, sees none of it however it asks
+func TestTenants_DataDoesNotLeak(t *testing.T) {
+	router := testTenants(t, 0, 0)
+	acme, globex := tenantToken(t, router, "acme"), tenantToken(t, router, "globex")
+
+	rr := tenantSend(router, "acme", http.MethodPost, "/books", acme, `{"title":"Acme Only","author":"Wile E. Coyote","price":9.99}`)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("creating in acme: status %d (body: %s)", rr.Code, rr.Body.String())
+	}
+	// acme's list is cached first, so a cache shared by URL would hand it
+	// to globex
+	if body := tenantSend(router, "acme", http.MethodGet, "/books", "", "").Body.String(); !strings.Contains(body, "Acme Only") {
+		t.Fatalf("acme's list lacks its own book: %s", body)
+	}
+	if body := tenantSend(router, "globex", http.MethodGet, "/books", "", "").Body.String(); strings.Contains(body, "Acme Only") {
+		t.Errorf("globex's list has acme's book: %s", body)
+	}
+	if rr := tenantSend(router, "globex", http.MethodGet, "/books/4", "", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("globex GET /books/4: status %d; want 404", rr.Code)
+	}
+
+	// Tokens are signed with a key per tenant
+	if rr := tenantSend(router, "globex", http.MethodPost, "/books", acme, `{"title":"T","author":"A","price":1}`); rr.Code != http.StatusUnauthorized {
+		t.Errorf("acme's token on globex: status %d; want 401", rr.Code)
+	}
+
+	body, contentType := multipartFile(t, "file", "cover.png", pngCover)
+	rr = sendWithSession(router, http.MethodPost, "/books/1/cover", body.String(), nil, tenantHeader, "acme", "Authorization", "Bearer "+acme, "Content-Type", contentType)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("uploading acme's cover: status %d (body: %s)", rr.Code, rr.Body.String())
+	}
+	if rr := tenantSend(router, "globex", http.MethodGet, "/books/1/cover", "", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("globex GET /books/1/cover: status %d; want 404", rr.Code)
+	}
+
+	rr = tenantSend(router, "acme", http.MethodPost, "/admin/keys", acme, `{"name":"importer","scopes":["books:create"]}`)
+	var key struct{ Key string }
+	if err := json.NewDecoder(rr.Body).Decode(&key); err != nil || key.Key == "" {
+		t.Fatalf("creating acme's API key: status %d, %v", rr.Code, err)
+	}
+	if rr := sendWithSession(router, http.MethodPost, "/books", `{"title":"T","author":"A","price":1}`, nil, tenantHeader, "globex", apiKeyHeader, key.Key); rr.Code != http.StatusUnauthorized {
+		t.Errorf("acme's API key on globex: status %d; want 401", rr.Code)
+	}
+
+	if rr := tenantSend(router, "acme", http.MethodPost, "/jobs", acme, `{"kind":"export"}`); rr.Code != http.StatusAccepted {
+		t.Fatalf("starting acme's job: status %d (body: %s)", rr.Code, rr.Body.String())
+	}
+	if rr := tenantSend(router, "globex", http.MethodGet, "/jobs/1", globex, ""); rr.Code != http.StatusNotFound {
+		t.Errorf("globex GET /jobs/1: status %d; want 404", rr.Code)
+	}
+
+	// Changes reach the audit log in the background, so wait for acme's
+	// before looking for them in globex's
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var entries []AuditEntry
+		json.NewDecoder(tenantSend(router, "acme", http.MethodGet, "/admin/audit", acme, "").Body).Decode(&entries)
+		if len(entries) > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("acme's audit log is still empty")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	var entries []AuditEntry
+	if err := json.NewDecoder(tenantSend(router, "globex", http.MethodGet, "/admin/audit", globex, "").Body).Decode(&entries); err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("globex's audit log has %+v; want nothing", entries)
+	}
+	var keys []APIKeyInfo
+	if err := json.NewDecoder(tenantSend(router, "globex", http.MethodGet, "/admin/keys", globex, "").Body).Decode(&keys); err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 0 {
+		t.Errorf("globex's API keys are %+v; want none", keys)
+	}
+}
+
+// One tenant using up its rate limit or quota leaves the others theirs
+func TestTenants_RateLimitAndQuota(t *testing.T) {
+	tests := []struct {
+		name            string
+		rate, quota, ok int
+	}{
+		{"rate limit", 2, 0, 2},
+		{"quota", 0, 3, 3},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			router := testTenants(t, tc.rate, tc.quota)
+			for i := range tc.ok {
+				rr := tenantSend(router, "acme", http.MethodGet, "/books", "", "")
+				if rr.Code != http.StatusOK {
+					t.Fatalf("request %d: status %d; want 200", i+1, rr.Code)
+				}
+				if tc.quota > 0 {
+					if got, want := rr.Header().Get("X-Quota-Remaining"), tc.ok-i-1; got != strconv.Itoa(want) {
+						t.Errorf("request %d: X-Quota-Remaining = %q; want %d", i+1, got, want)
+					}
+				}
+			}
+			rr := tenantSend(router, "acme", http.MethodGet, "/books", "", "")
+			if rr.Code != http.StatusTooManyRequests {
+				t.Fatalf("request %d: status %d; want 429", tc.ok+1, rr.Code)
+			}
+			if rr.Header().Get("Retry-After") == "" {
+				t.Error("429 without Retry-After")
+			}
+			if rr := tenantSend(router, "globex", http.MethodGet, "/books", "", ""); rr.Code != http.StatusOK {
+				t.Errorf("globex: status %d; want 200", rr.Code)
+			}
+		})
+	}
+}
+
+func TestTenantQuota_ResetsEachWindow(t *testing.T) {
+	q := newTenantQuota(2, tenantQuotaWindow)
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, now := range []time.Time{day.Add(time.Hour), day.Add(23 * time.Hour)} {
+		if _, _, _, ok := q.take("acme", now); !ok {
+			t.Fatalf("request %d refused", i+1)
+		}
+	}
+	_, remaining, reset, ok := q.take("acme", day.Add(23*time.Hour+30*time.Minute))
+	if ok || remaining != 0 || reset != 30*time.Minute {
+		t.Errorf("third request: ok %v, remaining %d, reset %v; want refused, 0 left, reset in 30m", ok, remaining, reset)
+	}
+	if _, remaining, _, ok := q.take("acme", day.Add(24*time.Hour)); !ok || remaining != 1 {
+		t.Errorf("next day: ok %v, remaining %d; want allowed with 1 left", ok, remaining)
+	}
+}
+
+func TestTenantConfig_KeyPerTenant(t *testing.T) {
+	cfg := defaultConfig
+	// With -tags filestore, tenantConfig creates each tenant's directories
+	// beside its files, which must not land in the package directory
+	dir := t.TempDir()
+	for _, path := range []*string{&cfg.DataFile, &cfg.KeysFile, &cfg.CoversDir, &cfg.AuditFile, &cfg.SessionsFile} {
+		*path = filepath.Join(dir, *path)
+	}
+	cfg.JWTSecret = strings.Repeat("k", jwt.MinKeySize)
+	acme, err := tenantConfig(cfg, "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	globex, err := tenantConfig(cfg, "globex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acme.JWTSecret == globex.JWTSecret || acme.JWTSecret == cfg.JWTSecret || len(acme.JWTSecret) < jwt.MinKeySize {
+		t.Errorf("keys acme %q, globex %q from %q; want distinct keys of at least %d bytes", acme.JWTSecret, globex.JWTSecret, cfg.JWTSecret, jwt.MinKeySize)
+	}
+	// Without a secret each tenant gets a random key, as the server would
+	cfg.JWTSecret = ""
+	if acme, _ := tenantConfig(cfg, "acme"); acme.JWTSecret != "" {
+		t.Errorf("key from an empty secret = %q; want empty", acme.JWTSecret)
+	}
+}
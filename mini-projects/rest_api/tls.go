@@ -0,0 +1,167 @@
+package restapi
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// selfSignedHosts are the names a development certificate is valid for
+var selfSignedHosts = []string{"localhost", "127.0.0.1", "::1"}
+
+// tlsConfig returns a server TLS configuration serving certs. It refuses
+// TLS 1.0 and 1.1, which have known weaknesses and which no maintained
+// client needs, and limits TLS 1.2 to suites with forward secrecy and
+// AEAD ciphers. TLS 1.3 suites are not configurable, and are all sound.
+func tlsConfig(certs ...tls.Certificate) *tls.Config {
+	return &tls.Config{
+		Certificates:     certs,
+		MinVersion:       tls.VersionTLS12,
+		CurvePreferences: []tls.CurveID{tls.X25519, tls.CurveP256},
+		CipherSuites: []uint16{
+			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+		},
+		// http.Server only offers HTTP/2 over its own ServeTLS unless the
+		// listener's config asks for it
+		NextProtos: []string{"h2", "http/1.1"},
+	}
+}
+
+// loadTLSConfig returns tlsConfig serving the PEM certificate and key in
+// certFile and keyFile
+func loadTLSConfig(certFile, keyFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("loading TLS certificate: %w", err)
+	}
+	return tlsConfig(cert), nil
+}
+
+// selfSignedCert returns a PEM certificate and key for hosts, valid from
+// now for validFor. Clients trust it only if told to, with curl --cacert
+// or by adding it to a browser, so it is for local development only.
+func selfSignedCert(hosts []string, now time.Time, validFor time.Duration) (certPEM, keyPEM []byte, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{Organization: []string{"rest_api development"}},
+		// An hour of slack for clocks running behind
+		NotBefore: now.Add(-time.Hour),
+		NotAfter:  now.Add(validFor),
+		// The certificate signs itself, so it is its own CA; that is what
+		// lets clients add it as a trusted root
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+		} else {
+			tmpl.DNSNames = append(tmpl.DNSNames, h)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM, nil
+}
+
+// ensureSelfSignedCert writes a year's self-signed certificate for
+// selfSignedHosts to certFile and keyFile, unless certFile exists already.
+// Keeping the certificate across restarts means a browser told to trust
+// it once goes on trusting it. It reports whether it wrote one.
+func ensureSelfSignedCert(certFile, keyFile string, now time.Time) (bool, error) {
+	if _, err := os.Stat(certFile); err == nil {
+		return false, nil
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return false, err
+	}
+	certPEM, keyPEM, err := selfSignedCert(selfSignedHosts, now, 365*24*time.Hour)
+	if err != nil {
+		return false, err
+	}
+	// The key first, so a certificate never exists without its key
+	if err := os.WriteFile(keyFile, keyPEM, 0o600); err != nil {
+		return false, err
+	}
+	if err := os.WriteFile(certFile, certPEM, 0o644); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// redirectHandler sends plain HTTP requests to the same host and path
+// over HTTPS on httpsPort. GET and HEAD get 301; other methods get 308,
+// which tells clients to repeat the method and body rather than turn the
+// request into a GET.
+func redirectHandler(httpsPort string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+		if httpsPort != "443" {
+			host = net.JoinHostPort(host, httpsPort)
+		} else if strings.Contains(host, ":") {
+			host = "[" + host + "]" // an IPv6 address
+		}
+		code := http.StatusPermanentRedirect
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			code = http.StatusMovedPermanently
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), code)
+	}
+}
+
+// hstsMiddleware sets Strict-Transport-Security on responses sent over
+// TLS, so browsers use HTTPS for the host for maxAge without trying plain
+// HTTP first, where an attacker could intercept the redirect. Browsers
+// ignore the header over plain HTTP (RFC 6797), so it is not sent there.
+func hstsMiddleware(maxAge time.Duration) Middleware {
+	value := "max-age=" + strconv.Itoa(int(maxAge.Seconds())) + "; includeSubDomains"
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.TLS != nil {
+				w.Header().Set("Strict-Transport-Security", value)
+			}
+			next(w, r)
+		}
+	}
+}
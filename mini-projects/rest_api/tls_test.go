@@ -0,0 +1,209 @@
+package restapi
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCert returns a self-signed localhost certificate and a pool that
+// trusts it
+func testCert(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	t.Helper()
+	certPEM, keyPEM, err := selfSignedCert(selfSignedHosts, time.Now(), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(certPEM)
+	return cert, pool
+}
+
+func TestSelfSignedCert(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	certPEM, _, err := selfSignedCert([]string{"localhost", "127.0.0.1", "::1"}, now, 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode(certPEM)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+
+	for _, host := range []string{"localhost", "127.0.0.1", "::1"} {
+		opts := x509.VerifyOptions{DNSName: host, Roots: pool, CurrentTime: now.Add(time.Hour)}
+		if _, err := cert.Verify(opts); err != nil {
+			t.Errorf("verify for %s: %v", host, err)
+		}
+	}
+	if _, err := cert.Verify(x509.VerifyOptions{DNSName: "example.com", Roots: pool, CurrentTime: now}); err == nil {
+		t.Error("certificate verified for a host it was not made for")
+	}
+	if _, err := cert.Verify(x509.VerifyOptions{DNSName: "localhost", Roots: pool, CurrentTime: now.Add(25 * time.Hour)}); err == nil {
+		t.Error("certificate verified after it expired")
+	}
+}
+
+func TestEnsureSelfSignedCert(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+
+	created, err := ensureSelfSignedCert(certFile, keyFile, time.Now())
+	if err != nil || !created {
+		t.Fatalf("first call = %v, %v; want a certificate written", created, err)
+	}
+	info, err := os.Stat(keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("key file mode = %v; want 0600", info.Mode().Perm())
+	}
+	first, _ := os.ReadFile(certFile)
+
+	// A later start keeps the certificate a browser may already trust
+	created, err = ensureSelfSignedCert(certFile, keyFile, time.Now())
+	if err != nil || created {
+		t.Fatalf("second call = %v, %v; want the certificate kept", created, err)
+	}
+	if second, _ := os.ReadFile(certFile); string(second) != string(first) {
+		t.Error("second call replaced the certificate")
+	}
+	if _, err := loadTLSConfig(certFile, keyFile); err != nil {
+		t.Errorf("loadTLSConfig: %v", err)
+	}
+}
+
+func TestTLSConfig_RefusesOldVersions(t *testing.T) {
+	cert, pool := testCert(t)
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	ts.TLS = tlsConfig(cert)
+	ts.StartTLS()
+	defer ts.Close()
+
+	tests := []struct {
+		name     string
+		min, max uint16
+		wantErr  bool
+	}{
+		{"TLS 1.1", tls.VersionTLS10, tls.VersionTLS11, true},
+		{"TLS 1.2", tls.VersionTLS12, tls.VersionTLS12, false},
+		{"TLS 1.3", tls.VersionTLS13, tls.VersionTLS13, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+				RootCAs:    pool,
+				ServerName: "localhost",
+				MinVersion: tc.min,
+				MaxVersion: tc.max,
+			}}}
+			resp, err := client.Get(ts.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if (err != nil) != tc.wantErr {
+				t.Errorf("GET = %v; want error %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestRedirectHandler(t *testing.T) {
+	tests := []struct {
+		method, host, target string
+		port                 string
+		wantCode             int
+		wantLocation         string
+	}{
+		{http.MethodGet, "example.com", "/books?page=2", "443", http.StatusMovedPermanently, "https://example.com/books?page=2"},
+		{http.MethodGet, "example.com:8080", "/books", "8443", http.StatusMovedPermanently, "https://example.com:8443/books"},
+		{http.MethodHead, "localhost:8080", "/", "8443", http.StatusMovedPermanently, "https://localhost:8443/"},
+		{http.MethodPost, "localhost:8080", "/books", "8443", http.StatusPermanentRedirect, "https://localhost:8443/books"},
+		{http.MethodGet, "[::1]:8080", "/books", "8443", http.StatusMovedPermanently, "https://[::1]:8443/books"},
+		{http.MethodGet, "[::1]", "/books", "443", http.StatusMovedPermanently, "https://[::1]/books"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.method+" "+tc.host+tc.target, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.target, nil)
+			req.Host = tc.host
+			rr := httptest.NewRecorder()
+			redirectHandler(tc.port)(rr, req)
+			if rr.Code != tc.wantCode || rr.Header().Get("Location") != tc.wantLocation {
+				t.Errorf("got %d to %q; want %d to %q", rr.Code, rr.Header().Get("Location"), tc.wantCode, tc.wantLocation)
+			}
+		})
+	}
+}
+
+// TestHTTPSRedirectAndHSTS follows a plain HTTP request through the
+// redirect to a TLS server, which marks its response with HSTS
+func TestHTTPSRedirectAndHSTS(t *testing.T) {
+	ok := func(w http.ResponseWriter, r *http.Request) { io.WriteString(w, r.URL.RequestURI()) }
+	secure := httptest.NewTLSServer(hstsMiddleware(24 * time.Hour)(ok))
+	defer secure.Close()
+	_, port, _ := strings.Cut(strings.TrimPrefix(secure.URL, "https://"), ":")
+	plain := httptest.NewServer(redirectHandler(port))
+	defer plain.Close()
+
+	resp, err := secure.Client().Get(plain.URL + "/books?page=2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.TLS == nil || string(body) != "/books?page=2" {
+		t.Errorf("response over TLS %v with body %q; want /books?page=2 over TLS", resp.TLS != nil, body)
+	}
+	if got, want := resp.Header.Get("Strict-Transport-Security"), "max-age=86400; includeSubDomains"; got != want {
+		t.Errorf("Strict-Transport-Security = %q; want %q", got, want)
+	}
+
+	// Plain HTTP never gets the header
+	rr := httptest.NewRecorder()
+	hstsMiddleware(24*time.Hour)(ok)(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+	if got := rr.Header().Get("Strict-Transport-Security"); got != "" {
+		t.Errorf("Strict-Transport-Security over plain HTTP = %q; want none", got)
+	}
+}
+
+func TestServe_TLS(t *testing.T) {
+	cert, pool := testCert(t)
+	url, cancel, done := startServerTLS(t, func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, r.Proto)
+	}, 5*time.Second, tlsConfig(cert))
+
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{RootCAs: pool, ServerName: "localhost"},
+		ForceAttemptHTTP2: true,
+	}}
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "HTTP/2.0" {
+		t.Errorf("protocol = %q; want HTTP/2.0 offered over TLS", body)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("serve = %v; want nil after shutdown", err)
+	}
+}
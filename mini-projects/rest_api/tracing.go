@@ -0,0 +1,188 @@
+package restapi
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// requestIDHeader carries the request ID in both directions: a client or
+// proxy may send one, and every response echoes the ID used
+const requestIDHeader = "X-Request-ID"
+
+// requestIDKey is the context key for the request ID
+type requestIDKey struct{}
+
+// RequestIDFromContext returns the request ID requestIDMiddleware stored
+// in ctx, or ""
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// newRequestID returns 16 random bytes in hex
+func newRequestID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// validRequestID accepts IDs made of letters, digits and -_.:, up to 128
+// bytes. Anything else is replaced, so a client cannot put newlines or
+// markup into logs through the header.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, c := range []byte(id) {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// requestIDMiddleware keeps the request's X-Request-ID, or makes one up,
+// and puts it in the request context and the response headers. It sets
+// the header before calling next so respondWithError can copy the ID into
+// problem bodies.
+func requestIDMiddleware() Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(requestIDHeader)
+			if !validRequestID(id) {
+				id = newRequestID()
+			}
+			w.Header().Set(requestIDHeader, id)
+			next(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+		}
+	}
+}
+
+// contextHandler adds the request ID and tenant in the context to every record
+// logged with a *Context method, so handlers need no request-scoped logger
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestIDFromContext(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if tenant := TenantFromContext(ctx); tenant != "" {
+		r.AddAttrs(slog.String("tenant", tenant))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// Tracer starts spans: named, timed steps of a request. Start returns a
+// context carrying the new span, so spans started from it become its
+// children, and a function that ends the span.
+//
+// The server runs without a tracer; tests pass a TraceRecorder. A real
+// deployment would plug OpenTelemetry in behind the same two calls.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, func())
+}
+
+// tracerKey is the context key for the request's tracer
+type tracerKey struct{}
+
+// startSpan starts a span with the tracer tracingMiddleware put in ctx. It
+// is a no-op when there is none, so code can be instrumented unconditionally.
+func startSpan(ctx context.Context, name string) (context.Context, func()) {
+	tracer, ok := ctx.Value(tracerKey{}).(Tracer)
+	if !ok {
+		return ctx, func() {}
+	}
+	return tracer.Start(ctx, name)
+}
+
+// spanMiddleware runs next inside a span called name
+func spanMiddleware(name string) Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			ctx, end := startSpan(r.Context(), name)
+			defer end()
+			next(w, r.WithContext(ctx))
+		}
+	}
+}
+
+// tracingMiddleware wraps each request in a span named after its method
+// and path, and makes tracer available to startSpan further down. A nil
+// tracer disables tracing.
+func tracingMiddleware(tracer Tracer) Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		if tracer == nil {
+			return next
+		}
+		return func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), tracerKey{}, tracer)
+			ctx, end := tracer.Start(ctx, r.Method+" "+r.URL.Path)
+			defer end()
+			next(w, r.WithContext(ctx))
+		}
+	}
+}
+
+// Span is one finished span recorded by a TraceRecorder. IDs count up in
+// the order spans started; the root span of a request has Parent 0.
+type Span struct {
+	ID, Parent int
+	Name       string
+	RequestID  string
+	Start, End time.Time
+}
+
+// TraceRecorder is a Tracer that keeps every span in memory, for tests
+type TraceRecorder struct {
+	mu     sync.Mutex
+	nextID int
+	spans  []Span
+}
+
+// spanKey is the context key for the ID of the current span
+type spanKey struct{}
+
+// Start begins a span as a child of the span in ctx, if any
+func (t *TraceRecorder) Start(ctx context.Context, name string) (context.Context, func()) {
+	t.mu.Lock()
+	t.nextID++
+	s := Span{ID: t.nextID, Name: name, RequestID: RequestIDFromContext(ctx), Start: time.Now()}
+	t.mu.Unlock()
+	s.Parent, _ = ctx.Value(spanKey{}).(int)
+
+	return context.WithValue(ctx, spanKey{}, s.ID), func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		s.End = time.Now()
+		t.spans = append(t.spans, s)
+	}
+}
+
+// Spans returns the finished spans in the order they started
+func (t *TraceRecorder) Spans() []Span {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	spans := make([]Span, len(t.spans))
+	copy(spans, t.spans)
+	sort.Slice(spans, func(i, j int) bool { return spans[i].ID < spans[j].ID })
+	return spans
+}
@@ -0,0 +1,124 @@
+package restapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	var seen string
+	handler := requestIDMiddleware()(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestIDFromContext(r.Context())
+	})
+
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{"none sent", "", false},
+		{"kept", "req-42.a:b_c", true},
+		{"newline replaced", "evil\nlog line", false},
+		{"too long replaced", strings.Repeat("a", 129), false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/books", nil)
+			if tc.incoming != "" {
+				req.Header.Set(requestIDHeader, tc.incoming)
+			}
+			rr := httptest.NewRecorder()
+			handler(rr, req)
+
+			got := rr.Header().Get(requestIDHeader)
+			if got != seen {
+				t.Errorf("response header %q differs from context %q", got, seen)
+			}
+			if tc.keep && got != tc.incoming {
+				t.Errorf("request ID = %q; want the incoming %q", got, tc.incoming)
+			}
+			if !tc.keep && (got == tc.incoming || !validRequestID(got)) {
+				t.Errorf("request ID = %q; want a fresh valid ID", got)
+			}
+		})
+	}
+}
+
+func TestRouter_RequestIDInErrorsAndLogs(t *testing.T) {
+	auth, _ := testAuth(t)
+	var logs bytes.Buffer
+	logger := slog.New(contextHandler{slog.NewJSONHandler(&logs, nil)})
+	router := newRouter(NewBookStore(), auth, logger, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/books/999", nil)
+	req.Header.Set(requestIDHeader, "trace-me")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	if got := rr.Header().Get(requestIDHeader); got != "trace-me" {
+		t.Errorf("%s = %q; want trace-me", requestIDHeader, got)
+	}
+	var p Problem
+	if err := json.NewDecoder(rr.Body).Decode(&p); err != nil {
+		t.Fatal(err)
+	}
+	if p.RequestID != "trace-me" {
+		t.Errorf("problem request_id = %q; want trace-me", p.RequestID)
+	}
+
+	var record map[string]any
+	if err := json.Unmarshal(logs.Bytes(), &record); err != nil {
+		t.Fatalf("log output %q: %v", logs.String(), err)
+	}
+	if record["request_id"] != "trace-me" {
+		t.Errorf("log record request_id = %v; want trace-me (record: %v)", record["request_id"], record)
+	}
+}
+
+func TestRouter_SpanOrdering(t *testing.T) {
+	auth, _ := testAuth(t)
+	tracer := &TraceRecorder{}
+	router := newRouter(NewBookStore(), auth, slog.New(slog.NewTextHandler(io.Discard, nil)), tracer, nil, nil, nil, nil, nil, nil, nil)
+
+	rr := login(t, router, `{"username":"alice","password":"wonderland"}`)
+	var lr LoginResponse
+	if err := json.NewDecoder(rr.Body).Decode(&lr); err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodDelete, "/books/1", nil)
+	req.Header.Set("Authorization", "Bearer "+lr.Token)
+	req.Header.Set(requestIDHeader, "del-1")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	type span struct {
+		ID, Parent int
+		Name       string
+	}
+	var got []span
+	for _, s := range tracer.Spans() {
+		got = append(got, span{s.ID, s.Parent, s.Name})
+		if s.End.Before(s.Start) {
+			t.Errorf("span %q ends before it starts", s.Name)
+		}
+	}
+	want := []span{
+		{1, 0, "POST /auth/login"},
+		{2, 1, "handle"},
+		{3, 0, "DELETE /books/1"},
+		{4, 3, "authorize"},
+		{5, 4, "handle"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("spans = %v; want %v", got, want)
+	}
+	if spans := tracer.Spans(); spans[len(spans)-1].RequestID != "del-1" {
+		t.Errorf("span request ID = %q; want del-1", spans[len(spans)-1].RequestID)
+	}
+}
@@ -0,0 +1,182 @@
+package restapi
+
+import (
+	"errors"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/errorsx"
+)
+
+// Tx is a transaction over a BookRepository. Its reads see the books as
+// committed plus its own changes, and its changes are seen by nobody else
+// until Commit applies all of them at once; Rollback discards them. A Tx
+// is for one goroutine, and must not be used after Commit or Rollback.
+//
+// Rollback after Commit does nothing but return errTxDone, so it can be
+// deferred as soon as the transaction begins, as with database/sql.
+type Tx interface {
+	BookRepository
+	Commit() error
+	Rollback() error
+}
+
+var (
+	// errTxDone is returned by Commit or Rollback on a transaction that has
+	// already ended
+	errTxDone = errors.New("transaction has already been committed or rolled back")
+
+	// errTxConflict is returned by Commit when another change got to a
+	// book first: the transaction is rolled back and may be retried
+	errTxConflict = errorsx.New(errorsx.CodeConflict, "Books changed while the transaction was open; try again")
+
+	// errTxNested is returned by Begin on a transaction
+	errTxNested = errors.New("transactions do not nest")
+)
+
+// Begin starts a transaction over the store
+func (bs *BookStore) Begin() (Tx, error) {
+	return bs.begin(), nil
+}
+
+func (bs *BookStore) begin() *bookTx {
+	return &bookTx{store: bs, writes: make(map[int]*Book), base: make(map[int]*Book)}
+}
+
+// bookTx is a copy-on-write transaction over a BookStore. It shares the
+// store's books until it changes one, when it copies the book into writes;
+// Commit then checks none of the books it copied has changed since and
+// applies its copies under the store's lock, which is what makes them
+// appear all at once.
+type bookTx struct {
+	store *BookStore
+
+	// writes holds the books the transaction has changed, by ID, with nil
+	// for a deleted book
+	writes map[int]*Book
+
+	// base holds each book in writes as the store had it when the
+	// transaction first changed it, nil if the store did not have it
+	base map[int]*Book
+
+	done bool
+}
+
+func (t *bookTx) Begin() (Tx, error) {
+	return nil, errTxNested
+}
+
+func (t *bookTx) GetBooks() []Book {
+	committed := t.store.GetBooks()
+	books := make([]Book, 0, len(committed)+len(t.writes))
+	for _, book := range committed {
+		if _, changed := t.writes[book.ID]; !changed {
+			books = append(books, book)
+		}
+	}
+	for _, book := range t.writes {
+		if book != nil {
+			books = append(books, *book)
+		}
+	}
+	return books
+}
+
+func (t *bookTx) GetBook(id int) (Book, bool) {
+	if book, changed := t.writes[id]; changed {
+		if book == nil {
+			return Book{}, false
+		}
+		return *book, true
+	}
+	return t.store.GetBook(id)
+}
+
+// AddBook takes the book's ID from the store now, so concurrent
+// transactions never pick the same one. The IDs of books rolled back are
+// not reused, as with a database sequence.
+func (t *bookTx) AddBook(book Book) int {
+	t.store.Lock()
+	book.ID = t.store.nextID
+	t.store.nextID++
+	t.store.Unlock()
+
+	book.CreatedAt = time.Now()
+	t.writes[book.ID] = &book
+	t.base[book.ID] = nil
+	return book.ID
+}
+
+func (t *bookTx) AddBooks(books []Book) []int {
+	ids := make([]int, len(books))
+	for i, book := range books {
+		ids[i] = t.AddBook(book)
+	}
+	return ids
+}
+
+func (t *bookTx) UpdateBook(id int, book Book) bool {
+	current, ok := t.GetBook(id)
+	if !ok {
+		return false
+	}
+	t.copyBase(id)
+	book.ID = id
+	book.CreatedAt = current.CreatedAt
+	t.writes[id] = &book
+	return true
+}
+
+func (t *bookTx) DeleteBook(id int) bool {
+	if _, ok := t.GetBook(id); !ok {
+		return false
+	}
+	t.copyBase(id)
+	t.writes[id] = nil
+	return true
+}
+
+// copyBase remembers the committed book id before the transaction first
+// changes it, for Commit to check it is still the same
+func (t *bookTx) copyBase(id int) {
+	if _, ok := t.base[id]; ok {
+		return
+	}
+	if book, ok := t.store.GetBook(id); ok {
+		t.base[id] = &book
+	} else {
+		t.base[id] = nil
+	}
+}
+
+func (t *bookTx) Commit() error {
+	if t.done {
+		return errTxDone
+	}
+	t.done = true
+
+	t.store.Lock()
+	defer t.store.Unlock()
+	for id, base := range t.base {
+		current, ok := t.store.books[id]
+		if ok != (base != nil) || (ok && current != *base) {
+			return errTxConflict
+		}
+	}
+	for id, book := range t.writes {
+		if book == nil {
+			delete(t.store.books, id)
+		} else {
+			t.store.books[id] = *book
+		}
+	}
+	return nil
+}
+
+func (t *bookTx) Rollback() error {
+	if t.done {
+		return errTxDone
+	}
+	t.done = true
+	t.writes, t.base = nil, nil
+	return nil
+}
@@ -0,0 +1,178 @@
+package restapi
+
+import (
+	"errors"
+	"slices"
+	"sort"
+	"testing"
+
+	"github.com/rehan/go-interview-prep/pkg/money"
+)
+
+// titles returns the titles of books, ordered by ID
+func titles(books []Book) []string {
+	sort.Slice(books, func(i, j int) bool { return books[i].ID < books[j].ID })
+	var got []string
+	for _, b := range books {
+		got = append(got, b.Title)
+	}
+	return got
+}
+
+func TestBookStoreTx(t *testing.T) {
+	committed := []string{"The Go Programming Language", "Concurrency in Go", "Go in Action"}
+	tests := []struct {
+		name   string
+		commit bool
+		want   []string
+	}{
+		{"commit", true, []string{"Updated", "Go in Action", "Learning Go"}},
+		{"rollback", false, committed},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			store := NewBookStore()
+			tx, err := store.Begin()
+			if err != nil {
+				t.Fatal(err)
+			}
+			id := tx.AddBook(Book{Title: "Learning Go", Author: "Jon Bodner", Price: money.MustParse("29.99")})
+			if !tx.UpdateBook(1, Book{Title: "Updated", Author: "A", Price: money.FromCents(100)}) || !tx.DeleteBook(2) {
+				t.Fatal("changing books 1 and 2 failed")
+			}
+			if tx.UpdateBook(2, Book{Title: "T", Author: "A", Price: 1}) || tx.DeleteBook(2) {
+				t.Error("changed book 2 after deleting it")
+			}
+
+			// The transaction sees its changes; nobody else does yet
+			if got, want := titles(tx.GetBooks()), []string{"Updated", "Go in Action", "Learning Go"}; !slices.Equal(got, want) {
+				t.Errorf("in the transaction: %q; want %q", got, want)
+			}
+			if book, ok := tx.GetBook(id); !ok || book.ID != id || book.CreatedAt.IsZero() {
+				t.Errorf("GetBook(%d) in the transaction = %+v, %v; want the added book", id, book, ok)
+			}
+			if got := titles(store.GetBooks()); !slices.Equal(got, committed) {
+				t.Errorf("outside the transaction: %q; want %q", got, committed)
+			}
+
+			if tc.commit {
+				err = tx.Commit()
+			} else {
+				err = tx.Rollback()
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := titles(store.GetBooks()); !slices.Equal(got, tc.want) {
+				t.Errorf("afterwards: %q; want %q", got, tc.want)
+			}
+			// A rolled back book's ID is not reused
+			if next := store.AddBook(Book{Title: "T", Author: "A", Price: 1}); next != id+1 {
+				t.Errorf("next ID = %d; want %d", next, id+1)
+			}
+		})
+	}
+}
+
+// A transaction that changed a book someone else changed before it
+// committed fails, leaving the other change and none of its own
+func TestBookStoreTx_Conflict(t *testing.T) {
+	store := NewBookStore()
+	tx, _ := store.Begin()
+	tx.AddBook(Book{Title: "Learning Go", Author: "Jon Bodner", Price: 1})
+	tx.UpdateBook(1, Book{Title: "From the transaction", Author: "A", Price: 1})
+	store.UpdateBook(1, Book{Title: "From outside", Author: "A", Price: 1})
+
+	if err := tx.Commit(); !errors.Is(err, errTxConflict) {
+		t.Fatalf("Commit = %v; want errTxConflict", err)
+	}
+	if book, _ := store.GetBook(1); book.Title != "From outside" {
+		t.Errorf("book 1 = %q; want the change made outside", book.Title)
+	}
+	if n := len(store.GetBooks()); n != 3 {
+		t.Errorf("store has %d books; want 3, the added one not committed", n)
+	}
+
+	// Books the transaction did not change may change freely
+	tx, _ = store.Begin()
+	tx.DeleteBook(3)
+	store.UpdateBook(1, Book{Title: "Again", Author: "A", Price: 1})
+	if err := tx.Commit(); err != nil {
+		t.Errorf("Commit = %v; want no conflict", err)
+	}
+}
+
+func TestBookStoreTx_Done(t *testing.T) {
+	tx, _ := NewBookStore().Begin()
+	if _, err := tx.Begin(); !errors.Is(err, errTxNested) {
+		t.Errorf("Begin on a transaction = %v; want errTxNested", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Rollback(); !errors.Is(err, errTxDone) {
+		t.Errorf("Rollback after Commit = %v; want errTxDone", err)
+	}
+	if err := tx.Commit(); !errors.Is(err, errTxDone) {
+		t.Errorf("Commit twice = %v; want errTxDone", err)
+	}
+}
+
+// The outbox hears of a transaction's changes when it commits, and never
+// of one that rolled back
+func TestOutboxTx(t *testing.T) {
+	outbox := NewOutbox()
+	store := outboxRepository{BookRepository: NewBookStore(), outbox: outbox, actor: "user:alice"}
+
+	tx, _ := store.Begin()
+	tx.AddBook(Book{Title: "Rolled back", Author: "A", Price: 1})
+	tx.DeleteBook(1)
+	tx.Rollback()
+
+	tx, _ = store.Begin()
+	id := tx.AddBook(Book{Title: "Learning Go", Author: "A", Price: 1})
+	tx.UpdateBook(2, Book{Title: "Updated", Author: "A", Price: 1})
+	if n := len(outbox.Pending()); n != 0 {
+		t.Fatalf("%d records before Commit; want none", n)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	records := outbox.Pending()
+	if len(records) != 2 {
+		t.Fatalf("%d records; want 2", len(records))
+	}
+	if c := records[0].Change; c.Type != EventBookCreated || c.ID != id || c.After.Title != "Learning Go" || c.Actor != "user:alice" {
+		t.Errorf("first record = %+v; want book %d created by alice", c, id)
+	}
+	if c := records[1].Change; c.Type != EventBookUpdated || c.Before.Title != "Concurrency in Go" || c.After.Title != "Updated" {
+		t.Errorf("second record = %+v; want book 2's update", c)
+	}
+}
+
+func TestCoverDeletingTx(t *testing.T) {
+	covers := NewMemoryCoverStore()
+	covers.PutCover(1, Cover{ContentType: "image/png", Data: pngCover})
+	covers.PutCover(2, Cover{ContentType: "image/png", Data: pngCover})
+	store := coverDeletingRepository{NewBookStore(), covers}
+
+	tx, _ := store.Begin()
+	tx.DeleteBook(1)
+	tx.Rollback()
+	if _, err := covers.Cover(1); err != nil {
+		t.Errorf("cover 1 after Rollback: %v; want it kept", err)
+	}
+
+	tx, _ = store.Begin()
+	tx.DeleteBook(2)
+	if _, err := covers.Cover(2); err != nil {
+		t.Fatalf("cover 2 before Commit: %v; want it kept", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := covers.Cover(2); err == nil {
+		t.Error("cover 2 kept after its book's deletion committed")
+	}
+}
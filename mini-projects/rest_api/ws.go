@@ -0,0 +1,105 @@
+package restapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/errorsx"
+	"github.com/rehan/go-interview-prep/pkg/pubsub"
+	"github.com/rehan/go-interview-prep/pkg/websocket"
+)
+
+// WebSocket keepalive. The server pings every wsPingPeriod; a client that
+// has not answered within two periods is taken to be gone.
+const (
+	wsPingPeriod = 30 * time.Second
+	wsWriteWait  = 10 * time.Second // bounds each write to a slow client
+	wsReadLimit  = 512              // clients have nothing to send but control frames
+)
+
+// WSMessage is what a /ws client receives for each book change: the
+// event as GET /books/events sends it, with the event ID alongside
+type WSMessage struct {
+	EventID uint64 `json:"event_id"`
+	BookEvent
+}
+
+// handleWebSocket handles GET /ws: a WebSocket over which every book
+// change is sent as a JSON text message. Each connection gets a write pump,
+// the handler's own goroutine, which alone writes events and pings, and a
+// read pump, which answers the client's control frames and notices when it
+// goes away. Either pump ending ends the other.
+func handleWebSocket(w http.ResponseWriter, r *http.Request, events *pubsub.Bus[BookEvent], pingPeriod time.Duration) {
+	conn, err := websocket.Upgrade(w, r)
+	if err != nil {
+		var hsErr *websocket.HandshakeError
+		if errors.As(err, &hsErr) {
+			err = errorsx.Wrap(err, errorsx.CodeInvalidArgument, hsErr.Reason)
+		}
+		respondWithError(w, err)
+		return
+	}
+	// The server's write timeout would cut the connection off; the pumps
+	// set their own deadlines instead
+	conn.SetWriteDeadline(time.Time{})
+
+	sub, _ := events.Subscribe(0, sseBuffer)
+	defer sub.Close()
+
+	readDone := make(chan struct{})
+	go wsReadPump(conn, 2*pingPeriod, readDone)
+	wsWritePump(conn, sub, pingPeriod, readDone)
+	conn.Close()
+	<-readDone
+}
+
+// wsReadPump reads until the connection fails, the client closes it or
+// misses a pong, then closes done
+func wsReadPump(conn *websocket.Conn, pongWait time.Duration, done chan<- struct{}) {
+	defer close(done)
+	conn.SetReadLimit(wsReadLimit)
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func([]byte) { conn.SetReadDeadline(time.Now().Add(pongWait)) })
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+// wsWritePump sends events and pings until the read pump stops or the
+// subscription ends. A client dropped for falling behind is told to try
+// again later; on shutdown, that the server is going away.
+func wsWritePump(conn *websocket.Conn, sub *pubsub.Subscription[BookEvent], pingPeriod time.Duration, readDone <-chan struct{}) {
+	ping := time.NewTicker(pingPeriod)
+	defer ping.Stop()
+	for {
+		select {
+		case ev, ok := <-sub.C():
+			if !ok {
+				code, text := websocket.CloseGoingAway, "server shutting down"
+				if sub.Dropped() {
+					code, text = websocket.CloseTryAgainLater, "too far behind"
+				}
+				conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, text), time.Now().Add(wsWriteWait))
+				return
+			}
+			data, err := json.Marshal(WSMessage{EventID: ev.ID, BookEvent: ev.Data})
+			if err != nil {
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				return
+			}
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+				return
+			}
+		case <-readDone:
+			return
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package restapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/websocket"
+)
+
+// dialWS opens a WebSocket to path on srv
+func dialWS(t *testing.T, srv *httptest.Server, path string) *websocket.Conn {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(srv.URL, "http")+path, nil)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	return conn
+}
+
+// readWS reads the next message as a WSMessage
+func readWS(t *testing.T, conn *websocket.Conn) WSMessage {
+	t.Helper()
+	typ, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("ReadMessage: %v", err)
+	}
+	var msg WSMessage
+	if err := json.Unmarshal(data, &msg); typ != websocket.TextMessage || err != nil {
+		t.Fatalf("message = type %d %q; want a JSON text message", typ, data)
+	}
+	return msg
+}
+
+func TestWebSocket_Broadcast(t *testing.T) {
+	srv, _, token := eventServer(t)
+	// Every client gets every change
+	conns := []*websocket.Conn{dialWS(t, srv, "/ws"), dialWS(t, srv, "/ws")}
+
+	send(t, srv, token, http.MethodPost, "/books", `{"title":"Learning Go","author":"Jon Bodner","price":29.99}`)
+	send(t, srv, token, http.MethodDelete, "/books/4", "")
+
+	tests := []struct {
+		eventID uint64
+		typ     string
+		title   string
+	}{
+		{1, EventBookCreated, "Learning Go"},
+		{2, EventBookDeleted, ""},
+	}
+	for i, conn := range conns {
+		for _, tc := range tests {
+			msg := readWS(t, conn)
+			var title string
+			if msg.Book != nil {
+				title = msg.Book.Title
+			}
+			if msg.EventID != tc.eventID || msg.Type != tc.typ || msg.ID != 4 || title != tc.title {
+				t.Errorf("client %d message = %+v; want event %d, %s of book 4 %q", i, msg, tc.eventID, tc.typ, tc.title)
+			}
+		}
+	}
+}
+
+func TestWebSocket_Keepalive(t *testing.T) {
+	const pingPeriod = 50 * time.Millisecond
+	events := newEventBus()
+	defer events.Close()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleWebSocket(w, r, events, pingPeriod)
+	}))
+	defer srv.Close()
+
+	// Reading answers the server's pings, which keeps the connection
+	// open well past the two periods the server waits for a pong
+	live := dialWS(t, srv, "")
+	go func() {
+		time.Sleep(10 * pingPeriod)
+		events.Publish(BookEvent{Type: EventBookDeleted, ID: 1})
+	}()
+	if msg := readWS(t, live); msg.Type != EventBookDeleted {
+		t.Errorf("message = %+v; want the deleted event", msg)
+	}
+
+	// A client that reads nothing sends no pongs, and is hung up on
+	silent := dialWS(t, srv, "")
+	time.Sleep(10 * pingPeriod)
+	for {
+		_, _, err := silent.ReadMessage()
+		if err == nil {
+			continue
+		}
+		var netErr interface{ Timeout() bool }
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			t.Fatal("silent client still connected after 5s")
+		}
+		break
+	}
+}
+
+func TestWebSocket_Shutdown(t *testing.T) {
+	srv, events, _ := eventServer(t)
+	conn := dialWS(t, srv, "/ws")
+	// Whether the handler subscribed before or after, a closed bus ends it
+	events.Close()
+	_, _, err := conn.ReadMessage()
+	var closeErr *websocket.CloseError
+	if !errors.As(err, &closeErr) || closeErr.Code != websocket.CloseGoingAway {
+		t.Errorf("ReadMessage error = %v; want close 1001", err)
+	}
+}
+
+func TestWebSocket_NotUpgrade(t *testing.T) {
+	auth, _ := testAuth(t)
+	router := newRouter(NewBookStore(), auth, slog.New(slog.NewTextHandler(io.Discard, nil)), nil, nil, nil, newEventBus(), nil, nil, nil, nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/ws", nil))
+	if rr.Code != http.StatusBadRequest || rr.Header().Get("Content-Type") != problemContentType {
+		t.Errorf("response = %d, %s; want a 400 problem", rr.Code, rr.Header().Get("Content-Type"))
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	fs := flag.NewFlagSet("thumbnails", flag.ContinueOnError)
+	out := fs.String("out", "thumbnails", "directory to write the thumbnails to, outside the source directory")
+	width := fs.Int("width", 128, "maximum thumbnail width")
+	height := fs.Int("height", 128, "maximum thumbnail height")
+	workers := fs.Int("workers", runtime.NumCPU(), "images processed at once")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: thumbnails [flags] dir")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("want one source directory")
+	}
+	src := fs.Arg(0)
+
+	// Thumbnails written inside the source would be walked and shrunk again
+	absSrc, err := filepath.Abs(src)
+	if err != nil {
+		return err
+	}
+	absOut, err := filepath.Abs(*out)
+	if err != nil {
+		return err
+	}
+	if absOut == absSrc || strings.HasPrefix(absOut, absSrc+string(filepath.Separator)) {
+		return fmt.Errorf("-out %s is inside the source directory %s", *out, src)
+	}
+
+	// Ctrl-C finishes the images in progress and stops
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	start := time.Now()
+	sum, err := Run(ctx, os.DirFS(src), *out, Options{
+		Width: *width, Height: *height, Workers: *workers,
+		Progress: func(p Progress) {
+			total := fmt.Sprint(p.Found)
+			if !p.Walked {
+				total += "+"
+			}
+			fmt.Fprintf(os.Stderr, "\r%d/%s written, %d failed", p.Written, total, p.Failed)
+		},
+	})
+	fmt.Fprintln(os.Stderr)
+	for _, f := range sum.Failures {
+		fmt.Fprintln(os.Stderr, "failed:", f)
+	}
+	fmt.Printf("%d of %d images written to %s in %v\n", sum.Written, sum.Found, *out, time.Since(start).Round(time.Millisecond))
+	return err
+}
+
+/*
+This project demonstrates:
+
+1. A bounded pipeline
+   - A walker, a fixed pool of workers and a collector joined by
+     unbuffered channels, so only Workers images are in memory at once
+   - Closing each channel when its stage ends, and a WaitGroup closing the
+     results once every worker has returned
+   - Progress reported from the collector, the only goroutine touching the
+     tally, so it needs no locks
+
+2. Cancellation
+   - The walk selects on ctx.Done() for every send; workers drain what was
+     already sent without processing it; Run returns ctx.Err() with the
+     summary so far
+
+3. Image processing with the standard library
+   - image.Decode with the JPEG, PNG and GIF decoders registered by import
+   - Nearest-neighbor resizing on the raw RGBA pixels, keeping the aspect
+     ratio and never scaling up
+   - Per-file failures collected instead of stopping the run
+
+4. Testable I/O
+   - The source is an fs.FS, so tests use an fstest.MapFS of images
+     generated in memory
+
+To try it:
+
+go run ./mini-projects/thumbnails -out /tmp/thumbs -width 200 -height 200 ~/Pictures
+*/
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Options configure Run. The zero Options make thumbnails of up to
+// 128×128 on one worker per CPU.
+type Options struct {
+	// Width and Height bound the thumbnails; images are scaled down to
+	// fit, keeping their aspect ratio
+	Width, Height int
+
+	// Workers is the number of images decoded, resized and written at once
+	Workers int
+
+	// Progress, if set, is called after each image, on the goroutine that
+	// called Run, so it needs no locking
+	Progress func(Progress)
+}
+
+// Progress is the state of a run after an image
+type Progress struct {
+	Found   int  // images found so far
+	Walked  bool // whether Found is final
+	Written int
+	Failed  int
+	Path    string // the image just handled
+	Err     error  // why it failed, if it did
+}
+
+// Failure is an image that could not be made into a thumbnail
+type Failure struct {
+	Path string
+	Err  error
+}
+
+func (f Failure) Error() string {
+	return f.Path + ": " + f.Err.Error()
+}
+
+// Summary is the outcome of a run
+type Summary struct {
+	Found    int
+	Written  int
+	Failures []Failure
+}
+
+// isImage reports whether name has the extension of a format the
+// pipeline reads
+func isImage(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".jpg", ".jpeg", ".png", ".gif":
+		return true
+	}
+	return false
+}
+
+// result is what a worker reports for one image
+type result struct {
+	path string
+	err  error
+}
+
+// Run writes a thumbnail of every JPEG, PNG and GIF under src to the same
+// path under dstDir, in the same format. It is a three-stage pipeline:
+//
+//	walk src ──paths──▶ Workers × (decode, resize, encode) ──results──▶ Run
+//
+// The channels between the stages are unbuffered, so the walk stays only
+// a step ahead of the workers and memory holds at most Workers images. An
+// image that fails is reported and the rest carry on. Canceling ctx stops
+// the walk and the workers after the images in progress; Run then returns
+// ctx's error with the summary so far.
+func Run(ctx context.Context, src fs.FS, dstDir string, opts Options) (Summary, error) {
+	if opts.Width <= 0 {
+		opts.Width = 128
+	}
+	if opts.Height <= 0 {
+		opts.Height = 128
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = runtime.NumCPU()
+	}
+
+	// Stage 1: walk src, sending the path of each image
+	paths := make(chan string)
+	var found atomic.Int64
+	walked := make(chan struct{}) // closed once the walk is over
+	var walkErr error
+	go func() {
+		defer close(walked)
+		defer close(paths)
+		walkErr = fs.WalkDir(src, ".", func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || !isImage(p) {
+				return nil
+			}
+			// Counted before it is sent, so Found is never behind the
+			// results
+			found.Add(1)
+			select {
+			case paths <- p:
+				return nil
+			case <-ctx.Done():
+				found.Add(-1)
+				return ctx.Err()
+			}
+		})
+	}()
+
+	// Stage 2: make the thumbnails
+	results := make(chan result)
+	var wg sync.WaitGroup
+	for range opts.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range paths {
+				if ctx.Err() != nil {
+					continue // drain what the walk sent before it stopped
+				}
+				err := thumbnail(src, p, filepath.Join(dstDir, filepath.FromSlash(p)), opts.Width, opts.Height)
+				results <- result{p, err}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Stage 3: tally the results
+	var sum Summary
+	for r := range results {
+		if r.err != nil {
+			sum.Failures = append(sum.Failures, Failure{r.path, r.err})
+		} else {
+			sum.Written++
+		}
+		if opts.Progress != nil {
+			p := Progress{
+				Found:   int(found.Load()),
+				Written: sum.Written,
+				Failed:  len(sum.Failures),
+				Path:    r.path,
+				Err:     r.err,
+			}
+			select {
+			case <-walked:
+				p.Walked = true
+			default:
+			}
+			opts.Progress(p)
+		}
+	}
+	<-walked
+	sum.Found = int(found.Load())
+	if err := ctx.Err(); err != nil {
+		return sum, err
+	}
+	return sum, walkErr
+}
+
+// thumbnail reads the image at name in src and writes it, scaled to fit
+// w×h, to dst
+func thumbnail(src fs.FS, name, dst string, w, h int) error {
+	f, err := src.Open(name)
+	if err != nil {
+		return err
+	}
+	img, format, err := image.Decode(f)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("decoding: %w", err)
+	}
+
+	b := img.Bounds()
+	tw, th := Fit(b.Dx(), b.Dy(), w, h)
+	thumb := Resize(img, tw, th)
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if err := errors.Join(encode(out, thumb, format), out.Close()); err != nil {
+		os.Remove(dst)
+		return fmt.Errorf("writing %s: %w", dst, err)
+	}
+	return nil
+}
+
+// encode writes img in format, one of those image.Decode reports
+func encode(w io.Writer, img image.Image, format string) error {
+	switch format {
+	case "jpeg":
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: 85})
+	case "gif":
+		return gif.Encode(w, img, nil)
+	default:
+		return png.Encode(w, img)
+	}
+}
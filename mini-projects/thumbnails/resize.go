@@ -0,0 +1,58 @@
+package main
+
+import (
+	"image"
+	"image/draw"
+)
+
+// Fit returns the size of an image of size w×h scaled down to fit within
+// maxW×maxH, keeping its aspect ratio. Images that already fit keep their
+// size, as scaling up only adds blur; neither side is ever less than 1.
+func Fit(w, h, maxW, maxH int) (int, int) {
+	if w <= maxW && h <= maxH {
+		return w, h
+	}
+	// Scale by whichever side is further over its bound
+	if w*maxH > h*maxW {
+		return maxW, max(1, h*maxW/w)
+	}
+	return max(1, w*maxH/h), maxH
+}
+
+// Resize returns src scaled to w×h by nearest-neighbor sampling: each
+// destination pixel takes the color of the source pixel under its center.
+// It is the simplest resampling there is, with no dependencies, at the
+// cost of jagged edges that a bilinear or Lanczos filter would smooth.
+func Resize(src image.Image, w, h int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	b := src.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	if sw == 0 || sh == 0 {
+		return dst
+	}
+
+	// Converting to RGBA once makes the sampling loop a copy of four bytes
+	// instead of a color conversion through an interface per pixel
+	rgba, ok := src.(*image.RGBA)
+	if !ok {
+		rgba = image.NewRGBA(b)
+		draw.Draw(rgba, b, src, b.Min, draw.Src)
+	}
+
+	// The source column of each destination column is the same on every
+	// row, so it is worked out once. Pix starts at rgba's Min corner, so
+	// offsets count from there.
+	xs := make([]int, w)
+	for x := range w {
+		xs[x] = (2*x + 1) * sw / (2 * w) * 4
+	}
+	for y := range h {
+		sy := (2*y + 1) * sh / (2 * h)
+		srow := rgba.Pix[sy*rgba.Stride:]
+		drow := dst.Pix[y*dst.Stride:]
+		for x, sx := range xs {
+			copy(drow[x*4:x*4+4], srow[sx:sx+4])
+		}
+	}
+	return dst
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		w, h, maxW, maxH int
+		wantW, wantH     int
+	}{
+		{100, 50, 128, 128, 100, 50},   // fits already
+		{256, 256, 128, 128, 128, 128}, // square
+		{400, 100, 128, 128, 128, 32},  // wide
+		{100, 400, 128, 128, 32, 128},  // tall
+		{300, 200, 100, 50, 75, 50},    // height is further over
+		{10000, 1, 100, 100, 100, 1},   // never below 1
+	}
+	for _, tc := range tests {
+		if w, h := Fit(tc.w, tc.h, tc.maxW, tc.maxH); w != tc.wantW || h != tc.wantH {
+			t.Errorf("Fit(%d, %d, %d, %d) = %d×%d; want %d×%d", tc.w, tc.h, tc.maxW, tc.maxH, w, h, tc.wantW, tc.wantH)
+		}
+	}
+}
+
+// quadrants returns a w×h image whose four quadrants are red, green, blue
+// and white, clockwise from the top left
+func quadrants(w, h int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			c := color.NRGBA{255, 255, 255, 255}
+			switch {
+			case x < w/2 && y < h/2:
+				c = color.NRGBA{255, 0, 0, 255}
+			case y < h/2:
+				c = color.NRGBA{0, 255, 0, 255}
+			case x < w/2:
+				c = color.NRGBA{0, 0, 255, 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestResize(t *testing.T) {
+	// Each pixel of a 2×2 result takes the color of one quadrant
+	got := Resize(quadrants(64, 32), 2, 2)
+	want := []color.RGBA{{255, 0, 0, 255}, {0, 255, 0, 255}, {255, 255, 255, 255}, {0, 0, 255, 255}}
+	for i, p := range []image.Point{{0, 0}, {1, 0}, {1, 1}, {0, 1}} {
+		if c := got.RGBAAt(p.X, p.Y); c != want[i] {
+			t.Errorf("pixel %v = %v; want %v", p, c, want[i])
+		}
+	}
+
+	// A sub-image is sampled within its own bounds: the top right quadrant
+	// is all green
+	sub := image.NewRGBA(image.Rect(0, 0, 64, 32))
+	copy(sub.Pix, Resize(quadrants(64, 32), 64, 32).Pix)
+	got = Resize(sub.SubImage(image.Rect(32, 0, 64, 16)), 4, 2)
+	for y := range 2 {
+		for x := range 4 {
+			if c := got.RGBAAt(x, y); c != (color.RGBA{0, 255, 0, 255}) {
+				t.Fatalf("sub-image pixel (%d,%d) = %v; want green", x, y, c)
+			}
+		}
+	}
+}
+
+func encodeImage(t *testing.T, format string, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case "png":
+		err = png.Encode(&buf, img)
+	case "jpeg":
+		err = jpeg.Encode(&buf, img, nil)
+	case "gif":
+		err = gif.Encode(&buf, img, nil)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// testImages is a source tree of images generated in memory, one of them
+// broken, and a file that is not an image
+func testImages(t *testing.T) fstest.MapFS {
+	return fstest.MapFS{
+		"wide.png":             {Data: encodeImage(t, "png", quadrants(400, 100))},
+		"small.PNG":            {Data: encodeImage(t, "png", quadrants(20, 10))},
+		"albums/tall.jpg":      {Data: encodeImage(t, "jpeg", quadrants(100, 400))},
+		"albums/2024/anim.gif": {Data: encodeImage(t, "gif", quadrants(256, 256))},
+		"albums/broken.jpeg":   {Data: []byte("not really a jpeg")},
+		"notes.txt":            {Data: []byte("ignored")},
+	}
+}
+
+func TestRun(t *testing.T) {
+	dst := t.TempDir()
+	var progress []Progress
+	sum, err := Run(context.Background(), testImages(t), dst, Options{
+		Workers:  3,
+		Progress: func(p Progress) { progress = append(progress, p) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum.Found != 5 || sum.Written != 4 || len(sum.Failures) != 1 || sum.Failures[0].Path != "albums/broken.jpeg" {
+		t.Errorf("summary = %+v; want 4 of 5 written and broken.jpeg failed", sum)
+	}
+
+	wantSizes := map[string][2]int{
+		"wide.png":             {128, 32},
+		"small.PNG":            {20, 10},
+		"albums/tall.jpg":      {32, 128},
+		"albums/2024/anim.gif": {128, 128},
+	}
+	for name, size := range wantSizes {
+		f, err := os.Open(filepath.Join(dst, filepath.FromSlash(name)))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		cfg, format, err := image.DecodeConfig(f)
+		f.Close()
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if cfg.Width != size[0] || cfg.Height != size[1] {
+			t.Errorf("%s is %d×%d; want %d×%d", name, cfg.Width, cfg.Height, size[0], size[1])
+		}
+		if want := map[string]string{".png": "png", ".PNG": "png", ".jpg": "jpeg", ".gif": "gif"}[filepath.Ext(name)]; format != want {
+			t.Errorf("%s written as %s; want %s", name, format, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dst, "albums", "broken.jpeg")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("broken image left a file behind: %v", err)
+	}
+
+	if len(progress) != 5 {
+		t.Fatalf("%d progress reports; want 5", len(progress))
+	}
+	for i, p := range progress {
+		if p.Written+p.Failed != i+1 || p.Found < i+1 {
+			t.Errorf("progress %d = %+v", i, p)
+		}
+	}
+	if last := progress[4]; last.Found != 5 {
+		t.Errorf("last progress = %+v; want all 5 found", last)
+	}
+}
+
+func TestRun_Canceled(t *testing.T) {
+	src := fstest.MapFS{}
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		src[name+".png"] = &fstest.MapFile{Data: encodeImage(t, "png", quadrants(300, 300))}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sum, err := Run(ctx, src, t.TempDir(), Options{
+		Workers:  1,
+		Progress: func(Progress) { cancel() }, // stop after the first image
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Run = %v; want context.Canceled", err)
+	}
+	if sum.Written == 0 || sum.Written >= len(src) {
+		t.Errorf("summary = %+v; want some but not all images written", sum)
+	}
+}
@@ -0,0 +1,255 @@
+// Package benchdiff catches performance regressions. It reads the output
+// of go test -bench -benchmem, keeps the results as a JSON baseline, and
+// compares later runs with the baseline, flagging each benchmark whose
+// time, bytes or allocations per op grew by more than a tolerance:
+//
+//	go test -run='^$' -bench=. -benchmem -count=5 ./basic-concepts/perf/ > new.txt
+//	results, _ := benchdiff.Parse(f)
+//	report := benchdiff.Compare(baseline.Benchmarks, results, benchdiff.Tolerance{Time: 0.10})
+//
+// A benchmark run several times (-count) is summarized by the median of
+// each measure, which one slow run does not move.
+package benchdiff
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Result is a benchmark's measures per op, the medians of its runs
+type Result struct {
+	Name        string  `json:"name"` // package path, space, benchmark name without -GOMAXPROCS
+	Runs        int     `json:"runs"`
+	NsPerOp     float64 `json:"ns_per_op"`
+	BytesPerOp  float64 `json:"bytes_per_op"`
+	AllocsPerOp float64 `json:"allocs_per_op"`
+}
+
+// procsSuffix is the -GOMAXPROCS that go test adds to benchmark names
+// when GOMAXPROCS is not 1
+var procsSuffix = regexp.MustCompile(`-\d+$`)
+
+// Parse reads go test -bench output, which may cover several packages,
+// and returns a Result per benchmark in the order they first ran. Lines
+// that are not results, such as PASS and the goos: header, are skipped.
+// Results without -benchmem have zero bytes and allocations.
+func Parse(r io.Reader) ([]Result, error) {
+	var (
+		pkg   string
+		order []string
+		runs  = make(map[string][][3]float64)
+	)
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text := sc.Text()
+		if p, ok := strings.CutPrefix(text, "pkg: "); ok {
+			pkg = strings.TrimSpace(p)
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) < 4 || !strings.HasPrefix(fields[0], "Benchmark") {
+			continue
+		}
+		if _, err := strconv.Atoi(fields[1]); err != nil {
+			continue // a benchmark's own log line
+		}
+		var m [3]float64
+		for i := 2; i+1 < len(fields); i += 2 {
+			v, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %q is not a measure", line, fields[i])
+			}
+			switch fields[i+1] {
+			case "ns/op":
+				m[0] = v
+			case "B/op":
+				m[1] = v
+			case "allocs/op":
+				m[2] = v
+			}
+		}
+		name := procsSuffix.ReplaceAllString(fields[0], "")
+		if pkg != "" {
+			name = pkg + " " + name
+		}
+		if _, ok := runs[name]; !ok {
+			order = append(order, name)
+		}
+		runs[name] = append(runs[name], m)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, len(order))
+	for i, name := range order {
+		rs := runs[name]
+		results[i] = Result{
+			Name:        name,
+			Runs:        len(rs),
+			NsPerOp:     median(rs, 0),
+			BytesPerOp:  median(rs, 1),
+			AllocsPerOp: median(rs, 2),
+		}
+	}
+	return results, nil
+}
+
+func median(runs [][3]float64, measure int) float64 {
+	vs := make([]float64, len(runs))
+	for i, r := range runs {
+		vs[i] = r[measure]
+	}
+	slices.Sort(vs)
+	if n := len(vs); n%2 == 0 {
+		return (vs[n/2-1] + vs[n/2]) / 2
+	}
+	return vs[len(vs)/2]
+}
+
+// Baseline is a set of results saved to compare later runs with. The
+// platform is kept because times measured on another one say little.
+type Baseline struct {
+	GoVersion  string   `json:"go_version"`
+	GOOS       string   `json:"goos"`
+	GOARCH     string   `json:"goarch"`
+	Benchmarks []Result `json:"benchmarks"`
+}
+
+// NewBaseline returns a baseline of results on this platform
+func NewBaseline(results []Result) Baseline {
+	return Baseline{GoVersion: runtime.Version(), GOOS: runtime.GOOS, GOARCH: runtime.GOARCH, Benchmarks: results}
+}
+
+// ReadBaseline reads a baseline written by WriteBaseline
+func ReadBaseline(path string) (Baseline, error) {
+	var b Baseline
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return b, err
+	}
+	if err := json.Unmarshal(data, &b); err != nil {
+		return b, fmt.Errorf("%s: %w", path, err)
+	}
+	return b, nil
+}
+
+// WriteBaseline writes b to path as indented JSON, to diff in review
+func WriteBaseline(path string, b Baseline) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Tolerance is how much each measure may grow before it is a regression,
+// as a fraction of the baseline: 0.1 allows 10%. Allocations are counted
+// exactly, so their tolerance is usually 0.
+type Tolerance struct {
+	Time, Bytes, Allocs float64
+}
+
+// Delta compares one benchmark's baseline with its new result
+type Delta struct {
+	Old, New    Result
+	Regressions []string // the measures that grew too much, such as "allocs/op"
+}
+
+// Report is the outcome of Compare
+type Report struct {
+	Deltas  []Delta  // benchmarks in both, in the order of the new run
+	Added   []string // benchmarks with no baseline
+	Missing []string // baseline benchmarks that did not run
+}
+
+// Regressed reports whether any benchmark regressed
+func (r Report) Regressed() bool {
+	for _, d := range r.Deltas {
+		if len(d.Regressions) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Compare compares the new results with the baseline ones
+func Compare(baseline, results []Result, tol Tolerance) Report {
+	var r Report
+	old := make(map[string]Result, len(baseline))
+	for _, b := range baseline {
+		old[b.Name] = b
+	}
+	seen := make(map[string]bool, len(results))
+	for _, n := range results {
+		seen[n.Name] = true
+		o, ok := old[n.Name]
+		if !ok {
+			r.Added = append(r.Added, n.Name)
+			continue
+		}
+		d := Delta{Old: o, New: n}
+		for _, m := range []struct {
+			unit     string
+			old, new float64
+			tol      float64
+		}{
+			{"ns/op", o.NsPerOp, n.NsPerOp, tol.Time},
+			{"B/op", o.BytesPerOp, n.BytesPerOp, tol.Bytes},
+			{"allocs/op", o.AllocsPerOp, n.AllocsPerOp, tol.Allocs},
+		} {
+			if m.new > m.old*(1+m.tol) {
+				d.Regressions = append(d.Regressions, m.unit)
+			}
+		}
+		r.Deltas = append(r.Deltas, d)
+	}
+	for _, b := range baseline {
+		if !seen[b.Name] {
+			r.Missing = append(r.Missing, b.Name)
+		}
+	}
+	return r
+}
+
+// Write prints the report, a line per benchmark with its old and new
+// measures, and then the benchmarks added or missing
+func (r Report) Write(w io.Writer) {
+	for _, d := range r.Deltas {
+		status := "ok"
+		if len(d.Regressions) > 0 {
+			status = "REGRESSED: " + strings.Join(d.Regressions, ", ")
+		}
+		fmt.Fprintf(w, "%s\n\t%s  %s  %s  %s\n", d.New.Name,
+			measure("ns/op", d.Old.NsPerOp, d.New.NsPerOp),
+			measure("B/op", d.Old.BytesPerOp, d.New.BytesPerOp),
+			measure("allocs/op", d.Old.AllocsPerOp, d.New.AllocsPerOp),
+			status)
+	}
+	for _, name := range r.Added {
+		fmt.Fprintf(w, "%s\n\tnot in the baseline\n", name)
+	}
+	for _, name := range r.Missing {
+		fmt.Fprintf(w, "%s\n\tin the baseline but did not run\n", name)
+	}
+}
+
+// measure formats a measure's change, such as "ns/op 120 -> 132 (+10.0%)"
+func measure(unit string, old, new float64) string {
+	s := fmt.Sprintf("%s %s -> %s", unit, strconv.FormatFloat(old, 'f', -1, 64), strconv.FormatFloat(new, 'f', -1, 64))
+	switch {
+	case old == new:
+		return s
+	case old == 0:
+		return s + " (new)"
+	}
+	return s + fmt.Sprintf(" (%+.1f%%)", (new-old)/old*100)
+}
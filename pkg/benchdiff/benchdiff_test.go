@@ -0,0 +1,96 @@
+package benchdiff
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const perfPkg = "github.com/rehan/go-interview-prep/basic-concepts/perf "
+
+func TestParse(t *testing.T) {
+	f, err := os.Open("testdata/bench.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	got, err := Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Result{
+		{Name: perfPkg + "BenchmarkConcat/Plus", Runs: 3, NsPerOp: 1200, BytesPerOp: 2048, AllocsPerOp: 19},
+		{Name: perfPkg + "BenchmarkConcat/Builder", Runs: 2, NsPerOp: 160, BytesPerOp: 248, AllocsPerOp: 5},
+		{Name: "github.com/rehan/go-interview-prep/mini-projects/loganalyzer BenchmarkAnalyze", Runs: 1, NsPerOp: 61.5},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestParse_BadMeasure(t *testing.T) {
+	_, err := Parse(strings.NewReader("BenchmarkX-8 100 fast ns/op\n"))
+	if err == nil || err.Error() != `line 1: "fast" is not a measure` {
+		t.Errorf("Parse error = %v; want the bad measure named", err)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	base := []Result{
+		{Name: "A", NsPerOp: 100, BytesPerOp: 64, AllocsPerOp: 1},
+		{Name: "B", NsPerOp: 100, BytesPerOp: 64, AllocsPerOp: 1},
+		{Name: "C", NsPerOp: 100},
+		{Name: "Gone", NsPerOp: 100},
+	}
+	results := []Result{
+		{Name: "A", NsPerOp: 109, BytesPerOp: 64, AllocsPerOp: 1}, // within 10%
+		{Name: "B", NsPerOp: 90, BytesPerOp: 80, AllocsPerOp: 2},
+		{Name: "C", NsPerOp: 111},
+		{Name: "New", NsPerOp: 5},
+	}
+	r := Compare(base, results, Tolerance{Time: 0.10})
+
+	var regressions [][]string
+	for _, d := range r.Deltas {
+		regressions = append(regressions, d.Regressions)
+	}
+	if want := [][]string{nil, {"B/op", "allocs/op"}, {"ns/op"}}; !reflect.DeepEqual(regressions, want) {
+		t.Errorf("regressions = %q; want %q", regressions, want)
+	}
+	if !reflect.DeepEqual(r.Added, []string{"New"}) || !reflect.DeepEqual(r.Missing, []string{"Gone"}) {
+		t.Errorf("Added = %q, Missing = %q; want [New], [Gone]", r.Added, r.Missing)
+	}
+	if !r.Regressed() {
+		t.Error("Regressed() = false; want true")
+	}
+	if Compare(base[:1], results[:1], Tolerance{Time: 0.10}).Regressed() {
+		t.Error("Regressed() = true for A alone, which is within tolerance")
+	}
+
+	var out strings.Builder
+	r.Write(&out)
+	for _, want := range []string{
+		"A\n\tns/op 100 -> 109 (+9.0%)  B/op 64 -> 64  allocs/op 1 -> 1  ok\n",
+		"B\n\tns/op 100 -> 90 (-10.0%)  B/op 64 -> 80 (+25.0%)  allocs/op 1 -> 2 (+100.0%)  REGRESSED: B/op, allocs/op\n",
+		"New\n\tnot in the baseline\n",
+		"Gone\n\tin the baseline but did not run\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("report lacks %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestBaseline_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	b := NewBaseline([]Result{{Name: "A", Runs: 5, NsPerOp: 1.5, BytesPerOp: 8, AllocsPerOp: 1}})
+	if err := WriteBaseline(path, b); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadBaseline(path)
+	if err != nil || !reflect.DeepEqual(got, b) {
+		t.Errorf("ReadBaseline = %+v, %v; want %+v", got, err, b)
+	}
+}
@@ -0,0 +1,200 @@
+// Package clock lets code that reads the time, or waits for it, be tested
+// without sleeping. The code takes a Clock, Real outside tests, and a test
+// gives it a Fake, whose time only moves when the test calls Advance:
+//
+//	c := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+//	b := batcher.New(flush, batcher.WithTimeout(time.Second), batcher.WithClock(c))
+//	b.Add(1)
+//	c.BlockUntil(1)        // the batcher has started its timer
+//	c.Advance(time.Second) // and it fires, flushing [1]
+//
+// BlockUntil is what replaces the sleep: it waits for the goroutine under
+// test to set its timers, after which Advance fires them at once.
+package clock
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// Clock tells the time and makes timers
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is the part of *time.Timer that code waiting on a Clock uses.
+// As with a *time.Timer since Go 1.23, no stale time is received from C
+// after Stop or Reset returns.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// Real is the system clock
+var Real Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+// realTimer adapts *time.Timer, whose C is a field, to Timer
+type realTimer struct {
+	t *time.Timer
+}
+
+func (r realTimer) C() <-chan time.Time        { return r.t.C }
+func (r realTimer) Stop() bool                 { return r.t.Stop() }
+func (r realTimer) Reset(d time.Duration) bool { return r.t.Reset(d) }
+
+// Fake is a Clock whose time is set by the test. Its timers fire during
+// Advance, in the order of their deadlines. It is safe for concurrent use.
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	timers  []*fakeTimer  // waiting to fire
+	changed chan struct{} // closed, and replaced, when timers changes
+}
+
+// NewFake returns a Fake reading now
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now, changed: make(chan struct{})}
+}
+
+// Now returns the fake time
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// NewTimer returns a timer that fires once Advance has moved the time d
+// on, or at once if d <= 0
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t := &fakeTimer{clock: f, c: make(chan time.Time, 1)}
+	f.schedule(t, d)
+	return t
+}
+
+// Advance moves the time on by d, firing the timers it passes. A
+// negative d moves it back, firing none.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	end := f.now.Add(d)
+	for {
+		// Timers fire in deadline order, each at its own time
+		i := slices.IndexFunc(f.timers, func(t *fakeTimer) bool { return !t.when.After(end) })
+		if i < 0 {
+			break
+		}
+		for j, t := range f.timers {
+			if t.when.Before(f.timers[i].when) {
+				i = j
+			}
+		}
+		t := f.timers[i]
+		f.now = t.when
+		f.remove(t)
+		t.fire(f.now)
+	}
+	f.now = end
+}
+
+// Timers returns how many timers are waiting to fire
+func (f *Fake) Timers() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.timers)
+}
+
+// BlockUntil returns once at least n timers are waiting to fire
+func (f *Fake) BlockUntil(n int) {
+	for {
+		f.mu.Lock()
+		waiting, changed := len(f.timers), f.changed
+		f.mu.Unlock()
+		if waiting >= n {
+			return
+		}
+		<-changed
+	}
+}
+
+// schedule sets t to fire d from now; f.mu is held
+func (f *Fake) schedule(t *fakeTimer, d time.Duration) {
+	if d <= 0 {
+		t.fire(f.now)
+		return
+	}
+	t.when = f.now.Add(d)
+	f.timers = append(f.timers, t)
+	f.notify()
+}
+
+// remove takes t off the waiting timers and reports whether it was
+// waiting; f.mu is held
+func (f *Fake) remove(t *fakeTimer) bool {
+	i := slices.Index(f.timers, t)
+	if i < 0 {
+		return false
+	}
+	f.timers = slices.Delete(f.timers, i, i+1)
+	f.notify()
+	return true
+}
+
+// notify wakes BlockUntil; f.mu is held
+func (f *Fake) notify() {
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+type fakeTimer struct {
+	clock *Fake
+	c     chan time.Time
+	when  time.Time // guarded by clock.mu
+}
+
+func (t *fakeTimer) C() <-chan time.Time { return t.c }
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	t.drain()
+	return t.clock.remove(t)
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	t.drain()
+	waiting := t.clock.remove(t)
+	t.clock.schedule(t, d)
+	return waiting
+}
+
+// fire sends now on C, which has room for it as it was drained when the
+// timer was last stopped or reset
+func (t *fakeTimer) fire(now time.Time) {
+	select {
+	case t.c <- now:
+	default:
+	}
+}
+
+// drain takes a time sent but not received off C
+func (t *fakeTimer) drain() {
+	select {
+	case <-t.c:
+	default:
+	}
+}
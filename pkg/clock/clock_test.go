@@ -0,0 +1,112 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+var start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// fired returns the time on c, or the zero time if there is none
+func fired(c <-chan time.Time) time.Time {
+	select {
+	case t := <-c:
+		return t
+	default:
+		return time.Time{}
+	}
+}
+
+func TestFake_Advance(t *testing.T) {
+	c := NewFake(start)
+	late := c.NewTimer(3 * time.Second)
+	early := c.NewTimer(time.Second)
+	later := c.NewTimer(time.Minute)
+
+	c.Advance(999 * time.Millisecond)
+	if got := fired(early.C()); !got.IsZero() {
+		t.Fatalf("timer fired at %v, before its time", got)
+	}
+	c.Advance(5 * time.Second)
+	if got, want := fired(early.C()), start.Add(time.Second); !got.Equal(want) {
+		t.Errorf("early timer fired at %v; want %v", got, want)
+	}
+	if got, want := fired(late.C()), start.Add(3*time.Second); !got.Equal(want) {
+		t.Errorf("late timer fired at %v; want %v", got, want)
+	}
+	if got := fired(later.C()); !got.IsZero() {
+		t.Errorf("minute timer fired at %v", got)
+	}
+	if got, want := c.Now(), start.Add(5999*time.Millisecond); !got.Equal(want) {
+		t.Errorf("Now() = %v; want %v", got, want)
+	}
+	if n := c.Timers(); n != 1 {
+		t.Errorf("%d timers waiting; want the minute one", n)
+	}
+}
+
+func TestFake_StopAndReset(t *testing.T) {
+	c := NewFake(start)
+	tm := c.NewTimer(time.Second)
+	if !tm.Stop() {
+		t.Error("Stop of a waiting timer returned false")
+	}
+	c.Advance(time.Hour)
+	if got := fired(tm.C()); !got.IsZero() {
+		t.Errorf("stopped timer fired at %v", got)
+	}
+	if tm.Stop() {
+		t.Error("second Stop returned true")
+	}
+
+	// A time fired but not received is dropped by Reset
+	tm.Reset(time.Second)
+	c.Advance(time.Second)
+	if tm.Reset(2 * time.Second) {
+		t.Error("Reset of a fired timer returned true")
+	}
+	c.Advance(time.Second)
+	if got := fired(tm.C()); !got.IsZero() {
+		t.Errorf("reset timer fired at %v, a second early or stale", got)
+	}
+	c.Advance(time.Second)
+	if got, want := fired(tm.C()), start.Add(time.Hour+3*time.Second); !got.Equal(want) {
+		t.Errorf("reset timer fired at %v; want %v", got, want)
+	}
+
+	if got := fired(c.NewTimer(0).C()); !got.Equal(c.Now()) {
+		t.Errorf("zero timer fired at %v; want at once", got)
+	}
+}
+
+func TestFake_BlockUntil(t *testing.T) {
+	c := NewFake(start)
+	done := make(chan time.Time)
+	go func() {
+		for range 2 {
+			tm := c.NewTimer(time.Second)
+			<-tm.C()
+		}
+		done <- c.Now()
+	}()
+
+	for range 2 {
+		c.BlockUntil(1)
+		c.Advance(time.Second)
+	}
+	if got, want := <-done, start.Add(2*time.Second); !got.Equal(want) {
+		t.Errorf("goroutine finished at %v; want %v", got, want)
+	}
+}
+
+func TestReal(t *testing.T) {
+	before := time.Now()
+	tm := Real.NewTimer(time.Millisecond)
+	got := <-tm.C()
+	if got.Before(before) || Real.Now().Before(got) {
+		t.Errorf("real timer fired at %v, started at %v", got, before)
+	}
+	if tm.Reset(time.Hour) || !tm.Stop() {
+		t.Error("Reset of a fired timer, then Stop, did not report it fired and then was waiting")
+	}
+}
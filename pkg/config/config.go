@@ -0,0 +1,354 @@
+// Package config loads settings into a struct from several sources. Later
+// sources override earlier ones:
+//
+//  1. the struct's field values before Load is called (the defaults)
+//  2. a JSON or YAML file
+//  3. environment variables
+//  4. command-line flags that were set explicitly
+//
+// Fields take part when they have a `config:"key"` tag:
+//
+//	type Config struct {
+//		Addr    string        `config:"addr" validate:"required"`
+//		Timeout time.Duration `config:"timeout" validate:"min=1"`
+//		Workers int           `config:"workers" flag:"n"`
+//	}
+//
+// The key is used as-is in files, upper-cased after the prefix for
+// environment variables (APP_ADDR), and with underscores turned into hyphens
+// for flags (-log-format), unless a `flag:"name"` tag says otherwise.
+//
+// After loading, the struct is checked with pkg/validator and, if it has a
+// Validate() error method, with that method too.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/validator"
+	"github.com/rehan/go-interview-prep/pkg/yamlx"
+)
+
+// Options selects the sources Load reads
+type Options struct {
+	// File is a .json, .yaml or .yml file; empty means no file
+	File string
+
+	// EnvPrefix is prepended to upper-cased keys, e.g. "BOOKS_" for BOOKS_ADDR
+	EnvPrefix string
+
+	// LookupEnv reads environment variables; nil means os.LookupEnv.
+	// Tests pass a map-backed function instead of mutating the environment.
+	LookupEnv func(string) (string, bool)
+
+	// Flags must already be parsed. Only flags set on the command line
+	// override other sources, so a flag's default never hides a file or
+	// environment value.
+	Flags *flag.FlagSet
+}
+
+// ErrUnknownKey is wrapped by errors for file keys that match no field,
+// which are almost always typos
+var ErrUnknownKey = errors.New("unknown key")
+
+// Validator is implemented by configs with checks beyond struct tags
+type Validator interface {
+	Validate() error
+}
+
+// field is one tagged struct field
+type field struct {
+	key  string
+	flag string
+	v    reflect.Value
+}
+
+// Load fills dst, a pointer to a struct, from the sources in opts
+func Load(dst any, opts Options) error {
+	fields, err := collectFields(dst)
+	if err != nil {
+		return err
+	}
+
+	if opts.File != "" {
+		values, err := readFile(opts.File)
+		if err != nil {
+			return err
+		}
+		for key, raw := range values {
+			f, ok := fields[key]
+			if !ok {
+				return fmt.Errorf("config: %s: %w %q", opts.File, ErrUnknownKey, key)
+			}
+			if err := set(f.v, raw); err != nil {
+				return fmt.Errorf("config: %s: key %q: %w", opts.File, key, err)
+			}
+		}
+	}
+
+	lookup := opts.LookupEnv
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+	for _, f := range fields {
+		name := opts.EnvPrefix + strings.ToUpper(f.key)
+		if raw, ok := lookup(name); ok {
+			if err := set(f.v, raw); err != nil {
+				return fmt.Errorf("config: environment %s: %w", name, err)
+			}
+		}
+	}
+
+	if opts.Flags != nil {
+		byFlag := make(map[string]field, len(fields))
+		for _, f := range fields {
+			byFlag[f.flag] = f
+		}
+		var flagErr error
+		opts.Flags.Visit(func(fl *flag.Flag) {
+			f, ok := byFlag[fl.Name]
+			if !ok || flagErr != nil {
+				return // flags such as -config are not settings
+			}
+			if err := set(f.v, fl.Value.String()); err != nil {
+				flagErr = fmt.Errorf("config: flag -%s: %w", fl.Name, err)
+			}
+		})
+		if flagErr != nil {
+			return flagErr
+		}
+	}
+
+	if err := validator.Struct(dst); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	if v, ok := dst.(Validator); ok {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("config: %w", err)
+		}
+	}
+	return nil
+}
+
+// collectFields maps each config key to its field
+func collectFields(dst any) (map[string]field, error) {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("config: Load needs a non-nil pointer to a struct, got %T", dst)
+	}
+	rv = rv.Elem()
+
+	fields := make(map[string]field)
+	for i := 0; i < rv.NumField(); i++ {
+		sf := rv.Type().Field(i)
+		key := sf.Tag.Get("config")
+		if key == "" || !sf.IsExported() {
+			continue
+		}
+		flagName := sf.Tag.Get("flag")
+		if flagName == "" {
+			flagName = strings.ReplaceAll(key, "_", "-")
+		}
+		fields[key] = field{key: key, flag: flagName, v: rv.Field(i)}
+	}
+	return fields, nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// set parses raw into v according to v's type
+func set(v reflect.Value, raw string) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid bool %q", raw)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid unsigned integer %q", raw)
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %s", v.Type())
+		}
+		var items []string
+		for _, s := range strings.Split(raw, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				items = append(items, s)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// Marshal writes the settings in src, a config struct or a pointer to
+// one, as a file Load reads back to the same values: JSON when ext is
+// ".json", YAML when it is ".yaml" or ".yml". It is how a program shows
+// the configuration it ended up with, or writes a starting file.
+func Marshal(src any, ext string) ([]byte, error) {
+	rv := reflect.ValueOf(src)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("config: Marshal needs a struct or a pointer to one, got %T", src)
+	}
+	copied := reflect.New(rv.Type())
+	copied.Elem().Set(rv)
+	fields, err := collectFields(copied.Interface())
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]any, len(fields))
+	for key, f := range fields {
+		switch {
+		case f.v.Type() == durationType:
+			values[key] = time.Duration(f.v.Int()).String()
+		case f.v.Kind() == reflect.Slice && f.v.Type().Elem().Kind() == reflect.String:
+			items := make([]string, f.v.Len())
+			for i := range items {
+				items[i] = f.v.Index(i).String()
+			}
+			values[key] = strings.Join(items, ",")
+		default:
+			values[key] = f.v.Interface()
+		}
+	}
+
+	switch ext := strings.ToLower(ext); ext {
+	case ".json":
+		data, err := json.MarshalIndent(values, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("config: %w", err)
+		}
+		return append(data, '\n'), nil
+	case ".yaml", ".yml":
+		data, err := yamlx.Marshal(values)
+		if err != nil {
+			return nil, fmt.Errorf("config: %w", err)
+		}
+		return data, nil
+	default:
+		return nil, fmt.Errorf("config: unsupported file type %q", ext)
+	}
+}
+
+// readFile returns the file's keys with their values as strings, so file
+// values go through the same parsing as environment variables and flags
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		values, err := parseJSON(data)
+		if err != nil {
+			return nil, fmt.Errorf("config: %s: %w", path, err)
+		}
+		return values, nil
+	case ".yaml", ".yml":
+		values, err := parseYAML(data)
+		if err != nil {
+			return nil, fmt.Errorf("config: %s: %w", path, err)
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("config: %s: unsupported file type %q", path, ext)
+	}
+}
+
+// parseJSON accepts a flat object of strings, numbers, booleans and arrays
+// of strings
+func parseJSON(data []byte) (map[string]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var raw map[string]any
+	if err := dec.Decode(&raw); err != nil {
+		return nil, err
+	}
+	return flatten(raw)
+}
+
+// parseYAML accepts a mapping of scalars, the subset of YAML pkg/yamlx
+// reads; an empty value is an empty string
+func parseYAML(data []byte) (map[string]string, error) {
+	raw, err := yamlx.ParseMapping(data)
+	if err != nil {
+		return nil, err
+	}
+	return flatten(raw)
+}
+
+// flatten turns decoded file values into the strings environment
+// variables and flags would give
+func flatten(raw map[string]any) (map[string]string, error) {
+	values := make(map[string]string, len(raw))
+	for key, v := range raw {
+		switch v := v.(type) {
+		case nil:
+			values[key] = ""
+		case string:
+			values[key] = v
+		case json.Number:
+			values[key] = v.String()
+		case bool:
+			values[key] = strconv.FormatBool(v)
+		case []any:
+			items := make([]string, len(v))
+			for i, item := range v {
+				s, ok := item.(string)
+				if !ok {
+					return nil, fmt.Errorf("key %q: arrays must hold strings", key)
+				}
+				items[i] = s
+			}
+			values[key] = strings.Join(items, ",")
+		default:
+			return nil, fmt.Errorf("key %q: nested values are not supported", key)
+		}
+	}
+	return values, nil
+}
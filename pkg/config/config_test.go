@@ -0,0 +1,255 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/validator"
+)
+
+type testConfig struct {
+	Addr    string        `config:"addr" validate:"required"`
+	Timeout time.Duration `config:"timeout"`
+	Workers int           `config:"workers" flag:"n" validate:"min=1"`
+	Debug   bool          `config:"debug"`
+	Tags    []string      `config:"tags"`
+	Mode    string        `config:"log_format"`
+}
+
+func (c testConfig) Validate() error {
+	if c.Mode != "json" && c.Mode != "text" {
+		return errors.New(`log_format must be "json" or "text"`)
+	}
+	return nil
+}
+
+func defaults() testConfig {
+	return testConfig{Addr: ":8080", Timeout: time.Second, Workers: 4, Mode: "json"}
+}
+
+// env returns a LookupEnv backed by a map
+func env(vars map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		v, ok := vars[name]
+		return v, ok
+	}
+}
+
+// flags defines the same flags a real program would and parses args
+func flags(t *testing.T, args ...string) *flag.FlagSet {
+	t.Helper()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("addr", ":8080", "")
+	fs.Duration("timeout", time.Second, "")
+	fs.Int("n", 4, "")
+	fs.Bool("debug", false, "")
+	fs.String("log-format", "json", "")
+	fs.String("config", "", "not a setting")
+	if err := fs.Parse(args); err != nil {
+		t.Fatalf("parsing flags: %v", err)
+	}
+	return fs
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	jsonFile := `{"addr": ":9000", "workers": 8, "timeout": "5s"}`
+
+	tests := []struct {
+		name string
+		file string
+		env  map[string]string
+		args []string
+		want func(*testConfig)
+	}{
+		{"defaults only", "", nil, nil, func(*testConfig) {}},
+		{"file overrides defaults", jsonFile, nil, nil, func(c *testConfig) {
+			c.Addr, c.Workers, c.Timeout = ":9000", 8, 5*time.Second
+		}},
+		{"env overrides file", jsonFile, map[string]string{"APP_WORKERS": "16"}, nil, func(c *testConfig) {
+			c.Addr, c.Workers, c.Timeout = ":9000", 16, 5*time.Second
+		}},
+		{"flag overrides env", jsonFile, map[string]string{"APP_WORKERS": "16"}, []string{"-n", "32"}, func(c *testConfig) {
+			c.Addr, c.Workers, c.Timeout = ":9000", 32, 5*time.Second
+		}},
+		{"unset flag keeps env", "", map[string]string{"APP_ADDR": ":7000"}, []string{"-n", "2"}, func(c *testConfig) {
+			c.Addr, c.Workers = ":7000", 2
+		}},
+		{"flag set to its default still wins", "", map[string]string{"APP_ADDR": ":7000"}, []string{"-addr", ":8080"}, func(*testConfig) {}},
+		{"hyphenated flag name", "", map[string]string{"APP_LOG_FORMAT": "json"}, []string{"-log-format", "text"}, func(c *testConfig) {
+			c.Mode = "text"
+		}},
+		{"env of other types", "", map[string]string{"APP_DEBUG": "true", "APP_TAGS": "a, b,,c"}, nil, func(c *testConfig) {
+			c.Debug, c.Tags = true, []string{"a", "b", "c"}
+		}},
+		{"unprefixed env ignored", "", map[string]string{"WORKERS": "99"}, nil, func(*testConfig) {}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := Options{EnvPrefix: "APP_", LookupEnv: env(tc.env), Flags: flags(t, tc.args...)}
+			if tc.file != "" {
+				opts.File = writeFile(t, "config.json", tc.file)
+			}
+
+			got := defaults()
+			if err := Load(&got, opts); err != nil {
+				t.Fatalf("Load returned error: %v", err)
+			}
+			want := defaults()
+			tc.want(&want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Load = %+v; want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestLoad_YAML(t *testing.T) {
+	path := writeFile(t, "config.yaml", `---
+# server settings
+addr: ":9100"
+workers: 3   # inline comment
+debug: true
+tags: x,y
+log_format: 'text'
+`)
+	got := defaults()
+	if err := Load(&got, Options{File: path, LookupEnv: env(nil)}); err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	want := testConfig{Addr: ":9100", Timeout: time.Second, Workers: 3, Debug: true, Tags: []string{"x", "y"}, Mode: "text"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Load = %+v; want %+v", got, want)
+	}
+}
+
+func TestMarshal_RoundTrip(t *testing.T) {
+	in := testConfig{Addr: "localhost:9000", Timeout: 90 * time.Second, Workers: 8, Debug: true, Tags: []string{"blue", "green"}, Mode: "text"}
+	for _, ext := range []string{".json", ".yaml", ".yml"} {
+		t.Run(ext, func(t *testing.T) {
+			data, err := Marshal(&in, ext)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			got := defaults()
+			if err := Load(&got, Options{File: writeFile(t, "config"+ext, string(data)), LookupEnv: env(nil)}); err != nil {
+				t.Fatalf("Load of\n%s: %v", data, err)
+			}
+			if !reflect.DeepEqual(got, in) {
+				t.Errorf("round trip through\n%s= %+v; want %+v", data, got, in)
+			}
+		})
+	}
+
+	data, err := Marshal(defaults(), ".yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `addr: ":8080"
+debug: false
+log_format: json
+tags: ""
+timeout: 1s
+workers: 4
+`
+	if string(data) != want {
+		t.Errorf("Marshal(defaults()) =\n%s; want\n%s", data, want)
+	}
+
+	if _, err := Marshal(in, ".toml"); err == nil || !strings.Contains(err.Error(), "unsupported file type") {
+		t.Errorf("Marshal to .toml: error = %v", err)
+	}
+	if _, err := Marshal("addr", ".json"); err == nil {
+		t.Error("Marshal of a string: want an error")
+	}
+}
+
+func TestLoad_JSONTypes(t *testing.T) {
+	path := writeFile(t, "config.json", `{"debug": true, "tags": ["a", "b"], "workers": 2}`)
+	got := defaults()
+	if err := Load(&got, Options{File: path, LookupEnv: env(nil)}); err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if !got.Debug || !reflect.DeepEqual(got.Tags, []string{"a", "b"}) || got.Workers != 2 {
+		t.Errorf("Load = %+v", got)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name           string
+		file           string
+		content        string
+		env            map[string]string
+		args           []string
+		wantErr        string
+		wantIs         error
+		wantValidation bool
+	}{
+		{name: "unknown file key", file: "c.json", content: `{"adr": ":1"}`, wantErr: `"adr"`, wantIs: ErrUnknownKey},
+		{name: "bad file value", file: "c.json", content: `{"workers": "many"}`, wantErr: `key "workers"`},
+		{name: "nested json", file: "c.json", content: `{"addr": {"host": "x"}}`, wantErr: "nested"},
+		{name: "nested yaml", file: "c.yaml", content: "server:\n  addr: x\n", wantErr: "line 2"},
+		{name: "unsupported file type", file: "c.toml", content: `addr = "x"`, wantErr: "unsupported file type"},
+		{name: "bad env value", env: map[string]string{"APP_TIMEOUT": "soon"}, wantErr: "environment APP_TIMEOUT"},
+		{name: "bad flag value", args: []string{"-log-format", "xml"}, wantErr: "log_format"},
+		{name: "validator rule", env: map[string]string{"APP_WORKERS": "0"}, wantValidation: true},
+		{name: "required", env: map[string]string{"APP_ADDR": ""}, wantErr: "required"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := Options{EnvPrefix: "APP_", LookupEnv: env(tc.env), Flags: flags(t, tc.args...)}
+			if tc.file != "" {
+				opts.File = writeFile(t, tc.file, tc.content)
+			}
+
+			cfg := defaults()
+			err := Load(&cfg, opts)
+			if err == nil {
+				t.Fatalf("Load = %+v; want an error", cfg)
+			}
+			if tc.wantErr != "" && !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("error = %v; want it to contain %q", err, tc.wantErr)
+			}
+			if tc.wantIs != nil && !errors.Is(err, tc.wantIs) {
+				t.Errorf("error = %v; want errors.Is %v", err, tc.wantIs)
+			}
+			if tc.wantValidation {
+				var verrs validator.Errors
+				if !errors.As(err, &verrs) {
+					t.Errorf("error = %v; want validator.Errors", err)
+				}
+			}
+		})
+	}
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	cfg := defaults()
+	err := Load(&cfg, Options{File: filepath.Join(t.TempDir(), "missing.json")})
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("error = %v; want os.ErrNotExist", err)
+	}
+}
+
+func TestLoad_RequiresStructPointer(t *testing.T) {
+	for _, dst := range []any{nil, defaults(), (*testConfig)(nil), new(int)} {
+		if err := Load(dst, Options{}); err == nil {
+			t.Errorf("Load(%T) returned no error", dst)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+// Package coverage reads the profiles go test -coverprofile writes and
+// maps them onto a package's exported functions, to show which of them
+// the tests never run:
+//
+//	go test -coverprofile=cover.out ./concurrency/races/
+//	blocks, _ := coverage.ParseProfile(f)
+//	funcs, _ := coverage.Funcs("concurrency/races", "github.com/rehan/go-interview-prep/concurrency/races", blocks)
+//
+// A profile counts statements in blocks, runs of code with no branches;
+// a function's coverage is the share of the statements in its blocks that
+// ran, as go tool cover -func reports it.
+package coverage
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Block is a line of a profile: a block of statements and how many times
+// it ran, or whether it ran in -covermode=set
+type Block struct {
+	File                string // import path, slash, file name
+	StartLine, StartCol int
+	EndLine, EndCol     int
+	Stmts               int
+	Count               int
+}
+
+// ParseProfile reads a coverage profile. A block listed more than once,
+// as when tests of several packages cover it, is merged with its counts
+// added.
+func ParseProfile(r io.Reader) ([]Block, error) {
+	var blocks []Block
+	index := make(map[Block]int) // block with a zero count -> its position in blocks
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text := sc.Text()
+		if line == 1 {
+			if !strings.HasPrefix(text, "mode: ") {
+				return nil, fmt.Errorf("line 1: want a mode: header, not %q", text)
+			}
+			continue
+		}
+		if text == "" {
+			continue
+		}
+		var b Block
+		// The file name is everything before the last colon, as a path
+		// may hold one
+		i := strings.LastIndexByte(text, ':')
+		if i < 0 {
+			return nil, fmt.Errorf("line %d: no file name in %q", line, text)
+		}
+		b.File = text[:i]
+		_, err := fmt.Sscanf(text[i+1:], "%d.%d,%d.%d %d %d",
+			&b.StartLine, &b.StartCol, &b.EndLine, &b.EndCol, &b.Stmts, &b.Count)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %q is not a block: %v", line, text, err)
+		}
+		key := b
+		key.Count = 0
+		if j, ok := index[key]; ok {
+			blocks[j].Count += b.Count
+			continue
+		}
+		index[key] = len(blocks)
+		blocks = append(blocks, b)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+// Func is an exported function or method and the statements of it that ran
+type Func struct {
+	Name    string // such as Reverse or (*LazyOnce).Get
+	File    string // file name within the package directory
+	Line    int
+	Stmts   int
+	Covered int
+}
+
+// Percent returns the share of the function's statements that ran. A
+// function without statements has nothing to test, and counts as covered.
+func (f Func) Percent() float64 {
+	if f.Stmts == 0 {
+		return 100
+	}
+	return float64(f.Covered) / float64(f.Stmts) * 100
+}
+
+// Funcs parses the Go files of the package in dir, other than its tests
+// and those the build constraints leave out, and returns its exported functions and the methods of its exported
+// types, sorted by file and line, with the statements in each that the
+// blocks cover. importPath is the package's, which the profile's file
+// names start with.
+func Funcs(dir, importPath string, blocks []Block) ([]Func, error) {
+	byFile := make(map[string][]Block)
+	for _, b := range blocks {
+		byFile[b.File] = append(byFile[b.File], b)
+	}
+
+	names, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	var funcs []Func
+	for _, name := range names {
+		base := filepath.Base(name)
+		if strings.HasSuffix(base, "_test.go") {
+			continue
+		}
+		if ok, err := build.Default.MatchFile(dir, base); err != nil || !ok {
+			continue
+		}
+		src, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		file, err := parser.ParseFile(fset, name, src, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil || !exported(fn) {
+				continue
+			}
+			start, end := fset.Position(fn.Pos()), fset.Position(fn.End())
+			f := Func{Name: funcName(fn), File: base, Line: start.Line}
+			for _, b := range byFile[importPath+"/"+base] {
+				if before(b.StartLine, b.StartCol, start.Line, start.Column) || before(end.Line, end.Column, b.EndLine, b.EndCol) {
+					continue
+				}
+				f.Stmts += b.Stmts
+				if b.Count > 0 {
+					f.Covered += b.Stmts
+				}
+			}
+			funcs = append(funcs, f)
+		}
+	}
+	slices.SortFunc(funcs, func(a, b Func) int {
+		return cmp.Or(strings.Compare(a.File, b.File), a.Line-b.Line)
+	})
+	return funcs, nil
+}
+
+// before reports whether line.col a comes before line.col b
+func before(aLine, aCol, bLine, bCol int) bool {
+	return aLine < bLine || aLine == bLine && aCol < bCol
+}
+
+// exported reports whether fn is a function callable from other packages:
+// its name is exported and so, for a method, is its receiver's type
+func exported(fn *ast.FuncDecl) bool {
+	if !fn.Name.IsExported() {
+		return false
+	}
+	if fn.Recv == nil {
+		return true
+	}
+	return ast.IsExported(strings.TrimPrefix(receiver(fn), "*"))
+}
+
+// funcName names fn as go tool cover does: Name, or (T).Name or
+// (*T).Name for a method
+func funcName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil {
+		return fn.Name.Name
+	}
+	return "(" + receiver(fn) + ")." + fn.Name.Name
+}
+
+// receiver returns the type of fn's receiver, T or *T, without type
+// parameters
+func receiver(fn *ast.FuncDecl) string {
+	typ := fn.Recv.List[0].Type
+	star := ""
+	if s, ok := typ.(*ast.StarExpr); ok {
+		star, typ = "*", s.X
+	}
+	switch t := typ.(type) {
+	case *ast.IndexExpr:
+		typ = t.X
+	case *ast.IndexListExpr:
+		typ = t.X
+	}
+	if id, ok := typ.(*ast.Ident); ok {
+		return star + id.Name
+	}
+	return star + "?"
+}
@@ -0,0 +1,100 @@
+package coverage
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testdata/shapes.out is the profile of go test -coverprofile run on
+// testdata/shapes, whose test calls Area(2, 3) only
+const shapesPkg = "github.com/rehan/go-interview-prep/pkg/coverage/testdata/shapes"
+
+func readShapes(t *testing.T) []Block {
+	t.Helper()
+	f, err := os.Open("testdata/shapes.out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	blocks, err := ParseProfile(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return blocks
+}
+
+func TestParseProfile(t *testing.T) {
+	blocks := readShapes(t)
+	if len(blocks) != 6 {
+		t.Fatalf("len(blocks) = %d; want 6", len(blocks))
+	}
+	want := Block{File: shapesPkg + "/shapes.go", StartLine: 7, StartCol: 3, EndLine: 8, EndCol: 1, Stmts: 1}
+	if blocks[1] != want {
+		t.Errorf("blocks[1] = %+v; want %+v", blocks[1], want)
+	}
+}
+
+func TestParseProfile_MergesRepeatedBlocks(t *testing.T) {
+	profile := "mode: count\n" +
+		"a/b.go:3.2,4.10 2 1\n" +
+		"a/b.go:6.2,6.9 1 0\n" +
+		"a/b.go:3.2,4.10 2 4\n"
+	blocks, err := ParseProfile(strings.NewReader(profile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) != 2 || blocks[0].Count != 5 {
+		t.Errorf("blocks = %+v; want 2, the first counted 5 times", blocks)
+	}
+}
+
+func TestParseProfile_Errors(t *testing.T) {
+	tests := []struct {
+		profile string
+		want    string
+	}{
+		{"a/b.go:1.1,2.2 1 1\n", "line 1: want a mode: header"},
+		{"mode: set\nno colon here\n", "line 2: no file name"},
+		{"mode: set\na/b.go:1.1 1 1\n", `line 2: "a/b.go:1.1 1 1" is not a block`},
+	}
+	for _, tc := range tests {
+		_, err := ParseProfile(strings.NewReader(tc.profile))
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("ParseProfile(%q) error = %v; want %q", tc.profile, err, tc.want)
+		}
+	}
+}
+
+func TestFuncs(t *testing.T) {
+	funcs, err := Funcs("testdata/shapes", shapesPkg, readShapes(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// helper and circle.Diameter are not exported, nor is Windows built
+	// here; the test file is skipped
+	want := []Func{
+		{Name: "Area", File: "shapes.go", Line: 5, Stmts: 3, Covered: 2},
+		{Name: "(*Square).Perimeter", File: "shapes.go", Line: 16, Stmts: 1, Covered: 0},
+	}
+	if !reflect.DeepEqual(funcs, want) {
+		t.Errorf("Funcs =\n%+v\nwant\n%+v", funcs, want)
+	}
+}
+
+func TestFunc_Percent(t *testing.T) {
+	tests := []struct {
+		f    Func
+		want float64
+	}{
+		{Func{Stmts: 4, Covered: 1}, 25},
+		{Func{Stmts: 3, Covered: 3}, 100},
+		{Func{}, 100},
+	}
+	for _, tc := range tests {
+		if got := tc.f.Percent(); got != tc.want {
+			t.Errorf("%+v.Percent() = %v; want %v", tc.f, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,4 @@
+package shapes
+
+// Windows is left out by its file name's build constraint
+func Windows() {}
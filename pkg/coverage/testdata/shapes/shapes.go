@@ -0,0 +1,25 @@
+// Package shapes is a fixture for the coverage tests
+package shapes
+
+// Area returns the area of a w by h rectangle, or 0 if either is negative
+func Area(w, h int) int {
+	if w < 0 || h < 0 {
+		return 0
+	}
+	return w * h
+}
+
+// Square is a square with sides of length Side
+type Square struct{ Side int }
+
+// Perimeter returns the length of the square's edge
+func (s *Square) Perimeter() int {
+	return 4 * s.Side
+}
+
+func helper() int { return 1 }
+
+type circle struct{ r int }
+
+// Diameter is exported but its type is not
+func (c circle) Diameter() int { return 2 * c.r }
@@ -0,0 +1,9 @@
+package shapes
+
+import "testing"
+
+func TestArea(t *testing.T) {
+	if got := Area(2, 3); got != 6 {
+		t.Errorf("Area(2, 3) = %d; want 6", got)
+	}
+}
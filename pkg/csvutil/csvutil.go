@@ -0,0 +1,355 @@
+// Package csvutil maps the rows of a CSV file with a header to structs by
+// their csv tags, a row at a time, so a file of any size is read or
+// written in constant memory:
+//
+//	type Book struct {
+//		Title string       `csv:"title,required"`
+//		Price money.Amount `csv:"price"`
+//		Notes string       `csv:"-"`
+//	}
+//
+//	r, err := csvutil.NewReader[Book](file)
+//	for book, err := range r.All() { ... }
+//
+// Columns are matched to tags by name, ignoring case and surrounding
+// spaces, in whatever order the header has them; columns with no field
+// are ignored, and "required" makes a column's absence an error. Untagged
+// fields are left alone. Fields may be strings, booleans, integers,
+// floats, time.Time (RFC 3339, written to the second) or types with
+// MarshalText and UnmarshalText methods, or pointers to any of these. An
+// empty cell leaves a field at its zero value, so presence checks belong
+// to validation after reading.
+//
+// A row that cannot be read into a struct, because it has the wrong
+// number of fields or a cell does not parse, is a *RowError and reading
+// goes on; anything else, such as broken quoting, stops it.
+package csvutil
+
+import (
+	"encoding"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNoHeader is returned by NewReader for input with no rows at all
+var ErrNoHeader = errors.New("csvutil: no header row")
+
+// MissingColumnsError is a header without some of the required columns
+type MissingColumnsError struct {
+	Columns []string
+}
+
+func (e *MissingColumnsError) Error() string {
+	return "csvutil: header has no " + strings.Join(e.Columns, ", ") + " column"
+}
+
+// RowError is one row that could not be read into a struct. Line is the
+// row's line in the file, counting the header as line 1, so it matches
+// what a spreadsheet shows; Column is empty when the row as a whole is
+// wrong.
+type RowError struct {
+	Line   int
+	Column string
+	Err    error
+}
+
+func (e *RowError) Error() string {
+	if e.Column == "" {
+		return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+	}
+	return fmt.Sprintf("line %d: column %s: %v", e.Line, e.Column, e.Err)
+}
+
+func (e *RowError) Unwrap() error { return e.Err }
+
+// field is a tagged struct field
+type field struct {
+	name     string
+	index    int
+	required bool
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// fieldsOf returns the tagged fields of the struct type T, in declaration
+// order. A struct tagged only for JSON does not grow CSV columns by
+// accident.
+func fieldsOf[T any]() []field {
+	t := reflect.TypeFor[T]()
+	if t.Kind() != reflect.Struct {
+		panic("csvutil: " + t.String() + " is not a struct")
+	}
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, ok := sf.Tag.Lookup("csv")
+		if !ok || tag == "-" || !sf.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = sf.Name
+		}
+		fields = append(fields, field{name: name, index: i, required: opts == "required"})
+	}
+	return fields
+}
+
+// Reader reads structs of type T from the rows of a CSV file
+type Reader[T any] struct {
+	cr      *csv.Reader
+	header  []string
+	columns []int // the column of each field of fields, -1 if absent
+	fields  []field
+}
+
+// NewReader reads the header row of r and matches its columns to the
+// tagged fields of T, which must be a struct type. Leading spaces of
+// cells are trimmed.
+func NewReader[T any](r io.Reader) (*Reader[T], error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, ErrNoHeader
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	rd := &Reader[T]{cr: cr, header: header, fields: fieldsOf[T]()}
+	var missing []string
+	for _, f := range rd.fields {
+		col, ok := index[strings.ToLower(f.name)]
+		if !ok {
+			col = -1
+			if f.required {
+				missing = append(missing, f.name)
+			}
+		}
+		rd.columns = append(rd.columns, col)
+	}
+	if missing != nil {
+		return nil, &MissingColumnsError{Columns: missing}
+	}
+	return rd, nil
+}
+
+// Header returns the header row as read
+func (r *Reader[T]) Header() []string {
+	return r.header
+}
+
+// Line returns the line in the file that the row last read starts on,
+// counting the header as line 1
+func (r *Reader[T]) Line() int {
+	line, _ := r.cr.FieldPos(0)
+	return line
+}
+
+// Read returns the next row as a T. At the end of the input it returns
+// io.EOF. A *RowError leaves the reader at the next row; after any other
+// error it should not be used again.
+func (r *Reader[T]) Read() (T, error) {
+	var v T
+	record, err := r.cr.Read()
+	if err != nil {
+		// The reader can go on after a row with the wrong number of
+		// fields; other parse errors leave it lost
+		if errors.Is(err, csv.ErrFieldCount) {
+			err = &RowError{Line: r.Line(), Err: fmt.Errorf("row has %d fields; the header has %d", len(record), len(r.header))}
+		}
+		return v, err
+	}
+
+	rv := reflect.ValueOf(&v).Elem()
+	for i, f := range r.fields {
+		col := r.columns[i]
+		if col < 0 || record[col] == "" {
+			continue
+		}
+		if err := setField(rv.Field(f.index), record[col]); err != nil {
+			line, _ := r.cr.FieldPos(col)
+			return v, &RowError{Line: line, Column: f.name, Err: err}
+		}
+	}
+	return v, nil
+}
+
+// All iterates over the remaining rows. Each *RowError is yielded with a
+// zero T and iteration goes on; any other error is yielded last.
+func (r *Reader[T]) All() iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for {
+			v, err := r.Read()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			var rowErr *RowError
+			if !yield(v, err) || err != nil && !errors.As(err, &rowErr) {
+				return
+			}
+		}
+	}
+}
+
+// setField parses s into v
+func setField(v reflect.Value, s string) error {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	if v.Type() == timeType {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", s)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", s)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid unsigned integer %q", s)
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid number %q", s)
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported field type %s", v.Type())
+	}
+	return nil
+}
+
+// Writer writes structs of type T as the rows of a CSV file, after a
+// header row naming the tagged fields
+type Writer[T any] struct {
+	cw          *csv.Writer
+	fields      []field
+	record      []string
+	wroteHeader bool
+}
+
+// NewWriter returns a Writer to w. T must be a struct type.
+func NewWriter[T any](w io.Writer) *Writer[T] {
+	fields := fieldsOf[T]()
+	return &Writer[T]{cw: csv.NewWriter(w), fields: fields, record: make([]string, len(fields))}
+}
+
+// Write writes v as a row, and the header first if this is the first row.
+// Rows are buffered; Flush writes them out.
+func (w *Writer[T]) Write(v T) error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	rv := reflect.ValueOf(v)
+	for i, f := range w.fields {
+		s, err := formatField(rv.Field(f.index))
+		if err != nil {
+			return fmt.Errorf("csvutil: %s: %w", f.name, err)
+		}
+		w.record[i] = s
+	}
+	return w.cw.Write(w.record)
+}
+
+func (w *Writer[T]) writeHeader() error {
+	if w.wroteHeader {
+		return nil
+	}
+	w.wroteHeader = true
+	for i, f := range w.fields {
+		w.record[i] = f.name
+	}
+	return w.cw.Write(w.record)
+}
+
+// Flush writes out the buffered rows, and the header if no row has been
+// written, so that no rows is still a file with columns. It returns the
+// first error writing has met.
+func (w *Writer[T]) Flush() error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	w.cw.Flush()
+	return w.cw.Error()
+}
+
+// WriteAll writes a header and every row of rows to w
+func WriteAll[T any](w io.Writer, rows iter.Seq[T]) error {
+	cw := NewWriter[T](w)
+	for v := range rows {
+		if err := cw.Write(v); err != nil {
+			return err
+		}
+	}
+	return cw.Flush()
+}
+
+// formatField is the cell for v; a nil pointer is an empty one
+func formatField(v reflect.Value) (string, error) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+	if v.Type() == timeType {
+		return v.Interface().(time.Time).Format(time.RFC3339), nil
+	}
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		b, err := m.MarshalText()
+		return string(b), err
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits()), nil
+	default:
+		return "", fmt.Errorf("unsupported field type %s", v.Type())
+	}
+}
@@ -0,0 +1,208 @@
+package csvutil
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/money"
+)
+
+type item struct {
+	ID      int          `csv:"id"`
+	Name    string       `csv:"name,required"`
+	Price   money.Amount `csv:"price,required"`
+	InStock bool         `csv:"in_stock"`
+	Weight  float64      `csv:"weight_kg"`
+	Added   time.Time    `csv:"added"`
+	Parent  *uint8       `csv:"parent"`
+	Note    string       // untagged: not a column
+	Secret  string       `csv:"-"`
+}
+
+func collect[T any](t *testing.T, r *Reader[T]) ([]T, []*RowError, error) {
+	t.Helper()
+	var rows []T
+	var rowErrs []*RowError
+	for v, err := range r.All() {
+		var rowErr *RowError
+		switch {
+		case errors.As(err, &rowErr):
+			rowErrs = append(rowErrs, rowErr)
+		case err != nil:
+			return rows, rowErrs, err
+		default:
+			rows = append(rows, v)
+		}
+	}
+	return rows, rowErrs, nil
+}
+
+func TestRoundTrip(t *testing.T) {
+	seven := uint8(7)
+	items := []item{
+		{ID: 1, Name: "Pen", Price: money.MustParse("1.50"), InStock: true, Weight: 0.02, Added: time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC), Parent: &seven},
+		{ID: 2, Name: `Quotes "and", commas`, Price: money.MustParse("-5"), Added: time.Date(2024, 3, 1, 11, 30, 0, 0, time.FixedZone("", 2*60*60))},
+		{ID: 3, Name: "Line\nbreak", Price: money.MustParse("1000000.01"), Weight: 1e6},
+	}
+	var buf bytes.Buffer
+	if err := WriteAll(&buf, slices.Values(items)); err != nil {
+		t.Fatal(err)
+	}
+	wantHead := "id,name,price,in_stock,weight_kg,added,parent\n1,Pen,1.50,true,0.02,2024-03-01T09:30:00Z,7\n"
+	if !strings.HasPrefix(buf.String(), wantHead) {
+		t.Errorf("output starts\n%s\nwant\n%s", buf.String(), wantHead)
+	}
+	if strings.Contains(buf.String(), "1e+06") {
+		t.Errorf("output has an exponent: %s", buf.String())
+	}
+
+	// One byte at a time, as a slow upload arrives
+	r, err := NewReader[item](iotest.OneByteReader(&buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, rowErrs, err := collect(t, r)
+	if err != nil || rowErrs != nil {
+		t.Fatalf("errors = %v, %v", rowErrs, err)
+	}
+	if len(got) != len(items) {
+		t.Fatalf("read %d rows; want %d", len(got), len(items))
+	}
+	for i := range items {
+		if !got[i].Added.Equal(items[i].Added) {
+			t.Errorf("row %d added = %v; want %v", i, got[i].Added, items[i].Added)
+		}
+		got[i].Added = items[i].Added
+	}
+	if !reflect.DeepEqual(got, items) {
+		t.Errorf("got %+v\nwant %+v", got, items)
+	}
+}
+
+func TestReader_Header(t *testing.T) {
+	// Columns in any order and case, extra ones ignored, optional ones
+	// absent
+	r, err := NewReader[item](strings.NewReader(" PRICE ,Name,colour\n3,Cup,red\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, rowErrs, err := collect(t, r)
+	if err != nil || rowErrs != nil {
+		t.Fatalf("errors = %v, %v", rowErrs, err)
+	}
+	want := []item{{Name: "Cup", Price: money.MustParse("3")}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v; want %+v", got, want)
+	}
+	if h := r.Header(); !reflect.DeepEqual(h, []string{"PRICE ", "Name", "colour"}) {
+		t.Errorf("Header() = %q", h)
+	}
+
+	_, err = NewReader[item](strings.NewReader("id,name\n1,Pen\n"))
+	var missing *MissingColumnsError
+	if !errors.As(err, &missing) || !reflect.DeepEqual(missing.Columns, []string{"price"}) {
+		t.Errorf("error = %v; want price missing", err)
+	}
+	if _, err := NewReader[item](strings.NewReader("")); !errors.Is(err, ErrNoHeader) {
+		t.Errorf("empty input: error = %v; want ErrNoHeader", err)
+	}
+}
+
+func TestReader_MalformedRows(t *testing.T) {
+	input := "name,price,id,in_stock,parent,added\n" +
+		"Good,1,,,,\n" + // line 2
+		"Short,2\n" + // line 3: too few fields
+		"Bad price,two,,,,\n" + // 4
+		"Bad id,1,x,,,\n" + // 5
+		"Bad bool,1,,maybe,,\n" + // 6
+		"Overflow,1,,,300,\n" + // 7
+		"Bad time,1,,,,yesterday\n" + // 8
+		"\"Multi\nline\",1,,,,\n" + // 9-10
+		"Too,many,1,,,,\n" + // 11
+		"Last,3,,,,\n" // 12
+	r, err := NewReader[item](strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, rowErrs, err := collect(t, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, v := range got {
+		names = append(names, v.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"Good", "Multi\nline", "Last"}) {
+		t.Errorf("rows read = %q; want Good, Multi\\nline and Last", names)
+	}
+
+	var errs []string
+	for _, re := range rowErrs {
+		errs = append(errs, fmt.Sprintf("%d %s", re.Line, re.Column))
+	}
+	want := []string{"3 ", "4 price", "5 id", "6 in_stock", "7 parent", "8 added", "11 "}
+	if !reflect.DeepEqual(errs, want) {
+		t.Errorf("row errors at %q; want %q", errs, want)
+	}
+	if msg := rowErrs[0].Error(); msg != "line 3: row has 2 fields; the header has 6" {
+		t.Errorf("field count error = %q", msg)
+	}
+	if msg := rowErrs[1].Error(); msg != `line 4: column price: money: invalid amount: "two"` {
+		t.Errorf("cell error = %q", msg)
+	}
+}
+
+func TestReader_FatalErrors(t *testing.T) {
+	t.Run("broken quoting stops reading", func(t *testing.T) {
+		r, err := NewReader[item](strings.NewReader("name,price\nA,1\n\"B,2\nC,3\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, _, err := collect(t, r)
+		if len(got) != 1 || err == nil {
+			t.Errorf("read %d rows, error %v; want 1 row then a parse error", len(got), err)
+		}
+	})
+
+	t.Run("read error stops reading", func(t *testing.T) {
+		boom := errors.New("connection reset")
+		src := io.MultiReader(strings.NewReader("name,price\nA,1\n"), iotest.ErrReader(boom))
+		r, err := NewReader[item](iotest.HalfReader(src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, _, err := collect(t, r)
+		if len(got) != 1 || !errors.Is(err, boom) {
+			t.Errorf("read %d rows, error %v; want 1 row then %v", len(got), err, boom)
+		}
+	})
+
+	t.Run("break stops early", func(t *testing.T) {
+		r, _ := NewReader[item](strings.NewReader("name,price\nA,1\nB,2\nC,3\n"))
+		for range r.All() {
+			break
+		}
+		if v, err := r.Read(); err != nil || v.Name != "B" {
+			t.Errorf("after break, Read() = %+v, %v; want row B", v, err)
+		}
+	})
+}
+
+func TestWriter_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter[item](&buf)
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if want := "id,name,price,in_stock,weight_kg,added,parent\n"; buf.String() != want {
+		t.Errorf("no rows wrote %q; want only the header", buf.String())
+	}
+}
@@ -0,0 +1,93 @@
+// Package assert documents conditions that must always hold, as checks
+// that can run:
+//
+//	func mergeInPlace(arr []int, mid, s, e int) {
+//		assert.Require(s <= mid && mid <= e, "bounds s=%d mid=%d e=%d", s, mid, e)
+//		...
+//	}
+//
+//	func (q *Queue) addElement(val int) {
+//		...
+//		assert.Invariant("queue links", q.checkLinks)
+//	}
+//
+// Checks are off by default, where each costs a function call and a
+// branch. They are on in binaries built with -tags assert, when GOASSERT is
+// set to a true value (1, t, true) at start-up, or after SetEnabled(true),
+// which tests typically call from TestMain. A failed check panics with a
+// *Failure: it marks a bug, not an error to handle.
+package assert
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"sync/atomic"
+)
+
+// EnvVar is the environment variable that enables checks at start-up
+const EnvVar = "GOASSERT"
+
+var enabled atomic.Bool
+
+func init() {
+	on, _ := strconv.ParseBool(os.Getenv(EnvVar))
+	enabled.Store(builtWithTag || on)
+}
+
+// Enabled reports whether checks run
+func Enabled() bool {
+	return enabled.Load()
+}
+
+// SetEnabled turns checks on or off and returns the previous setting
+func SetEnabled(on bool) bool {
+	return enabled.Swap(on)
+}
+
+// Kind says which helper a failure came from
+type Kind string
+
+const (
+	KindAssert    Kind = "assertion"
+	KindRequire   Kind = "precondition"
+	KindInvariant Kind = "invariant"
+)
+
+// Failure is the panic value of a failed check
+type Failure struct {
+	Kind    Kind
+	Message string
+}
+
+func (f *Failure) Error() string {
+	return fmt.Sprintf("%s failed: %s", f.Kind, f.Message)
+}
+
+// Assert panics if cond is false. Use it for conditions the surrounding
+// code guarantees, such as an index the loop above keeps in range.
+func Assert(cond bool, format string, args ...any) {
+	if enabled.Load() && !cond {
+		panic(&Failure{Kind: KindAssert, Message: fmt.Sprintf(format, args...)})
+	}
+}
+
+// Require panics if cond is false. Use it for preconditions a caller must
+// meet, such as a non-empty slice.
+func Require(cond bool, format string, args ...any) {
+	if enabled.Load() && !cond {
+		panic(&Failure{Kind: KindRequire, Message: fmt.Sprintf(format, args...)})
+	}
+}
+
+// Invariant runs check and panics if it returns an error. check is only
+// called while checks are enabled, so it may be as expensive as walking a
+// whole data structure.
+func Invariant(name string, check func() error) {
+	if !enabled.Load() {
+		return
+	}
+	if err := check(); err != nil {
+		panic(&Failure{Kind: KindInvariant, Message: name + ": " + err.Error()})
+	}
+}
@@ -0,0 +1,101 @@
+package assert
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// failure runs fn and returns the *Failure it panicked with, or nil
+func failure(t *testing.T, fn func()) (f *Failure) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r != nil {
+			var ok bool
+			if f, ok = r.(*Failure); !ok {
+				t.Fatalf("panic value = %#v; want *Failure", r)
+			}
+		}
+	}()
+	fn()
+	return nil
+}
+
+func TestChecks(t *testing.T) {
+	defer SetEnabled(SetEnabled(true))
+
+	errBroken := errors.New("broken")
+	tests := []struct {
+		name    string
+		fn      func()
+		wantErr string
+	}{
+		{"assert holds", func() { Assert(true, "unused") }, ""},
+		{"assert fails", func() { Assert(1 > 2, "1 > 2 with %d", 3) }, "assertion failed: 1 > 2 with 3"},
+		{"require holds", func() { Require(true, "unused") }, ""},
+		{"require fails", func() { Require(false, "need %s", "input") }, "precondition failed: need input"},
+		{"invariant holds", func() { Invariant("ok", func() error { return nil }) }, ""},
+		{"invariant fails", func() { Invariant("links", func() error { return errBroken }) }, "invariant failed: links: broken"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			f := failure(t, tc.fn)
+			got := ""
+			if f != nil {
+				got = f.Error()
+			}
+			if got != tc.wantErr {
+				t.Errorf("failure = %q; want %q", got, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestDisabledChecksDoNothing(t *testing.T) {
+	defer SetEnabled(SetEnabled(false))
+
+	called := false
+	f := failure(t, func() {
+		Assert(false, "x")
+		Require(false, "x")
+		Invariant("x", func() error {
+			called = true
+			return errors.New("x")
+		})
+	})
+	if f != nil {
+		t.Errorf("disabled checks panicked with %v", f)
+	}
+	if called {
+		t.Error("Invariant ran its check while disabled")
+	}
+}
+
+func TestSetEnabled(t *testing.T) {
+	orig := SetEnabled(true)
+	defer SetEnabled(orig)
+
+	if !Enabled() {
+		t.Error("Enabled() = false after SetEnabled(true)")
+	}
+	if prev := SetEnabled(false); !prev {
+		t.Error("SetEnabled did not return the previous setting")
+	}
+	if Enabled() {
+		t.Error("Enabled() = true after SetEnabled(false)")
+	}
+}
+
+func ExampleInvariant() {
+	defer SetEnabled(SetEnabled(true))
+	defer func() { fmt.Println(recover()) }()
+
+	balance := -5
+	Invariant("non-negative balance", func() error {
+		if balance < 0 {
+			return fmt.Errorf("balance is %d", balance)
+		}
+		return nil
+	})
+	// Output: invariant failed: non-negative balance: balance is -5
+}
@@ -0,0 +1,5 @@
+//go:build !assert
+
+package assert
+
+const builtWithTag = false
@@ -0,0 +1,6 @@
+//go:build assert
+
+package assert
+
+// builtWithTag is set by building with -tags assert
+const builtWithTag = true
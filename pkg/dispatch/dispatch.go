@@ -0,0 +1,241 @@
+// Package dispatch delivers events to handlers asynchronously, at least
+// once and in order. Each handler has its own queue and goroutine, so a
+// slow or failing handler only holds up its own events:
+//
+//	d := dispatch.New[OrderPlaced](dispatch.Options{MaxAttempts: 5})
+//	d.Subscribe("receipts", sendReceipt)
+//	d.Subscribe("stock", reserveItems)
+//	d.Dispatch(OrderPlaced{ID: 7})
+//	...
+//	d.Close(ctx) // handles what is queued, then stops
+//
+// A handler that returns an error, or panics, gets the same event again
+// after a backoff, and the events behind it wait their turn, so every
+// handler sees events in the order they were dispatched. An event may be
+// delivered again after a handler did part of its work, so handlers must
+// be idempotent. Unlike pubsub nothing is dropped: a queue grows for as
+// long as its handler is behind.
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/clock"
+)
+
+// ErrClosed is returned by Dispatch after Close
+var ErrClosed = errors.New("dispatch: dispatcher closed")
+
+// Handler handles one event. Returning an error asks for it again.
+type Handler[T any] func(ctx context.Context, event T) error
+
+// Options tune retries. The zero Options retries every failure until
+// Close gives up, starting 100ms apart and backing off to 10s.
+type Options struct {
+	// MaxAttempts bounds how often one event is offered to one handler;
+	// after the last failed attempt the handler moves on. Zero is no bound.
+	MaxAttempts int
+
+	// Backoff is the wait before the first retry, doubled before each
+	// retry after that, up to MaxBackoff
+	Backoff, MaxBackoff time.Duration
+
+	// OnError, if set, is called from the handler's goroutine after each
+	// failed attempt
+	OnError func(Failure)
+
+	// Clock times the backoffs; nil means clock.Real
+	Clock clock.Clock
+}
+
+// Failure describes one failed delivery
+type Failure struct {
+	Handler string // the name it was subscribed with
+	Attempt int    // 1 for the first delivery
+	Err     error
+	GaveUp  bool // the event will not be offered to this handler again
+}
+
+// queue is one handler's pending events, the first of which is being
+// handled
+type queue[T any] struct {
+	name   string
+	handle Handler[T]
+	events []T
+	ready  *sync.Cond // on Dispatcher.mu; signalled when events arrive or on Close
+}
+
+// Dispatcher queues events for its handlers. It is safe for concurrent
+// use.
+type Dispatcher[T any] struct {
+	opts   Options
+	ctx    context.Context // given to handlers; cancelled when Close gives up
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	queues  []*queue[T]
+	pending int           // events queued or being handled, over all queues
+	idle    chan struct{} // closed while pending is zero
+	closed  bool
+}
+
+// New returns a dispatcher with no handlers
+func New[T any](opts Options) *Dispatcher[T] {
+	if opts.Backoff <= 0 {
+		opts.Backoff = 100 * time.Millisecond
+	}
+	if opts.MaxBackoff < opts.Backoff {
+		opts.MaxBackoff = max(opts.Backoff, 10*time.Second)
+	}
+	if opts.Clock == nil {
+		opts.Clock = clock.Real
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	idle := make(chan struct{})
+	close(idle)
+	return &Dispatcher[T]{opts: opts, ctx: ctx, cancel: cancel, idle: idle}
+}
+
+// Subscribe adds a handler, which gets the events dispatched from now on.
+// name identifies it in Failures. After Close it does nothing.
+func (d *Dispatcher[T]) Subscribe(name string, h Handler[T]) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return
+	}
+	q := &queue[T]{name: name, handle: h, ready: sync.NewCond(&d.mu)}
+	d.queues = append(d.queues, q)
+	d.wg.Add(1)
+	go d.run(q)
+}
+
+// Dispatch queues event for every handler and returns without waiting for
+// any of them
+func (d *Dispatcher[T]) Dispatch(event T) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return ErrClosed
+	}
+	for _, q := range d.queues {
+		q.events = append(q.events, event)
+		if d.pending == 0 {
+			d.idle = make(chan struct{})
+		}
+		d.pending++
+		q.ready.Signal()
+	}
+	return nil
+}
+
+// Flush waits until no event is queued or being handled, or ctx ends
+func (d *Dispatcher[T]) Flush(ctx context.Context) error {
+	d.mu.Lock()
+	idle := d.idle
+	d.mu.Unlock()
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops new dispatches and waits for the queued events to be
+// handled. If ctx ends first, Close returns its error without waiting
+// further: handlers' contexts are cancelled, and each event still queued
+// gets one attempt with no retries.
+func (d *Dispatcher[T]) Close(ctx context.Context) error {
+	d.mu.Lock()
+	d.closed = true
+	for _, q := range d.queues {
+		q.ready.Signal()
+	}
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		d.cancel()
+		return nil
+	case <-ctx.Done():
+		d.cancel()
+		return ctx.Err()
+	}
+}
+
+// run hands q's events to its handler one at a time until the dispatcher
+// is closed and the queue empty
+func (d *Dispatcher[T]) run(q *queue[T]) {
+	defer d.wg.Done()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for {
+		for len(q.events) == 0 && !d.closed {
+			q.ready.Wait()
+		}
+		if len(q.events) == 0 {
+			return
+		}
+		event := q.events[0]
+		d.mu.Unlock()
+		d.deliver(q, event)
+		d.mu.Lock()
+
+		var zero T
+		q.events[0] = zero
+		q.events = q.events[1:]
+		d.pending--
+		if d.pending == 0 {
+			close(d.idle)
+		}
+	}
+}
+
+// deliver offers event to q's handler until it succeeds or is given up on
+func (d *Dispatcher[T]) deliver(q *queue[T], event T) {
+	backoff := d.opts.Backoff
+	for attempt := 1; ; attempt++ {
+		err := call(d.ctx, q.handle, event)
+		if err == nil {
+			return
+		}
+		gaveUp := (d.opts.MaxAttempts > 0 && attempt >= d.opts.MaxAttempts) || d.ctx.Err() != nil
+		if d.opts.OnError != nil {
+			d.opts.OnError(Failure{Handler: q.name, Attempt: attempt, Err: err, GaveUp: gaveUp})
+		}
+		if gaveUp {
+			return
+		}
+
+		timer := d.opts.Clock.NewTimer(backoff)
+		select {
+		case <-timer.C():
+		case <-d.ctx.Done():
+			// Close has given up waiting; one last try, then move on
+			timer.Stop()
+		}
+		backoff = min(2*backoff, d.opts.MaxBackoff)
+	}
+}
+
+// call runs h, turning a panic into an error so the event is retried
+// rather than the process crashing
+func call[T any](ctx context.Context, h Handler[T], event T) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("dispatch: handler panicked: %v", r)
+		}
+	}()
+	return h(ctx, event)
+}
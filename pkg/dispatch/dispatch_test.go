@@ -0,0 +1,261 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/clock"
+)
+
+// recorder is a handler that keeps the events it handled
+type recorder struct {
+	mu   sync.Mutex
+	seen []int
+}
+
+func (r *recorder) handle(_ context.Context, event int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seen = append(r.seen, event)
+	return nil
+}
+
+func (r *recorder) events() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.seen)
+}
+
+func flush(t *testing.T, d *Dispatcher[int]) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := d.Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+}
+
+func TestDispatch_Order(t *testing.T) {
+	d := New[int](Options{})
+	defer d.Close(context.Background())
+	var fast, slow recorder
+	d.Subscribe("fast", fast.handle)
+	d.Subscribe("slow", func(ctx context.Context, event int) error {
+		time.Sleep(time.Millisecond)
+		return slow.handle(ctx, event)
+	})
+
+	// Dispatching from several goroutines at once still gives every
+	// handler the same order
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.Dispatch(i)
+		}()
+	}
+	wg.Wait()
+	flush(t, d)
+
+	if got := fast.events(); len(got) != 50 {
+		t.Fatalf("fast handler saw %d events; want 50", len(got))
+	}
+	if !slices.Equal(fast.events(), slow.events()) {
+		t.Errorf("handlers saw different orders:\nfast %v\nslow %v", fast.events(), slow.events())
+	}
+}
+
+func TestDispatch_RetryKeepsOrder(t *testing.T) {
+	var failures []Failure
+	d := New[int](Options{Backoff: time.Millisecond, OnError: func(f Failure) { failures = append(failures, f) }})
+	defer d.Close(context.Background())
+
+	var rec recorder
+	attempts := 0
+	d.Subscribe("flaky", func(ctx context.Context, event int) error {
+		if event == 2 {
+			attempts++
+			if attempts < 3 {
+				return fmt.Errorf("attempt %d failed", attempts)
+			}
+		}
+		return rec.handle(ctx, event)
+	})
+	for i := 1; i <= 4; i++ {
+		d.Dispatch(i)
+	}
+	flush(t, d)
+
+	// Event 2 was delivered at least once, and 3 and 4 waited for it
+	if got := rec.events(); !slices.Equal(got, []int{1, 2, 3, 4}) {
+		t.Errorf("handled %v; want 1 2 3 4", got)
+	}
+	if attempts != 3 || len(failures) != 2 {
+		t.Fatalf("%d attempts, %d failures; want 3 attempts after 2 failures", attempts, len(failures))
+	}
+	for i, f := range failures {
+		if f.Handler != "flaky" || f.Attempt != i+1 || f.GaveUp || f.Err == nil {
+			t.Errorf("failure %d = %+v; want attempt %d of flaky, to be retried", i, f, i+1)
+		}
+	}
+}
+
+func TestDispatch_MaxAttempts(t *testing.T) {
+	var last Failure
+	d := New[int](Options{MaxAttempts: 3, Backoff: time.Millisecond, OnError: func(f Failure) { last = f }})
+	defer d.Close(context.Background())
+
+	var rec recorder
+	attempts := 0
+	d.Subscribe("broken", func(ctx context.Context, event int) error {
+		if event == 1 {
+			attempts++
+			panic("always fails")
+		}
+		return rec.handle(ctx, event)
+	})
+	d.Dispatch(1)
+	d.Dispatch(2)
+	flush(t, d)
+
+	if attempts != 3 || !last.GaveUp || last.Attempt != 3 {
+		t.Errorf("%d attempts, last failure %+v; want 3 attempts, giving up on the last", attempts, last)
+	}
+	if got := rec.events(); !slices.Equal(got, []int{2}) {
+		t.Errorf("handled %v; want event 2 after giving up on 1", got)
+	}
+}
+
+// TestDispatch_Backoff checks the waits between retries double up to
+// MaxBackoff, on a fake clock rather than by sleeping through them
+func TestDispatch_Backoff(t *testing.T) {
+	c := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	d := New[int](Options{Backoff: time.Second, MaxBackoff: 3 * time.Second, Clock: c})
+	defer d.Close(context.Background())
+
+	attempts := make(chan time.Time, 1)
+	n := 0
+	d.Subscribe("flaky", func(ctx context.Context, event int) error {
+		n++
+		attempts <- c.Now()
+		if n < 5 {
+			return errors.New("not yet")
+		}
+		return nil
+	})
+	d.Dispatch(1)
+
+	last := <-attempts
+	for _, wait := range []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second} {
+		c.BlockUntil(1)
+		c.Advance(wait - time.Millisecond)
+		select {
+		case at := <-attempts:
+			t.Fatalf("retried at %v, %v after the last attempt; want %v", at, at.Sub(last), wait)
+		default:
+		}
+		c.Advance(time.Millisecond)
+		if at := <-attempts; at.Sub(last) != wait {
+			t.Errorf("retried %v after the last attempt; want %v", at.Sub(last), wait)
+		}
+		last = last.Add(wait)
+	}
+	flush(t, d)
+}
+
+func TestDispatch_HandlersAreIndependent(t *testing.T) {
+	d := New[int](Options{})
+	release := make(chan struct{})
+	var rec recorder
+	d.Subscribe("stuck", func(ctx context.Context, event int) error {
+		<-release
+		return nil
+	})
+	d.Subscribe("free", rec.handle)
+	d.Dispatch(1)
+	d.Dispatch(2)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(rec.events()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := rec.events(); !slices.Equal(got, []int{1, 2}) {
+		t.Errorf("free handler saw %v while the other was stuck; want 1 2", got)
+	}
+	close(release)
+	d.Close(context.Background())
+}
+
+func TestClose(t *testing.T) {
+	d := New[int](Options{})
+	var rec recorder
+	d.Subscribe("slow", func(ctx context.Context, event int) error {
+		time.Sleep(time.Millisecond)
+		return rec.handle(ctx, event)
+	})
+	for i := range 10 {
+		d.Dispatch(i)
+	}
+	if err := d.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if got := rec.events(); len(got) != 10 {
+		t.Errorf("handled %d events before Close returned; want all 10", len(got))
+	}
+	if err := d.Dispatch(10); !errors.Is(err, ErrClosed) {
+		t.Errorf("Dispatch after Close = %v; want ErrClosed", err)
+	}
+}
+
+func TestClose_GivesUp(t *testing.T) {
+	gaveUp := make(chan Failure, 1)
+	d := New[int](Options{Backoff: time.Hour, OnError: func(f Failure) {
+		if f.GaveUp {
+			gaveUp <- f
+		}
+	}})
+	d.Subscribe("down", func(ctx context.Context, event int) error {
+		return errors.New("unavailable")
+	})
+	d.Dispatch(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := d.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Close = %v; want the context's deadline", err)
+	}
+	// The handler gets one last attempt, not another hour's wait
+	select {
+	case f := <-gaveUp:
+		if f.Attempt != 2 {
+			t.Errorf("gave up after attempt %d; want 2", f.Attempt)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("still retrying 5s after Close gave up")
+	}
+}
+
+func Example() {
+	d := New[string](Options{Backoff: time.Millisecond})
+	failed := false
+	d.Subscribe("audit", func(ctx context.Context, event string) error {
+		if !failed {
+			failed = true
+			return errors.New("audit store unavailable")
+		}
+		fmt.Println("audit:", event)
+		return nil
+	})
+	d.Dispatch("book 1 created")
+	d.Dispatch("book 1 deleted")
+	d.Close(context.Background())
+	// Output:
+	// audit: book 1 created
+	// audit: book 1 deleted
+}
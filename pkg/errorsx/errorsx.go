@@ -0,0 +1,192 @@
+// Package errorsx adds machine-readable codes and captured stack frames to
+// errors, and maps codes to HTTP status codes:
+//
+//	book, ok := store.GetBook(id)
+//	if !ok {
+//		return errorsx.New(errorsx.CodeNotFound, "Book not found")
+//	}
+//	...
+//	if err := decode(r.Body, &book); err != nil {
+//		return errorsx.Wrap(err, errorsx.CodeInvalidArgument, "Invalid request body")
+//	}
+//
+// Errors created here work with errors.Is and errors.As like any wrapped
+// error. Format one with %+v to print the stack where it was created.
+package errorsx
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+)
+
+// Code classifies an error independently of its message
+type Code string
+
+const (
+	CodeInvalidArgument   Code = "invalid_argument"
+	CodeNotFound          Code = "not_found"
+	CodeConflict          Code = "conflict"
+	CodeUnauthenticated   Code = "unauthenticated"
+	CodePermissionDenied  Code = "permission_denied"
+	CodeMethodNotAllowed  Code = "method_not_allowed"
+	CodeNotAcceptable     Code = "not_acceptable"
+	CodeTooLarge          Code = "too_large"
+	CodeUnsupportedMedia  Code = "unsupported_media_type"
+	CodeResourceExhausted Code = "resource_exhausted"
+	CodeUnavailable       Code = "unavailable"
+	CodeInternal          Code = "internal"
+)
+
+// HTTPStatus maps a code to an HTTP status; unknown codes map to 500
+func (c Code) HTTPStatus() int {
+	switch c {
+	case CodeInvalidArgument:
+		return http.StatusBadRequest
+	case CodeNotFound:
+		return http.StatusNotFound
+	case CodeConflict:
+		return http.StatusConflict
+	case CodeUnauthenticated:
+		return http.StatusUnauthorized
+	case CodePermissionDenied:
+		return http.StatusForbidden
+	case CodeMethodNotAllowed:
+		return http.StatusMethodNotAllowed
+	case CodeNotAcceptable:
+		return http.StatusNotAcceptable
+	case CodeTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case CodeUnsupportedMedia:
+		return http.StatusUnsupportedMediaType
+	case CodeResourceExhausted:
+		return http.StatusTooManyRequests
+	case CodeUnavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// maxDepth bounds the number of frames captured per error
+const maxDepth = 32
+
+// Error is an error with a code, an optional cause and the stack of the
+// place it was created
+type Error struct {
+	Code    Code
+	Message string
+	Err     error // cause, may be nil
+	stack   []uintptr
+}
+
+// New creates an error with a code and a message
+func New(code Code, message string) error {
+	return &Error{Code: code, Message: message, stack: callers()}
+}
+
+// Errorf creates an error with a formatted message. A %w verb makes the
+// wrapped error reachable through errors.Is and errors.As.
+func Errorf(code Code, format string, args ...any) error {
+	return &Error{Code: code, Err: fmt.Errorf(format, args...), stack: callers()}
+}
+
+// Wrap annotates err with a code and message. It returns nil if err is nil.
+func Wrap(err error, code Code, message string) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: code, Message: message, Err: err, stack: callers()}
+}
+
+func (e *Error) Error() string {
+	if e.Err == nil || e.Err.Error() == "" {
+		return e.Message
+	}
+	if e.Message == "" {
+		return e.Err.Error()
+	}
+	return e.Message + ": " + e.Err.Error()
+}
+
+// Unwrap returns the cause
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// StackTrace returns the frames captured when the error was created,
+// innermost call first
+func (e *Error) StackTrace() []runtime.Frame {
+	frames := runtime.CallersFrames(e.stack)
+	out := make([]runtime.Frame, 0, len(e.stack))
+	for {
+		frame, more := frames.Next()
+		out = append(out, frame)
+		if !more {
+			break
+		}
+	}
+	return out
+}
+
+// Format implements fmt.Formatter. %v and %s print the message; %+v adds
+// the code and stack trace.
+func (e *Error) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			fmt.Fprintf(s, "[%s] %s", e.Code, e.Error())
+			for _, frame := range e.StackTrace() {
+				fmt.Fprintf(s, "\n%s\n\t%s:%d", frame.Function, frame.File, frame.Line)
+			}
+			return
+		}
+		fallthrough
+	case 's':
+		io.WriteString(s, e.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	}
+}
+
+// CodeOf returns the code of the outermost *Error in err's chain,
+// CodeInternal if there is none, and "" for a nil error
+func CodeOf(err error) Code {
+	if err == nil {
+		return ""
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return CodeInternal
+}
+
+// HTTPStatus returns the HTTP status for err, 200 for a nil error
+func HTTPStatus(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+	return CodeOf(err).HTTPStatus()
+}
+
+// PublicMessage returns text that is safe to show to API clients. Errors
+// without a code, and internal errors, hide their details.
+func PublicMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+	if CodeOf(err) == CodeInternal {
+		return http.StatusText(http.StatusInternalServerError)
+	}
+	return err.Error()
+}
+
+// callers skips runtime.Callers, callers and the constructor
+func callers() []uintptr {
+	pcs := make([]uintptr, maxDepth)
+	n := runtime.Callers(3, pcs)
+	return pcs[:n]
+}
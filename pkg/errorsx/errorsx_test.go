@@ -0,0 +1,134 @@
+package errorsx
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCodeHTTPStatus(t *testing.T) {
+	tests := []struct {
+		code Code
+		want int
+	}{
+		{CodeInvalidArgument, http.StatusBadRequest},
+		{CodeNotFound, http.StatusNotFound},
+		{CodeConflict, http.StatusConflict},
+		{CodeUnauthenticated, http.StatusUnauthorized},
+		{CodePermissionDenied, http.StatusForbidden},
+		{CodeMethodNotAllowed, http.StatusMethodNotAllowed},
+		{CodeNotAcceptable, http.StatusNotAcceptable},
+		{CodeTooLarge, http.StatusRequestEntityTooLarge},
+		{CodeUnsupportedMedia, http.StatusUnsupportedMediaType},
+		{CodeResourceExhausted, http.StatusTooManyRequests},
+		{CodeUnavailable, http.StatusServiceUnavailable},
+		{CodeInternal, http.StatusInternalServerError},
+		{Code("made_up"), http.StatusInternalServerError},
+	}
+	for _, tc := range tests {
+		if got := tc.code.HTTPStatus(); got != tc.want {
+			t.Errorf("%s.HTTPStatus() = %d; want %d", tc.code, got, tc.want)
+		}
+	}
+}
+
+func TestWrap(t *testing.T) {
+	if Wrap(nil, CodeInternal, "ignored") != nil {
+		t.Error("Wrap(nil) != nil")
+	}
+
+	err := Wrap(io.ErrUnexpectedEOF, CodeInvalidArgument, "Invalid request body")
+	if got, want := err.Error(), "Invalid request body: unexpected EOF"; got != want {
+		t.Errorf("Error() = %q; want %q", got, want)
+	}
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Error("errors.Is did not find the cause")
+	}
+	if CodeOf(err) != CodeInvalidArgument || HTTPStatus(err) != http.StatusBadRequest {
+		t.Errorf("CodeOf = %s, HTTPStatus = %d; want invalid_argument, 400", CodeOf(err), HTTPStatus(err))
+	}
+}
+
+func TestErrorf(t *testing.T) {
+	err := Errorf(CodeNotFound, "book %d: %w", 7, io.EOF)
+	if got, want := err.Error(), "book 7: EOF"; got != want {
+		t.Errorf("Error() = %q; want %q", got, want)
+	}
+	if !errors.Is(err, io.EOF) {
+		t.Error("errors.Is did not find the %w argument")
+	}
+}
+
+func TestCodeOf(t *testing.T) {
+	inner := New(CodeNotFound, "missing")
+	tests := []struct {
+		name string
+		err  error
+		want Code
+	}{
+		{"nil", nil, ""},
+		{"plain error", errors.New("boom"), CodeInternal},
+		{"coded error", inner, CodeNotFound},
+		{"wrapped with fmt", fmt.Errorf("lookup: %w", inner), CodeNotFound},
+		{"outermost code wins", Wrap(inner, CodeUnavailable, "retry later"), CodeUnavailable},
+		{"joined", errors.Join(errors.New("other"), inner), CodeNotFound},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := CodeOf(tc.err); got != tc.want {
+				t.Errorf("CodeOf() = %q; want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPublicMessage(t *testing.T) {
+	if got := PublicMessage(errors.New("db password is hunter2")); got != "Internal Server Error" {
+		t.Errorf("PublicMessage(plain) = %q; want details hidden", got)
+	}
+	if got := PublicMessage(Wrap(errors.New("secret"), CodeInternal, "query failed")); got != "Internal Server Error" {
+		t.Errorf("PublicMessage(internal) = %q; want details hidden", got)
+	}
+	if got := PublicMessage(New(CodeNotFound, "Book not found")); got != "Book not found" {
+		t.Errorf("PublicMessage(not found) = %q; want %q", got, "Book not found")
+	}
+}
+
+func newHere() error {
+	return New(CodeInternal, "here")
+}
+
+func TestStackTrace(t *testing.T) {
+	var e *Error
+	if !errors.As(newHere(), &e) {
+		t.Fatal("New did not return *Error")
+	}
+
+	frames := e.StackTrace()
+	if len(frames) == 0 {
+		t.Fatal("no frames captured")
+	}
+	if !strings.HasSuffix(frames[0].Function, "errorsx.newHere") {
+		t.Errorf("innermost frame = %s; want the caller of New", frames[0].Function)
+	}
+
+	verbose := fmt.Sprintf("%+v", e)
+	if !strings.HasPrefix(verbose, "[internal] here\n") || !strings.Contains(verbose, "errorsx_test.go:") {
+		t.Errorf("%%+v output missing code or file:line:\n%s", verbose)
+	}
+	if got := fmt.Sprintf("%v", e); got != "here" {
+		t.Errorf("%%v = %q; want %q", got, "here")
+	}
+}
+
+func ExampleWrap() {
+	err := Wrap(io.ErrUnexpectedEOF, CodeInvalidArgument, "Invalid request body")
+	fmt.Println(err)
+	fmt.Println(CodeOf(err), HTTPStatus(err))
+	// Output:
+	// Invalid request body: unexpected EOF
+	// invalid_argument 400
+}
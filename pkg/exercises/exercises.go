@@ -0,0 +1,102 @@
+// Package exercises judges solutions to the practice problems in the
+// repository's exercises directory. Each exercise is a function stub there;
+// its test vectors are embedded here, out of the solver's sight. Judge
+// builds a solution package with a generated harness and runs the vectors
+// through it, reporting each one's result and how long it took.
+package exercises
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+//go:embed vectors/*.json
+var vectorFiles embed.FS
+
+// Exercise describes a problem: the function a solution implements and
+// how the harness calls it with a vector's input
+type Exercise struct {
+	ID        string // also the stub's package, in exercises/<ID>
+	Title     string
+	Signature string // the function the solution implements
+
+	// Input and Output are the Go types a vector's input and expected
+	// output decode into; Call is an expression of type Output calling the
+	// solution, imported as package solution, with the input named in
+	Input, Output, Call string
+}
+
+// Vector is one test case: the input, and the output a right solution
+// returns for it, both as JSON
+type Vector struct {
+	In   json.RawMessage `json:"in"`
+	Want json.RawMessage `json:"want"`
+}
+
+// All lists the exercises, sorted by ID
+var All = []Exercise{
+	{
+		ID:        "binarysearch",
+		Title:     "Search a sorted slice",
+		Signature: "func Search(nums []int, target int) int",
+		Input:     "struct{ Nums []int; Target int }",
+		Output:    "int",
+		Call:      "solution.Search(in.Nums, in.Target)",
+	},
+	{
+		ID:        "brackets",
+		Title:     "Check brackets are balanced",
+		Signature: "func Balanced(s string) bool",
+		Input:     "string",
+		Output:    "bool",
+		Call:      "solution.Balanced(in)",
+	},
+	{
+		ID:        "mergeintervals",
+		Title:     "Merge overlapping intervals",
+		Signature: "func Merge(intervals [][2]int) [][2]int",
+		Input:     "[][2]int",
+		Output:    "[][2]int",
+		Call:      "solution.Merge(in)",
+	},
+	{
+		ID:        "reverse",
+		Title:     "Reverse a string",
+		Signature: "func Reverse(s string) string",
+		Input:     "string",
+		Output:    "string",
+		Call:      "solution.Reverse(in)",
+	},
+	{
+		ID:        "twosum",
+		Title:     "Find two numbers adding up to a target",
+		Signature: "func TwoSum(nums []int, target int) []int",
+		Input:     "struct{ Nums []int; Target int }",
+		Output:    "[]int",
+		Call:      "solution.TwoSum(in.Nums, in.Target)",
+	},
+}
+
+// Lookup returns the exercise with this ID
+func Lookup(id string) (Exercise, error) {
+	i := slices.IndexFunc(All, func(e Exercise) bool { return e.ID == id })
+	if i < 0 {
+		return Exercise{}, fmt.Errorf("exercises: unknown exercise %q", id)
+	}
+	return All[i], nil
+}
+
+// Vectors returns the exercise's test vectors
+func (e Exercise) Vectors() ([]Vector, error) {
+	data, err := vectorFiles.ReadFile("vectors/" + e.ID + ".json")
+	if err != nil {
+		return nil, fmt.Errorf("exercises: %s: %w", e.ID, err)
+	}
+	var vectors []Vector
+	if err := json.Unmarshal(data, &vectors); err != nil {
+		return nil, fmt.Errorf("exercises: %s.json: %w", e.ID, err)
+	}
+	return vectors, nil
+}
@@ -0,0 +1,314 @@
+package exercises
+
+import (
+	"context"
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stubs is where the exercise stubs are, from this package
+const stubs = "../../exercises"
+
+// reference solves each exercise, decoding a vector's input and encoding
+// the answer, to check the vectors against
+var reference = map[string]func(in json.RawMessage) (any, error){
+	"binarysearch": func(in json.RawMessage) (any, error) {
+		var args struct {
+			Nums   []int
+			Target int
+		}
+		err := json.Unmarshal(in, &args)
+		i, found := slices.BinarySearch(args.Nums, args.Target)
+		if !found {
+			i = -1
+		}
+		return i, err
+	},
+	"brackets": func(in json.RawMessage) (any, error) {
+		var s string
+		err := json.Unmarshal(in, &s)
+		var open []rune
+		for _, r := range s {
+			switch r {
+			case '(', '[', '{':
+				open = append(open, r)
+			case ')', ']', '}':
+				want := map[rune]rune{')': '(', ']': '[', '}': '{'}[r]
+				if len(open) == 0 || open[len(open)-1] != want {
+					return false, err
+				}
+				open = open[:len(open)-1]
+			}
+		}
+		return len(open) == 0, err
+	},
+	"mergeintervals": func(in json.RawMessage) (any, error) {
+		var intervals [][2]int
+		err := json.Unmarshal(in, &intervals)
+		slices.SortFunc(intervals, func(a, b [2]int) int { return a[0] - b[0] })
+		merged := [][2]int{}
+		for _, iv := range intervals {
+			if n := len(merged); n > 0 && iv[0] <= merged[n-1][1] {
+				merged[n-1][1] = max(merged[n-1][1], iv[1])
+				continue
+			}
+			merged = append(merged, iv)
+		}
+		return merged, err
+	},
+	"reverse": func(in json.RawMessage) (any, error) {
+		var s string
+		err := json.Unmarshal(in, &s)
+		r := []rune(s)
+		slices.Reverse(r)
+		return string(r), err
+	},
+	"twosum": func(in json.RawMessage) (any, error) {
+		var args struct {
+			Nums   []int
+			Target int
+		}
+		err := json.Unmarshal(in, &args)
+		seen := make(map[int]int)
+		for j, n := range args.Nums {
+			if i, ok := seen[args.Target-n]; ok {
+				return []int{i, j}, err
+			}
+			seen[n] = j
+		}
+		return nil, err
+	},
+}
+
+func TestVectors(t *testing.T) {
+	for _, e := range All {
+		t.Run(e.ID, func(t *testing.T) {
+			vectors, err := e.Vectors()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(vectors) < 5 {
+				t.Errorf("%d vectors; want at least 5", len(vectors))
+			}
+			solve := reference[e.ID]
+			if solve == nil {
+				t.Fatal("no reference solution")
+			}
+			for i, v := range vectors {
+				got, err := solve(v.In)
+				if err != nil {
+					t.Fatalf("vector %d: %v", i+1, err)
+				}
+				var want any
+				json.Unmarshal(v.Want, &want)
+				gotJSON, _ := json.Marshal(got)
+				var gotAny any
+				json.Unmarshal(gotJSON, &gotAny)
+				if !reflect.DeepEqual(gotAny, want) {
+					t.Errorf("vector %d, input %s: reference gives %s; vector wants %s", i+1, v.In, gotJSON, v.Want)
+				}
+			}
+		})
+	}
+}
+
+// TestStubs checks each exercise has a stub declaring its signature, and
+// that nothing else is in the exercises directory
+func TestStubs(t *testing.T) {
+	entries, err := os.ReadDir(stubs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dirs []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			dirs = append(dirs, entry.Name())
+		}
+	}
+	var ids []string
+	for _, e := range All {
+		ids = append(ids, e.ID)
+	}
+	if !slices.IsSorted(ids) || !slices.Equal(dirs, ids) {
+		t.Errorf("exercises are %v, stubs %v; want the same, sorted", ids, dirs)
+	}
+
+	for _, e := range All {
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, filepath.Join(stubs, e.ID, e.ID+".go"), nil, 0)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		var sigs []string
+		for _, decl := range f.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok {
+				var b strings.Builder
+				printer.Fprint(&b, fset, &ast.FuncDecl{Name: fn.Name, Type: fn.Type})
+				sigs = append(sigs, b.String())
+			}
+		}
+		if !slices.Contains(sigs, e.Signature) {
+			t.Errorf("%s stub declares %q; want %q", e.ID, sigs, e.Signature)
+		}
+	}
+}
+
+// judge runs Judge on a solution to e with this source, written to a
+// directory of its own
+func judge(t *testing.T, id, source string, timeout time.Duration) Report {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("builds a program")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("no go command")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "solution.go"), []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	e, err := Lookup(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	report, err := Judge(ctx, e, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return report
+}
+
+func TestJudge(t *testing.T) {
+	tests := []struct {
+		name, source string
+		wantPassed   []bool
+	}{
+		{
+			"right",
+			`package twosum
+
+			func TwoSum(nums []int, target int) []int {
+				for i := range nums {
+					for j := i + 1; j < len(nums); j++ {
+						if nums[i]+nums[j] == target {
+							return []int{i, j}
+						}
+					}
+				}
+				return nil
+			}`,
+			[]bool{true, true, true, true, true, true},
+		},
+		{
+			"wrong for negatives",
+			`package twosum
+
+			import "fmt"
+
+			func TwoSum(nums []int, target int) []int {
+				fmt.Println("printing does not confuse the judge")
+				if target < 0 {
+					return []int{}
+				}
+				for i := range nums {
+					for j := i + 1; j < len(nums); j++ {
+						if nums[i]+nums[j] == target {
+							return []int{i, j}
+						}
+					}
+				}
+				return nil
+			}`,
+			[]bool{true, true, true, false, true, true},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			report := judge(t, "twosum", tc.source, time.Minute)
+			var passed []bool
+			for _, c := range report.Cases {
+				passed = append(passed, c.Passed)
+			}
+			if !slices.Equal(passed, tc.wantPassed) {
+				t.Fatalf("passed = %v; want %v (report %+v)", passed, tc.wantPassed, report)
+			}
+			if report.OK() != !slices.Contains(passed, false) {
+				t.Errorf("OK() = %v with cases passing %v", report.OK(), passed)
+			}
+		})
+	}
+}
+
+func TestJudge_Stub(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a program")
+	}
+	e, _ := Lookup("reverse")
+	report, err := Judge(context.Background(), e, filepath.Join(stubs, "reverse"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Passed() != 0 || report.Cases[0].Panic != "not implemented" {
+		t.Errorf("the stub passed %d cases, first %+v", report.Passed(), report.Cases[0])
+	}
+}
+
+func TestJudge_CompileError(t *testing.T) {
+	report := judge(t, "reverse", "package reverse\n\nfunc Reverse(s string) string { return x }\n", time.Minute)
+	if report.OK() || len(report.Cases) != 0 || !strings.Contains(report.CompileError, "solution.go:3:40: undefined: x") {
+		t.Errorf("report = %+v; want a compile error", report)
+	}
+	if strings.Contains(report.CompileError, os.TempDir()+string(filepath.Separator)+"judge-") {
+		t.Errorf("compile error points at the copied files: %s", report.CompileError)
+	}
+}
+
+func TestJudge_Stops(t *testing.T) {
+	tests := []struct {
+		name, source string
+		timeout      time.Duration
+		wantCrash    string
+	}{
+		{"endless loop", `package reverse
+
+			func Reverse(s string) string {
+				for len(s) > 3 {
+				}
+				return s
+			}`, 5 * time.Second, ""},
+		{"unbounded recursion", `package reverse
+
+			func Reverse(s string) string {
+				if len(s) > 3 {
+					return Reverse(s)
+				}
+				return s
+			}`, time.Minute, "stack overflow"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			report := judge(t, "reverse", tc.source, tc.timeout)
+			if report.TimedOut != (tc.wantCrash == "") || !strings.Contains(report.Crash, tc.wantCrash) {
+				t.Errorf("timed out %v, crash %q; want a crash containing %q", report.TimedOut, report.Crash, tc.wantCrash)
+			}
+			// "" and "a" are short enough; "hello" never finishes
+			if len(report.Cases) != 7 || report.Passed() != 2 || report.Cases[2].Got != nil || report.OK() {
+				t.Errorf("report = %+v; want the first two of seven cases passed", report)
+			}
+		})
+	}
+}
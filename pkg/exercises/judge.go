@@ -0,0 +1,224 @@
+package exercises
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+)
+
+//go:embed harness.go.tmpl
+var harnessSource string
+
+var harness = template.Must(template.New("harness").Parse(harnessSource))
+
+// copiedPath matches the start of compiler messages about the solution's
+// copied files
+var copiedPath = regexp.MustCompile(`(?m)^(\./)?solution[/\\]`)
+
+// Report is how a solution did
+type Report struct {
+	Exercise Exercise
+	Build    time.Duration // compiling the solution and harness
+
+	// CompileError is the compiler's output if the solution did not build;
+	// no cases ran then
+	CompileError string
+
+	Cases []Case
+
+	// TimedOut is set if the time ran out before every case had run, and
+	// Crash holds what the program printed if a case killed it, as
+	// unbounded recursion does. The cases that did not run have failed.
+	TimedOut bool
+	Crash    string
+}
+
+// Case is one vector's result
+type Case struct {
+	Input    json.RawMessage
+	Want     json.RawMessage
+	Got      json.RawMessage // nil if the solution panicked or did not finish
+	Panic    string
+	Passed   bool
+	Duration time.Duration
+}
+
+// Passed returns how many cases passed
+func (r Report) Passed() int {
+	n := 0
+	for _, c := range r.Cases {
+		if c.Passed {
+			n++
+		}
+	}
+	return n
+}
+
+// OK reports whether the solution built and passed every case
+func (r Report) OK() bool {
+	return r.CompileError == "" && !r.TimedOut && r.Crash == "" && r.Passed() == len(r.Cases)
+}
+
+// Judge builds the solution to e in the package directory dir and runs it
+// on e's vectors, stopping when ctx is done. A solution that does not
+// compile, panics or gives wrong answers is a Report, not an error; errors
+// are for not being able to judge it at all, such as the go command
+// missing. The solution is copied into a module of its own, so it may
+// import the standard library only.
+func Judge(ctx context.Context, e Exercise, dir string) (Report, error) {
+	report := Report{Exercise: e}
+	vectors, err := e.Vectors()
+	if err != nil {
+		return report, err
+	}
+	work, err := os.MkdirTemp("", "judge-"+e.ID+"-")
+	if err != nil {
+		return report, err
+	}
+	defer os.RemoveAll(work)
+	if err := prepare(work, e, dir); err != nil {
+		return report, err
+	}
+
+	start := time.Now()
+	bin := filepath.Join(work, "harness")
+	build := exec.CommandContext(ctx, "go", "build", "-o", bin, ".")
+	build.Dir = work
+	build.Env = append(os.Environ(), "GOWORK=off", "GOFLAGS=")
+	out, err := build.CombinedOutput()
+	report.Build = time.Since(start)
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || ctx.Err() != nil {
+			return report, fmt.Errorf("exercises: building %s: %w", e.ID, err)
+		}
+		// Point the messages at the solver's files, not the copies
+		report.CompileError = copiedPath.ReplaceAllLiteralString(string(out), dir+string(filepath.Separator))
+		return report, nil
+	}
+
+	input, err := json.Marshal(vectors)
+	if err != nil {
+		return report, err
+	}
+	results := filepath.Join(work, "results.jsonl")
+	runCmd := exec.CommandContext(ctx, bin, results)
+	runCmd.Stdin = bytes.NewReader(input)
+	var stderr bytes.Buffer
+	runCmd.Stderr = &stderr
+	runErr := runCmd.Run()
+
+	report.Cases, err = readResults(results, vectors)
+	if err != nil {
+		return report, err
+	}
+	switch {
+	case ctx.Err() != nil:
+		report.TimedOut = true
+	case runErr != nil && len(report.Cases) < len(vectors):
+		report.Crash = firstLines(stderr.String(), 20)
+	}
+	for _, v := range vectors[len(report.Cases):] {
+		report.Cases = append(report.Cases, Case{Input: v.In, Want: v.Want})
+	}
+	return report, nil
+}
+
+// prepare writes the harness module to work: a go.mod, the harness as
+// main.go, and the solution's non-test Go files in solution/
+func prepare(work string, e Exercise, dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return err
+	}
+	solution := filepath.Join(work, "solution")
+	if err := os.Mkdir(solution, 0o755); err != nil {
+		return err
+	}
+	copied := 0
+	for _, f := range files {
+		if strings.HasSuffix(f, "_test.go") {
+			continue
+		}
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(solution, filepath.Base(f)), data, 0o644); err != nil {
+			return err
+		}
+		copied++
+	}
+	if copied == 0 {
+		return fmt.Errorf("exercises: no Go files in %s", dir)
+	}
+
+	var main bytes.Buffer
+	if err := harness.Execute(&main, e); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(work, "main.go"), main.Bytes(), 0o644); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(work, "go.mod"), []byte("module judge\n\ngo 1.23\n"), 0o644)
+}
+
+// readResults reads the harness's results, one line per vector it
+// finished; a run cut short leaves fewer lines than vectors
+func readResults(path string, vectors []Vector) ([]Case, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var cases []Case
+	lines := bufio.NewScanner(f)
+	lines.Buffer(nil, 16<<20)
+	for lines.Scan() && len(cases) < len(vectors) {
+		var r struct {
+			Got   json.RawMessage `json:"got"`
+			Pass  bool            `json:"pass"`
+			Panic string          `json:"panic"`
+			NS    int64           `json:"ns"`
+		}
+		if err := json.Unmarshal(lines.Bytes(), &r); err != nil {
+			// A line cut off as the harness was killed
+			break
+		}
+		v := vectors[len(cases)]
+		cases = append(cases, Case{
+			Input:    v.In,
+			Want:     v.Want,
+			Got:      r.Got,
+			Panic:    r.Panic,
+			Passed:   r.Pass,
+			Duration: time.Duration(r.NS),
+		})
+	}
+	return cases, lines.Err()
+}
+
+// firstLines returns up to n lines of s, as a stack trace can run to
+// thousands
+func firstLines(s string, n int) string {
+	lines := strings.SplitAfterN(s, "\n", n+1)
+	if len(lines) > n {
+		lines[n] = "...\n"
+	}
+	return strings.Join(lines, "")
+}
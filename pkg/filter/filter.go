@@ -0,0 +1,222 @@
+// Package filter parses and evaluates filter expressions such as
+//
+//	price > 20 AND author ~ "Kennedy"
+//	(title = "Go in Action" OR NOT price >= 30) AND id != 2
+//
+// An expression compares fields with literals: = and != test equality, <,
+// <=, > and >= order, and ~ a case-insensitive substring match on strings.
+// NOT binds tighter than AND, which binds tighter than OR, and parentheses
+// group; the keywords are case-insensitive. Strings are in double quotes
+// with \" and \\ as the only escapes. Numbers are decimals such as 20, -3
+// or 9.99, compared exactly rather than as float64, so price = 0.3 matches
+// an amount of 0.30 however it is stored.
+//
+// Parse checks the expression against the fields the caller allows, so a
+// parsed expression names only known fields and compares each with a
+// literal of its kind. Evaluating it then cannot fail:
+//
+//	expr, err := filter.Parse(`price > 20`, filter.Fields{"price": filter.Number})
+//	if err != nil {
+//		return err // a *filter.Error saying where and what is wrong
+//	}
+//	ok := expr.Match(func(field string) filter.Value {
+//		return filter.Num(big.NewRat(book.PriceCents, 100))
+//	})
+package filter
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Kind is the type of a field or literal
+type Kind int
+
+const (
+	String Kind = iota + 1
+	Number
+)
+
+func (k Kind) String() string {
+	switch k {
+	case String:
+		return "string"
+	case Number:
+		return "number"
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// Fields names the fields an expression may use, with their kinds
+type Fields map[string]Kind
+
+// Value is a field's value or a literal: a string or an exact number
+type Value struct {
+	kind Kind
+	str  string
+	num  *big.Rat
+	lit  string // a number literal as written, for String
+}
+
+// Str returns the string value s
+func Str(s string) Value { return Value{kind: String, str: s} }
+
+// Num returns the number value n
+func Num(n *big.Rat) Value { return Value{kind: Number, num: n} }
+
+// Int returns the number value n
+func Int(n int64) Value { return Num(new(big.Rat).SetInt64(n)) }
+
+// Kind returns the kind of v
+func (v Value) Kind() Kind { return v.kind }
+
+// String returns v as a literal would be written
+func (v Value) String() string {
+	switch {
+	case v.kind == String:
+		return quote(v.str)
+	case v.lit != "":
+		return v.lit
+	case v.num != nil:
+		return v.num.RatString()
+	}
+	return "<invalid>"
+}
+
+// quote writes s as a string literal
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// Op is a comparison operator
+type Op string
+
+const (
+	Eq       Op = "="
+	Ne       Op = "!="
+	Lt       Op = "<"
+	Le       Op = "<="
+	Gt       Op = ">"
+	Ge       Op = ">="
+	Contains Op = "~"
+)
+
+// Expr is a parsed expression: an *And, *Or, *Not or *Compare
+type Expr interface {
+	// Match reports whether an item satisfies the expression, given a
+	// function returning the item's value for each field
+	Match(field func(name string) Value) bool
+
+	// String returns the expression with only the parentheses it needs,
+	// which parses back to the same expression
+	String() string
+}
+
+// And matches when both its sides do
+type And struct{ Left, Right Expr }
+
+// Or matches when either of its sides does
+type Or struct{ Left, Right Expr }
+
+// Not matches when X does not
+type Not struct{ X Expr }
+
+// Compare compares a field with a literal value
+type Compare struct {
+	Field string
+	Op    Op
+	Value Value
+}
+
+func (e *And) Match(field func(string) Value) bool {
+	return e.Left.Match(field) && e.Right.Match(field)
+}
+
+func (e *Or) Match(field func(string) Value) bool {
+	return e.Left.Match(field) || e.Right.Match(field)
+}
+
+func (e *Not) Match(field func(string) Value) bool {
+	return !e.X.Match(field)
+}
+
+// Match compares the field's value with e.Value. A value of another kind
+// than the literal, which a field function should never return, matches
+// nothing.
+func (e *Compare) Match(field func(string) Value) bool {
+	v := field(e.Field)
+	if v.kind != e.Value.kind {
+		return false
+	}
+	var cmp int
+	switch v.kind {
+	case String:
+		if e.Op == Contains {
+			return strings.Contains(strings.ToLower(v.str), strings.ToLower(e.Value.str))
+		}
+		cmp = strings.Compare(v.str, e.Value.str)
+	case Number:
+		if v.num == nil {
+			return false
+		}
+		cmp = v.num.Cmp(e.Value.num)
+	default:
+		return false
+	}
+	switch e.Op {
+	case Eq:
+		return cmp == 0
+	case Ne:
+		return cmp != 0
+	case Lt:
+		return cmp < 0
+	case Le:
+		return cmp <= 0
+	case Gt:
+		return cmp > 0
+	case Ge:
+		return cmp >= 0
+	}
+	return false
+}
+
+func (e *And) String() string     { return format(e.Left, precAnd) + " AND " + format(e.Right, precUnary) }
+func (e *Or) String() string      { return format(e.Left, precOr) + " OR " + format(e.Right, precAnd) }
+func (e *Not) String() string     { return "NOT " + format(e.X, precUnary) }
+func (e *Compare) String() string { return e.Field + " " + string(e.Op) + " " + e.Value.String() }
+
+// Precedences, loosest first
+const (
+	precOr = iota + 1
+	precAnd
+	precUnary
+)
+
+// format writes e, in parentheses if it binds more loosely than need. AND
+// and OR group to the left, so only a right operand of the same operator
+// needs them.
+func format(e Expr, need int) string {
+	prec := precUnary
+	switch e.(type) {
+	case *Or:
+		prec = precOr
+	case *And:
+		prec = precAnd
+	}
+	if prec < need {
+		return "(" + e.String() + ")"
+	}
+	return e.String()
+}
+
+// Error reports what is wrong with an expression and the byte offset in
+// it where the problem is
+type Error struct {
+	Offset int
+	Msg    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("filter: %s at offset %d", e.Msg, e.Offset)
+}
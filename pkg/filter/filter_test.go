@@ -0,0 +1,193 @@
+package filter
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+var bookFields = Fields{"id": Number, "title": String, "author": String, "price": Number}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		src, want string
+	}{
+		{`price > 20`, `price > 20`},
+		{`price>20 AND author~"Kennedy"`, `price > 20 AND author ~ "Kennedy"`},
+		// AND binds tighter than OR, and both group to the left
+		{`id = 1 OR id = 2 AND id = 3`, `id = 1 OR id = 2 AND id = 3`},
+		{`(id = 1 OR id = 2) AND id = 3`, `(id = 1 OR id = 2) AND id = 3`},
+		{`id = 1 OR (id = 2 OR id = 3)`, `id = 1 OR (id = 2 OR id = 3)`},
+		{`((id = 1))`, `id = 1`},
+		{`not id = 1 and NOT (id = 2 Or id = 3)`, `NOT id = 1 AND NOT (id = 2 OR id = 3)`},
+		{`NOT NOT id = 1`, `NOT NOT id = 1`},
+		{`title != "say \"hi\" \\ bye"`, `title != "say \"hi\" \\ bye"`},
+		{`price <= 9.990 AND price >= -3 AND id < 10`, `price <= 9.990 AND price >= -3 AND id < 10`},
+		{"\ttitle\n=\r\"\"", `title = ""`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.src, func(t *testing.T) {
+			expr, err := Parse(tc.src, bookFields)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if got := expr.String(); got != tc.want {
+				t.Errorf("String() = %s; want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParse_AST(t *testing.T) {
+	expr, err := Parse(`author ~ "k" OR NOT price < 10 AND id = 2`, bookFields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	or, ok := expr.(*Or)
+	if !ok {
+		t.Fatalf("root is %T; want *Or", expr)
+	}
+	if c, ok := or.Left.(*Compare); !ok || c.Field != "author" || c.Op != Contains || c.Value.Kind() != String {
+		t.Errorf("left = %#v; want author ~ string", or.Left)
+	}
+	and, ok := or.Right.(*And)
+	if !ok {
+		t.Fatalf("right is %T; want *And", or.Right)
+	}
+	if _, ok := and.Left.(*Not); !ok {
+		t.Errorf("right.Left is %T; want *Not", and.Left)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		src    string
+		offset int
+		msg    string
+	}{
+		{``, 0, "empty filter"},
+		{`   `, 0, "empty filter"},
+		{`price >`, 7, "expected a string or number, found end of filter"},
+		{`price 20`, 6, "expected an operator"},
+		{`isbn = "1"`, 0, `unknown field "isbn"`},
+		{`Price = 1`, 0, `unknown field "Price"`},
+		{`price = "cheap"`, 8, "price is a number, compared with a string"},
+		{`title > 3`, 8, "title is a string, compared with a number"},
+		{`price ~ 3`, 6, "~ needs a string field"},
+		{`(price > 1`, 10, `expected ")"`},
+		{`price > 1)`, 9, `unexpected ")"`},
+		{`price > 1 price < 2`, 10, `unexpected "price"`},
+		{`price > 1 AND`, 13, "expected a field name"},
+		{`AND price > 1`, 0, "expected a field name"},
+		{`price ! 1`, 6, `expected "!="`},
+		{`price == 1`, 7, "expected a string or number"},
+		{`title = "open`, 8, "unterminated string"},
+		{`title = "a\nb"`, 10, "invalid escape"},
+		{`price = 1.`, 8, "invalid number"},
+		{`price = -`, 8, "invalid number"},
+		{`price = .5`, 8, "unexpected character '.'"},
+		{`title = 'x'`, 8, "unexpected character"},
+		{strings.Repeat("(", MaxDepth+1) + "id = 1" + strings.Repeat(")", MaxDepth+1), MaxDepth, "nested more than"},
+		{strings.Repeat("NOT ", MaxDepth+1) + "id = 1", 4 * MaxDepth, "nested more than"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.src, func(t *testing.T) {
+			_, err := Parse(tc.src, bookFields)
+			var ferr *Error
+			if !errors.As(err, &ferr) {
+				t.Fatalf("Parse error = %v; want a *filter.Error", err)
+			}
+			if ferr.Offset != tc.offset || !strings.Contains(ferr.Msg, tc.msg) {
+				t.Errorf("error = %q at %d; want %q at %d", ferr.Msg, ferr.Offset, tc.msg, tc.offset)
+			}
+		})
+	}
+	// As deep as allowed is fine
+	if _, err := Parse(strings.Repeat("(", MaxDepth)+"id = 1"+strings.Repeat(")", MaxDepth), bookFields); err != nil {
+		t.Errorf("nested %d deep: %v", MaxDepth, err)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	book := map[string]Value{
+		"id":     Int(3),
+		"title":  Str("Go in Action"),
+		"author": Str("William Kennedy"),
+		"price":  Num(big.NewRat(2499, 100)),
+	}
+	field := func(name string) Value { return book[name] }
+	tests := []struct {
+		src  string
+		want bool
+	}{
+		{`price > 20 AND author ~ "kennedy"`, true},
+		{`price > 25 AND author ~ "kennedy"`, false},
+		{`price = 24.99`, true},
+		{`price = 24.990`, true},
+		{`price != 24.99`, false},
+		{`price < 24.99 OR price >= 25`, false},
+		{`price <= 24.99`, true},
+		{`id = 3`, true},
+		{`id > -1`, true},
+		{`title = "Go in Action"`, true},
+		{`title = "go in action"`, false}, // = is exact; ~ ignores case
+		{`title ~ "IN ACT"`, true},
+		{`title ~ ""`, true},
+		{`title < "H" AND title > "A"`, true},
+		{`NOT title ~ "go"`, false},
+		{`id = 1 OR id = 2 OR id = 3`, true},
+		{`(id = 1 OR id = 3) AND NOT price > 30`, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.src, func(t *testing.T) {
+			expr, err := Parse(tc.src, bookFields)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := expr.Match(field); got != tc.want {
+				t.Errorf("Match = %v; want %v", got, tc.want)
+			}
+		})
+	}
+
+	// A field function returning the wrong kind matches nothing
+	expr, _ := Parse(`id = 3`, bookFields)
+	if expr.Match(func(string) Value { return Str("3") }) {
+		t.Error("a string matched a number comparison")
+	}
+}
+
+// FuzzParse checks Parse never panics, and that what it parses prints as
+// an expression that parses back to the same thing
+func FuzzParse(f *testing.F) {
+	for _, seed := range []string{
+		`price > 20 AND author ~ "Kennedy"`,
+		`(title = "Go in Action" OR NOT price >= 30) AND id != 2`,
+		`NOT (id = 1 OR id = 2) AND title ~ "a\"b\\c"`,
+		`price <= -9.990`,
+		`((((id = 1))))`,
+		`title = "`,
+		`id = 1 OR`,
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, src string) {
+		expr, err := Parse(src, bookFields)
+		if err != nil {
+			var ferr *Error
+			if !errors.As(err, &ferr) || ferr.Offset < 0 || ferr.Offset > len(src) {
+				t.Fatalf("Parse(%q) error = %#v; want a *filter.Error within the input", src, err)
+			}
+			return
+		}
+		printed := expr.String()
+		again, err := Parse(printed, bookFields)
+		if err != nil {
+			t.Fatalf("Parse(%q) printed %q, which does not parse: %v", src, printed, err)
+		}
+		if again.String() != printed {
+			t.Fatalf("Parse(%q) printed %q, which prints as %q", src, printed, again.String())
+		}
+	})
+}
@@ -0,0 +1,284 @@
+package filter
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// MaxDepth bounds how deeply parentheses and NOTs may nest, so that a
+// hostile expression cannot exhaust the stack of the recursive parser
+const MaxDepth = 64
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokOp
+	tokLParen
+	tokRParen
+	tokAnd
+	tokOr
+	tokNot
+)
+
+type token struct {
+	kind tokenKind
+	text string // the identifier, operator or number as written; a string unquoted
+	pos  int
+}
+
+// describe names t for an error message
+func (t token) describe() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of filter"
+	case tokString:
+		return "string " + quote(t.text)
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// lex splits src into tokens, ending with tokEOF
+func lex(src string) ([]token, error) {
+	var toks []token
+	for i := 0; ; {
+		for i < len(src) && (src[i] == ' ' || src[i] == '\t' || src[i] == '\n' || src[i] == '\r') {
+			i++
+		}
+		if i == len(src) {
+			return append(toks, token{kind: tokEOF, pos: i}), nil
+		}
+		start := i
+		c := src[i]
+		switch {
+		case c == '(':
+			toks = append(toks, token{tokLParen, "(", i})
+			i++
+		case c == ')':
+			toks = append(toks, token{tokRParen, ")", i})
+			i++
+		case c == '=' || c == '~':
+			toks = append(toks, token{tokOp, src[i : i+1], i})
+			i++
+		case c == '<' || c == '>' || c == '!':
+			i++
+			if i < len(src) && src[i] == '=' {
+				i++
+			} else if c == '!' {
+				return nil, &Error{start, `expected "!="`}
+			}
+			toks = append(toks, token{tokOp, src[start:i], start})
+		case c == '"':
+			s, n, err := lexString(src[i:])
+			if err != nil {
+				err.Offset += i
+				return nil, err
+			}
+			toks = append(toks, token{tokString, s, i})
+			i += n
+		case c == '-' || isDigit(c):
+			i++
+			for i < len(src) && isDigit(src[i]) {
+				i++
+			}
+			if i < len(src) && src[i] == '.' {
+				i++
+				for i < len(src) && isDigit(src[i]) {
+					i++
+				}
+			}
+			lit := src[start:i]
+			if !isDigit(lit[len(lit)-1]) || lit == "-" || strings.HasPrefix(lit, "-.") {
+				return nil, &Error{start, fmt.Sprintf("invalid number %q", lit)}
+			}
+			toks = append(toks, token{tokNumber, lit, start})
+		case isIdentStart(c):
+			for i < len(src) && (isIdentStart(src[i]) || isDigit(src[i])) {
+				i++
+			}
+			word := src[start:i]
+			kind := tokIdent
+			switch strings.ToUpper(word) {
+			case "AND":
+				kind = tokAnd
+			case "OR":
+				kind = tokOr
+			case "NOT":
+				kind = tokNot
+			}
+			toks = append(toks, token{kind, word, start})
+		default:
+			return nil, &Error{start, fmt.Sprintf("unexpected character %q", rune(c))}
+		}
+	}
+}
+
+// lexString reads the string literal src starts with, returning it
+// unquoted and how many bytes of src it took
+func lexString(src string) (string, int, *Error) {
+	var b strings.Builder
+	for i := 1; i < len(src); i++ {
+		switch src[i] {
+		case '"':
+			return b.String(), i + 1, nil
+		case '\\':
+			i++
+			if i == len(src) {
+				return "", 0, &Error{0, "unterminated string"}
+			}
+			if src[i] != '"' && src[i] != '\\' {
+				return "", 0, &Error{i - 1, `invalid escape: only \" and \\ are allowed`}
+			}
+			b.WriteByte(src[i])
+		default:
+			b.WriteByte(src[i])
+		}
+	}
+	return "", 0, &Error{0, "unterminated string"}
+}
+
+func isDigit(c byte) bool      { return '0' <= c && c <= '9' }
+func isIdentStart(c byte) bool { return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c == '_' }
+
+// Parse parses src, allowing only the comparisons fields permits. Errors
+// are *Error.
+func Parse(src string, fields Fields) (Expr, error) {
+	toks, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks, fields: fields}
+	if p.peek().kind == tokEOF {
+		return nil, &Error{0, "empty filter"}
+	}
+	expr, err := p.parseOr(0)
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, &Error{t.pos, "unexpected " + t.describe()}
+	}
+	return expr, nil
+}
+
+// parser is a recursive-descent parser with a method per rule of the
+// grammar, loosest binding first:
+//
+//	or      = and { "OR" and }
+//	and     = unary { "AND" unary }
+//	unary   = "NOT" unary | "(" or ")" | compare
+//	compare = field op literal
+type parser struct {
+	toks   []token
+	next   int
+	fields Fields
+}
+
+func (p *parser) peek() token { return p.toks[p.next] }
+
+func (p *parser) take() token {
+	t := p.toks[p.next]
+	if t.kind != tokEOF {
+		p.next++
+	}
+	return t
+}
+
+func (p *parser) parseOr(depth int) (Expr, error) {
+	left, err := p.parseAnd(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokOr {
+		p.take()
+		right, err := p.parseAnd(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = &Or{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd(depth int) (Expr, error) {
+	left, err := p.parseUnary(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokAnd {
+		p.take()
+		right, err := p.parseUnary(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = &And{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary(depth int) (Expr, error) {
+	t := p.peek()
+	if (t.kind == tokNot || t.kind == tokLParen) && depth >= MaxDepth {
+		return nil, &Error{t.pos, fmt.Sprintf("nested more than %d deep", MaxDepth)}
+	}
+	switch t.kind {
+	case tokNot:
+		p.take()
+		x, err := p.parseUnary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return &Not{x}, nil
+	case tokLParen:
+		p.take()
+		x, err := p.parseOr(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if t := p.take(); t.kind != tokRParen {
+			return nil, &Error{t.pos, `expected ")", found ` + t.describe()}
+		}
+		return x, nil
+	}
+	return p.parseCompare()
+}
+
+func (p *parser) parseCompare() (Expr, error) {
+	name := p.take()
+	if name.kind != tokIdent {
+		return nil, &Error{name.pos, "expected a field name, found " + name.describe()}
+	}
+	kind, ok := p.fields[name.text]
+	if !ok {
+		return nil, &Error{name.pos, fmt.Sprintf("unknown field %q", name.text)}
+	}
+	op := p.take()
+	if op.kind != tokOp {
+		return nil, &Error{op.pos, "expected an operator after " + name.text + ", found " + op.describe()}
+	}
+	lit := p.take()
+	var v Value
+	switch lit.kind {
+	case tokString:
+		v = Str(lit.text)
+	case tokNumber:
+		n, ok := new(big.Rat).SetString(lit.text)
+		if !ok {
+			return nil, &Error{lit.pos, fmt.Sprintf("invalid number %q", lit.text)}
+		}
+		v = Value{kind: Number, num: n, lit: lit.text}
+	default:
+		return nil, &Error{lit.pos, "expected a string or number, found " + lit.describe()}
+	}
+	if v.kind != kind {
+		return nil, &Error{lit.pos, fmt.Sprintf("%s is a %s, compared with a %s", name.text, kind, v.kind)}
+	}
+	if Op(op.text) == Contains && kind != String {
+		return nil, &Error{op.pos, fmt.Sprintf("~ needs a string field, and %s is a %s", name.text, kind)}
+	}
+	return &Compare{Field: name.text, Op: Op(op.text), Value: v}, nil
+}
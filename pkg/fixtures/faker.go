@@ -0,0 +1,61 @@
+package fixtures
+
+import "math/rand/v2"
+
+// Faker makes up fake data from a seed. The same seed always gives the
+// same data, so a load test can be run again against the same records,
+// and a failure reproduced from the seed it printed.
+//
+// Everything is derived from a PCG generator's raw output, whose sequence
+// is fixed, rather than from math/rand's helpers, whose algorithms may
+// change between Go releases. A Faker is for one goroutine.
+type Faker struct {
+	src *rand.PCG
+}
+
+// NewFaker returns a Faker seeded with seed
+func NewFaker(seed uint64) *Faker {
+	return &Faker{src: rand.NewPCG(seed, 0x9e3779b97f4a7c15)}
+}
+
+// Intn returns a number in [0, n). It panics if n <= 0.
+func (f *Faker) Intn(n int) int {
+	if n <= 0 {
+		panic("fixtures: Intn with n <= 0")
+	}
+	// The modulo bias is at most n/2^64, far too small to matter here
+	return int(f.src.Uint64() % uint64(n))
+}
+
+// Between returns a number in [lo, hi]
+func (f *Faker) Between(lo, hi int) int {
+	return lo + f.Intn(hi-lo+1)
+}
+
+// Pick returns one of items. It panics if there are none.
+func (f *Faker) Pick(items ...string) string {
+	return items[f.Intn(len(items))]
+}
+
+// Name returns a person's full name
+func (f *Faker) Name() string {
+	return f.Pick(firstNames...) + " " + f.Pick(lastNames...)
+}
+
+// Title returns a title such as "The Practical Guide to Channels"
+func (f *Faker) Title() string {
+	switch f.Intn(3) {
+	case 0:
+		return "The " + f.Pick(adjectives...) + " " + f.Pick(nouns...)
+	case 1:
+		return f.Pick(adjectives...) + " " + f.Pick(nouns...) + " in Go"
+	}
+	return "The " + f.Pick(adjectives...) + " Guide to " + f.Pick(nouns...)
+}
+
+var (
+	firstNames = []string{"Ada", "Alan", "Barbara", "Brian", "Dennis", "Edsger", "Frances", "Grace", "John", "Katherine", "Ken", "Linus", "Margaret", "Niklaus", "Radia", "Rob", "Robert", "Sophie", "Tim", "Yukihiro"}
+	lastNames  = []string{"Allen", "Backus", "Cox", "Dijkstra", "Hamilton", "Hopper", "Johnson", "Kernighan", "Knuth", "Liskov", "Lovelace", "McCarthy", "Perlman", "Pike", "Ritchie", "Thompson", "Turing", "Wilson", "Wirth", "Griesemer"}
+	adjectives = []string{"Practical", "Concurrent", "Idiomatic", "Effective", "Pragmatic", "Modern", "Essential", "Advanced", "Distributed", "Reliable", "Elegant", "Complete"}
+	nouns      = []string{"Channels", "Goroutines", "Interfaces", "Generics", "Testing", "Networking", "Databases", "Systems", "Microservices", "Algorithms", "Tooling", "Patterns"}
+)
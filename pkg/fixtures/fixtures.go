@@ -0,0 +1,101 @@
+// Package fixtures loads the records a store is seeded with from JSON or
+// YAML files, usually embedded in the binary with go:embed, and makes up
+// fake records deterministically from a seed (see Faker).
+//
+// A fixture file is a list of flat records: in JSON an array of objects,
+// in YAML a sequence of mappings, the subset of YAML pkg/yamlx reads.
+//
+//	# books.yaml
+//	- title: Go in Action
+//	  author: William Kennedy
+//	  price: 24.99
+//
+// Both formats decode into T as encoding/json would decode the JSON, so
+// T's json tags and UnmarshalJSON methods apply to YAML files too. A key T
+// has no field for is an error, so a typo in a fixture is not silently
+// dropped. Encode writes records, such as ones a Faker made up, back out
+// as a fixture file.
+package fixtures
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/rehan/go-interview-prep/pkg/yamlx"
+)
+
+// Load reads the records in the file name of fsys, a .json, .yaml or .yml
+// file, into a []T
+func Load[T any](fsys fs.FS, name string) ([]T, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, fmt.Errorf("fixtures: %w", err)
+	}
+	records, err := Decode[T](path.Ext(name), data)
+	if err != nil {
+		return nil, fmt.Errorf("fixtures: %s: %w", name, err)
+	}
+	return records, nil
+}
+
+// Decode reads the records in data, in the format the file extension ext
+// names, into a []T
+func Decode[T any](ext string, data []byte) ([]T, error) {
+	var raw []json.RawMessage
+	switch strings.ToLower(ext) {
+	case ".json":
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("want an array of objects: %w", err)
+		}
+	case ".yaml", ".yml":
+		records, err := yamlx.ParseSequence(data)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			// Maps marshal with their keys sorted, which is fine: only the
+			// values matter to the decoder
+			b, err := json.Marshal(record)
+			if err != nil {
+				return nil, err
+			}
+			raw = append(raw, b)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported file type %q", ext)
+	}
+
+	out := make([]T, len(raw))
+	for i, r := range raw {
+		dec := json.NewDecoder(bytes.NewReader(r))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&out[i]); err != nil {
+			return nil, fmt.Errorf("record %d: %w", i+1, err)
+		}
+	}
+	return out, nil
+}
+
+// Encode writes records in the format the file extension ext names, as a
+// file Decode reads back into the same records
+func Encode[T any](ext string, records []T) ([]byte, error) {
+	if records == nil {
+		records = []T{}
+	}
+	switch strings.ToLower(ext) {
+	case ".json":
+		data, err := json.MarshalIndent(records, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	case ".yaml", ".yml":
+		return yamlx.Marshal(records)
+	default:
+		return nil, fmt.Errorf("unsupported file type %q", ext)
+	}
+}
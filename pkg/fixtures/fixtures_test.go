@@ -0,0 +1,193 @@
+package fixtures
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+type book struct {
+	Title    string  `json:"title"`
+	Author   string  `json:"author"`
+	Price    float64 `json:"price"`
+	InPrint  bool    `json:"in_print"`
+	Subtitle *string `json:"subtitle"`
+}
+
+func TestLoad_JSONAndYAMLAgree(t *testing.T) {
+	fsys := fstest.MapFS{
+		"books.json": {Data: []byte(`[
+			{"title": "Go in Action", "author": "William Kennedy", "price": 24.99, "in_print": true},
+			{"title": "It's \"Go\": a # story", "author": "A", "price": 1e1, "subtitle": null}
+		]`)},
+		"books.yaml": {Data: []byte(`---
+# Two books
+- title: Go in Action   # the first
+  author: William Kennedy
+  price: 24.99
+  in_print: true
+
+-
+  title: "It's \"Go\": a # story"
+  author: 'A'
+  price: 1e1
+  subtitle: ~
+`)},
+		"books.yml": {Data: []byte("  - title: 'It''s \"Go\": a # story'  # trailing\r\n    author: A\r\n    price: 10\r\n    subtitle:\r\n")},
+	}
+	want := []book{
+		{Title: "Go in Action", Author: "William Kennedy", Price: 24.99, InPrint: true},
+		{Title: `It's "Go": a # story`, Author: "A", Price: 10},
+	}
+	for _, name := range []string{"books.json", "books.yaml"} {
+		got, err := Load[book](fsys, name)
+		if err != nil {
+			t.Fatalf("Load(%s): %v", name, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Load(%s) = %+v; want %+v", name, got, want)
+		}
+	}
+	got, err := Load[book](fsys, "books.yml")
+	if err != nil || !reflect.DeepEqual(got, want[1:]) {
+		t.Errorf("Load(books.yml) = %+v, %v; want %+v", got, err, want[1:])
+	}
+}
+
+func TestEncode_RoundTrip(t *testing.T) {
+	subtitle := "a subtitle: with # marks"
+	f := NewFaker(7)
+	books := []book{
+		{Title: `It's "Go": a # story`, Author: "A", Price: 10, Subtitle: &subtitle},
+		{Title: "true", Author: "", Price: 0.1, InPrint: true},
+	}
+	for range 5 {
+		books = append(books, book{Title: f.Title(), Author: f.Name(), Price: float64(f.Between(100, 5000)) / 100})
+	}
+
+	for _, ext := range []string{".json", ".yaml"} {
+		for _, in := range [][]book{books, {}, nil} {
+			data, err := Encode(ext, in)
+			if err != nil {
+				t.Fatalf("Encode(%s): %v", ext, err)
+			}
+			got, err := Decode[book](ext, data)
+			if err != nil {
+				t.Fatalf("Decode(%s) of\n%s: %v", ext, data, err)
+			}
+			if len(in) == 0 && len(got) == 0 {
+				continue
+			}
+			if !reflect.DeepEqual(got, in) {
+				t.Errorf("round trip through %s\n%s= %+v; want %+v", ext, data, got, in)
+			}
+		}
+	}
+	if _, err := Encode(".toml", books); err == nil {
+		t.Error("Encode(.toml): want an error")
+	}
+}
+
+func TestDecode_Empty(t *testing.T) {
+	for _, tc := range []struct{ ext, data string }{
+		{".yaml", ""},
+		{".yaml", "# nothing yet\n---\n"},
+		{".yaml", "[]\n"},
+		{".json", "[]"},
+	} {
+		got, err := Decode[book](tc.ext, []byte(tc.data))
+		if err != nil || len(got) != 0 {
+			t.Errorf("Decode(%s, %q) = %v, %v; want no records", tc.ext, tc.data, got, err)
+		}
+	}
+}
+
+func TestDecode_Errors(t *testing.T) {
+	tests := []struct {
+		ext, data, want string
+	}{
+		{".toml", `title = "x"`, `unsupported file type ".toml"`},
+		{".json", `{"title": "x"}`, "want an array of objects"},
+		{".json", `[{"title": "x", "isbn": "1"}]`, `record 1: json: unknown field "isbn"`},
+		{".yaml", "- title: x\n- title: y\n  isbn: 1\n", `record 2: json: unknown field "isbn"`},
+		{".yaml", "- title: x\n  price: cheap\n", "record 1: json: cannot unmarshal string"},
+		{".yaml", "title: x\n", `line 1: expected a list item starting with "- "`},
+		{".yaml", "- title: x\nauthor: y\n", `line 2: expected a list item starting with "- "`},
+		{".yaml", "- title: x\n    author: y\n", "line 2: nested values are not supported"},
+		{".yaml", "-   title: x\n  author: y\n", "line 2: key indented differently"},
+		{".yaml", "- title: x\n  - author: y\n", "line 2: nested values are not supported"},
+		{".yaml", "- title: x\n\t author: y\n", "line 2: tabs are not allowed"},
+		{".yaml", "- title: x\n  title: y\n", `line 2: key "title" appears twice`},
+		{".yaml", "- title\n", `line 1: expected "key: value"`},
+		{".yaml", "- {title: x}\n", "line 1: flow collections are not supported"},
+		{".yaml", "- title: x\n  [a]: y\n", "line 2: flow collections are not supported"},
+		{".yaml", "- title: [a, b]\n", `line 1: title: values starting with '[' are not supported`},
+		{".yaml", "- title: |\n", `line 1: title: values starting with '|' are not supported`},
+		{".yaml", "- title: \"open\n", "line 1: title: bad double-quoted string"},
+		{".yaml", "- title: 'open\n", "line 1: title: unterminated single-quoted string"},
+		{".yaml", "- title: \"a\" b\n", `line 1: title: unexpected "b" after the string`},
+		{".yaml", "[]\n- title: x\n", `line 2: unexpected "- title: x" after []`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.data, func(t *testing.T) {
+			_, err := Decode[book](tc.ext, []byte(tc.data))
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("error = %v; want it to contain %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	fsys := fstest.MapFS{"books.yaml": {Data: []byte("- title: x\n  isbn: 1\n")}}
+	if _, err := Load[book](fsys, "missing.yaml"); err == nil || !strings.HasPrefix(err.Error(), "fixtures: ") {
+		t.Errorf("missing file: error = %v", err)
+	}
+	if _, err := Load[book](fsys, "books.yaml"); err == nil || !strings.HasPrefix(err.Error(), "fixtures: books.yaml: record 1: ") {
+		t.Errorf("bad record: error = %v; want it to name the file and record", err)
+	}
+}
+
+func TestFaker_Deterministic(t *testing.T) {
+	draw := func(seed uint64) []string {
+		f := NewFaker(seed)
+		var out []string
+		for range 20 {
+			out = append(out, f.Name(), f.Title())
+		}
+		return out
+	}
+	if a, b := draw(1), draw(1); !reflect.DeepEqual(a, b) {
+		t.Errorf("seed 1 gave %q, then %q", a, b)
+	}
+	if a, b := draw(1), draw(2); reflect.DeepEqual(a, b) {
+		t.Error("seeds 1 and 2 gave the same data")
+	}
+
+	// Pinned so that a change to the generator, which would change every
+	// seeded data set, is noticed
+	f := NewFaker(42)
+	got := []string{f.Name(), f.Title()}
+	if want := []string{"Ada Pike", "Pragmatic Goroutines in Go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("seed 42 gave %q; want %q", got, want)
+	}
+}
+
+func TestFaker_Between(t *testing.T) {
+	f := NewFaker(7)
+	seen := make(map[int]bool)
+	for range 1000 {
+		n := f.Between(-2, 2)
+		if n < -2 || n > 2 {
+			t.Fatalf("Between(-2, 2) = %d", n)
+		}
+		seen[n] = true
+	}
+	if len(seen) != 5 {
+		t.Errorf("Between(-2, 2) gave only %v in 1000 draws", seen)
+	}
+	if n := f.Between(3, 3); n != 3 {
+		t.Errorf("Between(3, 3) = %d", n)
+	}
+}
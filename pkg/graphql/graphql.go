@@ -0,0 +1,884 @@
+// Package graphql executes GraphQL requests against a schema of Go
+// resolvers. A schema is built from Objects whose Fields give their type,
+// their arguments and a Resolver; a field without one reads the struct
+// field (by JSON name) or map key of the same name from its parent:
+//
+//	book := &graphql.Object{Name: "Book", Fields: graphql.Fields{
+//		"id":    {Type: graphql.NewNonNull(graphql.Int)},
+//		"title": {Type: graphql.NewNonNull(graphql.String)},
+//	}}
+//	schema := &graphql.Schema{Query: &graphql.Object{Name: "Query", Fields: graphql.Fields{
+//		"book": {
+//			Type: book,
+//			Args: graphql.Args{"id": {Type: graphql.NewNonNull(graphql.Int)}},
+//			Resolve: func(p graphql.ResolveParams) (any, error) {
+//				return store.Book(p.Args["id"].(int))
+//			},
+//		},
+//	}}}
+//	resp := schema.Execute(ctx, graphql.Request{Query: `{ book(id: 1) { title } }`})
+//	json.NewEncoder(w).Encode(resp)
+//
+// Execute parses and validates the request, then resolves the selected
+// fields one at a time, in the order they were selected, for queries as
+// well as mutations. Only the fields selected are resolved and returned.
+// A resolver's error, or panic, becomes an entry in Response.Errors with
+// the field's path, and the field is null; if the field is non-null, its
+// parent is null instead, and so on up.
+//
+// This is a subset of GraphQL: queries and mutations with aliases,
+// arguments, variables, lists, non-null types, input objects and
+// __typename. Fragments, directives, enums, interfaces, unions,
+// subscriptions and introspection are not supported.
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Type is a GraphQL type: a *Scalar, *Object, *InputObject, *List or
+// *NonNull
+type Type interface {
+	String() string
+}
+
+// Scalar is a leaf type
+type Scalar struct {
+	Name string
+
+	// Serialize converts a resolved value to its JSON form
+	Serialize func(v any) (any, error)
+
+	// ParseValue converts an input value to the Go value resolvers get. v
+	// is a literal from the query (int, float64, string or bool) or a
+	// variable as decoded from JSON (float64 or json.Number for numbers).
+	ParseValue func(v any) (any, error)
+}
+
+func (s *Scalar) String() string { return s.Name }
+
+// The built-in scalars
+var (
+	// Int is a signed 32-bit integer, given to resolvers as an int
+	Int = &Scalar{Name: "Int", Serialize: toInt, ParseValue: toInt}
+	// Float is given to resolvers as a float64
+	Float = &Scalar{Name: "Float", Serialize: toFloat, ParseValue: toFloat}
+	// String is given to resolvers as a string
+	String = &Scalar{Name: "String", Serialize: toString, ParseValue: toString}
+	// Boolean is given to resolvers as a bool
+	Boolean = &Scalar{Name: "Boolean", Serialize: toBool, ParseValue: toBool}
+	// ID is a string or integer identifier, given to resolvers and
+	// serialized as a string
+	ID = &Scalar{Name: "ID", Serialize: toID, ParseValue: toID}
+)
+
+func toInt(v any) (any, error) {
+	if n, ok := v.(json.Number); ok {
+		v = string(n)
+		if i, err := n.Int64(); err == nil {
+			v = i
+		}
+	}
+	rv := reflect.ValueOf(v)
+	var n int64
+	switch {
+	case rv.CanInt():
+		n = rv.Int()
+	case rv.CanUint() && rv.Uint() <= math.MaxInt32:
+		n = int64(rv.Uint())
+	case rv.CanFloat() && rv.Float() == math.Trunc(rv.Float()) && math.Abs(rv.Float()) <= math.MaxInt32+1:
+		n = int64(rv.Float())
+	default:
+		return nil, fmt.Errorf("Int cannot represent non-integer value: %s", describe(v))
+	}
+	if n < math.MinInt32 || n > math.MaxInt32 {
+		return nil, fmt.Errorf("Int cannot represent non 32-bit signed integer value: %d", n)
+	}
+	return int(n), nil
+}
+
+func toFloat(v any) (any, error) {
+	if n, ok := v.(json.Number); ok {
+		return n.Float64()
+	}
+	rv := reflect.ValueOf(v)
+	switch {
+	case rv.CanInt():
+		return float64(rv.Int()), nil
+	case rv.CanUint():
+		return float64(rv.Uint()), nil
+	case rv.CanFloat():
+		return rv.Float(), nil
+	}
+	return nil, fmt.Errorf("Float cannot represent non numeric value: %s", describe(v))
+}
+
+func toString(v any) (any, error) {
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.String {
+		if _, ok := v.(json.Number); !ok {
+			return rv.String(), nil
+		}
+	}
+	return nil, fmt.Errorf("String cannot represent a non string value: %s", describe(v))
+}
+
+func toBool(v any) (any, error) {
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Bool {
+		return rv.Bool(), nil
+	}
+	return nil, fmt.Errorf("Boolean cannot represent a non boolean value: %s", describe(v))
+}
+
+func toID(v any) (any, error) {
+	if s, err := toString(v); err == nil {
+		return s, nil
+	}
+	if n, err := toInt(v); err == nil {
+		return strconv.Itoa(n.(int)), nil
+	}
+	return nil, fmt.Errorf("ID cannot represent value: %s", describe(v))
+}
+
+// describe formats an input or resolved value for an error message
+func describe(v any) string {
+	switch v := v.(type) {
+	case string:
+		return strconv.Quote(v)
+	case EnumValue:
+		return string(v)
+	case NullValue, nil:
+		return "null"
+	case ListValue, ObjectValue:
+		return fmt.Sprintf("%T", v)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// Object is an output type with fields, each of which is resolved from the
+// object's value
+type Object struct {
+	Name   string
+	Fields Fields
+}
+
+func (o *Object) String() string { return o.Name }
+
+// Fields are an object's fields by name
+type Fields map[string]*Field
+
+// Field is one field of an Object
+type Field struct {
+	Type Type
+	Args Args
+
+	// Resolve returns the field's value. If it is nil the field is read
+	// from the parent's value: the struct field with this JSON name, or
+	// failing that this name in any case, or the map entry with this key.
+	Resolve Resolver
+}
+
+// Resolver returns a field's value. A nil pointer, map or interface is
+// null; a nil slice is an empty list.
+type Resolver func(p ResolveParams) (any, error)
+
+// ResolveParams are what a resolver is called with
+type ResolveParams struct {
+	Context context.Context
+	Source  any            // the parent object's value; nil for root fields
+	Args    map[string]any // the arguments given, and the defaults of those not
+}
+
+// Args are a field's or input object's arguments by name
+type Args map[string]*Arg
+
+// Arg is an argument, or a field of an input object
+type Arg struct {
+	Type    Type
+	Default any // used when the argument is not given, unless nil
+}
+
+// InputObject is an argument type with fields, given to resolvers as a
+// map[string]any
+type InputObject struct {
+	Name   string
+	Fields Args
+}
+
+func (o *InputObject) String() string { return o.Name }
+
+// List is a list of OfType, given to resolvers as a []any
+type List struct {
+	OfType Type
+}
+
+// NewList returns the type [of]
+func NewList(of Type) *List { return &List{OfType: of} }
+
+func (l *List) String() string { return "[" + l.OfType.String() + "]" }
+
+// NonNull is OfType without null
+type NonNull struct {
+	OfType Type
+}
+
+// NewNonNull returns the type of!
+func NewNonNull(of Type) *NonNull { return &NonNull{OfType: of} }
+
+func (n *NonNull) String() string { return n.OfType.String() + "!" }
+
+// namedType returns t without its list and non-null wrappers
+func namedType(t Type) Type {
+	for {
+		switch w := t.(type) {
+		case *List:
+			t = w.OfType
+		case *NonNull:
+			t = w.OfType
+		default:
+			return t
+		}
+	}
+}
+
+func isNonNull(t Type) bool {
+	_, ok := t.(*NonNull)
+	return ok
+}
+
+// Schema is the root types requests start from
+type Schema struct {
+	Query    *Object
+	Mutation *Object // nil if there are no mutations
+}
+
+// Request is a GraphQL request, as POSTed in a JSON body
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+// Response is the result of a request. Data is absent when the request
+// could not be executed at all, and null when an error in a non-null
+// root field nulled it; otherwise it is a *Map.
+type Response struct {
+	Data   any      `json:"data,omitempty"`
+	Errors []*Error `json:"errors,omitempty"`
+}
+
+// Error is a GraphQL error: a syntax or validation error in the request,
+// with where it is, or a failed field, with its path from the root of
+// Data
+type Error struct {
+	Message   string     `json:"message"`
+	Locations []Location `json:"locations,omitempty"`
+	Path      []any      `json:"path,omitempty"` // keys and list indexes
+}
+
+func (e *Error) Error() string {
+	if len(e.Locations) == 0 {
+		return "graphql: " + e.Message
+	}
+	return fmt.Sprintf("graphql: %d:%d: %s", e.Locations[0].Line, e.Locations[0].Column, e.Message)
+}
+
+// Map is a JSON object whose keys keep the order they were selected in,
+// as the objects in a response do
+type Map struct {
+	keys   []string
+	values map[string]any
+}
+
+// Keys returns the keys in order
+func (m *Map) Keys() []string { return slices.Clone(m.keys) }
+
+// Get returns the value of key
+func (m *Map) Get(key string) (any, bool) {
+	v, ok := m.values[key]
+	return v, ok
+}
+
+func (m *Map) set(key string, v any) {
+	if m.values == nil {
+		m.values = make(map[string]any)
+	}
+	m.keys = append(m.keys, key)
+	m.values[key] = v
+}
+
+// MarshalJSON encodes m with its keys in order
+func (m *Map) MarshalJSON() ([]byte, error) {
+	if m == nil {
+		return []byte("null"), nil
+	}
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		buf.Write(k)
+		buf.WriteByte(':')
+		v, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// Execute runs req against the schema. It never fails: every problem is
+// reported in the response's Errors.
+func (s *Schema) Execute(ctx context.Context, req Request) *Response {
+	doc, err := Parse(req.Query)
+	if err != nil {
+		return &Response{Errors: []*Error{err.(*Error)}}
+	}
+	op, err := doc.Operation(req.OperationName)
+	if err != nil {
+		return &Response{Errors: []*Error{err.(*Error)}}
+	}
+	root := s.Query
+	if op.Type == Mutation {
+		root = s.Mutation
+		if root == nil {
+			return &Response{Errors: []*Error{{Message: "Schema is not configured for mutations.", Locations: []Location{op.Loc}}}}
+		}
+	}
+
+	v := &validator{types: s.types(), vars: make(map[string]*variable)}
+	v.operation(op, root)
+	if len(v.errors) > 0 {
+		return &Response{Errors: v.errors}
+	}
+	vars, errs := coerceVariables(v.vars, op.Variables, req.Variables)
+	if len(errs) > 0 {
+		return &Response{Errors: errs}
+	}
+
+	e := &executor{ctx: ctx, vars: vars}
+	data, _ := e.executeFields(root, nil, op.SelectionSet, nil)
+	return &Response{Data: data, Errors: e.errors}
+}
+
+// types returns the schema's named types by name, for variable definitions
+// to refer to
+func (s *Schema) types() map[string]Type {
+	types := make(map[string]Type)
+	for _, sc := range []*Scalar{Int, Float, String, Boolean, ID} {
+		types[sc.Name] = sc
+	}
+	var walk func(Type)
+	walk = func(t Type) {
+		t = namedType(t)
+		if _, seen := types[t.String()]; seen {
+			return
+		}
+		types[t.String()] = t
+		switch t := t.(type) {
+		case *Object:
+			for _, f := range t.Fields {
+				walk(f.Type)
+				for _, a := range f.Args {
+					walk(a.Type)
+				}
+			}
+		case *InputObject:
+			for _, f := range t.Fields {
+				walk(f.Type)
+			}
+		}
+	}
+	walk(s.Query)
+	if s.Mutation != nil {
+		walk(s.Mutation)
+	}
+	return types
+}
+
+// variable is a variable definition with its type looked up
+type variable struct {
+	def *VariableDefinition
+	typ Type
+}
+
+// validator checks an operation against the schema before anything is
+// resolved, so that a request with a mistake in it has no effects
+type validator struct {
+	types  map[string]Type
+	vars   map[string]*variable
+	errors []*Error
+}
+
+func (v *validator) add(loc Location, format string, args ...any) {
+	v.errors = append(v.errors, &Error{Message: fmt.Sprintf(format, args...), Locations: []Location{loc}})
+}
+
+func (v *validator) operation(op *Operation, root *Object) {
+	for _, def := range op.Variables {
+		if v.vars[def.Name] != nil {
+			v.add(def.Loc, "There can be only one variable named \"$%s\".", def.Name)
+			continue
+		}
+		t, err := v.typeOf(def.Type)
+		if err != nil {
+			v.add(def.Loc, "Variable \"$%s\": %v", def.Name, err)
+			continue
+		}
+		v.vars[def.Name] = &variable{def, t}
+		if def.Default != nil {
+			if _, err := coerceValue(t, def.Default, nil); err != nil {
+				v.add(def.Loc, "Variable \"$%s\" has invalid default value: %v", def.Name, err)
+			}
+		}
+	}
+	v.selections(root, op.SelectionSet)
+}
+
+// typeOf looks up a variable's type, which must be an input type
+func (v *validator) typeOf(ref *TypeRef) (Type, error) {
+	var t Type
+	if ref.Elem != nil {
+		elem, err := v.typeOf(ref.Elem)
+		if err != nil {
+			return nil, err
+		}
+		t = NewList(elem)
+	} else {
+		t = v.types[ref.Name]
+		switch t.(type) {
+		case nil:
+			return nil, fmt.Errorf("unknown type %q", ref.Name)
+		case *Object:
+			return nil, fmt.Errorf("%q is not an input type", ref.Name)
+		}
+	}
+	if ref.NonNull {
+		t = NewNonNull(t)
+	}
+	return t, nil
+}
+
+func (v *validator) selections(parent *Object, set []*Selection) {
+	keys := make(map[string]bool)
+	for _, sel := range set {
+		key := sel.ResponseKey()
+		if keys[key] {
+			v.add(sel.Loc, "Field %q is selected more than once; give it an alias.", key)
+		}
+		keys[key] = true
+		if sel.Name == "__typename" {
+			if len(sel.Arguments) > 0 {
+				v.add(sel.Arguments[0].Loc, "Unknown argument %q on field \"__typename\".", sel.Arguments[0].Name)
+			}
+			if sel.SelectionSet != nil {
+				v.add(sel.Loc, "Field \"__typename\" must not have a selection since type \"String!\" has no subfields.")
+			}
+			continue
+		}
+		field := parent.Fields[sel.Name]
+		if field == nil {
+			v.add(sel.Loc, "Cannot query field %q on type %q.", sel.Name, parent.Name)
+			continue
+		}
+		v.arguments(parent, sel, field)
+		if obj, ok := namedType(field.Type).(*Object); ok {
+			if sel.SelectionSet == nil {
+				v.add(sel.Loc, "Field %q of type %q must have a selection of subfields.", sel.Name, field.Type)
+				continue
+			}
+			v.selections(obj, sel.SelectionSet)
+		} else if sel.SelectionSet != nil {
+			v.add(sel.Loc, "Field %q must not have a selection since type %q has no subfields.", sel.Name, field.Type)
+		}
+	}
+}
+
+func (v *validator) arguments(parent *Object, sel *Selection, field *Field) {
+	given := make(map[string]bool)
+	for _, arg := range sel.Arguments {
+		def := field.Args[arg.Name]
+		if def == nil {
+			v.add(arg.Loc, "Unknown argument %q on field \"%s.%s\".", arg.Name, parent.Name, sel.Name)
+			continue
+		}
+		if given[arg.Name] {
+			v.add(arg.Loc, "There can be only one argument named %q.", arg.Name)
+		}
+		given[arg.Name] = true
+		_, err := coerceValue(def.Type, arg.Value, func(name Variable, t Type) (any, error) {
+			return nil, v.variableUse(name, t)
+		})
+		if err != nil {
+			v.add(arg.Loc, "Argument %q has invalid value: %v", arg.Name, err)
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(field.Args)) {
+		def := field.Args[name]
+		if !given[name] && isNonNull(def.Type) && def.Default == nil {
+			v.add(sel.Loc, "Field %q argument %q of type %q is required, but it was not provided.", sel.Name, name, def.Type)
+		}
+	}
+}
+
+// variableUse checks that a variable used where a value of type t goes is
+// defined, with a type that fits
+func (v *validator) variableUse(name Variable, t Type) error {
+	vr := v.vars[string(name)]
+	if vr == nil {
+		return fmt.Errorf("variable \"$%s\" is not defined", name)
+	}
+	from := vr.typ
+	if nn, ok := t.(*NonNull); ok && !isNonNull(from) && vr.def.Default != nil {
+		// A default stands in for a missing value, though not for null
+		t = nn.OfType
+	}
+	if !assignable(from, t) {
+		return fmt.Errorf("variable \"$%s\" of type %q used in position expecting type %q", name, from, t)
+	}
+	return nil
+}
+
+// assignable reports whether a value of type from can go where one of type
+// to does
+func assignable(from, to Type) bool {
+	if nn, ok := to.(*NonNull); ok {
+		f, ok := from.(*NonNull)
+		return ok && assignable(f.OfType, nn.OfType)
+	}
+	if f, ok := from.(*NonNull); ok {
+		from = f.OfType
+	}
+	if l, ok := to.(*List); ok {
+		f, ok := from.(*List)
+		return ok && assignable(f.OfType, l.OfType)
+	}
+	return from == to
+}
+
+// coerceVariables checks the request's variable values against the
+// operation's definitions, filling in defaults
+func coerceVariables(vars map[string]*variable, defs []*VariableDefinition, values map[string]any) (map[string]any, []*Error) {
+	coerced := make(map[string]any)
+	var errs []*Error
+	for _, def := range defs {
+		t := vars[def.Name].typ
+		value, given := values[def.Name]
+		if !given {
+			value = def.Default
+		}
+		if value == nil && !given {
+			if isNonNull(t) {
+				errs = append(errs, &Error{Message: fmt.Sprintf("Variable \"$%s\" of required type %q was not provided.", def.Name, t), Locations: []Location{def.Loc}})
+			}
+			continue
+		}
+		c, err := coerceValue(t, value, nil)
+		if err != nil {
+			errs = append(errs, &Error{Message: fmt.Sprintf("Variable \"$%s\" got invalid value: %v", def.Name, err), Locations: []Location{def.Loc}})
+			continue
+		}
+		coerced[def.Name] = c
+	}
+	return coerced, errs
+}
+
+// coerceValue converts v, a Value from the query or a variable's value
+// decoded from JSON, to what resolvers get for type t. Variables in it are
+// looked up with variable, which may be nil where there can be none.
+func coerceValue(t Type, v any, variable func(Variable, Type) (any, error)) (any, error) {
+	if name, ok := v.(Variable); ok {
+		if variable == nil {
+			return nil, fmt.Errorf("unexpected variable \"$%s\"", name)
+		}
+		return variable(name, t)
+	}
+	if nn, ok := t.(*NonNull); ok {
+		if isNull(v) {
+			return nil, fmt.Errorf("expected value of type %q, found null", t)
+		}
+		return coerceValue(nn.OfType, v, variable)
+	}
+	if isNull(v) {
+		return nil, nil
+	}
+	switch t := t.(type) {
+	case *List:
+		var items []any
+		switch v := v.(type) {
+		case ListValue:
+			for _, item := range v {
+				items = append(items, item)
+			}
+		case []any:
+			items = v
+		default:
+			// A single value stands for a list of one
+			item, err := coerceValue(t.OfType, v, variable)
+			return []any{item}, err
+		}
+		list := make([]any, len(items))
+		for i, item := range items {
+			c, err := coerceValue(t.OfType, item, variable)
+			if err != nil {
+				return nil, fmt.Errorf("at index %d: %w", i, err)
+			}
+			list[i] = c
+		}
+		return list, nil
+	case *InputObject:
+		fields := make(map[string]any)
+		switch v := v.(type) {
+		case ObjectValue:
+			for _, f := range v {
+				if _, dup := fields[f.Name]; dup {
+					return nil, fmt.Errorf("field %q given more than once", f.Name)
+				}
+				fields[f.Name] = f.Value
+			}
+		case map[string]any:
+			fields = v
+		default:
+			return nil, fmt.Errorf("expected type %q to be an object, found %s", t.Name, describe(v))
+		}
+		obj := make(map[string]any)
+		for _, name := range slices.Sorted(maps.Keys(fields)) {
+			def := t.Fields[name]
+			if def == nil {
+				return nil, fmt.Errorf("field %q is not defined by type %q", name, t.Name)
+			}
+			c, err := coerceValue(def.Type, fields[name], variable)
+			if err != nil {
+				return nil, fmt.Errorf("in field %q: %w", name, err)
+			}
+			obj[name] = c
+		}
+		for _, name := range slices.Sorted(maps.Keys(t.Fields)) {
+			if _, ok := fields[name]; ok {
+				continue
+			}
+			switch def := t.Fields[name]; {
+			case def.Default != nil:
+				obj[name] = def.Default
+			case isNonNull(def.Type):
+				return nil, fmt.Errorf("field \"%s.%s\" of required type %q was not provided", t.Name, name, def.Type)
+			}
+		}
+		return obj, nil
+	case *Scalar:
+		switch v.(type) {
+		case ListValue, ObjectValue, EnumValue, []any, map[string]any:
+			return nil, fmt.Errorf("%s cannot represent %s", t.Name, describe(v))
+		}
+		return t.ParseValue(v)
+	}
+	return nil, fmt.Errorf("%q is not an input type", t)
+}
+
+func isNull(v any) bool {
+	_, null := v.(NullValue)
+	return v == nil || null
+}
+
+// executor resolves an operation's fields, collecting the errors of those
+// that fail
+type executor struct {
+	ctx    context.Context
+	vars   map[string]any
+	errors []*Error
+}
+
+func (e *executor) fail(sel *Selection, path []any, err error) {
+	e.errors = append(e.errors, &Error{Message: err.Error(), Locations: []Location{sel.Loc}, Path: slices.Clone(path)})
+}
+
+// executeFields resolves the fields of set on source, an obj. It returns
+// false if a non-null field was null, so the object is null too.
+func (e *executor) executeFields(obj *Object, source any, set []*Selection, path []any) (*Map, bool) {
+	m := &Map{}
+	for _, sel := range set {
+		key := sel.ResponseKey()
+		if sel.Name == "__typename" {
+			m.set(key, obj.Name)
+			continue
+		}
+		v, ok := e.executeField(obj, obj.Fields[sel.Name], source, sel, append(slices.Clip(path), key))
+		if !ok {
+			return nil, false
+		}
+		m.set(key, v)
+	}
+	return m, true
+}
+
+func (e *executor) executeField(obj *Object, field *Field, source any, sel *Selection, path []any) (any, bool) {
+	args := make(map[string]any)
+	for _, arg := range sel.Arguments {
+		if name, ok := arg.Value.(Variable); ok {
+			if _, given := e.vars[string(name)]; !given {
+				continue // as if the argument were not given
+			}
+		}
+		// Validation has checked the values, so this cannot fail
+		args[arg.Name], _ = coerceValue(field.Args[arg.Name].Type, arg.Value, func(name Variable, t Type) (any, error) {
+			return e.vars[string(name)], nil
+		})
+	}
+	for name, def := range field.Args {
+		if _, ok := args[name]; !ok && def.Default != nil {
+			args[name] = def.Default
+		}
+	}
+
+	resolve := field.Resolve
+	if resolve == nil {
+		resolve = func(p ResolveParams) (any, error) { return defaultResolve(p.Source, sel.Name) }
+	}
+	v, err := call(resolve, ResolveParams{Context: e.ctx, Source: source, Args: args})
+	if err != nil {
+		e.fail(sel, path, err)
+		return nil, !isNonNull(field.Type)
+	}
+	return e.complete(field.Type, sel, path, v)
+}
+
+// call runs resolve, turning a panic into an error
+func call(resolve Resolver, p ResolveParams) (v any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("resolver panicked: %v", r)
+		}
+	}()
+	return resolve(p)
+}
+
+// complete converts v, resolved for a field of type t, to its response
+// form. It returns false if it is null in a non-null position, having
+// recorded why, so the parent must be null too.
+func (e *executor) complete(t Type, sel *Selection, path []any, v any) (any, bool) {
+	nn, nonNull := t.(*NonNull)
+	if !nonNull {
+		r, ok := e.completeNullable(t, sel, path, v)
+		if !ok {
+			return nil, true
+		}
+		return r, true
+	}
+	if isNilValue(v) {
+		e.fail(sel, path, fmt.Errorf("Cannot return null for non-nullable field %q.", sel.Name))
+		return nil, false
+	}
+	return e.completeNullable(nn.OfType, sel, path, v)
+}
+
+// completeNullable is complete for t that is not NonNull. It returns false
+// if v could not be completed.
+func (e *executor) completeNullable(t Type, sel *Selection, path []any, v any) (any, bool) {
+	if isNilValue(v) {
+		return nil, true
+	}
+	switch t := t.(type) {
+	case *Scalar:
+		r, err := t.Serialize(v)
+		if err != nil {
+			e.fail(sel, path, err)
+			return nil, false
+		}
+		return r, true
+	case *Object:
+		m, ok := e.executeFields(t, v, sel.SelectionSet, path)
+		if !ok {
+			return nil, false
+		}
+		return m, true
+	case *List:
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			e.fail(sel, path, fmt.Errorf("expected a list for field %q, got %T", sel.Name, v))
+			return nil, false
+		}
+		items := make([]any, rv.Len())
+		for i := range items {
+			item, ok := e.complete(t.OfType, sel, append(slices.Clip(path), i), rv.Index(i).Interface())
+			if !ok {
+				return nil, false
+			}
+			items[i] = item
+		}
+		return items, true
+	}
+	e.fail(sel, path, fmt.Errorf("field %q has input type %q", sel.Name, t))
+	return nil, false
+}
+
+// isNilValue reports whether v is null: nil, or a nil pointer, map or
+// interface. A nil slice is an empty list.
+func isNilValue(v any) bool {
+	if v == nil {
+		return true
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Interface:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// defaultResolve reads field name from source, for fields without a
+// resolver
+func defaultResolve(source any, name string) (any, error) {
+	rv := reflect.ValueOf(source)
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil, nil
+		}
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() == reflect.String {
+			v := rv.MapIndex(reflect.ValueOf(name).Convert(rv.Type().Key()))
+			if !v.IsValid() {
+				return nil, nil
+			}
+			return v.Interface(), nil
+		}
+	case reflect.Struct:
+		if f, ok := structField(rv.Type(), name); ok {
+			return rv.FieldByIndex(f.Index).Interface(), nil
+		}
+	}
+	return nil, fmt.Errorf("no resolver for field %q on %T", name, source)
+}
+
+// structField finds the exported field of t with JSON name name, or
+// failing that with name in any case
+func structField(t reflect.Type, name string) (reflect.StructField, bool) {
+	var byName reflect.StructField
+	found := false
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || f.Anonymous {
+			continue
+		}
+		tag, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if tag == name {
+			return f, true
+		}
+		if tag == "" && !found && strings.EqualFold(f.Name, name) {
+			byName, found = f, true
+		}
+	}
+	return byName, found
+}
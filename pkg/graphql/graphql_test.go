@@ -0,0 +1,467 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	doc, err := Parse(`
+		# the book and its author
+		query Book($id: Int! = 1, $tags: [String!]) {
+			book(id: $id, filter: {tags: $tags, price: 9.5, used: false, note: null}) {
+				name: title
+				author { name }
+			}
+		}
+		mutation Add { addBook(title: "Go\tin \"Action\"é", kind: HARDBACK) { id } }
+	`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(doc.Operations) != 2 {
+		t.Fatalf("%d operations; want 2", len(doc.Operations))
+	}
+
+	q := doc.Operations[0]
+	if q.Type != Query || q.Name != "Book" || q.Loc != (Location{3, 3}) {
+		t.Errorf("first operation = %s %q at %v; want query \"Book\" at 3:3", q.Type, q.Name, q.Loc)
+	}
+	if len(q.Variables) != 2 || q.Variables[0].Type.String() != "Int!" || q.Variables[0].Default != 1 || q.Variables[1].Type.String() != "[String!]" {
+		t.Errorf("variables = %+v; want $id: Int! = 1 and $tags: [String!]", q.Variables)
+	}
+	book := q.SelectionSet[0]
+	wantArgs := []*Argument{
+		{Name: "id", Value: Variable("id"), Loc: Location{4, 9}},
+		{Name: "filter", Value: ObjectValue{
+			{Name: "tags", Value: Variable("tags")},
+			{Name: "price", Value: 9.5},
+			{Name: "used", Value: false},
+			{Name: "note", Value: NullValue{}},
+		}, Loc: Location{4, 18}},
+	}
+	if !reflect.DeepEqual(book.Arguments, wantArgs) {
+		t.Errorf("book arguments = %s; want %s", dump(book.Arguments), dump(wantArgs))
+	}
+	if title := book.SelectionSet[0]; title.Alias != "name" || title.Name != "title" || title.ResponseKey() != "name" {
+		t.Errorf("first selection = %+v; want title aliased to name", title)
+	}
+	if author := book.SelectionSet[1]; author.Name != "author" || len(author.SelectionSet) != 1 || author.SelectionSet[0].Name != "name" {
+		t.Errorf("second selection = %+v; want author { name }", author)
+	}
+
+	m := doc.Operations[1]
+	args := m.SelectionSet[0].Arguments
+	if m.Type != Mutation || args[0].Value != "Go\tin \"Action\"é" || args[1].Value != EnumValue("HARDBACK") {
+		t.Errorf("mutation = %s with %s; want the title unescaped and an enum", m.Type, dump(args))
+	}
+}
+
+func dump(v any) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+func TestParse_Shorthand(t *testing.T) {
+	doc, err := Parse(`{ books { title } }`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	op, err := doc.Operation("")
+	if err != nil || op.Type != Query || op.Name != "" || op.SelectionSet[0].Name != "books" {
+		t.Errorf("Operation(\"\") = %+v, %v; want the anonymous query", op, err)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		query string
+		msg   string
+		loc   Location
+	}{
+		{``, "Syntax Error: Unexpected <EOF>", Location{1, 1}},
+		{`{ }`, `Syntax Error: Expected Name, found "}"`, Location{1, 3}},
+		{`{ book(id: ) { title } }`, `Syntax Error: Unexpected ")"`, Location{1, 12}},
+		{"{\n  book(id: 1 { title } }", `Syntax Error: Expected Name, found "{"`, Location{2, 14}},
+		{`{ title: }`, `Syntax Error: Expected Name, found "}"`, Location{1, 10}},
+		{`{ book(id: 01) { id } }`, "Syntax Error: Invalid number, unexpected digit after 0", Location{1, 13}},
+		{`{ book(id: 1.) { id } }`, "Syntax Error: Invalid number, expected digit", Location{1, 14}},
+		{`{ book(title: "unterminated) { id } }`, "Syntax Error: Unterminated string", Location{1, 38}},
+		{`{ book(title: "\q") { id } }`, "Syntax Error: Invalid character escape sequence", Location{1, 16}},
+		{`{ book ? }`, `Syntax Error: Unexpected character '?'`, Location{1, 8}},
+		{`query Q($id: Int = $other) { a }`, `Syntax Error: Unexpected "$"`, Location{1, 20}},
+		{`{ ...Parts }`, "Syntax Error: fragments are not supported", Location{1, 3}},
+		{`subscription { books }`, "Syntax Error: subscriptions are not supported", Location{1, 1}},
+		{`{ book @skip(if: true) }`, "Syntax Error: directives are not supported", Location{1, 8}},
+		{`{ a } { b }`, "This anonymous operation must be the only defined operation.", Location{1, 1}},
+		{`query A { a } query A { b }`, `There can be only one operation named "A".`, Location{1, 15}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.query, func(t *testing.T) {
+			_, err := Parse(tc.query)
+			var gqlErr *Error
+			if !errors.As(err, &gqlErr) {
+				t.Fatalf("Parse = %v; want an *Error", err)
+			}
+			if gqlErr.Message != tc.msg || len(gqlErr.Locations) != 1 || gqlErr.Locations[0] != tc.loc {
+				t.Errorf("error = %q at %v; want %q at %v", gqlErr.Message, gqlErr.Locations, tc.msg, tc.loc)
+			}
+		})
+	}
+}
+
+func TestDocument_Operation(t *testing.T) {
+	doc, _ := Parse(`query A { a } query B { b }`)
+	if op, err := doc.Operation("B"); err != nil || op.Name != "B" {
+		t.Errorf("Operation(B) = %v, %v; want B", op, err)
+	}
+	for name, want := range map[string]string{
+		"":  "Must provide operation name if query contains multiple operations.",
+		"C": `Unknown operation named "C".`,
+	} {
+		if _, err := doc.Operation(name); err == nil || err.(*Error).Message != want {
+			t.Errorf("Operation(%q) = %v; want %q", name, err, want)
+		}
+	}
+}
+
+type testAuthor struct {
+	Name string
+	Born int `json:"born_year"`
+}
+
+type testBook struct {
+	ID     int    `json:"id"`
+	Title  string `json:"title"`
+	Price  float64
+	Tags   []string
+	Author *testAuthor
+}
+
+// testSchema is a small library: books with authors, and a mutation to
+// add one, recording the mutations made
+func testSchema() (*Schema, *[]string) {
+	books := []*testBook{
+		{ID: 1, Title: "The Go Programming Language", Price: 39.99, Tags: []string{"go"}, Author: &testAuthor{"Alan Donovan", 1970}},
+		{ID: 2, Title: "Anonymous", Price: 5},
+	}
+	var mutations []string
+
+	author := &Object{Name: "Author", Fields: Fields{
+		"name": {Type: NewNonNull(String)},
+		"born": {Type: Int, Resolve: func(p ResolveParams) (any, error) { return p.Source.(*testAuthor).Born, nil }},
+	}}
+	book := &Object{Name: "Book", Fields: Fields{
+		"id":     {Type: NewNonNull(ID)},
+		"title":  {Type: NewNonNull(String)},
+		"price":  {Type: NewNonNull(Float)},
+		"tags":   {Type: NewNonNull(NewList(NewNonNull(String)))},
+		"author": {Type: author},
+		// Fails for books without an author, to test null propagation
+		"authorName": {Type: NewNonNull(String), Resolve: func(p ResolveParams) (any, error) {
+			if a := p.Source.(*testBook).Author; a != nil {
+				return a.Name, nil
+			}
+			return nil, errors.New("no author")
+		}},
+		"panics": {Type: String, Resolve: func(p ResolveParams) (any, error) { panic("boom") }},
+	}}
+	bookInput := &InputObject{Name: "BookInput", Fields: Args{
+		"title": {Type: NewNonNull(String)},
+		"price": {Type: Float, Default: 9.99},
+		"tags":  {Type: NewList(NewNonNull(String))},
+	}}
+
+	schema := &Schema{
+		Query: &Object{Name: "Query", Fields: Fields{
+			"books": {
+				Type: NewNonNull(NewList(NewNonNull(book))),
+				Args: Args{"first": {Type: Int, Default: 10}},
+				Resolve: func(p ResolveParams) (any, error) {
+					return books[:min(p.Args["first"].(int), len(books))], nil
+				},
+			},
+			"book": {
+				Type: book,
+				Args: Args{"id": {Type: NewNonNull(ID)}},
+				Resolve: func(p ResolveParams) (any, error) {
+					for _, b := range books {
+						if fmt.Sprint(b.ID) == p.Args["id"] {
+							return b, nil
+						}
+					}
+					return nil, nil // a typed nil *testBook would do as well
+				},
+			},
+			"echo": {
+				Type: String,
+				Args: Args{"value": {Type: String}},
+				Resolve: func(p ResolveParams) (any, error) {
+					v, given := p.Args["value"]
+					return fmt.Sprintf("%s %v", describe(v), given), nil
+				},
+			},
+			"map": {
+				Type:    &Object{Name: "Pair", Fields: Fields{"key": {Type: String}}},
+				Resolve: func(p ResolveParams) (any, error) { return map[string]any{"key": "value"}, nil },
+			},
+			"broken": {Type: NewNonNull(String), Resolve: func(p ResolveParams) (any, error) { return nil, nil }},
+		}},
+		Mutation: &Object{Name: "Mutation", Fields: Fields{
+			"addBook": {
+				Type: NewNonNull(book),
+				Args: Args{"input": {Type: NewNonNull(bookInput)}},
+				Resolve: func(p ResolveParams) (any, error) {
+					in := p.Args["input"].(map[string]any)
+					mutations = append(mutations, fmt.Sprint(in))
+					b := &testBook{ID: len(books) + 1, Title: in["title"].(string), Price: in["price"].(float64)}
+					for _, tag := range in["tags"].([]any) {
+						b.Tags = append(b.Tags, tag.(string))
+					}
+					books = append(books, b)
+					return b, nil
+				},
+			},
+		}},
+	}
+	return schema, &mutations
+}
+
+func TestExecute(t *testing.T) {
+	tests := []struct {
+		name string
+		req  Request
+		want string
+	}{
+		{
+			"selected fields only, in order",
+			Request{Query: `{ books { title id } }`},
+			`{"data":{"books":[{"title":"The Go Programming Language","id":"1"},{"title":"Anonymous","id":"2"}]}}`,
+		},
+		{
+			"aliases, nested objects and __typename",
+			Request{Query: `{ go: book(id: 1) { __typename name: title author { name born } } none: book(id: 9) { title } }`},
+			`{"data":{"go":{"__typename":"Book","name":"The Go Programming Language","author":{"name":"Alan Donovan","born":1970}},"none":null}}`,
+		},
+		{
+			"argument default and literal",
+			Request{Query: `{ all: books { id } one: books(first: 1) { id } }`},
+			`{"data":{"all":[{"id":"1"},{"id":"2"}],"one":[{"id":"1"}]}}`,
+		},
+		{
+			"variables, with a default",
+			Request{Query: `query ($id: ID!, $first: Int = 1) { book(id: $id) { title } books(first: $first) { id } }`, Variables: map[string]any{"id": 2.0}},
+			`{"data":{"book":{"title":"Anonymous"},"books":[{"id":"1"}]}}`,
+		},
+		{
+			"unset variable leaves the argument out",
+			Request{Query: `query ($v: String) { a: echo(value: $v) b: echo(value: null) c: echo }`},
+			`{"data":{"a":"null false","b":"null true","c":"null false"}}`,
+		},
+		{
+			"default resolver reads maps and untagged fields",
+			Request{Query: `{ map { key } book(id: "1") { price tags } }`},
+			`{"data":{"map":{"key":"value"},"book":{"price":39.99,"tags":["go"]}}}`,
+		},
+		{
+			"nil slice is an empty list",
+			Request{Query: `{ book(id: 2) { tags author { name } } }`},
+			`{"data":{"book":{"tags":[],"author":null}}}`,
+		},
+		{
+			"resolver error nulls the nearest nullable parent",
+			Request{Query: `{ book(id: 2) { title authorName } }`},
+			`{"data":{"book":null},"errors":[{"message":"no author","locations":[{"line":1,"column":23}],"path":["book","authorName"]}]}`,
+		},
+		{
+			"error in a non-null list item nulls the whole list, up to data",
+			Request{Query: `{ books { authorName } }`},
+			`{"data":null,"errors":[{"message":"no author","locations":[{"line":1,"column":11}],"path":["books",1,"authorName"]}]}`,
+		},
+		{
+			"panic is a field error",
+			Request{Query: `{ book(id: 1) { id panics } }`},
+			`{"data":{"book":{"id":"1","panics":null}},"errors":[{"message":"resolver panicked: boom","locations":[{"line":1,"column":20}],"path":["book","panics"]}]}`,
+		},
+		{
+			"null for a non-null field",
+			Request{Query: `{ broken }`},
+			`{"data":null,"errors":[{"message":"Cannot return null for non-nullable field \"broken\".","locations":[{"line":1,"column":3}],"path":["broken"]}]}`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			schema, _ := testSchema()
+			got, err := json.Marshal(schema.Execute(context.Background(), tc.req))
+			if err != nil {
+				t.Fatalf("encoding response: %v", err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("response\n got %s\nwant %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExecute_Mutation(t *testing.T) {
+	schema, mutations := testSchema()
+	resp := schema.Execute(context.Background(), Request{
+		Query: `mutation Add($tags: [String!]) {
+			first: addBook(input: {title: "Learning Go", tags: $tags}) { id title price tags }
+			second: addBook(input: {title: "Go in Action", price: 30, tags: "go"}) { id }
+		}`,
+		OperationName: "Add",
+		Variables:     map[string]any{"tags": []any{"go", "beginner"}},
+	})
+	want := `{"data":{"first":{"id":"3","title":"Learning Go","price":9.99,"tags":["go","beginner"]},"second":{"id":"4"}}}`
+	if got := dump(resp); got != want {
+		t.Errorf("response\n got %s\nwant %s", got, want)
+	}
+	// Mutations run one after another, in the order selected
+	wantMutations := []string{"map[price:9.99 tags:[go beginner] title:Learning Go]", "map[price:30 tags:[go] title:Go in Action]"}
+	if !reflect.DeepEqual(*mutations, wantMutations) {
+		t.Errorf("mutations = %q; want %q", *mutations, wantMutations)
+	}
+	if got := dump(schema.Execute(context.Background(), Request{Query: `{ books(first: 5) { title } }`})); !strings.Contains(got, "Go in Action") {
+		t.Errorf("books after the mutation = %s; want the new books", got)
+	}
+}
+
+// Requests that fail validation resolve nothing, so a mutation in them
+// has no effect, and have no data
+func TestExecute_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		vars  map[string]any
+		want  string
+	}{
+		{"syntax error", `{ books {`, nil, "Syntax Error: Expected Name, found <EOF>"},
+		{"unknown field", `{ books { isbn } }`, nil, `Cannot query field "isbn" on type "Book".`},
+		{"unknown root field", `mutation { deleteBook }`, nil, `Cannot query field "deleteBook" on type "Mutation".`},
+		{"missing subselection", `{ book(id: 1) }`, nil, `Field "book" of type "Book" must have a selection of subfields.`},
+		{"subselection on a scalar", `{ books { title { length } } }`, nil, `Field "title" must not have a selection since type "String!" has no subfields.`},
+		{"unknown argument", `{ books(last: 1) { id } }`, nil, `Unknown argument "last" on field "Query.books".`},
+		{"missing required argument", `{ book { id } }`, nil, `Field "book" argument "id" of type "ID!" is required, but it was not provided.`},
+		{"wrong argument type", `{ books(first: "ten") { id } }`, nil, `Argument "first" has invalid value: Int cannot represent non-integer value: "ten"`},
+		{"null for a non-null argument", `{ book(id: null) { id } }`, nil, `Argument "id" has invalid value: expected value of type "ID!", found null`},
+		{"duplicate response key", `{ books { id id } }`, nil, `Field "id" is selected more than once; give it an alias.`},
+		{"undefined variable", `{ book(id: $id) { id } }`, nil, `Argument "id" has invalid value: variable "$id" is not defined`},
+		{"variable of the wrong type", `query ($id: Int) { book(id: $id) { id } }`, nil, `Argument "id" has invalid value: variable "$id" of type "Int" used in position expecting type "ID!"`},
+		{"unknown variable type", `query ($b: Bool) { books { id } }`, nil, `Variable "$b": unknown type "Bool"`},
+		{"output type variable", `query ($b: Book) { books { id } }`, nil, `Variable "$b": "Book" is not an input type`},
+		{"missing variable", `query ($id: ID!) { book(id: $id) { id } }`, nil, `Variable "$id" of required type "ID!" was not provided.`},
+		{"invalid variable", `query ($n: Int) { books(first: $n) { id } }`, map[string]any{"n": 1.5}, `Variable "$n" got invalid value: Int cannot represent non-integer value: 1.5`},
+		{"Int out of range", `{ books(first: 3000000000) { id } }`, nil, `Argument "first" has invalid value: Int cannot represent non 32-bit signed integer value: 3000000000`},
+		{"input object missing a field", `mutation { addBook(input: {price: 1}) { id } }`, nil, `Argument "input" has invalid value: field "BookInput.title" of required type "String!" was not provided`},
+		{"input object unknown field", `mutation { addBook(input: {title: "T", isbn: "1"}) { id } }`, nil, `Argument "input" has invalid value: field "isbn" is not defined by type "BookInput"`},
+		{"input object list item", `mutation { addBook(input: {title: "T", tags: ["a", null]}) { id } }`, nil, `Argument "input" has invalid value: in field "tags": at index 1: expected value of type "String!", found null`},
+		{"enum value", `{ books(first: TEN) { id } }`, nil, `Argument "first" has invalid value: Int cannot represent TEN`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			schema, mutations := testSchema()
+			resp := schema.Execute(context.Background(), Request{Query: tc.query, Variables: tc.vars})
+			if resp.Data != nil {
+				t.Errorf("data = %s; want none", dump(resp.Data))
+			}
+			if len(resp.Errors) != 1 || resp.Errors[0].Message != tc.want {
+				t.Fatalf("errors = %s; want %q", dump(resp.Errors), tc.want)
+			}
+			if len(resp.Errors[0].Locations) != 1 {
+				t.Errorf("error has locations %v; want one", resp.Errors[0].Locations)
+			}
+			if len(*mutations) != 0 {
+				t.Errorf("mutations %q were run", *mutations)
+			}
+		})
+	}
+}
+
+func TestExecute_NoMutations(t *testing.T) {
+	schema := &Schema{Query: &Object{Name: "Query", Fields: Fields{"a": {Type: String}}}}
+	resp := schema.Execute(context.Background(), Request{Query: `mutation { a }`})
+	if len(resp.Errors) != 1 || resp.Errors[0].Message != "Schema is not configured for mutations." {
+		t.Errorf("errors = %s; want the schema to have no mutations", dump(resp.Errors))
+	}
+}
+
+func TestScalars(t *testing.T) {
+	tests := []struct {
+		scalar *Scalar
+		in     any
+		want   any // nil for an error
+	}{
+		{Int, 7, 7},
+		{Int, int64(-7), -7},
+		{Int, uint8(7), 7},
+		{Int, 7.0, 7},
+		{Int, json.Number("7"), 7},
+		{Int, 7.5, nil},
+		{Int, 1 << 31, nil},
+		{Int, "7", nil},
+		{Float, 7, 7.0},
+		{Float, float32(0.5), 0.5},
+		{Float, json.Number("2.5"), 2.5},
+		{Float, true, nil},
+		{String, "s", "s"},
+		{String, json.Number("1"), nil},
+		{String, 1, nil},
+		{Boolean, true, true},
+		{Boolean, 1, nil},
+		{ID, "a1", "a1"},
+		{ID, 42, "42"},
+		{ID, 4.2, nil},
+	}
+	for _, tc := range tests {
+		got, err := tc.scalar.ParseValue(tc.in)
+		if tc.want == nil {
+			if err == nil {
+				t.Errorf("%s(%#v) = %#v; want an error", tc.scalar, tc.in, got)
+			}
+			continue
+		}
+		if err != nil || got != tc.want {
+			t.Errorf("%s(%#v) = %#v, %v; want %#v", tc.scalar, tc.in, got, err, tc.want)
+		}
+	}
+}
+
+func Example() {
+	type book struct {
+		ID    int    `json:"id"`
+		Title string `json:"title"`
+	}
+	books := map[int]book{1: {1, "The Go Programming Language"}}
+
+	bookType := &Object{Name: "Book", Fields: Fields{
+		"id":    {Type: NewNonNull(Int)},
+		"title": {Type: NewNonNull(String)},
+	}}
+	schema := &Schema{Query: &Object{Name: "Query", Fields: Fields{
+		"book": {
+			Type: bookType,
+			Args: Args{"id": {Type: NewNonNull(Int)}},
+			Resolve: func(p ResolveParams) (any, error) {
+				if b, ok := books[p.Args["id"].(int)]; ok {
+					return b, nil
+				}
+				return nil, nil
+			},
+		},
+	}}}
+
+	resp := schema.Execute(context.Background(), Request{
+		Query:     `query ($id: Int!) { book(id: $id) { title } }`,
+		Variables: map[string]any{"id": 1},
+	})
+	out, _ := json.Marshal(resp)
+	fmt.Println(string(out))
+	// Output:
+	// {"data":{"book":{"title":"The Go Programming Language"}}}
+}
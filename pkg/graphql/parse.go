@@ -0,0 +1,591 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Document is a parsed request: one or more operations
+type Document struct {
+	Operations []*Operation
+}
+
+// Operation types
+const (
+	Query    = "query"
+	Mutation = "mutation"
+)
+
+// Operation is a query or mutation and the fields it selects
+type Operation struct {
+	Type         string // Query or Mutation
+	Name         string // empty for an anonymous operation
+	Variables    []*VariableDefinition
+	SelectionSet []*Selection
+	Loc          Location
+}
+
+// VariableDefinition declares a variable an operation takes, as in
+// ($id: Int! = 1)
+type VariableDefinition struct {
+	Name    string
+	Type    *TypeRef
+	Default Value // nil when there is none, or a NullValue
+	Loc     Location
+}
+
+// TypeRef is a type as written in a variable definition
+type TypeRef struct {
+	Name    string   // the named type, or "" for a list
+	Elem    *TypeRef // the element type of a list
+	NonNull bool
+}
+
+func (t *TypeRef) String() string {
+	s := t.Name
+	if t.Elem != nil {
+		s = "[" + t.Elem.String() + "]"
+	}
+	if t.NonNull {
+		s += "!"
+	}
+	return s
+}
+
+// Selection is a field selected from an object, with the fields selected
+// from its value if that is an object too
+type Selection struct {
+	Alias        string // empty when there is none
+	Name         string
+	Arguments    []*Argument
+	SelectionSet []*Selection
+	Loc          Location
+}
+
+// ResponseKey is the key the field's value has in the response: its alias,
+// or its name
+func (s *Selection) ResponseKey() string {
+	if s.Alias != "" {
+		return s.Alias
+	}
+	return s.Name
+}
+
+// Argument is a name: value pair passed to a field
+type Argument struct {
+	Name  string
+	Value Value
+	Loc   Location
+}
+
+// Value is an argument or default value as written. It is one of int,
+// float64, string, bool, NullValue, EnumValue, Variable, ListValue or
+// ObjectValue.
+type Value any
+
+type (
+	// NullValue is the literal null
+	NullValue struct{}
+	// EnumValue is a bare name other than true, false and null
+	EnumValue string
+	// Variable is a $name reference to one of the operation's variables
+	Variable string
+	// ListValue is a [list, of, values]
+	ListValue []Value
+	// ObjectValue is an {input: object}, its fields in the order written
+	ObjectValue []*ObjectField
+)
+
+// ObjectField is one field of an ObjectValue
+type ObjectField struct {
+	Name  string
+	Value Value
+}
+
+// Location is a 1-based line and column in the query
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Operation returns the operation named name, or the only one if name is
+// empty
+func (d *Document) Operation(name string) (*Operation, error) {
+	if name == "" {
+		if len(d.Operations) != 1 {
+			return nil, &Error{Message: "Must provide operation name if query contains multiple operations."}
+		}
+		return d.Operations[0], nil
+	}
+	for _, op := range d.Operations {
+		if op.Name == name {
+			return op, nil
+		}
+	}
+	return nil, &Error{Message: fmt.Sprintf("Unknown operation named %q.", name)}
+}
+
+// Parse parses a query document. The error is an *Error with the location
+// of the problem. Fragments, directives and subscriptions are reported as
+// unsupported rather than parsed.
+func Parse(query string) (doc *Document, err error) {
+	p := &parser{lexer: lexer{src: query, line: 1, lineStart: 0}}
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := r.(*Error)
+			if !ok {
+				panic(r)
+			}
+			doc, err = nil, e
+		}
+	}()
+	p.advance()
+	doc = &Document{}
+	for p.tok.kind != tokEOF {
+		doc.Operations = append(doc.Operations, p.operation())
+	}
+	if len(doc.Operations) == 0 {
+		p.fail(p.tok.loc, "Unexpected <EOF>")
+	}
+	names := make(map[string]bool)
+	for _, op := range doc.Operations {
+		if op.Name == "" && len(doc.Operations) > 1 {
+			return nil, &Error{Message: "This anonymous operation must be the only defined operation.", Locations: []Location{op.Loc}}
+		}
+		if names[op.Name] {
+			return nil, &Error{Message: fmt.Sprintf("There can be only one operation named %q.", op.Name), Locations: []Location{op.Loc}}
+		}
+		names[op.Name] = true
+	}
+	return doc, nil
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokPunct
+	tokName
+	tokInt
+	tokFloat
+	tokString
+)
+
+var tokenNames = map[tokenKind]string{
+	tokEOF:    "<EOF>",
+	tokName:   "Name",
+	tokInt:    "Int",
+	tokFloat:  "Float",
+	tokString: "String",
+}
+
+type token struct {
+	kind tokenKind
+	text string // the punctuator, name, number, or the string's value
+	loc  Location
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "<EOF>"
+	case tokString:
+		return strconv.Quote(t.text)
+	case tokPunct:
+		return fmt.Sprintf("%q", t.text)
+	}
+	return fmt.Sprintf("%s %q", tokenNames[t.kind], t.text)
+}
+
+// lexer splits a query into tokens, skipping whitespace, commas and
+// comments, which the grammar ignores
+type lexer struct {
+	src       string
+	pos       int
+	line      int
+	lineStart int // offset of the current line
+}
+
+func (l *lexer) loc() Location {
+	return Location{Line: l.line, Column: l.pos - l.lineStart + 1}
+}
+
+func (l *lexer) fail(loc Location, format string, args ...any) {
+	panic(&Error{Message: "Syntax Error: " + fmt.Sprintf(format, args...), Locations: []Location{loc}})
+}
+
+func (l *lexer) newline() {
+	l.line++
+	l.lineStart = l.pos
+}
+
+func (l *lexer) next() token {
+	l.skipIgnored()
+	loc := l.loc()
+	if l.pos >= len(l.src) {
+		return token{kind: tokEOF, loc: loc}
+	}
+	c := l.src[l.pos]
+	switch {
+	case strings.IndexByte("!$():=@[]{}|&", c) >= 0:
+		l.pos++
+		return token{kind: tokPunct, text: string(c), loc: loc}
+	case c == '.':
+		if strings.HasPrefix(l.src[l.pos:], "...") {
+			l.pos += 3
+			return token{kind: tokPunct, text: "...", loc: loc}
+		}
+		l.fail(loc, "Unexpected %q", ".")
+	case c == '_' || isLetter(c):
+		start := l.pos
+		for l.pos < len(l.src) && (l.src[l.pos] == '_' || isLetter(l.src[l.pos]) || isDigit(l.src[l.pos])) {
+			l.pos++
+		}
+		return token{kind: tokName, text: l.src[start:l.pos], loc: loc}
+	case c == '-' || isDigit(c):
+		return l.number(loc)
+	case c == '"':
+		if strings.HasPrefix(l.src[l.pos:], `"""`) {
+			l.fail(loc, "block strings are not supported")
+		}
+		return l.string(loc)
+	}
+	r, _ := utf8.DecodeRuneInString(l.src[l.pos:])
+	l.fail(loc, "Unexpected character %q", r)
+	return token{}
+}
+
+func (l *lexer) skipIgnored() {
+	for l.pos < len(l.src) {
+		switch c := l.src[l.pos]; c {
+		case ' ', '\t', ',':
+			l.pos++
+		case '\n':
+			l.pos++
+			l.newline()
+		case '\r':
+			l.pos++
+			if l.pos < len(l.src) && l.src[l.pos] == '\n' {
+				l.pos++
+			}
+			l.newline()
+		case '#':
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' && l.src[l.pos] != '\r' {
+				l.pos++
+			}
+		default:
+			if strings.HasPrefix(l.src[l.pos:], bom) {
+				l.pos += len(bom)
+				continue
+			}
+			return
+		}
+	}
+}
+
+// number reads -?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?
+func (l *lexer) number(loc Location) token {
+	start := l.pos
+	kind := tokInt
+	if l.src[l.pos] == '-' {
+		l.pos++
+	}
+	if l.pos < len(l.src) && l.src[l.pos] == '0' {
+		l.pos++
+		if l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+			l.fail(l.loc(), "Invalid number, unexpected digit after 0")
+		}
+	} else {
+		l.digits()
+	}
+	if l.pos < len(l.src) && l.src[l.pos] == '.' {
+		kind = tokFloat
+		l.pos++
+		l.digits()
+	}
+	if l.pos < len(l.src) && (l.src[l.pos] == 'e' || l.src[l.pos] == 'E') {
+		kind = tokFloat
+		l.pos++
+		if l.pos < len(l.src) && (l.src[l.pos] == '+' || l.src[l.pos] == '-') {
+			l.pos++
+		}
+		l.digits()
+	}
+	if l.pos < len(l.src) && (l.src[l.pos] == '_' || l.src[l.pos] == '.' || isLetter(l.src[l.pos])) {
+		l.fail(l.loc(), "Invalid number, expected digit but got %q", l.src[l.pos])
+	}
+	return token{kind: kind, text: l.src[start:l.pos], loc: loc}
+}
+
+func (l *lexer) digits() {
+	if l.pos >= len(l.src) || !isDigit(l.src[l.pos]) {
+		l.fail(l.loc(), "Invalid number, expected digit")
+	}
+	for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+		l.pos++
+	}
+}
+
+// string reads a "quoted string" with JSON-style escapes
+func (l *lexer) string(loc Location) token {
+	l.pos++ // opening quote
+	var b strings.Builder
+	for {
+		if l.pos >= len(l.src) || l.src[l.pos] == '\n' || l.src[l.pos] == '\r' {
+			l.fail(l.loc(), "Unterminated string")
+		}
+		c := l.src[l.pos]
+		switch {
+		case c == '"':
+			l.pos++
+			return token{kind: tokString, text: b.String(), loc: loc}
+		case c == '\\':
+			if l.pos+1 >= len(l.src) {
+				l.fail(l.loc(), "Unterminated string")
+			}
+			esc := l.src[l.pos+1]
+			if r, ok := escapes[esc]; ok {
+				b.WriteByte(r)
+				l.pos += 2
+				continue
+			}
+			if esc != 'u' || l.pos+6 > len(l.src) {
+				l.fail(l.loc(), "Invalid character escape sequence")
+			}
+			n, err := strconv.ParseUint(l.src[l.pos+2:l.pos+6], 16, 16)
+			if err != nil {
+				l.fail(l.loc(), "Invalid character escape sequence: %s", l.src[l.pos:l.pos+6])
+			}
+			b.WriteRune(rune(n))
+			l.pos += 6
+		default:
+			r, size := utf8.DecodeRuneInString(l.src[l.pos:])
+			if r < ' ' && r != '\t' {
+				l.fail(l.loc(), "Invalid character within String: %q", r)
+			}
+			b.WriteRune(r)
+			l.pos += size
+		}
+	}
+}
+
+// bom is a byte order mark, which may start a query
+const bom = "\uFEFF"
+
+var escapes = map[byte]byte{'"': '"', '\\': '\\', '/': '/', 'b': '\b', 'f': '\f', 'n': '\n', 'r': '\r', 't': '\t'}
+
+func isLetter(c byte) bool { return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' }
+func isDigit(c byte) bool  { return '0' <= c && c <= '9' }
+
+// parser is a recursive descent parser over the lexer's tokens. Errors
+// panic with an *Error, which Parse recovers.
+type parser struct {
+	lexer
+	tok token
+}
+
+func (p *parser) advance() { p.tok = p.lexer.next() }
+
+func (p *parser) unexpected() {
+	p.fail(p.tok.loc, "Unexpected %s", p.tok)
+}
+
+func (p *parser) peek(punct string) bool {
+	return p.tok.kind == tokPunct && p.tok.text == punct
+}
+
+func (p *parser) expect(punct string) {
+	if !p.peek(punct) {
+		p.fail(p.tok.loc, "Expected %q, found %s", punct, p.tok)
+	}
+	p.advance()
+}
+
+func (p *parser) name() string {
+	if p.tok.kind != tokName {
+		p.fail(p.tok.loc, "Expected Name, found %s", p.tok)
+	}
+	name := p.tok.text
+	p.advance()
+	return name
+}
+
+// operation reads a { selection set } shorthand query or
+// (query|mutation) Name? VariableDefinitions? SelectionSet
+func (p *parser) operation() *Operation {
+	op := &Operation{Type: Query, Loc: p.tok.loc}
+	if p.peek("{") {
+		op.SelectionSet = p.selectionSet()
+		return op
+	}
+	if p.tok.kind != tokName {
+		p.unexpected()
+	}
+	switch p.tok.text {
+	case Query, Mutation:
+		op.Type = p.tok.text
+	case "subscription", "fragment":
+		p.fail(p.tok.loc, "%ss are not supported", p.tok.text)
+	default:
+		p.unexpected()
+	}
+	p.advance()
+	if p.tok.kind == tokName {
+		op.Name = p.name()
+	}
+	if p.peek("(") {
+		p.advance()
+		for !p.peek(")") {
+			op.Variables = append(op.Variables, p.variableDefinition())
+		}
+		p.advance()
+	}
+	p.noDirectives()
+	op.SelectionSet = p.selectionSet()
+	return op
+}
+
+func (p *parser) noDirectives() {
+	if p.peek("@") {
+		p.fail(p.tok.loc, "directives are not supported")
+	}
+}
+
+func (p *parser) variableDefinition() *VariableDefinition {
+	v := &VariableDefinition{Loc: p.tok.loc}
+	p.expect("$")
+	v.Name = p.name()
+	p.expect(":")
+	v.Type = p.typeRef()
+	if p.peek("=") {
+		p.advance()
+		v.Default = p.value(true)
+	}
+	p.noDirectives()
+	return v
+}
+
+func (p *parser) typeRef() *TypeRef {
+	t := &TypeRef{}
+	if p.peek("[") {
+		p.advance()
+		t.Elem = p.typeRef()
+		p.expect("]")
+	} else {
+		t.Name = p.name()
+	}
+	if p.peek("!") {
+		p.advance()
+		t.NonNull = true
+	}
+	return t
+}
+
+func (p *parser) selectionSet() []*Selection {
+	p.expect("{")
+	var set []*Selection
+	for !p.peek("}") {
+		set = append(set, p.selection())
+	}
+	if len(set) == 0 {
+		p.fail(p.tok.loc, "Expected Name, found %s", p.tok)
+	}
+	p.advance()
+	return set
+}
+
+// selection reads Alias? Name Arguments? SelectionSet?
+func (p *parser) selection() *Selection {
+	if p.peek("...") {
+		p.fail(p.tok.loc, "fragments are not supported")
+	}
+	s := &Selection{Loc: p.tok.loc}
+	s.Name = p.name()
+	if p.peek(":") {
+		p.advance()
+		s.Alias, s.Name = s.Name, p.name()
+	}
+	if p.peek("(") {
+		p.advance()
+		for !p.peek(")") {
+			arg := &Argument{Loc: p.tok.loc}
+			arg.Name = p.name()
+			p.expect(":")
+			arg.Value = p.value(false)
+			s.Arguments = append(s.Arguments, arg)
+		}
+		if len(s.Arguments) == 0 {
+			p.fail(p.tok.loc, "Expected Name, found %s", p.tok)
+		}
+		p.advance()
+	}
+	p.noDirectives()
+	if p.peek("{") {
+		s.SelectionSet = p.selectionSet()
+	}
+	return s
+}
+
+// value reads a value; a constant one, as a default is, may not contain
+// variables
+func (p *parser) value(constant bool) Value {
+	tok := p.tok
+	switch tok.kind {
+	case tokInt:
+		p.advance()
+		n, err := strconv.Atoi(tok.text)
+		if err != nil {
+			p.fail(tok.loc, "Int %s is out of range", tok.text)
+		}
+		return n
+	case tokFloat:
+		p.advance()
+		f, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			p.fail(tok.loc, "Float %s is out of range", tok.text)
+		}
+		return f
+	case tokString:
+		p.advance()
+		return tok.text
+	case tokName:
+		p.advance()
+		switch tok.text {
+		case "true":
+			return true
+		case "false":
+			return false
+		case "null":
+			return NullValue{}
+		}
+		return EnumValue(tok.text)
+	}
+	switch {
+	case p.peek("$") && !constant:
+		p.advance()
+		return Variable(p.name())
+	case p.peek("["):
+		p.advance()
+		list := ListValue{}
+		for !p.peek("]") {
+			list = append(list, p.value(constant))
+		}
+		p.advance()
+		return list
+	case p.peek("{"):
+		p.advance()
+		obj := ObjectValue{}
+		for !p.peek("}") {
+			f := &ObjectField{Name: p.name()}
+			p.expect(":")
+			f.Value = p.value(constant)
+			obj = append(obj, f)
+		}
+		p.advance()
+		return obj
+	}
+	p.unexpected()
+	return nil
+}
@@ -0,0 +1,388 @@
+// Package httpclient calls upstream HTTP services that fail now and then.
+// A Client wraps http.Client with a timeout on each attempt, retries with
+// backoff on 5xx responses and transport errors, an optional circuit
+// breaker and hooks to log every attempt:
+//
+//	c := httpclient.New(httpclient.Options{
+//		Timeout:     2 * time.Second,
+//		MaxAttempts: 3,
+//		Breaker:     httpclient.NewBreaker(httpclient.BreakerConfig{Threshold: 5, Cooldown: 30 * time.Second}),
+//		OnResponse:  httpclient.LogAttempts(logger),
+//	})
+//	resp, err := c.Get(ctx, "http://inventory/items/7")
+//
+// Only requests that are safe to send twice are retried: GET, HEAD,
+// OPTIONS, TRACE, PUT and DELETE, or any request with an Idempotency-Key
+// header, and only if their body can be sent again (see
+// http.Request.GetBody). The caller's context bounds the whole call,
+// backoff included.
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/clock"
+)
+
+// ErrCircuitOpen is returned without calling the upstream while a
+// Breaker is open
+var ErrCircuitOpen = errors.New("httpclient: circuit breaker open")
+
+// Options configure a Client. The zero Options make three attempts of 10s
+// each, 100ms and then 200ms apart.
+type Options struct {
+	// Client sends the requests; nil means a new http.Client. Its own
+	// Timeout, if any, also applies to each attempt.
+	Client *http.Client
+
+	// Timeout bounds each attempt, reading the response body included
+	Timeout time.Duration
+
+	// MaxAttempts bounds the attempts of one call; 1 turns retries off
+	MaxAttempts int
+
+	// Backoff is the wait before the first retry, doubled before each
+	// retry after that, up to MaxBackoff. A Retry-After header asking for
+	// longer is honored, unless it is longer than MaxBackoff, in which
+	// case the response is returned instead.
+	Backoff, MaxBackoff time.Duration
+
+	// Breaker, if set, fails calls fast while the upstream is down. Share
+	// one Breaker between the Clients of one upstream.
+	Breaker *Breaker
+
+	// OnRequest, if set, is called before each attempt and may add
+	// headers to req
+	OnRequest func(req *http.Request, attempt int)
+
+	// OnResponse, if set, is called after each attempt
+	OnResponse func(Attempt)
+}
+
+// Attempt describes one attempt of a call, for Options.OnResponse
+type Attempt struct {
+	Request  *http.Request
+	Number   int            // 1 for the first attempt
+	Response *http.Response // nil if Err is set
+	Err      error
+	Duration time.Duration
+
+	// Retry is whether another attempt follows, after Wait
+	Retry bool
+	Wait  time.Duration
+}
+
+// Client sends requests with retries. It is safe for concurrent use.
+type Client struct {
+	opts Options
+}
+
+// New returns a Client with opts, filling in defaults for unset fields
+func New(opts Options) *Client {
+	if opts.Client == nil {
+		opts.Client = &http.Client{}
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 3
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = 100 * time.Millisecond
+	}
+	if opts.MaxBackoff < opts.Backoff {
+		opts.MaxBackoff = max(opts.Backoff, 5*time.Second)
+	}
+	return &Client{opts: opts}
+}
+
+// Get sends a GET request for url
+func (c *Client) Get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(req)
+}
+
+// Do sends req, retrying as the package comment describes. As with
+// http.Client, a response is not an error whatever its status: when the
+// attempts run out on 5xx responses the last one is returned. The caller
+// closes the body of the response it gets; the bodies of retried
+// responses are closed by Do.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	retryable := isIdempotent(req) && (req.Body == nil || req.Body == http.NoBody || req.GetBody != nil)
+	backoff := c.opts.Backoff
+	for attempt := 1; ; attempt++ {
+		if b := c.opts.Breaker; b != nil {
+			if err := b.Allow(); err != nil {
+				return nil, err
+			}
+		}
+		r := req
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("httpclient: rewinding body: %w", err)
+			}
+			r = req.Clone(ctx)
+			r.Body = body
+		}
+
+		start := time.Now()
+		resp, err := c.send(r, attempt)
+		if err != nil && ctx.Err() != nil {
+			// The caller gave up, which says nothing about the upstream
+			return nil, err
+		}
+		failed := err != nil || resp.StatusCode >= 500
+		if b := c.opts.Breaker; b != nil {
+			b.Record(!failed)
+		}
+
+		wait := backoff
+		if resp != nil {
+			wait = max(wait, retryAfter(resp.Header.Get("Retry-After"), time.Now()))
+		}
+		retry := failed && retryable && attempt < c.opts.MaxAttempts && wait <= c.opts.MaxBackoff
+		if c.opts.OnResponse != nil {
+			c.opts.OnResponse(Attempt{Request: r, Number: attempt, Response: resp, Err: err, Duration: time.Since(start), Retry: retry, Wait: wait})
+		}
+		if !retry {
+			return resp, err
+		}
+		if resp != nil {
+			// Reading the body to the end lets the connection be reused
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+		backoff = min(2*backoff, c.opts.MaxBackoff)
+	}
+}
+
+// send makes one attempt under the attempt timeout, which keeps running
+// until the response body is closed
+func (c *Client) send(req *http.Request, attempt int) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), c.opts.Timeout)
+	req = req.WithContext(ctx)
+	if c.opts.OnRequest != nil {
+		c.opts.OnRequest(req, attempt)
+	}
+	resp, err := c.opts.Client.Do(req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelBody ends an attempt's context when its body is closed
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// isIdempotent reports whether sending req twice has the effect of
+// sending it once
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+// retryAfter reads a Retry-After header, in seconds or an HTTP date, as a
+// wait from now; zero if there is none
+func retryAfter(header string, now time.Time) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(header); err == nil {
+		return time.Duration(max(secs, 0)) * time.Second
+	}
+	if t, err := http.ParseTime(header); err == nil {
+		return max(t.Sub(now), 0)
+	}
+	return 0
+}
+
+// LogAttempts returns an Options.OnResponse hook logging each attempt to
+// logger: failures that will be retried as warnings, the rest at info
+func LogAttempts(logger *slog.Logger) func(Attempt) {
+	return func(a Attempt) {
+		attrs := []any{
+			"method", a.Request.Method,
+			"url", a.Request.URL.String(),
+			"attempt", a.Number,
+			"duration", a.Duration,
+		}
+		if a.Response != nil {
+			attrs = append(attrs, "status", a.Response.StatusCode)
+		}
+		if a.Err != nil {
+			attrs = append(attrs, "error", a.Err)
+		}
+		if a.Retry {
+			logger.Warn("upstream request failed; retrying", append(attrs, "wait", a.Wait)...)
+			return
+		}
+		logger.Info("upstream request", attrs...)
+	}
+}
+
+// State is the state of a Breaker
+type State int
+
+const (
+	// Closed lets calls through, counting consecutive failures
+	Closed State = iota
+	// Open fails calls with ErrCircuitOpen until the cooldown has passed
+	Open
+	// HalfOpen lets one trial call through a cooldown; its success closes
+	// the breaker and its failure opens it again
+	HalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	}
+	return "State(" + strconv.Itoa(int(s)) + ")"
+}
+
+// BreakerConfig configures a Breaker. The zero BreakerConfig opens after 5
+// consecutive failures and tries again after 30s.
+type BreakerConfig struct {
+	// Threshold is the number of consecutive failures that opens the
+	// breaker
+	Threshold int
+
+	// Cooldown is how long the breaker stays open before letting a trial
+	// call through, and how long it waits for that call before letting
+	// another through
+	Cooldown time.Duration
+
+	// OnStateChange, if set, is called with the breaker's lock held
+	// whenever it changes state
+	OnStateChange func(from, to State)
+
+	// Clock tells the time; nil means clock.Real. Tests pass a clock.Fake.
+	Clock clock.Clock
+}
+
+// Breaker is a circuit breaker. While an upstream keeps failing, calls
+// fail fast instead of piling up behind timeouts, and the upstream gets a
+// rest; one trial call per cooldown finds out when it is back. It is safe
+// for concurrent use.
+type Breaker struct {
+	cfg BreakerConfig
+
+	mu       sync.Mutex
+	state    State
+	failures int       // consecutive, while closed
+	since    time.Time // when the breaker opened or let the last trial through
+}
+
+// NewBreaker returns a closed Breaker
+func NewBreaker(cfg BreakerConfig) *Breaker {
+	if cfg.Threshold <= 0 {
+		cfg.Threshold = 5
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = 30 * time.Second
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = clock.Real
+	}
+	return &Breaker{cfg: cfg}
+}
+
+// State returns the breaker's current state
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Allow returns ErrCircuitOpen if a call may not go through now. A call
+// that is allowed reports its outcome with Record.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == Closed {
+		return nil
+	}
+	// Open or half-open: one trial per cooldown, so a trial that never
+	// reports back does not keep the breaker from trying again
+	now := b.cfg.Clock.Now()
+	if now.Sub(b.since) < b.cfg.Cooldown {
+		return ErrCircuitOpen
+	}
+	b.since = now
+	b.setState(HalfOpen)
+	return nil
+}
+
+// Record reports the outcome of a call Allow let through
+func (b *Breaker) Record(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch {
+	case success:
+		b.failures = 0
+		b.setState(Closed)
+	case b.state == HalfOpen:
+		b.since = b.cfg.Clock.Now()
+		b.setState(Open)
+	case b.state == Closed:
+		b.failures++
+		if b.failures >= b.cfg.Threshold {
+			b.failures = 0
+			b.since = b.cfg.Clock.Now()
+			b.setState(Open)
+		}
+	}
+}
+
+// setState moves to state s; b.mu is held
+func (b *Breaker) setState(s State) {
+	if b.state == s {
+		return
+	}
+	from := b.state
+	b.state = s
+	if b.cfg.OnStateChange != nil {
+		b.cfg.OnStateChange(from, s)
+	}
+}
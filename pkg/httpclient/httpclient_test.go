@@ -0,0 +1,416 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/clock"
+)
+
+// flakyServer answers each request with the next of statuses, then with
+// 200 once they run out, and counts the requests
+type flakyServer struct {
+	*httptest.Server
+	calls atomic.Int32
+}
+
+func newFlakyServer(t *testing.T, statuses ...int) *flakyServer {
+	t.Helper()
+	s := &flakyServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(s.calls.Add(1))
+		if n <= len(statuses) {
+			w.WriteHeader(statuses[n-1])
+			fmt.Fprintf(w, "attempt %d failed", n)
+			return
+		}
+		fmt.Fprintf(w, "ok after %d", n)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// fast is Options with retries quick enough for tests
+func fast(opts Options) Options {
+	opts.Backoff = time.Millisecond
+	opts.MaxBackoff = 10 * time.Millisecond
+	return opts
+}
+
+func readBody(t *testing.T, resp *http.Response) string {
+	t.Helper()
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestDo_Retries(t *testing.T) {
+	tests := []struct {
+		name       string
+		statuses   []int
+		method     string
+		header     http.Header
+		wantStatus int
+		wantBody   string
+		wantCalls  int
+	}{
+		{"success", nil, http.MethodGet, nil, 200, "ok after 1", 1},
+		{"recovers from 5xx", []int{503, 502}, http.MethodGet, nil, 200, "ok after 3", 3},
+		{"gives up after MaxAttempts", []int{500, 500, 500, 500}, http.MethodGet, nil, 500, "attempt 3 failed", 3},
+		{"4xx is not retried", []int{404}, http.MethodGet, nil, 404, "attempt 1 failed", 1},
+		{"POST is not retried", []int{503}, http.MethodPost, nil, 503, "attempt 1 failed", 1},
+		{"POST with an Idempotency-Key is", []int{503}, http.MethodPost, http.Header{"Idempotency-Key": {"k1"}}, 200, "ok after 2", 2},
+		{"DELETE is", []int{503}, http.MethodDelete, nil, 200, "ok after 2", 2},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := newFlakyServer(t, tc.statuses...)
+			var attempts []Attempt
+			c := New(fast(Options{OnResponse: func(a Attempt) { attempts = append(attempts, a) }}))
+
+			req, _ := http.NewRequest(tc.method, srv.URL, nil)
+			for k, v := range tc.header {
+				req.Header[k] = v
+			}
+			resp, err := c.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if body := readBody(t, resp); resp.StatusCode != tc.wantStatus || body != tc.wantBody {
+				t.Errorf("response = %d %q; want %d %q", resp.StatusCode, body, tc.wantStatus, tc.wantBody)
+			}
+			if n := int(srv.calls.Load()); n != tc.wantCalls {
+				t.Errorf("%d requests; want %d", n, tc.wantCalls)
+			}
+			if len(attempts) != tc.wantCalls {
+				t.Fatalf("OnResponse called %d times; want %d", len(attempts), tc.wantCalls)
+			}
+			for i, a := range attempts {
+				if a.Number != i+1 || a.Retry != (i < tc.wantCalls-1) {
+					t.Errorf("attempt %d = number %d, retry %v", i+1, a.Number, a.Retry)
+				}
+			}
+		})
+	}
+}
+
+// A retried PUT sends its body again
+func TestDo_ResendsBody(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		bodies []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		bodies = append(bodies, string(b))
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodPut, srv.URL, strings.NewReader(`{"title":"Go"}`))
+	resp, err := New(fast(Options{})).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	mu.Lock()
+	defer mu.Unlock()
+	if resp.StatusCode != 200 || len(bodies) != 2 || bodies[1] != `{"title":"Go"}` {
+		t.Errorf("status %d, bodies %q; want 200 after the same body twice", resp.StatusCode, bodies)
+	}
+}
+
+func TestDo_RetryAfter(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryAfter string
+		wantCalls  int32
+	}{
+		{"within MaxBackoff is honored", "0", 2},
+		{"beyond MaxBackoff returns the response", "120", 1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if calls.Add(1) == 1 {
+					w.Header().Set("Retry-After", tc.retryAfter)
+					w.WriteHeader(http.StatusServiceUnavailable)
+				}
+			}))
+			defer srv.Close()
+
+			resp, err := New(fast(Options{})).Get(context.Background(), srv.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if calls.Load() != tc.wantCalls {
+				t.Errorf("%d requests; want %d", calls.Load(), tc.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		header string
+		want   time.Duration
+	}{
+		{"", 0},
+		{"3", 3 * time.Second},
+		{"-1", 0},
+		{"Mon, 01 Jan 2024 00:00:10 GMT", 10 * time.Second},
+		{"Sun, 31 Dec 2023 23:59:00 GMT", 0},
+		{"soon", 0},
+	}
+	for _, tc := range tests {
+		if got := retryAfter(tc.header, now); got != tc.want {
+			t.Errorf("retryAfter(%q) = %v; want %v", tc.header, got, tc.want)
+		}
+	}
+}
+
+// An attempt that outlasts Timeout is abandoned and retried, and the
+// response that comes back in time can still be read after Do returns
+func TestDo_Timeout(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			<-r.Context().Done()
+			return
+		}
+		w.(http.Flusher).Flush()
+		time.Sleep(20 * time.Millisecond)
+		io.WriteString(w, "slow but in time")
+	}))
+	defer srv.Close()
+
+	resp, err := New(fast(Options{Timeout: 200 * time.Millisecond})).Get(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body := readBody(t, resp); body != "slow but in time" || calls.Load() != 2 {
+		t.Errorf("body %q after %d requests; want the second response", body, calls.Load())
+	}
+}
+
+func TestDo_TransportError(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	var attempts int
+	c := New(fast(Options{OnResponse: func(a Attempt) { attempts++ }}))
+	if _, err := c.Get(context.Background(), url); err == nil {
+		t.Fatal("Get succeeded against a closed server")
+	}
+	if attempts != 3 {
+		t.Errorf("%d attempts; want 3", attempts)
+	}
+}
+
+// The caller's context bounds the whole call, backoff included
+func TestDo_ContextCanceled(t *testing.T) {
+	srv := newFlakyServer(t, 503, 503, 503)
+	c := New(Options{Backoff: time.Minute, MaxBackoff: time.Minute})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.Get(ctx, srv.URL)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Get = %v; want context.DeadlineExceeded", err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("Get took %v; want it to stop with the context", d)
+	}
+}
+
+func TestDo_OnRequest(t *testing.T) {
+	var (
+		mu  sync.Mutex
+		got []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, r.Header.Get("X-Attempt"))
+		if len(got) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	c := New(fast(Options{OnRequest: func(req *http.Request, attempt int) {
+		req.Header.Set("X-Attempt", fmt.Sprint(attempt))
+	}}))
+	resp, err := c.Get(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	mu.Lock()
+	defer mu.Unlock()
+	if strings.Join(got, ",") != "1,2" {
+		t.Errorf("X-Attempt headers = %q; want 1 then 2", got)
+	}
+}
+
+func TestBreaker(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	var changes []string
+	b := NewBreaker(BreakerConfig{Threshold: 2, Cooldown: time.Minute, Clock: clk, OnStateChange: func(from, to State) {
+		changes = append(changes, from.String()+"->"+to.String())
+	}})
+
+	// step is an action on the breaker and the state and Allow result
+	// after it
+	steps := []struct {
+		do        func()
+		wantState State
+		wantAllow error
+	}{
+		{func() { b.Record(false) }, Closed, nil},
+		{func() { b.Record(true) }, Closed, nil}, // a success resets the count
+		{func() { b.Record(false) }, Closed, nil},
+		{func() { b.Record(false) }, Open, ErrCircuitOpen},
+		{func() { clk.Advance(59 * time.Second) }, Open, ErrCircuitOpen},
+		{func() { clk.Advance(time.Second) }, Open, nil},   // the trial goes through
+		{func() {}, HalfOpen, ErrCircuitOpen},              // and only it
+		{func() { b.Record(false) }, Open, ErrCircuitOpen}, // it failed
+		{func() { clk.Advance(time.Minute) }, Open, nil},
+		{func() { b.Record(true) }, Closed, nil},
+	}
+	for i, s := range steps {
+		s.do()
+		if got := b.State(); got != s.wantState {
+			t.Errorf("step %d: state = %v; want %v", i, got, s.wantState)
+		}
+		if err := b.Allow(); err != s.wantAllow {
+			t.Errorf("step %d: Allow = %v; want %v", i, err, s.wantAllow)
+		}
+	}
+
+	want := "closed->open,open->half-open,half-open->open,open->half-open,half-open->closed"
+	if got := strings.Join(changes, ","); got != want {
+		t.Errorf("state changes = %s; want %s", got, want)
+	}
+}
+
+// A trial that never reports back does not keep the breaker from trying
+// again after another cooldown
+func TestBreaker_LostTrial(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	b := NewBreaker(BreakerConfig{Threshold: 1, Cooldown: time.Minute, Clock: clk})
+	b.Record(false)
+	clk.Advance(time.Minute)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("first trial: %v", err)
+	}
+	if err := b.Allow(); err != ErrCircuitOpen {
+		t.Fatalf("second call during the trial = %v; want ErrCircuitOpen", err)
+	}
+	clk.Advance(time.Minute)
+	if err := b.Allow(); err != nil {
+		t.Errorf("trial after another cooldown: %v", err)
+	}
+}
+
+// Once the upstream has failed Threshold times in a row, calls fail fast
+// without reaching it
+func TestDo_Breaker(t *testing.T) {
+	srv := newFlakyServer(t, 500, 500, 500, 500, 500, 500)
+	clk := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	breaker := NewBreaker(BreakerConfig{Threshold: 4, Cooldown: time.Minute, Clock: clk})
+	c := New(fast(Options{MaxAttempts: 3, Breaker: breaker}))
+
+	// 3 failed attempts, then 1 more and the breaker opens mid-call
+	resp, err := c.Get(context.Background(), srv.URL)
+	if err != nil || resp.StatusCode != 500 {
+		t.Fatalf("first call = %v, %v; want the last 500", resp, err)
+	}
+	resp.Body.Close()
+	if _, err := c.Get(context.Background(), srv.URL); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("second call = %v; want ErrCircuitOpen once the breaker opens", err)
+	}
+	if n := srv.calls.Load(); n != 4 {
+		t.Errorf("%d requests; want 4 before the breaker opened", n)
+	}
+	if _, err := c.Get(context.Background(), srv.URL); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Get while open = %v; want ErrCircuitOpen", err)
+	}
+	if n := srv.calls.Load(); n != 4 {
+		t.Errorf("%d requests; want none while open", n)
+	}
+
+	// After the cooldown one trial goes through; its failure reopens
+	clk.Advance(time.Minute)
+	resp, err = c.Get(context.Background(), srv.URL)
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Get after cooldown = %v, %v; want the trial to fail and the retry to find the breaker open", resp, err)
+	}
+	if n := srv.calls.Load(); n != 5 {
+		t.Errorf("%d requests; want 5 after the trial", n)
+	}
+
+	// The next trial finds the upstream back
+	clk.Advance(time.Minute)
+	srv.calls.Store(6)
+	resp, err = c.Get(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body := readBody(t, resp); body != "ok after 7" || breaker.State() != Closed {
+		t.Errorf("body %q, breaker %v; want ok and closed", body, breaker.State())
+	}
+}
+
+func Example() {
+	var calls atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			http.Error(w, "try again", http.StatusServiceUnavailable)
+			return
+		}
+		io.WriteString(w, "hello")
+	}))
+	defer upstream.Close()
+
+	c := New(Options{
+		Backoff: time.Millisecond,
+		OnResponse: func(a Attempt) {
+			fmt.Printf("attempt %d: %d, retry %v\n", a.Number, a.Response.StatusCode, a.Retry)
+		},
+	})
+	resp, err := c.Get(context.Background(), upstream.URL)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	fmt.Println(string(body))
+	// Output:
+	// attempt 1: 503, retry true
+	// attempt 2: 503, retry true
+	// attempt 3: 200, retry false
+	// hello
+}
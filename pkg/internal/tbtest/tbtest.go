@@ -0,0 +1,23 @@
+// Package tbtest helps test the pkg packages whose helpers take a
+// testing.TB: a Recorder stands in for the test, so a test can check what
+// a helper reported without failing itself. It is internal, as only
+// those packages' own tests need it.
+package tbtest
+
+import (
+	"fmt"
+	"testing"
+)
+
+// Recorder is a testing.TB that keeps its errors rather than failing.
+// Anything else, such as Fatal or TempDir, goes to the embedded TB.
+type Recorder struct {
+	testing.TB
+	Errors []string
+}
+
+func (r *Recorder) Helper() {}
+
+func (r *Recorder) Errorf(format string, args ...any) {
+	r.Errors = append(r.Errors, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,146 @@
+// Package jwt signs and verifies JSON Web Tokens (RFC 7519) with HMAC
+// SHA-256, the HS256 algorithm. It is written out by hand to show what a
+// token is, three base64url parts joined by dots:
+//
+//	base64url(header) . base64url(claims) . base64url(HMAC-SHA256(key, first two parts))
+//
+// and supports only what the REST API needs: one algorithm, a shared key,
+// and the sub, iat and exp claims plus a role. Libraries such as
+// github.com/golang-jwt/jwt add more algorithms, key rotation and audience
+// checks.
+package jwt
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Errors returned by Verify. Check them with errors.Is.
+var (
+	ErrMalformed        = errors.New("jwt: malformed token")
+	ErrAlgorithm        = errors.New("jwt: unsupported algorithm")
+	ErrInvalidSignature = errors.New("jwt: invalid signature")
+	ErrExpired          = errors.New("jwt: token expired")
+)
+
+// MinKeySize is the shortest key Sign and Verify accept; RFC 7518 requires
+// an HS256 key at least as long as the hash output
+const MinKeySize = sha256.Size
+
+// ErrShortKey is returned for keys shorter than MinKeySize
+var ErrShortKey = fmt.Errorf("jwt: key shorter than %d bytes", MinKeySize)
+
+// Claims is the token payload. Times are whole seconds since the Unix
+// epoch on the wire, as the RFC's NumericDate requires.
+type Claims struct {
+	Subject   string `json:"sub"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+
+	// Role is a private claim for role-based access control; the
+	// application decides what each role may do
+	Role string `json:"role,omitempty"`
+}
+
+// NewClaims returns claims for subject issued at now and valid for ttl
+func NewClaims(subject string, now time.Time, ttl time.Duration) Claims {
+	return Claims{Subject: subject, IssuedAt: now.Unix(), ExpiresAt: now.Add(ttl).Unix()}
+}
+
+// header is fixed: verifying a token whose header names another algorithm
+// fails, which rules out the "alg": "none" attack
+type header struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+}
+
+// encodedHeader is base64url({"alg":"HS256","typ":"JWT"})
+var encodedHeader = encode(must(json.Marshal(header{Alg: "HS256", Typ: "JWT"})))
+
+// Sign returns claims as a signed token
+func Sign(claims Claims, key []byte) (string, error) {
+	if len(key) < MinKeySize {
+		return "", ErrShortKey
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signingInput := encodedHeader + "." + encode(payload)
+	return signingInput + "." + encode(mac(key, signingInput)), nil
+}
+
+// Verify checks token's signature with key and its expiry against now,
+// and returns its claims. A token is expired from the second ExpiresAt
+// names onwards.
+func Verify(token string, key []byte, now time.Time) (Claims, error) {
+	if len(key) < MinKeySize {
+		return Claims{}, ErrShortKey
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Claims{}, ErrMalformed
+	}
+
+	var h header
+	if err := decodeJSON(parts[0], &h); err != nil {
+		return Claims{}, err
+	}
+	if h.Alg != "HS256" {
+		return Claims{}, fmt.Errorf("%w %q", ErrAlgorithm, h.Alg)
+	}
+
+	// Check the signature before trusting anything in the payload.
+	// hmac.Equal takes the same time wherever the first difference is, so
+	// response times do not reveal how much of a forged signature was right.
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Claims{}, ErrMalformed
+	}
+	if !hmac.Equal(sig, mac(key, parts[0]+"."+parts[1])) {
+		return Claims{}, ErrInvalidSignature
+	}
+
+	var claims Claims
+	if err := decodeJSON(parts[1], &claims); err != nil {
+		return Claims{}, err
+	}
+	if now.Unix() >= claims.ExpiresAt {
+		return Claims{}, ErrExpired
+	}
+	return claims, nil
+}
+
+func mac(key []byte, signingInput string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(signingInput))
+	return h.Sum(nil)
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeJSON(part string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return ErrMalformed
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	return nil
+}
+
+func must(b []byte, err error) []byte {
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
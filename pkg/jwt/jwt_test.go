@@ -0,0 +1,135 @@
+package jwt
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	testKey = []byte("0123456789abcdef0123456789abcdef")
+	t0      = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+)
+
+func TestSignVerify_RoundTrip(t *testing.T) {
+	claims := NewClaims("alice", t0, time.Hour)
+	claims.Role = "editor"
+	token, err := Sign(claims, testKey)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	if n := strings.Count(token, "."); n != 2 {
+		t.Fatalf("token %q has %d dots; want 2", token, n)
+	}
+
+	got, err := Verify(token, testKey, t0.Add(59*time.Minute))
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if got != claims {
+		t.Errorf("claims = %+v; want %+v", got, claims)
+	}
+}
+
+// The header and payload are only encoded, not encrypted
+func TestSign_KnownToken(t *testing.T) {
+	token, err := Sign(Claims{Subject: "alice", IssuedAt: 1, ExpiresAt: 2}, testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(token, ".")
+	header, _ := base64.RawURLEncoding.DecodeString(parts[0])
+	payload, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	if string(header) != `{"alg":"HS256","typ":"JWT"}` {
+		t.Errorf("header = %s", header)
+	}
+	if string(payload) != `{"sub":"alice","iat":1,"exp":2}` {
+		t.Errorf("payload = %s", payload)
+	}
+}
+
+func TestVerify_Errors(t *testing.T) {
+	valid, err := Sign(NewClaims("alice", t0, time.Hour), testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(valid, ".")
+	b64 := base64.RawURLEncoding.EncodeToString
+
+	otherKey := []byte("another-key-that-is-32-bytes-lon")
+	forgedPayload := b64([]byte(`{"sub":"admin","iat":0,"exp":9999999999}`))
+	noneHeader := b64([]byte(`{"alg":"none","typ":"JWT"}`))
+
+	tests := []struct {
+		name    string
+		token   string
+		now     time.Time
+		wantErr error
+	}{
+		{"expired", valid, t0.Add(time.Hour), ErrExpired},
+		{"long expired", valid, t0.Add(48 * time.Hour), ErrExpired},
+		{"tampered payload", parts[0] + "." + forgedPayload + "." + parts[2], t0, ErrInvalidSignature},
+		{"tampered signature", parts[0] + "." + parts[1] + "." + b64([]byte("not the signature")), t0, ErrInvalidSignature},
+		{"signed with another key", mustSign(t, otherKey), t0, ErrInvalidSignature},
+		{"alg none", noneHeader + "." + parts[1] + ".", t0, ErrAlgorithm},
+		{"two parts", parts[0] + "." + parts[1], t0, ErrMalformed},
+		{"empty", "", t0, ErrMalformed},
+		{"bad base64", parts[0] + ".!!!." + parts[2], t0, ErrInvalidSignature},
+		{"bad header json", b64([]byte("{")) + "." + parts[1] + "." + parts[2], t0, ErrMalformed},
+		{"missing exp", signRaw(t, `{"sub":"alice","iat":0}`), t0, ErrExpired},
+		{"payload not json", signRaw(t, `not json`), t0, ErrMalformed},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := Verify(tc.token, testKey, tc.now); !errors.Is(err, tc.wantErr) {
+				t.Errorf("Verify error = %v; want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestShortKey(t *testing.T) {
+	short := []byte("too short")
+	if _, err := Sign(Claims{}, short); !errors.Is(err, ErrShortKey) {
+		t.Errorf("Sign error = %v; want ErrShortKey", err)
+	}
+	if _, err := Verify("a.b.c", short, t0); !errors.Is(err, ErrShortKey) {
+		t.Errorf("Verify error = %v; want ErrShortKey", err)
+	}
+}
+
+func mustSign(t *testing.T, key []byte) string {
+	t.Helper()
+	token, err := Sign(NewClaims("alice", t0, time.Hour), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// signRaw signs an arbitrary payload with testKey, so tests can check
+// what Verify does with well-signed but unusual claims
+func signRaw(t *testing.T, payload string) string {
+	t.Helper()
+	input := encodedHeader + "." + base64.RawURLEncoding.EncodeToString([]byte(payload))
+	return input + "." + base64.RawURLEncoding.EncodeToString(mac(testKey, input))
+}
+
+func Example() {
+	key := []byte("a secret of at least thirty-two bytes")
+	issued := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	token, _ := Sign(NewClaims("alice", issued, 15*time.Minute), key)
+
+	claims, err := Verify(token, key, issued.Add(10*time.Minute))
+	fmt.Println(claims.Subject, err)
+
+	_, err = Verify(token, key, issued.Add(time.Hour))
+	fmt.Println(err)
+	// Output:
+	// alice <nil>
+	// jwt: token expired
+}
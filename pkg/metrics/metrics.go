@@ -0,0 +1,328 @@
+// Package metrics records counters, gauges and histograms and writes them
+// in the Prometheus text exposition format, without the Prometheus client
+// library:
+//
+//	reg := metrics.NewRegistry()
+//	requests := reg.NewCounter("http_requests_total", "Requests served.", "method", "status")
+//	requests.Inc("GET", "200")
+//	http.Handle("/metrics", reg)
+//
+// A metric declares its label names when it is created and every update
+// passes one value per name, in the same order. Each distinct set of
+// values is a series, so label values must come from a small set, such
+// as route patterns rather than raw paths.
+//
+// Metrics are safe for concurrent use. Misuse, such as a wrong number of
+// label values or a duplicate name, panics: it is a bug in the caller.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are histogram bucket upper bounds suited to request
+// durations in seconds, from 5ms to 10s
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+var (
+	nameRE  = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	labelRE = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// Registry holds metrics and writes them out
+type Registry struct {
+	mu       sync.Mutex
+	families map[string]*family
+}
+
+// NewRegistry returns an empty registry
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]*family)}
+}
+
+// kind is a metric type as the exposition format names it
+type kind string
+
+const (
+	kindCounter   kind = "counter"
+	kindGauge     kind = "gauge"
+	kindHistogram kind = "histogram"
+)
+
+// family is one named metric and all its series
+type family struct {
+	name, help string
+	kind       kind
+	labels     []string
+	buckets    []float64 // histograms only
+
+	mu     sync.Mutex
+	series map[string]*series // by joined label values
+}
+
+// series is one set of label values. Counters and gauges use value;
+// histograms use counts (cumulative per bucket), sum and count.
+type series struct {
+	labelValues []string
+	value       float64
+	counts      []uint64
+	sum         float64
+	count       uint64
+}
+
+func (r *Registry) register(name, help string, k kind, buckets []float64, labels []string) *family {
+	if !nameRE.MatchString(name) {
+		panic(fmt.Sprintf("metrics: invalid metric name %q", name))
+	}
+	for _, l := range labels {
+		if !labelRE.MatchString(l) || strings.HasPrefix(l, "__") || (k == kindHistogram && l == "le") {
+			panic(fmt.Sprintf("metrics: invalid label name %q for %s", l, name))
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, dup := r.families[name]; dup {
+		panic(fmt.Sprintf("metrics: %s registered twice", name))
+	}
+	f := &family{name: name, help: help, kind: k, labels: labels, buckets: buckets, series: make(map[string]*series)}
+	r.families[name] = f
+	return f
+}
+
+// lookup returns the series for labelValues, or nil if nothing has been
+// recorded for them. f.mu must be held.
+func (f *family) lookup(labelValues []string) (*series, string) {
+	if len(labelValues) != len(f.labels) {
+		panic(fmt.Sprintf("metrics: %s has labels %v, got %d values", f.name, f.labels, len(labelValues)))
+	}
+	// The values are joined with a byte that cannot appear in valid UTF-8
+	key := strings.Join(labelValues, "\xff")
+	return f.series[key], key
+}
+
+// get returns the series for labelValues, creating it on first use.
+// f.mu must be held.
+func (f *family) get(labelValues []string) *series {
+	s, key := f.lookup(labelValues)
+	if s == nil {
+		s = &series{labelValues: append([]string(nil), labelValues...)}
+		if f.kind == kindHistogram {
+			s.counts = make([]uint64, len(f.buckets))
+		}
+		f.series[key] = s
+	}
+	return s
+}
+
+// Counter is a value that only goes up, such as requests served
+type Counter struct{ f *family }
+
+// NewCounter registers a counter. Counter names conventionally end in _total.
+func (r *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	return &Counter{r.register(name, help, kindCounter, nil, labels)}
+}
+
+// Inc adds one
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds v, which must not be negative
+func (c *Counter) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		panic(fmt.Sprintf("metrics: counter %s decreased by %v", c.f.name, v))
+	}
+	c.f.mu.Lock()
+	defer c.f.mu.Unlock()
+	c.f.get(labelValues).value += v
+}
+
+// Value returns the current value, for tests
+func (c *Counter) Value(labelValues ...string) float64 {
+	return c.f.value(labelValues)
+}
+
+// value reads a counter or gauge without creating its series
+func (f *family) value(labelValues []string) float64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if s, _ := f.lookup(labelValues); s != nil {
+		return s.value
+	}
+	return 0
+}
+
+// Gauge is a value that goes up and down, such as requests in flight
+type Gauge struct{ f *family }
+
+// NewGauge registers a gauge
+func (r *Registry) NewGauge(name, help string, labels ...string) *Gauge {
+	return &Gauge{r.register(name, help, kindGauge, nil, labels)}
+}
+
+// Set sets the gauge to v
+func (g *Gauge) Set(v float64, labelValues ...string) {
+	g.f.mu.Lock()
+	defer g.f.mu.Unlock()
+	g.f.get(labelValues).value = v
+}
+
+// Add adds v, which may be negative
+func (g *Gauge) Add(v float64, labelValues ...string) {
+	g.f.mu.Lock()
+	defer g.f.mu.Unlock()
+	g.f.get(labelValues).value += v
+}
+
+// Inc adds one
+func (g *Gauge) Inc(labelValues ...string) { g.Add(1, labelValues...) }
+
+// Dec subtracts one
+func (g *Gauge) Dec(labelValues ...string) { g.Add(-1, labelValues...) }
+
+// Value returns the current value, for tests
+func (g *Gauge) Value(labelValues ...string) float64 {
+	return g.f.value(labelValues)
+}
+
+// Histogram counts observations, such as request durations, into buckets
+type Histogram struct{ f *family }
+
+// NewHistogram registers a histogram with the given bucket upper bounds,
+// which must be sorted; nil means DefaultBuckets. A +Inf bucket is implied.
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	if !sort.Float64sAreSorted(buckets) {
+		panic(fmt.Sprintf("metrics: buckets of %s are not sorted", name))
+	}
+	return &Histogram{r.register(name, help, kindHistogram, buckets, labels)}
+}
+
+// Observe records v
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	h.f.mu.Lock()
+	defer h.f.mu.Unlock()
+	s := h.f.get(labelValues)
+	// Buckets are cumulative, so v counts in every bucket from the first
+	// whose bound it does not exceed
+	for i := sort.SearchFloat64s(h.f.buckets, v); i < len(s.counts); i++ {
+		s.counts[i]++
+	}
+	s.sum += v
+	s.count++
+}
+
+// Count returns the number of observations, for tests
+func (h *Histogram) Count(labelValues ...string) uint64 {
+	h.f.mu.Lock()
+	defer h.f.mu.Unlock()
+	if s, _ := h.f.lookup(labelValues); s != nil {
+		return s.count
+	}
+	return 0
+}
+
+// WriteText writes every metric in the text exposition format. Metrics
+// are sorted by name and series by label values, so the output is stable.
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	families := make([]*family, 0, len(r.families))
+	for _, f := range r.families {
+		families = append(families, f)
+	}
+	r.mu.Unlock()
+	sort.Slice(families, func(i, j int) bool { return families[i].name < families[j].name })
+
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		f.write(bw)
+	}
+	return bw.Flush()
+}
+
+func (f *family) write(w *bufio.Writer) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fmt.Fprintf(w, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", f.name, f.kind)
+
+	all := make([]*series, 0, len(f.series))
+	for _, s := range f.series {
+		all = append(all, s)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return strings.Join(all[i].labelValues, "\xff") < strings.Join(all[j].labelValues, "\xff")
+	})
+
+	for _, s := range all {
+		if f.kind != kindHistogram {
+			fmt.Fprintf(w, "%s%s %s\n", f.name, f.labelSet(s, ""), formatFloat(s.value))
+			continue
+		}
+		for i, le := range f.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", f.name, f.labelSet(s, formatFloat(le)), s.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", f.name, f.labelSet(s, "+Inf"), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", f.name, f.labelSet(s, ""), formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", f.name, f.labelSet(s, ""), s.count)
+	}
+}
+
+// labelSet formats {name="value",...}, adding le if it is not empty. It
+// returns "" for a series with no labels.
+func (f *family) labelSet(s *series, le string) string {
+	var pairs []string
+	for i, name := range f.labels {
+		pairs = append(pairs, name+`="`+escapeLabel(s.labelValues[i])+`"`)
+	}
+	if le != "" {
+		pairs = append(pairs, `le="`+le+`"`)
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+// escapeLabel escapes a label value as the format requires: backslash,
+// double quote and newline
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
+
+// escapeHelp escapes help text, where quotes need no escaping
+func escapeHelp(s string) string { return helpEscaper.Replace(s) }
+
+// formatFloat writes v as the format expects, including +Inf, -Inf and NaN
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// ServeHTTP serves the metrics, so a Registry can be mounted at /metrics
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	r.WriteText(w)
+}
@@ -0,0 +1,228 @@
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// parseText reads exposition output into sample values keyed by the
+// series as written, e.g. `requests_total{code="200"}`, and checks that
+// every sample follows a TYPE line for its metric
+func parseText(t *testing.T, r io.Reader) map[string]float64 {
+	t.Helper()
+	samples := make(map[string]float64)
+	types := make(map[string]string)
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := sc.Text()
+		if strings.HasPrefix(line, "# TYPE ") {
+			fields := strings.Fields(line)
+			types[fields[2]] = fields[3]
+			continue
+		}
+		if strings.HasPrefix(line, "#") || line == "" {
+			continue
+		}
+		i := strings.LastIndexByte(line, ' ')
+		if i < 0 {
+			t.Fatalf("sample line %q has no value", line)
+		}
+		series, value := line[:i], line[i+1:]
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			t.Fatalf("sample line %q: %v", line, err)
+		}
+		name, _, _ := strings.Cut(series, "{")
+		base := name
+		for _, suffix := range []string{"_bucket", "_sum", "_count"} {
+			if trimmed, ok := strings.CutSuffix(name, suffix); ok && types[trimmed] == "histogram" {
+				base = trimmed
+			}
+		}
+		if types[base] == "" {
+			t.Errorf("sample %q has no TYPE line before it", series)
+		}
+		samples[series] = v
+	}
+	return samples
+}
+
+func TestCounterAndGauge(t *testing.T) {
+	reg := NewRegistry()
+	requests := reg.NewCounter("requests_total", "Requests served.", "method", "code")
+	inFlight := reg.NewGauge("in_flight", "Requests being served.")
+
+	requests.Inc("GET", "200")
+	requests.Inc("GET", "200")
+	requests.Add(3, "POST", "201")
+	inFlight.Inc()
+	inFlight.Inc()
+	inFlight.Dec()
+	inFlight.Add(0.5)
+
+	if got := requests.Value("GET", "200"); got != 2 {
+		t.Errorf("requests GET 200 = %v; want 2", got)
+	}
+	if got := requests.Value("DELETE", "204"); got != 0 {
+		t.Errorf("unrecorded series = %v; want 0", got)
+	}
+
+	var b strings.Builder
+	if err := reg.WriteText(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP in_flight Requests being served.
+# TYPE in_flight gauge
+in_flight 1.5
+# HELP requests_total Requests served.
+# TYPE requests_total counter
+requests_total{method="GET",code="200"} 2
+requests_total{method="POST",code="201"} 3
+`
+	if got := b.String(); got != want {
+		t.Errorf("WriteText =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	reg := NewRegistry()
+	h := reg.NewHistogram("duration_seconds", "How long it took.", []float64{0.1, 1}, "path")
+	h.Observe(0.05, "/a")
+	h.Observe(0.1, "/a") // on a bound: counts in that bucket
+	h.Observe(2, "/a")
+	h.Observe(0.5, "/b")
+
+	samples := parseText(t, strings.NewReader(writeText(t, reg)))
+	want := map[string]float64{
+		`duration_seconds_bucket{path="/a",le="0.1"}`:  2,
+		`duration_seconds_bucket{path="/a",le="1"}`:    2,
+		`duration_seconds_bucket{path="/a",le="+Inf"}`: 3,
+		`duration_seconds_sum{path="/a"}`:              2.15,
+		`duration_seconds_count{path="/a"}`:            3,
+		`duration_seconds_bucket{path="/b",le="0.1"}`:  0,
+		`duration_seconds_bucket{path="/b",le="1"}`:    1,
+		`duration_seconds_bucket{path="/b",le="+Inf"}`: 1,
+		`duration_seconds_sum{path="/b"}`:              0.5,
+		`duration_seconds_count{path="/b"}`:            1,
+	}
+	if len(samples) != len(want) {
+		t.Errorf("got %d samples; want %d: %v", len(samples), len(want), samples)
+	}
+	for series, v := range want {
+		if got, ok := samples[series]; !ok || got != v {
+			t.Errorf("%s = %v (present %v); want %v", series, got, ok, v)
+		}
+	}
+	if got := h.Count("/a"); got != 3 {
+		t.Errorf("Count(/a) = %d; want 3", got)
+	}
+}
+
+func writeText(t *testing.T, reg *Registry) string {
+	t.Helper()
+	var b strings.Builder
+	if err := reg.WriteText(&b); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
+
+func TestEscaping(t *testing.T) {
+	reg := NewRegistry()
+	reg.NewGauge("g", "Help with \\ and\nnewline \"quoted\".", "v").Set(math.Inf(1), "a\"b\\c\nd")
+	got := writeText(t, reg)
+	want := `# HELP g Help with \\ and\nnewline "quoted".
+# TYPE g gauge
+g{v="a\"b\\c\nd"} +Inf
+`
+	if got != want {
+		t.Errorf("WriteText =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestMisusePanics(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(reg *Registry)
+	}{
+		{"invalid name", func(reg *Registry) { reg.NewCounter("bad-name", "") }},
+		{"invalid label", func(reg *Registry) { reg.NewCounter("c", "", "bad label") }},
+		{"reserved label", func(reg *Registry) { reg.NewCounter("c", "", "__name") }},
+		{"le on histogram", func(reg *Registry) { reg.NewHistogram("h", "", nil, "le") }},
+		{"unsorted buckets", func(reg *Registry) { reg.NewHistogram("h", "", []float64{1, 0.5}) }},
+		{"duplicate", func(reg *Registry) { reg.NewCounter("c", ""); reg.NewGauge("c", "") }},
+		{"negative counter", func(reg *Registry) { reg.NewCounter("c", "").Add(-1) }},
+		{"too few label values", func(reg *Registry) { reg.NewCounter("c", "", "a", "b").Inc("x") }},
+		{"too many label values", func(reg *Registry) { reg.NewGauge("g", "").Set(1, "x") }},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("did not panic")
+				}
+			}()
+			tc.fn(NewRegistry())
+		})
+	}
+}
+
+func TestConcurrentUpdates(t *testing.T) {
+	reg := NewRegistry()
+	c := reg.NewCounter("c_total", "", "worker")
+	h := reg.NewHistogram("h", "", nil)
+
+	var wg sync.WaitGroup
+	for w := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 1000 {
+				c.Inc(strconv.Itoa(w % 2))
+				h.Observe(0.01)
+			}
+			reg.WriteText(io.Discard)
+		}()
+	}
+	wg.Wait()
+	if got := c.Value("0") + c.Value("1"); got != 8000 {
+		t.Errorf("counter total = %v; want 8000", got)
+	}
+	if got := h.Count(); got != 8000 {
+		t.Errorf("histogram count = %d; want 8000", got)
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	reg := NewRegistry()
+	reg.NewCounter("hits_total", "Hits.").Inc()
+	rr := httptest.NewRecorder()
+	reg.ServeHTTP(rr, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rr.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q; want the text exposition type", ct)
+	}
+	if samples := parseText(t, rr.Body); samples["hits_total"] != 1 {
+		t.Errorf("samples = %v; want hits_total 1", samples)
+	}
+}
+
+func ExampleRegistry() {
+	reg := NewRegistry()
+	jobs := reg.NewCounter("jobs_total", "Jobs processed.", "result")
+	jobs.Inc("ok")
+	jobs.Inc("ok")
+	jobs.Inc("failed")
+	reg.WriteText(os.Stdout)
+	// Output:
+	// # HELP jobs_total Jobs processed.
+	// # TYPE jobs_total counter
+	// jobs_total{result="failed"} 1
+	// jobs_total{result="ok"} 2
+}
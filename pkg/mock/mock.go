@@ -0,0 +1,190 @@
+// Package mock asserts on the calls recorded by the test doubles that
+// cmd/mockgen generates. A generated FooMock returns each method's calls
+// as structs with one field per argument, and these functions match the
+// fields, in order, against expected values or Matchers:
+//
+//	sender := &EmailSenderMock{}
+//	NotifyUser(user, sender)
+//	mock.AssertCalled(t, sender.SendCalls(), user.Email, "Account Created", mock.Contains("Hello John"))
+//
+// They work on any slice of structs, by reflection, so a hand-written
+// double recording its calls the same way can use them too.
+package mock
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Matcher decides whether an argument is the one expected. A plain value
+// given where a Matcher could be is matched as Eq(value).
+type Matcher interface {
+	Matches(arg any) bool
+	String() string
+}
+
+type anyMatcher struct{}
+
+func (anyMatcher) Matches(any) bool { return true }
+func (anyMatcher) String() string   { return "any" }
+
+// Any matches every argument
+func Any() Matcher { return anyMatcher{} }
+
+type eqMatcher struct{ want any }
+
+func (m eqMatcher) Matches(arg any) bool { return reflect.DeepEqual(arg, m.want) }
+func (m eqMatcher) String() string       { return fmt.Sprintf("%#v", m.want) }
+
+// Eq matches arguments deeply equal to want
+func Eq(want any) Matcher { return eqMatcher{want} }
+
+type containsMatcher struct{ substr string }
+
+func (m containsMatcher) Matches(arg any) bool {
+	s, ok := arg.(string)
+	return ok && strings.Contains(s, m.substr)
+}
+
+func (m containsMatcher) String() string { return fmt.Sprintf("contains %q", m.substr) }
+
+// Contains matches strings containing substr
+func Contains(substr string) Matcher { return containsMatcher{substr} }
+
+type funcMatcher[T any] struct {
+	desc  string
+	match func(T) bool
+}
+
+func (m funcMatcher[T]) Matches(arg any) bool {
+	v, ok := arg.(T)
+	return ok && m.match(v)
+}
+
+func (m funcMatcher[T]) String() string { return m.desc }
+
+// Func matches arguments of type T for which match returns true; desc
+// describes them in failures
+func Func[T any](desc string, match func(T) bool) Matcher {
+	return funcMatcher[T]{desc, match}
+}
+
+// Count returns how many of calls match args
+func Count[C any](calls []C, args ...any) int {
+	matchers := toMatchers(reflect.TypeFor[C](), args)
+	n := 0
+	for _, call := range calls {
+		if matches(call, matchers) {
+			n++
+		}
+	}
+	return n
+}
+
+// AssertCalled fails t unless at least one of calls matches args
+func AssertCalled[C any](t testing.TB, calls []C, args ...any) {
+	t.Helper()
+	if Count(calls, args...) == 0 {
+		t.Errorf("no call matched %s; got %s", describe(reflect.TypeFor[C](), args), formatCalls(calls))
+	}
+}
+
+// AssertCalledTimes fails t unless exactly n of calls match args
+func AssertCalledTimes[C any](t testing.TB, calls []C, n int, args ...any) {
+	t.Helper()
+	if got := Count(calls, args...); got != n {
+		t.Errorf("%d calls matched %s; want %d; got %s", got, describe(reflect.TypeFor[C](), args), n, formatCalls(calls))
+	}
+}
+
+// AssertNotCalled fails t if any of calls matches args. With no args it
+// fails if there are any calls at all.
+func AssertNotCalled[C any](t testing.TB, calls []C, args ...any) {
+	t.Helper()
+	if len(args) == 0 {
+		if len(calls) > 0 {
+			t.Errorf("got %s; want none", formatCalls(calls))
+		}
+		return
+	}
+	if got := Count(calls, args...); got > 0 {
+		t.Errorf("%d calls matched %s; want none; got %s", got, describe(reflect.TypeFor[C](), args), formatCalls(calls))
+	}
+}
+
+// toMatchers checks there is an arg for each field of the call struct
+// typ, panicking if not as the test is wrong, and turns the values among
+// them into Eq matchers of the field's type, so that nil and untyped
+// constants such as 1 match as they would in Go
+func toMatchers(typ reflect.Type, args []any) []Matcher {
+	if typ.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("mock: calls are %s; want a slice of structs", typ))
+	}
+	if len(args) != typ.NumField() {
+		panic(fmt.Sprintf("mock: %d args for %s, which has %d fields", len(args), typ, typ.NumField()))
+	}
+	matchers := make([]Matcher, len(args))
+	for i, arg := range args {
+		m, ok := arg.(Matcher)
+		if !ok {
+			m = Eq(convert(arg, typ.Field(i).Type))
+		}
+		matchers[i] = m
+	}
+	return matchers
+}
+
+// convert returns arg as a value of typ where Go would assign it: nil to
+// a pointer, slice, map or interface, and one number to another type
+func convert(arg any, typ reflect.Type) any {
+	if arg == nil {
+		switch typ.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface, reflect.Func, reflect.Chan:
+			return reflect.Zero(typ).Interface()
+		}
+		return nil
+	}
+	v := reflect.ValueOf(arg)
+	if v.Type() != typ && isNumber(v.Kind()) && isNumber(typ.Kind()) {
+		return v.Convert(typ).Interface()
+	}
+	return arg
+}
+
+func isNumber(k reflect.Kind) bool {
+	return reflect.Int <= k && k <= reflect.Float64
+}
+
+func matches(call any, matchers []Matcher) bool {
+	v := reflect.ValueOf(call)
+	for i, m := range matchers {
+		if !m.Matches(v.Field(i).Interface()) {
+			return false
+		}
+	}
+	return true
+}
+
+// describe formats args as a call, such as (Email: "a@b.c", Body: any)
+func describe(typ reflect.Type, args []any) string {
+	matchers := toMatchers(typ, args)
+	parts := make([]string, len(matchers))
+	for i, m := range matchers {
+		parts[i] = typ.Field(i).Name + ": " + m.String()
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+func formatCalls[C any](calls []C) string {
+	if len(calls) == 0 {
+		return "no calls"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d calls:", len(calls))
+	for _, call := range calls {
+		fmt.Fprintf(&b, "\n\t%+v", call)
+	}
+	return b.String()
+}
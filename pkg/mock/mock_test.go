@@ -0,0 +1,90 @@
+package mock
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/rehan/go-interview-prep/pkg/internal/tbtest"
+)
+
+// sendCall is shaped like the call structs cmd/mockgen generates
+type sendCall struct {
+	To    string
+	Tags  []string
+	Count int64
+	Err   error
+}
+
+var calls = []sendCall{
+	{To: "ann@example.com", Tags: []string{"welcome"}, Count: 1},
+	{To: "bob@example.com", Count: 2, Err: fmt.Errorf("bounced")},
+	{To: "ann@example.com", Tags: []string{"reminder"}, Count: 3},
+}
+
+func TestCount(t *testing.T) {
+	tests := []struct {
+		name string
+		args []any
+		want int
+	}{
+		{"values", []any{"ann@example.com", []string{"welcome"}, 1, nil}, 1},
+		{"any", []any{"ann@example.com", Any(), Any(), Any()}, 2},
+		{"nil slice", []any{Any(), nil, Any(), Any()}, 1},
+		{"contains", []any{Contains("@example"), Any(), Any(), Any()}, 3},
+		{"func", []any{Any(), Any(), Func("odd", func(n int64) bool { return n%2 == 1 }), Any()}, 2},
+		{"func of another type", []any{Any(), Any(), Func("odd", func(n int) bool { return n%2 == 1 }), Any()}, 0},
+		{"error", []any{Any(), Any(), Any(), Func("an error", func(error) bool { return true })}, 1},
+		{"no match", []any{"carol@example.com", Any(), Any(), Any()}, 0},
+	}
+	for _, tc := range tests {
+		if got := Count(calls, tc.args...); got != tc.want {
+			t.Errorf("%s: Count = %d; want %d", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestAssertions(t *testing.T) {
+	tests := []struct {
+		name      string
+		assert    func(testing.TB)
+		wantError string
+	}{
+		{"called", func(t testing.TB) { AssertCalled(t, calls, "bob@example.com", Any(), 2, Any()) }, ""},
+		{
+			"not called as expected",
+			func(t testing.TB) { AssertCalled(t, calls, "bob@example.com", Any(), 3, Any()) },
+			`no call matched (To: "bob@example.com", Tags: any, Count: 3, Err: any); got 3 calls:`,
+		},
+		{"times", func(t testing.TB) { AssertCalledTimes(t, calls, 2, "ann@example.com", Any(), Any(), nil) }, ""},
+		{
+			"wrong times",
+			func(t testing.TB) { AssertCalledTimes(t, calls, 1, Contains("ann"), Any(), Any(), Any()) },
+			`2 calls matched (To: contains "ann", Tags: any, Count: any, Err: any); want 1`,
+		},
+		{"not called", func(t testing.TB) { AssertNotCalled(t, calls, "carol@example.com", Any(), Any(), Any()) }, ""},
+		{"no calls", func(t testing.TB) { AssertNotCalled[sendCall](t, nil) }, ""},
+		{"some calls", func(t testing.TB) { AssertNotCalled(t, calls[:1]) }, "got 1 calls:\n\t{To:ann@example.com Tags:[welcome] Count:1 Err:<nil>}; want none"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &tbtest.Recorder{TB: t}
+			tc.assert(r)
+			switch {
+			case tc.wantError == "" && len(r.Errors) > 0:
+				t.Errorf("errors %q; want none", r.Errors)
+			case tc.wantError != "" && (len(r.Errors) != 1 || !strings.Contains(r.Errors[0], tc.wantError)):
+				t.Errorf("errors %q; want one containing %q", r.Errors, tc.wantError)
+			}
+		})
+	}
+}
+
+func TestCount_WrongArgs(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(fmt.Sprint(r), "2 args for mock.sendCall, which has 4 fields") {
+			t.Errorf("recovered %v; want a panic about the number of args", r)
+		}
+	}()
+	Count(calls, "ann@example.com", Any())
+}
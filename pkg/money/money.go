@@ -0,0 +1,210 @@
+// Package money represents amounts of money exactly, as a whole number of
+// cents, instead of as float64:
+//
+//	a, b := 0.1, 0.2
+//	a+b == 0.3 // false with float64
+//	money.MustParse("0.10")+money.MustParse("0.20") == money.MustParse("0.30") // true
+//
+// Amounts are written to JSON as plain numbers with two decimals (32.99)
+// and read from JSON numbers or strings without passing through float64,
+// so the wire format of an API does not change when it adopts Amount.
+// They are text ("32.99") to encoding.TextMarshaler users such as XML.
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Amount is a signed amount of money in cents (hundredths of the unit).
+// Adding and subtracting Amounts with + and - is exact; use Add and Mul
+// where overflow is possible.
+type Amount int64
+
+// ErrOverflow is returned when a result does not fit in an Amount
+var ErrOverflow = errors.New("money: amount out of range")
+
+// ErrSyntax is wrapped by errors for text that is not an amount
+var ErrSyntax = errors.New("money: invalid amount")
+
+// FromCents returns the Amount of n cents
+func FromCents(n int64) Amount {
+	return Amount(n)
+}
+
+// Parse reads a decimal amount such as "32.99", "-0.5" or "12". More than
+// two decimal places is an error rather than a silent rounding.
+func Parse(s string) (Amount, error) {
+	orig := s
+	neg := false
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+
+	whole, frac, hasDot := strings.Cut(s, ".")
+	if whole == "" && frac == "" || hasDot && frac == "" || len(frac) > 2 || !digits(whole) || !digits(frac) {
+		return 0, fmt.Errorf("%w: %q", ErrSyntax, orig)
+	}
+	frac += strings.Repeat("0", 2-len(frac))
+
+	units, err := strconv.ParseInt("0"+whole, 10, 64)
+	if err != nil || units > math.MaxInt64/100 {
+		return 0, fmt.Errorf("%w: %q", ErrOverflow, orig)
+	}
+	cents, _ := strconv.ParseInt(frac, 10, 64)
+	total := units*100 + cents
+	if total < 0 {
+		return 0, fmt.Errorf("%w: %q", ErrOverflow, orig)
+	}
+	if neg {
+		total = -total
+	}
+	return Amount(total), nil
+}
+
+func digits(s string) bool {
+	return strings.Trim(s, "0123456789") == ""
+}
+
+// MustParse is Parse for constants; it panics on invalid input
+func MustParse(s string) Amount {
+	a, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// Cents returns a as a number of cents
+func (a Amount) Cents() int64 {
+	return int64(a)
+}
+
+// Float64 returns a in whole units, rounded to the nearest float64. Use it
+// for display or comparisons with float limits, never for arithmetic.
+func (a Amount) Float64() float64 {
+	return float64(a) / 100
+}
+
+// String formats a with exactly two decimals, e.g. "-3.05"
+func (a Amount) String() string {
+	sign := ""
+	// Negate through uint64 so math.MinInt64 does not overflow
+	n := uint64(a)
+	if a < 0 {
+		sign = "-"
+		n = -n
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, n/100, n%100)
+}
+
+// Add returns a+b, or ErrOverflow
+func (a Amount) Add(b Amount) (Amount, error) {
+	sum := a + b
+	if (b > 0 && sum < a) || (b < 0 && sum > a) {
+		return 0, ErrOverflow
+	}
+	return sum, nil
+}
+
+// Mul returns a*n, for example a unit price times a quantity, or ErrOverflow
+func (a Amount) Mul(n int64) (Amount, error) {
+	if a == 0 || n == 0 {
+		return 0, nil
+	}
+	p := a * Amount(n)
+	if p/Amount(n) != a || (a == -1 && n == math.MinInt64) || (n == -1 && a == math.MinInt64) {
+		return 0, ErrOverflow
+	}
+	return p, nil
+}
+
+// MulRat returns a*r rounded to the nearest cent, with halves rounded to
+// even (banker's rounding) so repeated rounding does not drift upwards.
+// Use it for rates such as tax or discounts: big.NewRat(8, 100) is 8%.
+func (a Amount) MulRat(r *big.Rat) (Amount, error) {
+	exact := new(big.Rat).Mul(new(big.Rat).SetInt64(int64(a)), r)
+
+	// Split into quotient and remainder, then round on the remainder
+	num, den := exact.Num(), exact.Denom()
+	q, m := new(big.Int).QuoRem(num, den, new(big.Int))
+	twice := new(big.Int).Abs(m)
+	twice.Lsh(twice, 1)
+	switch cmp := twice.Cmp(den); {
+	case cmp > 0, cmp == 0 && q.Bit(0) == 1:
+		if num.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	if !q.IsInt64() {
+		return 0, ErrOverflow
+	}
+	return Amount(q.Int64()), nil
+}
+
+// Split divides a into n parts that differ by at most one cent and add up
+// to exactly a. The first parts get the extra cents: 10.00 split three ways
+// is 3.34, 3.33, 3.33.
+func (a Amount) Split(n int) []Amount {
+	if n <= 0 {
+		return nil
+	}
+	q, r := a/Amount(n), a%Amount(n)
+	parts := make([]Amount, n)
+	for i := range parts {
+		parts[i] = q
+		switch {
+		case r > 0 && Amount(i) < r:
+			parts[i]++
+		case r < 0 && Amount(i) < -r:
+			parts[i]--
+		}
+	}
+	return parts
+}
+
+// MarshalText writes a as String does. encoding/xml and encoding/csv
+// callers use it; JSON uses MarshalJSON, which takes precedence.
+func (a Amount) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalText reads an amount as Parse does
+func (a *Amount) UnmarshalText(text []byte) error {
+	v, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
+
+// MarshalJSON writes a as a JSON number with two decimals
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalJSON reads a JSON number, or a string holding one, from its
+// text, so 0.1 is exactly ten cents. null leaves a unchanged.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+	v, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
@@ -0,0 +1,214 @@
+package money
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Amount
+		wantErr error
+	}{
+		{"32.99", 3299, nil},
+		{"0.1", 10, nil},
+		{"12", 1200, nil},
+		{".5", 50, nil},
+		{"-0.05", -5, nil},
+		{"+1.00", 100, nil},
+		{"92233720368547758.07", math.MaxInt64, nil},
+		{"-92233720368547758.07", -math.MaxInt64, nil},
+		{"92233720368547758.08", 0, ErrOverflow},
+		{"99999999999999999999", 0, ErrOverflow},
+		{"1.999", 0, ErrSyntax},
+		{"1.", 0, ErrSyntax},
+		{"", 0, ErrSyntax},
+		{"-", 0, ErrSyntax},
+		{"-+1", 0, ErrSyntax},
+		{"1e3", 0, ErrSyntax},
+		{"1,000", 0, ErrSyntax},
+		{" 1", 0, ErrSyntax},
+	}
+	for _, tc := range tests {
+		got, err := Parse(tc.in)
+		if !errors.Is(err, tc.wantErr) || got != tc.want {
+			t.Errorf("Parse(%q) = %d, %v; want %d, %v", tc.in, got, err, tc.want, tc.wantErr)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		in   Amount
+		want string
+	}{
+		{0, "0.00"},
+		{5, "0.05"},
+		{-5, "-0.05"},
+		{3299, "32.99"},
+		{-120050, "-1200.50"},
+		{math.MinInt64, "-92233720368547758.08"},
+	}
+	for _, tc := range tests {
+		if got := tc.in.String(); got != tc.want {
+			t.Errorf("Amount(%d).String() = %q; want %q", int64(tc.in), got, tc.want)
+		}
+	}
+}
+
+func TestExactArithmetic(t *testing.T) {
+	a, b := 0.1, 0.2
+	if a+b == 0.3 {
+		t.Fatal("float64 0.1 + 0.2 == 0.3; the comparison below proves nothing")
+	}
+	if MustParse("0.10")+MustParse("0.20") != MustParse("0.30") {
+		t.Error("0.10 + 0.20 != 0.30 with Amount")
+	}
+}
+
+func TestAddMul_Overflow(t *testing.T) {
+	if _, err := Amount(math.MaxInt64).Add(1); !errors.Is(err, ErrOverflow) {
+		t.Errorf("MaxInt64 + 1: err = %v; want ErrOverflow", err)
+	}
+	if _, err := Amount(math.MinInt64).Add(-1); !errors.Is(err, ErrOverflow) {
+		t.Errorf("MinInt64 - 1: err = %v; want ErrOverflow", err)
+	}
+	if got, err := MustParse("19.99").Mul(3); err != nil || got != MustParse("59.97") {
+		t.Errorf("19.99 * 3 = %v, %v; want 59.97", got, err)
+	}
+	for _, tc := range []struct {
+		a Amount
+		n int64
+	}{
+		{math.MaxInt64 / 2, 3},
+		{math.MinInt64, -1},
+		{-1, math.MinInt64},
+	} {
+		if _, err := tc.a.Mul(tc.n); !errors.Is(err, ErrOverflow) {
+			t.Errorf("%d * %d: err = %v; want ErrOverflow", int64(tc.a), tc.n, err)
+		}
+	}
+}
+
+func TestMulRat_BankersRounding(t *testing.T) {
+	half := big.NewRat(1, 2)
+	tests := []struct {
+		in   Amount
+		rate *big.Rat
+		want Amount
+	}{
+		{1, half, 0},   // 0.5 cents rounds to even 0
+		{3, half, 2},   // 1.5 -> 2
+		{5, half, 2},   // 2.5 -> 2
+		{-3, half, -2}, // -1.5 -> -2
+		{-5, half, -2}, // -2.5 -> -2
+		{MustParse("19.99"), big.NewRat(8, 100), MustParse("1.60")}, // 159.92 cents
+		{MustParse("10.00"), big.NewRat(1, 3), MustParse("3.33")},   // 333.33 cents
+		{MustParse("-10.00"), big.NewRat(2, 3), MustParse("-6.67")}, // -666.67 cents
+	}
+	for _, tc := range tests {
+		got, err := tc.in.MulRat(tc.rate)
+		if err != nil || got != tc.want {
+			t.Errorf("%v * %v = %v, %v; want %v", tc.in, tc.rate, got, err, tc.want)
+		}
+	}
+	if _, err := Amount(math.MaxInt64).MulRat(big.NewRat(2, 1)); !errors.Is(err, ErrOverflow) {
+		t.Errorf("MaxInt64 * 2: err = %v; want ErrOverflow", err)
+	}
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		in   Amount
+		n    int
+		want []Amount
+	}{
+		{1000, 3, []Amount{334, 333, 333}},
+		{-1000, 3, []Amount{-334, -333, -333}},
+		{2, 3, []Amount{1, 1, 0}},
+		{900, 3, []Amount{300, 300, 300}},
+		{5, 0, nil},
+	}
+	for _, tc := range tests {
+		got := tc.in.Split(tc.n)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%v.Split(%d) = %v; want %v", tc.in, tc.n, got, tc.want)
+		}
+		var sum Amount
+		for _, p := range got {
+			sum += p
+		}
+		if tc.n > 0 && sum != tc.in {
+			t.Errorf("%v.Split(%d) parts add up to %v", tc.in, tc.n, sum)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	type item struct {
+		Price Amount `json:"price"`
+	}
+
+	data, err := json.Marshal(item{Price: 3299})
+	if err != nil || string(data) != `{"price":32.99}` {
+		t.Errorf("Marshal = %s, %v; want {\"price\":32.99}", data, err)
+	}
+
+	tests := []struct {
+		in      string
+		want    Amount
+		wantErr bool
+	}{
+		{`{"price":32.99}`, 3299, false},
+		{`{"price":"0.1"}`, 10, false},
+		{`{"price":7}`, 700, false},
+		{`{"price":null}`, 0, false},
+		{`{"price":1.005}`, 0, true},
+		{`{"price":1e2}`, 0, true},
+		{`{"price":true}`, 0, true},
+	}
+	for _, tc := range tests {
+		var got item
+		err := json.Unmarshal([]byte(tc.in), &got)
+		if (err != nil) != tc.wantErr || got.Price != tc.want {
+			t.Errorf("Unmarshal(%s) = %v, %v; want %v, error %v", tc.in, got.Price, err, tc.want, tc.wantErr)
+		}
+	}
+}
+
+func TestXML(t *testing.T) {
+	type item struct {
+		Price Amount `xml:"price"`
+	}
+
+	data, err := xml.Marshal(item{Price: -305})
+	if err != nil || string(data) != "<item><price>-3.05</price></item>" {
+		t.Errorf("Marshal = %s, %v; want <item><price>-3.05</price></item>", data, err)
+	}
+
+	var got item
+	if err := xml.Unmarshal([]byte("<item><price>12.5</price></item>"), &got); err != nil || got.Price != 1250 {
+		t.Errorf("Unmarshal = %v, %v; want 12.50", got.Price, err)
+	}
+	if err := xml.Unmarshal([]byte("<item><price>1.005</price></item>"), &got); !errors.Is(err, ErrSyntax) {
+		t.Errorf("Unmarshal(1.005) err = %v; want ErrSyntax", err)
+	}
+}
+
+func ExampleAmount_Split() {
+	bill := MustParse("100.00")
+	tip, _ := bill.MulRat(big.NewRat(15, 100))
+	total, _ := bill.Add(tip)
+	fmt.Println("total:", total)
+	fmt.Println("each:", total.Split(3))
+	// Output:
+	// total: 115.00
+	// each: [38.34 38.33 38.33]
+}
@@ -0,0 +1,569 @@
+package msgpack
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+)
+
+// maxDepth bounds how deeply arrays and maps may nest, so hostile input
+// cannot exhaust the stack
+const maxDepth = 1000
+
+// SyntaxError is input that is not MessagePack, is cut short, or uses a
+// part of the format this package does not support
+type SyntaxError struct {
+	Offset int // of the byte the problem was found at
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("msgpack: offset %d: %s", e.Offset, e.Msg)
+}
+
+// UnmarshalTypeError is a value that cannot be stored in the Go type it
+// is decoded into, such as a string for an int field or 300 for a uint8
+type UnmarshalTypeError struct {
+	Value  string // "string", "integer 300", ...
+	Type   reflect.Type
+	Offset int
+}
+
+func (e *UnmarshalTypeError) Error() string {
+	return fmt.Sprintf("msgpack: offset %d: cannot decode %s into Go value of type %s", e.Offset, e.Value, e.Type)
+}
+
+// Unmarshal decodes the single MessagePack value in data into the value v
+// points to. Map entries with no matching struct field are skipped, and
+// nil sets pointers, slices, maps and interfaces to nil. Into an any it
+// stores nil, bool, int64 (uint64 past math.MaxInt64), float64, string,
+// []byte, time.Time, []any or map[string]any.
+func Unmarshal(data []byte, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return errors.New("msgpack: Unmarshal needs a non-nil pointer")
+	}
+	d := &decoder{data: data}
+	if err := d.decode(rv.Elem()); err != nil {
+		return err
+	}
+	if d.off != len(d.data) {
+		return d.syntaxError("%d bytes after the value", len(d.data)-d.off)
+	}
+	return nil
+}
+
+type decoder struct {
+	data  []byte
+	off   int
+	depth int
+}
+
+func (d *decoder) syntaxError(format string, args ...any) error {
+	return &SyntaxError{Offset: d.off, Msg: fmt.Sprintf(format, args...)}
+}
+
+// next consumes the type byte of the next value
+func (d *decoder) next() (byte, error) {
+	if d.off >= len(d.data) {
+		return 0, d.syntaxError("unexpected end of data")
+	}
+	c := d.data[d.off]
+	d.off++
+	return c, nil
+}
+
+// read consumes n bytes
+func (d *decoder) read(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.off) {
+		return nil, d.syntaxError("unexpected end of data")
+	}
+	b := d.data[d.off : d.off+int(n)]
+	d.off += int(n)
+	return b, nil
+}
+
+// uint reads a big-endian unsigned integer of 1, 2, 4 or 8 bytes
+func (d *decoder) uint(size int) (uint64, error) {
+	b, err := d.read(uint64(size))
+	if err != nil {
+		return 0, err
+	}
+	switch size {
+	case 1:
+		return uint64(b[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(b)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(b)), nil
+	default:
+		return binary.BigEndian.Uint64(b), nil
+	}
+}
+
+func (d *decoder) decode(v reflect.Value) error {
+	start := d.off
+	if d.off < len(d.data) && d.data[d.off] == nilCode {
+		d.off++
+		switch v.Kind() {
+		case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+			v.SetZero()
+		}
+		return nil
+	}
+
+	switch {
+	case v.Kind() == reflect.Pointer:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return d.decode(v.Elem())
+	case v.Type() == timeType:
+		c, err := d.next()
+		if err != nil {
+			return err
+		}
+		t, err := d.time(c, start, v.Type())
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	case v.Kind() == reflect.Interface && v.NumMethod() == 0:
+		x, err := d.value()
+		if err != nil {
+			return err
+		}
+		if x == nil {
+			v.SetZero()
+		} else {
+			v.Set(reflect.ValueOf(x))
+		}
+		return nil
+	}
+
+	c, err := d.next()
+	if err != nil {
+		return err
+	}
+	mismatch := func(value string) error {
+		return &UnmarshalTypeError{Value: value, Type: v.Type(), Offset: start}
+	}
+	// fail reports a reader's errWrongType against the Go type
+	fail := func(err error) error {
+		if errors.Is(err, errWrongType) {
+			return mismatch(describe(c))
+		}
+		return err
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if c != falseTag && c != trueTag {
+			return mismatch(describe(c))
+		}
+		v.SetBool(c == trueTag)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, u, signed, err := d.integer(c)
+		if err != nil {
+			return fail(err)
+		}
+		if !signed && u > math.MaxInt64 || v.OverflowInt(i) {
+			return mismatch(fmt.Sprintf("integer %s", formatInt(i, u, signed)))
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		i, u, signed, err := d.integer(c)
+		if err != nil {
+			return fail(err)
+		}
+		if signed && i < 0 || v.OverflowUint(u) {
+			return mismatch(fmt.Sprintf("integer %s", formatInt(i, u, signed)))
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := d.float(c)
+		if err != nil {
+			return fail(err)
+		}
+		v.SetFloat(f)
+	case reflect.String:
+		s, err := d.str(c)
+		if err != nil {
+			return fail(err)
+		}
+		v.SetString(s)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			n, ok, err := d.binLength(c)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return mismatch(describe(c))
+			}
+			b, err := d.read(n)
+			if err != nil {
+				return err
+			}
+			v.SetBytes(append([]byte(nil), b...))
+			return nil
+		}
+		n, err := d.arrayLength(c)
+		if err != nil {
+			return fail(err)
+		}
+		s := reflect.MakeSlice(v.Type(), n, n)
+		if err := d.each(n, func(i int) error { return d.decode(s.Index(i)) }); err != nil {
+			return err
+		}
+		v.Set(s)
+	case reflect.Array:
+		n, err := d.arrayLength(c)
+		if err != nil {
+			return fail(err)
+		}
+		v.SetZero()
+		return d.each(n, func(i int) error {
+			if i >= v.Len() {
+				_, err := d.value()
+				return err
+			}
+			return d.decode(v.Index(i))
+		})
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return mismatch("map")
+		}
+		n, err := d.mapLength(c)
+		if err != nil {
+			return fail(err)
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMapWithSize(v.Type(), n))
+		}
+		return d.each(n, func(int) error {
+			key, err := d.key()
+			if err != nil {
+				return err
+			}
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := d.decode(elem); err != nil {
+				return err
+			}
+			v.SetMapIndex(reflect.ValueOf(key).Convert(v.Type().Key()), elem)
+			return nil
+		})
+	case reflect.Struct:
+		n, err := d.mapLength(c)
+		if err != nil {
+			return fail(err)
+		}
+		fields := cachedFields(v.Type())
+		return d.each(n, func(int) error {
+			key, err := d.key()
+			if err != nil {
+				return err
+			}
+			if f, ok := fieldByName(fields, key); ok {
+				return d.decode(v.Field(f.index))
+			}
+			_, err = d.value()
+			return err
+		})
+	default:
+		return mismatch(describe(c))
+	}
+	return nil
+}
+
+// errWrongType is returned by the readers below when the type byte is of
+// another kind of value; decode turns it into an UnmarshalTypeError, and
+// value tries the next reader
+var errWrongType = errors.New("wrong type")
+
+// each runs fn for the n items of an array or map one level down
+func (d *decoder) each(n int, fn func(i int) error) error {
+	if d.depth++; d.depth > maxDepth {
+		return d.syntaxError("nested more than %d deep", maxDepth)
+	}
+	defer func() { d.depth-- }()
+	for i := 0; i < n; i++ {
+		if err := fn(i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// integer reads an integer after its type byte c. Signed formats give i,
+// unsigned ones u; signed reports which, and for non-negative values both
+// are set.
+func (d *decoder) integer(c byte) (i int64, u uint64, signed bool, err error) {
+	switch {
+	case c <= 0x7f:
+		return int64(c), uint64(c), false, nil
+	case c >= negFix:
+		return int64(int8(c)), 0, true, nil
+	case c >= uint8T && c <= uint64T:
+		u, err = d.uint(1 << (c - uint8T))
+		return int64(u), u, false, err
+	case c >= int8T && c <= int64T:
+		size := 1 << (c - int8T)
+		if u, err = d.uint(size); err != nil {
+			return 0, 0, true, err
+		}
+		switch size {
+		case 1:
+			i = int64(int8(u))
+		case 2:
+			i = int64(int16(u))
+		case 4:
+			i = int64(int32(u))
+		default:
+			i = int64(u)
+		}
+		return i, uint64(i), true, nil
+	}
+	return 0, 0, false, d.typeError(c)
+}
+
+func formatInt(i int64, u uint64, signed bool) string {
+	if signed {
+		return fmt.Sprint(i)
+	}
+	return fmt.Sprint(u)
+}
+
+// float reads a float, or an integer as one
+func (d *decoder) float(c byte) (float64, error) {
+	switch c {
+	case float32T:
+		u, err := d.uint(4)
+		return float64(math.Float32frombits(uint32(u))), err
+	case float64T:
+		u, err := d.uint(8)
+		return math.Float64frombits(u), err
+	}
+	i, u, signed, err := d.integer(c)
+	if errors.Is(err, errWrongType) {
+		return 0, d.typeError(c)
+	}
+	if signed {
+		return float64(i), err
+	}
+	return float64(u), err
+}
+
+func (d *decoder) str(c byte) (string, error) {
+	var n uint64
+	var err error
+	switch {
+	case c >= fixStr && c <= fixStr|0x1f:
+		n = uint64(c & 0x1f)
+	case c >= str8 && c <= str32:
+		n, err = d.uint(1 << (c - str8))
+	default:
+		return "", d.typeError(c)
+	}
+	if err != nil {
+		return "", err
+	}
+	b, err := d.read(n)
+	return string(b), err
+}
+
+// key reads a map key, which must be a string
+func (d *decoder) key() (string, error) {
+	c, err := d.next()
+	if err != nil {
+		return "", err
+	}
+	s, err := d.str(c)
+	if errors.Is(err, errWrongType) {
+		return "", d.syntaxError("map key is %s; only string keys are supported", describe(c))
+	}
+	return s, err
+}
+
+// binLength reads the length of a byte string; ok is false for other types
+func (d *decoder) binLength(c byte) (n uint64, ok bool, err error) {
+	if c < bin8 || c > bin32 {
+		return 0, false, nil
+	}
+	n, err = d.uint(1 << (c - bin8))
+	return n, true, err
+}
+
+func (d *decoder) arrayLength(c byte) (int, error) {
+	var n uint64
+	var err error
+	switch {
+	case c >= fixArray && c <= fixArray|0x0f:
+		n = uint64(c & 0x0f)
+	case c == array16 || c == array32:
+		n, err = d.uint(2 << (c - array16))
+	default:
+		return 0, d.typeError(c)
+	}
+	return d.checkLength(n, 1, err)
+}
+
+func (d *decoder) mapLength(c byte) (int, error) {
+	var n uint64
+	var err error
+	switch {
+	case c >= fixMap && c <= fixMap|0x0f:
+		n = uint64(c & 0x0f)
+	case c == map16 || c == map32:
+		n, err = d.uint(2 << (c - map16))
+	default:
+		return 0, d.typeError(c)
+	}
+	return d.checkLength(n, 2, err)
+}
+
+// checkLength rejects a count of n items of at least size bytes each
+// that the rest of the input cannot hold, before anything is allocated
+// for them
+func (d *decoder) checkLength(n uint64, size int, err error) (int, error) {
+	if err != nil {
+		return 0, err
+	}
+	if n > uint64((len(d.data)-d.off)/size) {
+		return 0, d.syntaxError("length %d is longer than the data", n)
+	}
+	return int(n), nil
+}
+
+// typeError reports that the type byte just read, c, is not the kind of
+// value wanted
+func (d *decoder) typeError(c byte) error {
+	return fmt.Errorf("%w: %s", errWrongType, describe(c))
+}
+
+// time reads a timestamp extension after its type byte c
+func (d *decoder) time(c byte, start int, t reflect.Type) (time.Time, error) {
+	var size uint64
+	var err error
+	switch c {
+	case fixExt4:
+		size = 4
+	case fixExt8:
+		size = 8
+	case ext8:
+		size, err = d.uint(1)
+	default:
+		return time.Time{}, &UnmarshalTypeError{Value: describe(c), Type: t, Offset: start}
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	typ, err := d.uint(1)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if typ != timestampExt {
+		return time.Time{}, &UnmarshalTypeError{Value: fmt.Sprintf("extension type %d", int8(typ)), Type: t, Offset: start}
+	}
+
+	var sec int64
+	var nsec uint64
+	switch size {
+	case 4:
+		u, err := d.uint(4)
+		if err != nil {
+			return time.Time{}, err
+		}
+		sec = int64(u)
+	case 8:
+		u, err := d.uint(8)
+		if err != nil {
+			return time.Time{}, err
+		}
+		nsec, sec = u>>34, int64(u&(1<<34-1))
+	case 12:
+		if nsec, err = d.uint(4); err != nil {
+			return time.Time{}, err
+		}
+		u, err := d.uint(8)
+		if err != nil {
+			return time.Time{}, err
+		}
+		sec = int64(u)
+	default:
+		return time.Time{}, d.syntaxError("timestamp of %d bytes", size)
+	}
+	if nsec >= 1e9 {
+		return time.Time{}, d.syntaxError("timestamp nanoseconds %d out of range", nsec)
+	}
+	return time.Unix(sec, int64(nsec)).UTC(), nil
+}
+
+// value reads the next value into the types Unmarshal documents for any.
+// It also skips the values of unknown struct fields.
+func (d *decoder) value() (any, error) {
+	start := d.off
+	c, err := d.next()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case c == nilCode:
+		return nil, nil
+	case c == falseTag || c == trueTag:
+		return c == trueTag, nil
+	case c == float32T || c == float64T:
+		return d.float(c)
+	case c >= fixStr && c <= fixStr|0x1f || c >= str8 && c <= str32:
+		return d.str(c)
+	case c >= ext8 && c <= ext32 || c >= fixExt1 && c <= fixExt16:
+		t, err := d.time(c, start, timeType)
+		var typeErr *UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return nil, &SyntaxError{Offset: start, Msg: "unsupported " + typeErr.Value}
+		}
+		return t, err
+	}
+	if n, ok, err := d.binLength(c); ok {
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.read(n)
+		return append([]byte(nil), b...), err
+	}
+	if i, u, signed, err := d.integer(c); !errors.Is(err, errWrongType) {
+		if !signed && u > math.MaxInt64 {
+			return u, err
+		}
+		return i, err
+	}
+	if n, err := d.arrayLength(c); !errors.Is(err, errWrongType) {
+		if err != nil {
+			return nil, err
+		}
+		a := make([]any, n)
+		return a, d.each(n, func(i int) error {
+			return d.decode(reflect.ValueOf(&a[i]).Elem())
+		})
+	}
+	if n, err := d.mapLength(c); !errors.Is(err, errWrongType) {
+		if err != nil {
+			return nil, err
+		}
+		m := make(map[string]any, n)
+		return m, d.each(n, func(int) error {
+			key, err := d.key()
+			if err != nil {
+				return err
+			}
+			x, err := d.value()
+			m[key] = x
+			return err
+		})
+	}
+	return nil, &SyntaxError{Offset: start, Msg: fmt.Sprintf("invalid type byte 0x%02x", c)}
+}
@@ -0,0 +1,393 @@
+// Package msgpack encodes Go values in MessagePack, a binary format with
+// JSON's data model: nil, booleans, integers, floats, strings, byte
+// strings, arrays and maps, each behind a type byte that also holds small
+// values and lengths, so {"id": 1} takes 5 bytes rather than 8.
+//
+//	0x81             map of 1 entry
+//	0xa2 'i' 'd'     the 2-byte string "id"
+//	0x01             the positive fixint 1
+//
+// Values are walked by reflection as encoding/json walks them. A struct is
+// a map keyed by field name, taken from the msgpack tag or else the json
+// tag, so types tagged for JSON need nothing more; "-" and omitempty work
+// as they do there. Integers take the smallest encoding that holds them,
+// time.Time is the timestamp extension (type -1), and []byte is a byte
+// string rather than base64 text.
+//
+// The subset is what the repo's types need: maps must have string keys,
+// other extension types are rejected, and MarshalJSON-style hooks are not
+// consulted, so a named integer such as money.Amount goes as its number.
+package msgpack
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Type bytes from the MessagePack specification. Positive fixints are
+// 0x00-0x7f, negative fixints 0xe0-0xff; the fix* forms keep a length in
+// their low bits.
+const (
+	fixMap   = 0x80 // to 0x8f
+	fixArray = 0x90 // to 0x9f
+	fixStr   = 0xa0 // to 0xbf
+	nilCode  = 0xc0
+	falseTag = 0xc2
+	trueTag  = 0xc3
+	bin8     = 0xc4
+	bin16    = 0xc5
+	bin32    = 0xc6
+	ext8     = 0xc7
+	ext16    = 0xc8
+	ext32    = 0xc9
+	float32T = 0xca
+	float64T = 0xcb
+	uint8T   = 0xcc
+	uint16T  = 0xcd
+	uint32T  = 0xce
+	uint64T  = 0xcf
+	int8T    = 0xd0
+	int16T   = 0xd1
+	int32T   = 0xd2
+	int64T   = 0xd3
+	fixExt1  = 0xd4
+	fixExt4  = 0xd6
+	fixExt8  = 0xd7
+	fixExt16 = 0xd8
+	str8     = 0xd9
+	str16    = 0xda
+	str32    = 0xdb
+	array16  = 0xdc
+	array32  = 0xdd
+	map16    = 0xde
+	map32    = 0xdf
+	negFix   = 0xe0 // to 0xff
+)
+
+// timestampExt is the extension type the specification reserves for
+// points in time, -1 as a byte
+const timestampExt = 0xff
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// UnsupportedTypeError is a value Marshal cannot encode, such as a channel
+// or a map with non-string keys
+type UnsupportedTypeError struct {
+	Type reflect.Type
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	return "msgpack: unsupported type " + e.Type.String()
+}
+
+// Marshal returns the MessagePack encoding of v
+func Marshal(v any) ([]byte, error) {
+	e := &encoder{}
+	if err := e.encode(reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	return e.buf, nil
+}
+
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) encode(v reflect.Value) error {
+	if !v.IsValid() {
+		e.buf = append(e.buf, nilCode)
+		return nil
+	}
+	if v.Type() == timeType {
+		e.encodeTime(v.Interface().(time.Time))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			e.buf = append(e.buf, trueTag)
+		} else {
+			e.buf = append(e.buf, falseTag)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.encodeInt(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		e.encodeUint(v.Uint())
+	case reflect.Float32:
+		e.buf = append(e.buf, float32T)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, math.Float32bits(float32(v.Float())))
+	case reflect.Float64:
+		e.buf = append(e.buf, float64T)
+		e.buf = binary.BigEndian.AppendUint64(e.buf, math.Float64bits(v.Float()))
+	case reflect.String:
+		e.encodeString(v.String())
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			e.buf = append(e.buf, nilCode)
+			return nil
+		}
+		return e.encode(v.Elem())
+	case reflect.Slice:
+		if v.IsNil() {
+			e.buf = append(e.buf, nilCode)
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			e.encodeBytes(v.Bytes())
+			return nil
+		}
+		return e.encodeArray(v)
+	case reflect.Array:
+		return e.encodeArray(v)
+	case reflect.Map:
+		return e.encodeMap(v)
+	case reflect.Struct:
+		return e.encodeStruct(v)
+	default:
+		return &UnsupportedTypeError{v.Type()}
+	}
+	return nil
+}
+
+// encodeInt writes n in the fewest bytes, as an unsigned format when it
+// is not negative
+func (e *encoder) encodeInt(n int64) {
+	switch {
+	case n >= 0:
+		e.encodeUint(uint64(n))
+	case n >= -32:
+		e.buf = append(e.buf, byte(n))
+	case n >= math.MinInt8:
+		e.buf = append(e.buf, int8T, byte(n))
+	case n >= math.MinInt16:
+		e.buf = binary.BigEndian.AppendUint16(append(e.buf, int16T), uint16(n))
+	case n >= math.MinInt32:
+		e.buf = binary.BigEndian.AppendUint32(append(e.buf, int32T), uint32(n))
+	default:
+		e.buf = binary.BigEndian.AppendUint64(append(e.buf, int64T), uint64(n))
+	}
+}
+
+func (e *encoder) encodeUint(n uint64) {
+	switch {
+	case n <= 0x7f:
+		e.buf = append(e.buf, byte(n))
+	case n <= math.MaxUint8:
+		e.buf = append(e.buf, uint8T, byte(n))
+	case n <= math.MaxUint16:
+		e.buf = binary.BigEndian.AppendUint16(append(e.buf, uint16T), uint16(n))
+	case n <= math.MaxUint32:
+		e.buf = binary.BigEndian.AppendUint32(append(e.buf, uint32T), uint32(n))
+	default:
+		e.buf = binary.BigEndian.AppendUint64(append(e.buf, uint64T), n)
+	}
+}
+
+func (e *encoder) encodeString(s string) {
+	e.encodeLength(len(s), fixStr, 31, str8, str16, str32)
+	e.buf = append(e.buf, s...)
+}
+
+func (e *encoder) encodeBytes(b []byte) {
+	e.encodeLength(len(b), 0, -1, bin8, bin16, bin32)
+	e.buf = append(e.buf, b...)
+}
+
+// encodeLength writes the header of a string, byte string, array or map
+// of n items: the fix form when n fits in its low bits (fixMax of -1 for
+// types with no fix form), else the 8-, 16- or 32-bit length form. A 0
+// code8 means there is no 8-bit form.
+func (e *encoder) encodeLength(n int, fix byte, fixMax int, code8, code16, code32 byte) {
+	switch {
+	case n <= fixMax:
+		e.buf = append(e.buf, fix|byte(n))
+	case n <= math.MaxUint8 && code8 != 0:
+		e.buf = append(e.buf, code8, byte(n))
+	case n <= math.MaxUint16:
+		e.buf = binary.BigEndian.AppendUint16(append(e.buf, code16), uint16(n))
+	default:
+		e.buf = binary.BigEndian.AppendUint32(append(e.buf, code32), uint32(n))
+	}
+}
+
+func (e *encoder) encodeArray(v reflect.Value) error {
+	e.encodeLength(v.Len(), fixArray, 15, 0, array16, array32)
+	for i := 0; i < v.Len(); i++ {
+		if err := e.encode(v.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeMap writes a map with its keys sorted, so equal maps encode to
+// equal bytes
+func (e *encoder) encodeMap(v reflect.Value) error {
+	if v.Type().Key().Kind() != reflect.String {
+		return &UnsupportedTypeError{v.Type()}
+	}
+	if v.IsNil() {
+		e.buf = append(e.buf, nilCode)
+		return nil
+	}
+	keys := v.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	e.encodeLength(len(keys), fixMap, 15, 0, map16, map32)
+	for _, k := range keys {
+		e.encodeString(k.String())
+		if err := e.encode(v.MapIndex(k)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *encoder) encodeStruct(v reflect.Value) error {
+	fields := cachedFields(v.Type())
+	n := 0
+	for _, f := range fields {
+		if !f.omitEmpty || !isEmpty(v.Field(f.index)) {
+			n++
+		}
+	}
+	e.encodeLength(n, fixMap, 15, 0, map16, map32)
+	for _, f := range fields {
+		fv := v.Field(f.index)
+		if f.omitEmpty && isEmpty(fv) {
+			continue
+		}
+		e.encodeString(f.name)
+		if err := e.encode(fv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isEmpty reports whether omitempty leaves v out: by encoding/json's rule,
+// false, 0, nil and empty strings, slices and maps, but never a struct
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Struct:
+		return false
+	default:
+		return v.IsZero()
+	}
+}
+
+// encodeTime writes t as a timestamp in the smallest of the three forms
+// the specification defines: 32-bit seconds, 30-bit nanoseconds with
+// 34-bit seconds, or 32-bit nanoseconds with 64-bit seconds
+func (e *encoder) encodeTime(t time.Time) {
+	sec, nsec := t.Unix(), uint64(t.Nanosecond())
+	switch {
+	case nsec == 0 && sec >= 0 && sec <= math.MaxUint32:
+		e.buf = append(e.buf, fixExt4, timestampExt)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(sec))
+	case sec >= 0 && sec>>34 == 0:
+		e.buf = append(e.buf, fixExt8, timestampExt)
+		e.buf = binary.BigEndian.AppendUint64(e.buf, nsec<<34|uint64(sec))
+	default:
+		e.buf = append(e.buf, ext8, 12, timestampExt)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(nsec))
+		e.buf = binary.BigEndian.AppendUint64(e.buf, uint64(sec))
+	}
+}
+
+// field is an encoded struct field
+type field struct {
+	name      string
+	index     int
+	omitEmpty bool
+}
+
+var fieldCache sync.Map // reflect.Type -> []field
+
+// cachedFields returns the exported fields of t that are encoded, in
+// declaration order
+func cachedFields(t reflect.Type) []field {
+	if f, ok := fieldCache.Load(t); ok {
+		return f.([]field)
+	}
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		tag, ok := sf.Tag.Lookup("msgpack")
+		if !ok {
+			tag = sf.Tag.Get("json")
+		}
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = sf.Name
+		}
+		fields = append(fields, field{name: name, index: i, omitEmpty: hasOption(opts, "omitempty")})
+	}
+	f, _ := fieldCache.LoadOrStore(t, fields)
+	return f.([]field)
+}
+
+func hasOption(opts, want string) bool {
+	for _, o := range strings.Split(opts, ",") {
+		if o == want {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldByName finds the struct field a map key names, matching exactly
+// first and then ignoring case, as encoding/json does
+func fieldByName(fields []field, name string) (field, bool) {
+	for _, f := range fields {
+		if f.name == name {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.name, name) {
+			return f, true
+		}
+	}
+	return field{}, false
+}
+
+// describe names a type byte in errors
+func describe(c byte) string {
+	switch {
+	case c <= 0x7f || c >= negFix || c >= uint8T && c <= int64T:
+		return "integer"
+	case c >= fixMap && c <= 0x8f || c == map16 || c == map32:
+		return "map"
+	case c >= fixArray && c <= 0x9f || c == array16 || c == array32:
+		return "array"
+	case c >= fixStr && c <= 0xbf || c >= str8 && c <= str32:
+		return "string"
+	case c == nilCode:
+		return "nil"
+	case c == falseTag || c == trueTag:
+		return "bool"
+	case c >= bin8 && c <= bin32:
+		return "bytes"
+	case c == float32T || c == float64T:
+		return "float"
+	case c >= ext8 && c <= ext32 || c >= fixExt1 && c <= fixExt16:
+		return "extension"
+	default:
+		return fmt.Sprintf("0x%02x", c)
+	}
+}
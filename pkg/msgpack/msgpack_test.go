@@ -0,0 +1,293 @@
+package msgpack
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/money"
+)
+
+// book mirrors the REST API's Book: tagged for JSON only
+type book struct {
+	ID        int          `json:"id"`
+	Title     string       `json:"title"`
+	Author    string       `json:"author"`
+	Price     money.Amount `json:"price"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+var sampleBooks = []book{
+	{1, "The Go Programming Language", "Alan A. A. Donovan and Brian W. Kernighan", money.MustParse("32.99"), time.Date(2015, 10, 26, 0, 0, 0, 0, time.UTC)},
+	{2, "Concurrency in Go", "Katherine Cox-Buday", money.MustParse("34.99"), time.Date(2017, 7, 19, 12, 30, 0, 500, time.UTC)},
+	{3, "Go in Action", "William Kennedy", money.MustParse("24.99"), time.Date(2015, 11, 4, 0, 0, 0, 0, time.UTC)},
+}
+
+func TestMarshal_Encodings(t *testing.T) {
+	tests := []struct {
+		in   any
+		want string // hex
+	}{
+		{nil, "c0"},
+		{true, "c3"},
+		{false, "c2"},
+		{0, "00"},
+		{127, "7f"},
+		{128, "cc80"},
+		{256, "cd0100"},
+		{70000, "ce00011170"},
+		{int64(1) << 40, "cf0000010000000000"},
+		{-1, "ff"},
+		{-32, "e0"},
+		{-33, "d0df"},
+		{-200, "d1ff38"},
+		{-40000, "d2ffff63c0"},
+		{int64(math.MinInt64), "d38000000000000000"},
+		{uint8(200), "ccc8"},
+		{1.5, "cb3ff8000000000000"},
+		{float32(1.5), "ca3fc00000"},
+		{"", "a0"},
+		{"id", "a26964"},
+		{strings.Repeat("x", 32), "d920" + strings.Repeat("78", 32)},
+		{[]byte{1, 2}, "c4020102"},
+		{[]int{1, 2, 3}, "93010203"},
+		{[]int(nil), "c0"},
+		{[2]bool{true, false}, "92c3c2"},
+		{map[string]int{"b": 2, "a": 1}, "82a16101a16202"}, // keys sorted
+		{struct {
+			ID   int    `json:"id"`
+			Skip string `json:"-"`
+			Note string `msgpack:"n,omitempty" json:"note"`
+		}{ID: 1}, "81a26964" + "01"},
+		{money.MustParse("32.99"), "cd0ce3"}, // 3299 cents
+		{time.Unix(1, 0), "d6ff00000001"},
+		{time.Unix(1, 1), "d7ff0000000400000001"},
+		{time.Unix(-1, 0), "c70cff00000000ffffffffffffffff"},
+	}
+	for _, tc := range tests {
+		got, err := Marshal(tc.in)
+		if err != nil {
+			t.Errorf("Marshal(%v): %v", tc.in, err)
+			continue
+		}
+		if h := hex.EncodeToString(got); h != tc.want {
+			t.Errorf("Marshal(%v) = %s; want %s", tc.in, h, tc.want)
+		}
+	}
+}
+
+func TestMarshal_Unsupported(t *testing.T) {
+	for _, v := range []any{make(chan int), map[int]string{1: "a"}, []func(){nil}} {
+		var typeErr *UnsupportedTypeError
+		if _, err := Marshal(v); !errors.As(err, &typeErr) {
+			t.Errorf("Marshal(%T) error = %v; want an UnsupportedTypeError", v, err)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	data, err := Marshal(sampleBooks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []book
+	if err := Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, sampleBooks) {
+		t.Errorf("got %+v; want %+v", got, sampleBooks)
+	}
+
+	type nested struct {
+		Tags    []string          `json:"tags"`
+		Attrs   map[string]string `json:"attrs"`
+		Next    *nested           `json:"next"`
+		Blob    []byte            `json:"blob"`
+		Ratio   float32           `json:"ratio"`
+		Counter uint16            `json:"counter"`
+	}
+	in := nested{
+		Tags:  []string{"go", strings.Repeat("long", 100)},
+		Attrs: map[string]string{"lang": "en"},
+		Next:  &nested{Counter: 65535, Blob: bytes.Repeat([]byte{7}, 300)},
+		Ratio: 0.25,
+	}
+	data, err = Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out nested
+	if err := Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("got %+v; want %+v", out, in)
+	}
+}
+
+func TestUnmarshal_Any(t *testing.T) {
+	data, err := Marshal(map[string]any{
+		"n": -5, "big": uint64(math.MaxUint64), "f": 2.5, "s": "x", "b": []byte("y"),
+		"list": []any{true, nil}, "at": time.Unix(1700000000, 0),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got any
+	if err := Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"n": int64(-5), "big": uint64(math.MaxUint64), "f": 2.5, "s": "x", "b": []byte("y"),
+		"list": []any{true, nil}, "at": time.Unix(1700000000, 0).UTC(),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v; want %#v", got, want)
+	}
+}
+
+func TestUnmarshal_Fields(t *testing.T) {
+	// Unknown keys are skipped, names match case-insensitively as a
+	// fallback, and nil clears a pointer
+	data, _ := Marshal(map[string]any{
+		"TITLE": "Go", "extra": map[string]any{"deep": []any{1, "two"}}, "price": 100, "next": nil,
+	})
+	type target struct {
+		Title string       `json:"title"`
+		Price money.Amount `json:"price"`
+		Next  *int         `json:"next"`
+	}
+	one := 1
+	got := target{Next: &one}
+	if err := Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Title != "Go" || got.Price.String() != "1.00" || got.Next != nil {
+		t.Errorf("got %+v; want title Go, price 1.00 and no next", got)
+	}
+}
+
+func TestUnmarshal_Errors(t *testing.T) {
+	var b book
+	var n8 int8
+	var u uint
+	var s string
+	var arr []int
+	tests := []struct {
+		name string
+		data string // hex
+		into any
+		want any // *SyntaxError or *UnmarshalTypeError
+	}{
+		{"empty", "", &b, &SyntaxError{}},
+		{"truncated string", "a3616263"[:6], &s, &SyntaxError{}},
+		{"truncated map", "82a16101", &b, &SyntaxError{}},
+		{"trailing bytes", "0101", &n8, &SyntaxError{}},
+		{"reserved byte", "c1", new(any), &SyntaxError{}},
+		{"huge array length", "dd7fffffff", &arr, &SyntaxError{}},
+		{"non-string key", "8101a0", &b, &SyntaxError{}},
+		{"unknown extension", "d40501", new(any), &SyntaxError{}},
+		{"bad nanoseconds", "d7ff" + "ffffffff00000000", &b.CreatedAt, &SyntaxError{}},
+		{"string for int", "81a26964a131", &b, &UnmarshalTypeError{}},
+		{"int overflow", "cd012c", &n8, &UnmarshalTypeError{}},
+		{"negative for uint", "ff", &u, &UnmarshalTypeError{}},
+		{"uint64 for int", "cfffffffffffffffff", new(int64), &UnmarshalTypeError{}},
+		{"array for struct", "90", &b, &UnmarshalTypeError{}},
+		{"int for time", "01", &b.CreatedAt, &UnmarshalTypeError{}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			data, _ := hex.DecodeString(tc.data)
+			err := Unmarshal(data, tc.into)
+			switch tc.want.(type) {
+			case *SyntaxError:
+				var se *SyntaxError
+				if !errors.As(err, &se) {
+					t.Errorf("error = %v; want a SyntaxError", err)
+				}
+			case *UnmarshalTypeError:
+				var te *UnmarshalTypeError
+				if !errors.As(err, &te) {
+					t.Errorf("error = %v; want an UnmarshalTypeError", err)
+				}
+			}
+		})
+	}
+
+	deep := append(bytes.Repeat([]byte{0x91}, maxDepth+1), 0xc0)
+	var x any
+	if err := Unmarshal(deep, &x); err == nil || !strings.Contains(err.Error(), "nested") {
+		t.Errorf("Unmarshal of %d nested arrays: error = %v; want a depth error", maxDepth+1, err)
+	}
+	if err := Unmarshal([]byte{0xc0}, b); err == nil {
+		t.Error("Unmarshal into a non-pointer succeeded")
+	}
+}
+
+// TestSmallerThanJSON pins the size advantage the benchmarks report
+func TestSmallerThanJSON(t *testing.T) {
+	mp, _ := Marshal(sampleBooks)
+	js, _ := json.Marshal(sampleBooks)
+	if len(mp) >= len(js)*3/4 {
+		t.Errorf("MessagePack is %d bytes, JSON %d; want at least a quarter smaller", len(mp), len(js))
+	}
+}
+
+// Benchmarks compare speed and, as bytes/msg, size with encoding/json:
+//
+//	go test -bench=. -benchmem ./pkg/msgpack/
+
+func BenchmarkMarshal(b *testing.B) {
+	codecs := []struct {
+		name    string
+		marshal func(any) ([]byte, error)
+	}{
+		{"msgpack", Marshal},
+		{"json", json.Marshal},
+	}
+	for _, c := range codecs {
+		b.Run(c.name, func(b *testing.B) {
+			b.ReportAllocs()
+			var size int
+			for i := 0; i < b.N; i++ {
+				data, err := c.marshal(sampleBooks)
+				if err != nil {
+					b.Fatal(err)
+				}
+				size = len(data)
+			}
+			b.ReportMetric(float64(size), "bytes/msg")
+		})
+	}
+}
+
+func BenchmarkUnmarshal(b *testing.B) {
+	mp, _ := Marshal(sampleBooks)
+	js, _ := json.Marshal(sampleBooks)
+	codecs := []struct {
+		name      string
+		data      []byte
+		unmarshal func([]byte, any) error
+	}{
+		{"msgpack", mp, Unmarshal},
+		{"json", js, json.Unmarshal},
+	}
+	for _, c := range codecs {
+		b.Run(c.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(c.data)))
+			for i := 0; i < b.N; i++ {
+				var books []book
+				if err := c.unmarshal(c.data, &books); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+// Package ndjson reads and writes newline-delimited JSON: one JSON value
+// per line, so a stream of any length is handled a value at a time and a
+// bad line does not lose the ones after it.
+//
+//	{"title":"Go in Action","price":24.99}
+//	{"title":"Learning Go","price":29.99}
+//
+// Decoding skips blank lines and reports a line that is not valid JSON,
+// or does not fit the value it is decoded into, as a *LineError carrying
+// its line number; reading goes on at the next line. Both directions take
+// a context and stop between lines once it is done, so a canceled request
+// ends a long import or export without reading or writing the rest.
+package ndjson
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+)
+
+// MaxLineBytes bounds one line. A longer one ends decoding with
+// bufio.ErrTooLong, as there is no telling where the next line starts
+// without reading all of it.
+const MaxLineBytes = 1 << 20
+
+// LineError is a line that could not be decoded, or a value that could not
+// be encoded, with its 1-based line number. Blank lines count, so the
+// number matches what an editor shows.
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("ndjson: line %d: %v", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error { return e.Err }
+
+// Decoder reads JSON values from the lines of a stream
+type Decoder struct {
+	sc          *bufio.Scanner
+	scanned     int    // lines read from the stream
+	pending     []byte // the next non-blank line, read but not decoded
+	pendingLine int
+	line        int // of the line last decoded
+}
+
+// NewDecoder returns a Decoder reading from r. Only the current line is
+// held in memory.
+func NewDecoder(r io.Reader) *Decoder {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 4096), MaxLineBytes)
+	return &Decoder{sc: sc}
+}
+
+// Line returns the number of the line last decoded
+func (d *Decoder) Line() int {
+	return d.line
+}
+
+// More reports whether a non-blank line follows. It reads ahead to find
+// one, so a read error shows up from the next Decode.
+func (d *Decoder) More() bool {
+	return d.pending != nil || d.scan()
+}
+
+// scan reads up to the next non-blank line into pending
+func (d *Decoder) scan() bool {
+	for d.sc.Scan() {
+		d.scanned++
+		if line := bytes.TrimSpace(d.sc.Bytes()); len(line) > 0 {
+			d.pending, d.pendingLine = line, d.scanned
+			return true
+		}
+	}
+	return false
+}
+
+// Decode reads the next non-blank line into v. At the end of the stream
+// it returns io.EOF. A *LineError leaves the decoder at the next line;
+// after any other error, such as ctx being done or the stream failing to
+// read, it should not be used again.
+func (d *Decoder) Decode(ctx context.Context, v any) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if d.pending == nil && !d.scan() {
+		if err := d.sc.Err(); err != nil {
+			return err
+		}
+		return io.EOF
+	}
+	line := d.pending
+	d.pending, d.line = nil, d.pendingLine
+	if err := json.Unmarshal(line, v); err != nil {
+		return &LineError{Line: d.line, Err: err}
+	}
+	return nil
+}
+
+// Values iterates over the values of type T on the lines of r. Each
+// *LineError is yielded with a zero T and iteration goes on; any other
+// error, ctx's included, is yielded last.
+func Values[T any](ctx context.Context, r io.Reader) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		d := NewDecoder(r)
+		for {
+			var v T
+			err := d.Decode(ctx, &v)
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			var lineErr *LineError
+			if !yield(v, err) || err != nil && !errors.As(err, &lineErr) {
+				return
+			}
+		}
+	}
+}
+
+// Encoder writes JSON values to a stream, one per line
+type Encoder struct {
+	w     io.Writer
+	buf   bytes.Buffer
+	line  int
+	flush func()
+}
+
+// NewEncoder returns an Encoder writing to w. If w is an http.Flusher,
+// every line is flushed as it is written, so a client reading a long
+// export sees it arrive.
+func NewEncoder(w io.Writer) *Encoder {
+	e := &Encoder{w: w}
+	if f, ok := w.(interface{ Flush() }); ok {
+		e.flush = f.Flush
+	}
+	return e
+}
+
+// Encode writes v as one line. A value that cannot be marshaled is a
+// *LineError and nothing is written for it; the encoder can go on.
+func (e *Encoder) Encode(ctx context.Context, v any) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	e.buf.Reset()
+	// json.Encoder escapes U+2028 and U+2029 and writes no raw newlines,
+	// so the value stays on its line
+	if err := json.NewEncoder(&e.buf).Encode(v); err != nil {
+		return &LineError{Line: e.line + 1, Err: err}
+	}
+	if _, err := e.w.Write(e.buf.Bytes()); err != nil {
+		return err
+	}
+	e.line++
+	if e.flush != nil {
+		e.flush()
+	}
+	return nil
+}
+
+// Write encodes every value of values to w, one per line, and returns how
+// many lines it wrote. It stops at the first error.
+func Write[T any](ctx context.Context, w io.Writer, values iter.Seq[T]) (int, error) {
+	e := NewEncoder(w)
+	for v := range values {
+		if err := e.Encode(ctx, v); err != nil {
+			return e.line, err
+		}
+	}
+	return e.line, nil
+}
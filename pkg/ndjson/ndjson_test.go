@@ -0,0 +1,211 @@
+package ndjson
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+type book struct {
+	Title string  `json:"title"`
+	Price float64 `json:"price"`
+}
+
+// chunkReader returns the data of r in reads of the given sizes in turn,
+// so lines arrive split across reads at every possible point
+type chunkReader struct {
+	r     io.Reader
+	sizes []int
+	i     int
+}
+
+func (c *chunkReader) Read(p []byte) (int, error) {
+	n := c.sizes[c.i%len(c.sizes)]
+	c.i++
+	return c.r.Read(p[:min(n, len(p))])
+}
+
+// readers returns the ways of delivering input the decoder must not care
+// about
+func readers(input string) map[string]io.Reader {
+	return map[string]io.Reader{
+		"whole":    strings.NewReader(input),
+		"one byte": iotest.OneByteReader(strings.NewReader(input)),
+		"half":     iotest.HalfReader(strings.NewReader(input)),
+		"chunks":   &chunkReader{r: strings.NewReader(input), sizes: []int{3, 1, 7, 2}},
+		"data+err": iotest.DataErrReader(strings.NewReader(input)),
+		"buffered": bufio.NewReaderSize(strings.NewReader(input), 16),
+	}
+}
+
+type result struct {
+	books []book
+	lines []int // of the LineErrors
+	err   error
+}
+
+func readAll(ctx context.Context, r io.Reader) result {
+	var res result
+	for b, err := range Values[book](ctx, r) {
+		var lineErr *LineError
+		switch {
+		case errors.As(err, &lineErr):
+			res.lines = append(res.lines, lineErr.Line)
+		case err != nil:
+			res.err = err
+		default:
+			res.books = append(res.books, b)
+		}
+	}
+	return res
+}
+
+func TestValues_Chunked(t *testing.T) {
+	input := `{"title":"Go in Action","price":24.99}` + "\n" +
+		"\n" + // 2: blank
+		`{"title":"Learning Go",` + "\n" + // 3: cut short
+		"  \t\r\n" + // 4: blank
+		`{"title":"Crlf","price":1}` + "\r\n" + // 5
+		`{"title":"Wrong","price":"cheap"}` + "\n" + // 6: wrong shape
+		`[1,2]` + "\n" + // 7: not an object
+		`{"title":"Café ☕  ","price":2}` + "\n" + // 8
+		`{"title":"No newline at the end","price":3}`
+	want := result{
+		books: []book{
+			{"Go in Action", 24.99},
+			{"Crlf", 1},
+			{"Café ☕  ", 2},
+			{"No newline at the end", 3},
+		},
+		lines: []int{3, 6, 7},
+	}
+	for name, r := range readers(input) {
+		t.Run(name, func(t *testing.T) {
+			got := readAll(context.Background(), r)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %+v\nwant %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestDecoder_LineAndMore(t *testing.T) {
+	d := NewDecoder(strings.NewReader("\n{\"title\":\"A\"}\n\n\n{\"title\":\"B\"}\n\n"))
+	ctx := context.Background()
+	var b book
+	if err := d.Decode(ctx, &b); err != nil || b.Title != "A" || d.Line() != 2 {
+		t.Fatalf("Decode = %v, %+v at line %d; want A at line 2", err, b, d.Line())
+	}
+	if !d.More() || d.Line() != 2 {
+		t.Fatalf("More() = false or moved Line() to %d; want true, still line 2", d.Line())
+	}
+	if err := d.Decode(ctx, &b); err != nil || b.Title != "B" || d.Line() != 5 {
+		t.Fatalf("Decode = %v, %+v at line %d; want B at line 5", err, b, d.Line())
+	}
+	if d.More() {
+		t.Error("More() = true with only blank lines left")
+	}
+	if err := d.Decode(ctx, &b); err != io.EOF {
+		t.Errorf("Decode at the end = %v; want io.EOF", err)
+	}
+}
+
+func TestValues_StreamErrors(t *testing.T) {
+	t.Run("read error after some lines", func(t *testing.T) {
+		boom := errors.New("connection reset")
+		r := io.MultiReader(strings.NewReader(`{"title":"A"}`+"\n"), iotest.ErrReader(boom))
+		got := readAll(context.Background(), iotest.OneByteReader(r))
+		if len(got.books) != 1 || !errors.Is(got.err, boom) {
+			t.Errorf("got %+v; want one book then %v", got, boom)
+		}
+	})
+
+	t.Run("line too long", func(t *testing.T) {
+		long := `{"title":"` + strings.Repeat("x", MaxLineBytes) + `"}` + "\n" + `{"title":"B"}` + "\n"
+		got := readAll(context.Background(), strings.NewReader(long))
+		if len(got.books) != 0 || !errors.Is(got.err, bufio.ErrTooLong) {
+			t.Errorf("got %d books, error %v; want bufio.ErrTooLong", len(got.books), got.err)
+		}
+	})
+}
+
+func TestValues_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	input := strings.Repeat(`{"title":"A"}`+"\n", 10)
+	var n int
+	var last error
+	for _, err := range Values[book](ctx, iotest.HalfReader(strings.NewReader(input))) {
+		if err != nil {
+			last = err
+			break
+		}
+		if n++; n == 3 {
+			cancel()
+		}
+	}
+	if n != 3 || !errors.Is(last, context.Canceled) {
+		t.Errorf("read %d books, then %v; want 3 then context.Canceled", n, last)
+	}
+}
+
+func TestEncoder(t *testing.T) {
+	books := []book{{"Go in Action", 24.99}, {"Line\nbreak  ", 0}, {"", 1e21}}
+	var buf bytes.Buffer
+	n, err := Write(context.Background(), &buf, slices.Values(books))
+	if err != nil || n != 3 {
+		t.Fatalf("Write = %d, %v; want 3 lines", n, err)
+	}
+	if lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n"); len(lines) != 3 {
+		t.Fatalf("output has %d lines; want 3:\n%s", len(lines), buf.String())
+	}
+
+	// What it writes reads back, however it arrives
+	for name, r := range readers(buf.String()) {
+		got := readAll(context.Background(), r)
+		if got.err != nil || got.lines != nil || !reflect.DeepEqual(got.books, books) {
+			t.Errorf("%s: read back %+v; want %+v", name, got, books)
+		}
+	}
+}
+
+func TestEncoder_Errors(t *testing.T) {
+	var buf bytes.Buffer
+	e := NewEncoder(&buf)
+	ctx := context.Background()
+	e.Encode(ctx, book{Title: "A"})
+	err := e.Encode(ctx, map[string]any{"bad": make(chan int)})
+	var lineErr *LineError
+	if !errors.As(err, &lineErr) || lineErr.Line != 2 {
+		t.Errorf("Encode of a channel = %v; want a LineError for line 2", err)
+	}
+	e.Encode(ctx, book{Title: "B"})
+	if got := strings.Count(buf.String(), "\n"); got != 2 {
+		t.Errorf("wrote %d lines; want the 2 good ones:\n%s", got, buf.String())
+	}
+
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := e.Encode(cctx, book{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Encode after cancel = %v; want context.Canceled", err)
+	}
+}
+
+func TestEncoder_Flushes(t *testing.T) {
+	rr := httptest.NewRecorder()
+	e := NewEncoder(rr)
+	if err := e.Encode(context.Background(), book{Title: "A"}); err != nil {
+		t.Fatal(err)
+	}
+	if !rr.Flushed {
+		t.Error("the line was not flushed to the http.Flusher")
+	}
+}
@@ -0,0 +1,135 @@
+// Package profiling captures CPU and heap profiles programmatically and
+// serves the net/http/pprof endpoints on a dedicated handler.
+//
+// Profiles written here are read with the pprof tool:
+//
+//	go tool pprof -top cpu.out
+//	go tool pprof -http=:8081 heap.out
+//
+// Importing this package also imports net/http/pprof, which registers its
+// handlers on http.DefaultServeMux. Servers in this repo use their own
+// muxes, so those registrations are never reachable; use Handler instead.
+package profiling
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"runtime"
+	runtimepprof "runtime/pprof"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Handler returns a mux serving the pprof endpoints under /debug/pprof/.
+// Mount it on a separate, private listener: profiles expose internals and
+// a CPU profile request keeps a core busy for its whole duration.
+func Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index) // also serves heap, goroutine, allocs, ...
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	return mux
+}
+
+// CaptureCPU records a CPU profile to w while fn runs. Only one CPU profile
+// can be active per process, so this fails if another is in progress.
+func CaptureCPU(w io.Writer, fn func()) error {
+	if err := runtimepprof.StartCPUProfile(w); err != nil {
+		return fmt.Errorf("start CPU profile: %w", err)
+	}
+	defer runtimepprof.StopCPUProfile()
+	fn()
+	return nil
+}
+
+// WriteHeap writes a heap profile to w. It runs a GC first so the profile
+// reflects live objects rather than garbage awaiting collection.
+func WriteHeap(w io.Writer) error {
+	runtime.GC()
+	return runtimepprof.WriteHeapProfile(w)
+}
+
+// CaptureCPUToFile is CaptureCPU writing to a file at path
+func CaptureCPUToFile(path string, fn func()) error {
+	return writeFile(path, func(w io.Writer) error { return CaptureCPU(w, fn) })
+}
+
+// WriteHeapToFile is WriteHeap writing to a file at path
+func WriteHeapToFile(path string) error {
+	return writeFile(path, WriteHeap)
+}
+
+func writeFile(path string, write func(io.Writer) error) (err error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+	return write(f)
+}
+
+// retained keeps part of the workload's allocations alive so that they
+// show up in heap profiles
+var retained [][]string
+
+// Workload is a demo job with obvious hot spots for profiles to find:
+// string concatenation in a loop (CPU and allocations), sorting, and a
+// slice kept alive on the heap. It returns a checksum so the compiler
+// cannot discard the work.
+func Workload(iterations int) int {
+	checksum := 0
+	for i := 0; i < iterations; i++ {
+		words := make([]string, 0, 256)
+		for j := 0; j < 256; j++ {
+			s := ""
+			for k := 0; k < 8; k++ {
+				s += strconv.Itoa((i*j + k) % 97) // deliberately inefficient
+			}
+			words = append(words, s)
+		}
+		sort.Strings(words)
+		checksum += len(strings.Join(words, ","))
+		if i%16 == 0 {
+			retained = append(retained, words)
+		}
+	}
+	return checksum
+}
+
+// ReleaseWorkload drops the memory retained by Workload
+func ReleaseWorkload() {
+	retained = nil
+}
+
+// Instructions explains how to profile the examples in this repo
+const Instructions = `Profiling the examples
+
+  Capture profiles of the demo workload:
+    go run ./cmd/runner profile cpu -o cpu.out
+    go run ./cmd/runner profile heap -o heap.out
+
+  Inspect them:
+    go tool pprof -top cpu.out            # hottest functions
+    go tool pprof -list=Workload cpu.out  # line-by-line costs
+    go tool pprof -sample_index=alloc_space -top heap.out
+    go tool pprof -http=:8081 heap.out    # web UI with flame graph
+
+  Profile the running REST API:
+    go run ./cmd/runner serve rest-api -pprof=localhost:6060
+    go tool pprof http://localhost:6060/debug/pprof/profile?seconds=10
+    go tool pprof http://localhost:6060/debug/pprof/heap
+    curl 'http://localhost:6060/debug/pprof/goroutine?debug=2'
+
+  Profile benchmarks:
+    go test -bench=. -cpuprofile=cpu.out -memprofile=mem.out ./basic-concepts/reflection/
+`
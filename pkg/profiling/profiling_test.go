@@ -0,0 +1,97 @@
+package profiling
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCaptureCPU(t *testing.T) {
+	var buf bytes.Buffer
+	ran := false
+	err := CaptureCPU(&buf, func() {
+		ran = true
+		Workload(4)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ran || buf.Len() == 0 {
+		t.Errorf("ran = %t, profile bytes = %d; want true and a non-empty profile", ran, buf.Len())
+	}
+}
+
+func TestCaptureCPU_AlreadyRunning(t *testing.T) {
+	var outer, inner bytes.Buffer
+	var innerErr error
+	err := CaptureCPU(&outer, func() {
+		innerErr = CaptureCPU(&inner, func() {})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if innerErr == nil {
+		t.Error("nested CaptureCPU succeeded; want an error")
+	}
+}
+
+func TestWriteHeapToFile(t *testing.T) {
+	t.Cleanup(ReleaseWorkload)
+	Workload(32)
+
+	path := filepath.Join(t.TempDir(), "heap.out")
+	if err := WriteHeapToFile(path); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() == 0 {
+		t.Error("heap profile is empty")
+	}
+}
+
+func TestCaptureCPUToFile_BadPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing-dir", "cpu.out")
+	if err := CaptureCPUToFile(path, func() {}); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+}
+
+func TestWorkload(t *testing.T) {
+	t.Cleanup(ReleaseWorkload)
+	if a, b := Workload(3), Workload(3); a != b || a == 0 {
+		t.Errorf("Workload(3) = %d then %d; want the same non-zero checksum", a, b)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	handler := Handler()
+
+	tests := []struct {
+		path     string
+		contains string
+	}{
+		{"/debug/pprof/", "Types of profiles available"},
+		{"/debug/pprof/heap?debug=1", "heap profile"},
+		{"/debug/pprof/goroutine?debug=1", "goroutine profile"},
+		{"/debug/pprof/cmdline", ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.path, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tc.path, nil))
+			if rr.Code != http.StatusOK {
+				t.Fatalf("status = %d; want 200", rr.Code)
+			}
+			if !strings.Contains(rr.Body.String(), tc.contains) {
+				t.Errorf("body does not contain %q", tc.contains)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+// Package pubsub is an in-process publish/subscribe bus. Every event gets
+// the next sequence number as its ID, and the bus keeps the most recent
+// ones so a subscriber that reconnects can resume where it left off:
+//
+//	bus := pubsub.New[string](100)
+//	sub, _ := bus.Subscribe(lastSeenID, 16)
+//	defer sub.Close()
+//	for ev := range sub.C() {
+//		fmt.Println(ev.ID, ev.Data)
+//	}
+//
+// Publish never blocks. A subscriber whose buffer is full is dropped: its
+// channel is closed and Dropped reports true, and it can subscribe again
+// from the last ID it saw. One slow reader cannot stall the publisher or
+// the other subscribers.
+package pubsub
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Event is one published value and its sequence number, starting at 1
+type Event[T any] struct {
+	ID   uint64
+	Data T
+}
+
+// Bus delivers published events to every current subscriber. It is safe
+// for concurrent use.
+type Bus[T any] struct {
+	mu      sync.Mutex
+	history []Event[T] // ring of the last cap(history) events
+	start   int        // index of the oldest event in history
+	lastID  uint64
+	subs    map[*Subscription[T]]struct{}
+	closed  bool
+}
+
+// New returns a bus that keeps the last history events for replay. Zero
+// keeps none, so subscribers only see events published after they join.
+func New[T any](history int) *Bus[T] {
+	return &Bus[T]{history: make([]Event[T], 0, history), subs: make(map[*Subscription[T]]struct{})}
+}
+
+// Publish sends data to every subscriber and returns its event. On a
+// closed bus the event is numbered but delivered to no one.
+func (b *Bus[T]) Publish(data T) Event[T] {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	ev := Event[T]{ID: b.lastID, Data: data}
+	if cap(b.history) > 0 {
+		if len(b.history) < cap(b.history) {
+			b.history = append(b.history, ev)
+		} else {
+			b.history[b.start] = ev
+			b.start = (b.start + 1) % len(b.history)
+		}
+	}
+	for sub := range b.subs {
+		select {
+		case sub.ch <- ev:
+		default:
+			sub.dropped.Store(true)
+			b.remove(sub)
+		}
+	}
+	return ev
+}
+
+// Subscribe returns a subscription to events after the one with ID after;
+// zero means events published from now on. Kept events after that ID are
+// replayed first, in order, without any published in between being lost
+// or repeated. complete is false if some events after it are no longer
+// kept, or the ID is unknown, so the replay may have a gap. buffer is how
+// many events may wait to be received before the subscriber is dropped,
+// not counting the replay.
+func (b *Bus[T]) Subscribe(after uint64, buffer int) (sub *Subscription[T], complete bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var replay []Event[T]
+	complete = true
+	if after > 0 {
+		replay, complete = b.keptAfter(after)
+	}
+
+	sub = &Subscription[T]{bus: b, ch: make(chan Event[T], len(replay)+buffer)}
+	for _, ev := range replay {
+		sub.ch <- ev
+	}
+	if b.closed {
+		close(sub.ch)
+		return sub, complete
+	}
+	b.subs[sub] = struct{}{}
+	return sub, complete
+}
+
+// Since returns the kept events after the one with ID after, oldest
+// first, for reading the history without subscribing; zero means all of
+// them. complete is false as for Subscribe.
+func (b *Bus[T]) Since(after uint64) (events []Event[T], complete bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.keptAfter(after)
+}
+
+// keptAfter returns the kept events with IDs after after and whether they
+// are all the events there were after it. b.mu must be held.
+func (b *Bus[T]) keptAfter(after uint64) (events []Event[T], complete bool) {
+	// An ID past the last one was never issued by this bus, perhaps by one
+	// before a restart, so there is no telling what came after it
+	complete = after <= b.lastID
+	if after < b.lastID {
+		kept := b.ordered()
+		if len(kept) == 0 || kept[0].ID > after+1 {
+			complete = false
+		}
+		for _, ev := range kept {
+			if ev.ID > after {
+				events = append(events, ev)
+			}
+		}
+	}
+	return events, complete
+}
+
+// LastID returns the ID of the most recent event, zero if there is none
+func (b *Bus[T]) LastID() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.lastID
+}
+
+// Close ends every subscription, and any made later ends at once after
+// its replay. A server calls it on shutdown so long-lived streams return.
+func (b *Bus[T]) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for sub := range b.subs {
+		b.remove(sub)
+	}
+}
+
+// ordered returns the kept events oldest first. b.mu must be held.
+func (b *Bus[T]) ordered() []Event[T] {
+	return append(append([]Event[T](nil), b.history[b.start:]...), b.history[:b.start]...)
+}
+
+// remove ends sub. b.mu must be held.
+func (b *Bus[T]) remove(sub *Subscription[T]) {
+	if _, ok := b.subs[sub]; ok {
+		delete(b.subs, sub)
+		close(sub.ch)
+	}
+}
+
+// Subscription receives a bus's events until it is closed or dropped
+type Subscription[T any] struct {
+	bus     *Bus[T]
+	ch      chan Event[T]
+	dropped atomic.Bool
+}
+
+// C returns the channel events arrive on. It is closed when the
+// subscription ends, whether by Close, Bus.Close or being dropped.
+func (s *Subscription[T]) C() <-chan Event[T] {
+	return s.ch
+}
+
+// Dropped reports whether the subscription ended because it fell a full
+// buffer behind
+func (s *Subscription[T]) Dropped() bool {
+	return s.dropped.Load()
+}
+
+// Close ends the subscription. It is safe to call more than once.
+func (s *Subscription[T]) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	s.bus.remove(s)
+}
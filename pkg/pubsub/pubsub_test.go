@@ -0,0 +1,201 @@
+package pubsub
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// ids drains what is waiting on sub without blocking
+func ids[T any](sub *Subscription[T]) []uint64 {
+	var got []uint64
+	for {
+		select {
+		case ev, ok := <-sub.C():
+			if !ok {
+				return got
+			}
+			got = append(got, ev.ID)
+		default:
+			return got
+		}
+	}
+}
+
+func TestPublishSubscribe(t *testing.T) {
+	bus := New[string](0)
+	a, _ := bus.Subscribe(0, 4)
+	b, _ := bus.Subscribe(0, 4)
+	bus.Publish("one")
+	ev := bus.Publish("two")
+
+	if ev.ID != 2 || ev.Data != "two" || bus.LastID() != 2 {
+		t.Errorf("Publish = %+v, LastID = %d; want event 2", ev, bus.LastID())
+	}
+	for name, sub := range map[string]*Subscription[string]{"a": a, "b": b} {
+		if got := ids(sub); !slices.Equal(got, []uint64{1, 2}) {
+			t.Errorf("subscriber %s got %v; want [1 2]", name, got)
+		}
+	}
+
+	a.Close()
+	a.Close() // a second Close is harmless
+	bus.Publish("three")
+	if _, ok := <-a.C(); ok {
+		t.Error("closed subscription received an event")
+	}
+	if got := ids(b); !slices.Equal(got, []uint64{3}) {
+		t.Errorf("remaining subscriber got %v; want [3]", got)
+	}
+}
+
+func TestSubscribe_Replay(t *testing.T) {
+	bus := New[int](3)
+	for i := range 5 {
+		bus.Publish(i)
+	}
+	// Events 3, 4 and 5 are kept
+
+	tests := []struct {
+		name         string
+		after        uint64
+		want         []uint64
+		wantComplete bool
+	}{
+		{"new events only", 0, nil, true},
+		{"all kept events", 2, []uint64{3, 4, 5}, true},
+		{"some kept events", 4, []uint64{5}, true},
+		{"up to date", 5, nil, true},
+		{"gap", 1, []uint64{3, 4, 5}, false},
+		{"unknown ID", 9, nil, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			sub, complete := bus.Subscribe(tc.after, 1)
+			defer sub.Close()
+			if got := ids(sub); !slices.Equal(got, tc.want) || complete != tc.wantComplete {
+				t.Errorf("Subscribe(%d) replayed %v, complete %v; want %v, %v", tc.after, got, complete, tc.want, tc.wantComplete)
+			}
+		})
+	}
+}
+
+func TestSince(t *testing.T) {
+	bus := New[int](3)
+	if events, complete := bus.Since(0); events != nil || !complete {
+		t.Errorf("Since(0) on an empty bus = %v, %v; want nothing, complete", events, complete)
+	}
+	for i := range 5 {
+		bus.Publish(i)
+	}
+
+	tests := []struct {
+		after        uint64
+		want         []uint64
+		wantComplete bool
+	}{
+		{0, []uint64{3, 4, 5}, false}, // 1 and 2 are gone
+		{1, []uint64{3, 4, 5}, false},
+		{2, []uint64{3, 4, 5}, true},
+		{4, []uint64{5}, true},
+		{5, nil, true},
+		{9, nil, false},
+	}
+	for _, tc := range tests {
+		events, complete := bus.Since(tc.after)
+		var got []uint64
+		for _, ev := range events {
+			got = append(got, ev.ID)
+		}
+		if !slices.Equal(got, tc.want) || complete != tc.wantComplete {
+			t.Errorf("Since(%d) = %v, complete %v; want %v, %v", tc.after, got, complete, tc.want, tc.wantComplete)
+		}
+	}
+}
+
+func TestSlowSubscriberDropped(t *testing.T) {
+	bus := New[int](0)
+	slow, _ := bus.Subscribe(0, 1)
+	fast, _ := bus.Subscribe(0, 3)
+	for i := range 3 {
+		bus.Publish(i)
+	}
+
+	if got := ids(slow); !slices.Equal(got, []uint64{1}) || !slow.Dropped() {
+		t.Errorf("slow subscriber got %v, dropped %v; want [1], true", got, slow.Dropped())
+	}
+	if got := ids(fast); !slices.Equal(got, []uint64{1, 2, 3}) || fast.Dropped() {
+		t.Errorf("fast subscriber got %v, dropped %v; want [1 2 3], false", got, fast.Dropped())
+	}
+}
+
+func TestClose(t *testing.T) {
+	bus := New[int](2)
+	before, _ := bus.Subscribe(0, 1)
+	bus.Publish(1)
+	bus.Close()
+
+	if got := ids(before); !slices.Equal(got, []uint64{1}) {
+		t.Errorf("subscriber got %v; want [1] and then a closed channel", got)
+	}
+	if _, ok := <-before.C(); ok {
+		t.Error("subscription still open after Close")
+	}
+	if before.Dropped() {
+		t.Error("Close reported as a drop")
+	}
+
+	// Subscribing afterwards still replays, then ends
+	late, _ := bus.Subscribe(0, 1)
+	if _, ok := <-late.C(); ok {
+		t.Error("subscription made after Close is open")
+	}
+	bus.Publish(2)
+	resumed, _ := bus.Subscribe(1, 1)
+	if got := ids(resumed); !slices.Equal(got, []uint64{2}) {
+		t.Errorf("resumed subscriber got %v; want [2] and then a closed channel", got)
+	}
+}
+
+func TestConcurrentPublish(t *testing.T) {
+	bus := New[int](10)
+	sub, _ := bus.Subscribe(0, 1000)
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 100 {
+				bus.Publish(i)
+			}
+		}()
+	}
+	wg.Wait()
+
+	got := ids(sub)
+	if len(got) != 1000 || !slices.IsSorted(got) {
+		t.Errorf("got %d events, sorted %v; want all 1000 in ID order", len(got), slices.IsSorted(got))
+	}
+}
+
+func Example() {
+	bus := New[string](10)
+	bus.Publish("book 1 created")
+	bus.Publish("book 2 created")
+
+	// A client that saw event 1 resumes after it
+	sub, complete := bus.Subscribe(1, 8)
+	defer sub.Close()
+	bus.Publish("book 1 deleted")
+
+	for range 2 {
+		ev := <-sub.C()
+		fmt.Println(ev.ID, ev.Data)
+	}
+	fmt.Println("complete:", complete)
+	// Output:
+	// 2 book 2 created
+	// 3 book 1 deleted
+	// complete: true
+}
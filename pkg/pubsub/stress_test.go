@@ -0,0 +1,78 @@
+//go:build stress
+
+package pubsub
+
+import (
+	"math/rand/v2"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/testutil/stress"
+)
+
+// TestStress_Bus has goroutines publish while others subscribe from
+// random points in the history, read a few events and leave, or stall
+// until they are dropped. Each subscriber must see IDs in order without
+// gaps or repeats, replay included, and a complete replay must start
+// right after the ID it asked for.
+func TestStress_Bus(t *testing.T) {
+	const history = 256
+	b := New[uint64](history)
+	var published, dropped atomic.Int64
+
+	stress.Run(t, stress.Config{}, func(r *rand.Rand) {
+		if r.IntN(2) == 0 {
+			ev := b.Publish(r.Uint64())
+			if ev.ID == 0 {
+				t.Error("Publish returned ID 0")
+			}
+			published.Add(1)
+			return
+		}
+
+		after := b.LastID()
+		if back := uint64(r.IntN(2 * history)); back < after {
+			after -= back
+		}
+		sub, complete := b.Subscribe(after, 1+r.IntN(8))
+		defer sub.Close()
+		stall := r.IntN(10) == 0 // reads nothing, to be dropped
+		timeout := time.NewTimer(time.Millisecond)
+		defer timeout.Stop()
+		last := after
+		for read := 0; read < 20; read++ {
+			if stall {
+				time.Sleep(time.Millisecond)
+				break
+			}
+			select {
+			case ev, ok := <-sub.C():
+				if !ok {
+					if !sub.Dropped() {
+						t.Error("a subscription ended without Close or being dropped")
+					}
+					dropped.Add(1)
+					return
+				}
+				switch {
+				case last == after && complete && ev.ID != after+1:
+					t.Errorf("complete subscription after %d started at %d", after, ev.ID)
+				case last != after && ev.ID != last+1:
+					t.Errorf("subscriber got %d after %d; want %d", ev.ID, last, last+1)
+				case ev.ID <= after:
+					t.Errorf("subscription after %d got %d", after, ev.ID)
+				}
+				last = ev.ID
+			case <-timeout.C:
+				return
+			}
+		}
+	})
+
+	if got, want := b.LastID(), uint64(published.Load()); got != want {
+		t.Errorf("LastID() = %d; want %d, one per Publish", got, want)
+	}
+	b.Close()
+	t.Logf("%d published, %d subscribers dropped", published.Load(), dropped.Load())
+}
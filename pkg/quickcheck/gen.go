@@ -0,0 +1,214 @@
+package quickcheck
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"reflect"
+)
+
+// Generator makes random values of T, and simpler versions of a value
+type Generator[T any] struct {
+	// Generate returns a value whose size, such as a slice's length or a
+	// number's magnitude, is at most size
+	Generate func(r *rand.Rand, size int) T
+
+	// Shrink returns values simpler than v, most promising first; nil
+	// means v cannot be simplified. It may be nil itself.
+	Shrink func(v T) []T
+}
+
+// Int generates ints between -size and size
+func Int() Generator[int] {
+	return Generator[int]{
+		Generate: func(r *rand.Rand, size int) int {
+			return r.IntN(2*size+1) - size
+		},
+		Shrink: func(v int) []int {
+			return shrinkInt(v, 0)
+		},
+	}
+}
+
+// IntRange generates ints between lo and hi, whatever the size, shrinking
+// them towards the one nearest zero. It panics if lo > hi.
+func IntRange(lo, hi int) Generator[int] {
+	if lo > hi {
+		panic(fmt.Sprintf("quickcheck: IntRange(%d, %d)", lo, hi))
+	}
+	target := min(max(0, lo), hi)
+	return Generator[int]{
+		Generate: func(r *rand.Rand, size int) int {
+			// Unsigned, so that hi-lo does not overflow
+			n := uint64(hi) - uint64(lo)
+			if n == 1<<64-1 {
+				return int(r.Uint64())
+			}
+			return lo + int(r.Uint64N(n+1))
+		},
+		Shrink: func(v int) []int {
+			return shrinkInt(v, target)
+		},
+	}
+}
+
+// Bool generates true and false
+func Bool() Generator[bool] {
+	return Generator[bool]{
+		Generate: func(r *rand.Rand, size int) bool {
+			return r.IntN(2) == 1
+		},
+		Shrink: func(v bool) []bool {
+			if v {
+				return []bool{false}
+			}
+			return nil
+		},
+	}
+}
+
+// alphabet is what String draws from: mostly ASCII, with the separators
+// and multi-byte runes that trip up byte-indexed code
+const alphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789 \t\n.,;:-_'\"\\/éß€日本語🙂"
+
+// String generates strings of up to size runes, shrinking them towards
+// fewer runes and then towards "a"s
+func String() Generator[string] {
+	return StringOf(alphabet)
+}
+
+// StringOf generates strings of up to size runes from alphabet, shrinking
+// them towards its first rune. It panics if alphabet is empty.
+func StringOf(alphabet string) Generator[string] {
+	runes := []rune(alphabet)
+	if len(runes) == 0 {
+		panic("quickcheck: StringOf an empty alphabet")
+	}
+	return Generator[string]{
+		Generate: func(r *rand.Rand, size int) string {
+			s := make([]rune, r.IntN(size+1))
+			for i := range s {
+				s[i] = runes[r.IntN(len(runes))]
+			}
+			return string(s)
+		},
+		Shrink: func(v string) []string {
+			var shrunk []string
+			for _, s := range shrinkSlice([]rune(v), func(c rune) []rune {
+				if c == runes[0] {
+					return nil
+				}
+				return []rune{runes[0]}
+			}) {
+				shrunk = append(shrunk, string(s))
+			}
+			return shrunk
+		},
+	}
+}
+
+// SliceOf generates slices of up to size elements from elem, nil when
+// empty
+func SliceOf[T any](elem Generator[T]) Generator[[]T] {
+	return Generator[[]T]{
+		Generate: func(r *rand.Rand, size int) []T {
+			n := r.IntN(size + 1)
+			if n == 0 {
+				return nil
+			}
+			s := make([]T, n)
+			for i := range s {
+				s[i] = elem.Generate(r, size)
+			}
+			return s
+		},
+		Shrink: func(v []T) [][]T {
+			return shrinkSlice(v, elem.Shrink)
+		},
+	}
+}
+
+// Any generates values of any type built from booleans, numbers,
+// strings, slices, arrays, maps, pointers and structs, setting exported
+// struct fields only. Sizes apply at every level: a [][]int of size 10
+// has up to 10 slices of up to 10 ints between -10 and 10. Pointers are
+// sometimes nil. Any panics for other types, such as interfaces.
+func Any[T any]() Generator[T] {
+	t := reflect.TypeFor[T]()
+	if err := supported(t, map[reflect.Type]bool{}); err != nil {
+		panic("quickcheck: " + err.Error())
+	}
+	return Generator[T]{
+		Generate: func(r *rand.Rand, size int) T {
+			return generate(t, r, size).Interface().(T)
+		},
+		Shrink: func(v T) []T {
+			var shrunk []T
+			for _, s := range shrinkValue(reflect.ValueOf(&v).Elem()) {
+				shrunk = append(shrunk, s.Interface().(T))
+			}
+			return shrunk
+		},
+	}
+}
+
+// shrinkInt returns numbers between v and target, which is zero or has
+// v's sign: target itself, about halfway there, and one step there
+func shrinkInt[N int | int64](v, target N) []N {
+	if v == target {
+		return nil
+	}
+	shrunk := []N{target}
+	// Halved first, so that the sum cannot overflow
+	if mid := v/2 + target/2; mid != v && mid != target {
+		shrunk = append(shrunk, mid)
+	}
+	next := v - 1
+	if v < target {
+		next = v + 1
+	}
+	if next != target && next != shrunk[len(shrunk)-1] {
+		shrunk = append(shrunk, next)
+	}
+	return shrunk
+}
+
+// shrinkUint is shrinkInt towards zero for unsigned numbers
+func shrinkUint(v uint64) []uint64 {
+	if v == 0 {
+		return nil
+	}
+	shrunk := []uint64{0}
+	if v/2 != 0 {
+		shrunk = append(shrunk, v/2)
+	}
+	if v-1 != 0 && v-1 != v/2 {
+		shrunk = append(shrunk, v-1)
+	}
+	return shrunk
+}
+
+// shrinkSlice returns v without runs of elements, halves first, then v
+// with one element shrunk by elem, which may be nil
+func shrinkSlice[T any](v []T, elem func(T) []T) [][]T {
+	var shrunk [][]T
+	if len(v) > 0 {
+		shrunk = append(shrunk, nil)
+	}
+	for k := len(v) / 2; k > 0; k /= 2 {
+		for i := 0; i+k <= len(v); i += k {
+			s := make([]T, 0, len(v)-k)
+			shrunk = append(shrunk, append(append(s, v[:i]...), v[i+k:]...))
+		}
+	}
+	if elem == nil {
+		return shrunk
+	}
+	for i := range v {
+		for _, e := range elem(v[i]) {
+			s := append([]T(nil), v...)
+			s[i] = e
+			shrunk = append(shrunk, s)
+		}
+	}
+	return shrunk
+}
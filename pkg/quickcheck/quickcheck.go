@@ -0,0 +1,127 @@
+// Package quickcheck tests properties, statements that hold for every
+// input, by checking them against many random inputs:
+//
+//	quickcheck.Check(t, quickcheck.SliceOf(quickcheck.Int()), func(in []int) error {
+//		got := mergeSort(slices.Clone(in))
+//		if !slices.IsSorted(got) {
+//			return fmt.Errorf("got %v", got)
+//		}
+//		return nil
+//	})
+//
+// Inputs grow over a run, from empty and tiny ones, where the edge cases
+// are, to ones of Config.MaxSize. A failing input is shrunk before it is
+// reported: slices lose elements and numbers move towards zero for as long
+// as the property still fails, so the report shows [1 0] rather than the
+// forty numbers that first failed.
+//
+// A run starts from a seed, which a failure reports; setting
+// QUICKCHECK_SEED to it generates the same inputs again.
+package quickcheck
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"testing"
+)
+
+// SeedEnvVar is the environment variable that fixes the seed of every run
+const SeedEnvVar = "QUICKCHECK_SEED"
+
+// maxShrinks bounds the simplifications of one failing input, each of
+// which may call the property many times
+const maxShrinks = 1000
+
+// Config is how a property is checked
+type Config struct {
+	Count   int    // inputs to try; 0 means 100
+	MaxSize int    // size of the last input; 0 means 100
+	Seed    uint64 // 0 means the QUICKCHECK_SEED one, or a random one
+}
+
+// Failure is an input that broke a property
+type Failure[T any] struct {
+	Seed     uint64
+	Tries    int // inputs tried, the failing one included
+	Input    T   // the shrunk input
+	Original T   // the input as generated
+	Shrinks  int
+	Err      error // what the property returned for Input
+}
+
+func (f *Failure[T]) Error() string {
+	return fmt.Sprintf("property failed on input %d of seed %d (%s=%d to repeat): %v\ninput:    %#v\nshrunk %d times from %#v",
+		f.Tries, f.Seed, SeedEnvVar, f.Seed, f.Err, f.Input, f.Shrinks, f.Original)
+}
+
+// Check fails t if prop returns an error or panics for an input from g
+func Check[T any](t testing.TB, g Generator[T], prop func(T) error) {
+	t.Helper()
+	if f := Run(Config{}, g, prop); f != nil {
+		t.Fatal(f)
+	}
+}
+
+// Run tries prop on inputs from g and returns the first that fails it,
+// shrunk, or nil if none did. A panic in prop is a failure.
+func Run[T any](c Config, g Generator[T], prop func(T) error) *Failure[T] {
+	if c.Count <= 0 {
+		c.Count = 100
+	}
+	if c.MaxSize <= 0 {
+		c.MaxSize = 100
+	}
+	if c.Seed == 0 {
+		c.Seed = defaultSeed()
+	}
+	r := rand.New(rand.NewPCG(c.Seed, c.Seed^0x9e3779b97f4a7c15))
+	for i := range c.Count {
+		size := c.MaxSize * i / max(c.Count-1, 1)
+		in := g.Generate(r, size)
+		err := try(prop, in)
+		if err == nil {
+			continue
+		}
+		f := &Failure[T]{Seed: c.Seed, Tries: i + 1, Input: in, Original: in, Err: err}
+		for f.Shrinks < maxShrinks && g.Shrink != nil && shrinkOnce(f, g.Shrink, prop) {
+			f.Shrinks++
+		}
+		return f
+	}
+	return nil
+}
+
+// shrinkOnce replaces f's input with the first simpler one that also
+// fails, and reports whether there was one
+func shrinkOnce[T any](f *Failure[T], shrink func(T) []T, prop func(T) error) bool {
+	for _, in := range shrink(f.Input) {
+		if err := try(prop, in); err != nil {
+			f.Input, f.Err = in, err
+			return true
+		}
+	}
+	return false
+}
+
+// try calls prop, turning a panic into an error
+func try[T any](prop func(T) error, in T) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return prop(in)
+}
+
+func defaultSeed() uint64 {
+	if s := os.Getenv(SeedEnvVar); s != "" {
+		seed, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			panic(fmt.Sprintf("quickcheck: %s=%q is not a seed", SeedEnvVar, s))
+		}
+		return seed
+	}
+	return rand.Uint64()
+}
@@ -0,0 +1,173 @@
+package quickcheck
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// fixed is a seed for the tests that need their inputs to repeat
+var fixed = Config{Seed: 1}
+
+func TestRun_Passes(t *testing.T) {
+	tries := 0
+	f := Run(Config{Count: 50}, SliceOf(Int()), func(in []int) error {
+		tries++
+		if len(in) > 100 {
+			return fmt.Errorf("%d elements", len(in))
+		}
+		return nil
+	})
+	if f != nil || tries != 50 {
+		t.Errorf("Run = %v after %d tries; want nil after 50", f, tries)
+	}
+}
+
+func TestRun_Shrinks(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func() (got, want any)
+	}{
+		{"slice to the one element failing", func() (any, any) {
+			f := Run(fixed, SliceOf(Int()), func(in []int) error {
+				if slices.ContainsFunc(in, func(n int) bool { return n >= 5 }) {
+					return errors.New("5 or more")
+				}
+				return nil
+			})
+			return f.Input, []int{5}
+		}},
+		{"slice to the shortest failing", func() (any, any) {
+			f := Run(fixed, SliceOf(Int()), func(in []int) error {
+				if len(in) >= 3 {
+					return errors.New("too long")
+				}
+				return nil
+			})
+			return f.Input, []int{0, 0, 0}
+		}},
+		{"string to the first runes", func() (any, any) {
+			f := Run(fixed, String(), func(in string) error {
+				if utf8.RuneCountInString(in) > 2 {
+					return errors.New("too long")
+				}
+				return nil
+			})
+			return f.Input, "aaa"
+		}},
+		{"range to its bound nearest zero", func() (any, any) {
+			f := Run(fixed, IntRange(10, 20), func(in int) error {
+				return errors.New("always")
+			})
+			return f.Input, 10
+		}},
+		{"negative range", func() (any, any) {
+			f := Run(fixed, IntRange(-20, -10), func(in int) error {
+				if in < -15 {
+					return errors.New("below -15")
+				}
+				return nil
+			})
+			return f.Input, -16
+		}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got, want := tc.run(); !reflect.DeepEqual(got, want) {
+				t.Errorf("shrunk to %#v; want %#v", got, want)
+			}
+		})
+	}
+}
+
+func TestRun_Panic(t *testing.T) {
+	f := Run(fixed, SliceOf(Int()), func(in []int) error {
+		_ = in[1]
+		return nil
+	})
+	if f == nil || f.Input != nil || !strings.Contains(f.Err.Error(), "panic: runtime error: index out of range") {
+		t.Fatalf("Run = %v; want an index panic for a nil slice", f)
+	}
+}
+
+func TestRun_Seed(t *testing.T) {
+	inputs := func(seed uint64) [][]int {
+		var seen [][]int
+		Run(Config{Count: 20, Seed: seed}, SliceOf(Int()), func(in []int) error {
+			seen = append(seen, in)
+			return nil
+		})
+		return seen
+	}
+	if a, b := inputs(7), inputs(7); !reflect.DeepEqual(a, b) {
+		t.Errorf("seed 7 gave %v, then %v", a, b)
+	}
+	if a, b := inputs(7), inputs(8); reflect.DeepEqual(a, b) {
+		t.Errorf("seeds 7 and 8 both gave %v", a)
+	}
+
+	t.Setenv(SeedEnvVar, "42")
+	f := Run(Config{}, Int(), func(int) error { return errors.New("fails") })
+	if f.Seed != 42 || !strings.Contains(f.Error(), "seed 42 (QUICKCHECK_SEED=42 to repeat)") {
+		t.Errorf("with %s=42, failure is %v", SeedEnvVar, f)
+	}
+}
+
+func TestRun_Sizes(t *testing.T) {
+	var lens []int
+	Run(Config{Count: 11, MaxSize: 10, Seed: 1}, StringOf("x"), func(in string) error {
+		lens = append(lens, len(in))
+		return nil
+	})
+	for i, n := range lens {
+		if n > i {
+			t.Errorf("input %d has %d runes; want at most %d", i, n, i)
+		}
+	}
+}
+
+type record struct {
+	ID     int
+	Name   string
+	Tags   []string
+	Scores map[string]float64
+	Next   *record
+	Flags  [2]bool
+	Small  uint8
+	hidden int
+}
+
+func TestAny(t *testing.T) {
+	g := Any[record]()
+	f := Run(fixed, g, func(r record) error {
+		if r.hidden != 0 {
+			return errors.New("set an unexported field")
+		}
+		if r.Next != nil && r.Next.ID > 3 {
+			return errors.New("next ID over 3")
+		}
+		return nil
+	})
+	if f == nil {
+		t.Fatal("no record had a next ID over 3")
+	}
+	// Maps are always made, if empty
+	want := record{Scores: map[string]float64{}, Next: &record{ID: 4, Scores: map[string]float64{}}}
+	if !reflect.DeepEqual(f.Input, want) {
+		t.Errorf("shrunk to %+v (next %+v); want %+v (next %+v)", f.Input, f.Input.Next, want, want.Next)
+	}
+}
+
+func TestAny_Unsupported(t *testing.T) {
+	defer func() {
+		if p := recover(); p == nil || !strings.Contains(fmt.Sprint(p), "quickcheck.withFunc.F: cannot generate func()") {
+			t.Errorf("panic = %v; want one naming the func field", p)
+		}
+	}()
+	type withFunc struct{ F func() }
+	Any[withFunc]()
+}
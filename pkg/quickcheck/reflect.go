@@ -0,0 +1,201 @@
+package quickcheck
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"reflect"
+)
+
+// supported returns an error if Any cannot generate t. seen stops
+// recursive types, which are built up to the pointer that ends them.
+func supported(t reflect.Type, seen map[reflect.Type]bool) error {
+	if seen[t] {
+		return nil
+	}
+	seen[t] = true
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return nil
+	case reflect.Slice, reflect.Array, reflect.Pointer:
+		return supported(t.Elem(), seen)
+	case reflect.Map:
+		if err := supported(t.Key(), seen); err != nil {
+			return err
+		}
+		return supported(t.Elem(), seen)
+	case reflect.Struct:
+		for i := range t.NumField() {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			if err := supported(f.Type, seen); err != nil {
+				return fmt.Errorf("%s.%s: %w", t, f.Name, err)
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("cannot generate %s", t)
+}
+
+// generate makes a random value of t
+func generate(t reflect.Type, r *rand.Rand, size int) reflect.Value {
+	v := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.Bool:
+		v.SetBool(r.IntN(2) == 1)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n := r.Int64N(2*int64(size)+1) - int64(size)
+		// Narrow types wrap, as a conversion would
+		v.Set(reflect.ValueOf(n).Convert(t))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v.Set(reflect.ValueOf(r.Uint64N(uint64(size) + 1)).Convert(t))
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat((2*r.Float64() - 1) * float64(size))
+	case reflect.String:
+		v.SetString(String().Generate(r, size))
+	case reflect.Slice:
+		n := r.IntN(size + 1)
+		if n == 0 {
+			break
+		}
+		v.Set(reflect.MakeSlice(t, n, n))
+		for i := range n {
+			v.Index(i).Set(generate(t.Elem(), r, size))
+		}
+	case reflect.Array:
+		for i := range v.Len() {
+			v.Index(i).Set(generate(t.Elem(), r, size))
+		}
+	case reflect.Map:
+		n := r.IntN(size + 1)
+		v.Set(reflect.MakeMapWithSize(t, n))
+		for range n {
+			v.SetMapIndex(generate(t.Key(), r, size), generate(t.Elem(), r, size))
+		}
+	case reflect.Pointer:
+		// Halving the size ends recursive types such as lists
+		if size > 0 && r.IntN(4) != 0 {
+			p := reflect.New(t.Elem())
+			p.Elem().Set(generate(t.Elem(), r, size/2))
+			v.Set(p)
+		}
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if t.Field(i).IsExported() {
+				v.Field(i).Set(generate(t.Field(i).Type, r, size))
+			}
+		}
+	}
+	return v
+}
+
+// shrinkValue returns values simpler than v, of v's type
+func shrinkValue(v reflect.Value) []reflect.Value {
+	t := v.Type()
+	var shrunk []reflect.Value
+	// with returns a copy of v with set applied to it
+	with := func(set func(c reflect.Value)) {
+		c := reflect.New(t).Elem()
+		c.Set(v)
+		set(c)
+		shrunk = append(shrunk, c)
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			with(func(c reflect.Value) { c.SetBool(false) })
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		for _, n := range shrinkInt(v.Int(), 0) {
+			with(func(c reflect.Value) { c.SetInt(n) })
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		for _, n := range shrinkUint(v.Uint()) {
+			with(func(c reflect.Value) { c.SetUint(n) })
+		}
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		for _, g := range []float64{0, math.Trunc(f), f / 2} {
+			if g != f && math.Abs(g) < math.Abs(f) {
+				with(func(c reflect.Value) { c.SetFloat(g) })
+			}
+		}
+	case reflect.String:
+		for _, s := range String().Shrink(v.String()) {
+			with(func(c reflect.Value) { c.SetString(s) })
+		}
+	case reflect.Slice:
+		n := v.Len()
+		if n > 0 {
+			shrunk = append(shrunk, reflect.Zero(t))
+		}
+		for k := n / 2; k > 0; k /= 2 {
+			for i := 0; i+k <= n; i += k {
+				s := reflect.MakeSlice(t, 0, n-k)
+				s = reflect.AppendSlice(s, v.Slice(0, i))
+				shrunk = append(shrunk, reflect.AppendSlice(s, v.Slice(i+k, n)))
+			}
+		}
+		for i := range n {
+			for _, e := range shrinkValue(v.Index(i)) {
+				s := reflect.MakeSlice(t, n, n)
+				reflect.Copy(s, v)
+				s.Index(i).Set(e)
+				shrunk = append(shrunk, s)
+			}
+		}
+	case reflect.Array:
+		for i := range v.Len() {
+			for _, e := range shrinkValue(v.Index(i)) {
+				with(func(c reflect.Value) { c.Index(i).Set(e) })
+			}
+		}
+	case reflect.Map:
+		// Maps are copied rather than shared with v, which a failing
+		// property keeps
+		copyMap := func(skip reflect.Value) reflect.Value {
+			m := reflect.MakeMapWithSize(t, v.Len())
+			for iter := v.MapRange(); iter.Next(); {
+				if !skip.IsValid() || !iter.Key().Equal(skip) {
+					m.SetMapIndex(iter.Key(), iter.Value())
+				}
+			}
+			return m
+		}
+		for _, k := range v.MapKeys() {
+			shrunk = append(shrunk, copyMap(k))
+		}
+		for _, k := range v.MapKeys() {
+			for _, e := range shrinkValue(v.MapIndex(k)) {
+				m := copyMap(reflect.Value{})
+				m.SetMapIndex(k, e)
+				shrunk = append(shrunk, m)
+			}
+		}
+	case reflect.Pointer:
+		if v.IsNil() {
+			break
+		}
+		shrunk = append(shrunk, reflect.Zero(t))
+		for _, e := range shrinkValue(v.Elem()) {
+			p := reflect.New(t.Elem())
+			p.Elem().Set(e)
+			shrunk = append(shrunk, p)
+		}
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if !t.Field(i).IsExported() {
+				continue
+			}
+			for _, e := range shrinkValue(v.Field(i)) {
+				with(func(c reflect.Value) { c.Field(i).Set(e) })
+			}
+		}
+	}
+	return shrunk
+}
@@ -0,0 +1,185 @@
+// Package quiz holds the repository's interview questions as data, one
+// embedded JSON file per topic in questions/, and asks them as a quiz.
+// The demos print their topic's questions with Print;
+// "go run ./cmd/runner quiz" asks questions interactively, shuffled and
+// scored, optionally only some topics or one difficulty.
+//
+// A topic file looks like this:
+//
+//	{
+//	  "topic": "maps",
+//	  "title": "Maps",
+//	  "questions": [
+//	    {
+//	      "question": "How do you check if a key exists in a map?",
+//	      "answer": ["Use the comma ok idiom: value, ok := map[key]"],
+//	      "difficulty": "easy"
+//	    }
+//	  ]
+//	}
+package quiz
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+)
+
+//go:embed questions/*.json
+var questionFiles embed.FS
+
+// Difficulty is how hard a question is
+type Difficulty string
+
+const (
+	Easy   Difficulty = "easy"
+	Medium Difficulty = "medium"
+	Hard   Difficulty = "hard"
+)
+
+// ParseDifficulty returns the difficulty named s
+func ParseDifficulty(s string) (Difficulty, error) {
+	switch d := Difficulty(strings.ToLower(s)); d {
+	case Easy, Medium, Hard:
+		return d, nil
+	}
+	return "", fmt.Errorf("quiz: unknown difficulty %q (want easy, medium or hard)", s)
+}
+
+// Question is one interview question with the points a good answer makes
+type Question struct {
+	Topic      string     `json:"-"` // the ID of the topic it belongs to
+	Question   string     `json:"question"`
+	Answer     []string   `json:"answer"`
+	Difficulty Difficulty `json:"difficulty"`
+}
+
+// Topic is the questions about one subject, from questions/<ID>.json
+type Topic struct {
+	ID        string     `json:"topic"`
+	Title     string     `json:"title"`
+	Questions []Question `json:"questions"`
+}
+
+// Topics returns the embedded topics, sorted by ID. They are loaded once;
+// callers must not change them.
+var Topics = sync.OnceValues(func() ([]Topic, error) {
+	sub, err := fs.Sub(questionFiles, "questions")
+	if err != nil {
+		return nil, err
+	}
+	return Load(sub)
+})
+
+// Load reads every *.json topic file in fsys, sorted by ID, checking each
+// is complete: a topic whose ID matches its file name and a title, and
+// questions with an answer and a known difficulty
+func Load(fsys fs.FS) ([]Topic, error) {
+	names, err := fs.Glob(fsys, "*.json")
+	if err != nil {
+		return nil, err
+	}
+	topics := make([]Topic, 0, len(names))
+	for _, name := range names {
+		t, err := loadTopic(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("quiz: %s: %w", name, err)
+		}
+		topics = append(topics, t)
+	}
+	slices.SortFunc(topics, func(a, b Topic) int { return strings.Compare(a.ID, b.ID) })
+	return topics, nil
+}
+
+func loadTopic(fsys fs.FS, name string) (Topic, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return Topic{}, err
+	}
+	var t Topic
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&t); err != nil {
+		return Topic{}, err
+	}
+	if want := strings.TrimSuffix(path.Base(name), ".json"); t.ID != want {
+		return Topic{}, fmt.Errorf("topic is %q; want %q, as the file is named", t.ID, want)
+	}
+	if t.Title == "" || len(t.Questions) == 0 {
+		return Topic{}, fmt.Errorf("topic %s needs a title and questions", t.ID)
+	}
+	for i := range t.Questions {
+		q := &t.Questions[i]
+		q.Topic = t.ID
+		if q.Question == "" || len(q.Answer) == 0 {
+			return Topic{}, fmt.Errorf("question %d needs a question and an answer", i+1)
+		}
+		switch q.Difficulty {
+		case Easy, Medium, Hard:
+		default:
+			return Topic{}, fmt.Errorf("question %d: difficulty %q is not easy, medium or hard", i+1, q.Difficulty)
+		}
+	}
+	return t, nil
+}
+
+// Filter selects questions. Its zero value selects all of them.
+type Filter struct {
+	Topics     []string   // topic IDs; none means every topic
+	Difficulty Difficulty // empty means any difficulty
+}
+
+// Select returns the questions in topics that f selects, in order. A topic
+// ID f names that is not in topics is an error.
+func Select(topics []Topic, f Filter) ([]Question, error) {
+	for _, id := range f.Topics {
+		if !slices.ContainsFunc(topics, func(t Topic) bool { return t.ID == id }) {
+			return nil, fmt.Errorf("quiz: unknown topic %q", id)
+		}
+	}
+	var out []Question
+	for _, t := range topics {
+		if len(f.Topics) > 0 && !slices.Contains(f.Topics, t.ID) {
+			continue
+		}
+		for _, q := range t.Questions {
+			if f.Difficulty == "" || q.Difficulty == f.Difficulty {
+				out = append(out, q)
+			}
+		}
+	}
+	return out, nil
+}
+
+// Print writes the questions of the topic with this ID to w, with their
+// answers, as the demos show them
+func Print(w io.Writer, id string) error {
+	topics, err := Topics()
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(topics, func(t Topic) bool { return t.ID == id })
+	if i < 0 {
+		return fmt.Errorf("quiz: unknown topic %q", id)
+	}
+
+	fmt.Fprintln(w, "=========================================")
+	fmt.Fprintln(w, "COMMON INTERVIEW QUESTIONS:")
+	fmt.Fprintln(w, "=========================================")
+	for n, q := range topics[i].Questions {
+		number := fmt.Sprintf("%d. ", n+1)
+		fmt.Fprintf(w, "%s%s\n", number, q.Question)
+		for _, point := range q.Answer {
+			fmt.Fprintf(w, "%s- %s\n", strings.Repeat(" ", len(number)), point)
+		}
+		fmt.Fprintln(w)
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package quiz
+
+import (
+	"bytes"
+	"math/rand/v2"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestTopics_Embedded(t *testing.T) {
+	topics, err := Topics()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(topics) < 20 {
+		t.Errorf("%d topics; want one per demo", len(topics))
+	}
+	for i, topic := range topics {
+		if i > 0 && topics[i-1].ID >= topic.ID {
+			t.Errorf("topics out of order: %s before %s", topics[i-1].ID, topic.ID)
+		}
+		for _, q := range topic.Questions {
+			if q.Topic != topic.ID {
+				t.Errorf("question %q has topic %q; want %q", q.Question, q.Topic, topic.ID)
+			}
+		}
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name, file, data, want string
+	}{
+		{"bad JSON", "maps.json", `{"topic": "maps",`, "maps.json: unexpected EOF"},
+		{"unknown field", "maps.json", `{"topic": "maps", "title": "Maps", "level": 1}`, `unknown field "level"`},
+		{"topic not the file name", "maps.json", `{"topic": "slices", "title": "S", "questions": []}`, `topic is "slices"; want "maps"`},
+		{"no questions", "maps.json", `{"topic": "maps", "title": "Maps", "questions": []}`, "needs a title and questions"},
+		{"no answer", "maps.json", `{"topic": "maps", "title": "Maps", "questions": [{"question": "Q?", "answer": [], "difficulty": "easy"}]}`, "question 1 needs a question and an answer"},
+		{"bad difficulty", "maps.json", `{"topic": "maps", "title": "Maps", "questions": [{"question": "Q?", "answer": ["A"], "difficulty": "Easy"}]}`, `question 1: difficulty "Easy"`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Load(fstest.MapFS{tc.file: {Data: []byte(tc.data)}})
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("error = %v; want it to contain %q", err, tc.want)
+			}
+		})
+	}
+}
+
+// testTopics has three questions: a/easy, a/hard and b/easy
+func testTopics() []Topic {
+	return []Topic{
+		{ID: "a", Title: "A", Questions: []Question{
+			{Topic: "a", Question: "a1?", Answer: []string{"x"}, Difficulty: Easy},
+			{Topic: "a", Question: "a2?", Answer: []string{"y", "z"}, Difficulty: Hard},
+		}},
+		{ID: "b", Title: "B", Questions: []Question{
+			{Topic: "b", Question: "b1?", Answer: []string{"w"}, Difficulty: Easy},
+		}},
+	}
+}
+
+func TestSelect(t *testing.T) {
+	tests := []struct {
+		filter Filter
+		want   string
+	}{
+		{Filter{}, "a1? a2? b1?"},
+		{Filter{Topics: []string{"b"}}, "b1?"},
+		{Filter{Difficulty: Easy}, "a1? b1?"},
+		{Filter{Topics: []string{"a"}, Difficulty: Hard}, "a2?"},
+		{Filter{Topics: []string{"b"}, Difficulty: Hard}, ""},
+	}
+	for _, tc := range tests {
+		qs, err := Select(testTopics(), tc.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, q := range qs {
+			got = append(got, q.Question)
+		}
+		if strings.Join(got, " ") != tc.want {
+			t.Errorf("Select(%+v) = %q; want %q", tc.filter, got, tc.want)
+		}
+	}
+	if _, err := Select(testTopics(), Filter{Topics: []string{"c"}}); err == nil || !strings.Contains(err.Error(), `unknown topic "c"`) {
+		t.Errorf("unknown topic: error = %v", err)
+	}
+}
+
+func TestParseDifficulty(t *testing.T) {
+	if d, err := ParseDifficulty("HARD"); d != Hard || err != nil {
+		t.Errorf("ParseDifficulty(HARD) = %q, %v", d, err)
+	}
+	if _, err := ParseDifficulty("tricky"); err == nil {
+		t.Error("ParseDifficulty(tricky) succeeded")
+	}
+}
+
+func TestPrint(t *testing.T) {
+	var out bytes.Buffer
+	if err := Print(&out, "maps"); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"COMMON INTERVIEW QUESTIONS:\n=========================================\n1. What is a map in Go",
+		"\n3. How do you check if a key exists in a map?\n   - Use the comma ok idiom: value, ok := map[key]\n",
+		"\n10. Can a map be used as a key in another map?\n    - No, maps are not comparable\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output lacks %q:\n%s", want, out.String())
+		}
+	}
+	if err := Print(&out, "nope"); err == nil {
+		t.Error("Print of an unknown topic succeeded")
+	}
+}
+
+func TestRun(t *testing.T) {
+	qs, _ := Select(testTopics(), Filter{})
+	tests := []struct {
+		name    string
+		input   string
+		opts    Options
+		want    Result
+		summary string
+	}{
+		{
+			"all answered",
+			"x\ny\nmaybe\nn\n\nyes\n", Options{},
+			Result{Score{3, 2}, map[string]Score{"a": {2, 1}, "b": {1, 1}}},
+			"Score: 2/3 (66%)\n  a  1/2 (50%)\n  b  1/1 (100%)\n",
+		},
+		{
+			"quit",
+			"x\ny\nx\nq\n", Options{},
+			Result{Score{1, 1}, map[string]Score{"a": {1, 1}}},
+			"Score: 1/1 (100%)\n",
+		},
+		{
+			"input ends",
+			"x\n", Options{},
+			Result{Score{}, map[string]Score{}},
+			"Score: 0/0 (0%)\n",
+		},
+		{
+			"count",
+			"x\nn\nx\nn\n", Options{Count: 1},
+			Result{Score{1, 0}, map[string]Score{"a": {1, 0}}},
+			"Score: 0/1 (0%)\n",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			got, err := Run(strings.NewReader(tc.input), &out, qs, tc.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Score != tc.want.Score || len(got.ByTopic) != len(tc.want.ByTopic) {
+				t.Errorf("result = %+v; want %+v", got, tc.want)
+			}
+			for id, s := range tc.want.ByTopic {
+				if got.ByTopic[id] != s {
+					t.Errorf("topic %s = %+v; want %+v", id, got.ByTopic[id], s)
+				}
+			}
+			if !strings.HasSuffix(out.String(), "\n"+tc.summary) {
+				t.Errorf("output ends\n%s\nwant\n%s", out.String(), tc.summary)
+			}
+		})
+	}
+}
+
+func TestRun_Shuffles(t *testing.T) {
+	qs, _ := Select(testTopics(), Filter{})
+	order := func(seed uint64) string {
+		var out bytes.Buffer
+		Run(strings.NewReader(strings.Repeat("x\ny\n", 3)), &out, qs, Options{Rand: rand.New(rand.NewPCG(seed, 0))})
+		var asked []string
+		for _, line := range strings.Split(out.String(), "\n") {
+			if strings.HasSuffix(line, "?") {
+				asked = append(asked, line)
+			}
+		}
+		return strings.Join(asked, " ")
+	}
+	if order(1) != order(1) {
+		t.Error("the same seed asked questions in different orders")
+	}
+	seen := make(map[string]bool)
+	for seed := range uint64(20) {
+		seen[order(seed)] = true
+	}
+	if len(seen) < 2 {
+		t.Errorf("20 seeds gave only the orders %v", seen)
+	}
+	if qs[0].Question != "a1?" {
+		t.Error("Run shuffled the caller's slice")
+	}
+}
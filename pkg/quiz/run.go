@@ -0,0 +1,141 @@
+package quiz
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"slices"
+	"strings"
+)
+
+// Options configure Run
+type Options struct {
+	// Count is how many questions to ask; 0 or more than there are means
+	// all of them
+	Count int
+
+	// Rand shuffles the questions; nil asks them in the order given
+	Rand *rand.Rand
+}
+
+// Score counts questions asked and answered right
+type Score struct {
+	Asked   int `json:"asked"`
+	Correct int `json:"correct"`
+}
+
+// Percent returns the share answered right, rounded down; 0 if none were
+// asked
+func (s Score) Percent() int {
+	if s.Asked == 0 {
+		return 0
+	}
+	return s.Correct * 100 / s.Asked
+}
+
+// Result is how a quiz went, overall and per topic
+type Result struct {
+	Score
+	ByTopic map[string]Score `json:"by_topic"`
+}
+
+// Add counts an answer to a question on topic. ByTopic must not be nil.
+func (r *Result) Add(topic string, correct bool) {
+	s := r.ByTopic[topic]
+	s.Asked++
+	r.Asked++
+	if correct {
+		s.Correct++
+		r.Correct++
+	}
+	r.ByTopic[topic] = s
+}
+
+// Run asks questions on out, reading replies from in, and returns the
+// score. For each question the player types an answer, is shown the
+// points a good one makes, and says whether theirs got it right: y or n,
+// or q to stop early. Answers are free text, so the player grades them;
+// the quiz only keeps count. Input ending also stops the quiz, with the
+// questions answered so far scored. A summary is written at the end.
+func Run(in io.Reader, out io.Writer, questions []Question, opts Options) (Result, error) {
+	questions = Pick(questions, opts)
+	res := Result{ByTopic: make(map[string]Score)}
+	lines := bufio.NewScanner(in)
+	for n, q := range questions {
+		fmt.Fprintf(out, "\nQuestion %d of %d [%s, %s]\n%s\n> ", n+1, len(questions), q.Topic, q.Difficulty, q.Question)
+		if !lines.Scan() {
+			break
+		}
+
+		fmt.Fprintln(out, "A good answer says:")
+		for _, point := range q.Answer {
+			fmt.Fprintf(out, "  - %s\n", point)
+		}
+		correct, ok := askRight(lines, out)
+		if !ok {
+			break
+		}
+
+		res.Add(q.Topic, correct)
+	}
+	if err := lines.Err(); err != nil {
+		return res, err
+	}
+
+	writeSummary(out, res)
+	return res, nil
+}
+
+// Pick returns the questions a quiz asks: questions shuffled by opts.Rand
+// and cut to opts.Count. The questions passed in are not changed.
+func Pick(questions []Question, opts Options) []Question {
+	questions = slices.Clone(questions)
+	if opts.Rand != nil {
+		opts.Rand.Shuffle(len(questions), func(i, j int) {
+			questions[i], questions[j] = questions[j], questions[i]
+		})
+	}
+	if opts.Count > 0 && opts.Count < len(questions) {
+		questions = questions[:opts.Count]
+	}
+	return questions
+}
+
+// askRight asks whether the answer was right until it gets y or n. It
+// returns ok false if the player quit or input ended.
+func askRight(lines *bufio.Scanner, out io.Writer) (correct, ok bool) {
+	for {
+		fmt.Fprint(out, "Did you get it right? [y/n/q] ")
+		if !lines.Scan() {
+			return false, false
+		}
+		switch strings.ToLower(strings.TrimSpace(lines.Text())) {
+		case "y", "yes":
+			return true, true
+		case "n", "no":
+			return false, true
+		case "q", "quit":
+			return false, false
+		}
+	}
+}
+
+// writeSummary writes the overall score, then each topic's
+func writeSummary(out io.Writer, res Result) {
+	fmt.Fprintf(out, "\nScore: %d/%d (%d%%)\n", res.Correct, res.Asked, res.Percent())
+	if len(res.ByTopic) < 2 {
+		return
+	}
+	topics := make([]string, 0, len(res.ByTopic))
+	width := 0
+	for id := range res.ByTopic {
+		topics = append(topics, id)
+		width = max(width, len(id))
+	}
+	slices.Sort(topics)
+	for _, id := range topics {
+		s := res.ByTopic[id]
+		fmt.Fprintf(out, "  %-*s  %d/%d (%d%%)\n", width, id, s.Correct, s.Asked, s.Percent())
+	}
+}
@@ -0,0 +1,168 @@
+// Package ratelimit limits how often something may happen with token
+// buckets. A bucket holds up to Burst tokens and refills at Rate tokens a
+// second; each event takes one token, so a client may burst up to Burst
+// events and then sustain Rate:
+//
+//	l := ratelimit.NewLimiter(ratelimit.Config{
+//		Rate:  ratelimit.Per(60, time.Minute),
+//		Burst: 10,
+//	})
+//	if res := l.Allow(clientIP); !res.Allowed {
+//		// reject, and ask the client to wait res.RetryAfter
+//	}
+//
+// A Limiter keeps one bucket per key, evicting the least recently used
+// bucket once it holds MaxKeys of them, so memory stays bounded however
+// many clients there are.
+package ratelimit
+
+import (
+	"container/list"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/clock"
+)
+
+// Rate is a refill rate in tokens per second
+type Rate float64
+
+// Per returns the rate of n events every d, e.g. Per(100, time.Minute)
+func Per(n int, d time.Duration) Rate {
+	return Rate(float64(n) / d.Seconds())
+}
+
+// Result is the outcome of one Allow call, with what rate limit response
+// headers need
+type Result struct {
+	Allowed bool
+
+	// Limit is the bucket's capacity and Remaining the whole tokens left
+	// in it after this call
+	Limit, Remaining int
+
+	// RetryAfter is how long until the next token, zero if one is left
+	RetryAfter time.Duration
+
+	// Reset is how long until the bucket is full again
+	Reset time.Duration
+}
+
+// Bucket is a single token bucket. It is not safe for concurrent use;
+// Limiter adds the locking.
+type Bucket struct {
+	rate   Rate
+	burst  int
+	tokens float64
+	last   time.Time
+}
+
+// NewBucket returns a full bucket of burst tokens refilling at rate
+func NewBucket(rate Rate, burst int, now time.Time) *Bucket {
+	return &Bucket{rate: rate, burst: burst, tokens: float64(burst), last: now}
+}
+
+// Allow takes a token if there is one
+func (b *Bucket) Allow(now time.Time) Result {
+	// Refill for the time since the last call. A clock that went
+	// backwards adds nothing rather than removing tokens.
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(float64(b.burst), b.tokens+elapsed.Seconds()*float64(b.rate))
+		b.last = now
+	}
+
+	res := Result{Limit: b.burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = b.wait(1 - b.tokens)
+	}
+	res.Remaining = int(b.tokens)
+	res.Reset = b.wait(float64(b.burst) - b.tokens)
+	return res
+}
+
+// wait returns how long refilling n tokens takes
+func (b *Bucket) wait(n float64) time.Duration {
+	if n <= 0 {
+		return 0
+	}
+	if b.rate <= 0 {
+		return math.MaxInt64
+	}
+	return time.Duration(math.Ceil(n / float64(b.rate) * float64(time.Second)))
+}
+
+// DefaultMaxKeys is the number of buckets a Limiter keeps when
+// Config.MaxKeys is zero
+const DefaultMaxKeys = 10000
+
+// Config configures a Limiter
+type Config struct {
+	Rate  Rate
+	Burst int
+
+	// MaxKeys bounds the buckets kept. An evicted key starts again with a
+	// full bucket, so set it above the number of clients active at once.
+	MaxKeys int
+
+	// Clock tells the time; nil means clock.Real. Tests pass a clock.Fake.
+	Clock clock.Clock
+}
+
+// Limiter rate limits many keys, such as client IPs, each with its own
+// bucket. It is safe for concurrent use.
+type Limiter struct {
+	cfg Config
+
+	mu      sync.Mutex
+	buckets map[string]*list.Element // values are *entry
+	lru     list.List                // most recently used at the front
+}
+
+type entry struct {
+	key    string
+	bucket *Bucket
+}
+
+// NewLimiter returns a Limiter with no buckets yet
+func NewLimiter(cfg Config) *Limiter {
+	if cfg.MaxKeys <= 0 {
+		cfg.MaxKeys = DefaultMaxKeys
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = clock.Real
+	}
+	return &Limiter{cfg: cfg, buckets: make(map[string]*list.Element)}
+}
+
+// Allow takes a token from key's bucket, creating a full one for a key it
+// has not seen or has evicted
+func (l *Limiter) Allow(key string) Result {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.cfg.Clock.Now()
+	el, ok := l.buckets[key]
+	if ok {
+		l.lru.MoveToFront(el)
+	} else {
+		el = l.lru.PushFront(&entry{key: key, bucket: NewBucket(l.cfg.Rate, l.cfg.Burst, now)})
+		l.buckets[key] = el
+		if l.lru.Len() > l.cfg.MaxKeys {
+			oldest := l.lru.Back()
+			l.lru.Remove(oldest)
+			delete(l.buckets, oldest.Value.(*entry).key)
+		}
+	}
+	return el.Value.(*entry).bucket.Allow(now)
+}
+
+// Len returns the number of buckets held
+func (l *Limiter) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.lru.Len()
+}
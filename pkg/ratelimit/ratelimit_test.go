@@ -0,0 +1,153 @@
+package ratelimit
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/clock"
+)
+
+func newFakeClock() *clock.Fake {
+	return clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+}
+
+func TestPer(t *testing.T) {
+	tests := []struct {
+		n    int
+		d    time.Duration
+		want Rate
+	}{
+		{60, time.Minute, 1},
+		{1, 2 * time.Second, 0.5},
+		{10, time.Second, 10},
+		{0, time.Second, 0},
+	}
+	for _, tc := range tests {
+		if got := Per(tc.n, tc.d); got != tc.want {
+			t.Errorf("Per(%d, %v) = %v; want %v", tc.n, tc.d, got, tc.want)
+		}
+	}
+}
+
+func TestBucket(t *testing.T) {
+	c := newFakeClock()
+	b := NewBucket(1, 3, c.Now()) // 3 at once, then one a second
+
+	steps := []struct {
+		advance time.Duration
+		want    Result
+	}{
+		{0, Result{Allowed: true, Limit: 3, Remaining: 2, Reset: time.Second}},
+		{0, Result{Allowed: true, Limit: 3, Remaining: 1, Reset: 2 * time.Second}},
+		{0, Result{Allowed: true, Limit: 3, Remaining: 0, Reset: 3 * time.Second}},
+		{0, Result{Allowed: false, Limit: 3, Remaining: 0, RetryAfter: time.Second, Reset: 3 * time.Second}},
+		{500 * time.Millisecond, Result{Allowed: false, Limit: 3, Remaining: 0, RetryAfter: 500 * time.Millisecond, Reset: 2500 * time.Millisecond}},
+		{500 * time.Millisecond, Result{Allowed: true, Limit: 3, Remaining: 0, Reset: 3 * time.Second}},
+		// A long pause refills no more than the burst
+		{time.Hour, Result{Allowed: true, Limit: 3, Remaining: 2, Reset: time.Second}},
+		// A clock that goes backwards neither refills nor drains
+		{-time.Minute, Result{Allowed: true, Limit: 3, Remaining: 1, Reset: 2 * time.Second}},
+	}
+	for i, s := range steps {
+		c.Advance(s.advance)
+		if got := b.Allow(c.Now()); got != s.want {
+			t.Errorf("step %d: Allow() = %+v; want %+v", i, got, s.want)
+		}
+	}
+}
+
+func TestBucket_ZeroRate(t *testing.T) {
+	c := newFakeClock()
+	b := NewBucket(0, 1, c.Now())
+	b.Allow(c.Now())
+	c.Advance(time.Hour)
+	if got := b.Allow(c.Now()); got.Allowed || got.RetryAfter <= 0 {
+		t.Errorf("Allow() = %+v; want a rejection with no refill in sight", got)
+	}
+}
+
+func TestLimiter_KeysAreIndependent(t *testing.T) {
+	c := newFakeClock()
+	l := NewLimiter(Config{Rate: Per(2, time.Minute), Burst: 2, Clock: c})
+
+	for i := range 2 {
+		if !l.Allow("a").Allowed {
+			t.Fatalf("a: request %d rejected", i+1)
+		}
+	}
+	if l.Allow("a").Allowed {
+		t.Error("a: third request allowed")
+	}
+	if !l.Allow("b").Allowed {
+		t.Error("b was limited by a's requests")
+	}
+
+	// Two a minute refills one token every 30 seconds
+	c.Advance(30 * time.Second)
+	if res := l.Allow("a"); !res.Allowed {
+		t.Errorf("a after 30s: %+v; want allowed", res)
+	}
+}
+
+func TestLimiter_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := newFakeClock()
+	l := NewLimiter(Config{Rate: Per(1, time.Hour), Burst: 1, MaxKeys: 2, Clock: c})
+
+	l.Allow("a")
+	l.Allow("b")
+	l.Allow("a") // rejected, but makes b the least recently used
+	l.Allow("c") // evicts b
+
+	if got := l.Len(); got != 2 {
+		t.Errorf("Len() = %d; want 2", got)
+	}
+	if l.Allow("a").Allowed {
+		t.Error("a was evicted; want b evicted as least recently used")
+	}
+	if !l.Allow("b").Allowed {
+		t.Error("b kept its empty bucket; want it evicted and back with a full one")
+	}
+}
+
+func TestLimiter_Concurrent(t *testing.T) {
+	l := NewLimiter(Config{Rate: 0, Burst: 100, MaxKeys: 4, Clock: newFakeClock()})
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	allowed := 0
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				if l.Allow("shared").Allowed {
+					mu.Lock()
+					allowed++
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if allowed != 100 {
+		t.Errorf("allowed %d of 500 requests; want exactly the burst of 100", allowed)
+	}
+}
+
+func ExampleLimiter() {
+	l := NewLimiter(Config{
+		Rate:  Per(1, time.Second),
+		Burst: 2,
+		Clock: newFakeClock(),
+	})
+	for range 3 {
+		res := l.Allow("203.0.113.7")
+		fmt.Println(res.Allowed, res.Remaining, res.RetryAfter)
+	}
+	// Output:
+	// true 1 0s
+	// true 0 0s
+	// false 0 1s
+}
@@ -0,0 +1,125 @@
+// Package shardmap is a map safe for concurrent use, split into shards
+// that each have their own lock. A map behind one sync.RWMutex makes
+// every writer wait for every other; here goroutines only wait for those
+// using keys in the same shard, so contention falls as shards are added:
+//
+//	m := shardmap.New[int](32)
+//	m.Set("visits", 1)
+//	m.Update("visits", func(n int, _ bool) int { return n + 1 })
+//
+// sync.Map suits keys written once and read many times; a sharded map
+// suits keys that are written often by many goroutines.
+package shardmap
+
+import (
+	"hash/maphash"
+	"iter"
+	"sync"
+)
+
+// Map maps strings to values of type V. The zero value is not usable;
+// call New.
+type Map[V any] struct {
+	seed   maphash.Seed
+	shards []shard[V]
+}
+
+type shard[V any] struct {
+	mu sync.RWMutex
+	m  map[string]V
+}
+
+// New returns an empty map with n shards, at least 1. A few times
+// GOMAXPROCS is a good n.
+func New[V any](n int) *Map[V] {
+	m := &Map[V]{seed: maphash.MakeSeed(), shards: make([]shard[V], max(n, 1))}
+	for i := range m.shards {
+		m.shards[i].m = make(map[string]V)
+	}
+	return m
+}
+
+// shard returns the shard that holds key
+func (m *Map[V]) shard(key string) *shard[V] {
+	return &m.shards[maphash.String(m.seed, key)%uint64(len(m.shards))]
+}
+
+// Get returns the value for key and whether there is one
+func (m *Map[V]) Get(key string) (V, bool) {
+	s := m.shard(key)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v, ok := s.m[key]
+	return v, ok
+}
+
+// Set sets the value for key
+func (m *Map[V]) Set(key string, v V) {
+	s := m.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m[key] = v
+}
+
+// Delete removes key and reports whether it was there
+func (m *Map[V]) Delete(key string) bool {
+	s := m.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.m[key]
+	delete(s.m, key)
+	return ok
+}
+
+// Update sets key to what fn returns for its current value, and whether
+// there is one, and returns the new value. No other call changes key in
+// between, so Update can increment a counter that Get then Set would lose
+// updates to. fn must not use the map.
+func (m *Map[V]) Update(key string, fn func(v V, ok bool) V) V {
+	s := m.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.m[key]
+	v = fn(v, ok)
+	s.m[key] = v
+	return v
+}
+
+// Len returns the number of keys. While other goroutines write, it is a
+// count of each shard at a slightly different moment.
+func (m *Map[V]) Len() int {
+	n := 0
+	for i := range m.shards {
+		s := &m.shards[i]
+		s.mu.RLock()
+		n += len(s.m)
+		s.mu.RUnlock()
+	}
+	return n
+}
+
+// All returns an iterator over the keys and values, in no particular
+// order. It locks one shard at a time, reading, so the loop body may not
+// write to the map.
+func (m *Map[V]) All() iter.Seq2[string, V] {
+	return func(yield func(string, V) bool) {
+		for i := range m.shards {
+			if !m.shards[i].each(yield) {
+				return
+			}
+		}
+	}
+}
+
+// each calls yield for the shard's entries until it returns false, and
+// reports whether it never did
+func (s *shard[V]) each(yield func(string, V) bool) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for k, v := range s.m {
+		if !yield(k, v) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+package shardmap
+
+import (
+	"fmt"
+	"maps"
+	"sync"
+	"testing"
+)
+
+func TestMap(t *testing.T) {
+	m := New[int](4)
+	if _, ok := m.Get("a"); ok {
+		t.Error(`Get("a") on an empty map: ok = true`)
+	}
+	m.Set("a", 1)
+	m.Set("b", 2)
+	if v, ok := m.Get("a"); v != 1 || !ok {
+		t.Errorf(`Get("a") = %d, %v; want 1, true`, v, ok)
+	}
+	if got := m.Update("a", func(v int, ok bool) int { return v + 10 }); got != 11 {
+		t.Errorf(`Update("a") = %d; want 11`, got)
+	}
+	if got := m.Update("c", func(v int, ok bool) int {
+		if ok {
+			t.Error(`Update("c"): ok = true for a new key`)
+		}
+		return 3
+	}); got != 3 {
+		t.Errorf(`Update("c") = %d; want 3`, got)
+	}
+	if !m.Delete("b") || m.Delete("b") {
+		t.Error(`Delete("b") twice did not report true, then false`)
+	}
+	if n := m.Len(); n != 2 {
+		t.Errorf("Len() = %d; want 2", n)
+	}
+	if got, want := maps.Collect(m.All()), map[string]int{"a": 11, "c": 3}; !maps.Equal(got, want) {
+		t.Errorf("All() = %v; want %v", got, want)
+	}
+}
+
+func TestMap_AllStopsEarly(t *testing.T) {
+	m := New[int](8)
+	for i := range 100 {
+		m.Set(fmt.Sprint(i), i)
+	}
+	n := 0
+	for range m.All() {
+		if n++; n == 5 {
+			break
+		}
+	}
+	// Breaking out unlocks the shard, so this does not deadlock
+	m.Set("after", 0)
+	if n != 5 {
+		t.Errorf("loop ran %d times; want 5", n)
+	}
+}
+
+func TestMap_ConcurrentUpdates(t *testing.T) {
+	m := New[int](0) // one shard: the most contention
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				m.Update("n", func(v int, _ bool) int { return v + 1 })
+			}
+		}()
+	}
+	wg.Wait()
+	if v, _ := m.Get("n"); v != 5000 {
+		t.Errorf(`Get("n") = %d; want 5000, no update lost`, v)
+	}
+}
+
+func BenchmarkMap_Set(b *testing.B) {
+	for _, shards := range []int{1, 32} {
+		b.Run(fmt.Sprintf("shards=%d", shards), func(b *testing.B) {
+			m := New[int](shards)
+			keys := make([]string, 1024)
+			for i := range keys {
+				keys[i] = fmt.Sprint(i)
+			}
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					m.Set(keys[i%len(keys)], i)
+					i++
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,65 @@
+//go:build stress
+
+package shardmap
+
+import (
+	"math/rand/v2"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/rehan/go-interview-prep/pkg/testutil/stress"
+)
+
+// TestStress_Map mixes every operation on a small set of shared keys, so
+// goroutines collide on shards and keys constantly. Counters track what
+// must come out: Update never loses an increment, and a key's value is
+// always one some goroutine wrote.
+func TestStress_Map(t *testing.T) {
+	const keys = 64
+	m := New[int64](8)
+	var increments [keys]atomic.Int64 // per counter key
+	var sets, deletes atomic.Int64
+
+	stress.Run(t, stress.Config{}, func(r *rand.Rand) {
+		k := r.IntN(keys)
+		counter, plain := "counter:"+strconv.Itoa(k), "key:"+strconv.Itoa(k)
+		switch r.IntN(10) {
+		case 0, 1, 2:
+			m.Update(counter, func(v int64, _ bool) int64 { return v + 1 })
+			increments[k].Add(1)
+		case 3, 4:
+			// Values of plain keys are always their key's number
+			m.Set(plain, int64(k))
+			sets.Add(1)
+		case 5:
+			if m.Delete(plain) {
+				deletes.Add(1)
+			}
+		case 6, 7, 8:
+			if v, ok := m.Get(plain); ok && v != int64(k) {
+				t.Errorf("Get(%q) = %d; no goroutine wrote that", plain, v)
+			}
+		case 9:
+			if n := m.Len(); n > 2*keys {
+				t.Errorf("Len() = %d; there are only %d keys", n, 2*keys)
+			}
+		}
+	})
+
+	for k := range keys {
+		counter := "counter:" + strconv.Itoa(k)
+		v, _ := m.Get(counter)
+		if want := increments[k].Load(); v != want {
+			t.Errorf("Get(%q) = %d; want %d, every Update counted", counter, v, want)
+		}
+	}
+	n := 0
+	for range m.All() {
+		n++
+	}
+	if l := m.Len(); l != n {
+		t.Errorf("Len() = %d but All yields %d keys", l, n)
+	}
+	t.Logf("%d sets, %d deletes", sets.Load(), deletes.Load())
+}
@@ -0,0 +1,73 @@
+// Package golden compares a test's output with a file in testdata, for
+// output such as rendered templates, generated code and exports that is
+// easier to review as a file than as a string in the test:
+//
+//	golden.Assert(t, "report", buf.Bytes()) // testdata/report.golden
+//
+// When the output changes on purpose, "go test -update" rewrites the files
+// and the change shows up in the diff to review.
+package golden
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// Updating reports whether the tests were run with -update, for tests that
+// keep files of their own rather than use Assert
+func Updating() bool {
+	return *update
+}
+
+// Path returns the file that Assert compares name with
+func Path(name string) string {
+	return filepath.Join("testdata", name+".golden")
+}
+
+// Assert fails t unless got is what Path(name) holds. With -update it
+// writes got to the file first, creating testdata if need be.
+func Assert(t testing.TB, name string, got []byte) {
+	t.Helper()
+	path := Path(name)
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading golden file: %v (run go test -update to create it)", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output does not match %s (run go test -update to accept it)\n%s\ngot:\n%s\nwant:\n%s", path, firstDiff(got, want), got, want)
+	}
+}
+
+// firstDiff describes the first line where got and want differ, which
+// can be hard to spot in long output
+func firstDiff(got, want []byte) string {
+	gotLines := bytes.SplitAfter(got, []byte("\n"))
+	wantLines := bytes.SplitAfter(want, []byte("\n"))
+	for i := range max(len(gotLines), len(wantLines)) {
+		var g, w []byte
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if !bytes.Equal(g, w) {
+			return fmt.Sprintf("first difference at line %d:\n  got:  %q\n  want: %q", i+1, g, w)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,59 @@
+package golden
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/rehan/go-interview-prep/pkg/internal/tbtest"
+)
+
+func TestAssert(t *testing.T) {
+	tests := []struct {
+		name, got string
+		wantError string
+	}{
+		{"same", "line one\nline two\n", ""},
+		{"changed line", "line one\nline 2\n", `first difference at line 2:
+  got:  "line 2\n"
+  want: "line two\n"`},
+		{"extra line", "line one\nline two\nline three\n", `first difference at line 3:
+  got:  "line three\n"
+  want: ""`},
+		{"missing newline", "line one\nline two", `first difference at line 2:
+  got:  "line two"
+  want: "line two\n"`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &tbtest.Recorder{TB: t}
+			Assert(r, "two_lines", []byte(tc.got))
+			switch {
+			case tc.wantError == "" && len(r.Errors) > 0:
+				t.Errorf("errors %q; want none", r.Errors)
+			case tc.wantError != "" && (len(r.Errors) != 1 || !strings.Contains(r.Errors[0], tc.wantError)):
+				t.Errorf("errors %q; want one containing %q", r.Errors, tc.wantError)
+			}
+		})
+	}
+}
+
+func TestAssert_Update(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	*update = true
+	defer func() {
+		*update = false
+		os.Chdir(wd)
+	}()
+
+	Assert(t, "sub/new", []byte("created\n"))
+	if data, err := os.ReadFile("testdata/sub/new.golden"); err != nil || string(data) != "created\n" {
+		t.Errorf("golden file = %q, %v; want it written", data, err)
+	}
+}
@@ -0,0 +1,208 @@
+// Package httptestx is for end-to-end tests of HTTP APIs that run the
+// handler in-process. A Client sends requests built with NewRequest as
+// users it has logged in, and a Response has assertions that fail the
+// test:
+//
+//	c := httptestx.NewClient(t, router)
+//	c.LoginBearer("alice", "/auth/login", map[string]string{"username": "alice", "password": "wonderland"}, "token")
+//	c.DoAs("alice", httptestx.NewRequest(http.MethodPost, "/books").JSON(book)).
+//		AssertStatus(http.StatusCreated).
+//		AssertJSON(`{"id": 4, "title": "Learning Go", "created_at": "<any>", ...}`)
+//
+// A Scenario puts such requests and the responses expected to them in a
+// table of steps, the later steps using values saved from earlier
+// responses.
+package httptestx
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Request builds a request to a handler under test
+type Request struct {
+	method, path string
+	header       http.Header
+	body         []byte
+}
+
+// NewRequest starts a request with no body
+func NewRequest(method, path string) *Request {
+	return &Request{method: method, path: path, header: http.Header{}}
+}
+
+// Header sets a request header
+func (r *Request) Header(name, value string) *Request {
+	r.header.Set(name, value)
+	return r
+}
+
+// Bearer authorizes the request with a bearer token
+func (r *Request) Bearer(token string) *Request {
+	return r.Header("Authorization", "Bearer "+token)
+}
+
+// Body sets the body and its Content-Type
+func (r *Request) Body(contentType, body string) *Request {
+	r.body = []byte(body)
+	return r.Header("Content-Type", contentType)
+}
+
+// JSON sets the body to v encoded as JSON; a string or json.RawMessage is
+// sent as it is. It panics if v cannot be encoded, which is a mistake in
+// the test.
+func (r *Request) JSON(v any) *Request {
+	var body []byte
+	switch v := v.(type) {
+	case string:
+		body = []byte(v)
+	case json.RawMessage:
+		body = v
+	default:
+		var err error
+		if body, err = json.Marshal(v); err != nil {
+			panic(fmt.Sprintf("httptestx: encoding request body: %v", err))
+		}
+	}
+	return r.Body("application/json", string(body))
+}
+
+// Build returns the request as an *http.Request
+func (r *Request) Build() *http.Request {
+	req := httptest.NewRequest(r.method, r.path, bytes.NewReader(r.body))
+	for name, values := range r.header {
+		req.Header[name] = values
+	}
+	return req
+}
+
+// Client sends requests to a handler, without a network, as anonymous
+// callers or as the users registered with As or LoginBearer
+type Client struct {
+	t       testing.TB
+	handler http.Handler
+	users   map[string]http.Header
+}
+
+// NewClient returns a client of handler that fails t on errors
+func NewClient(t testing.TB, handler http.Handler) *Client {
+	return &Client{t: t, handler: handler, users: make(map[string]http.Header)}
+}
+
+// As registers user as a caller whose requests carry header
+func (c *Client) As(user string, header http.Header) {
+	c.users[user] = header
+}
+
+// LoginBearer logs user in by posting credentials as JSON to path, and
+// registers them as a caller sending the bearer token found at tokenPath
+// in the response, a path as Lookup takes
+func (c *Client) LoginBearer(user, path string, credentials any, tokenPath string) {
+	c.t.Helper()
+	token, ok := c.Do(NewRequest(http.MethodPost, path).JSON(credentials)).
+		AssertStatus(http.StatusOK).
+		Value(tokenPath).(string)
+	if !ok || token == "" {
+		c.t.Fatalf("logging in %s: no token at %s", user, tokenPath)
+	}
+	c.As(user, http.Header{"Authorization": {"Bearer " + token}})
+}
+
+// Do sends r anonymously
+func (c *Client) Do(r *Request) *Response {
+	c.t.Helper()
+	return c.DoAs("", r)
+}
+
+// DoAs sends r as user, or anonymously if user is ""
+func (c *Client) DoAs(user string, r *Request) *Response {
+	c.t.Helper()
+	req := r.Build()
+	if user != "" {
+		header, ok := c.users[user]
+		if !ok {
+			c.t.Fatalf("no user %q; register them with As or LoginBearer", user)
+		}
+		for name, values := range header {
+			req.Header[name] = values
+		}
+	}
+	rr := httptest.NewRecorder()
+	c.handler.ServeHTTP(rr, req)
+	return &Response{t: c.t, req: req, rr: rr}
+}
+
+// Response is a handler's response, with assertions that fail the test
+type Response struct {
+	t   testing.TB
+	req *http.Request
+	rr  *httptest.ResponseRecorder
+}
+
+// Code returns the status code
+func (r *Response) Code() int {
+	return r.rr.Code
+}
+
+// Header returns the response headers
+func (r *Response) Header() http.Header {
+	return r.rr.Header()
+}
+
+// Body returns the response body
+func (r *Response) Body() []byte {
+	return r.rr.Body.Bytes()
+}
+
+// AssertStatus stops the test unless the status is want, as the rest of
+// a response is seldom worth checking after a wrong status
+func (r *Response) AssertStatus(want int) *Response {
+	r.t.Helper()
+	if r.rr.Code != want {
+		r.t.Fatalf("%s %s = %d; want %d (body: %s)", r.req.Method, r.req.URL, r.rr.Code, want, r.rr.Body.Bytes())
+	}
+	return r
+}
+
+// AssertHeader fails the test unless the header name is want
+func (r *Response) AssertHeader(name, want string) *Response {
+	r.t.Helper()
+	if got := r.rr.Header().Get(name); got != want {
+		r.t.Errorf("%s %s: %s = %q; want %q", r.req.Method, r.req.URL, name, got, want)
+	}
+	return r
+}
+
+// AssertJSON fails the test unless the body matches the pattern want, as
+// MatchJSON does
+func (r *Response) AssertJSON(want string) *Response {
+	r.t.Helper()
+	if err := MatchJSON(r.rr.Body.Bytes(), want); err != nil {
+		r.t.Errorf("%s %s: %v\nbody: %s", r.req.Method, r.req.URL, err, r.rr.Body.Bytes())
+	}
+	return r
+}
+
+// Decode decodes the JSON body into v, stopping the test if it cannot
+func (r *Response) Decode(v any) {
+	r.t.Helper()
+	if err := json.NewDecoder(bytes.NewReader(r.rr.Body.Bytes())).Decode(v); err != nil && err != io.EOF {
+		r.t.Fatalf("%s %s: decoding body: %v (body: %s)", r.req.Method, r.req.URL, err, r.rr.Body.Bytes())
+	}
+}
+
+// Value returns the value at path in the JSON body, stopping the test if
+// there is none
+func (r *Response) Value(path string) any {
+	r.t.Helper()
+	v, err := Lookup(r.rr.Body.Bytes(), path)
+	if err != nil {
+		r.t.Fatalf("%s %s: %v (body: %s)", r.req.Method, r.req.URL, err, r.rr.Body.Bytes())
+	}
+	return v
+}
@@ -0,0 +1,158 @@
+package httptestx
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestMatchJSON(t *testing.T) {
+	tests := []struct {
+		name      string
+		got, want string
+		wantErr   string
+	}{
+		{"equal", `{"id": 1, "tags": ["a", "b"]}`, `{"tags": ["a", "b"], "id": 1.0}`, ""},
+		{"any", `{"id": 7, "created_at": "2024-01-01T00:00:00Z", "note": null}`, `{"id": "<any>", "created_at": "<any>", "note": "<any>"}`, ""},
+		{"wrong value", `{"books": [{"title": "A"}, {"title": "B"}]}`, `{"books": [{"title": "A"}, {"title": "C"}]}`, `$.books[1].title = "B"; want "C"`},
+		{"wrong type", `{"id": "1"}`, `{"id": 1}`, `$.id = "1"; want 1`},
+		{"missing key", `{"id": 1}`, `{"id": 1, "title": "A"}`, "$.title is missing"},
+		{"unexpected key", `{"id": 1, "title": "A"}`, `{"id": 1}`, `$.title is unexpected: "A"`},
+		{"length", `[1, 2, 3]`, `[1, 2]`, "$ has 3 elements; want 2"},
+		{"not JSON", `<html>`, `{}`, "body is not JSON"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := MatchJSON([]byte(tc.got), tc.want)
+			switch {
+			case tc.wantErr == "" && err != nil:
+				t.Errorf("MatchJSON = %v; want nil", err)
+			case tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)):
+				t.Errorf("MatchJSON = %v; want an error containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	data := []byte(`{"books": [{"id": 1, "title": "A"}, {"id": 2}], "total": 2}`)
+	tests := []struct {
+		path    string
+		want    any
+		wantErr string
+	}{
+		{"total", 2.0, ""},
+		{"books.1.id", 2.0, ""},
+		{"books.0.title", "A", ""},
+		{"books.2.id", nil, "no books.2 in the body"},
+		{"books.x", nil, "no books.x in the body"},
+		{"total.count", nil, "no total.count in the body"},
+	}
+	for _, tc := range tests {
+		got, err := Lookup(data, tc.path)
+		if tc.wantErr != "" {
+			if err == nil || err.Error() != tc.wantErr {
+				t.Errorf("Lookup(%q) error = %v; want %q", tc.path, err, tc.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tc.want {
+			t.Errorf("Lookup(%q) = %v, %v; want %v", tc.path, got, err, tc.want)
+		}
+	}
+}
+
+// notes is a handler storing notes, writable by callers sending the
+// token that POST /login hands out
+func notes() http.Handler {
+	var mu sync.Mutex
+	var saved []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /login", func(w http.ResponseWriter, r *http.Request) {
+		var creds struct{ User string }
+		json.NewDecoder(r.Body).Decode(&creds)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"session": {"token": "t-%s"}}`, creds.User)
+	})
+	mux.HandleFunc("POST /notes", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer t-ann" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var note struct{ Text string }
+		if err := json.NewDecoder(r.Body).Decode(&note); err != nil || r.Header.Get("Content-Type") != "application/json" {
+			http.Error(w, "bad note", http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		saved = append(saved, note.Text)
+		id := len(saved)
+		mu.Unlock()
+		w.Header().Set("Location", fmt.Sprintf("/notes/%d", id))
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"id": %d, "text": %q}`, id, note.Text)
+	})
+	mux.HandleFunc("GET /notes/{id}", func(w http.ResponseWriter, r *http.Request) {
+		var id int
+		fmt.Sscan(r.PathValue("id"), &id)
+		mu.Lock()
+		defer mu.Unlock()
+		if id < 1 || id > len(saved) {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, `{"id": %d, "text": %q}`, id, saved[id-1])
+	})
+	return mux
+}
+
+func TestClient(t *testing.T) {
+	c := NewClient(t, notes())
+	c.LoginBearer("ann", "/login", map[string]string{"user": "ann"}, "session.token")
+
+	c.Do(NewRequest(http.MethodPost, "/notes").JSON(`{"text": "hi"}`)).AssertStatus(http.StatusUnauthorized)
+	resp := c.DoAs("ann", NewRequest(http.MethodPost, "/notes").JSON(map[string]string{"text": "hi"})).
+		AssertStatus(http.StatusCreated).
+		AssertHeader("Location", "/notes/1").
+		AssertJSON(`{"id": 1, "text": "hi"}`)
+	var note struct {
+		ID   int
+		Text string
+	}
+	resp.Decode(&note)
+	if note.ID != 1 || note.Text != "hi" {
+		t.Errorf("decoded %+v; want note 1, hi", note)
+	}
+
+	c.Do(NewRequest(http.MethodPost, "/notes").Bearer("t-ann").Body("text/plain", "hi")).AssertStatus(http.StatusBadRequest)
+}
+
+func TestRunScenario(t *testing.T) {
+	c := NewClient(t, notes())
+	c.LoginBearer("ann", "/login", map[string]string{"user": "ann"}, "session.token")
+	RunScenario(t, c, Scenario{
+		Name: "write and read back",
+		Steps: []Step{
+			{
+				As: "ann", Method: http.MethodPost, Path: "/notes", Body: map[string]string{"text": "first"},
+				Status: http.StatusCreated, JSON: `{"id": 1, "text": "first"}`,
+			},
+			{
+				Name: "second", As: "ann", Method: http.MethodPost, Path: "/notes", Body: `{"text": "second"}`,
+				Status: http.StatusCreated, Save: map[string]string{"id": "id", "text": "text"},
+			},
+			{
+				Name: "saved values", As: "ann", Method: http.MethodPost, Path: "/notes", Body: `{"text": "after {{text}}"}`,
+				Status:  http.StatusCreated,
+				Headers: map[string]string{"Location": "/notes/3"},
+			},
+			{
+				Name: "read back", Method: http.MethodGet, Path: "/notes/{{id}}",
+				Status: http.StatusOK, JSON: `{"id": {{id}}, "text": "second"}`,
+			},
+		},
+	})
+}
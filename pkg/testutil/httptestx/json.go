@@ -0,0 +1,123 @@
+package httptestx
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Any, as a string in a JSON pattern, matches any value, for fields such
+// as IDs and times that a test cannot predict
+const Any = "<any>"
+
+// MatchJSON returns an error saying where got differs from the JSON
+// pattern want, or nil if it matches. Objects match if they have the
+// same keys and their values match, so a pattern lists every field, and
+// numbers match by value, so 1 matches 1.0. The string "<any>" matches
+// anything, nulls included.
+func MatchJSON(got []byte, want string) error {
+	var g, w any
+	if err := json.Unmarshal(got, &g); err != nil {
+		return fmt.Errorf("body is not JSON: %v", err)
+	}
+	if err := json.Unmarshal([]byte(want), &w); err != nil {
+		// A broken pattern is the test's mistake
+		panic(fmt.Sprintf("httptestx: pattern is not JSON: %v", err))
+	}
+	return match("$", g, w)
+}
+
+func match(path string, got, want any) error {
+	if want == Any {
+		return nil
+	}
+	switch want := want.(type) {
+	case map[string]any:
+		got, ok := got.(map[string]any)
+		if !ok {
+			return mismatch(path, got, want)
+		}
+		for _, key := range sortedKeys(want) {
+			v, ok := got[key]
+			if !ok {
+				return fmt.Errorf("%s.%s is missing", path, key)
+			}
+			if err := match(path+"."+key, v, want[key]); err != nil {
+				return err
+			}
+		}
+		for _, key := range sortedKeys(got) {
+			if _, ok := want[key]; !ok {
+				return fmt.Errorf("%s.%s is unexpected: %s", path, key, encode(got[key]))
+			}
+		}
+		return nil
+	case []any:
+		got, ok := got.([]any)
+		if !ok {
+			return mismatch(path, got, want)
+		}
+		if len(got) != len(want) {
+			return fmt.Errorf("%s has %d elements; want %d", path, len(got), len(want))
+		}
+		for i := range want {
+			if err := match(fmt.Sprintf("%s[%d]", path, i), got[i], want[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if !reflect.DeepEqual(got, want) {
+		return mismatch(path, got, want)
+	}
+	return nil
+}
+
+func mismatch(path string, got, want any) error {
+	return fmt.Errorf("%s = %s; want %s", path, encode(got), encode(want))
+}
+
+func encode(v any) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// sortedKeys returns the keys of m in order, so that of several
+// differences the same one is reported every run
+func sortedKeys(m map[string]any) []string {
+	return slices.Sorted(maps.Keys(m))
+}
+
+// Lookup returns the value at path in a JSON document. A path is object
+// keys and array indexes joined by dots, such as "books.0.title"; ""
+// is the whole document. Numbers are float64s, as encoding/json decodes
+// them into an any.
+func Lookup(data []byte, path string) (any, error) {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("body is not JSON: %v", err)
+	}
+	if path == "" {
+		return v, nil
+	}
+	parts := strings.Split(path, ".")
+	for i, part := range parts {
+		found := false
+		switch node := v.(type) {
+		case map[string]any:
+			v, found = node[part]
+		case []any:
+			if n, err := strconv.Atoi(part); err == nil && n >= 0 && n < len(node) {
+				v, found = node[n], true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no %s in the body", strings.Join(parts[:i+1], "."))
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,110 @@
+package httptestx
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"testing"
+)
+
+// Step is one request of a Scenario and the response expected to it.
+// In the request, and in the JSON and headers expected, {{name}} stands
+// for a value saved by an earlier step.
+type Step struct {
+	Name   string
+	As     string // the user sending the request, or "" for no one
+	Method string
+	Path   string
+	Header map[string]string
+	Body   any // sent as JSON, as Request.JSON sends it
+
+	Status  int               // the expected status
+	JSON    string            // if set, a pattern the body must match, as MatchJSON takes
+	Headers map[string]string // expected response headers
+	Save    map[string]string // names for values of the body, by the path Lookup takes
+}
+
+// Scenario is a sequence of steps, each depending on those before it,
+// such as creating a book and then reading it back
+type Scenario struct {
+	Name  string
+	Steps []Step
+}
+
+// RunScenario runs the steps of s in order, each as a subtest of a
+// subtest named s.Name. The steps after a failed one are skipped, as
+// they would fail for its reason.
+func RunScenario(t *testing.T, c *Client, s Scenario) {
+	t.Helper()
+	t.Run(s.Name, func(t *testing.T) {
+		vars := make(map[string]string)
+		for i, step := range s.Steps {
+			name := step.Name
+			if name == "" {
+				name = fmt.Sprintf("%d %s %s", i+1, step.Method, step.Path)
+			}
+			if !t.Run(name, func(t *testing.T) { runStep(t, c, step, vars) }) && i < len(s.Steps)-1 {
+				t.Fatalf("step %q failed; skipping the rest", name)
+			}
+		}
+	})
+}
+
+func runStep(t *testing.T, parent *Client, step Step, vars map[string]string) {
+	t.Helper()
+	// A client of this subtest, so that its failures are reported here
+	c := &Client{t: t, handler: parent.handler, users: parent.users}
+
+	req := NewRequest(step.Method, expand(t, step.Path, vars))
+	if step.Body != nil {
+		body, ok := step.Body.(string)
+		if !ok {
+			data, err := json.Marshal(step.Body)
+			if err != nil {
+				t.Fatalf("encoding request body: %v", err)
+			}
+			body = string(data)
+		}
+		req.JSON(expand(t, body, vars))
+	}
+	for name, value := range step.Header {
+		req.Header(name, expand(t, value, vars))
+	}
+
+	resp := c.DoAs(step.As, req).AssertStatus(step.Status)
+	for name, want := range step.Headers {
+		resp.AssertHeader(name, expand(t, want, vars))
+	}
+	if step.JSON != "" {
+		resp.AssertJSON(expand(t, step.JSON, vars))
+	}
+	for name, path := range step.Save {
+		vars[name] = format(resp.Value(path))
+	}
+}
+
+var placeholder = regexp.MustCompile(`\{\{(\w+)\}\}`)
+
+// expand replaces the {{name}}s in s with saved values, stopping the test
+// at one never saved
+func expand(t *testing.T, s string, vars map[string]string) string {
+	t.Helper()
+	return placeholder.ReplaceAllStringFunc(s, func(m string) string {
+		name := placeholder.FindStringSubmatch(m)[1]
+		v, ok := vars[name]
+		if !ok {
+			t.Fatalf("{{%s}} was not saved by an earlier step", name)
+		}
+		return v
+	})
+}
+
+// format returns v as it goes into a path or header: strings as they are
+// and everything else, such as the float64 of an ID, as JSON
+func format(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
@@ -0,0 +1,186 @@
+// Package middlewaretest has the pieces unit tests of HTTP middleware,
+// functions of the form func(http.Handler) http.Handler, keep writing by
+// hand: a handler to wrap that records what reached it, one that panics,
+// a writer that captures what was written through it, and assertions on
+// response headers:
+//
+//	next := &middlewaretest.RecordingHandler{Body: "OK"}
+//	rr := middlewaretest.Serve(CORSMiddleware(next), httptest.NewRequest("OPTIONS", "/", nil))
+//	next.AssertCalls(t, 0) // a preflight is answered before the handler
+//	middlewaretest.AssertHeaders(t, rr.Header(), map[string]string{"Access-Control-Allow-Origin": "*"})
+//
+// It depends only on the standard library, so any package's tests can
+// use it.
+package middlewaretest
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// RecordingHandler is the handler behind the middleware under test. It
+// records the requests that reach it and answers each with Header, Status
+// and Body. It is safe for concurrent use.
+type RecordingHandler struct {
+	Status int         // 0 means 200
+	Body   string      // written after the status
+	Header http.Header // set on every response
+
+	mu       sync.Mutex
+	requests []*http.Request
+}
+
+// ServeHTTP records r and writes the response
+func (h *RecordingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	h.requests = append(h.requests, r)
+	h.mu.Unlock()
+
+	for name, values := range h.Header {
+		w.Header()[name] = values
+	}
+	status := h.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	io.WriteString(w, h.Body)
+}
+
+// Calls returns how many requests reached the handler
+func (h *RecordingHandler) Calls() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.requests)
+}
+
+// Requests returns the requests that reached the handler, in order, as
+// the middleware passed them on: with the headers and context values it
+// added
+func (h *RecordingHandler) Requests() []*http.Request {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]*http.Request(nil), h.requests...)
+}
+
+// LastRequest returns the last request that reached the handler, failing
+// t if none did
+func (h *RecordingHandler) LastRequest(t testing.TB) *http.Request {
+	t.Helper()
+	requests := h.Requests()
+	if len(requests) == 0 {
+		t.Fatal("no request reached the handler")
+	}
+	return requests[len(requests)-1]
+}
+
+// AssertCalls fails t unless want requests reached the handler; 0 checks
+// that the middleware stopped the request
+func (h *RecordingHandler) AssertCalls(t testing.TB, want int) {
+	t.Helper()
+	if got := h.Calls(); got != want {
+		t.Errorf("handler called %d times; want %d", got, want)
+	}
+}
+
+// PanicHandler returns a handler that panics with v, for testing recovery
+// middleware
+func PanicHandler(v any) http.Handler {
+	return http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic(v)
+	})
+}
+
+// StatusCapturingWriter wraps a ResponseWriter and records what is written
+// through it. A test middleware wrapping the writer it passes on sees the
+// status the inner layers chose, before any outer layer changes it, and
+// WriteHeaderCalls shows a layer writing a second status, which net/http
+// ignores with a "superfluous WriteHeader" log.
+type StatusCapturingWriter struct {
+	http.ResponseWriter
+
+	Status           int   // the first status written, 200 if Write came first, 0 if neither
+	Bytes            int64 // body bytes written
+	WriteHeaderCalls int
+}
+
+// NewStatusCapturingWriter wraps w
+func NewStatusCapturingWriter(w http.ResponseWriter) *StatusCapturingWriter {
+	return &StatusCapturingWriter{ResponseWriter: w}
+}
+
+// WriteHeader records the first status and counts the calls
+func (w *StatusCapturingWriter) WriteHeader(code int) {
+	w.WriteHeaderCalls++
+	if w.Status == 0 {
+		w.Status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write counts the bytes; writing before WriteHeader means 200, as it does
+// to net/http
+func (w *StatusCapturingWriter) Write(b []byte) (int, error) {
+	if w.Status == 0 {
+		w.Status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.Bytes += int64(n)
+	return n, err
+}
+
+// Unwrap returns the wrapped writer, for http.ResponseController
+func (w *StatusCapturingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Serve sends r to h and returns the recorded response
+func Serve(h http.Handler, r *http.Request) *httptest.ResponseRecorder {
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, r)
+	return rr
+}
+
+// AssertStatus fails t unless the response's status is want
+func AssertStatus(t testing.TB, rr *httptest.ResponseRecorder, want int) {
+	t.Helper()
+	if rr.Code != want {
+		t.Errorf("status = %d; want %d (body: %q)", rr.Code, want, rr.Body.String())
+	}
+}
+
+// AssertHeader fails t unless the header name is want
+func AssertHeader(t testing.TB, h http.Header, name, want string) {
+	t.Helper()
+	if got := h[http.CanonicalHeaderKey(name)]; len(got) == 0 {
+		t.Errorf("%s is not set; want %q", name, want)
+	} else if got[0] != want {
+		t.Errorf("%s = %q; want %q", name, got[0], want)
+	}
+}
+
+// AssertHeaders calls AssertHeader for each header in want, in a stable
+// order so failures read the same on every run
+func AssertHeaders(t testing.TB, h http.Header, want map[string]string) {
+	t.Helper()
+	names := make([]string, 0, len(want))
+	for name := range want {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		AssertHeader(t, h, name, want[name])
+	}
+}
+
+// AssertNoHeader fails t if the header name is set
+func AssertNoHeader(t testing.TB, h http.Header, name string) {
+	t.Helper()
+	if values, ok := h[http.CanonicalHeaderKey(name)]; ok {
+		t.Errorf("%s = %q; want it not set", name, values)
+	}
+}
@@ -0,0 +1,113 @@
+package middlewaretest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rehan/go-interview-prep/pkg/internal/tbtest"
+)
+
+func TestRecordingHandler(t *testing.T) {
+	h := &RecordingHandler{Status: http.StatusCreated, Body: "made", Header: http.Header{"X-Test": {"1"}}}
+	addHeader := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.Header.Set("X-Added", "yes")
+			next.ServeHTTP(w, r)
+		})
+	}
+	rr := Serve(addHeader(h), httptest.NewRequest(http.MethodPost, "/a", nil))
+	Serve(h, httptest.NewRequest(http.MethodGet, "/b", nil))
+
+	if rr.Code != http.StatusCreated || rr.Body.String() != "made" || rr.Header().Get("X-Test") != "1" {
+		t.Errorf("response = %d %q %v; want 201 \"made\" with X-Test: 1", rr.Code, rr.Body, rr.Header())
+	}
+	h.AssertCalls(t, 2)
+	if got := h.Requests()[0].Header.Get("X-Added"); got != "yes" {
+		t.Errorf("first request X-Added = %q; want the header the middleware added", got)
+	}
+	if got := h.LastRequest(t).URL.Path; got != "/b" {
+		t.Errorf("LastRequest path = %q; want /b", got)
+	}
+
+	if rr := Serve(&RecordingHandler{}, httptest.NewRequest(http.MethodGet, "/", nil)); rr.Code != http.StatusOK {
+		t.Errorf("zero RecordingHandler status = %d; want 200", rr.Code)
+	}
+	rec := &tbtest.Recorder{TB: t}
+	h.AssertCalls(rec, 1)
+	if len(rec.Errors) != 1 || rec.Errors[0] != "handler called 2 times; want 1" {
+		t.Errorf("AssertCalls(1) errors = %q", rec.Errors)
+	}
+}
+
+func TestPanicHandler(t *testing.T) {
+	defer func() {
+		if p := recover(); p != "boom" {
+			t.Errorf("recovered %v; want boom", p)
+		}
+	}()
+	Serve(PanicHandler("boom"), httptest.NewRequest(http.MethodGet, "/", nil))
+	t.Error("PanicHandler did not panic")
+}
+
+func TestStatusCapturingWriter(t *testing.T) {
+	tests := []struct {
+		name        string
+		handler     http.HandlerFunc
+		wantStatus  int
+		wantBytes   int64
+		wantHeaders int
+	}{
+		{"nothing", func(http.ResponseWriter, *http.Request) {}, 0, 0, 0},
+		{"write only", func(w http.ResponseWriter, _ *http.Request) { w.Write([]byte("hello")) }, 200, 5, 0},
+		{"status then body", func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("no"))
+		}, 404, 2, 1},
+		{"second status", func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+			w.WriteHeader(http.StatusInternalServerError)
+		}, 202, 0, 2},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			w := NewStatusCapturingWriter(rr)
+			tc.handler(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			if w.Status != tc.wantStatus || w.Bytes != tc.wantBytes || w.WriteHeaderCalls != tc.wantHeaders {
+				t.Errorf("Status, Bytes, WriteHeaderCalls = %d, %d, %d; want %d, %d, %d",
+					w.Status, w.Bytes, w.WriteHeaderCalls, tc.wantStatus, tc.wantBytes, tc.wantHeaders)
+			}
+			if http.NewResponseController(w).Flush() != nil {
+				t.Error("Flush through the writer failed; Unwrap should reach the recorder")
+			}
+		})
+	}
+}
+
+func TestAssertions(t *testing.T) {
+	h := http.Header{"Content-Type": {"text/plain"}, "X-Empty": {}}
+	rr := httptest.NewRecorder()
+	rr.WriteHeader(http.StatusTeapot)
+
+	rec := &tbtest.Recorder{TB: t}
+	AssertHeader(rec, h, "content-type", "text/plain")
+	AssertHeaders(rec, h, map[string]string{"X-Missing": "a", "Content-Type": "text/html"})
+	AssertHeader(rec, h, "X-Empty", "")
+	AssertNoHeader(rec, h, "X-Missing")
+	AssertNoHeader(rec, h, "Content-Type")
+	AssertStatus(rec, rr, http.StatusTeapot)
+	AssertStatus(rec, rr, http.StatusOK)
+
+	want := []string{
+		`Content-Type = "text/plain"; want "text/html"`,
+		`X-Missing is not set; want "a"`,
+		`X-Empty is not set; want ""`,
+		`Content-Type = ["text/plain"]; want it not set`,
+		`status = 418; want 200 (body: "")`,
+	}
+	if got := strings.Join(rec.Errors, "\n"); got != strings.Join(want, "\n") {
+		t.Errorf("errors:\n%s\nwant:\n%s", got, strings.Join(want, "\n"))
+	}
+}
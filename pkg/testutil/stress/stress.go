@@ -0,0 +1,124 @@
+// Package stress runs an operation from many goroutines at once for a
+// while, to shake out races, deadlocks and broken invariants that short
+// tests rarely hit:
+//
+//	ops := stress.Run(t, stress.Config{}, func(r *rand.Rand) {
+//		switch r.IntN(3) {
+//		case 0:
+//			m.Set(key(r), 1)
+//		...
+//		}
+//	})
+//
+// The operation checks what must hold after each step and reports breaks
+// with t.Errorf; the test checks the rest once Run returns. Stress tests
+// are slow, so they sit behind the stress build tag, and the environment
+// sets how long they run:
+//
+//	STRESS_DURATION=1m go test -tags stress -race -run Stress ./pkg/pubsub/
+//
+// or: go run ./cmd/runner stress -duration 1m -race
+//
+// Each goroutine draws from its own random source, all derived from one
+// seed that Run logs; STRESS_SEED set to it gives every goroutine the
+// same sequence again. The goroutines interleave differently each run,
+// so a seed makes a failure likelier to recur, not certain.
+package stress
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Environment variables that set the defaults of Config
+const (
+	DurationEnvVar = "STRESS_DURATION"
+	SeedEnvVar     = "STRESS_SEED"
+)
+
+// DefaultDuration is how long Run runs unless STRESS_DURATION says
+const DefaultDuration = 2 * time.Second
+
+// Config is how hard and how long Run stresses
+type Config struct {
+	Goroutines int           // 0 means 1000
+	Duration   time.Duration // 0 means STRESS_DURATION, or DefaultDuration
+	Seed       uint64        // 0 means STRESS_SEED, or a random one
+}
+
+// Run calls op over and over from cfg.Goroutines goroutines until the
+// duration passes or the test fails, and returns how many calls there
+// were. A panic in op fails the test. op may call t.Errorf but not
+// t.Fatal, which must be called from the test's own goroutine.
+func Run(t testing.TB, cfg Config, op func(r *rand.Rand)) int64 {
+	t.Helper()
+	cfg, err := cfg.withDefaults()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("stress: %d goroutines for %v, %s=%d", cfg.Goroutines, cfg.Duration, SeedEnvVar, cfg.Seed)
+
+	var (
+		ops  atomic.Int64
+		stop atomic.Bool
+		wg   sync.WaitGroup
+	)
+	deadline := time.AfterFunc(cfg.Duration, func() { stop.Store(true) })
+	defer deadline.Stop()
+	for g := range cfg.Goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				if p := recover(); p != nil {
+					t.Errorf("goroutine %d panicked: %v", g, p)
+					stop.Store(true)
+				}
+			}()
+			r := rand.New(rand.NewPCG(cfg.Seed, uint64(g)))
+			for !stop.Load() && !t.Failed() {
+				op(r)
+				ops.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	t.Logf("stress: %d operations", ops.Load())
+	return ops.Load()
+}
+
+// withDefaults fills in what cfg leaves zero
+func (cfg Config) withDefaults() (Config, error) {
+	if cfg.Goroutines == 0 {
+		cfg.Goroutines = 1000
+	}
+	if cfg.Duration == 0 {
+		cfg.Duration = DefaultDuration
+		if s := os.Getenv(DurationEnvVar); s != "" {
+			d, err := time.ParseDuration(s)
+			if err != nil || d <= 0 {
+				return cfg, fmt.Errorf("stress: %s=%q is not a positive duration", DurationEnvVar, s)
+			}
+			cfg.Duration = d
+		}
+	}
+	if cfg.Seed == 0 {
+		if s := os.Getenv(SeedEnvVar); s != "" {
+			seed, err := strconv.ParseUint(s, 10, 64)
+			if err != nil {
+				return cfg, fmt.Errorf("stress: %s=%q is not a seed", SeedEnvVar, s)
+			}
+			cfg.Seed = seed
+		}
+	}
+	for cfg.Seed == 0 {
+		cfg.Seed = rand.Uint64()
+	}
+	return cfg, nil
+}
@@ -0,0 +1,53 @@
+package stress
+
+import (
+	"math/rand/v2"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	var calls atomic.Int64
+	start := time.Now()
+	ops := Run(t, Config{Goroutines: 4, Duration: 20 * time.Millisecond, Seed: 1}, func(r *rand.Rand) {
+		calls.Add(1)
+	})
+	if ops == 0 || ops != calls.Load() {
+		t.Errorf("Run = %d with %d calls; want them equal and not 0", ops, calls.Load())
+	}
+	if d := time.Since(start); d < 20*time.Millisecond {
+		t.Errorf("Run returned after %v; want 20ms", d)
+	}
+}
+
+func TestRun_SeedRepeats(t *testing.T) {
+	first := func() uint64 {
+		var got atomic.Uint64
+		Run(t, Config{Goroutines: 1, Duration: time.Millisecond, Seed: 42}, func(r *rand.Rand) {
+			got.CompareAndSwap(0, r.Uint64())
+		})
+		return got.Load()
+	}
+	if a, b := first(), first(); a != b {
+		t.Errorf("first draws with the same seed = %d, %d; want equal", a, b)
+	}
+}
+
+func TestConfig_Defaults(t *testing.T) {
+	t.Setenv(DurationEnvVar, "3s")
+	t.Setenv(SeedEnvVar, "7")
+	cfg, err := Config{}.withDefaults()
+	if err != nil || cfg != (Config{Goroutines: 1000, Duration: 3 * time.Second, Seed: 7}) {
+		t.Errorf("withDefaults() = %+v, %v; want 1000 goroutines, 3s, seed 7", cfg, err)
+	}
+
+	for _, env := range [][2]string{{DurationEnvVar, "soon"}, {DurationEnvVar, "-1s"}, {SeedEnvVar, "x"}} {
+		t.Setenv(DurationEnvVar, "")
+		t.Setenv(SeedEnvVar, "")
+		t.Setenv(env[0], env[1])
+		if _, err := (Config{}).withDefaults(); err == nil {
+			t.Errorf("%s=%s: withDefaults error = nil; want one", env[0], env[1])
+		}
+	}
+}
@@ -0,0 +1,203 @@
+// Package validator checks struct fields against rules declared in
+// `validate:"..."` struct tags, for example:
+//
+//	type User struct {
+//		Name  string `validate:"required,min=2,max=50"`
+//		Email string `validate:"required,email"`
+//		Age   int    `validate:"min=0"`
+//	}
+//
+// Supported rules:
+//
+//	required  the field must not be its zero value; other rules on the field are skipped if it fails
+//	min=N     strings/slices/maps: length >= N; numbers and Numeric types: value >= N
+//	max=N     strings/slices/maps: length <= N; numbers and Numeric types: value <= N
+//	email     the string must look like an email address (empty is allowed; combine with required)
+//
+// Fields are reported by their json tag name when they have one, so API
+// clients see the same names they sent.
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// FieldError describes a single rule a field failed
+type FieldError struct {
+	Field string // json name, or Go field name if untagged
+	Rule  string // rule name, e.g. "min"
+	Param string // rule parameter, e.g. "2"; empty for rules without one
+}
+
+func (e FieldError) Error() string {
+	switch e.Rule {
+	case "required":
+		return fmt.Sprintf("%s is required", e.Field)
+	case "min":
+		return fmt.Sprintf("%s must be at least %s", e.Field, e.Param)
+	case "max":
+		return fmt.Sprintf("%s must be at most %s", e.Field, e.Param)
+	case "email":
+		return fmt.Sprintf("%s must be a valid email address", e.Field)
+	default:
+		return fmt.Sprintf("%s failed %s", e.Field, e.Rule)
+	}
+}
+
+// Errors aggregates every FieldError found in one Struct call
+type Errors []FieldError
+
+func (errs Errors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap exposes the individual field errors to errors.Is and errors.As
+func (errs Errors) Unwrap() []error {
+	out := make([]error, len(errs))
+	for i, e := range errs {
+		out[i] = e
+	}
+	return out
+}
+
+// ErrInvalidRule is wrapped by errors caused by a malformed tag rather than bad data.
+// Such errors are programming mistakes, so they are returned instead of aggregated.
+var ErrInvalidRule = errors.New("validator: invalid rule")
+
+// Struct validates v, which must be a struct or a pointer to one.
+// It returns nil, an Errors value listing every failed rule, or an error
+// wrapping ErrInvalidRule if a tag cannot be interpreted.
+func Struct(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return fmt.Errorf("%w: nil pointer", ErrInvalidRule)
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("%w: expected struct, got %s", ErrInvalidRule, rv.Kind())
+	}
+
+	var errs Errors
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		tag := field.Tag.Get("validate")
+		if tag == "" || !field.IsExported() {
+			continue
+		}
+
+		name := fieldName(field)
+		fv := rv.Field(i)
+		for _, rule := range strings.Split(tag, ",") {
+			key, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+			ok, err := check(fv, key, param)
+			if err != nil {
+				return fmt.Errorf("%w: field %s: %v", ErrInvalidRule, field.Name, err)
+			}
+			if !ok {
+				errs = append(errs, FieldError{Field: name, Rule: key, Param: param})
+				if key == "required" {
+					break // further rules on a missing value only add noise
+				}
+			}
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// fieldName prefers the json tag name so errors match the wire format
+func fieldName(field reflect.StructField) string {
+	if tag, ok := field.Tag.Lookup("json"); ok {
+		name, _, _ := strings.Cut(tag, ",")
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// check reports whether fv satisfies one rule
+func check(fv reflect.Value, rule, param string) (bool, error) {
+	switch rule {
+	case "required":
+		return !fv.IsZero(), nil
+	case "min", "max":
+		limit, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return false, fmt.Errorf("%s needs a numeric parameter, got %q", rule, param)
+		}
+		n, err := measure(fv)
+		if err != nil {
+			return false, err
+		}
+		if rule == "min" {
+			return n >= limit, nil
+		}
+		return n <= limit, nil
+	case "email":
+		if fv.Kind() != reflect.String {
+			return false, fmt.Errorf("email needs a string field, got %s", fv.Kind())
+		}
+		s := fv.String()
+		return s == "" || looksLikeEmail(s), nil
+	default:
+		return false, fmt.Errorf("unknown rule %q", rule)
+	}
+}
+
+// Numeric is implemented by types whose min and max rules compare a value
+// other than their underlying one, such as money.Amount: an integer number
+// of cents that is compared in whole units, so min=0.01 means one cent
+type Numeric interface {
+	Float64() float64
+}
+
+// measure returns the number min/max compare against: the length of
+// strings (in runes), slices and maps, or the value of numbers
+func measure(fv reflect.Value) (float64, error) {
+	if fv.CanInterface() {
+		if n, ok := fv.Interface().(Numeric); ok {
+			return n.Float64(), nil
+		}
+	}
+	switch fv.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(fv.String())), nil
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return float64(fv.Len()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(fv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(fv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return fv.Float(), nil
+	default:
+		return 0, fmt.Errorf("min/max not supported for %s", fv.Kind())
+	}
+}
+
+// looksLikeEmail is a pragmatic check, not full RFC 5322 parsing:
+// exactly one @, a non-empty local part, and a dotted domain
+func looksLikeEmail(s string) bool {
+	local, domain, ok := strings.Cut(s, "@")
+	if !ok || local == "" || strings.Contains(domain, "@") || strings.ContainsAny(s, " \t\n") {
+		return false
+	}
+	dot := strings.LastIndex(domain, ".")
+	return dot > 0 && dot < len(domain)-1
+}
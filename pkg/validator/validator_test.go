@@ -0,0 +1,165 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type signup struct {
+	Name     string   `json:"name" validate:"required,min=2,max=5"`
+	Email    string   `json:"email" validate:"required,email"`
+	Age      int      `json:"age" validate:"min=0,max=150"`
+	Tags     []string `validate:"max=2"`
+	Nickname string   // no rules
+}
+
+func validSignup() signup {
+	return signup{Name: "Ann", Email: "ann@example.com", Age: 30}
+}
+
+func TestStruct_Valid(t *testing.T) {
+	if err := Struct(validSignup()); err != nil {
+		t.Errorf("Struct() = %v; want nil", err)
+	}
+	s := validSignup()
+	if err := Struct(&s); err != nil {
+		t.Errorf("Struct(pointer) = %v; want nil", err)
+	}
+}
+
+func TestStruct_Rules(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*signup)
+		field  string
+		rule   string
+	}{
+		{"required string", func(s *signup) { s.Name = "" }, "name", "required"},
+		{"min length", func(s *signup) { s.Name = "A" }, "name", "min"},
+		{"max length", func(s *signup) { s.Name = "Alexandra" }, "name", "max"},
+		{"min counts runes not bytes", func(s *signup) { s.Name = "é" }, "name", "min"},
+		{"email", func(s *signup) { s.Email = "not-an-email" }, "email", "email"},
+		{"email without domain dot", func(s *signup) { s.Email = "a@localhost" }, "email", "email"},
+		{"email with two @", func(s *signup) { s.Email = "a@b@c.com" }, "email", "email"},
+		{"min number", func(s *signup) { s.Age = -1 }, "age", "min"},
+		{"max number", func(s *signup) { s.Age = 200 }, "age", "max"},
+		{"max slice length", func(s *signup) { s.Tags = []string{"a", "b", "c"} }, "Tags", "max"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := validSignup()
+			tc.modify(&s)
+			err := Struct(s)
+
+			var errs Errors
+			if !errors.As(err, &errs) {
+				t.Fatalf("Struct() = %v; want validator.Errors", err)
+			}
+			if len(errs) != 1 || errs[0].Field != tc.field || errs[0].Rule != tc.rule {
+				t.Errorf("errors = %#v; want one %s/%s error", errs, tc.field, tc.rule)
+			}
+		})
+	}
+}
+
+func TestStruct_AggregatesAllErrors(t *testing.T) {
+	err := Struct(signup{Age: -5})
+
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Struct() = %v; want validator.Errors", err)
+	}
+
+	// name: required (min is skipped), email: required, age: min
+	if len(errs) != 3 {
+		t.Fatalf("got %d errors (%v); want 3", len(errs), err)
+	}
+
+	// Individual FieldErrors are reachable through errors.Is
+	if !errors.Is(err, FieldError{Field: "email", Rule: "required"}) {
+		t.Errorf("errors.Is did not find the email/required FieldError in %v", err)
+	}
+
+	want := "name is required; email is required; age must be at least 0"
+	if err.Error() != want {
+		t.Errorf("Error() = %q; want %q", err.Error(), want)
+	}
+}
+
+func TestStruct_InvalidRules(t *testing.T) {
+	type unknownRule struct {
+		A string `validate:"uppercase"`
+	}
+	type badParam struct {
+		A string `validate:"min=two"`
+	}
+	type emailOnInt struct {
+		A int `validate:"email"`
+	}
+	type minOnBool struct {
+		A bool `validate:"min=1"`
+	}
+
+	for _, v := range []any{unknownRule{}, badParam{}, emailOnInt{}, minOnBool{}, 42, (*signup)(nil)} {
+		t.Run(fmt.Sprintf("%T", v), func(t *testing.T) {
+			if err := Struct(v); !errors.Is(err, ErrInvalidRule) {
+				t.Errorf("Struct(%#v) = %v; want ErrInvalidRule", v, err)
+			}
+		})
+	}
+}
+
+func TestStruct_EmailAllowsEmptyWithoutRequired(t *testing.T) {
+	type optionalEmail struct {
+		Email string `validate:"email"`
+	}
+	if err := Struct(optionalEmail{}); err != nil {
+		t.Errorf("Struct() = %v; want nil for an empty optional email", err)
+	}
+}
+
+// cents stands in for money.Amount: the rules compare whole units
+type cents int64
+
+func (c cents) Float64() float64 { return float64(c) / 100 }
+
+func TestStruct_Numeric(t *testing.T) {
+	type item struct {
+		Price cents `json:"price" validate:"required,min=0.01,max=100"`
+	}
+	tests := []struct {
+		price    cents
+		wantRule string
+	}{
+		{1, ""},
+		{10000, ""},
+		{0, "required"},
+		{-1, "min"},
+		{10001, "max"},
+	}
+	for _, tc := range tests {
+		err := Struct(item{Price: tc.price})
+		var errs Errors
+		switch {
+		case tc.wantRule == "" && err != nil:
+			t.Errorf("Struct(%d cents) = %v; want nil", tc.price, err)
+		case tc.wantRule != "" && (!errors.As(err, &errs) || errs[0].Rule != tc.wantRule):
+			t.Errorf("Struct(%d cents) = %v; want a %s error", tc.price, err, tc.wantRule)
+		}
+	}
+}
+
+func ExampleStruct() {
+	type Book struct {
+		Title string  `json:"title" validate:"required"`
+		Price float64 `json:"price" validate:"min=0.01"`
+	}
+
+	fmt.Println(Struct(Book{Title: "Go in Action", Price: 24.99}))
+	fmt.Println(Struct(Book{}))
+	// Output:
+	// <nil>
+	// title is required; price must be at least 0.01
+}
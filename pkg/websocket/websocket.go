@@ -0,0 +1,533 @@
+// Package websocket implements the WebSocket protocol (RFC 6455) on top of
+// net/http: the opening handshake for servers (Upgrade) and clients
+// (Dial), and framing with masking, fragmented messages and the ping,
+// pong and close control frames.
+//
+//	conn, err := websocket.Upgrade(w, r)
+//	if err != nil {
+//		http.Error(w, err.Error(), http.StatusBadRequest)
+//		return
+//	}
+//	defer conn.Close()
+//	for {
+//		typ, msg, err := conn.ReadMessage()
+//		if err != nil {
+//			return // a *CloseError if the peer closed
+//		}
+//		conn.WriteMessage(typ, msg)
+//	}
+//
+// One goroutine may read and any number may write. ReadMessage answers
+// pings and close frames itself, so a connection must be read even if its
+// messages are of no interest. Extensions such as permessage-deflate and
+// subprotocol negotiation are not supported.
+package websocket
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Message types, which are the frame opcodes
+const (
+	TextMessage   = 1
+	BinaryMessage = 2
+	CloseMessage  = 8
+	PingMessage   = 9
+	PongMessage   = 10
+
+	continuationFrame = 0
+)
+
+// Close codes (RFC 6455 section 7.4)
+const (
+	CloseNormalClosure    = 1000
+	CloseGoingAway        = 1001
+	CloseProtocolError    = 1002
+	CloseNoStatusReceived = 1005 // reported, never sent
+	CloseInvalidPayload   = 1007
+	ClosePolicyViolation  = 1008
+	CloseMessageTooBig    = 1009
+	CloseTryAgainLater    = 1013
+)
+
+// acceptGUID is the fixed string RFC 6455 appends to the client's key
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// DefaultReadLimit is the largest message ReadMessage accepts unless
+// SetReadLimit says otherwise
+const DefaultReadLimit = 1 << 20
+
+// closeWait bounds writing the close frame in Close and the replies
+// ReadMessage sends by itself
+const closeWait = time.Second
+
+var (
+	// ErrBadHandshake is returned by Dial when the server does not
+	// switch protocols
+	ErrBadHandshake = errors.New("websocket: bad handshake")
+
+	// ErrReadLimit is returned by ReadMessage for a message over the
+	// read limit; the peer is sent CloseMessageTooBig
+	ErrReadLimit = errors.New("websocket: message exceeds read limit")
+
+	// ErrCloseSent is returned by writes after a close frame was sent
+	ErrCloseSent = errors.New("websocket: close sent")
+)
+
+// CloseError is returned by ReadMessage when the peer closes the
+// connection. Code is CloseNoStatusReceived if the peer gave none.
+type CloseError struct {
+	Code int
+	Text string
+}
+
+func (e *CloseError) Error() string {
+	if e.Text == "" {
+		return fmt.Sprintf("websocket: close %d", e.Code)
+	}
+	return fmt.Sprintf("websocket: close %d: %s", e.Code, e.Text)
+}
+
+// HandshakeError is returned by Upgrade for a request that is not a valid
+// WebSocket handshake
+type HandshakeError struct {
+	Reason string
+}
+
+func (e *HandshakeError) Error() string {
+	return "websocket: " + e.Reason
+}
+
+// AcceptKey returns the Sec-WebSocket-Accept value for a client's
+// Sec-WebSocket-Key
+func AcceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// Upgrade completes the server side of the handshake and takes over the
+// connection. If it returns an error nothing has been written, so the
+// caller can still send an HTTP response; the Sec-WebSocket-Version header
+// is set for a client that asked for another version. The headers already
+// in w, such as a request ID, go out with the 101 response.
+//
+// The returned connection may still have the server's read and write
+// deadlines; set or clear them as the connection needs.
+func Upgrade(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	if r.Method != http.MethodGet {
+		return nil, &HandshakeError{"handshake must be a GET request"}
+	}
+	if !hasToken(r.Header, "Connection", "upgrade") || !hasToken(r.Header, "Upgrade", "websocket") {
+		return nil, &HandshakeError{"not a WebSocket handshake: missing Connection: Upgrade or Upgrade: websocket"}
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		return nil, &HandshakeError{"unsupported Sec-WebSocket-Version; only 13 is supported"}
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != 16 {
+		return nil, &HandshakeError{"Sec-WebSocket-Key must be 16 bytes in base64"}
+	}
+
+	netConn, brw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return nil, err
+	}
+	h := w.Header().Clone()
+	h.Set("Upgrade", "websocket")
+	h.Set("Connection", "Upgrade")
+	h.Set("Sec-WebSocket-Accept", AcceptKey(key))
+	brw.WriteString("HTTP/1.1 101 Switching Protocols\r\n")
+	h.Write(brw)
+	brw.WriteString("\r\n")
+	if err := brw.Flush(); err != nil {
+		netConn.Close()
+		return nil, err
+	}
+	return newConn(netConn, brw.Reader, true), nil
+}
+
+// Dial opens a client connection to a ws:// or wss:// URL, sending header
+// with the handshake. If the server refuses, the error is ErrBadHandshake
+// and the response, with its body, says why.
+func Dial(ctx context.Context, rawURL string, header http.Header) (*Conn, *http.Response, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	var port string
+	switch u.Scheme {
+	case "ws":
+		u.Scheme, port = "http", "80"
+	case "wss":
+		u.Scheme, port = "https", "443"
+	default:
+		return nil, nil, fmt.Errorf("websocket: URL scheme must be ws or wss, not %q", u.Scheme)
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), port)
+	}
+
+	var d net.Dialer
+	netConn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	if u.Scheme == "https" {
+		tlsConn := tls.Client(netConn, &tls.Config{ServerName: u.Hostname()})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			netConn.Close()
+			return nil, nil, err
+		}
+		netConn = tlsConn
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		netConn.SetDeadline(deadline)
+	}
+
+	nonce := make([]byte, 16)
+	rand.Read(nonce)
+	key := base64.StdEncoding.EncodeToString(nonce)
+	req := &http.Request{Method: http.MethodGet, URL: u, Host: u.Host, Header: header.Clone()}
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	}
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	if err := req.Write(netConn); err != nil {
+		netConn.Close()
+		return nil, nil, err
+	}
+
+	br := bufio.NewReader(netConn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		netConn.Close()
+		return nil, nil, err
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols ||
+		!hasToken(resp.Header, "Upgrade", "websocket") ||
+		resp.Header.Get("Sec-WebSocket-Accept") != AcceptKey(key) {
+		// Keep the body readable after the connection is closed
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		netConn.Close()
+		return nil, resp, ErrBadHandshake
+	}
+	netConn.SetDeadline(time.Time{})
+	return newConn(netConn, br, false), resp, nil
+}
+
+// hasToken reports whether the comma-separated header name lists token,
+// ignoring case
+func hasToken(h http.Header, name, token string) bool {
+	for _, v := range h.Values(name) {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Conn is a WebSocket connection
+type Conn struct {
+	conn     net.Conn
+	br       *bufio.Reader
+	isServer bool // servers read masked frames and write unmasked ones
+
+	// Read side, used by the one reading goroutine
+	readLimit   int64
+	pongHandler func(data []byte)
+
+	writeMu       sync.Mutex
+	writeDeadline time.Time
+	closeSent     bool
+}
+
+func newConn(conn net.Conn, br *bufio.Reader, isServer bool) *Conn {
+	return &Conn{conn: conn, br: br, isServer: isServer, readLimit: DefaultReadLimit}
+}
+
+// SetReadLimit sets the largest message ReadMessage accepts
+func (c *Conn) SetReadLimit(n int64) {
+	c.readLimit = n
+}
+
+// SetPongHandler sets a function ReadMessage calls with each pong's
+// payload, typically to extend the read deadline
+func (c *Conn) SetPongHandler(h func(data []byte)) {
+	c.pongHandler = h
+}
+
+// SetReadDeadline sets the deadline for reading; a zero time means none
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	return c.conn.SetReadDeadline(t)
+}
+
+// SetWriteDeadline sets the deadline for WriteMessage; a zero time means
+// none
+func (c *Conn) SetWriteDeadline(t time.Time) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	c.writeDeadline = t
+	return c.conn.SetWriteDeadline(t)
+}
+
+// ReadMessage returns the next text or binary message, joining fragments.
+// It answers pings with pongs, passes pongs to the pong handler, and
+// answers a close frame with one of its own before returning a
+// *CloseError. A protocol violation by the peer closes the connection
+// with the matching close code and returns an error.
+func (c *Conn) ReadMessage() (messageType int, data []byte, err error) {
+	for {
+		fin, opcode, payload, err := c.readFrame(c.readLimit - int64(len(data)))
+		if err != nil {
+			return 0, nil, err
+		}
+		switch opcode {
+		case PingMessage:
+			if err := c.WriteControl(PongMessage, payload, time.Now().Add(closeWait)); err != nil && !errors.Is(err, ErrCloseSent) {
+				return 0, nil, err
+			}
+			continue
+		case PongMessage:
+			if c.pongHandler != nil {
+				c.pongHandler(payload)
+			}
+			continue
+		case CloseMessage:
+			return 0, nil, c.handleClose(payload)
+		case continuationFrame:
+			if messageType == 0 {
+				return 0, nil, c.fail(CloseProtocolError, "continuation frame without a message to continue")
+			}
+		default:
+			if messageType != 0 {
+				return 0, nil, c.fail(CloseProtocolError, "new message before the last one finished")
+			}
+			messageType = opcode
+		}
+
+		data = append(data, payload...)
+		if fin {
+			if messageType == TextMessage && !utf8.Valid(data) {
+				return 0, nil, c.fail(CloseInvalidPayload, "text message is not valid UTF-8")
+			}
+			return messageType, data, nil
+		}
+	}
+}
+
+// readFrame reads one frame, unmasking its payload. A data frame's
+// payload may be at most limit bytes.
+func (c *Conn) readFrame(limit int64) (fin bool, opcode int, payload []byte, err error) {
+	var head [2]byte
+	if _, err := io.ReadFull(c.br, head[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin = head[0]&0x80 != 0
+	opcode = int(head[0] & 0x0f)
+	masked := head[1]&0x80 != 0
+
+	if head[0]&0x70 != 0 {
+		return false, 0, nil, c.fail(CloseProtocolError, "reserved bits set without an extension")
+	}
+	switch opcode {
+	case continuationFrame, TextMessage, BinaryMessage, CloseMessage, PingMessage, PongMessage:
+	default:
+		return false, 0, nil, c.fail(CloseProtocolError, fmt.Sprintf("unknown opcode %d", opcode))
+	}
+	if masked != c.isServer {
+		if c.isServer {
+			return false, 0, nil, c.fail(CloseProtocolError, "client frames must be masked")
+		}
+		return false, 0, nil, c.fail(CloseProtocolError, "server frames must not be masked")
+	}
+
+	n := uint64(head[1] & 0x7f)
+	switch n {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		n = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		n = binary.BigEndian.Uint64(ext[:])
+	}
+
+	isControl := opcode >= CloseMessage
+	if isControl && (!fin || n > 125) {
+		return false, 0, nil, c.fail(CloseProtocolError, "control frames must be unfragmented and at most 125 bytes")
+	}
+	if !isControl && n > uint64(max(limit, 0)) {
+		c.fail(CloseMessageTooBig, "message too big")
+		return false, 0, nil, ErrReadLimit
+	}
+
+	var key [4]byte
+	if masked {
+		if _, err := io.ReadFull(c.br, key[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+	payload = make([]byte, n)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if masked {
+		maskBytes(key, payload)
+	}
+	return fin, opcode, payload, nil
+}
+
+// handleClose answers a close frame with the same code, as RFC 6455
+// asks, and describes it as an error
+func (c *Conn) handleClose(payload []byte) error {
+	closeErr := &CloseError{Code: CloseNoStatusReceived}
+	switch {
+	case len(payload) == 1:
+		return c.fail(CloseProtocolError, "close frame with a one-byte payload")
+	case len(payload) >= 2:
+		closeErr.Code = int(binary.BigEndian.Uint16(payload))
+		closeErr.Text = string(payload[2:])
+		if !utf8.ValidString(closeErr.Text) {
+			return c.fail(CloseInvalidPayload, "close reason is not valid UTF-8")
+		}
+	}
+
+	var reply []byte
+	if closeErr.Code != CloseNoStatusReceived {
+		reply = FormatCloseMessage(closeErr.Code, "")
+	}
+	c.WriteControl(CloseMessage, reply, time.Now().Add(closeWait))
+	return closeErr
+}
+
+// fail closes the connection with code after a protocol violation
+func (c *Conn) fail(code int, reason string) error {
+	c.WriteControl(CloseMessage, FormatCloseMessage(code, reason), time.Now().Add(closeWait))
+	c.conn.Close()
+	return fmt.Errorf("websocket: %s", reason)
+}
+
+// WriteMessage sends data as one text or binary message
+func (c *Conn) WriteMessage(messageType int, data []byte) error {
+	if messageType != TextMessage && messageType != BinaryMessage {
+		return fmt.Errorf("websocket: WriteMessage needs a text or binary message type, not %d", messageType)
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.closeSent {
+		return ErrCloseSent
+	}
+	return c.writeFrame(true, messageType, data)
+}
+
+// WriteControl sends a close, ping or pong frame, waiting no longer than
+// deadline. Nothing can be written after a close frame.
+func (c *Conn) WriteControl(messageType int, data []byte, deadline time.Time) error {
+	if messageType != CloseMessage && messageType != PingMessage && messageType != PongMessage {
+		return fmt.Errorf("websocket: WriteControl needs a control message type, not %d", messageType)
+	}
+	if len(data) > 125 {
+		return errors.New("websocket: control frame payload over 125 bytes")
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.closeSent {
+		return ErrCloseSent
+	}
+	c.conn.SetWriteDeadline(deadline)
+	defer c.conn.SetWriteDeadline(c.writeDeadline)
+	if messageType == CloseMessage {
+		c.closeSent = true
+	}
+	return c.writeFrame(true, messageType, data)
+}
+
+// writeFrame writes one frame, masked if this is a client. c.writeMu must
+// be held.
+func (c *Conn) writeFrame(fin bool, opcode int, payload []byte) error {
+	buf := make([]byte, 0, 14+len(payload))
+	b0 := byte(opcode)
+	if fin {
+		b0 |= 0x80
+	}
+	buf = append(buf, b0)
+
+	var maskBit byte
+	if !c.isServer {
+		maskBit = 0x80
+	}
+	switch n := len(payload); {
+	case n <= 125:
+		buf = append(buf, maskBit|byte(n))
+	case n <= 0xffff:
+		buf = append(buf, maskBit|126)
+		buf = binary.BigEndian.AppendUint16(buf, uint16(n))
+	default:
+		buf = append(buf, maskBit|127)
+		buf = binary.BigEndian.AppendUint64(buf, uint64(n))
+	}
+
+	if c.isServer {
+		buf = append(buf, payload...)
+	} else {
+		// Clients mask with a fresh random key so that a proxy that does
+		// not understand WebSocket cannot be fed chosen bytes
+		var key [4]byte
+		rand.Read(key[:])
+		buf = append(buf, key[:]...)
+		start := len(buf)
+		buf = append(buf, payload...)
+		maskBytes(key, buf[start:])
+	}
+	_, err := c.conn.Write(buf)
+	return err
+}
+
+// maskBytes XORs b with key in place; masking and unmasking are the same
+func maskBytes(key [4]byte, b []byte) {
+	for i := range b {
+		b[i] ^= key[i%4]
+	}
+}
+
+// FormatCloseMessage returns a close frame payload with code and text
+func FormatCloseMessage(code int, text string) []byte {
+	return append(binary.BigEndian.AppendUint16(nil, uint16(code)), text...)
+}
+
+// Close sends a normal close frame, unless one was sent already, and
+// closes the connection without waiting for the peer's reply
+func (c *Conn) Close() error {
+	c.WriteControl(CloseMessage, FormatCloseMessage(CloseNormalClosure, ""), time.Now().Add(closeWait))
+	return c.conn.Close()
+}
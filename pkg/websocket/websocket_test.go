@@ -0,0 +1,272 @@
+package websocket
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// echoServer serves WebSocket connections that send back every message,
+// after passing each new connection to setup if it is not nil
+func echoServer(t *testing.T, setup func(*Conn)) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer conn.Close()
+		if setup != nil {
+			setup(conn)
+		}
+		for {
+			typ, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if err := conn.WriteMessage(typ, msg); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func dial(t *testing.T, srv *httptest.Server) *Conn {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, _, err := Dial(ctx, "ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	return conn
+}
+
+func TestAcceptKey(t *testing.T) {
+	// The example in RFC 6455 section 1.3
+	if got := AcceptKey("dGhlIHNhbXBsZSBub25jZQ=="); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("AcceptKey = %q; want the RFC's s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", got)
+	}
+}
+
+func TestEcho(t *testing.T) {
+	conn := dial(t, echoServer(t, nil))
+	tests := []struct {
+		name string
+		typ  int
+		data []byte
+	}{
+		{"text", TextMessage, []byte("hello, 世界")},
+		{"empty", BinaryMessage, []byte{}},
+		{"125 bytes, the largest 7-bit length", BinaryMessage, bytes.Repeat([]byte{1}, 125)},
+		{"16-bit length", BinaryMessage, bytes.Repeat([]byte{2}, 126)},
+		{"64-bit length", BinaryMessage, bytes.Repeat([]byte{3}, 70000)},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := conn.WriteMessage(tc.typ, tc.data); err != nil {
+				t.Fatal(err)
+			}
+			typ, got, err := conn.ReadMessage()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if typ != tc.typ || !bytes.Equal(got, tc.data) {
+				t.Errorf("echo = type %d, %d bytes; want type %d, %d bytes", typ, len(got), tc.typ, len(tc.data))
+			}
+		})
+	}
+}
+
+func TestFragmentedMessage(t *testing.T) {
+	conn := dial(t, echoServer(t, nil))
+	conn.writeMu.Lock()
+	conn.writeFrame(false, TextMessage, []byte("frag"))
+	// Control frames may come between the fragments of a message
+	conn.writeFrame(true, PingMessage, []byte("in between"))
+	conn.writeFrame(false, continuationFrame, []byte("men"))
+	conn.writeFrame(true, continuationFrame, []byte("ted"))
+	conn.writeMu.Unlock()
+
+	var pong string
+	conn.SetPongHandler(func(data []byte) { pong = string(data) })
+	typ, msg, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if typ != TextMessage || string(msg) != "fragmented" {
+		t.Errorf("message = %d %q; want the fragments joined as text", typ, msg)
+	}
+	if pong != "in between" {
+		t.Errorf("pong payload = %q; want the ping's", pong)
+	}
+}
+
+func TestClose(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		conn.WriteControl(CloseMessage, FormatCloseMessage(CloseGoingAway, "bye"), time.Now().Add(time.Second))
+		// Wait for the client's reply before hanging up
+		if _, _, err := conn.ReadMessage(); err == nil {
+			t.Error("server read a message after closing")
+		}
+		conn.Close()
+	}))
+	defer srv.Close()
+
+	conn := dial(t, srv)
+	_, _, err := conn.ReadMessage()
+	var closeErr *CloseError
+	if !errors.As(err, &closeErr) || closeErr.Code != CloseGoingAway || closeErr.Text != "bye" {
+		t.Fatalf("ReadMessage error = %v; want close 1001 bye", err)
+	}
+	if err := conn.WriteMessage(TextMessage, []byte("late")); !errors.Is(err, ErrCloseSent) {
+		t.Errorf("write after close = %v; want ErrCloseSent", err)
+	}
+}
+
+func TestProtocolErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(*Conn)
+		send     func(*Conn)
+		wantCode int
+	}{
+		{
+			name:     "unmasked client frame",
+			send:     func(c *Conn) { c.isServer = true; c.writeFrame(true, TextMessage, []byte("hi")) },
+			wantCode: CloseProtocolError,
+		},
+		{
+			name:     "continuation without a message",
+			send:     func(c *Conn) { c.writeFrame(true, continuationFrame, []byte("hi")) },
+			wantCode: CloseProtocolError,
+		},
+		{
+			name:     "invalid UTF-8 text",
+			send:     func(c *Conn) { c.writeFrame(true, TextMessage, []byte{0xff, 0xfe}) },
+			wantCode: CloseInvalidPayload,
+		},
+		{
+			name:     "over the read limit",
+			setup:    func(c *Conn) { c.SetReadLimit(10) },
+			send:     func(c *Conn) { c.writeFrame(true, BinaryMessage, make([]byte, 11)) },
+			wantCode: CloseMessageTooBig,
+		},
+		{
+			name:  "fragments over the read limit",
+			setup: func(c *Conn) { c.SetReadLimit(10) },
+			send: func(c *Conn) {
+				c.writeFrame(false, BinaryMessage, make([]byte, 6))
+				c.writeFrame(true, continuationFrame, make([]byte, 6))
+			},
+			wantCode: CloseMessageTooBig,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			conn := dial(t, echoServer(t, tc.setup))
+			conn.writeMu.Lock()
+			tc.send(conn)
+			conn.isServer = false
+			conn.writeMu.Unlock()
+
+			_, _, err := conn.ReadMessage()
+			var closeErr *CloseError
+			if !errors.As(err, &closeErr) || closeErr.Code != tc.wantCode {
+				t.Errorf("ReadMessage error = %v; want close %d", err, tc.wantCode)
+			}
+		})
+	}
+}
+
+func TestUpgrade_BadHandshake(t *testing.T) {
+	valid := func() *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/ws", nil)
+		r.Header.Set("Connection", "keep-alive, Upgrade")
+		r.Header.Set("Upgrade", "websocket")
+		r.Header.Set("Sec-WebSocket-Version", "13")
+		r.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+		return r
+	}
+	tests := []struct {
+		name   string
+		modify func(*http.Request)
+	}{
+		{"POST", func(r *http.Request) { r.Method = http.MethodPost }},
+		{"no Upgrade header", func(r *http.Request) { r.Header.Del("Upgrade") }},
+		{"no Connection upgrade", func(r *http.Request) { r.Header.Set("Connection", "keep-alive") }},
+		{"old version", func(r *http.Request) { r.Header.Set("Sec-WebSocket-Version", "8") }},
+		{"short key", func(r *http.Request) { r.Header.Set("Sec-WebSocket-Key", "c2hvcnQ=") }},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := valid()
+			tc.modify(r)
+			rr := httptest.NewRecorder()
+			_, err := Upgrade(rr, r)
+			var hsErr *HandshakeError
+			if !errors.As(err, &hsErr) {
+				t.Fatalf("Upgrade error = %v; want a HandshakeError", err)
+			}
+			if tc.name == "old version" && rr.Header().Get("Sec-WebSocket-Version") != "13" {
+				t.Error("no Sec-WebSocket-Version header naming the supported version")
+			}
+		})
+	}
+}
+
+func TestDial_Refused(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no sockets here", http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	_, resp, err := Dial(context.Background(), "ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if !errors.Is(err, ErrBadHandshake) {
+		t.Fatalf("Dial error = %v; want ErrBadHandshake", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusForbidden || !strings.Contains(string(body), "no sockets here") {
+		t.Errorf("response = %d %q; want the server's 403 and its body", resp.StatusCode, body)
+	}
+}
+
+func ExampleDial() {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, msg, _ := conn.ReadMessage()
+		conn.WriteMessage(TextMessage, append([]byte("echo: "), msg...))
+	}))
+	defer srv.Close()
+
+	conn, _, err := Dial(context.Background(), "ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer conn.Close()
+	conn.WriteMessage(TextMessage, []byte("hello"))
+	_, msg, _ := conn.ReadMessage()
+	fmt.Println(string(msg))
+	// Output: echo: hello
+}
@@ -0,0 +1,66 @@
+//go:build stress
+
+package workerpool
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rehan/go-interview-prep/pkg/testutil/stress"
+)
+
+// TestStress_Pool submits from many goroutines at once, some with
+// deadlines too short to wait out a full queue, and tasks that sometimes
+// panic. Every accepted task must run exactly once, no more than workers
+// at a time, and no rejected one at all.
+func TestStress_Pool(t *testing.T) {
+	const workers = 8
+	p := New(WithWorkers(workers), WithQueue(64))
+	var accepted, ran, rejected, panicked, running, most atomic.Int64
+
+	stress.Run(t, stress.Config{}, func(r *rand.Rand) {
+		ctx := context.Background()
+		if r.IntN(4) == 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, time.Duration(r.IntN(100))*time.Microsecond)
+			defer cancel()
+		}
+		panics := r.IntN(100) == 0
+		counted := new(atomic.Bool) // set by the task, which must run once
+		err := p.Submit(ctx, func() {
+			storeMax(&most, running.Add(1))
+			defer running.Add(-1)
+			if !counted.CompareAndSwap(false, true) {
+				t.Error("a task ran twice")
+			}
+			ran.Add(1)
+			if panics {
+				panicked.Add(1)
+				panic("task panicked")
+			}
+		})
+		switch err {
+		case nil:
+			accepted.Add(1)
+		case context.DeadlineExceeded:
+			rejected.Add(1)
+		default:
+			t.Errorf("Submit = %v; want nil or a deadline", err)
+		}
+	})
+	p.Close()
+
+	if a, r := accepted.Load(), ran.Load(); a != r {
+		t.Errorf("%d tasks accepted but %d ran", a, r)
+	}
+	if m := most.Load(); m > workers {
+		t.Errorf("%d tasks ran at once; want at most %d", m, workers)
+	}
+	if got, want := p.Panics(), panicked.Load(); got != want {
+		t.Errorf("Panics() = %d; want %d", got, want)
+	}
+	t.Logf("%d accepted, %d timed out waiting, %d panicked", accepted.Load(), rejected.Load(), panicked.Load())
+}
@@ -0,0 +1,135 @@
+// Package workerpool runs tasks on a fixed number of goroutines fed from
+// a bounded queue, the pattern of the goroutines_and_channels demo made
+// reusable. The fixed number bounds how much runs at once; the bounded
+// queue makes Submit block when the workers fall behind, pushing back on
+// whoever produces the work instead of letting it pile up in memory:
+//
+//	p := workerpool.New(workerpool.WithWorkers(4), workerpool.WithQueue(16))
+//	for _, job := range jobs {
+//		if err := p.Submit(ctx, func() { process(job) }); err != nil {
+//			break
+//		}
+//	}
+//	p.Close() // waits for the queued tasks
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrClosed is returned by Submit after Close
+var ErrClosed = errors.New("workerpool: closed")
+
+// Pool runs submitted tasks on its workers. It is safe for concurrent use.
+type Pool struct {
+	tasks chan func()
+	wg    sync.WaitGroup
+
+	// mu orders Submit's sends before Close closes tasks: submitters
+	// hold it for reading, Close for writing
+	mu     sync.RWMutex
+	closed bool
+
+	panics atomic.Int64
+}
+
+// config holds the settings that options can change
+type config struct {
+	workers int
+	queue   int
+}
+
+// Option configures a Pool
+type Option func(*config)
+
+// WithWorkers runs tasks on n goroutines; values below 1 are treated as 1.
+// Without it the pool has runtime.GOMAXPROCS(0) workers.
+func WithWorkers(n int) Option {
+	return func(c *config) {
+		c.workers = max(n, 1)
+	}
+}
+
+// WithQueue makes room for n tasks waiting for a worker. Without it, or
+// with n below 1, Submit waits until a worker takes the task.
+func WithQueue(n int) Option {
+	return func(c *config) {
+		c.queue = max(n, 0)
+	}
+}
+
+// New starts a pool configured by opts
+func New(opts ...Option) *Pool {
+	cfg := config{workers: runtime.GOMAXPROCS(0)}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	p := &Pool{tasks: make(chan func(), cfg.queue)}
+	for range cfg.workers {
+		p.wg.Add(1)
+		go p.work()
+	}
+	return p
+}
+
+func (p *Pool) work() {
+	defer p.wg.Done()
+	for task := range p.tasks {
+		p.run(task)
+	}
+}
+
+// run runs task, counting a panic rather than letting it end the program
+// and the worker with it
+func (p *Pool) run(task func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			p.panics.Add(1)
+		}
+	}()
+	task()
+}
+
+// Submit queues task to run on a worker, waiting while the queue is full.
+// It returns ctx's error if ctx ends first, and ErrClosed after Close; in
+// both cases task will not run.
+func (p *Pool) Submit(ctx context.Context, task func()) error {
+	if task == nil {
+		return fmt.Errorf("workerpool: nil task")
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return ErrClosed
+	}
+	select {
+	case p.tasks <- task:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops the pool taking tasks and waits for the queued ones, and
+// any running, to finish. Calls after the first return at once.
+func (p *Pool) Close() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	close(p.tasks)
+	p.mu.Unlock()
+	p.wg.Wait()
+}
+
+// Panics returns how many tasks have panicked
+func (p *Pool) Panics() int64 {
+	return p.panics.Load()
+}
@@ -0,0 +1,103 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPool_RunsEveryTask(t *testing.T) {
+	p := New(WithWorkers(3), WithQueue(2))
+	var done atomic.Int64
+	for range 100 {
+		if err := p.Submit(context.Background(), func() { done.Add(1) }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p.Close()
+	if n := done.Load(); n != 100 {
+		t.Errorf("tasks run = %d; want 100, all of them by the time Close returns", n)
+	}
+}
+
+func TestPool_BoundsConcurrency(t *testing.T) {
+	const workers = 4
+	p := New(WithWorkers(workers))
+	var running, most atomic.Int64
+	for range 40 {
+		p.Submit(context.Background(), func() {
+			storeMax(&most, running.Add(1))
+			time.Sleep(time.Millisecond)
+			running.Add(-1)
+		})
+	}
+	p.Close()
+	if m := most.Load(); m > workers {
+		t.Errorf("at most %d tasks ran at once; want at most %d", m, workers)
+	}
+}
+
+func TestPool_SubmitWaitsForRoom(t *testing.T) {
+	p := New(WithWorkers(1), WithQueue(1))
+	defer p.Close()
+	release := make(chan struct{})
+	started := make(chan struct{})
+	p.Submit(context.Background(), func() { close(started); <-release })
+	<-started
+	p.Submit(context.Background(), func() {}) // fills the queue
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := p.Submit(ctx, func() { t.Error("a task whose Submit timed out ran") }); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Submit to a full queue = %v; want context.DeadlineExceeded", err)
+	}
+	close(release)
+}
+
+func TestPool_Options(t *testing.T) {
+	// The defaults, and out-of-range values, still make a pool that runs
+	// tasks
+	for name, opts := range map[string][]Option{
+		"defaults":     nil,
+		"out of range": {WithWorkers(-1), WithQueue(-5)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := New(opts...)
+			var done atomic.Int64
+			for range 10 {
+				if err := p.Submit(context.Background(), func() { done.Add(1) }); err != nil {
+					t.Fatal(err)
+				}
+			}
+			p.Close()
+			if n := done.Load(); n != 10 {
+				t.Errorf("tasks run = %d; want 10", n)
+			}
+		})
+	}
+}
+
+func TestPool_Close(t *testing.T) {
+	p := New(WithWorkers(2), WithQueue(2))
+	p.Submit(context.Background(), func() { panic("boom") })
+	p.Close()
+	p.Close()
+	if err := p.Submit(context.Background(), func() {}); !errors.Is(err, ErrClosed) {
+		t.Errorf("Submit after Close = %v; want ErrClosed", err)
+	}
+	if n := p.Panics(); n != 1 {
+		t.Errorf("Panics() = %d; want 1, and the pool still closing cleanly", n)
+	}
+}
+
+// storeMax sets v to n if n is larger
+func storeMax(v *atomic.Int64, n int64) {
+	for {
+		old := v.Load()
+		if n <= old || v.CompareAndSwap(old, n) {
+			return
+		}
+	}
+}
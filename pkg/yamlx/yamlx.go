@@ -0,0 +1,416 @@
+// Package yamlx reads and writes the small subset of YAML that
+// configuration and fixture files use: a document is either a mapping of
+// scalars or a sequence of such mappings, written in block style, with #
+// comments and plain, single- or double-quoted scalars.
+//
+//	# config.yaml          # books.yaml
+//	addr: ":8080"          - title: Go in Action
+//	workers: 4               price: 24.99
+//	                       - title: Learning Go
+//
+// Nested collections, flow style ([a, b]), block scalars (|), anchors and
+// tags are rejected with the line they are on rather than misread. The
+// standard library has no YAML package, and the whole language would need
+// a dependency.
+//
+// Values go through encoding/json on both sides, so json tags, omitempty
+// and MarshalJSON/UnmarshalJSON methods apply to YAML as they do to JSON.
+package yamlx
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// SyntaxError is a document outside the supported subset, or not YAML
+type SyntaxError struct {
+	Line int // 1-based
+	Msg  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("yamlx: line %d: %s", e.Line, e.Msg)
+}
+
+// kind is what a document holds
+type kind int
+
+const (
+	anyKind kind = iota // whichever the first line starts
+	mappingKind
+	sequenceKind
+)
+
+// Parse reads a document into the values encoding/json would decode its
+// JSON equivalent into with UseNumber: a map[string]any for a mapping, a
+// []map[string]any for a sequence, and nil, bool, json.Number or string
+// for scalars. An empty document is nil.
+func Parse(data []byte) (any, error) {
+	return parse(data, anyKind)
+}
+
+// ParseMapping reads a document that must be a mapping. An empty
+// document or {} is an empty mapping.
+func ParseMapping(data []byte) (map[string]any, error) {
+	v, err := parse(data, mappingKind)
+	if err != nil {
+		return nil, err
+	}
+	return v.(map[string]any), nil
+}
+
+// ParseSequence reads a document that must be a sequence of mappings. An
+// empty document or [] is an empty sequence.
+func ParseSequence(data []byte) ([]map[string]any, error) {
+	v, err := parse(data, sequenceKind)
+	if err != nil {
+		return nil, err
+	}
+	return v.([]map[string]any), nil
+}
+
+// Unmarshal reads a document into v as json.Unmarshal would read its JSON
+// equivalent. An empty document leaves v unchanged.
+func Unmarshal(data []byte, v any) error {
+	doc, err := Parse(data)
+	if err != nil || doc == nil {
+		return err
+	}
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func parse(data []byte, want kind) (any, error) {
+	var (
+		records              []map[string]any // the sequence
+		record               map[string]any   // the mapping keys go into
+		seqIndent, keyIndent = -1, -1
+		started              bool
+		empty                string // "[]" or "{}" once the document is one
+	)
+	fail := func(line int, format string, args ...any) (any, error) {
+		return nil, &SyntaxError{Line: line, Msg: fmt.Sprintf(format, args...)}
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if trimmed == "---" && !started {
+			continue
+		}
+		indentation := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if strings.Contains(indentation, "\t") {
+			return fail(lineNo, "tabs are not allowed in indentation")
+		}
+		indent := len(indentation)
+		if empty != "" {
+			return fail(lineNo, "unexpected %q after %s", trimmed, empty)
+		}
+		if !started {
+			started = true
+			if want == anyKind {
+				want = mappingKind
+				if isItem(trimmed) || trimmed == "[]" {
+					want = sequenceKind
+				}
+			}
+			if want == sequenceKind && trimmed == "[]" || want == mappingKind && trimmed == "{}" {
+				empty = trimmed
+				continue
+			}
+			if want == mappingKind {
+				record = make(map[string]any)
+			}
+		}
+
+		entry := trimmed
+		switch {
+		case want == sequenceKind && isItem(trimmed):
+			if seqIndent == -1 {
+				seqIndent = indent
+			}
+			if indent != seqIndent {
+				return fail(lineNo, "nested values are not supported")
+			}
+			record = make(map[string]any)
+			records = append(records, record)
+			entry = strings.TrimLeft(trimmed[1:], " ")
+			keyIndent = -1
+			if entry == "" {
+				continue
+			}
+			keyIndent = len(line) - len(entry)
+		case want == sequenceKind && (record == nil || indent <= seqIndent):
+			return fail(lineNo, `expected a list item starting with "- "`)
+		case want == mappingKind && isItem(trimmed) && (keyIndent == -1 || indent <= keyIndent):
+			return fail(lineNo, `expected "key: value", found a list item`)
+		default:
+			if keyIndent == -1 {
+				keyIndent = indent
+			}
+			if indent > keyIndent {
+				return fail(lineNo, "nested values are not supported")
+			}
+			if indent < keyIndent {
+				return fail(lineNo, "key indented differently from the ones before it")
+			}
+		}
+
+		if entry[0] == '[' || entry[0] == '{' {
+			return fail(lineNo, "flow collections are not supported")
+		}
+		key, value, err := splitEntry(entry)
+		if err != nil {
+			return fail(lineNo, "%v", err)
+		}
+		if _, dup := record[key]; dup {
+			return fail(lineNo, "key %q appears twice", key)
+		}
+		if record[key], err = scalar(value); err != nil {
+			return fail(lineNo, "%s: %v", key, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	switch want {
+	case sequenceKind:
+		if records == nil {
+			records = []map[string]any{}
+		}
+		return records, nil
+	case mappingKind:
+		if record == nil {
+			record = make(map[string]any)
+		}
+		return record, nil
+	}
+	return nil, nil // an empty document read by Parse
+}
+
+// isItem reports whether a trimmed line starts a sequence item
+func isItem(trimmed string) bool {
+	return trimmed == "-" || strings.HasPrefix(trimmed, "- ")
+}
+
+// splitEntry splits "key: value" at the first colon followed by a space or
+// the end of the line
+func splitEntry(entry string) (key, value string, err error) {
+	for i := 0; i < len(entry); i++ {
+		if entry[i] == ':' && (i+1 == len(entry) || entry[i+1] == ' ') {
+			key = strings.TrimSpace(entry[:i])
+			if key == "" {
+				break
+			}
+			return key, strings.TrimSpace(entry[i+1:]), nil
+		}
+	}
+	return "", "", fmt.Errorf(`expected "key: value", found %q`, entry)
+}
+
+// jsonNumber matches the numbers JSON allows, which are the plain YAML
+// scalars that decode as numbers
+var jsonNumber = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// scalar returns the value a YAML scalar stands for: nil, a bool, a
+// json.Number or a string
+func scalar(s string) (any, error) {
+	if s == "" {
+		return nil, nil
+	}
+	switch s[0] {
+	case '"':
+		quoted, err := strconv.QuotedPrefix(s)
+		if err != nil {
+			return nil, fmt.Errorf("bad double-quoted string %s", s)
+		}
+		if err := onlyComment(s[len(quoted):]); err != nil {
+			return nil, err
+		}
+		return strconv.Unquote(quoted)
+	case '\'':
+		// In single quotes, '' is a quote and nothing else is special
+		var b strings.Builder
+		for i := 1; i < len(s); i++ {
+			if s[i] != '\'' {
+				b.WriteByte(s[i])
+				continue
+			}
+			if i+1 < len(s) && s[i+1] == '\'' {
+				b.WriteByte('\'')
+				i++
+				continue
+			}
+			if err := onlyComment(s[i+1:]); err != nil {
+				return nil, err
+			}
+			return b.String(), nil
+		}
+		return nil, fmt.Errorf("unterminated single-quoted string %s", s)
+	case '[', '{', '|', '>', '&', '*', '!', '%', '@', '`':
+		return nil, fmt.Errorf("values starting with %q are not supported", s[0])
+	}
+
+	if i := strings.Index(s, " #"); i >= 0 {
+		s = strings.TrimSpace(s[:i])
+	}
+	switch s {
+	case "~", "null", "Null", "NULL":
+		return nil, nil
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	}
+	if jsonNumber.MatchString(s) {
+		return json.Number(s), nil
+	}
+	return s, nil
+}
+
+// onlyComment checks what follows a quoted string is at most a comment
+func onlyComment(rest string) error {
+	rest = strings.TrimSpace(rest)
+	if rest != "" && !strings.HasPrefix(rest, "#") {
+		return fmt.Errorf("unexpected %q after the string", rest)
+	}
+	return nil
+}
+
+// errShape is returned by Marshal for values outside the subset
+var errShape = errors.New("yamlx: want a mapping of scalars or a sequence of them")
+
+// Marshal writes v, which must encode to a JSON object of scalars or an
+// array of such objects, as a block-style document that Unmarshal reads
+// back to the same value. Keys come out in the order encoding/json writes
+// them: struct fields in declaration order, map keys sorted. A nil pointer
+// or slice is an empty document.
+func Marshal(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	switch tok {
+	case nil:
+		return nil, nil
+	case json.Delim('{'):
+		n, err := writeMapping(&b, dec, "", "")
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			b.WriteString("{}\n")
+		}
+	case json.Delim('['):
+		items := 0
+		for ; dec.More(); items++ {
+			if tok, err := dec.Token(); err != nil {
+				return nil, err
+			} else if tok != json.Delim('{') {
+				return nil, fmt.Errorf("%w: item %d is not a mapping", errShape, items+1)
+			}
+			n, err := writeMapping(&b, dec, "- ", "  ")
+			if err != nil {
+				return nil, fmt.Errorf("item %d: %w", items+1, err)
+			}
+			if n == 0 {
+				b.WriteString("-\n")
+			}
+		}
+		if items == 0 {
+			b.WriteString("[]\n")
+		}
+	default:
+		return nil, fmt.Errorf("%w, not %T", errShape, v)
+	}
+	return b.Bytes(), nil
+}
+
+// writeMapping writes the members of the object dec is inside, through
+// its closing brace, one per line: the first after first, the others
+// after rest. It returns how many it wrote.
+func writeMapping(b *bytes.Buffer, dec *json.Decoder, first, rest string) (int, error) {
+	n := 0
+	for ; dec.More(); n++ {
+		tok, err := dec.Token()
+		if err != nil {
+			return n, err
+		}
+		key := tok.(string)
+		if !plain(key) || strings.Contains(key, ":") {
+			return n, fmt.Errorf("yamlx: key %q cannot be written unquoted", key)
+		}
+		if tok, err = dec.Token(); err != nil {
+			return n, err
+		}
+		if _, nested := tok.(json.Delim); nested {
+			return n, fmt.Errorf("%w: %s holds a nested value", errShape, key)
+		}
+
+		if n == 0 {
+			b.WriteString(first)
+		} else {
+			b.WriteString(rest)
+		}
+		b.WriteString(key)
+		b.WriteString(": ")
+		switch tok := tok.(type) {
+		case nil:
+			b.WriteString("null")
+		case bool:
+			b.WriteString(strconv.FormatBool(tok))
+		case json.Number:
+			b.WriteString(tok.String())
+		case string:
+			if plain(tok) {
+				b.WriteString(tok)
+			} else {
+				b.WriteString(strconv.Quote(tok))
+			}
+		}
+		b.WriteByte('\n')
+	}
+	_, err := dec.Token() // the closing brace
+	return n, err
+}
+
+// plain reports whether s can be written without quotes and read back as
+// the same string, by this package and by a full YAML parser
+func plain(s string) bool {
+	if s == "" || s != strings.TrimSpace(s) || strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") {
+		return false
+	}
+	if strings.HasSuffix(s, ":") || strings.Contains(s, ": ") || strings.Contains(s, " #") {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	v, err := scalar(s)
+	return err == nil && v == s
+}
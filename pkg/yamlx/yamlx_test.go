@@ -0,0 +1,230 @@
+package yamlx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type book struct {
+	Title  string   `json:"title"`
+	Author string   `json:"author,omitempty"`
+	Price  float64  `json:"price"`
+	Stock  *int     `json:"stock"`
+	Used   bool     `json:"used"`
+	Tags   []string `json:"-"`
+}
+
+func TestRoundTrip(t *testing.T) {
+	three := 3
+	books := []book{
+		{Title: "Go in Action", Author: "William Kennedy", Price: 24.99, Stock: &three},
+		{Title: "", Price: 0, Used: true},
+		{Title: "Learning Go: 2nd ed.", Author: "Jon Bodner"},
+	}
+	strs := map[string]string{"plain": "text with spaces"}
+	for _, s := range []string{
+		"", " padded ", "true", "NULL", "~", "42", "-1.5e3", "0755", "#hash", "a #comment",
+		"- item", "key: value", "ends:", "[a]", "{b}", "|", "'single'", `"double"`,
+		"multi\nline", "tab\there", "日本語", "é", "\x00", " ",
+	} {
+		strs[fmt.Sprintf("k%02d", len(strs))] = s
+	}
+	config := struct {
+		Addr    string  `json:"addr"`
+		Workers int     `json:"workers"`
+		Ratio   float64 `json:"ratio"`
+		Debug   bool    `json:"debug"`
+		Nothing *string `json:"nothing"`
+	}{":8080", 4, 0.25, true, nil}
+
+	tests := []struct {
+		name string
+		in   any
+		out  func() any // a pointer to decode into
+	}{
+		{"sequence of structs", books, func() any { return new([]book) }},
+		{"empty sequence", []book{}, func() any { return new([]book) }},
+		{"mapping of strings", strs, func() any { return new(map[string]string) }},
+		{"struct", config, func() any { return reflect.New(reflect.TypeOf(config)).Interface() }},
+		{"empty mapping", map[string]int{}, func() any { return new(map[string]int) }},
+		{"empty items", []map[string]int{{}, {"a": 1}, {}}, func() any { return new([]map[string]int) }},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := Marshal(tc.in)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			out := tc.out()
+			if err := Unmarshal(data, out); err != nil {
+				t.Fatalf("Unmarshal of\n%s: %v", data, err)
+			}
+			if got := reflect.ValueOf(out).Elem().Interface(); !reflect.DeepEqual(got, tc.in) {
+				t.Errorf("round trip through\n%s= %#v; want %#v", data, got, tc.in)
+			}
+		})
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	three := 3
+	got, err := Marshal([]book{
+		{Title: "Go in Action", Author: "William Kennedy", Price: 24.99, Stock: &three},
+		{Title: "yes: no", Used: true, Tags: []string{"skipped"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `- title: Go in Action
+  author: William Kennedy
+  price: 24.99
+  stock: 3
+  used: false
+- title: "yes: no"
+  price: 0
+  stock: null
+  used: true
+`
+	if string(got) != want {
+		t.Errorf("Marshal =\n%s; want\n%s", got, want)
+	}
+
+	if got, err := Marshal([]book(nil)); err != nil || got != nil {
+		t.Errorf("Marshal(nil slice) = %q, %v; want an empty document", got, err)
+	}
+}
+
+func TestMarshal_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   any
+		want string
+	}{
+		{"scalar", 42, "want a mapping"},
+		{"sequence of scalars", []string{"a"}, "item 1 is not a mapping"},
+		{"nested mapping", map[string]any{"server": map[string]string{"addr": ":1"}}, "server holds a nested value"},
+		{"nested sequence", []map[string]any{{"tags": []string{"a"}}}, "item 1: yamlx: want a mapping"},
+		{"key needing quotes", map[string]int{"a: b": 1}, `key "a: b" cannot be written`},
+		{"key that is a number", map[string]int{"1": 1}, `key "1" cannot be written`},
+		{"unencodable", map[string]any{"f": func() {}}, "json: unsupported type"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := Marshal(tc.in); err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("Marshal error = %v; want it to contain %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name, data string
+		want       any
+	}{
+		{"empty", "", nil},
+		{"only comments", "# nothing\n---\n", nil},
+		{"mapping", "---\na: 1\nb: x # comment\nc:\nd: 'it''s'\n", map[string]any{"a": json.Number("1"), "b": "x", "c": nil, "d": "it's"}},
+		{"indented mapping", "  a: true\n  b: ~\n", map[string]any{"a": true, "b": nil}},
+		{"sequence", "- a: 1\n  b: \"x\\ty\"\n-\n- c: False\n", []map[string]any{{"a": json.Number("1"), "b": "x\ty"}, {}, {"c": false}}},
+		{"empty sequence", "[]\n", []map[string]any{}},
+		{"empty mapping", "{}\n", map[string]any{}},
+		{"windows line endings", "a: 1\r\nb: 2\r\n", map[string]any{"a": json.Number("1"), "b": json.Number("2")}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Parse([]byte(tc.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Parse(%q) = %#v; want %#v", tc.data, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		data string
+		line int
+		want string
+	}{
+		{"a: 1\n  b: 2\n", 2, "nested values are not supported"},
+		{"server:\n  - a: 1\n", 2, "nested values are not supported"},
+		{"  a: 1\nb: 2\n", 2, "key indented differently"},
+		{"a: 1\n- b: 2\n", 2, `expected "key: value", found a list item`},
+		{"a: 1\na: 2\n", 2, `key "a" appears twice`},
+		{"a\n", 1, `expected "key: value"`},
+		{"a: [1]\n", 1, "values starting with '[' are not supported"},
+		{"{}\na: 1\n", 2, `unexpected "a: 1" after {}`},
+		{"- a: 1\nb: 2\n", 2, `expected a list item starting with "- "`},
+		{"- a: 1\n\t b: 2\n", 2, "tabs are not allowed"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.data, func(t *testing.T) {
+			_, err := Parse([]byte(tc.data))
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("error = %v; want a *SyntaxError", err)
+			}
+			if syntaxErr.Line != tc.line || !strings.Contains(syntaxErr.Msg, tc.want) {
+				t.Errorf("error = %v; want line %d: %s", err, tc.line, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseMappingAndSequence(t *testing.T) {
+	if _, err := ParseMapping([]byte("- a: 1\n")); err == nil || !strings.Contains(err.Error(), "line 1: expected \"key: value\"") {
+		t.Errorf("ParseMapping of a sequence: error = %v", err)
+	}
+	if _, err := ParseSequence([]byte("a: 1\n")); err == nil || !strings.Contains(err.Error(), `line 1: expected a list item`) {
+		t.Errorf("ParseSequence of a mapping: error = %v", err)
+	}
+	if m, err := ParseMapping(nil); err != nil || m == nil || len(m) != 0 {
+		t.Errorf("ParseMapping(nil) = %#v, %v; want an empty mapping", m, err)
+	}
+	if s, err := ParseSequence(nil); err != nil || s == nil || len(s) != 0 {
+		t.Errorf("ParseSequence(nil) = %#v, %v; want an empty sequence", s, err)
+	}
+}
+
+func TestUnmarshal_EmptyLeavesValue(t *testing.T) {
+	v := map[string]int{"kept": 1}
+	if err := Unmarshal([]byte("# nothing\n"), &v); err != nil || v["kept"] != 1 {
+		t.Errorf("Unmarshal of an empty document = %v, %v; want v unchanged", v, err)
+	}
+}
+
+func Example() {
+	type Server struct {
+		Addr    string   `json:"addr"`
+		Workers int      `json:"workers"`
+		Debug   bool     `json:"debug,omitempty"`
+		Note    string   `json:"note"`
+		Hosts   []string `json:"-"`
+	}
+	data, err := Marshal(Server{Addr: ":8080", Workers: 4, Note: "# not a comment"})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Print(string(data))
+
+	var s Server
+	if err := Unmarshal(data, &s); err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("%+v\n", s)
+	// Output:
+	// addr: ":8080"
+	// workers: 4
+	// note: "# not a comment"
+	// {Addr::8080 Workers:4 Debug:false Note:# not a comment Hosts:[]}
+}